@@ -0,0 +1,336 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/config/anomaly"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	anomalyWindow        = time.Minute
+	anomalyAlertCooldown = 10 * time.Minute
+	anomalyIdleTimeout   = 24 * time.Hour
+	anomalyQueueSize     = 1000
+
+	// anomalyBaselineWeight is the weight of the last window in the
+	// moving average baseline, about the last 10 windows count.
+	anomalyBaselineWeight = 0.1
+)
+
+// Kinds of access anomalies.
+const (
+	anomalyMassDelete = iota
+	anomalyEnumeration
+	anomalyEgressSpike
+
+	anomalyKinds
+)
+
+var anomalyKindNames = [anomalyKinds]string{"mass_delete", "bucket_enumeration", "egress_spike"}
+
+// AccessAnomalyAlert - an anomalous minute of deletes, listings or
+// downloaded bytes of a principal in a bucket, posted to the webhook.
+type AccessAnomalyAlert struct {
+	Time      time.Time `json:"time"`
+	Node      string    `json:"node"`
+	Kind      string    `json:"kind"`
+	Principal string    `json:"principal"`
+	Bucket    string    `json:"bucket"`
+	Value     uint64    `json:"value"`
+	Baseline  float64   `json:"baseline"`
+	Threshold float64   `json:"threshold"`
+}
+
+// accessAnomalyStats counts the alerts of this node.
+type accessAnomalyStats struct {
+	Alerts  uint64
+	Failed  uint64
+	Dropped uint64
+}
+
+type accessPatternKey struct {
+	principal, bucket string
+}
+
+// accessPattern holds the counts of the current window and the
+// baseline of each anomaly kind of a principal in a bucket.
+type accessPattern struct {
+	current   [anomalyKinds]uint64
+	baseline  [anomalyKinds]float64
+	lastAlert [anomalyKinds]time.Time
+	lastSeen  time.Time
+}
+
+// accessAnomaly baselines the deletes, listings and egress of every
+// principal in every bucket, and alerts a webhook on sudden spikes as
+// an early sign of ransomware or data exfiltration.
+type accessAnomaly struct {
+	mu       sync.Mutex
+	cfg      anomaly.Config
+	patterns map[accessPatternKey]*accessPattern
+	queue    chan AccessAnomalyAlert
+	cancel   context.CancelFunc
+
+	stats accessAnomalyStats
+}
+
+var globalAccessAnomaly = &accessAnomaly{}
+
+// Update applies a new anomaly detection configuration, the baselines
+// are kept as long as detection stays enabled.
+func (a *accessAnomaly) Update(cfg anomaly.Config) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.cfg = cfg
+	a.queue = nil
+	if !cfg.Enabled {
+		a.patterns = nil
+		return
+	}
+	if a.patterns == nil {
+		a.patterns = make(map[accessPatternKey]*accessPattern)
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	a.cancel = cancel
+	a.queue = make(chan AccessAnomalyAlert, anomalyQueueSize)
+	go a.run(ctx, cfg, a.queue)
+}
+
+type accessAnomalyRequestKey struct{}
+
+// accessAnomalyRequest - the principal of a request, as authorized by
+// the auth layer.
+type accessAnomalyRequest struct {
+	once       sync.Once
+	authorized bool
+	principal  string
+}
+
+func (a *accessAnomaly) enabled() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfg.Enabled
+}
+
+// withRequest returns r to be counted for the principal it is
+// authorized as, while anomaly detection is enabled.
+func (a *accessAnomaly) withRequest(r *http.Request) *http.Request {
+	if !a.enabled() {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), accessAnomalyRequestKey{}, &accessAnomalyRequest{}))
+}
+
+// authorizeAccessAnomaly records cred as the principal of the request
+// of ctx, the parent user of temporary credentials and service accounts.
+func authorizeAccessAnomaly(ctx context.Context, cred auth.Credentials) {
+	ar, ok := ctx.Value(accessAnomalyRequestKey{}).(*accessAnomalyRequest)
+	if !ok {
+		return
+	}
+	ar.once.Do(func() {
+		ar.authorized = true
+		ar.principal = cred.AccessKey
+		if cred.ParentUser != "" {
+			ar.principal = cred.ParentUser
+		}
+	})
+}
+
+// add counts n events of the kind by the principal of r in bucket,
+// requests not authorized are not counted.
+func (a *accessAnomaly) add(r *http.Request, bucket string, kind int, n uint64) {
+	if bucket == "" || n == 0 {
+		return
+	}
+	ar, ok := r.Context().Value(accessAnomalyRequestKey{}).(*accessAnomalyRequest)
+	if !ok || !ar.authorized || !a.enabled() {
+		return
+	}
+	key := accessPatternKey{principal: ar.principal, bucket: bucket}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.patterns == nil {
+		return
+	}
+	p, ok := a.patterns[key]
+	if !ok {
+		p = &accessPattern{}
+		a.patterns[key] = p
+	}
+	p.current[kind] += n
+	p.lastSeen = time.Now()
+}
+
+// record counts the deleted objects, listings and downloaded bytes of
+// a successful S3 request.
+func (a *accessAnomaly) record(r *http.Request, api string, w *logger.ResponseWriter) {
+	if w.StatusCode < 200 || w.StatusCode > 299 {
+		return
+	}
+	bucket := mux.Vars(r)["bucket"]
+	switch api {
+	case "deleteobject":
+		a.add(r, bucket, anomalyMassDelete, 1)
+	case "listobjectsv1", "listobjectsv2", "listobjectsv2M", "listobjectversions":
+		a.add(r, bucket, anomalyEnumeration, 1)
+	case "getobject":
+		a.add(r, bucket, anomalyEgressSpike, uint64(w.Size()))
+	}
+}
+
+// recordDeletes counts the objects deleted by a multi-object delete.
+func (a *accessAnomaly) recordDeletes(r *http.Request, bucket string, n uint64) {
+	a.add(r, bucket, anomalyMassDelete, n)
+}
+
+// evaluate ends the current window, returns the alerts for counts
+// above both their minimum and sensitivity times their baseline, and
+// folds the counts into the baselines.
+func (a *accessAnomaly) evaluate(now time.Time) []AccessAnomalyAlert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	mins := [anomalyKinds]uint64{a.cfg.MinDeletes, a.cfg.MinLists, a.cfg.MinEgress}
+	var alerts []AccessAnomalyAlert
+	for key, p := range a.patterns {
+		if now.Sub(p.lastSeen) > anomalyIdleTimeout {
+			delete(a.patterns, key)
+			continue
+		}
+		for kind, v := range p.current {
+			threshold := a.cfg.Sensitivity * p.baseline[kind]
+			if min := float64(mins[kind]); threshold < min {
+				threshold = min
+			}
+			if v > 0 && float64(v) >= threshold && now.Sub(p.lastAlert[kind]) >= anomalyAlertCooldown {
+				p.lastAlert[kind] = now
+				alerts = append(alerts, AccessAnomalyAlert{
+					Time:      now.UTC(),
+					Node:      globalLocalNodeName,
+					Kind:      anomalyKindNames[kind],
+					Principal: key.principal,
+					Bucket:    key.bucket,
+					Value:     v,
+					Baseline:  p.baseline[kind],
+					Threshold: threshold,
+				})
+			}
+			p.baseline[kind] += anomalyBaselineWeight * (float64(v) - p.baseline[kind])
+			p.current[kind] = 0
+		}
+	}
+	return alerts
+}
+
+// run evaluates the access patterns every window and posts the alerts
+// until ctx is canceled.
+func (a *accessAnomaly) run(ctx context.Context, cfg anomaly.Config, queue chan AccessAnomalyAlert) {
+	go a.send(ctx, cfg, queue)
+
+	ticker := time.NewTicker(anomalyWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, alert := range a.evaluate(now) {
+				atomic.AddUint64(&a.stats.Alerts, 1)
+				logger.LogIf(ctx, fmt.Errorf("access anomaly: %s by %q in bucket %s: %d in the last minute, baseline %.1f",
+					alert.Kind, alert.Principal, alert.Bucket, alert.Value, alert.Baseline))
+				select {
+				case queue <- alert:
+				default:
+					atomic.AddUint64(&a.stats.Dropped, 1)
+				}
+			}
+		}
+	}
+}
+
+// send posts the queued alerts to the webhook.
+func (a *accessAnomaly) send(ctx context.Context, cfg anomaly.Config, queue <-chan AccessAnomalyAlert) {
+	client := &http.Client{Transport: NewRemoteTargetHTTPTransport()}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-queue:
+			if err := postAccessAnomalyAlert(ctx, client, cfg, alert); err != nil {
+				atomic.AddUint64(&a.stats.Failed, 1)
+				logger.LogOnceIf(ctx, fmt.Errorf("access anomaly: unable to post alert: %w", err), "access-anomaly-webhook")
+			}
+		}
+	}
+}
+
+func postAccessAnomalyAlert(ctx context.Context, client *http.Client, cfg anomaly.Config, alert AccessAnomalyAlert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(xhttp.ContentType, "application/json")
+	if cfg.AuthToken != "" {
+		req.Header.Set(xhttp.Authorization, "Bearer "+cfg.AuthToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	xhttp.DrainBody(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned '%s'", cfg.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// Stats returns the alert statistics.
+func (a *accessAnomaly) Stats() accessAnomalyStats {
+	return accessAnomalyStats{
+		Alerts:  atomic.LoadUint64(&a.stats.Alerts),
+		Failed:  atomic.LoadUint64(&a.stats.Failed),
+		Dropped: atomic.LoadUint64(&a.stats.Dropped),
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/config/anomaly"
+)
+
+func TestAccessAnomalyEvaluate(t *testing.T) {
+	a := &accessAnomaly{
+		cfg: anomaly.Config{
+			Enabled:     true,
+			Sensitivity: 10,
+			MinDeletes:  100,
+			MinLists:    100,
+			MinEgress:   1 << 20,
+		},
+		patterns: make(map[accessPatternKey]*accessPattern),
+	}
+	// Requests are counted for the principal authorized by the auth layer.
+	r := a.withRequest(httptest.NewRequest("DELETE", "/bucket/object", nil))
+	a.recordDeletes(r, "bucket", 50)
+	if len(a.patterns) != 0 {
+		t.Fatalf("expected requests not authorized not to be counted, got %d patterns", len(a.patterns))
+	}
+	authorizeAccessAnomaly(r.Context(), auth.Credentials{AccessKey: "sts", ParentUser: "user"})
+	authorizeAccessAnomaly(r.Context(), auth.Credentials{AccessKey: "other"})
+	now := time.Now()
+
+	// Steady deletes below the minimum build the baseline.
+	for i := 0; i < 50; i++ {
+		a.recordDeletes(r, "bucket", 50)
+		if alerts := a.evaluate(now); len(alerts) != 0 {
+			t.Fatalf("window %d: unexpected alerts %v", i, alerts)
+		}
+		now = now.Add(anomalyWindow)
+	}
+
+	// Above the minimum but within sensitivity times the baseline.
+	a.recordDeletes(r, "bucket", 400)
+	if alerts := a.evaluate(now); len(alerts) != 0 {
+		t.Fatalf("unexpected alerts %v", alerts)
+	}
+	now = now.Add(anomalyWindow)
+
+	a.recordDeletes(r, "bucket", 5000)
+	alerts := a.evaluate(now)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %v", alerts)
+	}
+	if alerts[0].Kind != "mass_delete" || alerts[0].Principal != "user" || alerts[0].Bucket != "bucket" || alerts[0].Value != 5000 {
+		t.Errorf("unexpected alert %v", alerts[0])
+	}
+	now = now.Add(anomalyWindow)
+
+	// The same anomaly is not alerted again within the cooldown.
+	a.recordDeletes(r, "bucket", 50000)
+	if alerts = a.evaluate(now); len(alerts) != 0 {
+		t.Fatalf("unexpected alerts within cooldown %v", alerts)
+	}
+
+	// Idle patterns are forgotten.
+	a.evaluate(now.Add(anomalyIdleTimeout + time.Minute))
+	if len(a.patterns) != 0 {
+		t.Errorf("expected idle patterns to be evicted, got %d", len(a.patterns))
+	}
+}
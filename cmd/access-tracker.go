@@ -0,0 +1,360 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// Last access times of the objects of a bucket are kept in
+	// accessTimeShards files under pathJoin(bucketMetaPrefix, bucket,
+	// accessTimesPrefix), mapping object names to the day of their
+	// last read. Every flush writes the recent reads of a shard to a
+	// delta file next to it, the deltas are merged into the shard file
+	// once they are more than maxAccessTimeDeltas.
+	accessTimesPrefix   = "access-times"
+	accessTimeShards    = 32
+	maxAccessTimeDeltas = 16
+
+	accessTimeFlushInterval = 5 * time.Minute
+	accessTimesCacheTTL     = time.Hour
+
+	// Reads of more objects than this between two flushes are not
+	// recorded, access times are a hint and need not be exact.
+	maxPendingAccessTimes = 100000
+)
+
+// Max number of access times cached for the scanner, all buckets
+// together, the access times of larger buckets are not cached.
+var maxCachedAccessTimes = 1000000
+
+var globalAccessTracker = newAccessTracker()
+
+// accessTracker records coarse last access times of objects, with day
+// granularity. Reads are batched in memory and merged periodically into
+// a sidecar index per bucket, such that reads never write xl.meta.
+type accessTracker struct {
+	enabled int32
+
+	mu       sync.Mutex
+	pending  map[string]map[string]int64 // index file => object => day
+	npending int
+
+	cacheMu     sync.Mutex
+	cache       map[string]bucketAccessTimes
+	cachedTimes int
+}
+
+// bucketAccessTimes - the access times of a bucket loaded by the scanner.
+type bucketAccessTimes struct {
+	times    map[string]int64
+	loadedAt time.Time
+}
+
+func newAccessTracker() *accessTracker {
+	return &accessTracker{
+		pending: make(map[string]map[string]int64),
+		cache:   make(map[string]bucketAccessTimes),
+	}
+}
+
+func (t *accessTracker) setEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&t.enabled, v)
+}
+
+func (t *accessTracker) isEnabled() bool {
+	return atomic.LoadInt32(&t.enabled) == 1
+}
+
+// accessDay returns the day of t, the unit of recorded access times.
+func accessDay(t time.Time) int64 {
+	return t.Unix() / int64(24*time.Hour/time.Second)
+}
+
+// accessTimesFile returns the index file holding the access time of object.
+func accessTimesFile(bucket, object string) string {
+	return accessTimesShardFile(bucket, int(xxhash.Sum64String(object)%accessTimeShards))
+}
+
+func accessTimesShardFile(bucket string, shard int) string {
+	return pathJoin(bucketMetaPrefix, bucket, accessTimesPrefix, fmt.Sprintf("%02d.json", shard))
+}
+
+// markAccessed records that object was read now.
+func (t *accessTracker) markAccessed(bucket, object string) {
+	if !t.isEnabled() {
+		return
+	}
+	day := accessDay(UTCNow())
+	file := accessTimesFile(bucket, object)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	times, ok := t.pending[file]
+	if !ok {
+		times = make(map[string]int64)
+		t.pending[file] = times
+	}
+	if _, ok = times[object]; !ok {
+		if t.npending >= maxPendingAccessTimes {
+			return
+		}
+		t.npending++
+	}
+	times[object] = day
+}
+
+// flush merges the pending access times into the index files.
+func (t *accessTracker) flush(ctx context.Context, objAPI ObjectLayer) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]map[string]int64)
+	t.npending = 0
+	t.mu.Unlock()
+
+	for file, times := range pending {
+		logger.LogIf(ctx, mergeAccessTimes(ctx, objAPI, file, times))
+	}
+}
+
+// accessTimesDeltaPrefix returns the prefix of the delta files of an
+// index file.
+func accessTimesDeltaPrefix(file string) string {
+	return strings.TrimSuffix(file, ".json") + SlashSeparator
+}
+
+func readAccessTimes(ctx context.Context, objAPI ObjectLayer, file string, times map[string]int64) error {
+	data, err := readConfig(ctx, objAPI, file)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil
+		}
+		return err
+	}
+	var index map[string]int64
+	if err = json.Unmarshal(data, &index); err != nil {
+		return err
+	}
+	for object, day := range index {
+		if day > times[object] {
+			times[object] = day
+		}
+	}
+	return nil
+}
+
+// loadAccessTimes reads an index file merged with its deltas, keeping
+// the latest access time of every object, a missing file is empty. The
+// names of the deltas read are returned along.
+func loadAccessTimes(ctx context.Context, objAPI ObjectLayer, file string) (map[string]int64, []string, error) {
+	times := make(map[string]int64)
+	if err := readAccessTimes(ctx, objAPI, file, times); err != nil {
+		return nil, nil, err
+	}
+
+	var deltas []string
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, accessTimesDeltaPrefix(file), marker, "", maxObjectList)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, oi := range loi.Objects {
+			if err = readAccessTimes(ctx, objAPI, oi.Name, times); err != nil {
+				return nil, nil, err
+			}
+			deltas = append(deltas, oi.Name)
+		}
+		if !loi.IsTruncated {
+			return times, deltas, nil
+		}
+		marker = loi.NextMarker
+	}
+}
+
+// mergeAccessTimes records times in a new delta of an index file, such
+// that a flush writes the recent reads only.
+func mergeAccessTimes(ctx context.Context, objAPI ObjectLayer, file string, times map[string]int64) error {
+	data, err := json.Marshal(times)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, accessTimesDeltaPrefix(file)+mustGetUUID()+".json", data)
+}
+
+// compactAccessTimes merges the deltas of an index file into it, once
+// they are more than maxAccessTimeDeltas.
+func compactAccessTimes(ctx context.Context, objAPI ObjectLayer, file string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, file)
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	times, deltas, err := loadAccessTimes(ctx, objAPI, file)
+	if err != nil {
+		return err
+	}
+	if len(deltas) <= maxAccessTimeDeltas {
+		return nil
+	}
+	data, err := json.Marshal(times)
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, file, data); err != nil {
+		return err
+	}
+	// Deltas written meanwhile are left for the next compaction.
+	for _, delta := range deltas {
+		if err = deleteConfig(ctx, objAPI, delta); err != nil && !errors.Is(err, errConfigNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketAccessTimes returns the recorded access times of all objects of
+// bucket, or nil when tracking is disabled. The index is loaded at most
+// once every accessTimesCacheTTL, as every drive scans the bucket.
+func (t *accessTracker) bucketAccessTimes(ctx context.Context, objAPI ObjectLayer, bucket string) map[string]int64 {
+	if !t.isEnabled() {
+		return nil
+	}
+
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	if c, ok := t.cache[bucket]; ok && time.Since(c.loadedAt) < accessTimesCacheTTL {
+		return c.times
+	}
+
+	times := make(map[string]int64)
+	for shard := 0; shard < accessTimeShards; shard++ {
+		file := accessTimesShardFile(bucket, shard)
+		index, deltas, err := loadAccessTimes(ctx, objAPI, file)
+		if err != nil {
+			logger.LogIf(ctx, err)
+			continue
+		}
+		for object, day := range index {
+			times[object] = day
+		}
+		if len(deltas) > maxAccessTimeDeltas {
+			logger.LogIf(ctx, compactAccessTimes(ctx, objAPI, file))
+		}
+	}
+	t.cacheTimes(bucket, times)
+	return times
+}
+
+// cacheTimes caches the access times of bucket, evicting the expired
+// and then the oldest buckets to stay within maxCachedAccessTimes.
+func (t *accessTracker) cacheTimes(bucket string, times map[string]int64) {
+	t.uncache(bucket)
+	if len(times) > maxCachedAccessTimes {
+		return
+	}
+	for b, c := range t.cache {
+		if time.Since(c.loadedAt) >= accessTimesCacheTTL {
+			t.uncache(b)
+		}
+	}
+	for t.cachedTimes+len(times) > maxCachedAccessTimes {
+		oldest := ""
+		for b, c := range t.cache {
+			if oldest == "" || c.loadedAt.Before(t.cache[oldest].loadedAt) {
+				oldest = b
+			}
+		}
+		t.uncache(oldest)
+	}
+	t.cache[bucket] = bucketAccessTimes{times: times, loadedAt: time.Now()}
+	t.cachedTimes += len(times)
+}
+
+func (t *accessTracker) uncache(bucket string) {
+	if c, ok := t.cache[bucket]; ok {
+		t.cachedTimes -= len(c.times)
+		delete(t.cache, bucket)
+	}
+}
+
+// ColdDataReport - sizes of the objects of a bucket not read for 30, 90
+// and 365 days, as of the last scanner cycle.
+type ColdDataReport struct {
+	Bucket string `json:"bucket"`
+	// Tracking is false when access tracking is disabled, objects are
+	// then only accounted once tracking is enabled.
+	Tracking    bool                       `json:"tracking"`
+	Size        uint64                     `json:"size"`
+	NotAccessed map[string]uint64          `json:"notAccessed,omitempty"`
+	Prefixes    map[string]ColdPrefixUsage `json:"prefixes,omitempty"`
+}
+
+// ColdPrefixUsage - sizes of the objects of a top level prefix.
+type ColdPrefixUsage struct {
+	Size        uint64            `json:"size"`
+	NotAccessed map[string]uint64 `json:"notAccessed,omitempty"`
+
+	coldSizes *coldSizes
+}
+
+// coldSizesOf returns the cold sizes of an object of size bytes last
+// read on lastAccessDay.
+func coldSizesOf(size int64, lastAccessDay int64) (cs coldSizes) {
+	age := accessDay(UTCNow()) - lastAccessDay
+	for i, days := range coldAccessDays {
+		if age >= int64(days) {
+			cs[i] = uint64(size)
+		}
+	}
+	return cs
+}
+
+// initAccessTracking starts the routine flushing recorded access times.
+func initAccessTracking(ctx context.Context, objAPI ObjectLayer) {
+	go func() {
+		t := time.NewTicker(accessTimeFlushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				globalAccessTracker.flush(ctx, objAPI)
+			}
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAccessTrackerFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	tracker := newAccessTracker()
+	tracker.markAccessed("bucket", "object")
+	if len(tracker.pending) != 0 {
+		t.Fatal("expected no access recorded while tracking is disabled")
+	}
+
+	tracker.setEnabled(true)
+	tracker.markAccessed("bucket", "object")
+	tracker.markAccessed("bucket", "dir/object")
+	tracker.flush(ctx, objLayer)
+	if tracker.npending != 0 {
+		t.Fatalf("expected pending access times to be flushed, got %d", tracker.npending)
+	}
+
+	// An older access merged later must not move the access time back.
+	file := accessTimesFile("bucket", "object")
+	if err = mergeAccessTimes(ctx, objLayer, file, map[string]int64{"object": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	today := accessDay(UTCNow())
+	times := tracker.bucketAccessTimes(ctx, objLayer, "bucket")
+	if len(times) != 2 || times["object"] != today || times["dir/object"] != today {
+		t.Fatalf("expected both objects accessed on day %d, got %v", today, times)
+	}
+
+	// Deltas are merged into the index file once too many.
+	for i := 0; i < maxAccessTimeDeltas; i++ {
+		if err = mergeAccessTimes(ctx, objLayer, file, map[string]int64{"old": int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = compactAccessTimes(ctx, objLayer, file); err != nil {
+		t.Fatal(err)
+	}
+	index, deltas, err := loadAccessTimes(ctx, objLayer, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltas) != 0 || index["object"] != today || index["old"] != maxAccessTimeDeltas-1 {
+		t.Fatalf("expected the deltas to be compacted, got %v %v", index, deltas)
+	}
+}
+
+func TestAccessTrackerCacheBound(t *testing.T) {
+	defer func(max int) { maxCachedAccessTimes = max }(maxCachedAccessTimes)
+	maxCachedAccessTimes = 3
+
+	tracker := newAccessTracker()
+	tracker.cacheTimes("a", map[string]int64{"1": 1, "2": 1})
+	tracker.cacheTimes("b", map[string]int64{"1": 1})
+	if tracker.cachedTimes != 3 || len(tracker.cache) != 2 {
+		t.Fatalf("expected both buckets cached, got %d %v", tracker.cachedTimes, tracker.cache)
+	}
+	// The oldest bucket is evicted.
+	tracker.cacheTimes("c", map[string]int64{"1": 1})
+	if _, ok := tracker.cache["a"]; ok || tracker.cachedTimes != 2 {
+		t.Fatalf("expected a to be evicted, got %d %v", tracker.cachedTimes, tracker.cache)
+	}
+	// Buckets larger than the cache are not cached.
+	tracker.cacheTimes("d", map[string]int64{"1": 1, "2": 1, "3": 1, "4": 1})
+	if _, ok := tracker.cache["d"]; ok || tracker.cachedTimes != 2 {
+		t.Fatalf("expected d not to be cached, got %d %v", tracker.cachedTimes, tracker.cache)
+	}
+}
@@ -0,0 +1,575 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/kms"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/certs"
+	"github.com/minio/pkg/env"
+	"golang.org/x/crypto/acme"
+)
+
+// ACME environment variables.
+const (
+	EnvACMEEnable       = "MINIO_ACME_ENABLE"
+	EnvACMEEmail        = "MINIO_ACME_EMAIL"
+	EnvACMEDirectoryURL = "MINIO_ACME_DIRECTORY_URL"
+	EnvACMEChallenge    = "MINIO_ACME_CHALLENGE"
+	EnvACMEHTTPAddress  = "MINIO_ACME_HTTP_ADDRESS"
+
+	EnvACMEDNSProvider    = "MINIO_ACME_DNS_PROVIDER"
+	EnvACMEDNSPropagation = "MINIO_ACME_DNS_PROPAGATION"
+	EnvACMEDNSWebhookURL  = "MINIO_ACME_DNS_WEBHOOK_ENDPOINT"
+	EnvACMEDNSWebhookAuth = "MINIO_ACME_DNS_WEBHOOK_AUTH_TOKEN"
+)
+
+// ACME challenge types.
+const (
+	acmeChallengeHTTP01 = "http-01"
+	acmeChallengeDNS01  = "dns-01"
+)
+
+const (
+	acmePrefix = "acme"
+
+	// Certificates are renewed a month before they expire.
+	acmeRenewBefore     = 30 * 24 * time.Hour
+	acmeRenewInterval   = 12 * time.Hour
+	acmeReloadInterval  = time.Hour
+	acmeLeaderLockRetry = time.Minute
+)
+
+var errACMENoCertificate = errors.New("No ACME certificate obtained yet")
+
+// acmeDNSProvider publishes the TXT records of DNS-01 challenges.
+type acmeDNSProvider interface {
+	// Present creates the TXT record fqdn with value.
+	Present(ctx context.Context, fqdn, value string) error
+	// CleanUp removes the TXT record fqdn with value.
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// acmeDNSProviders are the DNS-01 providers selectable by MINIO_ACME_DNS_PROVIDER.
+var acmeDNSProviders = map[string]func() (acmeDNSProvider, error){
+	"webhook": newACMEWebhookProvider,
+}
+
+// acmeWebhookProvider delegates TXT records to a webhook, which receives
+// a JSON object with the action "present" or "cleanup", the fqdn and the
+// value of the record.
+type acmeWebhookProvider struct {
+	endpoint  string
+	authToken string
+	client    *http.Client
+}
+
+func newACMEWebhookProvider() (acmeDNSProvider, error) {
+	endpoint := env.Get(EnvACMEDNSWebhookURL, "")
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s must be set for the webhook DNS provider", EnvACMEDNSWebhookURL)
+	}
+	return &acmeWebhookProvider{
+		endpoint:  endpoint,
+		authToken: env.Get(EnvACMEDNSWebhookAuth, ""),
+		client:    &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+func (p *acmeWebhookProvider) call(ctx context.Context, action, fqdn, value string) error {
+	body, err := json.Marshal(map[string]string{
+		"action": action,
+		"fqdn":   fqdn,
+		"value":  value,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.authToken)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ACME DNS webhook %s of %s failed: %s", action, fqdn, resp.Status)
+	}
+	return nil
+}
+
+func (p *acmeWebhookProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.call(ctx, "present", fqdn, value)
+}
+
+func (p *acmeWebhookProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.call(ctx, "cleanup", fqdn, value)
+}
+
+// acmeSys obtains and renews the certificates of the server domains
+// from an ACME directory. Certificates are kept in the backend, the
+// node holding the leader lock obtains them and all nodes reload them.
+type acmeSys struct {
+	mu    sync.RWMutex
+	certs map[string]*tls.Certificate // domain => certificate
+
+	domains      []string
+	email        string
+	directoryURL string
+	challenge    string
+	httpAddress  string
+	dnsProvider  acmeDNSProvider
+	propagation  time.Duration
+}
+
+// globalACMESys is nil unless MINIO_ACME_ENABLE is on.
+var globalACMESys *acmeSys
+
+// newACMESys returns the ACME subsystem configured by the environment,
+// nil if disabled.
+func newACMESys(domains []string) (*acmeSys, error) {
+	enabled, err := config.ParseBool(env.Get(EnvACMEEnable, config.EnableOff))
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("%s requires %s to be set", EnvACMEEnable, config.EnvDomain)
+	}
+	s := &acmeSys{
+		certs:        make(map[string]*tls.Certificate),
+		domains:      domains,
+		email:        env.Get(EnvACMEEmail, ""),
+		directoryURL: env.Get(EnvACMEDirectoryURL, acme.LetsEncryptURL),
+		challenge:    env.Get(EnvACMEChallenge, acmeChallengeHTTP01),
+		httpAddress:  env.Get(EnvACMEHTTPAddress, ":80"),
+	}
+	switch s.challenge {
+	case acmeChallengeHTTP01:
+	case acmeChallengeDNS01:
+		name := env.Get(EnvACMEDNSProvider, "webhook")
+		newProvider, ok := acmeDNSProviders[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown ACME DNS provider %q", name)
+		}
+		if s.dnsProvider, err = newProvider(); err != nil {
+			return nil, err
+		}
+		if s.propagation, err = time.ParseDuration(env.Get(EnvACMEDNSPropagation, "30s")); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unknown ACME challenge %q, expected %s or %s", s.challenge, acmeChallengeHTTP01, acmeChallengeDNS01)
+	}
+	return s, nil
+}
+
+// names returns the names of the certificate of domain, wildcard
+// certificates covering the buckets of the domain need DNS-01.
+func (s *acmeSys) names(domain string) []string {
+	if s.challenge == acmeChallengeDNS01 {
+		return []string{domain, "*." + domain}
+	}
+	return []string{domain}
+}
+
+// getCertificateFunc returns the certificates obtained for the names
+// they are valid for, others are served by fallback.
+func (s *acmeSys) getCertificateFunc(fallback certs.GetCertificateFunc) certs.GetCertificateFunc {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		var first *tls.Certificate
+		for _, domain := range s.domains {
+			cert, ok := s.certs[domain]
+			if !ok {
+				continue
+			}
+			if hello.ServerName != "" && cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return cert, nil
+			}
+			if first == nil {
+				first = cert
+			}
+		}
+		if fallback != nil {
+			return fallback(hello)
+		}
+		if first != nil {
+			return first, nil
+		}
+		return nil, errACMENoCertificate
+	}
+}
+
+func acmeCertPath(domain string) string {
+	return pathJoin(minioConfigPrefix, acmePrefix, "certs", domain+".pem")
+}
+
+func acmeAccountKeyPath() string {
+	return pathJoin(minioConfigPrefix, acmePrefix, "account.key")
+}
+
+func acmeHTTPChallengePath(token string) string {
+	return pathJoin(minioConfigPrefix, acmePrefix, acmeChallengeHTTP01, token)
+}
+
+// saveACMEConfig saves private keys, encrypted if a KMS is configured.
+func saveACMEConfig(ctx context.Context, objAPI ObjectLayer, configFile string, data []byte) (err error) {
+	if GlobalKMS != nil {
+		data, err = config.EncryptBytes(GlobalKMS, data, kms.Context{
+			minioMetaBucket: path.Join(minioMetaBucket, configFile),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return saveConfig(ctx, objAPI, configFile, data)
+}
+
+func readACMEConfig(ctx context.Context, objAPI ObjectLayer, configFile string) ([]byte, error) {
+	data, err := readConfig(ctx, objAPI, configFile)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(data) && GlobalKMS != nil {
+		return config.DecryptBytes(GlobalKMS, data, kms.Context{
+			minioMetaBucket: path.Join(minioMetaBucket, configFile),
+		})
+	}
+	return data, nil
+}
+
+// loadACMECertificate loads the certificate of domain from the backend, the
+// PEM encoded chain followed by its private key.
+func loadACMECertificate(ctx context.Context, objAPI ObjectLayer, domain string) (*tls.Certificate, error) {
+	data, err := readACMEConfig(ctx, objAPI, acmeCertPath(domain))
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// reload loads the certificates of all domains from the backend.
+func (s *acmeSys) reload(ctx context.Context, objAPI ObjectLayer) {
+	for _, domain := range s.domains {
+		cert, err := loadACMECertificate(ctx, objAPI, domain)
+		if err != nil {
+			if !errors.Is(err, errConfigNotFound) {
+				logger.LogIf(ctx, fmt.Errorf("Unable to load ACME certificate of %s: %w", domain, err))
+			}
+			continue
+		}
+		s.mu.Lock()
+		s.certs[domain] = cert
+		s.mu.Unlock()
+	}
+}
+
+// needsRenewal returns whether the certificate of domain is missing or
+// about to expire.
+func (s *acmeSys) needsRenewal(domain string, now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cert, ok := s.certs[domain]
+	return !ok || cert.Leaf.NotAfter.Sub(now) < acmeRenewBefore
+}
+
+// initACME starts serving HTTP-01 challenges and the routines obtaining,
+// renewing and reloading the certificates.
+func initACME(ctx context.Context, objAPI ObjectLayer) {
+	if globalACMESys == nil {
+		return
+	}
+	globalACMESys.reload(ctx, objAPI)
+	if globalACMESys.challenge == acmeChallengeHTTP01 {
+		go globalACMESys.serveHTTPChallenges(ctx, objAPI)
+	}
+	go globalACMESys.runReload(ctx, objAPI)
+	go globalACMESys.runRenewal(ctx, objAPI)
+}
+
+// serveHTTPChallenges answers the HTTP-01 challenges of all nodes, the
+// ACME server may reach any of them.
+func (s *acmeSys) serveHTTPChallenges(ctx context.Context, objAPI ObjectLayer) {
+	const challengePrefix = "/.well-known/acme-challenge/"
+	mux := http.NewServeMux()
+	mux.HandleFunc(challengePrefix, func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, challengePrefix)
+		if token == "" || strings.Contains(token, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := readConfig(r.Context(), objAPI, acmeHTTPChallengePath(token))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(data)
+	})
+	srv := &http.Server{
+		Addr:              s.httpAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.LogIf(ctx, fmt.Errorf("Unable to serve ACME challenges on %s: %w", s.httpAddress, err))
+	}
+}
+
+// runReload reloads the certificates renewed by other nodes.
+func (s *acmeSys) runReload(ctx context.Context, objAPI ObjectLayer) {
+	t := time.NewTicker(acmeReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.reload(ctx, objAPI)
+		}
+	}
+}
+
+// runRenewal obtains the missing certificates and renews those about to
+// expire, only the node holding the leader lock does the work. A node
+// losing the lock competes for it again.
+func (s *acmeSys) runRenewal(ctx context.Context, objAPI ObjectLayer) {
+	locker := objAPI.NewNSLock(minioMetaBucket, "runACMERenewal.lock")
+	for {
+		lkctx, err := locker.GetLock(ctx, newDynamicTimeout(acmeLeaderLockRetry, acmeLeaderLockRetry))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		// No unlock for "leader" lock.
+		s.renew(lkctx.Context(), objAPI)
+		lkctx.Cancel()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// renew obtains and renews the certificates until ctx is canceled.
+func (s *acmeSys) renew(ctx context.Context, objAPI ObjectLayer) {
+	for {
+		// Certificates may have been renewed by the previous leader.
+		s.reload(ctx, objAPI)
+		for _, domain := range s.domains {
+			if !s.needsRenewal(domain, UTCNow()) {
+				continue
+			}
+			if err := s.obtain(ctx, objAPI, domain); err != nil {
+				logger.LogIf(ctx, fmt.Errorf("Unable to obtain ACME certificate of %s: %w", domain, err))
+				continue
+			}
+			logger.Info("Obtained ACME certificate of %s", domain)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(acmeRenewInterval):
+		}
+	}
+}
+
+// accountKey returns the key of the ACME account, created on first use.
+func accountKey(ctx context.Context, objAPI ObjectLayer) (crypto.Signer, error) {
+	data, err := readACMEConfig(ctx, objAPI, acmeAccountKeyPath())
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("Invalid ACME account key")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	if !errors.Is(err, errConfigNotFound) {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err = saveACMEConfig(ctx, objAPI, acmeAccountKeyPath(), data); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// obtain orders a new certificate of domain, saves it to the backend
+// and starts serving it.
+func (s *acmeSys) obtain(ctx context.Context, objAPI ObjectLayer, domain string) error {
+	key, err := accountKey(ctx, objAPI)
+	if err != nil {
+		return err
+	}
+	client := &acme.Client{Key: key, DirectoryURL: s.directoryURL}
+	var contact []string
+	if s.email != "" {
+		contact = []string{"mailto:" + s.email}
+	}
+	if _, err = client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS); err != nil && err != acme.ErrAccountAlreadyExists {
+		return err
+	}
+
+	names := s.names(domain)
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(names...))
+	if err != nil {
+		return err
+	}
+	for _, u := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, u)
+		if err != nil {
+			return err
+		}
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+		if err = s.authorize(ctx, objAPI, client, authz); err != nil {
+			return err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return err
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: names,
+	}, certKey)
+	if err != nil {
+		return err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, der := range chain {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	der, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return err
+	}
+	pem.Encode(&buf, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err = saveACMEConfig(ctx, objAPI, acmeCertPath(domain), buf.Bytes()); err != nil {
+		return err
+	}
+	s.reload(ctx, objAPI)
+	return nil
+}
+
+// authorize fulfills the challenge of authz of the configured type.
+func (s *acmeSys) authorize(ctx context.Context, objAPI ObjectLayer, client *acme.Client, authz *acme.Authorization) error {
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == s.challenge {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("ACME server offers no %s challenge for %s", s.challenge, authz.Identifier.Value)
+	}
+
+	switch s.challenge {
+	case acmeChallengeHTTP01:
+		resp, err := client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return err
+		}
+		challengePath := acmeHTTPChallengePath(chal.Token)
+		if err = saveConfig(ctx, objAPI, challengePath, []byte(resp)); err != nil {
+			return err
+		}
+		defer deleteConfig(ctx, objAPI, challengePath)
+	case acmeChallengeDNS01:
+		value, err := client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return err
+		}
+		fqdn := "_acme-challenge." + authz.Identifier.Value
+		if err = s.dnsProvider.Present(ctx, fqdn, value); err != nil {
+			return err
+		}
+		defer s.dnsProvider.CleanUp(ctx, fqdn, value)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.propagation):
+		}
+	}
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return err
+	}
+	_, err := client.WaitAuthorization(ctx, authz.URI)
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newACMETestCertificate(t *testing.T, names ...string) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     names,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestACMEGetCertificate(t *testing.T) {
+	s := &acmeSys{
+		certs:     make(map[string]*tls.Certificate),
+		domains:   []string{"a.example.com", "b.example.com"},
+		challenge: acmeChallengeDNS01,
+	}
+	getCert := s.getCertificateFunc(nil)
+	if _, err := getCert(&tls.ClientHelloInfo{ServerName: "a.example.com"}); err != errACMENoCertificate {
+		t.Fatalf("expected %v, got %v", errACMENoCertificate, err)
+	}
+
+	certA := newACMETestCertificate(t, s.names("a.example.com")...)
+	certB := newACMETestCertificate(t, s.names("b.example.com")...)
+	s.certs["a.example.com"] = certA
+	s.certs["b.example.com"] = certB
+
+	testCases := []struct {
+		serverName string
+		want       *tls.Certificate
+	}{
+		{"a.example.com", certA},
+		{"bucket.a.example.com", certA},
+		{"bucket.b.example.com", certB},
+		{"", certA},
+		{"other.org", certA},
+	}
+	for i, tc := range testCases {
+		cert, err := getCert(&tls.ClientHelloInfo{ServerName: tc.serverName})
+		if err != nil || cert != tc.want {
+			t.Errorf("Test %d: unexpected certificate for %q, %v", i+1, tc.serverName, err)
+		}
+	}
+
+	fallback := newACMETestCertificate(t, "node1")
+	getCert = s.getCertificateFunc(func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return fallback, nil
+	})
+	if cert, _ := getCert(&tls.ClientHelloInfo{ServerName: "node1"}); cert != fallback {
+		t.Error("expected the static certificate for names without ACME certificate")
+	}
+
+	if !s.needsRenewal("a.example.com", time.Now()) {
+		t.Error("expected a certificate expiring within a month to be renewed")
+	}
+	if s.needsRenewal("a.example.com", certA.Leaf.NotAfter.Add(-2*acmeRenewBefore)) {
+		t.Error("expected a certificate valid beyond a month not to be renewed")
+	}
+}
@@ -18,10 +18,12 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	jsoniter "github.com/json-iterator/go"
@@ -39,7 +41,8 @@ const (
 // ----------
 // Places a quota configuration on the specified bucket. The quota
 // specified in the quota configuration will be applied by default
-// to enforce total quota for the specified bucket.
+// to enforce total quota for the specified bucket, its soft quota
+// thresholds are notified as the bucket usage crosses them.
 func (a adminAPIHandlers) PutBucketQuotaConfigHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "PutBucketQuotaConfig")
 
@@ -70,6 +73,11 @@ func (a adminAPIHandlers) PutBucketQuotaConfigHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	if _, err = parseBucketQuotaOptions(data); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	if err = globalBucketMetadataSys.Update(bucket, bucketQuotaConfigFile, data); err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
@@ -105,6 +113,264 @@ func (a adminAPIHandlers) GetBucketQuotaConfigHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	quotaOptions, err := globalBucketMetadataSys.GetQuotaOptions(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	qcfg := bucketQuotaConfig{}
+	if config != nil {
+		qcfg.BucketQuota = *config
+	}
+	if quotaOptions != nil {
+		qcfg.BucketQuotaOptions = *quotaOptions
+	}
+
+	configData, err := json.Marshal(qcfg)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketCompressionConfigHandler - PUT /minio/admin/v3/set-bucket-compression?bucket={bucket}
+// ----------
+// Configures how new objects of a bucket are compressed, overriding the
+// server compression settings. Existing objects are left as they are.
+func (a adminAPIHandlers) PutBucketCompressionConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketCompressionConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketCompressionConfig(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketCompressionConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketCompressionConfigHandler - gets bucket compression configuration
+func (a adminAPIHandlers) GetBucketCompressionConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketCompressionConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config, err := globalBucketMetadataSys.GetCompressionConfig(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if config == nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchBucketCompressionConfig",
+			Message:    "bucket has no compression configuration, the server compression settings apply",
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketReadAheadConfigHandler - PUT /minio/admin/v3/set-bucket-readahead?bucket={bucket}
+// ----------
+// Configures prefetching for the sequential ranged readers of the
+// objects of a bucket.
+func (a adminAPIHandlers) PutBucketReadAheadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketReadAheadConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketReadAheadConfig(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketReadAheadConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketReadAheadConfigHandler - gets bucket read-ahead configuration
+func (a adminAPIHandlers) GetBucketReadAheadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketReadAheadConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config := globalBucketMetadataSys.GetReadAheadConfig(bucket)
+	if config == nil {
+		config = &BucketReadAheadConfig{}
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketObjectDefaultsHandler - PUT /minio/admin/v3/set-bucket-object-defaults?bucket={bucket}
+// ----------
+// Sets the tags and user metadata of the objects written to a bucket
+// without them, objects already stored are left as they are.
+func (a adminAPIHandlers) PutBucketObjectDefaultsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketObjectDefaults")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketObjectDefaults(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketObjectDefaultsFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketObjectDefaultsHandler - gets the default tags and metadata of
+// the objects of a bucket
+func (a adminAPIHandlers) GetBucketObjectDefaultsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketObjectDefaults")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config := globalBucketMetadataSys.GetObjectDefaults(bucket)
+	if config == nil {
+		config = &BucketObjectDefaults{}
+	}
+
 	configData, err := json.Marshal(config)
 	if err != nil {
 		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
@@ -115,6 +381,1406 @@ func (a adminAPIHandlers) GetBucketQuotaConfigHandler(w http.ResponseWriter, r *
 	writeSuccessResponseJSON(w, configData)
 }
 
+// PutBucketNetworkACLHandler - PUT /minio/admin/v3/set-bucket-network-acl?bucket={bucket}
+// ----------
+// Sets the networks allowed and denied to access a bucket, requests
+// from other networks are rejected before they are authenticated.
+func (a adminAPIHandlers) PutBucketNetworkACLHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketNetworkACL")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketNetworkACL(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketNetworkACLFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketNetworkACLHandler - gets bucket network ACL
+func (a adminAPIHandlers) GetBucketNetworkACLHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketNetworkACL")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	acl := globalBucketMetadataSys.GetNetworkACL(bucket)
+	if acl == nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchBucketNetworkACL",
+			Message:    "bucket has no network ACL, all networks are allowed",
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(acl)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketPlacementHandler - PUT /minio/admin/v3/set-bucket-placement?bucket={bucket}&migrate={bool}
+// ----------
+// Pins a bucket to the pools having all the tags of the placement, new
+// objects are only placed on those pools. With migrate the objects on
+// other pools are moved in the background.
+func (a adminAPIHandlers) PutBucketPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketPlacement")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	migrate := r.Form.Get("migrate") == "true"
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	placement, err := parseBucketPlacementConfig(bucket, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidBucketPlacement",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+	if err = checkPlacementPools(objectAPI, placement.Tags); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	prevPlacement := globalBucketMetadataSys.GetPlacementConfig(bucket)
+	if err = setBucketPlacement(bucket, placement.Tags); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if migrate {
+		st, err := globalPlacementMigrations.start(bucket)
+		if err != nil {
+			// The running migration moves the objects to the pools of
+			// the placement it was started with.
+			logger.LogIf(ctx, restoreBucketPlacement(bucket, prevPlacement))
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+				Code:       "XMinioAdminBucketPlacementMigrating",
+				Message:    err.Error(),
+				StatusCode: http.StatusConflict,
+			}), r.URL)
+			return
+		}
+		go z.migrateBucketPlacement(GlobalContext, bucket, st)
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// BucketPlacementInfo - the placement of a bucket and the latest
+// migration of its objects started through the node answering.
+type BucketPlacementInfo struct {
+	Tags      []string                  `json:"tags"`
+	Migration *PlacementMigrationStatus `json:"migration,omitempty"`
+}
+
+// GetBucketPlacementHandler - GET /minio/admin/v3/get-bucket-placement?bucket={bucket}
+// ----------
+// Get the pool placement of a bucket and the status of its migration.
+func (a adminAPIHandlers) GetBucketPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketPlacement")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	placement := globalBucketMetadataSys.GetPlacementConfig(bucket)
+	if placement == nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchBucketPlacement",
+			Message:    "bucket is not pinned to pools",
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(BucketPlacementInfo{
+		Tags:      placement.Tags,
+		Migration: globalPlacementMigrations.get(bucket),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketTrashConfigHandler - PUT Bucket trash configuration.
+// ----------
+// Enables or disables soft deletes on the specified bucket, deleted
+// objects of an unversioned bucket are then kept in the bucket trash
+// for the configured retention and can be restored until purged.
+func (a adminAPIHandlers) PutBucketTrashConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketTrashConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	cfg, err := parseBucketTrashConfig(bucket, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if cfg.Enabled {
+		if globalBucketVersioningSys.Enabled(bucket) || globalBucketVersioningSys.Suspended(bucket) {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+				Code:       "XMinioAdminBucketVersioned",
+				Message:    "trash is only supported on unversioned buckets, versioning already keeps deleted objects",
+				StatusCode: http.StatusBadRequest,
+			}), r.URL)
+			return
+		}
+		if err = makeBucketTrash(ctx, objectAPI, bucket); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketTrashConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketTrashConfigHandler - gets bucket trash configuration
+func (a adminAPIHandlers) GetBucketTrashConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketTrashConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config, err := globalBucketMetadataSys.GetTrashConfig(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// ListBucketTrashHandler - GET /minio/admin/v3/list-bucket-trash?bucket={bucket}&prefix={prefix}&marker={marker}&max-keys={max-keys}
+// ----------
+// Lists the trashed objects of a bucket along with when they are purged.
+func (a adminAPIHandlers) ListBucketTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListBucketTrash")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	maxKeys := maxObjectList
+	if v := r.Form.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidMaxKeys), r.URL)
+			return
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+
+	result, err := listTrash(ctx, objectAPI, bucket, r.Form.Get("prefix"), r.Form.Get("marker"), maxKeys)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// RestoreBucketTrashHandler - POST /minio/admin/v3/restore-bucket-trash?bucket={bucket}&object={object}
+// ----------
+// Moves a trashed object back to its bucket, unless an object of the
+// same name was written since it was deleted.
+func (a adminAPIHandlers) RestoreBucketTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RestoreBucketTrash")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objInfo, err := restoreTrashedObject(ctx, objectAPI, bucket, vars["object"])
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(TrashedObject{
+		Name:    objInfo.Name,
+		Size:    objInfo.Size,
+		ETag:    objInfo.ETag,
+		ModTime: objInfo.ModTime,
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// UndeleteHandler - POST /minio/admin/v3/undelete?bucket={bucket}&prefix={prefix}&after={after}&before={before}&dry-run={bool}
+// ----------
+// Removes the latest delete markers under prefix created between after and
+// before (RFC3339, before defaults to now) from a versioned bucket, bringing
+// back the objects removed by an accidental recursive delete in one call.
+func (a adminAPIHandlers) UndeleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Undelete")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if isBucketArchived(bucket) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrBucketArchived), r.URL)
+		return
+	}
+
+	if !globalBucketVersioningSys.Enabled(bucket) && !globalBucketVersioningSys.Suspended(bucket) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminBucketNotVersioned",
+			Message:    "undelete needs a versioned bucket, only delete markers can be removed",
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	opts, err := parseUndeleteOpts(r.Form)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidArgument",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	result, err := undeleteObjects(ctx, objectAPI, bucket, opts)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// ArchiveBucketHandler - PUT /minio/admin/v3/bucket-archive?bucket={bucket}&reason={reason}
+// ----------
+// Archives a bucket, sealing it read-only regardless of policies.
+func (a adminAPIHandlers) ArchiveBucketHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ArchiveBucket")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if isBucketArchived(bucket) {
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+
+	data, err := json.Marshal(BucketArchiveState{
+		ArchivedAt: UTCNow(),
+		ArchivedBy: cred.AccessKey,
+		Reason:     r.Form.Get("reason"),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketArchiveConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// UnarchiveBucketHandler - DELETE /minio/admin/v3/bucket-archive?bucket={bucket}&reason={reason}
+// ----------
+// Makes an archived bucket writable again. The reason is mandatory and
+// recorded with the archive state in the audit log.
+func (a adminAPIHandlers) UnarchiveBucketHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "UnarchiveBucket")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	reason := r.Form.Get("reason")
+	if reason == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidArgument",
+			Message:    "a reason is required to unarchive a bucket",
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	state, err := globalBucketMetadataSys.GetArchiveState(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if state == nil {
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+
+	logger.GetReqInfo(ctx).
+		SetTags("archivedAt", state.ArchivedAt).
+		SetTags("archivedBy", state.ArchivedBy).
+		SetTags("unarchiveReason", reason)
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketArchiveConfigFile, nil); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketArchiveHandler - GET /minio/admin/v3/bucket-archive?bucket={bucket}
+// ----------
+// Returns the archive state of a bucket, empty if it is not archived.
+func (a adminAPIHandlers) GetBucketArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketArchive")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	state, err := globalBucketMetadataSys.GetArchiveState(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if state == nil {
+		state = &BucketArchiveState{}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// BucketFailoverHandler - POST /minio/admin/v3/bucket-failover?bucket={bucket}
+// ----------
+// Promotes the replica of a bucket to primary: freezes writes to the
+// bucket, waits for its replication to drain, promotes the replica
+// replicating back to this deployment and disables the replication to
+// it. Returns the objects not replicated, the body is encrypted as it
+// carries credentials. Once the promotion is attempted the bucket stays
+// frozen until unarchived.
+func (a adminAPIHandlers) BucketFailoverHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "BucketFailover")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.SetBucketTargetAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	var req BucketFailoverRequest
+	if errCode := readJSONBody(ctx, r.Body, &req, cred.SecretKey); errCode != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(errCode), r.URL)
+		return
+	}
+	if _, err := req.drainTimeout(); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, err), r.URL)
+		return
+	}
+
+	report, err := failoverBucket(ctx, objectAPI, bucket, cred.AccessKey, req)
+	switch err.(type) {
+	case nil:
+	case BucketReplicationConfigNotFound:
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	default:
+		if err == errFailoverTargetNotFound || err == errFailoverBucketFrozen {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, err), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// BucketPromoteHandler - POST /minio/admin/v3/bucket-failover/promote
+// ----------
+// Called by the primary of a failover on its replica, makes the bucket
+// of the replica replicate back to the former primary. The body is
+// encrypted as it carries credentials.
+func (a adminAPIHandlers) BucketPromoteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "BucketPromote")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.SetBucketTargetAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var req BucketPromoteRequest
+	if errCode := readJSONBody(ctx, r.Body, &req, cred.SecretKey); errCode != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(errCode), r.URL)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(ctx, req.Bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	arn, err := promoteBucket(ctx, req)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(BucketPromoteResponse{Arn: arn})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// ColdDataHandler - GET /minio/admin/v3/cold-data?bucket={bucket}
+// ----------
+// Reports the sizes of the objects of bucket and of its top level prefixes
+// which were not read for 30, 90 and 365 days, as of the last scanner
+// cycle. Reads are only tracked when api access_tracking is enabled.
+func (a adminAPIHandlers) ColdDataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ColdData")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	report, err := loadColdUsageFromBackend(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// PutBucketDedupConfigHandler - PUT /minio/admin/v3/set-bucket-dedup?bucket={bucket}
+// ----------
+// Configures the duplicate content report of a bucket and whether new
+// objects are stored deduplicated.
+func (a adminAPIHandlers) PutBucketDedupConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketDedupConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketDedupConfig(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketDedupConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketDedupConfigHandler - gets bucket dedup configuration
+func (a adminAPIHandlers) GetBucketDedupConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketDedupConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config, err := globalBucketMetadataSys.GetDedupConfig(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if config == nil {
+		config = &BucketDedupConfig{}
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketMetadataIndexConfigHandler - PUT /minio/admin/v3/set-bucket-metadata-index?bucket={bucket}
+// ----------
+// Enables or disables the metadata index of a bucket searched by the
+// metadata search API, a new index is built in the background.
+func (a adminAPIHandlers) PutBucketMetadataIndexConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketMetadataIndexConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	config, err := parseBucketMetadataIndexConfig(bucket, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	// Store the normalized user metadata keys.
+	if data, err = json.Marshal(config); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketMetadataIndexConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if config.Enabled {
+		globalMetadataIndexBuilder.queue(metadataIndexJob{bucket: bucket, force: true})
+	} else if err = removeMetadataIndex(ctx, objectAPI, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketMetadataIndexConfigHandler - GET /minio/admin/v3/get-bucket-metadata-index?bucket={bucket}
+// ----------
+// Returns the metadata index configuration of a bucket, with the time
+// its index was last built and the number of objects indexed.
+func (a adminAPIHandlers) GetBucketMetadataIndexConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketMetadataIndexConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config, err := globalBucketMetadataSys.GetMetadataIndexConfig(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	var status bucketMetadataIndexStatus
+	if config != nil {
+		status.BucketMetadataIndexConfig = *config
+	}
+	m, err := loadMetadataIndexManifest(ctx, objectAPI, bucket)
+	if err != nil && err != errConfigNotFound {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	status.Built, status.Building, status.Objects = m.Built, m.Building, m.Objects
+
+	configData, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketChangelogConfigHandler - PUT /minio/admin/v3/set-bucket-changelog?bucket={bucket}
+// ----------
+// Enables or disables the changelog of a bucket, disabling it removes
+// the log of the bucket.
+func (a adminAPIHandlers) PutBucketChangelogConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketChangelogConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	config, err := parseBucketChangelogConfig(bucket, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketChangelogConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if !config.Enabled {
+		if err = removeChangelog(ctx, objectAPI, bucket); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketChangelogConfigHandler - GET /minio/admin/v3/get-bucket-changelog?bucket={bucket}
+// ----------
+// Returns the changelog configuration of a bucket, with the offsets of
+// its log and the time it was last compacted.
+func (a adminAPIHandlers) GetBucketChangelogConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketChangelogConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	config, err := globalBucketMetadataSys.GetChangelogConfig(bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	var status bucketChangelogStatus
+	if config != nil {
+		status.BucketChangelogConfig = *config
+	}
+	h, err := loadChangelogHead(ctx, objectAPI, bucket)
+	if err != nil && err != errConfigNotFound {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	status.ID, status.FirstOffset, status.NextOffset = h.ID, h.firstOffset(), h.Next
+	status.Segments, status.Compacted = len(h.Segments), h.Compacted
+
+	configData, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketClassConfigHandler - PUT /minio/admin/v3/set-bucket-class?bucket={bucket}
+// ----------
+// Sets the class of a bucket, the new objects of a low-latency bucket are
+// placed on the pools tagged nvme with reduced parity and without sync.
+func (a adminAPIHandlers) PutBucketClassConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketClassConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	config, err := parseBucketClassConfig(bucket, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidBucketClass",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+	if config.Class == bucketClassLowLatency {
+		tags := []string{lowLatencyPoolTag}
+		if placement := globalBucketMetadataSys.GetPlacementConfig(bucket); placement != nil {
+			tags = append(tags, placement.Tags...)
+		}
+		if err = checkPlacementPools(objectAPI, tags); err != nil {
+			writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketClassConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketClassConfigHandler - GET /minio/admin/v3/get-bucket-class?bucket={bucket}
+// ----------
+// Returns the class of a bucket with the pool tags, parity and sync of
+// its new objects.
+func (a adminAPIHandlers) GetBucketClassConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketClassConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(getBucketClassInfo(bucket))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// PutBucketFsyncConfigHandler - PUT /minio/admin/v3/set-bucket-fsync?bucket={bucket}
+// ----------
+// Sets the fsync policy of the objects committed to a bucket, always,
+// batched or never.
+func (a adminAPIHandlers) PutBucketFsyncConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PutBucketFsyncConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if _, err = parseBucketFsyncConfig(bucket, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidBucketFsyncPolicy",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	if err = globalBucketMetadataSys.Update(bucket, bucketFsyncConfigFile, data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketFsyncConfigHandler - GET /minio/admin/v3/get-bucket-fsync?bucket={bucket}
+// ----------
+// Returns the fsync policy of the objects committed to a bucket, never for
+// low latency buckets whatever the policy set.
+func (a adminAPIHandlers) GetBucketFsyncConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetBucketFsyncConfig")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(BucketFsyncConfig{Policy: string(bucketFsyncPolicy(bucket))})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Write success response.
+	writeSuccessResponseJSON(w, configData)
+}
+
+// DedupReportHandler - GET /minio/admin/v3/dedup-report?bucket={bucket}
+// ----------
+// Reports the duplicate content of a bucket with a dedup configuration and
+// the space saved by its deduplicated objects, as of the last dedup scan.
+func (a adminAPIHandlers) DedupReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "DedupReport")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	report, err := loadDedupReport(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// toBucketSnapshotErr maps bucket snapshot errors to admin API errors.
+func toBucketSnapshotErr(ctx context.Context, err error) APIError {
+	switch err {
+	case errBucketSnapshotNotFound:
+		err = AdminError{
+			Code:       "XMinioAdminNoSuchBucketSnapshot",
+			Message:    err.Error(),
+			StatusCode: http.StatusNotFound,
+		}
+	case errBucketSnapshotMounted:
+		err = AdminError{
+			Code:       "XMinioAdminBucketSnapshotMounted",
+			Message:    "bucket snapshot is mounted, delete the mounting bucket first",
+			StatusCode: http.StatusConflict,
+		}
+	}
+	return toAdminAPIErr(ctx, err)
+}
+
+// CreateBucketSnapshotHandler - POST /minio/admin/v3/bucket-snapshot?bucket=mybucket
+// ----------
+// Takes a point-in-time snapshot of the current versions of a bucket.
+func (a adminAPIHandlers) CreateBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CreateBucketSnapshot")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	// Snapshots pin object versions, which only stay around as long
+	// as versioning keeps overwritten versions.
+	if !globalBucketVersioningSys.Enabled(bucket) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminBucketNotVersioned",
+			Message:    "bucket snapshots need versioning to be enabled on the bucket",
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	info, err := createBucketSnapshot(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// ListBucketSnapshotsHandler - GET /minio/admin/v3/bucket-snapshot?bucket=mybucket
+// ----------
+// Lists the snapshots of a bucket.
+func (a adminAPIHandlers) ListBucketSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListBucketSnapshots")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	snapshots, err := listBucketSnapshots(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// DeleteBucketSnapshotHandler - DELETE /minio/admin/v3/bucket-snapshot?bucket=mybucket&id=snapshotID
+// ----------
+// Deletes a snapshot of a bucket, which must not be mounted.
+func (a adminAPIHandlers) DeleteBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "DeleteBucketSnapshot")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if err := deleteBucketSnapshot(ctx, objectAPI, bucket, vars["id"]); err != nil {
+		writeErrorResponseJSON(ctx, w, toBucketSnapshotErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessNoContent(w)
+}
+
+// MountBucketSnapshotHandler - POST /minio/admin/v3/bucket-snapshot/mount?bucket=mybucket&id=snapshotID&target=newbucket
+// ----------
+// Creates the bucket target serving the snapshot read-only, deleting
+// the target bucket unmounts the snapshot again.
+func (a adminAPIHandlers) MountBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "MountBucketSnapshot")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	target := pathClean(vars["target"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if err := mountBucketSnapshot(ctx, objectAPI, bucket, vars["id"], target); err != nil {
+		writeErrorResponseJSON(ctx, w, toBucketSnapshotErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
 // SetRemoteTargetHandler - sets a remote target for bucket
 func (a adminAPIHandlers) SetRemoteTargetHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "SetBucketTarget")
@@ -18,14 +18,22 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/minio/kes"
 	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7/pkg/signer"
 	"github.com/minio/minio/internal/auth"
 	"github.com/minio/minio/internal/config"
+	xhttp "github.com/minio/minio/internal/http"
 	iampolicy "github.com/minio/pkg/iam/policy"
 )
 
@@ -170,3 +178,39 @@ func toAdminAPIErrCode(ctx context.Context, err error) APIErrorCode {
 		return toAPIErrorCode(ctx, err)
 	}
 }
+
+// callRemoteAdminAPI calls the admin API path of the remote deployment at
+// endpoint with a request signed by the given credentials, decoding the
+// JSON response into v if not nil.
+func callRemoteAdminAPI(ctx context.Context, endpoint, accessKey, secretKey, region, method, path string, body []byte, v interface{}) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	u.Path = adminPathPrefix + adminAPIVersionPrefix + path
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	req = signer.SignV4(*req, accessKey, secretKey, "", region)
+
+	resp, err := (&http.Client{Transport: newRemoteClusterHTTPTransport()}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer xhttp.DrainBody(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr APIErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio/internal/logger"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+// Maximum size of a bucket template definition.
+const maxBucketTemplateSize = 1 << 20
+
+// toBucketTemplateErr maps bucket template errors to admin API errors.
+func toBucketTemplateErr(ctx context.Context, err error) APIError {
+	switch err {
+	case errNoSuchBucketTemplate:
+		err = AdminError{
+			Code:       "XMinioAdminNoSuchBucketTemplate",
+			Message:    err.Error(),
+			StatusCode: http.StatusNotFound,
+		}
+	case errInvalidBucketTemplateName, errBucketTemplateNeedsVersioning,
+		errBucketTemplateNeedsObjectLock, errInvalidBucketTemplateTarget:
+		err = AdminError{
+			Code:       "XMinioAdminInvalidArgument",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return toAdminAPIErr(ctx, err)
+}
+
+// SetBucketTemplateHandler - PUT /minio/admin/v3/set-bucket-template?name={name}
+// ----------
+// Creates or replaces a bucket template, the body is the JSON template
+// encrypted with the secret key of the request, as it may hold the
+// credentials of replication targets.
+func (a adminAPIHandlers) SetBucketTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SetBucketTemplate")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := madmin.DecryptData(cred.SecretKey, io.LimitReader(r.Body, maxBucketTemplateSize))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminConfigBadJSON, err), r.URL)
+		return
+	}
+
+	var t BucketTemplate
+	if err = json.Unmarshal(data, &t); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrMalformedJSON), r.URL)
+		return
+	}
+	t.Name = mux.Vars(r)["name"]
+
+	if err = globalBucketTemplateSys.SetTemplate(ctx, objectAPI, t); err != nil {
+		writeErrorResponseJSON(ctx, w, toBucketTemplateErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// RemoveBucketTemplateHandler - DELETE /minio/admin/v3/remove-bucket-template?name={name}
+// ----------
+// Removes a bucket template, the buckets created with it keep their
+// configs.
+func (a adminAPIHandlers) RemoveBucketTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RemoveBucketTemplate")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if err := globalBucketTemplateSys.RemoveTemplate(ctx, objectAPI, mux.Vars(r)["name"]); err != nil {
+		writeErrorResponseJSON(ctx, w, toBucketTemplateErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ListBucketTemplatesHandler - GET /minio/admin/v3/list-bucket-templates
+// ----------
+// Lists all bucket templates, encrypted with the secret key of the
+// request.
+func (a adminAPIHandlers) ListBucketTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListBucketTemplates")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(globalBucketTemplateSys.ListTemplates())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	encryptedData, err := madmin.EncryptData(cred.SecretKey, data)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, encryptedData)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/minio/minio/internal/logger"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+// Maximum size of the job schedules config.
+const maxJobSchedulesConfigSize = 1 << 20
+
+// SetJobSchedulesHandler - PUT /minio/admin/v3/set-job-schedules
+// ----------
+// Replaces the schedules of the background jobs of all nodes, the body
+// is the JSON job schedules config.
+func (a adminAPIHandlers) SetJobSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SetJobSchedules")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxJobSchedulesConfigSize))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var cfg JobSchedulesConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrMalformedJSON), r.URL)
+		return
+	}
+
+	if _, err = parseJobSchedules(cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidArgument",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	if err = globalJobScheduler.save(ctx, objectAPI, cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetJobSchedulesHandler - GET /minio/admin/v3/get-job-schedules
+// ----------
+// Returns the schedules of the background jobs.
+func (a adminAPIHandlers) GetJobSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetJobSchedules")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(globalJobScheduler.config())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// JobStatusHandler - GET /minio/admin/v3/job-status
+// ----------
+// Reports which background jobs run, wait or are outside of their
+// windows on each node.
+func (a adminAPIHandlers) JobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "JobStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(getClusterJobStatus(ctx))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
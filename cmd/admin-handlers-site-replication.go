@@ -51,7 +51,10 @@ func (a adminAPIHandlers) SiteReplicationAdd(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	status, errInfo := globalSiteReplicationSys.AddPeerClusters(ctx, sites)
+	// The buckets replicated from the start, as comma separated
+	// include and exclude patterns.
+	filter := srBucketFilterFromOpts(r.Form.Get("include"), r.Form.Get("exclude"))
+	status, errInfo := globalSiteReplicationSys.AddPeerClusters(ctx, sites, filter)
 	if errInfo.Code != ErrNone {
 		logger.LogIf(ctx, errInfo)
 		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(errInfo.Code, errInfo.Cause), r.URL)
@@ -128,6 +131,8 @@ func (a adminAPIHandlers) SRInternalBucketOps(w http.ResponseWriter, r *http.Req
 		err = globalSiteReplicationSys.PeerBucketDeleteHandler(ctx, bucket, false)
 	case madmin.ForceDeleteBucketBktOp:
 		err = globalSiteReplicationSys.PeerBucketDeleteHandler(ctx, bucket, true)
+	case srBucketFilterBktOp:
+		err = globalSiteReplicationSys.PeerBucketFilterHandler(ctx, srBucketFilterFromOpts(r.Form.Get("include"), r.Form.Get("exclude")))
 	default:
 		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminInvalidArgument), r.URL)
 		return
@@ -273,6 +278,122 @@ func (a adminAPIHandlers) SiteReplicationInfo(w http.ResponseWriter, r *http.Req
 	w.(http.Flusher).Flush()
 }
 
+// SiteReplicationSetBucketFilter - PUT /minio/admin/v3/site-replication/bucket-filter
+// ----------
+// sets the include and exclude patterns of the buckets replicated on
+// all sites, body {"include": ["*"], "exclude": ["tmp-*"]}.
+func (a adminAPIHandlers) SiteReplicationSetBucketFilter(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SiteReplicationSetBucketFilter")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.SiteReplicationAddAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var filter SRBucketFilter
+	errCode := readJSONBody(ctx, r.Body, &filter, "")
+	if errCode != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(errCode), r.URL)
+		return
+	}
+	if err := filter.validate(); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, err), r.URL)
+		return
+	}
+
+	if err := globalSiteReplicationSys.SetBucketFilter(ctx, filter); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+}
+
+// SiteReplicationGetBucketFilter - GET /minio/admin/v3/site-replication/bucket-filter
+// ----------
+// returns the include and exclude patterns of the buckets replicated.
+func (a adminAPIHandlers) SiteReplicationGetBucketFilter(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SiteReplicationGetBucketFilter")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.SiteReplicationInfoAction)
+	if objectAPI == nil {
+		return
+	}
+
+	filter, err := globalSiteReplicationSys.GetBucketFilter()
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	body, err := json.Marshal(filter)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, body)
+}
+
+// SiteReplicationDiff - GET /minio/admin/v3/site-replication/diff
+// ----------
+// compares the IAM entities, the metadata and the usage of the replicated
+// buckets of all sites, returning the mismatches with remediation hints.
+func (a adminAPIHandlers) SiteReplicationDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SiteReplicationDiff")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.SiteReplicationInfoAction)
+	if objectAPI == nil {
+		return
+	}
+
+	report, err := globalSiteReplicationSys.Diff(ctx)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, body)
+}
+
+// SRInternalGetSnapshot - GET /minio/admin/v3/site-replication/peer/snapshot
+// ----------
+// returns the snapshot of this site compared by the diff of a peer.
+func (a adminAPIHandlers) SRInternalGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SiteReplicationGetSnapshot")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.SiteReplicationInfoAction)
+	if objectAPI == nil {
+		return
+	}
+
+	snapshot, err := globalSiteReplicationSys.PeerSnapshotHandler(ctx)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, body)
+}
+
 func (a adminAPIHandlers) SRInternalGetIDPSettings(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "SiteReplicationGetIDPSettings")
 
@@ -0,0 +1,168 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/logger"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+// Maximum size of a tenant definition.
+const maxTenantConfigSize = 1 << 20
+
+// toTenantErr maps tenant errors to admin API errors.
+func toTenantErr(ctx context.Context, err error) APIError {
+	switch err {
+	case errNoSuchTenant:
+		err = AdminError{
+			Code:       "XMinioAdminNoSuchTenant",
+			Message:    err.Error(),
+			StatusCode: http.StatusNotFound,
+		}
+	case errInvalidTenantName, errTenantNoBuckets, errInvalidTenantRate, errUnknownDomain:
+		err = AdminError{
+			Code:       "XMinioAdminInvalidArgument",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return toAdminAPIErr(ctx, err)
+}
+
+// SetTenantHandler - PUT /minio/admin/v3/set-tenant?name={name}
+// ----------
+// Creates or replaces a tenant, the body is the JSON tenant definition.
+func (a adminAPIHandlers) SetTenantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SetTenant")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxTenantConfigSize))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var t Tenant
+	if err = json.Unmarshal(data, &t); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrMalformedJSON), r.URL)
+		return
+	}
+	t.Name = mux.Vars(r)["name"]
+
+	if err = globalTenantSys.SetTenant(ctx, objectAPI, t); err != nil {
+		writeErrorResponseJSON(ctx, w, toTenantErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// RemoveTenantHandler - DELETE /minio/admin/v3/remove-tenant?name={name}
+// ----------
+// Removes a tenant, its buckets and users are not removed.
+func (a adminAPIHandlers) RemoveTenantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RemoveTenant")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if err := globalTenantSys.RemoveTenant(ctx, objectAPI, mux.Vars(r)["name"]); err != nil {
+		writeErrorResponseJSON(ctx, w, toTenantErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ListTenantsHandler - GET /minio/admin/v3/list-tenants
+// ----------
+// Lists all tenants.
+func (a adminAPIHandlers) ListTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListTenants")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(globalTenantSys.ListTenants())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// TenantUsageHandler - GET /minio/admin/v3/tenant-usage?name={name}
+// ----------
+// Reports the aggregate usage of the buckets of a tenant, as of the last
+// scanner cycle.
+func (a adminAPIHandlers) TenantUsageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TenantUsage")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	t, err := globalTenantSys.GetTenant(mux.Vars(r)["name"])
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toTenantErr(ctx, err), r.URL)
+		return
+	}
+
+	dui, err := loadDataUsageFromBackend(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(globalTenantSys.usage(t, dui))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
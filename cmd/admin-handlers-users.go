@@ -24,7 +24,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/minio/madmin-go"
@@ -32,6 +34,7 @@ import (
 	"github.com/minio/minio/internal/config/dns"
 	"github.com/minio/minio/internal/logger"
 	iampolicy "github.com/minio/pkg/iam/policy"
+	"github.com/minio/pkg/wildcard"
 )
 
 // RemoveUser - DELETE /minio/admin/v3/remove-user?accessKey=<access_key>
@@ -1092,6 +1095,14 @@ func (a adminAPIHandlers) AccountInfoHandler(w http.ResponseWriter, r *http.Requ
 	// Check if we are asked to return prefix usage
 	enablePrefixUsage := r.Form.Get("prefix-usage") == "true"
 
+	// Check if we are asked for a page of the buckets matching a name
+	// filter, all buckets ordered by name are returned by default.
+	page, err := parseAccountInfoPageOptions(r.Form)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+		return
+	}
+
 	isAllowedAccess := func(bucketName string) (rd, wr bool) {
 		if globalIAMSys.IsAllowed(iampolicy.Args{
 			AccountName:     cred.AccessKey,
@@ -1123,7 +1134,6 @@ func (a adminAPIHandlers) AccountInfoHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	var dataUsageInfo DataUsageInfo
-	var err error
 	if !globalIsGateway {
 		// Load the latest calculated data usage
 		dataUsageInfo, err = loadDataUsageFromBackend(ctx, objectAPI)
@@ -1186,60 +1196,72 @@ func (a adminAPIHandlers) AccountInfoHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	acctInfo := madmin.AccountInfo{
-		AccountName: accountName,
-		Server:      objectAPI.BackendInfo(),
-		Policy:      buf,
+	acctInfo := accountInfoPage{
+		AccountInfo: madmin.AccountInfo{
+			AccountName: accountName,
+			Server:      objectAPI.BackendInfo(),
+			Policy:      buf,
+		},
 	}
 
+	var accessible []accountBucket
 	for _, bucket := range buckets {
-		rd, wr := isAllowedAccess(bucket.Name)
-		if rd || wr {
-			// Fetch the data usage of the current bucket
-			var size uint64
-			var objectsCount uint64
-			var objectsHist map[string]uint64
-			if !dataUsageInfo.LastUpdate.IsZero() {
-				size = dataUsageInfo.BucketsUsage[bucket.Name].Size
-				objectsCount = dataUsageInfo.BucketsUsage[bucket.Name].ObjectsCount
-				objectsHist = dataUsageInfo.BucketsUsage[bucket.Name].ObjectSizesHistogram
-			}
-			// Fetch the prefix usage of the current bucket
-			var prefixUsage map[string]uint64
-			if enablePrefixUsage {
-				if pu, err := loadPrefixUsageFromBackend(ctx, objectAPI, bucket.Name); err == nil {
-					prefixUsage = pu
-				} else {
-					logger.LogIf(ctx, err)
-				}
+		if page.filter != "" && !wildcard.Match(page.filter, bucket.Name) {
+			continue
+		}
+		if rd, wr := isAllowedAccess(bucket.Name); rd || wr {
+			accessible = append(accessible, accountBucket{BucketInfo: bucket, rd: rd, wr: wr})
+		}
+	}
+	acctInfo.TotalBuckets = len(accessible)
+	accessible, acctInfo.NextOffset = page.apply(accessible, dataUsageInfo)
+	acctInfo.IsTruncated = acctInfo.NextOffset > 0
+
+	for _, bucket := range accessible {
+		// Fetch the data usage of the current bucket
+		var size uint64
+		var objectsCount uint64
+		var objectsHist map[string]uint64
+		if !dataUsageInfo.LastUpdate.IsZero() {
+			size = dataUsageInfo.BucketsUsage[bucket.Name].Size
+			objectsCount = dataUsageInfo.BucketsUsage[bucket.Name].ObjectsCount
+			objectsHist = dataUsageInfo.BucketsUsage[bucket.Name].ObjectSizesHistogram
+		}
+		// Fetch the prefix usage of the current bucket
+		var prefixUsage map[string]uint64
+		if enablePrefixUsage {
+			if pu, err := loadPrefixUsageFromBackend(ctx, objectAPI, bucket.Name); err == nil {
+				prefixUsage = pu
+			} else {
+				logger.LogIf(ctx, err)
 			}
-
-			lcfg, _ := globalBucketObjectLockSys.Get(bucket.Name)
-			quota, _ := globalBucketQuotaSys.Get(bucket.Name)
-			rcfg, _ := globalBucketMetadataSys.GetReplicationConfig(ctx, bucket.Name)
-			tcfg, _ := globalBucketMetadataSys.GetTaggingConfig(bucket.Name)
-
-			acctInfo.Buckets = append(acctInfo.Buckets, madmin.BucketAccessInfo{
-				Name:                 bucket.Name,
-				Created:              bucket.Created,
-				Size:                 size,
-				Objects:              objectsCount,
-				ObjectSizesHistogram: objectsHist,
-				PrefixUsage:          prefixUsage,
-				Details: &madmin.BucketDetails{
-					Versioning:          globalBucketVersioningSys.Enabled(bucket.Name),
-					VersioningSuspended: globalBucketVersioningSys.Suspended(bucket.Name),
-					Replication:         rcfg != nil,
-					Locking:             lcfg.LockEnabled,
-					Quota:               quota,
-					Tagging:             tcfg,
-				},
-				Access: madmin.AccountAccess{
-					Read:  rd,
-					Write: wr,
-				},
-			})
 		}
+
+		lcfg, _ := globalBucketObjectLockSys.Get(bucket.Name)
+		quota, _ := globalBucketQuotaSys.Get(bucket.Name)
+		rcfg, _ := globalBucketMetadataSys.GetReplicationConfig(ctx, bucket.Name)
+		tcfg, _ := globalBucketMetadataSys.GetTaggingConfig(bucket.Name)
+
+		acctInfo.AccountInfo.Buckets = append(acctInfo.AccountInfo.Buckets, madmin.BucketAccessInfo{
+			Name:                 bucket.Name,
+			Created:              bucket.Created,
+			Size:                 size,
+			Objects:              objectsCount,
+			ObjectSizesHistogram: objectsHist,
+			PrefixUsage:          prefixUsage,
+			Details: &madmin.BucketDetails{
+				Versioning:          globalBucketVersioningSys.Enabled(bucket.Name),
+				VersioningSuspended: globalBucketVersioningSys.Suspended(bucket.Name),
+				Replication:         rcfg != nil,
+				Locking:             lcfg.LockEnabled,
+				Quota:               quota,
+				Tagging:             tcfg,
+			},
+			Access: madmin.AccountAccess{
+				Read:  bucket.rd,
+				Write: bucket.wr,
+			},
+		})
 	}
 
 	usageInfoJSON, err := json.Marshal(acctInfo)
@@ -1251,6 +1273,78 @@ func (a adminAPIHandlers) AccountInfoHandler(w http.ResponseWriter, r *http.Requ
 	writeSuccessResponseJSON(w, usageInfoJSON)
 }
 
+// accountInfoPage - the account info of a page of the buckets the
+// account has access to.
+type accountInfoPage struct {
+	madmin.AccountInfo
+	TotalBuckets int  `json:"totalBuckets"`
+	IsTruncated  bool `json:"isTruncated,omitempty"`
+	NextOffset   int  `json:"nextOffset,omitempty"`
+}
+
+// accountBucket - a bucket the account has read or write access to.
+type accountBucket struct {
+	BucketInfo
+	rd, wr bool
+}
+
+// accountInfoPageOptions - the page of the buckets requested from
+// AccountInfo, buckets with names matching the filter pattern ordered
+// by name or by decreasing size.
+type accountInfoPageOptions struct {
+	filter     string
+	sortBySize bool
+	offset     int
+	maxBuckets int
+}
+
+// parseAccountInfoPageOptions parses the filter, sort, offset and
+// max-buckets query parameters of AccountInfo.
+func parseAccountInfoPageOptions(form url.Values) (opts accountInfoPageOptions, err error) {
+	opts.filter = form.Get("filter")
+	switch form.Get("sort") {
+	case "", "name":
+	case "size":
+		opts.sortBySize = true
+	default:
+		return opts, errInvalidArgument
+	}
+	if v := form.Get("offset"); v != "" {
+		if opts.offset, err = strconv.Atoi(v); err != nil || opts.offset < 0 {
+			return opts, errInvalidArgument
+		}
+	}
+	if v := form.Get("max-buckets"); v != "" {
+		if opts.maxBuckets, err = strconv.Atoi(v); err != nil || opts.maxBuckets <= 0 {
+			return opts, errInvalidArgument
+		}
+	}
+	return opts, nil
+}
+
+// apply orders buckets and returns those of the page, along with the
+// offset of the next page if more buckets follow.
+func (opts accountInfoPageOptions) apply(buckets []accountBucket, dui DataUsageInfo) ([]accountBucket, int) {
+	sort.Slice(buckets, func(i, j int) bool {
+		if opts.sortBySize {
+			si, sj := dui.BucketsUsage[buckets[i].Name].Size, dui.BucketsUsage[buckets[j].Name].Size
+			if si != sj {
+				return si > sj
+			}
+		}
+		return buckets[i].Name < buckets[j].Name
+	})
+
+	if opts.offset >= len(buckets) {
+		return nil, 0
+	}
+	buckets = buckets[opts.offset:]
+	if opts.maxBuckets == 0 || len(buckets) <= opts.maxBuckets {
+		return buckets, 0
+	}
+	return buckets[:opts.maxBuckets], opts.offset + opts.maxBuckets
+}
+
 // InfoCannedPolicy - GET /minio/admin/v3/info-canned-policy?name={policyName}
 func (a adminAPIHandlers) InfoCannedPolicy(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "InfoCannedPolicy")
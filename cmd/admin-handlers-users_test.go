@@ -20,6 +20,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -638,3 +639,51 @@ func mustGenerateCredentials(c *check) (string, string) {
 	}
 	return ak, sk
 }
+
+func TestAccountInfoPageOptions(t *testing.T) {
+	for i, query := range []string{"sort=date", "offset=-1", "max-buckets=0", "max-buckets=x"} {
+		form, _ := url.ParseQuery(query)
+		if _, err := parseAccountInfoPageOptions(form); err == nil {
+			t.Errorf("case %d: expected %q to be rejected", i+1, query)
+		}
+	}
+
+	dui := DataUsageInfo{
+		BucketsUsage: map[string]BucketUsageInfo{
+			"alpha": {Size: 10},
+			"beta":  {Size: 30},
+			"gamma": {Size: 20},
+		},
+	}
+	testCases := []struct {
+		query    string
+		expected []string
+		next     int
+	}{
+		{"", []string{"alpha", "beta", "delta", "gamma"}, 0},
+		{"sort=size", []string{"beta", "gamma", "alpha", "delta"}, 0},
+		{"sort=size&max-buckets=2", []string{"beta", "gamma"}, 2},
+		{"sort=size&max-buckets=2&offset=2", []string{"alpha", "delta"}, 0},
+		{"max-buckets=3&offset=1", []string{"beta", "delta", "gamma"}, 0},
+		{"offset=4", nil, 0},
+	}
+	for i, tc := range testCases {
+		form, _ := url.ParseQuery(tc.query)
+		opts, err := parseAccountInfoPageOptions(form)
+		if err != nil {
+			t.Fatalf("case %d: %v", i+1, err)
+		}
+		var buckets []accountBucket
+		for _, name := range []string{"gamma", "alpha", "delta", "beta"} {
+			buckets = append(buckets, accountBucket{BucketInfo: BucketInfo{Name: name}})
+		}
+		page, next := opts.apply(buckets, dui)
+		var names []string
+		for _, b := range page {
+			names = append(names, b.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tc.expected, ",") || next != tc.next {
+			t.Errorf("case %d: expected %v next %d, got %v next %d", i+1, tc.expected, tc.next, names, next)
+		}
+	}
+}
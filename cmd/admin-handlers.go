@@ -23,9 +23,11 @@ import (
 	"crypto/subtle"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
@@ -33,6 +35,7 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -66,8 +69,8 @@ const (
 	mgmtForceStop   = "forceStop"
 )
 
-func updateServer(u *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string, mode string) (us madmin.ServerUpdateStatus, err error) {
-	if err = doUpdate(u, lrTime, sha256Sum, releaseInfo, mode); err != nil {
+func updateServer(u *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string, mode string, keepOld bool) (us madmin.ServerUpdateStatus, err error) {
+	if err = doUpdate(u, lrTime, sha256Sum, releaseInfo, mode, keepOld); err != nil {
 		return us, err
 	}
 
@@ -76,9 +79,12 @@ func updateServer(u *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo st
 	return us, nil
 }
 
-// ServerUpdateHandler - POST /minio/admin/v3/update?updateURL={updateURL}
+// ServerUpdateHandler - POST /minio/admin/v3/update?updateURL={updateURL}[&rolling=true]
 // ----------
-// updates all minio servers and restarts them gracefully.
+// updates all minio servers and restarts them gracefully. With rolling
+// set, distributed setups are updated one batch of nodes at a time
+// which can be restarted without losing write quorum, see
+// ServerUpdateStatusHandler for the progress.
 func (a adminAPIHandlers) ServerUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "ServerUpdate")
 
@@ -147,6 +153,30 @@ func (a adminAPIHandlers) ServerUpdateHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if r.Form.Get("rolling") == "true" && globalIsDistErasure {
+		status, err := globalRollingUpdate.start(objectAPI, u, sha256Sum, lrTime, releaseInfo)
+		if err != nil {
+			if err == errRollingUpdateRunning {
+				err = AdminError{
+					Code:       AdminUpdateApplyFailure,
+					Message:    err.Error(),
+					StatusCode: http.StatusConflict,
+				}
+			}
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+
+		jsonBytes, err := json.Marshal(status)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+
+		writeSuccessResponseJSON(w, jsonBytes)
+		return
+	}
+
 	for _, nerr := range globalNotificationSys.ServerUpdate(ctx, u, sha256Sum, lrTime, releaseInfo) {
 		if nerr.Err != nil {
 			err := AdminError{
@@ -161,7 +191,7 @@ func (a adminAPIHandlers) ServerUpdateHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	updateStatus, err := updateServer(u, sha256Sum, lrTime, releaseInfo, mode)
+	updateStatus, err := updateServer(u, sha256Sum, lrTime, releaseInfo, mode, false)
 	if err != nil {
 		logger.LogIf(ctx, fmt.Errorf("server update failed with %w", err))
 		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
@@ -188,6 +218,65 @@ func (a adminAPIHandlers) ServerUpdateHandler(w http.ResponseWriter, r *http.Req
 	globalServiceSignalCh <- serviceRestart
 }
 
+// ServerUpdateStatusHandler - GET /minio/admin/v3/update/status
+// ----------
+// Returns the progress of the last rolling update coordinated by this
+// node. Once this node restarted itself at the end of the update the
+// status is gone, the version of each node is then in the server info.
+func (a adminAPIHandlers) ServerUpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ServerUpdateStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	status, ok := globalRollingUpdate.getStatus()
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchUpdate",
+			Message:    "no rolling update was started on this node",
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// ServerUpdateAbortHandler - POST /minio/admin/v3/update/abort
+// ----------
+// Stops the rolling update before its next batch of nodes.
+func (a adminAPIHandlers) ServerUpdateAbortHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ServerUpdateAbort")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if !globalRollingUpdate.abort() {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchUpdate",
+			Message:    "no rolling update is running on this node",
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	writeSuccessNoContent(w)
+}
+
 // ServiceHandler - POST /minio/admin/v3/service?action={action}
 // ----------
 // restarts/stops minio server gracefully. In a distributed setup,
@@ -236,6 +325,75 @@ func (a adminAPIHandlers) ServiceHandler(w http.ResponseWriter, r *http.Request)
 	globalServiceSignalCh <- serviceSig
 }
 
+func writeDrainStatusResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, status DrainStatus, err error) {
+	if err != nil {
+		if err == errNodeNotFound {
+			err = AdminError{
+				Code:       "XMinioAdminNoSuchNode",
+				Message:    err.Error(),
+				StatusCode: http.StatusNotFound,
+			}
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// DrainNodeHandler - POST /minio/admin/v3/service/drain?node={node}&timeout={timeout}
+// ----------
+// Makes the node stop accepting new S3 requests and fail health checks,
+// in-flight requests and multipart uploads are given until timeout to
+// finish and background jobs are handed off to other nodes. The node is
+// ready to be shut down once the drain status reports it as drained.
+func (a adminAPIHandlers) DrainNodeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "DrainNode")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServiceStopAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	timeout := defaultDrainTimeout
+	if t := r.Form.Get("timeout"); t != "" {
+		var err error
+		timeout, err = time.ParseDuration(t)
+		if err != nil || timeout < 0 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+
+	status, err := globalNotificationSys.DrainNode(ctx, r.Form.Get("node"), timeout)
+	writeDrainStatusResponse(ctx, w, r, status, err)
+}
+
+// DrainStatusHandler - GET /minio/admin/v3/service/drain?node={node}
+// ----------
+// Returns the drain status of the node.
+func (a adminAPIHandlers) DrainStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "DrainStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServiceStopAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	status, err := globalNotificationSys.NodeDrainStatus(ctx, r.Form.Get("node"))
+	writeDrainStatusResponse(ctx, w, r, status, err)
+}
+
 // ServerProperties holds some server information such as, version, region
 // uptime, etc..
 type ServerProperties struct {
@@ -347,176 +505,1156 @@ func (a adminAPIHandlers) DataUsageInfoHandler(w http.ResponseWriter, r *http.Re
 	writeSuccessResponseJSON(w, dataUsageInfoJSON)
 }
 
-func lriToLockEntry(l lockRequesterInfo, resource, server string) *madmin.LockEntry {
-	entry := &madmin.LockEntry{
-		Timestamp:  l.Timestamp,
-		Resource:   resource,
-		ServerList: []string{server},
-		Source:     l.Source,
-		Owner:      l.Owner,
-		ID:         l.UID,
-		Quorum:     l.Quorum,
-	}
-	if l.Writer {
-		entry.Type = "WRITE"
-	} else {
-		entry.Type = "READ"
+// DataUsageDeltaHandler - GET /minio/admin/v3/datausagedelta?since=
+// ----------
+// Get the changes of the usage of every bucket during the last scanner
+// cycle, or since the cycle completed before the RFC 3339 time since.
+func (a adminAPIHandlers) DataUsageDeltaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "DataUsageDelta")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
 	}
-	return entry
-}
 
-func topLockEntries(peerLocks []*PeerLocks, stale bool) madmin.LockEntries {
-	entryMap := make(map[string]*madmin.LockEntry)
-	for _, peerLock := range peerLocks {
-		if peerLock == nil {
-			continue
-		}
-		for k, v := range peerLock.Locks {
-			for _, lockReqInfo := range v {
-				if val, ok := entryMap[lockReqInfo.Name]; ok {
-					val.ServerList = append(val.ServerList, peerLock.Addr)
-				} else {
-					entryMap[lockReqInfo.Name] = lriToLockEntry(lockReqInfo, k, peerLock.Addr)
-				}
-			}
+	var since time.Time
+	if v := r.Form.Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
 		}
 	}
-	var lockEntries madmin.LockEntries
-	for _, v := range entryMap {
-		if stale {
-			lockEntries = append(lockEntries, *v)
-			continue
-		}
-		if len(v.ServerList) >= v.Quorum {
-			lockEntries = append(lockEntries, *v)
-		}
+
+	snapshots, err := loadDataUsageSnapshots(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
 	}
-	sort.Sort(lockEntries)
-	return lockEntries
-}
 
-// PeerLocks holds server information result of one node
-type PeerLocks struct {
-	Addr  string
-	Locks map[string][]lockRequesterInfo
+	deltaJSON, err := json.Marshal(dataUsageDelta(snapshots, since))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, deltaJSON)
 }
 
-// ForceUnlockHandler force unlocks requested resource
-func (a adminAPIHandlers) ForceUnlockHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := newContext(r, w, "ForceUnlock")
+// CapacityInfoHandler - GET /minio/admin/v3/capacity
+// ----------
+// Get the raw, usable, stranded and data capacity of every erasure set,
+// sets of mixed drive sizes being capped at their smallest drive.
+func (a adminAPIHandlers) CapacityInfoHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CapacityInfo")
 
 	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
 
-	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ForceUnlockAdminAction)
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.StorageInfoAdminAction)
 	if objectAPI == nil {
 		return
 	}
 
-	z, ok := objectAPI.(*erasureServerPools)
-	if !ok {
+	if !globalIsErasure {
 		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
 		return
 	}
 
-	vars := mux.Vars(r)
+	storageInfo, _ := objectAPI.StorageInfo(ctx)
+	capacityJSON, err := json.Marshal(getCapacityInfo(storageInfo))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
 
-	var args dsync.LockArgs
-	var lockers []dsync.NetLocker
-	for _, path := range strings.Split(vars["paths"], ",") {
-		if path == "" {
-			continue
-		}
-		args.Resources = append(args.Resources, path)
+	writeSuccessResponseJSON(w, capacityJSON)
+}
+
+// CapacityForecastHandler - GET /minio/admin/v3/capacity-forecast
+// ----------
+// Get the projected days until every pool is full and every bucket
+// reaches its hard quota.
+func (a adminAPIHandlers) CapacityForecastHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CapacityForecast")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
 	}
 
-	for _, lks := range z.serverPools[0].erasureLockers {
-		lockers = append(lockers, lks...)
+	forecast, err := loadCapacityForecast(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
 	}
 
-	for _, locker := range lockers {
-		locker.ForceUnlock(ctx, args)
+	forecastJSON, err := json.Marshal(forecast)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
 	}
+
+	writeSuccessResponseJSON(w, forecastJSON)
 }
 
-// TopLocksHandler Get list of locks in use
-func (a adminAPIHandlers) TopLocksHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := newContext(r, w, "TopLocks")
+// AddPoolHandler - POST /minio/admin/v3/pools/add
+// ----------
+// Adds a server pool without restarting, the servers of the new pool
+// being started with the arguments of all pools beforehand. The body
+// holds the arguments of the new pool, appended to the current ones.
+// All nodes validate the new pools, then the pool is activated on one
+// node at a time.
+func (a adminAPIHandlers) AddPoolHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "AddPool")
 
 	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
 
-	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.TopLocksAdminAction)
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
 	if objectAPI == nil {
 		return
 	}
 
-	count := 10 // by default list only top 10 entries
-	if countStr := r.Form.Get("count"); countStr != "" {
-		var err error
-		count, err = strconv.Atoi(countStr)
-		if err != nil {
-			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
-			return
-		}
+	if _, ok := objectAPI.(*erasureServerPools); !ok || !globalIsDistErasure {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
 	}
-	stale := r.Form.Get("stale") == "true" // list also stale locks
-
-	peerLocks := globalNotificationSys.GetLocks(ctx, r)
-
-	topLocks := topLockEntries(peerLocks, stale)
 
-	// Marshal API response upto requested count.
-	if len(topLocks) > count && count > 0 {
-		topLocks = topLocks[:count]
+	var req struct {
+		Args []string `json:"args"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Args) == 0 {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
 	}
 
-	jsonBytes, err := json.Marshal(topLocks)
+	args := append(append([]string{}, globalServerCmdArgs...), req.Args...)
+	ctx, cancel := context.WithTimeout(ctx, poolExpansionTimeout)
+	defer cancel()
+
+	infoJSON, err := json.Marshal(expandPools(ctx, objectAPI, args))
 	if err != nil {
 		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
 		return
 	}
 
-	// Reply with storage information (across nodes in a
-	// distributed setup) as json.
-	writeSuccessResponseJSON(w, jsonBytes)
-}
-
-// StartProfilingResult contains the status of the starting
-// profiling action in a given server
-type StartProfilingResult struct {
-	NodeName string `json:"nodeName"`
-	Success  bool   `json:"success"`
-	Error    string `json:"error"`
+	writeSuccessResponseJSON(w, infoJSON)
 }
 
-// StartProfilingHandler - POST /minio/admin/v3/profiling/start?profilerType={profilerType}
+// SetPoolTagsHandler - PUT /minio/admin/v3/pool-tags
 // ----------
-// Enable server profiling
-func (a adminAPIHandlers) StartProfilingHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := newContext(r, w, "StartProfiling")
+// Tags the server pools, such as by drive class or site, buckets are
+// pinned to pools by their tags. All nodes load the tags again.
+func (a adminAPIHandlers) SetPoolTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SetPoolTags")
 
 	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
 
-	// Validate request signature.
-	_, adminAPIErr := checkAdminRequestAuth(ctx, r, iampolicy.ProfilingAdminAction, "")
-	if adminAPIErr != ErrNone {
-		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
 		return
 	}
 
-	if globalNotificationSys == nil {
-		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
 		return
 	}
 
-	vars := mux.Vars(r)
-	profiles := strings.Split(vars["profilerType"], ",")
-	thisAddr, err := xnet.ParseHost(globalLocalNodeName)
+	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
 		return
 	}
 
-	globalProfilerMu.Lock()
+	poolTags, err := parsePoolTags(data, len(z.currentPools()))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidPoolTags",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	if data, err = json.Marshal(poolTags); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err = saveConfig(ctx, objectAPI, poolTagsPath(), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	resultsJSON, err := json.Marshal(reloadClusterConfig(ctx, objectAPI))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, resultsJSON)
+}
+
+// GetPoolTagsHandler - GET /minio/admin/v3/pool-tags
+// ----------
+// Get the tags of all server pools.
+func (a adminAPIHandlers) GetPoolTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetPoolTags")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	poolTagsJSON, err := json.Marshal(globalPoolTags.get(len(z.currentPools())))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, poolTagsJSON)
+}
+
+// IncompleteUploadsInfoHandler - GET /minio/admin/v3/incompleteuploadsinfo
+// ----------
+// Get the incomplete multipart uploads per bucket counted by the scanner
+func (a adminAPIHandlers) IncompleteUploadsInfoHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "IncompleteUploadsInfo")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	usage, err := loadIncompleteUploadsUsage(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	usageJSON, err := json.Marshal(usage)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, usageJSON)
+}
+
+// ScannerHealthHandler - GET /minio/admin/v3/scanner-health
+// ----------
+// Get the last scans of each erasure set and whether they are stale.
+func (a adminAPIHandlers) ScannerHealthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ScannerHealth")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	health, err := loadScannerHealth(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	healthJSON, err := json.Marshal(health)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, healthJSON)
+}
+
+// UsageCacheValidateHandler - GET /minio/admin/v3/usage-cache?bucket=
+// ----------
+// Validate the usage caches of the bucket on every erasure set, or of
+// the bucket totals and all buckets without a bucket.
+func (a adminAPIHandlers) UsageCacheValidateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "UsageCacheValidate")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	statuses, err := z.validateUsageCaches(ctx, r.Form.Get("bucket"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	statusesJSON, err := json.Marshal(statuses)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusesJSON)
+}
+
+// UsageCacheRebuildHandler - POST /minio/admin/v3/usage-cache/rebuild?bucket=
+// ----------
+// Start rebuilding the usage caches of the bucket on every erasure set
+// from a fresh scan.
+func (a adminAPIHandlers) UsageCacheRebuildHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "UsageCacheRebuild")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidBucketName), r.URL)
+		return
+	}
+
+	sets, err := z.rebuildUsageCaches(ctx, bucket)
+	if err != nil {
+		if err == errUsageCacheRebuilding {
+			err = AdminError{
+				Code:       "XMinioAdminUsageCacheRebuilding",
+				Message:    err.Error(),
+				StatusCode: http.StatusConflict,
+			}
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	resp, err := json.Marshal(struct {
+		Bucket string `json:"bucket"`
+		Sets   int    `json:"sets"`
+	}{Bucket: bucket, Sets: sets})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, resp)
+}
+
+func systemMetaAdminErr(err error) error {
+	switch err {
+	case errSystemMetaDenied:
+		return AdminError{
+			Code:       "XMinioAdminSystemMetaDenied",
+			Message:    err.Error(),
+			StatusCode: http.StatusForbidden,
+		}
+	case errSystemMetaTooLarge:
+		return AdminError{
+			Code:       "XMinioAdminSystemMetaTooLarge",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return err
+}
+
+// ListSystemMetaHandler - GET /minio/admin/v3/system-meta?prefix={prefix}&marker={marker}
+// ----------
+// Lists the internal metadata objects and prefixes of .minio.sys under
+// prefix, leaving out those holding credentials and secrets.
+func (a adminAPIHandlers) ListSystemMetaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListSystemMeta")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	prefix, err := systemMetaPath(r.Form.Get("prefix"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, systemMetaAdminErr(err)), r.URL)
+		return
+	}
+
+	list, err := listSystemMeta(ctx, objectAPI, prefix, r.Form.Get("marker"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	listJSON, err := json.Marshal(list)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, listJSON)
+}
+
+// GetSystemMetaHandler - GET /minio/admin/v3/system-meta/object?path={path}
+// ----------
+// Returns an internal metadata object of .minio.sys decoded from bucket
+// metadata, usage caches and JSON, other objects raw. Objects holding
+// credentials and secrets cannot be read, nothing can be written.
+func (a adminAPIHandlers) GetSystemMetaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "GetSystemMeta")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	p, err := systemMetaPath(r.Form.Get("path"))
+	if err == nil && (p == "" || strings.HasSuffix(p, SlashSeparator)) {
+		err = errSystemMetaDenied
+	}
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, systemMetaAdminErr(err)), r.URL)
+		return
+	}
+
+	obj, err := readSystemMeta(ctx, objectAPI, p)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, systemMetaAdminErr(err)), r.URL)
+		return
+	}
+
+	objJSON, err := json.Marshal(obj)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, objJSON)
+}
+
+func clusterMetadataAdminErr(err error) error {
+	if errors.Is(err, errClusterMetadataSignature) || errors.Is(err, errClusterMetadataInvalid) {
+		return AdminError{
+			Code:       "XMinioAdminInvalidClusterMetadata",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	if errors.Is(err, errClusterMetadataDeployment) || errors.Is(err, errClusterMetadataStale) {
+		return AdminError{
+			Code:       "XMinioAdminClusterMetadataConflict",
+			Message:    err.Error(),
+			StatusCode: http.StatusConflict,
+		}
+	}
+	return err
+}
+
+// ExportClusterMetadataHandler - GET /minio/admin/v3/cluster-metadata/export
+// ----------
+// Returns a zip archive of the cluster metadata, the config, IAM, tiers,
+// site replication state and the metadata of every bucket, signed and
+// encrypted with the root credentials, to rebuild the cluster state after
+// losing it. Only the root user may export it.
+func (a adminAPIHandlers) ExportClusterMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ExportClusterMetadata")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+	if cred.AccessKey != globalActiveCred.AccessKey {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
+	archive, err := exportClusterMetadata(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	w.Header().Set(xhttp.ContentDisposition, fmt.Sprintf("attachment; filename=\"cluster-metadata-%s.zip\"", UTCNow().Format("20060102150405")))
+	writeResponse(w, http.StatusOK, archive, mimeType("application/zip"))
+}
+
+// ImportClusterMetadataHandler - PUT /minio/admin/v3/cluster-metadata/import?dry-run={bool}&force={bool}
+// ----------
+// Restores the cluster metadata of an archive exported with the same
+// root credentials, creating the missing buckets and reloading the
+// metadata and config on all nodes. With dry-run the archive is only
+// verified. Archives of other deployments, or older than the current
+// metadata, are only imported with force. Only the root user may import.
+func (a adminAPIHandlers) ImportClusterMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ImportClusterMetadata")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+	if cred.AccessKey != globalActiveCred.AccessKey {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
+	if r.ContentLength <= 0 || r.ContentLength > maxClusterMetadataArchive {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	archive, err := ioutil.ReadAll(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.Form.Get("dry-run"))
+	force, _ := strconv.ParseBool(r.Form.Get("force"))
+
+	result, err := importClusterMetadata(ctx, objectAPI, archive, dryRun, force)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, clusterMetadataAdminErr(err)), r.URL)
+		return
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, resultJSON)
+}
+
+func multipartUploadsFilterAdminErr(err error) error {
+	return AdminError{
+		Code:       "XMinioAdminInvalidUploadsFilter",
+		Message:    err.Error(),
+		StatusCode: http.StatusBadRequest,
+	}
+}
+
+// ListMultipartUploadsHandler - GET /minio/admin/v3/multipart-uploads?bucket={bucket}&prefix={prefix}&older-than={duration}&max={max}
+// ----------
+// Lists the incomplete multipart uploads of all buckets, optionally of a
+// bucket and prefix only and older than the duration since their last
+// part was uploaded, up to max uploads, one thousand by default and ten
+// thousand at most.
+func (a adminAPIHandlers) ListMultipartUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ListClusterMultipartUploads")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	f, err := parseMultipartUploadsFilter(r.Form, false)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, multipartUploadsFilterAdminErr(err)), r.URL)
+		return
+	}
+	max := defaultClusterMultipartUploads
+	if v := r.Form.Get("max"); v != "" {
+		if max, err = strconv.Atoi(v); err != nil || max <= 0 || max > maxClusterMultipartUploads {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+
+	uploads, err := listClusterMultipartUploads(ctx, objectAPI, f, max)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	uploadsJSON, err := json.Marshal(uploads)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, uploadsJSON)
+}
+
+// AbortMultipartUploadsHandler - DELETE /minio/admin/v3/multipart-uploads?bucket={bucket}&prefix={prefix}&older-than={duration}
+// ----------
+// Aborts the incomplete multipart uploads of all buckets, optionally of
+// a bucket and prefix only, older than the duration since their last
+// part was uploaded.
+func (a adminAPIHandlers) AbortMultipartUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "AbortClusterMultipartUploads")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	f, err := parseMultipartUploadsFilter(r.Form, true)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, multipartUploadsFilterAdminErr(err)), r.URL)
+		return
+	}
+
+	result, err := abortClusterMultipartUploads(ctx, objectAPI, f)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, resultJSON)
+}
+
+func lriToLockEntry(l lockRequesterInfo, resource, server string) *madmin.LockEntry {
+	entry := &madmin.LockEntry{
+		Timestamp:  l.Timestamp,
+		Resource:   resource,
+		ServerList: []string{server},
+		Source:     l.Source,
+		Owner:      l.Owner,
+		ID:         l.UID,
+		Quorum:     l.Quorum,
+	}
+	if l.Writer {
+		entry.Type = "WRITE"
+	} else {
+		entry.Type = "READ"
+	}
+	return entry
+}
+
+func topLockEntries(peerLocks []*PeerLocks, stale bool) madmin.LockEntries {
+	entryMap := make(map[string]*madmin.LockEntry)
+	for _, peerLock := range peerLocks {
+		if peerLock == nil {
+			continue
+		}
+		for k, v := range peerLock.Locks {
+			for _, lockReqInfo := range v {
+				if val, ok := entryMap[lockReqInfo.Name]; ok {
+					val.ServerList = append(val.ServerList, peerLock.Addr)
+				} else {
+					entryMap[lockReqInfo.Name] = lriToLockEntry(lockReqInfo, k, peerLock.Addr)
+				}
+			}
+		}
+	}
+	var lockEntries madmin.LockEntries
+	for _, v := range entryMap {
+		if stale {
+			lockEntries = append(lockEntries, *v)
+			continue
+		}
+		if len(v.ServerList) >= v.Quorum {
+			lockEntries = append(lockEntries, *v)
+		}
+	}
+	sort.Sort(lockEntries)
+	return lockEntries
+}
+
+// PeerLocks holds server information result of one node
+type PeerLocks struct {
+	Addr  string
+	Locks map[string][]lockRequesterInfo
+}
+
+// ForceUnlockHandler force unlocks requested resource
+func (a adminAPIHandlers) ForceUnlockHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ForceUnlock")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	var args dsync.LockArgs
+	var lockers []dsync.NetLocker
+	for _, path := range strings.Split(vars["paths"], ",") {
+		if path == "" {
+			continue
+		}
+		args.Resources = append(args.Resources, path)
+	}
+
+	for _, lks := range z.currentPools()[0].erasureLockers {
+		lockers = append(lockers, lks...)
+	}
+
+	for _, locker := range lockers {
+		locker.ForceUnlock(ctx, args)
+	}
+}
+
+// TopLocksHandler Get list of locks in use
+func (a adminAPIHandlers) TopLocksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TopLocks")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.TopLocksAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	count := 10 // by default list only top 10 entries
+	if countStr := r.Form.Get("count"); countStr != "" {
+		var err error
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+	stale := r.Form.Get("stale") == "true" // list also stale locks
+
+	peerLocks := globalNotificationSys.GetLocks(ctx, r)
+
+	topLocks := topLockEntries(peerLocks, stale)
+
+	// Marshal API response upto requested count.
+	if len(topLocks) > count && count > 0 {
+		topLocks = topLocks[:count]
+	}
+
+	jsonBytes, err := json.Marshal(topLocks)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// Reply with storage information (across nodes in a
+	// distributed setup) as json.
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// TopAPIResult is a snapshot of the S3 requests in flight in the cluster.
+type TopAPIResult struct {
+	Time     time.Time         `json:"time"`
+	Total    int               `json:"total"`
+	Requests []InflightRequest `json:"requests"`
+}
+
+// TopAPIHandler - GET /minio/admin/v3/top/api?count={count}&interval={interval}
+// ----------
+// Streams the oldest S3 requests in flight across the cluster to the
+// connected client, a new snapshot is sent every interval.
+func (a adminAPIHandlers) TopAPIHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TopAPI")
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.TraceAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	count := 10 // by default list only top 10 entries
+	if countStr := r.Form.Get("count"); countStr != "" {
+		var err error
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+	interval := time.Second
+	if intervalStr := r.Form.Get("interval"); intervalStr != "" {
+		var err error
+		interval, err = time.ParseDuration(intervalStr)
+		if err != nil || interval < 100*time.Millisecond {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+
+	setEventStreamHeaders(w)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	enc := json.NewEncoder(w)
+	for {
+		reqs := globalNotificationSys.GetInflightRequests(ctx)
+		result := TopAPIResult{
+			Time:  UTCNow(),
+			Total: len(reqs),
+		}
+		if len(reqs) > count && count > 0 {
+			reqs = reqs[:count]
+		}
+		result.Requests = reqs
+		if err := enc.Encode(result); err != nil {
+			return
+		}
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CancelRequestHandler - POST /minio/admin/v3/top/api/cancel?id={requestID}
+// ----------
+// Asks an S3 request in flight, identified by its x-amz-request-id, to
+// stop. It is accepted once the node serving the request canceled its
+// context, the request is listed as canceled until its handler stops.
+func (a adminAPIHandlers) CancelRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CancelRequest")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, cancelRequestAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if !globalNotificationSys.CancelInflightRequest(ctx, id) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminNoSuchRequest",
+			Message:    fmt.Sprintf("no request with id %s is in flight", id),
+			StatusCode: http.StatusNotFound,
+		}), r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusAccepted, nil, mimeNone)
+}
+
+// ExtractProgressHandler - GET /minio/admin/v3/extract-progress
+// ----------
+// Returns the progress of the archives being extracted on all nodes.
+func (a adminAPIHandlers) ExtractProgressHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ExtractProgress")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.TraceAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := json.Marshal(globalNotificationSys.GetExtractProgress(ctx))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// SLOReportHandler - GET /minio/admin/v3/slo
+// ----------
+// Returns the error budgets and burn rates of the service level
+// objectives over the calls served by all nodes.
+func (a adminAPIHandlers) SLOReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SLOReport")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	nodes, errs := globalNotificationSys.GetSLOCounts(ctx)
+	report := SLOReport{
+		Time:   UTCNow(),
+		Nodes:  len(nodes) - len(errs),
+		Errors: errs,
+	}
+	for _, o := range globalSLOTracker.objectiveConfigs() {
+		report.Objectives = append(report.Objectives, sloStatus(o, nodes))
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// SlowRequestsResult - the slow requests of all nodes, newest first.
+type SlowRequestsResult struct {
+	Requests []SlowRequest `json:"requests"`
+	Errors   []string      `json:"errors,omitempty"`
+}
+
+// SlowRequestsHandler - GET /minio/admin/v3/slow-requests?count={count}&bucket={bucket}&api={api}&since={time}&until={time}
+// ----------
+// Returns the newest S3 requests of all nodes which took longer than
+// the slow request threshold, with a breakdown of their durations.
+func (a adminAPIHandlers) SlowRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SlowRequests")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	f, err := parseSlowRequestFilter(r.Form)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var result SlowRequestsResult
+	result.Requests, result.Errors = globalNotificationSys.GetSlowRequests(ctx, f)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// IncidentReportsHandler - GET /minio/admin/v3/incidents
+// ----------
+// Returns the panic and memory pressure incident reports recorded by
+// all nodes, newest first.
+func (a adminAPIHandlers) IncidentReportsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "IncidentReports")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConsoleLogAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	reports, err := listIncidentReports(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(reports)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// MetadataSinkBackfillHandler - POST /minio/admin/v3/metadata-sink/backfill
+// ----------
+// Requests the scanner to write the latest version of all objects of the
+// indexed buckets to the metadata sink, recovering from dropped changes
+// and rebuilding a lost index.
+func (a adminAPIHandlers) MetadataSinkBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "MetadataSinkBackfill")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if cfg, _, _ := globalMetadataSink.config(); !cfg.Enabled {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminMetadataSinkNotEnabled), r.URL)
+		return
+	}
+
+	backfill, err := requestMetadataSinkBackfill(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(backfill)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// MetadataSinkStatusHandler - GET /minio/admin/v3/metadata-sink/status
+// ----------
+// Returns the configuration of the metadata sink, the progress of its
+// last backfill and the statistics of the node serving the request.
+func (a adminAPIHandlers) MetadataSinkStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "MetadataSinkStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	status, err := getMetadataSinkStatus(ctx, objectAPI)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+// MalwareScanReleaseHandler - POST /minio/admin/v3/malware-scan/release?bucket={bucket}&object={object}&versionId={versionId}
+// ----------
+// Releases an object version quarantined by malware scanning, e.g. after
+// reviewing it, making it readable again.
+func (a adminAPIHandlers) MalwareScanReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "MalwareScanRelease")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket, object, versionID := r.Form.Get("bucket"), r.Form.Get("object"), r.Form.Get("versionId")
+	oi, err := objectAPI.GetObjectInfo(ctx, bucket, object, ObjectOptions{VersionID: versionID})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if !isQuarantined(oi) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrBadRequest), r.URL)
+		return
+	}
+
+	if err = setScanVerdict(ctx, objectAPI, bucket, object, oi.VersionID, oi.ETag, scanVerdictReleased, ""); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// StartProfilingResult contains the status of the starting
+// profiling action in a given server
+type StartProfilingResult struct {
+	NodeName string `json:"nodeName"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error"`
+}
+
+// StartProfilingHandler - POST /minio/admin/v3/profiling/start?profilerType={profilerType}
+// ----------
+// Enable server profiling
+func (a adminAPIHandlers) StartProfilingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "StartProfiling")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	// Validate request signature.
+	_, adminAPIErr := checkAdminRequestAuth(ctx, r, iampolicy.ProfilingAdminAction, "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+		return
+	}
+
+	if globalNotificationSys == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	profiles := strings.Split(vars["profilerType"], ",")
+	thisAddr, err := xnet.ParseHost(globalLocalNodeName)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	hostErrs := startProfiling(profiles, *thisAddr)
+
+	var startProfilingResult []StartProfilingResult
+
+	for _, nerr := range hostErrs {
+		result := StartProfilingResult{NodeName: nerr.Host.String()}
+		if nerr.Err != nil {
+			result.Error = nerr.Err.Error()
+		} else {
+			result.Success = true
+		}
+		startProfilingResult = append(startProfilingResult, result)
+	}
+
+	// Create JSON result and send it to the client
+	startProfilingResultInBytes, err := json.Marshal(startProfilingResult)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, startProfilingResultInBytes)
+}
+
+// startProfiling starts the profilers of each type of profiles on all
+// nodes, stopping those of the same types already running.
+func startProfiling(profiles []string, thisAddr xnet.Host) []NotificationPeerErr {
+	globalProfilerMu.Lock()
 	defer globalProfilerMu.Unlock()
 
 	if globalProfiler == nil {
@@ -542,37 +1680,17 @@ func (a adminAPIHandlers) StartProfilingHandler(w http.ResponseWriter, r *http.R
 		prof, err := startProfiler(profiler)
 		if err != nil {
 			hostErrs = append(hostErrs, NotificationPeerErr{
-				Host: *thisAddr,
+				Host: thisAddr,
 				Err:  err,
 			})
 		} else {
 			globalProfiler[profiler] = prof
 			hostErrs = append(hostErrs, NotificationPeerErr{
-				Host: *thisAddr,
+				Host: thisAddr,
 			})
 		}
 	}
-
-	var startProfilingResult []StartProfilingResult
-
-	for _, nerr := range hostErrs {
-		result := StartProfilingResult{NodeName: nerr.Host.String()}
-		if nerr.Err != nil {
-			result.Error = nerr.Err.Error()
-		} else {
-			result.Success = true
-		}
-		startProfilingResult = append(startProfilingResult, result)
-	}
-
-	// Create JSON result and send it to the client
-	startProfilingResultInBytes, err := json.Marshal(startProfilingResult)
-	if err != nil {
-		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
-		return
-	}
-
-	writeSuccessResponseJSON(w, startProfilingResultInBytes)
+	return hostErrs
 }
 
 // dummyFileInfo represents a dummy representation of a profile data file
@@ -599,24 +1717,194 @@ func (f dummyFileInfo) Sys() interface{}   { return f.sys }
 func (a adminAPIHandlers) DownloadProfilingHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "DownloadProfiling")
 
-	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	// Validate request signature.
+	_, adminAPIErr := checkAdminRequestAuth(ctx, r, iampolicy.ProfilingAdminAction, "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+		return
+	}
+
+	if globalNotificationSys == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if !globalNotificationSys.DownloadProfilingData(ctx, w) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminProfilerNotEnabled), r.URL)
+		return
+	}
+}
+
+const (
+	// Profilers of a profile bundle when none are requested.
+	defaultProfileBundleTypes = "cpu,mem,block,mutex,goroutines"
+
+	defaultProfileBundleDuration = time.Minute
+	maxProfileBundleDuration     = 10 * time.Minute
+)
+
+// profileBundleInfo - the build metadata of a profile bundle, added to
+// it as bundle.json for the profiles to be matched with the sources.
+type profileBundleInfo struct {
+	DeploymentID string              `json:"deploymentID"`
+	Started      time.Time           `json:"started"`
+	Duration     string              `json:"duration"`
+	Profilers    []string            `json:"profilers"`
+	GoVersion    string              `json:"goVersion"`
+	OS           string              `json:"os"`
+	Arch         string              `json:"arch"`
+	Modules      []string            `json:"modules,omitempty"`
+	Nodes        []profileBundleNode `json:"nodes"`
+	Errors       []string            `json:"errors,omitempty"`
+}
+
+// profileBundleNode - the build of a node of a profile bundle.
+type profileBundleNode struct {
+	Endpoint string `json:"endpoint"`
+	State    string `json:"state"`
+	Version  string `json:"version"`
+	CommitID string `json:"commitID"`
+}
+
+// parseProfileBundleTypes validates the comma separated profilers of
+// a profile bundle.
+func parseProfileBundleTypes(s string) ([]string, error) {
+	if s == "" {
+		s = defaultProfileBundleTypes
+	}
+	profiles := strings.Split(s, ",")
+	for _, p := range profiles {
+		switch madmin.ProfilerType(p) {
+		case madmin.ProfilerCPU, madmin.ProfilerMEM, madmin.ProfilerBlock, madmin.ProfilerMutex,
+			madmin.ProfilerThreads, madmin.ProfilerGoroutines, madmin.ProfilerTrace:
+		default:
+			return nil, fmt.Errorf("unknown profiler type %q", p)
+		}
+	}
+	return profiles, nil
+}
+
+// ProfileHandler - POST /minio/admin/v3/profile?profilerType={profilerType}&duration={duration}
+// ----------
+// Profiles all nodes for duration and replies with a single zip of their
+// profiles, along with the build metadata of the nodes in bundle.json.
+func (a adminAPIHandlers) ProfileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Profile")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	// Validate request signature.
+	_, adminAPIErr := checkAdminRequestAuth(ctx, r, iampolicy.ProfilingAdminAction, "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+		return
+	}
+
+	if globalNotificationSys == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	profiles, err := parseProfileBundleTypes(r.Form.Get("profilerType"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminInvalidArgument), r.URL)
+		return
+	}
+	duration := defaultProfileBundleDuration
+	if v := r.Form.Get("duration"); v != "" {
+		duration, err = time.ParseDuration(v)
+		if err != nil || duration <= 0 || duration > maxProfileBundleDuration {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminInvalidArgument), r.URL)
+			return
+		}
+	}
+
+	thisAddr, err := xnet.ParseHost(globalLocalNodeName)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
 
-	// Validate request signature.
-	_, adminAPIErr := checkAdminRequestAuth(ctx, r, iampolicy.ProfilingAdminAction, "")
-	if adminAPIErr != ErrNone {
-		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+	info := profileBundleInfo{
+		DeploymentID: globalDeploymentID,
+		Started:      UTCNow(),
+		Duration:     duration.String(),
+		Profilers:    profiles,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, m := range bi.Deps {
+			info.Modules = append(info.Modules, m.Path+"@"+m.Version)
+		}
+	}
+
+	var started bool
+	for _, nerr := range startProfiling(profiles, *thisAddr) {
+		if nerr.Err != nil {
+			info.Errors = append(info.Errors, nerr.Host.String()+": "+nerr.Err.Error())
+		} else {
+			started = true
+		}
+	}
+	if !started {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminProfilerNotEnabled), r.URL)
 		return
 	}
 
-	if globalNotificationSys == nil {
-		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// Stop the profilers, the client is gone.
+		globalNotificationSys.DownloadProfilingData(GlobalContext, ioutil.Discard)
 		return
+	case <-timer.C:
 	}
 
-	if !globalNotificationSys.DownloadProfilingData(ctx, w) {
-		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminProfilerNotEnabled), r.URL)
+	servers := globalNotificationSys.ServerInfo()
+	servers = append(servers, getLocalServerProperty(globalEndpoints, r))
+	for _, s := range servers {
+		info.Nodes = append(info.Nodes, profileBundleNode{
+			Endpoint: s.Endpoint,
+			State:    s.State,
+			Version:  s.Version,
+			CommitID: s.CommitID,
+		})
+	}
+
+	w.Header().Set(xhttp.ContentType, "application/zip")
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+
+	globalNotificationSys.addProfilingData(ctx, zipWriter)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	header, err := zip.FileInfoHeader(dummyFileInfo{
+		name:    "bundle.json",
+		size:    int64(len(data)),
+		mode:    0600,
+		modTime: UTCNow(),
+	})
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	header.Method = zip.Deflate
+	zwriter, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		logger.LogIf(ctx, err)
 		return
 	}
+	_, err = zwriter.Write(data)
+	logger.LogIf(ctx, err)
 }
 
 type healInitParams struct {
@@ -911,6 +2199,321 @@ func (a adminAPIHandlers) BackgroundHealStatusHandler(w http.ResponseWriter, r *
 	}
 }
 
+// VerifyBucketHandler - POST /minio/admin/v3/verify-bucket?bucket={bucket}&prefix={prefix}&versions={bool}&etag={bool}&reportBucket={bucket}
+// ----------
+// Starts a job re-reading all objects under the prefix of the bucket,
+// checking their erasure shards for bitrot and optionally recomputing
+// their ETag. Once done a signed report listing the corrupt, partial and
+// unreadable objects is saved, and written to reportBucket if given.
+func (a adminAPIHandlers) VerifyBucketHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "VerifyBucket")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if !globalIsErasure {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	opts := VerifyBucketOpts{
+		Bucket:       r.Form.Get("bucket"),
+		Prefix:       r.Form.Get("prefix"),
+		Versions:     r.Form.Get("versions") == "true",
+		ETag:         r.Form.Get("etag") == "true",
+		ReportBucket: r.Form.Get("reportBucket"),
+	}
+	buckets := []string{opts.Bucket}
+	if opts.ReportBucket != "" {
+		buckets = append(buckets, opts.ReportBucket)
+	}
+	for _, bucket := range buckets {
+		if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+			writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	}
+
+	jsonBytes, err := json.Marshal(globalVerifyJobs.start(objectAPI, opts))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// VerifyBucketStatusHandler - GET /minio/admin/v3/verify-bucket?id={id}
+// ----------
+// Returns the progress of the verification job, all jobs started on
+// this node if no id is given.
+func (a adminAPIHandlers) VerifyBucketStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "VerifyBucketStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var status interface{}
+	if id := r.Form.Get("id"); id != "" {
+		s, err := globalVerifyJobs.get(id)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, verifyJobAdminErr(err)), r.URL)
+			return
+		}
+		status = s
+	} else {
+		status = globalVerifyJobs.list()
+	}
+
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// VerifyBucketReportHandler - GET /minio/admin/v3/verify-bucket/report?id={id}
+// ----------
+// Returns the signed report of a finished verification job.
+func (a adminAPIHandlers) VerifyBucketReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "VerifyBucketReport")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := readVerifyReport(ctx, objectAPI, r.Form.Get("id"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, verifyJobAdminErr(err)), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+func verifyJobAdminErr(err error) error {
+	if err == errNoSuchVerifyJob {
+		return AdminError{
+			Code:       "XMinioAdminNoSuchVerifyJob",
+			Message:    err.Error(),
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return err
+}
+
+// TableMaintenanceHandler - POST /minio/admin/v3/table-maintenance?bucket={bucket}&prefix={prefix}&format={format}&olderThan={duration}&dryRun={bool}
+// ----------
+// Starts a job deleting the orphaned files of the Iceberg or Delta table
+// at prefix, the files older than olderThan referenced by none of the
+// table snapshots or log entries. Once done a report listing the files
+// deleted, or to delete with dryRun, is saved.
+func (a adminAPIHandlers) TableMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TableMaintenance")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if !globalIsErasure {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	opts := TableMaintenanceOpts{
+		Bucket:    r.Form.Get("bucket"),
+		Prefix:    strings.Trim(r.Form.Get("prefix"), SlashSeparator) + SlashSeparator,
+		Format:    r.Form.Get("format"),
+		OlderThan: defaultTableOrphanAge,
+		DryRun:    r.Form.Get("dryRun") == "true",
+	}
+	if opts.Prefix == SlashSeparator {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, errors.New("the prefix of the table is required")), r.URL)
+		return
+	}
+	switch opts.Format {
+	case "", tableFormatIceberg, tableFormatDelta:
+	default:
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, fmt.Errorf("unknown table format %s", opts.Format)), r.URL)
+		return
+	}
+	if v := r.Form.Get("olderThan"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minTableOrphanAge {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErrWithErr(ErrAdminInvalidArgument, fmt.Errorf("olderThan must be a duration of at least %s", minTableOrphanAge)), r.URL)
+			return
+		}
+		opts.OlderThan = d
+	}
+	if _, err := objectAPI.GetBucketInfo(ctx, opts.Bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(globalTableMaintenanceJobs.start(objectAPI, opts))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// TableMaintenanceStatusHandler - GET /minio/admin/v3/table-maintenance?id={id}
+// ----------
+// Returns the progress of the table maintenance job, all jobs started
+// on this node if no id is given.
+func (a adminAPIHandlers) TableMaintenanceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TableMaintenanceStatus")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var status interface{}
+	if id := r.Form.Get("id"); id != "" {
+		s, err := globalTableMaintenanceJobs.get(id)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, tableMaintenanceAdminErr(err)), r.URL)
+			return
+		}
+		status = s
+	} else {
+		status = globalTableMaintenanceJobs.list()
+	}
+
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
+// TableMaintenanceReportHandler - GET /minio/admin/v3/table-maintenance/report?id={id}
+// ----------
+// Returns the report of a finished table maintenance job.
+func (a adminAPIHandlers) TableMaintenanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "TableMaintenanceReport")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := readTableMaintenanceReport(ctx, objectAPI, r.Form.Get("id"))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, tableMaintenanceAdminErr(err)), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, data)
+}
+
+func tableMaintenanceAdminErr(err error) error {
+	if err == errNoSuchTableMaintenanceJob {
+		return AdminError{
+			Code:       "XMinioAdminNoSuchTableMaintenanceJob",
+			Message:    err.Error(),
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return err
+}
+
+// BenchmarkHandler - POST /minio/admin/v3/benchmark?profile={profile}&duration={duration}&concurrent={concurrent}&sizes={sizes}&objects={objects}
+// ----------
+// Runs a mixed GET/PUT/LIST/DELETE workload on all nodes at the same
+// time and returns the throughput and latencies of each operation per
+// node and for the whole cluster. The operation mix of the profile can
+// be overridden with the get, put, list and delete weights, sizes is a
+// list of object sizes and weights such as "4KiB:50,1MiB:40,64MiB:10".
+func (a adminAPIHandlers) BenchmarkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Benchmark")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if !globalIsErasure {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	opts, err := parseBenchmarkOpts(r.Form)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioAdminInvalidBenchmark",
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}), r.URL)
+		return
+	}
+
+	keepAliveTicker := time.NewTicker(500 * time.Millisecond)
+	defer keepAliveTicker.Stop()
+
+	endBlankRepliesCh := make(chan error)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				endBlankRepliesCh <- nil
+				return
+			case <-keepAliveTicker.C:
+				// Write a blank entry to prevent client from disconnecting
+				if err := json.NewEncoder(w).Encode(BenchmarkReport{}); err != nil {
+					endBlankRepliesCh <- err
+					return
+				}
+				w.(http.Flusher).Flush()
+			case endBlankRepliesCh <- nil:
+				return
+			}
+		}
+	}()
+
+	report := globalNotificationSys.Benchmark(ctx, opts)
+	if <-endBlankRepliesCh != nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	objectAPI.DeleteBucket(ctx, pathJoin(minioMetaBucket, minioMetaBenchmarkPrefix), DeleteBucketOptions{Force: true, NoRecreate: true})
+
+	w.(http.Flusher).Flush()
+}
+
 func (a adminAPIHandlers) SpeedtestHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "SpeedtestHandler")
 
@@ -1013,7 +2616,8 @@ const (
 // - input entry is not of the type *madmin.TraceInfo*
 // - errOnly entries are to be traced, not status code 2xx, 3xx.
 // - madmin.TraceInfo type is asked by opts
-func mustTrace(entry interface{}, opts madmin.ServiceTraceOpts) (shouldTrace bool) {
+// - the entry does not pass the filters and sampling of opts
+func mustTrace(entry interface{}, opts traceOptions) (shouldTrace bool) {
 	trcInfo, ok := entry.(madmin.TraceInfo)
 	if !ok {
 		return false
@@ -1024,6 +2628,9 @@ func mustTrace(entry interface{}, opts madmin.ServiceTraceOpts) (shouldTrace boo
 		if shouldTrace && opts.OnlyErrors {
 			shouldTrace = trcInfo.RespInfo.StatusCode >= http.StatusBadRequest
 		}
+		if shouldTrace {
+			shouldTrace = matchTraceFilters(trcInfo, opts)
+		}
 	}()
 
 	if opts.Threshold > 0 {
@@ -1056,7 +2663,7 @@ func mustTrace(entry interface{}, opts madmin.ServiceTraceOpts) (shouldTrace boo
 	return opts.OS && trcInfo.TraceType == madmin.TraceOS
 }
 
-func extractTraceOptions(r *http.Request) (opts madmin.ServiceTraceOpts, err error) {
+func extractTraceOptions(r *http.Request) (opts traceOptions, err error) {
 	q := r.Form
 
 	opts.OnlyErrors = q.Get("err") == "true"
@@ -1080,6 +2687,7 @@ func extractTraceOptions(r *http.Request) (opts madmin.ServiceTraceOpts, err err
 		}
 		opts.Threshold = d
 	}
+	err = parseTraceFilters(q, &opts)
 	return
 }
 
@@ -1897,7 +3505,7 @@ func getTLSInfo() madmin.TLSInfo {
 	}
 
 	if globalIsTLS {
-		for _, c := range globalPublicCerts {
+		for _, c := range getPublicCerts() {
 			tlsInfo.Certs = append(tlsInfo.Certs, madmin.TLSCert{
 				PubKeyAlgo:    c.PublicKeyAlgorithm.String(),
 				SignatureAlgo: c.SignatureAlgorithm.String(),
@@ -1992,6 +3600,29 @@ func (a adminAPIHandlers) ServerInfoHandler(w http.ResponseWriter, r *http.Reque
 	writeSuccessResponseJSON(w, jsonBytes)
 }
 
+// ClusterHealthHandler - GET /minio/admin/v3/cluster-health
+// ----------
+// Get the computed health state of the cluster, along with the
+// machine readable reasons it is degraded or critical.
+func (a adminAPIHandlers) ClusterHealthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ClusterHealth")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, iampolicy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	jsonBytes, err := json.Marshal(getClusterHealth(ctx, objectAPI))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
 func assignPoolNumbers(servers []madmin.ServerProperties) {
 	for i := range servers {
 		for idx, ge := range globalEndpoints {
@@ -2012,7 +3643,7 @@ func fetchLambdaInfo() []map[string][]madmin.TargetIDStatus {
 
 	lambdaMap := make(map[string][]madmin.TargetIDStatus)
 
-	for _, tgt := range globalConfigTargetList.Targets() {
+	for _, tgt := range getConfigTargetList().Targets() {
 		targetIDStatus := make(map[string]madmin.Status)
 		active, _ := tgt.IsActive()
 		targetID := tgt.ID()
@@ -375,3 +375,26 @@ func TestExtractHealInitParams(t *testing.T) {
 	}
 
 }
+
+func TestParseProfileBundleTypes(t *testing.T) {
+	testCases := []struct {
+		types    string
+		expected int
+		success  bool
+	}{
+		{"", 5, true},
+		{"cpu", 1, true},
+		{"cpu,mem,trace", 3, true},
+		{"cpu,disk", 0, false},
+		{"cpu,", 0, false},
+	}
+	for i, tc := range testCases {
+		profiles, err := parseProfileBundleTypes(tc.types)
+		if (err == nil) != tc.success {
+			t.Fatalf("case %d: expected success %v, got %v", i+1, tc.success, err)
+		}
+		if len(profiles) != tc.expected {
+			t.Errorf("case %d: expected %d profilers, got %v", i+1, tc.expected, profiles)
+		}
+	}
+}
@@ -58,17 +58,42 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 	for _, adminVersion := range adminVersions {
 		// Restart and stop MinIO service.
 		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/service").HandlerFunc(gz(httpTraceAll(adminAPI.ServiceHandler))).Queries("action", "{action:.*}")
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/service/drain").HandlerFunc(gz(httpTraceAll(adminAPI.DrainNodeHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/service/drain").HandlerFunc(gz(httpTraceAll(adminAPI.DrainStatusHandler)))
 		// Update MinIO servers.
 		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/update").HandlerFunc(gz(httpTraceAll(adminAPI.ServerUpdateHandler))).Queries("updateURL", "{updateURL:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/update/status").HandlerFunc(gz(httpTraceAll(adminAPI.ServerUpdateStatusHandler)))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/update/abort").HandlerFunc(gz(httpTraceAll(adminAPI.ServerUpdateAbortHandler)))
 
 		// Info operations
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/info").HandlerFunc(gz(httpTraceAll(adminAPI.ServerInfoHandler)))
+		// Cluster health state
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/cluster-health").HandlerFunc(gz(httpTraceAll(adminAPI.ClusterHealthHandler)))
 		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/inspect-data").HandlerFunc(httpTraceHdrs(adminAPI.InspectDataHandler)).Queries("volume", "{volume:.*}", "file", "{file:.*}")
 
 		// StorageInfo operations
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/storageinfo").HandlerFunc(gz(httpTraceAll(adminAPI.StorageInfoHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/capacity").HandlerFunc(gz(httpTraceAll(adminAPI.CapacityInfoHandler)))
 		// DataUsageInfo operations
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/datausageinfo").HandlerFunc(gz(httpTraceAll(adminAPI.DataUsageInfoHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/datausagedelta").HandlerFunc(gz(httpTraceAll(adminAPI.DataUsageDeltaHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/capacity-forecast").HandlerFunc(gz(httpTraceAll(adminAPI.CapacityForecastHandler)))
+		// Incomplete multipart uploads per bucket
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/incompleteuploadsinfo").HandlerFunc(gz(httpTraceAll(adminAPI.IncompleteUploadsInfoHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/scanner-health").HandlerFunc(gz(httpTraceAll(adminAPI.ScannerHealthHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/usage-cache").HandlerFunc(gz(httpTraceAll(adminAPI.UsageCacheValidateHandler)))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/usage-cache/rebuild").HandlerFunc(gz(httpTraceAll(adminAPI.UsageCacheRebuildHandler)))
+		// Incomplete multipart uploads of all buckets
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/multipart-uploads").HandlerFunc(gz(httpTraceAll(adminAPI.ListMultipartUploadsHandler)))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/multipart-uploads").HandlerFunc(gz(httpTraceAll(adminAPI.AbortMultipartUploadsHandler)))
+
+		// Backup and restore of the cluster metadata
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/cluster-metadata/export").HandlerFunc(httpTraceHdrs(adminAPI.ExportClusterMetadataHandler))
+		adminRouter.Methods(http.MethodPut).Path(adminVersion + "/cluster-metadata/import").HandlerFunc(gz(httpTraceHdrs(adminAPI.ImportClusterMetadataHandler)))
+
+		// Read-only browser of the internal metadata
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/system-meta").HandlerFunc(gz(httpTraceHdrs(adminAPI.ListSystemMetaHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/system-meta/object").HandlerFunc(gz(httpTraceHdrs(adminAPI.GetSystemMetaHandler)))
 
 		if globalIsDistErasure || globalIsErasure {
 			/// Heal operations
@@ -80,6 +105,13 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/background-heal/status").HandlerFunc(gz(httpTraceAll(adminAPI.BackgroundHealStatusHandler)))
 
+			// Online pool expansion
+			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/pools/add").HandlerFunc(gz(httpTraceHdrs(adminAPI.AddPoolHandler)))
+
+			// Pool tags pinning buckets to pools
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/pool-tags").HandlerFunc(gz(httpTraceAll(adminAPI.GetPoolTagsHandler)))
+			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/pool-tags").HandlerFunc(gz(httpTraceHdrs(adminAPI.SetPoolTagsHandler)))
+
 			/// Health operations
 
 		}
@@ -88,12 +120,17 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/profiling/start").HandlerFunc(gz(httpTraceAll(adminAPI.StartProfilingHandler))).
 			Queries("profilerType", "{profilerType:.*}")
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/profiling/download").HandlerFunc(gz(httpTraceAll(adminAPI.DownloadProfilingHandler)))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/profile").HandlerFunc(gz(httpTraceAll(adminAPI.ProfileHandler)))
 
 		// Config KV operations.
 		if enableConfigOps {
 			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-config-kv").HandlerFunc(gz(httpTraceHdrs(adminAPI.GetConfigKVHandler))).Queries("key", "{key:.*}")
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/set-config-kv").HandlerFunc(gz(httpTraceHdrs(adminAPI.SetConfigKVHandler)))
 			adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/del-config-kv").HandlerFunc(gz(httpTraceHdrs(adminAPI.DelConfigKVHandler)))
+
+			// Dynamic config and reload.
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/config-dynamic").HandlerFunc(gz(httpTraceAll(adminAPI.ConfigDynamicHandler)))
+			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/reload").HandlerFunc(gz(httpTraceHdrs(adminAPI.ReloadConfigHandler)))
 		}
 
 		// Enable config help in all modes.
@@ -104,6 +141,10 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/list-config-history-kv").HandlerFunc(gz(httpTraceAll(adminAPI.ListConfigHistoryKVHandler))).Queries("count", "{count:[0-9]+}")
 			adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/clear-config-history-kv").HandlerFunc(gz(httpTraceHdrs(adminAPI.ClearConfigHistoryKVHandler))).Queries("restoreId", "{restoreId:.*}")
 			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/restore-config-history-kv").HandlerFunc(gz(httpTraceHdrs(adminAPI.RestoreConfigHistoryKVHandler))).Queries("restoreId", "{restoreId:.*}")
+			// Versioned config history
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/config-history").HandlerFunc(gz(httpTraceAll(adminAPI.ListConfigVersionsHandler)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/config-history/diff").HandlerFunc(gz(httpTraceAll(adminAPI.DiffConfigVersionsHandler))).Queries("from", "{from:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/config-history/rollback").HandlerFunc(gz(httpTraceHdrs(adminAPI.RollbackConfigHandler))).Queries("id", "{id:.*}")
 		}
 
 		/// Config import/export bulk operations
@@ -176,6 +217,136 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-quota").HandlerFunc(
 				gz(httpTraceHdrs(adminAPI.PutBucketQuotaConfigHandler))).Queries("bucket", "{bucket:.*}")
 
+			// Bucket compression operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-compression").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketCompressionConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-compression").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketCompressionConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket read-ahead operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-readahead").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketReadAheadConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-readahead").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketReadAheadConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket object defaults operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-object-defaults").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketObjectDefaultsHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-object-defaults").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketObjectDefaultsHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket network ACL operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-network-acl").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketNetworkACLHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-network-acl").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketNetworkACLHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket pool placement operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-placement").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketPlacementHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-placement").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketPlacementHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket trash operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-trash").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketTrashConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-trash").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketTrashConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/list-bucket-trash").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ListBucketTrashHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/restore-bucket-trash").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.RestoreBucketTrashHandler))).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+
+			// Undelete, bulk removal of delete markers
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/undelete").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.UndeleteHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket archive operations
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/bucket-archive").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ArchiveBucketHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/bucket-archive").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.UnarchiveBucketHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/bucket-archive").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketArchiveHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket failover operations
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/bucket-failover").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.BucketFailoverHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/bucket-failover/promote").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.BucketPromoteHandler)))
+
+			// Tenant operations
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-tenant").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.SetTenantHandler))).Queries("name", "{name:.*}")
+			adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/remove-tenant").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.RemoveTenantHandler))).Queries("name", "{name:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/list-tenants").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ListTenantsHandler)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/tenant-usage").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.TenantUsageHandler))).Queries("name", "{name:.*}")
+
+			// Bucket template operations
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-template").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.SetBucketTemplateHandler))).Queries("name", "{name:.*}")
+			adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/remove-bucket-template").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.RemoveBucketTemplateHandler))).Queries("name", "{name:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/list-bucket-templates").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ListBucketTemplatesHandler)))
+
+			// Background job schedules
+			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/set-job-schedules").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.SetJobSchedulesHandler)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/get-job-schedules").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetJobSchedulesHandler)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/job-status").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.JobStatusHandler)))
+
+			// Objects not accessed recently
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/cold-data").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ColdDataHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket dedup operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-dedup").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketDedupConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-dedup").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketDedupConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/dedup-report").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.DedupReportHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket metadata index operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-metadata-index").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketMetadataIndexConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-metadata-index").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketMetadataIndexConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket changelog operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-changelog").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketChangelogConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-changelog").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketChangelogConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket class operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-class").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketClassConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-class").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketClassConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket fsync policy operations
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-fsync").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.GetBucketFsyncConfigHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-fsync").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.PutBucketFsyncConfigHandler))).Queries("bucket", "{bucket:.*}")
+
+			// Bucket snapshot operations
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/bucket-snapshot").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.CreateBucketSnapshotHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/bucket-snapshot").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.ListBucketSnapshotsHandler))).Queries("bucket", "{bucket:.*}")
+			adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/bucket-snapshot").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.DeleteBucketSnapshotHandler))).Queries("bucket", "{bucket:.*}", "id", "{id:.*}")
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/bucket-snapshot/mount").HandlerFunc(
+				gz(httpTraceHdrs(adminAPI.MountBucketSnapshotHandler))).Queries("bucket", "{bucket:.*}", "id", "{id:.*}", "target", "{target:.*}")
+
 			// Bucket replication operations
 			// GetBucketTargetHandler
 			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/list-remote-targets").HandlerFunc(
@@ -199,11 +370,15 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/add").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationAdd)))
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/disable").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationDisable)))
 			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/site-replication/info").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationInfo)))
+			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/bucket-filter").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationSetBucketFilter)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/site-replication/bucket-filter").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationGetBucketFilter)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/site-replication/diff").HandlerFunc(gz(httpTraceHdrs(adminAPI.SiteReplicationDiff)))
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/peer/join").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalJoin)))
 			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/site-replication/peer/bucket-ops").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalBucketOps))).Queries("bucket", "{bucket:.*}").Queries("operation", "{operation:.*}")
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/peer/iam-item").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalReplicateIAMItem)))
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/site-replication/peer/bucket-meta").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalReplicateBucketItem)))
 			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/site-replication/peer/idp-settings").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalGetIDPSettings)))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/site-replication/peer/snapshot").HandlerFunc(gz(httpTraceHdrs(adminAPI.SRInternalGetSnapshot)))
 		}
 
 		if globalIsDistErasure {
@@ -215,6 +390,26 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		}
 
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest").HandlerFunc(httpTraceHdrs(adminAPI.SpeedtestHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/benchmark").HandlerFunc(httpTraceHdrs(adminAPI.BenchmarkHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/verify-bucket").HandlerFunc(gz(httpTraceAll(adminAPI.VerifyBucketHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/verify-bucket").HandlerFunc(gz(httpTraceAll(adminAPI.VerifyBucketStatusHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/verify-bucket/report").HandlerFunc(gz(httpTraceAll(adminAPI.VerifyBucketReportHandler)))
+
+		// Table maintenance
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/table-maintenance").HandlerFunc(gz(httpTraceAll(adminAPI.TableMaintenanceHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/table-maintenance").HandlerFunc(gz(httpTraceAll(adminAPI.TableMaintenanceStatusHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/table-maintenance/report").HandlerFunc(gz(httpTraceAll(adminAPI.TableMaintenanceReportHandler)))
+
+		// Top in-flight S3 requests
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/top/api").HandlerFunc(gz(http.HandlerFunc(adminAPI.TopAPIHandler)))
+		// Cancel an in-flight S3 request
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/top/api/cancel").
+			Queries("id", "{id:.*}").HandlerFunc(gz(httpTraceHdrs(adminAPI.CancelRequestHandler)))
+		// Error budgets of the service level objectives
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/slo").HandlerFunc(gz(httpTraceHdrs(adminAPI.SLOReportHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/slow-requests").HandlerFunc(gz(httpTraceHdrs(adminAPI.SlowRequestsHandler)))
+		// Progress of archives being extracted
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/extract-progress").HandlerFunc(gz(httpTraceHdrs(adminAPI.ExtractProgressHandler)))
 
 		// HTTP Trace
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/trace").HandlerFunc(gz(http.HandlerFunc(adminAPI.TraceHandler)))
@@ -222,6 +417,14 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		// Console Logs
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/log").HandlerFunc(gz(httpTraceAll(adminAPI.ConsoleLogHandler)))
 
+		// Incident reports
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/incidents").HandlerFunc(gz(httpTraceAll(adminAPI.IncidentReportsHandler)))
+
+		// Metadata sink
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/metadata-sink/backfill").HandlerFunc(gz(httpTraceAll(adminAPI.MetadataSinkBackfillHandler)))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/metadata-sink/status").HandlerFunc(gz(httpTraceAll(adminAPI.MetadataSinkStatusHandler)))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/malware-scan/release").HandlerFunc(gz(httpTraceAll(adminAPI.MalwareScanReleaseHandler)))
+
 		// -- KMS APIs --
 		//
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/kms/status").HandlerFunc(gz(httpTraceAll(adminAPI.KMSStatusHandler)))
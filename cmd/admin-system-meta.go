@@ -0,0 +1,232 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxSystemMetaSize is the largest internal metadata object decoded.
+const maxSystemMetaSize = 32 << 20
+
+var (
+	errSystemMetaDenied   = errors.New("Internal metadata path is not readable")
+	errSystemMetaTooLarge = errors.New("Internal metadata object is too large")
+)
+
+// systemMetaDenied are the prefixes of .minio.sys never read, those of
+// credentials and secrets and the volumes which are not objects.
+var systemMetaDenied = []string{
+	minioConfigPrefix + SlashSeparator + minioConfigFile,
+	minioConfigHistoryPrefix + SlashSeparator,
+	iamConfigPrefix + SlashSeparator,
+	tierConfigPath,
+	srStatePrefix + SlashSeparator,
+	pathJoin(minioConfigPrefix, acmePrefix) + SlashSeparator,
+	mpartMetaPrefix + SlashSeparator,
+	"tmp" + SlashSeparator,
+}
+
+// SystemMetaEntry - an entry of .minio.sys, an object or a prefix.
+type SystemMetaEntry struct {
+	Path    string    `json:"path"`
+	Dir     bool      `json:"dir,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// SystemMetaList - the entries of a .minio.sys prefix.
+type SystemMetaList struct {
+	Entries    []SystemMetaEntry `json:"entries"`
+	NextMarker string            `json:"nextMarker,omitempty"`
+}
+
+// SystemMetaObject - an object of .minio.sys decoded by its format,
+// raw objects are returned base64 encoded.
+type SystemMetaObject struct {
+	SystemMetaEntry
+	Format  string          `json:"format"`
+	Content json.RawMessage `json:"content,omitempty"`
+	Raw     []byte          `json:"raw,omitempty"`
+}
+
+// systemMetaBucketMetadata - the bucket metadata, its configs as text.
+type systemMetaBucketMetadata struct {
+	Name    string            `json:"name"`
+	Created time.Time         `json:"created"`
+	Configs map[string]string `json:"configs"`
+}
+
+// systemMetaPath returns the cleaned path p of .minio.sys if it may be
+// read, directories keep their trailing slash.
+func systemMetaPath(p string) (string, error) {
+	if p == "" {
+		return "", nil
+	}
+	cleaned := path.Clean(strings.TrimPrefix(p, SlashSeparator))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errSystemMetaDenied
+	}
+	if strings.HasSuffix(p, SlashSeparator) {
+		cleaned += SlashSeparator
+	}
+	for _, denied := range systemMetaDenied {
+		if strings.HasPrefix(cleaned, denied) {
+			return "", errSystemMetaDenied
+		}
+	}
+	return cleaned, nil
+}
+
+// listSystemMeta lists the entries of .minio.sys under prefix, denied
+// entries are left out.
+func listSystemMeta(ctx context.Context, objAPI ObjectLayer, prefix, marker string) (SystemMetaList, error) {
+	result := SystemMetaList{Entries: []SystemMetaEntry{}}
+	lo, err := objAPI.ListObjects(ctx, minioMetaBucket, prefix, marker, SlashSeparator, maxObjectList)
+	if err != nil {
+		return result, err
+	}
+	for _, p := range lo.Prefixes {
+		if _, err := systemMetaPath(p); err == nil {
+			result.Entries = append(result.Entries, SystemMetaEntry{Path: p, Dir: true})
+		}
+	}
+	for _, oi := range lo.Objects {
+		if _, err := systemMetaPath(oi.Name); err == nil {
+			result.Entries = append(result.Entries, SystemMetaEntry{Path: oi.Name, Size: oi.Size, ModTime: oi.ModTime})
+		}
+	}
+	if lo.IsTruncated {
+		result.NextMarker = lo.NextMarker
+	}
+	return result, nil
+}
+
+// readSystemMeta reads the object p of .minio.sys and decodes it.
+func readSystemMeta(ctx context.Context, objAPI ObjectLayer, p string) (SystemMetaObject, error) {
+	r, err := objAPI.GetObjectNInfo(ctx, minioMetaBucket, p, nil, http.Header{}, readLock, ObjectOptions{})
+	if err != nil {
+		return SystemMetaObject{}, err
+	}
+	defer r.Close()
+	if r.ObjInfo.Size > maxSystemMetaSize {
+		return SystemMetaObject{}, errSystemMetaTooLarge
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSystemMetaSize))
+	if err != nil {
+		return SystemMetaObject{}, err
+	}
+	obj := SystemMetaObject{
+		SystemMetaEntry: SystemMetaEntry{Path: p, Size: r.ObjInfo.Size, ModTime: r.ObjInfo.ModTime},
+	}
+	obj.Format, obj.Content, err = decodeSystemMeta(p, data)
+	if err != nil {
+		// Undecodable objects are returned raw.
+		obj.Format, obj.Content, obj.Raw = "raw", nil, data
+	}
+	return obj, nil
+}
+
+// decodeSystemMeta decodes data of the object p by the format its name
+// implies, returning the format and the content as JSON.
+func decodeSystemMeta(p string, data []byte) (string, json.RawMessage, error) {
+	var v interface{}
+	var format string
+	switch name := path.Base(p); {
+	case name == bucketMetadataFile:
+		b, err := decodeSystemMetaBucketMetadata(data)
+		if err != nil {
+			return "", nil, err
+		}
+		format, v = "bucket-metadata", b
+	case strings.HasSuffix(name, dataUsageCacheName):
+		var d dataUsageCache
+		if err := d.deserialize(bytes.NewReader(data)); err != nil {
+			return "", nil, err
+		}
+		format, v = "usage-cache", d
+	case strings.HasSuffix(name, ".json"):
+		if !json.Valid(data) {
+			return "", nil, errors.New("invalid JSON")
+		}
+		return "json", data, nil
+	default:
+		return "", nil, errors.New("unknown format")
+	}
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", nil, err
+	}
+	return format, content, nil
+}
+
+func decodeSystemMetaBucketMetadata(data []byte) (systemMetaBucketMetadata, error) {
+	if len(data) <= 4 {
+		return systemMetaBucketMetadata{}, errors.New("no data")
+	}
+	if f, v := binary.LittleEndian.Uint16(data[0:2]), binary.LittleEndian.Uint16(data[2:4]); f != bucketMetadataFormat || v != bucketMetadataVersion {
+		return systemMetaBucketMetadata{}, fmt.Errorf("unknown format %d version %d", f, v)
+	}
+	var b BucketMetadata
+	if _, err := b.UnmarshalMsg(data[4:]); err != nil {
+		return systemMetaBucketMetadata{}, err
+	}
+	configs := map[string][]byte{
+		"policy":         b.PolicyConfigJSON,
+		"notification":   b.NotificationConfigXML,
+		"lifecycle":      b.LifecycleConfigXML,
+		"objectLock":     b.ObjectLockConfigXML,
+		"versioning":     b.VersioningConfigXML,
+		"encryption":     b.EncryptionConfigXML,
+		"tagging":        b.TaggingConfigXML,
+		"quota":          b.QuotaConfigJSON,
+		"replication":    b.ReplicationConfigXML,
+		"trash":          b.TrashConfigJSON,
+		"snapshotMount":  b.SnapshotMountJSON,
+		"archive":        b.ArchiveConfigJSON,
+		"dedup":          b.DedupConfigJSON,
+		"compression":    b.CompressionConfigJSON,
+		"networkACL":     b.NetworkACLJSON,
+		"placement":      b.PlacementConfigJSON,
+		"listIndex":      b.ListIndexJSON,
+		"readAhead":      b.ReadAheadConfigJSON,
+		"objectDefaults": b.ObjectDefaultsJSON,
+	}
+	m := systemMetaBucketMetadata{Name: b.Name, Created: b.Created, Configs: make(map[string]string)}
+	for name, config := range configs {
+		if len(config) > 0 {
+			m.Configs[name] = string(config)
+		}
+	}
+	// The remote targets hold credentials.
+	if len(b.BucketTargetsConfigJSON) > 0 {
+		m.Configs["bucketTargets"] = "(redacted)"
+	}
+	return m, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+func TestSystemMetaPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		denied   bool
+	}{
+		{path: "", expected: ""},
+		{path: "buckets/", expected: "buckets/"},
+		{path: "/buckets/bucket/.metadata.bin", expected: "buckets/bucket/.metadata.bin"},
+		{path: "buckets//bucket/./.usage-cache.bin", expected: "buckets/bucket/.usage-cache.bin"},
+		{path: "config/iam/users/user/identity.json", denied: true},
+		{path: "config/iam/", denied: true},
+		{path: "buckets/../config/config.json", denied: true},
+		{path: "../format.json", denied: true},
+		{path: "multipart/", denied: true},
+		{path: "config/acme/account.key", denied: true},
+	}
+	for i, tc := range testCases {
+		got, err := systemMetaPath(tc.path)
+		if tc.denied {
+			if err != errSystemMetaDenied {
+				t.Errorf("Test %d: expected %q to be denied, got %q, %v", i+1, tc.path, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.expected {
+			t.Errorf("Test %d: expected %q, got %q, %v", i+1, tc.expected, got, err)
+		}
+	}
+}
+
+func TestDecodeSystemMeta(t *testing.T) {
+	b := newBucketMetadata("bucket")
+	b.VersioningConfigXML = enabledBucketVersioningConfig
+	b.BucketTargetsConfigJSON = []byte(`{"secretKey":"secret"}`)
+	data := make([]byte, 4, b.Msgsize()+4)
+	binary.LittleEndian.PutUint16(data[0:2], bucketMetadataFormat)
+	binary.LittleEndian.PutUint16(data[2:4], bucketMetadataVersion)
+	data, err := b.MarshalMsg(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	format, content, err := decodeSystemMeta("buckets/bucket/.metadata.bin", data)
+	if err != nil || format != "bucket-metadata" {
+		t.Fatalf("Expected bucket metadata, got %q, %v", format, err)
+	}
+	var m systemMetaBucketMetadata
+	if err = json.Unmarshal(content, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "bucket" || m.Configs["versioning"] != string(enabledBucketVersioningConfig) {
+		t.Fatalf("Unexpected bucket metadata %+v", m)
+	}
+	if m.Configs["bucketTargets"] != "(redacted)" {
+		t.Fatalf("Expected the bucket targets to be redacted, got %q", m.Configs["bucketTargets"])
+	}
+
+	if format, _, err = decodeSystemMeta("buckets/.usage.json", []byte(`{"lastUpdate":"2021-11-01T00:00:00Z"}`)); err != nil || format != "json" {
+		t.Fatalf("Expected JSON, got %q, %v", format, err)
+	}
+	if _, _, err = decodeSystemMeta("buckets/.usage.json", []byte("{")); err == nil {
+		t.Fatal("Expected invalid JSON to fail")
+	}
+	if _, _, err = decodeSystemMeta("buckets/.bloomcycle.bin", []byte{1}); err == nil {
+		t.Fatal("Expected an unknown format to fail")
+	}
+}
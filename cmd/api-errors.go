@@ -55,15 +55,16 @@ type APIError struct {
 
 // APIErrorResponse - error response format
 type APIErrorResponse struct {
-	XMLName    xml.Name `xml:"Error" json:"-"`
-	Code       string
-	Message    string
-	Key        string `xml:"Key,omitempty" json:"Key,omitempty"`
-	BucketName string `xml:"BucketName,omitempty" json:"BucketName,omitempty"`
-	Resource   string
-	Region     string `xml:"Region,omitempty" json:"Region,omitempty"`
-	RequestID  string `xml:"RequestId" json:"RequestId"`
-	HostID     string `xml:"HostId" json:"HostId"`
+	XMLName       xml.Name `xml:"Error" json:"-"`
+	Code          string
+	Message       string
+	Key           string `xml:"Key,omitempty" json:"Key,omitempty"`
+	BucketName    string `xml:"BucketName,omitempty" json:"BucketName,omitempty"`
+	Resource      string
+	Region        string `xml:"Region,omitempty" json:"Region,omitempty"`
+	RequestID     string `xml:"RequestId" json:"RequestId"`
+	HostID        string `xml:"HostId" json:"HostId"`
+	CorrelationID string `xml:"CorrelationId,omitempty" json:"CorrelationId,omitempty"`
 }
 
 // APIErrorCode type of error status.
@@ -240,6 +241,31 @@ const (
 	ErrInvalidObjectNamePrefixSlash
 	ErrInvalidResourceName
 	ErrServerNotInitialized
+	ErrServerDraining
+	ErrBucketSnapshotReadOnly
+	ErrObjectPinnedBySnapshot
+	ErrBucketArchived
+	ErrInvalidRenameSource
+	ErrAppendPositionMismatch
+	ErrInvalidAppendPosition
+	ErrInvalidCommitManifest
+	ErrInvalidComposeRequest
+	ErrTenantQuotaExceeded
+	ErrTenantRequestRateExceeded
+	ErrInvalidObjectEncoding
+	ErrNoSuchLease
+	ErrLeaseHeld
+	ErrStaleFencingToken
+	ErrObjectQuarantined
+	ErrMalwareDetected
+	ErrUploadTokenUsed
+	ErrNoMatchingPools
+	ErrNoSuchBucketTemplate
+	ErrBucketWebhookDenied
+	ErrMetadataIndexNotReady
+	ErrInvalidMetadataQuery
+	ErrChangelogNotEnabled
+	ErrInvalidChangelogOffset
 	ErrOperationTimedOut
 	ErrClientDisconnected
 	ErrOperationMaxedOut
@@ -377,6 +403,7 @@ const (
 
 	ErrAdminConfigNotificationTargetsFailed
 	ErrAdminProfilerNotEnabled
+	ErrAdminMetadataSinkNotEnabled
 	ErrInvalidDecompressedSize
 	ErrAddUserInvalidArgument
 	ErrAdminAccountNotEligible
@@ -1163,6 +1190,131 @@ var errorCodes = errorCodeMap{
 		Description:    "Server not initialized, please try again.",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrServerDraining: {
+		Code:           "XMinioServerDraining",
+		Description:    "Server is draining and does not accept new requests, please try another server.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrBucketSnapshotReadOnly: {
+		Code:           "XMinioBucketSnapshotReadOnly",
+		Description:    "The bucket serves a snapshot of another bucket and is read-only.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrObjectPinnedBySnapshot: {
+		Code:           "XMinioObjectPinnedBySnapshot",
+		Description:    "The object version is pinned by a snapshot of the bucket and cannot be deleted.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrBucketArchived: {
+		Code:           "XMinioBucketArchived",
+		Description:    "The bucket is archived and read-only.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrInvalidRenameSource: {
+		Code:           "InvalidArgument",
+		Description:    "Rename Source must mention another key of the same bucket: bucket/sourcekey.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAppendPositionMismatch: {
+		Code:           "PositionNotEqualToLength",
+		Description:    "The append position does not match the current size of the object.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidAppendPosition: {
+		Code:           "InvalidArgument",
+		Description:    "Append position must be a non-negative integer.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidCommitManifest: {
+		Code:           "InvalidRequest",
+		Description:    "The commit must list between 1 and 1000 uploads of distinct objects, each with its parts.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidComposeRequest: {
+		Code:           "InvalidRequest",
+		Description:    "The compose must list between 1 and 32 source objects.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrTenantQuotaExceeded: {
+		Code:           "XMinioTenantQuotaExceeded",
+		Description:    "The aggregate quota of the tenant of this bucket is exceeded.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrTenantRequestRateExceeded: {
+		Code:           "SlowDown",
+		Description:    "The request rate budget of the tenant of this bucket is exceeded, please reduce your request rate.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrInvalidObjectEncoding: {
+		Code:           "XMinioInvalidObjectEncoding",
+		Description:    "The object is not gzip compressed as its metadata states and cannot be decompressed.",
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+	},
+	ErrNoSuchLease: {
+		Code:           "XMinioNoSuchLease",
+		Description:    "The specified lease does not exist or expired.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrLeaseHeld: {
+		Code:           "XMinioLeaseHeld",
+		Description:    "The object is leased by another client.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrStaleFencingToken: {
+		Code:           "XMinioStaleFencingToken",
+		Description:    "The fencing token is older than the latest lease of the object.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrObjectQuarantined: {
+		Code:           "XMinioObjectQuarantined",
+		Description:    "The object failed malware scanning and is quarantined.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrMalwareDetected: {
+		Code:           "XMinioMalwareDetected",
+		Description:    "The uploaded object failed malware scanning.",
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+	},
+	ErrUploadTokenUsed: {
+		Code:           "XMinioUploadTokenUsed",
+		Description:    "The upload token was already used.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrNoMatchingPools: {
+		Code:           "XMinioNoMatchingPools",
+		Description:    "No server pool has all the placement tags of the bucket.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchBucketTemplate: {
+		Code:           "XMinioNoSuchBucketTemplate",
+		Description:    "The specified bucket template does not exist.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrBucketWebhookDenied: {
+		Code:           "XMinioBucketWebhookDenied",
+		Description:    "The bucket operation was denied by the bucket webhook.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrMetadataIndexNotReady: {
+		Code:           "XMinioMetadataIndexNotReady",
+		Description:    "The metadata index of the bucket is not enabled or not built yet, please try again later.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidMetadataQuery: {
+		Code:           "XMinioInvalidMetadataQuery",
+		Description:    "The metadata query is invalid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrChangelogNotEnabled: {
+		Code:           "XMinioChangelogNotEnabled",
+		Description:    "The changelog of the bucket is not enabled.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrInvalidChangelogOffset: {
+		Code:           "InvalidArgument",
+		Description:    "The changelog offset must be a non-negative integer.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrMalformedJSON: {
 		Code:           "XMinioMalformedJSON",
 		Description:    "The JSON you provided was not well-formed or did not validate against our published format.",
@@ -1234,6 +1386,11 @@ var errorCodes = errorCodeMap{
 		Description:    "Unable to perform the requested operation because profiling is not enabled",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrAdminMetadataSinkNotEnabled: {
+		Code:           "XMinioAdminMetadataSinkNotEnabled",
+		Description:    "Unable to perform the requested operation because the metadata sink is not enabled",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrAdminCredentialsMismatch: {
 		Code:           "XMinioAdminCredentialsMismatch",
 		Description:    "Credentials in config mismatch with server environment variables",
@@ -1857,6 +2014,30 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrSignatureDoesNotMatch
 	case errInvalidRange:
 		apiErr = ErrInvalidRange
+	case errInvalidObjectEncoding:
+		apiErr = ErrInvalidObjectEncoding
+	case errLeaseNotFound:
+		apiErr = ErrNoSuchLease
+	case errLeaseHeld:
+		apiErr = ErrLeaseHeld
+	case errStaleFencingToken:
+		apiErr = ErrStaleFencingToken
+	case errObjectQuarantined:
+		apiErr = ErrObjectQuarantined
+	case errMalwareDetected:
+		apiErr = ErrMalwareDetected
+	case errUploadTokenUsed:
+		apiErr = ErrUploadTokenUsed
+	case errMetadataIndexNotReady:
+		apiErr = ErrMetadataIndexNotReady
+	case errChangelogNotEnabled:
+		apiErr = ErrChangelogNotEnabled
+	case errNoMatchingPools:
+		apiErr = ErrNoMatchingPools
+	case errNoSuchBucketTemplate:
+		apiErr = ErrNoSuchBucketTemplate
+	case errUploadTokenDenied, errBucketTemplateMismatch:
+		apiErr = ErrAccessDenied
 	case errDataTooLarge:
 		apiErr = ErrEntityTooLarge
 	case errDataTooSmall:
@@ -1896,7 +2077,7 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrKMSNotConfigured
 	case context.Canceled, context.DeadlineExceeded:
 		apiErr = ErrOperationTimedOut
-	case errDiskNotFound:
+	case errDiskNotFound, errZipSpoolFull:
 		apiErr = ErrSlowDown
 	case objectlock.ErrInvalidRetentionDate:
 		apiErr = ErrInvalidRetentionDate
@@ -1944,6 +2125,8 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrInvalidBucketName
 	case BucketNotFound:
 		apiErr = ErrNoSuchBucket
+	case BucketWebhookDenied:
+		apiErr = ErrBucketWebhookDenied
 	case BucketAlreadyOwnedByYou:
 		apiErr = ErrBucketAlreadyOwnedByYou
 	case BucketNotEmpty:
@@ -2034,9 +2217,15 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrTransitionStorageClassNotFoundError
 	case InvalidObjectState:
 		apiErr = ErrInvalidObjectState
+	case AppendPositionMismatch:
+		apiErr = ErrAppendPositionMismatch
+	case PreConditionFailed:
+		apiErr = ErrPreconditionFailed
 
 	case BucketQuotaExceeded:
 		apiErr = ErrAdminBucketQuotaExceeded
+	case TenantQuotaExceeded:
+		apiErr = ErrTenantQuotaExceeded
 	case *event.ErrInvalidEventName:
 		apiErr = ErrEventNotification
 	case *event.ErrInvalidARN:
@@ -2111,6 +2300,11 @@ func toAPIError(ctx context.Context, err error) APIError {
 		apiErr = errorCodes.ToAPIErrWithErr(code, e)
 	}
 
+	// The reason given by the bucket webhook.
+	if e, ok := err.(BucketWebhookDenied); ok {
+		apiErr.Description = e.Reason
+	}
+
 	if apiErr.Code == "NotImplemented" {
 		switch e := err.(type) {
 		case NotImplemented:
@@ -2266,13 +2460,14 @@ func getAPIError(code APIErrorCode) APIError {
 func getAPIErrorResponse(ctx context.Context, err APIError, resource, requestID, hostID string) APIErrorResponse {
 	reqInfo := logger.GetReqInfo(ctx)
 	return APIErrorResponse{
-		Code:       err.Code,
-		Message:    err.Description,
-		BucketName: reqInfo.BucketName,
-		Key:        reqInfo.ObjectName,
-		Resource:   resource,
-		Region:     globalServerRegion,
-		RequestID:  requestID,
-		HostID:     hostID,
+		Code:          err.Code,
+		Message:       err.Description,
+		BucketName:    reqInfo.BucketName,
+		Key:           reqInfo.ObjectName,
+		Resource:      resource,
+		Region:        globalServerRegion,
+		RequestID:     requestID,
+		HostID:        hostID,
+		CorrelationID: reqInfo.CorrelationID,
 	}
 }
@@ -754,6 +754,8 @@ const (
 	mimeJSON mimeType = "application/json"
 	// Means response type is XML.
 	mimeXML mimeType = "application/xml"
+	// Means response type is HTML.
+	mimeHTML mimeType = "text/html; charset=utf-8"
 )
 
 // writeSuccessResponseJSON writes success headers and response if any,
@@ -785,11 +787,16 @@ func writeSuccessResponseHeadersOnly(w http.ResponseWriter) {
 
 // writeErrorRespone writes error headers
 func writeErrorResponse(ctx context.Context, w http.ResponseWriter, err APIError, reqURL *url.URL) {
+	recordAuthFailure(ctx, err.Code)
+
 	switch err.Code {
 	case "SlowDown", "XMinioServerNotInitialized", "XMinioReadQuorum", "XMinioWriteQuorum":
-		// Set retry-after header to indicate user-agents to retry request after 120secs.
+		// Set retry-after header to indicate user-agents to retry request after 120secs,
+		// unless already set.
 		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-		w.Header().Set(xhttp.RetryAfter, "120")
+		if w.Header().Get(xhttp.RetryAfter) == "" {
+			w.Header().Set(xhttp.RetryAfter, "120")
+		}
 	case "InvalidRegion":
 		err.Description = fmt.Sprintf("Region does not match; expecting '%s'.", globalServerRegion)
 	case "AuthorizationHeaderMalformed":
@@ -815,6 +822,8 @@ func writeErrorResponseString(ctx context.Context, w http.ResponseWriter, err AP
 // writeErrorResponseJSON - writes error response in JSON format;
 // useful for admin APIs.
 func writeErrorResponseJSON(ctx context.Context, w http.ResponseWriter, err APIError, reqURL *url.URL) {
+	recordAuthFailure(ctx, err.Code)
+
 	// Generate error response.
 	errorResponse := getAPIErrorResponse(ctx, err, reqURL.Path, w.Header().Get(xhttp.AmzRequestID), globalDeploymentID)
 	encodedErrorResponse := encodeResponseJSON(errorResponse)
@@ -829,13 +838,14 @@ func writeCustomErrorResponseJSON(ctx context.Context, w http.ResponseWriter, er
 
 	reqInfo := logger.GetReqInfo(ctx)
 	errorResponse := APIErrorResponse{
-		Code:       err.Code,
-		Message:    errBody,
-		Resource:   reqURL.Path,
-		BucketName: reqInfo.BucketName,
-		Key:        reqInfo.ObjectName,
-		RequestID:  w.Header().Get(xhttp.AmzRequestID),
-		HostID:     globalDeploymentID,
+		Code:          err.Code,
+		Message:       errBody,
+		Resource:      reqURL.Path,
+		BucketName:    reqInfo.BucketName,
+		Key:           reqInfo.ObjectName,
+		RequestID:     w.Header().Get(xhttp.AmzRequestID),
+		HostID:        globalDeploymentID,
+		CorrelationID: reqInfo.CorrelationID,
 	}
 	encodedErrorResponse := encodeResponseJSON(errorResponse)
 	writeResponse(w, err.HTTPStatusCode, encodedErrorResponse, mimeJSON)
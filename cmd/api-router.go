@@ -227,7 +227,7 @@ func registerAPIRouter(router *mux.Router) {
 		// Object operations
 		// HeadObject
 		router.Methods(http.MethodHead).Path("/{object:.+}").HandlerFunc(
-			collectAPIStats("headobject", maxClients(gz(httpTraceAll(api.HeadObjectHandler)))))
+			collectAPIStats("headobject", maxClients(gz(shadowTraffic("headobject", httpTraceAll(api.HeadObjectHandler))))))
 		// CopyObjectPart
 		router.Methods(http.MethodPut).Path("/{object:.+}").
 			HeadersRegexp(xhttp.AmzCopySource, ".*?(\\/|%2F).*?").
@@ -245,12 +245,33 @@ func registerAPIRouter(router *mux.Router) {
 		// NewMultipartUpload
 		router.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
 			collectAPIStats("newmultipartupload", maxClients(gz(httpTraceAll(api.NewMultipartUploadHandler))))).Queries("uploads", "")
+		// PresignedMultipartUpload - MinIO extension API
+		router.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("presignedmultipartupload", maxClients(gz(httpTraceAll(api.PresignedMultipartUploadHandler))))).Queries("presigned-uploads", "")
 		// AbortMultipartUpload
 		router.Methods(http.MethodDelete).Path("/{object:.+}").HandlerFunc(
 			collectAPIStats("abortmultipartupload", maxClients(gz(httpTraceAll(api.AbortMultipartUploadHandler))))).Queries("uploadId", "{uploadId:.*}")
 		// GetObjectACL - this is a dummy call.
 		router.Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(
 			collectAPIStats("getobjectacl", maxClients(gz(httpTraceHdrs(api.GetObjectACLHandler))))).Queries("acl", "")
+		// AppendObject
+		router.Methods(http.MethodPut).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("appendobject", maxClients(gz(httpTraceHdrs(api.AppendObjectHandler))))).Queries("append", "")
+		// RenameObject
+		router.Methods(http.MethodPut).Path("/{object:.+}").HeadersRegexp(xhttp.AmzRenameSource, ".*?(\\/|%2F).*?").HandlerFunc(
+			collectAPIStats("renameobject", maxClients(gz(httpTraceAll(api.RenameObjectHandler))))).Queries("renameObject", "")
+		// ComposeObject
+		router.Methods(http.MethodPut).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("composeobject", maxClients(gz(httpTraceAll(api.ComposeObjectHandler))))).Queries("compose", "")
+		// AcquireObjectLease - MinIO extension API
+		router.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("acquireobjectlease", maxClients(gz(httpTraceAll(api.AcquireObjectLeaseHandler))))).Queries("lease", "")
+		// RenewObjectLease - MinIO extension API
+		router.Methods(http.MethodPut).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("renewobjectlease", maxClients(gz(httpTraceAll(api.RenewObjectLeaseHandler))))).Queries("lease", "", "leaseId", "{leaseId:.*}")
+		// ReleaseObjectLease - MinIO extension API
+		router.Methods(http.MethodDelete).Path("/{object:.+}").HandlerFunc(
+			collectAPIStats("releaseobjectlease", maxClients(gz(httpTraceAll(api.ReleaseObjectLeaseHandler))))).Queries("lease", "", "leaseId", "{leaseId:.*}")
 		// PutObjectACL - this is a dummy call.
 		router.Methods(http.MethodPut).Path("/{object:.+}").HandlerFunc(
 			collectAPIStats("putobjectacl", maxClients(gz(httpTraceHdrs(api.PutObjectACLHandler))))).Queries("acl", "")
@@ -274,7 +295,7 @@ func registerAPIRouter(router *mux.Router) {
 			collectAPIStats("getobjectlegalhold", maxClients(gz(httpTraceAll(api.GetObjectLegalHoldHandler))))).Queries("legal-hold", "")
 		// GetObject - note gzip compression is *not* added due to Range requests.
 		router.Methods(http.MethodGet).Path("/{object:.+}").HandlerFunc(
-			collectAPIStats("getobject", maxClients(httpTraceHdrs(api.GetObjectHandler))))
+			collectAPIStats("getobject", maxClients(shadowTraffic("getobject", httpTraceHdrs(api.GetObjectHandler)))))
 		// CopyObject
 		router.Methods(http.MethodPut).Path("/{object:.+}").HeadersRegexp(xhttp.AmzCopySource, ".*?(\\/|%2F).*?").HandlerFunc(
 			collectAPIStats("copyobject", maxClients(gz(httpTraceAll(api.CopyObjectHandler)))))
@@ -329,6 +350,15 @@ func registerAPIRouter(router *mux.Router) {
 		// ListenNotification
 		router.Methods(http.MethodGet).HandlerFunc(
 			collectAPIStats("listennotification", maxClients(gz(httpTraceAll(api.ListenNotificationHandler))))).Queries("events", "{events:.*}")
+		// MetadataSearch - MinIO extension API
+		router.Methods(http.MethodGet).HandlerFunc(
+			collectAPIStats("metadatasearch", maxClients(gz(httpTraceAll(api.MetadataSearchHandler))))).Queries("metadata-search", "")
+		// Changelog - MinIO extension API
+		router.Methods(http.MethodGet).HandlerFunc(
+			collectAPIStats("changelog", maxClients(httpTraceHdrs(api.ChangelogHandler)))).Queries("changelog", "")
+		// ExportObjects - MinIO extension API
+		router.Methods(http.MethodGet).HandlerFunc(
+			collectAPIStats("exportobjects", maxClients(httpTraceHdrs(api.ExportObjectsHandler)))).Queries("export", "")
 
 		// Dummy Bucket Calls
 		// GetBucketACL -- this is a dummy call.
@@ -412,6 +442,12 @@ func registerAPIRouter(router *mux.Router) {
 		// HeadBucket
 		router.Methods(http.MethodHead).HandlerFunc(
 			collectAPIStats("headbucket", maxClients(gz(httpTraceAll(api.HeadBucketHandler)))))
+		// CommitObjects - MinIO extension API
+		router.Methods(http.MethodPost).HandlerFunc(
+			collectAPIStats("commitobjects", maxClients(gz(httpTraceAll(api.CommitObjectsHandler))))).Queries("commit", "")
+		// SelectObjects - MinIO extension API
+		router.Methods(http.MethodPost).HandlerFunc(
+			collectAPIStats("selectobjectscontent", maxClients(gz(httpTraceHdrs(api.SelectObjectsContentHandler))))).Queries("select", "").Queries("select-type", "2")
 		// PostPolicy
 		router.Methods(http.MethodPost).HeadersRegexp(xhttp.ContentType, "multipart/form-data*").HandlerFunc(
 			collectAPIStats("postpolicybucket", maxClients(gz(httpTraceHdrs(api.PostPolicyBucketHandler)))))
@@ -161,141 +161,167 @@ func _() {
 	_ = x[ErrInvalidObjectNamePrefixSlash-150]
 	_ = x[ErrInvalidResourceName-151]
 	_ = x[ErrServerNotInitialized-152]
-	_ = x[ErrOperationTimedOut-153]
-	_ = x[ErrClientDisconnected-154]
-	_ = x[ErrOperationMaxedOut-155]
-	_ = x[ErrInvalidRequest-156]
-	_ = x[ErrTransitionStorageClassNotFoundError-157]
-	_ = x[ErrInvalidStorageClass-158]
-	_ = x[ErrBackendDown-159]
-	_ = x[ErrMalformedJSON-160]
-	_ = x[ErrAdminNoSuchUser-161]
-	_ = x[ErrAdminNoSuchGroup-162]
-	_ = x[ErrAdminGroupNotEmpty-163]
-	_ = x[ErrAdminNoSuchPolicy-164]
-	_ = x[ErrAdminInvalidArgument-165]
-	_ = x[ErrAdminInvalidAccessKey-166]
-	_ = x[ErrAdminInvalidSecretKey-167]
-	_ = x[ErrAdminConfigNoQuorum-168]
-	_ = x[ErrAdminConfigTooLarge-169]
-	_ = x[ErrAdminConfigBadJSON-170]
-	_ = x[ErrAdminConfigDuplicateKeys-171]
-	_ = x[ErrAdminCredentialsMismatch-172]
-	_ = x[ErrInsecureClientRequest-173]
-	_ = x[ErrObjectTampered-174]
-	_ = x[ErrSiteReplicationInvalidRequest-175]
-	_ = x[ErrSiteReplicationPeerResp-176]
-	_ = x[ErrSiteReplicationBackendIssue-177]
-	_ = x[ErrSiteReplicationServiceAccountError-178]
-	_ = x[ErrSiteReplicationBucketConfigError-179]
-	_ = x[ErrSiteReplicationBucketMetaError-180]
-	_ = x[ErrSiteReplicationIAMError-181]
-	_ = x[ErrAdminBucketQuotaExceeded-182]
-	_ = x[ErrAdminNoSuchQuotaConfiguration-183]
-	_ = x[ErrHealNotImplemented-184]
-	_ = x[ErrHealNoSuchProcess-185]
-	_ = x[ErrHealInvalidClientToken-186]
-	_ = x[ErrHealMissingBucket-187]
-	_ = x[ErrHealAlreadyRunning-188]
-	_ = x[ErrHealOverlappingPaths-189]
-	_ = x[ErrIncorrectContinuationToken-190]
-	_ = x[ErrEmptyRequestBody-191]
-	_ = x[ErrUnsupportedFunction-192]
-	_ = x[ErrInvalidExpressionType-193]
-	_ = x[ErrBusy-194]
-	_ = x[ErrUnauthorizedAccess-195]
-	_ = x[ErrExpressionTooLong-196]
-	_ = x[ErrIllegalSQLFunctionArgument-197]
-	_ = x[ErrInvalidKeyPath-198]
-	_ = x[ErrInvalidCompressionFormat-199]
-	_ = x[ErrInvalidFileHeaderInfo-200]
-	_ = x[ErrInvalidJSONType-201]
-	_ = x[ErrInvalidQuoteFields-202]
-	_ = x[ErrInvalidRequestParameter-203]
-	_ = x[ErrInvalidDataType-204]
-	_ = x[ErrInvalidTextEncoding-205]
-	_ = x[ErrInvalidDataSource-206]
-	_ = x[ErrInvalidTableAlias-207]
-	_ = x[ErrMissingRequiredParameter-208]
-	_ = x[ErrObjectSerializationConflict-209]
-	_ = x[ErrUnsupportedSQLOperation-210]
-	_ = x[ErrUnsupportedSQLStructure-211]
-	_ = x[ErrUnsupportedSyntax-212]
-	_ = x[ErrUnsupportedRangeHeader-213]
-	_ = x[ErrLexerInvalidChar-214]
-	_ = x[ErrLexerInvalidOperator-215]
-	_ = x[ErrLexerInvalidLiteral-216]
-	_ = x[ErrLexerInvalidIONLiteral-217]
-	_ = x[ErrParseExpectedDatePart-218]
-	_ = x[ErrParseExpectedKeyword-219]
-	_ = x[ErrParseExpectedTokenType-220]
-	_ = x[ErrParseExpected2TokenTypes-221]
-	_ = x[ErrParseExpectedNumber-222]
-	_ = x[ErrParseExpectedRightParenBuiltinFunctionCall-223]
-	_ = x[ErrParseExpectedTypeName-224]
-	_ = x[ErrParseExpectedWhenClause-225]
-	_ = x[ErrParseUnsupportedToken-226]
-	_ = x[ErrParseUnsupportedLiteralsGroupBy-227]
-	_ = x[ErrParseExpectedMember-228]
-	_ = x[ErrParseUnsupportedSelect-229]
-	_ = x[ErrParseUnsupportedCase-230]
-	_ = x[ErrParseUnsupportedCaseClause-231]
-	_ = x[ErrParseUnsupportedAlias-232]
-	_ = x[ErrParseUnsupportedSyntax-233]
-	_ = x[ErrParseUnknownOperator-234]
-	_ = x[ErrParseMissingIdentAfterAt-235]
-	_ = x[ErrParseUnexpectedOperator-236]
-	_ = x[ErrParseUnexpectedTerm-237]
-	_ = x[ErrParseUnexpectedToken-238]
-	_ = x[ErrParseUnexpectedKeyword-239]
-	_ = x[ErrParseExpectedExpression-240]
-	_ = x[ErrParseExpectedLeftParenAfterCast-241]
-	_ = x[ErrParseExpectedLeftParenValueConstructor-242]
-	_ = x[ErrParseExpectedLeftParenBuiltinFunctionCall-243]
-	_ = x[ErrParseExpectedArgumentDelimiter-244]
-	_ = x[ErrParseCastArity-245]
-	_ = x[ErrParseInvalidTypeParam-246]
-	_ = x[ErrParseEmptySelect-247]
-	_ = x[ErrParseSelectMissingFrom-248]
-	_ = x[ErrParseExpectedIdentForGroupName-249]
-	_ = x[ErrParseExpectedIdentForAlias-250]
-	_ = x[ErrParseUnsupportedCallWithStar-251]
-	_ = x[ErrParseNonUnaryAgregateFunctionCall-252]
-	_ = x[ErrParseMalformedJoin-253]
-	_ = x[ErrParseExpectedIdentForAt-254]
-	_ = x[ErrParseAsteriskIsNotAloneInSelectList-255]
-	_ = x[ErrParseCannotMixSqbAndWildcardInSelectList-256]
-	_ = x[ErrParseInvalidContextForWildcardInSelectList-257]
-	_ = x[ErrIncorrectSQLFunctionArgumentType-258]
-	_ = x[ErrValueParseFailure-259]
-	_ = x[ErrEvaluatorInvalidArguments-260]
-	_ = x[ErrIntegerOverflow-261]
-	_ = x[ErrLikeInvalidInputs-262]
-	_ = x[ErrCastFailed-263]
-	_ = x[ErrInvalidCast-264]
-	_ = x[ErrEvaluatorInvalidTimestampFormatPattern-265]
-	_ = x[ErrEvaluatorInvalidTimestampFormatPatternSymbolForParsing-266]
-	_ = x[ErrEvaluatorTimestampFormatPatternDuplicateFields-267]
-	_ = x[ErrEvaluatorTimestampFormatPatternHourClockAmPmMismatch-268]
-	_ = x[ErrEvaluatorUnterminatedTimestampFormatPatternToken-269]
-	_ = x[ErrEvaluatorInvalidTimestampFormatPatternToken-270]
-	_ = x[ErrEvaluatorInvalidTimestampFormatPatternSymbol-271]
-	_ = x[ErrEvaluatorBindingDoesNotExist-272]
-	_ = x[ErrMissingHeaders-273]
-	_ = x[ErrInvalidColumnIndex-274]
-	_ = x[ErrAdminConfigNotificationTargetsFailed-275]
-	_ = x[ErrAdminProfilerNotEnabled-276]
-	_ = x[ErrInvalidDecompressedSize-277]
-	_ = x[ErrAddUserInvalidArgument-278]
-	_ = x[ErrAdminAccountNotEligible-279]
-	_ = x[ErrAccountNotEligible-280]
-	_ = x[ErrAdminServiceAccountNotFound-281]
-	_ = x[ErrPostPolicyConditionInvalidFormat-282]
+	_ = x[ErrServerDraining-153]
+	_ = x[ErrBucketSnapshotReadOnly-154]
+	_ = x[ErrObjectPinnedBySnapshot-155]
+	_ = x[ErrBucketArchived-156]
+	_ = x[ErrInvalidRenameSource-157]
+	_ = x[ErrAppendPositionMismatch-158]
+	_ = x[ErrInvalidAppendPosition-159]
+	_ = x[ErrInvalidCommitManifest-160]
+	_ = x[ErrInvalidComposeRequest-161]
+	_ = x[ErrTenantQuotaExceeded-162]
+	_ = x[ErrTenantRequestRateExceeded-163]
+	_ = x[ErrInvalidObjectEncoding-164]
+	_ = x[ErrNoSuchLease-165]
+	_ = x[ErrLeaseHeld-166]
+	_ = x[ErrStaleFencingToken-167]
+	_ = x[ErrObjectQuarantined-168]
+	_ = x[ErrMalwareDetected-169]
+	_ = x[ErrUploadTokenUsed-170]
+	_ = x[ErrNoMatchingPools-171]
+	_ = x[ErrNoSuchBucketTemplate-172]
+	_ = x[ErrBucketWebhookDenied-173]
+	_ = x[ErrMetadataIndexNotReady-174]
+	_ = x[ErrInvalidMetadataQuery-175]
+	_ = x[ErrChangelogNotEnabled-176]
+	_ = x[ErrInvalidChangelogOffset-177]
+	_ = x[ErrOperationTimedOut-178]
+	_ = x[ErrClientDisconnected-179]
+	_ = x[ErrOperationMaxedOut-180]
+	_ = x[ErrInvalidRequest-181]
+	_ = x[ErrTransitionStorageClassNotFoundError-182]
+	_ = x[ErrInvalidStorageClass-183]
+	_ = x[ErrBackendDown-184]
+	_ = x[ErrMalformedJSON-185]
+	_ = x[ErrAdminNoSuchUser-186]
+	_ = x[ErrAdminNoSuchGroup-187]
+	_ = x[ErrAdminGroupNotEmpty-188]
+	_ = x[ErrAdminNoSuchPolicy-189]
+	_ = x[ErrAdminInvalidArgument-190]
+	_ = x[ErrAdminInvalidAccessKey-191]
+	_ = x[ErrAdminInvalidSecretKey-192]
+	_ = x[ErrAdminConfigNoQuorum-193]
+	_ = x[ErrAdminConfigTooLarge-194]
+	_ = x[ErrAdminConfigBadJSON-195]
+	_ = x[ErrAdminConfigDuplicateKeys-196]
+	_ = x[ErrAdminCredentialsMismatch-197]
+	_ = x[ErrInsecureClientRequest-198]
+	_ = x[ErrObjectTampered-199]
+	_ = x[ErrSiteReplicationInvalidRequest-200]
+	_ = x[ErrSiteReplicationPeerResp-201]
+	_ = x[ErrSiteReplicationBackendIssue-202]
+	_ = x[ErrSiteReplicationServiceAccountError-203]
+	_ = x[ErrSiteReplicationBucketConfigError-204]
+	_ = x[ErrSiteReplicationBucketMetaError-205]
+	_ = x[ErrSiteReplicationIAMError-206]
+	_ = x[ErrAdminBucketQuotaExceeded-207]
+	_ = x[ErrAdminNoSuchQuotaConfiguration-208]
+	_ = x[ErrHealNotImplemented-209]
+	_ = x[ErrHealNoSuchProcess-210]
+	_ = x[ErrHealInvalidClientToken-211]
+	_ = x[ErrHealMissingBucket-212]
+	_ = x[ErrHealAlreadyRunning-213]
+	_ = x[ErrHealOverlappingPaths-214]
+	_ = x[ErrIncorrectContinuationToken-215]
+	_ = x[ErrEmptyRequestBody-216]
+	_ = x[ErrUnsupportedFunction-217]
+	_ = x[ErrInvalidExpressionType-218]
+	_ = x[ErrBusy-219]
+	_ = x[ErrUnauthorizedAccess-220]
+	_ = x[ErrExpressionTooLong-221]
+	_ = x[ErrIllegalSQLFunctionArgument-222]
+	_ = x[ErrInvalidKeyPath-223]
+	_ = x[ErrInvalidCompressionFormat-224]
+	_ = x[ErrInvalidFileHeaderInfo-225]
+	_ = x[ErrInvalidJSONType-226]
+	_ = x[ErrInvalidQuoteFields-227]
+	_ = x[ErrInvalidRequestParameter-228]
+	_ = x[ErrInvalidDataType-229]
+	_ = x[ErrInvalidTextEncoding-230]
+	_ = x[ErrInvalidDataSource-231]
+	_ = x[ErrInvalidTableAlias-232]
+	_ = x[ErrMissingRequiredParameter-233]
+	_ = x[ErrObjectSerializationConflict-234]
+	_ = x[ErrUnsupportedSQLOperation-235]
+	_ = x[ErrUnsupportedSQLStructure-236]
+	_ = x[ErrUnsupportedSyntax-237]
+	_ = x[ErrUnsupportedRangeHeader-238]
+	_ = x[ErrLexerInvalidChar-239]
+	_ = x[ErrLexerInvalidOperator-240]
+	_ = x[ErrLexerInvalidLiteral-241]
+	_ = x[ErrLexerInvalidIONLiteral-242]
+	_ = x[ErrParseExpectedDatePart-243]
+	_ = x[ErrParseExpectedKeyword-244]
+	_ = x[ErrParseExpectedTokenType-245]
+	_ = x[ErrParseExpected2TokenTypes-246]
+	_ = x[ErrParseExpectedNumber-247]
+	_ = x[ErrParseExpectedRightParenBuiltinFunctionCall-248]
+	_ = x[ErrParseExpectedTypeName-249]
+	_ = x[ErrParseExpectedWhenClause-250]
+	_ = x[ErrParseUnsupportedToken-251]
+	_ = x[ErrParseUnsupportedLiteralsGroupBy-252]
+	_ = x[ErrParseExpectedMember-253]
+	_ = x[ErrParseUnsupportedSelect-254]
+	_ = x[ErrParseUnsupportedCase-255]
+	_ = x[ErrParseUnsupportedCaseClause-256]
+	_ = x[ErrParseUnsupportedAlias-257]
+	_ = x[ErrParseUnsupportedSyntax-258]
+	_ = x[ErrParseUnknownOperator-259]
+	_ = x[ErrParseMissingIdentAfterAt-260]
+	_ = x[ErrParseUnexpectedOperator-261]
+	_ = x[ErrParseUnexpectedTerm-262]
+	_ = x[ErrParseUnexpectedToken-263]
+	_ = x[ErrParseUnexpectedKeyword-264]
+	_ = x[ErrParseExpectedExpression-265]
+	_ = x[ErrParseExpectedLeftParenAfterCast-266]
+	_ = x[ErrParseExpectedLeftParenValueConstructor-267]
+	_ = x[ErrParseExpectedLeftParenBuiltinFunctionCall-268]
+	_ = x[ErrParseExpectedArgumentDelimiter-269]
+	_ = x[ErrParseCastArity-270]
+	_ = x[ErrParseInvalidTypeParam-271]
+	_ = x[ErrParseEmptySelect-272]
+	_ = x[ErrParseSelectMissingFrom-273]
+	_ = x[ErrParseExpectedIdentForGroupName-274]
+	_ = x[ErrParseExpectedIdentForAlias-275]
+	_ = x[ErrParseUnsupportedCallWithStar-276]
+	_ = x[ErrParseNonUnaryAgregateFunctionCall-277]
+	_ = x[ErrParseMalformedJoin-278]
+	_ = x[ErrParseExpectedIdentForAt-279]
+	_ = x[ErrParseAsteriskIsNotAloneInSelectList-280]
+	_ = x[ErrParseCannotMixSqbAndWildcardInSelectList-281]
+	_ = x[ErrParseInvalidContextForWildcardInSelectList-282]
+	_ = x[ErrIncorrectSQLFunctionArgumentType-283]
+	_ = x[ErrValueParseFailure-284]
+	_ = x[ErrEvaluatorInvalidArguments-285]
+	_ = x[ErrIntegerOverflow-286]
+	_ = x[ErrLikeInvalidInputs-287]
+	_ = x[ErrCastFailed-288]
+	_ = x[ErrInvalidCast-289]
+	_ = x[ErrEvaluatorInvalidTimestampFormatPattern-290]
+	_ = x[ErrEvaluatorInvalidTimestampFormatPatternSymbolForParsing-291]
+	_ = x[ErrEvaluatorTimestampFormatPatternDuplicateFields-292]
+	_ = x[ErrEvaluatorTimestampFormatPatternHourClockAmPmMismatch-293]
+	_ = x[ErrEvaluatorUnterminatedTimestampFormatPatternToken-294]
+	_ = x[ErrEvaluatorInvalidTimestampFormatPatternToken-295]
+	_ = x[ErrEvaluatorInvalidTimestampFormatPatternSymbol-296]
+	_ = x[ErrEvaluatorBindingDoesNotExist-297]
+	_ = x[ErrMissingHeaders-298]
+	_ = x[ErrInvalidColumnIndex-299]
+	_ = x[ErrAdminConfigNotificationTargetsFailed-300]
+	_ = x[ErrAdminProfilerNotEnabled-301]
+	_ = x[ErrAdminMetadataSinkNotEnabled-302]
+	_ = x[ErrInvalidDecompressedSize-303]
+	_ = x[ErrAddUserInvalidArgument-304]
+	_ = x[ErrAdminAccountNotEligible-305]
+	_ = x[ErrAccountNotEligible-306]
+	_ = x[ErrAdminServiceAccountNotFound-307]
+	_ = x[ErrPostPolicyConditionInvalidFormat-308]
 }
 
-const _APIErrorCode_name = "NoneAccessDeniedBadDigestEntityTooSmallEntityTooLargePolicyTooLargeIncompleteBodyInternalErrorInvalidAccessKeyIDInvalidBucketNameInvalidDigestInvalidRangeInvalidRangePartNumberInvalidCopyPartRangeInvalidCopyPartRangeSourceInvalidMaxKeysInvalidEncodingMethodInvalidMaxUploadsInvalidMaxPartsInvalidPartNumberMarkerInvalidPartNumberInvalidRequestBodyInvalidCopySourceInvalidMetadataDirectiveInvalidCopyDestInvalidPolicyDocumentInvalidObjectStateMalformedXMLMissingContentLengthMissingContentMD5MissingRequestBodyErrorMissingSecurityHeaderNoSuchBucketNoSuchBucketPolicyNoSuchBucketLifecycleNoSuchLifecycleConfigurationNoSuchBucketSSEConfigNoSuchCORSConfigurationNoSuchWebsiteConfigurationReplicationConfigurationNotFoundErrorRemoteDestinationNotFoundErrorReplicationDestinationMissingLockRemoteTargetNotFoundErrorReplicationRemoteConnectionErrorReplicationBandwidthLimitErrorBucketRemoteIdenticalToSourceBucketRemoteAlreadyExistsBucketRemoteLabelInUseBucketRemoteArnTypeInvalidBucketRemoteArnInvalidBucketRemoteRemoveDisallowedRemoteTargetNotVersionedErrorReplicationSourceNotVersionedErrorReplicationNeedsVersioningErrorReplicationBucketNeedsVersioningErrorReplicationNoMatchingRuleErrorObjectRestoreAlreadyInProgressNoSuchKeyNoSuchUploadInvalidVersionIDNoSuchVersionNotImplementedPreconditionFailedRequestTimeTooSkewedSignatureDoesNotMatchMethodNotAllowedInvalidPartInvalidPartOrderAuthorizationHeaderMalformedMalformedPOSTRequestPOSTFileRequiredSignatureVersionNotSupportedBucketNotEmptyAllAccessDisabledMalformedPolicyMissingFieldsMissingCredTagCredMalformedInvalidRegionInvalidServiceS3InvalidServiceSTSInvalidRequestVersionMissingSignTagMissingSignHeadersTagMalformedDateMalformedPresignedDateMalformedCredentialDateMalformedCredentialRegionMalformedExpiresNegativeExpiresAuthHeaderEmptyExpiredPresignRequestRequestNotReadyYetUnsignedHeadersMissingDateHeaderInvalidQuerySignatureAlgoInvalidQueryParamsBucketAlreadyOwnedByYouInvalidDurationBucketAlreadyExistsMetadataTooLargeUnsupportedMetadataMaximumExpiresSlowDownInvalidPrefixMarkerBadRequestKeyTooLongErrorInvalidBucketObjectLockConfigurationObjectLockConfigurationNotFoundObjectLockConfigurationNotAllowedNoSuchObjectLockConfigurationObjectLockedInvalidRetentionDatePastObjectLockRetainDateUnknownWORMModeDirectiveBucketTaggingNotFoundObjectLockInvalidHeadersInvalidTagDirectiveInvalidEncryptionMethodInsecureSSECustomerRequestSSEMultipartEncryptedSSEEncryptedObjectInvalidEncryptionParametersInvalidSSECustomerAlgorithmInvalidSSECustomerKeyMissingSSECustomerKeyMissingSSECustomerKeyMD5SSECustomerKeyMD5MismatchInvalidSSECustomerParametersIncompatibleEncryptionMethodKMSNotConfiguredNoAccessKeyInvalidTokenEventNotificationARNNotificationRegionNotificationOverlappingFilterNotificationFilterNameInvalidFilterNamePrefixFilterNameSuffixFilterValueInvalidOverlappingConfigsUnsupportedNotificationContentSHA256MismatchReadQuorumWriteQuorumStorageFullRequestBodyParseObjectExistsAsDirectoryInvalidObjectNameInvalidObjectNamePrefixSlashInvalidResourceNameServerNotInitializedOperationTimedOutClientDisconnectedOperationMaxedOutInvalidRequestTransitionStorageClassNotFoundErrorInvalidStorageClassBackendDownMalformedJSONAdminNoSuchUserAdminNoSuchGroupAdminGroupNotEmptyAdminNoSuchPolicyAdminInvalidArgumentAdminInvalidAccessKeyAdminInvalidSecretKeyAdminConfigNoQuorumAdminConfigTooLargeAdminConfigBadJSONAdminConfigDuplicateKeysAdminCredentialsMismatchInsecureClientRequestObjectTamperedSiteReplicationInvalidRequestSiteReplicationPeerRespSiteReplicationBackendIssueSiteReplicationServiceAccountErrorSiteReplicationBucketConfigErrorSiteReplicationBucketMetaErrorSiteReplicationIAMErrorAdminBucketQuotaExceededAdminNoSuchQuotaConfigurationHealNotImplementedHealNoSuchProcessHealInvalidClientTokenHealMissingBucketHealAlreadyRunningHealOverlappingPathsIncorrectContinuationTokenEmptyRequestBodyUnsupportedFunctionInvalidExpressionTypeBusyUnauthorizedAccessExpressionTooLongIllegalSQLFunctionArgumentInvalidKeyPathInvalidCompressionFormatInvalidFileHeaderInfoInvalidJSONTypeInvalidQuoteFieldsInvalidRequestParameterInvalidDataTypeInvalidTextEncodingInvalidDataSourceInvalidTableAliasMissingRequiredParameterObjectSerializationConflictUnsupportedSQLOperationUnsupportedSQLStructureUnsupportedSyntaxUnsupportedRangeHeaderLexerInvalidCharLexerInvalidOperatorLexerInvalidLiteralLexerInvalidIONLiteralParseExpectedDatePartParseExpectedKeywordParseExpectedTokenTypeParseExpected2TokenTypesParseExpectedNumberParseExpectedRightParenBuiltinFunctionCallParseExpectedTypeNameParseExpectedWhenClauseParseUnsupportedTokenParseUnsupportedLiteralsGroupByParseExpectedMemberParseUnsupportedSelectParseUnsupportedCaseParseUnsupportedCaseClauseParseUnsupportedAliasParseUnsupportedSyntaxParseUnknownOperatorParseMissingIdentAfterAtParseUnexpectedOperatorParseUnexpectedTermParseUnexpectedTokenParseUnexpectedKeywordParseExpectedExpressionParseExpectedLeftParenAfterCastParseExpectedLeftParenValueConstructorParseExpectedLeftParenBuiltinFunctionCallParseExpectedArgumentDelimiterParseCastArityParseInvalidTypeParamParseEmptySelectParseSelectMissingFromParseExpectedIdentForGroupNameParseExpectedIdentForAliasParseUnsupportedCallWithStarParseNonUnaryAgregateFunctionCallParseMalformedJoinParseExpectedIdentForAtParseAsteriskIsNotAloneInSelectListParseCannotMixSqbAndWildcardInSelectListParseInvalidContextForWildcardInSelectListIncorrectSQLFunctionArgumentTypeValueParseFailureEvaluatorInvalidArgumentsIntegerOverflowLikeInvalidInputsCastFailedInvalidCastEvaluatorInvalidTimestampFormatPatternEvaluatorInvalidTimestampFormatPatternSymbolForParsingEvaluatorTimestampFormatPatternDuplicateFieldsEvaluatorTimestampFormatPatternHourClockAmPmMismatchEvaluatorUnterminatedTimestampFormatPatternTokenEvaluatorInvalidTimestampFormatPatternTokenEvaluatorInvalidTimestampFormatPatternSymbolEvaluatorBindingDoesNotExistMissingHeadersInvalidColumnIndexAdminConfigNotificationTargetsFailedAdminProfilerNotEnabledInvalidDecompressedSizeAddUserInvalidArgumentAdminAccountNotEligibleAccountNotEligibleAdminServiceAccountNotFoundPostPolicyConditionInvalidFormat"
+const _APIErrorCode_name = "NoneAccessDeniedBadDigestEntityTooSmallEntityTooLargePolicyTooLargeIncompleteBodyInternalErrorInvalidAccessKeyIDInvalidBucketNameInvalidDigestInvalidRangeInvalidRangePartNumberInvalidCopyPartRangeInvalidCopyPartRangeSourceInvalidMaxKeysInvalidEncodingMethodInvalidMaxUploadsInvalidMaxPartsInvalidPartNumberMarkerInvalidPartNumberInvalidRequestBodyInvalidCopySourceInvalidMetadataDirectiveInvalidCopyDestInvalidPolicyDocumentInvalidObjectStateMalformedXMLMissingContentLengthMissingContentMD5MissingRequestBodyErrorMissingSecurityHeaderNoSuchBucketNoSuchBucketPolicyNoSuchBucketLifecycleNoSuchLifecycleConfigurationNoSuchBucketSSEConfigNoSuchCORSConfigurationNoSuchWebsiteConfigurationReplicationConfigurationNotFoundErrorRemoteDestinationNotFoundErrorReplicationDestinationMissingLockRemoteTargetNotFoundErrorReplicationRemoteConnectionErrorReplicationBandwidthLimitErrorBucketRemoteIdenticalToSourceBucketRemoteAlreadyExistsBucketRemoteLabelInUseBucketRemoteArnTypeInvalidBucketRemoteArnInvalidBucketRemoteRemoveDisallowedRemoteTargetNotVersionedErrorReplicationSourceNotVersionedErrorReplicationNeedsVersioningErrorReplicationBucketNeedsVersioningErrorReplicationNoMatchingRuleErrorObjectRestoreAlreadyInProgressNoSuchKeyNoSuchUploadInvalidVersionIDNoSuchVersionNotImplementedPreconditionFailedRequestTimeTooSkewedSignatureDoesNotMatchMethodNotAllowedInvalidPartInvalidPartOrderAuthorizationHeaderMalformedMalformedPOSTRequestPOSTFileRequiredSignatureVersionNotSupportedBucketNotEmptyAllAccessDisabledMalformedPolicyMissingFieldsMissingCredTagCredMalformedInvalidRegionInvalidServiceS3InvalidServiceSTSInvalidRequestVersionMissingSignTagMissingSignHeadersTagMalformedDateMalformedPresignedDateMalformedCredentialDateMalformedCredentialRegionMalformedExpiresNegativeExpiresAuthHeaderEmptyExpiredPresignRequestRequestNotReadyYetUnsignedHeadersMissingDateHeaderInvalidQuerySignatureAlgoInvalidQueryParamsBucketAlreadyOwnedByYouInvalidDurationBucketAlreadyExistsMetadataTooLargeUnsupportedMetadataMaximumExpiresSlowDownInvalidPrefixMarkerBadRequestKeyTooLongErrorInvalidBucketObjectLockConfigurationObjectLockConfigurationNotFoundObjectLockConfigurationNotAllowedNoSuchObjectLockConfigurationObjectLockedInvalidRetentionDatePastObjectLockRetainDateUnknownWORMModeDirectiveBucketTaggingNotFoundObjectLockInvalidHeadersInvalidTagDirectiveInvalidEncryptionMethodInsecureSSECustomerRequestSSEMultipartEncryptedSSEEncryptedObjectInvalidEncryptionParametersInvalidSSECustomerAlgorithmInvalidSSECustomerKeyMissingSSECustomerKeyMissingSSECustomerKeyMD5SSECustomerKeyMD5MismatchInvalidSSECustomerParametersIncompatibleEncryptionMethodKMSNotConfiguredNoAccessKeyInvalidTokenEventNotificationARNNotificationRegionNotificationOverlappingFilterNotificationFilterNameInvalidFilterNamePrefixFilterNameSuffixFilterValueInvalidOverlappingConfigsUnsupportedNotificationContentSHA256MismatchReadQuorumWriteQuorumStorageFullRequestBodyParseObjectExistsAsDirectoryInvalidObjectNameInvalidObjectNamePrefixSlashInvalidResourceNameServerNotInitializedServerDrainingBucketSnapshotReadOnlyObjectPinnedBySnapshotBucketArchivedInvalidRenameSourceAppendPositionMismatchInvalidAppendPositionInvalidCommitManifestInvalidComposeRequestTenantQuotaExceededTenantRequestRateExceededInvalidObjectEncodingNoSuchLeaseLeaseHeldStaleFencingTokenObjectQuarantinedMalwareDetectedUploadTokenUsedNoMatchingPoolsNoSuchBucketTemplateBucketWebhookDeniedMetadataIndexNotReadyInvalidMetadataQueryChangelogNotEnabledInvalidChangelogOffsetOperationTimedOutClientDisconnectedOperationMaxedOutInvalidRequestTransitionStorageClassNotFoundErrorInvalidStorageClassBackendDownMalformedJSONAdminNoSuchUserAdminNoSuchGroupAdminGroupNotEmptyAdminNoSuchPolicyAdminInvalidArgumentAdminInvalidAccessKeyAdminInvalidSecretKeyAdminConfigNoQuorumAdminConfigTooLargeAdminConfigBadJSONAdminConfigDuplicateKeysAdminCredentialsMismatchInsecureClientRequestObjectTamperedSiteReplicationInvalidRequestSiteReplicationPeerRespSiteReplicationBackendIssueSiteReplicationServiceAccountErrorSiteReplicationBucketConfigErrorSiteReplicationBucketMetaErrorSiteReplicationIAMErrorAdminBucketQuotaExceededAdminNoSuchQuotaConfigurationHealNotImplementedHealNoSuchProcessHealInvalidClientTokenHealMissingBucketHealAlreadyRunningHealOverlappingPathsIncorrectContinuationTokenEmptyRequestBodyUnsupportedFunctionInvalidExpressionTypeBusyUnauthorizedAccessExpressionTooLongIllegalSQLFunctionArgumentInvalidKeyPathInvalidCompressionFormatInvalidFileHeaderInfoInvalidJSONTypeInvalidQuoteFieldsInvalidRequestParameterInvalidDataTypeInvalidTextEncodingInvalidDataSourceInvalidTableAliasMissingRequiredParameterObjectSerializationConflictUnsupportedSQLOperationUnsupportedSQLStructureUnsupportedSyntaxUnsupportedRangeHeaderLexerInvalidCharLexerInvalidOperatorLexerInvalidLiteralLexerInvalidIONLiteralParseExpectedDatePartParseExpectedKeywordParseExpectedTokenTypeParseExpected2TokenTypesParseExpectedNumberParseExpectedRightParenBuiltinFunctionCallParseExpectedTypeNameParseExpectedWhenClauseParseUnsupportedTokenParseUnsupportedLiteralsGroupByParseExpectedMemberParseUnsupportedSelectParseUnsupportedCaseParseUnsupportedCaseClauseParseUnsupportedAliasParseUnsupportedSyntaxParseUnknownOperatorParseMissingIdentAfterAtParseUnexpectedOperatorParseUnexpectedTermParseUnexpectedTokenParseUnexpectedKeywordParseExpectedExpressionParseExpectedLeftParenAfterCastParseExpectedLeftParenValueConstructorParseExpectedLeftParenBuiltinFunctionCallParseExpectedArgumentDelimiterParseCastArityParseInvalidTypeParamParseEmptySelectParseSelectMissingFromParseExpectedIdentForGroupNameParseExpectedIdentForAliasParseUnsupportedCallWithStarParseNonUnaryAgregateFunctionCallParseMalformedJoinParseExpectedIdentForAtParseAsteriskIsNotAloneInSelectListParseCannotMixSqbAndWildcardInSelectListParseInvalidContextForWildcardInSelectListIncorrectSQLFunctionArgumentTypeValueParseFailureEvaluatorInvalidArgumentsIntegerOverflowLikeInvalidInputsCastFailedInvalidCastEvaluatorInvalidTimestampFormatPatternEvaluatorInvalidTimestampFormatPatternSymbolForParsingEvaluatorTimestampFormatPatternDuplicateFieldsEvaluatorTimestampFormatPatternHourClockAmPmMismatchEvaluatorUnterminatedTimestampFormatPatternTokenEvaluatorInvalidTimestampFormatPatternTokenEvaluatorInvalidTimestampFormatPatternSymbolEvaluatorBindingDoesNotExistMissingHeadersInvalidColumnIndexAdminConfigNotificationTargetsFailedAdminProfilerNotEnabledAdminMetadataSinkNotEnabledInvalidDecompressedSizeAddUserInvalidArgumentAdminAccountNotEligibleAccountNotEligibleAdminServiceAccountNotFoundPostPolicyConditionInvalidFormat"
 
-var _APIErrorCode_index = [...]uint16{0, 4, 16, 25, 39, 53, 67, 81, 94, 112, 129, 142, 154, 176, 196, 222, 236, 257, 274, 289, 312, 329, 347, 364, 388, 403, 424, 442, 454, 474, 491, 514, 535, 547, 565, 586, 614, 635, 658, 684, 721, 751, 784, 809, 841, 871, 900, 925, 947, 973, 995, 1023, 1052, 1086, 1117, 1154, 1184, 1214, 1223, 1235, 1251, 1264, 1278, 1296, 1316, 1337, 1353, 1364, 1380, 1408, 1428, 1444, 1472, 1486, 1503, 1518, 1531, 1545, 1558, 1571, 1587, 1604, 1625, 1639, 1660, 1673, 1695, 1718, 1743, 1759, 1774, 1789, 1810, 1828, 1843, 1860, 1885, 1903, 1926, 1941, 1960, 1976, 1995, 2009, 2017, 2036, 2046, 2061, 2097, 2128, 2161, 2190, 2202, 2222, 2246, 2270, 2291, 2315, 2334, 2357, 2383, 2404, 2422, 2449, 2476, 2497, 2518, 2542, 2567, 2595, 2623, 2639, 2650, 2662, 2679, 2694, 2712, 2741, 2758, 2774, 2790, 2808, 2826, 2849, 2870, 2880, 2891, 2902, 2918, 2941, 2958, 2986, 3005, 3025, 3042, 3060, 3077, 3091, 3126, 3145, 3156, 3169, 3184, 3200, 3218, 3235, 3255, 3276, 3297, 3316, 3335, 3353, 3377, 3401, 3422, 3436, 3465, 3488, 3515, 3549, 3581, 3611, 3634, 3658, 3687, 3705, 3722, 3744, 3761, 3779, 3799, 3825, 3841, 3860, 3881, 3885, 3903, 3920, 3946, 3960, 3984, 4005, 4020, 4038, 4061, 4076, 4095, 4112, 4129, 4153, 4180, 4203, 4226, 4243, 4265, 4281, 4301, 4320, 4342, 4363, 4383, 4405, 4429, 4448, 4490, 4511, 4534, 4555, 4586, 4605, 4627, 4647, 4673, 4694, 4716, 4736, 4760, 4783, 4802, 4822, 4844, 4867, 4898, 4936, 4977, 5007, 5021, 5042, 5058, 5080, 5110, 5136, 5164, 5197, 5215, 5238, 5273, 5313, 5355, 5387, 5404, 5429, 5444, 5461, 5471, 5482, 5520, 5574, 5620, 5672, 5720, 5763, 5807, 5835, 5849, 5867, 5903, 5926, 5949, 5971, 5994, 6012, 6039, 6071}
+var _APIErrorCode_index = [...]uint16{0, 4, 16, 25, 39, 53, 67, 81, 94, 112, 129, 142, 154, 176, 196, 222, 236, 257, 274, 289, 312, 329, 347, 364, 388, 403, 424, 442, 454, 474, 491, 514, 535, 547, 565, 586, 614, 635, 658, 684, 721, 751, 784, 809, 841, 871, 900, 925, 947, 973, 995, 1023, 1052, 1086, 1117, 1154, 1184, 1214, 1223, 1235, 1251, 1264, 1278, 1296, 1316, 1337, 1353, 1364, 1380, 1408, 1428, 1444, 1472, 1486, 1503, 1518, 1531, 1545, 1558, 1571, 1587, 1604, 1625, 1639, 1660, 1673, 1695, 1718, 1743, 1759, 1774, 1789, 1810, 1828, 1843, 1860, 1885, 1903, 1926, 1941, 1960, 1976, 1995, 2009, 2017, 2036, 2046, 2061, 2097, 2128, 2161, 2190, 2202, 2222, 2246, 2270, 2291, 2315, 2334, 2357, 2383, 2404, 2422, 2449, 2476, 2497, 2518, 2542, 2567, 2595, 2623, 2639, 2650, 2662, 2679, 2694, 2712, 2741, 2758, 2774, 2790, 2808, 2826, 2849, 2870, 2880, 2891, 2902, 2918, 2941, 2958, 2986, 3005, 3025, 3039, 3061, 3083, 3097, 3116, 3138, 3159, 3180, 3201, 3220, 3245, 3266, 3277, 3286, 3303, 3320, 3335, 3350, 3365, 3385, 3404, 3425, 3445, 3464, 3486, 3503, 3521, 3538, 3552, 3587, 3606, 3617, 3630, 3645, 3661, 3679, 3696, 3716, 3737, 3758, 3777, 3796, 3814, 3838, 3862, 3883, 3897, 3926, 3949, 3976, 4010, 4042, 4072, 4095, 4119, 4148, 4166, 4183, 4205, 4222, 4240, 4260, 4286, 4302, 4321, 4342, 4346, 4364, 4381, 4407, 4421, 4445, 4466, 4481, 4499, 4522, 4537, 4556, 4573, 4590, 4614, 4641, 4664, 4687, 4704, 4726, 4742, 4762, 4781, 4803, 4824, 4844, 4866, 4890, 4909, 4951, 4972, 4995, 5016, 5047, 5066, 5088, 5108, 5134, 5155, 5177, 5197, 5221, 5244, 5263, 5283, 5305, 5328, 5359, 5397, 5438, 5468, 5482, 5503, 5519, 5541, 5571, 5597, 5625, 5658, 5676, 5699, 5734, 5774, 5816, 5848, 5865, 5890, 5905, 5922, 5932, 5943, 5981, 6035, 6081, 6133, 6181, 6224, 6268, 6296, 6310, 6328, 6364, 6387, 6414, 6437, 6459, 6482, 6500, 6527, 6559}
 
 func (i APIErrorCode) String() string {
 	if i < 0 || i >= APIErrorCode(len(_APIErrorCode_index)-1) {
@@ -0,0 +1,211 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/cli"
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/dsync"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/certs"
+	"github.com/minio/pkg/env"
+	"github.com/minio/pkg/set"
+)
+
+var (
+	// globalArbiter is the locker of the arbiter of this deployment,
+	// set when MINIO_ARBITER is configured.
+	globalArbiter dsync.NetLocker
+
+	// globalIsArbiter is set when this process is an arbiter.
+	globalIsArbiter bool
+)
+
+var arbiterCmd = cli.Command{
+	Name:  "arbiter",
+	Usage: "start an arbiter breaking the quorum ties of a two node deployment",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "address",
+			Value: ":" + GlobalMinioDefaultPort,
+			Usage: "bind to a specific ADDRESS:PORT, ADDRESS can be an IP or hostname",
+		},
+	}, GlobalFlags...),
+	Action: arbiterMain,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} {{if .VisibleFlags}}[FLAGS]{{end}}
+{{if .VisibleFlags}}
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}{{end}}
+EXAMPLES:
+  1. Start an arbiter for the nodes "node1" and "node2" on "arbiter.example.com".
+     {{.Prompt}} {{.EnvVarSetCommand}} MINIO_ROOT_USER{{.AssignmentOperator}}minio
+     {{.Prompt}} {{.EnvVarSetCommand}} MINIO_ROOT_PASSWORD{{.AssignmentOperator}}miniostorage
+     {{.Prompt}} {{.HelpName}} --address :9000
+
+     Start the nodes with the same credentials and the arbiter URL.
+     {{.Prompt}} {{.EnvVarSetCommand}} MINIO_ARBITER{{.AssignmentOperator}}http://arbiter.example.com:9000
+     {{.Prompt}} minio server http://node{1...2}.example.com/mnt/export{1...4}
+`,
+}
+
+// parseArbiterURL parses the URL of the arbiter, its scheme must match
+// the scheme of the endpoints.
+func parseArbiterURL(v string, https bool) (*url.URL, error) {
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q, expected http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	if u.Path != "" && u.Path != SlashSeparator {
+		return nil, errors.New("path is not supported")
+	}
+	if (u.Scheme == "https") != https {
+		return nil, errors.New("scheme does not match the scheme of the endpoints")
+	}
+	return &url.URL{Scheme: u.Scheme, Host: u.Host}, nil
+}
+
+// endpointHostsCount returns the number of distinct hosts of endpoints.
+func endpointHostsCount(endpoints EndpointServerPools) int {
+	hosts := set.NewStringSet()
+	for _, ep := range endpoints {
+		for _, endpoint := range ep.Endpoints {
+			hosts.Add(endpoint.Host)
+		}
+	}
+	return len(hosts)
+}
+
+// initArbiter sets up the locker of the arbiter configured by
+// MINIO_ARBITER. The arbiter only breaks ties of distributed setups
+// of two nodes, with more nodes a majority exists without it.
+func initArbiter(endpoints EndpointServerPools) {
+	v := env.Get(config.EnvArbiter, "")
+	if v == "" {
+		return
+	}
+	if !globalIsDistErasure || endpointHostsCount(endpoints) != 2 {
+		logger.Fatal(errors.New("an arbiter requires a distributed setup of two nodes"), "Unable to configure the arbiter")
+	}
+	u, err := parseArbiterURL(v, endpoints.HTTPS())
+	logger.FatalIf(err, "Invalid %s value in environment variable", config.EnvArbiter)
+	globalArbiter = newlockRESTClient(Endpoint{URL: u})
+}
+
+// checkArbiterCredentials rejects the default credentials for the lock
+// RPCs of the nodes and the arbiter, anyone knowing them could take the
+// locks of the deployment through the arbiter.
+func checkArbiterCredentials(cred auth.Credentials) error {
+	if !cred.IsValid() || cred.Equal(auth.DefaultCredentials) {
+		return errors.New("an arbiter requires root credentials other than the default credentials")
+	}
+	return nil
+}
+
+// arbiterHealthHandler reports the arbiter alive and ready as long as
+// it serves requests.
+func arbiterHealthHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, http.StatusOK, nil, mimeNone)
+}
+
+// configureArbiterHandler returns the handler of the arbiter, serving
+// the lock REST API and the liveness and readiness checks only.
+func configureArbiterHandler() http.Handler {
+	router := mux.NewRouter().SkipClean(true).UseEncodedPath()
+	registerLockRESTHandlers(router)
+
+	healthRouter := router.PathPrefix(healthCheckPathPrefix).Subrouter()
+	for _, path := range []string{healthCheckLivenessPath, healthCheckReadinessPath} {
+		healthRouter.Methods(http.MethodGet, http.MethodHead).Path(path).HandlerFunc(httpTraceAll(arbiterHealthHandler))
+	}
+	return criticalErrorHandler{router}
+}
+
+// arbiterMain starts an arbiter, a process holding no data which takes
+// part in the lock quorum of the nodes of a two node deployment. Of two
+// partitioned nodes only the one reaching the arbiter acquires locks.
+func arbiterMain(ctx *cli.Context) {
+	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+
+	go handleSignals()
+
+	globalConsoleSys = NewConsoleLogger(GlobalContext)
+	logger.AddTarget(globalConsoleSys)
+
+	handleCommonCmdArgs(ctx)
+
+	logger.FatalIf(CheckLocalServerAddr(globalMinioAddr), "Unable to validate passed arguments")
+
+	var err error
+	globalPublicCerts, globalTLSCerts, globalIsTLS, err = getTLSConfig()
+	logger.FatalIf(err, "Unable to load the TLS configuration")
+
+	logger.FatalIf(checkPortAvailability(globalMinioHost, globalMinioPort), "Unable to start the arbiter")
+
+	handleCommonEnvVars()
+
+	// The nodes authenticate with the root credentials.
+	logger.FatalIf(checkArbiterCredentials(globalActiveCred), "Unable to start the arbiter")
+
+	globalIsArbiter = true
+
+	var getCert certs.GetCertificateFunc
+	if globalTLSCerts != nil {
+		getCert = getTLSCertificate
+	}
+
+	httpServer := xhttp.NewServer([]string{globalMinioAddr}, configureArbiterHandler(), getCert)
+	httpServer.BaseContext = func(listener net.Listener) context.Context {
+		return GlobalContext
+	}
+	// Turn-off random logging by Go internally
+	httpServer.ErrorLog = log.New(&nullWriter{}, "", 0)
+	go func() {
+		globalHTTPServerErrorCh <- httpServer.Start(GlobalContext)
+	}()
+
+	setHTTPServer(httpServer)
+
+	logStartupMessage(fmt.Sprintf("Arbiter listening on %s", globalMinioAddr))
+
+	<-globalOSSignalCh
+}
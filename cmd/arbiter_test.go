@@ -0,0 +1,67 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/auth"
+)
+
+func TestCheckArbiterCredentials(t *testing.T) {
+	testCases := []struct {
+		cred    auth.Credentials
+		success bool
+	}{
+		{cred: auth.Credentials{}},
+		{cred: auth.DefaultCredentials},
+		{cred: auth.Credentials{AccessKey: "arbiter", SecretKey: "arbitersecret"}, success: true},
+	}
+	for i, testCase := range testCases {
+		if err := checkArbiterCredentials(testCase.cred); (err == nil) != testCase.success {
+			t.Errorf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+	}
+}
+
+func TestParseArbiterURL(t *testing.T) {
+	testCases := []struct {
+		v       string
+		https   bool
+		host    string
+		success bool
+	}{
+		{v: "http://arbiter:9000", host: "arbiter:9000", success: true},
+		{v: "http://arbiter:9000/", host: "arbiter:9000", success: true},
+		{v: "https://arbiter:9000", https: true, host: "arbiter:9000", success: true},
+		{v: "https://arbiter:9000"},
+		{v: "http://arbiter:9000", https: true},
+		{v: "ftp://arbiter:9000"},
+		{v: "http://arbiter:9000/export"},
+		{v: "arbiter:9000"},
+	}
+	for i, testCase := range testCases {
+		u, err := parseArbiterURL(testCase.v, testCase.https)
+		if (err == nil) != testCase.success {
+			t.Fatalf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+		if err == nil && u.Host != testCase.host {
+			t.Errorf("Test %d: expected host %s, got %s", i+1, testCase.host, u.Host)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio/internal/logger/target/file"
+	xnet "github.com/minio/pkg/net"
+)
+
+// newAuditSegmentUploader returns the function delivering closed audit
+// segments of cfg to its audit bucket, nil if uploads are not configured.
+func newAuditSegmentUploader(cfg file.Config) (file.UploadFn, error) {
+	if cfg.UploadEndpoint == "" {
+		return nil, nil
+	}
+	u, err := xnet.ParseHTTPURL(cfg.UploadEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:     credentials.NewStaticV4(cfg.UploadAccessKey, cfg.UploadSecretKey, ""),
+		Secure:    u.Scheme == "https",
+		Transport: NewRemoteTargetHTTPTransport(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	bucket := cfg.UploadBucket
+	return func(ctx context.Context, object string, r io.Reader, size int64) error {
+		opts := minio.PutObjectOptions{
+			ContentType: "application/x-ndjson",
+		}
+		if strings.HasSuffix(object, ".gz") {
+			opts.ContentEncoding = "gzip"
+		}
+		if cfg.UploadRetentionMode != "" {
+			opts.Mode = minio.RetentionMode(cfg.UploadRetentionMode)
+			opts.RetainUntilDate = UTCNow().Add(time.Duration(cfg.UploadRetentionDays) * 24 * time.Hour)
+		}
+		_, err := client.PutObject(ctx, bucket, object, r, size, opts)
+		return err
+	}, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"github.com/minio/minio/internal/auth"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+// clientCertIdentity is the identity of a request authenticated by its
+// client certificate, an IAM user or a set of policies.
+type clientCertIdentity struct {
+	cred     auth.Credentials
+	policies []string
+}
+
+// isAllowed checks args against the IAM user or the policies of the
+// identity.
+func (id clientCertIdentity) isAllowed(args iampolicy.Args) bool {
+	if len(id.policies) == 0 {
+		return globalIAMSys.IsAllowed(args)
+	}
+	return globalIAMSys.IsAllowedPolicies(args, id.policies)
+}
+
+// clientCertLeaf returns the leaf certificate of the client of state
+// once its chain is verified. Only the first peer certificate is proven
+// to be held by the client in the handshake, the others may only link it
+// to a trusted root.
+func clientCertLeaf(state *tls.ConnectionState, roots *x509.CertPool, skipVerify bool) *x509.Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	if len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+		// Verified by the handshake already.
+		if leaf := state.VerifiedChains[0][0]; !leaf.IsCA {
+			return leaf
+		}
+		return nil
+	}
+
+	leaf := state.PeerCertificates[0]
+	if leaf.IsCA {
+		return nil
+	}
+	if !skipVerify {
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			KeyUsages: []x509.ExtKeyUsage{
+				x509.ExtKeyUsageClientAuth,
+			},
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			return nil
+		}
+	}
+	return leaf
+}
+
+// getClientCertIdentity returns the identity mapped from the client
+// certificate of r by the client auth rules. Requests without a verified
+// leaf certificate matching a rule stay anonymous.
+func getClientCertIdentity(r *http.Request) (clientCertIdentity, bool) {
+	if !globalSTSTLSConfig.ClientAuth || r.TLS == nil {
+		return clientCertIdentity{}, false
+	}
+
+	certificate := clientCertLeaf(r.TLS, globalRootCAs, globalSTSTLSConfig.InsecureSkipVerify)
+	if certificate == nil {
+		return clientCertIdentity{}, false
+	}
+
+	rule, value, ok := globalSTSTLSConfig.Match(certificate)
+	if !ok {
+		return clientCertIdentity{}, false
+	}
+	if rule.User != "" {
+		cred, ok := globalIAMSys.GetUser(rule.User)
+		if !ok || !cred.IsValid() || cred.IsTemp() || cred.IsServiceAccount() {
+			return clientCertIdentity{}, false
+		}
+		// Policies of the groups of the user apply as for signed requests.
+		info, err := globalIAMSys.GetUserInfo(rule.User)
+		if err != nil {
+			return clientCertIdentity{}, false
+		}
+		cred.Groups = info.MemberOf
+		return clientCertIdentity{cred: cred}, true
+	}
+	return clientCertIdentity{
+		cred: auth.Credentials{
+			AccessKey:  "tls:" + value,
+			ParentUser: "tls:" + value,
+			Status:     auth.AccountOn,
+		},
+		policies: rule.Policies,
+	}, true
+}
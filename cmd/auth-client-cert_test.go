@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestClientCert(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(crand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertLeaf(t *testing.T) {
+	ca, caKey := newTestClientCert(t, "ca", true, nil, nil)
+	alice, _ := newTestClientCert(t, "alice", false, ca, caKey)
+	bob, _ := newTestClientCert(t, "bob", false, ca, caKey)
+	mallory, _ := newTestClientCert(t, "mallory", false, nil, nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	testCases := []struct {
+		name       string
+		state      *tls.ConnectionState
+		skipVerify bool
+		want       *x509.Certificate
+	}{
+		{
+			name:  "no state",
+			state: nil,
+		},
+		{
+			name:  "no certificates",
+			state: &tls.ConnectionState{},
+		},
+		{
+			name:  "leaf",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{alice}},
+			want:  alice,
+		},
+		{
+			name:  "leaf and CA",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{alice, ca}},
+			want:  alice,
+		},
+		{
+			// An appended certificate never becomes the identity.
+			name:  "leaf and appended leaf",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{alice, bob}},
+			want:  alice,
+		},
+		{
+			name:  "untrusted leaf and appended trusted leaf",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{mallory, bob}},
+		},
+		{
+			name:       "untrusted leaf without verification",
+			state:      &tls.ConnectionState{PeerCertificates: []*x509.Certificate{mallory, bob}},
+			skipVerify: true,
+			want:       mallory,
+		},
+		{
+			name:  "CA only",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{ca}},
+		},
+		{
+			name: "verified by handshake",
+			state: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{bob, alice},
+				VerifiedChains:   [][]*x509.Certificate{{bob, ca}},
+			},
+			want: bob,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := clientCertLeaf(testCase.state, roots, testCase.skipVerify)
+			if got != testCase.want {
+				var gotCN, wantCN string
+				if got != nil {
+					gotCN = got.Subject.CommonName
+				}
+				if testCase.want != nil {
+					wantCN = testCase.want.Subject.CommonName
+				}
+				t.Fatalf("expected leaf %q, got %q", wantCN, gotCN)
+			}
+		})
+	}
+}
@@ -290,7 +290,23 @@ func checkRequestAuthType(ctx context.Context, r *http.Request, action policy.Ac
 // returns APIErrorCode if any to be replied to the client.
 // Additionally returns the accessKey used in the request, and if this request is by an admin.
 func checkRequestAuthTypeCredential(ctx context.Context, r *http.Request, action policy.Action, bucketName, objectName string) (cred auth.Credentials, owner bool, s3Err APIErrorCode) {
+	start := time.Now()
+	defer func() {
+		requestTimingsFromContext(ctx).authDone(start, cred.AccessKey)
+		if s3Err == ErrNone && !globalTenantSys.chargeRequest(ctx) {
+			s3Err = ErrTenantRequestRateExceeded
+		}
+		if s3Err == ErrNone {
+			authorizeAccessAnomaly(ctx, cred)
+		}
+	}()
+
+	isAllowed := globalIAMSys.IsAllowed
 	switch getRequestAuthType(r) {
+	case authTypeAnonymous:
+		if id, ok := getClientCertIdentity(r); ok {
+			cred, isAllowed = id.cred, id.isAllowed
+		}
 	case authTypeUnknown, authTypeStreamingSigned:
 		return cred, owner, ErrSignatureVersionNotSupported
 	case authTypePresignedV2, authTypeSignedV2:
@@ -312,6 +328,9 @@ func checkRequestAuthTypeCredential(ctx context.Context, r *http.Request, action
 	if s3Err != ErrNone {
 		return cred, owner, s3Err
 	}
+	if s3Err = checkUploadTokenAPI(ctx, cred); s3Err != ErrNone {
+		return cred, owner, s3Err
+	}
 
 	// LocationConstraint is valid only for CreateBucketAction.
 	var locationConstraint string
@@ -372,7 +391,7 @@ func checkRequestAuthTypeCredential(ctx context.Context, r *http.Request, action
 		return cred, owner, ErrAccessDenied
 	}
 
-	if globalIAMSys.IsAllowed(iampolicy.Args{
+	if isAllowed(iampolicy.Args{
 		AccountName:     cred.AccessKey,
 		Groups:          cred.Groups,
 		Action:          iampolicy.Action(action),
@@ -389,7 +408,7 @@ func checkRequestAuthTypeCredential(ctx context.Context, r *http.Request, action
 	if action == policy.ListBucketVersionsAction {
 		// In AWS S3 s3:ListBucket permission is same as s3:ListBucketVersions permission
 		// verify as a fallback.
-		if globalIAMSys.IsAllowed(iampolicy.Args{
+		if isAllowed(iampolicy.Args{
 			AccountName:     cred.AccessKey,
 			Groups:          cred.Groups,
 			Action:          iampolicy.ListBucketAction,
@@ -487,7 +506,8 @@ func setAuthHandler(h http.Handler) http.Handler {
 	// handler for validating incoming authorization headers.
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		aType := getRequestAuthType(r)
-		if isSupportedS3AuthType(aType) || aType == authTypeJWT || aType == authTypeSTS {
+		// The status page authenticates its requests with HTTP basic auth.
+		if isSupportedS3AuthType(aType) || aType == authTypeJWT || aType == authTypeSTS || guessIsStatusPageReq(r) {
 			h.ServeHTTP(w, r)
 			return
 		}
@@ -570,7 +590,23 @@ func isPutRetentionAllowed(bucketName, objectName string, retDays int, retDate t
 func isPutActionAllowed(ctx context.Context, atype authType, bucketName, objectName string, r *http.Request, action iampolicy.Action) (s3Err APIErrorCode) {
 	var cred auth.Credentials
 	var owner bool
+	start := time.Now()
+	defer func() {
+		requestTimingsFromContext(ctx).authDone(start, cred.AccessKey)
+		if s3Err == ErrNone && !globalTenantSys.chargeRequest(ctx) {
+			s3Err = ErrTenantRequestRateExceeded
+		}
+		if s3Err == ErrNone {
+			authorizeAccessAnomaly(ctx, cred)
+		}
+	}()
+
+	isAllowed := globalIAMSys.IsAllowed
 	switch atype {
+	case authTypeAnonymous:
+		if id, ok := getClientCertIdentity(r); ok {
+			cred, isAllowed = id.cred, id.isAllowed
+		}
 	case authTypeUnknown:
 		return ErrSignatureVersionNotSupported
 	case authTypeSignedV2, authTypePresignedV2:
@@ -582,6 +618,9 @@ func isPutActionAllowed(ctx context.Context, atype authType, bucketName, objectN
 	if s3Err != ErrNone {
 		return s3Err
 	}
+	if s3Err = checkUploadTokenAPI(ctx, cred); s3Err != ErrNone {
+		return s3Err
+	}
 
 	if cred.AccessKey != "" {
 		logger.GetReqInfo(ctx).AccessKey = cred.AccessKey
@@ -611,7 +650,7 @@ func isPutActionAllowed(ctx context.Context, atype authType, bucketName, objectN
 		return ErrAccessDenied
 	}
 
-	if globalIAMSys.IsAllowed(iampolicy.Args{
+	if isAllowed(iampolicy.Args{
 		AccountName:     cred.AccessKey,
 		Groups:          cred.Groups,
 		Action:          action,
@@ -337,8 +337,9 @@ func monitorLocalDisksAndHeal(ctx context.Context, z *erasureServerPools, bgSeq
 				logger.Info(fmt.Sprintf("Found drives to heal %d, proceeding to heal content...",
 					len(healDisks)))
 
-				erasureSetInPoolDisksToHeal = make([]map[int][]StorageAPI, len(z.serverPools))
-				for i := range z.serverPools {
+				pools := z.currentPools()
+				erasureSetInPoolDisksToHeal = make([]map[int][]StorageAPI, len(pools))
+				for i := range pools {
 					erasureSetInPoolDisksToHeal[i] = map[int][]StorageAPI{}
 				}
 			}
@@ -356,15 +357,16 @@ func monitorLocalDisksAndHeal(ctx context.Context, z *erasureServerPools, bgSeq
 				}
 
 				poolIdx := globalEndpoints.GetLocalPoolIdx(disk.Endpoint())
-				if poolIdx < 0 {
+				if poolIdx < 0 || poolIdx >= len(erasureSetInPoolDisksToHeal) {
 					continue
 				}
 
 				// Calculate the set index where the current endpoint belongs
-				z.serverPools[poolIdx].erasureDisksMu.RLock()
+				pool := z.currentPools()[poolIdx]
+				pool.erasureDisksMu.RLock()
 				// Protect reading reference format.
-				setIndex, _, err := findDiskIndex(z.serverPools[poolIdx].format, format)
-				z.serverPools[poolIdx].erasureDisksMu.RUnlock()
+				setIndex, _, err := findDiskIndex(pool.format, format)
+				pool.erasureDisksMu.RUnlock()
 				if err != nil {
 					printEndpointError(endpoint, err, false)
 					continue
@@ -418,7 +420,7 @@ func monitorLocalDisksAndHeal(ctx context.Context, z *erasureServerPools, bgSeq
 
 							// Load bucket totals
 							cache := dataUsageCache{}
-							if err := cache.load(ctx, z.serverPools[i].sets[setIndex], dataUsageCacheName); err == nil {
+							if err := cache.load(ctx, z.currentPools()[i].sets[setIndex], dataUsageCacheName); err == nil {
 								dataUsageInfo := cache.dui(dataUsageRoot, nil)
 								tracker.ObjectsTotalCount = dataUsageInfo.ObjectsTotalCount
 								tracker.ObjectsTotalSize = dataUsageInfo.ObjectsTotalSize
@@ -434,9 +436,19 @@ func monitorLocalDisksAndHeal(ctx context.Context, z *erasureServerPools, bgSeq
 								return
 							}
 
-							err = z.serverPools[i].sets[setIndex].healErasureSet(ctx, buckets, tracker)
+							// Healing interrupted by the end of its window or by a
+							// job of higher priority resumes from the tracker.
+							jctx, release, err := globalJobScheduler.acquire(ctx, jobHeal)
 							if err != nil {
-								logger.LogIf(ctx, err)
+								return
+							}
+							err = z.currentPools()[i].sets[setIndex].healErasureSet(jctx, buckets, tracker)
+							interrupted := jctx.Err() != nil
+							release()
+							if err != nil {
+								if !interrupted {
+									logger.LogIf(ctx, err)
+								}
 								continue
 							}
 
@@ -0,0 +1,546 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	mrand "math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/minio/minio/internal/hash"
+)
+
+// Benchmark objects are created under this prefix of the meta bucket.
+const minioMetaBenchmarkPrefix = "benchmark/"
+
+// Benchmark operations.
+const (
+	benchOpGet = iota
+	benchOpPut
+	benchOpList
+	benchOpDelete
+	benchOpCount
+)
+
+var benchOpNames = [benchOpCount]string{"GET", "PUT", "LIST", "DELETE"}
+
+// BenchmarkMix is the relative weight of each operation.
+type BenchmarkMix struct {
+	Get    int `json:"get"`
+	Put    int `json:"put"`
+	List   int `json:"list"`
+	Delete int `json:"delete"`
+}
+
+func (m BenchmarkMix) weights() [benchOpCount]int {
+	return [benchOpCount]int{m.Get, m.Put, m.List, m.Delete}
+}
+
+// Predefined operation mixes.
+var benchmarkProfiles = map[string]BenchmarkMix{
+	"mixed":       {Get: 45, Put: 25, List: 10, Delete: 20},
+	"read-heavy":  {Get: 90, Put: 5, List: 5},
+	"write-heavy": {Get: 20, Put: 70, Delete: 10},
+	"list-heavy":  {Get: 10, Put: 10, List: 80},
+}
+
+// Defaults of a benchmark run.
+const (
+	defaultBenchmarkProfile    = "mixed"
+	defaultBenchmarkSizes      = "4KiB:40,1MiB:50,16MiB:10"
+	defaultBenchmarkDuration   = 30 * time.Second
+	defaultBenchmarkConcurrent = 32
+	defaultBenchmarkObjects    = 100
+
+	// maxBenchmarkWeight bounds each weight so that their sum
+	// cannot overflow.
+	maxBenchmarkWeight = 1 << 20
+)
+
+// BenchmarkSize is an object size along with its relative weight.
+type BenchmarkSize struct {
+	Size   int64 `json:"size"`
+	Weight int   `json:"weight"`
+}
+
+// BenchmarkOpts are the parameters of a benchmark run on each node.
+type BenchmarkOpts struct {
+	Profile    string          `json:"profile"`
+	Mix        BenchmarkMix    `json:"mix"`
+	Sizes      []BenchmarkSize `json:"sizes"`
+	Duration   time.Duration   `json:"duration"`
+	Concurrent int             `json:"concurrent"`
+	// Objects uploaded on each node before the run, read,
+	// listed and deleted by the benchmark.
+	Objects int `json:"objects"`
+}
+
+// parseBenchmarkSizes parses a list of size:weight pairs such as
+// "4KiB:50,1MiB:40,64MiB:10", the weight defaults to 1.
+func parseBenchmarkSizes(s string) ([]BenchmarkSize, error) {
+	var sizes []BenchmarkSize
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		sizeStr, weightStr := entry, "1"
+		if i := strings.LastIndex(entry, ":"); i >= 0 {
+			sizeStr, weightStr = entry[:i], entry[i+1:]
+		}
+		size, err := humanize.ParseBytes(sizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object size %q: %w", sizeStr, err)
+		}
+		weight, err := strconv.Atoi(weightStr)
+		if err != nil || weight < 0 || weight > maxBenchmarkWeight {
+			return nil, fmt.Errorf("invalid weight %q for size %s", weightStr, sizeStr)
+		}
+		sizes = append(sizes, BenchmarkSize{Size: int64(size), Weight: weight})
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("no object size given")
+	}
+	if totalBenchmarkWeight(benchmarkSizeWeights(sizes)) == 0 {
+		return nil, fmt.Errorf("no object size with a weight given")
+	}
+	return sizes, nil
+}
+
+func benchmarkSizeWeights(sizes []BenchmarkSize) []int {
+	weights := make([]int, len(sizes))
+	for i, s := range sizes {
+		weights[i] = s.Weight
+	}
+	return weights
+}
+
+// totalBenchmarkWeight returns the sum of weights, -1 if any of them
+// is out of bounds.
+func totalBenchmarkWeight(weights []int) int {
+	total := 0
+	for _, w := range weights {
+		if w < 0 || w > maxBenchmarkWeight {
+			return -1
+		}
+		total += w
+	}
+	return total
+}
+
+// validate checks opts received from a peer, parseBenchmarkOpts
+// returns valid options.
+func (opts BenchmarkOpts) validate() error {
+	weights := opts.Mix.weights()
+	if totalBenchmarkWeight(weights[:]) <= 0 {
+		return fmt.Errorf("invalid operation weights %v", opts.Mix)
+	}
+	if totalBenchmarkWeight(benchmarkSizeWeights(opts.Sizes)) <= 0 {
+		return fmt.Errorf("invalid object size weights %v", opts.Sizes)
+	}
+	if opts.Duration <= 0 || opts.Concurrent <= 0 || opts.Objects < 0 {
+		return fmt.Errorf("invalid benchmark options %+v", opts)
+	}
+	return nil
+}
+
+// parseBenchmarkOpts returns the benchmark parameters from the query.
+func parseBenchmarkOpts(form url.Values) (opts BenchmarkOpts, err error) {
+	opts = BenchmarkOpts{
+		Profile:    form.Get("profile"),
+		Duration:   defaultBenchmarkDuration,
+		Concurrent: defaultBenchmarkConcurrent,
+		Objects:    defaultBenchmarkObjects,
+	}
+	if opts.Profile == "" {
+		opts.Profile = defaultBenchmarkProfile
+	}
+	mix, ok := benchmarkProfiles[opts.Profile]
+	if !ok {
+		return opts, fmt.Errorf("unknown benchmark profile %s", opts.Profile)
+	}
+	for _, w := range []struct {
+		name   string
+		weight *int
+	}{{"get", &mix.Get}, {"put", &mix.Put}, {"list", &mix.List}, {"delete", &mix.Delete}} {
+		if v := form.Get(w.name); v != "" {
+			if *w.weight, err = strconv.Atoi(v); err != nil || *w.weight < 0 || *w.weight > maxBenchmarkWeight {
+				return opts, fmt.Errorf("invalid %s weight %q", w.name, v)
+			}
+		}
+	}
+	if mix.Get+mix.Put+mix.List+mix.Delete == 0 {
+		return opts, fmt.Errorf("no operation to benchmark")
+	}
+	opts.Mix = mix
+
+	sizes := form.Get("sizes")
+	if sizes == "" {
+		sizes = defaultBenchmarkSizes
+	}
+	if opts.Sizes, err = parseBenchmarkSizes(sizes); err != nil {
+		return opts, err
+	}
+
+	if v := form.Get("duration"); v != "" {
+		if opts.Duration, err = time.ParseDuration(v); err != nil || opts.Duration <= 0 {
+			return opts, fmt.Errorf("invalid duration %q", v)
+		}
+	}
+	if v := form.Get("concurrent"); v != "" {
+		if opts.Concurrent, err = strconv.Atoi(v); err != nil || opts.Concurrent <= 0 {
+			return opts, fmt.Errorf("invalid concurrency %q", v)
+		}
+	}
+	if v := form.Get("objects"); v != "" {
+		if opts.Objects, err = strconv.Atoi(v); err != nil || opts.Objects < 0 {
+			return opts, fmt.Errorf("invalid object count %q", v)
+		}
+	}
+	if opts.Objects == 0 && mix.Put == 0 {
+		return opts, fmt.Errorf("no objects to read, list or delete")
+	}
+	return opts, nil
+}
+
+// pickWeighted returns an index chosen randomly according to weights.
+func pickWeighted(r *mrand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := r.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+const benchLatencyBuckets = 128
+
+// latencyHistogram counts latencies with four buckets per power of
+// two microseconds.
+type latencyHistogram [benchLatencyBuckets]uint64
+
+func latencyBucket(d time.Duration) int {
+	us := float64(d) / float64(time.Microsecond)
+	if us <= 1 {
+		return 0
+	}
+	i := int(math.Ceil(4 * math.Log2(us)))
+	if i >= benchLatencyBuckets {
+		i = benchLatencyBuckets - 1
+	}
+	return i
+}
+
+func (h *latencyHistogram) add(d time.Duration) {
+	h[latencyBucket(d)]++
+}
+
+func (h *latencyHistogram) merge(o *latencyHistogram) {
+	for i := range h {
+		h[i] += o[i]
+	}
+}
+
+// percentile returns the upper bound of the bucket holding the q-th percentile.
+func (h *latencyHistogram) percentile(q float64) time.Duration {
+	var total uint64
+	for _, n := range h {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i, n := range h {
+		seen += n
+		if seen >= target {
+			return time.Duration(math.Pow(2, float64(i)/4) * float64(time.Microsecond))
+		}
+	}
+	return 0
+}
+
+// benchmarkOpStats are the raw statistics of an operation on a node.
+type benchmarkOpStats struct {
+	Count     uint64
+	Errors    uint64
+	Bytes     uint64
+	Max       time.Duration
+	Latencies latencyHistogram
+}
+
+func (s *benchmarkOpStats) merge(o benchmarkOpStats) {
+	s.Count += o.Count
+	s.Errors += o.Errors
+	s.Bytes += o.Bytes
+	if o.Max > s.Max {
+		s.Max = o.Max
+	}
+	s.Latencies.merge(&o.Latencies)
+}
+
+// benchmarkNodeStats are the raw statistics of a node, sent between nodes.
+type benchmarkNodeStats struct {
+	Elapsed time.Duration
+	Ops     [benchOpCount]benchmarkOpStats
+	Error   string
+}
+
+// BenchmarkOpResult summarizes an operation of the benchmark.
+type BenchmarkOpResult struct {
+	Op          string        `json:"op"`
+	Count       uint64        `json:"count"`
+	Errors      uint64        `json:"errors"`
+	Bytes       uint64        `json:"bytes"`
+	OpsPerSec   float64       `json:"opsPerSec"`
+	BytesPerSec float64       `json:"bytesPerSec"`
+	LatencyP50  time.Duration `json:"latencyP50"`
+	LatencyP90  time.Duration `json:"latencyP90"`
+	LatencyP99  time.Duration `json:"latencyP99"`
+	LatencyMax  time.Duration `json:"latencyMax"`
+}
+
+// BenchmarkNodeResult is the benchmark result of a node.
+type BenchmarkNodeResult struct {
+	Endpoint string              `json:"endpoint"`
+	Ops      []BenchmarkOpResult `json:"ops,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// BenchmarkReport is the machine readable result of a benchmark.
+type BenchmarkReport struct {
+	Opts  BenchmarkOpts         `json:"opts"`
+	Total []BenchmarkOpResult   `json:"total"`
+	Nodes []BenchmarkNodeResult `json:"nodes"`
+}
+
+func benchmarkOpResults(stats [benchOpCount]benchmarkOpStats, elapsed time.Duration) []BenchmarkOpResult {
+	results := make([]BenchmarkOpResult, 0, benchOpCount)
+	for op, s := range stats {
+		if s.Count == 0 && s.Errors == 0 {
+			continue
+		}
+		r := BenchmarkOpResult{
+			Op:         benchOpNames[op],
+			Count:      s.Count,
+			Errors:     s.Errors,
+			Bytes:      s.Bytes,
+			LatencyP50: s.Latencies.percentile(0.5),
+			LatencyP90: s.Latencies.percentile(0.9),
+			LatencyP99: s.Latencies.percentile(0.99),
+			LatencyMax: s.Max,
+		}
+		if secs := elapsed.Seconds(); secs > 0 {
+			r.OpsPerSec = float64(s.Count) / secs
+			r.BytesPerSec = float64(s.Bytes) / secs
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// newBenchmarkReport summarizes the statistics of all nodes, the cluster
+// totals are computed over the longest run of all nodes.
+func newBenchmarkReport(opts BenchmarkOpts, endpoints []string, stats []benchmarkNodeStats) BenchmarkReport {
+	report := BenchmarkReport{Opts: opts}
+	var total [benchOpCount]benchmarkOpStats
+	var elapsed time.Duration
+	for i, s := range stats {
+		report.Nodes = append(report.Nodes, BenchmarkNodeResult{
+			Endpoint: endpoints[i],
+			Ops:      benchmarkOpResults(s.Ops, s.Elapsed),
+			Error:    s.Error,
+		})
+		for op := range s.Ops {
+			total[op].merge(s.Ops[op])
+		}
+		if s.Elapsed > elapsed {
+			elapsed = s.Elapsed
+		}
+	}
+	report.Total = benchmarkOpResults(total, elapsed)
+	return report
+}
+
+// benchmarkObjects is the set of objects available to GET and DELETE.
+type benchmarkObjects struct {
+	sync.Mutex
+	names []string
+}
+
+func (b *benchmarkObjects) add(name string) {
+	b.Lock()
+	b.names = append(b.names, name)
+	b.Unlock()
+}
+
+func (b *benchmarkObjects) pick(r *mrand.Rand, remove bool) (string, bool) {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.names) == 0 {
+		return "", false
+	}
+	i := r.Intn(len(b.names))
+	name := b.names[i]
+	if remove {
+		b.names[i] = b.names[len(b.names)-1]
+		b.names = b.names[:len(b.names)-1]
+	}
+	return name, true
+}
+
+// selfBenchmark runs the benchmark against the object layer of this node.
+func selfBenchmark(ctx context.Context, opts BenchmarkOpts) (benchmarkNodeStats, error) {
+	var stats benchmarkNodeStats
+	if err := opts.validate(); err != nil {
+		return stats, err
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return stats, errServerNotInitialized
+	}
+
+	buf := make([]byte, humanize.MiByte)
+	rand.Read(buf)
+
+	prefix := minioMetaBenchmarkPrefix + uuid.New().String() + SlashSeparator
+	var seq struct {
+		sync.Mutex
+		n int
+	}
+	sizeWeights := benchmarkSizeWeights(opts.Sizes)
+	put := func(ctx context.Context, r *mrand.Rand) (string, int64, error) {
+		seq.Lock()
+		name := fmt.Sprintf("%s%d", prefix, seq.n)
+		seq.n++
+		seq.Unlock()
+		size := opts.Sizes[pickWeighted(r, sizeWeights)].Size
+		var written uint64
+		hashReader, err := hash.NewReader(&SpeedtestObject{buf, int(size), &written}, size, "", "", size)
+		if err != nil {
+			return name, 0, err
+		}
+		_, err = objAPI.PutObject(ctx, minioMetaBucket, name, NewPutObjReader(hashReader), ObjectOptions{})
+		return name, size, err
+	}
+
+	objects := &benchmarkObjects{}
+	r := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < opts.Objects; i++ {
+		name, _, err := put(ctx, r)
+		if err != nil {
+			return stats, err
+		}
+		objects.add(name)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	weights := opts.Mix.weights()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	wg.Add(opts.Concurrent)
+	for i := 0; i < opts.Concurrent; i++ {
+		go func(seed int64) {
+			defer wg.Done()
+			r := mrand.New(mrand.NewSource(seed))
+			for runCtx.Err() == nil {
+				op := pickWeighted(r, weights[:])
+				var n int64
+				var err error
+				t := time.Now()
+				switch op {
+				case benchOpGet:
+					name, ok := objects.pick(r, false)
+					if !ok {
+						continue
+					}
+					var gr *GetObjectReader
+					gr, err = objAPI.GetObjectNInfo(runCtx, minioMetaBucket, name, nil, nil, readLock, ObjectOptions{})
+					if err == nil {
+						n, err = io.Copy(ioutil.Discard, gr)
+						gr.Close()
+					}
+					if isErrObjectNotFound(err) {
+						// Deleted by another worker meanwhile.
+						continue
+					}
+				case benchOpPut:
+					var name string
+					name, n, err = put(runCtx, r)
+					if err == nil {
+						objects.add(name)
+					}
+				case benchOpList:
+					_, err = objAPI.ListObjects(runCtx, minioMetaBucket, prefix, "", "", maxObjectList)
+				case benchOpDelete:
+					name, ok := objects.pick(r, true)
+					if !ok {
+						continue
+					}
+					_, err = objAPI.DeleteObject(runCtx, minioMetaBucket, name, ObjectOptions{})
+				}
+				d := time.Since(t)
+				if runCtx.Err() != nil {
+					// Interrupted by the end of the run.
+					return
+				}
+
+				mu.Lock()
+				s := &stats.Ops[op]
+				if err != nil {
+					s.Errors++
+					if stats.Error == "" {
+						stats.Error = err.Error()
+					}
+				} else {
+					s.Count++
+					s.Bytes += uint64(n)
+					s.Latencies.add(d)
+					if d > s.Max {
+						s.Max = d
+					}
+				}
+				mu.Unlock()
+			}
+		}(r.Int63())
+	}
+	wg.Wait()
+	stats.Elapsed = time.Since(start)
+	return stats, nil
+}
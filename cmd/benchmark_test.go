@@ -0,0 +1,108 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBenchmarkSizes(t *testing.T) {
+	testCases := []struct {
+		input   string
+		sizes   []BenchmarkSize
+		wantErr bool
+	}{
+		{"4KiB:50,1MiB:40,64MiB:10", []BenchmarkSize{{4 << 10, 50}, {1 << 20, 40}, {64 << 20, 10}}, false},
+		{"1MiB", []BenchmarkSize{{1 << 20, 1}}, false},
+		{"", nil, true},
+		{"1MiB:-1", nil, true},
+		{"4KiB:0,1MiB:0", nil, true},
+		{"1MiB:9999999999", nil, true},
+		{"big:10", nil, true},
+	}
+	for i, tc := range testCases {
+		sizes, err := parseBenchmarkSizes(tc.input)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.wantErr, err)
+		}
+		if !reflect.DeepEqual(sizes, tc.sizes) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, tc.sizes, sizes)
+		}
+	}
+}
+
+func TestParseBenchmarkOpts(t *testing.T) {
+	opts, err := parseBenchmarkOpts(url.Values{
+		"profile":  []string{"read-heavy"},
+		"put":      []string{"0"},
+		"duration": []string{"1m"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (BenchmarkMix{Get: 90, List: 5}); opts.Mix != want {
+		t.Errorf("expected mix %v, got %v", want, opts.Mix)
+	}
+	if opts.Duration != time.Minute {
+		t.Errorf("expected duration 1m, got %s", opts.Duration)
+	}
+
+	for _, form := range []url.Values{
+		{"profile": []string{"unknown"}},
+		{"get": []string{"0"}, "put": []string{"0"}, "list": []string{"0"}, "delete": []string{"0"}},
+		{"objects": []string{"0"}, "profile": []string{"read-heavy"}, "put": []string{"0"}},
+		{"concurrent": []string{"0"}},
+	} {
+		if _, err := parseBenchmarkOpts(form); err == nil {
+			t.Errorf("expected %v to be rejected", form)
+		}
+	}
+}
+
+func TestLatencyHistogramPercentile(t *testing.T) {
+	var h latencyHistogram
+	for i := 0; i < 90; i++ {
+		h.add(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.add(time.Second)
+	}
+
+	// Each bucket spans a fourth of a power of two.
+	within := func(got, want time.Duration) bool {
+		return got >= want && float64(got) < float64(want)*1.19
+	}
+	if p := h.percentile(0.5); !within(p, time.Millisecond) {
+		t.Errorf("expected p50 around 1ms, got %s", p)
+	}
+	if p := h.percentile(0.9); !within(p, time.Millisecond) {
+		t.Errorf("expected p90 around 1ms, got %s", p)
+	}
+	if p := h.percentile(0.99); !within(p, time.Second) {
+		t.Errorf("expected p99 around 1s, got %s", p)
+	}
+
+	var merged latencyHistogram
+	merged.merge(&h)
+	if merged != h {
+		t.Error("expected merged histogram to be equal")
+	}
+}
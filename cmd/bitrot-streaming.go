@@ -88,20 +88,22 @@ func newStreamingBitrotWriterBuffer(w io.Writer, algo BitrotAlgorithm, shardSize
 	}}
 }
 
-// Returns streaming bitrot writer implementation.
-func newStreamingBitrotWriter(disk StorageAPI, volume, filePath string, length int64, algo BitrotAlgorithm, shardSize int64) io.Writer {
+// Returns streaming bitrot writer implementation, the stream to disk
+// outlives ctx and only carries its traffic class.
+func newStreamingBitrotWriter(ctx context.Context, disk StorageAPI, volume, filePath string, length int64, algo BitrotAlgorithm, shardSize int64) io.Writer {
 	r, w := io.Pipe()
 	h := algo.New()
 
 	bw := &streamingBitrotWriter{iow: w, closeWithErr: w.CloseWithError, h: h, shardSize: shardSize, canClose: &sync.WaitGroup{}}
 	bw.canClose.Add(1)
+	ctx = detachedContext(ctx)
 	go func() {
 		totalFileSize := int64(-1) // For compressed objects length will be unknown (represented by length=-1)
 		if length != -1 {
 			bitrotSumsTotalSize := ceilFrac(length, shardSize) * int64(h.Size()) // Size used for storing bitrot checksums.
 			totalFileSize = bitrotSumsTotalSize + length
 		}
-		r.CloseWithError(disk.CreateFile(context.TODO(), volume, filePath, totalFileSize, r))
+		r.CloseWithError(disk.CreateFile(ctx, volume, filePath, totalFileSize, r))
 		bw.canClose.Done()
 	}()
 	return bw
@@ -109,6 +111,7 @@ func newStreamingBitrotWriter(disk StorageAPI, volume, filePath string, length i
 
 // ReadAt() implementation which verifies the bitrot hash available as part of the stream.
 type streamingBitrotReader struct {
+	ctx        context.Context
 	disk       StorageAPI
 	data       []byte
 	rc         io.Reader
@@ -151,7 +154,7 @@ func (b *streamingBitrotReader) ReadAt(buf []byte, offset int64) (int, error) {
 		b.currOffset = offset
 		streamOffset := (offset/b.shardSize)*int64(b.h.Size()) + offset
 		if len(b.data) == 0 && b.tillOffset != streamOffset {
-			b.rc, err = b.disk.ReadFileStream(context.TODO(), b.volume, b.filePath, streamOffset, b.tillOffset-streamOffset)
+			b.rc, err = b.disk.ReadFileStream(b.ctx, b.volume, b.filePath, streamOffset, b.tillOffset-streamOffset)
 			if err != nil {
 				logger.LogIf(GlobalContext,
 					fmt.Errorf("Error(%w) reading erasure shards at (%s: %s/%s), will attempt to reconstruct if we have quorum",
@@ -188,10 +191,12 @@ func (b *streamingBitrotReader) ReadAt(buf []byte, offset int64) (int, error) {
 	return len(buf), nil
 }
 
-// Returns streaming bitrot reader implementation.
-func newStreamingBitrotReader(disk StorageAPI, data []byte, volume, filePath string, tillOffset int64, algo BitrotAlgorithm, shardSize int64) *streamingBitrotReader {
+// Returns streaming bitrot reader implementation, the stream from disk
+// outlives ctx and only carries its traffic class.
+func newStreamingBitrotReader(ctx context.Context, disk StorageAPI, data []byte, volume, filePath string, tillOffset int64, algo BitrotAlgorithm, shardSize int64) *streamingBitrotReader {
 	h := algo.New()
 	return &streamingBitrotReader{
+		ctx:        detachedContext(ctx),
 		disk:       disk,
 		data:       data,
 		volume:     volume,
@@ -0,0 +1,101 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const bucketArchiveConfigFile = "archive.json"
+
+// BucketArchiveState - an archived bucket is sealed read-only: objects
+// cannot be written, deleted or have their metadata changed, and the
+// bucket configuration cannot be changed, regardless of policies.
+type BucketArchiveState struct {
+	ArchivedAt time.Time `json:"archivedAt"`
+	ArchivedBy string    `json:"archivedBy"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+func parseBucketArchiveState(data []byte) (*BucketArchiveState, error) {
+	state := &BucketArchiveState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// isBucketArchived returns whether bucket is sealed read-only.
+func isBucketArchived(bucket string) bool {
+	state, _ := globalBucketMetadataSys.GetArchiveState(bucket)
+	return state != nil
+}
+
+// setBucketArchiveHandler rejects all requests modifying an archived
+// bucket, only reads and selects go through.
+func setBucketArchiveHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet, r.Method == http.MethodHead, newObjectLayerFn() == nil:
+			h.ServeHTTP(w, r)
+			return
+		case guessIsRPCReq(r), isAdminReq(r), guessIsHealthCheckReq(r), guessIsMetricsReq(r):
+			h.ServeHTTP(w, r)
+			return
+		case isSelectReq(r):
+			h.ServeHTTP(w, r)
+			return
+		}
+		bucket, _ := request2BucketObjectName(r)
+		if bucket == "" || isMinioMetaBucketName(bucket) || !isBucketArchived(bucket) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrBucketArchived), r.URL)
+	})
+}
+
+// isSelectReq returns whether r is routed to the select APIs. Other
+// POST APIs are matched before them by their own query parameters, so
+// any of those present would let a write pass as a select.
+func isSelectReq(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	query := r.URL.Query()
+	if _, ok := query["select"]; !ok || query.Get("select-type") != "2" {
+		return false
+	}
+	for k := range query {
+		switch {
+		case k == "select", k == "select-type":
+		case strings.HasPrefix(k, "X-Amz-"):
+			// Presigned signature V4.
+		case k == xhttp.AmzAccessKeyID, k == xhttp.AmzSignatureV2, k == xhttp.Expires:
+			// Presigned signature V2.
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBucketArchiveHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	defer setObjectLayer(newObjectLayerFn())
+	setObjectLayer(objLayer)
+
+	oldMetadataSys := globalBucketMetadataSys
+	defer func() { globalBucketMetadataSys = oldMetadataSys }()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+
+	archived := newBucketMetadata("archived")
+	archived.archiveState = &BucketArchiveState{ArchivedAt: UTCNow(), ArchivedBy: "admin"}
+	globalBucketMetadataSys.Set("archived", archived)
+	globalBucketMetadataSys.Set("open", newBucketMetadata("open"))
+
+	h := setBucketArchiveHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		method, url string
+		status      int
+	}{
+		{http.MethodGet, "/archived/object", http.StatusOK},
+		{http.MethodHead, "/archived/object", http.StatusOK},
+		{http.MethodPost, "/archived/object?select&select-type=2", http.StatusOK},
+		{http.MethodPost, "/archived/object?select&select-type=2&X-Amz-Signature=abc", http.StatusOK},
+		{http.MethodPost, "/archived?select&select-type=2", http.StatusOK},
+		// Writes routed before the select APIs cannot pass as selects.
+		{http.MethodPost, "/archived/object?uploads&select&select-type=2", http.StatusForbidden},
+		{http.MethodPost, "/archived/object?uploadId=1&select&select-type=2", http.StatusForbidden},
+		{http.MethodPost, "/archived/object?lease&select&select-type=2", http.StatusForbidden},
+		{http.MethodPost, "/archived?commit&select&select-type=2", http.StatusForbidden},
+		{http.MethodPost, "/archived/object?select", http.StatusForbidden},
+		{http.MethodPut, "/archived/object", http.StatusForbidden},
+		{http.MethodDelete, "/archived/object", http.StatusForbidden},
+		{http.MethodPost, "/archived?delete", http.StatusForbidden},
+		{http.MethodPut, "/archived?policy", http.StatusForbidden},
+		{http.MethodDelete, "/archived", http.StatusForbidden},
+		{http.MethodPut, "/open/object", http.StatusOK},
+		{http.MethodDelete, "/open", http.StatusOK},
+	}
+	for i, tc := range testCases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.url, nil))
+		if rec.Code != tc.status {
+			t.Errorf("Test %d: %s %s: expected status %d, got %d", i+1, tc.method, tc.url, tc.status, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,983 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	bucketChangelogConfigFile = "changelog.json"
+
+	// changelogSegmentSize is the number of records of a segment, the
+	// segments compacted are merged up to this size.
+	changelogSegmentSize = 1000
+
+	// changelogOpenSegmentSize is the number of records of the segment
+	// appended to, rewritten by every append, before a new one is
+	// started.
+	changelogOpenSegmentSize = 100
+
+	// changelogBatchSize is the maximum number of changes of a bucket
+	// appended at once.
+	changelogBatchSize = 500
+
+	// changelogLookupWorkers is the number of objects of an append
+	// looked up concurrently.
+	changelogLookupWorkers = 16
+
+	changelogFlushInterval = 250 * time.Millisecond
+	changelogAppendRetries = 3
+	changelogRetryInterval = 5 * time.Second
+	changelogQueueSize     = 10000
+
+	// changelogCatchUpInterval is the age from which the scanner
+	// reconciles the changelog of a bucket with its objects, for the
+	// changes of nodes which exited before appending them.
+	changelogCatchUpInterval = 24 * time.Hour
+
+	// changelogPollInterval is how often followed logs are read for new
+	// records, keeping the stream alive in between.
+	changelogPollInterval = 500 * time.Millisecond
+
+	defaultChangelogTombstoneRetentionHours = 24
+
+	changelogOpPut    = "put"
+	changelogOpDelete = "delete"
+)
+
+var (
+	changelogLockTimeout = newDynamicTimeout(30*time.Second, 5*time.Second)
+
+	errChangelogNotEnabled = errors.New("changelog of the bucket is not enabled")
+)
+
+// BucketChangelogConfig - enables the changelog of a bucket, an ordered
+// log of the changes of its objects, each record holding the latest
+// version of an object, or a tombstone once it is deleted. Records
+// overwritten by a later record of the same object are compacted away,
+// tombstones after TombstoneRetentionHours.
+type BucketChangelogConfig struct {
+	Enabled                 bool `json:"enabled"`
+	TombstoneRetentionHours int  `json:"tombstoneRetentionHours,omitempty"`
+}
+
+func parseBucketChangelogConfig(bucket string, data []byte) (*BucketChangelogConfig, error) {
+	cfg := &BucketChangelogConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.TombstoneRetentionHours < 0 {
+		return cfg, fmt.Errorf("Invalid negative tombstone retention for the changelog of bucket %s", bucket)
+	}
+	return cfg, nil
+}
+
+// TombstoneRetention returns how long tombstones are kept by compaction,
+// for consumers to see the deletes of the objects they hold.
+func (c BucketChangelogConfig) TombstoneRetention() time.Duration {
+	hours := c.TombstoneRetentionHours
+	if hours == 0 {
+		hours = defaultChangelogTombstoneRetentionHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// bucketChangelogConfig returns the changelog configuration of bucket,
+// nil if the changelog is not enabled.
+func bucketChangelogConfig(bucket string) *BucketChangelogConfig {
+	cfg, _ := globalBucketMetadataSys.GetChangelogConfig(bucket)
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return cfg
+}
+
+// bucketChangelogStatus - the changelog configuration of a bucket with
+// the state of its log.
+type bucketChangelogStatus struct {
+	BucketChangelogConfig
+	ID          string    `json:"id,omitempty"`
+	FirstOffset uint64    `json:"firstOffset"`
+	NextOffset  uint64    `json:"nextOffset"`
+	Segments    int       `json:"segments"`
+	Compacted   time.Time `json:"compacted,omitempty"`
+}
+
+// ChangelogRecord - a change of an object, the latest version of the
+// object when the change was appended, Value being nil for a delete.
+type ChangelogRecord struct {
+	Offset uint64          `json:"offset"`
+	Time   time.Time       `json:"time"`
+	Key    string          `json:"key"`
+	Op     string          `json:"op"`
+	Value  *ChangelogValue `json:"value"`
+}
+
+// ChangelogValue - the metadata of an object version in the changelog.
+type ChangelogValue struct {
+	VersionID    string            `json:"versionId,omitempty"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"contentType,omitempty"`
+	StorageClass string            `json:"storageClass,omitempty"`
+	LastModified time.Time         `json:"lastModified"`
+	UserMetadata map[string]string `json:"userMetadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+func newChangelogValue(oi ObjectInfo) *ChangelogValue {
+	size, err := oi.GetActualSize()
+	if err != nil {
+		size = oi.Size
+	}
+	v := &ChangelogValue{
+		VersionID:    oi.VersionID,
+		Size:         size,
+		ETag:         oi.GetActualETag(nil),
+		ContentType:  oi.ContentType,
+		StorageClass: oi.StorageClass,
+		LastModified: oi.ModTime,
+		UserMetadata: metadataSinkUserMetadata(oi.UserDefined),
+	}
+	if oi.UserTags != "" {
+		if t, err := tags.ParseObjectTags(oi.UserTags); err == nil {
+			v.Tags = t.ToMap()
+		}
+	}
+	return v
+}
+
+// changelogHead - the segments of the changelog of a bucket, ordered by
+// offset. Offsets below Next are committed, the last segment is the one
+// appended to and the others are only rewritten by compaction, which
+// keeps the offsets of the records it retains. Nodes unable to append
+// changes request a catch-up, reconciling the log with the objects.
+type changelogHead struct {
+	ID               string                `json:"id"`
+	Next             uint64                `json:"next"`
+	Clean            uint64                `json:"clean"`
+	Compacted        time.Time             `json:"compacted,omitempty"`
+	CaughtUp         time.Time             `json:"caughtUp,omitempty"`
+	CatchUpRequested time.Time             `json:"catchUpRequested,omitempty"`
+	Segments         []changelogSegmentRef `json:"segments,omitempty"`
+}
+
+// changelogSegmentRef - a segment holding the records of the offsets
+// First to Last, fewer than the offsets once compacted.
+type changelogSegmentRef struct {
+	ID      string `json:"id"`
+	First   uint64 `json:"first"`
+	Last    uint64 `json:"last"`
+	Records int    `json:"records"`
+}
+
+type changelogSegment struct {
+	Records []ChangelogRecord `json:"records"`
+}
+
+// firstOffset returns the offset of the first record retained.
+func (h changelogHead) firstOffset() uint64 {
+	for _, s := range h.Segments {
+		if s.Records > 0 {
+			return s.First
+		}
+	}
+	return h.Next
+}
+
+// findSegment returns the position of the first segment holding offsets
+// from offset on.
+func (h changelogHead) findSegment(offset uint64) int {
+	return sort.Search(len(h.Segments), func(i int) bool {
+		return h.Segments[i].Last >= offset
+	})
+}
+
+// compactionDue returns whether the closed segments, all but the last,
+// hold enough records appended since the last compaction, or enough
+// small segments to merge, or tombstones may have expired.
+func (h changelogHead) compactionDue(retention time.Duration) bool {
+	if len(h.Segments) < 2 {
+		return false
+	}
+	var dirty, dirtySegments, total int
+	for _, s := range h.Segments[:len(h.Segments)-1] {
+		if s.First >= h.Clean {
+			dirty += s.Records
+			dirtySegments++
+		}
+		total += s.Records
+	}
+	if dirty >= changelogSegmentSize && 2*dirty >= total {
+		return true
+	}
+	if dirtySegments >= changelogSegmentSize/changelogOpenSegmentSize*10 {
+		return true
+	}
+	return total > 0 && time.Since(h.Compacted) > retention
+}
+
+// catchUpDue returns whether a node requested a catch-up since the last
+// one, or the last one is older than changelogCatchUpInterval.
+func (h changelogHead) catchUpDue() bool {
+	return h.CatchUpRequested.After(h.CaughtUp) || time.Since(h.CaughtUp) > changelogCatchUpInterval
+}
+
+func changelogLockPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "changelog.lock")
+}
+
+func changelogCompactionLockPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "changelog-compaction.lock")
+}
+
+func changelogDir(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "changelog")
+}
+
+func changelogHeadPath(bucket string) string {
+	return pathJoin(changelogDir(bucket), "head.json")
+}
+
+func changelogSegmentPath(bucket, id string) string {
+	return pathJoin(changelogDir(bucket), "segments", id+".json")
+}
+
+func loadChangelogHead(ctx context.Context, objAPI ObjectLayer, bucket string) (changelogHead, error) {
+	var h changelogHead
+	data, err := readConfig(ctx, objAPI, changelogHeadPath(bucket))
+	if err != nil {
+		return h, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err
+}
+
+func saveChangelogHead(ctx context.Context, objAPI ObjectLayer, bucket string, h changelogHead) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, changelogHeadPath(bucket), data)
+}
+
+func loadChangelogSegment(ctx context.Context, objAPI ObjectLayer, bucket, id string) (changelogSegment, error) {
+	var s changelogSegment
+	data, err := readConfig(ctx, objAPI, changelogSegmentPath(bucket, id))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func saveChangelogSegment(ctx context.Context, objAPI ObjectLayer, bucket, id string, s changelogSegment) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, changelogSegmentPath(bucket, id), data)
+}
+
+// appendChangelog appends a record of the latest version of each object
+// of keys to the changelog of bucket. Objects are looked up under the
+// changelog lock, the last record of an object always holds its state
+// after the last change appended, whichever node appends first.
+// Appends rewrite the last segment, which is closed once it holds
+// changelogOpenSegmentSize records, compaction merges the closed ones.
+func appendChangelog(ctx context.Context, objAPI ObjectLayer, bucket string, keys []string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, changelogLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	h, err := loadChangelogHead(ctx, objAPI, bucket)
+	switch err {
+	case nil:
+	case errConfigNotFound:
+		h = changelogHead{ID: mustGetUUID(), Compacted: UTCNow()}
+	default:
+		return err
+	}
+
+	// Continue the last segment, dropping the records of an append
+	// interrupted before its head was saved.
+	var seg changelogSegment
+	var ref changelogSegmentRef
+	if n := len(h.Segments); n > 0 && h.Segments[n-1].Records < changelogOpenSegmentSize {
+		ref = h.Segments[n-1]
+		h.Segments = h.Segments[:n-1]
+		if seg, err = loadChangelogSegment(ctx, objAPI, bucket, ref.ID); err != nil {
+			return err
+		}
+		for i, r := range seg.Records {
+			if r.Offset >= h.Next {
+				seg.Records = seg.Records[:i]
+				break
+			}
+		}
+	} else {
+		ref = changelogSegmentRef{ID: mustGetUUID(), First: h.Next}
+	}
+
+	values, err := lookupChangelogValues(ctx, objAPI, bucket, keys)
+	if err != nil {
+		return err
+	}
+	now := UTCNow()
+	for i, key := range keys {
+		r := ChangelogRecord{Offset: h.Next, Time: now, Key: key, Op: changelogOpPut, Value: values[i]}
+		if r.Value == nil {
+			r.Op = changelogOpDelete
+		}
+		if len(seg.Records) >= changelogOpenSegmentSize {
+			if err = saveChangelogSegment(ctx, objAPI, bucket, ref.ID, seg); err != nil {
+				return err
+			}
+			h.Segments = append(h.Segments, ref)
+			seg = changelogSegment{}
+			ref = changelogSegmentRef{ID: mustGetUUID(), First: h.Next}
+		}
+		seg.Records = append(seg.Records, r)
+		ref.Last, ref.Records = r.Offset, len(seg.Records)
+		h.Next++
+	}
+	if err = saveChangelogSegment(ctx, objAPI, bucket, ref.ID, seg); err != nil {
+		return err
+	}
+	h.Segments = append(h.Segments, ref)
+	return saveChangelogHead(ctx, objAPI, bucket, h)
+}
+
+// lookupChangelogValues looks up the latest version of each object of
+// keys, a nil value for a deleted object.
+func lookupChangelogValues(ctx context.Context, objAPI ObjectLayer, bucket string, keys []string) ([]*ChangelogValue, error) {
+	values := make([]*ChangelogValue, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, changelogLookupWorkers)
+	for i, key := range keys {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, key string) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			oi, err := objAPI.GetObjectInfo(ctx, bucket, key, ObjectOptions{})
+			switch {
+			case err == nil && !oi.DeleteMarker:
+				values[i] = newChangelogValue(oi)
+			case err == nil, isErrObjectNotFound(err), isErrVersionNotFound(err), isErrMethodNotAllowed(err):
+			default:
+				errs[i] = err
+			}
+		}(i, key)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// compactChangelogSegments returns the records of segs without the ones
+// overwritten by the record at offset latest[key] of the same key and
+// the tombstones appended before expiry, merged into segments of up to
+// changelogSegmentSize records covering the same offsets.
+func compactChangelogSegments(refs []changelogSegmentRef, segs []changelogSegment, latest map[string]uint64, expiry time.Time) ([]changelogSegmentRef, []changelogSegment) {
+	var newRefs []changelogSegmentRef
+	var newSegs []changelogSegment
+	var seg changelogSegment
+	ref := changelogSegmentRef{}
+	if len(refs) > 0 {
+		ref.First = refs[0].First
+	}
+	for i, s := range segs {
+		for _, r := range s.Records {
+			if latest[r.Key] != r.Offset || r.Op == changelogOpDelete && r.Time.Before(expiry) {
+				continue
+			}
+			if len(seg.Records) >= changelogSegmentSize {
+				newRefs = append(newRefs, ref)
+				newSegs = append(newSegs, seg)
+				seg = changelogSegment{}
+				ref = changelogSegmentRef{First: r.Offset}
+			}
+			seg.Records = append(seg.Records, r)
+			ref.Records = len(seg.Records)
+		}
+		ref.Last = refs[i].Last
+	}
+	if len(segs) > 0 {
+		newRefs = append(newRefs, ref)
+		newSegs = append(newSegs, seg)
+	}
+	for i := range newRefs {
+		newRefs[i].ID = mustGetUUID()
+		if i > 0 {
+			newRefs[i-1].Last = newRefs[i].First - 1
+		}
+	}
+	return newRefs, newSegs
+}
+
+// compactChangelog rewrites the closed segments of the changelog of
+// bucket with only the last record of each object, dropping expired
+// tombstones. Appends only wait for the head to be updated, readers of
+// the replaced segments reload the head.
+func compactChangelog(ctx context.Context, objAPI ObjectLayer, bucket string, cfg *BucketChangelogConfig) error {
+	clk := objAPI.NewNSLock(minioMetaBucket, changelogCompactionLockPath(bucket))
+	clkctx, err := clk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = clkctx.Context()
+	defer clk.Unlock(clkctx.Cancel)
+
+	h, err := loadChangelogHead(ctx, objAPI, bucket)
+	if err != nil {
+		return err
+	}
+	if !h.compactionDue(cfg.TombstoneRetention()) {
+		return nil
+	}
+	closed := h.Segments[:len(h.Segments)-1]
+
+	latest := make(map[string]uint64)
+	segs := make([]changelogSegment, 0, len(closed))
+	for i, ref := range h.Segments {
+		s, err := loadChangelogSegment(ctx, objAPI, bucket, ref.ID)
+		if err != nil {
+			return err
+		}
+		for _, r := range s.Records {
+			if r.Offset < h.Next {
+				latest[r.Key] = r.Offset
+			}
+		}
+		if i < len(closed) {
+			segs = append(segs, s)
+		}
+	}
+	// Records appended since the head was loaded only supersede more
+	// records, they are compacted by the next compaction.
+	newRefs, newSegs := compactChangelogSegments(closed, segs, latest, UTCNow().Add(-cfg.TombstoneRetention()))
+	for i, ref := range newRefs {
+		if err = saveChangelogSegment(ctx, objAPI, bucket, ref.ID, newSegs[i]); err != nil {
+			return err
+		}
+	}
+
+	lk := objAPI.NewNSLock(minioMetaBucket, changelogLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	cur, err := loadChangelogHead(lkctx.Context(), objAPI, bucket)
+	if err == nil && (cur.ID != h.ID || len(cur.Segments) < len(closed) || cur.Segments[len(closed)-1].ID != closed[len(closed)-1].ID) {
+		err = errors.New("changelog was modified during compaction")
+	}
+	if err == nil {
+		cur.Segments = append(newRefs, cur.Segments[len(closed):]...)
+		cur.Clean = closed[len(closed)-1].Last + 1
+		cur.Compacted = UTCNow()
+		err = saveChangelogHead(lkctx.Context(), objAPI, bucket, cur)
+	}
+	lk.Unlock(lkctx.Cancel)
+
+	if err != nil {
+		for _, ref := range newRefs {
+			deleteConfig(ctx, objAPI, changelogSegmentPath(bucket, ref.ID))
+		}
+		return err
+	}
+	for _, ref := range closed {
+		if err := deleteConfig(ctx, objAPI, changelogSegmentPath(bucket, ref.ID)); err != nil && err != errConfigNotFound {
+			logger.LogIf(ctx, err)
+		}
+	}
+	return nil
+}
+
+// removeChangelog removes the changelog of bucket, a new log with a new
+// ID is started if the changelog is enabled again.
+func removeChangelog(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, changelogLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	if err = deleteConfig(ctx, objAPI, changelogDir(bucket)); err != nil && err != errConfigNotFound {
+		return err
+	}
+	return nil
+}
+
+// requestChangelogCatchUp marks the changelog of bucket to be caught up
+// by the scanner, for changes this node could not append.
+func requestChangelogCatchUp(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, changelogLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	h, err := loadChangelogHead(ctx, objAPI, bucket)
+	switch err {
+	case nil:
+	case errConfigNotFound:
+		h = changelogHead{ID: mustGetUUID(), Compacted: UTCNow()}
+	default:
+		return err
+	}
+	h.CatchUpRequested = UTCNow()
+	return saveChangelogHead(ctx, objAPI, bucket, h)
+}
+
+// changelogValueChanged returns whether the latest record v of an
+// object does not hold its version oi.
+func changelogValueChanged(v *ChangelogValue, oi ObjectInfo) bool {
+	if v == nil {
+		return true
+	}
+	cur := newChangelogValue(oi)
+	if v.VersionID != cur.VersionID || v.ETag != cur.ETag || v.Size != cur.Size ||
+		v.StorageClass != cur.StorageClass || !v.LastModified.Equal(cur.LastModified) ||
+		len(v.Tags) != len(cur.Tags) {
+		return true
+	}
+	for k, val := range cur.Tags {
+		if v.Tags[k] != val {
+			return true
+		}
+	}
+	return false
+}
+
+// catchUpChangelog appends the changes missing from the changelog of
+// bucket: the objects whose latest record does not hold their latest
+// version, and the objects recorded but deleted since.
+func catchUpChangelog(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	start := UTCNow()
+	latest := make(map[string]*ChangelogValue)
+	if _, _, err := readChangelog(ctx, objAPI, bucket, 0, 0, func(r ChangelogRecord) error {
+		latest[r.Key] = r.Value
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var keys []string
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		err := appendChangelog(ctx, objAPI, bucket, keys)
+		keys = keys[:0]
+		return err
+	}
+	var marker string
+	for {
+		res, err := objAPI.ListObjects(ctx, bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range res.Objects {
+			v, ok := latest[oi.Name]
+			delete(latest, oi.Name)
+			if ok && !changelogValueChanged(v, oi) {
+				continue
+			}
+			if keys = append(keys, oi.Name); len(keys) >= changelogBatchSize {
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+	// Objects left were deleted unless recorded so.
+	for key, v := range latest {
+		if v == nil {
+			continue
+		}
+		if keys = append(keys, key); len(keys) >= changelogBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	lk := objAPI.NewNSLock(minioMetaBucket, changelogLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, changelogLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lk.Unlock(lkctx.Cancel)
+	h, err := loadChangelogHead(lkctx.Context(), objAPI, bucket)
+	if err == errConfigNotFound {
+		h = changelogHead{ID: mustGetUUID(), Compacted: UTCNow()}
+	} else if err != nil {
+		return err
+	}
+	// Requests made meanwhile get the next catch-up.
+	h.CaughtUp = start
+	return saveChangelogHead(lkctx.Context(), objAPI, bucket, h)
+}
+
+// readChangelog calls fn with the committed records of the changelog of
+// bucket from offset on, up to limit records if not zero. Returns the
+// offset following the last record read, and the head read first.
+func readChangelog(ctx context.Context, objAPI ObjectLayer, bucket string, offset uint64, limit int, fn func(ChangelogRecord) error) (uint64, changelogHead, error) {
+	h, err := loadChangelogHead(ctx, objAPI, bucket)
+	if err == errConfigNotFound {
+		return offset, h, nil
+	}
+	if err != nil {
+		return offset, h, err
+	}
+	first, next, n := h, h.Next, 0
+	var reloaded bool
+	for i := h.findSegment(offset); i < len(h.Segments) && offset < next; i++ {
+		s, err := loadChangelogSegment(ctx, objAPI, bucket, h.Segments[i].ID)
+		if err == errConfigNotFound && !reloaded {
+			// The segment was replaced by a compaction.
+			if h, err = loadChangelogHead(ctx, objAPI, bucket); err != nil {
+				return offset, first, err
+			}
+			i, reloaded = h.findSegment(offset)-1, true
+			continue
+		}
+		reloaded = false
+		if err != nil {
+			return offset, first, err
+		}
+		for _, r := range s.Records {
+			if r.Offset < offset || r.Offset >= next {
+				continue
+			}
+			if err = fn(r); err != nil {
+				return offset, first, err
+			}
+			offset = r.Offset + 1
+			if n++; limit > 0 && n >= limit {
+				return offset, first, nil
+			}
+		}
+		if offset <= h.Segments[i].Last && h.Segments[i].Last < next {
+			offset = h.Segments[i].Last + 1
+		}
+	}
+	if offset < next {
+		offset = next
+	}
+	return offset, first, nil
+}
+
+// streamChangelog writes the records of the changelog of bucket from
+// offset on to w as JSON lines, up to limit records if not zero. With
+// follow set, new records are streamed as they are appended, with a
+// space keeping the stream alive every poll, until ctx is done.
+func streamChangelog(ctx context.Context, objAPI ObjectLayer, bucket string, offset uint64, limit int, follow bool, w io.Writer, flush func()) error {
+	enc := json.NewEncoder(w)
+	var id string
+	for {
+		var n int
+		next, h, err := readChangelog(ctx, objAPI, bucket, offset, limit, func(r ChangelogRecord) error {
+			n++
+			return enc.Encode(r)
+		})
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			id = h.ID
+		} else if h.ID != "" && h.ID != id {
+			return errors.New("changelog was removed while streaming")
+		}
+		offset = next
+		if limit > 0 {
+			if limit -= n; limit <= 0 {
+				return nil
+			}
+		}
+		if !follow {
+			return nil
+		}
+		if n == 0 {
+			if _, err = w.Write([]byte(" ")); err != nil {
+				return err
+			}
+		}
+		flush()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(changelogPollInterval):
+		}
+	}
+}
+
+// checkChangelog queues a catch-up or a compaction of the changelog of
+// bucket when due, called by the scanner for every bucket scanned.
+func checkChangelog(ctx context.Context, objAPI ObjectLayer, bucket string) {
+	cfg := bucketChangelogConfig(bucket)
+	if cfg == nil {
+		return
+	}
+	h, err := loadChangelogHead(ctx, objAPI, bucket)
+	switch {
+	case err == errConfigNotFound:
+		// Changes appended before the first append are caught up.
+		globalChangelogAppender.queue(bucket, true)
+	case err != nil:
+	case h.catchUpDue():
+		globalChangelogAppender.queue(bucket, true)
+	case h.compactionDue(cfg.TombstoneRetention()):
+		globalChangelogAppender.queue(bucket, false)
+	}
+}
+
+type changelogChange struct {
+	bucket, object string
+}
+
+type changelogTask struct {
+	bucket  string
+	catchUp bool
+}
+
+// changelogAppender appends the changes of the objects served by this
+// node in batches, one bucket at a time, and catches up and compacts the
+// changelogs queued by the scanner.
+type changelogAppender struct {
+	changes chan changelogChange
+
+	mu       sync.Mutex
+	overflow map[string]struct{} // buckets of changes not queued
+	queued   map[changelogTask]struct{}
+	tasks    chan changelogTask
+}
+
+var globalChangelogAppender = &changelogAppender{
+	changes:  make(chan changelogChange, changelogQueueSize),
+	overflow: make(map[string]struct{}),
+	queued:   make(map[changelogTask]struct{}),
+	tasks:    make(chan changelogTask, 100),
+}
+
+// onEvent queues the change of the object of an event to the changelog
+// of its bucket. It never blocks the write, the changes not queued are
+// caught up.
+func (a *changelogAppender) onEvent(args eventArgs) {
+	switch args.EventName {
+	case event.ObjectCreatedCompleteMultipartUpload, event.ObjectCreatedCopy,
+		event.ObjectCreatedPost, event.ObjectCreatedPut,
+		event.ObjectCreatedPutTagging, event.ObjectCreatedDeleteTagging,
+		event.ObjectRemovedDelete, event.ObjectRemovedDeleteMarkerCreated,
+		event.ObjectTransitionComplete:
+	default:
+		return
+	}
+	if bucketChangelogConfig(args.BucketName) == nil {
+		return
+	}
+	select {
+	case a.changes <- changelogChange{bucket: args.BucketName, object: args.Object.Name}:
+	default:
+		a.setOverflow(args.BucketName)
+	}
+}
+
+func (a *changelogAppender) setOverflow(bucket string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.overflow[bucket] = struct{}{}
+}
+
+// queue queues a catch-up or a compaction of the changelog of bucket,
+// unless already queued.
+func (a *changelogAppender) queue(bucket string, catchUp bool) {
+	task := changelogTask{bucket: bucket, catchUp: catchUp}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.queued[task]; ok {
+		return
+	}
+	select {
+	case a.tasks <- task:
+		a.queued[task] = struct{}{}
+	default:
+	}
+}
+
+// requestCatchUps requests a catch-up of the changelogs of the buckets
+// with changes not appended, kept for the next call on failure.
+func (a *changelogAppender) requestCatchUps(ctx context.Context, objAPI ObjectLayer) {
+	a.mu.Lock()
+	buckets := make([]string, 0, len(a.overflow))
+	for bucket := range a.overflow {
+		buckets = append(buckets, bucket)
+	}
+	a.mu.Unlock()
+	for _, bucket := range buckets {
+		err := requestChangelogCatchUp(ctx, objAPI, bucket)
+		if err != nil {
+			logger.LogOnceIf(ctx, fmt.Errorf("changelog: unable to request a catch-up of bucket %s: %w", bucket, err), "changelog-catchup-"+bucket)
+			continue
+		}
+		a.mu.Lock()
+		delete(a.overflow, bucket)
+		a.mu.Unlock()
+	}
+}
+
+// run appends the changes queued, deduplicated by object, every flush
+// interval or once a batch of a bucket is full. Failed appends are kept
+// and retried every changelogRetryInterval, the changes of a bucket
+// beyond changelogQueueSize are caught up instead. The changes queued
+// by a previous run of this node being lost, the changelogs of all
+// buckets are caught up first.
+func (a *changelogAppender) run(ctx context.Context, objAPI ObjectLayer) {
+	if buckets, err := objAPI.ListBuckets(ctx); err == nil {
+		for _, b := range buckets {
+			if bucketChangelogConfig(b.Name) != nil {
+				a.setOverflow(b.Name)
+			}
+		}
+	}
+
+	pending := make(map[string][]string)
+	failures := make(map[string]int)
+	retryAt := make(map[string]time.Time)
+	seen := make(map[changelogChange]struct{})
+	flushBucket := func(bucket string) {
+		if time.Now().Before(retryAt[bucket]) {
+			return
+		}
+		keys := pending[bucket]
+		for len(keys) > 0 {
+			n := len(keys)
+			if n > changelogBatchSize {
+				n = changelogBatchSize
+			}
+			if err := appendChangelog(ctx, objAPI, bucket, keys[:n]); err != nil {
+				if failures[bucket]++; failures[bucket] == changelogAppendRetries {
+					logger.LogIf(ctx, fmt.Errorf("changelog: unable to append %d changes to bucket %s, retrying: %w", len(keys), bucket, err))
+				}
+				retryAt[bucket] = time.Now().Add(changelogRetryInterval)
+				break
+			}
+			for _, key := range keys[:n] {
+				delete(seen, changelogChange{bucket: bucket, object: key})
+			}
+			keys = keys[n:]
+			delete(failures, bucket)
+			delete(retryAt, bucket)
+		}
+		if len(keys) > changelogQueueSize {
+			// Too many changes kept, caught up once the log is back.
+			for _, key := range keys {
+				delete(seen, changelogChange{bucket: bucket, object: key})
+			}
+			keys = nil
+			a.setOverflow(bucket)
+		}
+		if len(keys) == 0 {
+			delete(pending, bucket)
+			return
+		}
+		pending[bucket] = keys
+	}
+	ticker := time.NewTicker(changelogFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for bucket := range pending {
+				flushBucket(bucket)
+			}
+			a.requestCatchUps(ctx, objAPI)
+		case change := <-a.changes:
+			if _, ok := seen[change]; ok {
+				continue
+			}
+			seen[change] = struct{}{}
+			pending[change.bucket] = append(pending[change.bucket], change.object)
+			if len(pending[change.bucket])%changelogBatchSize == 0 {
+				flushBucket(change.bucket)
+			}
+		}
+	}
+}
+
+func (a *changelogAppender) runCompaction(ctx context.Context, objAPI ObjectLayer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-a.tasks:
+			if cfg := bucketChangelogConfig(task.bucket); cfg != nil {
+				if task.catchUp {
+					logger.LogIf(ctx, catchUpChangelog(ctx, objAPI, task.bucket))
+				} else {
+					logger.LogIf(ctx, compactChangelog(ctx, objAPI, task.bucket, cfg))
+				}
+			}
+			a.mu.Lock()
+			delete(a.queued, task)
+			a.mu.Unlock()
+		}
+	}
+}
+
+func initChangelog(ctx context.Context, objAPI ObjectLayer) {
+	go globalChangelogAppender.run(ctx, objAPI)
+	go globalChangelogAppender.runCompaction(ctx, objAPI)
+}
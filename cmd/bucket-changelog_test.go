@@ -0,0 +1,217 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseBucketChangelogConfig(t *testing.T) {
+	cfg, err := parseBucketChangelogConfig("bucket", []byte(`{"enabled":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Enabled || cfg.TombstoneRetention() != 24*time.Hour {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg, err = parseBucketChangelogConfig("bucket", []byte(`{"enabled":true,"tombstoneRetentionHours":2}`)); err != nil || cfg.TombstoneRetention() != 2*time.Hour {
+		t.Fatalf("unexpected config %+v: %v", cfg, err)
+	}
+	if _, err = parseBucketChangelogConfig("bucket", []byte(`{"enabled":true,"tombstoneRetentionHours":-1}`)); err == nil {
+		t.Fatal("expected negative retention to fail")
+	}
+}
+
+func TestChangelogHead(t *testing.T) {
+	h := changelogHead{
+		Next: 3500,
+		Segments: []changelogSegmentRef{
+			{ID: "a", First: 0, Last: 1999, Records: 0},
+			{ID: "b", First: 2000, Last: 2999, Records: 1000},
+			{ID: "c", First: 3000, Last: 3499, Records: 500},
+		},
+		Compacted: time.Now(),
+	}
+	if first := h.firstOffset(); first != 2000 {
+		t.Errorf("expected first offset 2000, got %d", first)
+	}
+	for offset, want := range map[uint64]int{0: 0, 1999: 0, 2000: 1, 3000: 2, 3499: 2, 3500: 3} {
+		if got := h.findSegment(offset); got != want {
+			t.Errorf("offset %d: expected segment %d, got %d", offset, want, got)
+		}
+	}
+	// All 1000 records of the closed segments were appended since the
+	// last compaction.
+	if !h.compactionDue(time.Hour) {
+		t.Error("expected compaction to be due")
+	}
+	h.Clean = 3000
+	if h.compactionDue(time.Hour) {
+		t.Error("expected compaction not to be due")
+	}
+	h.Compacted = time.Now().Add(-2 * time.Hour)
+	if !h.compactionDue(time.Hour) {
+		t.Error("expected compaction to be due for tombstones")
+	}
+}
+
+func TestCompactChangelogSegments(t *testing.T) {
+	now := time.Now()
+	refs := []changelogSegmentRef{
+		{First: 0, Last: 3, Records: 4},
+		{First: 4, Last: 7, Records: 4},
+	}
+	segs := []changelogSegment{
+		{Records: []ChangelogRecord{
+			{Offset: 0, Key: "a", Op: changelogOpPut, Time: now},
+			{Offset: 1, Key: "b", Op: changelogOpPut, Time: now},
+			{Offset: 2, Key: "a", Op: changelogOpPut, Time: now},
+			{Offset: 3, Key: "c", Op: changelogOpDelete, Time: now.Add(-2 * time.Hour)},
+		}},
+		{Records: []ChangelogRecord{
+			{Offset: 4, Key: "d", Op: changelogOpDelete, Time: now},
+			{Offset: 5, Key: "b", Op: changelogOpPut, Time: now},
+			{Offset: 6, Key: "e", Op: changelogOpPut, Time: now},
+			{Offset: 7, Key: "f", Op: changelogOpPut, Time: now},
+		}},
+	}
+	// e was changed again in the open segment.
+	latest := map[string]uint64{"a": 2, "b": 5, "c": 3, "d": 4, "e": 9, "f": 7}
+	newRefs, newSegs := compactChangelogSegments(refs, segs, latest, now.Add(-time.Hour))
+	if len(newRefs) != 1 || len(newSegs) != 1 {
+		t.Fatalf("expected the segments to be merged, got %d", len(newRefs))
+	}
+	if newRefs[0].First != 0 || newRefs[0].Last != 7 || newRefs[0].Records != 4 || newRefs[0].ID == "" {
+		t.Fatalf("unexpected segment %+v", newRefs[0])
+	}
+	var got []string
+	for _, r := range newSegs[0].Records {
+		got = append(got, fmt.Sprintf("%d:%s", r.Offset, r.Key))
+	}
+	if want := "[2:a 4:d 5:b 7:f]"; fmt.Sprint(got) != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+}
+
+func TestCompactChangelogSegmentsSplit(t *testing.T) {
+	var refs []changelogSegmentRef
+	var segs []changelogSegment
+	latest := make(map[string]uint64)
+	for i := 0; i < 3; i++ {
+		var s changelogSegment
+		for j := 0; j < changelogSegmentSize; j++ {
+			offset := uint64(i*changelogSegmentSize + j)
+			key := fmt.Sprint(offset)
+			s.Records = append(s.Records, ChangelogRecord{Offset: offset, Key: key, Op: changelogOpPut})
+			latest[key] = offset
+		}
+		segs = append(segs, s)
+		refs = append(refs, changelogSegmentRef{First: uint64(i * changelogSegmentSize), Last: uint64((i+1)*changelogSegmentSize - 1), Records: changelogSegmentSize})
+	}
+	newRefs, _ := compactChangelogSegments(refs, segs, latest, time.Time{})
+	if len(newRefs) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(newRefs))
+	}
+	for i, ref := range newRefs {
+		if ref.First != refs[i].First || ref.Last != refs[i].Last || ref.Records != changelogSegmentSize {
+			t.Errorf("segment %d: expected %+v, got %+v", i, refs[i], ref)
+		}
+	}
+}
+
+func TestCatchUpChangelog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	const bucket = "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	put := func(object, content string) {
+		if _, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader([]byte(content)), int64(len(content)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := func() []string {
+		var got []string
+		if _, _, err := readChangelog(ctx, objLayer, bucket, 0, 0, func(r ChangelogRecord) error {
+			got = append(got, fmt.Sprintf("%d:%s:%s", r.Offset, r.Op, r.Key))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// Changes lost before being appended are caught up, the recorded
+	// ones are not appended again.
+	put("a", "a")
+	if err = appendChangelog(ctx, objLayer, bucket, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	put("b", "b")
+	if err = catchUpChangelog(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(records()), "[0:put:a 1:put:b]"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	put("a", "changed")
+	if _, err = objLayer.DeleteObject(ctx, bucket, "b", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = catchUpChangelog(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	got := records()
+	if want := "[0:put:a 1:put:b 2:put:a 3:delete:b]"; fmt.Sprint(got) != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+	h, err := loadChangelogHead(ctx, objLayer, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.catchUpDue() {
+		t.Error("expected no catch-up to be due")
+	}
+
+	if err = requestChangelogCatchUp(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	if h, err = loadChangelogHead(ctx, objLayer, bucket); err != nil || !h.catchUpDue() {
+		t.Errorf("expected a catch-up to be due, got %+v %v", h, err)
+	}
+	// Catching up without changes appends nothing.
+	if err = catchUpChangelog(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got = records(); len(got) != 4 {
+		t.Errorf("expected no new records, got %v", got)
+	}
+}
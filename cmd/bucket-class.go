@@ -0,0 +1,112 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/minio/minio/internal/config/storageclass"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const (
+	bucketClassConfigFile = "class.json"
+
+	bucketClassStandard   = "standard"
+	bucketClassLowLatency = "low-latency"
+
+	// lowLatencyPoolTag is the tag of the pools, of NVMe drives, the
+	// objects of low latency buckets are placed on.
+	lowLatencyPoolTag = "nvme"
+)
+
+// BucketClassConfig - the class of a bucket. The objects of a bucket of
+// the low-latency class are placed on the pools tagged nvme, written with
+// the parity of the REDUCED_REDUNDANCY storage class and committed
+// without syncing them to the drives, they may be lost or corrupted by a
+// power loss or the crash of a node.
+type BucketClassConfig struct {
+	Class string `json:"class"`
+}
+
+func parseBucketClassConfig(bucket string, data []byte) (*BucketClassConfig, error) {
+	cfg := &BucketClassConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	switch cfg.Class {
+	case bucketClassStandard, bucketClassLowLatency:
+	default:
+		return cfg, fmt.Errorf("Invalid class '%s' of bucket %s", cfg.Class, bucket)
+	}
+	return cfg, nil
+}
+
+// lowLatencyBucket returns whether bucket is of the low-latency class.
+func lowLatencyBucket(bucket string) bool {
+	cfg := globalBucketMetadataSys.GetClassConfig(bucket)
+	return cfg != nil && cfg.Class == bucketClassLowLatency
+}
+
+// applyBucketClass sets opts for the new objects of bucket to be written
+// with the parity of its class, they are not synced as the fsync policy
+// of low latency buckets is never.
+func applyBucketClass(bucket string, opts *ObjectOptions) {
+	if !lowLatencyBucket(bucket) {
+		return
+	}
+	if opts.UserDefined == nil {
+		opts.UserDefined = make(map[string]string)
+	}
+	opts.UserDefined[xhttp.AmzStorageClass] = storageclass.RRS
+}
+
+// bucketPlacementTags returns the tags of the pools new objects of bucket
+// are placed on, nil if it is not pinned to pools.
+func bucketPlacementTags(bucket string) []string {
+	var tags []string
+	if placement := globalBucketMetadataSys.GetPlacementConfig(bucket); placement != nil {
+		tags = placement.Tags
+	}
+	if lowLatencyBucket(bucket) {
+		tags = append(append([]string{}, tags...), lowLatencyPoolTag)
+	}
+	return tags
+}
+
+// BucketClassInfo - the class of a bucket and how the new objects of the
+// bucket are written.
+type BucketClassInfo struct {
+	Class       string      `json:"class"`
+	PoolTags    []string    `json:"poolTags,omitempty"`
+	Parity      int         `json:"parity,omitempty"`
+	FsyncPolicy fsyncPolicy `json:"fsyncPolicy"`
+}
+
+func getBucketClassInfo(bucket string) BucketClassInfo {
+	if !lowLatencyBucket(bucket) {
+		return BucketClassInfo{Class: bucketClassStandard, FsyncPolicy: bucketFsyncPolicy(bucket)}
+	}
+	return BucketClassInfo{
+		Class:       bucketClassLowLatency,
+		PoolTags:    bucketPlacementTags(bucket),
+		Parity:      globalStorageClass.GetParityForSC(storageclass.RRS),
+		FsyncPolicy: fsyncNever,
+	}
+}
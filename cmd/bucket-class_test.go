@@ -0,0 +1,117 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/minio/minio/internal/config/storageclass"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestParseBucketClassConfig(t *testing.T) {
+	for _, class := range []string{bucketClassStandard, bucketClassLowLatency} {
+		cfg, err := parseBucketClassConfig("bucket", []byte(`{"class":"`+class+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Class != class {
+			t.Fatalf("expected class %s, got %s", class, cfg.Class)
+		}
+	}
+	for _, data := range []string{`{}`, `{"class":"express"}`, `{"class":`} {
+		if _, err := parseBucketClassConfig("bucket", []byte(data)); err == nil {
+			t.Fatalf("expected %s to fail", data)
+		}
+	}
+}
+
+func TestLowLatencyBucketPlacement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	restoreGlobalStorageClass := globalStorageClass
+	defer func() { globalStorageClass = restoreGlobalStorageClass }()
+	globalStorageClass = storageclass.Config{RRS: storageclass.StorageClass{Parity: 1}}
+
+	z, fsDirs, err := preparePlacementPools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	defer globalPoolTags.set(nil)
+
+	oldMetadataSys := globalBucketMetadataSys
+	defer func() { globalBucketMetadataSys = oldMetadataSys }()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+
+	const bucket = "bucket"
+	if err = z.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	meta := newBucketMetadata(bucket)
+	meta.classConfig = &BucketClassConfig{Class: bucketClassLowLatency}
+	globalBucketMetadataSys.Set(bucket, meta)
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	if _, err = z.PutObject(ctx, bucket, "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	uploadID, err := z.NewMultipartUpload(ctx, bucket, "multipart", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi, err := z.PutObjectPart(ctx, bucket, "multipart", uploadID, 1, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = z.CompleteMultipartUpload(ctx, bucket, "multipart", uploadID, []CompletePart{{PartNumber: 1, ETag: pi.ETag}}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The objects are written to the nvme pool with the parity of the
+	// REDUCED_REDUNDANCY storage class.
+	for _, object := range []string{"object", "multipart"} {
+		if _, err = z.serverPools[0].getHashedSet(object).GetObjectInfo(ctx, bucket, object, ObjectOptions{}); !isErrObjectNotFound(err) {
+			t.Errorf("%s: expected no object on pool 0, got %v", object, err)
+		}
+		fi, _, _, err := z.serverPools[1].getHashedSet(object).getObjectFileInfo(ctx, bucket, object, ObjectOptions{}, false)
+		if err != nil {
+			t.Fatalf("%s: expected the object on pool 1, got %v", object, err)
+		}
+		if fi.Erasure.ParityBlocks != 1 || fi.Metadata[xhttp.AmzStorageClass] != storageclass.RRS {
+			t.Errorf("%s: expected parity 1 of %s, got %d of %q", object, storageclass.RRS, fi.Erasure.ParityBlocks, fi.Metadata[xhttp.AmzStorageClass])
+		}
+
+		gr, err := z.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: the data differs", object)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	bucketCommitsPrefix = "commits"
+
+	// Internal metadata naming the commit which published an object,
+	// it is cleared once the commit is complete.
+	commitIDKey = ReservedMetadataPrefixLower + "commit-id"
+
+	// Maximum number of uploads published by a single commit.
+	maxCommitUploads = 1000
+
+	commitRecoveryInterval = time.Hour
+	// Pending commits older than this were abandoned and are rolled back.
+	commitPendingExpiry = time.Hour
+)
+
+// States of a commit record.
+const (
+	commitPending   = "pending"
+	commitCommitted = "committed"
+)
+
+var commitRecoveryLeaderLockTimeout = newDynamicTimeout(30*time.Second, 10*time.Second)
+
+// CommitUpload - a staged multipart upload published by a commit.
+type CommitUpload struct {
+	Key      string         `xml:"Key"`
+	UploadID string         `xml:"UploadId"`
+	Parts    []CompletePart `xml:"Part"`
+}
+
+// CommitObjectsRequest - the manifest of uploads published atomically.
+type CommitObjectsRequest struct {
+	XMLName xml.Name       `xml:"Commit"`
+	Uploads []CommitUpload `xml:"Upload"`
+}
+
+// CommittedObject - an object published by a commit.
+type CommittedObject struct {
+	Key       string `xml:"Key" json:"key"`
+	VersionID string `xml:"VersionId,omitempty" json:"versionId,omitempty"`
+	ETag      string `xml:"ETag,omitempty" json:"etag,omitempty"`
+}
+
+// CommitObjectsResponse - the objects published by a commit.
+type CommitObjectsResponse struct {
+	XMLName  xml.Name          `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CommitResult" json:"-"`
+	CommitID string            `xml:"CommitId"`
+	Objects  []CommittedObject `xml:"Object"`
+}
+
+// bucketCommit is the record of a commit, saving it in the committed
+// state is the point at which all of its objects become visible.
+type bucketCommit struct {
+	ID      string            `json:"id"`
+	Bucket  string            `json:"bucket"`
+	State   string            `json:"state"`
+	Created time.Time         `json:"created"`
+	Objects []CommittedObject `json:"objects"`
+}
+
+// Commits known to be committed, their objects are visible even though
+// the commit marker was not cleared from them yet.
+var committedBucketCommits sync.Map
+
+func bucketCommitFile(bucket, id string) string {
+	return path.Join(bucketConfigPrefix, bucket, bucketCommitsPrefix, id+".json")
+}
+
+func saveBucketCommit(ctx context.Context, objAPI ObjectLayer, c *bucketCommit) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, bucketCommitFile(c.Bucket, c.ID), data)
+}
+
+func readBucketCommit(ctx context.Context, objAPI ObjectLayer, bucket, id string) (*bucketCommit, error) {
+	data, err := readConfig(ctx, objAPI, bucketCommitFile(bucket, id))
+	if err != nil {
+		return nil, err
+	}
+	c := &bucketCommit{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// commitObjects completes the staged uploads of req and publishes the
+// resulting objects atomically, either all of them become visible or
+// none. It returns the commit id along with the published objects. Existing objects of unversioned buckets cannot be replaced,
+// since that could not be undone.
+func commitObjects(ctx context.Context, objAPI ObjectLayer, bucket string, req CommitObjectsRequest) (string, []ObjectInfo, error) {
+	if _, ok := objAPI.(*erasureServerPools); !ok {
+		return "", nil, NotImplemented{}
+	}
+
+	versioned := globalBucketVersioningSys.Enabled(bucket)
+	versionSuspended := globalBucketVersioningSys.Suspended(bucket)
+
+	c := &bucketCommit{
+		ID:      mustGetUUID(),
+		Bucket:  bucket,
+		State:   commitPending,
+		Created: UTCNow(),
+	}
+	for _, u := range req.Uploads {
+		c.Objects = append(c.Objects, CommittedObject{Key: u.Key})
+	}
+	if err := saveBucketCommit(ctx, objAPI, c); err != nil {
+		return "", nil, err
+	}
+
+	objects := make([]ObjectInfo, 0, len(req.Uploads))
+	for i, u := range req.Uploads {
+		opts := ObjectOptions{
+			UserDefined:      map[string]string{commitIDKey: c.ID},
+			Versioned:        versioned,
+			VersionSuspended: versionSuspended,
+		}
+		if !versioned {
+			opts.CheckPrecondFn = func(oi ObjectInfo) bool {
+				return oi.Name != ""
+			}
+		}
+		oi, err := objAPI.CompleteMultipartUpload(ctx, bucket, u.Key, u.UploadID, u.Parts, opts)
+		if err != nil {
+			logger.LogIf(ctx, rollbackBucketCommit(ctx, objAPI, c))
+			return "", nil, err
+		}
+		c.Objects[i].VersionID = oi.VersionID
+		c.Objects[i].ETag = oi.ETag
+		objects = append(objects, oi)
+	}
+
+	c.State = commitCommitted
+	if err := saveBucketCommit(ctx, objAPI, c); err != nil {
+		logger.LogIf(ctx, rollbackBucketCommit(ctx, objAPI, c))
+		return "", nil, err
+	}
+	committedBucketCommits.Store(c.ID, struct{}{})
+
+	// Left over markers are cleared by the commit recovery.
+	logger.LogIf(ctx, finishBucketCommit(ctx, objAPI, c))
+
+	return c.ID, objects, nil
+}
+
+// finishBucketCommit clears the commit marker of the objects of a
+// committed commit, then removes its record.
+func finishBucketCommit(ctx context.Context, objAPI ObjectLayer, c *bucketCommit) error {
+	for _, o := range c.Objects {
+		oi, err := objAPI.GetObjectInfo(ctx, c.Bucket, o.Key, ObjectOptions{VersionID: o.VersionID})
+		if err != nil {
+			if isErrObjectNotFound(err) || isErrVersionNotFound(err) {
+				// Deleted since.
+				continue
+			}
+			return err
+		}
+		if oi.UserDefined[commitIDKey] != c.ID {
+			continue
+		}
+		if _, err = objAPI.PutObjectMetadata(ctx, c.Bucket, o.Key, ObjectOptions{
+			VersionID:   oi.VersionID,
+			MTime:       oi.ModTime,
+			UserDefined: map[string]string{commitIDKey: ""},
+		}); err != nil {
+			return err
+		}
+	}
+	if err := deleteConfig(ctx, objAPI, bucketCommitFile(c.Bucket, c.ID)); err != nil && err != errConfigNotFound {
+		return err
+	}
+	committedBucketCommits.Delete(c.ID)
+	return nil
+}
+
+// rollbackBucketCommit removes the objects already published by a commit
+// which did not get committed, then removes its record.
+func rollbackBucketCommit(ctx context.Context, objAPI ObjectLayer, c *bucketCommit) error {
+	for _, o := range c.Objects {
+		oi, err := objAPI.GetObjectInfo(ctx, c.Bucket, o.Key, ObjectOptions{VersionID: o.VersionID})
+		if err != nil {
+			if isErrObjectNotFound(err) || isErrVersionNotFound(err) {
+				// Never published.
+				continue
+			}
+			return err
+		}
+		if oi.UserDefined[commitIDKey] != c.ID {
+			continue
+		}
+		if _, err = objAPI.DeleteObject(ctx, c.Bucket, o.Key, ObjectOptions{
+			VersionID: oi.VersionID,
+		}); err != nil && !isErrObjectNotFound(err) && !isErrVersionNotFound(err) {
+			return err
+		}
+	}
+	if err := deleteConfig(ctx, objAPI, bucketCommitFile(c.Bucket, c.ID)); err != nil && err != errConfigNotFound {
+		return err
+	}
+	return nil
+}
+
+// commitHidden returns true when oi was published by a commit which is
+// not committed, such objects are hidden from reads and listings.
+func commitHidden(ctx context.Context, objAPI ObjectLayer, oi ObjectInfo) bool {
+	id := oi.UserDefined[commitIDKey]
+	if id == "" {
+		return false
+	}
+	if _, ok := committedBucketCommits.Load(id); ok {
+		return false
+	}
+	c, err := readBucketCommit(ctx, objAPI, oi.Bucket, id)
+	if err != nil || c.State != commitCommitted {
+		return true
+	}
+	committedBucketCommits.Store(id, struct{}{})
+	return false
+}
+
+// commitVisibleVersion returns the version of object to serve in place
+// of one hidden by a pending commit, requested with opts. In versioned
+// buckets the versions published before the commit remain visible.
+func commitVisibleVersion(ctx context.Context, objAPI ObjectLayer, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	if opts.VersionID != "" {
+		return ObjectInfo{}, VersionNotFound{Bucket: bucket, Object: object, VersionID: opts.VersionID}
+	}
+	notFound := ObjectNotFound{Bucket: bucket, Object: object}
+	if !globalBucketVersioningSys.Enabled(bucket) && !globalBucketVersioningSys.Suspended(bucket) {
+		return ObjectInfo{}, notFound
+	}
+	marker, versionMarker := "", ""
+	for {
+		loi, err := objAPI.ListObjectVersions(ctx, bucket, object, marker, versionMarker, "", maxObjectList)
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+		for _, oi := range loi.Objects {
+			if oi.Name != object {
+				// Versions are listed by key, object has no other.
+				return ObjectInfo{}, notFound
+			}
+			if commitHidden(ctx, objAPI, oi) {
+				continue
+			}
+			if oi.DeleteMarker {
+				return ObjectInfo{}, notFound
+			}
+			return oi, nil
+		}
+		if !loi.IsTruncated {
+			return ObjectInfo{}, notFound
+		}
+		marker, versionMarker = loi.NextMarker, loi.NextVersionIDMarker
+	}
+}
+
+// filterCommitHidden removes the objects hidden by a pending commit, in
+// versioned buckets they are replaced by their latest visible version.
+func filterCommitHidden(ctx context.Context, objAPI ObjectLayer, objects []ObjectInfo) []ObjectInfo {
+	n := 0
+	for _, oi := range objects {
+		if commitHidden(ctx, objAPI, oi) {
+			visible, err := commitVisibleVersion(ctx, objAPI, oi.Bucket, oi.Name, ObjectOptions{})
+			if err != nil {
+				continue
+			}
+			oi = visible
+		}
+		objects[n] = oi
+		n++
+	}
+	return objects[:n]
+}
+
+// filterCommitHiddenVersions removes the versions hidden by a pending
+// commit, the next version of a key replacing its hidden latest one
+// becomes the latest.
+func filterCommitHiddenVersions(ctx context.Context, objAPI ObjectLayer, versions []ObjectInfo) []ObjectInfo {
+	n := 0
+	var hiddenLatest string
+	for _, oi := range versions {
+		if commitHidden(ctx, objAPI, oi) {
+			if oi.IsLatest {
+				hiddenLatest = oi.Name
+			}
+			continue
+		}
+		if hiddenLatest != "" && oi.Name == hiddenLatest {
+			oi.IsLatest = true
+		}
+		hiddenLatest = ""
+		versions[n] = oi
+		n++
+	}
+	return versions[:n]
+}
+
+// recoverBucketCommits finishes the committed commits of bucket and
+// rolls back the abandoned pending ones.
+func recoverBucketCommits(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	prefix := path.Join(bucketConfigPrefix, bucket, bucketCommitsPrefix) + SlashSeparator
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range loi.Objects {
+			id := path.Base(oi.Name)
+			c, err := readBucketCommit(ctx, objAPI, bucket, id[:len(id)-len(path.Ext(id))])
+			if err != nil {
+				logger.LogIf(ctx, err)
+				continue
+			}
+			switch {
+			case c.State == commitCommitted:
+				logger.LogIf(ctx, finishBucketCommit(ctx, objAPI, c))
+			case UTCNow().Sub(c.Created) > commitPendingExpiry:
+				logger.LogIf(ctx, rollbackBucketCommit(ctx, objAPI, c))
+			}
+		}
+		if !loi.IsTruncated {
+			return nil
+		}
+		marker = loi.NextMarker
+	}
+}
+
+// initCommitRecovery starts the routine cleaning up after interrupted commits.
+func initCommitRecovery(ctx context.Context, objAPI ObjectLayer) {
+	go runCommitRecovery(ctx, objAPI)
+}
+
+// runCommitRecovery periodically recovers the commits of all buckets,
+// only the node holding the leader lock does the work.
+func runCommitRecovery(ctx context.Context, objAPI ObjectLayer) {
+	locker := objAPI.NewNSLock(minioMetaBucket, "runCommitRecovery.lock")
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		lkctx, err := locker.GetLock(ctx, commitRecoveryLeaderLockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Duration(r.Float64() * float64(commitRecoveryInterval)))
+			continue
+		}
+		ctx = lkctx.Context()
+		defer lkctx.Cancel()
+		break
+		// No unlock for "leader" lock.
+	}
+
+	recoveryTimer := time.NewTimer(commitRecoveryInterval)
+	defer recoveryTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-recoveryTimer.C:
+			buckets, err := objAPI.ListBuckets(ctx)
+			if err != nil {
+				logger.LogIf(ctx, err)
+			}
+			for _, bucket := range buckets {
+				logger.LogIf(ctx, recoverBucketCommits(ctx, objAPI, bucket.Name))
+			}
+			recoveryTimer.Reset(commitRecoveryInterval)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func stageCommitUpload(ctx context.Context, t *testing.T, objLayer ObjectLayer, bucket, object string, data []byte) CommitUpload {
+	uploadID, err := objLayer.NewMultipartUpload(ctx, bucket, object, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi, err := objLayer.PutObjectPart(ctx, bucket, object, uploadID, 1, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return CommitUpload{
+		Key:      object,
+		UploadID: uploadID,
+		Parts:    []CompletePart{{PartNumber: 1, ETag: pi.ETag}},
+	}
+}
+
+func TestCommitObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := CommitObjectsRequest{
+		Uploads: []CommitUpload{
+			stageCommitUpload(ctx, t, objLayer, bucket, "table/data-1", []byte("first")),
+			stageCommitUpload(ctx, t, objLayer, bucket, "table/data-2", []byte("second")),
+		},
+	}
+	id, objects, err := commitObjects(ctx, objLayer, bucket, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 committed objects, got %d", len(objects))
+	}
+	for _, u := range req.Uploads {
+		oi, err := objLayer.GetObjectInfo(ctx, bucket, u.Key, ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if oi.UserDefined[commitIDKey] != "" || commitHidden(ctx, objLayer, oi) {
+			t.Fatalf("expected %s to be visible after commit %s", u.Key, id)
+		}
+	}
+	if _, err = readBucketCommit(ctx, objLayer, bucket, id); err != errConfigNotFound {
+		t.Fatalf("expected finished commit record to be removed, got %v", err)
+	}
+
+	// An existing object of an unversioned bucket cannot be replaced,
+	// the objects published before the failure are rolled back.
+	req = CommitObjectsRequest{
+		Uploads: []CommitUpload{
+			stageCommitUpload(ctx, t, objLayer, bucket, "table/data-3", []byte("third")),
+			stageCommitUpload(ctx, t, objLayer, bucket, "table/data-1", []byte("replaced")),
+		},
+	}
+	if _, _, err = commitObjects(ctx, objLayer, bucket, req); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected precondition failure, got %v", err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "table/data-3", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected rolled back object to be gone, got %v", err)
+	}
+}
+
+func TestCommitHiddenPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	c := &bucketCommit{ID: mustGetUUID(), Bucket: "bucket", State: commitPending, Created: UTCNow()}
+	if err = saveBucketCommit(ctx, objLayer, c); err != nil {
+		t.Fatal(err)
+	}
+	oi := ObjectInfo{Bucket: c.Bucket, Name: "object", UserDefined: map[string]string{commitIDKey: c.ID}}
+	if !commitHidden(ctx, objLayer, oi) {
+		t.Fatal("expected object of a pending commit to be hidden")
+	}
+	if commitHidden(ctx, objLayer, ObjectInfo{Bucket: c.Bucket, Name: "object"}) {
+		t.Fatal("expected object outside of any commit to be visible")
+	}
+
+	// A hidden version is not found, the previous version of its key
+	// becomes the latest in listings.
+	if _, err = commitVisibleVersion(ctx, objLayer, c.Bucket, "object", ObjectOptions{VersionID: "v2"}); !isErrVersionNotFound(err) {
+		t.Fatalf("expected hidden version to be not found, got %v", err)
+	}
+	hidden := oi
+	hidden.VersionID, hidden.IsLatest = "v2", true
+	versions := filterCommitHiddenVersions(ctx, objLayer, []ObjectInfo{
+		hidden,
+		{Bucket: c.Bucket, Name: "object", VersionID: "v1"},
+		{Bucket: c.Bucket, Name: "other", VersionID: "v3", IsLatest: true},
+		{Bucket: c.Bucket, Name: "other", VersionID: "v4"},
+	})
+	if len(versions) != 3 || versions[0].VersionID != "v1" || !versions[0].IsLatest ||
+		!versions[1].IsLatest || versions[2].IsLatest {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+
+	c.State = commitCommitted
+	if err = saveBucketCommit(ctx, objLayer, c); err != nil {
+		t.Fatal(err)
+	}
+	if commitHidden(ctx, objLayer, oi) {
+		t.Fatal("expected object of a committed commit to be visible")
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio/internal/config/compress"
+	"github.com/minio/minio/internal/crypto"
+)
+
+const bucketCompressionConfigFile = "compression.json"
+
+// Compression algorithms of a bucket compression config.
+const (
+	compressionS2   = "s2"
+	compressionZstd = "zstd"
+)
+
+// Highest compression levels of each algorithm.
+const (
+	maxS2CompressionLevel   = 3
+	maxZstdCompressionLevel = 22
+)
+
+// The releases of the nodes are checked again after this interval
+// before objects are compressed with zstd.
+const zstdReleaseCheckInterval = time.Minute
+
+// BucketCompressionConfig - compression policy of a bucket, overriding
+// the server compression settings for its new objects. Level selects
+// the s2 default (1), better (2) or best (3) compression, or the zstd
+// compression level from 1 to 22. Empty Extensions and MimeTypes
+// compress all objects but already compressed formats.
+type BucketCompressionConfig struct {
+	Enabled        bool     `json:"enabled"`
+	Algorithm      string   `json:"algorithm,omitempty"`
+	Level          int      `json:"level,omitempty"`
+	AllowEncrypted bool     `json:"allowEncryption,omitempty"`
+	Extensions     []string `json:"extensions,omitempty"`
+	MimeTypes      []string `json:"mimeTypes,omitempty"`
+}
+
+func parseBucketCompressionConfig(bucket string, data []byte) (*BucketCompressionConfig, error) {
+	cfg := &BucketCompressionConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	maxLevel := maxS2CompressionLevel
+	switch cfg.Algorithm {
+	case "", compressionS2:
+	case compressionZstd:
+		maxLevel = maxZstdCompressionLevel
+	default:
+		return cfg, fmt.Errorf("Unknown compression algorithm %q for bucket %s", cfg.Algorithm, bucket)
+	}
+	if cfg.Level < 0 || cfg.Level > maxLevel {
+		return cfg, fmt.Errorf("Invalid %s compression level %d for bucket %s", cfg.Algorithm, cfg.Level, bucket)
+	}
+	return cfg, nil
+}
+
+// compressor returns the compressor of the objects of the bucket.
+func (c BucketCompressionConfig) compressor() objectCompressor {
+	if c.Algorithm == compressionZstd {
+		return objectCompressor{algorithm: compressionAlgorithmZstd, level: c.Level}
+	}
+	return objectCompressor{algorithm: compressionAlgorithmV2, level: c.Level}
+}
+
+// objectCompressor compresses objects with the algorithm recorded in
+// their compression metadata.
+type objectCompressor struct {
+	algorithm string
+	level     int
+}
+
+// compressorFor returns the compressor of object and whether it should
+// be compressed, as configured for bucket or else for the server.
+func compressorFor(header http.Header, bucket, object string) (objectCompressor, bool) {
+	bcfg, _ := globalBucketMetadataSys.GetCompressionConfig(bucket)
+	if bcfg == nil {
+		return objectCompressor{algorithm: compressionAlgorithmV2}, isCompressible(header, object)
+	}
+
+	cfg := compress.Config{
+		Enabled:        bcfg.Enabled,
+		AllowEncrypted: bcfg.AllowEncrypted,
+		Extensions:     bcfg.Extensions,
+		MimeTypes:      bcfg.MimeTypes,
+	}
+	_, ok := crypto.IsRequested(header)
+	if !cfg.Enabled || (ok && !cfg.AllowEncrypted) || excludeForCompression(header, object, cfg) {
+		return objectCompressor{}, false
+	}
+	c := bcfg.compressor()
+	// Nodes of older releases cannot read zstd, s2 is used until
+	// all nodes of the cluster are upgraded.
+	if c.algorithm == compressionAlgorithmZstd && !globalZstdReleases.supported() {
+		c = objectCompressor{algorithm: compressionAlgorithmV2}
+	}
+	return c, true
+}
+
+// zstdReleases tracks whether all nodes run a release reading zstd
+// compressed objects, the release of this node or a later one.
+type zstdReleases struct {
+	mu      sync.Mutex
+	checked time.Time
+	ok      bool
+}
+
+var globalZstdReleases = &zstdReleases{}
+
+func (z *zstdReleases) supported() bool {
+	if !globalIsDistErasure || globalNotificationSys == nil {
+		return true
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if time.Since(z.checked) < zstdReleaseCheckInterval {
+		return z.ok
+	}
+	z.ok = releasesReadZstd(Version, globalNotificationSys.ServerInfo())
+	z.checked = time.Now()
+	return z.ok
+}
+
+// releasesReadZstd returns whether all peers run the release version
+// or a later one, offline peers may run any release.
+func releasesReadZstd(version string, peers []madmin.ServerProperties) bool {
+	local, lerr := time.Parse(time.RFC3339, version)
+	for _, peer := range peers {
+		if peer.State != string(madmin.ItemOnline) {
+			return false
+		}
+		if peer.Version == version {
+			continue
+		}
+		release, err := time.Parse(time.RFC3339, peer.Version)
+		if lerr != nil || err != nil || release.Before(local) {
+			return false
+		}
+	}
+	return true
+}
+
+// partCompressor returns the compressor of the parts of a multipart
+// upload compressed with algorithm, at the level configured for bucket.
+func partCompressor(bucket, algorithm string) objectCompressor {
+	c := objectCompressor{algorithm: algorithm}
+	if bcfg, _ := globalBucketMetadataSys.GetCompressionConfig(bucket); bcfg != nil {
+		if bc := bcfg.compressor(); bc.algorithm == algorithm {
+			c.level = bc.level
+		}
+	}
+	return c
+}
+
+// newReader returns the compressed data read from r, see newS2CompressReader.
+func (c objectCompressor) newReader(r io.Reader, on int64) io.ReadCloser {
+	if c.algorithm == compressionAlgorithmZstd {
+		return newCompressReader(r, on, func(w io.Writer) (io.WriteCloser, error) {
+			level := zstd.SpeedDefault
+			if c.level > 0 {
+				level = zstd.EncoderLevelFromZstd(c.level)
+			}
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+		})
+	}
+	if c.level == 0 {
+		return newS2CompressReader(r, on)
+	}
+	return newCompressReader(r, on, func(w io.Writer) (io.WriteCloser, error) {
+		var opts []s2.WriterOption
+		switch c.level {
+		case 2:
+			opts = append(opts, s2.WriterBetterCompression())
+		case 3:
+			opts = append(opts, s2.WriterBestCompression())
+		}
+		return s2.NewWriter(w, opts...), nil
+	})
+}
+
+// newDecompressReader returns the decompressed data read from r
+// compressed with algorithm, after skipping the first skip bytes.
+// The returned function releases the resources of the decompressor.
+func newDecompressReader(algorithm string, r io.Reader, skip int64) (io.Reader, func(), error) {
+	if algorithm == compressionAlgorithmZstd {
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if skip > 0 {
+			if _, err = io.CopyN(ioutil.Discard, dec, skip); err != nil {
+				dec.Close()
+				return nil, nil, err
+			}
+		}
+		return dec, dec.Close, nil
+	}
+
+	s2Reader := s2.NewReader(r)
+	if skip > 0 {
+		if err := s2Reader.Skip(skip); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s2Reader, func() {}, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestParseBucketCompressionConfig(t *testing.T) {
+	testCases := []struct {
+		data      string
+		algorithm string
+		valid     bool
+	}{
+		{`{"enabled": true}`, compressionAlgorithmV2, true},
+		{`{"enabled": true, "algorithm": "s2", "level": 3}`, compressionAlgorithmV2, true},
+		{`{"enabled": true, "algorithm": "zstd", "level": 19}`, compressionAlgorithmZstd, true},
+		{`{"enabled": true, "algorithm": "s2", "level": 4}`, "", false},
+		{`{"enabled": true, "algorithm": "zstd", "level": -1}`, "", false},
+		{`{"enabled": true, "algorithm": "lz4"}`, "", false},
+	}
+	for i, tc := range testCases {
+		cfg, err := parseBucketCompressionConfig("bucket", []byte(tc.data))
+		if (err == nil) != tc.valid {
+			t.Fatalf("case %d: expected valid %v, got %v", i, tc.valid, err)
+		}
+		if err == nil && cfg.compressor().algorithm != tc.algorithm {
+			t.Errorf("case %d: expected algorithm %s, got %s", i, tc.algorithm, cfg.compressor().algorithm)
+		}
+	}
+}
+
+func TestReleasesReadZstd(t *testing.T) {
+	const version = "2021-11-24T23:19:33Z"
+	peer := func(version, state string) madmin.ServerProperties {
+		return madmin.ServerProperties{Version: version, State: state}
+	}
+	online := string(madmin.ItemOnline)
+	testCases := []struct {
+		version string
+		peers   []madmin.ServerProperties
+		want    bool
+	}{
+		{version, nil, true},
+		{version, []madmin.ServerProperties{peer(version, online), peer("2021-12-01T00:00:00Z", online)}, true},
+		{version, []madmin.ServerProperties{peer(version, online), peer("2021-11-01T00:00:00Z", online)}, false},
+		{version, []madmin.ServerProperties{peer(version, string(madmin.ItemOffline))}, false},
+		{version, []madmin.ServerProperties{peer("DEVELOPMENT.GOGET", online)}, false},
+		{"DEVELOPMENT.GOGET", []madmin.ServerProperties{peer("DEVELOPMENT.GOGET", online)}, true},
+		{"DEVELOPMENT.GOGET", []madmin.ServerProperties{peer(version, online)}, false},
+	}
+	for i, tc := range testCases {
+		if got := releasesReadZstd(tc.version, tc.peers); got != tc.want {
+			t.Errorf("Test %d: expected %v, got %v", i+1, tc.want, got)
+		}
+	}
+}
+
+func TestObjectCompressorRoundtrip(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible content "), 10000)
+	const skip = 12345
+	for _, c := range []objectCompressor{
+		{algorithm: compressionAlgorithmV2},
+		{algorithm: compressionAlgorithmV2, level: 3},
+		{algorithm: compressionAlgorithmZstd},
+		{algorithm: compressionAlgorithmZstd, level: 19},
+	} {
+		r := c.newReader(bytes.NewReader(data), int64(len(data)))
+		compressed, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed) >= len(data) {
+			t.Errorf("%s level %d: expected the data to be compressed", c.algorithm, c.level)
+		}
+
+		dec, closer, err := newDecompressReader(c.algorithm, bytes.NewReader(compressed), skip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(dec)
+		closer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data[skip:]) {
+			t.Errorf("%s level %d: roundtrip mismatch", c.algorithm, c.level)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Last entry of an export listing the objects which could not be
+	// exported, only present if there are any.
+	exportErrorsFile = ".minio-export-errors.txt"
+
+	exportCompressionZstd = "zstd"
+)
+
+// exportObjects writes the objects listed by keys to w as a tar archive,
+// zstd compressed if compression is exportCompressionZstd. Objects which
+// cannot be opened are skipped and listed in exportErrorsFile, since the
+// response is already under way. Listing and write errors abort the
+// export, leaving a truncated archive.
+func exportObjects(ctx context.Context, w io.Writer, compression string, keys *selectObjectKeys, open func(object string) (*GetObjectReader, error)) (err error) {
+	if compression == exportCompressionZstd {
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := enc.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = enc
+	}
+
+	tw := tar.NewWriter(w)
+	var errs bytes.Buffer
+	for {
+		object, ok, err := keys.next()
+		if !ok {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		gr, err := open(object)
+		if err != nil {
+			fmt.Fprintf(&errs, "%s: %v\n", object, err)
+			continue
+		}
+		err = exportObject(tw, object, gr)
+		gr.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if errs.Len() > 0 {
+		if err = tw.WriteHeader(&tar.Header{
+			Name:    exportErrorsFile,
+			Mode:    0644,
+			Size:    int64(errs.Len()),
+			ModTime: UTCNow(),
+		}); err != nil {
+			return err
+		}
+		if _, err = tw.Write(errs.Bytes()); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func exportObject(tw *tar.Writer, object string, gr *GetObjectReader) error {
+	size, err := gr.ObjInfo.GetActualSize()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    object,
+		Mode:    0644,
+		Size:    size,
+		ModTime: gr.ObjInfo.ModTime,
+	}
+	if strings.HasSuffix(object, SlashSeparator) && size == 0 {
+		hdr.Typeflag = tar.TypeDir
+		hdr.Mode = 0755
+	}
+	if err = tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag == tar.TypeDir {
+		return nil
+	}
+	_, err = io.CopyN(tw, gr, size)
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestExportObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	objects := map[string]string{
+		"logs/a.txt":     "hello",
+		"logs/sub/b.txt": "world!",
+		"logs/denied":    "secret",
+		"other/c.txt":    "not exported",
+	}
+	for object, data := range objects {
+		_, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader([]byte(data)), int64(len(data)), "", ""), ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	open := func(object string) (*GetObjectReader, error) {
+		if object == "logs/denied" {
+			return nil, errors.New("Access Denied.")
+		}
+		return objLayer.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, ObjectOptions{})
+	}
+
+	for _, compression := range []string{"", exportCompressionZstd} {
+		var buf bytes.Buffer
+		keys := newSelectPrefixKeys(ctx, objLayer, bucket, "logs/")
+		if err = exportObjects(ctx, &buf, compression, keys, open); err != nil {
+			t.Fatal(err)
+		}
+
+		var r io.Reader = &buf
+		if compression == exportCompressionZstd {
+			dec, err := zstd.NewReader(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer dec.Close()
+			r = dec
+		}
+
+		got := make(map[string]string)
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[hdr.Name] = string(data)
+		}
+
+		if len(got) != 3 {
+			t.Fatalf("compression %q: expected 3 entries, got %v", compression, got)
+		}
+		for _, object := range []string{"logs/a.txt", "logs/sub/b.txt"} {
+			if got[object] != objects[object] {
+				t.Errorf("compression %q: expected %q for %s, got %q", compression, objects[object], object, got[object])
+			}
+		}
+		if got[exportErrorsFile] != "logs/denied: Access Denied.\n" {
+			t.Errorf("compression %q: unexpected export errors %q", compression, got[exportErrorsFile])
+		}
+	}
+}
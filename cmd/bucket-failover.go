@@ -0,0 +1,438 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7/pkg/replication"
+	sreplication "github.com/minio/minio/internal/bucket/replication"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// defaultFailoverDrainTimeout is the default time waited for the
+	// replication of a frozen bucket to drain.
+	defaultFailoverDrainTimeout = 5 * time.Minute
+	// failoverDrainInterval is the interval the remaining objects are
+	// listed at while draining.
+	failoverDrainInterval = 5 * time.Second
+	// maxFailoverReportedObjects caps the objects listed as not
+	// replicated in a failover report.
+	maxFailoverReportedObjects = 10000
+)
+
+var (
+	errFailoverTargetNotFound = errors.New("replication target not found on the bucket")
+	errFailoverBucketFrozen   = errors.New("bucket is archived or already failing over")
+)
+
+// BucketFailoverPeer - a deployment as reached from the other side of
+// the failover, with the credentials replicating to it.
+type BucketFailoverPeer struct {
+	Endpoint  string `json:"endpoint"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+}
+
+// BucketFailoverRequest - promotes the replica of a bucket to primary.
+type BucketFailoverRequest struct {
+	// Arn is the replication target of the bucket promoted.
+	Arn string `json:"arn"`
+	// Timeout of waiting for the replication to drain, 5m by default.
+	Timeout string `json:"timeout,omitempty"`
+	// Force promotes the replica even if the replication did not
+	// drain in time, the objects not replicated are reported.
+	Force bool `json:"force,omitempty"`
+	// ReplicaAdmin are the admin credentials of the replica deployment.
+	ReplicaAdmin madmin.Credentials `json:"replicaAdmin"`
+	// Primary is this deployment as reached from the replica, the
+	// promoted replica replicates back to it.
+	Primary BucketFailoverPeer `json:"primary"`
+}
+
+// BucketPromoteRequest - sent to the replica deployment to replicate
+// its bucket back to the former primary.
+type BucketPromoteRequest struct {
+	Bucket       string             `json:"bucket"`
+	TargetBucket string             `json:"targetBucket"`
+	Target       BucketFailoverPeer `json:"target"`
+}
+
+// BucketPromoteResponse - the replication target created by a promote.
+type BucketPromoteResponse struct {
+	Arn string `json:"arn"`
+}
+
+// BucketFailoverObject - an object version not replicated before the
+// failover.
+type BucketFailoverObject struct {
+	Object       string `json:"object"`
+	VersionID    string `json:"versionId,omitempty"`
+	DeleteMarker bool   `json:"deleteMarker,omitempty"`
+	Status       string `json:"status"`
+}
+
+// BucketFailoverReport - the result of a failover.
+type BucketFailoverReport struct {
+	Bucket   string    `json:"bucket"`
+	Arn      string    `json:"arn"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Drained  bool      `json:"drained"`
+	// Incomplete is set when the last listing of the objects not
+	// replicated was cut by the timeout, NotReplicated is partial then.
+	Incomplete bool `json:"incomplete,omitempty"`
+	Promoted   bool `json:"promoted"`
+	// Frozen is set when this bucket stays read-only after the failover,
+	// until it is unarchived by the operator.
+	Frozen bool `json:"frozen"`
+	// ReverseArn is the replication target of the promoted replica
+	// replicating back to this deployment.
+	ReverseArn    string                 `json:"reverseArn,omitempty"`
+	NotReplicated []BucketFailoverObject `json:"notReplicated"`
+	Truncated     bool                   `json:"truncated,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+func (r BucketFailoverRequest) drainTimeout() (time.Duration, error) {
+	if r.Timeout == "" {
+		return defaultFailoverDrainTimeout, nil
+	}
+	d, err := time.ParseDuration(r.Timeout)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %s", r.Timeout)
+	}
+	return d, nil
+}
+
+// listNotReplicated returns the object versions of bucket pending or
+// failed replication to arn, queueing the failed ones again if retry.
+// When ctx is done the versions listed so far are returned with its
+// error.
+func listNotReplicated(ctx context.Context, objAPI ObjectLayer, bucket, arn string, rcfg replicationConfig, retry bool) ([]BucketFailoverObject, bool, error) {
+	objInfoCh := make(chan ObjectInfo)
+	if err := objAPI.Walk(ctx, bucket, "", objInfoCh, ObjectOptions{WalkVersions: true}); err != nil {
+		return nil, false, err
+	}
+	objects := []BucketFailoverObject{}
+	truncated := false
+	for oi := range objInfoCh {
+		status := oi.TargetReplicationStatus(arn)
+		if !oi.VersionPurgeStatus.Empty() {
+			status = sreplication.StatusType(oi.VersionPurgeStatus)
+		}
+		if status != sreplication.Pending && status != sreplication.Failed {
+			continue
+		}
+		if retry && status == sreplication.Failed && !oi.DeleteMarker && oi.VersionPurgeStatus.Empty() {
+			roi := getHealReplicateObjectInfo(oi, rcfg)
+			scheduleReplication(ctx, roi.ObjectInfo, objAPI, roi.Dsc, roi.OpType)
+		}
+		if len(objects) >= maxFailoverReportedObjects {
+			truncated = true
+			continue
+		}
+		objects = append(objects, BucketFailoverObject{
+			Object:       oi.Name,
+			VersionID:    oi.VersionID,
+			DeleteMarker: oi.DeleteMarker,
+			Status:       string(status),
+		})
+	}
+	return objects, truncated, ctx.Err()
+}
+
+// setBucketFrozen freezes writes to bucket by sealing it read-only as
+// an archived bucket, or unfreezes it.
+func setBucketFrozen(bucket string, frozen bool, by, reason string) error {
+	if !frozen {
+		return globalBucketMetadataSys.Update(bucket, bucketArchiveConfigFile, nil)
+	}
+	data, err := json.Marshal(BucketArchiveState{
+		ArchivedAt: UTCNow(),
+		ArchivedBy: by,
+		Reason:     reason,
+	})
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketArchiveConfigFile, data)
+}
+
+// removeReplicationRules removes the replication rules of bucket to arn,
+// the replication config is removed with its last rule.
+func removeReplicationRules(ctx context.Context, bucket, arn string) error {
+	cfg, err := globalBucketMetadataSys.GetReplicationConfig(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if cfg.RoleArn == arn {
+		return globalBucketMetadataSys.Update(bucket, bucketReplicationConfig, nil)
+	}
+	var rules []sreplication.Rule
+	for _, rule := range cfg.Rules {
+		if rule.Destination.Bucket != arn && rule.Destination.ARN != arn {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return globalBucketMetadataSys.Update(bucket, bucketReplicationConfig, nil)
+	}
+	newCfg := *cfg
+	newCfg.Rules = rules
+	data, err := xml.Marshal(newCfg)
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketReplicationConfig, data)
+}
+
+// failoverBucket freezes writes to bucket, waits for its replication to
+// the target arn to drain, promotes the replica to primary replicating
+// back to this deployment and disables the replication to it. Once the
+// promotion is attempted bucket stays frozen, the operator unarchives it
+// after moving the clients to the replica.
+func failoverBucket(ctx context.Context, objAPI ObjectLayer, bucket, by string, req BucketFailoverRequest) (report BucketFailoverReport, err error) {
+	report = BucketFailoverReport{Bucket: bucket, Arn: req.Arn, Started: UTCNow(), NotReplicated: []BucketFailoverObject{}}
+	promoting := false
+	timeout, err := req.drainTimeout()
+	if err != nil {
+		return report, err
+	}
+
+	rcfg, err := globalBucketMetadataSys.GetReplicationConfig(ctx, bucket)
+	if err != nil {
+		return report, err
+	}
+	tgts, err := globalBucketTargetSys.ListBucketTargets(ctx, bucket)
+	if err != nil {
+		return report, err
+	}
+	var tgt *madmin.BucketTarget
+	for i := range tgts.Targets {
+		if tgts.Targets[i].Arn == req.Arn && tgts.Targets[i].Type == madmin.ReplicationService {
+			tgt = &tgts.Targets[i]
+			break
+		}
+	}
+	if tgt == nil {
+		return report, errFailoverTargetNotFound
+	}
+	if isBucketArchived(bucket) {
+		return report, errFailoverBucketFrozen
+	}
+
+	if err = setBucketFrozen(bucket, true, by, "failover to "+tgt.Endpoint); err != nil {
+		return report, err
+	}
+	// The bucket is unfrozen also if the request is canceled, unless the
+	// replica may have been promoted. Both would take writes otherwise.
+	report.Frozen = true
+	defer func() {
+		if report.Frozen && !promoting {
+			if uerr := setBucketFrozen(bucket, false, by, ""); uerr != nil {
+				logger.LogIf(GlobalContext, uerr)
+				if err == nil {
+					err = uerr
+				}
+			} else {
+				report.Frozen = false
+			}
+		}
+		report.Finished = UTCNow()
+	}()
+
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	retry := true
+	for {
+		objects, truncated, lerr := listNotReplicated(drainCtx, objAPI, bucket, req.Arn, replicationConfig{Config: rcfg, remotes: tgts}, retry)
+		if lerr != nil && drainCtx.Err() == nil {
+			return report, lerr
+		}
+		// The objects a listing cut by the timeout did not reach may be
+		// pending as well.
+		report.NotReplicated, report.Truncated = objects, truncated
+		report.Incomplete = lerr != nil
+		report.Drained = lerr == nil && len(objects) == 0
+		if report.Drained || drainCtx.Err() != nil {
+			break
+		}
+		retry = false
+		select {
+		case <-drainCtx.Done():
+		case <-time.After(failoverDrainInterval):
+		}
+	}
+	if err = ctx.Err(); err != nil {
+		return report, err
+	}
+	if !report.Drained && !req.Force {
+		report.Error = "replication did not drain in time, the replica was not promoted"
+		return report, nil
+	}
+	if report.Incomplete {
+		report.Error = "replication did not drain in time, not all objects not replicated could be listed"
+	}
+
+	scheme := "http"
+	if tgt.Secure {
+		scheme = "https"
+	}
+	data, err := json.Marshal(BucketPromoteRequest{
+		Bucket:       tgt.TargetBucket,
+		TargetBucket: bucket,
+		Target:       req.Primary,
+	})
+	if err != nil {
+		return report, err
+	}
+	data, err = madmin.EncryptData(req.ReplicaAdmin.SecretKey, data)
+	if err != nil {
+		return report, err
+	}
+	// From here on the replica may take writes, this bucket stays frozen.
+	promoting = true
+	var resp BucketPromoteResponse
+	if err = callRemoteAdminAPI(ctx, scheme+"://"+tgt.Endpoint, req.ReplicaAdmin.AccessKey, req.ReplicaAdmin.SecretKey,
+		tgt.Region, http.MethodPost, "/bucket-failover/promote", data, &resp); err != nil {
+		report.Error = fmt.Sprintf("Unable to promote the replica, check its replication before unarchiving this bucket: %v", err)
+		return report, nil
+	}
+	report.Promoted = true
+	report.ReverseArn = resp.Arn
+
+	if err = removeReplicationRules(ctx, bucket, req.Arn); err != nil {
+		report.Error = fmt.Sprintf("Unable to disable the replication to the promoted replica: %v", err)
+	}
+	return report, nil
+}
+
+// promoteBucket makes bucket the primary replicating to the former
+// primary in req, returning the replication target created or reused.
+func promoteBucket(ctx context.Context, req BucketPromoteRequest) (string, error) {
+	tgt := madmin.BucketTarget{
+		SourceBucket: req.Bucket,
+		TargetBucket: req.TargetBucket,
+		Credentials: &madmin.Credentials{
+			AccessKey: req.Target.AccessKey,
+			SecretKey: req.Target.SecretKey,
+		},
+		API:  "s3v4",
+		Type: madmin.ReplicationService,
+	}
+	ep, err := url.Parse(req.Target.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	tgt.Endpoint, tgt.Secure = ep.Host, ep.Scheme == "https"
+
+	targetARN := ""
+	for _, t := range globalBucketTargetSys.ListTargets(ctx, req.Bucket, string(madmin.ReplicationService)) {
+		if t.TargetBucket == tgt.TargetBucket && t.Endpoint == tgt.Endpoint && t.Secure == tgt.Secure {
+			targetARN = t.Arn
+			break
+		}
+	}
+	if targetARN == "" {
+		tgt.Arn = globalBucketTargetSys.getRemoteARN(req.Bucket, &tgt)
+		if err = globalBucketTargetSys.SetTarget(ctx, req.Bucket, &tgt, false); err != nil {
+			return "", err
+		}
+		targets, err := globalBucketTargetSys.ListBucketTargets(ctx, req.Bucket)
+		if err != nil {
+			return "", err
+		}
+		tgtBytes, err := json.Marshal(&targets)
+		if err != nil {
+			return "", err
+		}
+		if err = globalBucketMetadataSys.Update(req.Bucket, bucketTargetsFile, tgtBytes); err != nil {
+			return "", err
+		}
+		targetARN = tgt.Arn
+	}
+
+	// The rule is added through minio-go's replication config as the
+	// server has no add-rule function, see also site replication.
+	replicationConfigS, err := globalBucketMetadataSys.GetReplicationConfig(ctx, req.Bucket)
+	if err != nil {
+		if _, ok := err.(BucketReplicationConfigNotFound); !ok {
+			return "", err
+		}
+	}
+	var replicationConfig replication.Config
+	if replicationConfigS != nil {
+		replCfgSBytes, err := xml.Marshal(replicationConfigS)
+		if err != nil {
+			return "", err
+		}
+		if err = xml.Unmarshal(replCfgSBytes, &replicationConfig); err != nil {
+			return "", err
+		}
+	}
+	err = replicationConfig.AddRule(replication.Options{
+		ID:                      fmt.Sprintf("failover-%s", req.TargetBucket),
+		Priority:                fmt.Sprintf("%d", getPriorityHelper(replicationConfig)),
+		Op:                      replication.AddOption,
+		RuleStatus:              "enable",
+		DestBucket:              targetARN,
+		ReplicateDeletes:        "enable",
+		ReplicateDeleteMarkers:  "enable",
+		ExistingObjectReplicate: "enable",
+	})
+	if err != nil {
+		return "", err
+	}
+	newReplCfgBytes, err := xml.Marshal(replicationConfig)
+	if err != nil {
+		return "", err
+	}
+	newReplicationConfig, err := sreplication.ParseConfig(bytes.NewReader(newReplCfgBytes))
+	if err != nil {
+		return "", err
+	}
+	sameTarget, apiErr := validateReplicationDestination(ctx, req.Bucket, newReplicationConfig)
+	if apiErr != noError {
+		return "", fmt.Errorf("bucket replication config validation error: %#v", apiErr)
+	}
+	if err = newReplicationConfig.Validate(req.Bucket, sameTarget); err != nil {
+		return "", err
+	}
+	replCfgData, err := xml.Marshal(newReplicationConfig)
+	if err != nil {
+		return "", err
+	}
+	if err = globalBucketMetadataSys.Update(req.Bucket, bucketReplicationConfig, replCfgData); err != nil {
+		return "", err
+	}
+	return targetARN, nil
+}
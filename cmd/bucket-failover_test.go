@@ -0,0 +1,47 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketFailoverDrainTimeout(t *testing.T) {
+	testCases := []struct {
+		timeout  string
+		expected time.Duration
+		success  bool
+	}{
+		{"", defaultFailoverDrainTimeout, true},
+		{"10m", 10 * time.Minute, true},
+		{"0s", 0, true},
+		{"-1m", 0, false},
+		{"ten minutes", 0, false},
+	}
+	for i, testCase := range testCases {
+		d, err := BucketFailoverRequest{Timeout: testCase.timeout}.drainTimeout()
+		if (err == nil) != testCase.success {
+			t.Errorf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+			continue
+		}
+		if testCase.success && d != testCase.expected {
+			t.Errorf("Test %d: expected %s, got %s", i+1, testCase.expected, d)
+		}
+	}
+}
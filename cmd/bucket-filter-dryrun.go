@@ -0,0 +1,225 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/bucket/lifecycle"
+	"github.com/minio/minio/internal/bucket/replication"
+)
+
+// filterDryRunMaxObjects is the number of objects sampled under the
+// prefix of each rule of a dry-run, beyond which the count of matching
+// objects is extrapolated from the data usage of the prefix.
+const filterDryRunMaxObjects = 10000
+
+// filterDryRunTimeout bounds the time a dry-run samples objects, the
+// rules are sampled concurrently and those not sampled completely by
+// then are extrapolated from the objects sampled so far.
+var filterDryRunTimeout = 10 * time.Second
+
+// filterDryRunConcurrency is the number of rules sampled at once.
+const filterDryRunConcurrency = 4
+
+// FilterDryRunResult - objects currently matched by the filters of the
+// rules of a lifecycle or replication configuration, returned instead of
+// applying the configuration when the request carries X-Minio-Dry-Run.
+type FilterDryRunResult struct {
+	XMLName xml.Name           `xml:"http://s3.amazonaws.com/doc/2006-03-01/ FilterDryRunResult"`
+	Rules   []FilterDryRunRule `xml:"Rule"`
+}
+
+// FilterDryRunRule - objects currently matched by the filter of a rule.
+type FilterDryRunRule struct {
+	ID     string `xml:"ID,omitempty"`
+	Prefix string `xml:"Prefix"`
+	Tags   string `xml:"Tags,omitempty"`
+	// Objects sampled under the prefix of the rule and how many of
+	// them carry the tags of the rule.
+	ScannedObjects  uint64 `xml:"ScannedObjects"`
+	MatchingObjects uint64 `xml:"MatchingObjects"`
+	MatchingSize    int64  `xml:"MatchingSize"`
+	// EstimatedObjects is the approximate number of objects matching
+	// the rule, equal to MatchingObjects when Complete is set.
+	EstimatedObjects uint64 `xml:"EstimatedObjects"`
+	// Complete is set when all objects under the prefix were sampled.
+	Complete bool `xml:"Complete"`
+}
+
+// filterDryRunRule - filter of a rule to be counted by a dry-run.
+type filterDryRunRule struct {
+	id, prefix, tags string
+	testTags         func(tags []string) bool
+}
+
+// lifecycleDryRunRules returns the filters of the rules of lc.
+func lifecycleDryRunRules(lc *lifecycle.Lifecycle) []filterDryRunRule {
+	rules := make([]filterDryRunRule, 0, len(lc.Rules))
+	for _, rule := range lc.Rules {
+		rules = append(rules, filterDryRunRule{
+			id:       rule.ID,
+			prefix:   rule.GetPrefix(),
+			tags:     rule.Tags(),
+			testTags: rule.Filter.TestTags,
+		})
+	}
+	return rules
+}
+
+// replicationDryRunRules returns the filters of the rules of rc.
+func replicationDryRunRules(rc *replication.Config) []filterDryRunRule {
+	rules := make([]filterDryRunRule, 0, len(rc.Rules))
+	for i := range rc.Rules {
+		rule := &rc.Rules[i]
+		rules = append(rules, filterDryRunRule{
+			id:       rule.ID,
+			prefix:   rule.Prefix(),
+			tags:     rule.Tags(),
+			testTags: rule.Filter.TestTags,
+		})
+	}
+	return rules
+}
+
+// filterDryRun counts the objects of bucket currently matching each of
+// the rules, without applying them.
+func filterDryRun(ctx context.Context, objAPI ObjectLayer, bucket string, rules []filterDryRunRule) (FilterDryRunResult, error) {
+	result := FilterDryRunResult{Rules: make([]FilterDryRunRule, len(rules))}
+	errs := make([]error, len(rules))
+	deadline := time.Now().Add(filterDryRunTimeout)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, filterDryRunConcurrency)
+	for i, rule := range rules {
+		i, rule := i, rule
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result.Rules[i], errs[i] = filterDryRunCount(ctx, objAPI, bucket, rule, filterDryRunMaxObjects, deadline)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return FilterDryRunResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// filterDryRunCount samples up to limit latest versions under the prefix
+// of rule until deadline and counts those carrying the tags of rule.
+func filterDryRunCount(ctx context.Context, objAPI ObjectLayer, bucket string, rule filterDryRunRule, limit uint64, deadline time.Time) (FilterDryRunRule, error) {
+	r := FilterDryRunRule{
+		ID:       rule.id,
+		Prefix:   rule.prefix,
+		Tags:     rule.tags,
+		Complete: true,
+	}
+
+	wctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	objInfoCh := make(chan ObjectInfo)
+	// A walk failing as it is out of time already closed objInfoCh.
+	err := objAPI.Walk(wctx, bucket, rule.prefix, objInfoCh, ObjectOptions{WalkVersions: true})
+	if err != nil && (ctx.Err() != nil || wctx.Err() != context.DeadlineExceeded) {
+		return r, err
+	}
+	for obj := range objInfoCh {
+		if !obj.IsLatest || obj.DeleteMarker || !strings.HasPrefix(obj.Name, rule.prefix) {
+			continue
+		}
+		if r.ScannedObjects == limit {
+			// Stop the walk and drain what it already listed.
+			r.Complete = false
+			cancel()
+			continue
+		}
+		r.ScannedObjects++
+		if rule.testTags(strings.Split(obj.UserTags, "&")) {
+			r.MatchingObjects++
+			r.MatchingSize += obj.Size
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return r, err
+	}
+	if wctx.Err() == context.DeadlineExceeded {
+		r.Complete = false
+	}
+
+	r.EstimatedObjects = r.MatchingObjects
+	if !r.Complete {
+		if total, ok := prefixObjectsCount(ctx, objAPI, bucket, rule.prefix); ok {
+			r.EstimatedObjects = estimateFilterMatches(r.MatchingObjects, r.ScannedObjects, total)
+		}
+	}
+	return r, nil
+}
+
+// estimateFilterMatches extrapolates matching objects out of scanned
+// to the total objects under a prefix.
+func estimateFilterMatches(matching, scanned, total uint64) uint64 {
+	if scanned == 0 || total <= scanned {
+		return matching
+	}
+	return uint64(float64(matching) / float64(scanned) * float64(total))
+}
+
+// prefixObjectsCount returns the number of objects under prefix recorded
+// by the last scanner cycle, only known for the bucket and its folders.
+func prefixObjectsCount(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) (uint64, bool) {
+	if prefix != "" && !strings.HasSuffix(prefix, SlashSeparator) {
+		return 0, false
+	}
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return 0, false
+	}
+
+	var total uint64
+	var found bool
+	cache := dataUsageCache{}
+	for _, pool := range z.currentPools() {
+		for _, er := range pool.sets {
+			if err := cache.load(ctx, er, bucket+slashSeparator+dataUsageCacheName); err != nil {
+				continue
+			}
+			e := cache.sizeRecursive(path.Join(bucket, prefix))
+			if e == nil {
+				// Either no objects under prefix in this set, or
+				// the folder is compacted into its parent.
+				continue
+			}
+			total += e.Objects
+			found = true
+		}
+	}
+	return total, found
+}
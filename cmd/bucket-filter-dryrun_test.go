@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+
+	"github.com/minio/minio/internal/bucket/lifecycle"
+	"github.com/minio/minio/internal/bucket/replication"
+)
+
+func TestFilterDryRunRules(t *testing.T) {
+	lc, err := lifecycle.ParseLifecycleConfig(bytes.NewReader([]byte(`<LifecycleConfiguration><Rule><ID>expire-temp</ID><Filter><And><Prefix>uploads/</Prefix><Tag><Key>temp</Key><Value>true</Value></Tag><Tag><Key>env</Key><Value>dev</Value></Tag></And></Filter><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := replication.ParseConfig(bytes.NewReader([]byte(`<ReplicationConfiguration><Role></Role><Rule><ID>replicate-prod</ID><Status>Enabled</Status><Priority>1</Priority><DeleteMarkerReplication><Status>Disabled</Status></DeleteMarkerReplication><Filter><Tag><Key>env</Key><Value>prod</Value></Tag></Filter><Destination><Bucket>arn:aws:s3:::destination</Bucket></Destination></Rule></ReplicationConfiguration>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		rule     filterDryRunRule
+		prefix   string
+		userTags string
+		match    bool
+	}{
+		{lifecycleDryRunRules(lc)[0], "uploads/", "temp=true&env=dev", true},
+		{lifecycleDryRunRules(lc)[0], "uploads/", "env=dev&temp=true&team=ops", true},
+		{lifecycleDryRunRules(lc)[0], "uploads/", "temp=true", false},
+		{lifecycleDryRunRules(lc)[0], "uploads/", "", false},
+		{replicationDryRunRules(rc)[0], "", "env=prod", true},
+		{replicationDryRunRules(rc)[0], "", "env=dev", false},
+	}
+	for i, tc := range testCases {
+		if tc.rule.prefix != tc.prefix {
+			t.Errorf("case %d: expected prefix %q, got %q", i+1, tc.prefix, tc.rule.prefix)
+		}
+		if match := tc.rule.testTags(strings.Split(tc.userTags, "&")); match != tc.match {
+			t.Errorf("case %d: expected match %v, got %v", i+1, tc.match, match)
+		}
+	}
+}
+
+func TestEstimateFilterMatches(t *testing.T) {
+	testCases := []struct {
+		matching, scanned, total uint64
+		estimated                uint64
+	}{
+		{0, 0, 0, 0},
+		{5, 10, 10, 5},
+		{5, 10, 2, 5},
+		{1250, 10000, 35000, 4375},
+		{0, 10000, 35000, 0},
+	}
+	for i, tc := range testCases {
+		if got := estimateFilterMatches(tc.matching, tc.scanned, tc.total); got != tc.estimated {
+			t.Errorf("case %d: expected %d, got %d", i+1, tc.estimated, got)
+		}
+	}
+}
+
+func TestFilterDryRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for object, tags := range map[string]string{
+		"uploads/a": "temp=true&env=dev",
+		"uploads/b": "temp=true",
+		"uploads/c": "",
+		"other/d":   "temp=true&env=dev",
+	} {
+		opts := ObjectOptions{UserDefined: map[string]string{xhttp.AmzObjectTagging: tags}}
+		if _, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader([]byte("data")), 4, "", ""), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lc, err := lifecycle.ParseLifecycleConfig(bytes.NewReader([]byte(`<LifecycleConfiguration><Rule><ID>expire-temp</ID><Filter><And><Prefix>uploads/</Prefix><Tag><Key>temp</Key><Value>true</Value></Tag><Tag><Key>env</Key><Value>dev</Value></Tag></And></Filter><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule><Rule><ID>expire-all</ID><Filter></Filter><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := filterDryRun(ctx, objLayer, bucket, lifecycleDryRunRules(lc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(result.Rules))
+	}
+	if r := result.Rules[0]; r.ID != "expire-temp" || !r.Complete || r.ScannedObjects != 3 || r.MatchingObjects != 1 || r.MatchingSize != 4 {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r := result.Rules[1]; r.ID != "expire-all" || !r.Complete || r.ScannedObjects != 4 || r.EstimatedObjects != 4 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	// Out of time, the rules are reported incomplete.
+	defer func(timeout time.Duration) { filterDryRunTimeout = timeout }(filterDryRunTimeout)
+	filterDryRunTimeout = 0
+	if result, err = filterDryRun(ctx, objLayer, bucket, lifecycleDryRunRules(lc)); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range result.Rules {
+		if r.Complete {
+			t.Errorf("expected %s to be incomplete, got %+v", r.ID, r)
+		}
+	}
+}
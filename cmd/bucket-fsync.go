@@ -0,0 +1,175 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/minio/minio/internal/disk"
+	"github.com/minio/minio/internal/logger"
+)
+
+const bucketFsyncConfigFile = "fsync.json"
+
+// fsyncPolicy is how the files of the objects committed are synced to
+// the drives.
+type fsyncPolicy string
+
+const (
+	// fsyncAlways syncs every file written before the commit of an object
+	// is acknowledged.
+	fsyncAlways fsyncPolicy = "always"
+	// fsyncBatched leaves the files written to the page cache and syncs
+	// the file system of the drive before the commit of an object is
+	// acknowledged, the objects committed meanwhile sharing that sync.
+	fsyncBatched fsyncPolicy = "batched"
+	// fsyncNever leaves the files written to the page cache, for the
+	// kernel to write them back.
+	fsyncNever fsyncPolicy = "never"
+)
+
+func parseFsyncPolicy(s string) (fsyncPolicy, error) {
+	switch p := fsyncPolicy(s); p {
+	case fsyncAlways, fsyncBatched, fsyncNever:
+		return p, nil
+	}
+	return "", fmt.Errorf("'%s' is not a valid fsync policy", s)
+}
+
+// BucketFsyncConfig - the fsync policy of the objects committed to a
+// bucket, trading durability for the throughput of writes.
+type BucketFsyncConfig struct {
+	Policy string `json:"policy"`
+}
+
+func parseBucketFsyncConfig(bucket string, data []byte) (*BucketFsyncConfig, error) {
+	cfg := &BucketFsyncConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if _, err := parseFsyncPolicy(cfg.Policy); err != nil {
+		return cfg, fmt.Errorf("Invalid fsync policy of bucket %s: %w", bucket, err)
+	}
+	return cfg, nil
+}
+
+// bucketFsyncPolicy returns the fsync policy of the objects committed to
+// bucket, never for low latency buckets.
+func bucketFsyncPolicy(bucket string) fsyncPolicy {
+	if lowLatencyBucket(bucket) {
+		return fsyncNever
+	}
+	if cfg := globalBucketMetadataSys.GetFsyncConfig(bucket); cfg != nil {
+		return fsyncPolicy(cfg.Policy)
+	}
+	return fsyncAlways
+}
+
+type fsyncPolicyKey struct{}
+
+// withFsyncPolicy returns ctx for the files written with it to be synced
+// according to policy.
+func withFsyncPolicy(ctx context.Context, policy fsyncPolicy) context.Context {
+	return context.WithValue(ctx, fsyncPolicyKey{}, policy)
+}
+
+// fsyncPolicyFromContext returns the fsync policy of the files written
+// with ctx, always by default.
+func fsyncPolicyFromContext(ctx context.Context) fsyncPolicy {
+	if policy, ok := ctx.Value(fsyncPolicyKey{}).(fsyncPolicy); ok {
+		return policy
+	}
+	return fsyncAlways
+}
+
+// bucketFsyncContext returns ctx for the files of the objects of bucket
+// to be written according to its fsync policy.
+func bucketFsyncContext(ctx context.Context, bucket string) context.Context {
+	if policy := bucketFsyncPolicy(bucket); policy != fsyncAlways {
+		return withFsyncPolicy(ctx, policy)
+	}
+	return ctx
+}
+
+// fsyncBatcher syncs the file system of a drive for the objects
+// committed to it with the batched policy. The commits arriving while the
+// drive is synced wait for the next sync together, a commit waits for at
+// most two syncs.
+type fsyncBatcher struct {
+	mu      sync.Mutex
+	syncing bool
+	next    *fsyncBatch
+}
+
+// fsyncBatch is a sync of a drive awaited by the commits before it.
+type fsyncBatch struct {
+	done chan struct{}
+	err  error
+}
+
+// sync returns once the file system of the drive at drivePath was synced
+// after the files written so far, with the error of that sync.
+func (b *fsyncBatcher) sync(drivePath string) error {
+	b.mu.Lock()
+	if b.next == nil {
+		b.next = &fsyncBatch{done: make(chan struct{})}
+	}
+	batch := b.next
+	if !b.syncing {
+		b.syncing = true
+		go b.run(drivePath)
+	}
+	b.mu.Unlock()
+
+	<-batch.done
+	return batch.err
+}
+
+// run syncs the drive until no commit awaits a sync anymore.
+func (b *fsyncBatcher) run(drivePath string) {
+	for {
+		b.mu.Lock()
+		batch := b.next
+		b.next = nil
+		if batch == nil {
+			b.syncing = false
+			b.mu.Unlock()
+			return
+		}
+		b.mu.Unlock()
+
+		batch.err = syncDrive(drivePath)
+		if batch.err != nil {
+			logger.LogOnceIf(GlobalContext, fmt.Errorf("Unable to sync drive %s: %w", drivePath, batch.err), drivePath)
+		}
+		close(batch.done)
+	}
+}
+
+var syncDrive = func(drivePath string) error {
+	f, err := os.Open(drivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return disk.Syncfs(f)
+}
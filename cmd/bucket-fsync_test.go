@@ -0,0 +1,115 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseBucketFsyncConfig(t *testing.T) {
+	for _, policy := range []fsyncPolicy{fsyncAlways, fsyncBatched, fsyncNever} {
+		cfg, err := parseBucketFsyncConfig("bucket", []byte(`{"policy":"`+string(policy)+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fsyncPolicy(cfg.Policy) != policy {
+			t.Fatalf("expected policy %s, got %s", policy, cfg.Policy)
+		}
+	}
+	for _, data := range []string{`{}`, `{"policy":"sometimes"}`, `{"policy":`} {
+		if _, err := parseBucketFsyncConfig("bucket", []byte(data)); err == nil {
+			t.Fatalf("expected %s to fail", data)
+		}
+	}
+}
+
+func TestFsyncPolicyContext(t *testing.T) {
+	ctx := context.Background()
+	if policy := fsyncPolicyFromContext(ctx); policy != fsyncAlways {
+		t.Fatalf("expected policy always by default, got %s", policy)
+	}
+	ctx = withFsyncPolicy(ctx, fsyncBatched)
+	if policy := fsyncPolicyFromContext(ctx); policy != fsyncBatched {
+		t.Fatalf("expected policy batched, got %s", policy)
+	}
+	if policy := fsyncPolicyFromContext(detachedContext(ctx)); policy != fsyncBatched {
+		t.Fatalf("expected detached context to keep policy batched, got %s", policy)
+	}
+}
+
+func TestFsyncBatcher(t *testing.T) {
+	prevSyncDrive := syncDrive
+	defer func() { syncDrive = prevSyncDrive }()
+
+	started := make(chan struct{})
+	release := make(chan error)
+	syncDrive = func(drivePath string) error {
+		started <- struct{}{}
+		return <-release
+	}
+
+	var b fsyncBatcher
+	sync := func() <-chan error {
+		errCh := make(chan error, 1)
+		go func() { errCh <- b.sync("drive") }()
+		return errCh
+	}
+	waiting := func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.next != nil
+	}
+
+	first := sync()
+	<-started
+
+	// A commit arriving during a sync waits for the next one, the one
+	// running may not cover it.
+	second := sync()
+	for !waiting() {
+		time.Sleep(time.Millisecond)
+	}
+	release <- nil
+	if err := <-first; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	<-started
+	select {
+	case <-second:
+		t.Fatal("commit acknowledged before the drive was synced")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// The error of the sync is the one of the commit.
+	errSync := errors.New("sync failed")
+	release <- errSync
+	if err := <-second; err != errSync {
+		t.Fatalf("expected %v, got %v", errSync, err)
+	}
+
+	// The batcher stops until the next commit.
+	next := sync()
+	<-started
+	release <- nil
+	if err := <-next; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -44,6 +44,7 @@ import (
 	sse "github.com/minio/minio/internal/bucket/encryption"
 	objectlock "github.com/minio/minio/internal/bucket/object/lock"
 	"github.com/minio/minio/internal/bucket/replication"
+	"github.com/minio/minio/internal/config/bucketwebhook"
 	"github.com/minio/minio/internal/config/dns"
 	"github.com/minio/minio/internal/crypto"
 	"github.com/minio/minio/internal/event"
@@ -55,6 +56,7 @@ import (
 	"github.com/minio/minio/internal/sync/errgroup"
 	"github.com/minio/pkg/bucket/policy"
 	iampolicy "github.com/minio/pkg/iam/policy"
+	xnet "github.com/minio/pkg/net"
 )
 
 const (
@@ -373,6 +375,16 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	// Users of a tenant only see the buckets of their tenant.
+	if !owner {
+		user := cred.AccessKey
+		if cred.ParentUser != "" {
+			user = cred.ParentUser
+		}
+		bucketsInfo = globalTenantSys.filterBuckets(user, bucketsInfo)
+	}
+	bucketsInfo = globalTenantSys.filterHostBuckets(r.Host, bucketsInfo)
+
 	// Generate response.
 	response := generateListBucketsResponse(bucketsInfo)
 	encodedSuccessResponse := encodeResponse(response)
@@ -544,6 +556,20 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 				continue
 			}
 		}
+		pinnedID := object.VersionID
+		if pinnedID == "" && suspended {
+			pinnedID = nullVersionID
+		}
+		if pinnedID != "" && globalBucketSnapshotSys.pinned(ctx, objectAPI, bucket, object.ObjectName, pinnedID) {
+			apiErr := errorCodes.ToAPIErr(ErrObjectPinnedBySnapshot)
+			dErrs[index] = DeleteError{
+				Code:      apiErr.Code,
+				Message:   apiErr.Description,
+				Key:       object.ObjectName,
+				VersionID: object.VersionID,
+			}
+			continue
+		}
 
 		// Avoid duplicate objects, we use map to filter them out.
 		if _, ok := objectsToDelete[object]; !ok {
@@ -561,11 +587,20 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		return
 	}
 
-	deleteList := toNames(objectsToDelete)
-	dObjects, errs := deleteObjectsFn(ctx, bucket, deleteList, ObjectOptions{
+	deleteOpts := ObjectOptions{
 		Versioned:        versioned,
 		VersionSuspended: suspended,
-	})
+	}
+	// Move the objects to the bucket trash instead when soft delete is enabled.
+	trashed := trashEnabled(bucket, deleteOpts)
+	if trashed {
+		deleteObjectsFn = func(ctx context.Context, bucket string, objects []ObjectToDelete, opts ObjectOptions) ([]DeletedObject, []error) {
+			return trashObjects(ctx, objectAPI, bucket, objects)
+		}
+	}
+
+	deleteList := toNames(objectsToDelete)
+	dObjects, errs := deleteObjectsFn(ctx, bucket, deleteList, deleteOpts)
 	deletedObjects := make([]DeletedObject, len(deleteObjects.Objects))
 	for i := range errs {
 		// DeleteMarkerVersionID is not used specifically to avoid
@@ -610,10 +645,12 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 
 	// Write success response.
 	writeSuccessResponseXML(w, encodedSuccessResponse)
+	var deleted uint64
 	for _, dobj := range deletedObjects {
 		if dobj.ObjectName == "" {
 			continue
 		}
+		deleted++
 
 		if replicateDeletes {
 			if dobj.DeleteMarkerReplicationStatus() == replication.Pending || dobj.VersionPurgeStatus() == Pending {
@@ -626,6 +663,7 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		}
 
 	}
+	globalAccessAnomaly.recordDeletes(r, bucket, deleted)
 
 	// Notify deleted event for objects.
 	for _, dobj := range deletedObjects {
@@ -656,15 +694,136 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		})
 	}
 
-	// Clean up transitioned objects from remote tier
+	// Clean up transitioned objects from remote tier, trashed
+	// objects keep their remote data until purged.
 	for _, os := range oss {
-		if os == nil { // skip objects that weren't deleted due to invalid versionID etc.
+		if os == nil || trashed { // skip objects that weren't deleted due to invalid versionID etc.
 			continue
 		}
 		logger.LogIf(ctx, os.Sweep())
 	}
 }
 
+// CommitObjectsHandler - POST Bucket?commit, MinIO extension API
+// ----------
+// Completes a set of staged multipart uploads of the bucket and publishes
+// the resulting objects atomically, either all of them become visible or none.
+func (api objectAPIHandlers) CommitObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CommitObjects")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Content-Length is required and should be non-zero
+	if r.ContentLength <= 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMissingContentLength), r.URL)
+		return
+	}
+
+	// The max. XML contains 1000 uploads of 10000 parts each + XML overhead
+	const maxBodySize = 2 * maxCommitUploads * 10000 * 256
+
+	commit := CommitObjectsRequest{}
+	if err := xmlDecoder(r.Body, &commit, maxBodySize); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if len(commit.Uploads) == 0 || len(commit.Uploads) > maxCommitUploads {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidCommitManifest), r.URL)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	// Committed objects are not replicated and do not get default
+	// retention applied, refuse to let either diverge.
+	if _, err := getReplicationConfig(ctx, bucket); err == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+	if rcfg, _ := globalBucketObjectLockSys.Get(bucket); rcfg.LockEnabled {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	keys := set.NewStringSet()
+	for i, u := range commit.Uploads {
+		object := trimLeadingSlash(u.Key)
+		if object == "" || u.UploadID == "" || len(u.Parts) == 0 || keys.Contains(object) {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidCommitManifest), r.URL)
+			return
+		}
+		keys.Add(object)
+		if !sort.IsSorted(CompletedParts(u.Parts)) {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidPartOrder), r.URL)
+			return
+		}
+		if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+		mi, err := objectAPI.GetMultipartInfo(ctx, bucket, object, u.UploadID, ObjectOptions{})
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		// Encrypted part etags need the key of each upload to be validated.
+		if _, encrypted := crypto.IsEncrypted(mi.UserDefined); encrypted {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+			return
+		}
+		commit.Uploads[i].Key = object
+		for j := range u.Parts {
+			u.Parts[j].ETag = canonicalizeETag(u.Parts[j].ETag)
+		}
+	}
+
+	commitID, objects, err := commitObjects(ctx, objectAPI, bucket, commit)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	response := CommitObjectsResponse{CommitID: commitID}
+	for _, objInfo := range objects {
+		response.Objects = append(response.Objects, CommittedObject{
+			Key:       objInfo.Name,
+			VersionID: objInfo.VersionID,
+			ETag:      "\"" + objInfo.ETag + "\"",
+		})
+	}
+	writeSuccessResponseXML(w, encodeResponse(response))
+
+	for _, objInfo := range objects {
+		// Notify object created event.
+		sendEvent(eventArgs{
+			EventName:    event.ObjectCreatedCompleteMultipartUpload,
+			BucketName:   bucket,
+			Object:       objInfo,
+			ReqParams:    extractReqParams(r),
+			RespElements: extractRespElements(w),
+			UserAgent:    r.UserAgent(),
+			Host:         handlers.GetSourceIP(r),
+		})
+	}
+}
+
 // PutBucketHandler - PUT Bucket
 // ----------
 // This implementation of the PUT operation creates a new bucket for authenticated request
@@ -692,7 +851,8 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		objectLockEnabled = v == "true"
 	}
 
-	if s3Error := checkRequestAuthType(ctx, r, policy.CreateBucketAction, bucket, ""); s3Error != ErrNone {
+	cred, owner, s3Error := checkRequestAuthTypeCredential(ctx, r, policy.CreateBucketAction, bucket, "")
+	if s3Error != ErrNone {
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
 		return
 	}
@@ -711,9 +871,47 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// The pools the bucket is pinned to must exist before it is created.
+	placementTags, err := parsePlacementTags(r.Header.Get(xhttp.MinIOBucketPoolTags))
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	if len(placementTags) > 0 {
+		if err = checkPlacementPools(objectAPI, placementTags); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	}
+
+	// The requested template, or the template matching the bucket name,
+	// sets the configs of the bucket right after it is created.
+	tmplName := r.Header.Get(xhttp.MinIOBucketTemplate)
+	tmpl, hasTemplate, err := globalBucketTemplateSys.bucketTemplate(bucket, tmplName)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	// Choosing a template needs the permissions of setting its configs.
+	if tmplName != "" && !tmpl.isAllowed(r, cred, owner, bucket) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
 	opts := BucketOptions{
 		Location:    location,
-		LockEnabled: objectLockEnabled,
+		LockEnabled: objectLockEnabled || (hasTemplate && tmpl.ObjectLock),
+	}
+
+	// The bucket webhook may deny the creation.
+	hookEv := newBucketWebhookEvent(ctx, bucket)
+	hookEv.Region, hookEv.ObjectLock = location, opts.LockEnabled
+	if hasTemplate {
+		hookEv.Template = tmpl.Name
+	}
+	if err = globalBucketWebhook.before(ctx, bucketwebhook.PreMakeBucket, hookEv); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
 	}
 
 	if globalDNSConfig != nil {
@@ -734,6 +932,23 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 					return
 				}
 
+				if len(placementTags) > 0 {
+					if err = setBucketPlacement(bucket, placementTags); err != nil {
+						objectAPI.DeleteBucket(context.Background(), bucket, DeleteBucketOptions{Force: false, NoRecreate: true})
+						logger.LogIf(ctx, globalDNSConfig.Delete(bucket))
+						writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+						return
+					}
+				}
+
+				if hasTemplate {
+					if err = applyBucketTemplate(ctx, objectAPI, bucket, tmpl); err != nil {
+						logger.LogIf(ctx, globalDNSConfig.Delete(bucket))
+						writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+						return
+					}
+				}
+
 				// Load updated bucket metadata into memory.
 				globalNotificationSys.LoadBucketMetadata(GlobalContext, bucket)
 
@@ -743,6 +958,8 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 
 				writeSuccessResponseHeadersOnly(w)
 
+				globalBucketWebhook.after(ctx, bucketwebhook.PostMakeBucket, hookEv)
+
 				sendEvent(eventArgs{
 					EventName:    event.BucketCreated,
 					BucketName:   bucket,
@@ -771,7 +988,7 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Proceed to creating a bucket.
-	err := objectAPI.MakeBucketWithLocation(ctx, bucket, opts)
+	err = objectAPI.MakeBucketWithLocation(ctx, bucket, opts)
 	if _, ok := err.(BucketExists); ok {
 		// Though bucket exists locally, we send the site-replication
 		// hook to ensure all sites have this bucket. If the hook
@@ -788,6 +1005,23 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if len(placementTags) > 0 {
+		if err = setBucketPlacement(bucket, placementTags); err != nil {
+			// A bucket not pinned to its pools would place its objects
+			// on any pool.
+			objectAPI.DeleteBucket(context.Background(), bucket, DeleteBucketOptions{Force: false, NoRecreate: true})
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	}
+
+	if hasTemplate {
+		if err = applyBucketTemplate(ctx, objectAPI, bucket, tmpl); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	}
+
 	// Load updated bucket metadata into memory.
 	globalNotificationSys.LoadBucketMetadata(GlobalContext, bucket)
 
@@ -805,6 +1039,8 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 
 	writeSuccessResponseHeadersOnly(w)
 
+	globalBucketWebhook.after(ctx, bucketwebhook.PostMakeBucket, hookEv)
+
 	sendEvent(eventArgs{
 		EventName:    event.BucketCreated,
 		BucketName:   bucket,
@@ -919,6 +1155,9 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 
 	// Verify policy signature.
 	cred, errCode := doesPolicySignatureMatch(formValues)
+	if errCode == ErrNone {
+		errCode = checkUploadTokenAPI(ctx, cred)
+	}
 	if errCode != ErrNone {
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(errCode), r.URL)
 		return
@@ -985,6 +1224,11 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	if err = applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	hashReader, err := hash.NewReader(fileBody, fileSize, "", "", fileSize)
 	if err != nil {
 		logger.LogIf(ctx, err)
@@ -1062,6 +1306,11 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	if err = globalMalwareScanner.scanUpload(ctx, objectAPI, objInfo); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	// We must not use the http.Header().Set method here because some (broken)
 	// clients expect the ETag header key to be literally "ETag" - not "Etag" (case-sensitive).
 	// Therefore, we have to set the ETag directly as map entry.
@@ -1167,6 +1416,159 @@ func (api objectAPIHandlers) GetBucketPolicyStatusHandler(w http.ResponseWriter,
 	writeSuccessResponseXML(w, encodedSuccessResponse)
 }
 
+// MetadataSearchHandler - GET /bucket?metadata-search&query={query}
+// ----------
+// MinIO extension API returning the objects of a bucket matching a
+// query on their size, modification time, tags and user metadata, such
+// as "size > 1GiB AND tag.env = prod", served from the metadata index
+// of the bucket maintained by the scanner.
+func (api objectAPIHandlers) MetadataSearchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "MetadataSearch")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	query := r.Form.Get("query")
+	q, err := parseMetadataQuery(query)
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErrWithErr(ErrInvalidMetadataQuery, err), r.URL)
+		return
+	}
+
+	maxKeys := maxMetadataSearchKeys
+	if v := r.Form.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidMaxKeys), r.URL)
+			return
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+
+	if _, err = objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	// Searching by tags or user metadata reveals them, only the
+	// objects they may be read of are found.
+	var allowed func(object string) bool
+	if actions := q.objectActions(); len(actions) > 0 {
+		allowed = func(object string) bool {
+			for _, action := range actions {
+				if checkRequestAuthType(ctx, r, action, bucket, object) != ErrNone {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	res, err := searchMetadataIndex(ctx, objectAPI, bucket, r.Form.Get("prefix"), r.Form.Get("marker"), q, maxKeys, allowed)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	res.Query = query
+
+	writeSuccessResponseXML(w, encodeResponse(res))
+}
+
+// ChangelogHandler - GET /bucket?changelog&offset={offset}&limit={limit}&follow={true}
+// ----------
+// MinIO extension API, streams the changelog of a bucket from offset on
+// as JSON lines, one record per change of an object, with the latest
+// version of the object or a null value once deleted. With follow set,
+// the stream stays open and new records are streamed as appended.
+func (api objectAPIHandlers) ChangelogHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Changelog")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+	// The records hold the metadata and tags of all objects.
+	for _, action := range []policy.Action{policy.GetObjectAction, policy.GetObjectTaggingAction} {
+		if s3Error := checkRequestAuthType(ctx, r, action, bucket, "*"); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	}
+
+	var offset uint64
+	if v := r.Form.Get("offset"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidChangelogOffset), r.URL)
+			return
+		}
+		offset = n
+	}
+	var limit int
+	if v := r.Form.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidMaxKeys), r.URL)
+			return
+		}
+		limit = n
+	}
+	follow := r.Form.Get("follow") == "true"
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if bucketChangelogConfig(bucket) == nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, errChangelogNotEnabled), r.URL)
+		return
+	}
+
+	h, err := loadChangelogHead(ctx, objectAPI, bucket)
+	if err != nil && err != errConfigNotFound {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	setEventStreamHeaders(w)
+	w.Header().Set(xhttp.ContentType, "application/x-ndjson")
+	w.Header().Set(xhttp.MinIOChangelogID, h.ID)
+	w.Header().Set(xhttp.MinIOChangelogFirstOffset, strconv.FormatUint(h.firstOffset(), 10))
+	w.Header().Set(xhttp.MinIOChangelogNextOffset, strconv.FormatUint(h.Next, 10))
+	w.WriteHeader(http.StatusOK)
+
+	err = streamChangelog(ctx, objectAPI, bucket, offset, limit, follow, w, w.(http.Flusher).Flush)
+	if err != nil && !xnet.IsNetworkOrHostDown(err, true) {
+		logger.LogIf(ctx, err)
+	}
+}
+
 // HeadBucketHandler - HEAD Bucket
 // ----------
 // This operation is useful to determine if a bucket exists.
@@ -1199,9 +1601,33 @@ func (api objectAPIHandlers) HeadBucketHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	setBucketUsageHeaders(w, bucket)
+	if globalIsErasure || globalIsDistErasure {
+		w.Header().Set(xhttp.MinIOBucketFsyncPolicy, string(bucketFsyncPolicy(bucket)))
+	}
+
 	writeSuccessResponseHeadersOnly(w)
 }
 
+// setBucketUsageHeaders sets the approximate size and object count of
+// bucket found by the last data usage scan, if any.
+func setBucketUsageHeaders(w http.ResponseWriter, bucket string) {
+	if globalIsGateway {
+		return
+	}
+	dui, err := globalBucketQuotaSys.usageInfo()
+	if err != nil {
+		return
+	}
+	bui, ok := dui.BucketsUsage[bucket]
+	if !ok {
+		return
+	}
+	w.Header().Set(xhttp.MinIOBucketUsageSize, strconv.FormatUint(bui.Size, 10))
+	w.Header().Set(xhttp.MinIOBucketUsageObjects, strconv.FormatUint(bui.ObjectsCount, 10))
+	w.Header().Set(xhttp.MinIOBucketUsageLastUpdate, dui.LastUpdate.UTC().Format(http.TimeFormat))
+}
+
 // DeleteBucketHandler - Delete bucket
 func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "DeleteBucket")
@@ -1249,6 +1675,14 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 		}
 	}
 
+	// The bucket webhook may deny the deletion.
+	hookEv := newBucketWebhookEvent(ctx, bucket)
+	hookEv.Force = forceDelete
+	if err := globalBucketWebhook.before(ctx, bucketwebhook.PreDeleteBucket, hookEv); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	if globalDNSConfig != nil {
 		if err := globalDNSConfig.Delete(bucket); err != nil {
 			logger.LogIf(ctx, fmt.Errorf("Unable to delete bucket DNS entry %w, please delete it manually", err))
@@ -1277,6 +1711,7 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	}
 
 	globalNotificationSys.DeleteBucketMetadata(ctx, bucket)
+	deleteBucketTrash(ctx, objectAPI, bucket)
 
 	// Call site replication hook.
 	if err := globalSiteReplicationSys.DeleteBucketHook(ctx, bucket, forceDelete); err != nil {
@@ -1287,6 +1722,8 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	// Write success response.
 	writeSuccessNoContent(w)
 
+	globalBucketWebhook.after(ctx, bucketwebhook.PostDeleteBucket, hookEv)
+
 	sendEvent(eventArgs{
 		EventName:    event.BucketRemoved,
 		BucketName:   bucket,
@@ -1597,6 +2034,16 @@ func (api objectAPIHandlers) PutBucketReplicationConfigHandler(w http.ResponseWr
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	// Count the objects matched by the rules instead of applying them.
+	if r.Header.Get(xhttp.MinIODryRun) == "true" {
+		result, err := filterDryRun(ctx, objectAPI, bucket, replicationDryRunRules(replicationConfig))
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		writeSuccessResponseXML(w, encodeResponse(result))
+		return
+	}
 	configData, err := xml.Marshal(replicationConfig)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -0,0 +1,194 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/logger"
+)
+
+// bucketLatencyBounds are the upper bounds of the latency histograms,
+// in seconds, same as the cluster wide TTFB distribution.
+var bucketLatencyBounds = []float64{.05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// bucketLatencyMaxBuckets is the number of buckets tracked unless more
+// are exported, past it the bucket called least recently is dropped.
+const bucketLatencyMaxBuckets = 1000
+
+// apiLatencyHistogram is a cumulative latency histogram of one API.
+type apiLatencyHistogram struct {
+	// counts[i] is the number of calls with latency <= bucketLatencyBounds[i],
+	// the last entry counts all calls.
+	counts []uint64
+	sum    float64
+}
+
+func (h *apiLatencyHistogram) observe(d time.Duration) {
+	secs := d.Seconds()
+	for i, bound := range bucketLatencyBounds {
+		if secs <= bound {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(bucketLatencyBounds)]++
+	h.sum += secs
+}
+
+type bucketLatency struct {
+	name  string
+	calls uint64
+	apis  map[string]*apiLatencyHistogram
+}
+
+// bucketLatencyStats keeps per bucket API latency histograms, they are
+// only recorded when enabled and only exported for the topN buckets
+// with the most calls, to keep the metrics cardinality bounded.
+type bucketLatencyStats struct {
+	// enabled is set when topN > 0, checked without the lock as it
+	// is on the path of every S3 call.
+	enabled uint32
+
+	sync.Mutex
+	topN int
+	// buckets holds the elements of lru, ordered from the bucket
+	// called most recently to the one called least recently.
+	buckets map[string]*list.Element
+	lru     *list.List
+}
+
+var globalBucketLatencyStats = &bucketLatencyStats{}
+
+func (s *bucketLatencyStats) setTopN(n int) {
+	s.Lock()
+	defer s.Unlock()
+	s.topN = n
+	if n == 0 {
+		s.buckets, s.lru = nil, nil
+		atomic.StoreUint32(&s.enabled, 0)
+		return
+	}
+	atomic.StoreUint32(&s.enabled, 1)
+}
+
+// record adds the latency of a completed S3 call, calls without a
+// bucket or ones that did not reach the handler are ignored.
+func (s *bucketLatencyStats) record(r *http.Request, api string, w *logger.ResponseWriter) {
+	if atomic.LoadUint32(&s.enabled) == 0 {
+		return
+	}
+	bucket := mux.Vars(r)["bucket"]
+	if bucket == "" {
+		return
+	}
+	d := time.Since(w.StartTime)
+
+	s.Lock()
+	defer s.Unlock()
+	if s.topN == 0 {
+		return
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[string]*list.Element)
+		s.lru = list.New()
+	}
+	var b *bucketLatency
+	if e, ok := s.buckets[bucket]; ok {
+		s.lru.MoveToFront(e)
+		b = e.Value.(*bucketLatency)
+	} else {
+		maxBuckets := bucketLatencyMaxBuckets
+		if s.topN > maxBuckets {
+			maxBuckets = s.topN
+		}
+		if s.lru.Len() >= maxBuckets {
+			e := s.lru.Back()
+			s.lru.Remove(e)
+			delete(s.buckets, e.Value.(*bucketLatency).name)
+		}
+		b = &bucketLatency{name: bucket, apis: make(map[string]*apiLatencyHistogram)}
+		s.buckets[bucket] = s.lru.PushFront(b)
+	}
+	h, ok := b.apis[api]
+	if !ok {
+		h = &apiLatencyHistogram{counts: make([]uint64, len(bucketLatencyBounds)+1)}
+		b.apis[api] = h
+	}
+	b.calls++
+	h.observe(d)
+}
+
+// forget drops the histograms of a deleted bucket.
+func (s *bucketLatencyStats) forget(bucket string) {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.buckets[bucket]; ok {
+		s.lru.Remove(e)
+		delete(s.buckets, bucket)
+	}
+}
+
+// bucketLatencySample is one exported histogram bucket.
+type bucketLatencySample struct {
+	bucket, api, le string
+	value           float64
+}
+
+// top returns the histograms of the busiest buckets flattened, along
+// with their sums and counts.
+func (s *bucketLatencyStats) top() (buckets, sums, counts []bucketLatencySample) {
+	s.Lock()
+	defer s.Unlock()
+	if s.topN == 0 || len(s.buckets) == 0 {
+		return nil, nil, nil
+	}
+
+	busiest := make([]*bucketLatency, 0, len(s.buckets))
+	for e := s.lru.Front(); e != nil; e = e.Next() {
+		busiest = append(busiest, e.Value.(*bucketLatency))
+	}
+	sort.Slice(busiest, func(i, j int) bool {
+		if busiest[i].calls != busiest[j].calls {
+			return busiest[i].calls > busiest[j].calls
+		}
+		return busiest[i].name < busiest[j].name
+	})
+	if len(busiest) > s.topN {
+		busiest = busiest[:s.topN]
+	}
+
+	for _, b := range busiest {
+		for api, h := range b.apis {
+			for i, bound := range bucketLatencyBounds {
+				buckets = append(buckets, bucketLatencySample{b.name, api, fmt.Sprintf("%.3f", bound), float64(h.counts[i])})
+			}
+			total := float64(h.counts[len(bucketLatencyBounds)])
+			buckets = append(buckets, bucketLatencySample{b.name, api, "+Inf", total})
+			sums = append(sums, bucketLatencySample{b.name, api, "", h.sum})
+			counts = append(counts, bucketLatencySample{b.name, api, "", total})
+		}
+	}
+	return buckets, sums, counts
+}
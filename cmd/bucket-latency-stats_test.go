@@ -0,0 +1,115 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/logger"
+)
+
+func TestBucketLatencyStatsTopN(t *testing.T) {
+	s := &bucketLatencyStats{}
+	record := func(bucket string, n int, d time.Duration) {
+		for i := 0; i < n; i++ {
+			r := mux.SetURLVars(httptest.NewRequest("GET", "/"+bucket+"/object", nil), map[string]string{"bucket": bucket})
+			w := logger.NewResponseWriter(httptest.NewRecorder())
+			w.StartTime = time.Now().UTC().Add(-d)
+			s.record(r, "getobject", w)
+		}
+	}
+
+	// Disabled by default.
+	record("bucket1", 1, time.Millisecond)
+	if buckets, _, _ := s.top(); len(buckets) != 0 {
+		t.Fatalf("expected no samples when disabled, got %d", len(buckets))
+	}
+
+	s.setTopN(2)
+	record("bucket1", 3, time.Millisecond)
+	record("bucket2", 2, 2*time.Second)
+	record("bucket3", 1, time.Millisecond)
+
+	buckets, sums, counts := s.top()
+	if len(counts) != 2 {
+		t.Fatalf("expected 2 exported buckets, got %d", len(counts))
+	}
+	if counts[0].bucket != "bucket1" || counts[0].value != 3 {
+		t.Errorf("unexpected busiest bucket %#v", counts[0])
+	}
+	if len(sums) != 2 || sums[1].value < 4 {
+		t.Errorf("unexpected latency sums %#v", sums)
+	}
+	for _, b := range buckets {
+		if b.bucket == "bucket2" && b.le == "1.000" && b.value != 0 {
+			t.Errorf("expected no bucket2 calls below 1s, got %v", b.value)
+		}
+		if b.bucket == "bucket2" && b.le == "2.500" && b.value != 2 {
+			t.Errorf("expected 2 bucket2 calls below 2.5s, got %v", b.value)
+		}
+	}
+
+	s.forget("bucket1")
+	if _, _, counts = s.top(); len(counts) != 2 || counts[0].bucket != "bucket2" {
+		t.Errorf("unexpected buckets after forget %#v", counts)
+	}
+}
+
+func TestBucketLatencyStatsBounded(t *testing.T) {
+	s := &bucketLatencyStats{}
+	s.setTopN(1)
+	record := func(bucket string) {
+		r := mux.SetURLVars(httptest.NewRequest("GET", "/"+bucket+"/object", nil), map[string]string{"bucket": bucket})
+		w := logger.NewResponseWriter(httptest.NewRecorder())
+		s.record(r, "getobject", w)
+	}
+
+	// The bucket called least recently is dropped, one called along
+	// the others is kept.
+	record("idle")
+	for i := 0; i < 2*bucketLatencyMaxBuckets; i++ {
+		record(fmt.Sprintf("bucket%d", i))
+		if i%100 == 0 {
+			record("busy")
+		}
+	}
+	if len(s.buckets) != bucketLatencyMaxBuckets || s.lru.Len() != bucketLatencyMaxBuckets {
+		t.Fatalf("expected %d tracked buckets, got %d", bucketLatencyMaxBuckets, len(s.buckets))
+	}
+	if _, ok := s.buckets["idle"]; ok {
+		t.Error("expected the idle bucket to be dropped")
+	}
+	if _, ok := s.buckets["bucket0"]; ok {
+		t.Error("expected the oldest bucket to be dropped")
+	}
+	if _, ok := s.buckets[fmt.Sprintf("bucket%d", 2*bucketLatencyMaxBuckets-1)]; !ok {
+		t.Error("expected the newest bucket to be kept")
+	}
+	if _, _, counts := s.top(); len(counts) != 1 || counts[0].bucket != "busy" {
+		t.Errorf("expected the busiest bucket to be kept, got %#v", counts)
+	}
+
+	s.forget("busy")
+	if _, ok := s.buckets["busy"]; ok || s.lru.Len() != bucketLatencyMaxBuckets-1 {
+		t.Errorf("expected the bucket to be forgotten, %d tracked", s.lru.Len())
+	}
+}
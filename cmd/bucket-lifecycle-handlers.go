@@ -85,6 +85,17 @@ func (api objectAPIHandlers) PutBucketLifecycleHandler(w http.ResponseWriter, r
 		return
 	}
 
+	// Count the objects matched by the rules instead of applying them.
+	if r.Header.Get(xhttp.MinIODryRun) == "true" {
+		result, err := filterDryRun(ctx, objAPI, bucket, lifecycleDryRunRules(bucketLifecycle))
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		writeSuccessResponseXML(w, encodeResponse(result))
+		return
+	}
+
 	configData, err := xml.Marshal(bucketLifecycle)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
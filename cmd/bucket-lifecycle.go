@@ -115,11 +115,16 @@ func initBackgroundExpiry(ctx context.Context, objectAPI ObjectLayer) {
 	globalExpiryState = newExpiryState()
 	go func() {
 		for t := range globalExpiryState.expiryCh {
+			jctx, release, err := globalJobScheduler.acquire(ctx, jobILM)
+			if err != nil {
+				return
+			}
 			if t.objInfo.TransitionedObject.Status != "" {
-				applyExpiryOnTransitionedObject(ctx, objectAPI, t.objInfo, t.restoredObject)
+				applyExpiryOnTransitionedObject(jctx, objectAPI, t.objInfo, t.restoredObject)
 			} else {
-				applyExpiryOnNonTransitionedObjects(ctx, objectAPI, t.objInfo, t.versionExpiry)
+				applyExpiryOnNonTransitionedObjects(jctx, objectAPI, t.objInfo, t.versionExpiry)
 			}
+			release()
 		}
 	}()
 }
@@ -184,11 +189,16 @@ func (t *transitionState) worker(ctx context.Context, objectAPI ObjectLayer) {
 			if !ok {
 				return
 			}
+			jctx, release, err := globalJobScheduler.acquire(ctx, jobILM)
+			if err != nil {
+				return
+			}
 			atomic.AddInt32(&t.activeTasks, 1)
-			if err := transitionObject(ctx, objectAPI, oi); err != nil {
+			if err := transitionObject(jctx, objectAPI, oi); err != nil && jctx.Err() == nil {
 				logger.LogIf(ctx, fmt.Errorf("Transition failed for %s/%s version:%s with %w", oi.Bucket, oi.Name, oi.VersionID, err))
 			}
 			atomic.AddInt32(&t.activeTasks, -1)
+			release()
 		}
 	}
 }
@@ -109,6 +109,15 @@ func (api objectAPIHandlers) ListObjectVersionsHandler(w http.ResponseWriter, r
 
 	listObjectVersions := objectAPI.ListObjectVersions
 
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		listObjectVersions = snapshot.ListObjectVersions
+	}
+
 	// Inititate a list object versions operation based on the input params.
 	// On success would return back ListObjectsInfo object to be
 	// marshaled into S3 compatible XML header.
@@ -118,6 +127,7 @@ func (api objectAPIHandlers) ListObjectVersionsHandler(w http.ResponseWriter, r
 		return
 	}
 
+	listObjectVersionsInfo.Objects = filterCommitHiddenVersions(ctx, objectAPI, listObjectVersionsInfo.Objects)
 	concurrentDecryptETag(ctx, listObjectVersionsInfo.Objects)
 
 	response := generateListVersionsResponse(bucket, prefix, marker, versionIDMarker, delimiter, encodingType, maxkeys, listObjectVersionsInfo)
@@ -171,6 +181,15 @@ func (api objectAPIHandlers) ListObjectsV2MHandler(w http.ResponseWriter, r *htt
 
 	listObjectsV2 := objectAPI.ListObjectsV2
 
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		listObjectsV2 = snapshot.ListObjectsV2
+	}
+
 	// Inititate a list objects operation based on the input params.
 	// On success would return back ListObjectsInfo object to be
 	// marshaled into S3 compatible XML header.
@@ -180,6 +199,7 @@ func (api objectAPIHandlers) ListObjectsV2MHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	listObjectsV2Info.Objects = filterCommitHidden(ctx, objectAPI, listObjectsV2Info.Objects)
 	concurrentDecryptETag(ctx, listObjectsV2Info.Objects)
 
 	// The next continuation token has id@node_index format to optimize paginated listing
@@ -236,10 +256,17 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 		return
 	}
 
-	var (
-		listObjectsV2Info ListObjectsV2Info
-		err               error
-	)
+	var listObjectsV2Info ListObjectsV2Info
+
+	listObjectsV2 := objectAPI.ListObjectsV2
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		listObjectsV2 = snapshot.ListObjectsV2
+	}
 
 	if r.Header.Get(xMinIOExtract) == "true" && strings.Contains(prefix, archivePattern) {
 		// Inititate a list objects operation inside a zip file based in the input params
@@ -248,13 +275,14 @@ func (api objectAPIHandlers) ListObjectsV2Handler(w http.ResponseWriter, r *http
 		// Inititate a list objects operation based on the input params.
 		// On success would return back ListObjectsInfo object to be
 		// marshaled into S3 compatible XML header.
-		listObjectsV2Info, err = objectAPI.ListObjectsV2(ctx, bucket, prefix, token, delimiter, maxKeys, fetchOwner, startAfter)
+		listObjectsV2Info, err = listObjectsV2(ctx, bucket, prefix, token, delimiter, maxKeys, fetchOwner, startAfter)
 	}
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
+	listObjectsV2Info.Objects = filterCommitHidden(ctx, objectAPI, listObjectsV2Info.Objects)
 	concurrentDecryptETag(ctx, listObjectsV2Info.Objects)
 
 	response := generateListObjectsV2Response(bucket, prefix, token, listObjectsV2Info.NextContinuationToken, startAfter,
@@ -343,6 +371,15 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 
 	listObjects := objectAPI.ListObjects
 
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		listObjects = snapshot.ListObjects
+	}
+
 	// Inititate a list objects operation based on the input params.
 	// On success would return back ListObjectsInfo object to be
 	// marshaled into S3 compatible XML header.
@@ -352,6 +389,7 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 		return
 	}
 
+	listObjectsInfo.Objects = filterCommitHidden(ctx, objectAPI, listObjectsInfo.Objects)
 	concurrentDecryptETag(ctx, listObjectsInfo.Objects)
 
 	response := generateListObjectsV1Response(bucket, prefix, marker, delimiter, encodingType, maxKeys, listObjectsInfo)
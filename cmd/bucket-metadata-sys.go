@@ -52,6 +52,7 @@ func (sys *BucketMetadataSys) Remove(bucket string) {
 	sys.Lock()
 	delete(sys.metadataMap, bucket)
 	globalBucketMonitor.DeleteBucket(bucket)
+	globalNamespacePressure.deleteBucket(bucket)
 	sys.Unlock()
 }
 
@@ -121,6 +122,69 @@ func (sys *BucketMetadataSys) Update(bucket string, configFile string, configDat
 		meta.TaggingConfigXML = configData
 	case bucketQuotaConfigFile:
 		meta.QuotaConfigJSON = configData
+	case bucketCompressionConfigFile:
+		meta.CompressionConfigJSON = configData
+	case bucketNetworkACLFile:
+		meta.NetworkACLJSON = configData
+	case bucketReadAheadConfigFile:
+		meta.ReadAheadConfigJSON = configData
+	case bucketObjectDefaultsFile:
+		meta.ObjectDefaultsJSON = configData
+	case bucketPlacementConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.PlacementConfigJSON = configData
+	case bucketTrashConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.TrashConfigJSON = configData
+	case bucketSnapshotMountFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.SnapshotMountJSON = configData
+	case bucketArchiveConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.ArchiveConfigJSON = configData
+	case bucketDedupConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.DedupConfigJSON = configData
+	case bucketListIndexFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.ListIndexJSON = configData
+	case bucketMetadataIndexConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.MetadataIndexJSON = configData
+	case bucketChangelogConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.ChangelogConfigJSON = configData
+	case bucketClassConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.ClassConfigJSON = configData
+	case bucketFsyncConfigFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.FsyncConfigJSON = configData
+	case bucketSnapshotsFile:
+		if !globalIsErasure && !globalIsDistErasure {
+			return NotImplemented{}
+		}
+		meta.SnapshotsJSON = configData
 	case objectLockConfig:
 		if !globalIsErasure && !globalIsDistErasure {
 			return NotImplemented{}
@@ -331,6 +395,196 @@ func (sys *BucketMetadataSys) GetQuotaConfig(bucket string) (*madmin.BucketQuota
 	return meta.quotaConfig, nil
 }
 
+// GetQuotaOptions returns the options of the bucket quota, nil
+// if no quota is configured.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetQuotaOptions(bucket string) (*BucketQuotaOptions, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.quotaOptions, nil
+}
+
+// GetTrashConfig returns configured bucket trash config
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetTrashConfig(bucket string) (*BucketTrashConfig, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.trashConfig, nil
+}
+
+// GetSnapshotMount returns the snapshot served by bucket, nil if it
+// does not mount a snapshot.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetSnapshotMount(bucket string) (*BucketSnapshotMount, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.snapshotMount, nil
+}
+
+// GetArchiveState returns the archive state of bucket, nil if it is
+// not archived.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetArchiveState(bucket string) (*BucketArchiveState, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.archiveState, nil
+}
+
+// GetDedupConfig returns the dedup config of bucket, nil if it is not
+// configured.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetDedupConfig(bucket string) (*BucketDedupConfig, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.dedupConfig, nil
+}
+
+// GetMetadataIndexConfig returns the metadata index config of bucket,
+// nil if it is not configured.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetMetadataIndexConfig(bucket string) (*BucketMetadataIndexConfig, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.metadataIndexConfig, nil
+}
+
+// GetChangelogConfig returns the changelog config of bucket, nil if it
+// is not configured.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetChangelogConfig(bucket string) (*BucketChangelogConfig, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.changelogConfig, nil
+}
+
+// GetCompressionConfig returns the compression config of bucket, nil
+// if the server compression settings apply.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetCompressionConfig(bucket string) (*BucketCompressionConfig, error) {
+	meta, err := sys.GetConfig(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return meta.compressionConfig, nil
+}
+
+// GetReadAheadConfig returns the read-ahead config of bucket, nil if
+// ranged reads of its objects are not prefetched. Only the bucket
+// metadata in memory is looked up, it is checked for all ranged reads.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetReadAheadConfig(bucket string) *BucketReadAheadConfig {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].readAheadConfig
+}
+
+// GetObjectDefaults returns the default tags and metadata of the objects
+// of bucket, nil if it has none. Only the bucket metadata in memory is
+// looked up, it is checked for all object writes.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetObjectDefaults(bucket string) *BucketObjectDefaults {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].objectDefaults
+}
+
+// GetNetworkACL returns the network ACL of bucket, nil if it has none.
+// Only the bucket metadata in memory is looked up, the ACL is checked
+// for all requests before they are authenticated.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetNetworkACL(bucket string) *BucketNetworkACL {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].networkACL
+}
+
+// GetPlacementConfig returns the pool placement of bucket, nil if it is
+// not pinned to pools.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetPlacementConfig(bucket string) *BucketPlacementConfig {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].placementConfig
+}
+
+// GetClassConfig returns the class of bucket, nil if it was never set.
+// Only the bucket metadata in memory is looked up, it is checked for all
+// object writes.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetClassConfig(bucket string) *BucketClassConfig {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].classConfig
+}
+
+// GetFsyncConfig returns the fsync policy of bucket, nil if it was never
+// set. Only the bucket metadata in memory is looked up, it is checked for
+// all object writes.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetFsyncConfig(bucket string) *BucketFsyncConfig {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].fsyncConfig
+}
+
+// GetSnapshots returns the ids of the snapshots of bucket, only the
+// bucket metadata in memory is looked up as the snapshots are checked
+// for every deletion of a version.
+// The returned slice may not be modified.
+func (sys *BucketMetadataSys) GetSnapshots(bucket string) []string {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].snapshots
+}
+
+// GetListIndex returns the listing indexes of the prefixes of bucket,
+// nil if it has none. Only the bucket metadata in memory is looked up,
+// the indexes are checked for all writes and listings.
+// The returned object may not be modified.
+func (sys *BucketMetadataSys) GetListIndex(bucket string) *BucketListIndex {
+	if globalIsGateway {
+		return nil
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	return sys.metadataMap[bucket].listIndex
+}
+
 // GetReplicationConfig returns configured bucket replication config
 // The returned object may not be modified.
 func (sys *BucketMetadataSys) GetReplicationConfig(ctx context.Context, bucket string) (*replication.Config, error) {
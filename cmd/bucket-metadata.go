@@ -81,6 +81,21 @@ type BucketMetadata struct {
 	ReplicationConfigXML        []byte
 	BucketTargetsConfigJSON     []byte
 	BucketTargetsConfigMetaJSON []byte
+	TrashConfigJSON             []byte
+	SnapshotMountJSON           []byte
+	ArchiveConfigJSON           []byte
+	DedupConfigJSON             []byte
+	CompressionConfigJSON       []byte
+	NetworkACLJSON              []byte
+	PlacementConfigJSON         []byte
+	ListIndexJSON               []byte
+	ReadAheadConfigJSON         []byte
+	ObjectDefaultsJSON          []byte
+	MetadataIndexJSON           []byte
+	ChangelogConfigJSON         []byte
+	ClassConfigJSON             []byte
+	FsyncConfigJSON             []byte
+	SnapshotsJSON               []byte
 
 	// Unexported fields. Must be updated atomically.
 	policyConfig           *policy.Policy
@@ -91,9 +106,25 @@ type BucketMetadata struct {
 	sseConfig              *bucketsse.BucketSSEConfig
 	taggingConfig          *tags.Tags
 	quotaConfig            *madmin.BucketQuota
+	quotaOptions           *BucketQuotaOptions
 	replicationConfig      *replication.Config
 	bucketTargetConfig     *madmin.BucketTargets
 	bucketTargetConfigMeta map[string]string
+	trashConfig            *BucketTrashConfig
+	snapshotMount          *BucketSnapshotMount
+	archiveState           *BucketArchiveState
+	dedupConfig            *BucketDedupConfig
+	compressionConfig      *BucketCompressionConfig
+	networkACL             *BucketNetworkACL
+	placementConfig        *BucketPlacementConfig
+	listIndex              *BucketListIndex
+	readAheadConfig        *BucketReadAheadConfig
+	objectDefaults         *BucketObjectDefaults
+	metadataIndexConfig    *BucketMetadataIndexConfig
+	changelogConfig        *BucketChangelogConfig
+	classConfig            *BucketClassConfig
+	fsyncConfig            *BucketFsyncConfig
+	snapshots              []string
 }
 
 // newBucketMetadata creates BucketMetadata with the supplied name and Created to Now.
@@ -110,6 +141,7 @@ func newBucketMetadata(name string) BucketMetadata {
 		},
 		bucketTargetConfig:     &madmin.BucketTargets{},
 		bucketTargetConfigMeta: make(map[string]string),
+		trashConfig:            &BucketTrashConfig{},
 	}
 }
 
@@ -231,6 +263,10 @@ func (b *BucketMetadata) parseAllConfigs(ctx context.Context, objectAPI ObjectLa
 		if err != nil {
 			return err
 		}
+		b.quotaOptions, err = parseBucketQuotaOptions(b.QuotaConfigJSON)
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(b.ReplicationConfigXML) != 0 {
@@ -250,6 +286,141 @@ func (b *BucketMetadata) parseAllConfigs(ctx context.Context, objectAPI ObjectLa
 	} else {
 		b.bucketTargetConfig = &madmin.BucketTargets{}
 	}
+
+	if len(b.TrashConfigJSON) != 0 {
+		b.trashConfig, err = parseBucketTrashConfig(b.Name, b.TrashConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.trashConfig = &BucketTrashConfig{}
+	}
+
+	if len(b.SnapshotMountJSON) != 0 {
+		b.snapshotMount, err = parseBucketSnapshotMount(b.SnapshotMountJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.snapshotMount = nil
+	}
+
+	if len(b.ArchiveConfigJSON) != 0 {
+		b.archiveState, err = parseBucketArchiveState(b.ArchiveConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.archiveState = nil
+	}
+
+	if len(b.DedupConfigJSON) != 0 {
+		b.dedupConfig, err = parseBucketDedupConfig(b.Name, b.DedupConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.dedupConfig = nil
+	}
+
+	if len(b.CompressionConfigJSON) != 0 {
+		b.compressionConfig, err = parseBucketCompressionConfig(b.Name, b.CompressionConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.compressionConfig = nil
+	}
+
+	if len(b.NetworkACLJSON) != 0 {
+		b.networkACL, err = parseBucketNetworkACL(b.Name, b.NetworkACLJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.networkACL = nil
+	}
+
+	if len(b.PlacementConfigJSON) != 0 {
+		b.placementConfig, err = parseBucketPlacementConfig(b.Name, b.PlacementConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.placementConfig = nil
+	}
+
+	if len(b.ListIndexJSON) != 0 {
+		b.listIndex, err = parseBucketListIndex(b.ListIndexJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.listIndex = nil
+	}
+
+	if len(b.ReadAheadConfigJSON) != 0 {
+		b.readAheadConfig, err = parseBucketReadAheadConfig(b.Name, b.ReadAheadConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.readAheadConfig = nil
+	}
+
+	if len(b.ObjectDefaultsJSON) != 0 {
+		b.objectDefaults, err = parseBucketObjectDefaults(b.Name, b.ObjectDefaultsJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.objectDefaults = nil
+	}
+
+	if len(b.MetadataIndexJSON) != 0 {
+		b.metadataIndexConfig, err = parseBucketMetadataIndexConfig(b.Name, b.MetadataIndexJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.metadataIndexConfig = nil
+	}
+
+	if len(b.ChangelogConfigJSON) != 0 {
+		b.changelogConfig, err = parseBucketChangelogConfig(b.Name, b.ChangelogConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.changelogConfig = nil
+	}
+
+	if len(b.ClassConfigJSON) != 0 {
+		b.classConfig, err = parseBucketClassConfig(b.Name, b.ClassConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.classConfig = nil
+	}
+
+	if len(b.FsyncConfigJSON) != 0 {
+		b.fsyncConfig, err = parseBucketFsyncConfig(b.Name, b.FsyncConfigJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.fsyncConfig = nil
+	}
+
+	if len(b.SnapshotsJSON) != 0 {
+		b.snapshots, err = parseBucketSnapshots(b.SnapshotsJSON)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.snapshots = nil
+	}
 	return nil
 }
 
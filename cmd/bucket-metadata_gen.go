@@ -108,6 +108,96 @@ func (z *BucketMetadata) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "BucketTargetsConfigMetaJSON")
 				return
 			}
+		case "TrashConfigJSON":
+			z.TrashConfigJSON, err = dc.ReadBytes(z.TrashConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "TrashConfigJSON")
+				return
+			}
+		case "SnapshotMountJSON":
+			z.SnapshotMountJSON, err = dc.ReadBytes(z.SnapshotMountJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "SnapshotMountJSON")
+				return
+			}
+		case "ArchiveConfigJSON":
+			z.ArchiveConfigJSON, err = dc.ReadBytes(z.ArchiveConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ArchiveConfigJSON")
+				return
+			}
+		case "DedupConfigJSON":
+			z.DedupConfigJSON, err = dc.ReadBytes(z.DedupConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "DedupConfigJSON")
+				return
+			}
+		case "CompressionConfigJSON":
+			z.CompressionConfigJSON, err = dc.ReadBytes(z.CompressionConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "CompressionConfigJSON")
+				return
+			}
+		case "NetworkACLJSON":
+			z.NetworkACLJSON, err = dc.ReadBytes(z.NetworkACLJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "NetworkACLJSON")
+				return
+			}
+		case "PlacementConfigJSON":
+			z.PlacementConfigJSON, err = dc.ReadBytes(z.PlacementConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "PlacementConfigJSON")
+				return
+			}
+		case "ListIndexJSON":
+			z.ListIndexJSON, err = dc.ReadBytes(z.ListIndexJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ListIndexJSON")
+				return
+			}
+		case "ReadAheadConfigJSON":
+			z.ReadAheadConfigJSON, err = dc.ReadBytes(z.ReadAheadConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ReadAheadConfigJSON")
+				return
+			}
+		case "ObjectDefaultsJSON":
+			z.ObjectDefaultsJSON, err = dc.ReadBytes(z.ObjectDefaultsJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ObjectDefaultsJSON")
+				return
+			}
+		case "MetadataIndexJSON":
+			z.MetadataIndexJSON, err = dc.ReadBytes(z.MetadataIndexJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "MetadataIndexJSON")
+				return
+			}
+		case "ChangelogConfigJSON":
+			z.ChangelogConfigJSON, err = dc.ReadBytes(z.ChangelogConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ChangelogConfigJSON")
+				return
+			}
+		case "ClassConfigJSON":
+			z.ClassConfigJSON, err = dc.ReadBytes(z.ClassConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ClassConfigJSON")
+				return
+			}
+		case "FsyncConfigJSON":
+			z.FsyncConfigJSON, err = dc.ReadBytes(z.FsyncConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "FsyncConfigJSON")
+				return
+			}
+		case "SnapshotsJSON":
+			z.SnapshotsJSON, err = dc.ReadBytes(z.SnapshotsJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "SnapshotsJSON")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -121,9 +211,9 @@ func (z *BucketMetadata) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *BucketMetadata) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 14
+	// map header, size 29
 	// write "Name"
-	err = en.Append(0x8e, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	err = en.Append(0xde, 0x0, 0x1d, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
@@ -262,15 +352,165 @@ func (z *BucketMetadata) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "BucketTargetsConfigMetaJSON")
 		return
 	}
+	// write "TrashConfigJSON"
+	err = en.Append(0xaf, 0x54, 0x72, 0x61, 0x73, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.TrashConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "TrashConfigJSON")
+		return
+	}
+	// write "SnapshotMountJSON"
+	err = en.Append(0xb1, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.SnapshotMountJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "SnapshotMountJSON")
+		return
+	}
+	// write "ArchiveConfigJSON"
+	err = en.Append(0xb1, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ArchiveConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ArchiveConfigJSON")
+		return
+	}
+	// write "DedupConfigJSON"
+	err = en.Append(0xaf, 0x44, 0x65, 0x64, 0x75, 0x70, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.DedupConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "DedupConfigJSON")
+		return
+	}
+	// write "CompressionConfigJSON"
+	err = en.Append(0xb5, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.CompressionConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "CompressionConfigJSON")
+		return
+	}
+	// write "NetworkACLJSON"
+	err = en.Append(0xae, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x41, 0x43, 0x4c, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.NetworkACLJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "NetworkACLJSON")
+		return
+	}
+	// write "PlacementConfigJSON"
+	err = en.Append(0xb3, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.PlacementConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "PlacementConfigJSON")
+		return
+	}
+	// write "ListIndexJSON"
+	err = en.Append(0xad, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ListIndexJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ListIndexJSON")
+		return
+	}
+	// write "ReadAheadConfigJSON"
+	err = en.Append(0xb3, 0x52, 0x65, 0x61, 0x64, 0x41, 0x68, 0x65, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ReadAheadConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ReadAheadConfigJSON")
+		return
+	}
+	// write "ObjectDefaultsJSON"
+	err = en.Append(0xb2, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ObjectDefaultsJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ObjectDefaultsJSON")
+		return
+	}
+	// write "MetadataIndexJSON"
+	err = en.Append(0xb1, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.MetadataIndexJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "MetadataIndexJSON")
+		return
+	}
+	// write "ChangelogConfigJSON"
+	err = en.Append(0xb3, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x6c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ChangelogConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ChangelogConfigJSON")
+		return
+	}
+	// write "ClassConfigJSON"
+	err = en.Append(0xaf, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.ClassConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "ClassConfigJSON")
+		return
+	}
+	// write "FsyncConfigJSON"
+	err = en.Append(0xaf, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.FsyncConfigJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "FsyncConfigJSON")
+		return
+	}
+	// write "SnapshotsJSON"
+	err = en.Append(0xad, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x4a, 0x53, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.SnapshotsJSON)
+	if err != nil {
+		err = msgp.WrapError(err, "SnapshotsJSON")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *BucketMetadata) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 14
+	// map header, size 29
 	// string "Name"
-	o = append(o, 0x8e, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = append(o, 0xde, 0x0, 0x1d, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	o = msgp.AppendString(o, z.Name)
 	// string "Created"
 	o = append(o, 0xa7, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64)
@@ -311,6 +551,51 @@ func (z *BucketMetadata) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "BucketTargetsConfigMetaJSON"
 	o = append(o, 0xbb, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x65, 0x74, 0x61, 0x4a, 0x53, 0x4f, 0x4e)
 	o = msgp.AppendBytes(o, z.BucketTargetsConfigMetaJSON)
+	// string "TrashConfigJSON"
+	o = append(o, 0xaf, 0x54, 0x72, 0x61, 0x73, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.TrashConfigJSON)
+	// string "SnapshotMountJSON"
+	o = append(o, 0xb1, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.SnapshotMountJSON)
+	// string "ArchiveConfigJSON"
+	o = append(o, 0xb1, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ArchiveConfigJSON)
+	// string "DedupConfigJSON"
+	o = append(o, 0xaf, 0x44, 0x65, 0x64, 0x75, 0x70, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.DedupConfigJSON)
+	// string "CompressionConfigJSON"
+	o = append(o, 0xb5, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.CompressionConfigJSON)
+	// string "NetworkACLJSON"
+	o = append(o, 0xae, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x41, 0x43, 0x4c, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.NetworkACLJSON)
+	// string "PlacementConfigJSON"
+	o = append(o, 0xb3, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.PlacementConfigJSON)
+	// string "ListIndexJSON"
+	o = append(o, 0xad, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ListIndexJSON)
+	// string "ReadAheadConfigJSON"
+	o = append(o, 0xb3, 0x52, 0x65, 0x61, 0x64, 0x41, 0x68, 0x65, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ReadAheadConfigJSON)
+	// string "ObjectDefaultsJSON"
+	o = append(o, 0xb2, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ObjectDefaultsJSON)
+	// string "MetadataIndexJSON"
+	o = append(o, 0xb1, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.MetadataIndexJSON)
+	// string "ChangelogConfigJSON"
+	o = append(o, 0xb3, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x6c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ChangelogConfigJSON)
+	// string "ClassConfigJSON"
+	o = append(o, 0xaf, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.ClassConfigJSON)
+	// string "FsyncConfigJSON"
+	o = append(o, 0xaf, 0x46, 0x73, 0x79, 0x6e, 0x63, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.FsyncConfigJSON)
+	// string "SnapshotsJSON"
+	o = append(o, 0xad, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x4a, 0x53, 0x4f, 0x4e)
+	o = msgp.AppendBytes(o, z.SnapshotsJSON)
 	return
 }
 
@@ -416,6 +701,96 @@ func (z *BucketMetadata) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "BucketTargetsConfigMetaJSON")
 				return
 			}
+		case "TrashConfigJSON":
+			z.TrashConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.TrashConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "TrashConfigJSON")
+				return
+			}
+		case "SnapshotMountJSON":
+			z.SnapshotMountJSON, bts, err = msgp.ReadBytesBytes(bts, z.SnapshotMountJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "SnapshotMountJSON")
+				return
+			}
+		case "ArchiveConfigJSON":
+			z.ArchiveConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.ArchiveConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ArchiveConfigJSON")
+				return
+			}
+		case "DedupConfigJSON":
+			z.DedupConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.DedupConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "DedupConfigJSON")
+				return
+			}
+		case "CompressionConfigJSON":
+			z.CompressionConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.CompressionConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "CompressionConfigJSON")
+				return
+			}
+		case "NetworkACLJSON":
+			z.NetworkACLJSON, bts, err = msgp.ReadBytesBytes(bts, z.NetworkACLJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "NetworkACLJSON")
+				return
+			}
+		case "PlacementConfigJSON":
+			z.PlacementConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.PlacementConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "PlacementConfigJSON")
+				return
+			}
+		case "ListIndexJSON":
+			z.ListIndexJSON, bts, err = msgp.ReadBytesBytes(bts, z.ListIndexJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ListIndexJSON")
+				return
+			}
+		case "ReadAheadConfigJSON":
+			z.ReadAheadConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.ReadAheadConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ReadAheadConfigJSON")
+				return
+			}
+		case "ObjectDefaultsJSON":
+			z.ObjectDefaultsJSON, bts, err = msgp.ReadBytesBytes(bts, z.ObjectDefaultsJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ObjectDefaultsJSON")
+				return
+			}
+		case "MetadataIndexJSON":
+			z.MetadataIndexJSON, bts, err = msgp.ReadBytesBytes(bts, z.MetadataIndexJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "MetadataIndexJSON")
+				return
+			}
+		case "ChangelogConfigJSON":
+			z.ChangelogConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.ChangelogConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ChangelogConfigJSON")
+				return
+			}
+		case "ClassConfigJSON":
+			z.ClassConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.ClassConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "ClassConfigJSON")
+				return
+			}
+		case "FsyncConfigJSON":
+			z.FsyncConfigJSON, bts, err = msgp.ReadBytesBytes(bts, z.FsyncConfigJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "FsyncConfigJSON")
+				return
+			}
+		case "SnapshotsJSON":
+			z.SnapshotsJSON, bts, err = msgp.ReadBytesBytes(bts, z.SnapshotsJSON)
+			if err != nil {
+				err = msgp.WrapError(err, "SnapshotsJSON")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -430,6 +805,6 @@ func (z *BucketMetadata) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *BucketMetadata) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 8 + msgp.TimeSize + 12 + msgp.BoolSize + 17 + msgp.BytesPrefixSize + len(z.PolicyConfigJSON) + 22 + msgp.BytesPrefixSize + len(z.NotificationConfigXML) + 19 + msgp.BytesPrefixSize + len(z.LifecycleConfigXML) + 20 + msgp.BytesPrefixSize + len(z.ObjectLockConfigXML) + 20 + msgp.BytesPrefixSize + len(z.VersioningConfigXML) + 20 + msgp.BytesPrefixSize + len(z.EncryptionConfigXML) + 17 + msgp.BytesPrefixSize + len(z.TaggingConfigXML) + 16 + msgp.BytesPrefixSize + len(z.QuotaConfigJSON) + 21 + msgp.BytesPrefixSize + len(z.ReplicationConfigXML) + 24 + msgp.BytesPrefixSize + len(z.BucketTargetsConfigJSON) + 28 + msgp.BytesPrefixSize + len(z.BucketTargetsConfigMetaJSON)
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 8 + msgp.TimeSize + 12 + msgp.BoolSize + 17 + msgp.BytesPrefixSize + len(z.PolicyConfigJSON) + 22 + msgp.BytesPrefixSize + len(z.NotificationConfigXML) + 19 + msgp.BytesPrefixSize + len(z.LifecycleConfigXML) + 20 + msgp.BytesPrefixSize + len(z.ObjectLockConfigXML) + 20 + msgp.BytesPrefixSize + len(z.VersioningConfigXML) + 20 + msgp.BytesPrefixSize + len(z.EncryptionConfigXML) + 17 + msgp.BytesPrefixSize + len(z.TaggingConfigXML) + 16 + msgp.BytesPrefixSize + len(z.QuotaConfigJSON) + 21 + msgp.BytesPrefixSize + len(z.ReplicationConfigXML) + 24 + msgp.BytesPrefixSize + len(z.BucketTargetsConfigJSON) + 28 + msgp.BytesPrefixSize + len(z.BucketTargetsConfigMetaJSON) + 16 + msgp.BytesPrefixSize + len(z.TrashConfigJSON) + 18 + msgp.BytesPrefixSize + len(z.SnapshotMountJSON) + 18 + msgp.BytesPrefixSize + len(z.ArchiveConfigJSON) + 16 + msgp.BytesPrefixSize + len(z.DedupConfigJSON) + 22 + msgp.BytesPrefixSize + len(z.CompressionConfigJSON) + 15 + msgp.BytesPrefixSize + len(z.NetworkACLJSON) + 20 + msgp.BytesPrefixSize + len(z.PlacementConfigJSON) + 14 + msgp.BytesPrefixSize + len(z.ListIndexJSON) + 20 + msgp.BytesPrefixSize + len(z.ReadAheadConfigJSON) + 19 + msgp.BytesPrefixSize + len(z.ObjectDefaultsJSON) + 18 + msgp.BytesPrefixSize + len(z.MetadataIndexJSON) + 20 + msgp.BytesPrefixSize + len(z.ChangelogConfigJSON) + 16 + msgp.BytesPrefixSize + len(z.ClassConfigJSON) + 16 + msgp.BytesPrefixSize + len(z.FsyncConfigJSON) + 14 + msgp.BytesPrefixSize + len(z.SnapshotsJSON)
 	return
 }
@@ -0,0 +1,174 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const bucketNetworkACLFile = "network-acl.json"
+
+// BucketNetworkACL - the networks allowed and denied to access a bucket,
+// as IP addresses or CIDR ranges. Denied networks take precedence, an
+// empty Allow list allows all networks not denied.
+type BucketNetworkACL struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+
+	allow, deny []*net.IPNet
+}
+
+func parseBucketNetworkACL(bucket string, data []byte) (*BucketNetworkACL, error) {
+	acl := &BucketNetworkACL{}
+	if err := json.Unmarshal(data, acl); err != nil {
+		return acl, err
+	}
+	var err error
+	if acl.allow, err = parseNetworks(acl.Allow); err != nil {
+		return acl, fmt.Errorf("Invalid allowed network for bucket %s: %w", bucket, err)
+	}
+	if acl.deny, err = parseNetworks(acl.Deny); err != nil {
+		return acl, fmt.Errorf("Invalid denied network for bucket %s: %w", bucket, err)
+	}
+	return acl, nil
+}
+
+// parseNetworks parses CIDR ranges, IP addresses being single address
+// ranges.
+func parseNetworks(networks []string) ([]*net.IPNet, error) {
+	ipNets := make([]*net.IPNet, 0, len(networks))
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			ip := net.ParseIP(network)
+			if ip == nil {
+				return nil, fmt.Errorf("'%s' is not an IP address or CIDR range", network)
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+			ipNets = append(ipNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, err
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return ipNets, nil
+}
+
+func containsIP(ipNets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns whether ip may access the bucket.
+func (acl *BucketNetworkACL) allows(ip net.IP) bool {
+	if ip == nil {
+		return len(acl.allow) == 0 && len(acl.deny) == 0
+	}
+	if containsIP(acl.deny, ip) {
+		return false
+	}
+	return len(acl.allow) == 0 || containsIP(acl.allow, ip)
+}
+
+// isNetworkAllowed returns whether the source of r may access bucket,
+// checked before the request is authenticated.
+func isNetworkAllowed(r *http.Request, bucket string) bool {
+	if bucket == "" {
+		return true
+	}
+	acl := globalBucketMetadataSys.GetNetworkACL(bucket)
+	if acl == nil {
+		return true
+	}
+	return acl.allows(networkSourceIP(r, globalAPIConfig.getTrustedProxies()))
+}
+
+// networkSourceIP returns the address r is checked by. Walking back
+// from the peer address through the addresses forwarded, it is the
+// first one not of a trusted proxy: proxies append the address of their
+// peer to the forwarded ones, those before are set by the client.
+func networkSourceIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(trustedProxies, ip) {
+		return ip
+	}
+	hops := forwardedHops(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(hops[i])
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !containsIP(trustedProxies, ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// forwardedHops returns the forwarded addresses of h, the client first,
+// from the X-Forwarded-For, X-Real-IP or RFC 7239 Forwarded headers, in
+// that order.
+func forwardedHops(h http.Header) []string {
+	var hops []string
+	for _, v := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	if len(hops) > 0 {
+		return hops
+	}
+	if v := h.Get("X-Real-Ip"); v != "" {
+		return []string{strings.TrimSpace(v)}
+	}
+	for _, v := range h.Values("Forwarded") {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				pair = strings.TrimSpace(pair)
+				if len(pair) < 4 || !strings.EqualFold(pair[:4], "for=") {
+					continue
+				}
+				// IPv6 addresses are quoted and bracketed, with an
+				// optional port.
+				hop := strings.Trim(pair[4:], `"`)
+				if h, _, err := net.SplitHostPort(hop); err == nil {
+					hop = h
+				}
+				hops = append(hops, strings.Trim(hop, "[]"))
+			}
+		}
+	}
+	return hops
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBucketNetworkACL(t *testing.T) {
+	testCases := []struct {
+		data    string
+		ip      string
+		allowed bool
+	}{
+		{`{}`, "192.0.2.1", true},
+		{`{"allow":["10.0.0.0/8","192.0.2.7"]}`, "10.1.2.3", true},
+		{`{"allow":["10.0.0.0/8","192.0.2.7"]}`, "192.0.2.7", true},
+		{`{"allow":["10.0.0.0/8","192.0.2.7"]}`, "192.0.2.8", false},
+		{`{"allow":["10.0.0.0/8"],"deny":["10.6.0.0/16"]}`, "10.6.1.1", false},
+		{`{"deny":["2001:db8::/32"]}`, "2001:db8::1", false},
+		{`{"deny":["2001:db8::/32"]}`, "192.0.2.1", true},
+		{`{"deny":["192.0.2.0/24"]}`, "::ffff:192.0.2.1", false},
+	}
+	for i, testCase := range testCases {
+		acl, err := parseBucketNetworkACL("bucket", []byte(testCase.data))
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if allowed := acl.allows(net.ParseIP(testCase.ip)); allowed != testCase.allowed {
+			t.Errorf("Test %d: expected %s allowed %v, got %v", i+1, testCase.ip, testCase.allowed, allowed)
+		}
+	}
+
+	for _, data := range []string{`{"allow":["10.0.0.0/33"]}`, `{"deny":["not-an-ip"]}`, `{"allow":`} {
+		if _, err := parseBucketNetworkACL("bucket", []byte(data)); err == nil {
+			t.Errorf("expected %s to be invalid", data)
+		}
+	}
+}
+
+func TestNetworkSourceIP(t *testing.T) {
+	trustedProxies, err := parseNetworks([]string{"10.0.0.1", "172.16.0.0/12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		remoteAddr string
+		header     string
+		forwarded  string
+		ip         string
+	}{
+		{"192.0.2.1:4321", "", "", "192.0.2.1"},
+		// Forwarded addresses of untrusted peers are ignored.
+		{"192.0.2.1:4321", "X-Forwarded-For", "10.6.1.1", "192.0.2.1"},
+		{"10.0.0.1:4321", "X-Forwarded-For", "10.6.1.1", "10.6.1.1"},
+		{"172.16.3.4:4321", "X-Forwarded-For", "198.51.100.2", "198.51.100.2"},
+		{"10.0.0.1:4321", "", "", "10.0.0.1"},
+		{"[2001:db8::1]:4321", "X-Forwarded-For", "10.6.1.1", "2001:db8::1"},
+		// Addresses set by the client before those of the proxies are
+		// not trusted.
+		{"10.0.0.1:4321", "X-Forwarded-For", "10.6.1.1, 198.51.100.2", "198.51.100.2"},
+		{"10.0.0.1:4321", "X-Forwarded-For", "10.6.1.1, 198.51.100.2, 172.16.0.9", "198.51.100.2"},
+		{"10.0.0.1:4321", "X-Forwarded-For", "garbage, 172.16.0.9", "172.16.0.9"},
+		{"10.0.0.1:4321", "X-Real-Ip", "198.51.100.2", "198.51.100.2"},
+		{"10.0.0.1:4321", "Forwarded", `for=10.6.1.1, for="[2001:db8::2]:4711";proto=https`, "2001:db8::2"},
+	}
+	for i, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r.RemoteAddr = testCase.remoteAddr
+		if testCase.header != "" {
+			r.Header.Set(testCase.header, testCase.forwarded)
+		}
+		if ip := networkSourceIP(r, trustedProxies); !ip.Equal(net.ParseIP(testCase.ip)) {
+			t.Errorf("Test %d: expected %s, got %s", i+1, testCase.ip, ip)
+		}
+	}
+}
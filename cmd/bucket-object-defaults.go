@@ -0,0 +1,118 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const bucketObjectDefaultsFile = "object-defaults.json"
+
+// BucketObjectDefaults - the tags and user metadata of the objects
+// written to a bucket when the client does not set them, such as the
+// cost allocation tags of a team. Keys set by the client keep their
+// value, replicas keep the tags and metadata of their source.
+type BucketObjectDefaults struct {
+	Tags map[string]string `json:"tags,omitempty"`
+	// Metadata keys are user metadata keys, e.g. X-Amz-Meta-Team.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func parseBucketObjectDefaults(bucket string, data []byte) (*BucketObjectDefaults, error) {
+	d := &BucketObjectDefaults{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return d, err
+	}
+	if len(d.Tags) > 0 {
+		if _, err := tags.NewTags(d.Tags, true); err != nil {
+			return d, fmt.Errorf("Invalid default tags for bucket %s: %w", bucket, err)
+		}
+	}
+	metadata := make(map[string]string, len(d.Metadata))
+	for k, v := range d.Metadata {
+		if !strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") || len(k) == len("x-amz-meta-") {
+			return d, fmt.Errorf("Invalid default metadata %s for bucket %s, only user metadata starting with X-Amz-Meta- can be set", k, bucket)
+		}
+		metadata[http.CanonicalHeaderKey(k)] = v
+	}
+	d.Metadata = metadata
+	return d, nil
+}
+
+// apply sets the default tags and metadata missing from the metadata
+// of an object, failing if the tags of the object and the default tags
+// are more than an object can have.
+func (d *BucketObjectDefaults) apply(metadata map[string]string) error {
+	if len(d.Tags) > 0 {
+		m := make(map[string]string, len(d.Tags))
+		if objTags := metadata[xhttp.AmzObjectTagging]; objTags != "" {
+			t, err := tags.ParseObjectTags(objTags)
+			if err != nil {
+				return err
+			}
+			m = t.ToMap()
+		}
+		n := len(m)
+		for k, v := range d.Tags {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+		if len(m) > n {
+			t, err := tags.NewTags(m, true)
+			if err != nil {
+				return err
+			}
+			metadata[xhttp.AmzObjectTagging] = t.String()
+		}
+	}
+
+	for k, v := range d.Metadata {
+		set := false
+		for mk := range metadata {
+			if strings.EqualFold(mk, k) {
+				set = true
+				break
+			}
+		}
+		if !set {
+			metadata[k] = v
+		}
+	}
+	return nil
+}
+
+// applyBucketObjectDefaults sets the default tags and metadata of bucket
+// missing from the metadata of an object written by r, unless r writes
+// a replica.
+func applyBucketObjectDefaults(r *http.Request, bucket string, metadata map[string]string) error {
+	d := globalBucketMetadataSys.GetObjectDefaults(bucket)
+	if d == nil {
+		return nil
+	}
+	if _, ok := r.Header[xhttp.MinIOSourceReplicationRequest]; ok {
+		return nil
+	}
+	return d.apply(metadata)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestParseBucketObjectDefaults(t *testing.T) {
+	testCases := []struct {
+		data    string
+		success bool
+	}{
+		{`{}`, true},
+		{`{"tags": {"cost-center": "ops"}, "metadata": {"x-amz-meta-team": "storage"}}`, true},
+		{`{"metadata": {"Content-Type": "text/plain"}}`, false},
+		{`{"metadata": {"x-amz-meta-": "empty"}}`, false},
+		{`{"tags": {"": "empty"}}`, false},
+		{`{"tags": "cost-center=ops"}`, false},
+	}
+	for i, tc := range testCases {
+		if _, err := parseBucketObjectDefaults("bucket", []byte(tc.data)); (err == nil) != tc.success {
+			t.Errorf("case %d: expected success %v, got %v", i+1, tc.success, err)
+		}
+	}
+
+	d, err := parseBucketObjectDefaults("bucket", []byte(`{"metadata": {"x-amz-meta-team": "storage"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Metadata, map[string]string{"X-Amz-Meta-Team": "storage"}) {
+		t.Fatalf("expected canonical metadata keys, got %v", d.Metadata)
+	}
+}
+
+func TestBucketObjectDefaultsApply(t *testing.T) {
+	d := &BucketObjectDefaults{
+		Tags:     map[string]string{"cost-center": "ops", "env": "prod"},
+		Metadata: map[string]string{"X-Amz-Meta-Team": "storage"},
+	}
+
+	metadata := map[string]string{}
+	if err := d.apply(metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata[xhttp.AmzObjectTagging] != "cost-center=ops&env=prod" || metadata["X-Amz-Meta-Team"] != "storage" {
+		t.Fatalf("expected the defaults to be set, got %v", metadata)
+	}
+
+	// Tags and metadata set by the client are kept.
+	metadata = map[string]string{
+		xhttp.AmzObjectTagging: "env=dev&app=web",
+		"x-amz-meta-team":      "web",
+	}
+	if err := d.apply(metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata[xhttp.AmzObjectTagging] != "app=web&cost-center=ops&env=dev" || metadata["x-amz-meta-team"] != "web" || len(metadata) != 2 {
+		t.Fatalf("expected the client tags and metadata to be kept, got %v", metadata)
+	}
+
+	// Objects may not have more than ten tags.
+	metadata = map[string]string{
+		xhttp.AmzObjectTagging: "a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9",
+	}
+	if err := d.apply(metadata); err == nil {
+		t.Fatal("expected too many tags to fail")
+	}
+}
@@ -20,7 +20,11 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/minio/madmin-go"
@@ -28,9 +32,160 @@ import (
 	"github.com/minio/minio/internal/logger"
 )
 
+// maxBucketQuotaThreshold is the highest soft quota threshold, letting
+// soft quotas notify buckets growing well past their quota.
+const maxBucketQuotaThreshold = 1000
+
+var (
+	errInvalidBucketQuotaThreshold = errors.New("Soft quota thresholds must be percentages of the quota between 1 and 1000")
+	errBucketSoftQuotaNotHard      = errors.New("Only hard quotas can be made soft")
+)
+
+// BucketQuotaOptions - the soft quota and the accounting of the quota of
+// a bucket, configured along with its quota e.g.
+// {"quota":1073741824,"quotatype":"hard","thresholds":[80,90,100]}.
+type BucketQuotaOptions struct {
+	// Thresholds are the percentages of the quota notified when the
+	// bucket usage crosses them.
+	Thresholds []int `json:"thresholds,omitempty"`
+	// Soft only notifies the thresholds of a hard quota, uploads
+	// over the quota are not rejected.
+	Soft bool `json:"soft,omitempty"`
+	// ExcludeNoncurrent leaves the noncurrent versions out of the
+	// usage accounted against the quota.
+	ExcludeNoncurrent bool `json:"excludeNoncurrent,omitempty"`
+	// IncludeIncompleteUploads accounts the uploaded parts of the
+	// incomplete multipart uploads against the quota.
+	IncludeIncompleteUploads bool `json:"includeIncompleteUploads,omitempty"`
+}
+
+// bucketQuotaConfig - the quota configuration of a bucket as set by the
+// admin API.
+type bucketQuotaConfig struct {
+	madmin.BucketQuota
+	BucketQuotaOptions
+}
+
+// parseBucketQuotaOptions parses the options of the quota configuration
+// of a bucket, sorting its thresholds.
+func parseBucketQuotaOptions(data []byte) (*BucketQuotaOptions, error) {
+	var cfg bucketQuotaConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	opts := cfg.BucketQuotaOptions
+	if opts.Soft && cfg.BucketQuota.Type != madmin.HardQuota {
+		return nil, errBucketSoftQuotaNotHard
+	}
+	sort.Ints(opts.Thresholds)
+	for i, t := range opts.Thresholds {
+		if t < 1 || t > maxBucketQuotaThreshold || (i > 0 && t == opts.Thresholds[i-1]) {
+			return nil, errInvalidBucketQuotaThreshold
+		}
+	}
+	return &opts, nil
+}
+
+// crossedThreshold returns the highest threshold reached by usage of
+// quota, 0 if none.
+func (opts BucketQuotaOptions) crossedThreshold(usage, quota uint64) int {
+	if quota == 0 {
+		return 0
+	}
+	percent := 100 * float64(usage) / float64(quota)
+	crossed := 0
+	for _, t := range opts.Thresholds {
+		if percent >= float64(t) {
+			crossed = t
+		}
+	}
+	return crossed
+}
+
+// quotaUsage returns the usage of a bucket accounted against its quota,
+// uploads being the size of its incomplete multipart uploads.
+func (opts *BucketQuotaOptions) quotaUsage(bui BucketUsageInfo, uploads uint64) uint64 {
+	usage := bui.Size
+	if opts == nil {
+		return usage
+	}
+	if opts.ExcludeNoncurrent && bui.NoncurrentSize <= usage {
+		usage -= bui.NoncurrentSize
+	}
+	if opts.IncludeIncompleteUploads {
+		usage += uploads
+	}
+	return usage
+}
+
+// bucketSoftQuotas remembers the threshold crossed last by each bucket,
+// notifying a threshold once until the usage falls below it again.
+type bucketSoftQuotas struct {
+	mu      sync.Mutex
+	crossed map[string]int
+}
+
+var globalBucketSoftQuotas = &bucketSoftQuotas{crossed: make(map[string]int)}
+
+// cross records the threshold crossed by bucket, returning whether it
+// is notified.
+func (b *bucketSoftQuotas) cross(bucket string, threshold int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	last := b.crossed[bucket]
+	if threshold == 0 {
+		delete(b.crossed, bucket)
+	} else {
+		b.crossed[bucket] = threshold
+	}
+	return threshold > last
+}
+
+// evaluateSoftQuotas notifies the buckets crossing a threshold of their
+// soft quota with the usage of a scanner cycle.
+func evaluateSoftQuotas(ctx context.Context, dui DataUsageInfo) {
+	for bucket, bui := range dui.BucketsUsage {
+		q, err := globalBucketQuotaSys.Get(bucket)
+		if err != nil || q == nil || q.Quota == 0 {
+			globalBucketSoftQuotas.cross(bucket, 0)
+			continue
+		}
+		opts, err := globalBucketMetadataSys.GetQuotaOptions(bucket)
+		if err != nil || opts == nil {
+			continue
+		}
+		usage, err := globalBucketQuotaSys.quotaUsage(bucket, bui)
+		if err != nil {
+			logger.LogIf(ctx, err)
+			continue
+		}
+		threshold := opts.crossedThreshold(usage, q.Quota)
+		if !globalBucketSoftQuotas.cross(bucket, threshold) {
+			continue
+		}
+		auditLogInternal(ctx, bucket, "", AuditLogOptions{
+			Trigger: "soft-quota",
+			APIName: "BucketQuotaThreshold",
+			Status:  strconv.Itoa(threshold) + "%",
+		})
+		sendEvent(eventArgs{
+			EventName:  event.BucketQuotaThreshold,
+			BucketName: bucket,
+			ReqParams: map[string]string{
+				"quota":          strconv.FormatUint(q.Quota, 10),
+				"quotaType":      string(q.Type),
+				"quotaThreshold": strconv.Itoa(threshold),
+				"usage":          strconv.FormatUint(usage, 10),
+			},
+			Host: "Internal: [SOFT-QUOTA]",
+		})
+	}
+}
+
 // BucketQuotaSys - map of bucket and quota configuration.
 type BucketQuotaSys struct {
 	bucketStorageCache timedValue
+	bucketUploadsCache timedValue
 }
 
 // Get - Get quota configuration.
@@ -69,14 +224,15 @@ func (sys *BucketQuotaSys) check(ctx context.Context, bucket string, size int64)
 		return errServerNotInitialized
 	}
 
-	sys.bucketStorageCache.Once.Do(func() {
-		sys.bucketStorageCache.TTL = 1 * time.Second
-		sys.bucketStorageCache.Update = func() (interface{}, error) {
-			ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
-			defer done()
-			return loadDataUsageFromBackend(ctx, objAPI)
+	if t, ok := globalTenantSys.bucketTenant(bucket); ok && t.Quota > 0 {
+		dui, err := sys.usageInfo()
+		if err != nil {
+			return err
 		}
-	})
+		if err = globalTenantSys.checkQuota(bucket, size, dui); err != nil {
+			return err
+		}
+	}
 
 	q, err := sys.Get(bucket)
 	if err != nil {
@@ -84,16 +240,14 @@ func (sys *BucketQuotaSys) check(ctx context.Context, bucket string, size int64)
 	}
 
 	if q != nil && q.Type == madmin.HardQuota && q.Quota > 0 {
-		v, err := sys.bucketStorageCache.Get()
+		if opts, _ := globalBucketMetadataSys.GetQuotaOptions(bucket); opts != nil && opts.Soft {
+			return nil
+		}
+		dui, err := sys.usageInfo()
 		if err != nil {
 			return err
 		}
 
-		dui, ok := v.(DataUsageInfo)
-		if !ok {
-			return fmt.Errorf("internal error: Unexpected DUI data type: %T", v)
-		}
-
 		bui, ok := dui.BucketsUsage[bucket]
 		if !ok {
 			// bucket not found, cannot enforce quota
@@ -101,7 +255,12 @@ func (sys *BucketQuotaSys) check(ctx context.Context, bucket string, size int64)
 			return nil
 		}
 
-		if (bui.Size + uint64(size)) >= q.Quota {
+		usage, err := sys.quotaUsage(bucket, bui)
+		if err != nil {
+			return err
+		}
+
+		if (usage + uint64(size)) >= q.Quota {
 			return BucketQuotaExceeded{Bucket: bucket}
 		}
 	}
@@ -109,6 +268,75 @@ func (sys *BucketQuotaSys) check(ctx context.Context, bucket string, size int64)
 	return nil
 }
 
+// usageInfo returns the cached data usage of the cluster.
+func (sys *BucketQuotaSys) usageInfo() (DataUsageInfo, error) {
+	sys.bucketStorageCache.Once.Do(func() {
+		sys.bucketStorageCache.TTL = 1 * time.Second
+		sys.bucketStorageCache.Update = func() (interface{}, error) {
+			objAPI := newObjectLayerFn()
+			if objAPI == nil {
+				return nil, errServerNotInitialized
+			}
+			ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+			defer done()
+			return loadDataUsageFromBackend(ctx, objAPI)
+		}
+	})
+
+	v, err := sys.bucketStorageCache.Get()
+	if err != nil {
+		return DataUsageInfo{}, err
+	}
+
+	dui, ok := v.(DataUsageInfo)
+	if !ok {
+		return DataUsageInfo{}, fmt.Errorf("internal error: Unexpected DUI data type: %T", v)
+	}
+	return dui, nil
+}
+
+// incompleteUploadsSize returns the size of the incomplete multipart
+// uploads of bucket counted by the last scanner cycle.
+func (sys *BucketQuotaSys) incompleteUploadsSize(bucket string) (uint64, error) {
+	sys.bucketUploadsCache.Once.Do(func() {
+		sys.bucketUploadsCache.TTL = 10 * time.Second
+		sys.bucketUploadsCache.Update = func() (interface{}, error) {
+			objAPI := newObjectLayerFn()
+			if objAPI == nil {
+				return nil, errServerNotInitialized
+			}
+			ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+			defer done()
+			return loadIncompleteUploadsUsage(ctx, objAPI)
+		}
+	})
+
+	v, err := sys.bucketUploadsCache.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	u, ok := v.(IncompleteUploadsUsage)
+	if !ok {
+		return 0, fmt.Errorf("internal error: Unexpected uploads usage data type: %T", v)
+	}
+	return u.Buckets[bucket].Size, nil
+}
+
+// quotaUsage returns the usage of bucket accounted against its quota by
+// the options of the quota.
+func (sys *BucketQuotaSys) quotaUsage(bucket string, bui BucketUsageInfo) (uint64, error) {
+	opts, _ := globalBucketMetadataSys.GetQuotaOptions(bucket)
+	var uploads uint64
+	if opts != nil && opts.IncludeIncompleteUploads {
+		var err error
+		if uploads, err = sys.incompleteUploadsSize(bucket); err != nil {
+			return 0, err
+		}
+	}
+	return opts.quotaUsage(bui, uploads), nil
+}
+
 func enforceBucketQuota(ctx context.Context, bucket string, size int64) error {
 	if size < 0 {
 		return nil
@@ -126,7 +354,7 @@ func enforceFIFOQuotaBucket(ctx context.Context, objectAPI ObjectLayer, bucket s
 		return
 	}
 
-	if cfg.Type != madmin.FIFOQuota {
+	if cfg.Type != madmin.FIFOQuota || isBucketArchived(bucket) {
 		return
 	}
 
@@ -171,6 +399,10 @@ func enforceFIFOQuotaBucket(ctx context.Context, objectAPI ObjectLayer, bucket s
 		if rcfg.LockEnabled && enforceRetentionForDeletion(ctx, obj) {
 			continue
 		}
+		// skip versions pinned by a snapshot
+		if globalBucketSnapshotSys.pinned(ctx, objectAPI, bucket, obj.Name, obj.VersionID) {
+			continue
+		}
 		scorer.addFileWithObjInfo(obj, 1)
 	}
 
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBucketQuotaOptions(t *testing.T) {
+	testCases := []struct {
+		data       string
+		thresholds []int
+		soft       bool
+		err        error
+	}{
+		{data: `{"quota":100,"quotatype":"hard"}`},
+		{data: `{"quota":100,"quotatype":"hard","thresholds":[100,80,90]}`, thresholds: []int{80, 90, 100}},
+		{data: `{"quota":100,"quotatype":"hard","thresholds":[80,120],"soft":true}`, thresholds: []int{80, 120}, soft: true},
+		{data: `{"quota":100,"quotatype":"fifo","thresholds":[90]}`, thresholds: []int{90}},
+		{data: `{"quota":100,"quotatype":"fifo","soft":true}`, err: errBucketSoftQuotaNotHard},
+		{data: `{"quota":100,"quotatype":"hard","thresholds":[0]}`, err: errInvalidBucketQuotaThreshold},
+		{data: `{"quota":100,"quotatype":"hard","thresholds":[1001]}`, err: errInvalidBucketQuotaThreshold},
+		{data: `{"quota":100,"quotatype":"hard","thresholds":[80,80]}`, err: errInvalidBucketQuotaThreshold},
+	}
+	for i, tc := range testCases {
+		opts, err := parseBucketQuotaOptions([]byte(tc.data))
+		if err != tc.err {
+			t.Fatalf("case %d: expected error %v, got %v", i, tc.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(opts.Thresholds, tc.thresholds) || opts.Soft != tc.soft {
+			t.Fatalf("case %d: unexpected quota options %#v", i, opts)
+		}
+	}
+}
+
+func TestBucketSoftQuotaCrossing(t *testing.T) {
+	opts := BucketQuotaOptions{Thresholds: []int{80, 90, 100}}
+	b := &bucketSoftQuotas{crossed: make(map[string]int)}
+	steps := []struct {
+		usage     uint64
+		threshold int
+		notified  bool
+	}{
+		{usage: 50},
+		{usage: 85, threshold: 80, notified: true},
+		{usage: 89, threshold: 80},
+		// Crossing several thresholds at once notifies the highest.
+		{usage: 150, threshold: 100, notified: true},
+		{usage: 95, threshold: 90},
+		{usage: 99, threshold: 90},
+		{usage: 100, threshold: 100, notified: true},
+		{usage: 10},
+		{usage: 80, threshold: 80, notified: true},
+	}
+	for i, s := range steps {
+		threshold := opts.crossedThreshold(s.usage, 100)
+		if threshold != s.threshold {
+			t.Fatalf("step %d: expected threshold %d, got %d", i, s.threshold, threshold)
+		}
+		if notified := b.cross("bucket", threshold); notified != s.notified {
+			t.Fatalf("step %d: expected notified %v, got %v", i, s.notified, notified)
+		}
+	}
+	if got := opts.crossedThreshold(100, 0); got != 0 {
+		t.Fatalf("expected no threshold without quota, got %d", got)
+	}
+}
+
+func TestBucketQuotaOptionsUsage(t *testing.T) {
+	bui := BucketUsageInfo{Size: 100, NoncurrentSize: 40}
+	testCases := []struct {
+		opts  *BucketQuotaOptions
+		usage uint64
+	}{
+		{opts: nil, usage: 100},
+		{opts: &BucketQuotaOptions{}, usage: 100},
+		{opts: &BucketQuotaOptions{ExcludeNoncurrent: true}, usage: 60},
+		{opts: &BucketQuotaOptions{IncludeIncompleteUploads: true}, usage: 125},
+		{opts: &BucketQuotaOptions{ExcludeNoncurrent: true, IncludeIncompleteUploads: true}, usage: 85},
+	}
+	for i, tc := range testCases {
+		if usage := tc.opts.quotaUsage(bui, 25); usage != tc.usage {
+			t.Fatalf("case %d: expected usage %d, got %d", i, tc.usage, usage)
+		}
+	}
+}
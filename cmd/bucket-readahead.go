@@ -0,0 +1,328 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const bucketReadAheadConfigFile = "readahead.json"
+
+const (
+	// Defaults of a bucket read-ahead config.
+	defaultReadAheadStripes      = 4
+	defaultReadAheadMaxRangeSize = 1 << 20
+	maxReadAheadStripes          = 16
+
+	// A range is sequential if it starts at most this far after the
+	// end of the previous range of the client.
+	readAheadMaxGap = 64 << 10
+
+	// Consecutive sequential ranges from which the next stripes are
+	// prefetched.
+	readAheadMinStreak = 2
+
+	// Bounds of the streams tracked and of the prefetched data held
+	// in memory by a node.
+	readAheadMaxStreams = 10000
+	readAheadMaxMemory  = 512 << 20
+	readAheadStreamIdle = time.Minute
+)
+
+// BucketReadAheadConfig - configures prefetching for clients reading
+// objects of a bucket in sequential ranges of at most MaxRangeSize
+// bytes, such as Parquet readers. Once a client has read consecutive
+// ranges of an object, the Stripes erasure stripes following its last
+// range are read ahead in one go and its next ranges served from
+// memory.
+type BucketReadAheadConfig struct {
+	Enabled      bool  `json:"enabled"`
+	Stripes      int   `json:"stripes,omitempty"`
+	MaxRangeSize int64 `json:"maxRangeSize,omitempty"`
+}
+
+func parseBucketReadAheadConfig(bucket string, data []byte) (*BucketReadAheadConfig, error) {
+	cfg := &BucketReadAheadConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Stripes < 0 || cfg.Stripes > maxReadAheadStripes {
+		return cfg, fmt.Errorf("Invalid read-ahead stripes %d for bucket %s, must be between 1 and %d", cfg.Stripes, bucket, maxReadAheadStripes)
+	}
+	if cfg.MaxRangeSize < 0 || cfg.MaxRangeSize > cfg.prefetchSize() {
+		return cfg, fmt.Errorf("Invalid read-ahead maximum range size %d for bucket %s, must be at most the size of the stripes read ahead", cfg.MaxRangeSize, bucket)
+	}
+	return cfg, nil
+}
+
+func (c BucketReadAheadConfig) stripes() int64 {
+	if c.Stripes == 0 {
+		return defaultReadAheadStripes
+	}
+	return int64(c.Stripes)
+}
+
+func (c BucketReadAheadConfig) maxRangeSize() int64 {
+	if c.MaxRangeSize == 0 {
+		return defaultReadAheadMaxRangeSize
+	}
+	return c.MaxRangeSize
+}
+
+func (c BucketReadAheadConfig) prefetchSize() int64 {
+	return c.stripes() * blockSizeV2
+}
+
+// readAheadBuffer holds object data prefetched from start, data is
+// set once done is closed.
+type readAheadBuffer struct {
+	start   int64
+	end     int64 // last offset requested, data may be shorter
+	etag    string
+	modTime time.Time
+	data    []byte
+	err     error
+	done    chan struct{}
+}
+
+func (b *readAheadBuffer) covers(start, end int64) bool {
+	return start >= b.start && end <= b.end
+}
+
+// readAheadStream tracks the ranges a client reads from an object
+// version. cur holds the prefetched data the client reads from, next
+// the data prefetched after it.
+type readAheadStream struct {
+	lastEnd   int64
+	streak    int
+	used      time.Time
+	cur, next *readAheadBuffer
+}
+
+// ReadAheadStats - read-ahead activity of a node.
+type ReadAheadStats struct {
+	Hits            uint64
+	PrefetchedBytes uint64
+	BufferedBytes   int64
+}
+
+// readAheadCache prefetches the ranges of the sequential readers of
+// the objects of buckets configured for read-ahead.
+type readAheadCache struct {
+	mu        sync.Mutex
+	streams   map[string]*readAheadStream
+	memory    int64
+	lastPurge time.Time
+
+	hits            uint64
+	prefetchedBytes uint64
+}
+
+var globalReadAhead = &readAheadCache{streams: make(map[string]*readAheadStream)}
+
+// Stats returns the read-ahead activity of this node.
+func (c *readAheadCache) Stats() ReadAheadStats {
+	c.mu.Lock()
+	memory := c.memory
+	c.mu.Unlock()
+	return ReadAheadStats{
+		Hits:            atomic.LoadUint64(&c.hits),
+		PrefetchedBytes: atomic.LoadUint64(&c.prefetchedBytes),
+		BufferedBytes:   memory,
+	}
+}
+
+// release frees the memory of b, the caller must hold the lock.
+func (c *readAheadCache) release(b *readAheadBuffer) {
+	if b != nil {
+		c.memory -= b.end - b.start + 1
+	}
+}
+
+// purgeLocked drops the streams idle for too long, the caller must
+// hold the lock.
+func (c *readAheadCache) purgeLocked(now time.Time) {
+	if now.Sub(c.lastPurge) < readAheadStreamIdle/6 && len(c.streams) < readAheadMaxStreams {
+		return
+	}
+	c.lastPurge = now
+	for key, s := range c.streams {
+		if now.Sub(s.used) > readAheadStreamIdle {
+			c.release(s.cur)
+			c.release(s.next)
+			delete(c.streams, key)
+		}
+	}
+}
+
+// drop forgets the stream key, whose object changed.
+func (c *readAheadCache) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.streams[key]; ok {
+		c.release(s.cur)
+		c.release(s.next)
+		delete(c.streams, key)
+	}
+}
+
+// observe records a read of [start, end] on the stream key and returns
+// the buffer holding it, if any, and the range to prefetch next, if
+// any. Buffers already read past are released.
+func (c *readAheadCache) observe(key string, start, end int64, cfg *BucketReadAheadConfig) (hit, prefetch *readAheadBuffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.purgeLocked(now)
+	s, ok := c.streams[key]
+	if !ok {
+		if len(c.streams) >= readAheadMaxStreams {
+			return nil, nil
+		}
+		s = &readAheadStream{lastEnd: -1}
+		c.streams[key] = s
+	}
+	s.used = now
+
+	if s.lastEnd >= 0 && start > s.lastEnd && start-s.lastEnd-1 <= readAheadMaxGap {
+		s.streak++
+	} else {
+		s.streak = 0
+	}
+	s.lastEnd = end
+
+	if s.next != nil && s.next.covers(start, end) {
+		c.release(s.cur)
+		s.cur, s.next = s.next, nil
+	}
+	if s.cur != nil && !s.cur.covers(start, end) {
+		c.release(s.cur)
+		s.cur = nil
+		if s.next != nil && !s.next.covers(start, end) && start > s.next.end {
+			c.release(s.next)
+			s.next = nil
+		}
+	}
+	hit = s.cur
+	if s.streak < readAheadMinStreak {
+		return hit, nil
+	}
+
+	// Prefetch the stripes following the current range, or following
+	// the current buffer once half of it is read.
+	from := end + 1
+	if hit != nil {
+		if s.next != nil || end < hit.start+(hit.end-hit.start)/2 {
+			return hit, nil
+		}
+		from = hit.end + 1
+	}
+	to := (from/blockSizeV2+cfg.stripes())*blockSizeV2 - 1
+	if c.memory+to-from+1 > readAheadMaxMemory {
+		return hit, nil
+	}
+	c.memory += to - from + 1
+	prefetch = &readAheadBuffer{start: from, end: to, done: make(chan struct{})}
+	if hit != nil {
+		s.next = prefetch
+	} else {
+		s.cur = prefetch
+	}
+	return hit, prefetch
+}
+
+// readAheadClient returns the name of the stream of a client reading
+// with accessKey, empty for anonymous requests, over the connection
+// from remoteAddr.
+func readAheadClient(accessKey, remoteAddr string) string {
+	return accessKey + "@" + remoteAddr
+}
+
+// fetch reads buffer b of object in the background.
+func (c *readAheadCache) fetch(ctx context.Context, getObjectNInfo func(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, opts ObjectOptions) (*GetObjectReader, error), bucket, object string, opts ObjectOptions, b *readAheadBuffer) {
+	defer close(b.done)
+
+	opts.CheckPrecondFn = nil
+	gr, err := getObjectNInfo(ctx, bucket, object, &HTTPRangeSpec{Start: b.start, End: b.end}, http.Header{}, readLock, opts)
+	if err != nil {
+		b.err = err
+		return
+	}
+	defer gr.Close()
+
+	b.etag, b.modTime = gr.ObjInfo.ETag, gr.ObjInfo.ModTime
+	b.data, b.err = ioutil.ReadAll(io.LimitReader(gr, b.end-b.start+1))
+	atomic.AddUint64(&c.prefetchedBytes, uint64(len(b.data)))
+}
+
+// wrap returns getObjectNInfo serving the ranges of the sequential
+// readers of a bucket configured for read-ahead from memory. Data
+// prefetched is only served while the object is unchanged.
+func (c *readAheadCache) wrap(getObjectNInfo func(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, opts ObjectOptions) (*GetObjectReader, error),
+	objAPI ObjectLayer, cfg *BucketReadAheadConfig, client string) func(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, opts ObjectOptions) (*GetObjectReader, error) {
+	return func(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, opts ObjectOptions) (*GetObjectReader, error) {
+		if rs == nil || rs.IsSuffixLength || rs.End < rs.Start || rs.End-rs.Start+1 > cfg.maxRangeSize() {
+			return getObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+		}
+
+		key := pathJoin(client, bucket, object, opts.VersionID)
+		hit, prefetch := c.observe(key, rs.Start, rs.End, cfg)
+		if prefetch != nil {
+			go c.fetch(detachedContext(ctx), getObjectNInfo, bucket, object, opts, prefetch)
+		}
+		if hit == nil {
+			return getObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+		}
+
+		select {
+		case <-hit.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if hit.err != nil || rs.Start-hit.start >= int64(len(hit.data)) {
+			return getObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+		}
+
+		// Serve the prefetched data only if it is still the data of
+		// the latest version read.
+		objInfo, err := objAPI.GetObjectInfo(ctx, bucket, object, opts)
+		if err != nil || objInfo.ETag != hit.etag || !objInfo.ModTime.Equal(hit.modTime) {
+			c.drop(key)
+			return getObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+		}
+		if opts.CheckPrecondFn != nil && opts.CheckPrecondFn(objInfo) {
+			return nil, PreConditionFailed{}
+		}
+		data := hit.data[rs.Start-hit.start:]
+		if n := rs.End - rs.Start + 1; n < int64(len(data)) {
+			data = data[:n]
+		}
+		atomic.AddUint64(&c.hits, 1)
+		return NewGetObjectReaderFromReader(bytes.NewReader(data), objInfo, opts)
+	}
+}
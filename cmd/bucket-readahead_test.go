@@ -0,0 +1,152 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func TestParseBucketReadAheadConfig(t *testing.T) {
+	testCases := []struct {
+		data    string
+		success bool
+	}{
+		{`{"enabled": true}`, true},
+		{`{"enabled": true, "stripes": 16, "maxRangeSize": 16777216}`, true},
+		{`{"enabled": true, "stripes": 17}`, false},
+		{`{"enabled": true, "stripes": -1}`, false},
+		{`{"enabled": true, "stripes": 1, "maxRangeSize": 2097152}`, false},
+		{`{"enabled": true, "maxRangeSize": -1}`, false},
+		{`{"enabled": "yes"}`, false},
+	}
+	for i, tc := range testCases {
+		if _, err := parseBucketReadAheadConfig("bucket", []byte(tc.data)); (err == nil) != tc.success {
+			t.Errorf("case %d: expected success %v, got %v", i+1, tc.success, err)
+		}
+	}
+}
+
+func TestReadAheadObserve(t *testing.T) {
+	c := &readAheadCache{streams: make(map[string]*readAheadStream)}
+	cfg := &BucketReadAheadConfig{Enabled: true}
+	size := cfg.prefetchSize()
+
+	// The first ranges only establish the sequential pattern.
+	for i := int64(0); i < readAheadMinStreak; i++ {
+		if hit, prefetch := c.observe("key", i*100, i*100+99, cfg); hit != nil || prefetch != nil {
+			t.Fatalf("range %d: unexpected read-ahead", i)
+		}
+	}
+	_, prefetch := c.observe("key", 200, 299, cfg)
+	if prefetch == nil || prefetch.start != 300 || prefetch.end != size-1 {
+		t.Fatalf("expected the stripes after the range to be prefetched, got %+v", prefetch)
+	}
+	if c.Stats().BufferedBytes != size-300 {
+		t.Errorf("unexpected buffered bytes %d", c.Stats().BufferedBytes)
+	}
+
+	// Ranges in the first half of the buffer are served from it.
+	if hit, next := c.observe("key", 300, 399, cfg); hit != prefetch || next != nil {
+		t.Fatalf("expected a hit without prefetch, got %+v %+v", hit, next)
+	}
+	// Past the half the next stripes are prefetched, the client read
+	// on up to the middle of the buffer.
+	c.streams["key"].lastEnd = size/2 - 1
+	hit, next := c.observe("key", size/2, size/2+99, cfg)
+	if hit != prefetch || next == nil || next.start != size || next.end != 2*size-1 {
+		t.Fatalf("expected a hit and the next stripes to be prefetched, got %+v %+v", hit, next)
+	}
+	// Reading the next buffer releases the first one.
+	if hit, _ = c.observe("key", size+100, size+199, cfg); hit != next {
+		t.Fatalf("expected a hit of the next buffer, got %+v", hit)
+	}
+	if c.Stats().BufferedBytes != size {
+		t.Errorf("unexpected buffered bytes %d", c.Stats().BufferedBytes)
+	}
+
+	// A random read resets the stream.
+	if hit, prefetch = c.observe("key", 0, 99, cfg); hit != nil || prefetch != nil {
+		t.Errorf("unexpected read-ahead of a random read")
+	}
+	c.drop("key")
+	if c.Stats().BufferedBytes != 0 {
+		t.Errorf("expected all buffers to be released, got %d", c.Stats().BufferedBytes)
+	}
+}
+
+func TestReadAheadWrap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	if err = objLayer.MakeBucketWithLocation(ctx, "bucket", BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1000)
+	objInfo, err := objLayer.PutObject(ctx, "bucket", "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &readAheadCache{streams: make(map[string]*readAheadStream)}
+	client := readAheadClient("access", "10.0.0.1:4242")
+	done := make(chan struct{})
+	close(done)
+	c.streams[pathJoin(client, "bucket", "object", "")] = &readAheadStream{
+		lastEnd: -1,
+		cur: &readAheadBuffer{
+			start: 0, end: int64(len(data)) - 1,
+			etag: objInfo.ETag, modTime: objInfo.ModTime,
+			data: data, done: done,
+		},
+	}
+	getObjectNInfo := c.wrap(objLayer.GetObjectNInfo, objLayer, &BucketReadAheadConfig{Enabled: true}, client)
+
+	// The preconditions of the request apply to the data served from
+	// memory.
+	opts := ObjectOptions{CheckPrecondFn: func(ObjectInfo) bool { return true }}
+	if _, err = getObjectNInfo(ctx, "bucket", "object", &HTTPRangeSpec{Start: 0, End: 99}, nil, readLock, opts); !errors.Is(err, PreConditionFailed{}) {
+		t.Fatalf("expected the precondition to fail, got %v", err)
+	}
+	if c.Stats().Hits != 0 {
+		t.Errorf("unexpected hits %d", c.Stats().Hits)
+	}
+
+	opts.CheckPrecondFn = func(ObjectInfo) bool { return false }
+	gr, err := getObjectNInfo(ctx, "bucket", "object", &HTTPRangeSpec{Start: 100, End: 199}, nil, readLock, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil || !bytes.Equal(got, data[100:200]) {
+		t.Fatalf("unexpected data %d bytes, %v", len(got), err)
+	}
+	if c.Stats().Hits != 1 {
+		t.Errorf("expected a hit, got %d", c.Stats().Hits)
+	}
+}
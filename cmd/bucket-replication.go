@@ -23,6 +23,7 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -823,6 +824,7 @@ func getReplicationAction(oi1 ObjectInfo, oi2 minio.ObjectInfo, opType replicati
 // replicateObject replicates the specified version of the object to destination bucket
 // The source object is then updated to reflect the replication status.
 func replicateObject(ctx context.Context, ri ReplicateObjectInfo, objectAPI ObjectLayer, trigger string) {
+	ctx = withTrafficClass(ctx, trafficClassReplication)
 	var replicationStatus replication.StatusType
 	defer func() {
 		if replicationStatus.Empty() {
@@ -1559,14 +1561,18 @@ func getproxyTargets(ctx context.Context, bucket, object string, opts ObjectOpti
 	return tgts
 }
 
-func proxyHeadToRepTarget(ctx context.Context, bucket, object string, opts ObjectOptions, proxyTargets *madmin.BucketTargets) (tgt *TargetClient, oi ObjectInfo, proxy bool) {
-	// this option is set when active-active replication is in place between site A -> B,
-	// and site B does not have the object yet.
-	if opts.ProxyRequest || (opts.ProxyHeaderSet && !opts.ProxyRequest) { // true only when site B sets MinIOSourceProxyRequest header
-		return nil, oi, false
-	}
+type proxyTargetClient struct {
+	target madmin.BucketTarget
+	client *TargetClient
+}
+
+// proxyTargetClients returns the online targets reads may be proxied
+// to, in the configured order or by lowest measured latency when the
+// nearest replica is preferred. Targets not measured yet come first.
+func proxyTargetClients(ctx context.Context, proxyTargets *madmin.BucketTargets) []proxyTargetClient {
+	var clients []proxyTargetClient
 	for _, t := range proxyTargets.Targets {
-		tgt = globalBucketTargetSys.GetRemoteTargetClient(ctx, t.Arn)
+		tgt := globalBucketTargetSys.GetRemoteTargetClient(ctx, t.Arn)
 		if tgt == nil || tgt.IsOffline() {
 			continue
 		}
@@ -1574,7 +1580,24 @@ func proxyHeadToRepTarget(ctx context.Context, bucket, object string, opts Objec
 		if tgt.disableProxy {
 			continue
 		}
+		clients = append(clients, proxyTargetClient{target: t, client: tgt})
+	}
+	if globalAPIConfig.isReplicationProxyNearest() {
+		sort.SliceStable(clients, func(i, j int) bool {
+			return clients[i].client.latency.Value() < clients[j].client.latency.Value()
+		})
+	}
+	return clients
+}
 
+func proxyHeadToRepTarget(ctx context.Context, bucket, object string, opts ObjectOptions, proxyTargets *madmin.BucketTargets) (tgt *TargetClient, oi ObjectInfo, proxy bool) {
+	// this option is set when active-active replication is in place between site A -> B,
+	// and site B does not have the object yet.
+	if opts.ProxyRequest || (opts.ProxyHeaderSet && !opts.ProxyRequest) { // true only when site B sets MinIOSourceProxyRequest header
+		return nil, oi, false
+	}
+	for _, pt := range proxyTargetClients(ctx, proxyTargets) {
+		t, tgt := pt.target, pt.client
 		gopts := miniogo.GetObjectOptions{
 			VersionID:            opts.VersionID,
 			ServerSideEncryption: opts.ServerSideEncryption,
@@ -1582,7 +1605,12 @@ func proxyHeadToRepTarget(ctx context.Context, bucket, object string, opts Objec
 				ReplicationProxyRequest: "true",
 			},
 		}
+		start := time.Now()
 		objInfo, err := tgt.StatObject(ctx, t.TargetBucket, object, gopts)
+		// Responses of the target measure its latency, also errors.
+		if err == nil || miniogo.ToErrorResponse(err).Code != "" {
+			tgt.latency.Add(float64(time.Since(start)))
+		}
 		if err != nil {
 			continue
 		}
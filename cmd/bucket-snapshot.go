@@ -0,0 +1,536 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// Snapshots of a bucket are saved under buckets/<bucket>/snapshots/<id>/.
+	bucketSnapshotsPrefix = "snapshots"
+	bucketSnapshotInfo    = "info.json"
+	bucketSnapshotEntries = "manifest.json"
+
+	bucketSnapshotMountFile = "snapshot-mount.json"
+
+	// The ids of the snapshots of a bucket are kept in its metadata
+	// too, all nodes check the versions they pin before deleting one.
+	bucketSnapshotsFile = "snapshots.json"
+)
+
+var (
+	errBucketSnapshotNotFound = errors.New("bucket snapshot not found")
+	errBucketSnapshotMounted  = errors.New("bucket snapshot is mounted")
+)
+
+// BucketSnapshotInfo describes a point-in-time snapshot of a bucket.
+type BucketSnapshotInfo struct {
+	ID      string    `json:"id"`
+	Bucket  string    `json:"bucket"`
+	Created time.Time `json:"created"`
+	Objects int64     `json:"objects"`
+	Size    int64     `json:"size"`
+}
+
+// bucketSnapshotEntry pins the latest version of an object when the
+// snapshot was taken.
+type bucketSnapshotEntry struct {
+	Name      string    `json:"n"`
+	VersionID string    `json:"v"`
+	Size      int64     `json:"s"`
+	ETag      string    `json:"e"`
+	ModTime   time.Time `json:"m"`
+	// Block is the dedup block holding the content of the version.
+	Block string `json:"b,omitempty"`
+}
+
+// BucketSnapshotMount is the configuration of a bucket serving a
+// snapshot of another bucket, read-only.
+type BucketSnapshotMount struct {
+	Bucket   string `json:"bucket"`
+	Snapshot string `json:"snapshot"`
+}
+
+// parseBucketSnapshotMount parses BucketSnapshotMount from json
+func parseBucketSnapshotMount(data []byte) (*BucketSnapshotMount, error) {
+	mount := &BucketSnapshotMount{}
+	if err := json.Unmarshal(data, mount); err != nil {
+		return nil, err
+	}
+	if mount.Bucket == "" || mount.Snapshot == "" {
+		return nil, fmt.Errorf("Invalid snapshot mount %#v", mount)
+	}
+	return mount, nil
+}
+
+// parseBucketSnapshots parses the ids of the snapshots of a bucket from json
+func parseBucketSnapshots(data []byte) ([]string, error) {
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// updateBucketSnapshots adds id to or removes it from the snapshots
+// recorded in the metadata of bucket.
+func updateBucketSnapshots(ctx context.Context, objAPI ObjectLayer, bucket, id string, add bool) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, pathJoin(bucketMetaPrefix, bucket, "snapshots.lock"))
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	defer lk.Unlock(lkctx.Cancel)
+
+	// The metadata in memory may be stale, the ids are read from disk.
+	meta, err := loadBucketMetadata(lkctx.Context(), objAPI, bucket)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(meta.snapshots)+1)
+	for _, v := range meta.snapshots {
+		if v != id {
+			ids = append(ids, v)
+		}
+	}
+	if add {
+		ids = append(ids, id)
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketSnapshotsFile, data)
+}
+
+// newBucketSnapshotID returns ids sorting in creation order.
+func newBucketSnapshotID(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+func bucketSnapshotFile(bucket, id, file string) string {
+	return path.Join(bucketConfigPrefix, bucket, bucketSnapshotsPrefix, id, file)
+}
+
+// createBucketSnapshot records the latest version of every object of a
+// versioned bucket, no data is copied. The snapshot is recorded in the
+// bucket metadata before its versions are listed, until it is complete
+// no version of the bucket can be deleted.
+func createBucketSnapshot(ctx context.Context, objAPI ObjectLayer, bucket string) (info BucketSnapshotInfo, err error) {
+	info = BucketSnapshotInfo{
+		Bucket:  bucket,
+		Created: UTCNow(),
+	}
+	info.ID = newBucketSnapshotID(info.Created)
+
+	if err = updateBucketSnapshots(ctx, objAPI, bucket, info.ID, true); err != nil {
+		return info, err
+	}
+	defer func() {
+		if err != nil {
+			logger.LogIf(ctx, updateBucketSnapshots(GlobalContext, objAPI, bucket, info.ID, false))
+		}
+	}()
+
+	entries := []bucketSnapshotEntry{}
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			return info, err
+		}
+		for _, oi := range loi.Objects {
+			block, _ := oi.dedupBlock()
+			entries = append(entries, bucketSnapshotEntry{
+				Name:      oi.Name,
+				VersionID: oi.VersionID,
+				Size:      oi.Size,
+				ETag:      oi.ETag,
+				ModTime:   oi.ModTime,
+				Block:     block,
+			})
+			info.Objects++
+			info.Size += oi.Size
+		}
+		if !loi.IsTruncated {
+			break
+		}
+		marker = loi.NextMarker
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return info, err
+	}
+	if err = saveConfig(ctx, objAPI, bucketSnapshotFile(bucket, info.ID, bucketSnapshotEntries), data); err != nil {
+		return info, err
+	}
+	// The info is saved last, a snapshot without it is incomplete.
+	if data, err = json.Marshal(info); err != nil {
+		return info, err
+	}
+	return info, saveConfig(ctx, objAPI, bucketSnapshotFile(bucket, info.ID, bucketSnapshotInfo), data)
+}
+
+func readBucketSnapshotInfo(ctx context.Context, objAPI ObjectLayer, bucket, id string) (info BucketSnapshotInfo, err error) {
+	data, err := readConfig(ctx, objAPI, bucketSnapshotFile(bucket, id, bucketSnapshotInfo))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			err = errBucketSnapshotNotFound
+		}
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+// listBucketSnapshots returns all complete snapshots of bucket, oldest first.
+func listBucketSnapshots(ctx context.Context, objAPI ObjectLayer, bucket string) ([]BucketSnapshotInfo, error) {
+	prefix := path.Join(bucketConfigPrefix, bucket, bucketSnapshotsPrefix) + SlashSeparator
+	infos := []BucketSnapshotInfo{}
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return nil, err
+		}
+		for _, oi := range loi.Objects {
+			if path.Base(oi.Name) != bucketSnapshotInfo {
+				continue
+			}
+			info, err := readBucketSnapshotInfo(ctx, objAPI, bucket, path.Base(path.Dir(oi.Name)))
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+		if !loi.IsTruncated {
+			break
+		}
+		marker = loi.NextMarker
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos, nil
+}
+
+// deleteBucketSnapshot removes a snapshot of bucket unless it is mounted,
+// incomplete snapshots left behind by a failed create are removed too.
+func deleteBucketSnapshot(ctx context.Context, objAPI ObjectLayer, bucket, id string) error {
+	if _, err := readBucketSnapshotInfo(ctx, objAPI, bucket, id); err != nil {
+		if err != errBucketSnapshotNotFound || !bucketSnapshotRecorded(bucket, id) {
+			return err
+		}
+	}
+	buckets, err := objAPI.ListBuckets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		mount, _ := globalBucketMetadataSys.GetSnapshotMount(b.Name)
+		if mount != nil && mount.Bucket == bucket && mount.Snapshot == id {
+			return errBucketSnapshotMounted
+		}
+	}
+	for _, file := range []string{bucketSnapshotInfo, bucketSnapshotEntries} {
+		if err = deleteConfig(ctx, objAPI, bucketSnapshotFile(bucket, id, file)); err != nil && !errors.Is(err, errConfigNotFound) {
+			return err
+		}
+	}
+	globalBucketSnapshotSys.evict(bucket, id)
+	return updateBucketSnapshots(ctx, objAPI, bucket, id, false)
+}
+
+func bucketSnapshotRecorded(bucket, id string) bool {
+	for _, v := range globalBucketMetadataSys.GetSnapshots(bucket) {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mountBucketSnapshot creates the bucket target serving the snapshot read-only.
+func mountBucketSnapshot(ctx context.Context, objAPI ObjectLayer, bucket, id, target string) error {
+	if _, err := readBucketSnapshotInfo(ctx, objAPI, bucket, id); err != nil {
+		return err
+	}
+	data, err := json.Marshal(BucketSnapshotMount{Bucket: bucket, Snapshot: id})
+	if err != nil {
+		return err
+	}
+	if err = objAPI.MakeBucketWithLocation(ctx, target, BucketOptions{}); err != nil {
+		return err
+	}
+	globalNotificationSys.LoadBucketMetadata(GlobalContext, target)
+	return globalBucketMetadataSys.Update(target, bucketSnapshotMountFile, data)
+}
+
+// bucketSnapshot is a loaded snapshot, the object layer calls below
+// serve its objects from the pinned versions of the source bucket.
+type bucketSnapshot struct {
+	objAPI  ObjectLayer
+	info    BucketSnapshotInfo
+	entries []bucketSnapshotEntry
+}
+
+func loadBucketSnapshot(ctx context.Context, objAPI ObjectLayer, bucket, id string) (*bucketSnapshot, error) {
+	info, err := readBucketSnapshotInfo(ctx, objAPI, bucket, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readConfig(ctx, objAPI, bucketSnapshotFile(bucket, id, bucketSnapshotEntries))
+	if err != nil {
+		return nil, err
+	}
+	s := &bucketSnapshot{objAPI: objAPI, info: info}
+	if err = json.Unmarshal(data, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *bucketSnapshot) lookup(bucket, object string) (bucketSnapshotEntry, error) {
+	i := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].Name >= object
+	})
+	if i == len(s.entries) || s.entries[i].Name != object {
+		return bucketSnapshotEntry{}, ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	return s.entries[i], nil
+}
+
+// GetObjectNInfo - reads the version of object pinned by the snapshot.
+func (s *bucketSnapshot) GetObjectNInfo(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, opts ObjectOptions) (*GetObjectReader, error) {
+	entry, err := s.lookup(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	opts.VersionID = entry.VersionID
+	return s.objAPI.GetObjectNInfo(ctx, s.info.Bucket, object, rs, h, lockType, opts)
+}
+
+// GetObjectInfo - returns the version of object pinned by the snapshot.
+func (s *bucketSnapshot) GetObjectInfo(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	entry, err := s.lookup(bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	opts.VersionID = entry.VersionID
+	return s.objAPI.GetObjectInfo(ctx, s.info.Bucket, object, opts)
+}
+
+// ListObjects - lists the objects of the snapshot with the same
+// semantics as ObjectLayer.ListObjects.
+func (s *bucketSnapshot) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (loi ListObjectsInfo, err error) {
+	if maxKeys <= 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+	start := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].Name > marker && s.entries[i].Name >= prefix
+	})
+	count := 0
+	for _, entry := range s.entries[start:] {
+		if !strings.HasPrefix(entry.Name, prefix) {
+			break
+		}
+		var commonPrefix string
+		if delimiter != "" {
+			if i := strings.Index(entry.Name[len(prefix):], delimiter); i >= 0 {
+				commonPrefix = entry.Name[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if commonPrefix != "" {
+			n := len(loi.Prefixes)
+			if (n > 0 && loi.Prefixes[n-1] == commonPrefix) || commonPrefix <= marker {
+				continue
+			}
+		}
+		if count == maxKeys {
+			loi.IsTruncated = true
+			break
+		}
+		count++
+		if commonPrefix != "" {
+			loi.Prefixes = append(loi.Prefixes, commonPrefix)
+			loi.NextMarker = commonPrefix
+			continue
+		}
+		loi.Objects = append(loi.Objects, ObjectInfo{
+			Bucket:    bucket,
+			Name:      entry.Name,
+			VersionID: entry.VersionID,
+			Size:      entry.Size,
+			ETag:      entry.ETag,
+			ModTime:   entry.ModTime,
+			IsLatest:  true,
+		})
+		loi.NextMarker = entry.Name
+	}
+	if !loi.IsTruncated {
+		loi.NextMarker = ""
+	}
+	return loi, nil
+}
+
+// ListObjectsV2 - lists the objects of the snapshot with the same
+// semantics as ObjectLayer.ListObjectsV2.
+func (s *bucketSnapshot) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (ListObjectsV2Info, error) {
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
+	}
+	loi, err := s.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return ListObjectsV2Info{}, err
+	}
+	return ListObjectsV2Info{
+		IsTruncated:           loi.IsTruncated,
+		ContinuationToken:     continuationToken,
+		NextContinuationToken: loi.NextMarker,
+		Objects:               loi.Objects,
+		Prefixes:              loi.Prefixes,
+	}, nil
+}
+
+// ListObjectVersions - lists the pinned versions of the snapshot, a
+// snapshot holds a single version per object.
+func (s *bucketSnapshot) ListObjectVersions(ctx context.Context, bucket, prefix, marker, versionMarker, delimiter string, maxKeys int) (ListObjectVersionsInfo, error) {
+	loi, err := s.ListObjects(ctx, bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return ListObjectVersionsInfo{}, err
+	}
+	return ListObjectVersionsInfo{
+		IsTruncated: loi.IsTruncated,
+		NextMarker:  loi.NextMarker,
+		Objects:     loi.Objects,
+		Prefixes:    loi.Prefixes,
+	}, nil
+}
+
+// bucketSnapshotSys caches the snapshots served by mounted buckets and
+// those pinning the versions of their bucket, snapshots never change.
+type bucketSnapshotSys struct {
+	mu        sync.Mutex
+	snapshots map[string]*bucketSnapshot
+}
+
+var globalBucketSnapshotSys = &bucketSnapshotSys{
+	snapshots: make(map[string]*bucketSnapshot),
+}
+
+// mounted returns the snapshot served by bucket, nil if bucket is not
+// a snapshot mount.
+func (sys *bucketSnapshotSys) mounted(ctx context.Context, objAPI ObjectLayer, bucket string) (*bucketSnapshot, error) {
+	mount, err := globalBucketMetadataSys.GetSnapshotMount(bucket)
+	if err != nil || mount == nil {
+		return nil, nil
+	}
+
+	return sys.load(ctx, objAPI, mount.Bucket, mount.Snapshot)
+}
+
+func (sys *bucketSnapshotSys) load(ctx context.Context, objAPI ObjectLayer, bucket, id string) (*bucketSnapshot, error) {
+	key := pathJoin(bucket, id)
+	sys.mu.Lock()
+	defer sys.mu.Unlock()
+	if s, ok := sys.snapshots[key]; ok {
+		return s, nil
+	}
+	s, err := loadBucketSnapshot(ctx, objAPI, bucket, id)
+	if err != nil {
+		return nil, err
+	}
+	sys.snapshots[key] = s
+	return s, nil
+}
+
+// pinned returns whether a snapshot of bucket pins versionID of object,
+// deleting the version would break the snapshot. Snapshots which are
+// incomplete or cannot be read pin all versions.
+func (sys *bucketSnapshotSys) pinned(ctx context.Context, objAPI ObjectLayer, bucket, object, versionID string) bool {
+	ids := globalBucketMetadataSys.GetSnapshots(bucket)
+	if len(ids) == 0 {
+		return false
+	}
+	if versionID == "" {
+		versionID = nullVersionID
+	}
+	for _, id := range ids {
+		s, err := sys.load(ctx, objAPI, bucket, id)
+		if err != nil {
+			if err != errBucketSnapshotNotFound {
+				logger.LogIf(ctx, fmt.Errorf("unable to load snapshot %s of bucket %s: %w", id, bucket, err))
+			}
+			return true
+		}
+		entry, err := s.lookup(bucket, object)
+		if err != nil {
+			continue
+		}
+		if v := entry.VersionID; v == versionID || (v == "" && versionID == nullVersionID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sys *bucketSnapshotSys) evict(bucket, id string) {
+	sys.mu.Lock()
+	delete(sys.snapshots, pathJoin(bucket, id))
+	sys.mu.Unlock()
+}
+
+// setSnapshotMountHandler rejects requests modifying the objects of a
+// bucket mounting a snapshot, bucket level configuration and deleting
+// the bucket to unmount it stay allowed.
+func setSnapshotMountHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet, r.Method == http.MethodHead, newObjectLayerFn() == nil:
+			h.ServeHTTP(w, r)
+			return
+		case guessIsRPCReq(r), isAdminReq(r), guessIsHealthCheckReq(r), guessIsMetricsReq(r):
+			h.ServeHTTP(w, r)
+			return
+		}
+		bucket, object := request2BucketObjectName(r)
+		if bucket == "" || isMinioMetaBucketName(bucket) || (object == "" && r.Method != http.MethodPost) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if mount, _ := globalBucketMetadataSys.GetSnapshotMount(bucket); mount != nil {
+			writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrBucketSnapshotReadOnly), r.URL)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/bucket/lifecycle"
+)
+
+func TestBucketSnapshotListObjects(t *testing.T) {
+	s := &bucketSnapshot{
+		entries: []bucketSnapshotEntry{
+			{Name: "a"},
+			{Name: "dir/b"},
+			{Name: "dir/c"},
+			{Name: "dir/sub/d"},
+			{Name: "e"},
+		},
+	}
+
+	testCases := []struct {
+		prefix, marker, delimiter string
+		maxKeys                   int
+		objects                   []string
+		prefixes                  []string
+		truncated                 bool
+	}{
+		{"", "", "", 0, []string{"a", "dir/b", "dir/c", "dir/sub/d", "e"}, nil, false},
+		{"", "", "/", 0, []string{"a", "e"}, []string{"dir/"}, false},
+		{"dir/", "", "/", 0, []string{"dir/b", "dir/c"}, []string{"dir/sub/"}, false},
+		{"", "", "", 2, []string{"a", "dir/b"}, nil, true},
+		{"", "dir/b", "", 2, []string{"dir/c", "dir/sub/d"}, nil, true},
+		{"", "a", "/", 1, nil, []string{"dir/"}, true},
+		{"", "dir/", "/", 0, []string{"e"}, nil, false},
+	}
+
+	for i, tc := range testCases {
+		loi, err := s.ListObjects(context.Background(), "mount", tc.prefix, tc.marker, tc.delimiter, tc.maxKeys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var objects []string
+		for _, oi := range loi.Objects {
+			objects = append(objects, oi.Name)
+		}
+		if !reflect.DeepEqual(objects, tc.objects) || !reflect.DeepEqual(loi.Prefixes, tc.prefixes) || loi.IsTruncated != tc.truncated {
+			t.Errorf("Test %d: expected %v %v %v, got %v %v %v", i+1, tc.objects, tc.prefixes, tc.truncated,
+				objects, loi.Prefixes, loi.IsTruncated)
+		}
+	}
+}
+
+func TestBucketSnapshotPinsVersions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	// Snapshots are recorded in the bucket metadata.
+	oldMetadataSys, oldNotificationSys, oldIsErasure := globalBucketMetadataSys, globalNotificationSys, globalIsErasure
+	defer func() {
+		globalBucketMetadataSys, globalNotificationSys, globalIsErasure = oldMetadataSys, oldNotificationSys, oldIsErasure
+	}()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+	globalNotificationSys = NewNotificationSys(EndpointServerPools{})
+	globalIsErasure = true
+	defer setObjectLayer(newObjectLayerFn())
+	setObjectLayer(objLayer)
+
+	bucket, object := "bucket", "object"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{VersioningEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	put := func(data []byte) ObjectInfo {
+		oi, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{Versioned: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return oi
+	}
+
+	old := put([]byte("before snapshot"))
+	info, err := createBucketSnapshot(ctx, objLayer, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Objects != 1 || info.Size != old.Size {
+		t.Fatalf("unexpected snapshot info %#v", info)
+	}
+	cur := put([]byte("after snapshot"))
+
+	s, err := loadBucketSnapshot(ctx, objLayer, bucket, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oi, err := s.GetObjectInfo(ctx, "mount", object, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oi.VersionID != old.VersionID || oi.ETag != old.ETag {
+		t.Fatalf("expected snapshot to serve version %s, got %s", old.VersionID, oi.VersionID)
+	}
+	if _, err = s.GetObjectInfo(ctx, "mount", "missing", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected object not found, got %v", err)
+	}
+
+	snapshots, err := listBucketSnapshots(ctx, objLayer, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != info.ID {
+		t.Fatalf("expected snapshot %s to be listed, got %#v", info.ID, snapshots)
+	}
+
+	// The pinned version cannot be deleted, the others can.
+	if ids := globalBucketMetadataSys.GetSnapshots(bucket); !reflect.DeepEqual(ids, []string{info.ID}) {
+		t.Fatalf("expected snapshot %s to be recorded, got %v", info.ID, ids)
+	}
+	if !globalBucketSnapshotSys.pinned(ctx, objLayer, bucket, object, old.VersionID) {
+		t.Fatalf("expected version %s to be pinned", old.VersionID)
+	}
+	if globalBucketSnapshotSys.pinned(ctx, objLayer, bucket, object, cur.VersionID) {
+		t.Fatalf("expected version %s not to be pinned", cur.VersionID)
+	}
+	if globalBucketSnapshotSys.pinned(ctx, objLayer, bucket, "missing", old.VersionID) {
+		t.Fatal("expected a missing object not to be pinned")
+	}
+
+	lc, err := lifecycle.ParseLifecycleConfig(bytes.NewReader([]byte(`<LifecycleConfiguration><Rule><ID>noncurrent</ID><Filter></Filter><Status>Enabled</Status><NoncurrentVersionExpiration><NoncurrentDays>1</NoncurrentDays></NoncurrentVersionExpiration></Rule></LifecycleConfiguration>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noncurrent := ObjectInfo{
+		Bucket:           bucket,
+		Name:             object,
+		VersionID:        old.VersionID,
+		ModTime:          time.Now().Add(-72 * time.Hour),
+		SuccessorModTime: time.Now().Add(-48 * time.Hour),
+	}
+	if action := evalActionFromLifecycle(ctx, *lc, noncurrent, false); action != lifecycle.NoneAction {
+		t.Fatalf("expected the pinned version not to expire, got %v", action)
+	}
+	noncurrent.VersionID = mustGetUUID()
+	if action := evalActionFromLifecycle(ctx, *lc, noncurrent, false); action != lifecycle.DeleteVersionAction {
+		t.Fatalf("expected an unpinned version to expire, got %v", action)
+	}
+
+	if err = deleteBucketSnapshot(ctx, objLayer, bucket, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ids := globalBucketMetadataSys.GetSnapshots(bucket); len(ids) != 0 {
+		t.Fatalf("expected no snapshots to be recorded, got %v", ids)
+	}
+	if globalBucketSnapshotSys.pinned(ctx, objLayer, bucket, object, old.VersionID) {
+		t.Fatal("expected no version to be pinned after deleting the snapshot")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/VividCortex/ewma"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/minio/madmin-go"
 	minio "github.com/minio/minio-go/v7"
@@ -370,6 +371,7 @@ func (sys *BucketTargetSys) getRemoteTargetClient(tcfg *madmin.BucketTarget) (*T
 		healthCancelFn:      cancelFn,
 		ARN:                 tcfg.Arn,
 		ResetID:             tcfg.ResetID,
+		latency:             &lockedSimpleEWMA{SimpleEWMA: new(ewma.SimpleEWMA)},
 	}
 	return tc, nil
 }
@@ -446,4 +448,7 @@ type TargetClient struct {
 	healthCancelFn      context.CancelFunc // cancellation function for client healthcheck
 	ARN                 string             //ARN to uniquely identify remote target
 	ResetID             string
+	// moving average of the latency of proxied requests, zero until
+	// the first request.
+	latency *lockedSimpleEWMA
 }
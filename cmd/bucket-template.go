@@ -0,0 +1,485 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7/pkg/replication"
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/bucket/lifecycle"
+	objectlock "github.com/minio/minio/internal/bucket/object/lock"
+	sreplication "github.com/minio/minio/internal/bucket/replication"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/kms"
+	"github.com/minio/minio/internal/logger"
+	iampolicy "github.com/minio/pkg/iam/policy"
+	"github.com/minio/pkg/wildcard"
+)
+
+const (
+	bucketTemplatesConfigFile = "bucket-templates.json"
+
+	bucketTemplatesFormatVersion1 = 1
+
+	// Replaced by the name of the bucket in the target buckets of the
+	// replication targets of a template.
+	bucketTemplateBucketVar = "${bucket}"
+)
+
+var (
+	errNoSuchBucketTemplate          = errors.New("The specified bucket template does not exist")
+	errInvalidBucketTemplateName     = errors.New("Bucket template names must be non-empty and shorter than 64 characters")
+	errBucketTemplateNeedsVersioning = errors.New("Bucket templates with replication targets must enable versioning")
+	errBucketTemplateNeedsObjectLock = errors.New("Bucket templates with an object lock config must enable object lock")
+	errInvalidBucketTemplateTarget   = errors.New("Replication targets of bucket templates need an endpoint, credentials and a target bucket")
+	errBucketTemplateMismatch        = errors.New("The bucket name does not match the patterns of the bucket template")
+)
+
+// BucketTemplate - the configs a new bucket starts with. A template is
+// applied to the buckets created matching one of its patterns, the
+// X-Minio-Bucket-Template header choosing among the matching templates.
+type BucketTemplate struct {
+	Name string `json:"name"`
+	// Bucket name patterns, like "finance-*".
+	Patterns   []string `json:"patterns,omitempty"`
+	Versioning bool     `json:"versioning,omitempty"`
+	// Object lock enables versioning too.
+	ObjectLock bool `json:"objectLock,omitempty"`
+	// XML documents as accepted by PutObjectLockConfiguration,
+	// PutBucketLifecycleConfiguration and PutBucketEncryption.
+	ObjectLockConfig string              `json:"objectLockConfig,omitempty"`
+	Lifecycle        string              `json:"lifecycle,omitempty"`
+	Encryption       string              `json:"encryption,omitempty"`
+	Quota            *madmin.BucketQuota `json:"quota,omitempty"`
+	// Remote targets all objects and deletes are replicated to,
+	// "${bucket}" in a target bucket is replaced by the name of the
+	// bucket.
+	ReplicationTargets []madmin.BucketTarget `json:"replicationTargets,omitempty"`
+}
+
+func (t BucketTemplate) validate() error {
+	if t.Name == "" || len(t.Name) > 63 {
+		return errInvalidBucketTemplateName
+	}
+	if t.ObjectLockConfig != "" {
+		if !t.ObjectLock {
+			return errBucketTemplateNeedsObjectLock
+		}
+		if _, err := objectlock.ParseObjectLockConfig(strings.NewReader(t.ObjectLockConfig)); err != nil {
+			return err
+		}
+	}
+	if t.Lifecycle != "" {
+		lc, err := lifecycle.ParseLifecycleConfig(strings.NewReader(t.Lifecycle))
+		if err != nil {
+			return err
+		}
+		if err = lc.Validate(); err != nil {
+			return err
+		}
+		if err = validateTransitionTier(lc); err != nil {
+			return err
+		}
+	}
+	if t.Encryption != "" {
+		if _, err := validateBucketSSEConfig(strings.NewReader(t.Encryption)); err != nil {
+			return err
+		}
+		if GlobalKMS == nil {
+			return errKMSNotConfigured
+		}
+	}
+	if t.Quota != nil && !t.Quota.IsValid() {
+		return fmt.Errorf("Invalid quota config %#v", t.Quota)
+	}
+	if len(t.ReplicationTargets) > 0 && !t.Versioning && !t.ObjectLock {
+		return errBucketTemplateNeedsVersioning
+	}
+	for _, target := range t.ReplicationTargets {
+		if target.Endpoint == "" || target.TargetBucket == "" || target.Credentials == nil {
+			return errInvalidBucketTemplateTarget
+		}
+	}
+	return nil
+}
+
+func (t BucketTemplate) hasBucket(bucket string) bool {
+	for _, pattern := range t.Patterns {
+		if wildcard.Match(pattern, bucket) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowed returns whether cred may request t by name, which needs
+// the permissions of setting all configs of t on bucket.
+func (t BucketTemplate) isAllowed(r *http.Request, cred auth.Credentials, owner bool, bucket string) bool {
+	var actions []iampolicy.Action
+	if t.Versioning || t.ObjectLock {
+		actions = append(actions, iampolicy.PutBucketVersioningAction)
+	}
+	if t.ObjectLockConfig != "" {
+		actions = append(actions, iampolicy.PutBucketObjectLockConfigurationAction)
+	}
+	if t.Lifecycle != "" {
+		actions = append(actions, iampolicy.PutBucketLifecycleAction)
+	}
+	if t.Encryption != "" {
+		actions = append(actions, iampolicy.PutBucketEncryptionAction)
+	}
+	if t.Quota != nil {
+		actions = append(actions, iampolicy.SetBucketQuotaAdminAction)
+	}
+	if len(t.ReplicationTargets) > 0 {
+		actions = append(actions, iampolicy.PutReplicationConfigurationAction, iampolicy.SetBucketTargetAction)
+	}
+	for _, action := range actions {
+		if !globalIAMSys.IsAllowed(iampolicy.Args{
+			AccountName:     cred.AccessKey,
+			Groups:          cred.Groups,
+			Action:          action,
+			BucketName:      bucket,
+			ConditionValues: getConditionValues(r, "", cred.AccessKey, cred.Claims),
+			IsOwner:         owner,
+			Claims:          cred.Claims,
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply sets the configs of t on the newly created bucket, object lock
+// is enabled by creating the bucket with it.
+func (t BucketTemplate) apply(ctx context.Context, bucket string) error {
+	if t.Versioning && !t.ObjectLock {
+		if err := globalBucketMetadataSys.Update(bucket, bucketVersioningConfig, enabledBucketVersioningConfig); err != nil {
+			return err
+		}
+	}
+	if t.ObjectLockConfig != "" {
+		cfg, err := objectlock.ParseObjectLockConfig(strings.NewReader(t.ObjectLockConfig))
+		if err != nil {
+			return err
+		}
+		data, err := xml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		if err = globalBucketMetadataSys.Update(bucket, objectLockConfig, data); err != nil {
+			return err
+		}
+	}
+	if t.Lifecycle != "" {
+		lc, err := lifecycle.ParseLifecycleConfig(strings.NewReader(t.Lifecycle))
+		if err != nil {
+			return err
+		}
+		data, err := xml.Marshal(lc)
+		if err != nil {
+			return err
+		}
+		if err = globalBucketMetadataSys.Update(bucket, bucketLifecycleConfig, data); err != nil {
+			return err
+		}
+	}
+	if t.Encryption != "" {
+		cfg, err := validateBucketSSEConfig(strings.NewReader(t.Encryption))
+		if err != nil {
+			return err
+		}
+		data, err := xml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		if err = globalBucketMetadataSys.Update(bucket, bucketSSEConfig, data); err != nil {
+			return err
+		}
+	}
+	if t.Quota != nil {
+		data, err := json.Marshal(t.Quota)
+		if err != nil {
+			return err
+		}
+		if err = globalBucketMetadataSys.Update(bucket, bucketQuotaConfigFile, data); err != nil {
+			return err
+		}
+	}
+	if len(t.ReplicationTargets) > 0 {
+		return t.applyReplication(ctx, bucket)
+	}
+	return nil
+}
+
+// applyReplication adds the replication targets of t to bucket, with a
+// rule replicating everything to each of them.
+func (t BucketTemplate) applyReplication(ctx context.Context, bucket string) error {
+	var replicationConfig replication.Config
+	for i, target := range t.ReplicationTargets {
+		target.SourceBucket = bucket
+		target.TargetBucket = strings.ReplaceAll(target.TargetBucket, bucketTemplateBucketVar, bucket)
+		target.Type = madmin.ReplicationService
+		if target.API == "" {
+			target.API = "s3v4"
+		}
+		target.Arn = globalBucketTargetSys.getRemoteARN(bucket, &target)
+		if err := globalBucketTargetSys.SetTarget(ctx, bucket, &target, false); err != nil {
+			return err
+		}
+		if err := replicationConfig.AddRule(replication.Options{
+			ID:                      fmt.Sprintf("template-%s-%d", t.Name, i+1),
+			Priority:                fmt.Sprintf("%d", i+1),
+			Op:                      replication.AddOption,
+			RuleStatus:              "enable",
+			DestBucket:              target.Arn,
+			ReplicateDeletes:        "enable",
+			ReplicateDeleteMarkers:  "enable",
+			ReplicaSync:             "enable",
+			ExistingObjectReplicate: "enable",
+		}); err != nil {
+			return err
+		}
+	}
+	targets, err := globalBucketTargetSys.ListBucketTargets(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	tgtBytes, err := json.Marshal(&targets)
+	if err != nil {
+		return err
+	}
+	if err = globalBucketMetadataSys.Update(bucket, bucketTargetsFile, tgtBytes); err != nil {
+		return err
+	}
+
+	// Convert to the server type, like site replication, to validate the
+	// config.
+	replCfgBytes, err := xml.Marshal(replicationConfig)
+	if err != nil {
+		return err
+	}
+	cfg, err := sreplication.ParseConfig(bytes.NewReader(replCfgBytes))
+	if err != nil {
+		return err
+	}
+	sameTarget, apiErr := validateReplicationDestination(ctx, bucket, cfg)
+	if apiErr != noError {
+		return fmt.Errorf("bucket replication config validation error: %#v", apiErr)
+	}
+	if err = cfg.Validate(bucket, sameTarget); err != nil {
+		return err
+	}
+	replCfgData, err := xml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketReplicationConfig, replCfgData)
+}
+
+// applyBucketTemplate sets the configs of t on the newly created
+// bucket. The bucket is removed again when that fails, so that it does
+// not exist without them.
+func applyBucketTemplate(ctx context.Context, objAPI ObjectLayer, bucket string, t BucketTemplate) error {
+	err := t.apply(ctx, bucket)
+	if err != nil {
+		objAPI.DeleteBucket(context.Background(), bucket, DeleteBucketOptions{Force: true, NoRecreate: true})
+		globalNotificationSys.DeleteBucketMetadata(ctx, bucket)
+	}
+	return err
+}
+
+type bucketTemplatesConfig struct {
+	Version   int                       `json:"version"`
+	Templates map[string]BucketTemplate `json:"templates"`
+}
+
+// BucketTemplateSys holds the bucket templates of the cluster.
+type BucketTemplateSys struct {
+	sync.RWMutex
+	templates map[string]BucketTemplate
+}
+
+// NewBucketTemplateSys - creates a new bucket template system.
+func NewBucketTemplateSys() *BucketTemplateSys {
+	return &BucketTemplateSys{templates: make(map[string]BucketTemplate)}
+}
+
+func getBucketTemplatesConfigPath() string {
+	return pathJoin(minioConfigPrefix, bucketTemplatesConfigFile)
+}
+
+// Init - loads the bucket templates from the backend, they are
+// encrypted when a KMS is configured as they hold the credentials of
+// their replication targets.
+func (sys *BucketTemplateSys) Init(ctx context.Context, objAPI ObjectLayer) error {
+	configFile := getBucketTemplatesConfigPath()
+	buf, err := readConfig(ctx, objAPI, configFile)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			sys.set(nil)
+			return nil
+		}
+		return err
+	}
+	if !utf8.Valid(buf) && GlobalKMS != nil {
+		buf, err = config.DecryptBytes(GlobalKMS, buf, kms.Context{
+			minioMetaBucket: path.Join(minioMetaBucket, configFile),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	var cfg bucketTemplatesConfig
+	if err = json.Unmarshal(buf, &cfg); err != nil {
+		return err
+	}
+	if cfg.Version != bucketTemplatesFormatVersion1 {
+		return fmt.Errorf("Unexpected bucket templates config version: %d", cfg.Version)
+	}
+	sys.set(cfg.Templates)
+	return nil
+}
+
+func (sys *BucketTemplateSys) set(templates map[string]BucketTemplate) {
+	if templates == nil {
+		templates = make(map[string]BucketTemplate)
+	}
+	sys.Lock()
+	defer sys.Unlock()
+	sys.templates = templates
+}
+
+// save persists templates and tells all peers to reload them.
+func (sys *BucketTemplateSys) save(ctx context.Context, objAPI ObjectLayer, templates map[string]BucketTemplate) error {
+	buf, err := json.Marshal(bucketTemplatesConfig{
+		Version:   bucketTemplatesFormatVersion1,
+		Templates: templates,
+	})
+	if err != nil {
+		return err
+	}
+	configFile := getBucketTemplatesConfigPath()
+	if GlobalKMS != nil {
+		buf, err = config.EncryptBytes(GlobalKMS, buf, kms.Context{
+			minioMetaBucket: path.Join(minioMetaBucket, configFile),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err = saveConfig(ctx, objAPI, configFile, buf); err != nil {
+		return err
+	}
+	sys.set(templates)
+	for _, nerr := range globalNotificationSys.LoadBucketTemplates(ctx) {
+		if nerr.Err != nil {
+			logger.GetReqInfo(ctx).SetTags("peerAddress", nerr.Host.String())
+			logger.LogIf(ctx, nerr.Err)
+		}
+	}
+	return nil
+}
+
+// copy returns a copy of the templates to modify.
+func (sys *BucketTemplateSys) copy() map[string]BucketTemplate {
+	sys.RLock()
+	defer sys.RUnlock()
+	templates := make(map[string]BucketTemplate, len(sys.templates))
+	for name, t := range sys.templates {
+		templates[name] = t
+	}
+	return templates
+}
+
+// SetTemplate - creates or replaces a bucket template, existing buckets
+// are left alone.
+func (sys *BucketTemplateSys) SetTemplate(ctx context.Context, objAPI ObjectLayer, t BucketTemplate) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	templates := sys.copy()
+	templates[t.Name] = t
+	return sys.save(ctx, objAPI, templates)
+}
+
+// RemoveTemplate - removes a bucket template, the buckets created with
+// it keep their configs.
+func (sys *BucketTemplateSys) RemoveTemplate(ctx context.Context, objAPI ObjectLayer, name string) error {
+	templates := sys.copy()
+	if _, ok := templates[name]; !ok {
+		return errNoSuchBucketTemplate
+	}
+	delete(templates, name)
+	return sys.save(ctx, objAPI, templates)
+}
+
+// ListTemplates - returns all bucket templates sorted by name.
+func (sys *BucketTemplateSys) ListTemplates() []BucketTemplate {
+	sys.RLock()
+	defer sys.RUnlock()
+	templates := make([]BucketTemplate, 0, len(sys.templates))
+	for _, t := range sys.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// bucketTemplate returns the template a new bucket is created with, the
+// template called name or, without name, the template with a pattern
+// matching bucket. When patterns of several templates match, the
+// template with the smallest name wins. A template called name must
+// match bucket as well.
+func (sys *BucketTemplateSys) bucketTemplate(bucket, name string) (BucketTemplate, bool, error) {
+	sys.RLock()
+	defer sys.RUnlock()
+	if name != "" {
+		t, ok := sys.templates[name]
+		if !ok {
+			return BucketTemplate{}, false, errNoSuchBucketTemplate
+		}
+		if !t.hasBucket(bucket) {
+			return BucketTemplate{}, false, errBucketTemplateMismatch
+		}
+		return t, true, nil
+	}
+	var found BucketTemplate
+	var ok bool
+	for tname, t := range sys.templates {
+		if t.hasBucket(bucket) && (!ok || tname < found.Name) {
+			found, ok = t, true
+		}
+	}
+	return found, ok, nil
+}
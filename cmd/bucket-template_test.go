@@ -0,0 +1,90 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestBucketTemplateValidate(t *testing.T) {
+	target := madmin.BucketTarget{
+		Endpoint:     "dr.example.com:9000",
+		TargetBucket: bucketTemplateBucketVar,
+		Credentials:  &madmin.Credentials{AccessKey: "minio", SecretKey: "minio123"},
+	}
+	testCases := []struct {
+		t   BucketTemplate
+		err bool
+	}{
+		{t: BucketTemplate{Name: "plain"}},
+		{t: BucketTemplate{}, err: true},
+		{t: BucketTemplate{Name: "versioned", Versioning: true}},
+		{t: BucketTemplate{Name: "locked", ObjectLock: true, ObjectLockConfig: `<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>`}},
+		{t: BucketTemplate{Name: "lock-config-only", ObjectLockConfig: `<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled></ObjectLockConfiguration>`}, err: true},
+		{t: BucketTemplate{Name: "bad-lock", ObjectLock: true, ObjectLockConfig: `<ObjectLockConfiguration>`}, err: true},
+		{t: BucketTemplate{Name: "ilm", Lifecycle: `<LifecycleConfiguration><Rule><ID>expire</ID><Status>Enabled</Status><Filter></Filter><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`}},
+		{t: BucketTemplate{Name: "bad-ilm", Lifecycle: `<LifecycleConfiguration></LifecycleConfiguration>`}, err: true},
+		{t: BucketTemplate{Name: "quota", Quota: &madmin.BucketQuota{Quota: 1 << 30, Type: madmin.HardQuota}}},
+		{t: BucketTemplate{Name: "replicated", Versioning: true, ReplicationTargets: []madmin.BucketTarget{target}}},
+		{t: BucketTemplate{Name: "unversioned-replication", ReplicationTargets: []madmin.BucketTarget{target}}, err: true},
+		{t: BucketTemplate{Name: "no-credentials", Versioning: true, ReplicationTargets: []madmin.BucketTarget{{Endpoint: "dr.example.com:9000", TargetBucket: "dr"}}}, err: true},
+	}
+	for i, tc := range testCases {
+		if err := tc.t.validate(); (err != nil) != tc.err {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, tc.err, err)
+		}
+	}
+}
+
+func TestBucketTemplateLookup(t *testing.T) {
+	sys := NewBucketTemplateSys()
+	sys.set(map[string]BucketTemplate{
+		"finance":  {Name: "finance", Patterns: []string{"finance-*"}},
+		"archive":  {Name: "archive", Patterns: []string{"*-archive"}},
+		"explicit": {Name: "explicit"},
+	})
+
+	testCases := []struct {
+		bucket, name string
+		expected     string
+		err          error
+	}{
+		{bucket: "finance-ledger", expected: "finance"},
+		{bucket: "logs-archive", expected: "archive"},
+		// Both match, the smallest name wins.
+		{bucket: "finance-archive", expected: "archive"},
+		{bucket: "photos"},
+		{bucket: "finance-archive", name: "finance", expected: "finance"},
+		// Templates requested by name must match the bucket too.
+		{bucket: "photos", name: "finance", err: errBucketTemplateMismatch},
+		{bucket: "finance-ledger", name: "explicit", err: errBucketTemplateMismatch},
+		{bucket: "photos", name: "missing", err: errNoSuchBucketTemplate},
+	}
+	for i, tc := range testCases {
+		tmpl, ok, err := sys.bucketTemplate(tc.bucket, tc.name)
+		if err != tc.err {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, tc.err, err)
+			continue
+		}
+		if ok != (tc.expected != "") || tmpl.Name != tc.expected {
+			t.Errorf("Test %d: expected template %q, got %q", i+1, tc.expected, tmpl.Name)
+		}
+	}
+}
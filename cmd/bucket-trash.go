@@ -0,0 +1,301 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/minio/minio/internal/bucket/lifecycle"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	bucketTrashConfigFile = "trash.json"
+
+	// Trashed objects of a bucket are kept under the same name in
+	// the volume pathJoin(minioMetaBucketTrash, bucket), such that
+	// they hash to the erasure set holding the original object.
+	minioMetaBucketTrash = minioMetaBucket + "/bucket-trash"
+
+	// Internal metadata recording when an object was trashed.
+	trashedAtKey = ReservedMetadataPrefixLower + "trashed-at"
+
+	defaultTrashRetentionDays = 7
+	trashPurgeInterval        = time.Hour
+)
+
+var trashPurgeLeaderLockTimeout = newDynamicTimeout(30*time.Second, 10*time.Second)
+
+// BucketTrashConfig - per bucket soft delete configuration, when
+// enabled deleting the latest version of an object in an unversioned
+// bucket moves it to the bucket trash for RetentionDays instead.
+type BucketTrashConfig struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retentionDays,omitempty"`
+}
+
+// Retention returns how long trashed objects are kept.
+func (c BucketTrashConfig) Retention() time.Duration {
+	days := c.RetentionDays
+	if days == 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TrashedObject - an object held in the trash of a bucket.
+type TrashedObject struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	ETag      string    `json:"etag"`
+	ModTime   time.Time `json:"modTime"`
+	TrashedAt time.Time `json:"trashedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ListTrashResult - a page of the trash of a bucket.
+type ListTrashResult struct {
+	Objects     []TrashedObject `json:"objects"`
+	IsTruncated bool            `json:"isTruncated"`
+	NextMarker  string          `json:"nextMarker,omitempty"`
+}
+
+// parseBucketTrashConfig parses BucketTrashConfig from json
+func parseBucketTrashConfig(bucket string, data []byte) (*BucketTrashConfig, error) {
+	cfg := &BucketTrashConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.RetentionDays < 0 {
+		return cfg, fmt.Errorf("Invalid trash retention %d days for bucket %s", cfg.RetentionDays, bucket)
+	}
+	return cfg, nil
+}
+
+// trashBucketName returns the volume holding the trash of bucket.
+func trashBucketName(bucket string) string {
+	return pathJoin(minioMetaBucketTrash, bucket)
+}
+
+// trashEnabled returns true when a delete with opts on bucket moves
+// the object to the trash instead of removing it.
+func trashEnabled(bucket string, opts ObjectOptions) bool {
+	if opts.VersionID != "" && opts.VersionID != nullVersionID {
+		return false
+	}
+	// Versioned buckets already keep deleted objects as noncurrent versions.
+	if opts.Versioned || opts.VersionSuspended || opts.DeletePrefix {
+		return false
+	}
+	cfg, err := globalBucketMetadataSys.GetTrashConfig(bucket)
+	return err == nil && cfg != nil && cfg.Enabled
+}
+
+// makeBucketTrash prepares the trash of bucket before enabling it.
+func makeBucketTrash(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return NotImplemented{}
+	}
+	return z.makeTrashVolume(ctx, bucket)
+}
+
+// trashObject moves object into the trash of bucket, if the
+// preconditions of opts hold.
+func trashObject(ctx context.Context, objAPI ObjectLayer, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return ObjectInfo{}, NotImplemented{}
+	}
+	return z.trashObject(ctx, bucket, object, opts)
+}
+
+// trashObjects moves objects into the trash of bucket, with the same
+// semantics as ObjectLayer.DeleteObjects.
+func trashObjects(ctx context.Context, objAPI ObjectLayer, bucket string, objects []ObjectToDelete) ([]DeletedObject, []error) {
+	dobjects := make([]DeletedObject, len(objects))
+	errs := make([]error, len(objects))
+	for i, obj := range objects {
+		_, errs[i] = trashObject(ctx, objAPI, bucket, obj.ObjectName, ObjectOptions{})
+		dobjects[i] = DeletedObject{
+			ObjectName: obj.ObjectName,
+			VersionID:  obj.VersionID,
+		}
+	}
+	return dobjects, errs
+}
+
+// restoreTrashedObject moves object back from the trash of bucket, it
+// fails if an object with the same name was written in the meantime.
+func restoreTrashedObject(ctx context.Context, objAPI ObjectLayer, bucket, object string) (ObjectInfo, error) {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return ObjectInfo{}, NotImplemented{}
+	}
+	return z.restoreTrashedObject(ctx, bucket, object)
+}
+
+// listTrash lists up to maxKeys trashed objects of bucket under prefix.
+func listTrash(ctx context.Context, objAPI ObjectLayer, bucket, prefix, marker string, maxKeys int) (ListTrashResult, error) {
+	cfg, err := globalBucketMetadataSys.GetTrashConfig(bucket)
+	if err != nil {
+		return ListTrashResult{}, err
+	}
+	if cfg == nil {
+		cfg = &BucketTrashConfig{}
+	}
+	loi, err := objAPI.ListObjects(ctx, trashBucketName(bucket), prefix, marker, "", maxKeys)
+	if err != nil {
+		if isErrBucketNotFound(err) {
+			// Nothing was ever trashed in this bucket.
+			return ListTrashResult{}, nil
+		}
+		return ListTrashResult{}, err
+	}
+	result := ListTrashResult{
+		Objects:     make([]TrashedObject, 0, len(loi.Objects)),
+		IsTruncated: loi.IsTruncated,
+		NextMarker:  loi.NextMarker,
+	}
+	for _, oi := range loi.Objects {
+		trashedAt := trashedTime(oi)
+		result.Objects = append(result.Objects, TrashedObject{
+			Name:      oi.Name,
+			Size:      oi.Size,
+			ETag:      oi.ETag,
+			ModTime:   oi.ModTime,
+			TrashedAt: trashedAt,
+			ExpiresAt: trashedAt.Add(cfg.Retention()),
+		})
+	}
+	return result, nil
+}
+
+// trashedTime returns when oi was moved to the trash, entries
+// missing the marker fall back to their modification time.
+func trashedTime(oi ObjectInfo) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, oi.UserDefined[trashedAtKey]); err == nil {
+		return t
+	}
+	return oi.ModTime
+}
+
+// deleteBucketTrash removes the trash of a deleted bucket.
+func deleteBucketTrash(ctx context.Context, objAPI ObjectLayer, bucket string) {
+	err := objAPI.DeleteBucket(ctx, trashBucketName(bucket), DeleteBucketOptions{Force: true, NoRecreate: true})
+	if err != nil && !isErrBucketNotFound(err) {
+		logger.LogIf(ctx, err)
+	}
+}
+
+// purgeBucketTrash removes the trashed objects of bucket past their retention.
+func purgeBucketTrash(ctx context.Context, objAPI ObjectLayer, bucket string, retention time.Duration) error {
+	trashBucket := trashBucketName(bucket)
+	now := UTCNow()
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, trashBucket, "", marker, "", maxObjectList)
+		if err != nil {
+			if isErrBucketNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		for _, oi := range loi.Objects {
+			if now.Sub(trashedTime(oi)) < retention {
+				continue
+			}
+			if _, err = objAPI.DeleteObject(ctx, trashBucket, oi.Name, ObjectOptions{}); err != nil {
+				if !isErrObjectNotFound(err) {
+					logger.LogIf(ctx, err)
+				}
+				continue
+			}
+			if oi.TransitionedObject.Status == lifecycle.TransitionComplete {
+				// The remote data was kept around for a restore, drop it too.
+				tobj := oi.TransitionedObject
+				logger.LogIf(ctx, deleteObjectFromRemoteTier(ctx, tobj.Name, tobj.VersionID, tobj.Tier))
+			}
+		}
+		if !loi.IsTruncated {
+			return nil
+		}
+		marker = loi.NextMarker
+	}
+}
+
+// initTrashPurge starts the routine removing expired trashed objects.
+func initTrashPurge(ctx context.Context, objAPI ObjectLayer) {
+	go runTrashPurge(ctx, objAPI)
+}
+
+// runTrashPurge periodically purges the trash of all buckets, only
+// the node holding the leader lock does the work.
+func runTrashPurge(ctx context.Context, objAPI ObjectLayer) {
+	locker := objAPI.NewNSLock(minioMetaBucket, "runTrashPurge.lock")
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		lkctx, err := locker.GetLock(ctx, trashPurgeLeaderLockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Duration(r.Float64() * float64(trashPurgeInterval)))
+			continue
+		}
+		ctx = lkctx.Context()
+		defer lkctx.Cancel()
+		break
+		// No unlock for "leader" lock.
+	}
+
+	purgeTimer := time.NewTimer(trashPurgeInterval)
+	defer purgeTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-purgeTimer.C:
+			buckets, err := objAPI.ListBuckets(ctx)
+			if err != nil {
+				logger.LogIf(ctx, err)
+			}
+			for _, bucket := range buckets {
+				cfg, err := globalBucketMetadataSys.GetTrashConfig(bucket.Name)
+				if err != nil || cfg == nil {
+					continue
+				}
+				// Trash left over from a disabled config expires all the same.
+				jctx, release, err := globalJobScheduler.acquire(ctx, jobTrashPurge)
+				if err != nil {
+					return
+				}
+				if err = purgeBucketTrash(jctx, objAPI, bucket.Name, cfg.Retention()); jctx.Err() == nil {
+					logger.LogIf(ctx, err)
+				}
+				release()
+			}
+			purgeTimer.Reset(trashPurgeInterval)
+		}
+	}
+}
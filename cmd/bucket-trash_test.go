@@ -0,0 +1,192 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseBucketTrashConfig(t *testing.T) {
+	cfg, err := parseBucketTrashConfig("bucket", []byte(`{"enabled":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Enabled || cfg.Retention() != defaultTrashRetentionDays*24*time.Hour {
+		t.Fatalf("unexpected config %#v", cfg)
+	}
+	if _, err = parseBucketTrashConfig("bucket", []byte(`{"enabled":true,"retentionDays":-1}`)); err == nil {
+		t.Fatal("expected negative retention to be rejected")
+	}
+}
+
+func TestTrashRestoreObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket, object := "bucket", "dir/object"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = makeBucketTrash(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1<<20)
+	oi, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = trashObject(ctx, objLayer, bucket, object, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected trashed object to be gone, got %v", err)
+	}
+
+	loi, err := objLayer.ListObjects(ctx, trashBucketName(bucket), "", "", "", maxObjectList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loi.Objects) != 1 || loi.Objects[0].Name != object || loi.Objects[0].ETag != oi.ETag {
+		t.Fatalf("expected %s in the trash, got %#v", object, loi.Objects)
+	}
+	if trashedTime(loi.Objects[0]).Before(oi.ModTime) {
+		t.Fatal("expected trashed time to be recorded")
+	}
+
+	if _, err = restoreTrashedObject(ctx, objLayer, bucket, object); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ETag != oi.ETag || restored.Size != oi.Size {
+		t.Fatalf("expected restored object to match, got %#v", restored)
+	}
+
+	if _, err = restoreTrashedObject(ctx, objLayer, bucket, object); err == nil {
+		t.Fatal("expected restoring over an existing object to fail")
+	}
+}
+
+// Objects nested under the name of a trashed or restored object stay
+// where they are.
+func TestTrashRestoreNestedObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = makeBucketTrash(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1<<20)
+	for _, name := range []string{"logs", "logs/2021.txt"} {
+		if _, err = objLayer.PutObject(ctx, bucket, name, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = trashObject(ctx, objLayer, bucket, "logs", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "logs/2021.txt", ObjectOptions{}); err != nil {
+		t.Fatalf("expected nested object to stay in the bucket, got %v", err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, trashBucketName(bucket), "logs/2021.txt", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected nested object not to be trashed, got %v", err)
+	}
+
+	if _, err = objLayer.PutObject(ctx, bucket, "logs/2022.txt", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = restoreTrashedObject(ctx, objLayer, bucket, "logs"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"logs", "logs/2021.txt", "logs/2022.txt"} {
+		if _, err = objLayer.GetObjectInfo(ctx, bucket, name, ObjectOptions{}); err != nil {
+			t.Fatalf("expected %s after restoring, got %v", name, err)
+		}
+	}
+}
+
+// Trashing an object drops it from the memory cache, it is not served
+// from memory once deleted.
+func TestTrashObjectMemCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	globalObjectMemCache.setLimits(1<<20, 1<<10)
+	defer globalObjectMemCache.setLimits(0, 0)
+
+	bucket, object := "bucket", "object"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = makeBucketTrash(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello world")
+	if _, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	gr, err := objLayer.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := globalObjectMemCache.get(bucket, object); !ok {
+		t.Fatal("expected the object to be cached")
+	}
+
+	if _, err = trashObject(ctx, objLayer, bucket, object, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected trashed object to be gone, got %v", err)
+	}
+}
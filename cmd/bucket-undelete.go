@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Maximum number of undeleted object names returned in a result.
+const maxUndeleteResultObjects = 1000
+
+// UndeleteOpts selects the delete markers removed by an undelete.
+type UndeleteOpts struct {
+	Prefix string
+	// Only delete markers created in [After, Before) are removed,
+	// a zero Before means up to now.
+	After  time.Time
+	Before time.Time
+	DryRun bool
+}
+
+// UndeleteError - a delete marker which could not be removed.
+type UndeleteError struct {
+	Object    string `json:"object"`
+	VersionID string `json:"versionId"`
+	Error     string `json:"error"`
+}
+
+// UndeleteResult - summary of an undelete.
+type UndeleteResult struct {
+	DryRun    bool            `json:"dryRun,omitempty"`
+	Scanned   int64           `json:"scanned"`
+	Undeleted int64           `json:"undeleted"`
+	Objects   []string        `json:"objects,omitempty"`
+	Truncated bool            `json:"objectsTruncated,omitempty"`
+	Errors    []UndeleteError `json:"errors,omitempty"`
+}
+
+// parseUndeleteOpts parses the undelete options from the request form.
+func parseUndeleteOpts(form url.Values) (opts UndeleteOpts, err error) {
+	opts.Prefix = form.Get("prefix")
+	opts.DryRun = form.Get("dry-run") == "true"
+	if form.Get("after") == "" {
+		return opts, errors.New("after is required to select the delete markers to remove")
+	}
+	if opts.After, err = time.Parse(time.RFC3339, form.Get("after")); err != nil {
+		return opts, err
+	}
+	if v := form.Get("before"); v != "" {
+		if opts.Before, err = time.Parse(time.RFC3339, v); err != nil {
+			return opts, err
+		}
+		if !opts.Before.After(opts.After) {
+			return opts, errors.New("before must be later than after")
+		}
+	}
+	return opts, nil
+}
+
+func (opts UndeleteOpts) matches(oi ObjectInfo) bool {
+	if !oi.DeleteMarker || !oi.IsLatest {
+		return false
+	}
+	if oi.ModTime.Before(opts.After) {
+		return false
+	}
+	return opts.Before.IsZero() || oi.ModTime.Before(opts.Before)
+}
+
+// undeleteObjects removes the latest delete markers of bucket matching
+// opts, making their previous versions current again. Delete markers
+// which are not the latest version are left alone, since removing them
+// does not bring back any object.
+func undeleteObjects(ctx context.Context, objAPI ObjectLayer, bucket string, opts UndeleteOpts) (UndeleteResult, error) {
+	result := UndeleteResult{DryRun: opts.DryRun}
+	marker, versionMarker := "", ""
+	for {
+		loi, err := objAPI.ListObjectVersions(ctx, bucket, opts.Prefix, marker, versionMarker, "", maxDeleteList)
+		if err != nil {
+			return result, err
+		}
+
+		var markers []ObjectToDelete
+		for _, oi := range loi.Objects {
+			result.Scanned++
+			if opts.matches(oi) {
+				markers = append(markers, ObjectToDelete{
+					ObjectName: oi.Name,
+					VersionID:  oi.VersionID,
+				})
+			}
+		}
+
+		if len(markers) > 0 {
+			errs := make([]error, len(markers))
+			if !opts.DryRun {
+				_, errs = objAPI.DeleteObjects(ctx, bucket, markers, ObjectOptions{
+					Versioned: true,
+				})
+			}
+			for i, err := range errs {
+				if isErrVersionNotFound(err) || isErrObjectNotFound(err) {
+					// Removed concurrently.
+					continue
+				}
+				if err != nil {
+					result.Errors = append(result.Errors, UndeleteError{
+						Object:    markers[i].ObjectName,
+						VersionID: markers[i].VersionID,
+						Error:     err.Error(),
+					})
+					continue
+				}
+				result.Undeleted++
+				if len(result.Objects) < maxUndeleteResultObjects {
+					result.Objects = append(result.Objects, markers[i].ObjectName)
+				} else {
+					result.Truncated = true
+				}
+			}
+		}
+
+		if !loi.IsTruncated {
+			return result, nil
+		}
+		marker, versionMarker = loi.NextMarker, loi.NextVersionIDMarker
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseUndeleteOpts(t *testing.T) {
+	opts, err := parseUndeleteOpts(url.Values{
+		"prefix": []string{"dir/"},
+		"after":  []string{"2021-10-01T00:00:00Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Prefix != "dir/" || !opts.Before.IsZero() {
+		t.Fatalf("unexpected options %#v", opts)
+	}
+
+	for _, form := range []url.Values{
+		{},
+		{"after": []string{"yesterday"}},
+		{"after": []string{"2021-10-02T00:00:00Z"}, "before": []string{"2021-10-01T00:00:00Z"}},
+	} {
+		if _, err := parseUndeleteOpts(form); err == nil {
+			t.Errorf("expected %v to be rejected", form)
+		}
+	}
+}
+
+func TestUndeleteObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{VersioningEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	versioned := ObjectOptions{Versioned: true}
+	data := []byte("hello")
+	for _, object := range []string{"dir/a", "dir/b", "other/c"} {
+		if _, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), versioned); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := UTCNow().Add(-time.Second)
+	for _, object := range []string{"dir/a", "dir/b", "other/c"} {
+		if _, err = objLayer.DeleteObject(ctx, bucket, object, versioned); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := UndeleteOpts{Prefix: "dir/", After: after, DryRun: true}
+	result, err := undeleteObjects(ctx, objLayer, bucket, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Undeleted != 2 {
+		t.Fatalf("expected 2 delete markers to match, got %#v", result)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "dir/a", ObjectOptions{}); err == nil {
+		t.Fatal("expected dry run to leave the delete markers")
+	}
+
+	opts.DryRun = false
+	if result, err = undeleteObjects(ctx, objLayer, bucket, opts); err != nil {
+		t.Fatal(err)
+	}
+	if result.Undeleted != 2 || len(result.Errors) != 0 {
+		t.Fatalf("expected 2 objects to be undeleted, got %#v", result)
+	}
+	for _, object := range []string{"dir/a", "dir/b"} {
+		if _, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil {
+			t.Fatalf("expected %s to be undeleted, got %v", object, err)
+		}
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "other/c", ObjectOptions{}); err == nil {
+		t.Fatal("expected other/c outside the prefix to stay deleted")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config/bucketwebhook"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+// BucketWebhookDenied - a pre hook of the bucket webhook denied the
+// bucket operation.
+type BucketWebhookDenied struct {
+	Reason string
+}
+
+func (e BucketWebhookDenied) Error() string {
+	return e.Reason
+}
+
+// bucketWebhookEvent is posted to the bucket webhook.
+type bucketWebhookEvent struct {
+	Hook      string    `json:"hook"`
+	Bucket    string    `json:"bucket"`
+	Time      time.Time `json:"time"`
+	AccessKey string    `json:"accessKey,omitempty"`
+	SourceIP  string    `json:"sourceIP,omitempty"`
+	// Set for bucket creations.
+	Region     string `json:"region,omitempty"`
+	ObjectLock bool   `json:"objectLock,omitempty"`
+	Template   string `json:"template,omitempty"`
+	// Set for bucket deletions.
+	Force bool `json:"force,omitempty"`
+}
+
+// bucketWebhookResult is the optional response of the bucket webhook
+// denying an operation.
+type bucketWebhookResult struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// bucketWebhook posts bucket creations and deletions to a webhook,
+// before they happen to let it deny them and after they succeeded to
+// let it provision the bucket elsewhere.
+type bucketWebhook struct {
+	mu     sync.RWMutex
+	cfg    bucketwebhook.Config
+	client *http.Client
+}
+
+var globalBucketWebhook = &bucketWebhook{}
+
+// Update applies a new bucket webhook configuration.
+func (h *bucketWebhook) Update(cfg bucketwebhook.Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cfg = cfg
+	h.client = nil
+	if cfg.Enabled {
+		h.client = &http.Client{Transport: NewRemoteTargetHTTPTransport()}
+	}
+}
+
+func (h *bucketWebhook) config() (bucketwebhook.Config, *http.Client) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cfg, h.client
+}
+
+func newBucketWebhookEvent(ctx context.Context, bucket string) bucketWebhookEvent {
+	reqInfo := logger.GetReqInfo(ctx)
+	return bucketWebhookEvent{
+		Bucket:    bucket,
+		AccessKey: reqInfo.AccessKey,
+		SourceIP:  reqInfo.RemoteHost,
+	}
+}
+
+// before invokes the pre hook with ev, when configured.
+// BucketWebhookDenied is returned when the webhook denies the operation,
+// or fails in the closed fail mode.
+func (h *bucketWebhook) before(ctx context.Context, hook string, ev bucketWebhookEvent) error {
+	cfg, client := h.config()
+	if !cfg.Invokes(hook) {
+		return nil
+	}
+	ev.Hook, ev.Time = hook, UTCNow()
+	denied, reason, err := h.call(ctx, cfg, client, ev)
+	if err != nil {
+		logger.LogOnceIf(ctx, fmt.Errorf("bucket webhook: %s of %s failed: %w", ev.Hook, ev.Bucket, err), "bucket-webhook-"+ev.Hook)
+		if cfg.FailMode == bucketwebhook.FailClosed {
+			return BucketWebhookDenied{Reason: "The bucket webhook is unavailable"}
+		}
+		return nil
+	}
+	if denied {
+		if reason == "" {
+			reason = "Denied by the bucket webhook"
+		}
+		return BucketWebhookDenied{Reason: reason}
+	}
+	return nil
+}
+
+// after invokes the post hook with ev in the background, when
+// configured. Failures are only logged.
+func (h *bucketWebhook) after(ctx context.Context, hook string, ev bucketWebhookEvent) {
+	cfg, client := h.config()
+	if !cfg.Invokes(hook) {
+		return
+	}
+	ev.Hook, ev.Time = hook, UTCNow()
+	go func() {
+		if _, _, err := h.call(GlobalContext, cfg, client, ev); err != nil {
+			logger.LogOnceIf(ctx, fmt.Errorf("bucket webhook: %s of %s failed: %w", ev.Hook, ev.Bucket, err), "bucket-webhook-"+ev.Hook)
+		}
+	}()
+}
+
+// call posts ev to the webhook, which denies a pre hook operation with
+// 403 Forbidden and allows it with any 2xx status.
+func (h *bucketWebhook) call(ctx context.Context, cfg bucketwebhook.Config, client *http.Client, ev bucketWebhookEvent) (denied bool, reason string, err error) {
+	if client == nil {
+		return false, "", errServerNotInitialized
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return false, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint.String(), bytes.NewReader(data))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set(xhttp.ContentType, "application/json")
+	if cfg.AuthToken != "" {
+		req.Header.Set(xhttp.Authorization, "Bearer "+cfg.AuthToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer xhttp.DrainBody(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		var result bucketWebhookResult
+		// The reason is optional.
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result)
+		return true, result.Reason, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return false, "", fmt.Errorf("%s returned '%s'", cfg.Endpoint, resp.Status)
+	}
+	return false, "", nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config/bucketwebhook"
+	xnet "github.com/minio/pkg/net"
+)
+
+func newTestBucketWebhook(t *testing.T, srv *httptest.Server, timeout time.Duration, failMode string) *bucketWebhook {
+	endpoint, err := xnet.ParseHTTPURL(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &bucketWebhook{
+		cfg: bucketwebhook.Config{
+			Enabled:   true,
+			Endpoint:  endpoint,
+			AuthToken: "token",
+			Hooks: map[string]bool{
+				bucketwebhook.PreMakeBucket:  true,
+				bucketwebhook.PostMakeBucket: true,
+			},
+			Timeout:  timeout,
+			FailMode: failMode,
+		},
+		client: srv.Client(),
+	}
+}
+
+func TestBucketWebhookBefore(t *testing.T) {
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+	testCases := []struct {
+		handler  http.HandlerFunc
+		failMode string
+		hook     string
+		err      error
+	}{
+		{func(w http.ResponseWriter, r *http.Request) {}, bucketwebhook.FailClosed, bucketwebhook.PreMakeBucket, nil},
+		{func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason":"Bucket names must start with the team name"}`))
+		}, bucketwebhook.FailOpen, bucketwebhook.PreMakeBucket, BucketWebhookDenied{Reason: "Bucket names must start with the team name"}},
+		{func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}, bucketwebhook.FailOpen, bucketwebhook.PreMakeBucket, BucketWebhookDenied{Reason: "Denied by the bucket webhook"}},
+		{func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, bucketwebhook.FailOpen, bucketwebhook.PreMakeBucket, nil},
+		{func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, bucketwebhook.FailClosed, bucketwebhook.PreMakeBucket, BucketWebhookDenied{Reason: "The bucket webhook is unavailable"}},
+		{slow, bucketwebhook.FailOpen, bucketwebhook.PreMakeBucket, nil},
+		{slow, bucketwebhook.FailClosed, bucketwebhook.PreMakeBucket, BucketWebhookDenied{Reason: "The bucket webhook is unavailable"}},
+		// Hooks not configured are not invoked.
+		{func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}, bucketwebhook.FailClosed, bucketwebhook.PreDeleteBucket, nil},
+	}
+	for i, tc := range testCases {
+		var ev bucketWebhookEvent
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				t.Errorf("Test %d: unexpected authorization %q", i+1, r.Header.Get("Authorization"))
+			}
+			if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+				t.Errorf("Test %d: %v", i+1, err)
+			}
+			tc.handler(w, r)
+		}))
+		h := newTestBucketWebhook(t, srv, 100*time.Millisecond, tc.failMode)
+
+		start := time.Now()
+		err := h.before(context.Background(), tc.hook, bucketWebhookEvent{Bucket: "bucket"})
+		elapsed := time.Since(start)
+		srv.Close()
+		if err != tc.err {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, tc.err, err)
+		}
+		if elapsed > 2*time.Second {
+			t.Fatalf("Test %d: the webhook call took %s", i+1, elapsed)
+		}
+		if h.cfg.Invokes(tc.hook) && (ev.Hook != tc.hook || ev.Bucket != "bucket") {
+			t.Fatalf("Test %d: unexpected event %#v", i+1, ev)
+		}
+	}
+}
+
+func TestBucketWebhookAfter(t *testing.T) {
+	events := make(chan bucketWebhookEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev bucketWebhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Error(err)
+		}
+		events <- ev
+	}))
+	defer srv.Close()
+	h := newTestBucketWebhook(t, srv, time.Second, bucketwebhook.FailClosed)
+
+	h.after(context.Background(), bucketwebhook.PostMakeBucket, bucketWebhookEvent{Bucket: "bucket", Region: "us-east-1"})
+	select {
+	case ev := <-events:
+		if ev.Hook != bucketwebhook.PostMakeBucket || ev.Bucket != "bucket" || ev.Region != "us-east-1" || ev.Time.IsZero() {
+			t.Fatalf("unexpected event %#v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the post hook was not invoked")
+	}
+
+	// Hooks not configured are not invoked.
+	h.after(context.Background(), bucketwebhook.PostDeleteBucket, bucketWebhookEvent{Bucket: "bucket"})
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event %#v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
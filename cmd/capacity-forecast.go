@@ -0,0 +1,308 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/minio/madmin-go"
+)
+
+const (
+	capacityHistoryObjName = ".capacity-history.json"
+
+	capacitySampleInterval = 6 * time.Hour
+	capacitySamplesMax     = 240 // 60 days
+	// The seasonal model repeats the growth of the last week.
+	capacitySeason = 7 * 24 * time.Hour
+)
+
+// capacitySample - the raw capacity of every pool and the size of every
+// bucket at a point in time.
+type capacitySample struct {
+	Time    time.Time            `json:"time"`
+	Pools   []poolCapacitySample `json:"pools"`
+	Buckets map[string]uint64    `json:"buckets"`
+}
+
+type poolCapacitySample struct {
+	Total uint64 `json:"total"`
+	Free  uint64 `json:"free"`
+}
+
+// PoolCapacityForecast - the projected raw capacity of a pool. The days
+// until full are -1 if the pool is not filling up or too few samples
+// are known, DaysUntilFull being the nearest of both models.
+type PoolCapacityForecast struct {
+	Pool                  int     `json:"pool"`
+	Total                 uint64  `json:"total"`
+	Free                  uint64  `json:"free"`
+	GrowthPerDay          float64 `json:"growthPerDay"`
+	LinearDaysUntilFull   float64 `json:"linearDaysUntilFull"`
+	SeasonalDaysUntilFull float64 `json:"seasonalDaysUntilFull"`
+	DaysUntilFull         float64 `json:"daysUntilFull"`
+}
+
+// BucketCapacityForecast - the projected size of a bucket, the days
+// until its hard quota is reached if it has one, -1 otherwise.
+type BucketCapacityForecast struct {
+	Size          uint64  `json:"size"`
+	Quota         uint64  `json:"quota,omitempty"`
+	GrowthPerDay  float64 `json:"growthPerDay"`
+	DaysUntilFull float64 `json:"daysUntilFull"`
+}
+
+// CapacityForecast - the forecast from the samples taken from First to
+// Last.
+type CapacityForecast struct {
+	First   time.Time                         `json:"first"`
+	Last    time.Time                         `json:"last"`
+	Samples int                               `json:"samples"`
+	Pools   []PoolCapacityForecast            `json:"pools"`
+	Buckets map[string]BucketCapacityForecast `json:"buckets"`
+}
+
+// samplePoolCapacity returns the raw capacity of every pool.
+func samplePoolCapacity(ctx context.Context, objAPI ObjectLayer) []poolCapacitySample {
+	var infos []StorageInfo
+	if z, ok := objAPI.(*erasureServerPools); ok {
+		for _, pool := range z.currentPools() {
+			info, _ := pool.StorageInfo(ctx)
+			infos = append(infos, info)
+		}
+	} else {
+		info, _ := objAPI.StorageInfo(ctx)
+		infos = append(infos, info)
+	}
+	pools := make([]poolCapacitySample, 0, len(infos))
+	for _, info := range infos {
+		pools = append(pools, poolCapacitySample{
+			Total: GetTotalCapacity(info.Disks),
+			Free:  GetTotalCapacityFree(info.Disks),
+		})
+	}
+	return pools
+}
+
+func loadCapacityHistory(ctx context.Context, objAPI ObjectLayer) ([]capacitySample, error) {
+	data, err := readConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, capacityHistoryObjName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var samples []capacitySample
+	if err = json.Unmarshal(data, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// storeCapacitySample adds a sample of the capacity and of the bucket
+// usage saved by the scanner to the history, unless the last sample is
+// recent.
+func storeCapacitySample(ctx context.Context, objAPI ObjectLayer) error {
+	samples, err := loadCapacityHistory(ctx, objAPI)
+	if err != nil {
+		return err
+	}
+	now := UTCNow()
+	if n := len(samples); n > 0 && now.Sub(samples[n-1].Time) < capacitySampleInterval {
+		return nil
+	}
+	dui, err := loadDataUsageFromBackend(ctx, objAPI)
+	if err != nil || dui.LastUpdate.IsZero() {
+		return err
+	}
+	s := capacitySample{
+		Time:    now,
+		Pools:   samplePoolCapacity(ctx, objAPI),
+		Buckets: make(map[string]uint64, len(dui.BucketsUsage)),
+	}
+	for bucket, bui := range dui.BucketsUsage {
+		s.Buckets[bucket] = bui.Size
+	}
+	samples = append(samples, s)
+	if len(samples) > capacitySamplesMax {
+		samples = samples[len(samples)-capacitySamplesMax:]
+	}
+	data, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, capacityHistoryObjName), data)
+}
+
+// capacityPoint is a used size in bytes at a time in days.
+type capacityPoint struct {
+	days float64
+	used float64
+}
+
+// linearGrowth returns the growth per day of the least squares fit of
+// points, false with less than two points.
+func linearGrowth(points []capacityPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.days
+		sumY += p.used
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+	var sxy, sxx float64
+	for _, p := range points {
+		sxy += (p.days - meanX) * (p.used - meanY)
+		sxx += (p.days - meanX) * (p.days - meanX)
+	}
+	if sxx == 0 {
+		return 0, false
+	}
+	return sxy / sxx, true
+}
+
+// seasonalGrowth returns the growth per day during the last season,
+// false if the points do not span a season.
+func seasonalGrowth(points []capacityPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	last := points[len(points)-1]
+	season := capacitySeason.Hours() / 24
+	for i := len(points) - 2; i >= 0; i-- {
+		if elapsed := last.days - points[i].days; elapsed >= season {
+			return (last.used - points[i].used) / elapsed, true
+		}
+	}
+	return 0, false
+}
+
+// daysUntil returns the days until free bytes are used at growth bytes
+// per day, -1 if never.
+func daysUntil(free, growth float64, ok bool) float64 {
+	if !ok || growth <= 0 {
+		return -1
+	}
+	return math.Max(free, 0) / growth
+}
+
+func nearestDays(days ...float64) float64 {
+	nearest := -1.0
+	for _, d := range days {
+		if d >= 0 && (nearest < 0 || d < nearest) {
+			nearest = d
+		}
+	}
+	return nearest
+}
+
+// forecastCapacity projects the capacity of the pools and the buckets
+// of the samples, quotas returning the hard quota of a bucket.
+func forecastCapacity(samples []capacitySample, quotas func(bucket string) uint64) CapacityForecast {
+	f := CapacityForecast{
+		Samples: len(samples),
+		Pools:   []PoolCapacityForecast{},
+		Buckets: make(map[string]BucketCapacityForecast),
+	}
+	if len(samples) == 0 {
+		return f
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+	first, last := samples[0], samples[len(samples)-1]
+	f.First, f.Last = first.Time, last.Time
+	days := func(t time.Time) float64 {
+		return t.Sub(first.Time).Hours() / 24
+	}
+
+	for pool, latest := range last.Pools {
+		var points []capacityPoint
+		for _, s := range samples {
+			// Samples of other pool layouts or of an expanded pool
+			// do not describe the current pool.
+			if len(s.Pools) != len(last.Pools) || s.Pools[pool].Total != latest.Total {
+				points = points[:0]
+				continue
+			}
+			points = append(points, capacityPoint{days(s.Time), float64(s.Pools[pool].Total - s.Pools[pool].Free)})
+		}
+		free := float64(latest.Free)
+		linear, lok := linearGrowth(points)
+		seasonal, sok := seasonalGrowth(points)
+		pf := PoolCapacityForecast{
+			Pool:                  pool,
+			Total:                 latest.Total,
+			Free:                  latest.Free,
+			GrowthPerDay:          linear,
+			LinearDaysUntilFull:   daysUntil(free, linear, lok),
+			SeasonalDaysUntilFull: daysUntil(free, seasonal, sok),
+		}
+		pf.DaysUntilFull = nearestDays(pf.LinearDaysUntilFull, pf.SeasonalDaysUntilFull)
+		f.Pools = append(f.Pools, pf)
+	}
+
+	for bucket, size := range last.Buckets {
+		var points []capacityPoint
+		for _, s := range samples {
+			if used, ok := s.Buckets[bucket]; ok {
+				points = append(points, capacityPoint{days(s.Time), float64(used)})
+			}
+		}
+		linear, lok := linearGrowth(points)
+		seasonal, sok := seasonalGrowth(points)
+		bf := BucketCapacityForecast{
+			Size:          size,
+			Quota:         quotas(bucket),
+			GrowthPerDay:  linear,
+			DaysUntilFull: -1,
+		}
+		if bf.Quota > 0 {
+			free := float64(bf.Quota) - float64(size)
+			bf.DaysUntilFull = nearestDays(daysUntil(free, linear, lok), daysUntil(free, seasonal, sok))
+		}
+		f.Buckets[bucket] = bf
+	}
+	return f
+}
+
+// bucketHardQuota returns the hard quota of bucket, 0 if none.
+func bucketHardQuota(bucket string) uint64 {
+	q, err := globalBucketQuotaSys.Get(bucket)
+	if err != nil || q == nil || q.Type != madmin.HardQuota {
+		return 0
+	}
+	return q.Quota
+}
+
+// loadCapacityForecast forecasts the capacity from the stored history.
+func loadCapacityForecast(ctx context.Context, objAPI ObjectLayer) (CapacityForecast, error) {
+	samples, err := loadCapacityHistory(ctx, objAPI)
+	if err != nil {
+		return CapacityForecast{}, err
+	}
+	return forecastCapacity(samples, bucketHardQuota), nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestForecastCapacity(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	var samples []capacitySample
+	for day := 0; day <= 10; day++ {
+		samples = append(samples, capacitySample{
+			Time: t0.Add(time.Duration(day) * 24 * time.Hour),
+			// Pool 0 uses 10 bytes a day of 1000, pool 1 is steady.
+			Pools: []poolCapacitySample{
+				{Total: 1000, Free: uint64(1000 - 100 - 10*day)},
+				{Total: 500, Free: 400},
+			},
+			Buckets: map[string]uint64{
+				"grows":  uint64(100 + 10*day),
+				"steady": 50,
+			},
+		})
+	}
+	quotas := func(bucket string) uint64 {
+		if bucket == "grows" {
+			return 300
+		}
+		return 0
+	}
+
+	f := forecastCapacity(samples, quotas)
+	if f.Samples != 11 || len(f.Pools) != 2 {
+		t.Fatalf("unexpected forecast %+v", f)
+	}
+	p := f.Pools[0]
+	if math.Abs(p.GrowthPerDay-10) > 1e-6 || math.Abs(p.LinearDaysUntilFull-80) > 1e-6 || math.Abs(p.SeasonalDaysUntilFull-80) > 1e-6 {
+		t.Fatalf("unexpected pool forecast %+v", p)
+	}
+	if math.Abs(p.DaysUntilFull-80) > 1e-6 {
+		t.Fatalf("expected 80 days, got %v", p.DaysUntilFull)
+	}
+	if f.Pools[1].DaysUntilFull != -1 {
+		t.Fatalf("expected a steady pool, got %+v", f.Pools[1])
+	}
+	if b := f.Buckets["grows"]; math.Abs(b.DaysUntilFull-10) > 1e-6 || b.Quota != 300 {
+		t.Fatalf("unexpected bucket forecast %+v", b)
+	}
+	if b := f.Buckets["steady"]; b.DaysUntilFull != -1 || b.GrowthPerDay != 0 {
+		t.Fatalf("unexpected bucket forecast %+v", b)
+	}
+
+	// An expanded pool is forecasted from the samples after expanding.
+	expanded := samples[len(samples)-1]
+	expanded.Time = expanded.Time.Add(24 * time.Hour)
+	expanded.Pools = []poolCapacitySample{{Total: 2000, Free: 1000}, {Total: 500, Free: 400}}
+	f = forecastCapacity(append(samples, expanded), quotas)
+	if f.Pools[0].DaysUntilFull != -1 {
+		t.Fatalf("expected too few samples after expanding, got %+v", f.Pools[0])
+	}
+
+	if f = forecastCapacity(nil, quotas); f.Samples != 0 || len(f.Pools) != 0 {
+		t.Fatalf("unexpected empty forecast %+v", f)
+	}
+}
+
+func TestSeasonalGrowth(t *testing.T) {
+	points := []capacityPoint{{0, 0}, {7, 70}, {8, 200}}
+	g, ok := seasonalGrowth(points)
+	if !ok || math.Abs(g-25) > 1e-6 {
+		t.Fatalf("expected 25 a day over the last 8 days, got %v %v", g, ok)
+	}
+	if _, ok = seasonalGrowth(points[1:]); ok {
+		t.Fatal("expected less than a season")
+	}
+}
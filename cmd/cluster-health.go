@@ -0,0 +1,237 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+// Cluster health states, from best to worst.
+const (
+	ClusterHealthOK       = "ok"
+	ClusterHealthDegraded = "degraded"
+	ClusterHealthCritical = "critical"
+)
+
+// Machine readable codes of the reasons degrading the cluster health.
+const (
+	healthReasonReadQuorumLost     = "ReadQuorumLost"
+	healthReasonWriteQuorumLost    = "WriteQuorumLost"
+	healthReasonNoQuorumMargin     = "NoQuorumMargin"
+	healthReasonDrivesOffline      = "DrivesOffline"
+	healthReasonHealBacklog        = "HealBacklog"
+	healthReasonHealStatusUnknown  = "HealStatusUnknown"
+	healthReasonKMSUnreachable     = "KMSUnreachable"
+	healthReasonReplicationFailed  = "ReplicationFailed"
+	healthReasonReplicationBacklog = "ReplicationBacklog"
+)
+
+// Number of pending replication operations of a bucket
+// above which replication is considered lagging.
+const replicationBacklogThreshold = 10000
+
+// ClusterHealthReason explains why the cluster is not healthy.
+type ClusterHealthReason struct {
+	Code    string `json:"code"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+	Pool    *int   `json:"pool,omitempty"`
+	Set     *int   `json:"set,omitempty"`
+	Bucket  string `json:"bucket,omitempty"`
+}
+
+// ClusterSetHealth is the drive availability of an erasure set.
+type ClusterSetHealth struct {
+	Pool         int `json:"pool"`
+	Set          int `json:"set"`
+	Drives       int `json:"drives"`
+	OnlineDrives int `json:"onlineDrives"`
+	ReadQuorum   int `json:"readQuorum"`
+	WriteQuorum  int `json:"writeQuorum"`
+	// Number of drives that can go offline before write quorum is lost.
+	QuorumMargin int `json:"quorumMargin"`
+}
+
+// ClusterHealth is the computed health of the cluster.
+type ClusterHealth struct {
+	Time    time.Time             `json:"time"`
+	State   string                `json:"state"`
+	Reasons []ClusterHealthReason `json:"reasons,omitempty"`
+	Sets    []ClusterSetHealth    `json:"sets,omitempty"`
+}
+
+func (h *ClusterHealth) addReason(r ClusterHealthReason) {
+	h.Reasons = append(h.Reasons, r)
+	if r.State == ClusterHealthCritical || h.State == ClusterHealthOK {
+		h.State = r.State
+	}
+}
+
+// sortReasons orders the reasons from the most to the least severe,
+// keeping the order they were found in otherwise.
+func (h *ClusterHealth) sortReasons() {
+	reasons := make([]ClusterHealthReason, 0, len(h.Reasons))
+	for _, state := range []string{ClusterHealthCritical, ClusterHealthDegraded} {
+		for _, r := range h.Reasons {
+			if r.State == state {
+				reasons = append(reasons, r)
+			}
+		}
+	}
+	h.Reasons = reasons
+}
+
+// setReason returns a reason attached to an erasure set.
+func setReason(code, state string, s ClusterSetHealth, format string, args ...interface{}) ClusterHealthReason {
+	pool, set := s.Pool, s.Set
+	return ClusterHealthReason{
+		Code:    code,
+		State:   state,
+		Message: fmt.Sprintf(format, args...),
+		Pool:    &pool,
+		Set:     &set,
+	}
+}
+
+// checkSetsHealth computes the quorum margins of all erasure sets.
+func checkSetsHealth(z *erasureServerPools, h *ClusterHealth) {
+	b := z.BackendInfo()
+	for poolIdx, pool := range z.currentPools() {
+		readQuorum := b.StandardSCData[poolIdx]
+		writeQuorum := readQuorum
+		if writeQuorum == b.StandardSCParity {
+			writeQuorum++
+		}
+		for setIdx, set := range pool.sets {
+			s := ClusterSetHealth{
+				Pool:        poolIdx,
+				Set:         setIdx,
+				ReadQuorum:  readQuorum,
+				WriteQuorum: writeQuorum,
+			}
+			for _, disk := range set.getDisks() {
+				s.Drives++
+				if disk != nil && disk.IsOnline() {
+					s.OnlineDrives++
+				}
+			}
+			s.QuorumMargin = s.OnlineDrives - writeQuorum
+			h.Sets = append(h.Sets, s)
+
+			offline := s.Drives - s.OnlineDrives
+			switch {
+			case s.OnlineDrives < readQuorum:
+				h.addReason(setReason(healthReasonReadQuorumLost, ClusterHealthCritical, s,
+					"%d of %d drives offline, read quorum of %d lost", offline, s.Drives, readQuorum))
+			case s.QuorumMargin < 0:
+				h.addReason(setReason(healthReasonWriteQuorumLost, ClusterHealthCritical, s,
+					"%d of %d drives offline, write quorum of %d lost", offline, s.Drives, writeQuorum))
+			case s.QuorumMargin == 0 && offline > 0:
+				h.addReason(setReason(healthReasonNoQuorumMargin, ClusterHealthDegraded, s,
+					"%d of %d drives offline, write quorum lost on next drive failure", offline, s.Drives))
+			case offline > 0:
+				h.addReason(setReason(healthReasonDrivesOffline, ClusterHealthDegraded, s,
+					"%d of %d drives offline", offline, s.Drives))
+			}
+		}
+	}
+}
+
+// checkReplicationHealth reports buckets with failed or lagging replication.
+func checkReplicationHealth(ctx context.Context, objAPI ObjectLayer, h *ClusterHealth) {
+	dataUsageInfo, err := loadDataUsageFromBackend(ctx, objAPI)
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	for bucket, usage := range dataUsageInfo.BucketsUsage {
+		if _, err := globalBucketMetadataSys.GetReplicationConfig(ctx, bucket); err != nil {
+			continue
+		}
+		stats := getLatestReplicationStats(bucket, usage)
+		if stats.FailedCount > 0 {
+			h.addReason(ClusterHealthReason{
+				Code:    healthReasonReplicationFailed,
+				State:   ClusterHealthDegraded,
+				Message: fmt.Sprintf("%d replication operations failed", stats.FailedCount),
+				Bucket:  bucket,
+			})
+		}
+		if stats.PendingCount > replicationBacklogThreshold {
+			h.addReason(ClusterHealthReason{
+				Code:    healthReasonReplicationBacklog,
+				State:   ClusterHealthDegraded,
+				Message: fmt.Sprintf("%d replication operations pending", stats.PendingCount),
+				Bucket:  bucket,
+			})
+		}
+	}
+}
+
+// getClusterHealth computes the health state of the cluster along with
+// the reasons it is not ok, from the most to the least severe.
+func getClusterHealth(ctx context.Context, objAPI ObjectLayer) ClusterHealth {
+	h := ClusterHealth{
+		Time:  UTCNow(),
+		State: ClusterHealthOK,
+	}
+
+	if z, ok := objAPI.(*erasureServerPools); ok {
+		checkSetsHealth(z, &h)
+
+		healState, err := getAggregatedBackgroundHealState(ctx, objAPI)
+		switch {
+		case err != nil:
+			h.addReason(ClusterHealthReason{
+				Code:    healthReasonHealStatusUnknown,
+				State:   ClusterHealthDegraded,
+				Message: fmt.Sprintf("unable to get heal status: %v", err),
+			})
+		case len(healState.HealDisks) > 0:
+			h.addReason(ClusterHealthReason{
+				Code:    healthReasonHealBacklog,
+				State:   ClusterHealthDegraded,
+				Message: fmt.Sprintf("%d drives are being healed", len(healState.HealDisks)),
+			})
+		}
+
+		checkReplicationHealth(ctx, objAPI, &h)
+	}
+
+	if GlobalKMS != nil {
+		if _, err := GlobalKMS.Stat(); err != nil {
+			// Without the KMS auto encrypted buckets can not serve any request.
+			state := ClusterHealthDegraded
+			if globalAutoEncryption {
+				state = ClusterHealthCritical
+			}
+			h.addReason(ClusterHealthReason{
+				Code:    healthReasonKMSUnreachable,
+				State:   state,
+				Message: fmt.Sprintf("KMS is not reachable: %v", err),
+			})
+		}
+	}
+
+	h.sortReasons()
+	return h
+}
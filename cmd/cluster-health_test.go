@@ -0,0 +1,132 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCheckSetsHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	z := objLayer.(*erasureServerPools)
+	// With as many data as parity drives the write quorum is one more
+	// than the read quorum.
+	z.serverPools[0].defaultParityCount = 8
+	if b := z.BackendInfo(); b.StandardSCData[0] != 8 || b.StandardSCParity != 8 {
+		t.Fatalf("unexpected backend %+v", b)
+	}
+	set := z.serverPools[0].sets[0]
+	disks := set.getDisks()
+
+	testCases := []struct {
+		offline int
+		state   string
+		code    string
+		margin  int
+	}{
+		{offline: 0, state: ClusterHealthOK, margin: 7},
+		{offline: 3, state: ClusterHealthDegraded, code: healthReasonDrivesOffline, margin: 4},
+		{offline: 7, state: ClusterHealthDegraded, code: healthReasonNoQuorumMargin, margin: 0},
+		{offline: 8, state: ClusterHealthCritical, code: healthReasonWriteQuorumLost, margin: -1},
+		{offline: 9, state: ClusterHealthCritical, code: healthReasonReadQuorumLost, margin: -2},
+	}
+	for i, testCase := range testCases {
+		stubbed := make([]StorageAPI, len(disks))
+		copy(stubbed, disks[testCase.offline:])
+		set.getDisks = func() []StorageAPI { return stubbed }
+
+		h := ClusterHealth{State: ClusterHealthOK}
+		checkSetsHealth(z, &h)
+		if h.State != testCase.state {
+			t.Errorf("Test %d: expected state %s, got %s", i+1, testCase.state, h.State)
+		}
+		if len(h.Sets) != 1 || h.Sets[0].Drives != 16 || h.Sets[0].OnlineDrives != 16-testCase.offline ||
+			h.Sets[0].ReadQuorum != 8 || h.Sets[0].WriteQuorum != 9 || h.Sets[0].QuorumMargin != testCase.margin {
+			t.Errorf("Test %d: unexpected sets %+v", i+1, h.Sets)
+		}
+		if testCase.code == "" {
+			if len(h.Reasons) != 0 {
+				t.Errorf("Test %d: expected no reasons, got %+v", i+1, h.Reasons)
+			}
+			continue
+		}
+		if len(h.Reasons) != 1 || h.Reasons[0].Code != testCase.code || h.Reasons[0].State != testCase.state ||
+			*h.Reasons[0].Pool != 0 || *h.Reasons[0].Set != 0 {
+			t.Errorf("Test %d: expected a %s reason, got %+v", i+1, testCase.code, h.Reasons)
+		}
+	}
+}
+
+func TestClusterHealthReasons(t *testing.T) {
+	testCases := []struct {
+		reasons []ClusterHealthReason
+		state   string
+		codes   []string
+	}{
+		{state: ClusterHealthOK},
+		{
+			reasons: []ClusterHealthReason{
+				{Code: healthReasonDrivesOffline, State: ClusterHealthDegraded},
+				{Code: healthReasonHealBacklog, State: ClusterHealthDegraded},
+			},
+			state: ClusterHealthDegraded,
+			codes: []string{healthReasonDrivesOffline, healthReasonHealBacklog},
+		},
+		// A later degraded reason does not lower a critical state.
+		{
+			reasons: []ClusterHealthReason{
+				{Code: healthReasonDrivesOffline, State: ClusterHealthDegraded},
+				{Code: healthReasonReadQuorumLost, State: ClusterHealthCritical},
+				{Code: healthReasonReplicationFailed, State: ClusterHealthDegraded},
+				{Code: healthReasonKMSUnreachable, State: ClusterHealthCritical},
+			},
+			state: ClusterHealthCritical,
+			codes: []string{
+				healthReasonReadQuorumLost, healthReasonKMSUnreachable,
+				healthReasonDrivesOffline, healthReasonReplicationFailed,
+			},
+		},
+	}
+	for i, testCase := range testCases {
+		h := ClusterHealth{State: ClusterHealthOK}
+		for _, r := range testCase.reasons {
+			h.addReason(r)
+		}
+		h.sortReasons()
+		if h.State != testCase.state {
+			t.Errorf("Test %d: expected state %s, got %s", i+1, testCase.state, h.State)
+		}
+		var codes []string
+		for _, r := range h.Reasons {
+			codes = append(codes, r.Code)
+		}
+		if !reflect.DeepEqual(codes, testCase.codes) {
+			t.Errorf("Test %d: expected reasons %v, got %v", i+1, testCase.codes, codes)
+		}
+	}
+}
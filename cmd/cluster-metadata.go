@@ -0,0 +1,332 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/minio/madmin-go"
+)
+
+const (
+	clusterMetadataVersion      = 1
+	clusterMetadataManifest     = "manifest.json"
+	clusterMetadataSignature    = "manifest.sig"
+	clusterMetadataFilesPrefix  = "meta/"
+	maxClusterMetadataArchive   = 1 << 30
+	clusterMetadataExportFormat = "minio-cluster-metadata"
+)
+
+var (
+	errClusterMetadataSignature  = errors.New("Cluster metadata archive signature does not match, it was not exported with the current root credentials or was modified")
+	errClusterMetadataInvalid    = errors.New("Cluster metadata archive is invalid")
+	errClusterMetadataDeployment = errors.New("Cluster metadata archive was exported by another deployment")
+	errClusterMetadataStale      = errors.New("Cluster metadata archive is older than the current metadata")
+)
+
+// clusterMetadataExcluded are the prefixes of the config never exported,
+// history and state meaningful to the running cluster only.
+var clusterMetadataExcluded = []string{
+	minioConfigHistoryPrefix + SlashSeparator,
+	uploadTokensPrefix + SlashSeparator,
+	pathJoin(minioConfigPrefix, acmePrefix, acmeChallengeHTTP01) + SlashSeparator,
+}
+
+// ClusterMetadataManifest - the content of a cluster metadata archive,
+// the SHA-256 checksum of each file by its path in .minio.sys.
+type ClusterMetadataManifest struct {
+	Format       string            `json:"format"`
+	Version      int               `json:"version"`
+	DeploymentID string            `json:"deploymentId"`
+	Created      time.Time         `json:"created"`
+	Files        map[string]string `json:"files"`
+}
+
+// ClusterMetadataImportResult - the outcome of an import.
+type ClusterMetadataImportResult struct {
+	DryRun         bool                 `json:"dryRun,omitempty"`
+	Files          int                  `json:"files"`
+	Buckets        []string             `json:"buckets"`
+	CreatedBuckets []string             `json:"createdBuckets,omitempty"`
+	Reload         []ReloadConfigResult `json:"reload,omitempty"`
+}
+
+// isClusterMetadataPath returns whether p of .minio.sys is part of the
+// cluster metadata: the config, IAM, tiers and site replication state
+// included, and the metadata of every bucket.
+func isClusterMetadataPath(p string) bool {
+	if p != path.Clean(p) || strings.HasPrefix(p, "../") {
+		return false
+	}
+	if strings.HasPrefix(p, minioConfigPrefix+SlashSeparator) {
+		for _, excluded := range clusterMetadataExcluded {
+			if strings.HasPrefix(p, excluded) {
+				return false
+			}
+		}
+		return !strings.HasSuffix(p, ".lock")
+	}
+	bucket, file := path2BucketObject(strings.TrimPrefix(p, bucketMetaPrefix+SlashSeparator))
+	return strings.HasPrefix(p, bucketMetaPrefix+SlashSeparator) && bucket != "" && file == bucketMetadataFile
+}
+
+// signClusterMetadata returns the signature of the manifest, keyed by
+// the root credentials.
+func signClusterMetadata(manifest []byte) string {
+	mac := hmac.New(sha256.New, []byte(globalActiveCred.SecretKey))
+	mac.Write(manifest)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// listClusterMetadata returns the paths of the cluster metadata.
+func listClusterMetadata(ctx context.Context, objAPI ObjectLayer) ([]string, error) {
+	var paths []string
+	for _, prefix := range []string{minioConfigPrefix + SlashSeparator, bucketMetaPrefix + SlashSeparator} {
+		marker := ""
+		for {
+			lo, err := objAPI.ListObjects(ctx, minioMetaBucket, prefix, marker, "", maxObjectList)
+			if err != nil {
+				return nil, err
+			}
+			for _, oi := range lo.Objects {
+				if isClusterMetadataPath(oi.Name) {
+					paths = append(paths, oi.Name)
+				}
+			}
+			if !lo.IsTruncated {
+				break
+			}
+			marker = lo.NextMarker
+		}
+	}
+	return paths, nil
+}
+
+// exportClusterMetadata returns a zip archive of the cluster metadata,
+// its files as stored and their manifest signed with the root
+// credentials, encrypted with the root credentials as it holds the
+// secrets of all users.
+func exportClusterMetadata(ctx context.Context, objAPI ObjectLayer) ([]byte, error) {
+	paths, err := listClusterMetadata(ctx, objAPI)
+	if err != nil {
+		return nil, err
+	}
+	manifest := ClusterMetadataManifest{
+		Format:       clusterMetadataExportFormat,
+		Version:      clusterMetadataVersion,
+		DeploymentID: globalDeploymentID,
+		Created:      UTCNow(),
+		Files:        make(map[string]string, len(paths)),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, p := range paths {
+		data, err := readConfig(ctx, objAPI, p)
+		if err != nil {
+			if errors.Is(err, errConfigNotFound) {
+				// Removed meanwhile.
+				continue
+			}
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files[p] = hex.EncodeToString(sum[:])
+		if err = writeClusterMetadataFile(zw, clusterMetadataFilesPrefix+p, data, manifest.Created); err != nil {
+			return nil, err
+		}
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = writeClusterMetadataFile(zw, clusterMetadataManifest, manifestJSON, manifest.Created); err != nil {
+		return nil, err
+	}
+	if err = writeClusterMetadataFile(zw, clusterMetadataSignature, []byte(signClusterMetadata(manifestJSON)), manifest.Created); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return madmin.EncryptData(globalActiveCred.SecretKey, buf.Bytes())
+}
+
+func writeClusterMetadataFile(zw *zip.Writer, name string, data []byte, modTime time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readClusterMetadata decrypts the archive, verifies its signature and
+// checksums and returns its files by their path in .minio.sys.
+func readClusterMetadata(archive []byte) (ClusterMetadataManifest, map[string][]byte, error) {
+	var manifest ClusterMetadataManifest
+	archive, err := madmin.DecryptData(globalActiveCred.SecretKey, bytes.NewReader(archive))
+	if err != nil {
+		return manifest, nil, errClusterMetadataSignature
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return manifest, nil, errClusterMetadataInvalid
+	}
+	var manifestJSON, signature []byte
+	files := make(map[string][]byte)
+	// Bound the decompressed size, not only the archive size.
+	remaining := int64(maxClusterMetadataArchive)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return manifest, nil, errClusterMetadataInvalid
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(rc, remaining+1))
+		rc.Close()
+		if err != nil || int64(len(data)) > remaining {
+			return manifest, nil, errClusterMetadataInvalid
+		}
+		remaining -= int64(len(data))
+		switch {
+		case f.Name == clusterMetadataManifest:
+			manifestJSON = data
+		case f.Name == clusterMetadataSignature:
+			signature = data
+		case strings.HasPrefix(f.Name, clusterMetadataFilesPrefix):
+			files[strings.TrimPrefix(f.Name, clusterMetadataFilesPrefix)] = data
+		default:
+			return manifest, nil, errClusterMetadataInvalid
+		}
+	}
+	if manifestJSON == nil || !hmac.Equal(signature, []byte(signClusterMetadata(manifestJSON))) {
+		return manifest, nil, errClusterMetadataSignature
+	}
+	if err = json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return manifest, nil, errClusterMetadataInvalid
+	}
+	if manifest.Format != clusterMetadataExportFormat || manifest.Version != clusterMetadataVersion || len(manifest.Files) != len(files) {
+		return manifest, nil, errClusterMetadataInvalid
+	}
+	for p, data := range files {
+		sum := sha256.Sum256(data)
+		if !isClusterMetadataPath(p) || manifest.Files[p] != hex.EncodeToString(sum[:]) {
+			return manifest, nil, fmt.Errorf("%w: %s does not match its checksum", errClusterMetadataInvalid, p)
+		}
+	}
+	return manifest, files, nil
+}
+
+// checkClusterMetadataImport rejects archives exported by another
+// deployment, and archives older than the current metadata they replace,
+// which would roll back the IAM and bucket configs changed meanwhile.
+func checkClusterMetadataImport(ctx context.Context, objAPI ObjectLayer, manifest ClusterMetadataManifest, paths []string) error {
+	if manifest.DeploymentID != globalDeploymentID {
+		return errClusterMetadataDeployment
+	}
+	for _, p := range paths {
+		oi, err := objAPI.GetObjectInfo(ctx, minioMetaBucket, p, ObjectOptions{})
+		if err != nil {
+			if isErrObjectNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if oi.ModTime.After(manifest.Created) {
+			return fmt.Errorf("%w: %s was changed at %s", errClusterMetadataStale, p, oi.ModTime.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// importClusterMetadata restores the cluster metadata of archive,
+// creating the buckets missing, then reloads the metadata on all nodes.
+// Existing files are replaced, files not in the archive are kept. Unless
+// forced, the archive must be exported by this deployment after the
+// metadata it replaces was last changed.
+func importClusterMetadata(ctx context.Context, objAPI ObjectLayer, archive []byte, dryRun, force bool) (ClusterMetadataImportResult, error) {
+	result := ClusterMetadataImportResult{DryRun: dryRun, Buckets: []string{}}
+	manifest, files, err := readClusterMetadata(archive)
+	if err != nil {
+		return result, err
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if !force {
+		if err = checkClusterMetadataImport(ctx, objAPI, manifest, paths); err != nil {
+			return result, err
+		}
+	}
+	result.Files = len(paths)
+	for _, p := range paths {
+		if bucket, file := path2BucketObject(strings.TrimPrefix(p, bucketMetaPrefix+SlashSeparator)); file == bucketMetadataFile && strings.HasPrefix(p, bucketMetaPrefix+SlashSeparator) {
+			result.Buckets = append(result.Buckets, bucket)
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	for _, bucket := range result.Buckets {
+		if _, err := objAPI.GetBucketInfo(ctx, bucket); err == nil {
+			continue
+		} else if _, ok := err.(BucketNotFound); !ok {
+			return result, err
+		}
+		if err := objAPI.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+			return result, err
+		}
+		result.CreatedBuckets = append(result.CreatedBuckets, bucket)
+	}
+	for _, p := range paths {
+		if err := saveConfig(ctx, objAPI, p, files[p]); err != nil {
+			return result, err
+		}
+	}
+	for _, bucket := range result.Buckets {
+		meta, err := loadBucketMetadata(ctx, objAPI, bucket)
+		if err != nil {
+			return result, err
+		}
+		globalBucketMetadataSys.Set(bucket, meta)
+		globalNotificationSys.LoadBucketMetadata(GlobalContext, bucket)
+	}
+	result.Reload = reloadClusterConfig(ctx, objAPI)
+	return result, nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/minio/madmin-go"
+)
+
+func TestIsClusterMetadataPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"config/config.json", true},
+		{"config/iam/users/user/identity.json", true},
+		{"config/tier-config.bin", true},
+		{"config/site-replication/state.json", true},
+		{"config/history/config.json.history", false},
+		{"config/upload-tokens/token", false},
+		{"config/iam.lock", false},
+		{"buckets/bucket/.metadata.bin", true},
+		{"buckets/bucket/.usage-cache.bin", false},
+		{"buckets/.usage.json", false},
+		{"buckets/bucket/dir/.metadata.bin", false},
+		{"buckets/../config/config.json", false},
+		{"format.json", false},
+	}
+	for i, tc := range testCases {
+		if got := isClusterMetadataPath(tc.path); got != tc.expected {
+			t.Errorf("Test %d: %s expected %v, got %v", i+1, tc.path, tc.expected, got)
+		}
+	}
+}
+
+func newTestClusterMetadataArchive(t *testing.T, files map[string][]byte, tamper func(m *ClusterMetadataManifest)) []byte {
+	t.Helper()
+	manifest := ClusterMetadataManifest{
+		Format:  clusterMetadataExportFormat,
+		Version: clusterMetadataVersion,
+		Created: UTCNow(),
+		Files:   make(map[string]string),
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for p, data := range files {
+		sum := sha256.Sum256(data)
+		manifest.Files[p] = hex.EncodeToString(sum[:])
+		if err := writeClusterMetadataFile(zw, clusterMetadataFilesPrefix+p, data, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tamper != nil {
+		tamper(&manifest)
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writeClusterMetadataFile(zw, clusterMetadataManifest, manifestJSON, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeClusterMetadataFile(zw, clusterMetadataSignature, []byte(signClusterMetadata(manifestJSON)), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := madmin.EncryptData(globalActiveCred.SecretKey, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func TestReadClusterMetadata(t *testing.T) {
+	files := map[string][]byte{
+		"config/config.json":           []byte("config"),
+		"buckets/bucket/.metadata.bin": []byte("metadata"),
+	}
+	_, got, err := readClusterMetadata(newTestClusterMetadataArchive(t, files, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || string(got["buckets/bucket/.metadata.bin"]) != "metadata" {
+		t.Fatalf("Unexpected files %v", got)
+	}
+
+	// A file not matching its checksum is rejected.
+	archive := newTestClusterMetadataArchive(t, files, func(m *ClusterMetadataManifest) {
+		m.Files["config/config.json"] = hex.EncodeToString(make([]byte, sha256.Size))
+	})
+	if _, _, err = readClusterMetadata(archive); !errors.Is(err, errClusterMetadataInvalid) {
+		t.Fatalf("Expected %v, got %v", errClusterMetadataInvalid, err)
+	}
+
+	// Files outside the cluster metadata are rejected.
+	archive = newTestClusterMetadataArchive(t, map[string][]byte{"format.json": []byte("format")}, nil)
+	if _, _, err = readClusterMetadata(archive); !errors.Is(err, errClusterMetadataInvalid) {
+		t.Fatalf("Expected %v, got %v", errClusterMetadataInvalid, err)
+	}
+
+	// An archive signed with other credentials is rejected.
+	archive = newTestClusterMetadataArchive(t, files, nil)
+	cred := globalActiveCred
+	defer func() { globalActiveCred = cred }()
+	globalActiveCred.SecretKey = "other-secret-key"
+	if _, _, err = readClusterMetadata(archive); err != errClusterMetadataSignature {
+		t.Fatalf("Expected %v, got %v", errClusterMetadataSignature, err)
+	}
+}
+
+func TestCheckClusterMetadataImport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	deploymentID := globalDeploymentID
+	defer func() { globalDeploymentID = deploymentID }()
+	globalDeploymentID = mustGetUUID()
+
+	const p = "config/iam/users/user/identity.json"
+	exported := UTCNow().Add(-time.Hour)
+	if err = saveConfig(ctx, objLayer, p, []byte("identity")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		deploymentID string
+		created      time.Time
+		paths        []string
+		err          error
+	}{
+		{deploymentID: globalDeploymentID, created: UTCNow().Add(time.Minute), paths: []string{p}},
+		// Files missing now are restored.
+		{deploymentID: globalDeploymentID, created: exported, paths: []string{"config/iam/users/other/identity.json"}},
+		// Rolls back the user changed after the export.
+		{deploymentID: globalDeploymentID, created: exported, paths: []string{p}, err: errClusterMetadataStale},
+		{deploymentID: mustGetUUID(), created: UTCNow().Add(time.Minute), paths: []string{p}, err: errClusterMetadataDeployment},
+	}
+	for i, tc := range testCases {
+		manifest := ClusterMetadataManifest{DeploymentID: tc.deploymentID, Created: tc.created}
+		if err := checkClusterMetadataImport(ctx, objLayer, manifest, tc.paths); !errors.Is(err, tc.err) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, tc.err, err)
+		}
+	}
+}
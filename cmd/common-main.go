@@ -154,18 +154,18 @@ func minioConfigToConsoleFeatures() {
 		os.Setenv("CONSOLE_LDAP_ENABLED", config.EnableOn)
 	}
 	// if IDP is enabled, set IDP environment variables
-	if globalOpenIDConfig.URL != nil {
-		os.Setenv("CONSOLE_IDP_URL", globalOpenIDConfig.URL.String())
-		os.Setenv("CONSOLE_IDP_CLIENT_ID", globalOpenIDConfig.ClientID)
-		os.Setenv("CONSOLE_IDP_SECRET", globalOpenIDConfig.ClientSecret)
+	if openIDConfig, _ := getOpenIDConfig(); openIDConfig.URL != nil {
+		os.Setenv("CONSOLE_IDP_URL", openIDConfig.URL.String())
+		os.Setenv("CONSOLE_IDP_CLIENT_ID", openIDConfig.ClientID)
+		os.Setenv("CONSOLE_IDP_SECRET", openIDConfig.ClientSecret)
 		os.Setenv("CONSOLE_IDP_HMAC_SALT", globalDeploymentID)
-		os.Setenv("CONSOLE_IDP_HMAC_PASSPHRASE", globalOpenIDConfig.ClientID)
-		os.Setenv("CONSOLE_IDP_SCOPES", strings.Join(globalOpenIDConfig.DiscoveryDoc.ScopesSupported, ","))
-		if globalOpenIDConfig.ClaimUserinfo {
+		os.Setenv("CONSOLE_IDP_HMAC_PASSPHRASE", openIDConfig.ClientID)
+		os.Setenv("CONSOLE_IDP_SCOPES", strings.Join(openIDConfig.DiscoveryDoc.ScopesSupported, ","))
+		if openIDConfig.ClaimUserinfo {
 			os.Setenv("CONSOLE_IDP_USERINFO", "on")
 		}
-		if globalOpenIDConfig.RedirectURI != "" {
-			os.Setenv("CONSOLE_IDP_CALLBACK", globalOpenIDConfig.RedirectURI)
+		if openIDConfig.RedirectURI != "" {
+			os.Setenv("CONSOLE_IDP_CALLBACK", openIDConfig.RedirectURI)
 		} else {
 			os.Setenv("CONSOLE_IDP_CALLBACK", getConsoleEndpoints()[0]+"/oauth_callback")
 		}
@@ -625,6 +625,19 @@ func logStartupMessage(msg string) {
 }
 
 func getTLSConfig() (x509Certs []*x509.Certificate, manager *certs.Manager, secureConn bool, err error) {
+	ctx, cancel := context.WithCancel(GlobalContext)
+	x509Certs, manager, secureConn, err = loadTLSConfig(ctx)
+	if err != nil || manager == nil {
+		cancel()
+		return x509Certs, manager, secureConn, err
+	}
+	globalTLSCertsCancel = cancel
+	return x509Certs, manager, secureConn, nil
+}
+
+// loadTLSConfig - loads the certificates of the certs directory, their
+// files are watched for changes until ctx is canceled.
+func loadTLSConfig(ctx context.Context) (x509Certs []*x509.Certificate, manager *certs.Manager, secureConn bool, err error) {
 	if !(isFile(getPublicCertFile()) && isFile(getPrivateKeyFile())) {
 		return nil, nil, false, nil
 	}
@@ -633,7 +646,7 @@ func getTLSConfig() (x509Certs []*x509.Certificate, manager *certs.Manager, secu
 		return nil, nil, false, err
 	}
 
-	manager, err = certs.NewManager(GlobalContext, getPublicCertFile(), getPrivateKeyFile(), config.LoadX509KeyPair)
+	manager, err = certs.NewManager(ctx, getPublicCertFile(), getPrivateKeyFile(), config.LoadX509KeyPair)
 	if err != nil {
 		return nil, nil, false, err
 	}
@@ -26,7 +26,9 @@ import (
 
 	"github.com/minio/madmin-go"
 	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/config/anomaly"
 	"github.com/minio/minio/internal/config/api"
+	"github.com/minio/minio/internal/config/bucketwebhook"
 	"github.com/minio/minio/internal/config/cache"
 	"github.com/minio/minio/internal/config/compress"
 	"github.com/minio/minio/internal/config/dns"
@@ -35,17 +37,25 @@ import (
 	xldap "github.com/minio/minio/internal/config/identity/ldap"
 	"github.com/minio/minio/internal/config/identity/openid"
 	xtls "github.com/minio/minio/internal/config/identity/tls"
+	"github.com/minio/minio/internal/config/malware"
+	"github.com/minio/minio/internal/config/metadatasink"
 	"github.com/minio/minio/internal/config/notify"
 	"github.com/minio/minio/internal/config/policy/opa"
+	"github.com/minio/minio/internal/config/rpc"
 	"github.com/minio/minio/internal/config/scanner"
+	"github.com/minio/minio/internal/config/shadow"
+	"github.com/minio/minio/internal/config/slo"
 	"github.com/minio/minio/internal/config/storageclass"
 	"github.com/minio/minio/internal/config/subnet"
+	"github.com/minio/minio/internal/config/tracing/otlp"
 	"github.com/minio/minio/internal/crypto"
 	xhttp "github.com/minio/minio/internal/http"
 	"github.com/minio/minio/internal/kms"
 	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/logger/target/file"
 	"github.com/minio/minio/internal/logger/target/http"
 	"github.com/minio/minio/internal/logger/target/kafka"
+	"github.com/minio/minio/internal/logger/target/syslog"
 	"github.com/minio/pkg/env"
 )
 
@@ -62,11 +72,22 @@ func initHelp() {
 		config.APISubSys:            api.DefaultKVS,
 		config.CredentialsSubSys:    config.DefaultCredentialKVS,
 		config.LoggerWebhookSubSys:  logger.DefaultKVS,
+		config.LoggerSyslogSubSys:   logger.DefaultSyslogKVS,
 		config.AuditWebhookSubSys:   logger.DefaultAuditWebhookKVS,
 		config.AuditKafkaSubSys:     logger.DefaultAuditKafkaKVS,
+		config.AuditFileSubSys:      logger.DefaultAuditFileKVS,
+		config.AuditRedactionSubSys: logger.DefaultAuditRedactionKVS,
 		config.HealSubSys:           heal.DefaultKVS,
 		config.ScannerSubSys:        scanner.DefaultKVS,
 		config.SubnetSubSys:         subnet.DefaultKVS,
+		config.ShadowSubSys:         shadow.DefaultKVS,
+		config.AnomalySubSys:        anomaly.DefaultKVS,
+		config.MalwareScanSubSys:    malware.DefaultKVS,
+		config.MetadataSinkSubSys:   metadatasink.DefaultKVS,
+		config.BucketWebhookSubSys:  bucketwebhook.DefaultKVS,
+		config.TracingOTLPSubSys:    otlp.DefaultKVS,
+		config.RPCSubSys:            rpc.DefaultKVS,
+		config.SLOSubSys:            slo.DefaultKVS,
 	}
 	for k, v := range notify.DefaultNotificationKVS {
 		kvs[k] = v
@@ -127,6 +148,11 @@ func initHelp() {
 			Description:     "send server logs to webhook endpoints",
 			MultipleTargets: true,
 		},
+		config.HelpKV{
+			Key:             config.LoggerSyslogSubSys,
+			Description:     "send server logs to syslog servers",
+			MultipleTargets: true,
+		},
 		config.HelpKV{
 			Key:             config.AuditWebhookSubSys,
 			Description:     "send audit logs to webhook endpoints",
@@ -137,6 +163,16 @@ func initHelp() {
 			Description:     "send audit logs to kafka endpoints",
 			MultipleTargets: true,
 		},
+		config.HelpKV{
+			Key:             config.AuditFileSubSys,
+			Description:     "write audit logs to local files and upload them to an audit bucket",
+			MultipleTargets: true,
+		},
+		config.HelpKV{
+			Key:         config.AuditRedactionSubSys,
+			Description: "redact sensitive query parameters, headers and claims from audit logs",
+			Optional:    true,
+		},
 		config.HelpKV{
 			Key:             config.NotifyWebhookSubSys,
 			Description:     "publish bucket notifications to webhook endpoints",
@@ -193,6 +229,47 @@ func initHelp() {
 			Description: "set subnet config for the cluster e.g. license token",
 			Optional:    true,
 		},
+		config.HelpKV{
+			Key:         config.ShadowSubSys,
+			Description: "mirror a sample of S3 read traffic to a second cluster and compare responses",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.AnomalySubSys,
+			Description: "alert a webhook on anomalous deletes, listings or egress of a user in a bucket",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.MalwareScanSubSys,
+			Description: "scan uploaded objects for malware, tagging or quarantining infected ones",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.MetadataSinkSubSys,
+			Description: "stream the metadata of objects to an Elasticsearch or PostgreSQL index",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.BucketWebhookSubSys,
+			Description: "call a webhook before and after buckets are created or deleted, pre hooks can deny the operation",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.TracingOTLPSubSys,
+			Description: "export request traces to an OpenTelemetry collector",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:         config.RPCSubSys,
+			Description: "tune the connections of the internode RPC transport",
+			Optional:    true,
+		},
+		config.HelpKV{
+			Key:             config.SLOSubSys,
+			Description:     "track availability and latency objectives and their error budgets",
+			Optional:        true,
+			MultipleTargets: true,
+		},
 	}
 
 	if globalIsErasure {
@@ -219,8 +296,11 @@ func initHelp() {
 		config.IdentityTLSSubSys:    xtls.Help,
 		config.PolicyOPASubSys:      opa.Help,
 		config.LoggerWebhookSubSys:  logger.Help,
+		config.LoggerSyslogSubSys:   logger.HelpSyslog,
 		config.AuditWebhookSubSys:   logger.HelpWebhook,
 		config.AuditKafkaSubSys:     logger.HelpKafka,
+		config.AuditFileSubSys:      logger.HelpFile,
+		config.AuditRedactionSubSys: logger.HelpAuditRedaction,
 		config.NotifyAMQPSubSys:     notify.HelpAMQP,
 		config.NotifyKafkaSubSys:    notify.HelpKafka,
 		config.NotifyMQTTSubSys:     notify.HelpMQTT,
@@ -232,6 +312,14 @@ func initHelp() {
 		config.NotifyWebhookSubSys:  notify.HelpWebhook,
 		config.NotifyESSubSys:       notify.HelpES,
 		config.SubnetSubSys:         subnet.HelpLicense,
+		config.ShadowSubSys:         shadow.Help,
+		config.AnomalySubSys:        anomaly.Help,
+		config.MalwareScanSubSys:    malware.Help,
+		config.MetadataSinkSubSys:   metadatasink.Help,
+		config.BucketWebhookSubSys:  bucketwebhook.Help,
+		config.TracingOTLPSubSys:    otlp.Help,
+		config.RPCSubSys:            rpc.Help,
+		config.SLOSubSys:            slo.Help,
 	}
 
 	config.RegisterHelpSubSys(helpMap)
@@ -301,6 +389,42 @@ func validateConfig(s config.Config) error {
 		return err
 	}
 
+	if _, err = shadow.LookupConfig(s[config.ShadowSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = anomaly.LookupConfig(s[config.AnomalySubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = malware.LookupConfig(s[config.MalwareScanSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = metadatasink.LookupConfig(s[config.MetadataSinkSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = bucketwebhook.LookupConfig(s[config.BucketWebhookSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = otlp.LookupConfig(s[config.TracingOTLPSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = rpc.LookupConfig(s[config.RPCSubSys][config.Default]); err != nil {
+		return err
+	}
+
+	if _, err = slo.LookupConfig(s[config.SLOSubSys]); err != nil {
+		return err
+	}
+
+	if _, err = logger.LookupAuditRedactionConfig(s[config.AuditRedactionSubSys][config.Default]); err != nil {
+		return err
+	}
+
 	{
 		etcdCfg, err := etcd.LookupConfig(s[config.EtcdSubSys][config.Default], globalRootCAs)
 		if err != nil {
@@ -500,19 +624,12 @@ func lookupConfigs(s config.Config, objAPI ObjectLayer) {
 		logger.Info("CRITICAL: enabling %s is not recommended in a production environment", xtls.EnvIdentityTLSSkipVerify)
 	}
 
-	globalOpenIDConfig, err = openid.LookupConfig(s[config.IdentityOpenIDSubSys][config.Default],
-		NewGatewayHTTPTransport(), xhttp.DrainBody)
-	if err != nil {
-		logger.LogIf(ctx, fmt.Errorf("Unable to initialize OpenID: %w", err))
-	}
-
 	opaCfg, err := opa.LookupConfig(s[config.PolicyOPASubSys][config.Default],
 		NewGatewayHTTPTransport(), xhttp.DrainBody)
 	if err != nil {
 		logger.LogIf(ctx, fmt.Errorf("Unable to initialize OPA: %w", err))
 	}
 
-	globalOpenIDValidators = getOpenIDValidators(globalOpenIDConfig)
 	globalPolicyOPA = opa.New(opaCfg)
 
 	globalLDAPConfig, err = xldap.Lookup(s[config.IdentityLDAPSubSys][config.Default],
@@ -546,6 +663,19 @@ func lookupConfigs(s config.Config, objAPI ObjectLayer) {
 		}
 	}
 
+	for name, l := range loggerCfg.Syslog {
+		if l.Enabled {
+			l.Name = name
+			l.LogOnce = logger.LogOnceIf
+			l.Hostname = globalLocalNodeName
+			l.RootCAs = globalRootCAs
+			// Enable syslog logging
+			if err = logger.AddTarget(syslog.New(l)); err != nil {
+				logger.LogIf(ctx, fmt.Errorf("Unable to initialize syslog target: %w", err))
+			}
+		}
+	}
+
 	for _, l := range loggerCfg.AuditWebhook {
 		if l.Enabled {
 			l.LogOnce = logger.LogOnceIf
@@ -568,9 +698,20 @@ func lookupConfigs(s config.Config, objAPI ObjectLayer) {
 		}
 	}
 
-	globalConfigTargetList, err = notify.GetNotificationTargets(GlobalContext, s, NewGatewayHTTPTransport(), false)
-	if err != nil {
-		logger.LogIf(ctx, fmt.Errorf("Unable to initialize notification target(s): %w", err))
+	for name, l := range loggerCfg.AuditFile {
+		if l.Enabled {
+			l.Name = name
+			l.LogOnce = logger.LogOnceIf
+			if l.Upload, err = newAuditSegmentUploader(l); err != nil {
+				logger.LogIf(ctx, fmt.Errorf("Unable to initialize audit file target: %w", err))
+				continue
+			}
+			l.UploadPrefix = globalLocalNodeName
+			// Enable file audit logging
+			if err = logger.AddAuditTarget(file.New(l)); err != nil {
+				logger.LogIf(ctx, fmt.Errorf("Unable to initialize audit file target: %w", err))
+			}
+		}
 	}
 
 	globalEnvTargetList, err = notify.GetNotificationTargets(GlobalContext, newServerConfig(), NewGatewayHTTPTransport(), true)
@@ -591,6 +732,29 @@ func lookupConfigs(s config.Config, objAPI ObjectLayer) {
 // applyDynamicConfig will apply dynamic config values.
 // Dynamic systems should be in config.SubSystemsDynamic as well.
 func applyDynamicConfig(ctx context.Context, objAPI ObjectLayer, s config.Config) error {
+	dynamicConfigMu.Lock()
+	defer dynamicConfigMu.Unlock()
+
+	// Identity providers and notification targets are only set up
+	// again when changed. They are applied first, those failing to
+	// initialize are left disabled without failing other settings.
+	if dynamicConfigChanged(s, config.IdentityOpenIDSubSys) {
+		openIDCfg, err := openid.LookupConfig(s[config.IdentityOpenIDSubSys][config.Default],
+			NewGatewayHTTPTransport(), xhttp.DrainBody)
+		if err != nil {
+			logger.LogIf(ctx, fmt.Errorf("Unable to initialize OpenID: %w", err))
+		}
+		setOpenIDConfig(openIDCfg)
+	}
+
+	if dynamicConfigChanged(s, notifySubSystems()...) {
+		targetList, err := notify.GetNotificationTargets(GlobalContext, s, NewGatewayHTTPTransport(), false)
+		if err != nil {
+			logger.LogIf(ctx, fmt.Errorf("Unable to initialize notification target(s): %w", err))
+		}
+		replaceNotificationTargets(targetList)
+	}
+
 	// Read all dynamic configs.
 	// API
 	apiConfig, err := api.LookupConfig(s[config.APISubSys][config.Default])
@@ -623,6 +787,60 @@ func applyDynamicConfig(ctx context.Context, objAPI ObjectLayer, s config.Config
 		return fmt.Errorf("Unable to apply scanner config: %w", err)
 	}
 
+	// Traffic shadowing
+	shadowCfg, err := shadow.LookupConfig(s[config.ShadowSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply traffic shadowing config: %w", err)
+	}
+
+	// Access anomaly detection
+	anomalyCfg, err := anomaly.LookupConfig(s[config.AnomalySubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply anomaly detection config: %w", err)
+	}
+
+	// Malware scanning
+	malwareCfg, err := malware.LookupConfig(s[config.MalwareScanSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply malware scan config: %w", err)
+	}
+
+	// Metadata sink
+	metadataSinkCfg, err := metadatasink.LookupConfig(s[config.MetadataSinkSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply metadata sink config: %w", err)
+	}
+
+	// Bucket provisioning webhooks
+	bucketWebhookCfg, err := bucketwebhook.LookupConfig(s[config.BucketWebhookSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply bucket webhook config: %w", err)
+	}
+
+	// OpenTelemetry tracing
+	otlpCfg, err := otlp.LookupConfig(s[config.TracingOTLPSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply tracing config: %w", err)
+	}
+
+	// Internode RPC transport
+	rpcCfg, err := rpc.LookupConfig(s[config.RPCSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply rpc config: %w", err)
+	}
+
+	// Service level objectives
+	sloCfg, err := slo.LookupConfig(s[config.SLOSubSys])
+	if err != nil {
+		return fmt.Errorf("Unable to apply slo config: %w", err)
+	}
+
+	// Audit redaction
+	redaction, err := logger.LookupAuditRedactionConfig(s[config.AuditRedactionSubSys][config.Default])
+	if err != nil {
+		return fmt.Errorf("Unable to apply audit redaction config: %w", err)
+	}
+
 	// Apply configurations.
 	// We should not fail after this.
 	var setDriveCounts []int
@@ -639,8 +857,30 @@ func applyDynamicConfig(ctx context.Context, objAPI ObjectLayer, s config.Config
 
 	// update dynamic scanner values.
 	scannerCycle.Update(scannerCfg.Cycle)
+	globalScannerHealth.setStaleAfter(scannerCfg.StaleAfter)
+	globalNamespacePressure.setLimits(scannerCfg.ObjectsAlert, scannerCfg.InodesAlert, scannerCfg.DirEntriesAlert)
 	logger.LogIf(ctx, scannerSleeper.Update(scannerCfg.Delay, scannerCfg.MaxWait))
 
+	logger.LogIf(ctx, globalTrafficShadow.Update(shadowCfg))
+
+	globalAccessAnomaly.Update(anomalyCfg)
+
+	globalMalwareScanner.Update(malwareCfg)
+
+	globalMetadataSink.Update(metadataSinkCfg)
+
+	globalBucketWebhook.Update(bucketWebhookCfg)
+
+	updateRequestTracing(otlpCfg)
+
+	if tr, ok := globalInternodeTransport.(*internodeTransport); ok {
+		tr.update(rpcCfg)
+	}
+
+	globalSLOTracker.update(sloCfg, UTCNow())
+
+	logger.SetAuditRedaction(redaction)
+
 	// Update all dynamic config values in memory.
 	globalServerConfigMu.Lock()
 	defer globalServerConfigMu.Unlock()
@@ -649,6 +889,7 @@ func applyDynamicConfig(ctx context.Context, objAPI ObjectLayer, s config.Config
 			globalServerConfig[k] = s[k]
 		}
 	}
+	setAppliedDynamicConfig(s)
 	return nil
 }
 
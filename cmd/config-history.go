@@ -0,0 +1,241 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+)
+
+// Metadata of the config history entries describing the change.
+const (
+	configHistoryUserKey       = ReservedMetadataPrefixLower + "config-user"
+	configHistoryActionKey     = ReservedMetadataPrefixLower + "config-action"
+	configHistorySubSystemsKey = ReservedMetadataPrefixLower + "config-subsystems"
+)
+
+// configHistoryCurrent refers to the config currently saved on the drives.
+const configHistoryCurrent = "current"
+
+// Config change actions recorded in the history.
+const (
+	configActionSetKV    = "set-config-kv"
+	configActionDelKV    = "del-config-kv"
+	configActionSet      = "set-config"
+	configActionRestore  = "restore-config-history-kv"
+	configActionRollback = "rollback"
+)
+
+// ConfigVersionInfo describes a recorded config change, the ID is the
+// restore id of the history entry.
+type ConfigVersionInfo struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	User       string    `json:"user,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	SubSystems []string  `json:"subSystems,omitempty"`
+}
+
+// ConfigDiffEntry is a single config value differing between two versions,
+// an empty Old or New value means the key was added or removed.
+type ConfigDiffEntry struct {
+	SubSys string `json:"subSys"`
+	Target string `json:"target,omitempty"`
+	Key    string `json:"key"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// configHistoryKV returns cfg in the KV format of the history, such
+// that restoring the entry restores the whole config. Sub-systems left
+// to their defaults are omitted, as some are not valid to set empty.
+func configHistoryKV(cfg config.Config) []byte {
+	subSystems := make([]string, 0, len(cfg))
+	for subSys := range cfg {
+		subSystems = append(subSystems, subSys)
+	}
+	sort.Strings(subSystems)
+
+	var s strings.Builder
+	for _, subSys := range subSystems {
+		targets := make([]string, 0, len(cfg[subSys]))
+		for target := range cfg[subSys] {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			kvs := cfg[subSys][target]
+			if target == config.Default && len(diffConfig(
+				config.Config{subSys: {target: kvs}},
+				config.Config{subSys: {target: config.DefaultKVS[subSys]}},
+			)) == 0 {
+				continue
+			}
+			s.WriteString(subSys)
+			if target != config.Default {
+				s.WriteString(config.SubSystemSeparator)
+				s.WriteString(target)
+			}
+			s.WriteString(config.KvSpaceSeparator)
+			s.WriteString(kvs.String())
+			s.WriteString(config.KvNewline)
+		}
+	}
+	return []byte(s.String())
+}
+
+// listConfigVersions returns up to count of the most recent history
+// entries, all of them if count is not positive, newest first. Entries
+// recorded before the change metadata was kept only have a time.
+func listConfigVersions(ctx context.Context, objAPI ObjectLayer, count int) ([]ConfigVersionInfo, error) {
+	var infos []ConfigVersionInfo
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, minioMetaBucket, minioConfigHistoryPrefix, marker, "", maxObjectList)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range res.Objects {
+			info := ConfigVersionInfo{
+				ID:     strings.TrimSuffix(path.Base(obj.Name), kvPrefix),
+				Time:   obj.ModTime,
+				User:   obj.UserDefined[configHistoryUserKey],
+				Action: obj.UserDefined[configHistoryActionKey],
+			}
+			if subSystems := obj.UserDefined[configHistorySubSystemsKey]; subSystems != "" {
+				info.SubSystems = strings.Split(subSystems, ",")
+			}
+			infos = append(infos, info)
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Time.After(infos[j].Time)
+	})
+	if count > 0 && len(infos) > count {
+		infos = infos[:count]
+	}
+	return infos, nil
+}
+
+// readConfigVersion returns the config recorded by the history entry
+// id, or the config currently saved for configHistoryCurrent.
+func readConfigVersion(ctx context.Context, objAPI ObjectLayer, id string) (config.Config, error) {
+	if id == configHistoryCurrent {
+		return readServerConfig(ctx, objAPI)
+	}
+	kv, err := readServerConfigHistory(ctx, objAPI, id)
+	if err != nil {
+		return nil, err
+	}
+	cfg := newServerConfig()
+	if _, err = cfg.ReadConfig(bytes.NewReader(kv)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// diffConfig returns the values differing between from and to,
+// sorted by sub-system, target and key.
+func diffConfig(from, to config.Config) []ConfigDiffEntry {
+	diff := []ConfigDiffEntry{}
+	lookup := func(c config.Config, subSys, target string) map[string]string {
+		m := make(map[string]string)
+		for _, kv := range c[subSys][target] {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	seen := make(map[[2]string]bool)
+	for _, c := range []config.Config{from, to} {
+		for subSys, targets := range c {
+			for target := range targets {
+				k := [2]string{subSys, target}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				oldKVs, newKVs := lookup(from, subSys, target), lookup(to, subSys, target)
+				keys := make(map[string]bool)
+				for key := range oldKVs {
+					keys[key] = true
+				}
+				for key := range newKVs {
+					keys[key] = true
+				}
+				for key := range keys {
+					if oldKVs[key] == newKVs[key] {
+						continue
+					}
+					diff = append(diff, ConfigDiffEntry{
+						SubSys: subSys,
+						Target: strings.TrimPrefix(target, config.Default),
+						Key:    key,
+						Old:    oldKVs[key],
+						New:    newKVs[key],
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].SubSys != diff[j].SubSys {
+			return diff[i].SubSys < diff[j].SubSys
+		}
+		if diff[i].Target != diff[j].Target {
+			return diff[i].Target < diff[j].Target
+		}
+		return diff[i].Key < diff[j].Key
+	})
+	return diff
+}
+
+// changedSubSystems returns the sub-systems differing between from and to.
+func changedSubSystems(from, to config.Config) []string {
+	var subSystems []string
+	for _, d := range diffConfig(from, to) {
+		if len(subSystems) == 0 || subSystems[len(subSystems)-1] != d.SubSys {
+			subSystems = append(subSystems, d.SubSys)
+		}
+	}
+	return subSystems
+}
+
+// rollbackConfig returns cfg with subSys replaced by its value in
+// version, the whole version if subSys is empty.
+func rollbackConfig(cfg, version config.Config, subSys string) (config.Config, error) {
+	if subSys == "" {
+		return version.Clone().Merge(), nil
+	}
+	if !config.SubSystems.Contains(subSys) {
+		return nil, config.Errorf("unknown sub-system %s", subSys)
+	}
+	// A sub-system missing from version had its default value.
+	cfg = cfg.Clone()
+	cfg[subSys] = version.Clone()[subSys]
+	return cfg.Merge(), nil
+}
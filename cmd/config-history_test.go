@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestDiffConfig(t *testing.T) {
+	from := config.Config{
+		config.APISubSys: {
+			config.Default: config.KVS{
+				{Key: "requests_max", Value: "0"},
+				{Key: "cors_allow_origin", Value: "*"},
+			},
+		},
+		config.NotifyWebhookSubSys: {
+			"old": config.KVS{{Key: "endpoint", Value: "http://old"}},
+		},
+	}
+	to := config.Config{
+		config.APISubSys: {
+			config.Default: config.KVS{
+				{Key: "requests_max", Value: "100"},
+				{Key: "cors_allow_origin", Value: "*"},
+			},
+		},
+		config.NotifyWebhookSubSys: {
+			"new": config.KVS{{Key: "endpoint", Value: "http://new"}},
+		},
+	}
+
+	want := []ConfigDiffEntry{
+		{SubSys: config.APISubSys, Key: "requests_max", Old: "0", New: "100"},
+		{SubSys: config.NotifyWebhookSubSys, Target: "new", Key: "endpoint", New: "http://new"},
+		{SubSys: config.NotifyWebhookSubSys, Target: "old", Key: "endpoint", Old: "http://old"},
+	}
+	if got := diffConfig(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diff\nwant %v\ngot  %v", want, got)
+	}
+
+	if got := changedSubSystems(from, to); !reflect.DeepEqual(got, []string{config.APISubSys, config.NotifyWebhookSubSys}) {
+		t.Errorf("unexpected changed sub-systems %v", got)
+	}
+	if got := diffConfig(from, from); len(got) != 0 {
+		t.Errorf("expected no diff, got %v", got)
+	}
+}
+
+func TestRollbackConfig(t *testing.T) {
+	current := config.Config{
+		config.APISubSys: {
+			config.Default: config.KVS{{Key: "requests_max", Value: "100"}},
+		},
+		config.NotifyWebhookSubSys: {
+			"a": config.KVS{{Key: "endpoint", Value: "http://a"}},
+		},
+	}
+	version := config.Config{
+		config.APISubSys: {
+			config.Default: config.KVS{{Key: "requests_max", Value: "10"}},
+		},
+	}
+
+	testCases := []struct {
+		subSys      string
+		requestsMax string
+		webhook     bool
+		wantErr     bool
+	}{
+		// The whole config is rolled back.
+		{subSys: "", requestsMax: "10", webhook: false},
+		// Only the API config is rolled back.
+		{subSys: config.APISubSys, requestsMax: "10", webhook: true},
+		// The webhook target did not exist in version.
+		{subSys: config.NotifyWebhookSubSys, requestsMax: "100", webhook: false},
+		{subSys: "unknown", wantErr: true},
+	}
+	for i, testCase := range testCases {
+		cfg, err := rollbackConfig(current, version, testCase.subSys)
+		if testCase.wantErr {
+			if err == nil {
+				t.Errorf("Test %d: expected an error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if v := cfg[config.APISubSys][config.Default].Get("requests_max"); v != testCase.requestsMax {
+			t.Errorf("Test %d: expected requests_max %s, got %s", i+1, testCase.requestsMax, v)
+		}
+		if _, ok := cfg[config.NotifyWebhookSubSys]["a"]; ok != testCase.webhook {
+			t.Errorf("Test %d: expected webhook target %v, got %v", i+1, testCase.webhook, ok)
+		}
+	}
+	if v := current[config.APISubSys][config.Default].Get("requests_max"); v != "100" {
+		t.Errorf("expected the current config not to be modified, got %s", v)
+	}
+}
+
+func TestConfigHistoryKV(t *testing.T) {
+	cfg := config.New().Clone()
+	if _, err := cfg.ReadConfig(strings.NewReader("api requests_max=100 cors_allow_origin=\"a b\"")); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := config.New().Clone()
+	if _, err := restored.ReadConfig(bytes.NewReader(configHistoryKV(cfg))); err != nil {
+		t.Fatal(err)
+	}
+	if diff := diffConfig(cfg, restored); len(diff) != 0 {
+		t.Errorf("expected the config to be restored from its history, got %v", diff)
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/config/identity/openid"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+)
+
+var (
+	// dynamicConfigMu serializes applyDynamicConfig.
+	dynamicConfigMu sync.Mutex
+	// appliedDynamicConfig holds the sub-systems last applied by
+	// applyDynamicConfig, protected by dynamicConfigMu.
+	appliedDynamicConfig = config.Config{}
+
+	// reloadConfigMu serializes reloadServerConfig.
+	reloadConfigMu sync.Mutex
+
+	// globalTLSCertsMu protects globalTLSCerts and globalPublicCerts
+	// replaced on reload, and globalTLSCertsCancel stopping the
+	// watchers of the certificate files of globalTLSCerts.
+	globalTLSCertsMu     sync.RWMutex
+	globalTLSCertsCancel context.CancelFunc
+
+	// globalOpenIDMu protects globalOpenIDConfig and
+	// globalOpenIDValidators replaced on reload.
+	globalOpenIDMu sync.RWMutex
+
+	// globalConfigTargetListMu protects globalConfigTargetList
+	// replaced on reload.
+	globalConfigTargetListMu sync.RWMutex
+)
+
+// notifySubSystems returns the notification target sub-systems.
+func notifySubSystems() []string {
+	var subSystems []string
+	for subSys := range config.SubSystemsDynamic {
+		if strings.HasPrefix(subSys, "notify_") {
+			subSystems = append(subSystems, subSys)
+		}
+	}
+	return subSystems
+}
+
+// dynamicConfigChanged returns whether any of subSystems differs in s
+// from the last applied config, the caller must hold dynamicConfigMu.
+func dynamicConfigChanged(s config.Config, subSystems ...string) bool {
+	for _, subSys := range subSystems {
+		prev, ok := appliedDynamicConfig[subSys]
+		if !ok || !reflect.DeepEqual(prev, s[subSys]) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAppliedDynamicConfig records the dynamic sub-systems of s as
+// applied, the caller must hold dynamicConfigMu.
+func setAppliedDynamicConfig(s config.Config) {
+	for subSys := range config.SubSystemsDynamic {
+		appliedDynamicConfig[subSys] = s[subSys]
+	}
+}
+
+// replaceNotificationTargets replaces the configured notification
+// targets by list, closing the current ones. At startup the targets
+// are added by NotificationSys.Init instead.
+func replaceNotificationTargets(list *event.TargetList) {
+	if list == nil {
+		list = event.NewTargetList()
+	}
+	globalConfigTargetListMu.Lock()
+	old := globalConfigTargetList
+	globalConfigTargetList = list
+	globalConfigTargetListMu.Unlock()
+	if old == nil || globalNotificationSys == nil {
+		return
+	}
+	globalNotificationSys.targetList.Remove(event.NewTargetIDSet(old.List()...))
+	logger.LogIf(GlobalContext, globalNotificationSys.targetList.Add(list.Targets()...))
+}
+
+// getConfigTargetList returns the configured notification targets.
+func getConfigTargetList() *event.TargetList {
+	globalConfigTargetListMu.RLock()
+	defer globalConfigTargetListMu.RUnlock()
+	return globalConfigTargetList
+}
+
+// setOpenIDConfig replaces the OpenID config and its validators.
+func setOpenIDConfig(cfg openid.Config) {
+	validators := getOpenIDValidators(cfg)
+	globalOpenIDMu.Lock()
+	globalOpenIDConfig, globalOpenIDValidators = cfg, validators
+	globalOpenIDMu.Unlock()
+}
+
+// getOpenIDConfig returns the current OpenID config and its validators.
+func getOpenIDConfig() (openid.Config, *openid.Validators) {
+	globalOpenIDMu.RLock()
+	defer globalOpenIDMu.RUnlock()
+	return globalOpenIDConfig, globalOpenIDValidators
+}
+
+// getPublicCerts returns the current public certificates.
+func getPublicCerts() []*x509.Certificate {
+	globalTLSCertsMu.RLock()
+	defer globalTLSCertsMu.RUnlock()
+	return globalPublicCerts
+}
+
+// getTLSCertificate returns the certificate of the client hello from
+// the current certificates, replaced on reload.
+func getTLSCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	globalTLSCertsMu.RLock()
+	tlsCerts := globalTLSCerts
+	globalTLSCertsMu.RUnlock()
+	if tlsCerts == nil {
+		return nil, errors.New("No TLS certificates found")
+	}
+	return tlsCerts.GetCertificate(hello)
+}
+
+// reloadTLSCertificates loads the certificates of the certs directory
+// again, picking up added and replaced domain certificates. Enabling
+// or disabling TLS still requires a restart, the current certificates
+// are kept then.
+func reloadTLSCertificates() error {
+	globalTLSCertsMu.RLock()
+	enabled := globalTLSCerts != nil
+	globalTLSCertsMu.RUnlock()
+	if !globalIsTLS || !enabled {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	publicCerts, tlsCerts, secure, err := loadTLSConfig(ctx)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("Unable to load the TLS configuration: %w", err)
+	}
+	if !secure {
+		cancel()
+		return errors.New("TLS certificates were removed, restart the server to disable TLS")
+	}
+
+	globalTLSCertsMu.Lock()
+	prevCancel := globalTLSCertsCancel
+	globalPublicCerts, globalTLSCerts, globalTLSCertsCancel = publicCerts, tlsCerts, cancel
+	globalTLSCertsMu.Unlock()
+
+	// Stop watching the files of the replaced certificates.
+	if prevCancel != nil {
+		prevCancel()
+	}
+	return nil
+}
+
+// reloadServerConfig reloads the TLS certificates, the dynamic config
+// including identity providers and notification targets, the tier
+// credentials, the ACME certificates and the pool tags of this node.
+func reloadServerConfig(ctx context.Context, objAPI ObjectLayer) error {
+	reloadConfigMu.Lock()
+	defer reloadConfigMu.Unlock()
+
+	var errs []string
+	if err := reloadTLSCertificates(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	srvCfg, err := getValidConfig(objAPI)
+	if err == nil {
+		// Set up all dynamic sub-systems again, also unchanged
+		// ones depending on external state.
+		dynamicConfigMu.Lock()
+		appliedDynamicConfig = config.Config{}
+		dynamicConfigMu.Unlock()
+		err = applyDynamicConfig(ctx, objAPI, srvCfg)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("Unable to apply the dynamic config: %v", err))
+	}
+
+	if globalTierConfigMgr != nil {
+		if err = globalTierConfigMgr.Reload(ctx, objAPI); err != nil {
+			errs = append(errs, fmt.Sprintf("Unable to reload the tier config: %v", err))
+		}
+	}
+	if globalACMESys != nil {
+		globalACMESys.reload(ctx, objAPI)
+	}
+	if err = reloadPoolTags(ctx, objAPI); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// reloadClusterConfig reloads the config on this node and all peers.
+func reloadClusterConfig(ctx context.Context, objectAPI ObjectLayer) []ReloadConfigResult {
+	results := []ReloadConfigResult{{Host: globalLocalNodeName}}
+	if err := reloadServerConfig(ctx, objectAPI); err != nil {
+		results[0].Error = err.Error()
+	}
+	for _, nerr := range globalNotificationSys.SignalService(serviceReload) {
+		if nerr.Host.String() == "" {
+			continue
+		}
+		result := ReloadConfigResult{Host: nerr.Host.String()}
+		if nerr.Err != nil {
+			result.Error = nerr.Err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Pseudo sub-systems reloaded besides the config sub-systems.
+const (
+	reloadTLSSubSys  = "tls"
+	reloadTierSubSys = "tier"
+)
+
+// DynamicConfigStatus - whether a sub-system is applied without a
+// restart, when set or on reload, and its keys.
+type DynamicConfigStatus struct {
+	SubSystem string   `json:"subSystem"`
+	Dynamic   bool     `json:"dynamic"`
+	Keys      []string `json:"keys,omitempty"`
+}
+
+// getDynamicConfigStatus returns the status of all sub-systems.
+func getDynamicConfigStatus() []DynamicConfigStatus {
+	status := []DynamicConfigStatus{
+		{SubSystem: reloadTLSSubSys, Dynamic: true},
+		{SubSystem: reloadTierSubSys, Dynamic: true},
+	}
+	for _, subSys := range config.SubSystems.ToSlice() {
+		s := DynamicConfigStatus{
+			SubSystem: subSys,
+			Dynamic:   config.SubSystemsDynamic.Contains(subSys),
+		}
+		for _, kv := range config.DefaultKVS[subSys] {
+			s.Keys = append(s.Keys, kv.Key)
+		}
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].SubSystem < status[j].SubSystem
+	})
+	return status
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestDynamicConfigChanged(t *testing.T) {
+	dynamicConfigMu.Lock()
+	defer dynamicConfigMu.Unlock()
+	prev := appliedDynamicConfig
+	defer func() { appliedDynamicConfig = prev }()
+	appliedDynamicConfig = config.Config{}
+
+	s := newServerConfig()
+	if !dynamicConfigChanged(s, config.IdentityOpenIDSubSys) {
+		t.Fatal("expected a sub-system never applied to be changed")
+	}
+	setAppliedDynamicConfig(s)
+	if dynamicConfigChanged(s, config.IdentityOpenIDSubSys, config.NotifyWebhookSubSys) {
+		t.Fatal("expected applied sub-systems to be unchanged")
+	}
+
+	s = newServerConfig()
+	if _, err := s.SetKVS("notify_webhook:1 endpoint=http://localhost:8080", config.DefaultKVS); err != nil {
+		t.Fatal(err)
+	}
+	if !dynamicConfigChanged(s, notifySubSystems()...) {
+		t.Fatal("expected notification targets to be changed")
+	}
+	if dynamicConfigChanged(s, config.IdentityOpenIDSubSys) {
+		t.Fatal("expected OpenID to be unchanged")
+	}
+}
+
+func TestDynamicConfigStatus(t *testing.T) {
+	status := map[string]DynamicConfigStatus{}
+	for _, s := range getDynamicConfigStatus() {
+		status[s.SubSystem] = s
+	}
+	for _, subSys := range []string{reloadTLSSubSys, reloadTierSubSys, config.APISubSys, config.IdentityOpenIDSubSys, config.NotifyKafkaSubSys} {
+		if !status[subSys].Dynamic {
+			t.Errorf("expected %s to be dynamic", subSys)
+		}
+	}
+	for _, subSys := range []string{config.StorageClassSubSys, config.IdentityLDAPSubSys} {
+		if s, ok := status[subSys]; !ok || s.Dynamic || len(s.Keys) == 0 {
+			t.Errorf("expected %s to be static with keys, got %#v", subSys, s)
+		}
+	}
+}
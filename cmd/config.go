@@ -117,9 +117,12 @@ func readServerConfigHistory(ctx context.Context, objAPI ObjectLayer, uuidKV str
 	return data, err
 }
 
-func saveServerConfigHistory(ctx context.Context, objAPI ObjectLayer, kv []byte) error {
+// saveServerConfigHistory records cfg, the config resulting from action
+// by user on prev, as a new history entry.
+func saveServerConfigHistory(ctx context.Context, objAPI ObjectLayer, user, action string, prev, cfg config.Config) error {
 	uuidKV := mustGetUUID() + kvPrefix
 	historyFile := pathJoin(minioConfigHistoryPrefix, uuidKV)
+	kv := configHistoryKV(cfg)
 
 	if GlobalKMS != nil {
 		var err error
@@ -130,7 +133,16 @@ func saveServerConfigHistory(ctx context.Context, objAPI ObjectLayer, kv []byte)
 			return err
 		}
 	}
-	return saveConfig(ctx, objAPI, historyFile, kv)
+	// The change is described in the metadata such that listing the
+	// history does not need to read every entry.
+	return saveConfigWithOpts(ctx, objAPI, historyFile, kv, ObjectOptions{
+		MaxParity: true,
+		UserDefined: map[string]string{
+			configHistoryUserKey:       user,
+			configHistoryActionKey:     action,
+			configHistorySubSystemsKey: strings.Join(changedSubSystems(prev, cfg), ","),
+		},
+	})
 }
 
 func saveServerConfig(ctx context.Context, objAPI ObjectLayer, cfg interface{}) error {
@@ -101,6 +101,9 @@ func runDataScanner(pctx context.Context, objAPI ObjectLayer) {
 	for {
 		lkctx, err := locker.GetLock(pctx, dataScannerLeaderLockTimeout)
 		if err != nil {
+			if pctx.Err() != nil {
+				return
+			}
 			time.Sleep(time.Duration(r.Float64() * float64(scannerCycle.Get())))
 			continue
 		}
@@ -110,6 +113,8 @@ func runDataScanner(pctx context.Context, objAPI ObjectLayer) {
 		// No unlock for "leader" lock.
 	}
 
+	go runScannerHealthCheck(ctx, objAPI)
+
 	// Load current bloom cycle
 	nextBloomCycle := intDataUpdateTracker.current() + 1
 
@@ -142,14 +147,35 @@ func runDataScanner(pctx context.Context, objAPI ObjectLayer) {
 				console.Debugln("starting scanner cycle")
 			}
 
+			// Wait for the job scheduler to allow the cycle, a cycle
+			// interrupted by the end of its window or by a job of higher
+			// priority is retried.
+			jctx, release, err := globalJobScheduler.acquire(ctx, jobScanner)
+			if err != nil {
+				return
+			}
+
 			// Wait before starting next cycle and wait on startup.
 			results := make(chan DataUsageInfo, 1)
-			go storeDataUsageInBackend(ctx, objAPI, results)
+			stored := make(chan struct{})
+			go func() {
+				storeDataUsageInBackend(ctx, objAPI, results)
+				close(stored)
+			}()
 			bf, err := globalNotificationSys.updateBloomFilter(ctx, nextBloomCycle)
 			logger.LogIf(ctx, err)
-			err = objAPI.NSScanner(ctx, bf, results, uint32(nextBloomCycle))
-			logger.LogIf(ctx, err)
+			err = objAPI.NSScanner(jctx, bf, results, uint32(nextBloomCycle))
+			if jctx.Err() == nil {
+				logger.LogIf(ctx, err)
+			}
+			release()
+			logger.LogIf(ctx, storeIncompleteUploadsUsage(ctx, objAPI))
 			if err == nil {
+				// Snapshot the usage of the completed cycle once saved.
+				<-stored
+				logger.LogIf(ctx, storeDataUsageSnapshot(ctx, objAPI))
+				logger.LogIf(ctx, storeCapacitySample(ctx, objAPI))
+
 				// Store new cycle...
 				nextBloomCycle++
 				var tmp [8]byte
@@ -164,6 +190,8 @@ func runDataScanner(pctx context.Context, objAPI ObjectLayer) {
 				if !isErrBucketNotFound(err) {
 					logger.LogIf(ctx, err)
 				}
+
+				globalMetadataSink.sweepBackfill(ctx, objAPI, nextBloomCycle)
 			}
 		}
 	}
@@ -526,6 +554,13 @@ func (f *folderScanner) scanFolder(ctx context.Context, folder cachedFolder, int
 			break
 		}
 
+		// Folders with the most direct children are listed by an index.
+		if globalIsErasure && !foundObjects {
+			children := len(existingFolders) + len(newFolders)
+			checkListIndexFolder(f.root, folder.name, children)
+			globalNamespacePressure.observeDir(f.root, f.newCache.Info.Name, folder.name, uint64(children))
+		}
+
 		// If we have many subfolders, compact ourself.
 		if !into.Compacted &&
 			f.newCache.Info.Name != folder.name &&
@@ -830,6 +865,7 @@ type scannerItem struct {
 
 type sizeSummary struct {
 	totalSize       int64
+	noncurrentSize  int64
 	versions        uint64
 	replicatedSize  int64
 	pendingSize     int64
@@ -839,6 +875,14 @@ type sizeSummary struct {
 	failedCount     uint64
 	replTargetStats map[string]replTargetSizeSummary
 	tiers           map[string]tierStats
+	storageClasses  map[string]tierStats
+	// Keys of the latest version, unset when it is a delete marker.
+	contentType string
+	extension   string
+	// Sizes not read for coldAccessDays, nil unless access tracking is enabled.
+	coldSizes *coldSizes
+	// Sizes of compressed versions, nil if there are none.
+	compressedSizes *compressedSizes
 }
 
 // replTargetSizeSummary holds summary of replication stats by target
@@ -898,6 +942,12 @@ func (i *scannerItem) applyLifecycle(ctx context.Context, o ObjectLayer, oi Obje
 		}
 		return false, size
 	}
+	// Expiries and transitions queued while the ILM window is closed
+	// wait for it, filling the queues until the next ones are dropped.
+	// The object is checked again by a later cycle.
+	if !globalJobScheduler.windowOpen(jobILM) {
+		return false, size
+	}
 
 	atomic.AddUint64(&globalScannerStats.ilmChecks, 1)
 	versionID := oi.VersionID
@@ -1020,6 +1070,12 @@ func evalActionFromLifecycle(ctx context.Context, lc lifecycle.Lifecycle, obj Ob
 				return lifecycle.NoneAction
 			}
 		}
+		if objAPI := newObjectLayerFn(); objAPI != nil && globalBucketSnapshotSys.pinned(ctx, objAPI, obj.Bucket, obj.Name, obj.VersionID) {
+			if debug {
+				console.Debugf(applyActionsLogPrefix+" lifecycle: %s v(%s) is pinned by a snapshot, not deleting\n", obj.Name, obj.VersionID)
+			}
+			return lifecycle.NoneAction
+		}
 	}
 
 	return action
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"path"
 	"path/filepath"
@@ -46,6 +47,12 @@ type dataUsageHash string
 // sizeHistogram is a size histogram.
 type sizeHistogram [dataUsageBucketLen]uint64
 
+// coldSizes holds the sizes of objects not read for each of coldAccessDays.
+type coldSizes [coldSizesLen]uint64
+
+// compressedSizes holds the stored and the actual sizes of compressed versions.
+type compressedSizes [compressedSizesLen]uint64
+
 type dataUsageEntry struct {
 	Children dataUsageHashMap `msg:"ch"`
 	// These fields do no include any children.
@@ -55,7 +62,17 @@ type dataUsageEntry struct {
 	ObjSizes         sizeHistogram        `msg:"szs"`
 	ReplicationStats *replicationAllStats `msg:"rs,omitempty"`
 	AllTierStats     *allTierStats        `msg:"ats,omitempty"`
-	Compacted        bool                 `msg:"c"`
+	ContentStats     *allContentStats     `msg:"cts,omitempty"`
+	// Stats by storage class, transitioned versions are
+	// accounted under their remote tier.
+	StorageClassStats *allTierStats `msg:"scs,omitempty"`
+	// Sizes of objects not read for coldAccessDays.
+	ColdSizes *coldSizes `msg:"cold,omitempty"`
+	// Sizes of compressed versions, to report compression ratios.
+	CompressedSizes *compressedSizes `msg:"cmp,omitempty"`
+	// Size of the noncurrent versions, included in Size.
+	NoncurrentSize int64 `msg:"ncs,omitempty"`
+	Compacted      bool  `msg:"c"`
 }
 
 // allTierStats is a collection of per-tier stats across all configured remote
@@ -112,6 +129,99 @@ func (ts tierStats) add(u tierStats) tierStats {
 	return ts
 }
 
+const (
+	// Maximum number of distinct content-types and extensions kept
+	// per entry, objects beyond are accounted as otherContentKey.
+	maxContentStatsKeys = 64
+	maxExtensionLength  = 16
+
+	otherContentKey = "other"
+	noContentKey    = "none"
+)
+
+// allContentStats holds object counts and sizes by content-type and by
+// file extension.
+type allContentStats struct {
+	ContentTypes map[string]contentStats `msg:"ct"`
+	Extensions   map[string]contentStats `msg:"ext"`
+}
+
+func newAllContentStats() *allContentStats {
+	return &allContentStats{
+		ContentTypes: make(map[string]contentStats),
+		Extensions:   make(map[string]contentStats),
+	}
+}
+
+func (acs *allContentStats) addSizes(sz sizeSummary) {
+	st := contentStats{Size: uint64(sz.totalSize), Objects: 1}
+	addContentStats(acs.ContentTypes, sz.contentType, st)
+	addContentStats(acs.Extensions, sz.extension, st)
+}
+
+func (acs *allContentStats) merge(other *allContentStats) {
+	for key, st := range other.ContentTypes {
+		addContentStats(acs.ContentTypes, key, st)
+	}
+	for key, st := range other.Extensions {
+		addContentStats(acs.Extensions, key, st)
+	}
+}
+
+func (acs *allContentStats) usageInfo() (contentTypes, extensions map[string]BucketContentUsage) {
+	if acs == nil {
+		return nil, nil
+	}
+	toUsage := func(m map[string]contentStats) map[string]BucketContentUsage {
+		usage := make(map[string]BucketContentUsage, len(m))
+		for key, st := range m {
+			usage[key] = BucketContentUsage{Size: st.Size, ObjectsCount: st.Objects}
+		}
+		return usage
+	}
+	return toUsage(acs.ContentTypes), toUsage(acs.Extensions)
+}
+
+func addContentStats(m map[string]contentStats, key string, st contentStats) {
+	if _, ok := m[key]; !ok && len(m) >= maxContentStatsKeys {
+		key = otherContentKey
+	}
+	m[key] = m[key].add(st)
+}
+
+// contentStatsKeys returns the keys oi is accounted under by content-type
+// and by extension.
+func contentStatsKeys(oi ObjectInfo) (contentType, extension string) {
+	contentType = noContentKey
+	if mediaType, _, err := mime.ParseMediaType(oi.ContentType); err == nil {
+		contentType = mediaType
+	} else if oi.ContentType != "" {
+		contentType = otherContentKey
+	}
+
+	extension = noContentKey
+	if ext := path.Ext(oi.Name); len(ext) > 1 {
+		extension = strings.ToLower(ext[1:])
+		if len(extension) > maxExtensionLength {
+			extension = otherContentKey
+		}
+	}
+	return contentType, extension
+}
+
+// contentStats holds the number and size of objects of a content-type
+// or an extension.
+type contentStats struct {
+	Size    uint64 `msg:"sz"`
+	Objects uint64 `msg:"os"`
+}
+
+func (cs contentStats) add(u contentStats) contentStats {
+	cs.Size += u.Size
+	cs.Objects += u.Objects
+	return cs
+}
+
 //msgp:tuple replicationStatsV1
 type replicationStatsV1 struct {
 	PendingSize          uint64
@@ -295,6 +405,7 @@ type dataUsageCacheInfo struct {
 
 func (e *dataUsageEntry) addSizes(summary sizeSummary) {
 	e.Size += summary.totalSize
+	e.NoncurrentSize += summary.noncurrentSize
 	e.Versions += summary.versions
 	e.ObjSizes.add(summary.totalSize)
 
@@ -327,6 +438,30 @@ func (e *dataUsageEntry) addSizes(summary sizeSummary) {
 		}
 		e.AllTierStats.addSizes(summary)
 	}
+	if summary.contentType != "" {
+		if e.ContentStats == nil {
+			e.ContentStats = newAllContentStats()
+		}
+		e.ContentStats.addSizes(summary)
+	}
+	if len(summary.storageClasses) > 0 {
+		if e.StorageClassStats == nil {
+			e.StorageClassStats = newAllTierStats()
+		}
+		e.StorageClassStats.merge(&allTierStats{Tiers: summary.storageClasses})
+	}
+	if summary.coldSizes != nil {
+		if e.ColdSizes == nil {
+			e.ColdSizes = &coldSizes{}
+		}
+		e.ColdSizes.merge(*summary.coldSizes)
+	}
+	if summary.compressedSizes != nil {
+		if e.CompressedSizes == nil {
+			e.CompressedSizes = &compressedSizes{}
+		}
+		e.CompressedSizes.merge(*summary.compressedSizes)
+	}
 }
 
 // merge other data usage entry into this, excluding children.
@@ -334,6 +469,7 @@ func (e *dataUsageEntry) merge(other dataUsageEntry) {
 	e.Objects += other.Objects
 	e.Versions += other.Versions
 	e.Size += other.Size
+	e.NoncurrentSize += other.NoncurrentSize
 	if other.ReplicationStats != nil {
 		if e.ReplicationStats == nil {
 			e.ReplicationStats = &replicationAllStats{Targets: make(map[string]replicationStats)}
@@ -363,6 +499,34 @@ func (e *dataUsageEntry) merge(other dataUsageEntry) {
 		}
 		e.AllTierStats.merge(other.AllTierStats)
 	}
+
+	if other.ContentStats != nil {
+		if e.ContentStats == nil {
+			e.ContentStats = newAllContentStats()
+		}
+		e.ContentStats.merge(other.ContentStats)
+	}
+
+	if other.StorageClassStats != nil {
+		if e.StorageClassStats == nil {
+			e.StorageClassStats = newAllTierStats()
+		}
+		e.StorageClassStats.merge(other.StorageClassStats)
+	}
+
+	if other.ColdSizes != nil {
+		if e.ColdSizes == nil {
+			e.ColdSizes = &coldSizes{}
+		}
+		e.ColdSizes.merge(*other.ColdSizes)
+	}
+
+	if other.CompressedSizes != nil {
+		if e.CompressedSizes == nil {
+			e.CompressedSizes = &compressedSizes{}
+		}
+		e.CompressedSizes.merge(*other.CompressedSizes)
+	}
 }
 
 // mod returns true if the hash mod cycles == cycle.
@@ -414,6 +578,24 @@ func (e dataUsageEntry) clone() dataUsageEntry {
 		ats.merge(e.AllTierStats)
 		e.AllTierStats = ats
 	}
+	if e.ContentStats != nil {
+		cs := newAllContentStats()
+		cs.merge(e.ContentStats)
+		e.ContentStats = cs
+	}
+	if e.StorageClassStats != nil {
+		scs := newAllTierStats()
+		scs.merge(e.StorageClassStats)
+		e.StorageClassStats = scs
+	}
+	if e.ColdSizes != nil {
+		cs := *e.ColdSizes
+		e.ColdSizes = &cs
+	}
+	if e.CompressedSizes != nil {
+		cs := *e.CompressedSizes
+		e.CompressedSizes = &cs
+	}
 	return e
 }
 
@@ -536,6 +718,7 @@ func (d *dataUsageCache) dui(path string, buckets []BucketInfo) DataUsageInfo {
 		BucketsCount:      uint64(len(e.Children)),
 		BucketsUsage:      d.bucketsUsageInfo(buckets),
 		TierStats:         d.tiersUsageInfo(buckets),
+		StorageClassStats: d.storageClassUsageInfo(buckets),
 	}
 	return dui
 }
@@ -752,6 +935,47 @@ func (h *sizeHistogram) toMap() map[string]uint64 {
 	return res
 }
 
+// merge adds the sizes of other.
+func (c *coldSizes) merge(other coldSizes) {
+	for i, size := range other {
+		c[i] += size
+	}
+}
+
+// toMap returns the sizes keyed by number of days not accessed.
+func (c *coldSizes) toMap() map[string]uint64 {
+	if c == nil {
+		return nil
+	}
+	res := make(map[string]uint64, coldSizesLen)
+	for i, size := range c {
+		res[fmt.Sprintf("%dd", coldAccessDays[i])] = size
+	}
+	return res
+}
+
+// merge adds the sizes of other.
+func (c *compressedSizes) merge(other compressedSizes) {
+	for i, size := range other {
+		c[i] += size
+	}
+}
+
+// add accounts a compressed version of actual size stored in stored bytes.
+func (c *compressedSizes) add(stored, actual int64) {
+	c[0] += uint64(stored)
+	c[1] += uint64(actual)
+}
+
+// usageInfo returns the stored and actual sizes of the compressed versions
+// and the ratio of the actual to the stored size.
+func (c *compressedSizes) usageInfo() (stored, actual uint64, ratio float64) {
+	if c == nil || c[0] == 0 {
+		return 0, 0, 0
+	}
+	return c[0], c[1], float64(c[1]) / float64(c[0])
+}
+
 func (d *dataUsageCache) tiersUsageInfo(buckets []BucketInfo) *allTierStats {
 	dst := newAllTierStats()
 	for _, bucket := range buckets {
@@ -771,6 +995,25 @@ func (d *dataUsageCache) tiersUsageInfo(buckets []BucketInfo) *allTierStats {
 	return dst
 }
 
+func (d *dataUsageCache) storageClassUsageInfo(buckets []BucketInfo) *allTierStats {
+	var dst *allTierStats
+	for _, bucket := range buckets {
+		e := d.find(bucket.Name)
+		if e == nil {
+			continue
+		}
+		flat := d.flatten(*e)
+		if flat.StorageClassStats == nil {
+			continue
+		}
+		if dst == nil {
+			dst = newAllTierStats()
+		}
+		dst.merge(flat.StorageClassStats)
+	}
+	return dst
+}
+
 // bucketsUsageInfo returns the buckets usage info as a map, with
 // key as bucket name
 func (d *dataUsageCache) bucketsUsageInfo(buckets []BucketInfo) map[string]BucketUsageInfo {
@@ -786,6 +1029,13 @@ func (d *dataUsageCache) bucketsUsageInfo(buckets []BucketInfo) map[string]Bucke
 			ObjectsCount:         flat.Objects,
 			ObjectSizesHistogram: flat.ObjSizes.toMap(),
 		}
+		bui.ContentTypes, bui.Extensions = flat.ContentStats.usageInfo()
+		bui.NotAccessedSizes = flat.ColdSizes.toMap()
+		bui.CompressedStoredSize, bui.CompressedSize, bui.CompressionRatio = flat.CompressedSizes.usageInfo()
+		bui.NoncurrentSize = uint64(flat.NoncurrentSize)
+		if flat.StorageClassStats != nil {
+			bui.StorageClasses = flat.StorageClassStats.adminStats(make(map[string]madmin.TierStats))
+		}
 		if flat.ReplicationStats != nil {
 			bui.ReplicaSize = flat.ReplicationStats.ReplicaSize
 			bui.ReplicationInfo = make(map[string]BucketTargetUsageInfo, len(flat.ReplicationStats.Targets))
@@ -818,6 +1068,13 @@ func (d *dataUsageCache) bucketUsageInfo(bucket string) BucketUsageInfo {
 		ObjectsCount:         flat.Objects,
 		ObjectSizesHistogram: flat.ObjSizes.toMap(),
 	}
+	bui.ContentTypes, bui.Extensions = flat.ContentStats.usageInfo()
+	bui.NotAccessedSizes = flat.ColdSizes.toMap()
+	bui.CompressedStoredSize, bui.CompressedSize, bui.CompressionRatio = flat.CompressedSizes.usageInfo()
+	bui.NoncurrentSize = uint64(flat.NoncurrentSize)
+	if flat.StorageClassStats != nil {
+		bui.StorageClasses = flat.StorageClassStats.adminStats(make(map[string]madmin.TierStats))
+	}
 	if flat.ReplicationStats != nil {
 		bui.ReplicaSize = flat.ReplicationStats.ReplicaSize
 		bui.ReplicationInfo = make(map[string]BucketTargetUsageInfo, len(flat.ReplicationStats.Targets))
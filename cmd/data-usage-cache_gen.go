@@ -6,6 +6,435 @@ import (
 	"github.com/tinylib/msgp/msgp"
 )
 
+// DecodeMsg implements msgp.Decodable
+func (z *allContentStats) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ct":
+			var zb0002 uint32
+			zb0002, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "ContentTypes")
+				return
+			}
+			if z.ContentTypes == nil {
+				z.ContentTypes = make(map[string]contentStats, zb0002)
+			} else if len(z.ContentTypes) > 0 {
+				for key := range z.ContentTypes {
+					delete(z.ContentTypes, key)
+				}
+			}
+			for zb0002 > 0 {
+				zb0002--
+				var za0001 string
+				var za0002 contentStats
+				za0001, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "ContentTypes")
+					return
+				}
+				var zb0003 uint32
+				zb0003, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "ContentTypes", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "ContentTypes", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "sz":
+						za0002.Size, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001, "Size")
+							return
+						}
+					case "os":
+						za0002.Objects, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001, "Objects")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001)
+							return
+						}
+					}
+				}
+				z.ContentTypes[za0001] = za0002
+			}
+		case "ext":
+			var zb0004 uint32
+			zb0004, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Extensions")
+				return
+			}
+			if z.Extensions == nil {
+				z.Extensions = make(map[string]contentStats, zb0004)
+			} else if len(z.Extensions) > 0 {
+				for key := range z.Extensions {
+					delete(z.Extensions, key)
+				}
+			}
+			for zb0004 > 0 {
+				zb0004--
+				var za0003 string
+				var za0004 contentStats
+				za0003, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Extensions")
+					return
+				}
+				var zb0005 uint32
+				zb0005, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Extensions", za0003)
+					return
+				}
+				for zb0005 > 0 {
+					zb0005--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Extensions", za0003)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "sz":
+						za0004.Size, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003, "Size")
+							return
+						}
+					case "os":
+						za0004.Objects, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003, "Objects")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003)
+							return
+						}
+					}
+				}
+				z.Extensions[za0003] = za0004
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *allContentStats) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "ct"
+	err = en.Append(0x82, 0xa2, 0x63, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.ContentTypes)))
+	if err != nil {
+		err = msgp.WrapError(err, "ContentTypes")
+		return
+	}
+	for za0001, za0002 := range z.ContentTypes {
+		err = en.WriteString(za0001)
+		if err != nil {
+			err = msgp.WrapError(err, "ContentTypes")
+			return
+		}
+		// map header, size 2
+		// write "sz"
+		err = en.Append(0x82, 0xa2, 0x73, 0x7a)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(za0002.Size)
+		if err != nil {
+			err = msgp.WrapError(err, "ContentTypes", za0001, "Size")
+			return
+		}
+		// write "os"
+		err = en.Append(0xa2, 0x6f, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(za0002.Objects)
+		if err != nil {
+			err = msgp.WrapError(err, "ContentTypes", za0001, "Objects")
+			return
+		}
+	}
+	// write "ext"
+	err = en.Append(0xa3, 0x65, 0x78, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Extensions)))
+	if err != nil {
+		err = msgp.WrapError(err, "Extensions")
+		return
+	}
+	for za0003, za0004 := range z.Extensions {
+		err = en.WriteString(za0003)
+		if err != nil {
+			err = msgp.WrapError(err, "Extensions")
+			return
+		}
+		// map header, size 2
+		// write "sz"
+		err = en.Append(0x82, 0xa2, 0x73, 0x7a)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(za0004.Size)
+		if err != nil {
+			err = msgp.WrapError(err, "Extensions", za0003, "Size")
+			return
+		}
+		// write "os"
+		err = en.Append(0xa2, 0x6f, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(za0004.Objects)
+		if err != nil {
+			err = msgp.WrapError(err, "Extensions", za0003, "Objects")
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *allContentStats) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "ct"
+	o = append(o, 0x82, 0xa2, 0x63, 0x74)
+	o = msgp.AppendMapHeader(o, uint32(len(z.ContentTypes)))
+	for za0001, za0002 := range z.ContentTypes {
+		o = msgp.AppendString(o, za0001)
+		// map header, size 2
+		// string "sz"
+		o = append(o, 0x82, 0xa2, 0x73, 0x7a)
+		o = msgp.AppendUint64(o, za0002.Size)
+		// string "os"
+		o = append(o, 0xa2, 0x6f, 0x73)
+		o = msgp.AppendUint64(o, za0002.Objects)
+	}
+	// string "ext"
+	o = append(o, 0xa3, 0x65, 0x78, 0x74)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Extensions)))
+	for za0003, za0004 := range z.Extensions {
+		o = msgp.AppendString(o, za0003)
+		// map header, size 2
+		// string "sz"
+		o = append(o, 0x82, 0xa2, 0x73, 0x7a)
+		o = msgp.AppendUint64(o, za0004.Size)
+		// string "os"
+		o = append(o, 0xa2, 0x6f, 0x73)
+		o = msgp.AppendUint64(o, za0004.Objects)
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *allContentStats) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ct":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ContentTypes")
+				return
+			}
+			if z.ContentTypes == nil {
+				z.ContentTypes = make(map[string]contentStats, zb0002)
+			} else if len(z.ContentTypes) > 0 {
+				for key := range z.ContentTypes {
+					delete(z.ContentTypes, key)
+				}
+			}
+			for zb0002 > 0 {
+				var za0001 string
+				var za0002 contentStats
+				zb0002--
+				za0001, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ContentTypes")
+					return
+				}
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ContentTypes", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "ContentTypes", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "sz":
+						za0002.Size, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001, "Size")
+							return
+						}
+					case "os":
+						za0002.Objects, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001, "Objects")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "ContentTypes", za0001)
+							return
+						}
+					}
+				}
+				z.ContentTypes[za0001] = za0002
+			}
+		case "ext":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Extensions")
+				return
+			}
+			if z.Extensions == nil {
+				z.Extensions = make(map[string]contentStats, zb0004)
+			} else if len(z.Extensions) > 0 {
+				for key := range z.Extensions {
+					delete(z.Extensions, key)
+				}
+			}
+			for zb0004 > 0 {
+				var za0003 string
+				var za0004 contentStats
+				zb0004--
+				za0003, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Extensions")
+					return
+				}
+				var zb0005 uint32
+				zb0005, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Extensions", za0003)
+					return
+				}
+				for zb0005 > 0 {
+					zb0005--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Extensions", za0003)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "sz":
+						za0004.Size, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003, "Size")
+							return
+						}
+					case "os":
+						za0004.Objects, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003, "Objects")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Extensions", za0003)
+							return
+						}
+					}
+				}
+				z.Extensions[za0003] = za0004
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *allContentStats) Msgsize() (s int) {
+	s = 1 + 3 + msgp.MapHeaderSize
+	if z.ContentTypes != nil {
+		for za0001, za0002 := range z.ContentTypes {
+			_ = za0002
+			s += msgp.StringPrefixSize + len(za0001) + 1 + 3 + msgp.Uint64Size + 3 + msgp.Uint64Size
+		}
+	}
+	s += 4 + msgp.MapHeaderSize
+	if z.Extensions != nil {
+		for za0003, za0004 := range z.Extensions {
+			_ = za0004
+			s += msgp.StringPrefixSize + len(za0003) + 1 + 3 + msgp.Uint64Size + 3 + msgp.Uint64Size
+		}
+	}
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *allTierStats) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
@@ -284,6 +713,290 @@ func (z *allTierStats) Msgsize() (s int) {
 	return
 }
 
+// DecodeMsg implements msgp.Decodable
+func (z *coldSizes) DecodeMsg(dc *msgp.Reader) (err error) {
+	var zb0001 uint32
+	zb0001, err = dc.ReadArrayHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(coldSizesLen) {
+		err = msgp.ArrayError{Wanted: uint32(coldSizesLen), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], err = dc.ReadUint64()
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *coldSizes) EncodeMsg(en *msgp.Writer) (err error) {
+	err = en.WriteArrayHeader(uint32(coldSizesLen))
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for za0001 := range z {
+		err = en.WriteUint64(z[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *coldSizes) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	o = msgp.AppendArrayHeader(o, uint32(coldSizesLen))
+	for za0001 := range z {
+		o = msgp.AppendUint64(o, z[za0001])
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *coldSizes) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadArrayHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(coldSizesLen) {
+		err = msgp.ArrayError{Wanted: uint32(coldSizesLen), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], bts, err = msgp.ReadUint64Bytes(bts)
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *coldSizes) Msgsize() (s int) {
+	s = msgp.ArrayHeaderSize + (coldSizesLen * (msgp.Uint64Size))
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *compressedSizes) DecodeMsg(dc *msgp.Reader) (err error) {
+	var zb0001 uint32
+	zb0001, err = dc.ReadArrayHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(compressedSizesLen) {
+		err = msgp.ArrayError{Wanted: uint32(compressedSizesLen), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], err = dc.ReadUint64()
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *compressedSizes) EncodeMsg(en *msgp.Writer) (err error) {
+	err = en.WriteArrayHeader(uint32(compressedSizesLen))
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for za0001 := range z {
+		err = en.WriteUint64(z[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *compressedSizes) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	o = msgp.AppendArrayHeader(o, uint32(compressedSizesLen))
+	for za0001 := range z {
+		o = msgp.AppendUint64(o, z[za0001])
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *compressedSizes) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadArrayHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(compressedSizesLen) {
+		err = msgp.ArrayError{Wanted: uint32(compressedSizesLen), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], bts, err = msgp.ReadUint64Bytes(bts)
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *compressedSizes) Msgsize() (s int) {
+	s = msgp.ArrayHeaderSize + (compressedSizesLen * (msgp.Uint64Size))
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *contentStats) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "sz":
+			z.Size, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "os":
+			z.Objects, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Objects")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z contentStats) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "sz"
+	err = en.Append(0x82, 0xa2, 0x73, 0x7a)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Size")
+		return
+	}
+	// write "os"
+	err = en.Append(0xa2, 0x6f, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Objects)
+	if err != nil {
+		err = msgp.WrapError(err, "Objects")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z contentStats) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "sz"
+	o = append(o, 0x82, 0xa2, 0x73, 0x7a)
+	o = msgp.AppendUint64(o, z.Size)
+	// string "os"
+	o = append(o, 0xa2, 0x6f, 0x73)
+	o = msgp.AppendUint64(o, z.Objects)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *contentStats) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "sz":
+			z.Size, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "os":
+			z.Objects, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Objects")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z contentStats) Msgsize() (s int) {
+	s = 1 + 3 + msgp.Uint64Size + 3 + msgp.Uint64Size
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *dataUsageCache) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
@@ -1808,6 +2521,108 @@ func (z *dataUsageEntry) DecodeMsg(dc *msgp.Reader) (err error) {
 					return
 				}
 			}
+		case "cts":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "ContentStats")
+					return
+				}
+				z.ContentStats = nil
+			} else {
+				if z.ContentStats == nil {
+					z.ContentStats = new(allContentStats)
+				}
+				err = z.ContentStats.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "ContentStats")
+					return
+				}
+			}
+		case "scs":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "StorageClassStats")
+					return
+				}
+				z.StorageClassStats = nil
+			} else {
+				if z.StorageClassStats == nil {
+					z.StorageClassStats = new(allTierStats)
+				}
+				err = z.StorageClassStats.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "StorageClassStats")
+					return
+				}
+			}
+		case "cold":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "ColdSizes")
+					return
+				}
+				z.ColdSizes = nil
+			} else {
+				if z.ColdSizes == nil {
+					z.ColdSizes = new(coldSizes)
+				}
+				var zb0003 uint32
+				zb0003, err = dc.ReadArrayHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "ColdSizes")
+					return
+				}
+				if zb0003 != uint32(coldSizesLen) {
+					err = msgp.ArrayError{Wanted: uint32(coldSizesLen), Got: zb0003}
+					return
+				}
+				for za0002 := range *z.ColdSizes {
+					(*z.ColdSizes)[za0002], err = dc.ReadUint64()
+					if err != nil {
+						err = msgp.WrapError(err, "ColdSizes", za0002)
+						return
+					}
+				}
+			}
+		case "cmp":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "CompressedSizes")
+					return
+				}
+				z.CompressedSizes = nil
+			} else {
+				if z.CompressedSizes == nil {
+					z.CompressedSizes = new(compressedSizes)
+				}
+				var zb0003 uint32
+				zb0003, err = dc.ReadArrayHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "CompressedSizes")
+					return
+				}
+				if zb0003 != uint32(compressedSizesLen) {
+					err = msgp.ArrayError{Wanted: uint32(compressedSizesLen), Got: zb0003}
+					return
+				}
+				for za0002 := range *z.CompressedSizes {
+					(*z.CompressedSizes)[za0002], err = dc.ReadUint64()
+					if err != nil {
+						err = msgp.WrapError(err, "CompressedSizes", za0002)
+						return
+					}
+				}
+			}
+		case "ncs":
+			z.NoncurrentSize, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "NoncurrentSize")
+				return
+			}
 		case "c":
 			z.Compacted, err = dc.ReadBool()
 			if err != nil {
@@ -1828,8 +2643,8 @@ func (z *dataUsageEntry) DecodeMsg(dc *msgp.Reader) (err error) {
 // EncodeMsg implements msgp.Encodable
 func (z *dataUsageEntry) EncodeMsg(en *msgp.Writer) (err error) {
 	// omitempty: check for empty values
-	zb0001Len := uint32(8)
-	var zb0001Mask uint8 /* 8 bits */
+	zb0001Len := uint32(13)
+	var zb0001Mask uint16 /* 13 bits */
 	if z.ReplicationStats == nil {
 		zb0001Len--
 		zb0001Mask |= 0x20
@@ -1838,6 +2653,26 @@ func (z *dataUsageEntry) EncodeMsg(en *msgp.Writer) (err error) {
 		zb0001Len--
 		zb0001Mask |= 0x40
 	}
+	if z.ContentStats == nil {
+		zb0001Len--
+		zb0001Mask |= 0x80
+	}
+	if z.StorageClassStats == nil {
+		zb0001Len--
+		zb0001Mask |= 0x100
+	}
+	if z.ColdSizes == nil {
+		zb0001Len--
+		zb0001Mask |= 0x200
+	}
+	if z.CompressedSizes == nil {
+		zb0001Len--
+		zb0001Mask |= 0x400
+	}
+	if z.NoncurrentSize == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x800
+	}
 	// variable map header, size zb0001Len
 	err = en.Append(0x80 | uint8(zb0001Len))
 	if err != nil {
@@ -1941,6 +2776,108 @@ func (z *dataUsageEntry) EncodeMsg(en *msgp.Writer) (err error) {
 			}
 		}
 	}
+	if (zb0001Mask & 0x80) == 0 { // if not empty
+		// write "cts"
+		err = en.Append(0xa3, 0x63, 0x74, 0x73)
+		if err != nil {
+			return
+		}
+		if z.ContentStats == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			err = z.ContentStats.EncodeMsg(en)
+			if err != nil {
+				err = msgp.WrapError(err, "ContentStats")
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x100) == 0 { // if not empty
+		// write "scs"
+		err = en.Append(0xa3, 0x73, 0x63, 0x73)
+		if err != nil {
+			return
+		}
+		if z.StorageClassStats == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			err = z.StorageClassStats.EncodeMsg(en)
+			if err != nil {
+				err = msgp.WrapError(err, "StorageClassStats")
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x200) == 0 { // if not empty
+		// write "cold"
+		err = en.Append(0xa4, 0x63, 0x6f, 0x6c, 0x64)
+		if err != nil {
+			return
+		}
+		if z.ColdSizes == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			err = en.WriteArrayHeader(uint32(coldSizesLen))
+			if err != nil {
+				err = msgp.WrapError(err, "ColdSizes")
+				return
+			}
+			for za0002 := range *z.ColdSizes {
+				err = en.WriteUint64((*z.ColdSizes)[za0002])
+				if err != nil {
+					err = msgp.WrapError(err, "ColdSizes", za0002)
+					return
+				}
+			}
+		}
+	}
+	if (zb0001Mask & 0x400) == 0 { // if not empty
+		// write "cmp"
+		err = en.Append(0xa3, 0x63, 0x6d, 0x70)
+		if err != nil {
+			return
+		}
+		if z.CompressedSizes == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			err = en.WriteArrayHeader(uint32(compressedSizesLen))
+			if err != nil {
+				err = msgp.WrapError(err, "CompressedSizes")
+				return
+			}
+			for za0002 := range *z.CompressedSizes {
+				err = en.WriteUint64((*z.CompressedSizes)[za0002])
+				if err != nil {
+					err = msgp.WrapError(err, "CompressedSizes", za0002)
+					return
+				}
+			}
+		}
+	}
+	if (zb0001Mask & 0x800) == 0 { // if not empty
+		// write "ncs"
+		err = en.Append(0xa3, 0x6e, 0x63, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt64(z.NoncurrentSize)
+		if err != nil {
+			err = msgp.WrapError(err, "NoncurrentSize")
+			return
+		}
+	}
 	// write "c"
 	err = en.Append(0xa1, 0x63)
 	if err != nil {
@@ -1958,8 +2895,8 @@ func (z *dataUsageEntry) EncodeMsg(en *msgp.Writer) (err error) {
 func (z *dataUsageEntry) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
 	// omitempty: check for empty values
-	zb0001Len := uint32(8)
-	var zb0001Mask uint8 /* 8 bits */
+	zb0001Len := uint32(13)
+	var zb0001Mask uint16 /* 13 bits */
 	if z.ReplicationStats == nil {
 		zb0001Len--
 		zb0001Mask |= 0x20
@@ -1968,6 +2905,26 @@ func (z *dataUsageEntry) MarshalMsg(b []byte) (o []byte, err error) {
 		zb0001Len--
 		zb0001Mask |= 0x40
 	}
+	if z.ContentStats == nil {
+		zb0001Len--
+		zb0001Mask |= 0x80
+	}
+	if z.StorageClassStats == nil {
+		zb0001Len--
+		zb0001Mask |= 0x100
+	}
+	if z.ColdSizes == nil {
+		zb0001Len--
+		zb0001Mask |= 0x200
+	}
+	if z.CompressedSizes == nil {
+		zb0001Len--
+		zb0001Mask |= 0x400
+	}
+	if z.NoncurrentSize == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x800
+	}
 	// variable map header, size zb0001Len
 	o = append(o, 0x80|uint8(zb0001Len))
 	if zb0001Len == 0 {
@@ -2021,6 +2978,61 @@ func (z *dataUsageEntry) MarshalMsg(b []byte) (o []byte, err error) {
 			}
 		}
 	}
+	if (zb0001Mask & 0x80) == 0 { // if not empty
+		// string "cts"
+		o = append(o, 0xa3, 0x63, 0x74, 0x73)
+		if z.ContentStats == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o, err = z.ContentStats.MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "ContentStats")
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x100) == 0 { // if not empty
+		// string "scs"
+		o = append(o, 0xa3, 0x73, 0x63, 0x73)
+		if z.StorageClassStats == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o, err = z.StorageClassStats.MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "StorageClassStats")
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x200) == 0 { // if not empty
+		// string "cold"
+		o = append(o, 0xa4, 0x63, 0x6f, 0x6c, 0x64)
+		if z.ColdSizes == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o = msgp.AppendArrayHeader(o, uint32(coldSizesLen))
+			for za0002 := range *z.ColdSizes {
+				o = msgp.AppendUint64(o, (*z.ColdSizes)[za0002])
+			}
+		}
+	}
+	if (zb0001Mask & 0x400) == 0 { // if not empty
+		// string "cmp"
+		o = append(o, 0xa3, 0x63, 0x6d, 0x70)
+		if z.CompressedSizes == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o = msgp.AppendArrayHeader(o, uint32(compressedSizesLen))
+			for za0002 := range *z.CompressedSizes {
+				o = msgp.AppendUint64(o, (*z.CompressedSizes)[za0002])
+			}
+		}
+	}
+	if (zb0001Mask & 0x800) == 0 { // if not empty
+		// string "ncs"
+		o = append(o, 0xa3, 0x6e, 0x63, 0x73)
+		o = msgp.AppendInt64(o, z.NoncurrentSize)
+	}
 	// string "c"
 	o = append(o, 0xa1, 0x63)
 	o = msgp.AppendBool(o, z.Compacted)
@@ -2121,6 +3133,106 @@ func (z *dataUsageEntry) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					return
 				}
 			}
+		case "cts":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.ContentStats = nil
+			} else {
+				if z.ContentStats == nil {
+					z.ContentStats = new(allContentStats)
+				}
+				bts, err = z.ContentStats.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ContentStats")
+					return
+				}
+			}
+		case "scs":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.StorageClassStats = nil
+			} else {
+				if z.StorageClassStats == nil {
+					z.StorageClassStats = new(allTierStats)
+				}
+				bts, err = z.StorageClassStats.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "StorageClassStats")
+					return
+				}
+			}
+		case "cold":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ColdSizes")
+					return
+				}
+				z.ColdSizes = nil
+			} else {
+				if z.ColdSizes == nil {
+					z.ColdSizes = new(coldSizes)
+				}
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ColdSizes")
+					return
+				}
+				if zb0003 != uint32(coldSizesLen) {
+					err = msgp.ArrayError{Wanted: uint32(coldSizesLen), Got: zb0003}
+					return
+				}
+				for za0002 := range *z.ColdSizes {
+					(*z.ColdSizes)[za0002], bts, err = msgp.ReadUint64Bytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "ColdSizes", za0002)
+						return
+					}
+				}
+			}
+		case "cmp":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "CompressedSizes")
+					return
+				}
+				z.CompressedSizes = nil
+			} else {
+				if z.CompressedSizes == nil {
+					z.CompressedSizes = new(compressedSizes)
+				}
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "CompressedSizes")
+					return
+				}
+				if zb0003 != uint32(compressedSizesLen) {
+					err = msgp.ArrayError{Wanted: uint32(compressedSizesLen), Got: zb0003}
+					return
+				}
+				for za0002 := range *z.CompressedSizes {
+					(*z.CompressedSizes)[za0002], bts, err = msgp.ReadUint64Bytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "CompressedSizes", za0002)
+						return
+					}
+				}
+			}
+		case "ncs":
+			z.NoncurrentSize, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "NoncurrentSize")
+				return
+			}
 		case "c":
 			z.Compacted, bts, err = msgp.ReadBoolBytes(bts)
 			if err != nil {
@@ -2153,7 +3265,31 @@ func (z *dataUsageEntry) Msgsize() (s int) {
 	} else {
 		s += z.AllTierStats.Msgsize()
 	}
-	s += 2 + msgp.BoolSize
+	s += 4
+	if z.ContentStats == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.ContentStats.Msgsize()
+	}
+	s += 4
+	if z.StorageClassStats == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.StorageClassStats.Msgsize()
+	}
+	s += 5
+	if z.ColdSizes == nil {
+		s += msgp.NilSize
+	} else {
+		s += msgp.ArrayHeaderSize + (coldSizesLen * (msgp.Uint64Size))
+	}
+	s += 4
+	if z.CompressedSizes == nil {
+		s += msgp.NilSize
+	} else {
+		s += msgp.ArrayHeaderSize + (compressedSizesLen * (msgp.Uint64Size))
+	}
+	s += 4 + msgp.Int64Size + 2 + msgp.BoolSize
 	return
 }
 
@@ -0,0 +1,163 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	dataUsageSnapshotsObjName = ".usage-snapshots.json"
+	// Number of scanner cycles kept to compute deltas from.
+	dataUsageSnapshotsMax = 24
+)
+
+// dataUsageSnapshot - the usage of all buckets at the end of a scanner
+// cycle.
+type dataUsageSnapshot struct {
+	Time    time.Time                         `json:"time"`
+	Buckets map[string]BucketUsageInfoSummary `json:"buckets"`
+}
+
+// BucketUsageInfoSummary - the size and object count of a bucket.
+type BucketUsageInfoSummary struct {
+	Size    uint64 `json:"size"`
+	Objects uint64 `json:"objects"`
+}
+
+// BucketUsageDelta - the change of the usage of a bucket between two
+// scanner cycles. The changes are net, an object replaced by an object
+// of the same size is no change.
+type BucketUsageDelta struct {
+	Size         uint64 `json:"size"`
+	Objects      uint64 `json:"objects"`
+	SizeDelta    int64  `json:"sizeDelta"`
+	ObjectsDelta int64  `json:"objectsDelta"`
+	Created      bool   `json:"created,omitempty"`
+	Deleted      bool   `json:"deleted,omitempty"`
+}
+
+// DataUsageDelta - the changes of the usage of all buckets from the
+// cycle ending at From to the cycle ending at To.
+type DataUsageDelta struct {
+	From    time.Time                   `json:"from"`
+	To      time.Time                   `json:"to"`
+	Buckets map[string]BucketUsageDelta `json:"buckets"`
+}
+
+func newDataUsageSnapshot(dui DataUsageInfo) dataUsageSnapshot {
+	s := dataUsageSnapshot{
+		Time:    dui.LastUpdate,
+		Buckets: make(map[string]BucketUsageInfoSummary, len(dui.BucketsUsage)),
+	}
+	for bucket, bui := range dui.BucketsUsage {
+		s.Buckets[bucket] = BucketUsageInfoSummary{Size: bui.Size, Objects: bui.ObjectsCount}
+	}
+	return s
+}
+
+func loadDataUsageSnapshots(ctx context.Context, objAPI ObjectLayer) ([]dataUsageSnapshot, error) {
+	data, err := readConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, dataUsageSnapshotsObjName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshots []dataUsageSnapshot
+	if err = json.Unmarshal(data, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// storeDataUsageSnapshot adds the data usage saved by the scanner cycle
+// just completed to the snapshots, dropping the oldest.
+func storeDataUsageSnapshot(ctx context.Context, objAPI ObjectLayer) error {
+	dui, err := loadDataUsageFromBackend(ctx, objAPI)
+	if err != nil || dui.LastUpdate.IsZero() {
+		return err
+	}
+	snapshots, err := loadDataUsageSnapshots(ctx, objAPI)
+	if err != nil {
+		return err
+	}
+	snapshots = append(snapshots, newDataUsageSnapshot(dui))
+	if len(snapshots) > dataUsageSnapshotsMax {
+		snapshots = snapshots[len(snapshots)-dataUsageSnapshotsMax:]
+	}
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, dataUsageSnapshotsObjName), data)
+}
+
+// dataUsageDelta returns the changes from the last snapshot taken at or
+// before since, or the oldest, to the latest. Without since the changes
+// of the latest cycle are returned.
+func dataUsageDelta(snapshots []dataUsageSnapshot, since time.Time) DataUsageDelta {
+	delta := DataUsageDelta{Buckets: make(map[string]BucketUsageDelta)}
+	if len(snapshots) == 0 {
+		return delta
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	to := snapshots[len(snapshots)-1]
+	from := dataUsageSnapshot{Buckets: map[string]BucketUsageInfoSummary{}}
+	switch {
+	case since.IsZero():
+		if len(snapshots) > 1 {
+			from = snapshots[len(snapshots)-2]
+		}
+	default:
+		from = snapshots[0]
+		for _, s := range snapshots {
+			if s.Time.After(since) {
+				break
+			}
+			from = s
+		}
+	}
+	delta.From, delta.To = from.Time, to.Time
+	for bucket, u := range to.Buckets {
+		prev, ok := from.Buckets[bucket]
+		delta.Buckets[bucket] = BucketUsageDelta{
+			Size:         u.Size,
+			Objects:      u.Objects,
+			SizeDelta:    int64(u.Size) - int64(prev.Size),
+			ObjectsDelta: int64(u.Objects) - int64(prev.Objects),
+			Created:      !ok && !from.Time.IsZero(),
+		}
+	}
+	for bucket, prev := range from.Buckets {
+		if _, ok := to.Buckets[bucket]; !ok {
+			delta.Buckets[bucket] = BucketUsageDelta{
+				SizeDelta:    -int64(prev.Size),
+				ObjectsDelta: -int64(prev.Objects),
+				Deleted:      true,
+			}
+		}
+	}
+	return delta
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDataUsageDelta(t *testing.T) {
+	t0 := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	snapshots := []dataUsageSnapshot{
+		{Time: t0.Add(2 * time.Hour), Buckets: map[string]BucketUsageInfoSummary{
+			"a": {Size: 300, Objects: 3},
+			"c": {Size: 10, Objects: 1},
+		}},
+		{Time: t0, Buckets: map[string]BucketUsageInfoSummary{
+			"a": {Size: 100, Objects: 1},
+			"b": {Size: 50, Objects: 5},
+		}},
+		{Time: t0.Add(time.Hour), Buckets: map[string]BucketUsageInfoSummary{
+			"a": {Size: 200, Objects: 2},
+			"b": {Size: 50, Objects: 5},
+		}},
+	}
+
+	delta := dataUsageDelta(snapshots, time.Time{})
+	if !delta.From.Equal(t0.Add(time.Hour)) || !delta.To.Equal(t0.Add(2*time.Hour)) {
+		t.Fatalf("unexpected range %v - %v", delta.From, delta.To)
+	}
+	if d := delta.Buckets["a"]; d.SizeDelta != 100 || d.ObjectsDelta != 1 || d.Size != 300 || d.Created {
+		t.Fatalf("unexpected delta of a %+v", d)
+	}
+	if d := delta.Buckets["b"]; !d.Deleted || d.SizeDelta != -50 || d.ObjectsDelta != -5 {
+		t.Fatalf("unexpected delta of b %+v", d)
+	}
+	if d := delta.Buckets["c"]; !d.Created || d.SizeDelta != 10 {
+		t.Fatalf("unexpected delta of c %+v", d)
+	}
+
+	delta = dataUsageDelta(snapshots, t0.Add(30*time.Minute))
+	if !delta.From.Equal(t0) || delta.Buckets["a"].SizeDelta != 200 {
+		t.Fatalf("unexpected delta since %v: %+v", t0, delta)
+	}
+	// Before the oldest snapshot the oldest is used.
+	delta = dataUsageDelta(snapshots, t0.Add(-time.Hour))
+	if !delta.From.Equal(t0) {
+		t.Fatalf("expected the oldest snapshot, got %v", delta.From)
+	}
+
+	// A single snapshot is all growth, no bucket created.
+	delta = dataUsageDelta([]dataUsageSnapshot{{Time: t0, Buckets: map[string]BucketUsageInfoSummary{
+		"a": {Size: 300, Objects: 3},
+	}}}, time.Time{})
+	if d := delta.Buckets["a"]; d.SizeDelta != 300 || d.Created {
+		t.Fatalf("unexpected delta of a %+v", d)
+	}
+	if len(dataUsageDelta(nil, time.Time{}).Buckets) != 0 {
+		t.Fatal("expected no buckets")
+	}
+}
@@ -62,6 +62,28 @@ type BucketUsageInfo struct {
 	ObjectSizesHistogram map[string]uint64                `json:"objectsSizesHistogram"`
 	ReplicaSize          uint64                           `json:"objectReplicaTotalSize"`
 	ReplicationInfo      map[string]BucketTargetUsageInfo `json:"objectsReplicationInfo"`
+	// Objects by content-type and by file extension.
+	ContentTypes map[string]BucketContentUsage `json:"objectsContentTypes,omitempty"`
+	Extensions   map[string]BucketContentUsage `json:"objectsExtensions,omitempty"`
+	// Objects by storage class, transitioned objects are
+	// reported under their remote tier.
+	StorageClasses map[string]madmin.TierStats `json:"objectsStorageClasses,omitempty"`
+	// Sizes of objects not read for 30, 90 and 365 days, only
+	// reported when access tracking is enabled.
+	NotAccessedSizes map[string]uint64 `json:"objectsNotAccessedSizes,omitempty"`
+	// Sizes of the compressed objects as stored and decompressed, with
+	// the achieved compression ratio.
+	CompressedStoredSize uint64  `json:"objectsCompressedStoredSize,omitempty"`
+	CompressedSize       uint64  `json:"objectsCompressedSize,omitempty"`
+	CompressionRatio     float64 `json:"objectsCompressionRatio,omitempty"`
+	// Size of the noncurrent versions, included in Size.
+	NoncurrentSize uint64 `json:"objectsNoncurrentSize,omitempty"`
+}
+
+// BucketContentUsage - usage of the objects of a content-type or an extension.
+type BucketContentUsage struct {
+	Size         uint64 `json:"size"`
+	ObjectsCount uint64 `json:"objectsCount"`
 }
 
 // DataUsageInfo represents data usage stats of the underlying Object API
@@ -89,6 +111,10 @@ type DataUsageInfo struct {
 
 	// TierStats contains per-tier stats of all configured remote tiers
 	TierStats *allTierStats `json:"tierStats,omitempty"`
+
+	// StorageClassStats contains per storage class stats of all
+	// buckets, transitioned objects are accounted under their tier.
+	StorageClassStats *allTierStats `json:"storageClassStats,omitempty"`
 }
 
 func (dui DataUsageInfo) tierStats() []madmin.TierInfo {
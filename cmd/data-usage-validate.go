@@ -0,0 +1,221 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+var errUsageCacheRebuilding = errors.New("Usage cache of the bucket is already being rebuilt")
+
+// UsageCacheStatus - the integrity of the usage cache of a bucket, or of
+// the bucket totals if Bucket is empty, on an erasure set.
+type UsageCacheStatus struct {
+	Pool        int       `json:"pool"`
+	Set         int       `json:"set"`
+	Bucket      string    `json:"bucket,omitempty"`
+	Missing     bool      `json:"missing,omitempty"`
+	LastUpdate  time.Time `json:"lastUpdate,omitempty"`
+	Entries     int       `json:"entries"`
+	Unreachable int       `json:"unreachable,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// validate checks the entries of d are a tree rooted at name, every
+// child present and referenced once, and returns the number of entries
+// not referenced.
+func (d *dataUsageCache) validate(name string) (unreachable int, err error) {
+	if d.Info.Name != name {
+		return 0, fmt.Errorf("cache of %q found", d.Info.Name)
+	}
+	if len(d.Cache) == 0 {
+		return 0, nil
+	}
+	root := hashPath(name)
+	if _, ok := d.Cache[root.Key()]; !ok {
+		return 0, errors.New("root entry missing")
+	}
+	seen := make(map[string]struct{}, len(d.Cache))
+	queue := []string{root.Key()}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[key]; ok {
+			return 0, fmt.Errorf("entry %q referenced more than once", key)
+		}
+		seen[key] = struct{}{}
+		e, ok := d.Cache[key]
+		if !ok {
+			return 0, fmt.Errorf("entry %q missing", key)
+		}
+		if e.Size < 0 {
+			return 0, fmt.Errorf("entry %q has negative size %d", key, e.Size)
+		}
+		for child := range e.Children {
+			queue = append(queue, child)
+		}
+	}
+	return len(d.Cache) - len(seen), nil
+}
+
+// validateUsageCache reads the usage cache name of the set, reporting all
+// errors load ignores.
+func (er erasureObjects) validateUsageCache(ctx context.Context, bucket string) UsageCacheStatus {
+	status := UsageCacheStatus{Pool: er.poolIndex, Set: er.setIndex, Bucket: bucket}
+	name, cacheName := dataUsageRoot, dataUsageCacheName
+	if bucket != "" {
+		name, cacheName = bucket, pathJoin(bucket, dataUsageCacheName)
+	}
+	r, err := er.GetObjectNInfo(ctx, dataUsageBucket, cacheName, nil, http.Header{}, readLock, ObjectOptions{})
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrBucketNotFound(err) {
+			status.Missing = true
+		} else {
+			status.Error = err.Error()
+		}
+		return status
+	}
+	defer r.Close()
+
+	var d dataUsageCache
+	if err = d.deserialize(r); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.LastUpdate = d.Info.LastUpdate
+	status.Entries = len(d.Cache)
+	status.Unreachable, err = d.validate(name)
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// validateUsageCaches validates the usage caches of bucket on all sets,
+// or those of the bucket totals and all buckets if bucket is empty.
+func (z *erasureServerPools) validateUsageCaches(ctx context.Context, bucket string) ([]UsageCacheStatus, error) {
+	buckets := []string{bucket}
+	if bucket == "" {
+		infos, err := z.ListBuckets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		buckets = []string{""}
+		for _, info := range infos {
+			buckets = append(buckets, info.Name)
+		}
+	}
+	statuses := []UsageCacheStatus{}
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
+			for _, bucket := range buckets {
+				statuses = append(statuses, set.validateUsageCache(ctx, bucket))
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// rebuildUsageCache scans bucket on the set from scratch and replaces
+// its usage cache, without healing. The bucket totals of the set are
+// updated from the new cache by the next scanner cycle.
+func (er erasureObjects) rebuildUsageCache(ctx context.Context, bucket string) error {
+	disks, _ := er.getOnlineDisksWithHealing()
+	if len(disks) == 0 {
+		return errErasureReadQuorum
+	}
+	var diskIDs []string
+	for _, disk := range disks {
+		if id, _ := disk.GetDiskID(); id != "" {
+			diskIDs = append(diskIDs, id)
+		}
+	}
+	cache := dataUsageCache{
+		Info: dataUsageCacheInfo{
+			Name:        bucket,
+			NextCycle:   uint32(intDataUpdateTracker.current()),
+			SkipHealing: true,
+		},
+		Disks: diskIDs,
+	}
+	updates := make(chan dataUsageEntry, 1)
+	go func() {
+		for range updates {
+		}
+	}()
+	cache, err := disks[0].NSScanner(ctx, cache, updates)
+	if err != nil {
+		return err
+	}
+	return cache.save(ctx, er, pathJoin(bucket, dataUsageCacheName))
+}
+
+// usageCacheRebuilds holds the buckets whose usage caches are being
+// rebuilt by this node.
+var usageCacheRebuilds = struct {
+	sync.Mutex
+	buckets map[string]struct{}
+}{buckets: make(map[string]struct{})}
+
+// rebuildUsageCaches starts rebuilding the usage caches of bucket on all
+// sets in the background, returning the number of sets.
+func (z *erasureServerPools) rebuildUsageCaches(ctx context.Context, bucket string) (int, error) {
+	if _, err := z.GetBucketInfo(ctx, bucket); err != nil {
+		return 0, err
+	}
+	usageCacheRebuilds.Lock()
+	if _, ok := usageCacheRebuilds.buckets[bucket]; ok {
+		usageCacheRebuilds.Unlock()
+		return 0, errUsageCacheRebuilding
+	}
+	usageCacheRebuilds.buckets[bucket] = struct{}{}
+	usageCacheRebuilds.Unlock()
+
+	var sets []*erasureObjects
+	for _, pool := range z.currentPools() {
+		sets = append(sets, pool.sets...)
+	}
+	go func() {
+		defer func() {
+			usageCacheRebuilds.Lock()
+			delete(usageCacheRebuilds.buckets, bucket)
+			usageCacheRebuilds.Unlock()
+		}()
+		var wg sync.WaitGroup
+		for _, set := range sets {
+			wg.Add(1)
+			go func(set *erasureObjects) {
+				defer wg.Done()
+				if err := set.rebuildUsageCache(GlobalContext, bucket); err != nil {
+					logger.LogIf(GlobalContext, fmt.Errorf("Unable to rebuild the usage cache of bucket %s on pool %d set %d: %w",
+						bucket, set.poolIndex+1, set.setIndex+1, err))
+				}
+			}(set)
+		}
+		wg.Wait()
+		logger.Info("Rebuilt the usage cache of bucket %s", bucket)
+	}()
+	return len(sets), nil
+}
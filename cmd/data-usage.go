@@ -40,6 +40,9 @@ const (
 // storeDataUsageInBackend will store all objects sent on the gui channel until closed.
 func storeDataUsageInBackend(ctx context.Context, objAPI ObjectLayer, dui <-chan DataUsageInfo) {
 	for dataUsageInfo := range dui {
+		logger.LogIf(ctx, globalNamespacePressure.evaluateObjects(dataUsageInfo.ObjectsTotalCount), logger.Application)
+		evaluateSoftQuotas(ctx, dataUsageInfo)
+
 		var json = jsoniter.ConfigCompatibleWithStandardLibrary
 		dataUsageJSON, err := json.Marshal(dataUsageInfo)
 		if err != nil {
@@ -71,7 +74,7 @@ func loadPrefixUsageFromBackend(ctx context.Context, objAPI ObjectLayer, bucket
 	cache := dataUsageCache{}
 
 	m := make(map[string]uint64)
-	for _, pool := range z.serverPools {
+	for _, pool := range z.currentPools() {
 		for _, er := range pool.sets {
 			// Load bucket usage prefixes
 			if err := cache.load(ctx, er, bucket+slashSeparator+dataUsageCacheName); err == nil {
@@ -94,6 +97,59 @@ func loadPrefixUsageFromBackend(ctx context.Context, objAPI ObjectLayer, bucket
 	return m, nil
 }
 
+// loadColdUsageFromBackend returns the sizes of objects not accessed
+// recently in bucket and in each of its top level prefixes.
+func loadColdUsageFromBackend(ctx context.Context, objAPI ObjectLayer, bucket string) (ColdDataReport, error) {
+	report := ColdDataReport{
+		Bucket:   bucket,
+		Tracking: globalAccessTracker.isEnabled(),
+		Prefixes: make(map[string]ColdPrefixUsage),
+	}
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return report, nil
+	}
+
+	var total coldSizes
+	cache := dataUsageCache{}
+	for _, pool := range z.currentPools() {
+		for _, er := range pool.sets {
+			if err := cache.load(ctx, er, bucket+slashSeparator+dataUsageCacheName); err != nil {
+				continue
+			}
+			root := cache.find(bucket)
+			if root == nil {
+				continue
+			}
+			flat := cache.flatten(*root)
+			report.Size += uint64(flat.Size)
+			if flat.ColdSizes != nil {
+				total.merge(*flat.ColdSizes)
+			}
+
+			for id, usageInfo := range cache.flattenChildrens(*root) {
+				prefix := decodeDirObject(strings.TrimPrefix(id, bucket+slashSeparator))
+				pu := report.Prefixes[prefix]
+				pu.Size += uint64(usageInfo.Size)
+				if usageInfo.ColdSizes != nil {
+					if pu.coldSizes == nil {
+						pu.coldSizes = &coldSizes{}
+					}
+					pu.coldSizes.merge(*usageInfo.ColdSizes)
+				}
+				report.Prefixes[prefix] = pu
+			}
+		}
+	}
+
+	report.NotAccessed = total.toMap()
+	for prefix, pu := range report.Prefixes {
+		pu.NotAccessed = pu.coldSizes.toMap()
+		report.Prefixes[prefix] = pu
+	}
+	return report, nil
+}
+
 func loadDataUsageFromBackend(ctx context.Context, objAPI ObjectLayer) (DataUsageInfo, error) {
 	r, err := objAPI.GetObjectNInfo(ctx, dataUsageBucket, dataUsageObjName, nil, http.Header{}, readLock, ObjectOptions{})
 	if err != nil {
@@ -26,7 +26,10 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/minio/madmin-go"
 )
 
 type usageTestFile struct {
@@ -633,3 +636,163 @@ func equalAsJSON(a, b interface{}) bool {
 	}
 	return bytes.Equal(aj, bj)
 }
+
+func TestDataUsageContentStats(t *testing.T) {
+	objects := []ObjectInfo{
+		{Name: "videos/a.MP4", ContentType: "video/mp4", Size: 1000},
+		{Name: "videos/b.mp4", ContentType: "video/mp4", Size: 2000},
+		{Name: "tables/c.parquet", ContentType: "application/octet-stream", Size: 300},
+		{Name: "notes", ContentType: "text/plain; charset=utf-8", Size: 10},
+	}
+	var d dataUsageCache
+	d.replace("bucket", "", dataUsageEntry{})
+	for _, oi := range objects {
+		var e dataUsageEntry
+		contentType, extension := contentStatsKeys(oi)
+		e.addSizes(sizeSummary{totalSize: oi.Size, contentType: contentType, extension: extension})
+		e.Objects++
+
+		// Entries must survive a round trip through the cache.
+		b, err := e.MarshalMsg(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got dataUsageEntry
+		if _, err = got.UnmarshalMsg(b); err != nil {
+			t.Fatal(err)
+		}
+		d.replace(path.Join("bucket", oi.Name), "bucket", got)
+	}
+
+	bui := d.bucketUsageInfo("bucket")
+	wantTypes := map[string]BucketContentUsage{
+		"video/mp4":                {Size: 3000, ObjectsCount: 2},
+		"application/octet-stream": {Size: 300, ObjectsCount: 1},
+		"text/plain":               {Size: 10, ObjectsCount: 1},
+	}
+	wantExtensions := map[string]BucketContentUsage{
+		"mp4":        {Size: 3000, ObjectsCount: 2},
+		"parquet":    {Size: 300, ObjectsCount: 1},
+		noContentKey: {Size: 10, ObjectsCount: 1},
+	}
+	if !reflect.DeepEqual(bui.ContentTypes, wantTypes) {
+		t.Errorf("expected content types %v, got %v", wantTypes, bui.ContentTypes)
+	}
+	if !reflect.DeepEqual(bui.Extensions, wantExtensions) {
+		t.Errorf("expected extensions %v, got %v", wantExtensions, bui.Extensions)
+	}
+}
+
+func TestDataUsageStorageClassStats(t *testing.T) {
+	var empty dataUsageEntry
+	empty.addSizes(sizeSummary{totalSize: 1})
+	if empty.StorageClassStats != nil {
+		t.Errorf("expected no storage class stats, got %#v", empty.StorageClassStats)
+	}
+
+	var d dataUsageCache
+	d.replace("bucket", "", dataUsageEntry{})
+	summaries := map[string]map[string]tierStats{
+		"bucket/a": {"STANDARD": {TotalSize: 100, NumVersions: 2, NumObjects: 1}},
+		"bucket/b": {"REDUCED_REDUNDANCY": {TotalSize: 10, NumVersions: 1, NumObjects: 1}},
+		"bucket/c": {"STANDARD": {TotalSize: 5, NumVersions: 1}, "WARM": {TotalSize: 50, NumVersions: 1, NumObjects: 1}},
+	}
+	for name, sc := range summaries {
+		var e dataUsageEntry
+		e.addSizes(sizeSummary{storageClasses: sc})
+		d.replace(name, "bucket", e)
+	}
+
+	want := map[string]madmin.TierStats{
+		"STANDARD":           {TotalSize: 105, NumVersions: 3, NumObjects: 1},
+		"REDUCED_REDUNDANCY": {TotalSize: 10, NumVersions: 1, NumObjects: 1},
+		"WARM":               {TotalSize: 50, NumVersions: 1, NumObjects: 1},
+	}
+	if got := d.bucketUsageInfo("bucket").StorageClasses; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected storage classes %v, got %v", want, got)
+	}
+	dui := d.dui("bucket", []BucketInfo{{Name: "bucket"}})
+	if dui.StorageClassStats == nil || len(dui.StorageClassStats.Tiers) != 3 {
+		t.Errorf("expected totals for 3 storage classes, got %#v", dui.StorageClassStats)
+	}
+}
+
+func TestDataUsageColdSizes(t *testing.T) {
+	var d dataUsageCache
+	d.replace("bucket", "", dataUsageEntry{})
+	today := accessDay(UTCNow())
+	lastAccess := map[string]int64{
+		"bucket/a": today,
+		"bucket/b": today - 45,
+		"bucket/c": today - 400,
+	}
+	for name, day := range lastAccess {
+		cs := coldSizesOf(100, day)
+		var e dataUsageEntry
+		e.addSizes(sizeSummary{totalSize: 100, coldSizes: &cs})
+		d.replace(name, "bucket", e)
+	}
+
+	want := map[string]uint64{"30d": 200, "90d": 100, "365d": 100}
+	if got := d.bucketUsageInfo("bucket").NotAccessedSizes; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected not accessed sizes %v, got %v", want, got)
+	}
+
+	var untracked dataUsageCache
+	untracked.replace("bucket", "", dataUsageEntry{})
+	untracked.replace("bucket/a", "bucket", dataUsageEntry{Size: 100, Objects: 1})
+	if got := untracked.bucketUsageInfo("bucket").NotAccessedSizes; got != nil {
+		t.Errorf("expected no not accessed sizes without tracking, got %v", got)
+	}
+}
+
+func TestDataUsageCompressedSizes(t *testing.T) {
+	var d dataUsageCache
+	d.replace("bucket", "", dataUsageEntry{})
+	for name, sizes := range map[string][2]int64{"bucket/a": {100, 400}, "bucket/b": {50, 200}} {
+		var cs compressedSizes
+		cs.add(sizes[0], sizes[1])
+		var e dataUsageEntry
+		e.addSizes(sizeSummary{totalSize: sizes[1], compressedSizes: &cs})
+		d.replace(name, "bucket", e)
+	}
+	d.replace("bucket/c", "bucket", dataUsageEntry{Size: 10, Objects: 1})
+
+	bui := d.bucketUsageInfo("bucket")
+	if bui.CompressedStoredSize != 150 || bui.CompressedSize != 600 || bui.CompressionRatio != 4 {
+		t.Errorf("unexpected compression usage %d/%d ratio %v", bui.CompressedStoredSize, bui.CompressedSize, bui.CompressionRatio)
+	}
+}
+
+func TestDataUsageCacheValidate(t *testing.T) {
+	d := dataUsageCache{Info: dataUsageCacheInfo{Name: "bucket"}, Cache: make(map[string]dataUsageEntry)}
+	if _, err := d.validate("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	d.replace("bucket/dir", "bucket", dataUsageEntry{Size: 10, Objects: 1})
+	d.replace("bucket/dir/sub", "bucket/dir", dataUsageEntry{Size: 5, Objects: 1})
+	if n, err := d.validate("bucket"); err != nil || n != 0 {
+		t.Fatalf("expected a valid cache, got %d unreachable, %v", n, err)
+	}
+	if _, err := d.validate("other"); err == nil {
+		t.Fatal("expected a name mismatch")
+	}
+
+	d.Cache["orphan"] = dataUsageEntry{Size: 1}
+	if n, err := d.validate("bucket"); err != nil || n != 1 {
+		t.Fatalf("expected 1 unreachable entry, got %d, %v", n, err)
+	}
+	delete(d.Cache, "orphan")
+
+	e := d.Cache["bucket/dir/sub"]
+	e.Size = -1
+	d.Cache["bucket/dir/sub"] = e
+	if _, err := d.validate("bucket"); err == nil {
+		t.Fatal("expected a negative size")
+	}
+
+	delete(d.Cache, "bucket/dir/sub")
+	if _, err := d.validate("bucket"); err == nil {
+		t.Fatal("expected a missing entry")
+	}
+}
@@ -0,0 +1,518 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	bucketDedupConfigFile = "dedup.json"
+	bucketDedupReportFile = "dedup-report.json"
+
+	// Internal metadata of an object stored deduplicated, naming the
+	// block holding its content.
+	dedupBlockKey = ReservedMetadataPrefix + "dedup-block"
+
+	defaultDedupMinSize = 1 << 20
+	dedupScanInterval   = 24 * time.Hour
+
+	// Blocks no longer referenced are removed once they have not been
+	// referenced for this long.
+	dedupBlockGrace = 24 * time.Hour
+
+	// Bounds of the duplicate content report of a bucket.
+	maxDedupReportGroups    = 100
+	maxDedupTrackedContents = 500000
+)
+
+var dedupScanLeaderLockTimeout = newDynamicTimeout(30*time.Second, 10*time.Second)
+
+// BucketDedupConfig - configures the duplicate content report of a
+// bucket and, if Store is set, stores the content of new objects at
+// least MinSize large once per bucket, referenced by all objects with
+// the same content. Only single part uploads carrying the SHA256 of
+// their payload, neither encrypted nor compressed, are deduplicated.
+type BucketDedupConfig struct {
+	Store   bool  `json:"store"`
+	MinSize int64 `json:"minSize,omitempty"`
+}
+
+func (c BucketDedupConfig) minSize() int64 {
+	if c.MinSize == 0 {
+		return defaultDedupMinSize
+	}
+	return c.MinSize
+}
+
+func parseBucketDedupConfig(bucket string, data []byte) (*BucketDedupConfig, error) {
+	cfg := &BucketDedupConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.MinSize < 0 {
+		return cfg, fmt.Errorf("Invalid dedup minimum size %d for bucket %s", cfg.MinSize, bucket)
+	}
+	return cfg, nil
+}
+
+// DedupGroup - objects of a bucket sharing the same content.
+type DedupGroup struct {
+	ETag  string `json:"etag"`
+	Size  int64  `json:"size"`
+	Count int    `json:"count"`
+}
+
+// DedupStoreUsage - usage of the deduplicated store of a bucket.
+type DedupStoreUsage struct {
+	References     uint64 `json:"references"`
+	ReferencedSize int64  `json:"referencedSize"`
+	Blocks         uint64 `json:"blocks"`
+	BlockSize      int64  `json:"blockSize"`
+	SavedSize      int64  `json:"savedSize"`
+}
+
+// DedupReport - duplicate content of a bucket, objects are considered
+// duplicates if they have the same ETag and size. Duplicates lists the
+// groups wasting the most space, Truncated is set if the bucket holds
+// too many distinct objects to all be compared.
+type DedupReport struct {
+	Bucket           string          `json:"bucket"`
+	Updated          time.Time       `json:"updated"`
+	Objects          uint64          `json:"objects"`
+	Size             int64           `json:"size"`
+	DuplicateObjects uint64          `json:"duplicateObjects"`
+	DuplicateSize    int64           `json:"duplicateSize"`
+	Duplicates       []DedupGroup    `json:"duplicates,omitempty"`
+	Truncated        bool            `json:"truncated,omitempty"`
+	Store            DedupStoreUsage `json:"store"`
+}
+
+func dedupBlocksPrefix(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "dedup") + SlashSeparator
+}
+
+func dedupBlockPath(bucket, sum string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "dedup", sum[:2], sum)
+}
+
+func dedupReportPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, bucketDedupReportFile)
+}
+
+// bucketDedupConfig returns the dedup configuration of bucket, nil if
+// it has none. Once configured, objects of the bucket may reference
+// blocks, disabling the store only stops deduplicating new objects.
+func bucketDedupConfig(bucket string) *BucketDedupConfig {
+	if isMinioMetaBucketName(bucket) {
+		return nil
+	}
+	cfg, _ := globalBucketMetadataSys.GetDedupConfig(bucket)
+	return cfg
+}
+
+// dedupBlock returns the block holding the content of an object stored
+// deduplicated.
+func (o ObjectInfo) dedupBlock() (string, bool) {
+	sum, ok := o.UserDefined[dedupBlockKey]
+	return sum, ok
+}
+
+// canDedup returns whether the content of data can be stored in a block.
+func canDedup(cfg *BucketDedupConfig, data *PutObjReader, opts ObjectOptions) bool {
+	if cfg == nil || !cfg.Store || data.Size() < cfg.minSize() {
+		return false
+	}
+	if _, ok := crypto.IsEncrypted(opts.UserDefined); ok {
+		return false
+	}
+	if _, ok := opts.UserDefined[ReservedMetadataPrefix+"compression"]; ok {
+		return false
+	}
+	// The SHA256 names the block, it is verified while reading data.
+	return len(data.Reader.SHA256()) != 0
+}
+
+// putDedupObject stores the content of data in the block named by its
+// SHA256 unless it already exists, and writes object as an empty
+// object referencing the block.
+func (z *erasureServerPools) putDedupObject(ctx context.Context, bucket, object string, data *PutObjReader, opts ObjectOptions) (ObjectInfo, error) {
+	sum := data.Reader.SHA256HexString()
+	block := dedupBlockPath(bucket, sum)
+	size := data.Size()
+
+	// Serializes the creation and reuse of a block with its removal.
+	lk := z.NewNSLock(minioMetaBucket, block+".ref")
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	// The object is charged its size, also when its content is shared.
+	if err = enforceBucketQuota(ctx, bucket, size); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	bi, err := z.GetObjectInfo(ctx, minioMetaBucket, block, ObjectOptions{})
+	reused := err == nil && bi.Size == size
+	switch {
+	case reused:
+		// Read the content anyways to verify its SHA256.
+		if _, err = io.Copy(ioutil.Discard, data); err != nil {
+			return ObjectInfo{}, err
+		}
+	case err == nil, isErrObjectNotFound(err):
+		if _, err = z.PutObject(ctx, minioMetaBucket, block, data, ObjectOptions{}); err != nil {
+			return ObjectInfo{}, err
+		}
+	default:
+		return ObjectInfo{}, err
+	}
+
+	metadata := make(map[string]string, len(opts.UserDefined)+3)
+	for k, v := range opts.UserDefined {
+		metadata[k] = v
+	}
+	metadata[dedupBlockKey] = sum
+	metadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(size, 10)
+	metadata["etag"] = data.MD5CurrentHexString()
+
+	hr, err := hash.NewReader(bytes.NewReader(nil), 0, "", "", 0)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	popts := opts
+	popts.UserDefined = metadata
+	objInfo, err := z.PutObject(ctx, bucket, object, NewPutObjReader(hr), popts)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// The modification time of a block is when it was last referenced.
+	if reused {
+		_, err = z.PutObjectMetadata(ctx, minioMetaBucket, block, ObjectOptions{MTime: UTCNow()})
+		logger.LogIf(ctx, err)
+	}
+	return objInfo, nil
+}
+
+// getDedupObjectNInfo returns a reader of the block holding the content
+// of object, nil if object is not stored deduplicated.
+func (z *erasureServerPools) getDedupObjectNInfo(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, opts ObjectOptions) (*GetObjectReader, error) {
+	objInfo, err := z.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		// Errors are reported reading the object itself.
+		return nil, nil
+	}
+	sum, ok := objInfo.dedupBlock()
+	if !ok {
+		return nil, nil
+	}
+	if opts.CheckPrecondFn != nil && opts.CheckPrecondFn(objInfo) {
+		return nil, PreConditionFailed{}
+	}
+	size, err := objInfo.GetActualSize()
+	if err != nil {
+		return nil, err
+	}
+
+	gr, err := z.GetObjectNInfo(ctx, minioMetaBucket, dedupBlockPath(bucket, sum), rs, h, readLock, ObjectOptions{})
+	if err != nil {
+		if isErrObjectNotFound(err) {
+			return nil, toObjectErr(errFileCorrupt, bucket, object)
+		}
+		return nil, err
+	}
+	objInfo.Size = size
+	gr.ObjInfo = objInfo
+	return gr, nil
+}
+
+func initDedupScan(ctx context.Context, objAPI ObjectLayer) {
+	go runDedupScan(ctx, objAPI)
+}
+
+// runDedupScan periodically reports the duplicate content of buckets
+// with a dedup configuration and removes their unreferenced blocks,
+// only the node holding the leader lock does the work.
+func runDedupScan(ctx context.Context, objAPI ObjectLayer) {
+	locker := objAPI.NewNSLock(minioMetaBucket, "runDedupScan.lock")
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		lkctx, err := locker.GetLock(ctx, dedupScanLeaderLockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Duration(r.Float64() * float64(dedupScanInterval)))
+			continue
+		}
+		ctx = lkctx.Context()
+		defer lkctx.Cancel()
+		break
+		// No unlock for "leader" lock.
+	}
+
+	scanTimer := time.NewTimer(scannerCycle.Get())
+	defer scanTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-scanTimer.C:
+			buckets, err := objAPI.ListBuckets(ctx)
+			if err != nil {
+				logger.LogIf(ctx, err)
+			}
+			for _, bucket := range buckets {
+				cfg := bucketDedupConfig(bucket.Name)
+				if cfg == nil {
+					continue
+				}
+				report, err := loadDedupReport(ctx, objAPI, bucket.Name)
+				if err == nil && time.Since(report.Updated) < dedupScanInterval {
+					continue
+				}
+				jctx, release, err := globalJobScheduler.acquire(ctx, jobDedup)
+				if err != nil {
+					return
+				}
+				if err = scanBucketDedup(jctx, objAPI, bucket.Name, cfg); jctx.Err() == nil {
+					logger.LogIf(ctx, err)
+				}
+				release()
+			}
+			scanTimer.Reset(scannerCycle.Get())
+		}
+	}
+}
+
+// scanBucketDedup lists all object versions of bucket to report its
+// duplicate content and count the references to its blocks, removing
+// the blocks not referenced anymore. Trashed objects and snapshots hold
+// references too. Any listing failing aborts the scan, as references
+// missed would get blocks removed.
+func scanBucketDedup(ctx context.Context, objAPI ObjectLayer, bucket string, cfg *BucketDedupConfig) error {
+	type content struct {
+		etag string
+		size int64
+	}
+
+	start := UTCNow()
+	report := DedupReport{Bucket: bucket, Updated: start}
+	counts := make(map[content]int)
+	refs := make(map[string]int)
+
+	marker, versionMarker := "", ""
+	for {
+		lo, err := objAPI.ListObjectVersions(ctx, bucket, "", marker, versionMarker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range lo.Objects {
+			if oi.DeleteMarker {
+				continue
+			}
+			sum, deduped := oi.dedupBlock()
+			if deduped {
+				refs[sum]++
+			}
+			size, err := oi.GetActualSize()
+			if err != nil {
+				continue
+			}
+			report.Objects++
+			report.Size += size
+
+			if deduped {
+				report.Store.References++
+				report.Store.ReferencedSize += size
+				continue
+			}
+			// The ETag of encrypted objects does not identify their content.
+			if _, encrypted := crypto.IsEncrypted(oi.UserDefined); encrypted || size < cfg.minSize() {
+				continue
+			}
+			k := content{etag: oi.ETag, size: size}
+			if _, ok := counts[k]; !ok && len(counts) >= maxDedupTrackedContents {
+				report.Truncated = true
+				continue
+			}
+			counts[k]++
+		}
+		if !lo.IsTruncated {
+			break
+		}
+		marker, versionMarker = lo.NextMarker, lo.NextVersionIDMarker
+	}
+	if err := countDedupTrashRefs(ctx, objAPI, bucket, refs); err != nil {
+		return err
+	}
+	if err := countDedupSnapshotRefs(ctx, objAPI, bucket, refs); err != nil {
+		return err
+	}
+
+	for k, n := range counts {
+		if n < 2 {
+			continue
+		}
+		report.DuplicateObjects += uint64(n - 1)
+		report.DuplicateSize += int64(n-1) * k.size
+		report.Duplicates = append(report.Duplicates, DedupGroup{ETag: k.etag, Size: k.size, Count: n})
+	}
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		gi, gj := report.Duplicates[i], report.Duplicates[j]
+		return int64(gi.Count-1)*gi.Size > int64(gj.Count-1)*gj.Size
+	})
+	if len(report.Duplicates) > maxDedupReportGroups {
+		report.Duplicates = report.Duplicates[:maxDedupReportGroups]
+	}
+
+	var unreferenced []string
+	marker = ""
+	for {
+		lo, err := objAPI.ListObjects(ctx, minioMetaBucket, dedupBlocksPrefix(bucket), marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range lo.Objects {
+			if refs[path.Base(oi.Name)] > 0 {
+				report.Store.Blocks++
+				report.Store.BlockSize += oi.Size
+				continue
+			}
+			if start.Sub(oi.ModTime) > dedupBlockGrace {
+				unreferenced = append(unreferenced, oi.Name)
+			}
+		}
+		if !lo.IsTruncated {
+			break
+		}
+		marker = lo.NextMarker
+	}
+	report.Store.SavedSize = report.Store.ReferencedSize - report.Store.BlockSize
+
+	for _, block := range unreferenced {
+		logger.LogIf(ctx, removeDedupBlock(ctx, objAPI, block, start))
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, dedupReportPath(bucket), data)
+}
+
+// countDedupTrashRefs counts the references to blocks of the objects in
+// the trash of bucket, which may be restored.
+func countDedupTrashRefs(ctx context.Context, objAPI ObjectLayer, bucket string, refs map[string]int) error {
+	marker := ""
+	for {
+		lo, err := objAPI.ListObjects(ctx, trashBucketName(bucket), "", marker, "", maxObjectList)
+		if err != nil {
+			if isErrBucketNotFound(err) {
+				// Nothing was ever trashed in this bucket.
+				return nil
+			}
+			return err
+		}
+		for _, oi := range lo.Objects {
+			if sum, ok := oi.dedupBlock(); ok {
+				refs[sum]++
+			}
+		}
+		if !lo.IsTruncated {
+			return nil
+		}
+		marker = lo.NextMarker
+	}
+}
+
+// countDedupSnapshotRefs counts the references to blocks of the object
+// versions pinned by the snapshots of bucket.
+func countDedupSnapshotRefs(ctx context.Context, objAPI ObjectLayer, bucket string, refs map[string]int) error {
+	snapshots, err := listBucketSnapshots(ctx, objAPI, bucket)
+	if err != nil {
+		return err
+	}
+	for _, info := range snapshots {
+		snapshot, err := loadBucketSnapshot(ctx, objAPI, bucket, info.ID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range snapshot.entries {
+			if entry.Block != "" {
+				refs[entry.Block]++
+			}
+		}
+	}
+	return nil
+}
+
+// removeDedupBlock removes a block unless it was referenced since the
+// references were counted at start.
+func removeDedupBlock(ctx context.Context, objAPI ObjectLayer, block string, start time.Time) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, block+".ref")
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	oi, err := objAPI.GetObjectInfo(ctx, minioMetaBucket, block, ObjectOptions{})
+	if err != nil {
+		if isErrObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if start.Sub(oi.ModTime) <= dedupBlockGrace {
+		return nil
+	}
+	_, err = objAPI.DeleteObject(ctx, minioMetaBucket, block, ObjectOptions{})
+	return err
+}
+
+func loadDedupReport(ctx context.Context, objAPI ObjectLayer, bucket string) (DedupReport, error) {
+	var report DedupReport
+	data, err := readConfig(ctx, objAPI, dedupReportPath(bucket))
+	if err != nil {
+		return report, err
+	}
+	err = json.Unmarshal(data, &report)
+	return report, err
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDedupStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	oldMetadataSys := globalBucketMetadataSys
+	defer func() { globalBucketMetadataSys = oldMetadataSys }()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	meta := newBucketMetadata(bucket)
+	meta.dedupConfig = &BucketDedupConfig{Store: true, MinSize: 1}
+	globalBucketMetadataSys.Set(bucket, meta)
+
+	data := []byte("backup image content")
+	sha := getSHA256Hash(data)
+	for _, object := range []string{"a", "b"} {
+		oi, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", sha), ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := oi.dedupBlock(); !ok || oi.Size != 0 || oi.ETag != getMD5Hash(data) {
+			t.Fatalf("expected %s to reference a block, got %+v", object, oi)
+		}
+	}
+	// Duplicates without a payload checksum are stored as is.
+	other := []byte("unsigned content")
+	for _, object := range []string{"c", "d"} {
+		if _, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(other), int64(len(other)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gr, err := objLayer.GetObjectNInfo(ctx, bucket, "b", &HTTPRangeSpec{Start: 7, End: 11}, nil, readLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "image" {
+		t.Errorf("expected the range of the block, got %q", got)
+	}
+	if size, _ := gr.ObjInfo.GetActualSize(); size != int64(len(data)) {
+		t.Errorf("expected the size of the content, got %d", size)
+	}
+
+	if err = scanBucketDedup(ctx, objLayer, bucket, meta.dedupConfig); err != nil {
+		t.Fatal(err)
+	}
+	report, err := loadDedupReport(ctx, objLayer, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Objects != 4 || report.DuplicateObjects != 1 || report.DuplicateSize != int64(len(other)) {
+		t.Errorf("unexpected duplicates in report %+v", report)
+	}
+	if report.Store.References != 2 || report.Store.Blocks != 1 || report.Store.SavedSize != int64(len(data)) {
+		t.Errorf("unexpected store usage %+v", report.Store)
+	}
+}
+
+func TestDedupScanKeepsTrashedRefs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	oldMetadataSys := globalBucketMetadataSys
+	defer func() { globalBucketMetadataSys = oldMetadataSys }()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = makeBucketTrash(ctx, objLayer, bucket); err != nil {
+		t.Fatal(err)
+	}
+	meta := newBucketMetadata(bucket)
+	meta.dedupConfig = &BucketDedupConfig{Store: true, MinSize: 1}
+	globalBucketMetadataSys.Set(bucket, meta)
+
+	data := []byte("backup image content")
+	sha := getSHA256Hash(data)
+	if _, err = objLayer.PutObject(ctx, bucket, "a", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", sha), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	block := dedupBlockPath(bucket, sha)
+	// Past its grace period the block is removed once unreferenced.
+	if _, err = objLayer.PutObjectMetadata(ctx, minioMetaBucket, block, ObjectOptions{MTime: UTCNow().Add(-2 * dedupBlockGrace)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = trashObject(ctx, objLayer, bucket, "a", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = scanBucketDedup(ctx, objLayer, bucket, meta.dedupConfig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, minioMetaBucket, block, ObjectOptions{}); err != nil {
+		t.Fatalf("expected the block of the trashed object to be kept, got %v", err)
+	}
+
+	if _, err = objLayer.DeleteObject(ctx, trashBucketName(bucket), "a", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = scanBucketDedup(ctx, objLayer, bucket, meta.dedupConfig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, minioMetaBucket, block, ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected the unreferenced block to be removed, got %v", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -329,6 +330,15 @@ func (c *diskCache) purge(ctx context.Context) {
 		cacheDir := pathJoin(c.dir, name)
 		meta, _, numHits, err := c.statCachedMeta(ctx, cacheDir)
 		if err != nil {
+			// With writeback commits the entry may hold the only copy of
+			// an object not committed yet, whose commit status is not
+			// known without its meta.
+			if c.commitWriteback {
+				if !os.IsNotExist(err) {
+					logger.LogIf(ctx, fmt.Errorf("cache entry %s kept, its meta is unreadable: %w", cacheDir, err))
+				}
+				return nil
+			}
 			// delete any partially filled cache entry left behind.
 			removeAll(cacheDir)
 			// Proceed to next file.
@@ -539,20 +549,27 @@ func (c *diskCache) saveMetadata(ctx context.Context, bucket, object string, met
 	if err := os.MkdirAll(cachedPath, 0777); err != nil {
 		return err
 	}
-	f, err := os.OpenFile(metaPath, os.O_RDWR|os.O_CREATE, 0666)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
 	m := &cacheMeta{
 		Version: cacheMetaVersion,
 		Bucket:  bucket,
 		Object:  object,
 	}
-	if err := jsonLoad(f, m); err != nil && err != io.EOF {
+	if f, err := os.Open(metaPath); err == nil {
+		err = jsonLoad(f, m)
+		f.Close()
+		if err != nil && err != io.EOF {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
 		return err
 	}
+	if incHitsOnly {
+		// Uploads not committed yet are only ever rewritten by their
+		// commit, never for a hit.
+		if status, ok := m.Meta[writeBackStatusHeader]; ok && status != CommitComplete.String() {
+			return nil
+		}
+	}
 	// increment hits
 	if rs != nil {
 		// rsFileName gets set by putRange. Check for blank values here
@@ -588,7 +605,48 @@ func (c *diskCache) saveMetadata(ctx context.Context, bucket, object string, met
 	m.Hits++
 
 	m.Checksum = CacheChecksumInfoV1{Algorithm: HighwayHash256S.String(), Blocksize: cacheBlkSize}
-	return jsonSave(f, m)
+	return c.writeMetadata(cachedPath, m)
+}
+
+// writeMetadata replaces the cache.json of the cache entry in cachedPath
+// by m. It is written aside and renamed over, since a truncated meta gets
+// the entry removed. With writeback commits the meta holds the commit
+// status of the only copy of the object, and is synced.
+func (c *diskCache) writeMetadata(cachedPath string, m *cacheMeta) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	metaPath := pathJoin(cachedPath, cacheMetaJSONFile)
+	tmpPath := metaPath + "." + mustGetUUID()
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil && c.commitWriteback {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, metaPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if c.commitWriteback {
+		// Persist the rename.
+		d, err := os.Open(cachedPath)
+		if err != nil {
+			return err
+		}
+		defer d.Close()
+		return d.Sync()
+	}
+	return nil
 }
 
 func getCacheSHADir(dir, bucket, object string) string {
@@ -655,6 +713,12 @@ func (c *diskCache) bitrotWriteToCache(cachePath, fileName string, reader io.Rea
 			break
 		}
 	}
+	// The cache holds the only copy of objects until committed.
+	if c.commitWriteback {
+		if err = f.Sync(); err != nil {
+			return 0, "", err
+		}
+	}
 
 	return bytesWritten, base64.StdEncoding.EncodeToString(md5Hash.Sum(nil)), nil
 }
@@ -1022,7 +1086,7 @@ func (c *diskCache) scanCacheWritebackFailures(ctx context.Context) {
 			return nil
 		}
 
-		objInfo := meta.ToObjectInfo("", "")
+		objInfo := meta.ToObjectInfo(meta.Bucket, meta.Object)
 		status, ok := objInfo.UserDefined[writeBackStatusHeader]
 		if !ok || status == CommitComplete.String() {
 			return nil
@@ -64,6 +64,13 @@ func (s cacheCommitStatus) String() string {
 	return string(s)
 }
 
+// isWritebackPending returns whether the cached object oi is not yet
+// committed to the backend, the cache holds its only copy then.
+func isWritebackPending(oi ObjectInfo) bool {
+	st := cacheCommitStatus(oi.UserDefined[writeBackStatusHeader])
+	return st == CommitPending || st == CommitFailed
+}
+
 // CacheStorageInfo - represents total, free capacity of
 // underlying cache storage.
 type CacheStorageInfo struct {
@@ -234,6 +241,12 @@ func (c *cacheObjects) GetObjectNInfo(ctx context.Context, bucket, object string
 				cacheObjSize = len
 			}
 		}
+		// Until committed the backend has an older version or none,
+		// the cached object is the latest.
+		if isWritebackPending(cacheReader.ObjInfo) {
+			c.incCacheStats(cacheObjSize)
+			return cacheReader, nil
+		}
 		cc = cacheControlOpts(cacheReader.ObjInfo)
 		if cc != nil && (!cc.isStale(cacheReader.ObjInfo.ModTime) ||
 			cc.onlyIfCached) {
@@ -397,6 +410,10 @@ func (c *cacheObjects) GetObjectInfo(ctx context.Context, bucket, object string,
 	// if cache control setting is valid, avoid HEAD operation to backend
 	cachedObjInfo, _, cerr := dcache.Stat(ctx, bucket, object)
 	if cerr == nil {
+		if isWritebackPending(cachedObjInfo) {
+			c.cacheStats.incHit()
+			return cachedObjInfo, nil
+		}
 		cc = cacheControlOpts(cachedObjInfo)
 		if cc == nil || (cc != nil && !cc.isStale(cachedObjInfo.ModTime)) {
 			// This is a cache hit, mark it so
@@ -717,6 +734,13 @@ func (c *cacheObjects) uploadObject(ctx context.Context, oi ObjectInfo) {
 	}
 	var opts ObjectOptions
 	opts.UserDefined = make(map[string]string)
+	// Commit the metadata of the upload along with the data.
+	for k, v := range cReader.ObjInfo.UserDefined {
+		if strings.HasPrefix(strings.ToLower(k), ReservedMetadataPrefixLower) || k == "content-md5" {
+			continue
+		}
+		opts.UserDefined[k] = v
+	}
 	opts.UserDefined[xhttp.ContentMD5] = oi.UserDefined["content-md5"]
 	objInfo, err := c.InnerPutObjectFn(ctx, oi.Bucket, oi.Name, NewPutObjReader(hashReader), opts)
 	wbCommitStatus := CommitComplete
@@ -738,16 +762,31 @@ func (c *cacheObjects) uploadObject(ctx context.Context, oi ObjectInfo) {
 	dcache.SaveMetadata(ctx, oi.Bucket, oi.Name, meta, objInfo.Size, nil, "", false)
 	if retryCnt > 0 {
 		// slow down retries
-		time.Sleep(time.Second * time.Duration(retryCnt%10+1))
-		c.queueWritebackRetry(oi)
+		time.AfterFunc(time.Second*time.Duration(retryCnt%10+1), func() {
+			c.queueWritebackRetry(oi)
+		})
 	}
 }
 
+// queueWritebackRetry queues the upload of oi, waiting for room in the
+// queue rather than dropping an uncommitted object.
 func (c *cacheObjects) queueWritebackRetry(oi ObjectInfo) {
 	select {
 	case c.wbRetryCh <- oi:
-		c.uploadObject(GlobalContext, oi)
-	default:
+	case <-GlobalContext.Done():
+	}
+}
+
+// writebackRetryLoop uploads the queued objects of pending or failed
+// writeback commits one at a time, sparing the link to the backend.
+func (c *cacheObjects) writebackRetryLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case oi := <-c.wbRetryCh:
+			c.uploadObject(ctx, oi)
+		}
 	}
 }
 
@@ -805,10 +844,7 @@ func newServerCacheObjects(ctx context.Context, config cache.Config) (CacheObjec
 	go c.gc(ctx)
 	if c.commitWriteback {
 		c.wbRetryCh = make(chan ObjectInfo, 10000)
-		go func() {
-			<-GlobalContext.Done()
-			close(c.wbRetryCh)
-		}()
+		go c.writebackRetryLoop(ctx)
 		go c.queuePendingWriteback(ctx)
 	}
 
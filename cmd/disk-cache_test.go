@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"context"
+	"io/ioutil"
 	"testing"
 	"time"
 )
@@ -72,3 +74,68 @@ func TestCacheExclusion(t *testing.T) {
 		}
 	}
 }
+
+// test uncommitted writeback uploads are detected
+func TestIsWritebackPending(t *testing.T) {
+	testCases := []struct {
+		status  string
+		pending bool
+	}{
+		{"", false},
+		{CommitPending.String(), true},
+		{CommitFailed.String(), true},
+		{CommitComplete.String(), false},
+	}
+	for i, testCase := range testCases {
+		oi := ObjectInfo{UserDefined: map[string]string{}}
+		if testCase.status != "" {
+			oi.UserDefined[writeBackStatusHeader] = testCase.status
+		}
+		if pending := isWritebackPending(oi); pending != testCase.pending {
+			t.Errorf("Test %d: expected pending %t, got %t", i+1, testCase.pending, pending)
+		}
+	}
+}
+
+// test hits never rewrite the meta of uncommitted writeback uploads
+func TestSaveMetadataWritebackPending(t *testing.T) {
+	c := &diskCache{dir: t.TempDir(), commitWriteback: true}
+	ctx := context.Background()
+
+	save := func(status string, incHitsOnly bool) {
+		t.Helper()
+		meta := map[string]string{writeBackStatusHeader: status}
+		if err := c.saveMetadata(ctx, "bucket", "object", meta, 10, nil, "", incHitsOnly); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(status string, hits int) {
+		t.Helper()
+		cachedPath := getCacheSHADir(c.dir, "bucket", "object")
+		meta, _, numHits, err := c.statCache(ctx, cachedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Meta[writeBackStatusHeader] != status || numHits != hits {
+			t.Fatalf("expected status %s with %d hits, got %s with %d hits", status, hits, meta.Meta[writeBackStatusHeader], numHits)
+		}
+		// The meta is written aside and renamed over.
+		entries, err := ioutil.ReadDir(cachedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Name() != cacheMetaJSONFile {
+			t.Fatalf("unexpected files in cache entry: %v", entries)
+		}
+	}
+
+	save(CommitPending.String(), false)
+	check(CommitPending.String(), 1)
+	save(CommitPending.String(), true)
+	check(CommitPending.String(), 1)
+
+	save(CommitComplete.String(), false)
+	check(CommitComplete.String(), 2)
+	save(CommitComplete.String(), true)
+	check(CommitComplete.String(), 3)
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+)
+
+// appendObject appends data to object in the pool holding it.
+func (z *erasureServerPools) appendObject(ctx context.Context, bucket, object string, position int64, data *PutObjReader) (ObjectInfo, error) {
+	if err := checkPutObjectArgs(ctx, bucket, object, z); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
+	object = encodeDirObject(object)
+	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return z.currentPools()[idx].getHashedSet(object).appendObject(ctx, bucket, object, position, data)
+}
+
+// appendObject erasure codes data as an additional part of the latest
+// version of object, objects whose data cannot take another part are
+// rewritten whole under the same lock.
+func (er erasureObjects) appendObject(ctx context.Context, bucket, object string, position int64, r *PutObjReader) (ObjectInfo, error) {
+	lk := er.NewNSLock(bucket, object)
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	data := r.Reader
+	storageDisks := er.getDisks()
+
+	// Read metadata associated with the object from all disks.
+	partsMetadata, errs := readAllFileInfo(ctx, storageDisks, bucket, object, "", false)
+
+	_, writeQuorum, err := objectQuorumFromMeta(ctx, partsMetadata, errs, er.defaultParityCount)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	if reducedErr := reduceWriteQuorumErrs(ctx, errs, objectOpIgnoredErrs, writeQuorum); reducedErr == errErasureWriteQuorum {
+		return ObjectInfo{}, toObjectErr(reducedErr, bucket, object)
+	}
+
+	// List all online disks.
+	onlineDisks, modTime, dataDir := listOnlineDisks(storageDisks, partsMetadata, errs)
+
+	// Pick one from the first valid metadata.
+	fi, err := pickValidFileInfo(ctx, partsMetadata, modTime, dataDir, writeQuorum)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	if fi.Deleted {
+		return ObjectInfo{}, toObjectErr(errFileNotFound, bucket, object)
+	}
+	oi := fi.ToObjectInfo(bucket, object)
+	if isQuarantined(oi) {
+		return ObjectInfo{}, errObjectQuarantined
+	}
+	_, encrypted := crypto.IsEncrypted(fi.Metadata)
+	_, dedup := oi.dedupBlock()
+	if encrypted || dedup || oi.IsCompressed() || fi.TransitionStatus != "" {
+		return ObjectInfo{}, NotImplemented{Message: "Appending to encrypted, deduplicated, compressed or transitioned objects is not supported"}
+	}
+	if fi.Size != position {
+		return ObjectInfo{}, AppendPositionMismatch{
+			GenericError: GenericError{Bucket: bucket, Object: object},
+			Size:         fi.Size,
+		}
+	}
+
+	// Inlined data cannot be extended by simply adding a part.
+	if fi.DataDir == "" || fi.InlineData() {
+		return er.rewriteAppendObject(ctx, bucket, object, r)
+	}
+
+	partID := len(fi.Parts) + 1
+	if isMaxPartID(partID) {
+		return ObjectInfo{}, NotImplemented{
+			Message: fmt.Sprintf("Object %s/%s reached the maximum of %d appended parts", bucket, object, globalMaxPartID),
+		}
+	}
+
+	onlineDisks = shuffleDisks(onlineDisks, fi.Erasure.Distribution)
+	partsMetadata = shufflePartsMetadata(partsMetadata, fi.Erasure.Distribution)
+
+	partSuffix := fmt.Sprintf("part.%d", partID)
+	tmpPart := mustGetUUID()
+	tmpPartPath := pathJoin(tmpPart, partSuffix)
+
+	// Delete the temporary part, there is nothing left to delete on success.
+	var online int
+	defer func() {
+		if online != len(onlineDisks) {
+			er.deleteObject(context.Background(), minioMetaTmpBucket, tmpPart, writeQuorum)
+		}
+	}()
+
+	erasure, err := NewErasure(ctx, fi.Erasure.DataBlocks, fi.Erasure.ParityBlocks, fi.Erasure.BlockSize)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	var buffer []byte
+	switch size := data.Size(); {
+	case size == 0:
+		buffer = make([]byte, 1) // Allocate atleast a byte to reach EOF
+	case size >= fi.Erasure.BlockSize || size == -1:
+		buffer = er.bp.Get()
+		defer er.bp.Put(buffer)
+	case size < fi.Erasure.BlockSize:
+		// No need to allocate fully fi.Erasure.BlockSize buffer if the incoming data is smaller.
+		buffer = make([]byte, size, 2*size+int64(fi.Erasure.ParityBlocks+fi.Erasure.DataBlocks-1))
+	}
+
+	if len(buffer) > int(fi.Erasure.BlockSize) {
+		buffer = buffer[:fi.Erasure.BlockSize]
+	}
+	writers := make([]io.Writer, len(onlineDisks))
+	for i, disk := range onlineDisks {
+		if disk == nil {
+			continue
+		}
+		writers[i] = newBitrotWriter(ctx, disk, minioMetaTmpBucket, tmpPartPath, erasure.ShardFileSize(data.Size()), DefaultBitrotAlgorithm, erasure.ShardSize())
+	}
+
+	n, err := erasure.Encode(ctx, data, writers, buffer, writeQuorum)
+	closeBitrotWriters(writers)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// Should return IncompleteBody{} error when reader has fewer bytes
+	// than specified in request header.
+	if n < data.Size() {
+		return ObjectInfo{}, IncompleteBody{Bucket: bucket, Object: object}
+	}
+
+	for i := range writers {
+		if writers[i] == nil {
+			onlineDisks[i] = nil
+		}
+	}
+
+	// Rename temporary part file next to the existing parts.
+	partPath := pathJoin(object, fi.DataDir, partSuffix)
+	onlineDisks, err = rename(ctx, onlineDisks, minioMetaTmpBucket, tmpPartPath, bucket, partPath, false, writeQuorum, nil)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	// The ETag of a single part object is the one of its first part.
+	parts := make([]CompletePart, 0, partID)
+	for i, part := range fi.Parts {
+		if i == 0 && part.ETag == "" {
+			part.ETag = fi.Metadata["etag"]
+		}
+		parts = append(parts, CompletePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+	md5hex := r.MD5CurrentHexString()
+	parts = append(parts, CompletePart{PartNumber: partID, ETag: md5hex})
+
+	fi.ModTime = UTCNow()
+	fi.AddObjectPart(partID, md5hex, n, data.ActualSize())
+	fi.Metadata["etag"] = getCompleteMultipartMD5(parts)
+
+	for i, disk := range onlineDisks {
+		if disk == OfflineDisk {
+			continue
+		}
+		partsMetadata[i].Size = fi.Size
+		partsMetadata[i].ModTime = fi.ModTime
+		partsMetadata[i].Parts = fi.Parts
+		partsMetadata[i].Metadata = fi.Metadata
+		partsMetadata[i].Erasure.AddChecksumInfo(ChecksumInfo{
+			PartNumber: partID,
+			Algorithm:  DefaultBitrotAlgorithm,
+			Hash:       bitrotWriterSum(writers[i]),
+		})
+	}
+
+	// Writes update `xl.meta` format for each disk.
+	if onlineDisks, err = writeUniqueFileInfo(ctx, onlineDisks, bucket, object, partsMetadata, writeQuorum); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	online = countOnlineDisks(onlineDisks)
+	if online < len(onlineDisks) {
+		// Disks missing the new part are healed in the background.
+		er.addPartial(bucket, object, fi.VersionID, fi.Size)
+	}
+
+	return fi.ToObjectInfo(bucket, object), nil
+}
+
+// rewriteAppendObject appends data by rewriting the whole object, this
+// is only done for small objects with their data inlined in metadata.
+// The caller must hold the object lock.
+func (er erasureObjects) rewriteAppendObject(ctx context.Context, bucket, object string, data *PutObjReader) (ObjectInfo, error) {
+	gr, err := er.GetObjectNInfo(ctx, bucket, object, nil, nil, noLock, ObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	existing, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	size := int64(len(existing)) + data.Size()
+	hr, err := hash.NewReader(io.MultiReader(bytes.NewReader(existing), data), size, "", "", size)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Keys describing the old data, like it being inlined, must not
+	// carry over to the new one.
+	metadata := cleanMetadataKeys(gr.ObjInfo.UserDefined, "etag",
+		ReservedMetadataPrefixLower+"inline-data", ReservedMetadataPrefix+"actual-size")
+	return er.PutObject(ctx, bucket, object, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: metadata,
+		NoLock:      true,
+	})
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/sync/errgroup"
+)
+
+// linkObjectParts adds the parts of srcObject to uploadID as parts
+// partID and up, their files are hard linked into the upload on every
+// drive instead of being copied. errCopyNeedsRewrite is returned when
+// the source is not stored alike the upload, the caller copies its
+// data as a part then.
+func (z *erasureServerPools) linkObjectParts(ctx context.Context, srcBucket, srcObject, srcVersionID, bucket, object, uploadID string, partID int, last bool) ([]PartInfo, error) {
+	srcObject = encodeDirObject(srcObject)
+	srcIdx, err := z.getPoolIdxExistingWithOpts(ctx, srcBucket, srcObject, ObjectOptions{
+		VersionID: srcVersionID,
+		NoLock:    true,
+	})
+	if err != nil {
+		return nil, errCopyNeedsRewrite
+	}
+
+	pools := z.currentPools()
+	dstIdx := 0
+	if !z.SinglePool() {
+		dstIdx = -1
+		for idx, pool := range pools {
+			if _, err = pool.GetMultipartInfo(ctx, bucket, object, uploadID, ObjectOptions{}); err == nil {
+				dstIdx = idx
+				break
+			}
+		}
+	}
+
+	// Parts can only be linked between the drives of one set.
+	if srcIdx != dstIdx {
+		return nil, errCopyNeedsRewrite
+	}
+	set := pools[dstIdx].getHashedSet(object)
+	if set != pools[srcIdx].getHashedSet(srcObject) {
+		return nil, errCopyNeedsRewrite
+	}
+	return set.linkObjectParts(ctx, srcBucket, srcObject, srcVersionID, bucket, object, uploadID, partID, last)
+}
+
+// partsLinkable returns whether the parts of fi can be linked into an
+// upload, not being the last part of the upload all of them have to be
+// at least of the minimum part size.
+func partsLinkable(fi FileInfo, last bool) bool {
+	switch {
+	case fi.Deleted, fi.XLV1, fi.IsRemote(), fi.InlineData(), len(fi.Data) > 0:
+		return false
+	case fi.DataDir == "", len(fi.Parts) == 0, fi.Size <= 0:
+		return false
+	case isMaxPartID(len(fi.Parts)), len(fi.Erasure.Checksums) != len(fi.Parts):
+		return false
+	}
+	oi := ObjectInfo{UserDefined: fi.Metadata}
+	if _, dedup := oi.dedupBlock(); dedup || isQuarantined(oi) || oi.IsCompressed() {
+		return false
+	}
+	if _, encrypted := crypto.IsEncrypted(fi.Metadata); encrypted {
+		return false
+	}
+	for i, part := range fi.Parts {
+		if (!last || i < len(fi.Parts)-1) && !isMinAllowedPartSize(part.ActualSize) {
+			return false
+		}
+		if part.ETag == "" && len(fi.Parts) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// linkObjectParts links the part files of srcObject of this set into
+// uploadID of object in this set. The drives holding a shard of the
+// source have to hold the same shard of the upload, an upload without
+// parts yet takes over the erasure distribution of the source.
+func (er erasureObjects) linkObjectParts(ctx context.Context, srcBucket, srcObject, srcVersionID, bucket, object, uploadID string, partID int, last bool) ([]PartInfo, error) {
+	// The source must not change while its parts are linked.
+	srcLock := er.NewNSLock(srcBucket, srcObject)
+	slkctx, err := srcLock.GetRLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return nil, err
+	}
+	ctx = slkctx.Context()
+	defer srcLock.RUnlock(slkctx.Cancel)
+
+	uploadIDLock := er.NewNSLock(bucket, pathJoin(object, uploadID))
+	ulkctx, err := uploadIDLock.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return nil, err
+	}
+	ctx = ulkctx.Context()
+	defer uploadIDLock.Unlock(ulkctx.Cancel)
+
+	if err = er.checkUploadIDExists(ctx, bucket, object, uploadID); err != nil {
+		return nil, toObjectErr(err, bucket, object, uploadID)
+	}
+
+	fi, metaArr, onlineDisks, err := er.getObjectFileInfo(ctx, srcBucket, srcObject, ObjectOptions{
+		VersionID: srcVersionID,
+		NoLock:    true,
+	}, false)
+	if err != nil || !partsLinkable(fi, last) || isMaxPartID(partID+len(fi.Parts)-1) {
+		return nil, errCopyNeedsRewrite
+	}
+
+	// Linked parts are only complete with all drives online and up
+	// to date, else the upload is copied to get healed as usual.
+	storageDisks := er.getDisks()
+	uploadIDPath := er.getUploadIDDir(bucket, object, uploadID)
+	partsMetadata, errs := readAllFileInfo(ctx, storageDisks, minioMetaMultipartBucket, uploadIDPath, "", false)
+	for _, err := range errs {
+		if err != nil {
+			return nil, errCopyNeedsRewrite
+		}
+	}
+	_, modTime, dataDir := listOnlineDisks(storageDisks, partsMetadata, errs)
+	uploadFi, err := pickValidFileInfo(ctx, partsMetadata, modTime, dataDir, len(storageDisks))
+	if err != nil {
+		return nil, errCopyNeedsRewrite
+	}
+	if _, encrypted := crypto.IsEncrypted(uploadFi.Metadata); encrypted {
+		return nil, errCopyNeedsRewrite
+	}
+	if _, compressed := uploadFi.Metadata[ReservedMetadataPrefix+"compression"]; compressed {
+		return nil, errCopyNeedsRewrite
+	}
+	if uploadFi.Erasure.Algorithm != fi.Erasure.Algorithm || uploadFi.Erasure.BlockSize != fi.Erasure.BlockSize ||
+		uploadFi.Erasure.DataBlocks != fi.Erasure.DataBlocks || uploadFi.Erasure.ParityBlocks != fi.Erasure.ParityBlocks {
+		return nil, errCopyNeedsRewrite
+	}
+	adopt := len(uploadFi.Parts) == 0
+	for i, disk := range storageDisks {
+		if disk == nil || onlineDisks[i] == nil || !disk.IsOnline() {
+			return nil, errCopyNeedsRewrite
+		}
+		if metaArr[i].DataDir != fi.DataDir || !metaArr[i].ModTime.Equal(fi.ModTime) {
+			return nil, errCopyNeedsRewrite
+		}
+		if partsMetadata[i].DataDir != uploadFi.DataDir {
+			return nil, errCopyNeedsRewrite
+		}
+		if !adopt && partsMetadata[i].Erasure.Index != metaArr[i].Erasure.Index {
+			return nil, errCopyNeedsRewrite
+		}
+	}
+
+	partPath := func(index int) string {
+		return pathJoin(uploadIDPath, uploadFi.DataDir, fmt.Sprintf("part.%d", partID+index))
+	}
+	g := errgroup.WithNErrs(len(storageDisks))
+	for index := range storageDisks {
+		index := index
+		g.Go(func() error {
+			for i, part := range fi.Parts {
+				srcPart := pathJoin(srcObject, fi.DataDir, fmt.Sprintf("part.%d", part.Number))
+				if err := storageDisks[index].LinkFile(ctx, srcBucket, srcPart, minioMetaMultipartBucket, partPath(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, index)
+	}
+	for _, err := range g.Wait() {
+		if err == nil {
+			continue
+		}
+		// Remove what was linked, the parts are copied instead.
+		for _, disk := range storageDisks {
+			for i := range fi.Parts {
+				disk.Delete(context.Background(), minioMetaMultipartBucket, partPath(i), false)
+			}
+		}
+		return nil, errCopyNeedsRewrite
+	}
+
+	uploadFi.ModTime = UTCNow()
+	pis := make([]PartInfo, 0, len(fi.Parts))
+	for i, part := range fi.Parts {
+		// The ETag of a single part object is the one of its first part.
+		etag := part.ETag
+		if etag == "" {
+			etag = fi.Metadata["etag"]
+		}
+		uploadFi.AddObjectPart(partID+i, etag, part.Size, part.ActualSize)
+		pis = append(pis, PartInfo{
+			PartNumber:   partID + i,
+			ETag:         etag,
+			LastModified: uploadFi.ModTime,
+			Size:         part.Size,
+			ActualSize:   part.ActualSize,
+		})
+	}
+
+	for i := range storageDisks {
+		partsMetadata[i].Size = uploadFi.Size
+		partsMetadata[i].ModTime = uploadFi.ModTime
+		partsMetadata[i].Parts = uploadFi.Parts
+		if adopt {
+			partsMetadata[i].Erasure.Distribution = fi.Erasure.Distribution
+			partsMetadata[i].Erasure.Index = metaArr[i].Erasure.Index
+		}
+		for j, part := range fi.Parts {
+			ckSum := metaArr[i].Erasure.GetChecksumInfo(part.Number)
+			ckSum.PartNumber = partID + j
+			partsMetadata[i].Erasure.AddChecksumInfo(ckSum)
+		}
+	}
+
+	writeQuorum := uploadFi.Erasure.DataBlocks
+	if uploadFi.Erasure.DataBlocks == uploadFi.Erasure.ParityBlocks {
+		writeQuorum++
+	}
+
+	// Writes update `xl.meta` format for each disk.
+	if _, err = writeUniqueFileInfo(ctx, storageDisks, minioMetaMultipartBucket, uploadIDPath, partsMetadata, writeQuorum); err != nil {
+		return nil, toObjectErr(err, minioMetaMultipartBucket, uploadIDPath)
+	}
+	return pis, nil
+}
@@ -156,7 +156,7 @@ func (er erasureObjects) cleanupStaleUploadsOnDisk(ctx context.Context, disk Sto
 				return nil
 			}
 			wait := er.deletedCleanupSleeper.Timer(ctx)
-			if now.Sub(fi.ModTime) > expiry {
+			if now.Sub(fi.ModTime) > multipartUploadExpiry(fi, expiry) {
 				er.renameAll(ctx, minioMetaMultipartBucket, uploadIDPath)
 			}
 			wait()
@@ -336,6 +336,7 @@ func (er erasureObjects) newMultipartUpload(ctx context.Context, bucket string,
 	if opts.UserDefined["content-type"] == "" {
 		opts.UserDefined["content-type"] = mimedb.TypeByExtension(path.Ext(object))
 	}
+	opts.UserDefined[multipartObjectKey] = pathJoin(bucket, object)
 
 	modTime := opts.MTime
 	if opts.MTime.IsZero() {
@@ -518,7 +519,7 @@ func (er erasureObjects) PutObjectPart(ctx context.Context, bucket, object, uplo
 		if disk == nil {
 			continue
 		}
-		writers[i] = newBitrotWriter(disk, minioMetaTmpBucket, tmpPartPath, erasure.ShardFileSize(data.Size()), DefaultBitrotAlgorithm, erasure.ShardSize())
+		writers[i] = newBitrotWriter(ctx, disk, minioMetaTmpBucket, tmpPartPath, erasure.ShardFileSize(data.Size()), DefaultBitrotAlgorithm, erasure.ShardSize())
 	}
 
 	n, err := erasure.Encode(pctx, data, writers, buffer, writeQuorum)
@@ -883,6 +884,12 @@ func (er erasureObjects) CompleteMultipartUpload(ctx context.Context, bucket str
 	if fi.Metadata["etag"] == "" {
 		fi.Metadata["etag"] = getCompleteMultipartMD5(parts)
 	}
+	// Objects of a multi-object commit stay hidden until it is committed.
+	if id, ok := opts.UserDefined[commitIDKey]; ok {
+		fi.Metadata[commitIDKey] = id
+	}
+	delete(fi.Metadata, multipartObjectKey)
+	delete(fi.Metadata, multipartInitiatorKey)
 
 	// Save the consolidated actual size.
 	fi.Metadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(objectActualSize, 10)
@@ -898,14 +905,21 @@ func (er erasureObjects) CompleteMultipartUpload(ctx context.Context, bucket str
 		}
 	}
 
-	// Hold namespace to complete the transaction
-	lk := er.NewNSLock(bucket, object)
-	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
-	if err != nil {
+	if !opts.NoLock {
+		// Hold namespace to complete the transaction
+		lk := er.NewNSLock(bucket, object)
+		lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+		if err != nil {
+			return oi, err
+		}
+		ctx = lkctx.Context()
+		defer lk.Unlock(lkctx.Cancel)
+	}
+
+	// Conditional writes are evaluated against the object under lock.
+	if err = er.checkWritePrecondition(ctx, bucket, object, opts); err != nil {
 		return oi, err
 	}
-	ctx = lkctx.Context()
-	defer lk.Unlock(lkctx.Cancel)
 
 	// Write final `xl.meta` at uploadID location
 	onlineDisks, err = writeUniqueFileInfo(ctx, onlineDisks, minioMetaMultipartBucket, uploadIDPath, partsMetadata, writeQuorum)
@@ -927,6 +941,7 @@ func (er erasureObjects) CompleteMultipartUpload(ctx context.Context, bucket str
 	}
 
 	// Rename the multipart object to final location.
+	er.keyFilter.add(bucket, object)
 	if onlineDisks, err = renameData(ctx, onlineDisks, minioMetaMultipartBucket, uploadIDPath,
 		partsMetadata, bucket, object, writeQuorum); err != nil {
 		return oi, toObjectErr(err, bucket, object)
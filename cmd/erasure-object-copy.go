@@ -0,0 +1,222 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"github.com/minio/minio/internal/crypto"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/sync/errgroup"
+	"github.com/minio/pkg/mimedb"
+)
+
+// errCopyNeedsRewrite - the object cannot be copied as stored, its
+// data has to be erasure coded again for the destination.
+var errCopyNeedsRewrite = errors.New("copy needs a rewrite of the object data")
+
+// copyObjectShards copies srcObject to dstObject in the pool dstIdx by
+// streaming the existing erasure shards disk to disk, without decoding
+// and encoding the object again. errCopyNeedsRewrite is returned when
+// the object as stored does not fit the destination, the caller falls
+// back to PutObject then. Objects uploaded in several parts are always
+// rewritten, their copy is a single part object with the MD5 sum of its
+// data as ETag like any copy written by PutObject. The caller must hold
+// the lock of dstObject.
+func (z *erasureServerPools) copyObjectShards(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string, srcInfo ObjectInfo, srcOpts ObjectOptions, dstIdx int, opts ObjectOptions) (ObjectInfo, error) {
+	// srcInfo.UserDefined holds the metadata of the destination, the
+	// data changes when it is encrypted or compressed.
+	if opts.ServerSideEncryption != nil || srcInfo.IsCompressed() {
+		return ObjectInfo{}, errCopyNeedsRewrite
+	}
+	if _, encrypted := crypto.IsEncrypted(srcInfo.UserDefined); encrypted {
+		return ObjectInfo{}, errCopyNeedsRewrite
+	}
+
+	srcIdx, err := z.getPoolIdxExistingWithOpts(ctx, srcBucket, srcObject, ObjectOptions{
+		VersionID: srcOpts.VersionID,
+		NoLock:    true,
+	})
+	if err != nil {
+		return ObjectInfo{}, errCopyNeedsRewrite
+	}
+	src := z.currentPools()[srcIdx].getHashedSet(srcObject)
+	dst := z.currentPools()[dstIdx].getHashedSet(dstObject)
+	return dst.copyObjectShards(ctx, src, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, opts)
+}
+
+// shardCopyable returns whether the object fi can be copied shard by
+// shard to a set of driveCount drives with parity drives of parity. Only
+// single part objects stored as they are read qualify, the ETag of
+// those is the MD5 sum a rewrite computes as well, unlike the ETag of an
+// object uploaded as a single multipart part.
+func shardCopyable(fi FileInfo, srcInfo ObjectInfo, driveCount, parity int) bool {
+	switch {
+	case fi.Deleted, fi.XLV1, fi.IsRemote(), fi.InlineData(), len(fi.Data) > 0:
+		return false
+	case len(fi.Parts) != 1, len(fi.Erasure.Checksums) != 1, fi.Size <= 0:
+		return false
+	case isMultipartETag(fi.Metadata["etag"]):
+		return false
+	case fi.Size != srcInfo.Size, fi.Metadata["etag"] != srcInfo.ETag:
+		// The object changed since the copy was started.
+		return false
+	case fi.Erasure.DataBlocks+fi.Erasure.ParityBlocks != driveCount:
+		return false
+	case fi.Erasure.ParityBlocks != parity, fi.Erasure.BlockSize != blockSizeV2:
+		return false
+	}
+	if _, encrypted := crypto.IsEncrypted(fi.Metadata); encrypted {
+		return false
+	}
+	_, compressed := fi.Metadata[ReservedMetadataPrefix+"compression"]
+	return !compressed
+}
+
+// copyObjectShards writes the shards of srcObject in the set src as
+// dstObject of this set, every shard is streamed from the source drive
+// holding it to the destination drive of the same erasure index.
+func (er erasureObjects) copyObjectShards(ctx context.Context, src *erasureObjects, srcBucket, srcObject, dstBucket, dstObject string, srcInfo ObjectInfo, srcOpts, opts ObjectOptions) (ObjectInfo, error) {
+	fi, metaArr, onlineDisks, err := src.getObjectFileInfo(ctx, srcBucket, srcObject, ObjectOptions{
+		VersionID: srcOpts.VersionID,
+		NoLock:    true,
+	}, false)
+	if err != nil {
+		return ObjectInfo{}, errCopyNeedsRewrite
+	}
+
+	if opts.UserDefined == nil {
+		opts.UserDefined = make(map[string]string)
+	}
+
+	// The parity a rewrite of the object would pick, without the
+	// upgrade for offline drives as all drives need to be online.
+	parityDrives := globalStorageClass.GetParityForSC(opts.UserDefined[xhttp.AmzStorageClass])
+	if parityDrives <= 0 {
+		parityDrives = er.defaultParityCount
+	}
+	storageDisks := er.getDisks()
+	if !shardCopyable(fi, srcInfo, len(storageDisks), parityDrives) {
+		return ObjectInfo{}, errCopyNeedsRewrite
+	}
+
+	dstFi := newFileInfo(pathJoin(dstBucket, dstObject), fi.Erasure.DataBlocks, fi.Erasure.ParityBlocks)
+	dstFi.VersionID = opts.VersionID
+	if opts.Versioned && dstFi.VersionID == "" {
+		dstFi.VersionID = mustGetUUID()
+	}
+	dstFi.DataDir = mustGetUUID()
+	tempObj := mustGetUUID()
+
+	// Order both sides by erasure index, a missing shard or drive
+	// would leave the copy degraded.
+	srcDisks, srcMetas := shuffleDisksAndPartsMetadataByIndex(onlineDisks, metaArr, fi)
+	dstDisks := shuffleDisks(storageDisks, dstFi.Erasure.Distribution)
+	for i := range dstDisks {
+		if srcDisks[i] == nil || dstDisks[i] == nil || !dstDisks[i].IsOnline() {
+			return ObjectInfo{}, errCopyNeedsRewrite
+		}
+		if srcMetas[i].DataDir != fi.DataDir || !srcMetas[i].ModTime.Equal(fi.ModTime) {
+			return ObjectInfo{}, errCopyNeedsRewrite
+		}
+	}
+
+	writeQuorum := fi.Erasure.DataBlocks
+	if fi.Erasure.DataBlocks == fi.Erasure.ParityBlocks {
+		writeQuorum++
+	}
+
+	// Delete temporary object in the event of failure.
+	var online int
+	defer func() {
+		if online != len(dstDisks) {
+			er.deleteObject(context.Background(), minioMetaTmpBucket, tempObj, writeQuorum)
+		}
+	}()
+
+	partName := "part.1"
+	part := fi.Parts[0]
+	checksum := fi.Erasure.GetChecksumInfo(part.Number)
+	shardFileSize := bitrotShardFileSize(fi.Erasure.ShardFileSize(part.Size), fi.Erasure.ShardSize(), checksum.Algorithm)
+
+	g := errgroup.WithNErrs(len(dstDisks))
+	for i := range dstDisks {
+		i := i
+		g.Go(func() error {
+			r, err := srcDisks[i].ReadFileStream(ctx, srcBucket, pathJoin(srcObject, fi.DataDir, partName), 0, shardFileSize)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			return dstDisks[i].CreateFile(ctx, minioMetaTmpBucket, pathJoin(tempObj, dstFi.DataDir, partName), shardFileSize, r)
+		}, i)
+	}
+	for _, err := range g.Wait() {
+		if err != nil {
+			return ObjectInfo{}, errCopyNeedsRewrite
+		}
+	}
+
+	opts.UserDefined["etag"] = fi.Metadata["etag"]
+	// Guess content-type from the extension if possible.
+	if opts.UserDefined["content-type"] == "" {
+		opts.UserDefined["content-type"] = mimedb.TypeByExtension(path.Ext(dstObject))
+	}
+
+	modTime := opts.MTime
+	if opts.MTime.IsZero() {
+		modTime = UTCNow()
+	}
+
+	partsMetadata := make([]FileInfo, len(dstDisks))
+	for i := range partsMetadata {
+		partsMetadata[i] = dstFi
+		partsMetadata[i].Erasure.Index = i + 1
+		partsMetadata[i].AddObjectPart(1, "", part.Size, part.ActualSize)
+		// Whole file bitrot sums differ per shard.
+		partsMetadata[i].Erasure.AddChecksumInfo(ChecksumInfo{
+			PartNumber: 1,
+			Algorithm:  checksum.Algorithm,
+			Hash:       srcMetas[i].Erasure.GetChecksumInfo(part.Number).Hash,
+		})
+		partsMetadata[i].Metadata = opts.UserDefined
+		partsMetadata[i].Size = fi.Size
+		partsMetadata[i].ModTime = modTime
+	}
+
+	er.keyFilter.add(dstBucket, dstObject)
+	if dstDisks, err = renameData(ctx, dstDisks, minioMetaTmpBucket, tempObj, partsMetadata, dstBucket, dstObject, writeQuorum); err != nil {
+		return ObjectInfo{}, toObjectErr(err, dstBucket, dstObject)
+	}
+
+	dstFi = partsMetadata[0]
+	for i := range dstDisks {
+		if dstDisks[i] == nil || !dstDisks[i].IsOnline() {
+			er.addPartial(dstBucket, dstObject, dstFi.VersionID, dstFi.Size)
+			break
+		}
+	}
+
+	dstFi.ReplicationState = opts.PutReplicationState()
+	online = countOnlineDisks(dstDisks)
+	dstFi.IsLatest = true
+
+	return dstFi.ToObjectInfo(dstBucket, dstObject), nil
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestCopyObjectShards(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	z := objLayer.(*erasureServerPools)
+
+	for _, bucket := range []string{"src", "dst"} {
+		if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	large := bytes.Repeat([]byte("a"), 5<<20+17)
+	small := []byte("small")
+	for name, data := range map[string][]byte{"large": large, "small": small} {
+		if _, err = objLayer.PutObject(ctx, "src", name, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		object string
+		data   []byte
+		err    error
+	}{
+		{"large", large, nil},
+		// Inline objects are rewritten.
+		{"small", small, errCopyNeedsRewrite},
+	}
+	for i, tc := range testCases {
+		srcInfo, err := objLayer.GetObjectInfo(ctx, "src", tc.object, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		srcInfo.UserDefined = map[string]string{"content-type": srcInfo.ContentType}
+		oi, err := z.copyObjectShards(ctx, "src", tc.object, "dst", "copy/"+tc.object, srcInfo, ObjectOptions{}, 0, ObjectOptions{UserDefined: srcInfo.UserDefined})
+		if err != tc.err {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, tc.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if oi.Size != int64(len(tc.data)) || oi.ETag != srcInfo.ETag {
+			t.Fatalf("Test %d: unexpected object info %#v", i+1, oi)
+		}
+		gr, err := objLayer.GetObjectNInfo(ctx, "dst", "copy/"+tc.object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(got, tc.data) {
+			t.Fatalf("Test %d: copied object content differs", i+1)
+		}
+	}
+}
+
+func TestCopyObjectShardsRewrite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	z := objLayer.(*erasureServerPools)
+
+	for _, bucket := range []string{"src", "dst"} {
+		if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := bytes.Repeat([]byte("a"), 5<<20+17)
+	if _, err = objLayer.PutObject(ctx, "src", "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	uploadID, err := objLayer.NewMultipartUpload(ctx, "src", "multipart", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parts []CompletePart
+	for partID := 1; partID <= 2; partID++ {
+		pi, err := objLayer.PutObjectPart(ctx, "src", "multipart", uploadID, partID, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, CompletePart{PartNumber: partID, ETag: pi.ETag})
+	}
+	if _, err = objLayer.CompleteMultipartUpload(ctx, "src", "multipart", uploadID, parts, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	set := z.serverPools[0].getHashedSet("copy")
+	disks := set.getDisks()
+	parity := set.defaultParityCount
+
+	testCases := []struct {
+		object string
+		setup  func()
+	}{
+		// Objects uploaded in parts are rewritten.
+		{"multipart", func() {}},
+		// The parity of the destination differs.
+		{"object", func() { set.defaultParityCount = parity + 2 }},
+		// A drive of the destination is offline.
+		{"object", func() {
+			stubbed := append([]StorageAPI(nil), disks...)
+			stubbed[3] = nil
+			set.getDisks = func() []StorageAPI { return stubbed }
+		}},
+	}
+	for i, tc := range testCases {
+		srcInfo, err := objLayer.GetObjectInfo(ctx, "src", tc.object, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		tc.setup()
+		_, err = z.copyObjectShards(ctx, "src", tc.object, "dst", "copy", srcInfo, ObjectOptions{}, 0, ObjectOptions{UserDefined: srcInfo.UserDefined})
+		set.defaultParityCount = parity
+		set.getDisks = func() []StorageAPI { return disks }
+		if err != errCopyNeedsRewrite {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, errCopyNeedsRewrite, err)
+		}
+		if _, err = objLayer.GetObjectInfo(ctx, "dst", "copy", ObjectOptions{}); !isErrObjectNotFound(err) {
+			t.Fatalf("Test %d: expected no copy, got %v", i+1, err)
+		}
+	}
+}
+
+func TestCopyObjectFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	for _, bucket := range []string{"src", "dst"} {
+		if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := bytes.Repeat([]byte("a"), 5<<20+17)
+	opts := ObjectOptions{UserDefined: map[string]string{"X-Amz-Meta-Old": "old"}}
+	if _, err = objLayer.PutObject(ctx, "src", "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	uploadID, err := objLayer.NewMultipartUpload(ctx, "src", "multipart", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi, err := objLayer.PutObjectPart(ctx, "src", "multipart", uploadID, 1, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = objLayer.CompleteMultipartUpload(ctx, "src", "multipart", uploadID, []CompletePart{{PartNumber: 1, ETag: pi.ETag}}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	md5sum := getMD5Hash(data)
+
+	// The copy of the single part object is taken shard by shard, the
+	// one of the multipart object is written again by PutObject. The
+	// metadata of both is replaced.
+	for i, object := range []string{"object", "multipart"} {
+		gr, err := objLayer.GetObjectNInfo(ctx, "src", object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		srcInfo := gr.ObjInfo
+		srcInfo.UserDefined = map[string]string{"content-type": "text/plain", "X-Amz-Meta-New": "new"}
+		srcInfo.PutObjReader = mustGetPutObjReader(t, gr, srcInfo.Size, "", "")
+		oi, err := objLayer.CopyObject(ctx, "src", object, "dst", object, srcInfo, ObjectOptions{}, ObjectOptions{})
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if oi.ETag != md5sum {
+			t.Fatalf("Test %d: expected ETag %s, got %s", i+1, md5sum, oi.ETag)
+		}
+
+		gr, err = objLayer.GetObjectNInfo(ctx, "dst", object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Test %d: copied object content differs", i+1)
+		}
+		dstInfo := gr.ObjInfo
+		if dstInfo.ContentType != "text/plain" || dstInfo.UserDefined["X-Amz-Meta-New"] != "new" {
+			t.Fatalf("Test %d: metadata not replaced %#v", i+1, dstInfo.UserDefined)
+		}
+		if _, ok := dstInfo.UserDefined["X-Amz-Meta-Old"]; ok {
+			t.Fatalf("Test %d: metadata of the source kept %#v", i+1, dstInfo.UserDefined)
+		}
+	}
+}
@@ -24,12 +24,14 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strconv"
 	"testing"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/minio/minio/internal/config/storageclass"
+	xhttp "github.com/minio/minio/internal/http"
 )
 
 func TestRepeatPutObjectPart(t *testing.T) {
@@ -802,6 +804,56 @@ func TestPutObjectSmallInlineData(t *testing.T) {
 	}
 }
 
+func TestPutObjectConditional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create an instance of xl backend.
+	obj, fsDirs, err := prepareErasure(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cleanup backend directories.
+	defer obj.Shutdown(context.Background())
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	object := "object"
+	if err = obj.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(header, value string) (ObjectInfo, error) {
+		r, err := http.NewRequest(http.MethodPut, "/"+bucket+"/"+object, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set(header, value)
+		data := []byte("data")
+		return obj.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{
+			CheckPrecondFn: putPrecondFn(r),
+		})
+	}
+
+	if _, err = put(xhttp.IfMatch, "*"); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-Match on a missing object to fail, got %v", err)
+	}
+	oi, err := put(xhttp.IfNoneMatch, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = put(xhttp.IfNoneMatch, "*"); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-None-Match on an existing object to fail, got %v", err)
+	}
+	if _, err = put(xhttp.IfMatch, "deadbeef"); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-Match with another ETag to fail, got %v", err)
+	}
+	if _, err = put(xhttp.IfMatch, `"`+oi.ETag+`"`); err != nil {
+		t.Fatalf("expected If-Match with the current ETag to succeed, got %v", err)
+	}
+}
+
 func TestObjectQuorumFromMeta(t *testing.T) {
 	ExecObjectLayerTestWithDirs(t, testObjectQuorumFromMeta)
 }
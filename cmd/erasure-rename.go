@@ -0,0 +1,78 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/minio/minio/internal/crypto"
+)
+
+// renameObject renames srcObject to dstObject without copying any data,
+// errRenameNeedsCopy is returned when both names do not hash to the
+// erasure set holding srcObject.
+func (z *erasureServerPools) renameObject(ctx context.Context, bucket, srcObject, dstObject string) (ObjectInfo, error) {
+	defer invalidateObjectMemCache(ctx, bucket, []string{srcObject, dstObject}, false)
+
+	srcObject = encodeDirObject(srcObject)
+	dstObject = encodeDirObject(dstObject)
+
+	idx, err := z.getPoolIdxExisting(ctx, bucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	// An existing destination in another pool would survive the rename.
+	if dstIdx, err := z.getPoolIdxExisting(ctx, bucket, dstObject); err == nil && dstIdx != idx {
+		return ObjectInfo{}, errRenameNeedsCopy
+	}
+
+	pool := z.currentPools()[idx]
+	if pool.getHashedSetIndex(srcObject) != pool.getHashedSetIndex(dstObject) {
+		return ObjectInfo{}, errRenameNeedsCopy
+	}
+	return pool.getHashedSet(srcObject).renameObject(ctx, bucket, srcObject, dstObject)
+}
+
+// renameObject moves srcObject with all its versions to dstObject,
+// replacing dstObject if it exists.
+func (er erasureObjects) renameObject(ctx context.Context, bucket, srcObject, dstObject string) (ObjectInfo, error) {
+	lk := er.NewNSLock(bucket, srcObject, dstObject)
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	fi, _, _, err := er.getObjectFileInfo(ctx, bucket, srcObject, ObjectOptions{NoLock: true}, false)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, srcObject)
+	}
+	if fi.Deleted {
+		return ObjectInfo{}, toObjectErr(errFileNotFound, bucket, srcObject)
+	}
+	// The object key of encrypted objects is sealed for their name.
+	if _, encrypted := crypto.IsEncrypted(fi.Metadata); encrypted {
+		return ObjectInfo{}, errRenameNeedsCopy
+	}
+
+	if err = er.moveObject(ctx, bucket, srcObject, bucket, dstObject); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, dstObject)
+	}
+	return fi.ToObjectInfo(bucket, dstObject), nil
+}
@@ -42,14 +42,34 @@ import (
 type erasureServerPools struct {
 	GatewayUnsupported
 
-	serverPools []*erasureSets
+	// poolsMu guards serverPools, replaced when a pool is added online,
+	// and preparedPools, the number of last pools added online serving
+	// reads but not taking new objects until all nodes added them.
+	poolsMu       sync.RWMutex
+	serverPools   []*erasureSets
+	preparedPools int
 
 	// Shut down async operations
 	shutdown context.CancelFunc
 }
 
+// currentPools returns the pools, pools are only ever added so the
+// indexes of pools returned by earlier calls stay valid.
+func (z *erasureServerPools) currentPools() []*erasureSets {
+	z.poolsMu.RLock()
+	defer z.poolsMu.RUnlock()
+	return z.serverPools
+}
+
+// writablePools returns the number of first pools taking new objects.
+func (z *erasureServerPools) writablePools() int {
+	z.poolsMu.RLock()
+	defer z.poolsMu.RUnlock()
+	return len(z.serverPools) - z.preparedPools
+}
+
 func (z *erasureServerPools) SinglePool() bool {
-	return len(z.serverPools) == 1
+	return len(z.currentPools()) == 1
 }
 
 // Initialize new pool of erasure sets.
@@ -88,7 +108,7 @@ func newErasureServerPools(ctx context.Context, endpointServerPools EndpointServ
 			return nil, fmt.Errorf("All current serverPools should have same parity ratio - expected %d, got %d", commonParityDrives, ecDrivesNoConfig(ep.DrivesPerSet))
 		}
 
-		storageDisks[i], formats[i], err = waitForFormatErasure(local, ep.Endpoints, i+1,
+		storageDisks[i], formats[i], err = waitForFormatErasure(ctx, local, ep.Endpoints, i+1,
 			ep.SetCount, ep.DrivesPerSet, deploymentID, distributionAlgo)
 		if err != nil {
 			return nil, err
@@ -119,7 +139,7 @@ func newErasureServerPools(ctx context.Context, endpointServerPools EndpointServ
 }
 
 func (z *erasureServerPools) NewNSLock(bucket string, objects ...string) RWLocker {
-	return z.serverPools[0].NewNSLock(bucket, objects...)
+	return z.currentPools()[0].NewNSLock(bucket, objects...)
 }
 
 // GetDisksID will return disks by their ID.
@@ -129,7 +149,7 @@ func (z *erasureServerPools) GetDisksID(ids ...string) []StorageAPI {
 		idMap[id] = struct{}{}
 	}
 	res := make([]StorageAPI, 0, len(idMap))
-	for _, s := range z.serverPools {
+	for _, s := range z.currentPools() {
 		s.erasureDisksMu.RLock()
 		defer s.erasureDisksMu.RUnlock()
 		for _, disks := range s.erasureDisks {
@@ -153,7 +173,7 @@ func (z *erasureServerPools) GetDisksID(ids ...string) []StorageAPI {
 // For now only direct file paths are supported.
 func (z *erasureServerPools) GetRawData(ctx context.Context, volume, file string, fn func(r io.Reader, host string, disk string, filename string, info StatInfo) error) error {
 	found := 0
-	for _, s := range z.serverPools {
+	for _, s := range z.currentPools() {
 		for _, disks := range s.erasureDisks {
 			for i, disk := range disks {
 				if disk == OfflineDisk {
@@ -194,9 +214,10 @@ func (z *erasureServerPools) GetRawData(ctx context.Context, volume, file string
 }
 
 func (z *erasureServerPools) SetDriveCounts() []int {
-	setDriveCounts := make([]int, len(z.serverPools))
-	for i := range z.serverPools {
-		setDriveCounts[i] = z.serverPools[i].SetDriveCount()
+	serverPools := z.currentPools()
+	setDriveCounts := make([]int, len(serverPools))
+	for i := range serverPools {
+		setDriveCounts[i] = serverPools[i].SetDriveCount()
 	}
 	return setDriveCounts
 }
@@ -243,15 +264,16 @@ func (z *erasureServerPools) getAvailablePoolIdx(ctx context.Context, bucket, ob
 // If there is not enough space the pool will return 0 bytes available.
 // Negative sizes are seen as 0 bytes.
 func (z *erasureServerPools) getServerPoolsAvailableSpace(ctx context.Context, bucket, object string, size int64) serverPoolsAvailableSpace {
-	var serverPools = make(serverPoolsAvailableSpace, len(z.serverPools))
+	pools := z.currentPools()
+	var serverPools = make(serverPoolsAvailableSpace, len(pools))
 
-	storageInfos := make([][]*DiskInfo, len(z.serverPools))
-	g := errgroup.WithNErrs(len(z.serverPools))
-	for index := range z.serverPools {
+	storageInfos := make([][]*DiskInfo, len(pools))
+	g := errgroup.WithNErrs(len(pools))
+	for index := range pools {
 		index := index
 		g.Go(func() error {
 			// Get the set where it would be placed.
-			storageInfos[index] = getDiskInfos(ctx, z.serverPools[index].getHashedSet(object).getDisks())
+			storageInfos[index] = getDiskInfos(ctx, pools[index].getHashedSet(object).getDisks())
 			return nil
 		}, index)
 	}
@@ -260,20 +282,27 @@ func (z *erasureServerPools) getServerPoolsAvailableSpace(ctx context.Context, b
 	g.Wait()
 
 	for i, zinfo := range storageInfos {
-		var available uint64
 		if !isMinioMetaBucketName(bucket) && !hasSpaceFor(zinfo, size) {
 			serverPools[i] = poolAvailableSpace{Index: i}
 			continue
 		}
-		for _, disk := range zinfo {
-			if disk == nil {
-				continue
-			}
-			available += disk.Total - disk.Used
-		}
+		// Pools are weighted by the space shards can be written to,
+		// sets of mixed drive sizes fill up with their smallest drive.
 		serverPools[i] = poolAvailableSpace{
 			Index:     i,
-			Available: available,
+			Available: usableAvailable(zinfo),
+		}
+	}
+	// Pools being added online take no new objects yet.
+	for i := z.writablePools(); i < len(serverPools); i++ {
+		serverPools[i].Available = 0
+	}
+	// Only the pools of the placement of the bucket take new objects.
+	if placement := z.placementPools(bucket); placement != nil {
+		for i := range serverPools {
+			if !placement[i] {
+				serverPools[i].Available = 0
+			}
 		}
 	}
 	return serverPools
@@ -287,14 +316,15 @@ type poolObjInfo struct {
 }
 
 func (z *erasureServerPools) getPoolIdxExistingWithOpts(ctx context.Context, bucket, object string, opts ObjectOptions) (idx int, err error) {
+	serverPools := z.currentPools()
 	if z.SinglePool() {
 		return 0, nil
 	}
 
-	poolObjInfos := make([]poolObjInfo, len(z.serverPools))
+	poolObjInfos := make([]poolObjInfo, len(serverPools))
 
 	var wg sync.WaitGroup
-	for i, pool := range z.serverPools {
+	for i, pool := range serverPools {
 		wg.Add(1)
 		go func(i int, pool *erasureSets) {
 			defer wg.Done()
@@ -387,14 +417,15 @@ func (z *erasureServerPools) getPoolIdx(ctx context.Context, bucket, object stri
 }
 
 func (z *erasureServerPools) Shutdown(ctx context.Context) error {
+	serverPools := z.currentPools()
 	defer z.shutdown()
 
-	g := errgroup.WithNErrs(len(z.serverPools))
+	g := errgroup.WithNErrs(len(serverPools))
 
-	for index := range z.serverPools {
+	for index := range serverPools {
 		index := index
 		g.Go(func() error {
-			return z.serverPools[index].Shutdown(ctx)
+			return serverPools[index].Shutdown(ctx)
 		}, index)
 	}
 
@@ -412,7 +443,7 @@ func (z *erasureServerPools) BackendInfo() (b madmin.BackendInfo) {
 
 	scParity := globalStorageClass.GetParityForSC(storageclass.STANDARD)
 	if scParity <= 0 {
-		scParity = z.serverPools[0].defaultParityCount
+		scParity = z.currentPools()[0].defaultParityCount
 	}
 	rrSCParity := globalStorageClass.GetParityForSC(storageclass.RRS)
 
@@ -428,15 +459,16 @@ func (z *erasureServerPools) BackendInfo() (b madmin.BackendInfo) {
 }
 
 func (z *erasureServerPools) LocalStorageInfo(ctx context.Context) (StorageInfo, []error) {
+	serverPools := z.currentPools()
 	var storageInfo StorageInfo
 
-	storageInfos := make([]StorageInfo, len(z.serverPools))
-	storageInfosErrs := make([][]error, len(z.serverPools))
-	g := errgroup.WithNErrs(len(z.serverPools))
-	for index := range z.serverPools {
+	storageInfos := make([]StorageInfo, len(serverPools))
+	storageInfosErrs := make([][]error, len(serverPools))
+	g := errgroup.WithNErrs(len(serverPools))
+	for index := range serverPools {
 		index := index
 		g.Go(func() error {
-			storageInfos[index], storageInfosErrs[index] = z.serverPools[index].LocalStorageInfo(ctx)
+			storageInfos[index], storageInfosErrs[index] = serverPools[index].LocalStorageInfo(ctx)
 			return nil
 		}, index)
 	}
@@ -450,22 +482,23 @@ func (z *erasureServerPools) LocalStorageInfo(ctx context.Context) (StorageInfo,
 	}
 
 	var errs []error
-	for i := range z.serverPools {
+	for i := range serverPools {
 		errs = append(errs, storageInfosErrs[i]...)
 	}
 	return storageInfo, errs
 }
 
 func (z *erasureServerPools) StorageInfo(ctx context.Context) (StorageInfo, []error) {
+	serverPools := z.currentPools()
 	var storageInfo StorageInfo
 
-	storageInfos := make([]StorageInfo, len(z.serverPools))
-	storageInfosErrs := make([][]error, len(z.serverPools))
-	g := errgroup.WithNErrs(len(z.serverPools))
-	for index := range z.serverPools {
+	storageInfos := make([]StorageInfo, len(serverPools))
+	storageInfosErrs := make([][]error, len(serverPools))
+	g := errgroup.WithNErrs(len(serverPools))
+	for index := range serverPools {
 		index := index
 		g.Go(func() error {
-			storageInfos[index], storageInfosErrs[index] = z.serverPools[index].StorageInfo(ctx)
+			storageInfos[index], storageInfosErrs[index] = serverPools[index].StorageInfo(ctx)
 			return nil
 		}, index)
 	}
@@ -479,7 +512,7 @@ func (z *erasureServerPools) StorageInfo(ctx context.Context) (StorageInfo, []er
 	}
 
 	var errs []error
-	for i := range z.serverPools {
+	for i := range serverPools {
 		errs = append(errs, storageInfosErrs[i]...)
 	}
 	return storageInfo, errs
@@ -512,8 +545,13 @@ func (z *erasureServerPools) NSScanner(ctx context.Context, bf *bloomFilter, upd
 		return allBuckets[i].Created.After(allBuckets[j].Created)
 	})
 
+	for _, b := range allBuckets {
+		checkMetadataIndex(ctx, z, b.Name)
+		checkChangelog(ctx, z, b.Name)
+	}
+
 	// Collect for each set in serverPools.
-	for _, z := range z.serverPools {
+	for _, z := range z.currentPools() {
 		for _, erObj := range z.sets {
 			wg.Add(1)
 			results = append(results, dataUsageCache{})
@@ -530,7 +568,9 @@ func (z *erasureServerPools) NSScanner(ctx context.Context, bf *bloomFilter, upd
 					}
 				}()
 				// Start scanner. Blocks until done.
+				globalScannerHealth.started(erObj.poolIndex, erObj.setIndex, UTCNow())
 				err := erObj.nsScanner(ctx, allBuckets, bf, wantCycle, updates)
+				globalScannerHealth.finished(erObj.poolIndex, erObj.setIndex, err, UTCNow())
 				if err != nil {
 					logger.LogIf(ctx, err)
 					mu.Lock()
@@ -609,7 +649,8 @@ func (z *erasureServerPools) NSScanner(ctx context.Context, bf *bloomFilter, upd
 // even if one of the sets fail to create buckets, we proceed all the successful
 // operations.
 func (z *erasureServerPools) MakeBucketWithLocation(ctx context.Context, bucket string, opts BucketOptions) error {
-	g := errgroup.WithNErrs(len(z.serverPools))
+	serverPools := z.currentPools()
+	g := errgroup.WithNErrs(len(serverPools))
 
 	// Lock the bucket name before creating.
 	lk := z.NewNSLock(minioMetaTmpBucket, bucket+".lck")
@@ -621,10 +662,10 @@ func (z *erasureServerPools) MakeBucketWithLocation(ctx context.Context, bucket
 	defer lk.Unlock(lkctx.Cancel)
 
 	// Create buckets in parallel across all sets.
-	for index := range z.serverPools {
+	for index := range serverPools {
 		index := index
 		g.Go(func() error {
-			return z.serverPools[index].MakeBucketWithLocation(ctx, bucket, opts)
+			return serverPools[index].MakeBucketWithLocation(ctx, bucket, opts)
 		}, index)
 	}
 
@@ -663,10 +704,39 @@ func (z *erasureServerPools) GetObjectNInfo(ctx context.Context, bucket, object
 		return nil, err
 	}
 
+	// Small hot objects are served from memory.
+	if objectMemCacheable(bucket, h, opts) && globalObjectMemCache.enabled() {
+		if gr, err = globalObjectMemCache.getObjectNInfo(bucket, object, rs, opts); gr != nil || err != nil {
+			return gr, err
+		}
+		if rs == nil {
+			defer func(gen uint64) {
+				if err == nil {
+					globalObjectMemCache.fill(bucket, object, gen, gr)
+				}
+			}(globalObjectMemCache.generation(bucket, object))
+		}
+	}
+
+	if t := requestTimingsFromContext(ctx); t != nil {
+		defer func(start time.Time) {
+			t.storageReadDone(start)
+			if gr != nil {
+				gr.Reader = &timedStorageReader{Reader: gr.Reader, t: t}
+			}
+		}(time.Now())
+	}
+
+	if bucketDedupConfig(bucket) != nil {
+		if gr, err = z.getDedupObjectNInfo(ctx, bucket, object, rs, h, opts); gr != nil || err != nil {
+			return gr, err
+		}
+	}
+
 	object = encodeDirObject(object)
 
 	if z.SinglePool() {
-		return z.serverPools[0].GetObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+		return z.currentPools()[0].GetObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
 	}
 
 	var unlockOnDefer bool
@@ -724,21 +794,22 @@ func (z *erasureServerPools) GetObjectNInfo(ctx context.Context, bucket, object
 	}
 
 	lockType = noLock // do not take locks at lower levels for GetObjectNInfo()
-	return z.serverPools[zIdx].GetObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
+	return z.currentPools()[zIdx].GetObjectNInfo(ctx, bucket, object, rs, h, lockType, opts)
 }
 
 // getLatestObjectInfoWithIdx returns the objectInfo of the latest object from multiple pools (this function
 // is present in-case there were duplicate writes to both pools, this function also returns the
 // additional index where the latest object exists, that is used to start the GetObject stream.
 func (z *erasureServerPools) getLatestObjectInfoWithIdx(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, int, error) {
+	serverPools := z.currentPools()
 	object = encodeDirObject(object)
 	results := make([]struct {
 		zIdx int
 		oi   ObjectInfo
 		err  error
-	}, len(z.serverPools))
+	}, len(serverPools))
 	var wg sync.WaitGroup
-	for i, pool := range z.serverPools {
+	for i, pool := range serverPools {
 		wg.Add(1)
 		go func(i int, pool *erasureSets) {
 			defer wg.Done()
@@ -785,10 +856,23 @@ func (z *erasureServerPools) GetObjectInfo(ctx context.Context, bucket, object s
 		return objInfo, err
 	}
 
+	if objectMemCacheable(bucket, nil, opts) {
+		if objInfo, _, ok := globalObjectMemCache.get(bucket, object); ok {
+			return objInfo, nil
+		}
+	}
+
+	defer requestTimingsFromContext(ctx).storageReadDone(time.Now())
+
 	object = encodeDirObject(object)
 
+	// Misses confirmed by the key filters need no lock.
+	if z.keyFilterMissing(ctx, bucket, object, opts) {
+		return objInfo, toObjectErr(errFileNotFound, bucket, object)
+	}
+
 	if z.SinglePool() {
-		return z.serverPools[0].GetObjectInfo(ctx, bucket, object, opts)
+		return z.currentPools()[0].GetObjectInfo(ctx, bucket, object, opts)
 	}
 
 	if !opts.NoLock {
@@ -815,14 +899,25 @@ func (z *erasureServerPools) PutObject(ctx context.Context, bucket string, objec
 		return ObjectInfo{}, err
 	}
 
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
+	ctx = bucketFsyncContext(ctx, bucket)
+	applyBucketClass(bucket, &opts)
+
+	if canDedup(bucketDedupConfig(bucket), data, opts) {
+		return z.putDedupObject(ctx, bucket, object, data, opts)
+	}
+
 	object = encodeDirObject(object)
 
 	if z.SinglePool() {
-		if !isMinioMetaBucketName(bucket) && !hasSpaceFor(getDiskInfos(ctx, z.serverPools[0].getHashedSet(object).getDisks()), data.Size()) {
+		if !z.hasSetSpaceFor(ctx, 0, bucket, object, data.Size()) {
 			return ObjectInfo{}, toObjectErr(errDiskFull)
 		}
-		return z.serverPools[0].PutObject(ctx, bucket, object, data, opts)
+		return z.currentPools()[0].PutObject(ctx, bucket, object, data, opts)
 	}
+
+	locked := !opts.NoLock
 	if !opts.NoLock {
 		ns := z.NewNSLock(bucket, object)
 		lkctx, err := ns.GetLock(ctx, globalOperationTimeout)
@@ -839,12 +934,58 @@ func (z *erasureServerPools) PutObject(ctx context.Context, bucket string, objec
 		return ObjectInfo{}, err
 	}
 
+	if !z.hasSetSpaceFor(ctx, idx, bucket, object, data.Size()) {
+		// Reject the overwrite before writing, unless the object is
+		// unversioned and can move to a pool with space.
+		newIdx := -1
+		if locked && !opts.Versioned && !opts.VersionSuspended {
+			newIdx = z.getAvailablePoolIdx(ctx, bucket, object, data.Size())
+		}
+		if newIdx < 0 || newIdx == idx {
+			return ObjectInfo{}, toObjectErr(errDiskFull)
+		}
+		return z.moveObject(ctx, idx, newIdx, bucket, object, data, opts)
+	}
+
 	// Overwrite the object at the right pool
-	return z.serverPools[idx].PutObject(ctx, bucket, object, data, opts)
+	return z.currentPools()[idx].PutObject(ctx, bucket, object, data, opts)
+}
+
+// moveObject writes the unversioned object to the pool newIdx and
+// deletes its previous copy from the pool idx, the caller must hold the
+// object lock. The upload fails, and the new copy is deleted again, if
+// the previous copy cannot be deleted, the object is never left in two
+// pools.
+func (z *erasureServerPools) moveObject(ctx context.Context, idx, newIdx int, bucket, object string, data *PutObjReader, opts ObjectOptions) (ObjectInfo, error) {
+	serverPools := z.currentPools()
+	objInfo, err := serverPools[newIdx].PutObject(ctx, bucket, object, data, opts)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	_, err = serverPools[idx].DeleteObject(ctx, bucket, object, ObjectOptions{NoLock: true})
+	if err == nil || isErrObjectNotFound(err) {
+		return objInfo, nil
+	}
+	if _, derr := serverPools[newIdx].DeleteObject(ctx, bucket, object, ObjectOptions{NoLock: true}); derr != nil {
+		// The newer copy takes precedence over the previous one
+		// until the object is written or deleted again.
+		logger.LogIf(ctx, fmt.Errorf("unable to remove %s/%s from pool %d: %w", bucket, object, newIdx, derr))
+	}
+	return ObjectInfo{}, toObjectErr(err, bucket, object)
+}
+
+// hasSetSpaceFor returns whether the set of object in the pool idx has
+// space for size bytes below the disk high watermark. Unknown sizes are
+// negative.
+func (z *erasureServerPools) hasSetSpaceFor(ctx context.Context, idx int, bucket, object string, size int64) bool {
+	if isMinioMetaBucketName(bucket) {
+		return true
+	}
+	return hasSpaceFor(getDiskInfos(ctx, z.currentPools()[idx].getHashedSet(object).getDisks()), size)
 }
 
 func (z *erasureServerPools) deletePrefix(ctx context.Context, bucket string, prefix string) error {
-	for _, zone := range z.serverPools {
+	for _, zone := range z.currentPools() {
 		_, err := zone.DeleteObject(ctx, bucket, prefix, ObjectOptions{DeletePrefix: true})
 		if err != nil {
 			return err
@@ -860,12 +1001,15 @@ func (z *erasureServerPools) DeleteObject(ctx context.Context, bucket string, ob
 
 	if opts.DeletePrefix {
 		err := z.deletePrefix(ctx, bucket, object)
+		invalidateObjectMemCache(ctx, bucket, []string{object}, true)
 		return ObjectInfo{}, err
 	}
 
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].DeleteObject(ctx, bucket, object, opts)
+		return z.currentPools()[0].DeleteObject(ctx, bucket, object, opts)
 	}
 
 	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
@@ -873,7 +1017,7 @@ func (z *erasureServerPools) DeleteObject(ctx context.Context, bucket string, ob
 		return objInfo, err
 	}
 
-	return z.serverPools[idx].DeleteObject(ctx, bucket, object, opts)
+	return z.currentPools()[idx].DeleteObject(ctx, bucket, object, opts)
 }
 
 func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, objects []ObjectToDelete, opts ObjectOptions) ([]DeletedObject, []error) {
@@ -887,6 +1031,14 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 		objSets.Add(objects[i].ObjectName)
 	}
 
+	defer func() {
+		names := make([]string, 0, len(objects))
+		for _, object := range objects {
+			names = append(names, decodeDirObject(object.ObjectName))
+		}
+		invalidateObjectMemCache(ctx, bucket, names, false)
+	}()
+
 	// Acquire a bulk write lock across 'objects'
 	multiDeleteLock := z.NewNSLock(bucket, objSets.ToSlice()...)
 	lkctx, err := multiDeleteLock.GetLock(ctx, globalOperationTimeout)
@@ -900,7 +1052,7 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 	defer multiDeleteLock.Unlock(lkctx.Cancel)
 
 	if z.SinglePool() {
-		deleteObjects, dErrs := z.serverPools[0].DeleteObjects(ctx, bucket, objects, opts)
+		deleteObjects, dErrs := z.currentPools()[0].DeleteObjects(ctx, bucket, objects, opts)
 		for i := range deleteObjects {
 			deleteObjects[i].ObjectName = decodeDirObject(deleteObjects[i].ObjectName)
 		}
@@ -945,8 +1097,9 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 
 	// Delete concurrently in all server pools.
 	var wg sync.WaitGroup
-	wg.Add(len(z.serverPools))
-	for idx, pool := range z.serverPools {
+	pools := z.currentPools()
+	wg.Add(len(pools))
+	for idx, pool := range pools {
 		go func(idx int, pool *erasureSets) {
 			defer wg.Done()
 			objs := poolObjIdxMap[idx]
@@ -971,6 +1124,8 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 }
 
 func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string, srcInfo ObjectInfo, srcOpts, dstOpts ObjectOptions) (objInfo ObjectInfo, err error) {
+	defer invalidateObjectMemCache(ctx, dstBucket, []string{dstObject}, false)
+
 	srcObject = encodeDirObject(srcObject)
 	dstObject = encodeDirObject(dstObject)
 
@@ -992,15 +1147,21 @@ func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObjec
 		return objInfo, err
 	}
 
+	// The destination is locked, check its preconditions once for all
+	// the ways of copying below.
+	if err = z.currentPools()[poolIdx].getHashedSet(dstObject).checkWritePrecondition(ctx, dstBucket, dstObject, dstOpts); err != nil {
+		return objInfo, err
+	}
+
 	if cpSrcDstSame && srcInfo.metadataOnly {
 		// Version ID is set for the destination and source == destination version ID.
 		if dstOpts.VersionID != "" && srcOpts.VersionID == dstOpts.VersionID {
-			return z.serverPools[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
+			return z.currentPools()[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
 		}
 		// Destination is not versioned and source version ID is empty
 		// perform an in-place update.
 		if !dstOpts.Versioned && srcOpts.VersionID == "" {
-			return z.serverPools[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
+			return z.currentPools()[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
 		}
 		// Destination is versioned, source is not destination version,
 		// as a special case look for if the source object is not legacy
@@ -1010,7 +1171,7 @@ func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObjec
 			// CopyObject optimization where we don't create an entire copy
 			// of the content, instead we add a reference.
 			srcInfo.versionOnly = true
-			return z.serverPools[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
+			return z.currentPools()[poolIdx].CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
 		}
 	}
 
@@ -1022,8 +1183,19 @@ func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObjec
 		MTime:                dstOpts.MTime,
 		NoLock:               true,
 	}
+	ctx = bucketFsyncContext(ctx, dstBucket)
+	applyBucketClass(dstBucket, &putOpts)
+
+	// The shards copied keep the parity of the source object, the objects of
+	// low latency buckets are written again with their reduced parity.
+	if !cpSrcDstSame && !lowLatencyBucket(dstBucket) {
+		objInfo, err = z.copyObjectShards(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, poolIdx, putOpts)
+		if err != errCopyNeedsRewrite {
+			return objInfo, err
+		}
+	}
 
-	return z.serverPools[poolIdx].PutObject(ctx, dstBucket, dstObject, srcInfo.PutObjReader, putOpts)
+	return z.currentPools()[poolIdx].PutObject(ctx, dstBucket, dstObject, srcInfo.PutObjReader, putOpts)
 }
 
 func (z *erasureServerPools) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (ListObjectsV2Info, error) {
@@ -1124,6 +1296,11 @@ func (z *erasureServerPools) ListObjects(ctx context.Context, bucket, prefix, ma
 		}
 	}
 
+	// Prefixes with the most direct children are served by their index.
+	if iloi, ok := listObjectsFromIndex(ctx, z, bucket, prefix, marker, delimiter, maxKeys); ok {
+		return iloi, nil
+	}
+
 	opts := listPathOptions{
 		Bucket:      bucket,
 		Prefix:      prefix,
@@ -1164,12 +1341,13 @@ func (z *erasureServerPools) ListObjects(ctx context.Context, bucket, prefix, ma
 }
 
 func (z *erasureServerPools) ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	serverPools := z.currentPools()
 	if err := checkListMultipartArgs(ctx, bucket, prefix, keyMarker, uploadIDMarker, delimiter, z); err != nil {
 		return ListMultipartsInfo{}, err
 	}
 
 	if z.SinglePool() {
-		return z.serverPools[0].ListMultipartUploads(ctx, bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+		return serverPools[0].ListMultipartUploads(ctx, bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
 	}
 
 	var poolResult = ListMultipartsInfo{}
@@ -1177,7 +1355,7 @@ func (z *erasureServerPools) ListMultipartUploads(ctx context.Context, bucket, p
 	poolResult.KeyMarker = keyMarker
 	poolResult.Prefix = prefix
 	poolResult.Delimiter = delimiter
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		result, err := pool.ListMultipartUploads(ctx, bucket, prefix, keyMarker, uploadIDMarker,
 			delimiter, maxUploads)
 		if err != nil {
@@ -1194,14 +1372,16 @@ func (z *erasureServerPools) NewMultipartUpload(ctx context.Context, bucket, obj
 		return "", err
 	}
 
+	applyBucketClass(bucket, &opts)
+
 	if z.SinglePool() {
-		if !isMinioMetaBucketName(bucket) && !hasSpaceFor(getDiskInfos(ctx, z.serverPools[0].getHashedSet(object).getDisks()), -1) {
+		if !z.hasSetSpaceFor(ctx, 0, bucket, object, -1) {
 			return "", toObjectErr(errDiskFull)
 		}
-		return z.serverPools[0].NewMultipartUpload(ctx, bucket, object, opts)
+		return z.currentPools()[0].NewMultipartUpload(ctx, bucket, object, opts)
 	}
 
-	for idx, pool := range z.serverPools {
+	for idx, pool := range z.currentPools() {
 		result, err := pool.ListMultipartUploads(ctx, bucket, object, "", "", "", maxUploadsList)
 		if err != nil {
 			return "", err
@@ -1210,7 +1390,10 @@ func (z *erasureServerPools) NewMultipartUpload(ctx context.Context, bucket, obj
 		// create the new multipart in the same pool, this will avoid
 		// creating two multiparts uploads in two different pools
 		if len(result.Uploads) != 0 {
-			return z.serverPools[idx].NewMultipartUpload(ctx, bucket, object, opts)
+			if !z.hasSetSpaceFor(ctx, idx, bucket, object, -1) {
+				return "", toObjectErr(errDiskFull)
+			}
+			return z.currentPools()[idx].NewMultipartUpload(ctx, bucket, object, opts)
 		}
 	}
 
@@ -1220,8 +1403,11 @@ func (z *erasureServerPools) NewMultipartUpload(ctx context.Context, bucket, obj
 	if err != nil {
 		return "", err
 	}
+	if !z.hasSetSpaceFor(ctx, idx, bucket, object, -1) {
+		return "", toObjectErr(errDiskFull)
+	}
 
-	return z.serverPools[idx].NewMultipartUpload(ctx, bucket, object, opts)
+	return z.currentPools()[idx].NewMultipartUpload(ctx, bucket, object, opts)
 }
 
 // Copies a part of an object from source hashedSet to destination hashedSet.
@@ -1236,17 +1422,26 @@ func (z *erasureServerPools) CopyObjectPart(ctx context.Context, srcBucket, srcO
 
 // PutObjectPart - writes part of an object to hashedSet based on the object name.
 func (z *erasureServerPools) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *PutObjReader, opts ObjectOptions) (PartInfo, error) {
+	serverPools := z.currentPools()
 	if err := checkPutObjectPartArgs(ctx, bucket, object, z); err != nil {
 		return PartInfo{}, err
 	}
 
+	ctx = bucketFsyncContext(ctx, bucket)
+
 	if z.SinglePool() {
-		return z.serverPools[0].PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
+		if !z.hasSetSpaceFor(ctx, 0, bucket, object, data.Size()) {
+			return PartInfo{}, toObjectErr(errDiskFull)
+		}
+		return serverPools[0].PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
 	}
 
-	for _, pool := range z.serverPools {
+	for idx, pool := range serverPools {
 		_, err := pool.GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 		if err == nil {
+			if !z.hasSetSpaceFor(ctx, idx, bucket, object, data.Size()) {
+				return PartInfo{}, toObjectErr(errDiskFull)
+			}
 			return pool.PutObjectPart(ctx, bucket, object, uploadID, partID, data, opts)
 		}
 		switch err.(type) {
@@ -1266,14 +1461,15 @@ func (z *erasureServerPools) PutObjectPart(ctx context.Context, bucket, object,
 }
 
 func (z *erasureServerPools) GetMultipartInfo(ctx context.Context, bucket, object, uploadID string, opts ObjectOptions) (MultipartInfo, error) {
+	serverPools := z.currentPools()
 	if err := checkListPartsArgs(ctx, bucket, object, z); err != nil {
 		return MultipartInfo{}, err
 	}
 
 	if z.SinglePool() {
-		return z.serverPools[0].GetMultipartInfo(ctx, bucket, object, uploadID, opts)
+		return serverPools[0].GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 	}
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		mi, err := pool.GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 		if err == nil {
 			return mi, nil
@@ -1296,14 +1492,15 @@ func (z *erasureServerPools) GetMultipartInfo(ctx context.Context, bucket, objec
 
 // ListObjectParts - lists all uploaded parts to an object in hashedSet.
 func (z *erasureServerPools) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int, opts ObjectOptions) (ListPartsInfo, error) {
+	serverPools := z.currentPools()
 	if err := checkListPartsArgs(ctx, bucket, object, z); err != nil {
 		return ListPartsInfo{}, err
 	}
 
 	if z.SinglePool() {
-		return z.serverPools[0].ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts, opts)
+		return serverPools[0].ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts, opts)
 	}
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		_, err := pool.GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 		if err == nil {
 			return pool.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, maxParts, opts)
@@ -1323,15 +1520,16 @@ func (z *erasureServerPools) ListObjectParts(ctx context.Context, bucket, object
 
 // Aborts an in-progress multipart operation on hashedSet based on the object name.
 func (z *erasureServerPools) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string, opts ObjectOptions) error {
+	serverPools := z.currentPools()
 	if err := checkAbortMultipartArgs(ctx, bucket, object, z); err != nil {
 		return err
 	}
 
 	if z.SinglePool() {
-		return z.serverPools[0].AbortMultipartUpload(ctx, bucket, object, uploadID, opts)
+		return serverPools[0].AbortMultipartUpload(ctx, bucket, object, uploadID, opts)
 	}
 
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		_, err := pool.GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 		if err == nil {
 			return pool.AbortMultipartUpload(ctx, bucket, object, uploadID, opts)
@@ -1352,15 +1550,20 @@ func (z *erasureServerPools) AbortMultipartUpload(ctx context.Context, bucket, o
 
 // CompleteMultipartUpload - completes a pending multipart transaction, on hashedSet based on object name.
 func (z *erasureServerPools) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []CompletePart, opts ObjectOptions) (objInfo ObjectInfo, err error) {
+	serverPools := z.currentPools()
 	if err = checkCompleteMultipartArgs(ctx, bucket, object, z); err != nil {
 		return objInfo, err
 	}
 
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
+	ctx = bucketFsyncContext(ctx, bucket)
+
 	if z.SinglePool() {
-		return z.serverPools[0].CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
+		return serverPools[0].CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
 	}
 
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		_, err := pool.GetMultipartInfo(ctx, bucket, object, uploadID, opts)
 		if err == nil {
 			return pool.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, opts)
@@ -1376,8 +1579,9 @@ func (z *erasureServerPools) CompleteMultipartUpload(ctx context.Context, bucket
 
 // GetBucketInfo - returns bucket info from one of the erasure coded serverPools.
 func (z *erasureServerPools) GetBucketInfo(ctx context.Context, bucket string) (bucketInfo BucketInfo, err error) {
+	serverPools := z.currentPools()
 	if z.SinglePool() {
-		bucketInfo, err = z.serverPools[0].GetBucketInfo(ctx, bucket)
+		bucketInfo, err = serverPools[0].GetBucketInfo(ctx, bucket)
 		if err != nil {
 			return bucketInfo, err
 		}
@@ -1387,7 +1591,7 @@ func (z *erasureServerPools) GetBucketInfo(ctx context.Context, bucket string) (
 		}
 		return bucketInfo, nil
 	}
-	for _, pool := range z.serverPools {
+	for _, pool := range serverPools {
 		bucketInfo, err = pool.GetBucketInfo(ctx, bucket)
 		if err != nil {
 			if isErrBucketNotFound(err) {
@@ -1434,13 +1638,16 @@ func (z *erasureServerPools) IsTaggingSupported() bool {
 // even if one of the serverPools fail to delete buckets, we proceed to
 // undo a successful operation.
 func (z *erasureServerPools) DeleteBucket(ctx context.Context, bucket string, opts DeleteBucketOptions) error {
-	g := errgroup.WithNErrs(len(z.serverPools))
+	defer invalidateObjectMemCache(ctx, bucket, []string{""}, true)
+
+	pools := z.currentPools()
+	g := errgroup.WithNErrs(len(pools))
 
 	// Delete buckets in parallel across all serverPools.
-	for index := range z.serverPools {
+	for index := range pools {
 		index := index
 		g.Go(func() error {
-			return z.serverPools[index].DeleteBucket(ctx, bucket, opts)
+			return pools[index].DeleteBucket(ctx, bucket, opts)
 		}, index)
 	}
 
@@ -1451,7 +1658,7 @@ func (z *erasureServerPools) DeleteBucket(ctx context.Context, bucket string, op
 	for _, err := range errs {
 		if err != nil {
 			if !z.SinglePool() && !opts.NoRecreate {
-				undoDeleteBucketServerPools(context.Background(), bucket, z.serverPools, errs)
+				undoDeleteBucketServerPools(context.Background(), bucket, pools, errs)
 			}
 			return err
 		}
@@ -1470,7 +1677,7 @@ func (z *erasureServerPools) DeleteBucket(ctx context.Context, bucket string, op
 // data is not distributed across sets. Errors are logged but individual
 // disk failures are not returned.
 func (z *erasureServerPools) renameAll(ctx context.Context, bucket, prefix string) {
-	for _, servers := range z.serverPools {
+	for _, servers := range z.currentPools() {
 		for _, set := range servers.sets {
 			set.renameAll(ctx, bucket, prefix)
 		}
@@ -1499,10 +1706,11 @@ func undoDeleteBucketServerPools(ctx context.Context, bucket string, serverPools
 // sort here just for simplification. As per design it is assumed
 // that all buckets are present on all serverPools.
 func (z *erasureServerPools) ListBuckets(ctx context.Context) (buckets []BucketInfo, err error) {
+	serverPools := z.currentPools()
 	if z.SinglePool() {
-		buckets, err = z.serverPools[0].ListBuckets(ctx)
+		buckets, err = serverPools[0].ListBuckets(ctx)
 	} else {
-		for _, pool := range z.serverPools {
+		for _, pool := range serverPools {
 			buckets, err = pool.ListBuckets(ctx)
 			if err != nil {
 				logger.LogIf(ctx, err)
@@ -1538,8 +1746,9 @@ func (z *erasureServerPools) HealFormat(ctx context.Context, dryRun bool) (madmi
 		Detail: "disk-format",
 	}
 
+	pools := z.currentPools()
 	var countNoHeal int
-	for _, pool := range z.serverPools {
+	for _, pool := range pools {
 		result, err := pool.HealFormat(ctx, dryRun)
 		if err != nil && !errors.Is(err, errNoHealRequired) {
 			logger.LogIf(ctx, err)
@@ -1557,7 +1766,7 @@ func (z *erasureServerPools) HealFormat(ctx context.Context, dryRun bool) (madmi
 	}
 
 	// No heal returned by all serverPools, return errNoHealRequired
-	if countNoHeal == len(z.serverPools) {
+	if countNoHeal == len(pools) {
 		return r, errNoHealRequired
 	}
 
@@ -1573,7 +1782,7 @@ func (z *erasureServerPools) HealBucket(ctx context.Context, bucket string, opts
 	// Attempt heal on the bucket metadata, ignore any failures
 	_, _ = z.HealObject(ctx, minioMetaBucket, pathJoin(bucketConfigPrefix, bucket, bucketMetadataFile), "", opts)
 
-	for _, pool := range z.serverPools {
+	for _, pool := range z.currentPools() {
 		result, err := pool.HealBucket(ctx, bucket, opts)
 		if err != nil {
 			switch err.(type) {
@@ -1608,7 +1817,7 @@ func (z *erasureServerPools) Walk(ctx context.Context, bucket, prefix string, re
 		defer cancel()
 		defer close(results)
 
-		for _, erasureSet := range z.serverPools {
+		for _, erasureSet := range z.currentPools() {
 			var wg sync.WaitGroup
 			for _, set := range erasureSet.sets {
 				set := set
@@ -1695,7 +1904,7 @@ func (z *erasureServerPools) HealObjects(ctx context.Context, bucket, prefix str
 		defer close(errCh)
 		defer cancel()
 
-		for _, erasureSet := range z.serverPools {
+		for _, erasureSet := range z.currentPools() {
 			var wg sync.WaitGroup
 			for _, set := range erasureSet.sets {
 				set := set
@@ -1800,7 +2009,7 @@ func (z *erasureServerPools) HealObjects(ctx context.Context, bucket, prefix str
 func (z *erasureServerPools) HealObject(ctx context.Context, bucket, object, versionID string, opts madmin.HealOpts) (madmin.HealResultItem, error) {
 	object = encodeDirObject(object)
 
-	for _, pool := range z.serverPools {
+	for _, pool := range z.currentPools() {
 		result, err := pool.HealObject(ctx, bucket, object, versionID, opts)
 		result.Object = decodeDirObject(result.Object)
 		if err != nil {
@@ -1828,8 +2037,9 @@ func (z *erasureServerPools) GetMetrics(ctx context.Context) (*BackendMetrics, e
 }
 
 func (z *erasureServerPools) getPoolAndSet(id string) (poolIdx, setIdx, diskIdx int, err error) {
-	for poolIdx := range z.serverPools {
-		format := z.serverPools[poolIdx].format
+	serverPools := z.currentPools()
+	for poolIdx := range serverPools {
+		format := serverPools[poolIdx].format
 		for setIdx, set := range format.Erasure.Sets {
 			for i, diskID := range set {
 				if diskID == id {
@@ -1854,13 +2064,27 @@ type HealthResult struct {
 	HealingDrives int
 	PoolID, SetID int
 	WriteQuorum   int
+	ESHealth      []ErasureSetHealth
+}
+
+// ErasureSetHealth - the online drives of an erasure set and how many
+// more of them may go offline before its read or write quorum is lost.
+type ErasureSetHealth struct {
+	PoolID       int `json:"pool"`
+	SetID        int `json:"set"`
+	OnlineDrives int `json:"onlineDrives"`
+	ReadQuorum   int `json:"readQuorum"`
+	WriteQuorum  int `json:"writeQuorum"`
+	ReadMargin   int `json:"readMargin"`
+	WriteMargin  int `json:"writeMargin"`
 }
 
 // ReadHealth returns if the cluster can serve read requests
 func (z *erasureServerPools) ReadHealth(ctx context.Context) bool {
-	erasureSetUpCount := make([][]int, len(z.serverPools))
-	for i := range z.serverPools {
-		erasureSetUpCount[i] = make([]int, len(z.serverPools[i].sets))
+	serverPools := z.currentPools()
+	erasureSetUpCount := make([][]int, len(serverPools))
+	for i := range serverPools {
+		erasureSetUpCount[i] = make([]int, len(serverPools[i].sets))
 	}
 
 	diskIDs := globalNotificationSys.GetLocalDiskIDs(ctx)
@@ -1895,9 +2119,10 @@ func (z *erasureServerPools) ReadHealth(ctx context.Context) bool {
 // can be used to query scenarios if health may be lost
 // if this node is taken down by an external orchestrator.
 func (z *erasureServerPools) Health(ctx context.Context, opts HealthOptions) HealthResult {
-	erasureSetUpCount := make([][]int, len(z.serverPools))
-	for i := range z.serverPools {
-		erasureSetUpCount[i] = make([]int, len(z.serverPools[i].sets))
+	serverPools := z.currentPools()
+	erasureSetUpCount := make([][]int, len(serverPools))
+	for i := range serverPools {
+		erasureSetUpCount[i] = make([]int, len(serverPools[i].sets))
 	}
 
 	diskIDs := globalNotificationSys.GetLocalDiskIDs(ctx)
@@ -1918,10 +2143,18 @@ func (z *erasureServerPools) Health(ctx context.Context, opts HealthOptions) Hea
 
 	reqInfo := (&logger.ReqInfo{}).AppendTags("maintenance", strconv.FormatBool(opts.Maintenance))
 
+	// The data drives of the sets of each pool can differ.
 	b := z.BackendInfo()
-	writeQuorum := b.StandardSCData[0]
-	if writeQuorum == b.StandardSCParity {
-		writeQuorum++
+	poolWriteQuorums := make([]int, len(b.StandardSCData))
+	maximumWriteQuorum := 0
+	for i, data := range b.StandardSCData {
+		poolWriteQuorums[i] = data
+		if data == b.StandardSCParity {
+			poolWriteQuorums[i] = data + 1
+		}
+		if poolWriteQuorums[i] > maximumWriteQuorum {
+			maximumWriteQuorum = poolWriteQuorums[i]
+		}
 	}
 
 	var aggHealStateResult madmin.BgHealState
@@ -1943,7 +2176,24 @@ func (z *erasureServerPools) Health(ctx context.Context, opts HealthOptions) Hea
 		}
 	}
 
+	var esHealth []ErasureSetHealth
+	for poolIdx := range erasureSetUpCount {
+		readQuorum, writeQuorum := b.StandardSCData[poolIdx], poolWriteQuorums[poolIdx]
+		for setIdx, online := range erasureSetUpCount[poolIdx] {
+			esHealth = append(esHealth, ErasureSetHealth{
+				PoolID:       poolIdx,
+				SetID:        setIdx,
+				OnlineDrives: online,
+				ReadQuorum:   readQuorum,
+				WriteQuorum:  writeQuorum,
+				ReadMargin:   online - readQuorum,
+				WriteMargin:  online - writeQuorum,
+			})
+		}
+	}
+
 	for poolIdx := range erasureSetUpCount {
+		writeQuorum := poolWriteQuorums[poolIdx]
 		for setIdx := range erasureSetUpCount[poolIdx] {
 			if erasureSetUpCount[poolIdx][setIdx] < writeQuorum {
 				logger.LogIf(logger.SetReqInfo(ctx, reqInfo),
@@ -1955,6 +2205,7 @@ func (z *erasureServerPools) Health(ctx context.Context, opts HealthOptions) Hea
 					PoolID:        poolIdx,
 					SetID:         setIdx,
 					WriteQuorum:   writeQuorum,
+					ESHealth:      esHealth,
 				}
 			}
 		}
@@ -1965,22 +2216,26 @@ func (z *erasureServerPools) Health(ctx context.Context, opts HealthOptions) Hea
 	if !opts.Maintenance {
 		return HealthResult{
 			Healthy:     true,
-			WriteQuorum: writeQuorum,
+			WriteQuorum: maximumWriteQuorum,
+			ESHealth:    esHealth,
 		}
 	}
 
 	return HealthResult{
 		Healthy:       len(aggHealStateResult.HealDisks) == 0,
 		HealingDrives: len(aggHealStateResult.HealDisks),
-		WriteQuorum:   writeQuorum,
+		WriteQuorum:   maximumWriteQuorum,
+		ESHealth:      esHealth,
 	}
 }
 
 // PutObjectMetadata - replace or add tags to an existing object
 func (z *erasureServerPools) PutObjectMetadata(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].PutObjectMetadata(ctx, bucket, object, opts)
+		return z.currentPools()[0].PutObjectMetadata(ctx, bucket, object, opts)
 	}
 
 	// We don't know the size here set 1GiB atleast.
@@ -1989,14 +2244,16 @@ func (z *erasureServerPools) PutObjectMetadata(ctx context.Context, bucket, obje
 		return ObjectInfo{}, err
 	}
 
-	return z.serverPools[idx].PutObjectMetadata(ctx, bucket, object, opts)
+	return z.currentPools()[idx].PutObjectMetadata(ctx, bucket, object, opts)
 }
 
 // PutObjectTags - replace or add tags to an existing object
 func (z *erasureServerPools) PutObjectTags(ctx context.Context, bucket, object string, tags string, opts ObjectOptions) (ObjectInfo, error) {
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].PutObjectTags(ctx, bucket, object, tags, opts)
+		return z.currentPools()[0].PutObjectTags(ctx, bucket, object, tags, opts)
 	}
 
 	// We don't know the size here set 1GiB atleast.
@@ -2005,14 +2262,16 @@ func (z *erasureServerPools) PutObjectTags(ctx context.Context, bucket, object s
 		return ObjectInfo{}, err
 	}
 
-	return z.serverPools[idx].PutObjectTags(ctx, bucket, object, tags, opts)
+	return z.currentPools()[idx].PutObjectTags(ctx, bucket, object, tags, opts)
 }
 
 // DeleteObjectTags - delete object tags from an existing object
 func (z *erasureServerPools) DeleteObjectTags(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].DeleteObjectTags(ctx, bucket, object, opts)
+		return z.currentPools()[0].DeleteObjectTags(ctx, bucket, object, opts)
 	}
 
 	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
@@ -2020,14 +2279,14 @@ func (z *erasureServerPools) DeleteObjectTags(ctx context.Context, bucket, objec
 		return ObjectInfo{}, err
 	}
 
-	return z.serverPools[idx].DeleteObjectTags(ctx, bucket, object, opts)
+	return z.currentPools()[idx].DeleteObjectTags(ctx, bucket, object, opts)
 }
 
 // GetObjectTags - get object tags from an existing object
 func (z *erasureServerPools) GetObjectTags(ctx context.Context, bucket, object string, opts ObjectOptions) (*tags.Tags, error) {
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].GetObjectTags(ctx, bucket, object, opts)
+		return z.currentPools()[0].GetObjectTags(ctx, bucket, object, opts)
 	}
 
 	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
@@ -2035,14 +2294,16 @@ func (z *erasureServerPools) GetObjectTags(ctx context.Context, bucket, object s
 		return nil, err
 	}
 
-	return z.serverPools[idx].GetObjectTags(ctx, bucket, object, opts)
+	return z.currentPools()[idx].GetObjectTags(ctx, bucket, object, opts)
 }
 
 // TransitionObject - transition object content to target tier.
 func (z *erasureServerPools) TransitionObject(ctx context.Context, bucket, object string, opts ObjectOptions) error {
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].TransitionObject(ctx, bucket, object, opts)
+		return z.currentPools()[0].TransitionObject(ctx, bucket, object, opts)
 	}
 
 	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
@@ -2050,14 +2311,16 @@ func (z *erasureServerPools) TransitionObject(ctx context.Context, bucket, objec
 		return err
 	}
 
-	return z.serverPools[idx].TransitionObject(ctx, bucket, object, opts)
+	return z.currentPools()[idx].TransitionObject(ctx, bucket, object, opts)
 }
 
 // RestoreTransitionedObject - restore transitioned object content locally on this cluster.
 func (z *erasureServerPools) RestoreTransitionedObject(ctx context.Context, bucket, object string, opts ObjectOptions) error {
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
 	object = encodeDirObject(object)
 	if z.SinglePool() {
-		return z.serverPools[0].RestoreTransitionedObject(ctx, bucket, object, opts)
+		return z.currentPools()[0].RestoreTransitionedObject(ctx, bucket, object, opts)
 	}
 
 	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
@@ -2065,5 +2328,5 @@ func (z *erasureServerPools) RestoreTransitionedObject(ctx context.Context, buck
 		return err
 	}
 
-	return z.serverPools[idx].RestoreTransitionedObject(ctx, bucket, object, opts)
+	return z.currentPools()[idx].RestoreTransitionedObject(ctx, bucket, object, opts)
 }
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestMoveObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	other, otherDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(otherDirs)
+
+	z := objLayer.(*erasureServerPools)
+	z.serverPools = append(z.serverPools, other.(*erasureServerPools).serverPools[0])
+	if err = z.MakeBucketWithLocation(ctx, "bucket", BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("previous")
+	if _, err = z.serverPools[0].PutObject(ctx, "bucket", "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data = []byte("moved")
+	objInfo, err := z.moveObject(ctx, 0, 1, "bucket", "object", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{NoLock: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data)) {
+		t.Errorf("unexpected object %+v", objInfo)
+	}
+
+	// The previous copy is gone once the upload returns.
+	if _, err = z.serverPools[0].GetObjectInfo(ctx, "bucket", "object", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Errorf("expected the previous copy to be deleted, got %v", err)
+	}
+	if _, err = z.serverPools[1].GetObjectInfo(ctx, "bucket", "object", ObjectOptions{}); err != nil {
+		t.Errorf("expected the object in the other pool, got %v", err)
+	}
+}
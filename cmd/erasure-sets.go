@@ -423,6 +423,11 @@ func newErasureSets(ctx context.Context, endpoints Endpoints, storageDisks []Sto
 			s.erasureDisks[m][n] = disk
 		}
 
+		// The arbiter takes part in the lock quorum of all sets.
+		if globalArbiter != nil {
+			s.erasureLockers[i] = append(s.erasureLockers[i], globalArbiter)
+		}
+
 		// Initialize erasure objects for a given set.
 		s.sets[i] = &erasureObjects{
 			setIndex:              i,
@@ -436,6 +441,7 @@ func newErasureSets(ctx context.Context, endpoints Endpoints, storageDisks []Sto
 			nsMutex:               mutex,
 			bp:                    bp,
 			bpOld:                 bpOld,
+			keyFilter:             &setKeyFilter{},
 		}
 	}
 
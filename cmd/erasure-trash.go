@@ -0,0 +1,314 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio/internal/sync/errgroup"
+)
+
+// trashObject moves object to the trash of bucket in the pool holding it.
+func (z *erasureServerPools) trashObject(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	if err := checkDelObjArgs(ctx, bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
+	object = encodeDirObject(object)
+	idx, err := z.getPoolIdxExisting(ctx, bucket, object)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return z.currentPools()[idx].getHashedSet(object).trashObject(ctx, bucket, object, opts)
+}
+
+// restoreTrashedObject moves object back from the trash of bucket in
+// the pool holding it.
+func (z *erasureServerPools) restoreTrashedObject(ctx context.Context, bucket, object string) (ObjectInfo, error) {
+	if err := checkDelObjArgs(ctx, bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	defer invalidateObjectMemCache(ctx, bucket, []string{object}, false)
+
+	// Restoring must not overwrite an object written since, in any pool.
+	if _, err := z.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err == nil {
+		return ObjectInfo{}, ObjectAlreadyExists{Bucket: bucket, Object: object}
+	} else if !isErrObjectNotFound(err) {
+		return ObjectInfo{}, err
+	}
+
+	object = encodeDirObject(object)
+	idx, err := z.getPoolIdxExisting(ctx, trashBucketName(bucket), object)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(errFileNotFound, bucket, object)
+	}
+	return z.currentPools()[idx].getHashedSet(object).restoreTrashedObject(ctx, bucket, object)
+}
+
+// makeTrashVolume creates the trash volume of bucket on all disks, such
+// that it can be listed like any other bucket.
+func (z *erasureServerPools) makeTrashVolume(ctx context.Context, bucket string) error {
+	trashBucket := trashBucketName(bucket)
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
+			disks := set.getDisks()
+			g := errgroup.WithNErrs(len(disks))
+			for index := range disks {
+				index := index
+				g.Go(func() error {
+					if disks[index] == nil {
+						return errDiskNotFound
+					}
+					if err := disks[index].MakeVol(ctx, trashBucket); err != nil && err != errVolumeExists {
+						return err
+					}
+					return nil
+				}, index)
+			}
+			if err := reduceWriteQuorumErrs(ctx, g.Wait(), bucketOpIgnoredErrs, getWriteQuorum(len(disks))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// trashObject moves the latest version of object, data included, to
+// the trash volume of bucket and records when it was trashed.
+func (er erasureObjects) trashObject(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+	lk := er.NewNSLock(bucket, object)
+	lkctx, err := lk.GetLock(ctx, globalDeleteOperationTimeout)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	fi, _, _, err := er.getObjectFileInfo(ctx, bucket, object, ObjectOptions{NoLock: true}, false)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	if fi.Deleted {
+		return ObjectInfo{}, toObjectErr(errFileNotFound, bucket, object)
+	}
+	if opts.CheckPrecondFn != nil && opts.CheckPrecondFn(fi.ToObjectInfo(bucket, object)) {
+		return ObjectInfo{}, PreConditionFailed{}
+	}
+
+	trashBucket := trashBucketName(bucket)
+	if err = er.moveObject(ctx, bucket, object, trashBucket, object); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	if fi.Metadata == nil {
+		fi.Metadata = make(map[string]string)
+	}
+	fi.Metadata[trashedAtKey] = UTCNow().Format(time.RFC3339Nano)
+	if err = er.updateObjectMeta(ctx, trashBucket, object, fi); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return fi.ToObjectInfo(bucket, object), nil
+}
+
+// restoreTrashedObject moves object from the trash volume of bucket
+// back to the bucket.
+func (er erasureObjects) restoreTrashedObject(ctx context.Context, bucket, object string) (ObjectInfo, error) {
+	lk := er.NewNSLock(bucket, object)
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	trashBucket := trashBucketName(bucket)
+	if _, _, _, err = er.getObjectFileInfo(ctx, bucket, object, ObjectOptions{NoLock: true}, false); err == nil {
+		return ObjectInfo{}, ObjectAlreadyExists{Bucket: bucket, Object: object}
+	}
+	if _, _, _, err = er.getObjectFileInfo(ctx, trashBucket, object, ObjectOptions{NoLock: true}, false); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	if err = er.moveObject(ctx, trashBucket, object, bucket, object); err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+
+	fi, _, _, err := er.getObjectFileInfo(ctx, bucket, object, ObjectOptions{NoLock: true}, false)
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return fi.ToObjectInfo(bucket, object), nil
+}
+
+// xlMetaDataDirs returns the data directories the versions of the
+// xl.meta in buf refer to.
+func xlMetaDataDirs(buf []byte) ([]string, error) {
+	var xlMeta xlMetaV2
+	if err := xlMeta.Load(buf); err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, version := range xlMeta.Versions {
+		var dir string
+		switch {
+		case version.ObjectV2 != nil:
+			dir = uuid.UUID(version.ObjectV2.DataDir).String()
+		case version.ObjectV1 != nil:
+			dir = legacyDataDir
+		default:
+			continue
+		}
+		if !contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// objectMove is what was moved on a disk by moveObject, to undo it.
+type objectMove struct {
+	dirs      []string
+	moved     []string
+	metaMoved bool
+	oldMeta   []byte
+	oldDirs   []string
+}
+
+// moveObject moves the xl.meta of srcObject, with all its versions, and
+// the data directories they refer to, to dstObject in dstBucket on all
+// disks of the set. The objects nested under the name of either object
+// are left in place. The xl.meta of dstObject is replaced last, such
+// that dstObject is restored if write quorum is not met, its data
+// directories being only removed once the move succeeded.
+func (er erasureObjects) moveObject(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	disks := er.getDisks()
+	writeQuorum := getWriteQuorum(len(disks))
+	srcMeta, dstMeta := pathJoin(srcObject, xlStorageFormatFile), pathJoin(dstObject, xlStorageFormatFile)
+
+	moves := make([]objectMove, len(disks))
+	g := errgroup.WithNErrs(len(disks))
+	for index := range disks {
+		index := index
+		g.Go(func() error {
+			disk := disks[index]
+			if disk == nil {
+				return errDiskNotFound
+			}
+			m := &moves[index]
+			buf, err := disk.ReadAll(ctx, srcBucket, srcMeta)
+			if err != nil {
+				return err
+			}
+			if m.dirs, err = xlMetaDataDirs(buf); err != nil {
+				return err
+			}
+			m.oldMeta, err = disk.ReadAll(ctx, dstBucket, dstMeta)
+			switch err {
+			case nil:
+				if m.oldDirs, err = xlMetaDataDirs(m.oldMeta); err != nil {
+					return err
+				}
+			case errFileNotFound:
+			case errVolumeNotFound:
+				// Trash volumes are only created on first use.
+				if err = disk.MakeVol(ctx, dstBucket); err != nil && err != errVolumeExists {
+					return err
+				}
+			default:
+				return err
+			}
+			for _, dir := range m.dirs {
+				if contains(m.oldDirs, dir) {
+					return errFileAccessDenied
+				}
+				err = disk.RenameFile(ctx, srcBucket, retainSlash(pathJoin(srcObject, dir)), dstBucket, retainSlash(pathJoin(dstObject, dir)))
+				if err == errFileNotFound {
+					// Inlined versions have no data directory.
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				m.moved = append(m.moved, dir)
+			}
+			if err = disk.RenameFile(ctx, srcBucket, srcMeta, dstBucket, dstMeta); err != nil {
+				return err
+			}
+			m.metaMoved = true
+			return nil
+		}, index)
+	}
+
+	errs := g.Wait()
+	err := reduceWriteQuorumErrs(ctx, errs, objectOpIgnoredErrs, writeQuorum)
+	defer NSUpdated(srcBucket, srcObject)
+	defer NSUpdated(dstBucket, dstObject)
+	if err != nil {
+		undoMoveObject(disks, moves, srcBucket, srcObject, dstBucket, dstObject)
+		return err
+	}
+
+	// Remove the data directories of the previous dstObject.
+	for index, disk := range disks {
+		if disk == nil || !moves[index].metaMoved {
+			continue
+		}
+		for _, dir := range moves[index].oldDirs {
+			if !contains(moves[index].dirs, dir) {
+				disk.Delete(ctx, dstBucket, retainSlash(pathJoin(dstObject, dir)), true)
+			}
+		}
+	}
+	return nil
+}
+
+// undoMoveObject moves back what moveObject moved, restoring the
+// xl.meta of dstObject it replaced.
+func undoMoveObject(disks []StorageAPI, moves []objectMove, srcBucket, srcObject, dstBucket, dstObject string) {
+	srcMeta, dstMeta := pathJoin(srcObject, xlStorageFormatFile), pathJoin(dstObject, xlStorageFormatFile)
+	g := errgroup.WithNErrs(len(disks))
+	for index, disk := range disks {
+		if disk == nil {
+			continue
+		}
+		index, disk := index, disk
+		g.Go(func() error {
+			ctx := context.TODO()
+			m := moves[index]
+			if m.metaMoved {
+				if err := disk.RenameFile(ctx, dstBucket, dstMeta, srcBucket, srcMeta); err != nil {
+					return err
+				}
+				if m.oldMeta != nil {
+					if err := disk.WriteAll(ctx, dstBucket, dstMeta, m.oldMeta); err != nil {
+						return err
+					}
+				}
+			}
+			for _, dir := range m.moved {
+				disk.RenameFile(ctx, dstBucket, retainSlash(pathJoin(dstObject, dir)), srcBucket, retainSlash(pathJoin(srcObject, dir)))
+			}
+			return nil
+		}, index)
+	}
+	g.Wait()
+}
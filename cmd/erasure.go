@@ -70,6 +70,9 @@ type erasureObjects struct {
 	bpOld *bpool.BytePoolCap
 
 	deletedCleanupSleeper *dynamicSleeper
+
+	// Key filter of the set consulted on lookups.
+	keyFilter *setKeyFilter
 }
 
 // NewNSLock - initialize a new namespace RWLocker instance.
@@ -77,6 +80,16 @@ func (er erasureObjects) NewNSLock(bucket string, objects ...string) RWLocker {
 	return er.nsMutex.NewNSLock(er.getLockers, bucket, objects...)
 }
 
+// defaultWQuorum returns the write quorum of objects written with the
+// default parity of the set.
+func (er erasureObjects) defaultWQuorum() int {
+	dataCount := er.setDriveCount - er.defaultParityCount
+	if dataCount == er.defaultParityCount {
+		return dataCount + 1
+	}
+	return dataCount
+}
+
 // Shutdown function for object storage interface.
 func (er erasureObjects) Shutdown(ctx context.Context) error {
 	// Add any object layer shutdown activities here.
@@ -200,16 +213,7 @@ func getDisksInfo(disks []StorageAPI, endpoints []Endpoint) (disksInfo []madmin.
 					di.HealInfo = &hd
 				}
 			}
-			di.Metrics = &madmin.DiskMetrics{
-				APILatencies: make(map[string]string),
-				APICalls:     make(map[string]uint64),
-			}
-			for k, v := range info.Metrics.APILatencies {
-				di.Metrics.APILatencies[k] = v
-			}
-			for k, v := range info.Metrics.APICalls {
-				di.Metrics.APICalls[k] = v
-			}
+			di.Metrics = toMadminDiskMetrics(info.Metrics)
 			if info.Total > 0 {
 				di.Utilization = float64(info.Used / info.Total * 100)
 			}
@@ -221,6 +225,38 @@ func getDisksInfo(disks []StorageAPI, endpoints []Endpoint) (disksInfo []madmin.
 	return disksInfo, g.Wait()
 }
 
+// Keys of the drive error counters and IO latency percentiles, reported
+// along with the API calls and latencies in madmin.DiskMetrics.
+const (
+	diskMetricTotalErrors   = "TotalErrors"
+	diskMetricTotalTimeouts = "TotalTimeouts"
+	diskMetricOfflineEvents = "OfflineEvents"
+	diskMetricIOLatency     = "IO"
+)
+
+// toMadminDiskMetrics converts the metrics of a drive, its error counters
+// are added to the API calls and its IO latency percentiles, like
+// "IOReadP99", to the API latencies.
+func toMadminDiskMetrics(m DiskMetrics) *madmin.DiskMetrics {
+	dm := &madmin.DiskMetrics{
+		APILatencies: make(map[string]string, len(m.APILatencies)+len(m.IOLatencies)),
+		APICalls:     make(map[string]uint64, len(m.APICalls)+3),
+	}
+	for k, v := range m.APILatencies {
+		dm.APILatencies[k] = v
+	}
+	for k, v := range m.APICalls {
+		dm.APICalls[k] = v
+	}
+	for k, v := range m.IOLatencies {
+		dm.APILatencies[diskMetricIOLatency+k] = time.Duration(v).String()
+	}
+	dm.APICalls[diskMetricTotalErrors] = m.TotalErrors
+	dm.APICalls[diskMetricTotalTimeouts] = m.TotalTimeouts
+	dm.APICalls[diskMetricOfflineEvents] = m.OfflineEvents
+	return dm
+}
+
 // Get an aggregated storage info across all disks.
 func getStorageInfo(disks []StorageAPI, endpoints []Endpoint) (StorageInfo, []error) {
 	disksInfo, errs := getDisksInfo(disks, endpoints)
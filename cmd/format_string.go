@@ -14,11 +14,12 @@ func _() {
 	_ = x[formatLZ4-3]
 	_ = x[formatS2-4]
 	_ = x[formatBZ2-5]
+	_ = x[formatZip-6]
 }
 
-const _format_name = "UnknownGzipZstdLZ4S2BZ2"
+const _format_name = "UnknownGzipZstdLZ4S2BZ2Zip"
 
-var _format_index = [...]uint8{0, 7, 11, 15, 18, 20, 23}
+var _format_index = [...]uint8{0, 7, 11, 15, 18, 20, 23, 26}
 
 func (i format) String() string {
 	if i < 0 || i >= format(len(_format_index)-1) {
@@ -708,6 +708,11 @@ func (fs *FSObjects) CompleteMultipartUpload(ctx context.Context, bucket string,
 	ctx = lkctx.Context()
 	defer destLock.Unlock(lkctx.Cancel)
 
+	// Conditional writes are evaluated against the object under lock.
+	if err = fs.checkWritePrecondition(ctx, bucket, object, opts); err != nil {
+		return oi, err
+	}
+
 	bucketMetaDir := pathJoin(fs.fsPath, minioMetaBucket, bucketMetaPrefix)
 	fsMetaPath := pathJoin(bucketMetaDir, bucket, object, fs.metaJSONFile)
 	metaFile, err := fs.rwPool.Write(fsMetaPath)
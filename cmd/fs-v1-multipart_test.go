@@ -20,6 +20,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -28,6 +29,7 @@ import (
 	"time"
 
 	"github.com/minio/minio/internal/config/api"
+	xhttp "github.com/minio/minio/internal/http"
 )
 
 // Tests cleanup multipart uploads for filesystem backend.
@@ -209,6 +211,61 @@ func TestCompleteMultipartUpload(t *testing.T) {
 	}
 }
 
+// TestFSConditionalWrites - test conditional CompleteMultipartUpload and PutObject
+func TestFSConditionalWrites(t *testing.T) {
+	disk := filepath.Join(globalTestTmpDir, "minio-"+nextSuffix())
+	defer os.RemoveAll(disk)
+	obj := initFSObjects(disk, t)
+
+	bucketName := "bucket"
+	objectName := "object"
+	data := []byte("12345")
+	md5Hex := getMD5Hash(data)
+
+	if err := obj.MakeBucketWithLocation(GlobalContext, bucketName, BucketOptions{}); err != nil {
+		t.Fatal("Cannot create bucket, err: ", err)
+	}
+
+	precond := func(header, value string) ObjectOptions {
+		r, err := http.NewRequest(http.MethodPut, "/"+bucketName+"/"+objectName, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set(header, value)
+		return ObjectOptions{CheckPrecondFn: putPrecondFn(r)}
+	}
+	put := func(opts ObjectOptions) (ObjectInfo, error) {
+		return obj.PutObject(GlobalContext, bucketName, objectName, mustGetPutObjReader(t, bytes.NewReader(data), 5, md5Hex, ""), opts)
+	}
+
+	uploadID, err := obj.NewMultipartUpload(GlobalContext, bucketName, objectName, ObjectOptions{})
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if _, err = obj.PutObjectPart(GlobalContext, bucketName, objectName, uploadID, 1, mustGetPutObjReader(t, bytes.NewReader(data), 5, md5Hex, ""), ObjectOptions{}); err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	parts := []CompletePart{{PartNumber: 1, ETag: md5Hex}}
+	if _, err = obj.CompleteMultipartUpload(GlobalContext, bucketName, objectName, uploadID, parts, precond(xhttp.IfMatch, "*")); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-Match on a missing object to fail, got %v", err)
+	}
+	// The upload is kept when its precondition fails.
+	oi, err := obj.CompleteMultipartUpload(GlobalContext, bucketName, objectName, uploadID, parts, precond(xhttp.IfNoneMatch, "*"))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if _, err = put(precond(xhttp.IfNoneMatch, "*")); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-None-Match on an existing object to fail, got %v", err)
+	}
+	if _, err = put(precond(xhttp.IfMatch, "deadbeef")); !isErrPreconditionFailed(err) {
+		t.Fatalf("expected If-Match with another ETag to fail, got %v", err)
+	}
+	if _, err = put(precond(xhttp.IfMatch, `"`+oi.ETag+`"`)); err != nil {
+		t.Fatalf("expected If-Match with the current ETag to succeed, got %v", err)
+	}
+}
+
 // TestCompleteMultipartUpload - test CompleteMultipartUpload
 func TestAbortMultipartUpload(t *testing.T) {
 	if runtime.GOOS == globalWindowsOSName {
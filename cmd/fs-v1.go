@@ -1024,6 +1024,26 @@ func (fs *FSObjects) GetObjectInfo(ctx context.Context, bucket, object string, o
 	return oi, toObjectErr(err, bucket, object)
 }
 
+// checkWritePrecondition returns PreConditionFailed when the write
+// preconditions in opts do not hold for object, a missing object is
+// passed as an empty ObjectInfo. The caller must hold the object lock.
+func (fs *FSObjects) checkWritePrecondition(ctx context.Context, bucket, object string, opts ObjectOptions) error {
+	if opts.CheckPrecondFn == nil {
+		return nil
+	}
+	oi, err := fs.getObjectInfo(ctx, bucket, object)
+	if err != nil {
+		if !isErrObjectNotFound(err) {
+			return err
+		}
+		oi = ObjectInfo{}
+	}
+	if opts.CheckPrecondFn(oi) {
+		return PreConditionFailed{}
+	}
+	return nil
+}
+
 // PutObject - creates an object upon reading from the input stream
 // until EOF, writes data directly to configured filesystem path.
 // Additionally writes `fs.json` which carries the necessary metadata
@@ -1049,6 +1069,11 @@ func (fs *FSObjects) PutObject(ctx context.Context, bucket string, object string
 	ctx = lkctx.Context()
 	defer lk.Unlock(lkctx.Cancel)
 
+	// Conditional writes are evaluated against the object under lock.
+	if err = fs.checkWritePrecondition(ctx, bucket, object, opts); err != nil {
+		return objInfo, err
+	}
+
 	atomic.AddInt64(&fs.activeIOCount, 1)
 	defer func() {
 		atomic.AddInt64(&fs.activeIOCount, -1)
@@ -1221,6 +1246,19 @@ func (fs *FSObjects) DeleteObject(ctx context.Context, bucket, object string, op
 		return objInfo, err
 	}
 
+	if opts.CheckPrecondFn != nil {
+		oi, err := fs.getObjectInfo(ctx, bucket, object)
+		if err != nil {
+			if !isErrObjectNotFound(err) {
+				return objInfo, err
+			}
+			oi = ObjectInfo{}
+		}
+		if opts.CheckPrecondFn(oi) {
+			return objInfo, PreConditionFailed{}
+		}
+	}
+
 	atomic.AddInt64(&fs.activeIOCount, 1)
 	defer func() {
 		atomic.AddInt64(&fs.activeIOCount, -1)
@@ -1370,6 +1408,16 @@ func (fs *FSObjects) PutObjectTags(ctx context.Context, bucket, object string, t
 		fsMeta = fs.defaultFsJSON(object)
 	}
 
+	if opts.CheckPrecondFn != nil {
+		fi, err := fsStatFile(ctx, pathJoin(fs.fsPath, bucket, object))
+		if err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+		if opts.CheckPrecondFn(fsMeta.ToObjectInfo(bucket, object, fi)) {
+			return ObjectInfo{}, PreConditionFailed{}
+		}
+	}
+
 	// clean fsMeta.Meta of tag key, before updating the new tags
 	delete(fsMeta.Meta, xhttp.AmzObjectTagging)
 
@@ -1377,6 +1425,9 @@ func (fs *FSObjects) PutObjectTags(ctx context.Context, bucket, object string, t
 	if tags != "" {
 		fsMeta.Meta[xhttp.AmzObjectTagging] = tags
 	}
+	for k, v := range opts.UserDefined {
+		fsMeta.Meta[k] = v
+	}
 
 	if _, err = fsMeta.WriteTo(wlk); err != nil {
 		return ObjectInfo{}, toObjectErr(err, bucket, object)
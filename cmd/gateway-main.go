@@ -158,7 +158,7 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 
 	go handleSignals()
 
-	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 	// This is only to uniquely identify each gateway deployments.
 	globalDeploymentID = env.Get("MINIO_GATEWAY_DEPLOYMENT_ID", mustGetUUID())
 	logger.SetDeploymentID(globalDeploymentID)
@@ -257,7 +257,7 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 
 	var getCert certs.GetCertificateFunc
 	if globalTLSCerts != nil {
-		getCert = globalTLSCerts.GetCertificate
+		getCert = getTLSCertificate
 	}
 
 	listeners := ctx.Int("listeners")
@@ -108,7 +108,8 @@ const (
 	// Maximum size of default bucket encryption configuration allowed
 	maxBucketSSEConfigSize = 1 * humanize.MiByte
 
-	// diskFillFraction is the fraction of a disk we allow to be filled.
+	// diskFillFraction is the default fraction of a disk we allow to be
+	// filled, set by the api disk_high_watermark.
 	diskFillFraction = 0.99
 
 	// diskAssumeUnknownSize is the size to assume when an unknown size upload is requested.
@@ -184,7 +185,7 @@ var (
 	globalBucketTargetSys    *BucketTargetSys
 	// globalAPIConfig controls S3 API requests throttling,
 	// healthcheck readiness deadlines and cors settings.
-	globalAPIConfig = apiConfig{listQuorum: 3}
+	globalAPIConfig = apiConfig{listQuorum: 3, readyQuorumMargin: -1, readyHealBacklog: -1}
 
 	globalStorageClass storageclass.Config
 	globalLDAPConfig   xldap.Config
@@ -247,6 +248,12 @@ var (
 	globalBucketQuotaSys      *BucketQuotaSys
 	globalBucketVersioningSys *BucketVersioningSys
 
+	// Tenants grouping buckets and users.
+	globalTenantSys *TenantSys
+
+	// Templates of the configs of new buckets.
+	globalBucketTemplateSys *BucketTemplateSys
+
 	// Disk cache drives
 	globalCacheConfig cache.Config
 
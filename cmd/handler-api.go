@@ -18,10 +18,12 @@
 package cmd
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/gorilla/mux"
 	mem "github.com/shirou/gopsutil/v3/mem"
 
 	"github.com/minio/minio/internal/config/api"
@@ -46,6 +48,31 @@ type apiConfig struct {
 	staleUploadsExpiry          time.Duration
 	staleUploadsCleanupInterval time.Duration
 	deleteCleanupInterval       time.Duration
+
+	// readiness gates, negative when disabled.
+	readyQuorumMargin int
+	readyHealBacklog  int
+
+	// fraction of the drives of a set uploads may fill.
+	diskFillFraction float64
+
+	// proxy reads to the replication target of the lowest latency.
+	replicationProxyNearest bool
+
+	// direct children of a prefix from which its listings are indexed.
+	listIndexThreshold int64
+
+	// consult the key filters of the sets on object lookups.
+	headKeyFilter bool
+
+	// request header carrying client correlation IDs, empty if ignored.
+	correlationHeader string
+
+	// latency from which S3 requests are logged as slow, 0 if disabled.
+	slowRequestsThreshold time.Duration
+
+	// reverse proxies trusted to forward the client address.
+	trustedProxies []*net.IPNet
 }
 
 func (t *apiConfig) init(cfg api.Config, setDriveCounts []int) {
@@ -115,6 +142,23 @@ func (t *apiConfig) init(cfg api.Config, setDriveCounts []int) {
 	t.staleUploadsExpiry = cfg.StaleUploadsExpiry
 	t.staleUploadsCleanupInterval = cfg.StaleUploadsCleanupInterval
 	t.deleteCleanupInterval = cfg.DeleteCleanupInterval
+	t.readyQuorumMargin = cfg.ReadyQuorumMargin
+	t.readyHealBacklog = cfg.ReadyHealBacklog
+	t.diskFillFraction = float64(cfg.DiskHighWatermark) / 100
+	t.replicationProxyNearest = cfg.ReplicationProxyNearest
+	t.listIndexThreshold = cfg.ListIndexThreshold
+	t.headKeyFilter = cfg.HeadKeyFilter
+	t.correlationHeader = cfg.CorrelationHeader
+	t.slowRequestsThreshold = cfg.SlowRequestsThreshold
+	// The trusted proxies are validated by the api config.
+	t.trustedProxies, _ = parseNetworks(cfg.TrustedProxies)
+	globalSlowRequests.resize(cfg.SlowRequestsMax)
+	globalObjectMemCache.setLimits(cfg.MemoryCacheSize, cfg.MemoryCacheObjectMax)
+
+	globalBucketLatencyStats.setTopN(cfg.BucketLatencyTopN)
+	globalAccessTracker.setEnabled(cfg.AccessTracking)
+	globalIPThrottle.setConfig(cfg.ThrottleAuthFailures, cfg.ThrottleRequestsRate,
+		cfg.ThrottleBanDuration, cfg.ThrottleAllowlist)
 }
 
 func (t *apiConfig) getListQuorum() int {
@@ -124,6 +168,79 @@ func (t *apiConfig) getListQuorum() int {
 	return t.listQuorum
 }
 
+func (t *apiConfig) getReadyGates() (quorumMargin, healBacklog int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.readyQuorumMargin, t.readyHealBacklog
+}
+
+// getDiskFillFraction returns the fraction of the drives of a set
+// uploads may fill.
+func (t *apiConfig) getDiskFillFraction() float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.diskFillFraction <= 0 {
+		return diskFillFraction
+	}
+	return t.diskFillFraction
+}
+
+// isReplicationProxyNearest returns whether reads are proxied to the
+// replication target of the lowest measured latency.
+func (t *apiConfig) isReplicationProxyNearest() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.replicationProxyNearest
+}
+
+// getListIndexThreshold returns the number of direct children of a
+// prefix from which its listings are served by an index, 0 if disabled.
+func (t *apiConfig) getListIndexThreshold() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.listIndexThreshold
+}
+
+// isHeadKeyFilter returns whether lookups of missing objects are
+// answered from the key filters of the erasure sets.
+func (t *apiConfig) isHeadKeyFilter() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.headKeyFilter
+}
+
+// getTrustedProxies returns the networks of the reverse proxies trusted
+// to forward the client address.
+func (t *apiConfig) getTrustedProxies() []*net.IPNet {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.trustedProxies
+}
+
+// getCorrelationHeader returns the request header carrying client
+// correlation IDs, empty if they are ignored.
+func (t *apiConfig) getCorrelationHeader() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.correlationHeader
+}
+
+// getSlowRequestsThreshold returns the latency from which S3 requests
+// are logged as slow, 0 if the slow request log is disabled.
+func (t *apiConfig) getSlowRequestsThreshold() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.slowRequestsThreshold
+}
+
 func (t *apiConfig) getCorsAllowOrigins() []string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -191,6 +308,27 @@ func (t *apiConfig) getRequestsPool() (chan struct{}, time.Duration) {
 // maxClients throttles the S3 API calls
 func maxClients(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// The domains of a tenant are separate bucket namespaces.
+		if !globalTenantSys.allowsHostBucket(r.Host, mux.Vars(r)["bucket"]) {
+			writeErrorResponse(r.Context(), w,
+				errorCodes.ToAPIErr(ErrNoSuchBucket),
+				r.URL)
+			return
+		}
+
+		// Networks denied access to a bucket are rejected before
+		// any signature verification.
+		if !isNetworkAllowed(r, mux.Vars(r)["bucket"]) {
+			writeErrorResponse(r.Context(), w,
+				errorCodes.ToAPIErr(ErrAccessDenied),
+				r.URL)
+			return
+		}
+
+		// The request budget of a tenant is only charged once the
+		// request is authorized.
+		r = withTenantRequest(r, mux.Vars(r)["bucket"])
+
 		pool, deadline := globalAPIConfig.getRequestsPool()
 		if pool == nil {
 			f.ServeHTTP(w, r)
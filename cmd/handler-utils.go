@@ -384,7 +384,24 @@ func collectAPIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 
 		statsWriter := logger.NewResponseWriter(w)
 
+		var timings *requestTimings
+		if globalAPIConfig.getSlowRequestsThreshold() > 0 {
+			var ctx context.Context
+			ctx, timings = withRequestTimings(r.Context())
+			r = r.WithContext(ctx)
+			statsWriter.TimeWrites = true
+		}
+
+		r, span := startS3Span(r, api)
+		r, untrack := globalInflightRequests.track(r, api, statsWriter)
+		r = globalAccessAnomaly.withRequest(r)
 		f.ServeHTTP(statsWriter, r)
+		untrack()
+		endS3Span(span, statsWriter)
+		globalBucketLatencyStats.record(r, api, statsWriter)
+		globalAccessAnomaly.record(r, api, statsWriter)
+		globalSLOTracker.record(r, api, statsWriter)
+		globalSlowRequests.record(r, api, statsWriter, timings)
 
 		globalHTTPStats.updateStats(api, r, statsWriter)
 	}
@@ -19,17 +19,25 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	xhttp "github.com/minio/minio/internal/http"
 )
 
 const unavailable = "offline"
 
-// ClusterCheckHandler returns if the server is ready for requests.
+// ClusterCheckHandler returns if the server is ready for requests,
+// with ?verbose=true also the quorum margins of every erasure set and
+// the status of its dependencies for clients with a prometheus token.
 func ClusterCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if writeDrainingResponse(w) {
+		return
+	}
+
 	if globalIsGateway {
 		writeResponse(w, http.StatusOK, nil, mimeNone)
 		return
@@ -48,11 +56,19 @@ func ClusterCheckHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(ctx, globalAPIConfig.getClusterDeadline())
 	defer cancel()
 
+	// Dependency details are only given to authorized clients.
+	verbose := isHealthVerboseRequest(r)
+	if verbose && !isPrometheusRequestAllowed(r) {
+		writeResponse(w, http.StatusForbidden, nil, mimeNone)
+		return
+	}
+
 	opts := HealthOptions{Maintenance: r.Form.Get("maintenance") == "true"}
 	result := objLayer.Health(ctx, opts)
 	if result.WriteQuorum > 0 {
 		w.Header().Set(xhttp.MinIOWriteQuorum, strconv.Itoa(result.WriteQuorum))
 	}
+	statusCode := http.StatusOK
 	if !result.Healthy {
 		// return how many drives are being healed if any
 		if result.HealingDrives > 0 {
@@ -62,17 +78,37 @@ func ClusterCheckHandler(w http.ResponseWriter, r *http.Request) {
 		// down, this is for orchestrators to know if we can safely
 		// take this server down, return appropriate error.
 		if opts.Maintenance {
-			writeResponse(w, http.StatusPreconditionFailed, nil, mimeNone)
+			statusCode = http.StatusPreconditionFailed
 		} else {
-			writeResponse(w, http.StatusServiceUnavailable, nil, mimeNone)
+			statusCode = http.StatusServiceUnavailable
 		}
+	}
+	if !verbose {
+		writeResponse(w, statusCode, nil, mimeNone)
 		return
 	}
-	writeResponse(w, http.StatusOK, nil, mimeNone)
+
+	resp, err := json.Marshal(healthVerboseResult{
+		Healthy:       result.Healthy,
+		Maintenance:   opts.Maintenance,
+		HealingDrives: result.HealingDrives,
+		WriteQuorum:   result.WriteQuorum,
+		Sets:          result.ESHealth,
+		Dependencies:  checkHealthDependencies(ctx),
+	})
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, nil, mimeNone)
+		return
+	}
+	writeResponse(w, statusCode, resp, mimeJSON)
 }
 
 // ClusterReadCheckHandler returns if the server is ready for requests.
 func ClusterReadCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if writeDrainingResponse(w) {
+		return
+	}
+
 	if globalIsGateway {
 		writeResponse(w, http.StatusOK, nil, mimeNone)
 		return
@@ -100,11 +136,118 @@ func ClusterReadCheckHandler(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, http.StatusOK, nil, mimeNone)
 }
 
-// ReadinessCheckHandler Checks if the process is up. Always returns success.
+// writeDrainingResponse fails the health check if the node is draining
+// so that load balancers stop sending new requests, returns false otherwise.
+func writeDrainingResponse(w http.ResponseWriter) bool {
+	if !globalNodeDrain.isDraining() {
+		return false
+	}
+	w.Header().Set(xhttp.MinIOServerStatus, globalNodeDrain.status().State)
+	writeResponse(w, http.StatusServiceUnavailable, nil, mimeNone)
+	return true
+}
+
+// ReadinessCheckHandler Checks if the process is up and not draining,
+// and when configured that the erasure sets of this node are above the
+// quorum margin and the heal backlog of its drives is low enough.
 func ReadinessCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if writeDrainingResponse(w) {
+		return
+	}
+	if status := readinessGateStatus(); status != "" {
+		w.Header().Set(xhttp.MinIOServerStatus, status)
+		writeResponse(w, http.StatusServiceUnavailable, nil, mimeNone)
+		return
+	}
 	LivenessCheckHandler(w, r)
 }
 
+// Statuses of a node held not ready by a readiness gate.
+const (
+	readyStatusQuorumMargin = "quorum-margin"
+	readyStatusHealBacklog  = "heal-backlog"
+)
+
+// readinessGateStatus returns why the node is not ready as configured
+// by the api ready_quorum_margin and ready_heal_backlog settings, an
+// empty string when it is ready.
+func readinessGateStatus() string {
+	quorumMargin, healBacklog := globalAPIConfig.getReadyGates()
+	if (quorumMargin < 0 && healBacklog < 0) || globalIsGateway || !globalIsErasure {
+		return ""
+	}
+	if shouldProxy() {
+		return unavailable
+	}
+	z, ok := newObjectLayerFn().(*erasureServerPools)
+	if !ok {
+		return ""
+	}
+
+	if quorumMargin >= 0 {
+		local := localErasureSets(globalEndpoints)
+		for _, set := range readyErasureSetHealth(z) {
+			if local[[2]int{set.PoolID, set.SetID}] && set.WriteMargin < quorumMargin {
+				return readyStatusQuorumMargin
+			}
+		}
+	}
+	if healBacklog >= 0 && localHealBacklog() > uint64(healBacklog) {
+		return readyStatusHealBacklog
+	}
+	return ""
+}
+
+// readyHealthCache holds the erasure set health the readiness gate
+// checked last, so that frequent probes don't ask all peers every time.
+var readyHealthCache timedValue
+
+// readyErasureSetHealth returns the erasure set health of the cluster,
+// at most a few seconds old.
+func readyErasureSetHealth(z *erasureServerPools) []ErasureSetHealth {
+	readyHealthCache.Once.Do(func() {
+		readyHealthCache.TTL = 5 * time.Second
+		readyHealthCache.Update = func() (interface{}, error) {
+			ctx, cancel := context.WithTimeout(GlobalContext, globalAPIConfig.getClusterDeadline())
+			defer cancel()
+			return z.Health(ctx, HealthOptions{}).ESHealth, nil
+		}
+	})
+	v, _ := readyHealthCache.Get()
+	return v.([]ErasureSetHealth)
+}
+
+// localErasureSets returns the pool and set indexes of the erasure sets
+// with drives on this node.
+func localErasureSets(endpointServerPools EndpointServerPools) map[[2]int]bool {
+	sets := make(map[[2]int]bool)
+	for poolIdx, ep := range endpointServerPools {
+		if ep.DrivesPerSet == 0 {
+			continue
+		}
+		for i, endpoint := range ep.Endpoints {
+			if endpoint.IsLocal {
+				sets[[2]int{poolIdx, i / ep.DrivesPerSet}] = true
+			}
+		}
+	}
+	return sets
+}
+
+// localHealBacklog returns how many objects remain to be healed on the
+// drives of this node being healed.
+func localHealBacklog() (backlog uint64) {
+	if globalBackgroundHealState == nil {
+		return 0
+	}
+	for _, disk := range globalBackgroundHealState.getLocalHealingDisks() {
+		if done := disk.ItemsHealed + disk.ItemsFailed; disk.ObjectsTotalCount > done {
+			backlog += disk.ObjectsTotalCount - done
+		}
+	}
+	return backlog
+}
+
 // LivenessCheckHandler - Checks if the process is up. Always returns success.
 func LivenessCheckHandler(w http.ResponseWriter, r *http.Request) {
 	if shouldProxy() {
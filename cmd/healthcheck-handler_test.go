@@ -0,0 +1,41 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocalErasureSets(t *testing.T) {
+	endpoints := func(local ...bool) Endpoints {
+		eps := make(Endpoints, len(local))
+		for i := range local {
+			eps[i].IsLocal = local[i]
+		}
+		return eps
+	}
+	pools := EndpointServerPools{
+		{SetCount: 2, DrivesPerSet: 2, Endpoints: endpoints(true, false, false, false)},
+		{SetCount: 2, DrivesPerSet: 2, Endpoints: endpoints(false, false, false, true)},
+	}
+	want := map[[2]int]bool{{0, 0}: true, {1, 1}: true}
+	if got := localErasureSets(pools); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/minio/madmin-go"
+)
+
+// Dependency types of a verbose health check.
+const (
+	healthDependencyKMS         = "kms"
+	healthDependencyIDP         = "idp"
+	healthDependencyTier        = "tier"
+	healthDependencyReplication = "replication"
+	healthDependencyEtcd        = "etcd"
+)
+
+// healthDependency - status of a service the cluster depends on.
+type healthDependency struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthVerboseResult - the body of a verbose cluster health check,
+// detailing the quorum margins of every erasure set and the status of
+// the configured dependencies.
+type healthVerboseResult struct {
+	Healthy       bool               `json:"healthy"`
+	Maintenance   bool               `json:"maintenance,omitempty"`
+	HealingDrives int                `json:"healingDrives,omitempty"`
+	WriteQuorum   int                `json:"writeQuorum,omitempty"`
+	Sets          []ErasureSetHealth `json:"sets,omitempty"`
+	Dependencies  []healthDependency `json:"dependencies,omitempty"`
+}
+
+// isHealthVerboseRequest returns whether r asks for a verbose health check.
+func isHealthVerboseRequest(r *http.Request) bool {
+	return r.Form.Get("verbose") == "true"
+}
+
+func newHealthDependency(typ, name string, err error) healthDependency {
+	d := healthDependency{Type: typ, Name: name, Status: string(madmin.ItemOnline)}
+	if err != nil {
+		d.Status = string(madmin.ItemOffline)
+		d.Error = err.Error()
+	}
+	return d
+}
+
+// checkHealthDependencies checks the configured dependencies of the
+// cluster concurrently, until ctx is done.
+func checkHealthDependencies(ctx context.Context) []healthDependency {
+	var checks []func() healthDependency
+
+	if GlobalKMS != nil {
+		checks = append(checks, func() healthDependency {
+			stat, err := GlobalKMS.Stat()
+			return newHealthDependency(healthDependencyKMS, stat.Name, err)
+		})
+	}
+	if globalLDAPConfig.Enabled {
+		checks = append(checks, func() healthDependency {
+			conn, err := globalLDAPConfig.Connect()
+			if err == nil && conn != nil {
+				// Close ldap connection to avoid leaks.
+				conn.Close()
+			}
+			return newHealthDependency(healthDependencyIDP, "ldap", err)
+		})
+	}
+	if openIDConfig, _ := getOpenIDConfig(); openIDConfig.URL != nil {
+		checks = append(checks, func() healthDependency {
+			err := checkConnection(openIDConfig.URL.String(), globalAPIConfig.getClusterDeadline())
+			return newHealthDependency(healthDependencyIDP, "openid", err)
+		})
+	}
+	if globalEtcdClient != nil {
+		checks = append(checks, func() healthDependency {
+			_, err := globalEtcdClient.Get(ctx, "health")
+			return newHealthDependency(healthDependencyEtcd, "", err)
+		})
+	}
+	if globalTierConfigMgr != nil {
+		for _, tier := range globalTierConfigMgr.ListTiers() {
+			name := tier.Name
+			checks = append(checks, func() healthDependency {
+				d, err := globalTierConfigMgr.getDriver(name)
+				if err == nil {
+					_, err = d.InUse(ctx)
+				}
+				return newHealthDependency(healthDependencyTier, name, err)
+			})
+		}
+	}
+	if globalBucketTargetSys != nil {
+		for _, target := range globalBucketTargetSys.ListTargets(ctx, "", string(madmin.ReplicationService)) {
+			arn := target.Arn
+			checks = append(checks, func() healthDependency {
+				// The remote targets are health checked in the background.
+				var err error
+				if tgt := globalBucketTargetSys.GetRemoteTargetClient(ctx, arn); tgt == nil || tgt.IsOffline() {
+					err = errors.New("remote target is offline")
+				}
+				return newHealthDependency(healthDependencyReplication, arn, err)
+			})
+		}
+	}
+
+	deps := make([]healthDependency, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func() healthDependency) {
+			defer wg.Done()
+			deps[i] = check()
+		}(i, check)
+	}
+	wg.Wait()
+	return deps
+}
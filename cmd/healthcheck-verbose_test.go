@@ -0,0 +1,88 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHealthErasureSetMargins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	result := objLayer.Health(ctx, HealthOptions{})
+	if !result.Healthy || len(result.ESHealth) != 1 {
+		t.Fatalf("expected a healthy erasure set, got %+v", result)
+	}
+	set := result.ESHealth[0]
+	if set.OnlineDrives != 16 || set.WriteQuorum != result.WriteQuorum {
+		t.Errorf("unexpected erasure set health %+v", set)
+	}
+	if set.WriteMargin != 16-set.WriteQuorum || set.ReadMargin != 16-set.ReadQuorum {
+		t.Errorf("unexpected quorum margins %+v", set)
+	}
+}
+
+func TestHealthPoolQuorums(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fsDirs, err := getRandomDisks(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	endpoints := append(mustGetPoolEndpoints(fsDirs[:4]...), mustGetPoolEndpoints(fsDirs[4:]...)...)
+	objLayer, _, err := initObjectLayer(ctx, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := objLayer.BackendInfo()
+	result := objLayer.Health(ctx, HealthOptions{})
+	if !result.Healthy || len(result.ESHealth) != 2 {
+		t.Fatalf("expected two healthy erasure sets, got %+v", result)
+	}
+	maximumWriteQuorum := 0
+	for _, set := range result.ESHealth {
+		writeQuorum := b.StandardSCData[set.PoolID]
+		if writeQuorum == b.StandardSCParity {
+			writeQuorum++
+		}
+		if set.ReadQuorum != b.StandardSCData[set.PoolID] || set.WriteQuorum != writeQuorum {
+			t.Errorf("pool %d: expected quorums %d/%d, got %+v", set.PoolID, b.StandardSCData[set.PoolID], writeQuorum, set)
+		}
+		if writeQuorum > maximumWriteQuorum {
+			maximumWriteQuorum = writeQuorum
+		}
+	}
+	if result.ESHealth[0].WriteQuorum == result.ESHealth[1].WriteQuorum {
+		t.Errorf("expected the pools to differ in their quorums, got %+v", result.ESHealth)
+	}
+	if result.WriteQuorum != maximumWriteQuorum {
+		t.Errorf("expected write quorum %d, got %d", maximumWriteQuorum, result.WriteQuorum)
+	}
+}
@@ -26,6 +26,9 @@ import (
 
 const (
 	byteRangePrefix = "bytes="
+
+	// Maximum number of ranges of a multi-range GET.
+	maxByteRanges = 100
 )
 
 // HTTPRangeSpec represents a range specification as supported by S3 GET
@@ -163,6 +166,26 @@ func parseRequestRangeSpec(rangeString string) (hrange *HTTPRangeSpec, err error
 	}
 }
 
+// parseRequestRangeSpecs parses a HTTP range header value of one or
+// more comma separated ranges, e.g. "bytes=0-99,200-299".
+func parseRequestRangeSpecs(rangeString string) (ranges []*HTTPRangeSpec, err error) {
+	if !strings.HasPrefix(rangeString, byteRangePrefix) {
+		return nil, fmt.Errorf("'%s' does not start with '%s'", rangeString, byteRangePrefix)
+	}
+	specs := strings.Split(strings.TrimPrefix(rangeString, byteRangePrefix), ",")
+	if len(specs) > maxByteRanges {
+		return nil, fmt.Errorf("'%s' has more than %d ranges", rangeString, maxByteRanges)
+	}
+	for _, spec := range specs {
+		rs, err := parseRequestRangeSpec(byteRangePrefix + strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, rs)
+	}
+	return ranges, nil
+}
+
 // String returns stringified representation of range for a particular resource size.
 func (h *HTTPRangeSpec) String(resourceSize int64) string {
 	if h == nil {
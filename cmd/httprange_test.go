@@ -18,6 +18,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -101,3 +102,29 @@ func TestHTTPRequestRangeSpec(t *testing.T) {
 		t.Errorf("Case %d: Expected errInvalidRange but: %v %v %d %d %v", i, rs, err1, o, l, err2)
 	}
 }
+
+func TestHTTPRequestRangeSpecs(t *testing.T) {
+	ranges, err := parseRequestRangeSpecs("bytes=0-9, 20-, -5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []HTTPRangeSpec{{false, 0, 9}, {false, 20, -1}, {true, -5, -1}}
+	if len(ranges) != len(expected) {
+		t.Fatalf("expected %d ranges, got %d", len(expected), len(ranges))
+	}
+	for i, rs := range ranges {
+		if *rs != expected[i] {
+			t.Errorf("range %d: expected %v, got %v", i, expected[i], *rs)
+		}
+	}
+
+	for _, spec := range []string{"bytes=0-9,", "bytes=0-9,x", "0-9,10-19", "bytes=9-0,10-19"} {
+		if _, err := parseRequestRangeSpecs(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+	tooMany := "bytes=0-0" + strings.Repeat(",0-0", maxByteRanges)
+	if _, err := parseRequestRangeSpecs(tooMany); err == nil {
+		t.Errorf("expected more than %d ranges to be rejected", maxByteRanges)
+	}
+}
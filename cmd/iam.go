@@ -645,8 +645,9 @@ func (sys *IAMSys) Init(ctx context.Context, objAPI ObjectLayer, etcdClient *etc
 	}
 
 	// Set up polling for expired accounts and credentials purging.
+	openIDConfig, _ := getOpenIDConfig()
 	switch {
-	case globalOpenIDConfig.ProviderEnabled():
+	case openIDConfig.ProviderEnabled():
 		go func() {
 			for {
 				time.Sleep(globalRefreshIAMInterval)
@@ -1753,9 +1754,10 @@ func (sys *IAMSys) purgeExpiredCredentialsForExternalSSO(ctx context.Context) {
 	}
 	sys.store.unlock()
 
+	openIDConfig, _ := getOpenIDConfig()
 	expiredUsers := make([]auth.Credentials, 0, len(parentUsersMap))
 	for userid, creds := range parentUsersMap {
-		u, err := globalOpenIDConfig.LookupUser(userid)
+		u, err := openIDConfig.LookupUser(userid)
 		if err != nil {
 			logger.LogIf(GlobalContext, err)
 			continue
@@ -2712,7 +2714,7 @@ func (sys *IAMSys) IsAllowed(args iampolicy.Args) bool {
 		return false
 	}
 	if ok {
-		return sys.IsAllowedSTS(args, parentUser)
+		return globalTenantSys.allowsBucket(parentUser, args.BucketName) && sys.IsAllowedSTS(args, parentUser)
 	}
 
 	// If the credential is for a service account, perform related check
@@ -2721,10 +2723,13 @@ func (sys *IAMSys) IsAllowed(args iampolicy.Args) bool {
 		return false
 	}
 	if ok {
-		return sys.IsAllowedServiceAccount(args, parentUser)
+		return globalTenantSys.allowsBucket(parentUser, args.BucketName) && sys.IsAllowedServiceAccount(args, parentUser)
 	}
 
 	// Continue with the assumption of a regular user
+	if !globalTenantSys.allowsBucket(args.AccountName, args.BucketName) {
+		return false
+	}
 	policies, err := sys.PolicyDBGet(args.AccountName, false, args.Groups...)
 	if err != nil {
 		return false
@@ -2739,6 +2744,24 @@ func (sys *IAMSys) IsAllowed(args iampolicy.Args) bool {
 	return sys.GetCombinedPolicy(policies...).IsAllowed(args)
 }
 
+// IsAllowedPolicies - checks given policy args is allowed by the given
+// policies, for identities not mapped to an IAM user.
+func (sys *IAMSys) IsAllowedPolicies(args iampolicy.Args, policies []string) bool {
+	// If opa is configured, use OPA always.
+	if globalPolicyOPA != nil {
+		ok, err := globalPolicyOPA.IsAllowed(args)
+		if err != nil {
+			logger.LogIf(GlobalContext, err)
+		}
+		return ok
+	}
+
+	if len(policies) == 0 {
+		return false
+	}
+	return sys.GetCombinedPolicy(policies...).IsAllowed(args)
+}
+
 // Set default canned policies only if not already overridden by users.
 func setDefaultCannedPolicies(policies map[string]iampolicy.Policy) {
 	_, ok := policies["writeonly"]
@@ -0,0 +1,332 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/logger/message/log"
+	mem "github.com/shirou/gopsutil/v3/mem"
+)
+
+const (
+	// Incident reports of all nodes, one per incident.
+	incidentsPrefix = "incidents"
+
+	// Oldest reports are removed beyond this count.
+	maxIncidentReports = 20
+
+	// Recent log entries of the node added to a report.
+	incidentLogEntries = 100
+
+	// Goroutine dumps added to memory reports are cut at this size.
+	incidentStackMaxSize = 256 << 10
+
+	// Reports of a kind are recorded at most once per interval on a
+	// node, so that a panic hit by every request does not flood them.
+	incidentReportInterval = time.Minute
+
+	// Memory pressure is reported once the used system memory reaches
+	// the threshold, as the node is then at risk of being OOM killed.
+	incidentMemoryCheckInterval  = 30 * time.Second
+	incidentMemoryReportInterval = time.Hour
+	incidentMemoryThreshold      = 95
+)
+
+// Kinds of incidents.
+const (
+	incidentPanic  = "panic"
+	incidentMemory = "memory"
+)
+
+// IncidentReport - the forensic evidence of a panic or of memory
+// pressure on a node, redacted of request details and secrets.
+type IncidentReport struct {
+	ID       string    `json:"id"`
+	Kind     string    `json:"kind"`
+	Node     string    `json:"node"`
+	Time     time.Time `json:"time"`
+	Version  string    `json:"version"`
+	CommitID string    `json:"commitID"`
+	Message  string    `json:"message"`
+	// Method of the request which panicked.
+	Method string `json:"method,omitempty"`
+	Stack  string `json:"stack,omitempty"`
+
+	Goroutines     int     `json:"goroutines"`
+	HeapAlloc      uint64  `json:"heapAlloc"`
+	HeapSys        uint64  `json:"heapSys"`
+	MemUsedPercent float64 `json:"memUsedPercent,omitempty"`
+
+	// Digests of the server config as a whole and by sub-system, to
+	// be compared against other nodes and config history versions.
+	ConfigDigest     string            `json:"configDigest"`
+	SubSystemDigests map[string]string `json:"subSystemDigests,omitempty"`
+
+	Logs []log.Entry `json:"logs,omitempty"`
+}
+
+// incidentReports records the incident reports of this node.
+type incidentReports struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+var globalIncidentReports = &incidentReports{last: make(map[string]time.Time)}
+
+// due returns whether an incident of kind is to be reported at now,
+// reports of a kind are recorded at most once per interval.
+func (ir *incidentReports) due(kind string, now time.Time, interval time.Duration) bool {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if now.Sub(ir.last[kind]) < interval {
+		return false
+	}
+	ir.last[kind] = now
+	return true
+}
+
+func incidentReportFile(id string) string {
+	return path.Join(incidentsPrefix, id+".json")
+}
+
+// newIncidentReport returns a report of kind with the state of the
+// node, the recent log entries and the digests of the config.
+func newIncidentReport(kind, message string) IncidentReport {
+	now := UTCNow()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	rep := IncidentReport{
+		ID:         fmt.Sprintf("%020d-%s", now.UnixNano(), mustGetUUID()[:8]),
+		Kind:       kind,
+		Node:       globalLocalNodeName,
+		Time:       now,
+		Version:    Version,
+		CommitID:   CommitID,
+		Message:    message,
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  ms.HeapAlloc,
+		HeapSys:    ms.HeapSys,
+	}
+	rep.ConfigDigest, rep.SubSystemDigests = serverConfigDigests()
+	if globalConsoleSys != nil {
+		logs := globalConsoleSys.Content()
+		if len(logs) > incidentLogEntries {
+			logs = logs[len(logs)-incidentLogEntries:]
+		}
+		for _, e := range logs {
+			rep.Logs = append(rep.Logs, redactIncidentLogEntry(e))
+		}
+	}
+	return rep
+}
+
+// redactIncidentLogEntry drops the client details, user metadata and
+// error variables of a log entry added to an incident report.
+func redactIncidentLogEntry(e log.Entry) log.Entry {
+	e.RemoteHost = ""
+	e.UserAgent = ""
+	if e.API != nil && e.API.Args != nil {
+		api := *e.API
+		args := *e.API.Args
+		args.Metadata = nil
+		api.Args = &args
+		e.API = &api
+	}
+	if e.Trace != nil {
+		trace := *e.Trace
+		trace.Variables = nil
+		e.Trace = &trace
+	}
+	return e
+}
+
+// serverConfigDigests returns the sha256 digests of the server config
+// and of each of its sub-systems.
+func serverConfigDigests() (string, map[string]string) {
+	globalServerConfigMu.RLock()
+	defer globalServerConfigMu.RUnlock()
+	return configDigests(globalServerConfig)
+}
+
+// configDigests returns the sha256 digests of cfg and of each of its
+// sub-systems, with sensitive values, like credentials, redacted so
+// that the digests given away with a report cannot be brute forced.
+func configDigests(cfg config.Config) (string, map[string]string) {
+	cfg = cfg.RedactSensitiveInfo()
+
+	subSystems := make([]string, 0, len(cfg))
+	for subSys := range cfg {
+		subSystems = append(subSystems, subSys)
+	}
+	sort.Strings(subSystems)
+
+	digests := make(map[string]string, len(subSystems))
+	all := sha256.New()
+	for _, subSys := range subSystems {
+		data, err := json.Marshal(cfg[subSys])
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		digests[subSys] = hex.EncodeToString(sum[:])
+		all.Write([]byte(subSys))
+		all.Write(sum[:])
+	}
+	return hex.EncodeToString(all.Sum(nil)), digests
+}
+
+// recordPanicIncident records a report of the panic err recovered
+// while serving r, along with the stack of the panicking goroutine.
+func recordPanicIncident(r *http.Request, err interface{}) {
+	if err == http.ErrAbortHandler || !globalIncidentReports.due(incidentPanic, UTCNow(), incidentReportInterval) {
+		return
+	}
+	message := fmt.Sprint(err)
+	if err == logger.ErrCritical {
+		message = "critical error"
+	}
+	rep := newIncidentReport(incidentPanic, message)
+	rep.Method = r.Method
+	rep.Stack = string(debug.Stack())
+	go saveIncidentReport(GlobalContext, rep)
+}
+
+// saveIncidentReport saves rep and removes the oldest reports beyond
+// maxIncidentReports.
+func saveIncidentReport(ctx context.Context, rep IncidentReport) {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return
+	}
+	data, err := json.Marshal(rep)
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	if err = saveConfig(ctx, objAPI, incidentReportFile(rep.ID), data); err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+
+	ids, err := listIncidentReportIDs(ctx, objAPI)
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	for len(ids) > maxIncidentReports {
+		logger.LogIf(ctx, deleteConfig(ctx, objAPI, incidentReportFile(ids[0])))
+		ids = ids[1:]
+	}
+}
+
+// listIncidentReportIDs returns the ids of all reports, oldest first.
+func listIncidentReportIDs(ctx context.Context, objAPI ObjectLayer) ([]string, error) {
+	var ids []string
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, minioMetaBucket, incidentsPrefix+SlashSeparator, marker, "", maxObjectList)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range res.Objects {
+			ids = append(ids, strings.TrimSuffix(path.Base(obj.Name), ".json"))
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// listIncidentReports returns the reports of all nodes, newest first.
+func listIncidentReports(ctx context.Context, objAPI ObjectLayer) ([]IncidentReport, error) {
+	ids, err := listIncidentReportIDs(ctx, objAPI)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]IncidentReport, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		data, err := readConfig(ctx, objAPI, incidentReportFile(ids[i]))
+		if err != nil {
+			if err == errConfigNotFound {
+				// Removed since listed.
+				continue
+			}
+			return nil, err
+		}
+		var rep IncidentReport
+		if err = json.Unmarshal(data, &rep); err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+	return reports, nil
+}
+
+// initIncidentReports starts reporting the memory pressure of this node.
+func initIncidentReports(ctx context.Context) {
+	go runIncidentMemoryCheck(ctx)
+}
+
+func runIncidentMemoryCheck(ctx context.Context) {
+	ticker := time.NewTicker(incidentMemoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			vm, err := mem.VirtualMemory()
+			if err != nil || vm.UsedPercent < incidentMemoryThreshold {
+				continue
+			}
+			if !globalIncidentReports.due(incidentMemory, UTCNow(), incidentMemoryReportInterval) {
+				continue
+			}
+			rep := newIncidentReport(incidentMemory, fmt.Sprintf("system memory %.1f%% used", vm.UsedPercent))
+			rep.MemUsedPercent = vm.UsedPercent
+			var buf bytes.Buffer
+			if err = pprof.Lookup("goroutine").WriteTo(&buf, 1); err == nil {
+				if buf.Len() > incidentStackMaxSize {
+					buf.Truncate(incidentStackMaxSize)
+				}
+				rep.Stack = buf.String()
+			}
+			saveIncidentReport(ctx, rep)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger/message/log"
+)
+
+func TestRedactIncidentLogEntry(t *testing.T) {
+	e := log.Entry{
+		RemoteHost: "10.0.0.1",
+		UserAgent:  "aws-sdk-go",
+		Message:    "unable to read object",
+		API: &log.API{
+			Name: "GetObject",
+			Args: &log.Args{Bucket: "bucket", Object: "object", Metadata: map[string]string{"X-Amz-Meta-Owner": "alice"}},
+		},
+		Trace: &log.Trace{Message: "drive not found", Variables: map[string]interface{}{"drive": "/data1"}},
+	}
+	r := redactIncidentLogEntry(e)
+	if r.RemoteHost != "" || r.UserAgent != "" || r.API.Args.Metadata != nil || r.Trace.Variables != nil {
+		t.Fatalf("expected entry to be redacted, got %+v", r)
+	}
+	if r.Message != e.Message || r.API.Name != "GetObject" || r.API.Args.Bucket != "bucket" || r.Trace.Message != e.Trace.Message {
+		t.Fatalf("expected entry to keep its message and API, got %+v", r)
+	}
+	if e.API.Args.Metadata == nil || e.Trace.Variables == nil {
+		t.Fatal("expected the original entry to be left as is")
+	}
+}
+
+func TestIncidentReportsDue(t *testing.T) {
+	ir := &incidentReports{last: make(map[string]time.Time)}
+	now := time.Now()
+	if !ir.due(incidentPanic, now, time.Minute) {
+		t.Fatal("expected first panic to be reported")
+	}
+	if ir.due(incidentPanic, now.Add(30*time.Second), time.Minute) {
+		t.Fatal("expected panic within the interval not to be reported")
+	}
+	if !ir.due(incidentMemory, now.Add(30*time.Second), time.Hour) {
+		t.Fatal("expected kinds to be reported independently")
+	}
+	if !ir.due(incidentPanic, now.Add(time.Minute), time.Minute) {
+		t.Fatal("expected panic after the interval to be reported")
+	}
+}
+
+func TestConfigDigests(t *testing.T) {
+	ldapConfig := func(serverAddr, skipVerify string) config.Config {
+		return config.Config{
+			config.IdentityLDAPSubSys: {
+				config.Default: config.KVS{
+					{Key: "server_addr", Value: serverAddr},
+					{Key: "tls_skip_verify", Value: skipVerify},
+				},
+			},
+		}
+	}
+	digest, subSystems := configDigests(ldapConfig("ldap.example.com:636", "off"))
+	if len(subSystems) != 1 || subSystems[config.IdentityLDAPSubSys] == "" {
+		t.Fatalf("unexpected sub-system digests %v", subSystems)
+	}
+
+	// Sensitive values are not part of the digests.
+	if d, _ := configDigests(ldapConfig("ldap.other.com:636", "off")); d != digest {
+		t.Fatal("expected the digest not to depend on sensitive values")
+	}
+	if d, _ := configDigests(ldapConfig("ldap.example.com:636", "on")); d == digest {
+		t.Fatal("expected the digest to depend on other values")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+func TestInflightRequests(t *testing.T) {
+	tracker := &inflightRequests{reqs: make(map[string]*inflightEntry)}
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set(xhttp.AmzRequestID, "16A0E4C2E3F0C0D1")
+	w := logger.NewResponseWriter(rec)
+	req := httptest.NewRequest(http.MethodPut, "/bucket/object", strings.NewReader("hello"))
+	req.Header.Set(xhttp.Authorization, "AWS4-HMAC-SHA256 Credential=minio/20211102/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=0")
+
+	r, untrack := tracker.track(req, "PutObject", w)
+	if _, err := ioutil.ReadAll(r.Body); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("ok"))
+
+	reqs := tracker.list()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request in flight, got %d", len(reqs))
+	}
+	if reqs[0].ID != "16A0E4C2E3F0C0D1" || reqs[0].API != "PutObject" || reqs[0].Method != http.MethodPut ||
+		reqs[0].Principal != "minio" || reqs[0].Canceled {
+		t.Errorf("unexpected request %#v", reqs[0])
+	}
+	if reqs[0].BytesReceived != 5 || reqs[0].BytesSent == 0 {
+		t.Errorf("unexpected transfer counters %d/%d", reqs[0].BytesReceived, reqs[0].BytesSent)
+	}
+
+	if tracker.cancel("unknown") {
+		t.Error("expected unknown request not to be canceled")
+	}
+	if !tracker.cancel("16A0E4C2E3F0C0D1") {
+		t.Fatal("expected request to be canceled")
+	}
+	if r.Context().Err() == nil {
+		t.Error("expected request context to be canceled")
+	}
+	if reqs = tracker.list(); len(reqs) != 1 || !reqs[0].Canceled {
+		t.Errorf("expected the request to be listed as canceled, got %v", reqs)
+	}
+
+	untrack()
+	if reqs = tracker.list(); len(reqs) != 0 {
+		t.Errorf("expected no requests in flight, got %d", len(reqs))
+	}
+}
+
+func TestRequestAccessKey(t *testing.T) {
+	testCases := []struct {
+		url, auth, accessKey string
+	}{
+		{"/bucket", "AWS4-HMAC-SHA256 Credential=minio/20211102/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=0", "minio"},
+		{"/bucket", "AWS minio:c2lnbmF0dXJl", "minio"},
+		{"/bucket?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=minio%2F20211102%2Fus-east-1%2Fs3%2Faws4_request", "", "minio"},
+		{"/bucket?AWSAccessKeyId=minio&Signature=0", "", "minio"},
+		{"/bucket", "", ""},
+		{"/bucket", "AWS4-HMAC-SHA256 Signature=0", ""},
+	}
+	for i, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, testCase.url, nil)
+		if testCase.auth != "" {
+			req.Header.Set(xhttp.Authorization, testCase.auth)
+		}
+		if accessKey := requestAccessKey(req); accessKey != testCase.accessKey {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.accessKey, accessKey)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/google/uuid"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/tinylib/msgp/msgp"
+)
+
+var errXLMetaNoVersions = errors.New("no versions would be left")
+
+var inspectMetaFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "drop",
+		Usage: "drop the version entry with this version id, \"null\" for the null version",
+	},
+	cli.BoolFlag{
+		Name:  "yes",
+		Usage: "write the repaired xl.meta, after saving a backup next to it",
+	},
+}
+
+var inspectMetaCmd = cli.Command{
+	Name:   "inspect-meta",
+	Usage:  "decode, validate and repair xl.meta files offline",
+	Flags:  inspectMetaFlags,
+	Action: inspectMetaMain,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} FILE [FILE..]
+  {{.HelpName}} --drop VERSIONID [--yes] FILE
+{{if .VisibleFlags}}
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}{{end}}
+The server must not be running on the drive of FILE while repairing it.
+Without --yes a repair only reports the versions it would keep.
+
+EXAMPLES:
+  1. Decode and validate all versions of an object on a drive.
+     {{.Prompt}} {{.HelpName}} /mnt/drive1/bucket/object/xl.meta
+
+  2. Drop a corrupt version entry, keeping a backup of the original.
+     {{.Prompt}} {{.HelpName}} --drop 2e8e5c5a-3f4c-4d75-8a9c-6d1d55a0b6e7 --yes /mnt/drive1/bucket/object/xl.meta
+`,
+}
+
+// XLMetaInspectVersion - a decoded version entry of xl.meta and its
+// problems, if any.
+type XLMetaInspectVersion struct {
+	Index     int             `json:"index"`
+	Type      string          `json:"type"`
+	VersionID string          `json:"versionId"`
+	ModTime   time.Time       `json:"modTime"`
+	Problems  []string        `json:"problems,omitempty"`
+	Entry     xlMetaV2Version `json:"entry"`
+}
+
+// XLMetaInspectReport - the decoded content of an xl.meta file and its
+// problems, if any.
+type XLMetaInspectReport struct {
+	File       string                 `json:"file"`
+	Major      uint16                 `json:"major"`
+	Minor      uint16                 `json:"minor"`
+	CRCOK      bool                   `json:"crcOk"`
+	Versions   []XLMetaInspectVersion `json:"versions"`
+	InlineData []string               `json:"inlineData,omitempty"`
+	Problems   []string               `json:"problems,omitempty"`
+}
+
+// decodeXLMetaLenient decodes buf as xl.meta like xlMetaV2.Load does,
+// but reports a CRC mismatch and invalid inline data instead of failing,
+// such that damaged files can still be inspected and repaired.
+func decodeXLMetaLenient(buf []byte) (z xlMetaV2, report XLMetaInspectReport, err error) {
+	payload, major, minor, err := checkXL2V1(buf)
+	if err != nil {
+		return z, report, err
+	}
+	report.Major, report.Minor, report.CRCOK = major, minor, true
+	if major != 1 || minor > xlVersionMinor {
+		return z, report, fmt.Errorf("unknown metadata version %d.%d", major, minor)
+	}
+	if minor == 0 {
+		_, err = z.UnmarshalMsg(payload)
+		return z, report, err
+	}
+	v, rest, err := msgp.ReadBytesZC(payload)
+	if err != nil {
+		return z, report, err
+	}
+	if minor >= 2 {
+		crc, nrest, err := msgp.ReadUint32Bytes(rest)
+		if err != nil {
+			return z, report, err
+		}
+		rest = nrest
+		if got := uint32(xxhash.Sum64(v)); got != crc {
+			report.CRCOK = false
+			report.Problems = append(report.Problems, fmt.Sprintf("metadata CRC mismatch, want 0x%x, got 0x%x", crc, got))
+		}
+	}
+	if _, err = z.UnmarshalMsg(v); err != nil {
+		return z, report, err
+	}
+	z.data = rest
+	if err := z.data.validate(); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("inline data: %v", err))
+		z.data.repair()
+	}
+	return z, report, nil
+}
+
+func xlMetaVersionType(t VersionType) string {
+	switch t {
+	case ObjectType:
+		return "object"
+	case DeleteType:
+		return "delete-marker"
+	case LegacyType:
+		return "legacy"
+	}
+	return fmt.Sprintf("invalid(%d)", t)
+}
+
+// xlMetaVersionID returns the version id of the entry, "null" for the
+// null version.
+func xlMetaVersionID(j xlMetaV2Version) string {
+	var id [16]byte
+	switch j.Type {
+	case LegacyType:
+		if j.ObjectV1 != nil && j.ObjectV1.VersionID != "" {
+			return j.ObjectV1.VersionID
+		}
+		return nullVersionID
+	case ObjectType:
+		if j.ObjectV2 != nil {
+			id = j.ObjectV2.VersionID
+		}
+	case DeleteType:
+		if j.DeleteMarker != nil {
+			id = j.DeleteMarker.VersionID
+		}
+	}
+	if id == [16]byte{} {
+		return nullVersionID
+	}
+	return uuid.UUID(id).String()
+}
+
+// inspectXLMetaVersion returns the problems of the version entry j.
+func inspectXLMetaVersion(j xlMetaV2Version) []string {
+	var problems []string
+	if !j.Valid() {
+		problems = append(problems, "invalid version entry")
+	}
+	if j.Type != ObjectType || j.ObjectV2 == nil {
+		return problems
+	}
+	obj := j.ObjectV2
+	if len(obj.PartNumbers) != len(obj.PartSizes) || len(obj.PartNumbers) != len(obj.PartETags) {
+		problems = append(problems, fmt.Sprintf("%d part numbers, %d part sizes and %d part etags", len(obj.PartNumbers), len(obj.PartSizes), len(obj.PartETags)))
+	}
+	if len(obj.PartActualSizes) > 0 && len(obj.PartActualSizes) != len(obj.PartSizes) {
+		problems = append(problems, fmt.Sprintf("%d part actual sizes for %d parts", len(obj.PartActualSizes), len(obj.PartSizes)))
+	}
+	var size int64
+	for _, partSize := range obj.PartSizes {
+		size += partSize
+	}
+	if size != obj.Size {
+		problems = append(problems, fmt.Sprintf("part sizes add up to %d, object size is %d", size, obj.Size))
+	}
+	if len(obj.ErasureDist) != obj.ErasureM+obj.ErasureN {
+		problems = append(problems, fmt.Sprintf("erasure distribution of %d drives for %d data and %d parity blocks", len(obj.ErasureDist), obj.ErasureM, obj.ErasureN))
+	}
+	if obj.ErasureIndex < 1 || obj.ErasureIndex > obj.ErasureM+obj.ErasureN {
+		problems = append(problems, fmt.Sprintf("erasure index %d out of range", obj.ErasureIndex))
+	}
+	return problems
+}
+
+// inspectXLMeta decodes and validates the xl.meta file.
+func inspectXLMeta(file string, buf []byte) (xlMetaV2, XLMetaInspectReport, error) {
+	z, report, err := decodeXLMetaLenient(buf)
+	report.File = file
+	if err != nil {
+		return z, report, err
+	}
+	report.Versions = []XLMetaInspectVersion{}
+	seen := make(map[string]int, len(z.Versions))
+	for i, j := range z.Versions {
+		v := XLMetaInspectVersion{
+			Index:     i,
+			Type:      xlMetaVersionType(j.Type),
+			VersionID: xlMetaVersionID(j),
+			Problems:  inspectXLMetaVersion(j),
+			Entry:     j,
+		}
+		if j.Valid() {
+			v.ModTime = j.getModTime().UTC()
+		}
+		if prev, ok := seen[v.VersionID]; ok {
+			v.Problems = append(v.Problems, fmt.Sprintf("duplicate of version entry %d", prev))
+		}
+		seen[v.VersionID] = i
+		report.Versions = append(report.Versions, v)
+	}
+	report.InlineData, _ = z.data.list()
+	return z, report, nil
+}
+
+// dropXLMetaVersion removes the version entries with versionID from z
+// and their inline data, returning how many were removed.
+func dropXLMetaVersion(z *xlMetaV2, versionID string) int {
+	var versions []xlMetaV2Version
+	var dropped int
+	for _, j := range z.Versions {
+		if xlMetaVersionID(j) != versionID {
+			versions = append(versions, j)
+			continue
+		}
+		dropped++
+		keys := []string{versionID}
+		if versionID == nullVersionID {
+			keys = append(keys, uuid.UUID{}.String())
+		}
+		if j.Type == ObjectType && j.ObjectV2 != nil {
+			keys = append(keys, uuid.UUID(j.ObjectV2.DataDir).String())
+		}
+		z.data.remove(keys...)
+	}
+	z.Versions = versions
+	return dropped
+}
+
+func inspectMetaMain(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "inspect-meta", 1)
+	}
+	versionID := ctx.String("drop")
+	if versionID != "" && len(ctx.Args()) != 1 {
+		console.Fatalln("Only one xl.meta can be repaired at a time")
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	var failed bool
+	for _, file := range ctx.Args() {
+		buf, err := ioutil.ReadFile(file)
+		if err != nil {
+			console.Fatalln(fmt.Errorf("Unable to read %s: %w", file, err))
+		}
+		z, report, err := inspectXLMeta(file, buf)
+		if err != nil {
+			report.Problems = append(report.Problems, err.Error())
+			failed = true
+		}
+		for _, v := range report.Versions {
+			failed = failed || len(v.Problems) > 0
+		}
+		failed = failed || len(report.Problems) > 0
+		if versionID == "" {
+			if err := enc.Encode(report); err != nil {
+				console.Fatalln(err)
+			}
+			continue
+		}
+		if err != nil {
+			console.Fatalln(fmt.Errorf("Unable to decode %s for repair: %w", file, err))
+		}
+		if dropXLMetaVersion(&z, versionID) == 0 {
+			console.Fatalln(fmt.Errorf("No version entry %s in %s", versionID, file))
+		}
+		if len(z.Versions) == 0 {
+			console.Fatalln(fmt.Errorf("Unable to repair %s: %w, remove the object instead", file, errXLMetaNoVersions))
+		}
+		repaired, err := z.AppendTo(nil)
+		if err != nil {
+			console.Fatalln(fmt.Errorf("Unable to encode the repaired %s: %w", file, err))
+		}
+		_, report, err = inspectXLMeta(file, repaired)
+		if err != nil {
+			console.Fatalln(fmt.Errorf("Unable to decode the repaired %s: %w", file, err))
+		}
+		if err := enc.Encode(report); err != nil {
+			console.Fatalln(err)
+		}
+		if !ctx.Bool("yes") {
+			console.Println("Not written, run again with --yes to write the repaired xl.meta")
+			return
+		}
+		if err := writeXLMetaRepair(file, buf, repaired); err != nil {
+			console.Fatalln(err)
+		}
+		return
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// writeXLMetaRepair saves the original xl.meta next to file and replaces
+// file by the repaired one.
+func writeXLMetaRepair(file string, original, repaired []byte) error {
+	backup := fmt.Sprintf("%s.bak-%d", file, time.Now().Unix())
+	f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to create the backup %s: %w", backup, err)
+	}
+	if _, err = f.Write(original); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write the backup %s: %w", backup, err)
+	}
+	tmp := file + ".repair"
+	if err = ioutil.WriteFile(tmp, repaired, 0644); err != nil {
+		return fmt.Errorf("Unable to write the repaired %s: %w", file, err)
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Unable to replace %s: %w", file, err)
+	}
+	console.Printf("Repaired %s, the original is saved as %s\n", file, backup)
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInspectXLMeta(t *testing.T) {
+	fi := FileInfo{
+		Volume:    "volume",
+		Name:      "object-name",
+		VersionID: "756100c6-b393-4981-928a-d49bbc164741",
+		DataDir:   "bffea160-ca7f-465f-98bc-9b4f1c3ba1ef",
+		ModTime:   time.Now(),
+		Erasure: ErasureInfo{
+			Algorithm:    ReedSolomon.String(),
+			DataBlocks:   4,
+			ParityBlocks: 2,
+			BlockSize:    10000,
+			Index:        1,
+			Distribution: []int{1, 2, 3, 4, 5, 6},
+			Checksums: []ChecksumInfo{{
+				PartNumber: 1,
+				Algorithm:  HighwayHash256S,
+			}},
+		},
+		Data: []byte("some object data"),
+	}
+	var z xlMetaV2
+	if err := z.AddVersion(fi); err != nil {
+		t.Fatal(err)
+	}
+	fi.VersionID = "8a8f0a9a-3c1f-4b4b-a5d8-6d3e2f7c9b11"
+	fi.DataDir = "413a5c3f-8c3a-4e4b-9f0b-0d8f1a6f2e3c"
+	fi.ModTime = fi.ModTime.Add(time.Second)
+	if err := z.AddVersion(fi); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := z.AppendTo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, report, err := inspectXLMeta("xl.meta", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.CRCOK || len(report.Problems) > 0 || len(report.Versions) != 2 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	for _, v := range report.Versions {
+		if len(v.Problems) > 0 {
+			t.Fatalf("Unexpected problems of version %s: %v", v.VersionID, v.Problems)
+		}
+	}
+	if len(report.InlineData) != 2 {
+		t.Fatalf("Expected the inline data of 2 versions, got %v", report.InlineData)
+	}
+
+	// A damaged CRC is reported, the versions are still decoded.
+	damaged := append([]byte{}, buf...)
+	payloadLen := int(damaged[9])<<24 | int(damaged[10])<<16 | int(damaged[11])<<8 | int(damaged[12])
+	damaged[13+payloadLen+1] ^= 0xff
+	damagedMeta, report, err := inspectXLMeta("xl.meta", damaged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CRCOK || len(report.Versions) != 2 {
+		t.Fatalf("Expected a CRC mismatch with 2 versions, got %+v", report)
+	}
+
+	// Dropping a version repairs the file, removing its inline data.
+	if n := dropXLMetaVersion(&damagedMeta, "8a8f0a9a-3c1f-4b4b-a5d8-6d3e2f7c9b11"); n != 1 {
+		t.Fatalf("Expected 1 version to be dropped, got %d", n)
+	}
+	repaired, err := damagedMeta.AppendTo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, report, err = inspectXLMeta("xl.meta", repaired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.CRCOK || len(report.Versions) != 1 || report.Versions[0].VersionID != "756100c6-b393-4981-928a-d49bbc164741" {
+		t.Fatalf("Unexpected repaired report %+v", report)
+	}
+	if len(report.InlineData) != 1 {
+		t.Fatalf("Expected the inline data of 1 version, got %v", report.InlineData)
+	}
+
+	// Duplicate version entries are reported.
+	damagedMeta.Versions = append(damagedMeta.Versions, damagedMeta.Versions[0])
+	duplicated, err := damagedMeta.AppendTo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, report, err = inspectXLMeta("xl.meta", duplicated); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Versions) != 2 || len(report.Versions[1].Problems) != 1 {
+		t.Fatalf("Expected the duplicate version to be reported, got %+v", report.Versions)
+	}
+}
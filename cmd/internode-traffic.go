@@ -0,0 +1,117 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio/internal/config/rpc"
+	"golang.org/x/time/rate"
+)
+
+// trafficClass classifies internode requests for their bandwidth caps.
+type trafficClass int
+
+const (
+	trafficClassDefault trafficClass = iota
+	trafficClassHeal
+	trafficClassReplication
+)
+
+type trafficClassKey struct{}
+
+// withTrafficClass returns ctx classifying the internode requests
+// made with it as class.
+func withTrafficClass(ctx context.Context, class trafficClass) context.Context {
+	return context.WithValue(ctx, trafficClassKey{}, class)
+}
+
+func trafficClassFromContext(ctx context.Context) trafficClass {
+	class, _ := ctx.Value(trafficClassKey{}).(trafficClass)
+	return class
+}
+
+// detachedContext returns a context carrying only the traffic class,
+// correlation ID and fsync policy of ctx, for drive streams which must not
+// end with ctx.
+func detachedContext(ctx context.Context) context.Context {
+	dctx := context.Background()
+	if class := trafficClassFromContext(ctx); class != trafficClassDefault {
+		dctx = withTrafficClass(dctx, class)
+	}
+	if id := correlationIDFromContext(ctx); id != "" {
+		dctx = withCorrelationID(dctx, id)
+	}
+	if policy := fsyncPolicyFromContext(ctx); policy != fsyncAlways {
+		dctx = withFsyncPolicy(dctx, policy)
+	}
+	return dctx
+}
+
+// trafficLimiters are the bandwidth caps of the internode traffic
+// classes of this node, nil if unlimited.
+type trafficLimiters struct {
+	heal        *rate.Limiter
+	replication *rate.Limiter
+}
+
+func newTrafficLimiter(bytesPerSec uint64) *rate.Limiter {
+	if bytesPerSec == 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+func newTrafficLimiters(cfg rpc.Config) trafficLimiters {
+	return trafficLimiters{
+		heal:        newTrafficLimiter(cfg.HealBandwidth),
+		replication: newTrafficLimiter(cfg.ReplicationBandwidth),
+	}
+}
+
+func (l trafficLimiters) get(class trafficClass) *rate.Limiter {
+	switch class {
+	case trafficClassHeal:
+		return l.heal
+	case trafficClassReplication:
+		return l.replication
+	}
+	return nil
+}
+
+// throttledBody reads a request or response body within the bandwidth
+// of its limiter.
+type throttledBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if burst := b.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := b.limiter.WaitN(b.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config/rpc"
+)
+
+func TestDetachedContext(t *testing.T) {
+	ctx := withCorrelationID(withTrafficClass(context.Background(), trafficClassHeal), "app-42")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	bctx := detachedContext(ctx)
+	if bctx.Err() != nil {
+		t.Fatal("expected the detached context to outlive ctx")
+	}
+	if class := trafficClassFromContext(bctx); class != trafficClassHeal {
+		t.Fatalf("expected heal traffic class, got %d", class)
+	}
+	if id := correlationIDFromContext(bctx); id != "app-42" {
+		t.Fatalf("expected correlation ID app-42, got %q", id)
+	}
+	if class := trafficClassFromContext(detachedContext(context.Background())); class != trafficClassDefault {
+		t.Fatalf("expected default traffic class, got %d", class)
+	}
+}
+
+func TestThrottledBody(t *testing.T) {
+	limiters := newTrafficLimiters(rpc.Config{HealBandwidth: 64 << 10})
+	if limiters.get(trafficClassReplication) != nil || limiters.get(trafficClassDefault) != nil {
+		t.Fatal("expected only heal traffic to be limited")
+	}
+
+	data := make([]byte, 128<<10)
+	body := &throttledBody{
+		ReadCloser: ioutil.NopCloser(bytes.NewReader(data)),
+		ctx:        context.Background(),
+		limiter:    limiters.get(trafficClassHeal),
+	}
+	start := time.Now()
+	got, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("throttled body differs")
+	}
+	// A second of burst, then a second for the rest.
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("read 128KiB at 64KiB/s in %s", elapsed)
+	}
+}
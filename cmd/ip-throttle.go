@@ -0,0 +1,327 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const (
+	ipThrottleFailuresWindow = time.Minute
+	ipThrottleRequestsWindow = time.Second
+	ipThrottleMaxBan         = time.Hour
+
+	// Clients are tracked in shards, each with its own lock, bounded to
+	// ipThrottleMaxClients in total, the least recently seen are
+	// forgotten first.
+	ipThrottleShards     = 64
+	ipThrottleMaxClients = 1 << 17
+)
+
+// ipThrottleClient tracks the recent requests, authentication failures
+// and bans of one client address.
+type ipThrottleClient struct {
+	addr          string
+	failures      int
+	failuresStart time.Time
+	requests      int
+	requestsStart time.Time
+	bans          int
+	bannedUntil   time.Time
+	lastSeen      time.Time
+}
+
+// IPThrottleStats - statistics of the client throttling of this node.
+type IPThrottleStats struct {
+	Banned       uint64
+	BansTotal    uint64
+	AuthFailures uint64
+	Rejected     uint64
+}
+
+type ipThrottleConfig struct {
+	authFailures int
+	requestsRate int
+	banDuration  time.Duration
+	allowlist    []*net.IPNet
+}
+
+// enabled returns whether clients are throttled.
+func (cfg *ipThrottleConfig) enabled() bool {
+	return cfg.authFailures > 0 || cfg.requestsRate > 0
+}
+
+// ipThrottleShard holds the clients of a shard, most recently seen
+// first.
+type ipThrottleShard struct {
+	mu      sync.Mutex
+	clients map[string]*list.Element
+	lru     *list.List
+}
+
+// ipThrottle bans clients temporarily after too many authentication
+// failures or requests, longer for each repeated ban, so a misbehaving
+// client cannot hammer signature verification.
+type ipThrottle struct {
+	cfg    atomic.Value // *ipThrottleConfig
+	shards [ipThrottleShards]ipThrottleShard
+
+	bansTotal    uint64
+	authFailures uint64
+	rejected     uint64
+}
+
+func newIPThrottle() *ipThrottle {
+	t := &ipThrottle{}
+	t.cfg.Store(&ipThrottleConfig{banDuration: time.Minute})
+	for i := range t.shards {
+		t.shards[i].clients = make(map[string]*list.Element)
+		t.shards[i].lru = list.New()
+	}
+	return t
+}
+
+var globalIPThrottle = newIPThrottle()
+
+func (t *ipThrottle) setConfig(authFailures, requestsRate int, banDuration time.Duration, allowlist []string) {
+	// The allowlist is validated by the api config.
+	ipNets, _ := parseNetworks(allowlist)
+	cfg := &ipThrottleConfig{
+		authFailures: authFailures,
+		requestsRate: requestsRate,
+		banDuration:  banDuration,
+		allowlist:    ipNets,
+	}
+	t.cfg.Store(cfg)
+	if !cfg.enabled() {
+		for i := range t.shards {
+			sh := &t.shards[i]
+			sh.mu.Lock()
+			sh.clients = make(map[string]*list.Element)
+			sh.lru.Init()
+			sh.mu.Unlock()
+		}
+	}
+}
+
+func (t *ipThrottle) config() *ipThrottleConfig {
+	return t.cfg.Load().(*ipThrottleConfig)
+}
+
+// clientAddress returns the address r is throttled by, the peer address
+// unless it is in the allowlist. Allowlisted load balancers are trusted
+// to forward the client address, the last forwarded address not in the
+// allowlist, they are never banned themselves.
+func (t *ipThrottle) clientAddress(r *http.Request) string {
+	allowlist := t.config().allowlist
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !containsIP(allowlist, net.ParseIP(host)) {
+		return host
+	}
+	ip := networkSourceIP(r, allowlist)
+	if ip == nil || containsIP(allowlist, ip) {
+		return ""
+	}
+	return ip.String()
+}
+
+// ban bans c for the next ban duration, the caller must hold the lock
+// of its shard.
+func (t *ipThrottle) ban(cfg *ipThrottleConfig, c *ipThrottleClient, now time.Time) {
+	d := cfg.banDuration << uint(c.bans)
+	if d <= 0 || d > ipThrottleMaxBan {
+		d = ipThrottleMaxBan
+	}
+	c.bans++
+	c.bannedUntil = now.Add(d)
+	c.failures, c.requests = 0, 0
+	atomic.AddUint64(&t.bansTotal, 1)
+}
+
+func (t *ipThrottle) shard(addr string) *ipThrottleShard {
+	return &t.shards[xxhash.Sum64String(addr)%ipThrottleShards]
+}
+
+// client returns the state of addr, the caller must hold the lock of
+// the shard.
+func (sh *ipThrottleShard) client(addr string, now time.Time) *ipThrottleClient {
+	if e, ok := sh.clients[addr]; ok {
+		sh.lru.MoveToFront(e)
+		c := e.Value.(*ipThrottleClient)
+		c.lastSeen = now
+		return c
+	}
+	// Forget clients idle long enough, their bans having ended with
+	// them, and the least recently seen beyond the bound.
+	for e := sh.lru.Back(); e != nil; e = sh.lru.Back() {
+		c := e.Value.(*ipThrottleClient)
+		if sh.lru.Len() < ipThrottleMaxClients/ipThrottleShards && now.Sub(c.lastSeen) <= ipThrottleMaxBan {
+			break
+		}
+		sh.lru.Remove(e)
+		delete(sh.clients, c.addr)
+	}
+	c := &ipThrottleClient{addr: addr, lastSeen: now}
+	sh.clients[addr] = sh.lru.PushFront(c)
+	return c
+}
+
+// allow counts a request of addr and returns how long addr remains
+// banned, zero if the request is allowed.
+func (t *ipThrottle) allow(addr string, now time.Time) time.Duration {
+	cfg := t.config()
+	if !cfg.enabled() || addr == "" {
+		return 0
+	}
+	sh := t.shard(addr)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	c := sh.client(addr, now)
+	if now.Before(c.bannedUntil) {
+		atomic.AddUint64(&t.rejected, 1)
+		return c.bannedUntil.Sub(now)
+	}
+	if cfg.requestsRate == 0 {
+		return 0
+	}
+	if now.Sub(c.requestsStart) > ipThrottleRequestsWindow {
+		c.requests, c.requestsStart = 0, now
+	}
+	c.requests++
+	if c.requests > cfg.requestsRate {
+		t.ban(cfg, c, now)
+		atomic.AddUint64(&t.rejected, 1)
+		return c.bannedUntil.Sub(now)
+	}
+	return 0
+}
+
+// authFailed counts an authentication failure of addr.
+func (t *ipThrottle) authFailed(addr string, now time.Time) {
+	cfg := t.config()
+	if cfg.authFailures == 0 || addr == "" {
+		return
+	}
+	atomic.AddUint64(&t.authFailures, 1)
+	sh := t.shard(addr)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	c := sh.client(addr, now)
+	if now.Sub(c.failuresStart) > ipThrottleFailuresWindow {
+		c.failures, c.failuresStart = 0, now
+	}
+	c.failures++
+	if c.failures >= cfg.authFailures {
+		t.ban(cfg, c, now)
+	}
+}
+
+// Stats returns the throttling statistics.
+func (t *ipThrottle) Stats() IPThrottleStats {
+	stats := IPThrottleStats{
+		BansTotal:    atomic.LoadUint64(&t.bansTotal),
+		AuthFailures: atomic.LoadUint64(&t.authFailures),
+		Rejected:     atomic.LoadUint64(&t.rejected),
+	}
+	now := time.Now()
+	for i := range t.shards {
+		sh := &t.shards[i]
+		sh.mu.Lock()
+		for _, e := range sh.clients {
+			if now.Before(e.Value.(*ipThrottleClient).bannedUntil) {
+				stats.Banned++
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return stats
+}
+
+type ipThrottleContextKey struct{}
+
+// isAuthFailure returns whether the error code is a failed authentication.
+func isAuthFailure(code string) bool {
+	switch code {
+	case "SignatureDoesNotMatch", "InvalidAccessKeyId", "InvalidTokenId":
+		return true
+	}
+	return false
+}
+
+// recordAuthFailure counts the failed authentication of the request of
+// ctx against its client.
+func recordAuthFailure(ctx context.Context, code string) {
+	if !isAuthFailure(code) {
+		return
+	}
+	if addr, ok := ctx.Value(ipThrottleContextKey{}).(string); ok {
+		globalIPThrottle.authFailed(addr, time.Now())
+	}
+}
+
+// isInternodeReq returns whether r is a request of another node.
+func isInternodeReq(r *http.Request) bool {
+	for _, prefix := range []string{peerRESTPrefix, storageRESTPrefix, lockRESTPrefix, bootstrapRESTPrefix} {
+		if strings.HasPrefix(r.URL.Path, prefix+SlashSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// setIPThrottleHandler rejects the requests of banned clients before
+// they are authenticated. Internode, health check and metrics requests
+// are not throttled.
+func setIPThrottleHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isInternodeReq(r) || guessIsHealthCheckReq(r) || guessIsMetricsReq(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		addr := globalIPThrottle.clientAddress(r)
+		if d := globalIPThrottle.allow(addr, time.Now()); d > 0 {
+			w.Header().Set(xhttp.Connection, "close")
+			w.Header().Set(xhttp.RetryAfter, retryAfterSeconds(d))
+			writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrSlowDown), r.URL)
+			return
+		}
+		if addr != "" {
+			r = r.WithContext(context.WithValue(r.Context(), ipThrottleContextKey{}, addr))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// retryAfterSeconds formats d as a Retry-After value.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int((d + time.Second - 1) / time.Second))
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIPThrottleBans(t *testing.T) {
+	th := newIPThrottle()
+	th.setConfig(3, 0, time.Minute, nil)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if d := th.allow("192.0.2.1", now); d != 0 {
+			t.Fatalf("expected request %d to be allowed, banned for %s", i+1, d)
+		}
+		th.authFailed("192.0.2.1", now)
+	}
+	if d := th.allow("192.0.2.1", now); d != time.Minute {
+		t.Fatalf("expected a ban of a minute, got %s", d)
+	}
+	if d := th.allow("192.0.2.2", now); d != 0 {
+		t.Fatalf("expected other clients to be allowed, banned for %s", d)
+	}
+
+	// Repeated bans last twice as long.
+	now = now.Add(time.Minute + time.Second)
+	for i := 0; i < 3; i++ {
+		th.authFailed("192.0.2.1", now)
+	}
+	if d := th.allow("192.0.2.1", now); d != 2*time.Minute {
+		t.Fatalf("expected a ban of two minutes, got %s", d)
+	}
+	if stats := th.Stats(); stats.BansTotal != 2 || stats.AuthFailures != 6 {
+		t.Fatalf("unexpected stats %#v", stats)
+	}
+}
+
+func TestIPThrottleRequestsRate(t *testing.T) {
+	th := newIPThrottle()
+	th.setConfig(0, 2, time.Minute, nil)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if d := th.allow("192.0.2.1", now); d != 0 {
+			t.Fatalf("expected request %d to be allowed, banned for %s", i+1, d)
+		}
+	}
+	if d := th.allow("192.0.2.1", now); d == 0 {
+		t.Fatal("expected the client to be banned")
+	}
+}
+
+func TestIPThrottleClientAddress(t *testing.T) {
+	th := newIPThrottle()
+	th.setConfig(1, 0, time.Minute, []string{"10.0.0.0/24"})
+
+	testCases := []struct {
+		remoteAddr, forwardedFor, addr string
+	}{
+		{"192.0.2.1:4000", "", "192.0.2.1"},
+		// Forwarded addresses of other clients are not trusted.
+		{"192.0.2.1:4000", "198.51.100.1", "192.0.2.1"},
+		{"10.0.0.5:4000", "198.51.100.1", "198.51.100.1"},
+		// Addresses set by the client before the one the load balancer
+		// appended are not trusted.
+		{"10.0.0.5:4000", "203.0.113.7, 198.51.100.1", "198.51.100.1"},
+		{"10.0.0.5:4000", "203.0.113.7, 198.51.100.1, 10.0.0.6", "198.51.100.1"},
+		// Load balancers are never banned.
+		{"10.0.0.5:4000", "", ""},
+		{"10.0.0.5:4000", "10.0.0.6", ""},
+	}
+	for i, testCase := range testCases {
+		r := &http.Request{RemoteAddr: testCase.remoteAddr, Header: http.Header{}}
+		if testCase.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", testCase.forwardedFor)
+		}
+		if addr := th.clientAddress(r); addr != testCase.addr {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.addr, addr)
+		}
+	}
+}
+
+func TestIPThrottleClientsBound(t *testing.T) {
+	th := newIPThrottle()
+	th.setConfig(1, 0, time.Minute, nil)
+
+	now := time.Now()
+	th.authFailed("192.0.2.1", now)
+	sh := th.shard("192.0.2.1")
+	perShard := ipThrottleMaxClients / ipThrottleShards
+	for i := 0; sh.lru.Len() < perShard; i++ {
+		if addr := fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff); th.shard(addr) == sh {
+			th.allow(addr, now)
+		}
+	}
+	if d := th.allow("192.0.2.1", now); d == 0 {
+		t.Fatal("expected the client to be banned")
+	}
+
+	// Seen again, the banned client is not the least recently seen.
+	for i := 0; ; i++ {
+		addr := fmt.Sprintf("172.%d.%d.%d", 16+i>>16&0xff, i>>8&0xff, i&0xff)
+		if th.shard(addr) == sh {
+			th.allow(addr, now)
+			break
+		}
+	}
+	if sh.lru.Len() != perShard {
+		t.Fatalf("expected %d clients, got %d", perShard, sh.lru.Len())
+	}
+	if d := th.allow("192.0.2.1", now); d == 0 {
+		t.Fatal("expected the client to stay banned")
+	}
+
+	// Idle clients are forgotten.
+	for i := 0; ; i++ {
+		addr := fmt.Sprintf("198.51.%d.%d", i>>8&0xff, i&0xff)
+		if th.shard(addr) == sh {
+			th.allow(addr, now.Add(ipThrottleMaxBan+time.Minute))
+			break
+		}
+	}
+	if n := sh.lru.Len(); n != 1 {
+		t.Fatalf("expected idle clients to be forgotten, got %d", n)
+	}
+}
@@ -0,0 +1,561 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/cron"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	jobSchedulesConfigFile = "job-schedules.json"
+
+	jobSchedulesFormatVersion1 = 1
+
+	defaultJobWindow = time.Hour
+)
+
+// Background jobs run by the job scheduler.
+const (
+	jobScanner    = "scanner"
+	jobHeal       = "heal"
+	jobILM        = "ilm"
+	jobDedup      = "dedup"
+	jobTrashPurge = "trash-purge"
+)
+
+var backgroundJobs = []string{jobScanner, jobHeal, jobILM, jobDedup, jobTrashPurge}
+
+var (
+	errUnknownJob        = errors.New("Unknown background job, must be one of scanner, heal, ilm, dedup and trash-purge")
+	errDuplicateJob      = errors.New("A background job can only be scheduled once")
+	errInvalidJobWeight  = errors.New("Job weights must not be negative")
+	errInvalidJobWindow  = errors.New("Job windows must be positive durations like '4h'")
+	errWindowWithoutCron = errors.New("A job window needs a cron expression")
+)
+
+// JobSchedule - when a background job may run and how much of the
+// background work of a node it takes.
+type JobSchedule struct {
+	Job string `json:"job"`
+	// Cron expressions, in UTC, of the times windows of the job open.
+	// The job may run at any time without one.
+	Cron []string `json:"cron,omitempty"`
+	// How long a window stays open, like "4h", an hour by default. Work
+	// still running when the window closes is interrupted and continues
+	// in the next window.
+	Window string `json:"window,omitempty"`
+	// Jobs of the same group do not run at the same time on a node.
+	Group string `json:"group,omitempty"`
+	// Weight of the job against the max weight of a node, 1 by default.
+	Weight int `json:"weight,omitempty"`
+	// Jobs of higher priority start before waiting jobs of lower
+	// priority, and preempt the running jobs of lower priority keeping
+	// them from starting. Zero by default.
+	Priority int `json:"priority,omitempty"`
+}
+
+// JobSchedulesConfig - the schedules of the background jobs, jobs not
+// listed run at any time with a weight of 1.
+type JobSchedulesConfig struct {
+	Version int `json:"version"`
+	// The total weight of the jobs running at once on a node, zero is
+	// unlimited. A job heavier than the max weight runs alone.
+	MaxWeight int           `json:"maxWeight,omitempty"`
+	Jobs      []JobSchedule `json:"jobs"`
+}
+
+// JobStatus - the state of a background job on a node.
+type JobStatus struct {
+	Job      string    `json:"job"`
+	Priority int       `json:"priority"`
+	Running  bool      `json:"running"`
+	Since    time.Time `json:"since,omitempty"`
+	// Set while a running job stops for a job of higher priority.
+	Preempted bool `json:"preempted,omitempty"`
+	// Number of times the job was preempted since the node started.
+	Preemptions uint64 `json:"preemptions,omitempty"`
+	// Number of workers of the job waiting to run.
+	Waiting int `json:"waiting,omitempty"`
+	// The jobs keeping the waiting workers from running while the
+	// window of the job is open.
+	BlockedBy []string `json:"blockedBy,omitempty"`
+	// End of the open window, zero when the job is not scheduled.
+	WindowEnd time.Time `json:"windowEnd,omitempty"`
+	// Start of the next window of a scheduled job.
+	NextWindow time.Time `json:"nextWindow,omitempty"`
+}
+
+// NodeJobStatus - the background jobs of a node.
+type NodeJobStatus struct {
+	Host  string      `json:"host"`
+	Jobs  []JobStatus `json:"jobs,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+type jobRule struct {
+	crons    []*cron.Schedule
+	window   time.Duration
+	group    string
+	weight   int
+	priority int
+}
+
+// parseJobSchedules validates cfg and returns the rules of its jobs.
+func parseJobSchedules(cfg JobSchedulesConfig) (map[string]jobRule, error) {
+	if cfg.MaxWeight < 0 {
+		return nil, errInvalidJobWeight
+	}
+	rules := make(map[string]jobRule, len(cfg.Jobs))
+	for _, js := range cfg.Jobs {
+		known := false
+		for _, job := range backgroundJobs {
+			known = known || job == js.Job
+		}
+		if !known {
+			return nil, errUnknownJob
+		}
+		if _, ok := rules[js.Job]; ok {
+			return nil, errDuplicateJob
+		}
+		if js.Weight < 0 {
+			return nil, errInvalidJobWeight
+		}
+		rule := jobRule{window: defaultJobWindow, group: js.Group, weight: js.Weight, priority: js.Priority}
+		if rule.weight == 0 {
+			rule.weight = 1
+		}
+		if js.Window != "" {
+			if len(js.Cron) == 0 {
+				return nil, errWindowWithoutCron
+			}
+			d, err := time.ParseDuration(js.Window)
+			if err != nil || d <= 0 {
+				return nil, errInvalidJobWindow
+			}
+			rule.window = d
+		}
+		for _, expr := range js.Cron {
+			c, err := cron.Parse(expr)
+			if err != nil {
+				return nil, err
+			}
+			rule.crons = append(rule.crons, c)
+		}
+		rules[js.Job] = rule
+	}
+	return rules, nil
+}
+
+// openAt returns whether a window of rule is open at now, when the open
+// window ends and when the next window opens. Rules without schedule
+// are always open.
+func (rule jobRule) openAt(now time.Time) (open bool, end, next time.Time) {
+	if len(rule.crons) == 0 {
+		return true, time.Time{}, time.Time{}
+	}
+	now = now.UTC()
+	for _, c := range rule.crons {
+		// Overlapping windows extend each other.
+		for start := c.Next(now.Add(-rule.window)); !start.IsZero() && !start.After(now); start = c.Next(start) {
+			if e := start.Add(rule.window); e.After(end) {
+				end = e
+			}
+		}
+		if n := c.Next(now); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return end.After(now), end, next
+}
+
+type runningJob struct {
+	// Cancel functions of the contexts of the workers of the run.
+	holders   map[uint64]context.CancelFunc
+	since     time.Time
+	weight    int
+	preempted bool
+}
+
+// jobScheduler decides when the background jobs of a node run. A job
+// runs while a window of its schedule is open, no other job of its
+// group runs, no job of higher priority waits and the weight of the
+// running jobs allows it. Running jobs of lower priority keeping a job
+// from starting are preempted. The workers of a job share a single run.
+type jobScheduler struct {
+	mu          sync.Mutex
+	cfg         JobSchedulesConfig
+	rules       map[string]jobRule
+	running     map[string]*runningJob
+	waiting     map[string]int
+	blocked     map[string]int
+	preemptions map[string]uint64
+	weight      int
+	nextID      uint64
+	changedCh   chan struct{}
+}
+
+func newJobScheduler() *jobScheduler {
+	return &jobScheduler{
+		cfg:         JobSchedulesConfig{Version: jobSchedulesFormatVersion1, Jobs: []JobSchedule{}},
+		rules:       make(map[string]jobRule),
+		running:     make(map[string]*runningJob),
+		waiting:     make(map[string]int),
+		blocked:     make(map[string]int),
+		preemptions: make(map[string]uint64),
+		changedCh:   make(chan struct{}),
+	}
+}
+
+var globalJobScheduler = newJobScheduler()
+
+// changed wakes up the waiting workers, the caller must hold the lock.
+func (s *jobScheduler) changed() {
+	close(s.changedCh)
+	s.changedCh = make(chan struct{})
+}
+
+func (s *jobScheduler) rule(job string) jobRule {
+	if rule, ok := s.rules[job]; ok {
+		return rule
+	}
+	return jobRule{weight: 1}
+}
+
+// overweight returns whether a job of weight does not fit next to
+// running jobs of total weight.
+func (s *jobScheduler) overweight(running, weight int) bool {
+	return s.cfg.MaxWeight > 0 && running > 0 && running+weight > s.cfg.MaxWeight
+}
+
+// blockers returns the sorted jobs keeping job from starting, the
+// caller must hold the lock.
+func (s *jobScheduler) blockers(job string, rule jobRule) []string {
+	if r, ok := s.running[job]; ok {
+		// New workers do not join a preempted run.
+		if r.preempted {
+			return []string{job}
+		}
+		return nil
+	}
+	blocking := make(map[string]struct{})
+	for other, n := range s.blocked {
+		if n > 0 && other != job && s.rule(other).priority > rule.priority {
+			blocking[other] = struct{}{}
+		}
+	}
+	overweight := s.overweight(s.weight, rule.weight)
+	for other := range s.running {
+		if overweight || (rule.group != "" && s.rule(other).group == rule.group) {
+			blocking[other] = struct{}{}
+		}
+	}
+	jobs := make([]string, 0, len(blocking))
+	for other := range blocking {
+		jobs = append(jobs, other)
+	}
+	sort.Strings(jobs)
+	return jobs
+}
+
+// preempt stops the running jobs of lower priority than job keeping it
+// from starting, lowest priority first, when that lets job start. The
+// caller must hold the lock.
+func (s *jobScheduler) preempt(job string, rule jobRule) {
+	var lower []string
+	weight := 0
+	for other, r := range s.running {
+		if r.preempted {
+			continue
+		}
+		if s.rule(other).priority < rule.priority {
+			lower = append(lower, other)
+			continue
+		}
+		if rule.group != "" && s.rule(other).group == rule.group {
+			return
+		}
+		weight += r.weight
+	}
+	if s.overweight(weight, rule.weight) {
+		return
+	}
+	sort.Slice(lower, func(i, j int) bool {
+		return s.rule(lower[i]).priority < s.rule(lower[j]).priority
+	})
+	for _, other := range lower {
+		weight += s.running[other].weight
+	}
+	for _, other := range lower {
+		r := s.running[other]
+		conflict := rule.group != "" && s.rule(other).group == rule.group
+		if !conflict && !s.overweight(weight, rule.weight) {
+			continue
+		}
+		r.preempted = true
+		weight -= r.weight
+		for _, cancel := range r.holders {
+			cancel()
+		}
+		s.preemptions[other]++
+	}
+}
+
+// set replaces the schedules, running jobs keep running.
+func (s *jobScheduler) set(cfg JobSchedulesConfig) error {
+	rules, err := parseJobSchedules(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Jobs == nil {
+		cfg.Jobs = []JobSchedule{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg, s.rules = cfg, rules
+	s.changed()
+	return nil
+}
+
+func (s *jobScheduler) config() JobSchedulesConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// windowOpen returns whether a window of job is open, whether or not
+// other jobs keep it from running.
+func (s *jobScheduler) windowOpen(job string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	open, _, _ := s.rule(job).openAt(UTCNow())
+	return open
+}
+
+// acquire blocks until job may run and returns a context canceled when
+// the window of the job closes or the job is preempted, the job keeps
+// running until release is called. An error is returned when ctx is
+// canceled while waiting.
+func (s *jobScheduler) acquire(ctx context.Context, job string) (jctx context.Context, release func(), err error) {
+	// Whether this worker counts as blocked, waiting while the window
+	// of the job is open.
+	blocked := false
+	unblock := func() {
+		if !blocked {
+			return
+		}
+		blocked = false
+		s.blocked[job]--
+		if s.blocked[job] == 0 {
+			// Jobs of lower priority may start now.
+			s.changed()
+		}
+	}
+	for {
+		s.mu.Lock()
+		rule := s.rule(job)
+		now := UTCNow()
+		open, end, next := rule.openAt(now)
+		if open && len(s.blockers(job, rule)) == 0 {
+			unblock()
+			r, ok := s.running[job]
+			if !ok {
+				r = &runningJob{
+					holders: make(map[uint64]context.CancelFunc),
+					since:   now,
+					weight:  rule.weight,
+				}
+				s.running[job] = r
+				s.weight += r.weight
+			}
+			var cancel context.CancelFunc
+			if end.IsZero() {
+				jctx, cancel = context.WithCancel(ctx)
+			} else {
+				jctx, cancel = context.WithDeadline(ctx, end)
+			}
+			id := s.nextID
+			s.nextID++
+			r.holders[id] = cancel
+			s.mu.Unlock()
+
+			var once sync.Once
+			return jctx, func() {
+				once.Do(func() {
+					cancel()
+					s.release(job, id)
+				})
+			}, nil
+		}
+		if open {
+			s.preempt(job, rule)
+			if !blocked {
+				blocked = true
+				s.blocked[job]++
+			}
+		} else {
+			unblock()
+		}
+		s.waiting[job]++
+		changedCh := s.changedCh
+		s.mu.Unlock()
+
+		// Wait for a job to finish, the schedules to change or the next
+		// window to open.
+		var timer *time.Timer
+		var wakeCh <-chan time.Time
+		if !open && !next.IsZero() {
+			timer = time.NewTimer(next.Sub(now))
+			wakeCh = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-changedCh:
+		case <-wakeCh:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+
+		s.mu.Lock()
+		s.waiting[job]--
+		if err != nil {
+			unblock()
+		}
+		s.mu.Unlock()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (s *jobScheduler) release(job string, id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.running[job]
+	if !ok {
+		return
+	}
+	delete(r.holders, id)
+	if len(r.holders) > 0 {
+		return
+	}
+	delete(s.running, job)
+	s.weight -= r.weight
+	s.changed()
+}
+
+// status returns the state of all background jobs of this node.
+func (s *jobScheduler) status(now time.Time) []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]JobStatus, 0, len(backgroundJobs))
+	for _, job := range backgroundJobs {
+		rule := s.rule(job)
+		st := JobStatus{
+			Job:         job,
+			Priority:    rule.priority,
+			Preemptions: s.preemptions[job],
+			Waiting:     s.waiting[job],
+		}
+		if r, ok := s.running[job]; ok {
+			st.Running, st.Since, st.Preempted = true, r.since, r.preempted
+		}
+		if s.blocked[job] > 0 {
+			st.BlockedBy = s.blockers(job, rule)
+		}
+		if open, end, next := rule.openAt(now); open {
+			st.WindowEnd = end
+		} else {
+			st.NextWindow = next
+		}
+		jobs = append(jobs, st)
+	}
+	return jobs
+}
+
+func getJobSchedulesConfigPath() string {
+	return pathJoin(minioConfigPrefix, jobSchedulesConfigFile)
+}
+
+// Init - loads the job schedules from the backend.
+func (s *jobScheduler) Init(ctx context.Context, objAPI ObjectLayer) error {
+	buf, err := readConfig(ctx, objAPI, getJobSchedulesConfigPath())
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return s.set(JobSchedulesConfig{Version: jobSchedulesFormatVersion1})
+		}
+		return err
+	}
+	var cfg JobSchedulesConfig
+	if err = json.Unmarshal(buf, &cfg); err != nil {
+		return err
+	}
+	if cfg.Version != jobSchedulesFormatVersion1 {
+		return fmt.Errorf("Unexpected job schedules config version: %d", cfg.Version)
+	}
+	return s.set(cfg)
+}
+
+// save persists cfg and tells all peers to reload the job schedules.
+func (s *jobScheduler) save(ctx context.Context, objAPI ObjectLayer, cfg JobSchedulesConfig) error {
+	cfg.Version = jobSchedulesFormatVersion1
+	if _, err := parseJobSchedules(cfg); err != nil {
+		return err
+	}
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, getJobSchedulesConfigPath(), buf); err != nil {
+		return err
+	}
+	if err = s.set(cfg); err != nil {
+		return err
+	}
+	for _, nerr := range globalNotificationSys.LoadJobSchedules(ctx) {
+		if nerr.Err != nil {
+			logger.GetReqInfo(ctx).SetTags("peerAddress", nerr.Host.String())
+			logger.LogIf(ctx, nerr.Err)
+		}
+	}
+	return nil
+}
+
+// getClusterJobStatus returns the background jobs of all nodes, sorted
+// by host.
+func getClusterJobStatus(ctx context.Context) []NodeJobStatus {
+	nodes := []NodeJobStatus{{
+		Host: globalLocalNodeName,
+		Jobs: globalJobScheduler.status(UTCNow()),
+	}}
+	nodes = append(nodes, globalNotificationSys.GetJobStatus(ctx)...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Host < nodes[j].Host
+	})
+	return nodes
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/bucket/lifecycle"
+)
+
+func TestParseJobSchedules(t *testing.T) {
+	testCases := []struct {
+		cfg JobSchedulesConfig
+		err bool
+	}{
+		{cfg: JobSchedulesConfig{}},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobScanner, Cron: []string{"0 22 * * *"}, Window: "8h"}}}},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: "rebalance"}}}, err: true},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobHeal}, {Job: jobHeal}}}, err: true},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobHeal, Weight: -1}}}, err: true},
+		{cfg: JobSchedulesConfig{MaxWeight: -1}, err: true},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobHeal, Window: "1h"}}}, err: true},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobHeal, Cron: []string{"* * * *"}}}}, err: true},
+		{cfg: JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobHeal, Cron: []string{"@daily"}, Window: "-1h"}}}, err: true},
+	}
+	for i, tc := range testCases {
+		_, err := parseJobSchedules(tc.cfg)
+		if (err != nil) != tc.err {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, tc.err, err)
+		}
+	}
+}
+
+func TestJobRuleOpenAt(t *testing.T) {
+	rules, err := parseJobSchedules(JobSchedulesConfig{Jobs: []JobSchedule{
+		{Job: jobScanner, Cron: []string{"0 22 * * *"}, Window: "4h"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := rules[jobScanner]
+
+	day := time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		now  time.Time
+		open bool
+		end  time.Time
+		next time.Time
+	}{
+		{now: day.Add(12 * time.Hour), next: day.Add(22 * time.Hour)},
+		{now: day.Add(23 * time.Hour), open: true, end: day.Add(26 * time.Hour), next: day.Add(46 * time.Hour)},
+		{now: day.Add(25*time.Hour + 59*time.Minute), open: true, end: day.Add(26 * time.Hour), next: day.Add(46 * time.Hour)},
+		{now: day.Add(26 * time.Hour), next: day.Add(46 * time.Hour)},
+	}
+	for i, tc := range testCases {
+		open, end, next := rule.openAt(tc.now)
+		if open != tc.open || (open && !end.Equal(tc.end)) || !next.Equal(tc.next) {
+			t.Errorf("Test %d: expected %v %v %v, got %v %v %v", i+1, tc.open, tc.end, tc.next, open, end, next)
+		}
+	}
+
+	if open, _, _ := (jobRule{weight: 1}).openAt(day); !open {
+		t.Error("a job without schedule must always be open")
+	}
+}
+
+func TestJobSchedulerAdmission(t *testing.T) {
+	s := newJobScheduler()
+	if err := s.set(JobSchedulesConfig{
+		MaxWeight: 3,
+		Jobs: []JobSchedule{
+			{Job: jobHeal, Group: "io"},
+			{Job: jobDedup, Group: "io"},
+			{Job: jobScanner, Weight: 2},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, releaseHeal, err := s.acquire(context.Background(), jobHeal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Workers of a running job share its run.
+	_, releaseHeal2, err := s.acquire(context.Background(), jobHeal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same group as heal.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	if _, _, err = s.acquire(ctx, jobDedup); err == nil {
+		t.Fatal("dedup must wait for heal of the same group")
+	}
+	cancel()
+
+	_, releaseScanner, err := s.acquire(context.Background(), jobScanner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Heal and scanner weigh 3 already.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	if _, _, err = s.acquire(ctx, jobILM); err == nil {
+		t.Fatal("ilm must wait for the max weight")
+	}
+	cancel()
+
+	admitted := make(chan error, 1)
+	go func() {
+		_, release, err := s.acquire(context.Background(), jobDedup)
+		if err == nil {
+			release()
+		}
+		admitted <- err
+	}()
+
+	releaseHeal()
+	releaseHeal() // Releasing twice is a no-op.
+	select {
+	case <-admitted:
+		t.Fatal("dedup must wait for all heal workers")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseHeal2()
+	select {
+	case err = <-admitted:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dedup was not admitted after heal finished")
+	}
+	releaseScanner()
+
+	for _, st := range s.status(UTCNow()) {
+		if st.Running || st.Waiting != 0 {
+			t.Errorf("job %s: expected idle, got %+v", st.Job, st)
+		}
+	}
+}
+
+func TestJobSchedulerPreemption(t *testing.T) {
+	s := newJobScheduler()
+	if err := s.set(JobSchedulesConfig{
+		MaxWeight: 2,
+		Jobs: []JobSchedule{
+			{Job: jobHeal, Weight: 2, Priority: 10},
+			{Job: jobILM, Priority: 5},
+			{Job: jobDedup, Group: "io", Priority: 20},
+			{Job: jobTrashPurge, Group: "io", Priority: 30},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	scannerCtx, releaseScanner, err := s.acquire(context.Background(), jobScanner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ilmCtx, releaseILM, err := s.acquire(context.Background(), jobILM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Heal needs the whole node, both scanner and ilm are of lower
+	// priority.
+	admitted := make(chan func(), 1)
+	go func() {
+		_, release, err := s.acquire(context.Background(), jobHeal)
+		if err != nil {
+			t.Error(err)
+		}
+		admitted <- release
+	}()
+	for _, jctx := range []context.Context{scannerCtx, ilmCtx} {
+		select {
+		case <-jctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("lower priority job was not preempted")
+		}
+	}
+
+	// A preempted job takes no new workers.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	if _, _, err = s.acquire(ctx, jobILM); err == nil {
+		t.Fatal("preempted job must not take new workers")
+	}
+	cancel()
+
+	var st JobStatus
+	for _, st = range s.status(UTCNow()) {
+		if st.Job == jobHeal {
+			break
+		}
+	}
+	if len(st.BlockedBy) != 2 || st.BlockedBy[0] != jobILM || st.BlockedBy[1] != jobScanner {
+		t.Errorf("expected heal to be blocked by ilm and scanner, got %v", st.BlockedBy)
+	}
+
+	releaseScanner()
+	releaseILM()
+	var releaseHeal func()
+	select {
+	case releaseHeal = <-admitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("heal was not admitted after the preempted jobs finished")
+	}
+
+	// Heal of higher priority is waited for, not preempted.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	if _, _, err = s.acquire(ctx, jobILM); err == nil {
+		t.Fatal("ilm must wait for heal")
+	}
+	cancel()
+
+	// Trash purge preempts dedup of the same group.
+	releaseHeal()
+	dedupCtx, releaseDedup, err := s.acquire(context.Background(), jobDedup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, release, err := s.acquire(context.Background(), jobTrashPurge)
+		if err != nil {
+			t.Error(err)
+		}
+		admitted <- release
+	}()
+	select {
+	case <-dedupCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("dedup was not preempted")
+	}
+	releaseDedup()
+	select {
+	case release := <-admitted:
+		release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("trash purge was not admitted")
+	}
+
+	for _, st := range s.status(UTCNow()) {
+		preemptions := uint64(0)
+		switch st.Job {
+		case jobScanner, jobILM, jobDedup:
+			preemptions = 1
+		}
+		if st.Preemptions != preemptions {
+			t.Errorf("job %s: expected %d preemptions, got %d", st.Job, preemptions, st.Preemptions)
+		}
+		if st.Running || st.Waiting != 0 || len(st.BlockedBy) != 0 {
+			t.Errorf("job %s: expected idle, got %+v", st.Job, st)
+		}
+	}
+}
+
+func TestScannerLifecycleWindow(t *testing.T) {
+	lc, err := lifecycle.ParseLifecycleConfig(bytes.NewReader([]byte(`<LifecycleConfiguration><Rule><ID>expire</ID><Filter></Filter><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(s *jobScheduler, es *expiryState) {
+		globalJobScheduler, globalExpiryState = s, es
+	}(globalJobScheduler, globalExpiryState)
+	globalExpiryState = newExpiryState()
+
+	// A window opening in twelve hours is closed now.
+	globalJobScheduler = newJobScheduler()
+	closed := fmt.Sprintf("0 %d * * *", (UTCNow().Hour()+12)%24)
+	if err = globalJobScheduler.set(JobSchedulesConfig{Jobs: []JobSchedule{{Job: jobILM, Cron: []string{closed}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	item := scannerItem{bucket: "bucket", objectName: "object", lifeCycle: lc}
+	oi := ObjectInfo{Bucket: "bucket", Name: "object", Size: 10, ModTime: UTCNow().AddDate(0, 0, -2), IsLatest: true, NumVersions: 1}
+	if applied, size := item.applyLifecycle(context.Background(), nil, oi); applied || size != 10 {
+		t.Fatalf("expected no action while the ILM window is closed, got %v %d", applied, size)
+	}
+	if n := globalExpiryState.PendingTasks(); n != 0 {
+		t.Fatalf("expected no expiry queued while the ILM window is closed, got %d", n)
+	}
+
+	if err = globalJobScheduler.set(JobSchedulesConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if applied, size := item.applyLifecycle(context.Background(), nil, oi); !applied || size != 0 {
+		t.Fatalf("expected the object to expire, got %v %d", applied, size)
+	}
+	if n := globalExpiryState.PendingTasks(); n != 1 {
+		t.Fatalf("expected an expiry queued, got %d", n)
+	}
+}
@@ -0,0 +1,854 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	bucketListIndexFile = "list-index.json"
+
+	// listIndexShardSize is the number of entries of the shards of a new
+	// index, shards are split in two beyond twice the size.
+	listIndexShardSize = 5000
+
+	// listIndexRebuildInterval is the age from which the scanner
+	// rebuilds an index, dropping entries missed by writes.
+	listIndexRebuildInterval = 24 * time.Hour
+
+	// listIndexBuildTimeout is the time a build is considered abandoned
+	// after, by a node restarted while building.
+	listIndexBuildTimeout = 24 * time.Hour
+
+	// listIndexUpdateInterval is the interval the writes journaled are
+	// applied to the shards at, in batches of listIndexUpdateBatch
+	// writes at most.
+	listIndexUpdateInterval = time.Second
+	listIndexUpdateBatch    = 10000
+
+	// listIndexMaxPending is the number of writes journaled, and not
+	// applied yet, a listing looks up. Beyond it the prefix is listed as
+	// usual.
+	listIndexMaxPending = 1000
+
+	listIndexStateBuilding = "building"
+	listIndexStateReady    = "ready"
+)
+
+var (
+	listIndexLockTimeout = newDynamicTimeout(30*time.Second, 5*time.Second)
+
+	errListIndexNested = errors.New("prefix has nested prefixes and cannot be indexed")
+)
+
+// BucketListIndex - the listing indexes of the prefixes of a bucket with
+// the most direct children. The entries of the indexes are kept in shards
+// under the bucket metadata, updated on writes and rebuilt by the scanner.
+type BucketListIndex struct {
+	Prefixes map[string]listIndexManifest `json:"prefixes"`
+}
+
+// listIndexManifest - the shards of the index of a prefix, ordered by the
+// first object name they hold, the first shard holding all names before.
+type listIndexManifest struct {
+	State   string              `json:"state"`
+	Started time.Time           `json:"started"`
+	Built   time.Time           `json:"built,omitempty"`
+	Shards  []listIndexShardRef `json:"shards,omitempty"`
+}
+
+type listIndexShardRef struct {
+	ID    string `json:"id"`
+	First string `json:"first"`
+}
+
+// listIndexEntry - an object listed, with its actual size and ETag.
+type listIndexEntry struct {
+	Name         string    `json:"n"`
+	ModTime      time.Time `json:"m"`
+	Size         int64     `json:"s"`
+	ETag         string    `json:"e"`
+	StorageClass string    `json:"c,omitempty"`
+}
+
+type listIndexShard struct {
+	Entries []listIndexEntry `json:"entries"`
+}
+
+// listIndexJournalEntry - a write of object journaled at path.
+type listIndexJournalEntry struct {
+	path   string
+	object string
+}
+
+func parseBucketListIndex(data []byte) (*BucketListIndex, error) {
+	idx := &BucketListIndex{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// match returns the indexed prefix holding object, if the object is
+// nested below a direct child of the prefix nested is true.
+func (idx *BucketListIndex) match(object string) (prefix string, nested, ok bool) {
+	if idx == nil {
+		return "", false, false
+	}
+	for p := range idx.Prefixes {
+		if strings.HasPrefix(object, p) && (!ok || len(p) > len(prefix)) {
+			prefix, ok = p, true
+		}
+	}
+	if ok {
+		nested = strings.Contains(object[len(prefix):], SlashSeparator)
+	}
+	return prefix, nested, ok
+}
+
+// get returns the manifest of the index of prefix.
+func (idx *BucketListIndex) get(prefix string) (listIndexManifest, bool) {
+	if idx == nil {
+		return listIndexManifest{}, false
+	}
+	m, ok := idx.Prefixes[prefix]
+	return m, ok
+}
+
+// findShard returns the position of the shard holding name.
+func (m listIndexManifest) findShard(name string) int {
+	i := sort.Search(len(m.Shards), func(i int) bool {
+		return m.Shards[i].First > name
+	}) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// search returns the position of the first entry after name.
+func (s *listIndexShard) search(name string) int {
+	return sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].Name > name
+	})
+}
+
+// set inserts or replaces the entry of e.Name.
+func (s *listIndexShard) set(e listIndexEntry) {
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].Name >= e.Name
+	})
+	if i < len(s.Entries) && s.Entries[i].Name == e.Name {
+		s.Entries[i] = e
+		return
+	}
+	s.Entries = append(s.Entries, listIndexEntry{})
+	copy(s.Entries[i+1:], s.Entries[i:])
+	s.Entries[i] = e
+}
+
+// remove removes the entry of name if present.
+func (s *listIndexShard) remove(name string) {
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].Name >= name
+	})
+	if i < len(s.Entries) && s.Entries[i].Name == name {
+		s.Entries = append(s.Entries[:i], s.Entries[i+1:]...)
+	}
+}
+
+func newListIndexEntry(oi ObjectInfo) listIndexEntry {
+	size, err := oi.GetActualSize()
+	if err != nil {
+		size = oi.Size
+	}
+	return listIndexEntry{
+		Name:         oi.Name,
+		ModTime:      oi.ModTime,
+		Size:         size,
+		ETag:         oi.GetActualETag(nil),
+		StorageClass: oi.StorageClass,
+	}
+}
+
+func listIndexLockPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "list-index.lock")
+}
+
+func listIndexDir(bucket, prefix string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "list-index", getSHA256Hash([]byte(prefix)))
+}
+
+func listIndexShardPath(bucket, prefix, id string) string {
+	return pathJoin(listIndexDir(bucket, prefix), "shards", id+".json")
+}
+
+func listIndexJournalDir(bucket, prefix string) string {
+	return pathJoin(listIndexDir(bucket, prefix), "journal")
+}
+
+func loadListIndexShard(ctx context.Context, objAPI ObjectLayer, bucket, prefix, id string) (listIndexShard, error) {
+	var s listIndexShard
+	data, err := readConfig(ctx, objAPI, listIndexShardPath(bucket, prefix, id))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func saveListIndexShard(ctx context.Context, objAPI ObjectLayer, bucket, prefix, id string, s listIndexShard) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, listIndexShardPath(bucket, prefix, id), data)
+}
+
+// saveBucketListIndex saves the manifest of prefix, removing it if nil,
+// the caller must hold the list index lock of bucket.
+func saveBucketListIndex(bucket, prefix string, m *listIndexManifest) error {
+	idx := BucketListIndex{Prefixes: make(map[string]listIndexManifest)}
+	if cur := globalBucketMetadataSys.GetListIndex(bucket); cur != nil {
+		for p, pm := range cur.Prefixes {
+			idx.Prefixes[p] = pm
+		}
+	}
+	if m != nil {
+		idx.Prefixes[prefix] = *m
+	} else {
+		delete(idx.Prefixes, prefix)
+	}
+	if len(idx.Prefixes) == 0 {
+		return globalBucketMetadataSys.Update(bucket, bucketListIndexFile, nil)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketListIndexFile, data)
+}
+
+// lookupListIndexEntry returns the entry of the latest version of
+// object, found is false if the object was deleted.
+func lookupListIndexEntry(ctx context.Context, objAPI ObjectLayer, bucket, object string) (e listIndexEntry, found bool, err error) {
+	oi, err := objAPI.GetObjectInfo(ctx, bucket, object, ObjectOptions{})
+	switch {
+	case err == nil && !oi.DeleteMarker:
+		return newListIndexEntry(oi), true, nil
+	case err == nil, isErrObjectNotFound(err), isErrVersionNotFound(err), isErrMethodNotAllowed(err):
+		return e, false, nil
+	default:
+		return e, false, err
+	}
+}
+
+// refreshListIndexEntries sets the entries of objects in the shard id to
+// the latest versions of the objects, removing those deleted. Returns
+// the number of entries of the shard.
+func refreshListIndexEntries(ctx context.Context, objAPI ObjectLayer, bucket, prefix, id string, objects []string) (int, error) {
+	// The objects are looked up under the shard lock, the last update of
+	// concurrent writes of an object sets its latest version.
+	lk := objAPI.NewNSLock(minioMetaBucket, listIndexShardPath(bucket, prefix, id))
+	lkctx, err := lk.GetLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return 0, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	s, err := loadListIndexShard(ctx, objAPI, bucket, prefix, id)
+	if err != nil {
+		return 0, err
+	}
+	for _, object := range objects {
+		e, found, err := lookupListIndexEntry(ctx, objAPI, bucket, object)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			s.set(e)
+		} else {
+			s.remove(object)
+		}
+	}
+	return len(s.Entries), saveListIndexShard(ctx, objAPI, bucket, prefix, id, s)
+}
+
+// readListIndexJournal returns up to max writes journaled for the index
+// of prefix, more is true if the journal holds more.
+func readListIndexJournal(ctx context.Context, objAPI ObjectLayer, bucket, prefix string, max int) (journal []listIndexJournalEntry, more bool, err error) {
+	dir := listIndexJournalDir(bucket, prefix) + SlashSeparator
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, dir, marker, "", maxObjectList)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, oi := range loi.Objects {
+			if len(journal) == max {
+				return journal, true, nil
+			}
+			data, err := readConfig(ctx, objAPI, oi.Name)
+			if err == errConfigNotFound {
+				// Applied meanwhile.
+				continue
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			journal = append(journal, listIndexJournalEntry{path: oi.Name, object: string(data)})
+		}
+		if !loi.IsTruncated {
+			return journal, false, nil
+		}
+		marker = loi.NextMarker
+	}
+}
+
+// applyListIndexJournal applies the writes journaled to the shards of
+// manifest m, rewriting each shard once, and removes them from the
+// journal. The writes of the shards failing to update stay journaled.
+// Returns the shards to split.
+func applyListIndexJournal(ctx context.Context, objAPI ObjectLayer, bucket, prefix string, m listIndexManifest, journal []listIndexJournalEntry) (splits []string, err error) {
+	if len(m.Shards) == 0 {
+		return nil, errConfigNotFound
+	}
+	byShard := make(map[string][]listIndexJournalEntry)
+	for _, e := range journal {
+		id := m.Shards[m.findShard(e.object)].ID
+		byShard[id] = append(byShard[id], e)
+	}
+	for id, entries := range byShard {
+		objects := make([]string, 0, len(entries))
+		seen := make(map[string]struct{}, len(entries))
+		for _, e := range entries {
+			if _, ok := seen[e.object]; !ok {
+				seen[e.object] = struct{}{}
+				objects = append(objects, e.object)
+			}
+		}
+		n, rerr := refreshListIndexEntries(ctx, objAPI, bucket, prefix, id, objects)
+		if rerr != nil {
+			err = rerr
+			continue
+		}
+		if n > 2*listIndexShardSize {
+			splits = append(splits, id)
+		}
+		for _, e := range entries {
+			if derr := deleteConfig(ctx, objAPI, e.path); derr != nil && derr != errConfigNotFound {
+				logger.LogIf(ctx, derr)
+			}
+		}
+	}
+	return splits, err
+}
+
+// updateListIndex records a write or a delete of object in the journal
+// of the index of the prefix holding it. The list index updater applies
+// the journal to the shards in batches, listings look up the writes not
+// applied yet. A nested object drops the index, such prefixes are not
+// indexed.
+func updateListIndex(ctx context.Context, objAPI ObjectLayer, bucket, object string) {
+	prefix, nested, ok := globalBucketMetadataSys.GetListIndex(bucket).match(object)
+	switch {
+	case !ok:
+	case nested:
+		logger.LogIf(ctx, removeListIndex(ctx, objAPI, bucket, prefix))
+	default:
+		logger.LogIf(ctx, saveConfig(ctx, objAPI, pathJoin(listIndexJournalDir(bucket, prefix), mustGetUUID()), []byte(object)))
+		globalListIndexUpdater.mark(bucket, prefix)
+	}
+}
+
+// updateListIndexShards applies a batch of the writes journaled to the
+// shards of the ready index of prefix. Returns true if more writes are
+// journaled.
+func updateListIndexShards(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) (bool, error) {
+	// Builds, splits and removals of indexes wait for the updates.
+	lk := objAPI.NewNSLock(minioMetaBucket, listIndexLockPath(bucket))
+	lkctx, err := lk.GetRLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return true, err
+	}
+	m, ok := globalBucketMetadataSys.GetListIndex(bucket).get(prefix)
+	if !ok || m.State != listIndexStateReady {
+		// Builds apply the journal themselves.
+		lk.RUnlock(lkctx.Cancel)
+		return false, nil
+	}
+	journal, more, err := readListIndexJournal(lkctx.Context(), objAPI, bucket, prefix, listIndexUpdateBatch)
+	var splits []string
+	if err == nil {
+		splits, err = applyListIndexJournal(lkctx.Context(), objAPI, bucket, prefix, m, journal)
+	}
+	lk.RUnlock(lkctx.Cancel)
+
+	for _, id := range splits {
+		logger.LogIf(ctx, splitListIndexShard(ctx, objAPI, bucket, prefix, id))
+	}
+	return more, err
+}
+
+// updateListIndexOnEvent updates the list index for the object events
+// changing the objects listed.
+func updateListIndexOnEvent(args eventArgs) {
+	switch args.EventName {
+	case event.ObjectCreatedCompleteMultipartUpload, event.ObjectCreatedCopy,
+		event.ObjectCreatedPost, event.ObjectCreatedPut,
+		event.ObjectRemovedDelete, event.ObjectRemovedDeleteMarkerCreated,
+		event.ObjectTransitionComplete:
+	default:
+		return
+	}
+	if objAPI := newObjectLayerFn(); objAPI != nil {
+		updateListIndex(GlobalContext, objAPI, args.BucketName, args.Object.Name)
+	}
+}
+
+// splitListIndexShard splits the shard id in two halves.
+func splitListIndexShard(ctx context.Context, objAPI ObjectLayer, bucket, prefix, id string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, listIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	m, ok := globalBucketMetadataSys.GetListIndex(bucket).get(prefix)
+	if !ok || m.State != listIndexStateReady {
+		return nil
+	}
+	pos := -1
+	for i, ref := range m.Shards {
+		if ref.ID == id {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		return nil
+	}
+	s, err := loadListIndexShard(ctx, objAPI, bucket, prefix, id)
+	if err != nil {
+		return err
+	}
+	if len(s.Entries) <= 2*listIndexShardSize {
+		return nil
+	}
+
+	half := len(s.Entries) / 2
+	left := listIndexShardRef{ID: mustGetUUID(), First: m.Shards[pos].First}
+	right := listIndexShardRef{ID: mustGetUUID(), First: s.Entries[half].Name}
+	if err = saveListIndexShard(ctx, objAPI, bucket, prefix, left.ID, listIndexShard{Entries: s.Entries[:half]}); err != nil {
+		return err
+	}
+	if err = saveListIndexShard(ctx, objAPI, bucket, prefix, right.ID, listIndexShard{Entries: s.Entries[half:]}); err != nil {
+		return err
+	}
+	shards := make([]listIndexShardRef, 0, len(m.Shards)+1)
+	shards = append(shards, m.Shards[:pos]...)
+	shards = append(shards, left, right)
+	shards = append(shards, m.Shards[pos+1:]...)
+	m.Shards = shards
+	if err = saveBucketListIndex(bucket, prefix, &m); err != nil {
+		return err
+	}
+	// Listings having loaded the manifest before fall back to the
+	// regular listing when the shard is gone.
+	return deleteConfig(ctx, objAPI, listIndexShardPath(bucket, prefix, id))
+}
+
+// removeListIndex removes the index of prefix with its shards.
+func removeListIndex(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, listIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	if _, ok := globalBucketMetadataSys.GetListIndex(bucket).get(prefix); !ok {
+		return nil
+	}
+	if err = saveBucketListIndex(bucket, prefix, nil); err != nil {
+		return err
+	}
+	if err = deleteConfig(ctx, objAPI, listIndexDir(bucket, prefix)); err != nil && err != errConfigNotFound {
+		return err
+	}
+	return nil
+}
+
+// buildListIndex builds the index of the direct children of prefix,
+// replacing the current one. Writes during the build are journaled
+// and applied to the new index before it is used.
+func buildListIndex(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) (err error) {
+	lk := objAPI.NewNSLock(minioMetaBucket, listIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	prev, exists := globalBucketMetadataSys.GetListIndex(bucket).get(prefix)
+	if exists && !listIndexDue(prev) {
+		lk.Unlock(lkctx.Cancel)
+		return nil
+	}
+	m := listIndexManifest{State: listIndexStateBuilding, Started: UTCNow()}
+	err = saveBucketListIndex(bucket, prefix, &m)
+	lk.Unlock(lkctx.Cancel)
+	if err != nil {
+		return err
+	}
+	// A failed build removes the index, the journal and all shards.
+	defer func() {
+		if err != nil {
+			logger.LogIf(ctx, removeListIndex(GlobalContext, objAPI, bucket, prefix))
+		}
+	}()
+
+	var (
+		cur    listIndexShard
+		shards []listIndexShardRef
+	)
+	flush := func() error {
+		ref := listIndexShardRef{ID: mustGetUUID()}
+		if len(shards) > 0 {
+			ref.First = cur.Entries[0].Name
+		}
+		if err := saveListIndexShard(ctx, objAPI, bucket, prefix, ref.ID, cur); err != nil {
+			return err
+		}
+		shards = append(shards, ref)
+		cur = listIndexShard{}
+		return nil
+	}
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, bucket, prefix, marker, SlashSeparator, maxObjectList)
+		if err == nil && len(loi.Prefixes) > 0 {
+			err = errListIndexNested
+		}
+		if err != nil {
+			return err
+		}
+		for _, oi := range loi.Objects {
+			cur.Entries = append(cur.Entries, newListIndexEntry(oi))
+			if len(cur.Entries) == listIndexShardSize {
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if !loi.IsTruncated {
+			break
+		}
+		marker = loi.NextMarker
+	}
+	if len(cur.Entries) > 0 || len(shards) == 0 {
+		if err = flush(); err != nil {
+			return err
+		}
+	}
+
+	lkctx, err = lk.GetLock(ctx, listIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lk.Unlock(lkctx.Cancel)
+	lctx := lkctx.Context()
+
+	// The shards are split by the next updates of the index.
+	m.Shards = shards
+	for more := true; more; {
+		var journal []listIndexJournalEntry
+		journal, more, err = readListIndexJournal(lctx, objAPI, bucket, prefix, listIndexUpdateBatch)
+		if err != nil {
+			return err
+		}
+		if _, err = applyListIndexJournal(lctx, objAPI, bucket, prefix, m, journal); err != nil {
+			return err
+		}
+	}
+
+	m.State, m.Built = listIndexStateReady, UTCNow()
+	if err = saveBucketListIndex(bucket, prefix, &m); err != nil {
+		return err
+	}
+	for _, ref := range prev.Shards {
+		logger.LogIf(ctx, deleteConfig(lctx, objAPI, listIndexShardPath(bucket, prefix, ref.ID)))
+	}
+	// Writes journaled since are applied by the updater.
+	globalListIndexUpdater.mark(bucket, prefix)
+	return nil
+}
+
+// listIndexDue returns whether the index of manifest m is to be rebuilt.
+func listIndexDue(m listIndexManifest) bool {
+	if m.State == listIndexStateBuilding {
+		return time.Since(m.Started) > listIndexBuildTimeout
+	}
+	return time.Since(m.Built) > listIndexRebuildInterval
+}
+
+// listObjectsFromIndex serves a listing of the direct children of an
+// indexed prefix, returns false if the listing is to be served by the
+// regular listing.
+func listObjectsFromIndex(ctx context.Context, objAPI ObjectLayer, bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, bool) {
+	var loi ListObjectsInfo
+	if maxKeys <= 0 || (delimiter != "" && delimiter != SlashSeparator) || globalAPIConfig.getListIndexThreshold() == 0 {
+		return loi, false
+	}
+	m, ok := globalBucketMetadataSys.GetListIndex(bucket).get(prefix)
+	if !ok || m.State != listIndexStateReady || len(m.Shards) == 0 {
+		return loi, false
+	}
+	opts := listPathOptions{Marker: marker}
+	opts.parseMarker()
+	marker = opts.Marker
+
+	// The writes journaled are not applied to the shards yet, the index
+	// is not used while too many are pending.
+	journal, more, err := readListIndexJournal(ctx, objAPI, bucket, prefix, listIndexMaxPending)
+	if err != nil || more {
+		return loi, false
+	}
+	pending := make([]string, 0, len(journal))
+	for _, e := range journal {
+		pending = append(pending, e.object)
+	}
+
+	// Indexed prefixes have no nested prefixes, the direct children are
+	// listed with and without a delimiter.
+	entries, err := listIndexPage(m, marker, maxKeys, pending,
+		func(id string) (listIndexShard, error) {
+			return loadListIndexShard(ctx, objAPI, bucket, prefix, id)
+		},
+		func(object string) (listIndexEntry, bool, error) {
+			return lookupListIndexEntry(ctx, objAPI, bucket, object)
+		})
+	if err != nil {
+		return loi, false
+	}
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
+		loi.IsTruncated = true
+	}
+	for _, e := range entries {
+		loi.Objects = append(loi.Objects, ObjectInfo{
+			Bucket:       bucket,
+			Name:         e.Name,
+			ModTime:      e.ModTime,
+			Size:         e.Size,
+			ETag:         e.ETag,
+			StorageClass: e.StorageClass,
+			IsLatest:     true,
+		})
+	}
+	if loi.IsTruncated {
+		loi.NextMarker = entries[len(entries)-1].Name
+	}
+	return loi, true
+}
+
+// listIndexPage returns the entries of manifest m after marker, more
+// than maxKeys if the listing is truncated, with the latest versions of
+// the pending objects written since their shards were updated.
+func listIndexPage(m listIndexManifest, marker string, maxKeys int, pending []string,
+	load func(id string) (listIndexShard, error), lookup func(object string) (listIndexEntry, bool, error)) ([]listIndexEntry, error) {
+	// Enough entries are read for maxKeys entries to remain once all
+	// pending objects are found deleted.
+	var page listIndexShard
+	i := m.findShard(marker)
+	for ; i < len(m.Shards) && len(page.Entries) <= maxKeys+len(pending); i++ {
+		s, err := load(m.Shards[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, s.Entries[s.search(marker):]...)
+	}
+	var last string
+	if i < len(m.Shards) {
+		last = page.Entries[len(page.Entries)-1].Name
+	}
+	for _, object := range pending {
+		if object <= marker || (last != "" && object > last) {
+			continue
+		}
+		e, found, err := lookup(object)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			page.set(e)
+		} else {
+			page.remove(object)
+		}
+	}
+	return page.Entries, nil
+}
+
+// totalErasureSets returns the number of erasure sets of all pools.
+func totalErasureSets() int {
+	n := 0
+	for _, ep := range globalEndpoints {
+		n += ep.SetCount
+	}
+	return n
+}
+
+// checkListIndexCandidate queues a build of the index of prefix when
+// the direct children of the folder scanned on a drive of a set reach
+// the threshold across all sets, or a rebuild when it is due. Indexes
+// are removed when the index is disabled.
+func checkListIndexCandidate(bucket, prefix string, children int) {
+	threshold := globalAPIConfig.getListIndexThreshold()
+	m, exists := globalBucketMetadataSys.GetListIndex(bucket).get(prefix)
+	switch {
+	case threshold == 0:
+		if exists {
+			globalListIndexBuilder.queue(listIndexJob{bucket: bucket, prefix: prefix, remove: true})
+		}
+	case exists && !listIndexDue(m):
+	case exists || int64(children)*int64(totalErasureSets()) >= threshold:
+		globalListIndexBuilder.queue(listIndexJob{bucket: bucket, prefix: prefix})
+	}
+}
+
+// checkListIndexFolder checks the folder of the scanner root holding
+// children directories for a list index.
+func checkListIndexFolder(root, folder string, children int) {
+	bucket, prefix := path2BucketObjectWithBasePath(root, folder)
+	if bucket == "" || isReservedOrInvalidBucket(bucket, false) {
+		return
+	}
+	if prefix != "" {
+		prefix += SlashSeparator
+	}
+	checkListIndexCandidate(bucket, prefix, children)
+}
+
+type listIndexJob struct {
+	bucket, prefix string
+	remove         bool
+}
+
+// listIndexBuilder builds the indexes queued by the scanner one at a time.
+type listIndexBuilder struct {
+	mu     sync.Mutex
+	queued map[listIndexJob]struct{}
+	jobs   chan listIndexJob
+}
+
+var globalListIndexBuilder = &listIndexBuilder{
+	queued: make(map[listIndexJob]struct{}),
+	jobs:   make(chan listIndexJob, 100),
+}
+
+func (b *listIndexBuilder) queue(job listIndexJob) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.queued[job]; ok {
+		return
+	}
+	select {
+	case b.jobs <- job:
+		b.queued[job] = struct{}{}
+	default:
+	}
+}
+
+func (b *listIndexBuilder) run(ctx context.Context, objAPI ObjectLayer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-b.jobs:
+			var err error
+			if job.remove {
+				err = removeListIndex(ctx, objAPI, job.bucket, job.prefix)
+			} else {
+				err = buildListIndex(ctx, objAPI, job.bucket, job.prefix)
+			}
+			if err != nil && err != errListIndexNested {
+				logger.LogIf(ctx, err)
+			}
+			b.mu.Lock()
+			delete(b.queued, job)
+			b.mu.Unlock()
+		}
+	}
+}
+
+// listIndexUpdater applies the journals of the indexes written to on
+// this node in batches.
+type listIndexUpdater struct {
+	mu    sync.Mutex
+	dirty map[listIndexJob]struct{}
+}
+
+var globalListIndexUpdater = &listIndexUpdater{dirty: make(map[listIndexJob]struct{})}
+
+// mark queues an update of the index of prefix.
+func (u *listIndexUpdater) mark(bucket, prefix string) {
+	u.mu.Lock()
+	u.dirty[listIndexJob{bucket: bucket, prefix: prefix}] = struct{}{}
+	u.mu.Unlock()
+}
+
+func (u *listIndexUpdater) run(ctx context.Context, objAPI ObjectLayer) {
+	t := time.NewTicker(listIndexUpdateInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		u.mu.Lock()
+		dirty := u.dirty
+		u.dirty = make(map[listIndexJob]struct{})
+		u.mu.Unlock()
+
+		for job := range dirty {
+			more, err := updateListIndexShards(ctx, objAPI, job.bucket, job.prefix)
+			logger.LogIf(ctx, err)
+			if more || err != nil {
+				u.mark(job.bucket, job.prefix)
+			}
+		}
+	}
+}
+
+func initListIndex(ctx context.Context, objAPI ObjectLayer) {
+	go globalListIndexBuilder.run(ctx, objAPI)
+	go globalListIndexUpdater.run(ctx, objAPI)
+}
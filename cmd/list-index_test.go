@@ -0,0 +1,141 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBucketListIndexMatch(t *testing.T) {
+	idx := &BucketListIndex{Prefixes: map[string]listIndexManifest{
+		"logs/":     {},
+		"logs/app/": {},
+	}}
+	testCases := []struct {
+		object string
+		prefix string
+		nested bool
+		ok     bool
+	}{
+		{"logs/1.log", "logs/", false, true},
+		{"logs/app/1.log", "logs/app/", false, true},
+		{"logs/web/1.log", "logs/", true, true},
+		{"photos/1.jpg", "", false, false},
+	}
+	for i, testCase := range testCases {
+		prefix, nested, ok := idx.match(testCase.object)
+		if prefix != testCase.prefix || nested != testCase.nested || ok != testCase.ok {
+			t.Errorf("Test %d: expected (%q, %t, %t), got (%q, %t, %t)", i+1,
+				testCase.prefix, testCase.nested, testCase.ok, prefix, nested, ok)
+		}
+	}
+	var none *BucketListIndex
+	if _, _, ok := none.match("logs/1.log"); ok {
+		t.Error("expected no match without an index")
+	}
+}
+
+func TestListIndexShards(t *testing.T) {
+	m := listIndexManifest{Shards: []listIndexShardRef{{ID: "a"}, {ID: "b", First: "k"}, {ID: "c", First: "t"}}}
+	for name, expected := range map[string]int{"": 0, "a": 0, "k": 1, "m": 1, "t": 2, "z": 2} {
+		if i := m.findShard(name); i != expected {
+			t.Errorf("expected shard %d for %q, got %d", expected, name, i)
+		}
+	}
+
+	var s listIndexShard
+	for _, name := range []string{"c", "a", "b", "a"} {
+		s.set(listIndexEntry{Name: name})
+	}
+	s.remove("b")
+	s.remove("x")
+	if len(s.Entries) != 2 || s.Entries[0].Name != "a" || s.Entries[1].Name != "c" {
+		t.Fatalf("unexpected entries %v", s.Entries)
+	}
+	if i := s.search("a"); i != 1 {
+		t.Errorf("expected entries after a at 1, got %d", i)
+	}
+}
+
+func TestListIndexDue(t *testing.T) {
+	testCases := []struct {
+		m   listIndexManifest
+		due bool
+	}{
+		{listIndexManifest{State: listIndexStateReady, Built: UTCNow()}, false},
+		{listIndexManifest{State: listIndexStateReady, Built: UTCNow().Add(-2 * listIndexRebuildInterval)}, true},
+		{listIndexManifest{State: listIndexStateBuilding, Started: UTCNow()}, false},
+		{listIndexManifest{State: listIndexStateBuilding, Started: UTCNow().Add(-listIndexBuildTimeout - time.Hour)}, true},
+	}
+	for i, testCase := range testCases {
+		if due := listIndexDue(testCase.m); due != testCase.due {
+			t.Errorf("Test %d: expected %t, got %t", i+1, testCase.due, due)
+		}
+	}
+}
+
+func TestListIndexPage(t *testing.T) {
+	shards := map[string]listIndexShard{
+		"a": {Entries: []listIndexEntry{{Name: "p/1"}, {Name: "p/3"}}},
+		"b": {Entries: []listIndexEntry{{Name: "p/5"}, {Name: "p/7"}}},
+		"c": {Entries: []listIndexEntry{{Name: "p/9"}}},
+	}
+	m := listIndexManifest{Shards: []listIndexShardRef{{ID: "a"}, {ID: "b", First: "p/5"}, {ID: "c", First: "p/9"}}}
+	load := func(id string) (listIndexShard, error) {
+		s := shards[id]
+		return listIndexShard{Entries: append([]listIndexEntry{}, s.Entries...)}, nil
+	}
+	// p/2 was written and p/3 and p/5 deleted since the shards were
+	// updated, p/8 is past the entries read.
+	latest := map[string]int64{"p/2": 2, "p/8": 8}
+	var looked []string
+	lookup := func(object string) (listIndexEntry, bool, error) {
+		looked = append(looked, object)
+		size, ok := latest[object]
+		return listIndexEntry{Name: object, Size: size}, ok, nil
+	}
+
+	testCases := []struct {
+		marker  string
+		maxKeys int
+		pending []string
+		names   []string
+		looked  []string
+	}{
+		{"", 2, nil, []string{"p/1", "p/3", "p/5", "p/7"}, nil},
+		{"", 1, []string{"p/2", "p/3", "p/5", "p/8"}, []string{"p/1", "p/2", "p/7", "p/8", "p/9"}, []string{"p/2", "p/3", "p/5", "p/8"}},
+		{"", 1, []string{"p/3", "p/8"}, []string{"p/1", "p/5", "p/7"}, []string{"p/3"}},
+		{"p/5", 10, []string{"p/2", "p/8"}, []string{"p/7", "p/8", "p/9"}, []string{"p/8"}},
+	}
+	for i, testCase := range testCases {
+		looked = nil
+		entries, err := listIndexPage(m, testCase.marker, testCase.maxKeys, testCase.pending, load, lookup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		if !reflect.DeepEqual(names, testCase.names) || !reflect.DeepEqual(looked, testCase.looked) {
+			t.Errorf("Test %d: expected %v looking up %v, got %v looking up %v", i+1, testCase.names, testCase.looked, names, looked)
+		}
+	}
+}
@@ -213,19 +213,22 @@ func lockMaintenance(ctx context.Context) {
 	// no need to start the lock maintenance
 	// if ObjectAPI is not initialized.
 
-	var objAPI ObjectLayer
-
-	for {
-		objAPI = newObjectLayerFn()
-		if objAPI == nil {
-			time.Sleep(time.Second)
-			continue
+	// An arbiter has no object API, it expires the locks right away.
+	if !globalIsArbiter {
+		var objAPI ObjectLayer
+
+		for {
+			objAPI = newObjectLayerFn()
+			if objAPI == nil {
+				time.Sleep(time.Second)
+				continue
+			}
+			break
 		}
-		break
-	}
 
-	if _, ok := objAPI.(*erasureServerPools); !ok {
-		return
+		if _, ok := objAPI.(*erasureServerPools); !ok {
+			return
+		}
 	}
 
 	// Initialize a new ticker with 1 minute between each ticks.
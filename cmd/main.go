@@ -125,6 +125,8 @@ func newApp(name string) *cli.App {
 	// Register all commands.
 	registerCommand(serverCmd)
 	registerCommand(gatewayCmd)
+	registerCommand(arbiterCmd)
+	registerCommand(inspectMetaCmd)
 
 	// Set up app.
 	cli.HelpFlag = cli.BoolFlag{
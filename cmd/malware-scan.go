@@ -0,0 +1,386 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/config/malware"
+	"github.com/minio/minio/internal/crypto"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	malwareScanWorkers = 4
+
+	// Object tags recording the scan verdict.
+	scanVerdictTag   = "minio-scan-verdict"
+	scanSignatureTag = "minio-scan-signature"
+
+	// scanVerdictKey is the internal metadata recording the verdict,
+	// the tags only informing of it as users may change them.
+	scanVerdictKey = ReservedMetadataPrefix + "scan-verdict"
+
+	// scanVerdictRetries is how often recording a verdict is retried
+	// when the tags of the object change meanwhile.
+	scanVerdictRetries = 3
+)
+
+// Scan verdicts, the first three and released are recorded in
+// scanVerdictTag and scanVerdictKey.
+const (
+	scanVerdictClean       = "clean"
+	scanVerdictInfected    = "infected"
+	scanVerdictQuarantined = "quarantined"
+	scanVerdictReleased    = "released"
+	scanVerdictFailed      = "failed"
+	scanVerdictSkipped     = "skipped"
+)
+
+var (
+	errObjectQuarantined = errors.New("Object failed malware scanning and is quarantined")
+	errMalwareDetected   = errors.New("Uploaded object failed malware scanning")
+)
+
+// malwareScanResult is the response of the scan service.
+type malwareScanResult struct {
+	Infected  bool   `json:"infected"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// malwareScanTask is an uploaded object version to scan.
+type malwareScanTask struct {
+	bucket, object, versionID, etag string
+}
+
+// malwareScanStats counts the scan verdicts of this node.
+type malwareScanStats struct {
+	Clean    uint64
+	Infected uint64
+	Failed   uint64
+	Skipped  uint64
+	Dropped  uint64
+}
+
+// malwareScanner posts uploaded objects to a ClamAV compatible scan
+// service, tagging infected objects or quarantining them.
+type malwareScanner struct {
+	mu     sync.RWMutex
+	cfg    malware.Config
+	client *http.Client
+	queue  chan malwareScanTask
+	cancel context.CancelFunc
+
+	stats malwareScanStats
+
+	latencyMu sync.Mutex
+	latency   apiLatencyHistogram
+}
+
+var globalMalwareScanner = &malwareScanner{
+	latency: apiLatencyHistogram{counts: make([]uint64, len(bucketLatencyBounds)+1)},
+}
+
+// Update applies a new malware scan configuration, restarting the
+// background workers if needed.
+func (s *malwareScanner) Update(cfg malware.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.cfg = cfg
+	s.queue = nil
+	if !cfg.Enabled {
+		s.client = nil
+		return
+	}
+	s.client = &http.Client{Transport: NewRemoteTargetHTTPTransport()}
+	if cfg.Mode != malware.ModeAsync {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	s.cancel = cancel
+	s.queue = make(chan malwareScanTask, cfg.QueueSize)
+	for i := 0; i < malwareScanWorkers; i++ {
+		go s.worker(ctx, s.queue)
+	}
+}
+
+func (s *malwareScanner) config() (malware.Config, *http.Client, chan malwareScanTask) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg, s.client, s.queue
+}
+
+// scanUpload scans the uploaded object of oi if its bucket is scanned,
+// in the background or before returning by the scan mode. In sync mode
+// errMalwareDetected is returned for infected objects, failed scans do
+// not fail the upload.
+func (s *malwareScanner) scanUpload(ctx context.Context, objAPI ObjectLayer, oi ObjectInfo) error {
+	cfg, client, queue := s.config()
+	if !cfg.Scans(oi.Bucket) || oi.DeleteMarker {
+		return nil
+	}
+	task := malwareScanTask{bucket: oi.Bucket, object: oi.Name, versionID: oi.VersionID, etag: oi.ETag}
+	if cfg.Mode == malware.ModeAsync {
+		select {
+		case queue <- task:
+		default:
+			atomic.AddUint64(&s.stats.Dropped, 1)
+			logger.LogOnceIf(ctx, errors.New("malware scan: queue full, uploads are not scanned"), "malware-scan-queue-full")
+		}
+		return nil
+	}
+	if s.scan(ctx, objAPI, cfg, client, task) == scanVerdictInfected {
+		return errMalwareDetected
+	}
+	return nil
+}
+
+func (s *malwareScanner) worker(ctx context.Context, queue <-chan malwareScanTask) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-queue:
+			if objAPI := newObjectLayerFn(); objAPI != nil {
+				cfg, client, _ := s.config()
+				s.scan(ctx, objAPI, cfg, client, task)
+			}
+		}
+	}
+}
+
+// scan scans the object version of task, applies the action of cfg if
+// it is infected and returns the verdict, infected also when it was
+// quarantined.
+func (s *malwareScanner) scan(ctx context.Context, objAPI ObjectLayer, cfg malware.Config, client *http.Client, task malwareScanTask) string {
+	if client == nil {
+		return scanVerdictSkipped
+	}
+	start := time.Now()
+	verdict, signature, _, err := s.post(ctx, objAPI, cfg, client, task)
+	switch verdict {
+	case scanVerdictSkipped:
+		atomic.AddUint64(&s.stats.Skipped, 1)
+		return verdict
+	case scanVerdictFailed:
+		atomic.AddUint64(&s.stats.Failed, 1)
+		logger.LogOnceIf(ctx, fmt.Errorf("malware scan: unable to scan %s/%s: %w", task.bucket, task.object, err), "malware-scan-failed")
+		return verdict
+	}
+	s.observe(time.Since(start))
+
+	tagVerdict := verdict
+	if verdict == scanVerdictInfected {
+		atomic.AddUint64(&s.stats.Infected, 1)
+		if cfg.Action == malware.ActionQuarantine {
+			tagVerdict = scanVerdictQuarantined
+		}
+		reqInfo := (&logger.ReqInfo{}).AppendTags("bucket", task.bucket)
+		reqInfo.AppendTags("object", task.object)
+		logger.LogIf(logger.SetReqInfo(ctx, reqInfo),
+			fmt.Errorf("malware scan: %s/%s (version %q) is infected with %q, %s", task.bucket, task.object, task.versionID, signature, tagVerdict))
+	} else {
+		atomic.AddUint64(&s.stats.Clean, 1)
+	}
+	logger.LogIf(ctx, setScanVerdict(ctx, objAPI, task.bucket, task.object, task.versionID, task.etag, tagVerdict, signature))
+	return verdict
+}
+
+// post sends the object version of task to the scan service. Objects
+// encrypted with client keys, larger than the maximum size or replaced
+// meanwhile are skipped.
+func (s *malwareScanner) post(ctx context.Context, objAPI ObjectLayer, cfg malware.Config, client *http.Client, task malwareScanTask) (verdict, signature string, oi ObjectInfo, err error) {
+	opts := ObjectOptions{VersionID: task.versionID}
+	oi, err = objAPI.GetObjectInfo(ctx, task.bucket, task.object, opts)
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrVersionNotFound(err) {
+			return scanVerdictSkipped, "", oi, nil
+		}
+		return scanVerdictFailed, "", oi, err
+	}
+	if oi.ETag != task.etag || crypto.SSEC.IsEncrypted(oi.UserDefined) {
+		return scanVerdictSkipped, "", oi, nil
+	}
+	size, err := oi.GetActualSize()
+	if err != nil {
+		return scanVerdictFailed, "", oi, err
+	}
+	if uint64(size) > cfg.MaxSize {
+		return scanVerdictSkipped, "", oi, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	gr, err := objAPI.GetObjectNInfo(ctx, task.bucket, task.object, nil, http.Header{}, readLock, opts)
+	if err != nil {
+		return scanVerdictFailed, "", oi, err
+	}
+	defer gr.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint.String(), gr)
+	if err != nil {
+		return scanVerdictFailed, "", oi, err
+	}
+	req.ContentLength = size
+	req.Header.Set(xhttp.ContentType, "application/octet-stream")
+	req.Header.Set(xhttp.MinIOScanBucket, task.bucket)
+	req.Header.Set(xhttp.MinIOScanObject, task.object)
+	if cfg.AuthToken != "" {
+		req.Header.Set(xhttp.Authorization, "Bearer "+cfg.AuthToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return scanVerdictFailed, "", oi, err
+	}
+	defer xhttp.DrainBody(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return scanVerdictFailed, "", oi, fmt.Errorf("%s returned '%s'", cfg.Endpoint, resp.Status)
+	}
+	var result malwareScanResult
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return scanVerdictFailed, "", oi, err
+	}
+	if result.Infected {
+		return scanVerdictInfected, result.Signature, oi, nil
+	}
+	return scanVerdictClean, "", oi, nil
+}
+
+func (s *malwareScanner) observe(d time.Duration) {
+	s.latencyMu.Lock()
+	defer s.latencyMu.Unlock()
+	s.latency.observe(d)
+}
+
+// Stats returns the scan verdict statistics and the scan latency
+// histogram.
+func (s *malwareScanner) Stats() (malwareScanStats, apiLatencyHistogram) {
+	stats := malwareScanStats{
+		Clean:    atomic.LoadUint64(&s.stats.Clean),
+		Infected: atomic.LoadUint64(&s.stats.Infected),
+		Failed:   atomic.LoadUint64(&s.stats.Failed),
+		Skipped:  atomic.LoadUint64(&s.stats.Skipped),
+		Dropped:  atomic.LoadUint64(&s.stats.Dropped),
+	}
+	s.latencyMu.Lock()
+	defer s.latencyMu.Unlock()
+	latency := apiLatencyHistogram{
+		counts: append([]uint64(nil), s.latency.counts...),
+		sum:    s.latency.sum,
+	}
+	return stats, latency
+}
+
+// scanTagValue replaces the characters not allowed in tag values.
+func scanTagValue(v string) string {
+	v = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune(" +-=._:/@", r):
+			return r
+		}
+		return '_'
+	}, v)
+	if len(v) > 256 {
+		v = v[:256]
+	}
+	return v
+}
+
+// scanVerdictTags returns the tags userTags with the verdict and
+// signature of a scan.
+func scanVerdictTags(userTags, verdict, signature string) (string, error) {
+	m := make(map[string]string)
+	if userTags != "" {
+		t, err := tags.ParseObjectTags(userTags)
+		if err != nil {
+			return "", err
+		}
+		m = t.ToMap()
+	}
+	m[scanVerdictTag] = verdict
+	delete(m, scanSignatureTag)
+	if signature = scanTagValue(signature); signature != "" {
+		m[scanSignatureTag] = signature
+	}
+	t, err := tags.MapToObjectTags(m)
+	if err != nil {
+		return "", err
+	}
+	return t.String(), nil
+}
+
+// setScanVerdict records the verdict of a scan of an object version in
+// its internal metadata and in its tags, keeping its other tags. The
+// version is left alone if its data no longer has the etag scanned, an
+// empty etag matching any, the update being retried if only its tags
+// changed meanwhile.
+func setScanVerdict(ctx context.Context, objAPI ObjectLayer, bucket, object, versionID, etag, verdict, signature string) error {
+	for i := 0; ; i++ {
+		oi, err := objAPI.GetObjectInfo(ctx, bucket, object, ObjectOptions{VersionID: versionID})
+		if err != nil {
+			if isErrObjectNotFound(err) || isErrVersionNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if etag != "" && oi.ETag != etag {
+			return nil
+		}
+		t, err := scanVerdictTags(oi.UserTags, verdict, signature)
+		if err != nil {
+			return fmt.Errorf("malware scan: unable to tag %s/%s: %w", bucket, object, err)
+		}
+		_, err = objAPI.PutObjectTags(ctx, bucket, object, t, ObjectOptions{
+			VersionID:   versionID,
+			UserDefined: map[string]string{scanVerdictKey: verdict},
+			CheckPrecondFn: func(cur ObjectInfo) bool {
+				return cur.ETag != oi.ETag || cur.UserTags != oi.UserTags
+			},
+		})
+		if _, ok := err.(PreConditionFailed); ok && i < scanVerdictRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// isQuarantined returns whether oi is quarantined by malware scanning,
+// reading it is denied until it is released.
+func isQuarantined(oi ObjectInfo) bool {
+	return oi.UserDefined[scanVerdictKey] == scanVerdictQuarantined
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestScanTagValue(t *testing.T) {
+	testCases := []struct {
+		signature, expected string
+	}{
+		{"Win.Test.EICAR_HDB-1", "Win.Test.EICAR_HDB-1"},
+		{"Heuristics.Phishing.Email(SpoofedDomain)", "Heuristics.Phishing.Email_SpoofedDomain_"},
+		{"", ""},
+	}
+	for i, testCase := range testCases {
+		if v := scanTagValue(testCase.signature); v != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, v)
+		}
+	}
+}
+
+func TestIsQuarantined(t *testing.T) {
+	testCases := []struct {
+		oi          ObjectInfo
+		quarantined bool
+	}{
+		{ObjectInfo{}, false},
+		{ObjectInfo{UserDefined: map[string]string{scanVerdictKey: scanVerdictInfected}}, false},
+		{ObjectInfo{UserDefined: map[string]string{scanVerdictKey: scanVerdictReleased}}, false},
+		{ObjectInfo{UserDefined: map[string]string{scanVerdictKey: scanVerdictQuarantined}}, true},
+		// Tags set by users do not quarantine, nor release.
+		{ObjectInfo{UserTags: "minio-scan-verdict=quarantined"}, false},
+		{ObjectInfo{UserTags: "a=b", UserDefined: map[string]string{scanVerdictKey: scanVerdictQuarantined}}, true},
+	}
+	for i, testCase := range testCases {
+		if isQuarantined(testCase.oi) != testCase.quarantined {
+			t.Errorf("Test %d: expected quarantined %t for %+v", i+1, testCase.quarantined, testCase.oi)
+		}
+	}
+}
+
+func TestSetScanVerdict(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket, object := "inbox", "upload.bin"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("infected")
+	oi, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""),
+		ObjectOptions{UserDefined: map[string]string{xhttp.AmzObjectTagging: "project=x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A verdict of other data is not recorded.
+	if err = setScanVerdict(ctx, objLayer, bucket, object, "", "other", scanVerdictQuarantined, "Eicar"); err != nil {
+		t.Fatal(err)
+	}
+	if oi, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil || isQuarantined(oi) {
+		t.Fatalf("expected the object not to be quarantined, got %v", err)
+	}
+
+	if err = setScanVerdict(ctx, objLayer, bucket, object, "", oi.ETag, scanVerdictQuarantined, "Eicar"); err != nil {
+		t.Fatal(err)
+	}
+	if oi, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil || !isQuarantined(oi) {
+		t.Fatalf("expected the object to be quarantined, got %v", err)
+	}
+	if want := "minio-scan-signature=Eicar&minio-scan-verdict=quarantined&project=x"; oi.UserTags != want {
+		t.Fatalf("expected tags %q, got %q", want, oi.UserTags)
+	}
+
+	// Removing the verdict tag does not release the object.
+	if _, err = objLayer.PutObjectTags(ctx, bucket, object, "project=x", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if oi, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil || !isQuarantined(oi) {
+		t.Fatalf("expected the object to stay quarantined, got %v", err)
+	}
+
+	if err = setScanVerdict(ctx, objLayer, bucket, object, "", oi.ETag, scanVerdictReleased, ""); err != nil {
+		t.Fatal(err)
+	}
+	if oi, err = objLayer.GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil || isQuarantined(oi) {
+		t.Fatalf("expected the object to be released, got %v", err)
+	}
+}
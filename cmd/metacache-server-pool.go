@@ -57,6 +57,7 @@ func renameAllBucketMetacache(epPath string) error {
 // Other important fields are Limit, Marker.
 // List ID always derived from the Marker.
 func (z *erasureServerPools) listPath(ctx context.Context, o *listPathOptions) (entries metaCacheEntriesSorted, err error) {
+	serverPools := z.currentPools()
 	if err := checkListObjsArgs(ctx, o.Bucket, o.Prefix, o.Marker, z); err != nil {
 		return entries, err
 	}
@@ -174,9 +175,9 @@ func (z *erasureServerPools) listPath(ctx context.Context, o *listPathOptions) (
 			}
 			entries.truncate(0)
 		} else {
-			if o.pool < len(z.serverPools) && o.set < len(z.serverPools[o.pool].sets) {
+			if o.pool < len(serverPools) && o.set < len(serverPools[o.pool].sets) {
 				o.debugln("Resuming", o)
-				entries, err = z.serverPools[o.pool].sets[o.set].streamMetadataParts(ctx, *o)
+				entries, err = serverPools[o.pool].sets[o.set].streamMetadataParts(ctx, *o)
 				entries.reuse = true // We read from stream and are not sharing results.
 				if err == nil {
 					return entries, nil
@@ -267,7 +268,7 @@ func (z *erasureServerPools) listMerged(ctx context.Context, o listPathOptions,
 	// Ask all sets and merge entries.
 	listCtx, cancelList := context.WithCancel(ctx)
 	defer cancelList()
-	for _, pool := range z.serverPools {
+	for _, pool := range z.currentPools() {
 		for _, set := range pool.sets {
 			wg.Add(1)
 			results := make(chan metaCacheEntry, 100)
@@ -355,8 +356,8 @@ func (z *erasureServerPools) listAndSave(ctx context.Context, o *listPathOptions
 		o.Transient = true
 		return entries, errDiskFull
 	}
-	o.set = z.serverPools[o.pool].getHashedSetIndex(o.ID)
-	saver := z.serverPools[o.pool].sets[o.set]
+	o.set = z.currentPools()[o.pool].getHashedSetIndex(o.ID)
+	saver := z.currentPools()[o.pool].sets[o.set]
 
 	// Disconnect from call above, but cancel on exit.
 	listCtx, cancel := context.WithCancel(GlobalContext)
@@ -298,14 +298,14 @@ func (s *xlStorage) WalkDir(ctx context.Context, opts WalkDirOptions, wr io.Writ
 	return scanDir(opts.BaseDir)
 }
 
-func (p *xlStorageDiskIDCheck) WalkDir(ctx context.Context, opts WalkDirOptions, wr io.Writer) error {
-	defer p.updateStorageMetrics(storageMetricWalkDir, opts.Bucket, opts.BaseDir)()
+func (p *xlStorageDiskIDCheck) WalkDir(ctx context.Context, opts WalkDirOptions, wr io.Writer) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricWalkDir, opts.Bucket, opts.BaseDir)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return err
 	}
 
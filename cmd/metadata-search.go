@@ -0,0 +1,792 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/bucket/policy"
+	"github.com/minio/pkg/wildcard"
+)
+
+const (
+	bucketMetadataIndexConfigFile = "metadata-index.json"
+
+	// metadataIndexShardSize is the number of entries of a shard.
+	metadataIndexShardSize = 5000
+
+	// metadataIndexRebuildInterval is the age from which the scanner
+	// rebuilds the index of a bucket.
+	metadataIndexRebuildInterval = 24 * time.Hour
+
+	// metadataIndexBuildTimeout is the time a build is considered
+	// abandoned after, by a node restarted while building.
+	metadataIndexBuildTimeout = 24 * time.Hour
+
+	maxMetadataIndexUserMetadata = 16
+	maxMetadataQueryConditions   = 16
+	maxMetadataSearchKeys        = 1000
+
+	// maxMetadataSearchScanned is the number of index entries a search
+	// request reads at most, it is truncated after the last one read.
+	maxMetadataSearchScanned = 100000
+)
+
+var (
+	metadataIndexLockTimeout = newDynamicTimeout(30*time.Second, 5*time.Second)
+
+	errMetadataIndexNotReady = errors.New("metadata index of the bucket is not built yet")
+)
+
+// BucketMetadataIndexConfig - enables the metadata index of a bucket,
+// holding the size, modification time, ETag, content type, storage
+// class and tags of the latest version of its objects and the values
+// of the listed user metadata keys.
+type BucketMetadataIndexConfig struct {
+	Enabled      bool     `json:"enabled"`
+	UserMetadata []string `json:"userMetadata,omitempty"`
+}
+
+func parseBucketMetadataIndexConfig(bucket string, data []byte) (*BucketMetadataIndexConfig, error) {
+	cfg := &BucketMetadataIndexConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	if len(cfg.UserMetadata) > maxMetadataIndexUserMetadata {
+		return cfg, fmt.Errorf("Too many user metadata keys to index for bucket %s, at most %d are allowed", bucket, maxMetadataIndexUserMetadata)
+	}
+	for i, k := range cfg.UserMetadata {
+		k = userMetadataIndexKey(k)
+		if k == "" {
+			return cfg, fmt.Errorf("Invalid empty user metadata key to index for bucket %s", bucket)
+		}
+		cfg.UserMetadata[i] = k
+	}
+	return cfg, nil
+}
+
+// userMetadataIndexKey returns the key indexing the user metadata k,
+// which may be given with or without its x-amz-meta- prefix.
+func userMetadataIndexKey(k string) string {
+	return strings.TrimPrefix(strings.ToLower(k), "x-amz-meta-")
+}
+
+// bucketMetadataIndexConfig returns the metadata index configuration of
+// bucket, nil if the index is not enabled.
+func bucketMetadataIndexConfig(bucket string) *BucketMetadataIndexConfig {
+	cfg, _ := globalBucketMetadataSys.GetMetadataIndexConfig(bucket)
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return cfg
+}
+
+// bucketMetadataIndexStatus - the metadata index configuration of a
+// bucket with the state of its index.
+type bucketMetadataIndexStatus struct {
+	BucketMetadataIndexConfig
+	Built    time.Time `json:"built,omitempty"`
+	Building time.Time `json:"building,omitempty"`
+	Objects  uint64    `json:"objects"`
+}
+
+// metadataIndexManifest - the shards of the last build of the index of
+// a bucket, ordered by the first object name they hold. Building is set
+// while a new build is in progress.
+type metadataIndexManifest struct {
+	Build        string              `json:"build,omitempty"`
+	Building     time.Time           `json:"building,omitempty"`
+	Built        time.Time           `json:"built,omitempty"`
+	Objects      uint64              `json:"objects"`
+	UserMetadata []string            `json:"userMetadata,omitempty"`
+	Shards       []listIndexShardRef `json:"shards,omitempty"`
+}
+
+// due returns whether the index is to be rebuilt for configuration cfg.
+func (m metadataIndexManifest) due(cfg *BucketMetadataIndexConfig) bool {
+	if !m.Building.IsZero() {
+		return time.Since(m.Building) > metadataIndexBuildTimeout
+	}
+	if strings.Join(m.UserMetadata, ",") != strings.Join(cfg.UserMetadata, ",") {
+		return true
+	}
+	return time.Since(m.Built) > metadataIndexRebuildInterval
+}
+
+// findShard returns the position of the shard holding name.
+func (m metadataIndexManifest) findShard(name string) int {
+	i := sort.Search(len(m.Shards), func(i int) bool {
+		return m.Shards[i].First > name
+	}) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// metadataIndexEntry - the indexed metadata of the latest version of an
+// object, with its actual size and ETag.
+type metadataIndexEntry struct {
+	Name         string            `json:"n"`
+	ModTime      time.Time         `json:"m"`
+	Size         int64             `json:"s"`
+	ETag         string            `json:"e"`
+	ContentType  string            `json:"t,omitempty"`
+	StorageClass string            `json:"c,omitempty"`
+	Tags         map[string]string `json:"g,omitempty"`
+	UserMetadata map[string]string `json:"u,omitempty"`
+}
+
+type metadataIndexShard struct {
+	Entries []metadataIndexEntry `json:"entries"`
+}
+
+func newMetadataIndexEntry(oi ObjectInfo, userMetadata []string) metadataIndexEntry {
+	size, err := oi.GetActualSize()
+	if err != nil {
+		size = oi.Size
+	}
+	e := metadataIndexEntry{
+		Name:         oi.Name,
+		ModTime:      oi.ModTime,
+		Size:         size,
+		ETag:         oi.GetActualETag(nil),
+		ContentType:  oi.ContentType,
+		StorageClass: oi.StorageClass,
+	}
+	if oi.UserTags != "" {
+		if t, err := tags.ParseObjectTags(oi.UserTags); err == nil {
+			e.Tags = t.ToMap()
+		}
+	}
+	if len(userMetadata) > 0 {
+		for k, v := range oi.UserDefined {
+			k = strings.ToLower(k)
+			if !strings.HasPrefix(k, "x-amz-meta-") {
+				continue
+			}
+			k = userMetadataIndexKey(k)
+			for _, ik := range userMetadata {
+				if k != ik {
+					continue
+				}
+				if e.UserMetadata == nil {
+					e.UserMetadata = make(map[string]string, len(userMetadata))
+				}
+				e.UserMetadata[k] = v
+			}
+		}
+	}
+	return e
+}
+
+func metadataIndexDir(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "metadata-index")
+}
+
+func metadataIndexLockPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, "metadata-index.lock")
+}
+
+func metadataIndexManifestPath(bucket string) string {
+	return pathJoin(metadataIndexDir(bucket), "manifest.json")
+}
+
+func metadataIndexBuildDir(bucket, build string) string {
+	return pathJoin(metadataIndexDir(bucket), "builds", build)
+}
+
+func metadataIndexShardPath(bucket, build, id string) string {
+	return pathJoin(metadataIndexBuildDir(bucket, build), id+".json")
+}
+
+func loadMetadataIndexManifest(ctx context.Context, objAPI ObjectLayer, bucket string) (metadataIndexManifest, error) {
+	var m metadataIndexManifest
+	data, err := readConfig(ctx, objAPI, metadataIndexManifestPath(bucket))
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func saveMetadataIndexManifest(ctx context.Context, objAPI ObjectLayer, bucket string, m metadataIndexManifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, metadataIndexManifestPath(bucket), data)
+}
+
+func loadMetadataIndexShard(ctx context.Context, objAPI ObjectLayer, bucket, build, id string) (metadataIndexShard, error) {
+	var s metadataIndexShard
+	data, err := readConfig(ctx, objAPI, metadataIndexShardPath(bucket, build, id))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func saveMetadataIndexShard(ctx context.Context, objAPI ObjectLayer, bucket, build, id string, s metadataIndexShard) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, metadataIndexShardPath(bucket, build, id), data)
+}
+
+// buildMetadataIndex walks the latest versions of all objects of bucket
+// to build a new index, replacing the current one once complete. The
+// build is skipped unless the index is due or force is set.
+func buildMetadataIndex(ctx context.Context, objAPI ObjectLayer, bucket string, cfg *BucketMetadataIndexConfig, force bool) (err error) {
+	lk := objAPI.NewNSLock(minioMetaBucket, metadataIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, metadataIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	m, err := loadMetadataIndexManifest(lkctx.Context(), objAPI, bucket)
+	if err != nil && err != errConfigNotFound {
+		lk.Unlock(lkctx.Cancel)
+		return err
+	}
+	if !m.due(cfg) && (!force || !m.Building.IsZero()) {
+		lk.Unlock(lkctx.Cancel)
+		return nil
+	}
+	m.Building = UTCNow()
+	err = saveMetadataIndexManifest(lkctx.Context(), objAPI, bucket, m)
+	lk.Unlock(lkctx.Cancel)
+	if err != nil {
+		return err
+	}
+
+	next := metadataIndexManifest{Build: mustGetUUID(), UserMetadata: cfg.UserMetadata}
+	// A failed build removes its shards and keeps the current index.
+	defer func() {
+		if err != nil {
+			logger.LogIf(ctx, finishMetadataIndexBuild(GlobalContext, objAPI, bucket, nil))
+			if derr := deleteConfig(GlobalContext, objAPI, metadataIndexBuildDir(bucket, next.Build)); derr != nil && derr != errConfigNotFound {
+				logger.LogIf(ctx, derr)
+			}
+		}
+	}()
+
+	var cur metadataIndexShard
+	flush := func() error {
+		ref := listIndexShardRef{ID: strconv.Itoa(len(next.Shards))}
+		if len(next.Shards) > 0 {
+			ref.First = cur.Entries[0].Name
+		}
+		if err := saveMetadataIndexShard(ctx, objAPI, bucket, next.Build, ref.ID, cur); err != nil {
+			return err
+		}
+		next.Shards = append(next.Shards, ref)
+		cur = metadataIndexShard{}
+		return nil
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	objInfoCh := make(chan ObjectInfo)
+	if err = objAPI.Walk(wctx, bucket, "", objInfoCh, ObjectOptions{WalkVersions: true}); err != nil {
+		return err
+	}
+	for oi := range objInfoCh {
+		if err != nil || !oi.IsLatest || oi.DeleteMarker {
+			continue
+		}
+		cur.Entries = append(cur.Entries, newMetadataIndexEntry(oi, cfg.UserMetadata))
+		next.Objects++
+		if len(cur.Entries) == metadataIndexShardSize {
+			if err = flush(); err != nil {
+				// Stop the walk and drain what it already listed.
+				cancel()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if len(cur.Entries) > 0 || len(next.Shards) == 0 {
+		if err = flush(); err != nil {
+			return err
+		}
+	}
+	next.Built = UTCNow()
+	return finishMetadataIndexBuild(ctx, objAPI, bucket, &next)
+}
+
+// finishMetadataIndexBuild replaces the index of bucket by the build of
+// next and removes the shards of the build replaced. If next is nil the
+// current index is kept.
+func finishMetadataIndexBuild(ctx context.Context, objAPI ObjectLayer, bucket string, next *metadataIndexManifest) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, metadataIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, metadataIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	m, err := loadMetadataIndexManifest(ctx, objAPI, bucket)
+	if err == errConfigNotFound {
+		// The index was removed while building.
+		if next != nil {
+			err = deleteConfig(ctx, objAPI, metadataIndexBuildDir(bucket, next.Build))
+		}
+		if err == errConfigNotFound {
+			err = nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		m.Building = time.Time{}
+		return saveMetadataIndexManifest(ctx, objAPI, bucket, m)
+	}
+	if err = saveMetadataIndexManifest(ctx, objAPI, bucket, *next); err != nil {
+		return err
+	}
+	if m.Build != "" {
+		// Searches having loaded the manifest before fail on the
+		// removed shards and are to be retried.
+		if err = deleteConfig(ctx, objAPI, metadataIndexBuildDir(bucket, m.Build)); err != nil && err != errConfigNotFound {
+			logger.LogIf(ctx, err)
+		}
+	}
+	return nil
+}
+
+// removeMetadataIndex removes the index of bucket with all its builds.
+func removeMetadataIndex(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, metadataIndexLockPath(bucket))
+	lkctx, err := lk.GetLock(ctx, metadataIndexLockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	if err = deleteConfig(ctx, objAPI, metadataIndexDir(bucket)); err != nil && err != errConfigNotFound {
+		return err
+	}
+	return nil
+}
+
+// checkMetadataIndex queues a build of the metadata index of bucket when
+// it is due, called by the scanner for every bucket scanned.
+func checkMetadataIndex(ctx context.Context, objAPI ObjectLayer, bucket string) {
+	cfg := bucketMetadataIndexConfig(bucket)
+	if cfg == nil {
+		return
+	}
+	m, err := loadMetadataIndexManifest(ctx, objAPI, bucket)
+	if err == errConfigNotFound || err == nil && m.due(cfg) {
+		globalMetadataIndexBuilder.queue(metadataIndexJob{bucket: bucket})
+	}
+}
+
+type metadataIndexJob struct {
+	bucket        string
+	remove, force bool
+}
+
+// metadataIndexBuilder builds the indexes queued one at a time.
+type metadataIndexBuilder struct {
+	mu     sync.Mutex
+	queued map[metadataIndexJob]struct{}
+	jobs   chan metadataIndexJob
+}
+
+var globalMetadataIndexBuilder = &metadataIndexBuilder{
+	queued: make(map[metadataIndexJob]struct{}),
+	jobs:   make(chan metadataIndexJob, 100),
+}
+
+func (b *metadataIndexBuilder) queue(job metadataIndexJob) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.queued[job]; ok {
+		return
+	}
+	select {
+	case b.jobs <- job:
+		b.queued[job] = struct{}{}
+	default:
+	}
+}
+
+func (b *metadataIndexBuilder) run(ctx context.Context, objAPI ObjectLayer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-b.jobs:
+			var err error
+			if cfg := bucketMetadataIndexConfig(job.bucket); cfg != nil && !job.remove {
+				err = buildMetadataIndex(ctx, objAPI, job.bucket, cfg, job.force)
+			} else {
+				err = removeMetadataIndex(ctx, objAPI, job.bucket)
+			}
+			logger.LogIf(ctx, err)
+			b.mu.Lock()
+			delete(b.queued, job)
+			b.mu.Unlock()
+		}
+	}
+}
+
+func initMetadataIndex(ctx context.Context, objAPI ObjectLayer) {
+	go globalMetadataIndexBuilder.run(ctx, objAPI)
+}
+
+// metadataCondition - a comparison of an indexed field with a value.
+type metadataCondition struct {
+	field string
+	key   string
+	op    string
+	value string
+	size  int64
+	time  time.Time
+}
+
+// metadataQuery - conditions all matched by the objects found.
+type metadataQuery []metadataCondition
+
+// parseMetadataQuery parses a query of conditions joined by AND, such as
+//
+//	size > 1GiB AND tag.env = prod AND lastModified < 2024-01-01
+//
+// Conditions compare a field, one of size, lastModified, name, etag,
+// contentType, storageClass, tag.<key> and meta.<key>, with one of the
+// operators =, !=, <, <=, > and >=. Values holding spaces or operators
+// are to be double quoted, = and != match string fields with * and ?
+// wildcards.
+func parseMetadataQuery(s string) (metadataQuery, error) {
+	tokens, err := lexMetadataQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	var q metadataQuery
+	for len(tokens) > 0 {
+		if len(q) > 0 {
+			if !strings.EqualFold(tokens[0], "AND") {
+				return nil, fmt.Errorf("expected AND, found %q", tokens[0])
+			}
+			tokens = tokens[1:]
+		}
+		if len(tokens) < 3 {
+			return nil, errors.New("incomplete condition")
+		}
+		c, err := newMetadataCondition(tokens[0], tokens[1], tokens[2])
+		if err != nil {
+			return nil, err
+		}
+		q = append(q, c)
+		tokens = tokens[3:]
+	}
+	switch {
+	case len(q) == 0:
+		return nil, errors.New("empty query")
+	case len(q) > maxMetadataQueryConditions:
+		return nil, fmt.Errorf("too many conditions, at most %d are allowed", maxMetadataQueryConditions)
+	}
+	return q, nil
+}
+
+// lexMetadataQuery splits a query into fields, operators and values.
+// Quoted values keep their quotes to be told apart from fields.
+func lexMetadataQuery(s string) ([]string, error) {
+	const operators = "=!<>"
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"':
+			j := strings.IndexByte(s[i+1:], '"')
+			if j < 0 {
+				return nil, errors.New("unterminated quoted value")
+			}
+			tokens = append(tokens, s[i:i+j+2])
+			i += j + 2
+		case strings.IndexByte(operators, c) >= 0:
+			j := i + 1
+			for j < len(s) && strings.IndexByte(operators, s[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			j := i + 1
+			for j < len(s) && strings.IndexByte(" \t\""+operators, s[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func newMetadataCondition(field, op, value string) (metadataCondition, error) {
+	c := metadataCondition{op: op}
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		return c, fmt.Errorf("invalid operator %q", op)
+	}
+	if strings.HasPrefix(value, `"`) {
+		value = strings.Trim(value, `"`)
+	} else if strings.IndexAny(value, "=!<>") >= 0 {
+		return c, fmt.Errorf("invalid value %q", value)
+	}
+	c.value = value
+
+	switch lf := strings.ToLower(field); {
+	case lf == "size":
+		c.field = "size"
+		n, err := humanize.ParseBytes(value)
+		if err != nil {
+			return c, fmt.Errorf("invalid size %q", value)
+		}
+		c.size = int64(n)
+	case lf == "lastmodified":
+		c.field = "lastModified"
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return c, fmt.Errorf("invalid date %q", value)
+			}
+		}
+		c.time = t
+	case lf == "name", lf == "etag", lf == "contenttype", lf == "storageclass":
+		c.field = lf
+	case strings.HasPrefix(lf, "tag.") && len(field) > len("tag."):
+		c.field, c.key = "tag", field[len("tag."):]
+	case strings.HasPrefix(lf, "meta.") && len(field) > len("meta."):
+		c.field, c.key = "meta", userMetadataIndexKey(field[len("meta."):])
+	default:
+		return c, fmt.Errorf("invalid field %q", field)
+	}
+	return c, nil
+}
+
+// compared returns whether the comparison result cmp satisfies op.
+func (c metadataCondition) compared(cmp int) bool {
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// match returns whether e satisfies the condition, missing tags and user
+// metadata compare as empty values.
+func (c metadataCondition) match(e metadataIndexEntry) bool {
+	var v string
+	switch c.field {
+	case "size":
+		switch {
+		case e.Size < c.size:
+			return c.compared(-1)
+		case e.Size > c.size:
+			return c.compared(1)
+		}
+		return c.compared(0)
+	case "lastModified":
+		switch {
+		case e.ModTime.Before(c.time):
+			return c.compared(-1)
+		case e.ModTime.After(c.time):
+			return c.compared(1)
+		}
+		return c.compared(0)
+	case "name":
+		v = e.Name
+	case "etag":
+		v = e.ETag
+	case "contenttype":
+		v = e.ContentType
+	case "storageclass":
+		v = e.StorageClass
+		if v == "" {
+			v = "STANDARD"
+		}
+	case "tag":
+		v = e.Tags[c.key]
+	case "meta":
+		v = e.UserMetadata[c.key]
+	}
+	if c.op == "=" || c.op == "!=" {
+		return wildcard.Match(c.value, v) == (c.op == "=")
+	}
+	return c.compared(strings.Compare(v, c.value))
+}
+
+func (q metadataQuery) match(e metadataIndexEntry) bool {
+	for _, c := range q {
+		if !c.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// objectActions returns the actions on each object found needed to
+// search by its tags or user metadata.
+func (q metadataQuery) objectActions() []policy.Action {
+	var tags, meta bool
+	for _, c := range q {
+		tags = tags || c.field == "tag"
+		meta = meta || c.field == "meta"
+	}
+	var actions []policy.Action
+	if tags {
+		actions = append(actions, policy.GetObjectTaggingAction)
+	}
+	if meta {
+		actions = append(actions, policy.GetObjectAction)
+	}
+	return actions
+}
+
+// MetadataSearchObject - an object found by a metadata search.
+type MetadataSearchObject struct {
+	Key          string
+	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
+	ETag         string
+	Size         int64
+	ContentType  string `xml:",omitempty"`
+	StorageClass string `xml:",omitempty"`
+}
+
+// MetadataSearchResult - the objects of a bucket matching a metadata
+// query, as of the time the index was built.
+type MetadataSearchResult struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ MetadataSearchResult" json:"-"`
+
+	Name        string
+	Query       string
+	Prefix      string
+	Marker      string
+	MaxKeys     int
+	IndexBuilt  string
+	IsTruncated bool
+	NextMarker  string                 `xml:",omitempty"`
+	Contents    []MetadataSearchObject `xml:",omitempty"`
+}
+
+// searchMetadataIndex returns the objects of bucket after marker with
+// names starting with prefix which match the query q, and are allowed
+// if allowed is set. The search is truncated after reading
+// maxMetadataSearchScanned entries of the index.
+func searchMetadataIndex(ctx context.Context, objAPI ObjectLayer, bucket, prefix, marker string, q metadataQuery, maxKeys int, allowed func(object string) bool) (MetadataSearchResult, error) {
+	res := MetadataSearchResult{Name: bucket, Prefix: prefix, Marker: marker, MaxKeys: maxKeys}
+	if bucketMetadataIndexConfig(bucket) == nil {
+		return res, errMetadataIndexNotReady
+	}
+	m, err := loadMetadataIndexManifest(ctx, objAPI, bucket)
+	if err == nil && m.Build == "" {
+		err = errConfigNotFound
+	}
+	if err != nil {
+		if err == errConfigNotFound {
+			err = errMetadataIndexNotReady
+		}
+		return res, err
+	}
+	res.IndexBuilt = m.Built.UTC().Format(iso8601TimeFormat)
+
+	start := marker
+	if prefix > start {
+		start = prefix
+	}
+	var scanned int
+	var last string
+	for i := m.findShard(start); i < len(m.Shards); i++ {
+		s, err := loadMetadataIndexShard(ctx, objAPI, bucket, m.Build, m.Shards[i].ID)
+		if err != nil {
+			if err == errConfigNotFound {
+				// Replaced by a new build.
+				err = errMetadataIndexNotReady
+			}
+			return res, err
+		}
+		for _, e := range s.Entries {
+			if e.Name <= marker || e.Name < prefix {
+				continue
+			}
+			if !strings.HasPrefix(e.Name, prefix) {
+				return res, nil
+			}
+			if scanned == maxMetadataSearchScanned {
+				// Continue after the last entry read, matching or not.
+				res.IsTruncated = true
+				res.NextMarker = last
+				return res, nil
+			}
+			scanned, last = scanned+1, e.Name
+			if !q.match(e) || (allowed != nil && !allowed(e.Name)) {
+				continue
+			}
+			if len(res.Contents) == maxKeys {
+				res.IsTruncated = true
+				res.NextMarker = res.Contents[maxKeys-1].Key
+				return res, nil
+			}
+			res.Contents = append(res.Contents, MetadataSearchObject{
+				Key:          e.Name,
+				LastModified: e.ModTime.UTC().Format(iso8601TimeFormat),
+				ETag:         "\"" + e.ETag + "\"",
+				Size:         e.Size,
+				ContentType:  e.ContentType,
+				StorageClass: e.StorageClass,
+			})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/pkg/bucket/policy"
+)
+
+func TestParseMetadataQuery(t *testing.T) {
+	testCases := []struct {
+		query      string
+		conditions int
+		wantErr    bool
+	}{
+		{query: "size > 1GiB", conditions: 1},
+		{query: "size>1GiB AND tag.env=prod", conditions: 2},
+		{query: `size > 1GiB and tag.env = prod AND lastModified < 2024-01-01`, conditions: 3},
+		{query: `name = "photos/2021 trip/*" AND meta.X-Amz-Meta-Owner != alice`, conditions: 2},
+		{query: "lastModified >= 2021-10-01T12:00:00Z", conditions: 1},
+		{query: "", wantErr: true},
+		{query: "size >", wantErr: true},
+		{query: "size == 1", wantErr: true},
+		{query: "size > 1GiB OR tag.env = prod", wantErr: true},
+		{query: "size > big", wantErr: true},
+		{query: "lastModified < yesterday", wantErr: true},
+		{query: "owner = alice", wantErr: true},
+		{query: "tag. = prod", wantErr: true},
+		{query: `name = "photos`, wantErr: true},
+	}
+	for _, tc := range testCases {
+		q, err := parseMetadataQuery(tc.query)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tc.query, tc.wantErr, err)
+			continue
+		}
+		if err == nil && len(q) != tc.conditions {
+			t.Errorf("%q: expected %d conditions, got %d", tc.query, tc.conditions, len(q))
+		}
+	}
+}
+
+func TestMetadataQueryMatch(t *testing.T) {
+	e := metadataIndexEntry{
+		Name:         "logs/2021/app.log",
+		ModTime:      time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		Size:         2 << 30,
+		ContentType:  "text/plain",
+		Tags:         map[string]string{"env": "prod"},
+		UserMetadata: map[string]string{"owner": "alice"},
+	}
+	testCases := []struct {
+		query string
+		match bool
+	}{
+		{query: "size > 1GiB AND tag.env = prod AND lastModified < 2024-01-01", match: true},
+		{query: "size <= 1GiB", match: false},
+		{query: "size = 2GiB", match: true},
+		{query: "lastModified > 2021-06-01", match: false},
+		{query: "lastModified >= 2021-06-01", match: true},
+		{query: "name = logs/*", match: true},
+		{query: "name != logs/*", match: false},
+		{query: "contentType = text/*", match: true},
+		{query: "storageClass = STANDARD", match: true},
+		{query: "tag.env != prod", match: false},
+		{query: "tag.team = \"\"", match: true},
+		{query: "meta.owner = alice", match: true},
+		{query: "meta.X-Amz-Meta-Owner = alice", match: true},
+		{query: "meta.owner > bob", match: false},
+	}
+	for _, tc := range testCases {
+		q, err := parseMetadataQuery(tc.query)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		if got := q.match(e); got != tc.match {
+			t.Errorf("%q: expected match %v, got %v", tc.query, tc.match, got)
+		}
+	}
+}
+
+func TestMetadataQueryObjectActions(t *testing.T) {
+	for query, want := range map[string][]policy.Action{
+		"size > 1GiB AND name = logs/*":          nil,
+		"size > 1GiB AND tag.env = prod":         {policy.GetObjectTaggingAction},
+		"meta.owner = alice":                     {policy.GetObjectAction},
+		"tag.env = prod AND meta.owner = alice":  {policy.GetObjectTaggingAction, policy.GetObjectAction},
+		"meta.owner = alice AND tag.env != prod": {policy.GetObjectTaggingAction, policy.GetObjectAction},
+	} {
+		q, err := parseMetadataQuery(query)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+		if got := q.objectActions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected actions %v, got %v", query, want, got)
+		}
+	}
+}
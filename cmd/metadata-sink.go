@@ -0,0 +1,589 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/config/metadatasink"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/event"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/metasink"
+)
+
+const (
+	metadataSinkBackfillFile = "metadata-sink-backfill.json"
+
+	// metadataSinkBackfillRefresh is how often nodes reload the state of
+	// the backfill.
+	metadataSinkBackfillRefresh = time.Minute
+
+	metadataSinkWriteRetries = 3
+	metadataSinkInitRetry    = 10 * time.Second
+
+	// metadataSinkSweepTimeout is how long the scanner waits for the
+	// sweep of the index after a backfill.
+	metadataSinkSweepTimeout = 10 * time.Minute
+
+	metadataSinkEventBackfill = "backfill"
+)
+
+// metadataSinkTask - a change of an object to write to the sink, the
+// latest version of the object is looked up unless oi is set. Tasks
+// with a sweep time sweep the index once the changes queued before
+// are written, sending the result to done.
+type metadataSinkTask struct {
+	event          string
+	bucket, object string
+	oi             *ObjectInfo
+
+	sweep time.Time
+	done  chan<- error
+}
+
+// metadataSinkStats counts the object changes of this node.
+type metadataSinkStats struct {
+	Written    uint64 `json:"written"`
+	Failed     uint64 `json:"failed"`
+	Dropped    uint64 `json:"dropped"`
+	Backfilled uint64 `json:"backfilled"`
+}
+
+// MetadataSinkBackfill - a backfill of the sink by the scanner, writing
+// the latest version of the objects scanned from StartCycle until
+// EndCycle, enough cycles for the scanner to visit all folders. The
+// objects of the index not written since the request, deleted while
+// their change was lost, are swept after the cycle following EndCycle,
+// unless a node failed to write backfilled objects.
+type MetadataSinkBackfill struct {
+	Requested  time.Time `json:"requested"`
+	StartCycle uint64    `json:"startCycle"`
+	EndCycle   uint64    `json:"endCycle"`
+	Failed     bool      `json:"failed,omitempty"`
+	Swept      bool      `json:"swept,omitempty"`
+}
+
+// active returns whether the objects scanned in cycle are backfilled.
+func (b MetadataSinkBackfill) active(cycle uint64) bool {
+	return cycle >= b.StartCycle && cycle < b.EndCycle
+}
+
+// sweepable returns whether the index is to be swept once cycle ran,
+// leaving a cycle for the nodes to write their queued objects.
+func (b MetadataSinkBackfill) sweepable(cycle uint64) bool {
+	return !b.Requested.IsZero() && !b.Failed && !b.Swept && cycle > b.EndCycle
+}
+
+// metadataSink streams the metadata of the objects written, tagged and
+// removed to an external index, one batch at a time to keep the changes
+// of an object in order.
+type metadataSink struct {
+	mu    sync.RWMutex
+	cfg   metadatasink.Config
+	queue chan metadataSinkTask
+	ctx   context.Context
+
+	cancel context.CancelFunc
+
+	backfillMu     sync.Mutex
+	backfillState  MetadataSinkBackfill
+	backfillLoaded time.Time
+	// backfillFailed is the request time of the backfill this node
+	// failed to write objects of.
+	backfillFailed time.Time
+
+	stats metadataSinkStats
+}
+
+var globalMetadataSink = &metadataSink{}
+
+// Update applies a new metadata sink configuration, restarting the
+// writer of the sink.
+func (s *metadataSink) Update(cfg metadatasink.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.cfg = cfg
+	s.queue, s.ctx = nil, nil
+	if !cfg.Enabled {
+		return
+	}
+
+	var sink metasink.Sink
+	switch cfg.Backend {
+	case metadatasink.BackendElasticsearch:
+		sink = metasink.NewElasticsearch(cfg.URL, cfg.Index, cfg.Username, cfg.Password, NewRemoteTargetHTTPTransport())
+	case metadatasink.BackendPostgreSQL:
+		var err error
+		if sink, err = metasink.NewPostgreSQL(cfg.ConnectionString, cfg.Table); err != nil {
+			logger.LogIf(GlobalContext, fmt.Errorf("metadata sink: %w", err))
+			return
+		}
+	}
+
+	s.ctx, s.cancel = context.WithCancel(GlobalContext)
+	s.queue = make(chan metadataSinkTask, cfg.QueueSize)
+	go s.run(s.ctx, cfg, sink, s.queue)
+}
+
+func (s *metadataSink) config() (metadatasink.Config, chan metadataSinkTask, context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg, s.queue, s.ctx
+}
+
+// onEvent queues the change of the object of an event. Changes are
+// dropped while the queue is full, a backfill recovers them.
+func (s *metadataSink) onEvent(args eventArgs) {
+	switch args.EventName {
+	case event.ObjectCreatedCompleteMultipartUpload, event.ObjectCreatedCopy,
+		event.ObjectCreatedPost, event.ObjectCreatedPut,
+		event.ObjectCreatedPutTagging, event.ObjectCreatedDeleteTagging,
+		event.ObjectRemovedDelete, event.ObjectRemovedDeleteMarkerCreated,
+		event.ObjectTransitionComplete:
+	default:
+		return
+	}
+	cfg, queue, _ := s.config()
+	if !cfg.Includes(args.BucketName) || queue == nil {
+		return
+	}
+	select {
+	case queue <- metadataSinkTask{event: args.EventName.String(), bucket: args.BucketName, object: args.Object.Name}:
+	default:
+		atomic.AddUint64(&s.stats.Dropped, 1)
+		logger.LogOnceIf(GlobalContext, errors.New("metadata sink: queue full, object changes are dropped until the next backfill"), "metadata-sink-queue-full")
+	}
+}
+
+// backfilling returns whether the objects of bucket scanned in cycle are
+// to be backfilled.
+func (s *metadataSink) backfilling(ctx context.Context, objAPI ObjectLayer, bucket string, cycle uint32) bool {
+	if cfg, _, _ := s.config(); !cfg.Includes(bucket) {
+		return false
+	}
+	s.backfillMu.Lock()
+	defer s.backfillMu.Unlock()
+	if time.Since(s.backfillLoaded) > metadataSinkBackfillRefresh {
+		b, err := loadMetadataSinkBackfill(ctx, objAPI)
+		switch err {
+		case nil, errConfigNotFound:
+			s.backfillState = b
+		default:
+			logger.LogIf(ctx, err)
+		}
+		s.backfillLoaded = time.Now()
+	}
+	return s.backfillState.active(uint64(cycle))
+}
+
+// backfill queues the latest version of an object scanned during a
+// backfill, waiting for room in the queue.
+func (s *metadataSink) backfill(ctx context.Context, oi ObjectInfo) {
+	_, queue, sctx := s.config()
+	if queue == nil {
+		return
+	}
+	select {
+	case queue <- metadataSinkTask{event: metadataSinkEventBackfill, bucket: oi.Bucket, object: oi.Name, oi: &oi}:
+		atomic.AddUint64(&s.stats.Backfilled, 1)
+	case <-sctx.Done():
+		s.failBackfill(ctx)
+	case <-ctx.Done():
+		s.failBackfill(ctx)
+	}
+}
+
+// failBackfill records that this node failed to write objects of the
+// backfill running, so that the objects not written are not swept.
+func (s *metadataSink) failBackfill(ctx context.Context) {
+	s.backfillMu.Lock()
+	b := s.backfillState
+	if b.Requested.IsZero() || b.Failed || b.Swept || b.Requested.Equal(s.backfillFailed) {
+		s.backfillMu.Unlock()
+		return
+	}
+	s.backfillFailed = b.Requested
+	s.backfillMu.Unlock()
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return
+	}
+	go func() {
+		err := updateMetadataSinkBackfill(GlobalContext, objAPI, b.Requested, func(b *MetadataSinkBackfill) bool {
+			b.Failed = true
+			return true
+		})
+		logger.LogIf(GlobalContext, err)
+	}()
+}
+
+// sweepBackfill sweeps the index once the backfill completed, removing
+// the objects deleted while their change was lost. It runs on the node
+// running the scanner after every cycle.
+func (s *metadataSink) sweepBackfill(ctx context.Context, objAPI ObjectLayer, cycle uint64) {
+	cfg, queue, sctx := s.config()
+	if !cfg.Enabled || queue == nil {
+		return
+	}
+	b, err := loadMetadataSinkBackfill(ctx, objAPI)
+	if err != nil || !b.sweepable(cycle) {
+		if err != errConfigNotFound {
+			logger.LogIf(ctx, err)
+		}
+		return
+	}
+
+	err = updateMetadataSinkBackfill(ctx, objAPI, b.Requested, func(b *MetadataSinkBackfill) bool {
+		if !b.sweepable(cycle) {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(ctx, metadataSinkSweepTimeout)
+		defer cancel()
+		done := make(chan error, 1)
+		select {
+		case queue <- metadataSinkTask{sweep: b.Requested, done: done}:
+		case <-sctx.Done():
+			return false
+		case <-ctx.Done():
+			return false
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				logger.LogIf(ctx, fmt.Errorf("metadata sink: unable to sweep the %s index: %w", cfg.Backend, err))
+				return false
+			}
+		case <-sctx.Done():
+			return false
+		case <-ctx.Done():
+			return false
+		}
+		b.Swept = true
+		return true
+	})
+	logger.LogIf(ctx, err)
+}
+
+// run sets up the schema of the index, retried until the index is
+// reachable, then writes the queued changes in batches.
+func (s *metadataSink) run(ctx context.Context, cfg metadatasink.Config, sink metasink.Sink, queue <-chan metadataSinkTask) {
+	defer sink.Close()
+
+	for {
+		err := sink.Init(ctx)
+		if err == nil {
+			break
+		}
+		logger.LogOnceIf(ctx, fmt.Errorf("metadata sink: unable to set up the %s index: %w", cfg.Backend, err), "metadata-sink-init")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(metadataSinkInitRetry):
+		}
+	}
+
+	batch := make([]metasink.Record, 0, cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			s.write(ctx, cfg, sink, batch)
+			batch = batch[:0]
+		}
+	}
+	ticker := time.NewTicker(cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			flush()
+		case task := <-queue:
+			if !task.sweep.IsZero() {
+				flush()
+				task.done <- sink.Sweep(ctx, task.sweep)
+				continue
+			}
+			if r, ok := s.record(ctx, task); ok {
+				batch = append(batch, r)
+			}
+			if len(batch) >= cfg.BatchSize {
+				flush()
+			}
+		}
+	}
+}
+
+// write writes a batch of records, retrying failed writes.
+func (s *metadataSink) write(ctx context.Context, cfg metadatasink.Config, sink metasink.Sink, batch []metasink.Record) {
+	var err error
+	for i := 0; i < metadataSinkWriteRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(i) * time.Second):
+			}
+		}
+		if err = sink.Write(ctx, batch); err == nil {
+			atomic.AddUint64(&s.stats.Written, uint64(len(batch)))
+			return
+		}
+	}
+	atomic.AddUint64(&s.stats.Failed, uint64(len(batch)))
+	for _, r := range batch {
+		if r.Event == metadataSinkEventBackfill {
+			s.failBackfill(ctx)
+			break
+		}
+	}
+	logger.LogOnceIf(ctx, fmt.Errorf("metadata sink: unable to write %d object changes to the %s index: %w", len(batch), cfg.Backend, err), "metadata-sink-write")
+}
+
+// record returns the record of the latest version of the object of
+// task, a deleted record if the object has no current version, removed
+// by the time of its delete marker or else of the lookup.
+func (s *metadataSink) record(ctx context.Context, task metadataSinkTask) (metasink.Record, bool) {
+	r := metasink.Record{Bucket: task.bucket, Key: task.object, Event: task.event, EventTime: UTCNow()}
+	oi := task.oi
+	if oi == nil {
+		objAPI := newObjectLayerFn()
+		if objAPI == nil {
+			return r, false
+		}
+		info, err := objAPI.GetObjectInfo(ctx, task.bucket, task.object, ObjectOptions{})
+		switch {
+		case err == nil:
+			oi = &info
+		case isErrObjectNotFound(err), isErrVersionNotFound(err), isErrMethodNotAllowed(err):
+			r.Deleted = true
+			r.LastModified = r.EventTime
+			if !info.ModTime.IsZero() {
+				r.LastModified = info.ModTime
+			}
+			return r, true
+		default:
+			atomic.AddUint64(&s.stats.Failed, 1)
+			logger.LogOnceIf(ctx, fmt.Errorf("metadata sink: unable to read %s/%s: %w", task.bucket, task.object, err), "metadata-sink-read")
+			if task.event == metadataSinkEventBackfill {
+				s.failBackfill(ctx)
+			}
+			return r, false
+		}
+	}
+	if oi.DeleteMarker {
+		r.Deleted = true
+		r.LastModified = oi.ModTime
+		return r, true
+	}
+
+	size, err := oi.GetActualSize()
+	if err != nil {
+		size = oi.Size
+	}
+	r.VersionID = oi.VersionID
+	r.Size = size
+	r.ETag = oi.GetActualETag(nil)
+	r.ContentType = oi.ContentType
+	r.StorageClass = oi.StorageClass
+	r.LastModified = oi.ModTime
+	r.UserMetadata = metadataSinkUserMetadata(oi.UserDefined)
+	if oi.UserTags != "" {
+		if t, err := tags.ParseObjectTags(oi.UserTags); err == nil {
+			r.Tags = t.ToMap()
+		}
+	}
+	return r, true
+}
+
+// metadataSinkUserMetadata returns the user metadata and the headers
+// stored with an object, without its internal and encryption entries.
+func metadataSinkUserMetadata(userDefined map[string]string) map[string]string {
+	m := make(map[string]string, len(userDefined))
+	for k, v := range userDefined {
+		switch lk := strings.ToLower(k); {
+		case strings.HasPrefix(lk, ReservedMetadataPrefixLower):
+		case lk == "etag", lk == strings.ToLower(xhttp.ContentType), lk == strings.ToLower(xhttp.AmzObjectTagging):
+		default:
+			m[k] = v
+		}
+	}
+	crypto.RemoveSensitiveEntries(m)
+	crypto.RemoveInternalEntries(m)
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Stats returns the object change statistics of this node.
+func (s *metadataSink) Stats() metadataSinkStats {
+	return metadataSinkStats{
+		Written:    atomic.LoadUint64(&s.stats.Written),
+		Failed:     atomic.LoadUint64(&s.stats.Failed),
+		Dropped:    atomic.LoadUint64(&s.stats.Dropped),
+		Backfilled: atomic.LoadUint64(&s.stats.Backfilled),
+	}
+}
+
+// queued returns the number of changes waiting to be written.
+func (s *metadataSink) queued() int {
+	_, queue, _ := s.config()
+	return len(queue)
+}
+
+func metadataSinkBackfillPath() string {
+	return pathJoin(minioConfigPrefix, metadataSinkBackfillFile)
+}
+
+func loadMetadataSinkBackfill(ctx context.Context, objAPI ObjectLayer) (MetadataSinkBackfill, error) {
+	var b MetadataSinkBackfill
+	data, err := readConfig(ctx, objAPI, metadataSinkBackfillPath())
+	if err != nil {
+		return b, err
+	}
+	err = json.Unmarshal(data, &b)
+	return b, err
+}
+
+// loadNextScannerCycle returns the number of the scanner cycle running
+// or to run next.
+func loadNextScannerCycle(ctx context.Context, objAPI ObjectLayer) (uint64, error) {
+	cycle := intDataUpdateTracker.current() + 1
+	br, err := objAPI.GetObjectNInfo(ctx, dataUsageBucket, dataUsageBloomName, nil, http.Header{}, readLock, ObjectOptions{})
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrBucketNotFound(err) {
+			return cycle, nil
+		}
+		return 0, err
+	}
+	defer br.Close()
+	if br.ObjInfo.Size == 8 {
+		err = binary.Read(br, binary.LittleEndian, &cycle)
+	}
+	return cycle, err
+}
+
+// updateMetadataSinkBackfill updates the backfill requested at the
+// given time under its lock, saving it when update returns true.
+func updateMetadataSinkBackfill(ctx context.Context, objAPI ObjectLayer, requested time.Time, update func(b *MetadataSinkBackfill) bool) error {
+	lk := objAPI.NewNSLock(minioMetaBucket, metadataSinkBackfillPath())
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	b, err := loadMetadataSinkBackfill(ctx, objAPI)
+	if err != nil {
+		return err
+	}
+	// A new backfill was requested meanwhile.
+	if !b.Requested.Equal(requested) || !update(&b) {
+		return nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, metadataSinkBackfillPath(), data)
+}
+
+// requestMetadataSinkBackfill records a backfill by the scanner cycles
+// following the one running, which may already be halfway.
+func requestMetadataSinkBackfill(ctx context.Context, objAPI ObjectLayer) (MetadataSinkBackfill, error) {
+	lk := objAPI.NewNSLock(minioMetaBucket, metadataSinkBackfillPath())
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return MetadataSinkBackfill{}, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	cycle, err := loadNextScannerCycle(ctx, objAPI)
+	if err != nil {
+		return MetadataSinkBackfill{}, err
+	}
+	b := MetadataSinkBackfill{
+		Requested:  UTCNow(),
+		StartCycle: cycle + 1,
+		EndCycle:   cycle + 1 + dataUsageUpdateDirCycles,
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return b, err
+	}
+	return b, saveConfig(ctx, objAPI, metadataSinkBackfillPath(), data)
+}
+
+// MetadataSinkStatus - the configuration of the metadata sink, the state
+// of its last backfill and the statistics of a node.
+type MetadataSinkStatus struct {
+	Enabled   bool                  `json:"enabled"`
+	Backend   string                `json:"backend,omitempty"`
+	Buckets   []string              `json:"buckets,omitempty"`
+	Queued    int                   `json:"queued"`
+	Stats     metadataSinkStats     `json:"stats"`
+	Backfill  *MetadataSinkBackfill `json:"backfill,omitempty"`
+	NextCycle uint64                `json:"nextCycle"`
+	// BackfillComplete is set once all cycles of the backfill ran.
+	BackfillComplete bool `json:"backfillComplete,omitempty"`
+}
+
+func getMetadataSinkStatus(ctx context.Context, objAPI ObjectLayer) (MetadataSinkStatus, error) {
+	cfg, _, _ := globalMetadataSink.config()
+	status := MetadataSinkStatus{
+		Enabled: cfg.Enabled,
+		Backend: cfg.Backend,
+		Buckets: cfg.Buckets,
+		Queued:  globalMetadataSink.queued(),
+		Stats:   globalMetadataSink.Stats(),
+	}
+	var err error
+	if status.NextCycle, err = loadNextScannerCycle(ctx, objAPI); err != nil {
+		return status, err
+	}
+	b, err := loadMetadataSinkBackfill(ctx, objAPI)
+	switch err {
+	case nil:
+		status.Backfill = &b
+		status.BackfillComplete = status.NextCycle >= b.EndCycle
+	case errConfigNotFound:
+	default:
+		return status, err
+	}
+	return status, nil
+}
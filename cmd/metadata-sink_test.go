@@ -0,0 +1,76 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataSinkUserMetadata(t *testing.T) {
+	userDefined := map[string]string{
+		"etag":                                 "d41d8cd98f00b204e9800998ecf8427e",
+		"content-type":                         "image/png",
+		"X-Amz-Meta-Camera":                    "x100v",
+		"Cache-Control":                        "max-age=60",
+		"X-Amz-Tagging":                        "a=b",
+		ReservedMetadataPrefix + "compression": "klauspost/compress/s2",
+		"X-Minio-Internal-Server-Side-Encryption-Sealed-Key": "c2VhbGVk",
+	}
+	want := map[string]string{
+		"X-Amz-Meta-Camera": "x100v",
+		"Cache-Control":     "max-age=60",
+	}
+	if got := metadataSinkUserMetadata(userDefined); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := metadataSinkUserMetadata(map[string]string{"etag": "abc"}); got != nil {
+		t.Fatalf("expected no user metadata, got %v", got)
+	}
+}
+
+func TestMetadataSinkBackfillActive(t *testing.T) {
+	b := MetadataSinkBackfill{StartCycle: 10, EndCycle: 26}
+	for cycle, want := range map[uint64]bool{9: false, 10: true, 25: true, 26: false} {
+		if got := b.active(cycle); got != want {
+			t.Errorf("cycle %d: expected %v, got %v", cycle, want, got)
+		}
+	}
+	if (MetadataSinkBackfill{}).active(0) {
+		t.Error("expected no backfill to be inactive")
+	}
+}
+
+func TestMetadataSinkBackfillSweepable(t *testing.T) {
+	b := MetadataSinkBackfill{Requested: time.Now(), StartCycle: 10, EndCycle: 26}
+	for cycle, want := range map[uint64]bool{25: false, 26: false, 27: true, 40: true} {
+		if got := b.sweepable(cycle); got != want {
+			t.Errorf("cycle %d: expected %v, got %v", cycle, want, got)
+		}
+	}
+	for _, b := range []MetadataSinkBackfill{
+		{StartCycle: 10, EndCycle: 26},
+		{Requested: b.Requested, StartCycle: 10, EndCycle: 26, Failed: true},
+		{Requested: b.Requested, StartCycle: 10, EndCycle: 26, Swept: true},
+	} {
+		if b.sweepable(27) {
+			t.Errorf("expected %+v not to be swept", b)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -72,6 +73,15 @@ const (
 	usageSubsystem            MetricSubsystem = "usage"
 	ilmSubsystem              MetricSubsystem = "ilm"
 	scannerSubsystem          MetricSubsystem = "scanner"
+	shadowSubsystem           MetricSubsystem = "shadow"
+	throttleSubsystem         MetricSubsystem = "throttle"
+	anomalySubsystem          MetricSubsystem = "anomaly"
+	malwareScanSubsystem      MetricSubsystem = "malware_scan"
+	metadataSinkSubsystem     MetricSubsystem = "metadata_sink"
+	sloSubsystem              MetricSubsystem = "slo"
+	readAheadSubsystem        MetricSubsystem = "readahead"
+	memCacheSubsystem         MetricSubsystem = "memory_cache"
+	namespaceSubsystem        MetricSubsystem = "namespace"
 )
 
 // MetricName are the individual names for the metric.
@@ -93,6 +103,8 @@ const (
 	offlineTotal   MetricName = "offline_total"
 	onlineTotal    MetricName = "online_total"
 	openTotal      MetricName = "open_total"
+	offlineEvents  MetricName = "offline_events_total"
+	timeoutsTotal  MetricName = "timeouts_total"
 	readTotal      MetricName = "read_total"
 	timestampTotal MetricName = "timestamp_total"
 	writeTotal     MetricName = "write_total"
@@ -119,6 +131,7 @@ const (
 
 	sizeDistribution = "size_distribution"
 	ttfbDistribution = "ttfb_seconds_distribution"
+	ioLatency        = "io_latency_seconds"
 
 	lastActivityTime = "last_activity_nano_seconds"
 	startTime        = "starttime_seconds"
@@ -233,6 +246,8 @@ func GetGlobalGenerators() []MetricsGenerator {
 	g := []MetricsGenerator{
 		getBucketUsageMetrics,
 		getMinioHealingMetrics,
+		getScannerHealthMetrics,
+		getCapacityForecastMetrics,
 		getNodeHealthMetrics,
 		getClusterStorageMetrics,
 	}
@@ -259,6 +274,16 @@ func GetGeneratorsForPeer() []MetricsGenerator {
 		getS3TTFBMetric,
 		getILMNodeMetrics,
 		getScannerNodeMetrics,
+		getTrafficShadowMetrics,
+		getBucketLatencyMetrics,
+		getIPThrottleMetrics,
+		getAccessAnomalyMetrics,
+		getMalwareScanMetrics,
+		getMetadataSinkMetrics,
+		getSLOMetrics,
+		getReadAheadMetrics,
+		getObjectMemCacheMetrics,
+		getNamespaceMetrics,
 	}
 	return g
 }
@@ -294,6 +319,15 @@ func getClusterCapacityFreeBytesMD() MetricDescription {
 		Type:      gaugeMetric,
 	}
 }
+func getClusterCapacityStrandedBytesMD() MetricDescription {
+	return MetricDescription{
+		Namespace: clusterMetricNamespace,
+		Subsystem: capacityRawSubsystem,
+		Name:      "stranded_bytes",
+		Help:      "Total capacity of drives beyond the smallest drive of their erasure set, not usable.",
+		Type:      gaugeMetric,
+	}
+}
 func getClusterCapacityUsageBytesMD() MetricDescription {
 	return MetricDescription{
 		Namespace: clusterMetricNamespace,
@@ -380,6 +414,42 @@ func getNodeDiskTotalBytesMD() MetricDescription {
 		Type:      gaugeMetric,
 	}
 }
+func getNodeDiskErrorsTotalMD() MetricDescription {
+	return MetricDescription{
+		Namespace: nodeMetricNamespace,
+		Subsystem: diskSubsystem,
+		Name:      errorsTotal,
+		Help:      "Total number of failed IO calls on a disk, excluding missing files.",
+		Type:      counterMetric,
+	}
+}
+func getNodeDiskTimeoutsTotalMD() MetricDescription {
+	return MetricDescription{
+		Namespace: nodeMetricNamespace,
+		Subsystem: diskSubsystem,
+		Name:      timeoutsTotal,
+		Help:      "Total number of IO calls timed out on a disk.",
+		Type:      counterMetric,
+	}
+}
+func getNodeDiskOfflineEventsMD() MetricDescription {
+	return MetricDescription{
+		Namespace: nodeMetricNamespace,
+		Subsystem: diskSubsystem,
+		Name:      offlineEvents,
+		Help:      "Total number of times a disk was taken offline.",
+		Type:      counterMetric,
+	}
+}
+func getNodeDiskIOLatencyMD() MetricDescription {
+	return MetricDescription{
+		Namespace: nodeMetricNamespace,
+		Subsystem: diskSubsystem,
+		Name:      ioLatency,
+		Help:      "Read and write latency percentiles of the recent calls on a disk.",
+		Type:      gaugeMetric,
+	}
+}
 func getUsageLastScanActivityMD() MetricDescription {
 	return MetricDescription{
 		Namespace: minioMetricNamespace,
@@ -408,6 +478,44 @@ func getBucketUsageObjectsTotalMD() MetricDescription {
 		Type:      gaugeMetric,
 	}
 }
+func getBucketUsageIncompleteUploadsBytesMD() MetricDescription {
+	return MetricDescription{
+		Namespace: bucketMetricNamespace,
+		Subsystem: usageSubsystem,
+		Name:      "incomplete_uploads_bytes",
+		Help:      "Total size in bytes of the uploaded parts of incomplete multipart uploads",
+		Type:      gaugeMetric,
+	}
+}
+func getBucketUsageIncompleteUploadsTotalMD() MetricDescription {
+	return MetricDescription{
+		Namespace: bucketMetricNamespace,
+		Subsystem: usageSubsystem,
+		Name:      "incomplete_uploads_total",
+		Help:      "Total number of incomplete multipart uploads",
+		Type:      gaugeMetric,
+	}
+}
+
+func getBucketUsageQuotaPercentMD() MetricDescription {
+	return MetricDescription{
+		Namespace: bucketMetricNamespace,
+		Subsystem: usageSubsystem,
+		Name:      "quota_percent",
+		Help:      "Percentage of the bucket quota used",
+		Type:      gaugeMetric,
+	}
+}
+
+func getBucketUsageQuotaThresholdMD() MetricDescription {
+	return MetricDescription{
+		Namespace: bucketMetricNamespace,
+		Subsystem: usageSubsystem,
+		Name:      "quota_threshold",
+		Help:      "Highest soft quota threshold crossed by the bucket usage, 0 if none",
+		Type:      gaugeMetric,
+	}
+}
 
 func getBucketRepFailedBytesMD() MetricDescription {
 	return MetricDescription{
@@ -1159,6 +1267,368 @@ func getScannerNodeMetrics() MetricsGroup {
 	}
 }
 
+func getTrafficShadowMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "TrafficShadowMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalTrafficShadow.Stats()
+			newMetric := func(name MetricName, help string, v uint64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: shadowSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      counterMetric,
+					},
+					Value: float64(v),
+				}
+			}
+			return []Metric{
+				newMetric("requests_total", "Total number of read requests mirrored to the shadow cluster", stats.Mirrored),
+				newMetric("matched_total", "Total number of mirrored requests with matching status code and ETag", stats.Matched),
+				newMetric("mismatched_total", "Total number of mirrored requests with differing status code or ETag", stats.Mismatched),
+				newMetric("failed_total", "Total number of mirrored requests that could not reach the shadow cluster", stats.Failed),
+				newMetric("dropped_total", "Total number of sampled requests dropped because the shadow queue was full", stats.Dropped),
+			}
+		},
+	}
+}
+
+func getAccessAnomalyMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "AccessAnomalyMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalAccessAnomaly.Stats()
+			newMetric := func(name MetricName, help string, v uint64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: anomalySubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      counterMetric,
+					},
+					Value: float64(v),
+				}
+			}
+			return []Metric{
+				newMetric("alerts_total", "Total number of access anomalies detected", stats.Alerts),
+				newMetric("failed_total", "Total number of anomaly alerts that could not be posted to the webhook", stats.Failed),
+				newMetric("dropped_total", "Total number of anomaly alerts dropped because the alert queue was full", stats.Dropped),
+			}
+		},
+	}
+}
+
+func getReadAheadMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "ReadAheadMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalReadAhead.Stats()
+			newMetric := func(name MetricName, help string, typ MetricType, v float64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: readAheadSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      typ,
+					},
+					Value: v,
+				}
+			}
+			return []Metric{
+				newMetric("hits_total", "Total number of ranged reads served from prefetched data", counterMetric, float64(stats.Hits)),
+				newMetric("prefetched_bytes_total", "Total number of bytes read ahead for sequential ranged readers", counterMetric, float64(stats.PrefetchedBytes)),
+				newMetric("buffered_bytes", "Memory held by data read ahead in bytes", gaugeMetric, float64(stats.BufferedBytes)),
+			}
+		},
+	}
+}
+
+func getObjectMemCacheMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "ObjectMemCacheMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalObjectMemCache.Stats()
+			newMetric := func(name MetricName, help string, typ MetricType, v float64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: memCacheSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      typ,
+					},
+					Value: v,
+				}
+			}
+			hitRate := 0.0
+			if total := stats.Hits + stats.Misses; total > 0 {
+				hitRate = float64(stats.Hits) / float64(total)
+			}
+			return []Metric{
+				newMetric("hits_total", "Total number of object reads served from memory", counterMetric, float64(stats.Hits)),
+				newMetric("misses_total", "Total number of cacheable object reads not served from memory", counterMetric, float64(stats.Misses)),
+				newMetric("hit_ratio", "Fraction of cacheable object reads served from memory", gaugeMetric, hitRate),
+				newMetric("admitted_total", "Total number of objects admitted into the memory cache", counterMetric, float64(stats.Admitted)),
+				newMetric("rejected_total", "Total number of objects not admitted into the full memory cache for being accessed less often than the objects cached", counterMetric, float64(stats.Rejected)),
+				newMetric("invalidations_total", "Total number of objects written and dropped from the memory cache", counterMetric, float64(stats.Invalidations)),
+				newMetric("objects", "Number of objects cached in memory", gaugeMetric, float64(stats.Objects)),
+				newMetric("used_bytes", "Memory used by the objects cached in bytes", gaugeMetric, float64(stats.Size)),
+			}
+		},
+	}
+}
+
+func getNamespaceMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "NamespaceMetrics",
+		cachedRead: cachedRead,
+		read: func(ctx context.Context) (metrics []Metric) {
+			objLayer := newObjectLayerFn()
+			// Service not initialized yet
+			if objLayer == nil || globalIsGateway {
+				return
+			}
+
+			newMetric := func(name MetricName, help string, labels map[string]string, v float64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: namespaceSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      gaugeMetric,
+					},
+					VariableLabels: labels,
+					Value:          v,
+				}
+			}
+			drives := localDriveNamespaces(ctx, objLayer)
+			metrics = make([]Metric, 0, 4*len(drives))
+			sets := make(map[[2]int]float64)
+			for _, d := range drives {
+				labels := map[string]string{
+					"disk": d.Drive,
+					"pool": strconv.Itoa(d.Pool),
+					"set":  strconv.Itoa(d.Set),
+				}
+				used := d.inodesUsedPercent()
+				metrics = append(metrics,
+					newMetric("inodes_used", "Number of inodes used on a drive", labels, float64(d.UsedInodes)),
+					newMetric("inodes_total", "Total number of inodes of a drive", labels, float64(d.TotalInodes)),
+					newMetric("inodes_used_percent", "Percentage of the inodes of a drive used", labels, used),
+					newMetric("dir_entries_max", "Number of entries of the largest directory of a drive found by the scanner", labels, float64(d.LargestDir.Entries)),
+				)
+				key := [2]int{d.Pool, d.Set}
+				if cur, ok := sets[key]; !ok || used > cur {
+					sets[key] = used
+				}
+			}
+			for key, used := range sets {
+				metrics = append(metrics, newMetric("set_inodes_used_percent", "Percentage of inodes used of the fullest local drive of an erasure set", map[string]string{
+					"pool": strconv.Itoa(key[0]),
+					"set":  strconv.Itoa(key[1]),
+				}, used))
+			}
+			return
+		},
+	}
+}
+
+func getMalwareScanMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "MalwareScanMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) (metrics []Metric) {
+			stats, latency := globalMalwareScanner.Stats()
+			newMetric := func(name MetricName, help string, labels map[string]string, v float64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: malwareScanSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      counterMetric,
+					},
+					VariableLabels: labels,
+					Value:          v,
+				}
+			}
+			for verdict, v := range map[string]uint64{
+				scanVerdictClean:    stats.Clean,
+				scanVerdictInfected: stats.Infected,
+				scanVerdictFailed:   stats.Failed,
+				scanVerdictSkipped:  stats.Skipped,
+			} {
+				metrics = append(metrics, newMetric("verdicts_total", "Total number of scanned uploads by verdict", map[string]string{"verdict": verdict}, float64(v)))
+			}
+			metrics = append(metrics, newMetric("dropped_total", "Total number of uploads not scanned because the scan queue was full", nil, float64(stats.Dropped)))
+			for i, bound := range bucketLatencyBounds {
+				metrics = append(metrics, newMetric("latency_seconds_distribution", "Distribution of the time to scan uploads", map[string]string{"le": fmt.Sprintf("%.3f", bound)}, float64(latency.counts[i])))
+			}
+			total := float64(latency.counts[len(bucketLatencyBounds)])
+			metrics = append(metrics, newMetric("latency_seconds_distribution", "Distribution of the time to scan uploads", map[string]string{"le": "+Inf"}, total))
+			metrics = append(metrics, newMetric("latency_seconds_sum", "Total time spent scanning uploads", nil, latency.sum))
+			metrics = append(metrics, newMetric("latency_seconds_count", "Total number of completed scans", nil, total))
+			return metrics
+		},
+	}
+}
+
+func getMetadataSinkMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "MetadataSinkMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalMetadataSink.Stats()
+			newMetric := func(name MetricName, help string, typ MetricType, v uint64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: metadataSinkSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      typ,
+					},
+					Value: float64(v),
+				}
+			}
+			return []Metric{
+				newMetric("queued", "Number of object changes waiting to be written to the metadata sink", gaugeMetric, uint64(globalMetadataSink.queued())),
+				newMetric("written_total", "Total number of object changes written to the metadata sink", counterMetric, stats.Written),
+				newMetric("failed_total", "Total number of object changes that failed to be written to the metadata sink", counterMetric, stats.Failed),
+				newMetric("dropped_total", "Total number of object changes dropped because the metadata sink queue was full", counterMetric, stats.Dropped),
+				newMetric("backfilled_total", "Total number of objects queued by the scanner for a metadata sink backfill", counterMetric, stats.Backfilled),
+			}
+		},
+	}
+}
+
+func getIPThrottleMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "IPThrottleMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) []Metric {
+			stats := globalIPThrottle.Stats()
+			newMetric := func(name MetricName, help string, typ MetricType, v uint64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: throttleSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      typ,
+					},
+					Value: float64(v),
+				}
+			}
+			return []Metric{
+				newMetric("banned_clients", "Number of client addresses currently banned", gaugeMetric, stats.Banned),
+				newMetric("bans_total", "Total number of temporary client bans", counterMetric, stats.BansTotal),
+				newMetric("auth_failures_total", "Total number of authentication failures counted against clients", counterMetric, stats.AuthFailures),
+				newMetric("rejected_total", "Total number of requests rejected from banned clients", counterMetric, stats.Rejected),
+			}
+		},
+	}
+}
+
+func getSLOMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "SLOMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) (metrics []Metric) {
+			newMetric := func(name MetricName, help string, typ MetricType, labels map[string]string, v float64) Metric {
+				return Metric{
+					Description: MetricDescription{
+						Namespace: nodeMetricNamespace,
+						Subsystem: sloSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      typ,
+					},
+					VariableLabels: labels,
+					Value:          v,
+				}
+			}
+			objectives := globalSLOTracker.objectiveConfigs()
+			counts := [][]SLONodeCounts{globalSLOTracker.nodeCounts(UTCNow())}
+			for _, c := range counts[0] {
+				labels := map[string]string{"slo": c.Name}
+				metrics = append(metrics,
+					newMetric("requests_total", "Total number of S3 calls covered by the objective", counterMetric, labels, float64(c.Total.Requests)),
+					newMetric("errors_total", "Total number of S3 calls covered by the objective failing with a server error", counterMetric, labels, float64(c.Total.Errors)),
+					newMetric("slow_requests_total", "Total number of S3 calls covered by the objective slower than its latency threshold", counterMetric, labels, float64(c.Total.Slow)))
+			}
+			for _, o := range objectives {
+				status := sloStatus(o, counts)
+				for _, w := range status.Windows {
+					if o.Availability > 0 {
+						metrics = append(metrics, newMetric("burn_rate", "Rate the calls of the window spend the error budget of the objective on this node", gaugeMetric,
+							map[string]string{"slo": o.Name, "objective": "availability", "window": w.Window}, w.AvailabilityBurnRate))
+					}
+					if o.Latency > 0 {
+						metrics = append(metrics, newMetric("burn_rate", "Rate the calls of the window spend the error budget of the objective on this node", gaugeMetric,
+							map[string]string{"slo": o.Name, "objective": "latency", "window": w.Window}, w.LatencyBurnRate))
+					}
+				}
+				if o.Availability > 0 {
+					metrics = append(metrics, newMetric("error_budget_remaining_ratio", "Fraction of the error budget of the objective left on this node", gaugeMetric,
+						map[string]string{"slo": o.Name, "objective": "availability"}, status.AvailabilityBudgetRemaining))
+				}
+				if o.Latency > 0 {
+					metrics = append(metrics, newMetric("error_budget_remaining_ratio", "Fraction of the error budget of the objective left on this node", gaugeMetric,
+						map[string]string{"slo": o.Name, "objective": "latency"}, status.LatencyBudgetRemaining))
+				}
+			}
+			return metrics
+		},
+	}
+}
+
+func getBucketLatencyMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "BucketLatencyMetrics",
+		cachedRead: cachedRead,
+		read: func(_ context.Context) (metrics []Metric) {
+			buckets, sums, counts := globalBucketLatencyStats.top()
+			newMetric := func(name MetricName, help string, sample bucketLatencySample) Metric {
+				labels := map[string]string{"bucket": sample.bucket, "api": sample.api}
+				if sample.le != "" {
+					labels["le"] = sample.le
+				}
+				return Metric{
+					Description: MetricDescription{
+						Namespace: bucketMetricNamespace,
+						Subsystem: requestsSubsystem,
+						Name:      name,
+						Help:      help,
+						Type:      counterMetric,
+					},
+					VariableLabels: labels,
+					Value:          sample.value,
+				}
+			}
+			for _, sample := range buckets {
+				metrics = append(metrics, newMetric("latency_seconds_distribution", "Distribution of the time to respond to API calls per bucket.", sample))
+			}
+			for _, sample := range sums {
+				metrics = append(metrics, newMetric("latency_seconds_sum", "Total time spent responding to API calls per bucket.", sample))
+			}
+			for _, sample := range counts {
+				metrics = append(metrics, newMetric("latency_seconds_count", "Total number of API calls per bucket.", sample))
+			}
+			return metrics
+		},
+	}
+}
+
 func getMinioVersionMetrics() MetricsGroup {
 	return MetricsGroup{
 		id:         "MinioVersionMetrics",
@@ -1230,6 +1700,70 @@ func getMinioHealingMetrics() MetricsGroup {
 	}
 }
 
+func getScannerSetLastSuccessMD() MetricDescription {
+	return MetricDescription{
+		Namespace: clusterMetricNamespace,
+		Subsystem: scannerSubsystem,
+		Name:      "set_last_success_seconds",
+		Help:      "Time elapsed in seconds since the last successful scan of the erasure set",
+		Type:      gaugeMetric,
+	}
+}
+
+func getScannerSetStaleMD() MetricDescription {
+	return MetricDescription{
+		Namespace: clusterMetricNamespace,
+		Subsystem: scannerSubsystem,
+		Name:      "set_stale",
+		Help:      "Whether the last successful scan of the erasure set is older than the stale threshold, 1 if stale",
+		Type:      gaugeMetric,
+	}
+}
+
+func getScannerHealthMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "ScannerHealthMetrics",
+		cachedRead: cachedRead,
+		read: func(ctx context.Context) (metrics []Metric) {
+			objLayer := newObjectLayerFn()
+			// Service not initialized yet
+			if objLayer == nil || globalIsGateway {
+				return
+			}
+
+			health, err := loadScannerHealth(ctx, objLayer)
+			if err != nil {
+				return
+			}
+
+			metrics = make([]Metric, 0, 2*len(health.Sets))
+			for _, h := range health.Sets {
+				labels := map[string]string{
+					"pool": strconv.Itoa(h.Pool),
+					"set":  strconv.Itoa(h.Set),
+				}
+				if !h.LastSuccess.IsZero() {
+					metrics = append(metrics, Metric{
+						Description:    getScannerSetLastSuccessMD(),
+						VariableLabels: labels,
+						Value:          time.Since(h.LastSuccess).Seconds(),
+					})
+				}
+				stale := 0.0
+				if h.Stale {
+					stale = 1
+				}
+				metrics = append(metrics, Metric{
+					Description:    getScannerSetStaleMD(),
+					VariableLabels: labels,
+					Value:          stale,
+				})
+			}
+			return
+		},
+	}
+}
+
 func getFailedItems(seq *healSequence) (m []Metric) {
 	m = make([]Metric, 0, 1)
 	for k, v := range seq.gethealFailedItemsMap() {
@@ -1465,6 +1999,23 @@ func getBucketUsageMetrics() MetricsGroup {
 					VariableLabels: map[string]string{"bucket": bucket},
 				})
 
+				if q, err := globalBucketQuotaSys.Get(bucket); err == nil && q != nil && q.Quota > 0 {
+					if quotaUsage, err := globalBucketQuotaSys.quotaUsage(bucket, usage); err == nil {
+						metrics = append(metrics, Metric{
+							Description:    getBucketUsageQuotaPercentMD(),
+							Value:          100 * float64(quotaUsage) / float64(q.Quota),
+							VariableLabels: map[string]string{"bucket": bucket},
+						})
+						if opts, _ := globalBucketMetadataSys.GetQuotaOptions(bucket); opts != nil && len(opts.Thresholds) > 0 {
+							metrics = append(metrics, Metric{
+								Description:    getBucketUsageQuotaThresholdMD(),
+								Value:          float64(opts.crossedThreshold(quotaUsage, q.Quota)),
+								VariableLabels: map[string]string{"bucket": bucket},
+							})
+						}
+					}
+				}
+
 				if stats.hasReplicationUsage() {
 					for arn, stat := range stats.Stats {
 						metrics = append(metrics, Metric{
@@ -1493,6 +2044,23 @@ func getBucketUsageMetrics() MetricsGroup {
 				})
 
 			}
+
+			uploads, err := loadIncompleteUploadsUsage(ctx, objLayer)
+			if err != nil {
+				return
+			}
+			for bucket, usage := range uploads.Buckets {
+				metrics = append(metrics, Metric{
+					Description:    getBucketUsageIncompleteUploadsBytesMD(),
+					Value:          float64(usage.Size),
+					VariableLabels: map[string]string{"bucket": bucket},
+				})
+				metrics = append(metrics, Metric{
+					Description:    getBucketUsageIncompleteUploadsTotalMD(),
+					Value:          float64(usage.Uploads),
+					VariableLabels: map[string]string{"bucket": bucket},
+				})
+			}
 			return
 		},
 	}
@@ -1510,6 +2078,7 @@ func getLocalStorageMetrics() MetricsGroup {
 
 			metrics = make([]Metric, 0, 50)
 			storageInfo, _ := objLayer.LocalStorageInfo(ctx)
+			diskMetrics := getLocalDiskMetrics(objLayer)
 			for _, disk := range storageInfo.Disks {
 				metrics = append(metrics, Metric{
 					Description:    getNodeDiskUsedBytesMD(),
@@ -1534,11 +2103,134 @@ func getLocalStorageMetrics() MetricsGroup {
 					Value:          float64(disk.FreeInodes),
 					VariableLabels: map[string]string{"disk": disk.DrivePath},
 				})
+
+				m, ok := diskMetrics[disk.DrivePath]
+				if !ok {
+					continue
+				}
+				metrics = append(metrics, Metric{
+					Description:    getNodeDiskErrorsTotalMD(),
+					Value:          float64(m.TotalErrors),
+					VariableLabels: map[string]string{"disk": disk.DrivePath},
+				})
+
+				metrics = append(metrics, Metric{
+					Description:    getNodeDiskTimeoutsTotalMD(),
+					Value:          float64(m.TotalTimeouts),
+					VariableLabels: map[string]string{"disk": disk.DrivePath},
+				})
+
+				metrics = append(metrics, Metric{
+					Description:    getNodeDiskOfflineEventsMD(),
+					Value:          float64(m.OfflineEvents),
+					VariableLabels: map[string]string{"disk": disk.DrivePath},
+				})
+
+				for _, op := range []string{"Read", "Write"} {
+					for _, q := range []string{"P50", "P90", "P99"} {
+						d, ok := m.IOLatencies[op+q]
+						if !ok {
+							continue
+						}
+						metrics = append(metrics, Metric{
+							Description: getNodeDiskIOLatencyMD(),
+							Value:       time.Duration(d).Seconds(),
+							VariableLabels: map[string]string{
+								"disk":     disk.DrivePath,
+								"op":       strings.ToLower(op),
+								"quantile": "0." + q[1:],
+							},
+						})
+					}
+				}
+			}
+			return
+		},
+	}
+}
+
+// getLocalDiskMetrics returns the metrics of the local drives by drive
+// path, they are not carried by madmin.Disk.
+func getLocalDiskMetrics(objLayer ObjectLayer) map[string]DiskMetrics {
+	z, ok := objLayer.(*erasureServerPools)
+	if !ok {
+		return nil
+	}
+	diskMetrics := make(map[string]DiskMetrics)
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
+			for _, disk := range set.getDisks() {
+				if disk == nil || !disk.IsLocal() {
+					continue
+				}
+				if p, ok := disk.(*xlStorageDiskIDCheck); ok {
+					diskMetrics[p.String()] = p.getMetrics()
+				}
+			}
+		}
+	}
+	return diskMetrics
+}
+
+func getClusterCapacityDaysUntilFullMD() MetricDescription {
+	return MetricDescription{
+		Namespace: clusterMetricNamespace,
+		Subsystem: capacityRawSubsystem,
+		Name:      "days_until_full",
+		Help:      "Projected days until the pool is full, -1 if not filling up",
+		Type:      gaugeMetric,
+	}
+}
+
+func getBucketUsageDaysUntilQuotaMD() MetricDescription {
+	return MetricDescription{
+		Namespace: bucketMetricNamespace,
+		Subsystem: usageSubsystem,
+		Name:      "days_until_quota",
+		Help:      "Projected days until the bucket reaches its hard quota, -1 if not filling up",
+		Type:      gaugeMetric,
+	}
+}
+
+func getCapacityForecastMetrics() MetricsGroup {
+	return MetricsGroup{
+		id:         "CapacityForecastMetrics",
+		cachedRead: cachedRead,
+		read: func(ctx context.Context) (metrics []Metric) {
+			objLayer := newObjectLayerFn()
+			// Service not initialized yet
+			if objLayer == nil || globalIsGateway {
+				return
+			}
+
+			f, err := loadCapacityForecast(ctx, objLayer)
+			if err != nil || f.Samples == 0 {
+				return
+			}
+
+			metrics = make([]Metric, 0, len(f.Pools)+len(f.Buckets))
+			for _, pf := range f.Pools {
+				metrics = append(metrics, Metric{
+					Description:    getClusterCapacityDaysUntilFullMD(),
+					VariableLabels: map[string]string{"pool": strconv.Itoa(pf.Pool)},
+					Value:          pf.DaysUntilFull,
+				})
+			}
+			for bucket, bf := range f.Buckets {
+				if bf.Quota == 0 {
+					continue
+				}
+				metrics = append(metrics, Metric{
+					Description:    getBucketUsageDaysUntilQuotaMD(),
+					VariableLabels: map[string]string{"bucket": bucket},
+					Value:          bf.DaysUntilFull,
+				})
 			}
 			return
 		},
 	}
 }
+
 func getClusterStorageMetrics() MetricsGroup {
 	return MetricsGroup{
 		id:         "ClusterStorageMetrics",
@@ -1555,6 +2247,7 @@ func getClusterStorageMetrics() MetricsGroup {
 			storageInfo, _ := objLayer.StorageInfo(ctx)
 			onlineDisks, offlineDisks := getOnlineOfflineDisksStats(storageInfo.Disks)
 			totalDisks := onlineDisks.Merge(offlineDisks)
+			capacity := getCapacityInfo(storageInfo)
 
 			metrics = append(metrics, Metric{
 				Description: getClusterCapacityTotalBytesMD(),
@@ -1566,14 +2259,19 @@ func getClusterStorageMetrics() MetricsGroup {
 				Value:       float64(GetTotalCapacityFree(storageInfo.Disks)),
 			})
 
+			metrics = append(metrics, Metric{
+				Description: getClusterCapacityStrandedBytesMD(),
+				Value:       float64(capacity.Stranded),
+			})
+
 			metrics = append(metrics, Metric{
 				Description: getClusterCapacityUsageBytesMD(),
-				Value:       GetTotalUsableCapacity(storageInfo.Disks, storageInfo),
+				Value:       float64(capacity.Data),
 			})
 
 			metrics = append(metrics, Metric{
 				Description: getClusterCapacityUsageFreeBytesMD(),
-				Value:       GetTotalUsableCapacityFree(storageInfo.Disks, storageInfo),
+				Value:       float64(capacity.DataFree),
 			})
 
 			metrics = append(metrics, Metric{
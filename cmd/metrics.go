@@ -749,22 +749,27 @@ func metricsHandler() http.Handler {
 // AuthMiddleware checks if the bearer token is valid and authorized.
 func AuthMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		claims, owner, authErr := webRequestAuthenticate(r)
-		if authErr != nil || !claims.VerifyIssuer("prometheus", true) {
-			w.WriteHeader(http.StatusForbidden)
-			return
-		}
-		// For authenticated users apply IAM policy.
-		if !globalIAMSys.IsAllowed(iampolicy.Args{
-			AccountName:     claims.AccessKey,
-			Action:          iampolicy.PrometheusAdminAction,
-			ConditionValues: getConditionValues(r, "", claims.AccessKey, claims.Map()),
-			IsOwner:         owner,
-			Claims:          claims.Map(),
-		}) {
+		if !isPrometheusRequestAllowed(r) {
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
 		h.ServeHTTP(w, r)
 	})
 }
+
+// isPrometheusRequestAllowed returns whether r carries a prometheus
+// bearer token of a user allowed the prometheus admin action.
+func isPrometheusRequestAllowed(r *http.Request) bool {
+	claims, owner, authErr := webRequestAuthenticate(r)
+	if authErr != nil || !claims.VerifyIssuer("prometheus", true) {
+		return false
+	}
+	// For authenticated users apply IAM policy.
+	return globalIAMSys.IsAllowed(iampolicy.Args{
+		AccountName:     claims.AccessKey,
+		Action:          iampolicy.PrometheusAdminAction,
+		ConditionValues: getConditionValues(r, "", claims.AccessKey, claims.Map()),
+		IsOwner:         owner,
+		Claims:          claims.Map(),
+	})
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// multipartObjectKey records the bucket and object of a multipart
+	// upload in its metadata, the upload directory is only a hash of them.
+	multipartObjectKey = ReservedMetadataPrefixLower + "multipart-object"
+	// multipartInitiatorKey records the access key starting the upload.
+	multipartInitiatorKey = ReservedMetadataPrefixLower + "multipart-initiator"
+
+	incompleteUploadsObjName = ".incomplete-uploads.json"
+
+	defaultClusterMultipartUploads = 1000
+	maxClusterMultipartUploads     = 10000
+)
+
+// multipartUploadExpiry returns the age after which the upload of fi is
+// removed, per the AbortIncompleteMultipartUpload rules of its bucket if
+// any, expiry otherwise. Uploads started by older releases do not record
+// their object and always use expiry.
+func multipartUploadExpiry(fi FileInfo, expiry time.Duration) time.Duration {
+	bucket, object := path2BucketObject(fi.Metadata[multipartObjectKey])
+	if bucket == "" || object == "" {
+		return expiry
+	}
+	lc, err := globalLifecycleSys.Get(bucket)
+	if err != nil {
+		return expiry
+	}
+	if d, ok := lc.AbortIncompleteUploadAfter(object); ok {
+		return d
+	}
+	return expiry
+}
+
+// IncompleteUploadsBucketUsage - the incomplete multipart uploads of a
+// bucket and the size of their uploaded parts.
+type IncompleteUploadsBucketUsage struct {
+	Uploads uint64 `json:"uploads"`
+	Parts   uint64 `json:"parts"`
+	Size    uint64 `json:"size"`
+}
+
+func (u *IncompleteUploadsBucketUsage) add(fi FileInfo) {
+	u.Uploads++
+	for _, part := range fi.Parts {
+		u.Parts++
+		u.Size += uint64(part.Size)
+	}
+}
+
+// IncompleteUploadsUsage - the incomplete multipart uploads per bucket
+// as of the last scanner cycle. Uploads started by older releases do
+// not record their bucket, they are counted as unattributed.
+type IncompleteUploadsUsage struct {
+	LastUpdate   time.Time                               `json:"lastUpdate"`
+	Buckets      map[string]IncompleteUploadsBucketUsage `json:"buckets"`
+	Unattributed IncompleteUploadsBucketUsage            `json:"unattributed"`
+}
+
+func (u *IncompleteUploadsUsage) add(fi FileInfo) {
+	bucket, _ := path2BucketObject(fi.Metadata[multipartObjectKey])
+	if bucket == "" {
+		u.Unattributed.add(fi)
+		return
+	}
+	b := u.Buckets[bucket]
+	b.add(fi)
+	u.Buckets[bucket] = b
+}
+
+// walkMultipartUploads calls fn with every incomplete multipart upload
+// of the set, read from one of its disks, until fn returns false.
+func (er erasureObjects) walkMultipartUploads(ctx context.Context, fn func(uploadID, uploadIDPath string, fi FileInfo) bool) error {
+	disks := er.getLoadBalancedDisks(true)
+	if len(disks) == 0 {
+		return errDiskNotFound
+	}
+	disk := disks[0]
+	shaDirs, err := disk.ListDir(ctx, minioMetaMultipartBucket, "", -1)
+	if err != nil {
+		if errors.Is(err, errVolumeNotFound) || errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	for _, shaDir := range shaDirs {
+		uploadIDs, err := disk.ListDir(ctx, minioMetaMultipartBucket, shaDir, -1)
+		if err != nil {
+			continue
+		}
+		for _, uploadID := range uploadIDs {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			uploadIDPath := pathJoin(shaDir, uploadID)
+			wait := scannerSleeper.Timer(ctx)
+			fi, err := disk.ReadVersion(ctx, minioMetaMultipartBucket, uploadIDPath, "", false)
+			wait()
+			if err != nil {
+				continue
+			}
+			if !fn(strings.TrimSuffix(uploadID, SlashSeparator), uploadIDPath, fi) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// storeIncompleteUploadsUsage counts the incomplete multipart uploads of
+// all buckets and saves the result for the admin API and the metrics.
+func storeIncompleteUploadsUsage(ctx context.Context, objAPI ObjectLayer) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return nil
+	}
+	u := IncompleteUploadsUsage{Buckets: make(map[string]IncompleteUploadsBucketUsage)}
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
+			err := set.walkMultipartUploads(ctx, func(uploadID, uploadIDPath string, fi FileInfo) bool {
+				u.add(fi)
+				return true
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	u.LastUpdate = UTCNow()
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, incompleteUploadsObjName), data)
+}
+
+// loadIncompleteUploadsUsage returns the incomplete multipart uploads
+// counted by the last scanner cycle, empty if not counted yet.
+func loadIncompleteUploadsUsage(ctx context.Context, objAPI ObjectLayer) (IncompleteUploadsUsage, error) {
+	var u IncompleteUploadsUsage
+	data, err := readConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, incompleteUploadsObjName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return u, nil
+		}
+		return u, err
+	}
+	if err = json.Unmarshal(data, &u); err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// ClusterMultipartUpload - an incomplete multipart upload of the cluster.
+// Uploads started by older releases have no bucket, object and initiator.
+type ClusterMultipartUpload struct {
+	Bucket       string    `json:"bucket,omitempty"`
+	Object       string    `json:"object,omitempty"`
+	UploadID     string    `json:"uploadId"`
+	Pool         int       `json:"pool"`
+	Set          int       `json:"set"`
+	Initiator    string    `json:"initiator,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	Parts        int       `json:"parts"`
+	Size         int64     `json:"size"`
+}
+
+// ClusterMultipartUploads - the incomplete multipart uploads matching a
+// filter, truncated at the maximum number requested.
+type ClusterMultipartUploads struct {
+	Uploads   []ClusterMultipartUpload `json:"uploads"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// ClusterMultipartAbortResult - the uploads aborted by age.
+type ClusterMultipartAbortResult struct {
+	Aborted uint64 `json:"aborted"`
+	Failed  uint64 `json:"failed"`
+	Size    int64  `json:"size"`
+}
+
+// multipartUploadsFilter selects incomplete multipart uploads by bucket,
+// object prefix and the age since their last part was uploaded, the age
+// stale uploads are removed by.
+type multipartUploadsFilter struct {
+	bucket    string
+	prefix    string
+	olderThan time.Duration
+}
+
+// parseMultipartUploadsFilter parses the bucket, prefix and older-than
+// filter of an admin request, the age is required to abort uploads.
+func parseMultipartUploadsFilter(form url.Values, requireAge bool) (f multipartUploadsFilter, err error) {
+	f.bucket = form.Get("bucket")
+	f.prefix = form.Get("prefix")
+	if f.prefix != "" && f.bucket == "" {
+		return f, errors.New("prefix requires a bucket")
+	}
+	v := form.Get("older-than")
+	if v == "" {
+		if requireAge {
+			return f, errors.New("older-than is required")
+		}
+		return f, nil
+	}
+	if f.olderThan, err = time.ParseDuration(v); err != nil || f.olderThan < 0 {
+		return f, fmt.Errorf("invalid older-than %q", v)
+	}
+	return f, nil
+}
+
+func (f multipartUploadsFilter) matches(u ClusterMultipartUpload, now time.Time) bool {
+	if f.bucket != "" && u.Bucket != f.bucket {
+		return false
+	}
+	if f.prefix != "" && (u.Bucket == "" || !strings.HasPrefix(u.Object, f.prefix)) {
+		return false
+	}
+	return now.Sub(u.LastModified) >= f.olderThan
+}
+
+func newClusterMultipartUpload(pool, set int, uploadID string, fi FileInfo) ClusterMultipartUpload {
+	bucket, object := path2BucketObject(fi.Metadata[multipartObjectKey])
+	u := ClusterMultipartUpload{
+		Bucket:       bucket,
+		Object:       object,
+		UploadID:     uploadID,
+		Pool:         pool,
+		Set:          set,
+		Initiator:    fi.Metadata[multipartInitiatorKey],
+		LastModified: fi.ModTime,
+		Parts:        len(fi.Parts),
+	}
+	for _, part := range fi.Parts {
+		u.Size += part.Size
+	}
+	return u
+}
+
+// listClusterMultipartUploads returns up to max incomplete multipart
+// uploads of all buckets matching f.
+func listClusterMultipartUploads(ctx context.Context, objAPI ObjectLayer, f multipartUploadsFilter, max int) (ClusterMultipartUploads, error) {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return ClusterMultipartUploads{}, NotImplemented{}
+	}
+	result := ClusterMultipartUploads{Uploads: []ClusterMultipartUpload{}}
+	now := UTCNow()
+	for poolIdx, pool := range z.currentPools() {
+		for setIdx, set := range pool.sets {
+			err := set.walkMultipartUploads(ctx, func(uploadID, uploadIDPath string, fi FileInfo) bool {
+				u := newClusterMultipartUpload(poolIdx, setIdx, uploadID, fi)
+				if !f.matches(u, now) {
+					return true
+				}
+				if len(result.Uploads) >= max {
+					result.Truncated = true
+					return false
+				}
+				result.Uploads = append(result.Uploads, u)
+				return true
+			})
+			if err != nil || result.Truncated {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// abortClusterMultipartUploads aborts the incomplete multipart uploads
+// of all buckets matching f. Uploads without their object are removed
+// like stale uploads are.
+func abortClusterMultipartUploads(ctx context.Context, objAPI ObjectLayer, f multipartUploadsFilter) (ClusterMultipartAbortResult, error) {
+	var result ClusterMultipartAbortResult
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return result, NotImplemented{}
+	}
+	type abortUpload struct {
+		ClusterMultipartUpload
+		uploadIDPath string
+	}
+	now := UTCNow()
+	for poolIdx, pool := range z.currentPools() {
+		for setIdx, set := range pool.sets {
+			var uploads []abortUpload
+			err := set.walkMultipartUploads(ctx, func(uploadID, uploadIDPath string, fi FileInfo) bool {
+				u := newClusterMultipartUpload(poolIdx, setIdx, uploadID, fi)
+				if f.matches(u, now) {
+					uploads = append(uploads, abortUpload{u, uploadIDPath})
+				}
+				return true
+			})
+			if err != nil {
+				return result, err
+			}
+			for _, u := range uploads {
+				if u.Bucket == "" {
+					set.renameAll(ctx, minioMetaMultipartBucket, u.uploadIDPath)
+				} else if err := objAPI.AbortMultipartUpload(ctx, u.Bucket, u.Object, u.UploadID, ObjectOptions{}); err != nil {
+					if _, ok := err.(InvalidUploadID); !ok {
+						logger.LogIf(ctx, err)
+						result.Failed++
+					}
+					continue
+				}
+				result.Aborted++
+				result.Size += u.Size
+			}
+		}
+	}
+	return result, nil
+}
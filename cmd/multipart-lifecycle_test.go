@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestIncompleteUploadsUsage(t *testing.T) {
+	u := IncompleteUploadsUsage{Buckets: make(map[string]IncompleteUploadsBucketUsage)}
+	u.add(FileInfo{
+		Metadata: map[string]string{multipartObjectKey: "bucket/dir/object"},
+		Parts:    []ObjectPartInfo{{Number: 1, Size: 5}, {Number: 2, Size: 3}},
+	})
+	u.add(FileInfo{
+		Metadata: map[string]string{multipartObjectKey: "bucket/object"},
+		Parts:    []ObjectPartInfo{{Number: 1, Size: 2}},
+	})
+	u.add(FileInfo{
+		Metadata: map[string]string{},
+		Parts:    []ObjectPartInfo{{Number: 1, Size: 7}},
+	})
+
+	want := IncompleteUploadsBucketUsage{Uploads: 2, Parts: 3, Size: 10}
+	if got := u.Buckets["bucket"]; got != want {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	want = IncompleteUploadsBucketUsage{Uploads: 1, Parts: 1, Size: 7}
+	if u.Unattributed != want {
+		t.Fatalf("Expected %+v unattributed, got %+v", want, u.Unattributed)
+	}
+}
+
+func TestMultipartUploadExpiryUnattributed(t *testing.T) {
+	fi := FileInfo{Metadata: map[string]string{}}
+	if got := multipartUploadExpiry(fi, time.Hour); got != time.Hour {
+		t.Fatalf("Expected %v, got %v", time.Hour, got)
+	}
+}
+
+func TestMultipartUploadsFilter(t *testing.T) {
+	now := UTCNow()
+	testCases := []struct {
+		form     url.Values
+		upload   ClusterMultipartUpload
+		matches  bool
+		parseErr bool
+	}{
+		{
+			form:    url.Values{},
+			upload:  ClusterMultipartUpload{LastModified: now},
+			matches: true,
+		},
+		{
+			form:    url.Values{"older-than": []string{"1h"}},
+			upload:  ClusterMultipartUpload{Bucket: "bucket", Object: "object", LastModified: now.Add(-30 * time.Minute)},
+			matches: false,
+		},
+		{
+			form:    url.Values{"bucket": []string{"bucket"}, "prefix": []string{"dir/"}, "older-than": []string{"1h"}},
+			upload:  ClusterMultipartUpload{Bucket: "bucket", Object: "dir/object", LastModified: now.Add(-2 * time.Hour)},
+			matches: true,
+		},
+		{
+			form:    url.Values{"bucket": []string{"bucket"}, "prefix": []string{"dir/"}},
+			upload:  ClusterMultipartUpload{Bucket: "bucket", Object: "object", LastModified: now},
+			matches: false,
+		},
+		{
+			form:    url.Values{"bucket": []string{"bucket"}},
+			upload:  ClusterMultipartUpload{LastModified: now},
+			matches: false,
+		},
+		{
+			form:     url.Values{"prefix": []string{"dir/"}},
+			parseErr: true,
+		},
+		{
+			form:     url.Values{"older-than": []string{"a day"}},
+			parseErr: true,
+		},
+	}
+	for i, tc := range testCases {
+		f, err := parseMultipartUploadsFilter(tc.form, false)
+		if (err != nil) != tc.parseErr {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if err != nil {
+			continue
+		}
+		if got := f.matches(tc.upload, now); got != tc.matches {
+			t.Errorf("Test %d: expected match %v, got %v", i+1, tc.matches, got)
+		}
+	}
+
+	if _, err := parseMultipartUploadsFilter(url.Values{}, true); err == nil {
+		t.Fatal("Expected an error aborting uploads without an age")
+	}
+}
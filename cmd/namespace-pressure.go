@@ -0,0 +1,217 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+const namespacePressureCheckInterval = time.Minute
+
+// NamespaceDir - a directory of a drive and its number of entries.
+type NamespaceDir struct {
+	Path    string `json:"path"`
+	Entries uint64 `json:"entries"`
+}
+
+// DriveNamespace - the namespace use of a local drive, its inodes and
+// the directory with the most entries found by the scanner.
+type DriveNamespace struct {
+	Pool        int          `json:"pool"`
+	Set         int          `json:"set"`
+	Drive       string       `json:"drive"`
+	UsedInodes  uint64       `json:"usedInodes"`
+	TotalInodes uint64       `json:"totalInodes"`
+	LargestDir  NamespaceDir `json:"largestDir"`
+}
+
+// inodesUsedPercent returns the percentage of inodes used, zero when the
+// filesystem does not report inodes.
+func (d DriveNamespace) inodesUsedPercent() float64 {
+	if d.TotalInodes == 0 {
+		return 0
+	}
+	return 100 * float64(d.UsedInodes) / float64(d.TotalInodes)
+}
+
+// namespacePressure tracks the namespace use of the local drives and the
+// objects of the cluster, alerting before creates start failing. Drives
+// run out of inodes regardless of free space and directories of ext4
+// without large_dir hold some ten million entries at most, with lookups
+// slowing down well before on XFS and ext4 alike.
+type namespacePressure struct {
+	mu              sync.Mutex
+	objectsAlert    uint64
+	inodesAlert     float64
+	dirEntriesAlert uint64
+
+	// dirs holds the largest directory per bucket per drive.
+	dirs    map[string]map[string]NamespaceDir
+	alerted map[string]bool
+}
+
+var globalNamespacePressure = &namespacePressure{
+	inodesAlert:     90,
+	dirEntriesAlert: 5000000,
+	dirs:            make(map[string]map[string]NamespaceDir),
+	alerted:         make(map[string]bool),
+}
+
+func (n *namespacePressure) setLimits(objects uint64, inodes float64, dirEntries uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.objectsAlert, n.inodesAlert, n.dirEntriesAlert = objects, inodes, dirEntries
+}
+
+// observeDir records the number of entries the scanner found in dir of
+// bucket on drive. Unchanged directories are not read every scanner
+// cycle, so the largest directory of a bucket is replaced by a larger
+// one or when it is found smaller by a rescan.
+func (n *namespacePressure) observeDir(drive, bucket, dir string, entries uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	buckets, ok := n.dirs[drive]
+	if !ok {
+		buckets = make(map[string]NamespaceDir)
+		n.dirs[drive] = buckets
+	}
+	if cur := buckets[bucket]; entries > cur.Entries || dir == cur.Path {
+		buckets[bucket] = NamespaceDir{Path: dir, Entries: entries}
+	}
+}
+
+// deleteBucket forgets the directories of bucket on all drives.
+func (n *namespacePressure) deleteBucket(bucket string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, buckets := range n.dirs {
+		delete(buckets, bucket)
+	}
+}
+
+// largestDir returns the directory with the most entries of drive.
+func (n *namespacePressure) largestDir(drive string) (largest NamespaceDir) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, dir := range n.dirs[drive] {
+		if dir.Entries > largest.Entries {
+			largest = dir
+		}
+	}
+	return largest
+}
+
+// alert returns whether key becomes pressured, alerting once until it
+// is no longer pressured. The caller must hold the lock.
+func (n *namespacePressure) alert(key string, pressured bool) bool {
+	was := n.alerted[key]
+	n.alerted[key] = pressured
+	return pressured && !was
+}
+
+// evaluateDrives returns the alerts of the drives becoming pressured.
+func (n *namespacePressure) evaluateDrives(drives []DriveNamespace) (alerts []error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, d := range drives {
+		used := d.inodesUsedPercent()
+		if n.alert("inodes:"+d.Drive, n.inodesAlert > 0 && used >= n.inodesAlert) {
+			alerts = append(alerts, fmt.Errorf("Drive %s of pool %d set %d uses %.1f%% of its inodes (%d of %d), object creates fail when none are left",
+				d.Drive, d.Pool+1, d.Set+1, used, d.UsedInodes, d.TotalInodes))
+		}
+		if n.alert("dir:"+d.Drive, n.dirEntriesAlert > 0 && d.LargestDir.Entries >= n.dirEntriesAlert) {
+			alerts = append(alerts, fmt.Errorf("Directory %s of drive %s of pool %d set %d holds %d entries, spread the objects over more prefixes",
+				d.LargestDir.Path, d.Drive, d.Pool+1, d.Set+1, d.LargestDir.Entries))
+		}
+	}
+	return alerts
+}
+
+// evaluateObjects returns the alert of the cluster object count when it
+// reaches the limit.
+func (n *namespacePressure) evaluateObjects(objects uint64) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.alert("objects", n.objectsAlert > 0 && objects >= n.objectsAlert) {
+		return fmt.Errorf("The cluster holds %d objects, reaching the alert limit of %d objects", objects, n.objectsAlert)
+	}
+	return nil
+}
+
+// localDriveNamespaces returns the namespace use of the local drives,
+// skipping offline drives.
+func localDriveNamespaces(ctx context.Context, objAPI ObjectLayer) []DriveNamespace {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return nil
+	}
+	var drives []DriveNamespace
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
+			endpoints := set.getEndpoints()
+			for i, disk := range set.getDisks() {
+				if disk == nil || !endpoints[i].IsLocal {
+					continue
+				}
+				info, err := disk.DiskInfo(ctx)
+				if err != nil {
+					continue
+				}
+				d := DriveNamespace{
+					Drive:       info.MountPath,
+					UsedInodes:  info.UsedInodes,
+					TotalInodes: info.UsedInodes + info.FreeInodes,
+					LargestDir:  globalNamespacePressure.largestDir(info.MountPath),
+				}
+				d.Pool, d.Set, _ = disk.GetDiskLoc()
+				drives = append(drives, d)
+			}
+		}
+	}
+	sort.Slice(drives, func(i, j int) bool {
+		return drives[i].Drive < drives[j].Drive
+	})
+	return drives
+}
+
+// initNamespacePressureCheck starts alerting the local drives
+// approaching their namespace limits.
+func initNamespacePressureCheck(ctx context.Context, objAPI ObjectLayer) {
+	go runNamespacePressureCheck(ctx, objAPI)
+}
+
+func runNamespacePressureCheck(ctx context.Context, objAPI ObjectLayer) {
+	ticker := time.NewTicker(namespacePressureCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, err := range globalNamespacePressure.evaluateDrives(localDriveNamespaces(ctx, objAPI)) {
+				logger.LogIf(ctx, err, logger.Application)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNamespacePressureLargestDir(t *testing.T) {
+	n := &namespacePressure{
+		dirs:    make(map[string]map[string]NamespaceDir),
+		alerted: make(map[string]bool),
+	}
+	n.observeDir("/drive1", "bucket", "bucket/a", 10)
+	n.observeDir("/drive1", "bucket", "bucket/b", 5)
+	n.observeDir("/drive1", "other", "other", 7)
+	n.observeDir("/drive2", "bucket", "bucket/c", 100)
+	if got := n.largestDir("/drive1"); got.Path != "bucket/a" || got.Entries != 10 {
+		t.Fatalf("unexpected largest dir %#v", got)
+	}
+
+	// A rescan finding the largest directory smaller replaces it.
+	n.observeDir("/drive1", "bucket", "bucket/a", 3)
+	if got := n.largestDir("/drive1"); got.Path != "other" || got.Entries != 7 {
+		t.Fatalf("unexpected largest dir %#v", got)
+	}
+
+	n.deleteBucket("other")
+	if got := n.largestDir("/drive1"); got.Path != "bucket/a" || got.Entries != 3 {
+		t.Fatalf("unexpected largest dir %#v", got)
+	}
+	if got := n.largestDir("/drive3"); got.Entries != 0 {
+		t.Fatalf("unexpected largest dir %#v", got)
+	}
+}
+
+func TestNamespacePressureAlerts(t *testing.T) {
+	n := &namespacePressure{
+		dirs:    make(map[string]map[string]NamespaceDir),
+		alerted: make(map[string]bool),
+	}
+	n.setLimits(1000, 90, 100)
+
+	drives := []DriveNamespace{
+		{Drive: "/drive1", UsedInodes: 95, TotalInodes: 100},
+		{Drive: "/drive2", UsedInodes: 10, TotalInodes: 100, LargestDir: NamespaceDir{Path: "bucket/prefix", Entries: 150}},
+		// Filesystems without inodes are never alerted.
+		{Drive: "/drive3"},
+	}
+	if alerts := n.evaluateDrives(drives); len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %v", alerts)
+	}
+	// Alerted once while pressured.
+	if alerts := n.evaluateDrives(drives); len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %v", alerts)
+	}
+	drives[0].UsedInodes = 50
+	if alerts := n.evaluateDrives(drives); len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %v", alerts)
+	}
+	drives[0].UsedInodes = 90
+	if alerts := n.evaluateDrives(drives); len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %v", alerts)
+	}
+
+	if err := n.evaluateObjects(999); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.evaluateObjects(1000); err == nil {
+		t.Fatal("expected an alert")
+	}
+	if err := n.evaluateObjects(2000); err != nil {
+		t.Fatalf("expected no repeated alert, got %v", err)
+	}
+
+	// Zero disables the alerts.
+	n.setLimits(0, 0, 0)
+	n.alerted = make(map[string]bool)
+	if alerts := n.evaluateDrives(drives); len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %v", alerts)
+	}
+	if err := n.evaluateObjects(2000); err != nil {
+		t.Fatal(err)
+	}
+}
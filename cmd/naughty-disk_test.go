@@ -211,6 +211,13 @@ func (d *naughtyDisk) RenameFile(ctx context.Context, srcVolume, srcPath, dstVol
 	return d.disk.RenameFile(ctx, srcVolume, srcPath, dstVolume, dstPath)
 }
 
+func (d *naughtyDisk) LinkFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) error {
+	if err := d.calcError(); err != nil {
+		return err
+	}
+	return d.disk.LinkFile(ctx, srcVolume, srcPath, dstVolume, dstPath)
+}
+
 func (d *naughtyDisk) CheckParts(ctx context.Context, volume string, path string, fi FileInfo) (err error) {
 	if err := d.calcError(); err != nil {
 		return err
@@ -29,6 +29,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bits-and-blooms/bloom/v3"
@@ -300,13 +301,19 @@ func (sys *NotificationSys) StartProfiling(profiler string) []NotificationPeerEr
 
 // DownloadProfilingData - download profiling data from all remote peers.
 func (sys *NotificationSys) DownloadProfilingData(ctx context.Context, writer io.Writer) bool {
-	profilingDataFound := false
-
 	// Initialize a zip writer which will provide a zipped content
 	// of profiling data of all nodes
 	zipWriter := zip.NewWriter(writer)
 	defer zipWriter.Close()
 
+	return sys.addProfilingData(ctx, zipWriter)
+}
+
+// addProfilingData - adds the profiling data of all nodes to zipWriter,
+// stopping their profilers.
+func (sys *NotificationSys) addProfilingData(ctx context.Context, zipWriter *zip.Writer) bool {
+	profilingDataFound := false
+
 	for _, client := range sys.peerClients {
 		if client == nil {
 			continue
@@ -408,7 +415,7 @@ func (sys *NotificationSys) ServerUpdate(ctx context.Context, u *url.URL, sha256
 		}
 		client := client
 		ng.Go(ctx, func() error {
-			return client.ServerUpdate(ctx, u, sha256Sum, lrTime, releaseInfo)
+			return client.ServerUpdate(ctx, u, sha256Sum, lrTime, releaseInfo, false)
 		}, idx, *client.host)
 	}
 	return ng.Wait()
@@ -563,6 +570,7 @@ func (sys *NotificationSys) DeleteBucketMetadata(ctx context.Context, bucketName
 	globalReplicationStats.Delete(bucketName)
 	globalBucketMetadataSys.Remove(bucketName)
 	globalBucketTargetSys.Delete(bucketName)
+	globalBucketLatencyStats.forget(bucketName)
 	if localMetacacheMgr != nil {
 		localMetacacheMgr.deleteBucketCache(bucketName)
 	}
@@ -663,7 +671,7 @@ func (sys *NotificationSys) Init(ctx context.Context, buckets []BucketInfo, objA
 		return nil
 	}
 
-	logger.LogIf(ctx, sys.targetList.Add(globalConfigTargetList.Targets()...))
+	logger.LogIf(ctx, sys.targetList.Add(getConfigTargetList().Targets()...))
 
 	go func() {
 		for res := range sys.targetResCh {
@@ -1382,6 +1390,13 @@ func (args eventArgs) ToEvent(escape bool) event.Event {
 func sendEvent(args eventArgs) {
 	args.Object.Size, _ = args.Object.GetActualSize()
 
+	// The list index also follows the writes of replicas.
+	if globalIsErasure {
+		updateListIndexOnEvent(args)
+		globalChangelogAppender.onEvent(args)
+	}
+	globalMetadataSink.onEvent(args)
+
 	// avoid generating a notification for REPLICA creation event.
 	if _, ok := args.ReqParams[xhttp.MinIOSourceReplicationRequest]; ok {
 		return
@@ -1501,6 +1516,57 @@ func (sys *NotificationSys) GetClusterMetrics(ctx context.Context) chan Metric {
 	return ch
 }
 
+// Benchmark runs the workload benchmark on all nodes at the same time.
+func (sys *NotificationSys) Benchmark(ctx context.Context, opts BenchmarkOpts) BenchmarkReport {
+	endpoints := make([]string, len(sys.peerClients)+1)
+	stats := make([]benchmarkNodeStats, len(sys.peerClients)+1)
+
+	scheme := "http"
+	if globalIsTLS {
+		scheme = "https"
+	}
+
+	var wg sync.WaitGroup
+	for index := range sys.peerClients {
+		if sys.peerClients[index] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			s, err := sys.peerClients[index].Benchmark(ctx, opts)
+			if err != nil {
+				s.Error = err.Error()
+			}
+			stats[index] = s
+			endpoints[index] = (&url.URL{Scheme: scheme, Host: sys.peerClients[index].host.String()}).String()
+		}(index)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s, err := selfBenchmark(ctx, opts)
+		if err != nil {
+			s.Error = err.Error()
+		}
+		stats[len(stats)-1] = s
+		endpoints[len(endpoints)-1] = (&url.URL{Scheme: scheme, Host: globalLocalNodeName}).String()
+	}()
+	wg.Wait()
+
+	// Skip the peers we could not reach.
+	var reachable []string
+	var reachableStats []benchmarkNodeStats
+	for i := range endpoints {
+		if endpoints[i] != "" {
+			reachable = append(reachable, endpoints[i])
+			reachableStats = append(reachableStats, stats[i])
+		}
+	}
+	return newBenchmarkReport(opts, reachable, reachableStats)
+}
+
 // Speedtest run GET/PUT tests at input concurrency for requested object size,
 // optionally you can extend the tests longer with time.Duration.
 func (sys *NotificationSys) Speedtest(ctx context.Context, size int, concurrent int, duration time.Duration) []SpeedtestResult {
@@ -1577,3 +1643,301 @@ func (sys *NotificationSys) ReloadSiteReplicationConfig(ctx context.Context) []e
 	wg.Wait()
 	return errs
 }
+
+// LoadTenants - tells all peer minio nodes to reload the tenants.
+func (sys *NotificationSys) LoadTenants(ctx context.Context) []NotificationPeerErr {
+	ng := WithNPeers(len(sys.peerClients))
+	for idx, client := range sys.peerClients {
+		if client == nil {
+			continue
+		}
+		client := client
+		ng.Go(ctx, func() error {
+			return client.LoadTenants(ctx)
+		}, idx, *client.host)
+	}
+	return ng.Wait()
+}
+
+// LoadBucketTemplates - tells all peer minio nodes to reload the bucket
+// templates.
+func (sys *NotificationSys) LoadBucketTemplates(ctx context.Context) []NotificationPeerErr {
+	ng := WithNPeers(len(sys.peerClients))
+	for idx, client := range sys.peerClients {
+		if client == nil {
+			continue
+		}
+		client := client
+		ng.Go(ctx, func() error {
+			return client.LoadBucketTemplates(ctx)
+		}, idx, *client.host)
+	}
+	return ng.Wait()
+}
+
+// LoadJobSchedules - tells all peer minio nodes to reload the job
+// schedules.
+func (sys *NotificationSys) LoadJobSchedules(ctx context.Context) []NotificationPeerErr {
+	ng := WithNPeers(len(sys.peerClients))
+	for idx, client := range sys.peerClients {
+		if client == nil {
+			continue
+		}
+		client := client
+		ng.Go(ctx, func() error {
+			return client.LoadJobSchedules(ctx)
+		}, idx, *client.host)
+	}
+	return ng.Wait()
+}
+
+// GetJobStatus - returns the background jobs of all peers, unreachable
+// peers are reported with their error.
+func (sys *NotificationSys) GetJobStatus(ctx context.Context) []NodeJobStatus {
+	nodes := make([]NodeJobStatus, len(sys.peerClients))
+	g := errgroup.WithNErrs(len(sys.peerClients))
+	for index, client := range sys.peerClients {
+		index := index
+		client := client
+		g.Go(func() error {
+			if client == nil {
+				return errPeerNotReachable
+			}
+			nodes[index].Host = client.host.String()
+			var err error
+			nodes[index].Jobs, err = client.GetJobStatus(ctx)
+			return err
+		}, index)
+	}
+	for index, err := range g.Wait() {
+		if err != nil {
+			if nodes[index].Host == "" {
+				nodes[index].Host = "unknown"
+			}
+			nodes[index].Error = err.Error()
+		}
+	}
+	return nodes
+}
+
+// GetExtractProgress - returns the progress of the archives being
+// extracted on all nodes, oldest first.
+func (sys *NotificationSys) GetExtractProgress(ctx context.Context) []ExtractProgress {
+	progress := make([][]ExtractProgress, len(sys.peerClients))
+	g := errgroup.WithNErrs(len(sys.peerClients))
+	for index, client := range sys.peerClients {
+		index := index
+		client := client
+		g.Go(func() error {
+			if client == nil {
+				return errPeerNotReachable
+			}
+			var err error
+			progress[index], err = client.GetExtractProgress(ctx)
+			return err
+		}, index)
+	}
+	for index, err := range g.Wait() {
+		if err != nil {
+			reqInfo := (&logger.ReqInfo{}).AppendTags("peerAddress",
+				sys.peerClients[index].host.String())
+			ctx := logger.SetReqInfo(ctx, reqInfo)
+			logger.LogOnceIf(ctx, err, sys.peerClients[index].host.String())
+		}
+	}
+	all := globalExtractTracker.list()
+	for _, p := range progress {
+		all = append(all, p...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].StartedAt.Before(all[j].StartedAt)
+	})
+	return all
+}
+
+// GetSLOCounts - returns the calls counted by the objectives of all
+// nodes, this node first, along with the errors of unreachable peers.
+func (sys *NotificationSys) GetSLOCounts(ctx context.Context) ([][]SLONodeCounts, []string) {
+	counts := make([][]SLONodeCounts, len(sys.peerClients))
+	g := errgroup.WithNErrs(len(sys.peerClients))
+	for index, client := range sys.peerClients {
+		index := index
+		client := client
+		g.Go(func() error {
+			if client == nil {
+				return errPeerNotReachable
+			}
+			var err error
+			counts[index], err = client.GetSLOCounts(ctx)
+			return err
+		}, index)
+	}
+	var errs []string
+	for index, err := range g.Wait() {
+		if err != nil {
+			host := "unknown"
+			if sys.peerClients[index] != nil {
+				host = sys.peerClients[index].host.String()
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", host, err))
+		}
+	}
+	return append([][]SLONodeCounts{globalSLOTracker.nodeCounts(UTCNow())}, counts...), errs
+}
+
+// InvalidateObjectMemCache - drops objects of bucket from the object
+// memory cache of all peers, all objects starting with the first object
+// if prefix is set.
+func (sys *NotificationSys) InvalidateObjectMemCache(ctx context.Context, bucket string, objects []string, prefix bool) {
+	ng := WithNPeers(len(sys.peerClients))
+	for idx, client := range sys.peerClients {
+		if client == nil {
+			continue
+		}
+		client := client
+		ng.Go(ctx, func() error {
+			return client.InvalidateObjectMemCache(ctx, bucket, objects, prefix)
+		}, idx, *client.host)
+	}
+	for _, nErr := range ng.Wait() {
+		if nErr.Err != nil {
+			reqInfo := (&logger.ReqInfo{}).AppendTags("peerAddress", nErr.Host.String())
+			logger.LogOnceIf(logger.SetReqInfo(ctx, reqInfo), nErr.Err, "objectmemcache-"+nErr.Host.String())
+		}
+	}
+}
+
+// GetSlowRequests - returns the newest slow requests of all nodes
+// matching f, along with the errors of unreachable peers.
+func (sys *NotificationSys) GetSlowRequests(ctx context.Context, f slowRequestFilter) ([]SlowRequest, []string) {
+	reqs := make([][]SlowRequest, len(sys.peerClients))
+	g := errgroup.WithNErrs(len(sys.peerClients))
+	for index, client := range sys.peerClients {
+		index := index
+		client := client
+		g.Go(func() error {
+			if client == nil {
+				return errPeerNotReachable
+			}
+			var err error
+			reqs[index], err = client.GetSlowRequests(ctx, f)
+			return err
+		}, index)
+	}
+	var errs []string
+	for index, err := range g.Wait() {
+		if err != nil {
+			host := "unknown"
+			if sys.peerClients[index] != nil {
+				host = sys.peerClients[index].host.String()
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", host, err))
+		}
+	}
+	all := globalSlowRequests.query(f)
+	for _, r := range reqs {
+		all = append(all, r...)
+	}
+	sortSlowRequests(all)
+	if len(all) > f.Count {
+		all = all[:f.Count]
+	}
+	return all, errs
+}
+
+// GetInflightRequests - returns the S3 requests in flight on all nodes,
+// oldest first.
+func (sys *NotificationSys) GetInflightRequests(ctx context.Context) []InflightRequest {
+	reqs := make([][]InflightRequest, len(sys.peerClients))
+	g := errgroup.WithNErrs(len(sys.peerClients))
+	for index, client := range sys.peerClients {
+		index := index
+		client := client
+		g.Go(func() error {
+			if client == nil {
+				return errPeerNotReachable
+			}
+			var err error
+			reqs[index], err = client.GetInflightRequests(ctx)
+			return err
+		}, index)
+	}
+	for index, err := range g.Wait() {
+		if err != nil {
+			reqInfo := (&logger.ReqInfo{}).AppendTags("peerAddress",
+				sys.peerClients[index].host.String())
+			ctx := logger.SetReqInfo(ctx, reqInfo)
+			logger.LogOnceIf(ctx, err, sys.peerClients[index].host.String())
+		}
+	}
+	all := globalInflightRequests.list()
+	for _, r := range reqs {
+		all = append(all, r...)
+	}
+	sortInflightRequests(all)
+	return all
+}
+
+// peerClient returns the client of the peer node, nil if not found.
+func (sys *NotificationSys) peerClient(node string) *peerRESTClient {
+	for _, client := range sys.peerClients {
+		if client != nil && client.host.String() == node {
+			return client
+		}
+	}
+	return nil
+}
+
+// DrainNode - starts draining the given node.
+func (sys *NotificationSys) DrainNode(ctx context.Context, node string, timeout time.Duration) (DrainStatus, error) {
+	if node == "" || node == globalLocalNodeName {
+		return globalNodeDrain.start(timeout), nil
+	}
+	client := sys.peerClient(node)
+	if client == nil {
+		return DrainStatus{}, errNodeNotFound
+	}
+	return client.Drain(ctx, timeout)
+}
+
+// NodeDrainStatus - returns the drain status of the given node.
+func (sys *NotificationSys) NodeDrainStatus(ctx context.Context, node string) (DrainStatus, error) {
+	if node == "" || node == globalLocalNodeName {
+		return globalNodeDrain.status(), nil
+	}
+	client := sys.peerClient(node)
+	if client == nil {
+		return DrainStatus{}, errNodeNotFound
+	}
+	return client.DrainStatus(ctx)
+}
+
+// CancelInflightRequest - cancels the S3 request with the given id
+// on whichever node serves it, returns false if it was not found.
+func (sys *NotificationSys) CancelInflightRequest(ctx context.Context, id string) bool {
+	if globalInflightRequests.cancel(id) {
+		return true
+	}
+	var found int32
+	ng := WithNPeers(len(sys.peerClients))
+	for idx, client := range sys.peerClients {
+		if client == nil {
+			continue
+		}
+		client := client
+		ng.Go(ctx, func() error {
+			ok, err := client.CancelInflightRequest(ctx, id)
+			if ok {
+				atomic.StoreInt32(&found, 1)
+			}
+			return err
+		}, idx, *client.host)
+	}
+	for _, nErr := range ng.Wait() {
+		reqInfo := (&logger.ReqInfo{}).AppendTags("peerAddress", nErr.Host.String())
+		if nErr.Err != nil {
+			logger.LogIf(logger.SetReqInfo(ctx, reqInfo), nErr.Err)
+		}
+	}
+	return atomic.LoadInt32(&found) == 1
+}
@@ -56,8 +56,18 @@ type objectHistogramInterval struct {
 const (
 	// dataUsageBucketLen must be length of ObjectsHistogramIntervals
 	dataUsageBucketLen = 7
+
+	// coldSizesLen must be length of coldAccessDays
+	coldSizesLen = 3
+
+	// compressedSizesLen holds the stored and the actual size
+	compressedSizesLen = 2
 )
 
+// coldAccessDays is the list of numbers of days without access after
+// which objects are reported as not accessed.
+var coldAccessDays = [coldSizesLen]int{30, 90, 365}
+
 // ObjectsHistogramIntervals is the list of all intervals
 // of object sizes to be included in objects histogram.
 var ObjectsHistogramIntervals = []objectHistogramInterval{
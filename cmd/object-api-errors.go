@@ -386,6 +386,15 @@ func (e BucketQuotaExceeded) Error() string {
 	return "Bucket quota exceeded for bucket: " + e.Bucket
 }
 
+// TenantQuotaExceeded - aggregate quota of a tenant exceeded.
+type TenantQuotaExceeded struct {
+	Tenant string
+}
+
+func (e TenantQuotaExceeded) Error() string {
+	return "Tenant quota exceeded for tenant: " + e.Tenant
+}
+
 // BucketReplicationConfigNotFound - no bucket replication config found
 type BucketReplicationConfigNotFound GenericError
 
@@ -668,6 +677,16 @@ func isErrSignatureDoesNotMatch(err error) bool {
 	return errors.As(err, &signatureDoesNotMatch)
 }
 
+// AppendPositionMismatch - append position differs from the object size.
+type AppendPositionMismatch struct {
+	GenericError
+	Size int64
+}
+
+func (e AppendPositionMismatch) Error() string {
+	return fmt.Sprintf("Append position does not match the size %d of object %s/%s", e.Size, e.Bucket, e.Object)
+}
+
 // PreConditionFailed - Check if copy precondition failed
 type PreConditionFailed struct{}
 
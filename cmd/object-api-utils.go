@@ -401,7 +401,7 @@ func (o ObjectInfo) IsCompressedOK() (bool, error) {
 		return false, nil
 	}
 	switch scheme {
-	case compressionAlgorithmV1, compressionAlgorithmV2:
+	case compressionAlgorithmV1, compressionAlgorithmV2, compressionAlgorithmZstd:
 		return true, nil
 	}
 	return true, fmt.Errorf("unknown compression scheme: %s", scheme)
@@ -418,7 +418,8 @@ func (o ObjectInfo) GetActualETag(h http.Header) string {
 
 // GetActualSize - returns the actual size of the stored object
 func (o ObjectInfo) GetActualSize() (int64, error) {
-	if o.IsCompressed() {
+	_, dedup := o.dedupBlock()
+	if o.IsCompressed() || dedup {
 		sizeStr, ok := o.UserDefined[ReservedMetadataPrefix+"actual-size"]
 		if !ok {
 			return -1, errInvalidDecompressedSize
@@ -668,20 +669,19 @@ func NewGetObjectReader(rs *HTTPRangeSpec, oi ObjectInfo, opts ObjectOptions) (
 				}
 				oi.Size = decLength
 			}
-			// Decompression reader.
-			s2Reader := s2.NewReader(inputReader)
-			// Apply the skipLen and limit on the decompressed stream.
-			if decOff > 0 {
-				if err = s2Reader.Skip(decOff); err != nil {
-					// Call the cleanup funcs
-					for i := len(cFns) - 1; i >= 0; i-- {
-						cFns[i]()
-					}
-					return nil, err
+			// Decompression reader, applying the skipLen on the decompressed stream.
+			decompReader, decompClose, err := newDecompressReader(oi.UserDefined[ReservedMetadataPrefix+"compression"], inputReader, decOff)
+			if err != nil {
+				// Call the cleanup funcs
+				for i := len(cFns) - 1; i >= 0; i-- {
+					cFns[i]()
 				}
+				return nil, err
 			}
+			cFns = append([]func(){decompClose}, cFns...)
 
-			decReader := io.LimitReader(s2Reader, decLength)
+			// Apply the limit on the decompressed stream.
+			decReader := io.LimitReader(decompReader, decLength)
 			if decLength > compReadAheadSize {
 				rah, err := readahead.NewReaderSize(decReader, compReadAheadBuffers, compReadAheadBufSize)
 				if err == nil {
@@ -901,10 +901,22 @@ func init() {
 // properly, because we do not wish to create an object even if
 // client closed the stream prematurely.
 func newS2CompressReader(r io.Reader, on int64) io.ReadCloser {
+	return newCompressReader(r, on, func(w io.Writer) (io.WriteCloser, error) {
+		return s2.NewWriter(w, compressOpts...), nil
+	})
+}
+
+// newCompressReader returns the data read from r compressed by the
+// compressor returned by newWriter, see newS2CompressReader.
+func newCompressReader(r io.Reader, on int64, newWriter func(w io.Writer) (io.WriteCloser, error)) io.ReadCloser {
 	pr, pw := io.Pipe()
 	// Copy input to compressor
 	go func() {
-		comp := s2.NewWriter(pw, compressOpts...)
+		comp, err := newWriter(pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
 		cn, err := io.Copy(comp, r)
 		if err != nil {
 			comp.Close()
@@ -1013,7 +1025,7 @@ func hasSpaceFor(di []*DiskInfo, size int64) bool {
 		}
 	}
 
-	// Make sure we can fit "size" on to the disk without getting above the diskFillFraction
+	// Make sure we can fit "size" on to the disk without getting above the disk high watermark
 	if available < uint64(size) {
 		return false
 	}
@@ -1022,6 +1034,6 @@ func hasSpaceFor(di []*DiskInfo, size int64) bool {
 	available -= uint64(size)
 
 	// wantLeft is how much space there at least must be left.
-	wantLeft := uint64(float64(total) * (1.0 - diskFillFraction))
+	wantLeft := uint64(float64(total) * (1.0 - globalAPIConfig.getDiskFillFraction()))
 	return available > wantLeft
 }
@@ -647,3 +647,27 @@ func TestS2CompressReader(t *testing.T) {
 		})
 	}
 }
+
+func TestHasSpaceForWatermark(t *testing.T) {
+	disks := []*DiskInfo{
+		{Total: 1000, Used: 850, Free: 150, FreeInodes: diskMinInodes},
+		{Total: 1000, Used: 850, Free: 150, FreeInodes: diskMinInodes},
+	}
+	setFraction := func(f float64) {
+		globalAPIConfig.mu.Lock()
+		globalAPIConfig.diskFillFraction = f
+		globalAPIConfig.mu.Unlock()
+	}
+	defer setFraction(globalAPIConfig.getDiskFillFraction())
+
+	setFraction(0.99)
+	// 10 bytes are written twice for erasure coding.
+	if !hasSpaceFor(disks, 10) {
+		t.Fatal("expected space below 99% filled")
+	}
+
+	setFraction(0.85)
+	if hasSpaceFor(disks, 10) {
+		t.Fatal("expected no space above 85% filled")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "context"
+
+// appendObject appends data to object, position must be the current
+// size of the object. Appending at position 0 to a missing object
+// creates it with opts.
+func appendObject(ctx context.Context, objAPI ObjectLayer, bucket, object string, position int64, data *PutObjReader, opts ObjectOptions) (ObjectInfo, error) {
+	// Versions are immutable, appending would change an existing one.
+	if globalBucketVersioningSys.Enabled(bucket) || globalBucketVersioningSys.Suspended(bucket) {
+		return ObjectInfo{}, NotImplemented{Message: "Appending to objects of versioned buckets is not supported"}
+	}
+
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return ObjectInfo{}, NotImplemented{}
+	}
+
+	oi, err := z.appendObject(ctx, bucket, object, position, data)
+	if !isErrObjectNotFound(err) || position != 0 {
+		return oi, err
+	}
+
+	// The object may be created concurrently, which is checked under
+	// the lock taken for writing it.
+	size := int64(-1)
+	opts.CheckPrecondFn = func(oi ObjectInfo) bool {
+		if oi.Name != "" {
+			size = oi.Size
+			return true
+		}
+		return false
+	}
+	oi, err = objAPI.PutObject(ctx, bucket, object, data, opts)
+	if _, ok := err.(PreConditionFailed); ok && size >= 0 {
+		return ObjectInfo{}, AppendPositionMismatch{
+			GenericError: GenericError{Bucket: bucket, Object: object},
+			Size:         size,
+		}
+	}
+	return oi, err
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func TestAppendObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		object string
+		chunks [][]byte
+	}{
+		// Small objects are inlined and rewritten on append.
+		{"small", [][]byte{[]byte("first line\n"), []byte("second line\n")}},
+		// Objects outgrowing the inline size are rewritten with their
+		// data stored in parts, which later appends extend.
+		{"grown", [][]byte{[]byte("first line\n"), bytes.Repeat([]byte("a"), 1<<20), []byte("b")}},
+		// Large objects get a part per append.
+		{"large", [][]byte{bytes.Repeat([]byte("a"), 1<<20), bytes.Repeat([]byte("b"), 1<<20), []byte("c")}},
+	}
+
+	for i, tc := range testCases {
+		var expected []byte
+		for _, chunk := range tc.chunks {
+			oi, err := appendObject(ctx, objLayer, bucket, tc.object, int64(len(expected)),
+				mustGetPutObjReader(t, bytes.NewReader(chunk), int64(len(chunk)), "", ""), ObjectOptions{})
+			if err != nil {
+				t.Fatalf("Test %d: %v", i+1, err)
+			}
+			expected = append(expected, chunk...)
+			if oi.Size != int64(len(expected)) {
+				t.Fatalf("Test %d: expected size %d, got %d", i+1, len(expected), oi.Size)
+			}
+		}
+
+		gr, err := objLayer.GetObjectNInfo(ctx, bucket, tc.object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(got, expected) {
+			t.Fatalf("Test %d: appended content differs", i+1)
+		}
+
+		chunk := []byte("stale")
+		_, err = appendObject(ctx, objLayer, bucket, tc.object, 1,
+			mustGetPutObjReader(t, bytes.NewReader(chunk), int64(len(chunk)), "", ""), ObjectOptions{})
+		var mismatch AppendPositionMismatch
+		if !errors.As(err, &mismatch) || mismatch.Size != int64(len(expected)) {
+			t.Fatalf("Test %d: expected position mismatch, got %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+)
+
+// Maximum number of source objects of a compose.
+const maxComposeSources = 32
+
+// ComposeSource - an object concatenated by a compose.
+type ComposeSource struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId,omitempty"`
+}
+
+// ComposeObjectRequest - the source objects of a compose, in order.
+type ComposeObjectRequest struct {
+	XMLName xml.Name        `xml:"Compose"`
+	Sources []ComposeSource `xml:"Source"`
+}
+
+// composeObject creates object in bucket from the concatenation of the
+// sources, the parts of each source becoming parts of a multipart upload
+// which is assembled server side. On erasure backends the part files of
+// a source stored in the same erasure set and alike the upload are hard
+// linked into it, other sources are copied as one part. Like any
+// multipart object all but the last source must be at least
+// globalMinPartSize large, so must all parts of a linked source but
+// the last part of the last source.
+func composeObject(ctx context.Context, objAPI ObjectLayer, bucket, object string, sources []ComposeSource, opts ObjectOptions) (ObjectInfo, error) {
+	uploadID, err := objAPI.NewMultipartUpload(ctx, bucket, object, opts)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	z, linkable := objAPI.(*erasureServerPools)
+	parts := make([]CompletePart, 0, len(sources))
+	partID := 1
+	for i, src := range sources {
+		last := i == len(sources)-1
+		var pis []PartInfo
+		err = errCopyNeedsRewrite
+		if linkable {
+			pis, err = z.linkObjectParts(ctx, bucket, src.Key, src.VersionID, bucket, object, uploadID, partID, last)
+		}
+		if err == errCopyNeedsRewrite {
+			var pi PartInfo
+			if pi, err = composePart(ctx, objAPI, bucket, object, uploadID, partID, src, last); err == nil {
+				pis = []PartInfo{pi}
+			}
+		}
+		if err != nil {
+			logger.LogIf(ctx, objAPI.AbortMultipartUpload(ctx, bucket, object, uploadID, ObjectOptions{}))
+			return ObjectInfo{}, err
+		}
+		for _, pi := range pis {
+			parts = append(parts, CompletePart{PartNumber: pi.PartNumber, ETag: pi.ETag})
+		}
+		partID += len(pis)
+	}
+
+	objInfo, err := objAPI.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+	if err != nil {
+		logger.LogIf(ctx, objAPI.AbortMultipartUpload(ctx, bucket, object, uploadID, ObjectOptions{}))
+		return ObjectInfo{}, err
+	}
+	return objInfo, nil
+}
+
+// composePart uploads the data of src as part partID of uploadID.
+func composePart(ctx context.Context, objAPI ObjectLayer, bucket, object, uploadID string, partID int, src ComposeSource, last bool) (PartInfo, error) {
+	if isMaxPartID(partID) {
+		return PartInfo{}, NotImplemented{
+			Message: fmt.Sprintf("Composed objects are limited to %d parts", globalMaxPartID),
+		}
+	}
+
+	gr, err := objAPI.GetObjectNInfo(ctx, bucket, src.Key, nil, nil, readLock, ObjectOptions{
+		VersionID: src.VersionID,
+	})
+	if err != nil {
+		return PartInfo{}, err
+	}
+	defer gr.Close()
+
+	srcInfo := gr.ObjInfo
+	if srcInfo.DeleteMarker {
+		return PartInfo{}, ObjectNotFound{Bucket: bucket, Object: src.Key}
+	}
+	if isQuarantined(srcInfo) {
+		return PartInfo{}, errObjectQuarantined
+	}
+	if _, encrypted := crypto.IsEncrypted(srcInfo.UserDefined); encrypted {
+		return PartInfo{}, NotImplemented{
+			Message: "Composing encrypted objects is not supported",
+		}
+	}
+
+	// Compressed objects are read back decompressed.
+	size, err := srcInfo.GetActualSize()
+	if err != nil {
+		return PartInfo{}, err
+	}
+	if !last && !isMinAllowedPartSize(size) {
+		return PartInfo{}, PartTooSmall{
+			PartNumber: partID,
+			PartSize:   size,
+			PartETag:   srcInfo.ETag,
+		}
+	}
+
+	hr, err := hash.NewReader(gr, size, "", "", size)
+	if err != nil {
+		return PartInfo{}, err
+	}
+	return objAPI.PutObjectPart(ctx, bucket, object, uploadID, partID, NewPutObjReader(hr), ObjectOptions{})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComposeObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	first := bytes.Repeat([]byte("a"), globalMinPartSize)
+	second := []byte("tail")
+	for object, data := range map[string][]byte{"chunk-1": first, "chunk-2": second} {
+		if _, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources := []ComposeSource{{Key: "chunk-1"}, {Key: "chunk-2"}}
+	oi, err := composeObject(ctx, objLayer, bucket, "artifact", sources, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oi.Size != int64(len(first)+len(second)) {
+		t.Fatalf("expected composed size %d, got %d", len(first)+len(second), oi.Size)
+	}
+	gr, err := objLayer.GetObjectNInfo(ctx, bucket, "artifact", nil, nil, readLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, append(append([]byte{}, first...), second...)) {
+		t.Fatal("composed object does not match its sources")
+	}
+
+	// The first source is linked into the composed object, the inlined
+	// second one is copied.
+	for _, dir := range fsDirs {
+		linked, _ := filepath.Glob(filepath.Join(dir, bucket, "artifact", "*", "part.1"))
+		source, _ := filepath.Glob(filepath.Join(dir, bucket, "chunk-1", "*", "part.1"))
+		if len(linked) != 1 || len(source) != 1 {
+			t.Fatalf("%s: expected one part file of each object, got %v %v", dir, linked, source)
+		}
+		li, err := os.Stat(linked[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		si, err := os.Stat(source[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !os.SameFile(li, si) {
+			t.Fatalf("%s: expected the part of the composed object to be linked", dir)
+		}
+	}
+
+	// All parts of a multipart source are linked, the composed object
+	// survives removing its sources.
+	sources = []ComposeSource{{Key: "chunk-1"}, {Key: "chunk-1"}}
+	if _, err = composeObject(ctx, objLayer, bucket, "double", sources, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sources = []ComposeSource{{Key: "double"}, {Key: "chunk-2"}}
+	if oi, err = composeObject(ctx, objLayer, bucket, "triple", sources, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(oi.Parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(oi.Parts))
+	}
+	for _, object := range []string{"chunk-1", "double"} {
+		if _, err = objLayer.DeleteObject(ctx, bucket, object, ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gr, err = objLayer.GetObjectNInfo(ctx, bucket, "triple", nil, nil, readLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, append(append(append([]byte{}, first...), first...), second...)) {
+		t.Fatal("composed object does not match its sources")
+	}
+
+	// Only the last source may be smaller than the minimum part size.
+	sources = []ComposeSource{{Key: "chunk-2"}, {Key: "artifact"}}
+	if _, err = composeObject(ctx, objLayer, bucket, "artifact-2", sources, ObjectOptions{}); err == nil {
+		t.Fatal("expected a small leading source to be rejected")
+	} else if _, ok := err.(PartTooSmall); !ok {
+		t.Fatalf("expected part too small, got %v", err)
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "artifact-2", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected failed compose to leave no object, got %v", err)
+	}
+}
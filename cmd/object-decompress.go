@@ -0,0 +1,153 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+// isDecompressRequested returns whether objects stored gzip compressed
+// should be decompressed for r, unless its client accepts gzip anyways.
+func isDecompressRequested(r *http.Request) bool {
+	v := r.Header.Get(xhttp.MinIODecompress)
+	if v == "" {
+		v = r.URL.Query().Get(strings.ToLower(xhttp.MinIODecompress))
+	}
+	if ok, _ := strconv.ParseBool(v); !ok {
+		return false
+	}
+	return !acceptsGzip(r.Header.Get(xhttp.AcceptEncoding))
+}
+
+// acceptsGzip returns whether the Accept-Encoding header value accepts
+// gzip encoded content.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(coding, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "gzip") {
+			continue
+		}
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if qv, err := strconv.ParseFloat(q[2:], 64); err == nil && qv == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isGzipObject returns whether the object is stored gzip compressed,
+// by its content encoding, content type or name.
+func isGzipObject(oi ObjectInfo) bool {
+	if strings.EqualFold(oi.ContentEncoding, "gzip") {
+		return true
+	}
+	switch strings.ToLower(oi.ContentType) {
+	case "application/gzip", "application/x-gzip":
+		return true
+	}
+	return strings.HasSuffix(strings.ToLower(oi.Name), ".gz")
+}
+
+// decompressedRange returns the range rs of the decompressed content
+// of an object, nil for the whole content. The decompressed size being
+// unknown, only ranges with an end are supported, ranges from the start
+// are the whole content.
+func decompressedRange(rs *HTTPRangeSpec) (*HTTPRangeSpec, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	if rs.IsSuffixLength || (rs.End < 0 && rs.Start > 0) {
+		return nil, errInvalidRange
+	}
+	if rs.End < 0 {
+		return nil, nil
+	}
+	return rs, nil
+}
+
+// newGzipDecompressReader returns the decompressed content of r in the
+// range rs, see decompressedRange.
+func newGzipDecompressReader(r io.Reader, rs *HTTPRangeSpec) (io.Reader, error) {
+	rs, err := decompressedRange(rs)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		if err == gzip.ErrHeader || err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = errInvalidObjectEncoding
+		}
+		return nil, err
+	}
+	if rs == nil {
+		return zr, nil
+	}
+	if rs.Start > 0 {
+		if _, err = io.CopyN(ioutil.Discard, zr, rs.Start); err != nil {
+			if err == io.EOF {
+				err = errInvalidRange
+			}
+			return nil, err
+		}
+	}
+	return io.LimitReader(zr, rs.End-rs.Start+1), nil
+}
+
+// setDecompressedHeaders replaces the object headers describing the
+// stored content by those of the decompressed content in the range rs,
+// for GET and HEAD alike. The decompressed length is not known.
+func setDecompressedHeaders(w http.ResponseWriter, objInfo ObjectInfo, rs *HTTPRangeSpec) {
+	h := w.Header()
+	h.Del(xhttp.ContentLength)
+	h.Del(xhttp.ContentEncoding)
+	h.Del(xhttp.ContentRange)
+	if objInfo.ETag != "" {
+		h.Set(xhttp.ETag, "W/\""+objInfo.ETag+"\"")
+	}
+	switch strings.ToLower(objInfo.ContentType) {
+	case "application/gzip", "application/x-gzip":
+		h.Set(xhttp.ContentType, "application/octet-stream")
+	}
+	if rs != nil && rs.End >= 0 {
+		h.Set(xhttp.ContentRange, fmt.Sprintf("bytes %d-%d/*", rs.Start, rs.End))
+	}
+}
+
+// newRangeReader returns the range rs of r holding size bytes.
+func newRangeReader(r io.Reader, size int64, rs *HTTPRangeSpec) (io.Reader, error) {
+	off, length, err := rs.GetOffsetLength(size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.CopyN(ioutil.Discard, r, off); err != nil {
+		return nil, err
+	}
+	return io.LimitReader(r, length), nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minio/minio/internal/auth"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestIsDecompressRequested(t *testing.T) {
+	testCases := []struct {
+		url            string
+		decompress     string
+		acceptEncoding string
+		want           bool
+	}{
+		{url: "/bucket/object.gz"},
+		{url: "/bucket/object.gz", decompress: "true", want: true},
+		{url: "/bucket/object.gz?x-minio-decompress=true", want: true},
+		{url: "/bucket/object.gz", decompress: "true", acceptEncoding: "identity", want: true},
+		{url: "/bucket/object.gz", decompress: "true", acceptEncoding: "br, gzip;q=0", want: true},
+		{url: "/bucket/object.gz", decompress: "true", acceptEncoding: "deflate, GZIP;q=0.5"},
+		{url: "/bucket/object.gz", decompress: "false"},
+	}
+	for i, tc := range testCases {
+		r, err := http.NewRequest(http.MethodGet, "http://localhost:9000"+tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.decompress != "" {
+			r.Header.Set(xhttp.MinIODecompress, tc.decompress)
+		}
+		if tc.acceptEncoding != "" {
+			r.Header.Set(xhttp.AcceptEncoding, tc.acceptEncoding)
+		}
+		if got := isDecompressRequested(r); got != tc.want {
+			t.Errorf("case %d: expected %v, got %v", i, tc.want, got)
+		}
+	}
+}
+
+func TestGzipDecompressReader(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("0123456789"))
+	zw.Close()
+
+	testCases := []struct {
+		rs   *HTTPRangeSpec
+		want string
+		err  error
+	}{
+		{rs: nil, want: "0123456789"},
+		{rs: &HTTPRangeSpec{Start: 0, End: -1}, want: "0123456789"},
+		{rs: &HTTPRangeSpec{Start: 2, End: 4}, want: "234"},
+		{rs: &HTTPRangeSpec{Start: 8, End: 20}, want: "89"},
+		{rs: &HTTPRangeSpec{Start: 20, End: 30}, err: errInvalidRange},
+		{rs: &HTTPRangeSpec{Start: 2, End: -1}, err: errInvalidRange},
+		{rs: &HTTPRangeSpec{IsSuffixLength: true, Start: -2}, err: errInvalidRange},
+	}
+	for i, tc := range testCases {
+		r, err := newGzipDecompressReader(bytes.NewReader(buf.Bytes()), tc.rs)
+		if err != tc.err {
+			t.Fatalf("case %d: expected error %v, got %v", i, tc.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("case %d: expected %q, got %q", i, tc.want, got)
+		}
+	}
+
+	if _, err := newGzipDecompressReader(bytes.NewReader([]byte("not gzip")), nil); err != errInvalidObjectEncoding {
+		t.Errorf("expected a non gzip object to fail, got %v", err)
+	}
+}
+
+func TestDecompressedHeadObject(t *testing.T) {
+	ExecObjectLayerAPITest(t, testDecompressedHeadObject, []string{"GetObject", "HeadObject"})
+}
+
+// testDecompressedHeadObject checks that HEAD describes a decompressed
+// object the same as GET.
+func testDecompressedHeadObject(obj ObjectLayer, instanceType, bucketName string, apiRouter http.Handler,
+	credentials auth.Credentials, t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("0123456789"))
+	zw.Close()
+
+	objectName := "object.gz"
+	_, err := obj.PutObject(GlobalContext, bucketName, objectName, mustGetPutObjReader(t, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "", ""),
+		ObjectOptions{UserDefined: map[string]string{"content-type": "application/gzip"}})
+	if err != nil {
+		t.Fatalf("%s: %v", instanceType, err)
+	}
+
+	for _, byteRange := range []string{"", "bytes=0-", "bytes=2-4", "bytes=2-"} {
+		recs := make(map[string]*httptest.ResponseRecorder)
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			rec := httptest.NewRecorder()
+			req, err := newTestSignedRequestV4(method, getGetObjectURL("", bucketName, objectName),
+				0, nil, credentials.AccessKey, credentials.SecretKey, nil)
+			if err != nil {
+				t.Fatalf("%s: %v", instanceType, err)
+			}
+			req.Header.Set(xhttp.MinIODecompress, "true")
+			if byteRange != "" {
+				req.Header.Set("Range", byteRange)
+			}
+			apiRouter.ServeHTTP(rec, req)
+			recs[method] = rec
+		}
+		get, head := recs[http.MethodGet], recs[http.MethodHead]
+		if get.Code != head.Code {
+			t.Fatalf("%s: range %q: GET returned %d, HEAD %d", instanceType, byteRange, get.Code, head.Code)
+		}
+		if get.Code != http.StatusOK && get.Code != http.StatusPartialContent {
+			continue
+		}
+		for _, key := range []string{xhttp.ContentLength, xhttp.ContentRange, xhttp.ContentType, xhttp.ContentEncoding, xhttp.ETag} {
+			if g, h := get.Header().Get(key), head.Header().Get(key); g != h {
+				t.Errorf("%s: range %q: GET returned %s %q, HEAD %q", instanceType, byteRange, key, g, h)
+			}
+		}
+	}
+}
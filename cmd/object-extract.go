@@ -0,0 +1,286 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/tags"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const (
+	// Maximum size of a manifest carrying per-entry metadata and tags of
+	// an extracted archive.
+	maxExtractManifestSize = 8 << 20
+
+	// Maximum number of failed entries listed in an extract result, any
+	// further failures are only counted.
+	maxExtractErrors = 1000
+)
+
+var globalExtractTracker = newExtractTracker()
+
+// extractManifest holds the metadata and tags to set on extracted
+// objects, by object name.
+type extractManifest map[string]map[string]string
+
+// extractManifestEntry is an entry of a manifest object, metadata takes
+// the same keys as PutObject headers.
+type extractManifestEntry struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// loadExtractManifest reads a JSON manifest object, mapping the names
+// of extracted objects to their metadata and tags.
+func loadExtractManifest(ctx context.Context, objAPI ObjectLayer, bucket, object string, opts ObjectOptions) (extractManifest, error) {
+	gr, err := objAPI.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	if gr.ObjInfo.Size > maxExtractManifestSize {
+		return nil, errInvalidArgument
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(gr, maxExtractManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseExtractManifest(ctx, data)
+}
+
+func parseExtractManifest(ctx context.Context, data []byte) (extractManifest, error) {
+	var entries map[string]extractManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	manifest := make(extractManifest, len(entries))
+	for name, entry := range entries {
+		header := make(textproto.MIMEHeader, len(entry.Metadata))
+		for k, v := range entry.Metadata {
+			header.Set(k, v)
+		}
+		metadata := make(map[string]string)
+		if err := extractMetadataFromMime(ctx, header, metadata); err != nil {
+			return nil, err
+		}
+		// The storage class and tags of an entry are not taken from
+		// its metadata, the request header sets the storage class.
+		for k := range metadata {
+			if equals(k, xhttp.AmzStorageClass, xhttp.AmzObjectTagging, xhttp.AmzBucketReplicationStatus) {
+				delete(metadata, k)
+			}
+		}
+		if len(entry.Tags) > 0 {
+			t, err := tags.MapToObjectTags(entry.Tags)
+			if err != nil {
+				return nil, err
+			}
+			metadata[xhttp.AmzObjectTagging] = t.String()
+		}
+		manifest[trimLeadingSlash(name)] = metadata
+	}
+	return manifest, nil
+}
+
+// hasTags returns whether any entry of the manifest is tagged.
+func (m extractManifest) hasTags() bool {
+	for _, metadata := range m {
+		if _, ok := metadata[xhttp.AmzObjectTagging]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractProgress - progress of an archive being extracted.
+type ExtractProgress struct {
+	Node      string    `json:"node,omitempty"`
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	StartedAt time.Time `json:"startedAt"`
+	Extracted int       `json:"extracted"`
+	Failed    int       `json:"failed"`
+	Bytes     int64     `json:"bytes"`
+}
+
+// ExtractError - an archive entry which could not be extracted.
+type ExtractError struct {
+	Name    string `xml:"Name"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// ExtractResult - response of an extracting PutObject requesting it,
+// listing the entries which could not be extracted. Error is set if the
+// archive could not be read to its end.
+type ExtractResult struct {
+	XMLName   xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ExtractResult" json:"-"`
+	Extracted int      `xml:"Extracted"`
+	Failed    int      `xml:"Failed"`
+	Bytes     int64    `xml:"Bytes"`
+
+	Errors []ExtractError `xml:"FailedEntry,omitempty"`
+	Error  *ExtractError  `xml:"Error,omitempty"`
+}
+
+// extractError fails a single archive entry with an API error.
+type extractError struct {
+	apiErr APIError
+}
+
+func (e extractError) Error() string { return e.apiErr.Description }
+
+func toExtractAPIError(ctx context.Context, err error) APIError {
+	if e, ok := err.(extractError); ok {
+		return e.apiErr
+	}
+	return toAPIError(ctx, err)
+}
+
+// extractReport accounts the entries of an archive being extracted, it
+// is read concurrently to report progress.
+type extractReport struct {
+	mu       sync.Mutex
+	progress ExtractProgress
+	errs     []ExtractError
+	firstErr error
+}
+
+func (r *extractReport) done(size int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.Extracted++
+	r.progress.Bytes += size
+}
+
+func (r *extractReport) fail(ctx context.Context, name string, err error) {
+	apiErr := toExtractAPIError(ctx, err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.firstErr == nil {
+		r.firstErr = err
+	}
+	r.progress.Failed++
+	if len(r.errs) < maxExtractErrors {
+		r.errs = append(r.errs, ExtractError{Name: name, Code: apiErr.Code, Message: apiErr.Description})
+	}
+}
+
+func (r *extractReport) snapshot() ExtractProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress
+}
+
+// err returns the error of the first failed entry.
+func (r *extractReport) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.firstErr
+}
+
+// result returns the extract result, err is the error reading the
+// archive if any.
+func (r *extractReport) result(ctx context.Context, err error) ExtractResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := ExtractResult{
+		Extracted: r.progress.Extracted,
+		Failed:    r.progress.Failed,
+		Bytes:     r.progress.Bytes,
+		Errors:    append([]ExtractError(nil), r.errs...),
+	}
+	if err != nil {
+		apiErr := toAPIError(ctx, err)
+		res.Error = &ExtractError{Code: apiErr.Code, Message: apiErr.Description}
+	}
+	return res
+}
+
+// extractTracker tracks the archives being extracted on this node.
+type extractTracker struct {
+	mu     sync.Mutex
+	active map[*extractReport]struct{}
+}
+
+func newExtractTracker() *extractTracker {
+	return &extractTracker{active: make(map[*extractReport]struct{})}
+}
+
+func (t *extractTracker) start(bucket, object string) *extractReport {
+	r := &extractReport{progress: ExtractProgress{
+		Bucket:    bucket,
+		Object:    object,
+		StartedAt: UTCNow(),
+	}}
+	t.mu.Lock()
+	t.active[r] = struct{}{}
+	t.mu.Unlock()
+	return r
+}
+
+func (t *extractTracker) finish(r *extractReport) {
+	t.mu.Lock()
+	delete(t.active, r)
+	t.mu.Unlock()
+}
+
+// list returns the progress of all archives being extracted, oldest first.
+func (t *extractTracker) list() []ExtractProgress {
+	t.mu.Lock()
+	progress := make([]ExtractProgress, 0, len(t.active))
+	for r := range t.active {
+		progress = append(progress, r.snapshot())
+	}
+	t.mu.Unlock()
+
+	node := globalLocalNodeName
+	for i := range progress {
+		progress[i].Node = node
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].StartedAt.Before(progress[j].StartedAt)
+	})
+	return progress
+}
+
+// isExtractResultRequested returns whether the request asks for the
+// ExtractResult response, instead of failing with the first error.
+func isExtractResultRequested(h http.Header) bool {
+	return h.Get(xhttp.MinIOExtractResult) == "true"
+}
+
+// isExtractManifestRequested returns the manifest object named by the
+// request, relative to the bucket.
+func isExtractManifestRequested(h http.Header) (string, bool) {
+	manifest := strings.TrimSpace(h.Get(xhttp.AmzSnowballManifest))
+	return trimLeadingSlash(manifest), manifest != ""
+}
@@ -238,6 +238,32 @@ func isETagEqual(left, right string) bool {
 	return canonicalizeETag(left) == canonicalizeETag(right)
 }
 
+// putPrecondFn returns the precondition of a write conditional on
+// If-Match or If-None-Match, nil for unconditional writes. The returned
+// function reports true when the write must be rejected, it is passed
+// an empty ObjectInfo when the object does not exist.
+func putPrecondFn(r *http.Request) CheckPreconditionFn {
+	ifMatch := r.Header.Get(xhttp.IfMatch)
+	ifNoneMatch := r.Header.Get(xhttp.IfNoneMatch)
+	if ifMatch == "" && ifNoneMatch == "" {
+		return nil
+	}
+	return func(oi ObjectInfo) bool {
+		exists := oi.Name != ""
+		if ifMatch != "" {
+			if !exists || (ifMatch != "*" && !isETagEqual(oi.ETag, ifMatch)) {
+				return true
+			}
+		}
+		if ifNoneMatch != "" && exists {
+			if ifNoneMatch == "*" || isETagEqual(oi.ETag, ifNoneMatch) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // setPutObjHeaders sets all the necessary headers returned back
 // upon a success Put/Copy/CompleteMultipart/Delete requests
 // to activate delete only headers set delete as true
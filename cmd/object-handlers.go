@@ -29,6 +29,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -77,6 +78,8 @@ var supportedHeadGetReqParams = map[string]string{
 const (
 	compressionAlgorithmV1 = "golang/snappy/LZ77"
 	compressionAlgorithmV2 = "klauspost/compress/s2"
+	// Selected by a bucket compression config.
+	compressionAlgorithmZstd = "klauspost/compress/zstd"
 
 	// When an upload exceeds encryptBufferThreshold ...
 	encryptBufferThreshold = 1 << 20
@@ -206,7 +209,16 @@ func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r
 			End:            offset + length,
 		}
 
-		return getObjectNInfo(ctx, bucket, object, rs, r.Header, readLock, opts)
+		gr, err := getObjectNInfo(ctx, bucket, object, rs, r.Header, readLock, opts)
+		if err != nil {
+			return nil, err
+		}
+		// The version read may have been quarantined meanwhile.
+		if isQuarantined(gr.ObjInfo) {
+			gr.Close()
+			return nil, errObjectQuarantined
+		}
+		return gr, nil
 	}
 
 	objInfo, err := getObjectInfo(ctx, bucket, object, opts)
@@ -223,6 +235,12 @@ func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r
 		return
 	}
 
+	// Quarantined objects are not readable until released.
+	if isQuarantined(objInfo) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectQuarantined), r.URL)
+		return
+	}
+
 	// filter object lock metadata if permission does not permit
 	getRetPerms := checkRequestAuthType(ctx, r, policy.GetObjectRetentionAction, bucket, object)
 	legalHoldPerms := checkRequestAuthType(ctx, r, policy.GetObjectLegalHoldAction, bucket, object)
@@ -299,6 +317,8 @@ func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r
 
 	s3Select.Evaluate(w)
 
+	globalAccessTracker.markAccessed(bucket, object)
+
 	// Notify object accessed via a GET request.
 	sendEvent(eventArgs{
 		EventName:    event.ObjectAccessedGet,
@@ -311,6 +331,229 @@ func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r
 	})
 }
 
+// SelectObjectsContentHandler - POST Bucket?select&select-type=2
+// ----------
+// MinIO extension API, runs an SQL expression like SelectObjectContent
+// over all objects listed under the prefix query parameter, or named
+// one per line in the manifest object, streaming their concatenated
+// results. Objects which cannot be read are reported in ObjectError
+// messages instead of failing the request.
+func (api objectAPIHandlers) SelectObjectsContentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "SelectObjects")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if crypto.S3.IsRequested(r.Header) || crypto.S3KMS.IsRequested(r.Header) { // If SSE-S3 or SSE-KMS present -> AWS fails with undefined error
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrBadRequest), r.URL)
+		return
+	}
+
+	if _, ok := crypto.IsRequested(r.Header); ok && !objectAPI.IsEncryptionSupported() {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrBadRequest), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	prefix, manifest := r.Form.Get("prefix"), r.Form.Get("manifest")
+	if manifest != "" && prefix != "" {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var keys *selectObjectKeys
+	if manifest != "" {
+		if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, manifest); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+		opts, err := getOpts(ctx, r, bucket, manifest)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		if keys, err = newSelectManifestKeys(ctx, objectAPI, bucket, manifest, opts); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+	} else {
+		if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+		keys = newSelectPrefixKeys(ctx, objectAPI, bucket, prefix)
+	}
+
+	// Get request range.
+	if r.Header.Get(xhttp.Range) != "" {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrUnsupportedRangeHeader), r.URL)
+		return
+	}
+
+	if r.ContentLength <= 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEmptyRequestBody), r.URL)
+		return
+	}
+
+	s3Select, err := s3select.NewS3Select(r.Body)
+	if err != nil {
+		if serr, ok := err.(s3select.SelectError); ok {
+			encodedErrorResponse := encodeResponse(APIErrorResponse{
+				Code:       serr.ErrorCode(),
+				Message:    serr.ErrorMessage(),
+				BucketName: bucket,
+				Resource:   r.URL.Path,
+				RequestID:  w.Header().Get(xhttp.AmzRequestID),
+				HostID:     globalDeploymentID,
+			})
+			writeResponse(w, serr.HTTPStatusCode(), encodedErrorResponse, mimeXML)
+		} else {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		}
+		return
+	}
+	defer s3Select.Close()
+
+	getObjectNInfo := objectAPI.GetObjectNInfo
+	if api.CacheAPI() != nil {
+		getObjectNInfo = api.CacheAPI().GetObjectNInfo
+	}
+
+	s3Select.EvaluateObjects(w, func() (s3select.SelectObject, bool) {
+		object, ok, err := keys.next()
+		if !ok {
+			return s3select.SelectObject{}, false
+		}
+		if err != nil {
+			return s3select.SelectObject{Key: object, Err: selectObjectError{toAPIError(ctx, err)}}, true
+		}
+		if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, object); s3Error != ErrNone {
+			return s3select.SelectObject{Key: object, Err: selectObjectError{errorCodes.ToAPIErr(s3Error)}}, true
+		}
+		opts, err := getOpts(ctx, r, bucket, object)
+		if err != nil {
+			return s3select.SelectObject{Key: object, Err: selectObjectError{toAPIError(ctx, err)}}, true
+		}
+		return s3select.SelectObject{
+			Key: object,
+			GetReader: func(offset, length int64) (io.ReadCloser, error) {
+				isSuffixLength := false
+				if offset < 0 {
+					isSuffixLength = true
+				}
+
+				if length > 0 {
+					length--
+				}
+
+				rs := &HTTPRangeSpec{
+					IsSuffixLength: isSuffixLength,
+					Start:          offset,
+					End:            offset + length,
+				}
+
+				gr, err := getObjectNInfo(ctx, bucket, object, rs, r.Header, readLock, opts)
+				if err != nil {
+					return nil, selectObjectError{toAPIError(ctx, err)}
+				}
+				if isQuarantined(gr.ObjInfo) {
+					gr.Close()
+					return nil, selectObjectError{errorCodes.ToAPIErr(ErrObjectQuarantined)}
+				}
+				return gr, nil
+			},
+		}, true
+	})
+}
+
+// ExportObjectsHandler - GET Bucket?export&prefix={prefix}&compression={zstd}
+// ----------
+// MinIO extension API, streams all objects listed under prefix as a
+// single tar archive, optionally zstd compressed, generated on the fly.
+// Objects which cannot be read are skipped and listed in a last archive
+// entry, since the response has already started.
+func (api objectAPIHandlers) ExportObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ExportObjects")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if _, ok := crypto.IsRequested(r.Header); ok && !objectAPI.IsEncryptionSupported() {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrBadRequest), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	prefix, compression := r.Form.Get("prefix"), r.Form.Get("compression")
+	if compression != "" && compression != exportCompressionZstd {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	getObjectNInfo := objectAPI.GetObjectNInfo
+	if api.CacheAPI() != nil {
+		getObjectNInfo = api.CacheAPI().GetObjectNInfo
+	}
+
+	name := bucket
+	if p := strings.Trim(prefix, SlashSeparator); p != "" {
+		name = path.Base(p)
+	}
+	contentType, ext := "application/x-tar", ".tar"
+	if compression == exportCompressionZstd {
+		contentType, ext = "application/zstd", ".tar.zst"
+	}
+	w.Header().Set(xhttp.ContentType, contentType)
+	w.Header().Set(xhttp.ContentDisposition, fmt.Sprintf("attachment; filename=%q", name+ext))
+	w.WriteHeader(http.StatusOK)
+
+	keys := newSelectPrefixKeys(ctx, objectAPI, bucket, prefix)
+	err := exportObjects(ctx, w, compression, keys, func(object string) (*GetObjectReader, error) {
+		if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, object); s3Error != ErrNone {
+			return nil, errors.New(errorCodes.ToAPIErr(s3Error).Description)
+		}
+		opts, err := getOpts(ctx, r, bucket, object)
+		if err != nil {
+			return nil, err
+		}
+		gr, err := getObjectNInfo(ctx, bucket, object, nil, r.Header, readLock, opts)
+		if err != nil {
+			return nil, err
+		}
+		if isQuarantined(gr.ObjInfo) {
+			gr.Close()
+			return nil, errObjectQuarantined
+		}
+		globalAccessTracker.markAccessed(bucket, object)
+		return gr, nil
+	})
+	if err != nil && !xnet.IsNetworkOrHostDown(err, true) {
+		logger.LogIf(ctx, err)
+	}
+}
+
 func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI ObjectLayer, bucket, object string, w http.ResponseWriter, r *http.Request) {
 	if crypto.S3.IsRequested(r.Header) || crypto.S3KMS.IsRequested(r.Header) { // If SSE-S3 or SSE-KMS present -> AWS fails with undefined error
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrBadRequest), r.URL)
@@ -371,8 +614,19 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		getObjectNInfo = api.CacheAPI().GetObjectNInfo
 	}
 
-	// Get request range.
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		getObjectNInfo = snapshot.GetObjectNInfo
+	}
+
+	// Get request range, several ranges are served as a
+	// multipart/byteranges response.
 	var rs *HTTPRangeSpec
+	var ranges []*HTTPRangeSpec
 	var rangeErr error
 	rangeHeader := r.Header.Get(xhttp.Range)
 	if rangeHeader != "" {
@@ -382,7 +636,13 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 			return
 		}
 
-		rs, rangeErr = parseRequestRangeSpec(rangeHeader)
+		if strings.Contains(rangeHeader, ",") {
+			if ranges, rangeErr = parseRequestRangeSpecs(rangeHeader); rangeErr == nil {
+				rs = spanRange(ranges)
+			}
+		} else {
+			rs, rangeErr = parseRequestRangeSpec(rangeHeader)
+		}
 		// Handle only errInvalidRange. Ignore other
 		// parse error and treat it as regular Get
 		// request like Amazon S3.
@@ -395,8 +655,36 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		}
 	}
 
+	// The range of a decompressed object applies to its decompressed
+	// content, objects stored gzip compressed are then read whole.
+	decompress := opts.PartNumber == 0 && isDecompressRequested(r)
+	var decompressRange *HTTPRangeSpec
+	if decompress {
+		if len(ranges) > 1 {
+			// Several ranges of decompressed content are not
+			// supported, the whole object is served.
+			ranges, rs = nil, nil
+		}
+		decompressRange, rs = rs, nil
+	}
+
+	// Sequential ranged reads of buckets configured for read-ahead are
+	// served from the stripes prefetched after them. The readers are
+	// told apart by their authenticated access key and connection, not
+	// by headers a client can set.
+	if snapshot == nil && rs != nil && !crypto.SSEC.IsRequested(r.Header) {
+		if cfg := globalBucketMetadataSys.GetReadAheadConfig(bucket); cfg != nil && cfg.Enabled {
+			client := readAheadClient(getReqAccessCred(r, globalServerRegion).AccessKey, r.RemoteAddr)
+			getObjectNInfo = globalReadAhead.wrap(getObjectNInfo, objectAPI, cfg, client)
+		}
+	}
+
 	// Validate pre-conditions if any.
 	opts.CheckPrecondFn = func(oi ObjectInfo) bool {
+		// Objects hidden by a pending commit are replaced below.
+		if commitHidden(ctx, objectAPI, oi) {
+			return false
+		}
 		if objectAPI.IsEncryptionSupported() {
 			if _, err := DecryptObjectInfo(&oi, r); err != nil {
 				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -449,6 +737,33 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 
 	objInfo := gr.ObjInfo
 
+	// Objects of a pending multi-object commit are not visible yet,
+	// the version published before is served instead if any.
+	if commitHidden(ctx, objectAPI, objInfo) {
+		visible, err := commitVisibleVersion(ctx, objectAPI, bucket, object, opts)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		vopts := opts
+		vopts.VersionID = visible.VersionID
+		vgr, err := getObjectNInfo(ctx, bucket, object, rs, r.Header, readLock, vopts)
+		if err != nil {
+			if !isErrPreconditionFailed(err) {
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			}
+			return
+		}
+		defer vgr.Close()
+		gr, objInfo = vgr, vgr.ObjInfo
+	}
+
+	// Quarantined objects are not readable until released.
+	if isQuarantined(objInfo) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectQuarantined), r.URL)
+		return
+	}
+
 	// Automatically remove the object/version is an expiry lifecycle rule can be applied
 	if lc, err := globalLifecycleSys.Get(bucket); err == nil {
 		action := evalActionFromLifecycle(ctx, *lc, objInfo, false)
@@ -474,6 +789,25 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 	// filter object lock metadata if permission does not permit
 	objInfo.UserDefined = objectlock.FilterObjectLockMetadata(objInfo.UserDefined, getRetPerms != ErrNone, legalHoldPerms != ErrNone)
 
+	var body io.Reader = gr
+	switch {
+	case decompress && isGzipObject(objInfo):
+		if body, err = newGzipDecompressReader(gr, decompressRange); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		decompressRange, _ = decompressedRange(decompressRange)
+	case decompress:
+		// Not compressed, the range applies to the object as stored.
+		decompress, rs, decompressRange = false, decompressRange, nil
+		if rs != nil {
+			if body, err = newRangeReader(gr, objInfo.Size, rs); err != nil {
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+		}
+	}
+
 	// Set encryption response headers
 	if objectAPI.IsEncryptionSupported() {
 		switch kind, _ := crypto.IsEncrypted(objInfo.UserDefined); kind {
@@ -495,6 +829,9 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	if decompress {
+		setDecompressedHeaders(w, objInfo, decompressRange)
+	}
 
 	// Set Parts Count Header
 	if opts.PartNumber > 0 && len(objInfo.Parts) > 0 {
@@ -503,15 +840,47 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 
 	setHeadGetRespHeaders(w, r.Form)
 
+	if len(ranges) > 1 {
+		// The ranges are read from the span of the object holding
+		// them all, which was read under one lock.
+		size, err := objInfo.GetActualSize()
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		spanStart, _, err := rs.GetOffsetLength(size)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		merged, err := mergeByteRanges(ranges, size)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		if len(merged) == 1 {
+			br := merged[0]
+			body = newSpanRangeReader(body, br.start-spanStart, br.length)
+			w.Header().Set(xhttp.ContentRange, fmt.Sprintf("bytes %d-%d/%d", br.start, br.start+br.length-1, size))
+			w.Header().Set(xhttp.ContentLength, strconv.FormatInt(br.length, 10))
+		} else {
+			mr := newMultiRangeReader(body, spanStart, merged, size, w.Header().Get(xhttp.ContentType))
+			body = mr
+			w.Header().Del(xhttp.ContentRange)
+			w.Header().Set(xhttp.ContentType, "multipart/byteranges; boundary="+mr.boundary)
+			w.Header().Set(xhttp.ContentLength, strconv.FormatInt(mr.size, 10))
+		}
+	}
+
 	statusCodeWritten := false
 	httpWriter := ioutil.WriteOnClose(w)
-	if rs != nil || opts.PartNumber > 0 {
+	if rs != nil || decompressRange != nil || opts.PartNumber > 0 {
 		statusCodeWritten = true
 		w.WriteHeader(http.StatusPartialContent)
 	}
 
 	// Write object content to response body
-	if _, err = io.Copy(httpWriter, gr); err != nil {
+	if _, err = io.Copy(httpWriter, body); err != nil {
 		if !httpWriter.HasWritten() && !statusCodeWritten {
 			// write error response only if no data or headers has been written to client yet
 			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -534,6 +903,8 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		return
 	}
 
+	globalAccessTracker.markAccessed(bucket, object)
+
 	// Notify object accessed via a GET request.
 	sendEvent(eventArgs{
 		EventName:    event.ObjectAccessedGet,
@@ -628,6 +999,15 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 		return
 	}
 
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
+		return
+	}
+	if snapshot != nil {
+		getObjectInfo = snapshot.GetObjectInfo
+	}
+
 	objInfo, err := getObjectInfo(ctx, bucket, object, opts)
 	if err != nil {
 		var (
@@ -662,6 +1042,21 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 		}
 	}
 
+	// Objects of a pending multi-object commit are not visible yet,
+	// the version published before is reported instead if any.
+	if commitHidden(ctx, objectAPI, objInfo) {
+		visible, err := commitVisibleVersion(ctx, objectAPI, bucket, object, opts)
+		if err == nil {
+			vopts := opts
+			vopts.VersionID = visible.VersionID
+			objInfo, err = getObjectInfo(ctx, bucket, object, vopts)
+		}
+		if err != nil {
+			writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
+			return
+		}
+	}
+
 	// Automatically remove the object/version is an expiry lifecycle rule can be applied
 	if lc, err := globalLifecycleSys.Get(bucket); err == nil {
 		action := evalActionFromLifecycle(ctx, *lc, objInfo, false)
@@ -722,6 +1117,18 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 		}
 	}
 
+	// Objects stored gzip compressed are described as a GET serves
+	// them decompressed, with the range of the decompressed content.
+	decompress := opts.PartNumber == 0 && isDecompressRequested(r) && isGzipObject(objInfo)
+	var decompressRange *HTTPRangeSpec
+	if decompress {
+		if decompressRange, err = decompressedRange(rs); err != nil {
+			writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
+			return
+		}
+		rs = nil
+	}
+
 	// Set encryption response headers
 	if objectAPI.IsEncryptionSupported() {
 		switch kind, _ := crypto.IsEncrypted(objInfo.UserDefined); kind {
@@ -749,6 +1156,9 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 		writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
 		return
 	}
+	if decompress {
+		setDecompressedHeaders(w, objInfo, decompressRange)
+	}
 
 	// Set Parts Count Header
 	if opts.PartNumber > 0 && len(objInfo.Parts) > 0 {
@@ -759,12 +1169,14 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 	setHeadGetRespHeaders(w, r.Form)
 
 	// Successful response.
-	if rs != nil || opts.PartNumber > 0 {
+	if rs != nil || decompressRange != nil || opts.PartNumber > 0 {
 		w.WriteHeader(http.StatusPartialContent)
 	} else {
 		w.WriteHeader(http.StatusOK)
 	}
 
+	globalAccessTracker.markAccessed(bucket, object)
+
 	// Notify object accessed via a HEAD request.
 	sendEvent(eventArgs{
 		EventName:    event.ObjectAccessedHead,
@@ -1025,6 +1437,11 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	fenced, err := fenceObjectWrite(ctx, objectAPI, dstBucket, dstObject, r.Header, &dstOpts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 	cpSrcDstSame := isStringEqual(pathJoin(srcBucket, srcObject), pathJoin(dstBucket, dstObject))
 
 	getObjectNInfo := objectAPI.GetObjectNInfo
@@ -1032,6 +1449,15 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		getObjectNInfo = api.CacheAPI().GetObjectNInfo
 	}
 
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, srcBucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		getObjectNInfo = snapshot.GetObjectNInfo
+	}
+
 	checkCopyPrecondFn := func(o ObjectInfo) bool {
 		if objectAPI.IsEncryptionSupported() {
 			if _, err := DecryptObjectInfo(&o, r); err != nil {
@@ -1077,6 +1503,11 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	defer gr.Close()
 	srcInfo := gr.ObjInfo
 
+	if isQuarantined(srcInfo) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectQuarantined), r.URL)
+		return
+	}
+
 	// maximum Upload size for object in a single CopyObject operation.
 	if isMaxObjectSize(srcInfo.Size) {
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEntityTooLarge), r.URL)
@@ -1119,17 +1550,17 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	var compressMetadata map[string]string
 	// No need to compress for remote etcd calls
 	// Pass the decompressed stream to such calls.
-	isDstCompressed := objectAPI.IsCompressionSupported() &&
-		isCompressible(r.Header, dstObject) &&
+	compressor, isDstCompressed := compressorFor(r.Header, dstBucket, dstObject)
+	isDstCompressed = objectAPI.IsCompressionSupported() && isDstCompressed &&
 		!isRemoteCopyRequired(ctx, srcBucket, dstBucket, objectAPI) && !cpSrcDstSame && !objectEncryption
 	if isDstCompressed {
 		compressMetadata = make(map[string]string, 2)
 		// Preserving the compression metadata.
-		compressMetadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
+		compressMetadata[ReservedMetadataPrefix+"compression"] = compressor.algorithm
 		compressMetadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(actualSize, 10)
 
 		reader = etag.NewReader(reader, nil)
-		s2c := newS2CompressReader(reader, actualSize)
+		s2c := compressor.newReader(reader, actualSize)
 		defer s2c.Close()
 		reader = etag.Wrap(s2c, reader)
 		length = -1
@@ -1317,6 +1748,11 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 
 	}
 
+	if err = applyBucketObjectDefaults(r, dstBucket, srcInfo.UserDefined); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	srcInfo.UserDefined = filterReplicationStatusMetadata(srcInfo.UserDefined)
 	srcInfo.UserDefined = objectlock.FilterObjectLockMetadata(srcInfo.UserDefined, true, true)
 	retPerms := isPutActionAllowed(ctx, getRequestAuthType(r), dstBucket, dstObject, r, iampolicy.PutObjectRetentionAction)
@@ -1468,10 +1904,17 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		// object is same then only metadata is updated.
 		objInfo, err = copyObjectFn(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
 		if err != nil {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			writeErrorResponse(ctx, w, toAPIError(ctx, fenced(err)), r.URL)
 			return
 		}
 
+		if !srcInfo.metadataOnly {
+			if err = globalMalwareScanner.scanUpload(ctx, objectAPI, objInfo); err != nil {
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+		}
+
 		// Remove the transitioned object whose object version is being overwritten.
 		if !globalTierConfigMgr.Empty() {
 			logger.LogIf(ctx, os.Sweep())
@@ -1621,6 +2064,11 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		metadata[xhttp.AmzObjectTagging] = objTags
 	}
 
+	if err = applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	var (
 		md5hex              = clientETag.String()
 		sha256hex           = ""
@@ -1635,6 +2083,13 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	releaseUploadToken, err := useUploadToken(ctx, objectAPI, r, bucket, object, size)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	defer releaseUploadToken(false)
+
 	switch rAuthType {
 	case authTypeStreamingSigned:
 		// Initialize stream signature verifier.
@@ -1682,9 +2137,9 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	})
 
 	actualSize := size
-	if objectAPI.IsCompressionSupported() && isCompressible(r.Header, object) && size > 0 {
+	if compressor, ok := compressorFor(r.Header, bucket, object); objectAPI.IsCompressionSupported() && ok && size > 0 {
 		// Storing the compression metadata.
-		metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
+		metadata[ReservedMetadataPrefix+"compression"] = compressor.algorithm
 		metadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(size, 10)
 
 		actualReader, err := hash.NewReader(reader, size, md5hex, sha256hex, actualSize)
@@ -1694,7 +2149,7 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 
 		// Set compression metrics.
-		s2c := newS2CompressReader(actualReader, actualSize)
+		s2c := compressor.newReader(actualReader, actualSize)
 		defer s2c.Close()
 		reader = etag.Wrap(s2c, actualReader)
 		size = -1   // Since compressed size is un-predictable.
@@ -1718,6 +2173,12 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	opts.CheckPrecondFn = putPrecondFn(r)
+	fenced, err := fenceObjectWrite(ctx, objectAPI, bucket, object, r.Header, &opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 
 	if api.CacheAPI() != nil {
 		putObject = api.CacheAPI().PutObject
@@ -1798,6 +2259,12 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	// Create the object..
 	objInfo, err := putObject(ctx, bucket, object, pReader, opts)
 	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, fenced(err)), r.URL)
+		return
+	}
+	releaseUploadToken(true)
+
+	if err = globalMalwareScanner.scanUpload(ctx, objectAPI, objInfo); err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
@@ -1865,7 +2332,11 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 // PutObjectExtractHandler - PUT Object extract is an extended API
 // based off from AWS Snowball feature to auto extract compressed
 // stream will be extracted in the same directory it is stored in
-// and the folder structures will be built out accordingly.
+// and the folder structures will be built out accordingly. Both tar
+// and zip archives are supported, metadata and tags of the extracted
+// objects may be set from a manifest object. Entries which cannot be
+// extracted are skipped, the request fails with the first error unless
+// the ExtractResult response listing them is requested.
 func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r, w, "PutObjectExtract")
 	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
@@ -2002,6 +2473,29 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	// Metadata and tags of the extracted objects, from a manifest object
+	// uploaded beforehand.
+	var manifest extractManifest
+	if manifestObject, ok := isExtractManifestRequested(r.Header); ok {
+		if s3Err = checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, manifestObject); s3Err != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+			return
+		}
+		opts, err := getOpts(ctx, r, bucket, manifestObject)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		if manifest, err = loadExtractManifest(ctx, objectAPI, bucket, manifestObject, opts); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		if manifest.hasTags() && !objectAPI.IsTaggingSupported() {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+			return
+		}
+	}
+
 	// Check if bucket encryption is enabled
 	sseConfig, _ := globalBucketSSEConfigSys.Get(bucket)
 	sseConfig.Apply(r.Header, sse.ApplyOptions{
@@ -2021,26 +2515,31 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		getObjectInfo = api.CacheAPI().GetObjectInfo
 	}
 
-	putObjectTar := func(reader io.Reader, info os.FileInfo, object string) {
+	putObjectTar := func(reader io.Reader, info os.FileInfo, object string) error {
 		size := info.Size()
 		metadata := map[string]string{
 			xhttp.AmzStorageClass: sc,
 		}
+		for k, v := range manifest[object] {
+			metadata[k] = v
+		}
+		if err := applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+			return err
+		}
 
 		actualSize := size
-		if objectAPI.IsCompressionSupported() && isCompressible(r.Header, object) && size > 0 {
+		if compressor, ok := compressorFor(r.Header, bucket, object); objectAPI.IsCompressionSupported() && ok && size > 0 {
 			// Storing the compression metadata.
-			metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
+			metadata[ReservedMetadataPrefix+"compression"] = compressor.algorithm
 			metadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(size, 10)
 
 			actualReader, err := hash.NewReader(reader, size, "", "", actualSize)
 			if err != nil {
-				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-				return
+				return err
 			}
 
 			// Set compression metrics.
-			s2c := newS2CompressReader(actualReader, actualSize)
+			s2c := compressor.newReader(actualReader, actualSize)
 			defer s2c.Close()
 			reader = etag.Wrap(s2c, actualReader)
 			size = -1 // Since compressed size is un-predictable.
@@ -2048,8 +2547,7 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 
 		hashReader, err := hash.NewReader(reader, size, "", "", actualSize)
 		if err != nil {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-			return
+			return err
 		}
 
 		rawReader := hashReader
@@ -2057,8 +2555,7 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 
 		if r.Header.Get(xhttp.AmzBucketReplicationStatus) == replication.Replica.String() {
 			if s3Err = isPutActionAllowed(ctx, getRequestAuthType(r), bucket, object, r, iampolicy.ReplicateObjectAction); s3Err != ErrNone {
-				writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
-				return
+				return extractError{errorCodes.ToAPIErr(s3Err)}
 			}
 			metadata[ReservedMetadataPrefixLower+ReplicaStatus] = replication.Replica.String()
 			metadata[ReservedMetadataPrefixLower+ReplicaTimestamp] = UTCNow().Format(time.RFC3339Nano)
@@ -2067,8 +2564,7 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		// get encryption options
 		opts, err := putOpts(ctx, r, bucket, object, metadata)
 		if err != nil {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-			return
+			return err
 		}
 		opts.MTime = info.ModTime()
 
@@ -2083,8 +2579,7 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		}
 
 		if s3Err != ErrNone {
-			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
-			return
+			return extractError{errorCodes.ToAPIErr(s3Err)}
 		}
 
 		if dsc := mustReplicate(ctx, bucket, object, getMustReplicateOptions(ObjectInfo{
@@ -2099,14 +2594,12 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		if objectAPI.IsEncryptionSupported() {
 			if _, ok := crypto.IsRequested(r.Header); ok && !HasSuffix(object, SlashSeparator) { // handle SSE requests
 				if crypto.SSECopy.IsRequested(r.Header) {
-					writeErrorResponse(ctx, w, toAPIError(ctx, errInvalidEncryptionParameters), r.URL)
-					return
+					return errInvalidEncryptionParameters
 				}
 
 				reader, objectEncryptionKey, err = EncryptRequest(hashReader, r, bucket, object, metadata)
 				if err != nil {
-					writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-					return
+					return err
 				}
 
 				wantSize := int64(-1)
@@ -2118,14 +2611,12 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 				// do not try to verify encrypted content
 				hashReader, err = hash.NewReader(etag.Wrap(reader, hashReader), wantSize, "", "", actualSize)
 				if err != nil {
-					writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-					return
+					return err
 				}
 
 				pReader, err = pReader.WithEncryption(hashReader, &objectEncryptionKey)
 				if err != nil {
-					writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-					return
+					return err
 				}
 			}
 		}
@@ -2136,23 +2627,41 @@ func (api objectAPIHandlers) PutObjectExtractHandler(w http.ResponseWriter, r *h
 		// Create the object..
 		objInfo, err := putObject(ctx, bucket, object, pReader, opts)
 		if err != nil {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-			return
+			return err
 		}
 
 		if dsc := mustReplicate(ctx, bucket, object, getMustReplicateOptions(ObjectInfo{
 			UserDefined: metadata,
 		}, replication.ObjectReplicationType, opts)); dsc.ReplicateAny() {
 			scheduleReplication(ctx, objInfo.Clone(), objectAPI, dsc, replication.ObjectReplicationType)
-
 		}
-
+		return nil
 	}
 
-	untar(hreader, putObjectTar)
+	report := globalExtractTracker.start(bucket, object)
+	defer globalExtractTracker.finish(report)
+
+	err = untar(ctx, hreader, report, putObjectTar)
+	if !isExtractResultRequested(r.Header) {
+		// The response of a single upload, failed by the first error.
+		if err == nil {
+			err = report.err()
+		}
+		if err != nil {
+			writeErrorResponse(ctx, w, toExtractAPIError(ctx, err), r.URL)
+			return
+		}
+		w.Header()[xhttp.ETag] = []string{`"` + hex.EncodeToString(hreader.MD5Current()) + `"`}
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+	if p := report.snapshot(); err != nil && p.Extracted == 0 && p.Failed == 0 {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 
 	w.Header()[xhttp.ETag] = []string{`"` + hex.EncodeToString(hreader.MD5Current()) + `"`}
-	writeSuccessResponseHeadersOnly(w)
+	writeSuccessResponseXML(w, encodeResponse(report.result(ctx, err)))
 }
 
 /// Multipart objectAPIHandlers
@@ -2201,6 +2710,116 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	uploadID, apiErr := api.newMultipartUpload(ctx, objectAPI, bucket, object, r)
+	if apiErr != noError {
+		writeErrorResponse(ctx, w, apiErr, r.URL)
+		return
+	}
+
+	response := generateInitiateMultipartUploadResponse(bucket, object, uploadID)
+	encodedSuccessResponse := encodeResponse(response)
+
+	// Write success response.
+	writeSuccessResponseXML(w, encodedSuccessResponse)
+}
+
+// PresignedMultipartUploadHandler - POST Object?presigned-uploads&size={size}
+// ----------
+// MinIO extension API, creates a multipart upload for an object of size
+// and returns pre-signed URLs to upload each part and to complete or
+// abort the upload, signed with the credentials of the request, such
+// that untrusted clients can upload large objects directly. The part
+// size is chosen to fit the part limit unless partSize is set, the URLs
+// expire after expires seconds, one hour by default.
+func (api objectAPIHandlers) PresignedMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PresignedMultipartUpload")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if _, ok := crypto.IsRequested(r.Header); ok && !objectAPI.IsEncryptionSupported() {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	cred, _, s3Error := checkRequestAuthTypeCredential(ctx, r, policy.PutObjectAction, bucket, object)
+	if s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+	// Anonymous requests have no credentials to sign with.
+	if cred.AccessKey == "" {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
+	size, err := strconv.ParseInt(r.Form.Get("size"), 10, 64)
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	var partSize int64
+	if v := r.Form.Get("partSize"); v != "" {
+		if partSize, err = strconv.ParseInt(v, 10, 64); err != nil || partSize <= 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+	expires := defaultPresignedUploadExpiry
+	if v := r.Form.Get("expires"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 || time.Duration(secs)*time.Second > maxPresignedUploadExpiry {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMalformedExpires), r.URL)
+			return
+		}
+		expires = time.Duration(secs) * time.Second
+	}
+
+	partSize, parts, s3Error := presignedPartSize(size, partSize)
+	if s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	if err = enforceBucketQuota(ctx, bucket, size); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	uploadID, apiErr := api.newMultipartUpload(ctx, objectAPI, bucket, object, r)
+	if apiErr != noError {
+		writeErrorResponse(ctx, w, apiErr, r.URL)
+		return
+	}
+
+	response := PresignedMultipartUploadResponse{
+		Bucket:   bucket,
+		Key:      object,
+		UploadID: uploadID,
+		PartSize: partSize,
+		Expires:  UTCNow().Add(expires),
+	}
+	response.Parts, response.CompleteURL, response.AbortURL = presignedUploadURLs(r, cred, uploadID, size, partSize, parts, expires)
+
+	writeSuccessResponseXML(w, encodeResponse(response))
+}
+
+// newMultipartUpload creates a multipart upload with the metadata,
+// encryption and object lock headers of r.
+func (api objectAPIHandlers) newMultipartUpload(ctx context.Context, objectAPI ObjectLayer, bucket, object string, r *http.Request) (string, APIError) {
 	// Check if bucket encryption is enabled
 	sseConfig, _ := globalBucketSSEConfigSys.Get(bucket)
 	sseConfig.Apply(r.Header, sse.ApplyOptions{
@@ -2211,8 +2830,7 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	// Validate storage class metadata if present
 	if sc := r.Header.Get(xhttp.AmzStorageClass); sc != "" {
 		if !storageclass.IsValid(sc) {
-			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidStorageClass), r.URL)
-			return
+			return "", errorCodes.ToAPIErr(ErrInvalidStorageClass)
 		}
 	}
 
@@ -2220,9 +2838,8 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 
 	if objectAPI.IsEncryptionSupported() {
 		if _, ok := crypto.IsRequested(r.Header); ok {
-			if err = setEncryptionMetadata(r, bucket, object, encMetadata); err != nil {
-				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-				return
+			if err := setEncryptionMetadata(r, bucket, object, encMetadata); err != nil {
+				return "", toAPIError(ctx, err)
 			}
 			// Set this for multipart only operations, we need to differentiate during
 			// decryption if the file was actually multipart or not.
@@ -2233,8 +2850,13 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	// Extract metadata that needs to be saved.
 	metadata, err := extractMetadata(ctx, r)
 	if err != nil {
-		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-		return
+		return "", toAPIError(ctx, err)
+	}
+	if err = applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+		return "", toAPIError(ctx, err)
+	}
+	if accessKey := logger.GetReqInfo(ctx).AccessKey; accessKey != "" && !globalIsGateway {
+		metadata[multipartInitiatorKey] = accessKey
 	}
 
 	retPerms := isPutActionAllowed(ctx, getRequestAuthType(r), bucket, object, r, iampolicy.PutObjectRetentionAction)
@@ -2254,8 +2876,7 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		metadata[strings.ToLower(xhttp.AmzObjectLockLegalHold)] = string(legalHold.Status)
 	}
 	if s3Err != ErrNone {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
-		return
+		return "", errorCodes.ToAPIErr(s3Err)
 	}
 	if dsc := mustReplicate(ctx, bucket, object, getMustReplicateOptions(ObjectInfo{
 		UserDefined: metadata,
@@ -2272,34 +2893,33 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 	// Ensure that metadata does not contain sensitive information
 	crypto.RemoveSensitiveEntries(metadata)
 
-	if objectAPI.IsCompressionSupported() && isCompressible(r.Header, object) {
+	if compressor, ok := compressorFor(r.Header, bucket, object); objectAPI.IsCompressionSupported() && ok {
 		// Storing the compression metadata.
-		metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
+		metadata[ReservedMetadataPrefix+"compression"] = compressor.algorithm
 	}
 
 	opts, err := putOpts(ctx, r, bucket, object, metadata)
 	if err != nil {
-		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-		return
+		return "", toAPIError(ctx, err)
 	}
 	newMultipartUpload := objectAPI.NewMultipartUpload
 
 	uploadID, err := newMultipartUpload(ctx, bucket, object, opts)
 	if err != nil {
-		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-		return
+		return "", toAPIError(ctx, err)
 	}
-
-	response := generateInitiateMultipartUploadResponse(bucket, object, uploadID)
-	encodedSuccessResponse := encodeResponse(response)
-
-	// Write success response.
-	writeSuccessResponseXML(w, encodedSuccessResponse)
+	return uploadID, noError
 }
 
-// CopyObjectPartHandler - uploads a part by copying data from an existing object as data source.
-func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := newContext(r, w, "CopyObjectPart")
+// AcquireObjectLeaseHandler - POST Object?lease&duration={seconds}&wait={seconds}
+// ----------
+// MinIO extension API, leases an object for duration seconds, thirty by
+// default, waiting up to wait seconds for a current lease to end. The
+// lease is a distributed lock of the object name only, it does not
+// prevent reads or writes of the object but coordinates its writers,
+// which may fence their writes with the token of their lease.
+func (api objectAPIHandlers) AcquireObjectLeaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "AcquireObjectLease")
 
 	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
 
@@ -2309,106 +2929,262 @@ func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if crypto.S3KMS.IsRequested(r.Header) { // SSE-KMS is not supported
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
-	if _, ok := crypto.IsRequested(r.Header); !objectAPI.IsEncryptionSupported() && ok {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
 		return
 	}
 
-	vars := mux.Vars(r)
-	dstBucket := vars["bucket"]
-	dstObject, err := unescapePath(vars["object"])
-	if err != nil {
+	if _, err = objectAPI.GetBucketInfo(ctx, bucket); err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
-	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, dstBucket, dstObject); s3Error != ErrNone {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+	duration, ok := parseLeaseDuration(r.Form.Get("duration"), defaultLeaseDuration, maxLeaseDuration)
+	if !ok || duration == 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
 		return
 	}
-
-	// Read escaped copy source path to check for parameters.
-	cpSrcPath := r.Header.Get(xhttp.AmzCopySource)
-	var vid string
-	if u, err := url.Parse(cpSrcPath); err == nil {
-		vid = strings.TrimSpace(u.Query().Get(xhttp.VersionID))
-		// Note that url.Parse does the unescaping
-		cpSrcPath = u.Path
+	wait, ok := parseLeaseDuration(r.Form.Get("wait"), 0, maxLeaseWait)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
 	}
 
-	srcBucket, srcObject := path2BucketObject(cpSrcPath)
-	// If source object is empty or bucket is empty, reply back invalid copy source.
-	if srcObject == "" || srcBucket == "" {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidCopySource), r.URL)
+	response, err := globalLeaseSys.acquire(ctx, objectAPI, bucket, object, duration, wait)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
-	if vid != "" && vid != nullVersionID {
-		_, err := uuid.Parse(vid)
-		if err != nil {
-			writeErrorResponse(ctx, w, toAPIError(ctx, VersionNotFound{
-				Bucket:    srcBucket,
-				Object:    srcObject,
-				VersionID: vid,
-			}), r.URL)
-			return
-		}
-	}
+	writeSuccessResponseXML(w, encodeResponse(response))
+}
 
-	if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, srcBucket, srcObject); s3Error != ErrNone {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+// RenewObjectLeaseHandler - PUT Object?lease&leaseId={leaseId}&duration={seconds}
+// ----------
+// MinIO extension API, extends a lease by duration seconds from now.
+// Leases are held by the node which granted them, requests reaching
+// other nodes are proxied to it.
+func (api objectAPIHandlers) RenewObjectLeaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RenewObjectLease")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
 		return
 	}
 
-	uploadID := r.Form.Get(xhttp.UploadID)
-	partIDString := r.Form.Get(xhttp.PartNumber)
-
-	partID, err := strconv.Atoi(partIDString)
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
 	if err != nil {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidPart), r.URL)
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
-	// check partID with maximum part ID for multipart objects
-	if isMaxPartID(partID) {
-		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidMaxParts), r.URL)
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
 		return
 	}
 
-	var srcOpts, dstOpts ObjectOptions
-	srcOpts, err = copySrcOpts(ctx, r, srcBucket, srcObject)
-	if err != nil {
-		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+	duration, ok := parseLeaseDuration(r.Form.Get("duration"), defaultLeaseDuration, maxLeaseDuration)
+	if !ok || duration == 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
 		return
 	}
-	srcOpts.VersionID = vid
 
-	// convert copy src and dst encryption options for GET/PUT calls
-	var getOpts = ObjectOptions{VersionID: srcOpts.VersionID}
-	if srcOpts.ServerSideEncryption != nil {
-		getOpts.ServerSideEncryption = encrypt.SSE(srcOpts.ServerSideEncryption)
+	leaseID := r.Form.Get("leaseId")
+	if _, nodeIndex := parseRequestToken(leaseID); proxyRequestByNodeIndex(ctx, w, r, nodeIndex) {
+		return
+	}
+	if _, ok = globalLeaseSys.get(leaseID, bucket, object); !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNoSuchLease), r.URL)
+		return
 	}
 
-	dstOpts, err = copyDstOpts(ctx, r, dstBucket, dstObject, nil)
+	response, err := globalLeaseSys.renew(leaseID, duration)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
 
-	getObjectNInfo := objectAPI.GetObjectNInfo
-	if api.CacheAPI() != nil {
-		getObjectNInfo = api.CacheAPI().GetObjectNInfo
-	}
+	writeSuccessResponseXML(w, encodeResponse(response))
+}
 
-	// Get request range.
-	var rs *HTTPRangeSpec
-	var parseRangeErr error
-	if rangeHeader := r.Header.Get(xhttp.AmzCopySourceRange); rangeHeader != "" {
+// ReleaseObjectLeaseHandler - DELETE Object?lease&leaseId={leaseId}
+// ----------
+// MinIO extension API, releases a lease before it expires.
+func (api objectAPIHandlers) ReleaseObjectLeaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ReleaseObjectLease")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	leaseID := r.Form.Get("leaseId")
+	if _, nodeIndex := parseRequestToken(leaseID); proxyRequestByNodeIndex(ctx, w, r, nodeIndex) {
+		return
+	}
+	if _, ok := globalLeaseSys.get(leaseID, bucket, object); !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNoSuchLease), r.URL)
+		return
+	}
+
+	if err = globalLeaseSys.release(leaseID); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessNoContent(w)
+}
+
+// CopyObjectPartHandler - uploads a part by copying data from an existing object as data source.
+func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "CopyObjectPart")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if crypto.S3KMS.IsRequested(r.Header) { // SSE-KMS is not supported
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	if _, ok := crypto.IsRequested(r.Header); !objectAPI.IsEncryptionSupported() && ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	dstBucket := vars["bucket"]
+	dstObject, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, dstBucket, dstObject); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Read escaped copy source path to check for parameters.
+	cpSrcPath := r.Header.Get(xhttp.AmzCopySource)
+	var vid string
+	if u, err := url.Parse(cpSrcPath); err == nil {
+		vid = strings.TrimSpace(u.Query().Get(xhttp.VersionID))
+		// Note that url.Parse does the unescaping
+		cpSrcPath = u.Path
+	}
+
+	srcBucket, srcObject := path2BucketObject(cpSrcPath)
+	// If source object is empty or bucket is empty, reply back invalid copy source.
+	if srcObject == "" || srcBucket == "" {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidCopySource), r.URL)
+		return
+	}
+
+	if vid != "" && vid != nullVersionID {
+		_, err := uuid.Parse(vid)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, VersionNotFound{
+				Bucket:    srcBucket,
+				Object:    srcObject,
+				VersionID: vid,
+			}), r.URL)
+			return
+		}
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, srcBucket, srcObject); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	uploadID := r.Form.Get(xhttp.UploadID)
+	partIDString := r.Form.Get(xhttp.PartNumber)
+
+	partID, err := strconv.Atoi(partIDString)
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidPart), r.URL)
+		return
+	}
+
+	// check partID with maximum part ID for multipart objects
+	if isMaxPartID(partID) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidMaxParts), r.URL)
+		return
+	}
+
+	var srcOpts, dstOpts ObjectOptions
+	srcOpts, err = copySrcOpts(ctx, r, srcBucket, srcObject)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	srcOpts.VersionID = vid
+
+	// convert copy src and dst encryption options for GET/PUT calls
+	var getOpts = ObjectOptions{VersionID: srcOpts.VersionID}
+	if srcOpts.ServerSideEncryption != nil {
+		getOpts.ServerSideEncryption = encrypt.SSE(srcOpts.ServerSideEncryption)
+	}
+
+	dstOpts, err = copyDstOpts(ctx, r, dstBucket, dstObject, nil)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	getObjectNInfo := objectAPI.GetObjectNInfo
+	if api.CacheAPI() != nil {
+		getObjectNInfo = api.CacheAPI().GetObjectNInfo
+	}
+
+	snapshot, err := globalBucketSnapshotSys.mounted(ctx, objectAPI, srcBucket)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if snapshot != nil {
+		getObjectNInfo = snapshot.GetObjectNInfo
+	}
+
+	// Get request range.
+	var rs *HTTPRangeSpec
+	var parseRangeErr error
+	if rangeHeader := r.Header.Get(xhttp.AmzCopySourceRange); rangeHeader != "" {
 		rs, parseRangeErr = parseCopyPartRangeSpec(rangeHeader)
 	}
 
@@ -2451,6 +3227,11 @@ func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 	defer gr.Close()
 	srcInfo := gr.ObjInfo
 
+	if isQuarantined(srcInfo) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectQuarantined), r.URL)
+		return
+	}
+
 	actualPartSize, err := srcInfo.GetActualSize()
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -2520,10 +3301,10 @@ func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 	}
 
 	// Read compression metadata preserved in the init multipart for the decision.
-	_, isCompressed := mi.UserDefined[ReservedMetadataPrefix+"compression"]
+	algorithm, isCompressed := mi.UserDefined[ReservedMetadataPrefix+"compression"]
 	// Compress only if the compression is enabled during initial multipart.
 	if isCompressed {
-		s2c := newS2CompressReader(reader, actualPartSize)
+		s2c := partCompressor(dstBucket, algorithm).newReader(reader, actualPartSize)
 		defer s2c.Close()
 		reader = etag.Wrap(s2c, reader)
 		length = -1
@@ -2766,7 +3547,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 	}
 
 	// Read compression metadata preserved in the init multipart for the decision.
-	_, isCompressed := mi.UserDefined[ReservedMetadataPrefix+"compression"]
+	algorithm, isCompressed := mi.UserDefined[ReservedMetadataPrefix+"compression"]
 
 	if objectAPI.IsCompressionSupported() && isCompressed {
 		actualReader, err := hash.NewReader(reader, size, md5hex, sha256hex, actualSize)
@@ -2776,7 +3557,7 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		}
 
 		// Set compression metrics.
-		s2c := newS2CompressReader(actualReader, actualSize)
+		s2c := partCompressor(bucket, algorithm).newReader(actualReader, actualSize)
 		defer s2c.Close()
 		reader = etag.Wrap(s2c, actualReader)
 		size = -1   // Since compressed size is un-predictable.
@@ -3230,6 +4011,12 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	opts.CheckPrecondFn = putPrecondFn(r)
+	fenced, err := fenceObjectWrite(ctx, objectAPI, bucket, object, r.Header, &opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 
 	// preserve ETag if set, or set from parts.
 	if _, ok := opts.UserDefined["etag"]; !ok {
@@ -3239,6 +4026,10 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	w = &whiteSpaceWriter{ResponseWriter: w, Flusher: w.(http.Flusher)}
 	completeDoneCh := sendWhiteSpace(w)
 	objInfo, err := completeMultiPartUpload(ctx, bucket, object, uploadID, completeParts, opts)
+	if err == nil {
+		err = globalMalwareScanner.scanUpload(ctx, objectAPI, objInfo)
+	}
+	err = fenced(err)
 	// Stop writing white spaces to the client. Note that close(doneCh) style is not used as it
 	// can cause white space to be written after we send XML response in a race condition.
 	headerWritten := <-completeDoneCh
@@ -3305,6 +4096,368 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	}
 }
 
+// AppendObjectHandler - PUT Object?append&position=N
+// ----------
+// This extension appends the request body to an object, position must
+// match the current size of the object. The position of the next
+// append is returned in the X-Minio-Next-Append-Position header.
+func (api objectAPIHandlers) AppendObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "AppendObject")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	// Encrypted objects cannot be appended to.
+	if _, ok := crypto.IsRequested(r.Header); ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	position, err := strconv.ParseInt(r.Form.Get("position"), 10, 64)
+	if err != nil || position < 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidAppendPosition), r.URL)
+		return
+	}
+
+	clientETag, err := etag.FromContentMD5(r.Header)
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidDigest), r.URL)
+		return
+	}
+
+	/// if Content-Length is unknown/missing, throw away
+	size := r.ContentLength
+
+	rAuthType := getRequestAuthType(r)
+	// For auth type streaming signature, we need to gather a different content length.
+	if rAuthType == authTypeStreamingSigned {
+		if sizeStr, ok := r.Header[xhttp.AmzDecodedContentLength]; ok {
+			if sizeStr[0] == "" {
+				writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMissingContentLength), r.URL)
+				return
+			}
+			size, err = strconv.ParseInt(sizeStr[0], 10, 64)
+			if err != nil {
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+		}
+	}
+	if size == -1 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMissingContentLength), r.URL)
+		return
+	}
+
+	/// maximum Upload size for objects in a single operation
+	if isMaxAllowedPartSize(size) || isMaxObjectSize(position+size) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEntityTooLarge), r.URL)
+		return
+	}
+
+	var (
+		md5hex              = clientETag.String()
+		sha256hex           = ""
+		reader    io.Reader = r.Body
+		s3Error   APIErrorCode
+	)
+	if s3Error = isPutActionAllowed(ctx, rAuthType, bucket, object, r, iampolicy.PutObjectAction); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	switch rAuthType {
+	case authTypeStreamingSigned:
+		// Initialize stream signature verifier.
+		reader, s3Error = newSignV4ChunkedReader(r)
+		if s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	case authTypeSignedV2, authTypePresignedV2:
+		if s3Error = isReqAuthenticatedV2(r); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	case authTypePresigned, authTypeSigned:
+		if s3Error = reqSignatureV4Verify(r, globalServerRegion, serviceS3); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+
+		if !skipContentSha256Cksum(r) {
+			sha256hex = getContentSha256Cksum(r, serviceS3)
+		}
+	}
+
+	if err := enforceBucketQuota(ctx, bucket, size); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	hashReader, err := hash.NewReader(reader, size, md5hex, sha256hex, size)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	// Metadata is only applied when the append creates the object.
+	metadata, err := extractMetadata(ctx, r)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if err = applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	opts, err := putOpts(ctx, r, bucket, object, metadata)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objInfo, err := appendObject(ctx, objectAPI, bucket, object, position, NewPutObjReader(hashReader), opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	w.Header().Set(xhttp.MinIONextAppendPosition, strconv.FormatInt(objInfo.Size, 10))
+	setPutObjHeaders(w, objInfo, false)
+	writeSuccessResponseHeadersOnly(w)
+
+	// Notify object created event.
+	sendEvent(eventArgs{
+		EventName:    event.ObjectCreatedPut,
+		BucketName:   bucket,
+		Object:       objInfo,
+		ReqParams:    extractReqParams(r),
+		RespElements: extractRespElements(w),
+		UserAgent:    r.UserAgent(),
+		Host:         handlers.GetSourceIP(r),
+	})
+}
+
+// RenameObjectHandler - PUT Object?renameObject
+// ----------
+// This extension renames the key named by the X-Amz-Rename-Source
+// header to the requested key of the same bucket, without copying
+// data when the backend allows.
+func (api objectAPIHandlers) RenameObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RenameObject")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Read escaped rename source path, url.Parse does the unescaping.
+	srcPath := r.Header.Get(xhttp.AmzRenameSource)
+	if u, err := url.Parse(srcPath); err == nil {
+		srcPath = u.Path
+	}
+	srcBucket, srcObject := path2BucketObject(srcPath)
+	if srcBucket != bucket || srcObject == "" || srcObject == object {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRenameSource), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, srcObject); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+	if s3Error := checkRequestAuthType(ctx, r, policy.DeleteObjectAction, bucket, srcObject); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Renamed objects are not replicated, refuse to let targets diverge.
+	if _, err := getReplicationConfig(ctx, bucket); err == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	objInfo, err := renameObject(ctx, objectAPI, bucket, srcObject, object)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	setPutObjHeaders(w, objInfo, false)
+	writeSuccessResponseHeadersOnly(w)
+
+	// Notify the removal of the old name and the creation of the new one.
+	sendEvent(eventArgs{
+		EventName:    event.ObjectRemovedDelete,
+		BucketName:   bucket,
+		Object:       ObjectInfo{Bucket: bucket, Name: srcObject},
+		ReqParams:    extractReqParams(r),
+		RespElements: extractRespElements(w),
+		UserAgent:    r.UserAgent(),
+		Host:         handlers.GetSourceIP(r),
+	})
+	sendEvent(eventArgs{
+		EventName:    event.ObjectCreatedCopy,
+		BucketName:   bucket,
+		Object:       objInfo,
+		ReqParams:    extractReqParams(r),
+		RespElements: extractRespElements(w),
+		UserAgent:    r.UserAgent(),
+		Host:         handlers.GetSourceIP(r),
+	})
+}
+
+// ComposeObjectHandler - PUT Object?compose
+// ----------
+// This extension creates the requested key from the concatenation of
+// up to 32 existing objects of the same bucket, assembled server side
+// without the client uploading the data again. The sources become parts
+// of the new object, so all but the last one must be at least 5MiB large
+// and fail the request with EntityTooSmall otherwise.
+func (api objectAPIHandlers) ComposeObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ComposeObject")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	// Composed objects are not encrypted.
+	if _, ok := crypto.IsRequested(r.Header); ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Content-Length is required and should be non-zero
+	if r.ContentLength <= 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMissingContentLength), r.URL)
+		return
+	}
+
+	// The max. XML contains 32 source names (each at most 1024 bytes long) + XML overhead
+	const maxBodySize = 2 * maxComposeSources * 2048
+
+	compose := ComposeObjectRequest{}
+	if err = xmlDecoder(r.Body, &compose, maxBodySize); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if len(compose.Sources) == 0 || len(compose.Sources) > maxComposeSources {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidComposeRequest), r.URL)
+		return
+	}
+	for i, src := range compose.Sources {
+		src.Key = trimLeadingSlash(src.Key)
+		if src.Key == "" {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidComposeRequest), r.URL)
+			return
+		}
+		if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, src.Key); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+		compose.Sources[i] = src
+	}
+
+	// Composed objects are not replicated and do not get default
+	// retention applied, refuse to let either diverge.
+	if _, err := getReplicationConfig(ctx, bucket); err == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+	if rcfg, _ := globalBucketObjectLockSys.Get(bucket); rcfg.LockEnabled {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	metadata, err := extractMetadata(ctx, r)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if err = applyBucketObjectDefaults(r, bucket, metadata); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	opts, err := putOpts(ctx, r, bucket, object, metadata)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	opts.CheckPrecondFn = putPrecondFn(r)
+	fenced, err := fenceObjectWrite(ctx, objectAPI, bucket, object, r.Header, &opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objInfo, err := composeObject(ctx, objectAPI, bucket, object, compose.Sources, opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, fenced(err)), r.URL)
+		return
+	}
+
+	setPutObjHeaders(w, objInfo, false)
+	response := generateCopyObjectResponse(objInfo.ETag, objInfo.ModTime)
+	writeSuccessResponseXML(w, encodeResponse(response))
+
+	// Notify object created event.
+	sendEvent(eventArgs{
+		EventName:    event.ObjectCreatedCompleteMultipartUpload,
+		BucketName:   bucket,
+		Object:       objInfo,
+		ReqParams:    extractReqParams(r),
+		RespElements: extractRespElements(w),
+		UserAgent:    r.UserAgent(),
+		Host:         handlers.GetSourceIP(r),
+	})
+}
+
 /// Delete objectAPIHandlers
 
 // DeleteObjectHandler - delete an object
@@ -3345,6 +4498,11 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	fenced, err := fenceObjectWrite(ctx, objectAPI, bucket, object, r.Header, &opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 	var (
 		goi  ObjectInfo
 		gerr error
@@ -3408,11 +4566,30 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Versions pinned by a snapshot of the bucket cannot be deleted,
+	// on versioning suspended buckets that is the null version.
+	pinnedID := vID
+	if pinnedID == "" && opts.VersionSuspended {
+		pinnedID = nullVersionID
+	}
+	if pinnedID != "" && globalBucketSnapshotSys.pinned(ctx, objectAPI, bucket, object, pinnedID) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectPinnedBySnapshot), r.URL)
+		return
+	}
+
 	deleteObject := objectAPI.DeleteObject
 	if api.CacheAPI() != nil {
 		deleteObject = api.CacheAPI().DeleteObject
 	}
 
+	// Move the object to the bucket trash instead when soft delete is enabled.
+	trashed := trashEnabled(bucket, opts)
+	if trashed {
+		deleteObject = func(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+			return trashObject(ctx, objectAPI, bucket, object, opts)
+		}
+	}
+
 	// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	objInfo, err := deleteObject(ctx, bucket, object, opts)
 	if err != nil {
@@ -3422,6 +4599,10 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 			return
 		}
+		if err = fenced(err); err == errStaleFencingToken {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
 	}
 
 	if objInfo.Name == "" {
@@ -3470,8 +4651,9 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		scheduleReplicationDelete(ctx, dobj, objectAPI)
 	}
 
-	// Remove the transitioned object whose object version is being overwritten.
-	if !globalTierConfigMgr.Empty() {
+	// Remove the transitioned object whose object version is being overwritten,
+	// trashed objects keep their remote data until purged.
+	if !globalTierConfigMgr.Empty() && !trashed {
 		os.Sweep()
 	}
 }
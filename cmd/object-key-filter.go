@@ -0,0 +1,417 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/sync/errgroup"
+)
+
+const (
+	// The key filter of a set is saved next to its usage cache.
+	keyFilterName    = ".key-filter.bin"
+	keyFilterVersion = 1
+
+	// False positive rate of the key filters, sized for the keys of
+	// the last build and at least keyFilterMinItems.
+	keyFilterFP       = 0.01
+	keyFilterMinItems = 1 << 20
+
+	keyFilterBuildInterval  = 24 * time.Hour
+	keyFilterReloadInterval = 10 * time.Minute
+)
+
+var keyFilterLeaderLockTimeout = newDynamicTimeout(30*time.Second, 10*time.Second)
+
+var errKeyFilterVersion = errors.New("unknown key filter version")
+
+// setKeyFilter is a bloom filter of the keys of the objects of an
+// erasure set, holding the keys found by the last build and the keys
+// written through this node since it was loaded. A key not in the
+// filter is only reported missing after objectMissing confirms it, a
+// stale filter makes lookups slower, never wrong.
+type setKeyFilter struct {
+	mu sync.RWMutex
+	bf *bloom.BloomFilter
+
+	// Build time and number of keys of the filter.
+	built time.Time
+	items uint64
+
+	// The filter being built on this node, receiving the keys
+	// written meanwhile as well.
+	building *bloom.BloomFilter
+}
+
+func keyFilterKey(bucket, object string) string {
+	return bucket + SlashSeparator + object
+}
+
+func newKeyFilterBloom(items uint64) *bloom.BloomFilter {
+	if items < keyFilterMinItems {
+		items = keyFilterMinItems
+	}
+	return bloom.NewWithEstimates(uint(items), keyFilterFP)
+}
+
+// add adds a key written to the filter.
+func (f *setKeyFilter) add(bucket, object string) {
+	if f == nil || isMinioMetaBucketName(bucket) {
+		return
+	}
+	key := keyFilterKey(bucket, object)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.bf != nil {
+		f.bf.AddString(key)
+	}
+	if f.building != nil {
+		f.building.AddString(key)
+	}
+}
+
+// absent returns whether the filter is loaded and does not have the
+// key of object.
+func (f *setKeyFilter) absent(bucket, object string) bool {
+	if f == nil || isMinioMetaBucketName(bucket) {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.bf != nil && !f.bf.TestString(keyFilterKey(bucket, object))
+}
+
+// reset drops the filter, when key filters are disabled.
+func (f *setKeyFilter) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bf, f.built, f.items = nil, time.Time{}, 0
+}
+
+// install replaces the filter by bf if it was built later.
+func (f *setKeyFilter) install(bf *bloom.BloomFilter, built time.Time, items uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !built.After(f.built) {
+		return
+	}
+	f.bf, f.built, f.items = bf, built, items
+}
+
+func (f *setKeyFilter) getBuilt() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.built
+}
+
+// serializeKeyFilter writes the version, build time and number of keys
+// followed by the filter.
+func serializeKeyFilter(dst io.Writer, bf *bloom.BloomFilter, built time.Time, items uint64) error {
+	var hdr [17]byte
+	hdr[0] = keyFilterVersion
+	binary.LittleEndian.PutUint64(hdr[1:], uint64(built.UnixNano()))
+	binary.LittleEndian.PutUint64(hdr[9:], items)
+	if _, err := dst.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := bf.WriteTo(dst)
+	return err
+}
+
+func deserializeKeyFilter(r io.Reader) (bf *bloom.BloomFilter, built time.Time, items uint64, err error) {
+	var hdr [17]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return nil, built, 0, err
+	}
+	if hdr[0] != keyFilterVersion {
+		return nil, built, 0, errKeyFilterVersion
+	}
+	built = time.Unix(0, int64(binary.LittleEndian.Uint64(hdr[1:]))).UTC()
+	items = binary.LittleEndian.Uint64(hdr[9:])
+	bf = &bloom.BloomFilter{}
+	if _, err = bf.ReadFrom(r); err != nil {
+		return nil, built, 0, err
+	}
+	return bf, built, items, nil
+}
+
+// objectMissing returns whether the latest version of object has no
+// metadata on any of half the drives of the set plus one. An object is
+// readable from its data drives, at least half the drives of the set
+// whatever its parity, so one of the drives checked has it. Local
+// drives are checked first, sparing requests to other nodes. Offline
+// drives, errors and missing buckets fail the check.
+func (er erasureObjects) objectMissing(ctx context.Context, bucket, object string) bool {
+	disks := er.getDisks()
+	need := len(disks)/2 + 1
+
+	checked := make([]StorageAPI, 0, need)
+	start := rand.Intn(len(disks))
+	for _, local := range []bool{true, false} {
+		for i := range disks {
+			disk := disks[(start+i)%len(disks)]
+			if len(checked) == need || disk == nil || disk.IsLocal() != local || !disk.IsOnline() {
+				continue
+			}
+			checked = append(checked, disk)
+		}
+	}
+	if len(checked) < need {
+		return false
+	}
+
+	g := errgroup.WithNErrs(len(checked))
+	for index := range checked {
+		index := index
+		g.Go(func() error {
+			_, err := checked[index].ReadVersion(ctx, bucket, object, "", false)
+			return err
+		}, index)
+	}
+	for _, err := range g.Wait() {
+		if err != errFileNotFound {
+			return false
+		}
+	}
+	return true
+}
+
+// keyFilterMissing returns whether the key filters of the sets object
+// hashes to in all pools do not have it and the drives confirm it is
+// missing, latest versions only.
+func (z *erasureServerPools) keyFilterMissing(ctx context.Context, bucket, object string, opts ObjectOptions) bool {
+	serverPools := z.currentPools()
+	if opts.VersionID != "" || !globalAPIConfig.isHeadKeyFilter() {
+		return false
+	}
+	sets := make([]*erasureObjects, len(serverPools))
+	for i, pool := range serverPools {
+		sets[i] = pool.getHashedSet(object)
+		if !sets[i].keyFilter.absent(bucket, object) {
+			return false
+		}
+	}
+	if len(sets) == 1 {
+		return sets[0].objectMissing(ctx, bucket, object)
+	}
+	missing := make([]bool, len(sets))
+	var wg sync.WaitGroup
+	for i, set := range sets {
+		wg.Add(1)
+		go func(i int, set *erasureObjects) {
+			defer wg.Done()
+			missing[i] = set.objectMissing(ctx, bucket, object)
+		}(i, set)
+	}
+	wg.Wait()
+	for _, m := range missing {
+		if !m {
+			return false
+		}
+	}
+	return true
+}
+
+// buildKeyFilter lists all objects of the buckets in the set and saves
+// their keys as the key filter of the set.
+func (er erasureObjects) buildKeyFilter(ctx context.Context, buckets []BucketInfo) error {
+	f := er.keyFilter
+	disks, _ := er.getOnlineDisksWithHealing()
+	if len(disks) == 0 {
+		return errDiskNotFound
+	}
+
+	built := UTCNow()
+	f.mu.Lock()
+	bf := newKeyFilterBloom(f.items + f.items/4)
+	f.building = bf
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.building = nil
+		f.mu.Unlock()
+	}()
+
+	var items uint64
+	for _, bucket := range buckets {
+		bucket := bucket.Name
+		addEntry := func(entry metaCacheEntry) {
+			if entry.isDir() {
+				return
+			}
+			items++
+			f.mu.Lock()
+			bf.AddString(keyFilterKey(bucket, entry.name))
+			f.mu.Unlock()
+		}
+		err := listPathRaw(ctx, listPathRawOptions{
+			disks:     disks,
+			bucket:    bucket,
+			recursive: true,
+			minDisks:  1,
+			agreed:    addEntry,
+			partial: func(entries metaCacheEntries, nAgreed int, errs []error) {
+				if entry, n := entries.firstFound(); n > 0 {
+					addEntry(*entry)
+				}
+			},
+		})
+		if err != nil && !errors.Is(err, errVolumeNotFound) {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	f.mu.RLock()
+	err := serializeKeyFilter(&buf, bf, built, items)
+	f.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	r, err := hash.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "", "", int64(buf.Len()))
+	if err != nil {
+		return err
+	}
+	if _, err = er.PutObject(ctx, dataUsageBucket, keyFilterName, NewPutObjReader(r), ObjectOptions{}); err != nil {
+		return err
+	}
+	f.install(bf, built, items)
+	return nil
+}
+
+// loadKeyFilter loads the key filter saved for the set if it was built
+// after the one in memory.
+func (er erasureObjects) loadKeyFilter(ctx context.Context) error {
+	f := er.keyFilter
+	oi, err := er.GetObjectInfo(ctx, dataUsageBucket, keyFilterName, ObjectOptions{})
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrBucketNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !oi.ModTime.After(f.getBuilt()) {
+		return nil
+	}
+	rd, err := er.GetObjectNInfo(ctx, dataUsageBucket, keyFilterName, nil, http.Header{}, readLock, ObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	bf, built, items, err := deserializeKeyFilter(rd)
+	if err != nil {
+		return err
+	}
+	f.install(bf, built, items)
+	return nil
+}
+
+func initKeyFilters(ctx context.Context, objAPI ObjectLayer) {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return
+	}
+	go z.reloadKeyFilters(ctx)
+	go z.runKeyFilterBuild(ctx)
+}
+
+// reloadKeyFilters periodically loads the key filters built by the
+// leader, or drops them when key filters are disabled.
+func (z *erasureServerPools) reloadKeyFilters(ctx context.Context) {
+	for {
+		for _, pool := range z.currentPools() {
+			for _, set := range pool.sets {
+				if !globalAPIConfig.isHeadKeyFilter() {
+					set.keyFilter.reset()
+					continue
+				}
+				logger.LogIf(ctx, set.loadKeyFilter(ctx))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(keyFilterReloadInterval):
+		}
+	}
+}
+
+// runKeyFilterBuild rebuilds the key filters of all sets daily, only
+// the node holding the leader lock does the work. A node losing the
+// lock competes for it again.
+func (z *erasureServerPools) runKeyFilterBuild(ctx context.Context) {
+	locker := z.NewNSLock(minioMetaBucket, "runKeyFilterBuild.lock")
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		lkctx, err := locker.GetLock(ctx, keyFilterLeaderLockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Duration(r.Float64() * float64(keyFilterReloadInterval)))
+			continue
+		}
+		// No unlock for "leader" lock.
+		z.buildKeyFilters(lkctx.Context())
+		lkctx.Cancel()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// buildKeyFilters rebuilds the key filters older than a day until ctx
+// is canceled.
+func (z *erasureServerPools) buildKeyFilters(ctx context.Context) {
+	for {
+		if globalAPIConfig.isHeadKeyFilter() {
+			buckets, err := z.ListBuckets(ctx)
+			logger.LogIf(ctx, err)
+			for _, pool := range z.currentPools() {
+				for _, set := range pool.sets {
+					if err != nil {
+						continue
+					}
+					// Another leader may have built it meanwhile.
+					logger.LogIf(ctx, set.loadKeyFilter(ctx))
+					if time.Since(set.keyFilter.getBuilt()) < keyFilterBuildInterval {
+						continue
+					}
+					logger.LogIf(ctx, set.buildKeyFilter(ctx, buckets))
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(keyFilterReloadInterval):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeyFilterSerialize(t *testing.T) {
+	bf := newKeyFilterBloom(0)
+	bf.AddString(keyFilterKey("bucket", "object"))
+	built := UTCNow().Truncate(time.Second)
+
+	var buf bytes.Buffer
+	if err := serializeKeyFilter(&buf, bf, built, 1); err != nil {
+		t.Fatal(err)
+	}
+	got, gotBuilt, items, err := deserializeKeyFilter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotBuilt.Equal(built) || items != 1 {
+		t.Fatalf("got build time %v and %d keys, want %v and 1", gotBuilt, items, built)
+	}
+	if !got.TestString(keyFilterKey("bucket", "object")) {
+		t.Fatal("key missing from the loaded filter")
+	}
+
+	if _, _, _, err = deserializeKeyFilter(bytes.NewReader([]byte{keyFilterVersion + 1})); err == nil {
+		t.Fatal("expected an error for an invalid filter")
+	}
+}
+
+func TestKeyFilterMissing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obj, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Shutdown(context.Background())
+	defer removeRoots(fsDirs)
+
+	globalAPIConfig.mu.Lock()
+	globalAPIConfig.headKeyFilter = true
+	globalAPIConfig.mu.Unlock()
+	defer func() {
+		globalAPIConfig.mu.Lock()
+		globalAPIConfig.headKeyFilter = false
+		globalAPIConfig.mu.Unlock()
+	}()
+
+	if err = obj.MakeBucketWithLocation(ctx, "bucket", BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("abcd")
+	if _, err = obj.PutObject(ctx, "bucket", "present", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	z := obj.(*erasureServerPools)
+	buckets, err := z.ListBuckets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := z.serverPools[0].sets[0]
+	if err = set.buildKeyFilter(ctx, buckets); err != nil {
+		t.Fatal(err)
+	}
+
+	if !z.keyFilterMissing(ctx, "bucket", "missing", ObjectOptions{}) {
+		t.Fatal("expected the missing object to be answered by the filter")
+	}
+	if _, err = obj.GetObjectInfo(ctx, "bucket", "missing", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected object not found, got %v", err)
+	}
+	if _, err = obj.GetObjectInfo(ctx, "bucket", "present", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects written after the build are added.
+	if _, err = obj.PutObject(ctx, "bucket", "new", mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if set.keyFilter.absent("bucket", "new") {
+		t.Fatal("expected the new object in the filter")
+	}
+
+	// A stale filter missing an object falls back to the drives.
+	set.keyFilter.install(newKeyFilterBloom(0), UTCNow().Add(time.Hour), 0)
+	if z.keyFilterMissing(ctx, "bucket", "present", ObjectOptions{}) {
+		t.Fatal("expected the drives to find the object missing from the filter")
+	}
+	if _, err = obj.GetObjectInfo(ctx, "bucket", "present", ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	leasesPrefix = "leases"
+
+	defaultLeaseDuration = 30 * time.Second
+	maxLeaseDuration     = time.Hour
+	minLeaseWait         = time.Second
+	maxLeaseWait         = time.Minute
+
+	leaseTokenGCInterval = time.Hour
+)
+
+var leaseTokenGCLeaderLockTimeout = newDynamicTimeout(30*time.Second, 10*time.Second)
+
+var (
+	errLeaseNotFound     = errors.New("Specified lease does not exist or expired")
+	errLeaseHeld         = errors.New("Object is leased by another client")
+	errStaleFencingToken = errors.New("Fencing token is older than the latest lease")
+)
+
+// LeaseResponse - a lease of an object, expiring at Expires unless
+// renewed. Token increases with every lease of the object, writes can
+// be fenced with the token of their lease.
+type LeaseResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LeaseResult" json:"-"`
+
+	Bucket  string
+	Key     string
+	LeaseID string `xml:"LeaseId"`
+	Token   uint64
+	Expires time.Time
+}
+
+// objectLease is a lease held by this node, as the write lock of the
+// lease resource of the object, kept until the lease is released or
+// expires, or the lock is lost.
+type objectLease struct {
+	id             string
+	bucket, object string
+	token          uint64
+	expires        time.Time
+	timer          *time.Timer
+	lock           RWLocker
+	lkctx          LockContext
+}
+
+func (l *objectLease) response() LeaseResponse {
+	return LeaseResponse{
+		Bucket:  l.bucket,
+		Key:     l.object,
+		LeaseID: l.id,
+		Token:   l.token,
+		Expires: l.expires,
+	}
+}
+
+// leaseSys holds the leases acquired through this node.
+type leaseSys struct {
+	sync.Mutex
+	leases map[string]*objectLease
+}
+
+var globalLeaseSys = &leaseSys{leases: make(map[string]*objectLease)}
+
+// leaseResource returns the name of the lock of the leases of object,
+// not to conflict with the lock of the object itself.
+func leaseResource(bucket, object string) string {
+	return pathJoin(leasesPrefix, bucket, object)
+}
+
+func leaseTokenPath(bucket, object string) string {
+	return pathJoin(bucketMetaPrefix, bucket, leasesPrefix, object)
+}
+
+// leaseTokenFloorPath is the file of the lowest token of the objects of
+// bucket without a token file, above the tokens of the removed files.
+func leaseTokenFloorPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, leasesPrefix+".floor")
+}
+
+// parseLeaseDuration parses a duration in seconds, d if empty.
+func parseLeaseDuration(v string, d, max time.Duration) (time.Duration, bool) {
+	if v == "" {
+		return d, true
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 || time.Duration(secs)*time.Second > max {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// acquire leases object for ttl, waiting up to wait for the current
+// lease to be released or to expire.
+func (s *leaseSys) acquire(ctx context.Context, objAPI ObjectLayer, bucket, object string, ttl, wait time.Duration) (LeaseResponse, error) {
+	if wait < minLeaseWait {
+		wait = minLeaseWait
+	}
+	lock := objAPI.NewNSLock(minioMetaBucket, leaseResource(bucket, object))
+	// The lock outlives the request, it is held until the lease ends.
+	lkctx, err := lock.GetLock(GlobalContext, newDynamicTimeout(wait, wait))
+	if err != nil {
+		if _, ok := err.(OperationTimedOut); ok {
+			err = errLeaseHeld
+		}
+		return LeaseResponse{}, err
+	}
+
+	// Holding the lock, no other lease of the object is taken meanwhile.
+	token, err := nextLeaseToken(ctx, objAPI, bucket, object)
+	if err != nil {
+		lock.Unlock(lkctx.Cancel)
+		return LeaseResponse{}, err
+	}
+
+	l := &objectLease{
+		id:      fmt.Sprintf("%s@%d", mustGetUUID(), GetProxyEndpointLocalIndex(globalProxyEndpoints)),
+		bucket:  bucket,
+		object:  object,
+		token:   token,
+		expires: UTCNow().Add(ttl),
+		lock:    lock,
+		lkctx:   lkctx,
+	}
+	s.Lock()
+	s.leases[l.id] = l
+	l.timer = time.AfterFunc(ttl, func() { s.release(l.id) })
+	s.Unlock()
+
+	// The lease ends with its lock, also when the lock is lost.
+	go func() {
+		<-lkctx.Context().Done()
+		s.Lock()
+		delete(s.leases, l.id)
+		s.Unlock()
+	}()
+	return l.response(), nil
+}
+
+// renew extends the lease id by ttl from now.
+func (s *leaseSys) renew(id string, ttl time.Duration) (LeaseResponse, error) {
+	s.Lock()
+	defer s.Unlock()
+	l, ok := s.leases[id]
+	if !ok {
+		return LeaseResponse{}, errLeaseNotFound
+	}
+	l.timer.Reset(ttl)
+	l.expires = UTCNow().Add(ttl)
+	return l.response(), nil
+}
+
+// release ends the lease id.
+func (s *leaseSys) release(id string) error {
+	s.Lock()
+	l, ok := s.leases[id]
+	if ok {
+		delete(s.leases, id)
+		l.timer.Stop()
+	}
+	s.Unlock()
+	if !ok {
+		return errLeaseNotFound
+	}
+	l.lock.Unlock(l.lkctx.Cancel)
+	return nil
+}
+
+// get returns the lease id if it leases object.
+func (s *leaseSys) get(id, bucket, object string) (*objectLease, bool) {
+	s.Lock()
+	defer s.Unlock()
+	l, ok := s.leases[id]
+	if !ok || l.bucket != bucket || l.object != object {
+		return nil, false
+	}
+	return l, true
+}
+
+func readLeaseToken(ctx context.Context, objAPI ObjectLayer, configFile string) (uint64, error) {
+	data, err := readConfig(ctx, objAPI, configFile)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseUint(string(data), 10, 64)
+}
+
+// loadLeaseToken returns the token of the latest lease of object, the
+// token floor of bucket once the token file was garbage collected.
+func loadLeaseToken(ctx context.Context, objAPI ObjectLayer, bucket, object string) (uint64, error) {
+	data, err := readConfig(ctx, objAPI, leaseTokenPath(bucket, object))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return readLeaseToken(ctx, objAPI, leaseTokenFloorPath(bucket))
+		}
+		return 0, err
+	}
+	return strconv.ParseUint(string(data), 10, 64)
+}
+
+// nextLeaseToken increments the fencing token of object, the caller
+// must hold the lease lock of object.
+func nextLeaseToken(ctx context.Context, objAPI ObjectLayer, bucket, object string) (uint64, error) {
+	token, err := loadLeaseToken(ctx, objAPI, bucket, object)
+	if err != nil {
+		return 0, err
+	}
+	token++
+	if err = saveConfig(ctx, objAPI, leaseTokenPath(bucket, object), []byte(strconv.FormatUint(token, 10))); err != nil {
+		return 0, err
+	}
+	return token, nil
+}
+
+// checkFencingToken rejects writes of object fenced with the token of a
+// lease older than the latest lease of object.
+func checkFencingToken(ctx context.Context, objAPI ObjectLayer, bucket, object string, token uint64) error {
+	latest, err := loadLeaseToken(ctx, objAPI, bucket, object)
+	if err != nil {
+		return err
+	}
+	if token < latest {
+		return errStaleFencingToken
+	}
+	return nil
+}
+
+// fenceObjectWrite makes the write with opts check the fencing token of
+// h under the object lock, together with the other preconditions of
+// opts, such that no lease of object is taken between the check and the
+// write. Writes without a token are not fenced. The returned function
+// maps the failed precondition of a stale token to its error.
+func fenceObjectWrite(ctx context.Context, objAPI ObjectLayer, bucket, object string, h http.Header, opts *ObjectOptions) (func(error) error, error) {
+	var fenceErr error
+	fenced := func(err error) error {
+		if fenceErr != nil && isErrPreconditionFailed(err) {
+			return fenceErr
+		}
+		return err
+	}
+
+	v := h.Get(xhttp.MinIOFencingToken)
+	if v == "" {
+		return fenced, nil
+	}
+	token, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return fenced, errInvalidArgument
+	}
+	// Fail early without taking the object lock, the token can only
+	// get older.
+	if err = checkFencingToken(ctx, objAPI, bucket, object, token); err != nil {
+		return fenced, err
+	}
+
+	checkPrecondFn := opts.CheckPrecondFn
+	opts.CheckPrecondFn = func(oi ObjectInfo) bool {
+		if fenceErr = checkFencingToken(ctx, objAPI, bucket, object, token); fenceErr != nil {
+			return true
+		}
+		return checkPrecondFn != nil && checkPrecondFn(oi)
+	}
+	return fenced, nil
+}
+
+// gcLeaseTokens removes the token files of the objects of bucket not
+// leased for longer than any lease lasts without renewal, raising the
+// token floor of bucket above their tokens first such that the tokens
+// stay fenced.
+func gcLeaseTokens(ctx context.Context, objAPI ObjectLayer, bucket string) error {
+	prefix := leaseTokenPath(bucket, "") + SlashSeparator
+	marker := ""
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range loi.Objects {
+			if UTCNow().Sub(oi.ModTime) <= maxLeaseDuration {
+				continue
+			}
+			logger.LogIf(ctx, gcLeaseToken(ctx, objAPI, bucket, strings.TrimPrefix(oi.Name, prefix)))
+		}
+		if !loi.IsTruncated {
+			return nil
+		}
+		marker = loi.NextMarker
+	}
+}
+
+// gcLeaseToken removes the token file of object unless it is leased.
+func gcLeaseToken(ctx context.Context, objAPI ObjectLayer, bucket, object string) error {
+	lock := objAPI.NewNSLock(minioMetaBucket, leaseResource(bucket, object))
+	lkctx, err := lock.GetLock(ctx, newDynamicTimeout(minLeaseWait, minLeaseWait))
+	if err != nil {
+		if _, ok := err.(OperationTimedOut); ok {
+			// Leased, the token is in use.
+			return nil
+		}
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lock.Unlock(lkctx.Cancel)
+
+	token, err := readLeaseToken(ctx, objAPI, leaseTokenPath(bucket, object))
+	if err != nil {
+		return err
+	}
+	floor, err := readLeaseToken(ctx, objAPI, leaseTokenFloorPath(bucket))
+	if err != nil {
+		return err
+	}
+	// The floor is above the token, a write fenced with it is stale.
+	if token >= floor {
+		if err = saveConfig(ctx, objAPI, leaseTokenFloorPath(bucket), []byte(strconv.FormatUint(token+1, 10))); err != nil {
+			return err
+		}
+	}
+	return deleteConfig(ctx, objAPI, leaseTokenPath(bucket, object))
+}
+
+// initLeaseTokenGC starts the routine removing the unused token files.
+func initLeaseTokenGC(ctx context.Context, objAPI ObjectLayer) {
+	go runLeaseTokenGC(ctx, objAPI)
+}
+
+// runLeaseTokenGC periodically removes the unused token files of all
+// buckets, only the node holding the leader lock does the work. The
+// lock is taken again when it is lost.
+func runLeaseTokenGC(ctx context.Context, objAPI ObjectLayer) {
+	locker := objAPI.NewNSLock(minioMetaBucket, "runLeaseTokenGC.lock")
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		lkctx, err := locker.GetLock(ctx, leaseTokenGCLeaderLockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Duration(r.Float64() * float64(leaseTokenGCInterval)))
+			continue
+		}
+		// No unlock for "leader" lock.
+		gcLeaseTokensLoop(lkctx.Context(), objAPI)
+		lkctx.Cancel()
+	}
+}
+
+func gcLeaseTokensLoop(ctx context.Context, objAPI ObjectLayer) {
+	gcTimer := time.NewTimer(leaseTokenGCInterval)
+	defer gcTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-gcTimer.C:
+			buckets, err := objAPI.ListBuckets(ctx)
+			if err != nil {
+				logger.LogIf(ctx, err)
+			}
+			for _, bucket := range buckets {
+				logger.LogIf(ctx, gcLeaseTokens(ctx, objAPI, bucket.Name))
+			}
+			gcTimer.Reset(leaseTokenGCInterval)
+		}
+	}
+}
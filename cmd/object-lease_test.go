@@ -0,0 +1,160 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestObjectLease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket, object := "bucket", "object"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &leaseSys{leases: make(map[string]*objectLease)}
+	lease, err := s.acquire(ctx, objLayer, bucket, object, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Token != 1 {
+		t.Errorf("expected the first token, got %d", lease.Token)
+	}
+	if _, err = s.acquire(ctx, objLayer, bucket, object, time.Minute, 0); err != errLeaseHeld {
+		t.Fatalf("expected %v, got %v", errLeaseHeld, err)
+	}
+	if _, ok := s.get(lease.LeaseID, bucket, "other"); ok {
+		t.Error("expected the lease of another object not to be found")
+	}
+
+	renewed, err := s.renew(lease.LeaseID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !renewed.Expires.After(lease.Expires) {
+		t.Errorf("expected the lease to be extended, got %v", renewed.Expires)
+	}
+	if err = s.release(lease.LeaseID); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.release(lease.LeaseID); err != errLeaseNotFound {
+		t.Fatalf("expected %v, got %v", errLeaseNotFound, err)
+	}
+
+	next, err := s.acquire(ctx, objLayer, bucket, object, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Token != 2 {
+		t.Errorf("expected the second token, got %d", next.Token)
+	}
+
+	fence := func(token string, opts *ObjectOptions) (func(error) error, error) {
+		h := http.Header{}
+		if token != "" {
+			h.Set(xhttp.MinIOFencingToken, token)
+		}
+		return fenceObjectWrite(ctx, objLayer, bucket, object, h, opts)
+	}
+	for token, want := range map[string]error{
+		"":    nil,
+		"1":   errStaleFencingToken,
+		"2":   nil,
+		"two": errInvalidArgument,
+	} {
+		if _, err = fence(token, &ObjectOptions{}); err != want {
+			t.Errorf("token %q: expected %v, got %v", token, want, err)
+		}
+	}
+
+	// A lease taken after the early check fences the write under the
+	// object lock.
+	var opts ObjectOptions
+	fenced, err := fence("2", &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.release(next.LeaseID); err != nil {
+		t.Fatal(err)
+	}
+	last, err := s.acquire(ctx, objLayer, bucket, object, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader([]byte("a")), 1, "", ""), opts)
+	if err = fenced(err); err != errStaleFencingToken {
+		t.Fatalf("expected %v, got %v", errStaleFencingToken, err)
+	}
+	opts = ObjectOptions{}
+	if fenced, err = fence(strconv.FormatUint(last.Token, 10), &opts); err != nil {
+		t.Fatal(err)
+	}
+	_, err = objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader([]byte("a")), 1, "", ""), opts)
+	if err = fenced(err); err != nil {
+		t.Fatal(err)
+	}
+
+	// The token of a leased object is kept.
+	if err = gcLeaseToken(ctx, objLayer, bucket, object); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := loadLeaseToken(ctx, objLayer, bucket, object); err != nil || token != last.Token {
+		t.Fatalf("expected token %d, got %d %v", last.Token, token, err)
+	}
+	if err = s.release(last.LeaseID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once removed, the token stays fenced by the floor of the bucket
+	// and the next lease gets a newer one.
+	if err = gcLeaseToken(ctx, objLayer, bucket, object); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readConfig(ctx, objLayer, leaseTokenPath(bucket, object)); !errors.Is(err, errConfigNotFound) {
+		t.Fatalf("expected the token file to be removed, got %v", err)
+	}
+	opts = ObjectOptions{}
+	if _, err = fence(strconv.FormatUint(last.Token, 10), &opts); err != errStaleFencingToken {
+		t.Fatalf("expected %v, got %v", errStaleFencingToken, err)
+	}
+	lease, err = s.acquire(ctx, objLayer, bucket, "other", time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.release(lease.LeaseID)
+	if lease.Token <= last.Token {
+		t.Errorf("expected a token above %d, got %d", last.Token, lease.Token)
+	}
+}
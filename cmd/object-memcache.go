@@ -0,0 +1,545 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/minio/minio/internal/crypto"
+)
+
+const (
+	// Writes increment the generation of the shard of their object,
+	// objects read while it changed are not cached.
+	objectMemCacheShards = 256
+
+	// The objects are spread over up to this many segments, each with
+	// its own lock, LRU list and frequency sketch.
+	objectMemCacheSegments = 16
+
+	// Bounds of the width of the frequency sketch.
+	objectMemCacheMinSketch = 1 << 10
+	objectMemCacheMaxSketch = 1 << 22
+
+	// Invalidations of peers are best effort, cached objects are read
+	// again from the drives once this old so that an object written
+	// through a node unreachable meanwhile is not served stale for long.
+	objectMemCacheTTL = 10 * time.Second
+
+	// Time for which writes wait for the peers to drop their objects.
+	objectMemCacheInvalidateTimeout = 2 * time.Second
+)
+
+// objectMemCacheEntry - the info and content of the latest version of
+// an object.
+type objectMemCacheEntry struct {
+	key     string
+	objInfo ObjectInfo
+	data    []byte
+	expires time.Time
+}
+
+// ObjectMemCacheStats - activity of the object memory cache of a node.
+type ObjectMemCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Admitted      uint64
+	Rejected      uint64
+	Invalidations uint64
+	Objects       int
+	Size          int64
+}
+
+// objectMemCacheSegment holds the objects of a part of the keys.
+type objectMemCacheSegment struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List // most recently used first
+	sketch  *frequencySketch
+}
+
+// objectMemCacheBatch - objects written through this node meanwhile,
+// dropped from the caches of the peers with one call per bucket.
+type objectMemCacheBatch struct {
+	objects map[string][]string
+	done    chan struct{}
+}
+
+// objectMemCache caches the latest version of small hot objects in
+// memory, evicting the least recently used ones. A TinyLFU sketch of
+// the access frequencies admits an object into a full cache only if it
+// is accessed more often than the objects it would evict. Writes
+// through any node invalidate the objects on all nodes.
+type objectMemCache struct {
+	mu        sync.RWMutex // guards segments
+	maxSize   int64        // accessed atomically
+	maxObject int64        // accessed atomically
+	segments  []*objectMemCacheSegment
+
+	generations [objectMemCacheShards]uint64
+
+	batchMu sync.Mutex
+	batch   *objectMemCacheBatch
+	sending bool
+
+	hits, misses, admitted, rejected, invalidations uint64
+}
+
+var globalObjectMemCache = &objectMemCache{}
+
+func objectMemCacheKey(bucket, object string) string {
+	return bucket + SlashSeparator + object
+}
+
+// setLimits sets the memory of the cache and the size of the largest
+// objects cached, a size of 0 disables the cache.
+func (c *objectMemCache) setLimits(size, maxObject uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if int64(size) == c.maxSize && int64(maxObject) == c.maxObject {
+		return
+	}
+	atomic.StoreInt64(&c.maxSize, int64(size))
+	atomic.StoreInt64(&c.maxObject, int64(maxObject))
+	c.segments = nil
+	if size == 0 {
+		return
+	}
+	// Small caches are not split, each segment holds at least four of
+	// the largest objects.
+	n := c.maxSize / (4*c.maxObject + 1)
+	if n < 1 {
+		n = 1
+	}
+	if n > objectMemCacheSegments {
+		n = objectMemCacheSegments
+	}
+	// Size the sketches for objects of a quarter of the largest size.
+	width := c.maxSize / (c.maxObject/4 + 1) / n
+	if width < objectMemCacheMinSketch {
+		width = objectMemCacheMinSketch
+	}
+	if width > objectMemCacheMaxSketch {
+		width = objectMemCacheMaxSketch
+	}
+	c.segments = make([]*objectMemCacheSegment, n)
+	for i := range c.segments {
+		c.segments[i] = &objectMemCacheSegment{
+			maxSize: c.maxSize / n,
+			entries: make(map[string]*list.Element),
+			lru:     list.New(),
+			sketch:  newFrequencySketch(int(width)),
+		}
+	}
+}
+
+func (c *objectMemCache) enabled() bool {
+	return atomic.LoadInt64(&c.maxSize) > 0
+}
+
+func (c *objectMemCache) maxObjectSize() int64 {
+	return atomic.LoadInt64(&c.maxObject)
+}
+
+// segment returns the segment holding key, nil if the cache is disabled.
+func (c *objectMemCache) segment(key string) *objectMemCacheSegment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.segments) == 0 {
+		return nil
+	}
+	return c.segments[xxhash.Sum64String(key)>>32%uint64(len(c.segments))]
+}
+
+func (c *objectMemCache) allSegments() []*objectMemCacheSegment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.segments
+}
+
+// Stats returns the activity of the cache of this node.
+func (c *objectMemCache) Stats() ObjectMemCacheStats {
+	var objects int
+	var size int64
+	for _, s := range c.allSegments() {
+		s.mu.Lock()
+		objects += len(s.entries)
+		size += s.size
+		s.mu.Unlock()
+	}
+	return ObjectMemCacheStats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Admitted:      atomic.LoadUint64(&c.admitted),
+		Rejected:      atomic.LoadUint64(&c.rejected),
+		Invalidations: atomic.LoadUint64(&c.invalidations),
+		Objects:       objects,
+		Size:          size,
+	}
+}
+
+func objectMemCacheShard(key string) int {
+	return int(xxhash.Sum64String(key) % objectMemCacheShards)
+}
+
+// generation returns the generation of the shard of an object, to be
+// passed to add the object once read.
+func (c *objectMemCache) generation(bucket, object string) uint64 {
+	return atomic.LoadUint64(&c.generations[objectMemCacheShard(objectMemCacheKey(bucket, object))])
+}
+
+// get returns the cached info and content of an object and counts the
+// access.
+func (c *objectMemCache) get(bucket, object string) (ObjectInfo, []byte, bool) {
+	key := objectMemCacheKey(bucket, object)
+	s := c.segment(key)
+	if s == nil {
+		return ObjectInfo{}, nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sketch.increment(key)
+	e, ok := s.entries[key]
+	if ok && UTCNow().After(e.Value.(*objectMemCacheEntry).expires) {
+		s.removeLocked(e)
+		ok = false
+	}
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return ObjectInfo{}, nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	s.lru.MoveToFront(e)
+	entry := e.Value.(*objectMemCacheEntry)
+	objInfo := entry.objInfo
+	objInfo.UserDefined = cloneMSS(objInfo.UserDefined)
+	return objInfo, entry.data, true
+}
+
+// add caches an object read while the generation of its shard was gen,
+// if it is admitted.
+func (c *objectMemCache) add(bucket, object string, gen uint64, objInfo ObjectInfo, data []byte) {
+	key := objectMemCacheKey(bucket, object)
+	size := int64(len(data))
+	s := c.segment(key)
+	if s == nil || size > c.maxObjectSize() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if size > s.maxSize {
+		return
+	}
+	if atomic.LoadUint64(&c.generations[objectMemCacheShard(key)]) != gen {
+		return
+	}
+	if e, ok := s.entries[key]; ok {
+		s.removeLocked(e)
+	}
+
+	// Evict the least recently used objects accessed less often than
+	// the new one to make room for it.
+	freq := s.sketch.estimate(key)
+	var victims []*list.Element
+	free := s.maxSize - s.size
+	for e := s.lru.Back(); free < size && e != nil; e = e.Prev() {
+		victim := e.Value.(*objectMemCacheEntry)
+		if s.sketch.estimate(victim.key) >= freq {
+			atomic.AddUint64(&c.rejected, 1)
+			return
+		}
+		victims = append(victims, e)
+		free += int64(len(victim.data))
+	}
+	for _, e := range victims {
+		s.removeLocked(e)
+	}
+	objInfo.UserDefined = cloneMSS(objInfo.UserDefined)
+	s.entries[key] = s.lru.PushFront(&objectMemCacheEntry{
+		key:     key,
+		objInfo: objInfo,
+		data:    data,
+		expires: UTCNow().Add(objectMemCacheTTL),
+	})
+	s.size += size
+	atomic.AddUint64(&c.admitted, 1)
+}
+
+func (s *objectMemCacheSegment) removeLocked(e *list.Element) {
+	entry := s.lru.Remove(e).(*objectMemCacheEntry)
+	delete(s.entries, entry.key)
+	s.size -= int64(len(entry.data))
+}
+
+// invalidate drops objects of bucket from the cache of this node, all
+// objects of the bucket starting with the first object if prefix is set.
+func (c *objectMemCache) invalidate(bucket string, objects []string, prefix bool) {
+	if prefix {
+		for i := range c.generations {
+			atomic.AddUint64(&c.generations[i], 1)
+		}
+	} else {
+		for _, object := range objects {
+			atomic.AddUint64(&c.generations[objectMemCacheShard(objectMemCacheKey(bucket, object))], 1)
+		}
+	}
+	atomic.AddUint64(&c.invalidations, uint64(len(objects)))
+
+	if prefix {
+		keyPrefix := objectMemCacheKey(bucket, objects[0])
+		for _, s := range c.allSegments() {
+			s.mu.Lock()
+			for key, e := range s.entries {
+				if strings.HasPrefix(key, keyPrefix) {
+					s.removeLocked(e)
+				}
+			}
+			s.mu.Unlock()
+		}
+		return
+	}
+	for _, object := range objects {
+		key := objectMemCacheKey(bucket, object)
+		s := c.segment(key)
+		if s == nil {
+			return
+		}
+		s.mu.Lock()
+		if e, ok := s.entries[key]; ok {
+			s.removeLocked(e)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// objectMemCacheable returns whether a read of an object may be served
+// from the cache, only reads of the latest version of objects neither
+// SSE-C encrypted nor internal are.
+func objectMemCacheable(bucket string, h http.Header, opts ObjectOptions) bool {
+	return opts.VersionID == "" && opts.PartNumber == 0 && !opts.ProxyRequest &&
+		!isMinioMetaBucketName(bucket) && !crypto.SSEC.IsRequested(h)
+}
+
+// admissible returns whether the latest version of an object may be
+// cached.
+func (c *objectMemCache) admissible(objInfo ObjectInfo) bool {
+	if objInfo.DeleteMarker || objInfo.IsRemote() || objInfo.Size > c.maxObjectSize() {
+		return false
+	}
+	if _, encrypted := crypto.IsEncrypted(objInfo.UserDefined); encrypted {
+		return false
+	}
+	return true
+}
+
+// getObjectNInfo returns a reader of the cached content of an object,
+// nil if it is not cached.
+func (c *objectMemCache) getObjectNInfo(bucket, object string, rs *HTTPRangeSpec, opts ObjectOptions) (*GetObjectReader, error) {
+	objInfo, data, ok := c.get(bucket, object)
+	if !ok {
+		return nil, nil
+	}
+	if opts.CheckPrecondFn != nil && opts.CheckPrecondFn(objInfo) {
+		return nil, PreConditionFailed{}
+	}
+	off, length, err := rs.GetOffsetLength(int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return NewGetObjectReaderFromReader(bytes.NewReader(data[off:off+length]), objInfo, opts)
+}
+
+// fill caches the content of an object read whole by gr, once read if
+// the object was not written meanwhile.
+func (c *objectMemCache) fill(bucket, object string, gen uint64, gr *GetObjectReader) {
+	if !c.admissible(gr.ObjInfo) {
+		return
+	}
+	size, err := gr.ObjInfo.GetActualSize()
+	if err != nil || size > c.maxObjectSize() {
+		return
+	}
+	gr.Reader = &objectMemCacheFiller{
+		Reader: gr.Reader,
+		buf:    bytes.NewBuffer(make([]byte, 0, size)),
+		size:   size,
+		done: func(data []byte) {
+			c.add(bucket, object, gen, gr.ObjInfo, data)
+		},
+	}
+}
+
+// objectMemCacheFiller copies the content of an object read whole to
+// the cache.
+type objectMemCacheFiller struct {
+	io.Reader
+	buf  *bytes.Buffer
+	size int64
+	done func(data []byte)
+}
+
+func (f *objectMemCacheFiller) Read(p []byte) (int, error) {
+	n, err := f.Reader.Read(p)
+	if f.buf == nil {
+		return n, err
+	}
+	f.buf.Write(p[:n])
+	if int64(f.buf.Len()) > f.size {
+		f.buf = nil
+		return n, err
+	}
+	if err == io.EOF && int64(f.buf.Len()) == f.size {
+		f.done(f.buf.Bytes())
+		f.buf = nil
+	}
+	return n, err
+}
+
+// invalidateObjectMemCache drops objects written through this node from
+// the caches of all nodes, before the write is acknowledged. Writes
+// issued while the peers are called share their next call, unreachable
+// peers hold the write for objectMemCacheInvalidateTimeout at most.
+func invalidateObjectMemCache(ctx context.Context, bucket string, objects []string, prefix bool) {
+	if len(objects) == 0 || !globalObjectMemCache.enabled() {
+		return
+	}
+	globalObjectMemCache.invalidate(bucket, objects, prefix)
+	if globalNotificationSys == nil {
+		return
+	}
+	if prefix {
+		ctx, cancel := context.WithTimeout(ctx, objectMemCacheInvalidateTimeout)
+		defer cancel()
+		globalNotificationSys.InvalidateObjectMemCache(ctx, bucket, objects, prefix)
+		return
+	}
+	globalObjectMemCache.invalidatePeers(ctx, bucket, objects, globalNotificationSys.InvalidateObjectMemCache)
+}
+
+// invalidatePeers adds objects to the batch of the next call to the
+// peers and waits for it to be sent.
+func (c *objectMemCache) invalidatePeers(ctx context.Context, bucket string, objects []string,
+	send func(ctx context.Context, bucket string, objects []string, prefix bool)) {
+	c.batchMu.Lock()
+	b := c.batch
+	if b == nil {
+		b = &objectMemCacheBatch{objects: make(map[string][]string), done: make(chan struct{})}
+		c.batch = b
+	}
+	b.objects[bucket] = append(b.objects[bucket], objects...)
+	if !c.sending {
+		c.sending = true
+		go c.sendBatches(send)
+	}
+	c.batchMu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+	}
+}
+
+// sendBatches sends the batches of invalidations to the peers one after
+// the other until no write is waiting.
+func (c *objectMemCache) sendBatches(send func(ctx context.Context, bucket string, objects []string, prefix bool)) {
+	for {
+		c.batchMu.Lock()
+		b := c.batch
+		c.batch = nil
+		if b == nil {
+			c.sending = false
+			c.batchMu.Unlock()
+			return
+		}
+		c.batchMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(GlobalContext, objectMemCacheInvalidateTimeout)
+		for bucket, objects := range b.objects {
+			send(ctx, bucket, objects, false)
+		}
+		cancel()
+		close(b.done)
+	}
+}
+
+// frequencySketch is a count-min sketch of 4 bit counters estimating
+// the access frequency of keys, halved once the number of accesses
+// counted reaches 10 times its width so that it follows recent
+// popularity.
+type frequencySketch struct {
+	rows    [4][]uint8
+	mask    uint64
+	count   int
+	maxSize int
+}
+
+func newFrequencySketch(width int) *frequencySketch {
+	w := 1
+	for w < width {
+		w <<= 1
+	}
+	s := &frequencySketch{mask: uint64(w - 1), maxSize: 10 * w}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, w)
+	}
+	return s
+}
+
+func (s *frequencySketch) index(h uint64, i int) uint64 {
+	h1, h2 := h&0xffffffff, h>>32
+	return (h1 + uint64(i)*h2) & s.mask
+}
+
+func (s *frequencySketch) increment(key string) {
+	h := xxhash.Sum64String(key)
+	for i := range s.rows {
+		if idx := s.index(h, i); s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+	if s.count++; s.count >= s.maxSize {
+		s.count /= 2
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] /= 2
+			}
+		}
+	}
+}
+
+func (s *frequencySketch) estimate(key string) uint8 {
+	h := xxhash.Sum64String(key)
+	min := uint8(15)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestObjectMemCache(t *testing.T) {
+	c := &objectMemCache{}
+	c.setLimits(10, 4)
+
+	gen := c.generation("bucket", "a")
+	c.add("bucket", "a", gen, ObjectInfo{Name: "a", UserDefined: map[string]string{"k": "v"}}, []byte("aaaa"))
+	objInfo, data, ok := c.get("bucket", "a")
+	if !ok || string(data) != "aaaa" || objInfo.Name != "a" {
+		t.Fatalf("expected a to be cached, got %v %q", ok, data)
+	}
+	// The cached metadata may not be modified by callers.
+	objInfo.UserDefined["k"] = "changed"
+	if objInfo, _, _ = c.get("bucket", "a"); objInfo.UserDefined["k"] != "v" {
+		t.Errorf("cached metadata was modified")
+	}
+
+	// Objects larger than the largest size are not cached.
+	c.add("bucket", "large", c.generation("bucket", "large"), ObjectInfo{}, []byte("large"))
+	if _, _, ok = c.get("bucket", "large"); ok {
+		t.Errorf("expected a large object not to be cached")
+	}
+
+	// Objects read while written are not cached.
+	gen = c.generation("bucket", "b")
+	c.invalidate("bucket", []string{"b"}, false)
+	c.add("bucket", "b", gen, ObjectInfo{}, []byte("bbbb"))
+	if _, _, ok = c.get("bucket", "b"); ok {
+		t.Errorf("expected an object written while read not to be cached")
+	}
+	c.add("bucket", "b", c.generation("bucket", "b"), ObjectInfo{}, []byte("bbbb"))
+
+	// The cache is full, an object read once is not admitted in place
+	// of objects read more often.
+	c.add("bucket", "c", c.generation("bucket", "c"), ObjectInfo{}, []byte("cccc"))
+	if _, _, ok = c.get("bucket", "c"); ok {
+		t.Errorf("expected c not to be admitted")
+	}
+	// Once read more often it is, evicting the least recently used.
+	for i := 0; i < 5; i++ {
+		c.get("bucket", "c")
+	}
+	c.add("bucket", "c", c.generation("bucket", "c"), ObjectInfo{}, []byte("cccc"))
+	if _, _, ok = c.get("bucket", "c"); !ok {
+		t.Errorf("expected c to be admitted")
+	}
+	if _, _, ok = c.get("bucket", "a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	if stats := c.Stats(); stats.Size > 10 || stats.Objects != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	c.invalidate("bucket", []string{""}, true)
+	if stats := c.Stats(); stats.Objects != 0 || stats.Size != 0 {
+		t.Errorf("expected all objects of the bucket to be dropped, got %+v", stats)
+	}
+
+	c.setLimits(0, 4)
+	if c.enabled() {
+		t.Errorf("expected the cache to be disabled")
+	}
+}
+
+func TestObjectMemCacheFill(t *testing.T) {
+	c := &objectMemCache{}
+	c.setLimits(1<<20, 1<<10)
+
+	content := []byte("hello world")
+	gr := &GetObjectReader{Reader: bytes.NewReader(content), ObjInfo: ObjectInfo{Name: "obj", Size: int64(len(content))}}
+	c.fill("bucket", "obj", c.generation("bucket", "obj"), gr)
+	if data, err := ioutil.ReadAll(gr.Reader); err != nil || !bytes.Equal(data, content) {
+		t.Fatalf("unexpected content %q %v", data, err)
+	}
+
+	gr, err := c.getObjectNInfo("bucket", "obj", &HTTPRangeSpec{Start: 6, End: -1}, ObjectOptions{})
+	if err != nil || gr == nil {
+		t.Fatalf("expected obj to be cached, got %v", err)
+	}
+	if data, _ := ioutil.ReadAll(gr); string(data) != "world" {
+		t.Errorf("expected the range to be served, got %q", data)
+	}
+	if _, err = c.getObjectNInfo("bucket", "obj", &HTTPRangeSpec{Start: 20, End: -1}, ObjectOptions{}); err != errInvalidRange {
+		t.Errorf("expected an invalid range, got %v", err)
+	}
+}
+
+func TestObjectMemCacheHit(t *testing.T) {
+	c := &objectMemCache{}
+	c.setLimits(1<<20, 1<<10)
+	if n := len(c.allSegments()); n != objectMemCacheSegments {
+		t.Fatalf("expected %d segments, got %d", objectMemCacheSegments, n)
+	}
+
+	c.add("bucket", "obj", c.generation("bucket", "obj"), ObjectInfo{Name: "obj", ETag: "etag"}, []byte("content"))
+
+	// Preconditions are evaluated on hits.
+	opts := ObjectOptions{CheckPrecondFn: func(oi ObjectInfo) bool { return oi.ETag != "other" }}
+	if _, err := c.getObjectNInfo("bucket", "obj", nil, opts); err != (PreConditionFailed{}) {
+		t.Errorf("expected the precondition to fail, got %v", err)
+	}
+	opts.CheckPrecondFn = func(oi ObjectInfo) bool { return oi.ETag != "etag" }
+	if gr, err := c.getObjectNInfo("bucket", "obj", nil, opts); err != nil || gr == nil {
+		t.Errorf("expected obj to be served, got %v", err)
+	}
+
+	// Expired objects are read again.
+	key := objectMemCacheKey("bucket", "obj")
+	s := c.segment(key)
+	s.mu.Lock()
+	s.entries[key].Value.(*objectMemCacheEntry).expires = UTCNow().Add(-time.Second)
+	s.mu.Unlock()
+	if _, _, ok := c.get("bucket", "obj"); ok {
+		t.Errorf("expected an expired object not to be served")
+	}
+	if stats := c.Stats(); stats.Objects != 0 || stats.Size != 0 {
+		t.Errorf("expected the expired object to be dropped, got %+v", stats)
+	}
+}
+
+func TestObjectMemCacheInvalidatePeers(t *testing.T) {
+	c := &objectMemCache{}
+
+	var mu sync.Mutex
+	var calls int
+	sent := make(map[string]int)
+	release := make(chan struct{})
+	send := func(ctx context.Context, bucket string, objects []string, prefix bool) {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		sent[bucket] += len(objects)
+	}
+
+	// Writes issued while a batch is sent share the next call.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.invalidatePeers(context.Background(), "bucket", []string{"a"}, send)
+	}()
+	for {
+		c.batchMu.Lock()
+		sending := c.sending && c.batch == nil
+		c.batchMu.Unlock()
+		if sending {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.invalidatePeers(context.Background(), "bucket", []string{"b"}, send)
+		}()
+	}
+	for {
+		c.batchMu.Lock()
+		queued := c.batch != nil && len(c.batch.objects["bucket"]) == 10
+		c.batchMu.Unlock()
+		if queued {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if calls != 2 || sent["bucket"] != 11 {
+		t.Errorf("expected 2 calls for 11 objects, got %d calls %v", calls, sent)
+	}
+
+	// Writes do not wait longer than their context.
+	block := make(chan struct{})
+	defer close(block)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c.invalidatePeers(ctx, "bucket", []string{"c"}, func(context.Context, string, []string, bool) { <-block })
+}
+
+func TestFrequencySketch(t *testing.T) {
+	s := newFrequencySketch(16)
+	for i := 0; i < 20; i++ {
+		s.increment("hot")
+	}
+	s.increment("cold")
+	if s.estimate("hot") != 15 || s.estimate("cold") == 0 || s.estimate("cold") >= s.estimate("hot") {
+		t.Errorf("unexpected estimates hot %d cold %d", s.estimate("hot"), s.estimate("cold"))
+	}
+
+	// Counts are halved once enough accesses were counted.
+	for i := 0; i < s.maxSize; i++ {
+		s.increment("other")
+	}
+	if s.estimate("hot") > 8 {
+		t.Errorf("expected the counts to be aged, got %d", s.estimate("hot"))
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// Ranges of a multi-range GET may cover the object bytes they are merged
+// into at most this many times.
+const maxByteRangesOverlap = 2
+
+// byteRange - a range of an object resolved against its size.
+type byteRange struct {
+	start, length int64
+}
+
+// mergeByteRanges resolves ranges against an object of size bytes and
+// returns them sorted, overlapping and adjacent ranges being merged.
+// Ranges overlapping more than maxByteRangesOverlap times are rejected
+// like unsatisfiable ones.
+func mergeByteRanges(ranges []*HTTPRangeSpec, size int64) ([]byteRange, error) {
+	resolved := make([]byteRange, 0, len(ranges))
+	var requested int64
+	for _, rs := range ranges {
+		start, length, err := rs.GetOffsetLength(size)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, byteRange{start: start, length: length})
+		requested += length
+	}
+	if len(resolved) == 0 {
+		return nil, errInvalidRange
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].start < resolved[j].start
+	})
+
+	merged := []byteRange{resolved[0]}
+	covered := resolved[0].length
+	for _, br := range resolved[1:] {
+		last := &merged[len(merged)-1]
+		lastEnd := last.start + last.length
+		if br.start > lastEnd {
+			merged = append(merged, br)
+			covered += br.length
+			continue
+		}
+		if end := br.start + br.length; end > lastEnd {
+			last.length = end - last.start
+			covered += end - lastEnd
+		}
+	}
+	if requested > maxByteRangesOverlap*covered {
+		return nil, errInvalidRange
+	}
+	return merged, nil
+}
+
+// spanRange returns the range spanning all ranges, read at once so that
+// all ranges are read from the same version of the object under one
+// lock. The span starts at the first byte for suffix ranges along with
+// other ranges, their start is only known along with the object size.
+func spanRange(ranges []*HTTPRangeSpec) *HTTPRangeSpec {
+	var span, suffix *HTTPRangeSpec
+	for _, rs := range ranges {
+		if rs.IsSuffixLength {
+			if suffix == nil || rs.Start < suffix.Start {
+				suffix = &HTTPRangeSpec{IsSuffixLength: true, Start: rs.Start, End: -1}
+			}
+			continue
+		}
+		if span == nil {
+			span = &HTTPRangeSpec{Start: rs.Start, End: rs.End}
+			continue
+		}
+		if rs.Start < span.Start {
+			span.Start = rs.Start
+		}
+		if rs.End < 0 || (span.End >= 0 && rs.End > span.End) {
+			span.End = rs.End
+		}
+	}
+	switch {
+	case span == nil:
+		return suffix
+	case suffix != nil:
+		return &HTTPRangeSpec{Start: 0, End: -1}
+	}
+	return span
+}
+
+// multiRangeReader streams the ranges of an object as the parts of a
+// multipart/byteranges response body, reading them from the span of
+// the object holding them all.
+type multiRangeReader struct {
+	boundary string
+	size     int64
+	reader   io.Reader
+}
+
+// newMultiRangeReader returns the multipart/byteranges body of ranges,
+// as returned by mergeByteRanges, of an object of size bytes with the
+// given content type, read from span starting at offset spanStart.
+func newMultiRangeReader(span io.Reader, spanStart int64, ranges []byteRange, size int64, contentType string) *multiRangeReader {
+	m := &multiRangeReader{boundary: mustGetUUID()}
+	var readers []io.Reader
+	offset := spanStart
+	for i, br := range ranges {
+		var header bytes.Buffer
+		if i > 0 {
+			header.WriteString("\r\n")
+		}
+		fmt.Fprintf(&header, "--%s\r\n", m.boundary)
+		if contentType != "" {
+			fmt.Fprintf(&header, "Content-Type: %s\r\n", contentType)
+		}
+		fmt.Fprintf(&header, "Content-Range: bytes %d-%d/%d\r\n\r\n", br.start, br.start+br.length-1, size)
+		m.size += int64(header.Len()) + br.length
+		readers = append(readers, &header, newSpanRangeReader(span, br.start-offset, br.length))
+		offset = br.start + br.length
+	}
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", m.boundary)
+	m.size += int64(len(trailer))
+	m.reader = io.MultiReader(append(readers, bytes.NewReader([]byte(trailer)))...)
+	return m
+}
+
+func (m *multiRangeReader) Read(p []byte) (int, error) {
+	return m.reader.Read(p)
+}
+
+// spanRangeReader reads a range of a span, skipping the bytes before
+// the range on its first read.
+type spanRangeReader struct {
+	r         io.Reader
+	skip      int64
+	remaining int64
+}
+
+func newSpanRangeReader(span io.Reader, skip, length int64) *spanRangeReader {
+	return &spanRangeReader{r: span, skip: skip, remaining: length}
+}
+
+func (s *spanRangeReader) Read(p []byte) (n int, err error) {
+	if s.skip > 0 {
+		skipped, err := io.CopyN(ioutil.Discard, s.r, s.skip)
+		s.skip -= skipped
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+	if s.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+	n, err = s.r.Read(p)
+	s.remaining -= int64(n)
+	if s.remaining == 0 {
+		return n, nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
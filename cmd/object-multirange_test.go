@@ -0,0 +1,137 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"testing"
+)
+
+func TestMergeByteRanges(t *testing.T) {
+	testCases := []struct {
+		ranges []*HTTPRangeSpec
+		merged []byteRange
+		err    error
+	}{
+		{
+			ranges: []*HTTPRangeSpec{{false, 10, 12}, {false, 0, 3}, {true, -2, -1}},
+			merged: []byteRange{{0, 4}, {10, 3}, {18, 2}},
+		},
+		// Overlapping and adjacent ranges are merged.
+		{
+			ranges: []*HTTPRangeSpec{{false, 5, 9}, {false, 0, 5}, {false, 10, 11}, {false, 15, -1}, {true, -3, -1}},
+			merged: []byteRange{{0, 12}, {15, 5}},
+		},
+		{
+			ranges: []*HTTPRangeSpec{{false, 0, 9}, {false, 0, 9}},
+			merged: []byteRange{{0, 10}},
+		},
+		// Overlapping more than twice.
+		{
+			ranges: []*HTTPRangeSpec{{false, 0, 9}, {false, 0, 9}, {false, 0, 9}},
+			err:    errInvalidRange,
+		},
+		{
+			ranges: []*HTTPRangeSpec{{false, 0, 3}, {false, 20, 21}},
+			err:    errInvalidRange,
+		},
+	}
+	for i, testCase := range testCases {
+		merged, err := mergeByteRanges(testCase.ranges, 20)
+		if err != testCase.err {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.err, err)
+		}
+		if err == nil && !reflect.DeepEqual(merged, testCase.merged) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.merged, merged)
+		}
+	}
+}
+
+func TestSpanRange(t *testing.T) {
+	testCases := []struct {
+		ranges []*HTTPRangeSpec
+		span   HTTPRangeSpec
+	}{
+		{[]*HTTPRangeSpec{{false, 10, 12}, {false, 4, 6}}, HTTPRangeSpec{false, 4, 12}},
+		{[]*HTTPRangeSpec{{false, 10, 12}, {false, 4, -1}}, HTTPRangeSpec{false, 4, -1}},
+		{[]*HTTPRangeSpec{{true, -2, -1}, {true, -8, -1}}, HTTPRangeSpec{true, -8, -1}},
+		{[]*HTTPRangeSpec{{false, 10, 12}, {true, -2, -1}}, HTTPRangeSpec{false, 0, -1}},
+	}
+	for i, testCase := range testCases {
+		if span := spanRange(testCase.ranges); *span != testCase.span {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.span, *span)
+		}
+	}
+}
+
+func TestMultiRangeReader(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	merged, err := mergeByteRanges([]*HTTPRangeSpec{{false, 10, 12}, {false, 2, 3}, {true, -2, -1}}, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The span from the first range on.
+	mr := newMultiRangeReader(bytes.NewReader(data[2:]), 2, merged, int64(len(data)), "text/plain")
+
+	body, err := ioutil.ReadAll(mr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(body)) != mr.size {
+		t.Fatalf("expected a body of %d bytes, got %d", mr.size, len(body))
+	}
+
+	_, params, err := mime.ParseMediaType("multipart/byteranges; boundary=" + mr.boundary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		contentRange, data string
+	}{
+		{"bytes 2-3/20", "23"},
+		{"bytes 10-12/20", "abc"},
+		{"bytes 18-19/20", "ij"},
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for i, exp := range expected {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if part.Header.Get("Content-Range") != exp.contentRange || part.Header.Get("Content-Type") != "text/plain" {
+			t.Errorf("part %d: unexpected headers %v", i, part.Header)
+		}
+		if b, _ := ioutil.ReadAll(part); string(b) != exp.data {
+			t.Errorf("part %d: expected %q, got %q", i, exp.data, b)
+		}
+	}
+	if _, err = reader.NextPart(); err != io.EOF {
+		t.Errorf("expected the last part, got %v", err)
+	}
+
+	// A span cut short fails the body.
+	mr = newMultiRangeReader(bytes.NewReader(data[2:12]), 2, merged, int64(len(data)), "")
+	if _, err = ioutil.ReadAll(mr); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected a short span to fail, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+// errRenameNeedsCopy - the object cannot be renamed in place.
+var errRenameNeedsCopy = errors.New("rename needs a copy of the object data")
+
+// renameObject renames srcObject to dstObject in bucket. The rename only
+// touches metadata when both names live in the same erasure set of an
+// unversioned bucket, otherwise the data is copied to dstObject and
+// srcObject is deleted.
+func renameObject(ctx context.Context, objAPI ObjectLayer, bucket, srcObject, dstObject string) (ObjectInfo, error) {
+	versioned := globalBucketVersioningSys.Enabled(bucket)
+	versionSuspended := globalBucketVersioningSys.Suspended(bucket)
+
+	// Versioned buckets keep the history of srcObject, which moving
+	// the namespace entry would take along.
+	if z, ok := objAPI.(*erasureServerPools); ok && !versioned && !versionSuspended {
+		oi, err := z.renameObject(ctx, bucket, srcObject, dstObject)
+		if err != errRenameNeedsCopy {
+			return oi, err
+		}
+	}
+
+	gr, err := objAPI.GetObjectNInfo(ctx, bucket, srcObject, nil, nil, readLock, ObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer gr.Close()
+
+	srcInfo := gr.ObjInfo
+	if _, encrypted := crypto.IsEncrypted(srcInfo.UserDefined); encrypted || srcInfo.IsCompressed() {
+		return ObjectInfo{}, NotImplemented{
+			Message: "Renaming encrypted or compressed objects is only supported within an erasure set",
+		}
+	}
+
+	hr, err := hash.NewReader(gr, srcInfo.Size, "", "", srcInfo.Size)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	metadata := cloneMSS(srcInfo.UserDefined)
+	delete(metadata, xhttp.AmzBucketReplicationStatus)
+	objInfo, err := objAPI.PutObject(ctx, bucket, dstObject, NewPutObjReader(hr), ObjectOptions{
+		UserDefined:      metadata,
+		MTime:            srcInfo.ModTime,
+		Versioned:        versioned,
+		VersionSuspended: versionSuspended,
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Release the read lock held on srcObject before deleting it.
+	gr.Close()
+	_, err = objAPI.DeleteObject(ctx, bucket, srcObject, ObjectOptions{
+		Versioned:        versioned,
+		VersionSuspended: versionSuspended,
+	})
+	return objInfo, err
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRenameObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket := "bucket"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 1<<20)
+	for _, name := range []string{"src/object", "existing"} {
+		if _, err = objLayer.PutObject(ctx, bucket, name, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		src, dst string
+	}{
+		{"src/object", "dst/renamed"},
+		// Renaming replaces an existing destination.
+		{"dst/renamed", "existing"},
+	}
+	for i, tc := range testCases {
+		oi, err := renameObject(ctx, objLayer, bucket, tc.src, tc.dst)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if oi.Name != tc.dst || oi.Size != int64(len(data)) {
+			t.Fatalf("Test %d: unexpected object info %#v", i+1, oi)
+		}
+		if _, err = objLayer.GetObjectInfo(ctx, bucket, tc.src, ObjectOptions{}); !isErrObjectNotFound(err) {
+			t.Fatalf("Test %d: expected %s to be gone, got %v", i+1, tc.src, err)
+		}
+		gr, err := objLayer.GetObjectNInfo(ctx, bucket, tc.dst, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Test %d: renamed object content differs", i+1)
+		}
+	}
+
+	// Objects nested under the names of the source and the destination
+	// are left in place.
+	for _, name := range []string{"a", "a/child", "b", "b/child"} {
+		if _, err = objLayer.PutObject(ctx, bucket, name, mustGetPutObjReader(t, bytes.NewReader([]byte(name)), int64(len(name)), "", ""), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = renameObject(ctx, objLayer, bucket, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"a/child": "a/child", "b": "a", "b/child": "b/child"} {
+		gr, err := objLayer.GetObjectNInfo(ctx, bucket, name, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("expected %s after renaming, got %v", name, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Fatalf("expected %s to hold %q, got %q", name, content, got)
+		}
+	}
+	if _, err = objLayer.GetObjectInfo(ctx, bucket, "a", ObjectOptions{}); !isErrObjectNotFound(err) {
+		t.Fatalf("expected a to be gone, got %v", err)
+	}
+
+	if _, err = renameObject(ctx, objLayer, bucket, "missing", "other"); !isErrObjectNotFound(err) {
+		t.Fatalf("expected object not found, got %v", err)
+	}
+}
@@ -657,10 +657,12 @@ type serverUpdateInfo struct {
 	Sha256Sum   []byte
 	Time        time.Time
 	ReleaseInfo string
+	// Keep the replaced binary to allow a rollback.
+	KeepOld bool
 }
 
 // ServerUpdate - sends server update message to remote peers.
-func (client *peerRESTClient) ServerUpdate(ctx context.Context, u *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string) error {
+func (client *peerRESTClient) ServerUpdate(ctx context.Context, u *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string, keepOld bool) error {
 	values := make(url.Values)
 	var reader bytes.Buffer
 	if err := gob.NewEncoder(&reader).Encode(serverUpdateInfo{
@@ -668,6 +670,7 @@ func (client *peerRESTClient) ServerUpdate(ctx context.Context, u *url.URL, sha2
 		Sha256Sum:   sha256Sum,
 		Time:        lrTime,
 		ReleaseInfo: releaseInfo,
+		KeepOld:     keepOld,
 	}); err != nil {
 		return err
 	}
@@ -679,6 +682,16 @@ func (client *peerRESTClient) ServerUpdate(ctx context.Context, u *url.URL, sha2
 	return nil
 }
 
+// ServerUpdateRollback - restores the binary replaced by the last update on a remote node.
+func (client *peerRESTClient) ServerUpdateRollback(ctx context.Context) error {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodServerUpdateRollback, nil, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
 // SignalService - sends signal to peer nodes.
 func (client *peerRESTClient) SignalService(sig serviceSignal) error {
 	values := make(url.Values)
@@ -762,7 +775,7 @@ func (client *peerRESTClient) LoadTransitionTierConfig(ctx context.Context) erro
 	return nil
 }
 
-func (client *peerRESTClient) doTrace(traceCh chan interface{}, doneCh <-chan struct{}, traceOpts madmin.ServiceTraceOpts) {
+func (client *peerRESTClient) doTrace(traceCh chan interface{}, doneCh <-chan struct{}, traceOpts traceOptions) {
 	values := make(url.Values)
 	values.Set(peerRESTTraceErr, strconv.FormatBool(traceOpts.OnlyErrors))
 	values.Set(peerRESTTraceS3, strconv.FormatBool(traceOpts.S3))
@@ -770,6 +783,7 @@ func (client *peerRESTClient) doTrace(traceCh chan interface{}, doneCh <-chan st
 	values.Set(peerRESTTraceOS, strconv.FormatBool(traceOpts.OS))
 	values.Set(peerRESTTraceInternal, strconv.FormatBool(traceOpts.Internal))
 	values.Set(peerRESTTraceThreshold, traceOpts.Threshold.String())
+	setTraceFilters(values, traceOpts)
 
 	// To cancel the REST request in case doneCh gets closed.
 	ctx, cancel := context.WithCancel(GlobalContext)
@@ -863,7 +877,7 @@ func (client *peerRESTClient) Listen(listenCh chan interface{}, doneCh <-chan st
 }
 
 // Trace - send http trace request to peer nodes
-func (client *peerRESTClient) Trace(traceCh chan interface{}, doneCh <-chan struct{}, traceOpts madmin.ServiceTraceOpts) {
+func (client *peerRESTClient) Trace(traceCh chan interface{}, doneCh <-chan struct{}, traceOpts traceOptions) {
 	go func() {
 		for {
 			client.doTrace(traceCh, doneCh, traceOpts)
@@ -1013,6 +1027,25 @@ func (client *peerRESTClient) GetPeerMetrics(ctx context.Context) (<-chan Metric
 	return ch, nil
 }
 
+// Benchmark - runs the workload benchmark on a remote node.
+func (client *peerRESTClient) Benchmark(ctx context.Context, opts BenchmarkOpts) (stats benchmarkNodeStats, err error) {
+	var reader bytes.Buffer
+	if err = gob.NewEncoder(&reader).Encode(opts); err != nil {
+		return stats, err
+	}
+	respBody, err := client.callWithContext(ctx, peerRESTMethodBenchmark, nil, &reader, -1)
+	if err != nil {
+		return stats, err
+	}
+	defer http.DrainBody(respBody)
+	waitReader, err := waitForHTTPResponse(respBody)
+	if err != nil {
+		return stats, err
+	}
+	err = gob.NewDecoder(waitReader).Decode(&stats)
+	return stats, err
+}
+
 func (client *peerRESTClient) Speedtest(ctx context.Context, size, concurrent int, duration time.Duration) (SpeedtestResult, error) {
 	values := make(url.Values)
 	values.Set(peerRESTSize, strconv.Itoa(size))
@@ -1048,3 +1081,163 @@ func (client *peerRESTClient) ReloadSiteReplicationConfig(ctx context.Context) e
 	defer http.DrainBody(respBody)
 	return nil
 }
+
+// LoadTenants - tells a remote node to reload the tenants.
+func (client *peerRESTClient) LoadTenants(ctx context.Context) error {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodLoadTenants, nil, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
+// LoadBucketTemplates - tells a remote node to reload the bucket templates.
+func (client *peerRESTClient) LoadBucketTemplates(ctx context.Context) error {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodLoadBucketTemplates, nil, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
+// GetExtractProgress - fetch the progress of the archives being
+// extracted on a remote node.
+func (client *peerRESTClient) GetExtractProgress(ctx context.Context) (progress []ExtractProgress, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodGetExtractProgress, nil, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&progress)
+	return progress, err
+}
+
+// AddPool - runs a phase of the expansion to the pools of args on a
+// remote node.
+func (client *peerRESTClient) AddPool(ctx context.Context, args []string, phase string) error {
+	values := make(url.Values)
+	values.Set(peerRESTPhase, phase)
+	var reader bytes.Buffer
+	if err := gob.NewEncoder(&reader).Encode(args); err != nil {
+		return err
+	}
+	respBody, err := client.callWithContext(ctx, peerRESTMethodAddPool, values, &reader, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
+// GetInflightRequests - fetch the S3 requests in flight on a remote node.
+func (client *peerRESTClient) GetInflightRequests(ctx context.Context) (reqs []InflightRequest, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodGetInflightRequests, nil, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&reqs)
+	return reqs, err
+}
+
+// GetSLOCounts - fetch the calls counted by the objectives of a remote node.
+func (client *peerRESTClient) GetSLOCounts(ctx context.Context) (counts []SLONodeCounts, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodGetSLOCounts, nil, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&counts)
+	return counts, err
+}
+
+// GetSlowRequests - fetch the slow requests of a remote node matching f.
+func (client *peerRESTClient) GetSlowRequests(ctx context.Context, f slowRequestFilter) (reqs []SlowRequest, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodGetSlowRequests, f.values(), nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&reqs)
+	return reqs, err
+}
+
+// InvalidateObjectMemCache - drop objects of bucket from the object
+// memory cache of a remote node, all objects starting with the first
+// object if prefix is set.
+func (client *peerRESTClient) InvalidateObjectMemCache(ctx context.Context, bucket string, objects []string, prefix bool) error {
+	values := make(url.Values)
+	values.Set(peerRESTBucket, bucket)
+	values.Set(peerRESTIsPrefix, strconv.FormatBool(prefix))
+	var reader bytes.Buffer
+	if err := gob.NewEncoder(&reader).Encode(objects); err != nil {
+		return err
+	}
+	respBody, err := client.callWithContext(ctx, peerRESTMethodInvalidateObjectMemCache, values, &reader, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
+// CancelInflightRequest - cancel an S3 request in flight on a remote node,
+// returns false if the node does not serve a request with this id.
+func (client *peerRESTClient) CancelInflightRequest(ctx context.Context, id string) (found bool, err error) {
+	values := make(url.Values)
+	values.Set(peerRESTRequestID, id)
+	respBody, err := client.callWithContext(ctx, peerRESTMethodCancelInflightRequest, values, nil, -1)
+	if err != nil {
+		return false, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&found)
+	return found, err
+}
+
+// Drain - starts draining a remote node.
+func (client *peerRESTClient) Drain(ctx context.Context, timeout time.Duration) (status DrainStatus, err error) {
+	values := make(url.Values)
+	values.Set(peerRESTDuration, timeout.String())
+	respBody, err := client.callWithContext(ctx, peerRESTMethodDrain, values, nil, -1)
+	if err != nil {
+		return status, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&status)
+	return status, err
+}
+
+// DrainStatus - returns the drain status of a remote node.
+func (client *peerRESTClient) DrainStatus(ctx context.Context) (status DrainStatus, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodDrainStatus, nil, nil, -1)
+	if err != nil {
+		return status, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&status)
+	return status, err
+}
+
+// LoadJobSchedules - tells a remote node to reload the job schedules.
+func (client *peerRESTClient) LoadJobSchedules(ctx context.Context) error {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodLoadJobSchedules, nil, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer http.DrainBody(respBody)
+	return nil
+}
+
+// GetJobStatus - fetch the state of the background jobs of a remote node.
+func (client *peerRESTClient) GetJobStatus(ctx context.Context) (jobs []JobStatus, err error) {
+	respBody, err := client.callWithContext(ctx, peerRESTMethodGetJobStatus, nil, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer http.DrainBody(respBody)
+	err = gob.NewDecoder(respBody).Decode(&jobs)
+	return jobs, err
+}
@@ -18,7 +18,7 @@
 package cmd
 
 const (
-	peerRESTVersion       = "v15" // Add LoadTransitionTierConfig
+	peerRESTVersion       = "v22" // Add pool expansion
 	peerRESTVersionPrefix = SlashSeparator + peerRESTVersion
 	peerRESTPrefix        = minioReservedBucketPath + "/peer"
 	peerRESTPath          = peerRESTPrefix + peerRESTVersionPrefix
@@ -67,6 +67,21 @@ const (
 	peerRESTMethodLoadTransitionTierConfig    = "/loadtransitiontierconfig"
 	peerRESTMethodSpeedtest                   = "/speedtest"
 	peerRESTMethodReloadSiteReplicationConfig = "/reloadsitereplicationconfig"
+	peerRESTMethodGetInflightRequests         = "/getinflightrequests"
+	peerRESTMethodCancelInflightRequest       = "/cancelinflightrequest"
+	peerRESTMethodDrain                       = "/drain"
+	peerRESTMethodDrainStatus                 = "/drainstatus"
+	peerRESTMethodServerUpdateRollback        = "/serverupdaterollback"
+	peerRESTMethodBenchmark                   = "/benchmark"
+	peerRESTMethodLoadTenants                 = "/loadtenants"
+	peerRESTMethodGetExtractProgress          = "/getextractprogress"
+	peerRESTMethodAddPool                     = "/addpool"
+	peerRESTMethodGetSLOCounts                = "/getslocounts"
+	peerRESTMethodGetSlowRequests             = "/getslowrequests"
+	peerRESTMethodInvalidateObjectMemCache    = "/invalidateobjectmemcache"
+	peerRESTMethodLoadJobSchedules            = "/loadjobschedules"
+	peerRESTMethodGetJobStatus                = "/getjobstatus"
+	peerRESTMethodLoadBucketTemplates         = "/loadbuckettemplates"
 )
 
 const (
@@ -89,6 +104,9 @@ const (
 	peerRESTSize           = "size"
 	peerRESTConcurrent     = "concurrent"
 	peerRESTDuration       = "duration"
+	peerRESTRequestID      = "request-id"
+	peerRESTPhase          = "phase"
+	peerRESTIsPrefix       = "is-prefix"
 
 	peerRESTListenBucket = "bucket"
 	peerRESTListenPrefix = "prefix"
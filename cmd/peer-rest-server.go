@@ -577,6 +577,7 @@ func (s *peerRESTServer) DeleteBucketMetadataHandler(w http.ResponseWriter, r *h
 	globalReplicationStats.Delete(bucketName)
 	globalBucketMetadataSys.Remove(bucketName)
 	globalBucketTargetSys.Delete(bucketName)
+	globalBucketLatencyStats.forget(bucketName)
 	if localMetacacheMgr != nil {
 		localMetacacheMgr.deleteBucketCache(bucketName)
 	}
@@ -600,6 +601,221 @@ func (s *peerRESTServer) ReloadSiteReplicationConfigHandler(w http.ResponseWrite
 	logger.LogIf(r.Context(), globalSiteReplicationSys.Init(ctx, objAPI))
 }
 
+// LoadTenantsHandler - reloads the tenants from the disks.
+func (s *peerRESTServer) LoadTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		s.writeErrorResponse(w, errServerNotInitialized)
+		return
+	}
+
+	if err := globalTenantSys.Init(r.Context(), objAPI); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+}
+
+// LoadBucketTemplatesHandler - reloads the bucket templates from the disks.
+func (s *peerRESTServer) LoadBucketTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		s.writeErrorResponse(w, errServerNotInitialized)
+		return
+	}
+
+	if err := globalBucketTemplateSys.Init(r.Context(), objAPI); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+}
+
+// GetExtractProgressHandler - returns the progress of the archives being
+// extracted on this node.
+func (s *peerRESTServer) GetExtractProgressHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	ctx := newContext(r, w, "GetExtractProgress")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalExtractTracker.list()))
+}
+
+// AddPoolHandler - runs a phase of the expansion to the pools of the
+// arguments on this node.
+func (s *peerRESTServer) AddPoolHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		s.writeErrorResponse(w, errServerNotInitialized)
+		return
+	}
+
+	var args []string
+	if err := gob.NewDecoder(r.Body).Decode(&args); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	if err := activatePool(r.Context(), objAPI, args, r.Form.Get(peerRESTPhase)); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+}
+
+// GetInflightRequestsHandler - returns the S3 requests in flight on this node.
+func (s *peerRESTServer) GetInflightRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	ctx := newContext(r, w, "GetInflightRequests")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalInflightRequests.list()))
+}
+
+// GetSLOCountsHandler - returns the calls counted by the objectives of this node.
+func (s *peerRESTServer) GetSLOCountsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	ctx := newContext(r, w, "GetSLOCounts")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalSLOTracker.nodeCounts(UTCNow())))
+}
+
+// GetSlowRequestsHandler - returns the slow requests of this node.
+func (s *peerRESTServer) GetSlowRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	f, err := parseSlowRequestFilter(r.Form)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	ctx := newContext(r, w, "GetSlowRequests")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalSlowRequests.query(f)))
+}
+
+// LoadJobSchedulesHandler - reloads the job schedules from the disks.
+func (s *peerRESTServer) LoadJobSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		s.writeErrorResponse(w, errServerNotInitialized)
+		return
+	}
+
+	if err := globalJobScheduler.Init(r.Context(), objAPI); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+}
+
+// GetJobStatusHandler - returns the state of the background jobs of
+// this node.
+func (s *peerRESTServer) GetJobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	ctx := newContext(r, w, "GetJobStatus")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalJobScheduler.status(UTCNow())))
+}
+
+// InvalidateObjectMemCacheHandler - drops objects written through another
+// node from the object memory cache of this node.
+func (s *peerRESTServer) InvalidateObjectMemCacheHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	bucket := r.Form.Get(peerRESTBucket)
+	if bucket == "" {
+		s.writeErrorResponse(w, errors.New("Bucket name is missing"))
+		return
+	}
+
+	var objects []string
+	if err := gob.NewDecoder(r.Body).Decode(&objects); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	if len(objects) > 0 {
+		globalObjectMemCache.invalidate(bucket, objects, r.Form.Get(peerRESTIsPrefix) == "true")
+	}
+}
+
+// CancelInflightRequestHandler - cancels an S3 request in flight on this node.
+func (s *peerRESTServer) CancelInflightRequestHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	id := r.Form.Get(peerRESTRequestID)
+	if id == "" {
+		s.writeErrorResponse(w, errors.New("request id is missing"))
+		return
+	}
+
+	ctx := newContext(r, w, "CancelInflightRequest")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalInflightRequests.cancel(id)))
+}
+
+// DrainHandler - starts draining this node.
+func (s *peerRESTServer) DrainHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	timeout, err := time.ParseDuration(r.Form.Get(peerRESTDuration))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	ctx := newContext(r, w, "Drain")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalNodeDrain.start(timeout)))
+}
+
+// DrainStatusHandler - returns the drain status of this node.
+func (s *peerRESTServer) DrainStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	ctx := newContext(r, w, "DrainStatus")
+	logger.LogIf(ctx, gob.NewEncoder(w).Encode(globalNodeDrain.status()))
+}
+
 // GetBucketStatsHandler - fetches current in-memory bucket stats, currently only
 // returns BucketReplicationStatus
 func (s *peerRESTServer) GetBucketStatsHandler(w http.ResponseWriter, r *http.Request) {
@@ -756,8 +972,8 @@ func (s *peerRESTServer) PutBucketNotificationHandler(w http.ResponseWriter, r *
 func getLocalDiskIDs(z *erasureServerPools) []string {
 	var ids []string
 
-	for poolIdx := range z.serverPools {
-		for _, set := range z.serverPools[poolIdx].sets {
+	for _, pool := range z.currentPools() {
+		for _, set := range pool.sets {
 			disks := set.getDisks()
 			for _, disk := range disks {
 				if disk == nil {
@@ -832,7 +1048,20 @@ func (s *peerRESTServer) ServerUpdateHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if _, err = updateServer(info.URL, info.Sha256Sum, info.Time, info.ReleaseInfo, getMinioMode()); err != nil {
+	if _, err = updateServer(info.URL, info.Sha256Sum, info.Time, info.ReleaseInfo, getMinioMode(), info.KeepOld); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+}
+
+// ServerUpdateRollbackHandler - restores the binary replaced by the last update.
+func (s *peerRESTServer) ServerUpdateRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	if err := rollbackUpdate(); err != nil {
 		s.writeErrorResponse(w, err)
 		return
 	}
@@ -880,6 +1109,16 @@ func (s *peerRESTServer) SignalServiceHandler(w http.ResponseWriter, r *http.Req
 			s.writeErrorResponse(w, err)
 		}
 		return
+	case serviceReload:
+		objAPI := newObjectLayerFn()
+		if objAPI == nil {
+			s.writeErrorResponse(w, errServerNotInitialized)
+			return
+		}
+		if err = reloadServerConfig(r.Context(), objAPI); err != nil {
+			s.writeErrorResponse(w, err)
+		}
+		return
 	default:
 		s.writeErrorResponse(w, errUnsupportedSignal)
 		return
@@ -983,7 +1222,7 @@ func (s *peerRESTServer) ListenHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func extractTraceOptsFromPeerRequest(r *http.Request) (opts madmin.ServiceTraceOpts, err error) {
+func extractTraceOptsFromPeerRequest(r *http.Request) (opts traceOptions, err error) {
 	opts.S3 = r.Form.Get(peerRESTTraceS3) == "true"
 	opts.OS = r.Form.Get(peerRESTTraceOS) == "true"
 	opts.Storage = r.Form.Get(peerRESTTraceStorage) == "true"
@@ -997,6 +1236,7 @@ func extractTraceOptsFromPeerRequest(r *http.Request) (opts madmin.ServiceTraceO
 		}
 		opts.Threshold = d
 	}
+	err = parseTraceFilters(r.Form, &opts)
 	return
 }
 
@@ -1355,6 +1595,31 @@ func (s *peerRESTServer) SpeedtestHandler(w http.ResponseWriter, r *http.Request
 	w.(http.Flusher).Flush()
 }
 
+// BenchmarkHandler - runs the workload benchmark on this node.
+func (s *peerRESTServer) BenchmarkHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("Invalid request"))
+		return
+	}
+
+	var opts BenchmarkOpts
+	if err := gob.NewDecoder(r.Body).Decode(&opts); err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	done := keepHTTPResponseAlive(w)
+
+	stats, err := selfBenchmark(r.Context(), opts)
+	if err != nil {
+		stats.Error = err.Error()
+	}
+
+	done(nil)
+	logger.LogIf(r.Context(), gob.NewEncoder(w).Encode(stats))
+	w.(http.Flusher).Flush()
+}
+
 // registerPeerRESTHandlers - register peer rest router.
 func registerPeerRESTHandlers(router *mux.Router) {
 	server := &peerRESTServer{}
@@ -1379,6 +1644,7 @@ func registerPeerRESTHandlers(router *mux.Router) {
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetBucketStats).HandlerFunc(httpTraceHdrs(server.GetBucketStatsHandler)).Queries(restQueries(peerRESTBucket)...)
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodSignalService).HandlerFunc(httpTraceHdrs(server.SignalServiceHandler)).Queries(restQueries(peerRESTSignal)...)
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodServerUpdate).HandlerFunc(httpTraceHdrs(server.ServerUpdateHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodServerUpdateRollback).HandlerFunc(httpTraceHdrs(server.ServerUpdateRollbackHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDeletePolicy).HandlerFunc(httpTraceAll(server.DeletePolicyHandler)).Queries(restQueries(peerRESTPolicy)...)
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadPolicy).HandlerFunc(httpTraceAll(server.LoadPolicyHandler)).Queries(restQueries(peerRESTPolicy)...)
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadPolicyMapping).HandlerFunc(httpTraceAll(server.LoadPolicyMappingHandler)).Queries(restQueries(peerRESTUserOrGroup)...)
@@ -1401,5 +1667,19 @@ func registerPeerRESTHandlers(router *mux.Router) {
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetPeerMetrics).HandlerFunc(httpTraceHdrs(server.GetPeerMetrics))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadTransitionTierConfig).HandlerFunc(httpTraceHdrs(server.LoadTransitionTierConfigHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodSpeedtest).HandlerFunc(httpTraceHdrs(server.SpeedtestHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodBenchmark).HandlerFunc(httpTraceHdrs(server.BenchmarkHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodReloadSiteReplicationConfig).HandlerFunc(httpTraceHdrs(server.ReloadSiteReplicationConfigHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadTenants).HandlerFunc(httpTraceHdrs(server.LoadTenantsHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetExtractProgress).HandlerFunc(httpTraceHdrs(server.GetExtractProgressHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodAddPool).HandlerFunc(httpTraceHdrs(server.AddPoolHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetInflightRequests).HandlerFunc(httpTraceHdrs(server.GetInflightRequestsHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetSLOCounts).HandlerFunc(httpTraceHdrs(server.GetSLOCountsHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetSlowRequests).HandlerFunc(httpTraceHdrs(server.GetSlowRequestsHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodInvalidateObjectMemCache).HandlerFunc(httpTraceHdrs(server.InvalidateObjectMemCacheHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadJobSchedules).HandlerFunc(httpTraceHdrs(server.LoadJobSchedulesHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodGetJobStatus).HandlerFunc(httpTraceHdrs(server.GetJobStatusHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodLoadBucketTemplates).HandlerFunc(httpTraceHdrs(server.LoadBucketTemplatesHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodCancelInflightRequest).HandlerFunc(httpTraceHdrs(server.CancelInflightRequestHandler)).Queries(restQueries(peerRESTRequestID)...)
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDrain).HandlerFunc(httpTraceHdrs(server.DrainHandler)).Queries(restQueries(peerRESTDuration)...)
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDrainStatus).HandlerFunc(httpTraceHdrs(server.DrainStatusHandler))
 }
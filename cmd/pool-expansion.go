@@ -0,0 +1,331 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/set"
+	"github.com/minio/minio/internal/config/storageclass"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// poolExpansionFile in the config directory holds the arguments of
+	// the pools added online, used instead of the command line arguments
+	// they extend on restart.
+	poolExpansionFile = "pools.json"
+
+	poolExpansionTimeout = 10 * time.Minute
+)
+
+var (
+	// globalServerCmdArgs holds the arguments of the current pools.
+	globalServerCmdArgs []string
+
+	// poolExpansionMu serializes pool expansions of this node.
+	poolExpansionMu sync.Mutex
+
+	errPoolExpansionSetup = errors.New("Pools can only be added online to distributed erasure coded setups")
+)
+
+// PoolExpansionResult - the outcome of adding a pool on one node.
+type PoolExpansionResult struct {
+	Host  string `json:"host"`
+	Error string `json:"error,omitempty"`
+}
+
+// PoolExpansionInfo - the pools after an expansion and the outcome per
+// node of its last phase run, nodes being validated before the pool is
+// prepared on any, and prepared on all before it is committed on any.
+type PoolExpansionInfo struct {
+	Args      []string              `json:"args"`
+	Phase     string                `json:"phase"`
+	Validated bool                  `json:"validated"`
+	Committed bool                  `json:"committed"`
+	Results   []PoolExpansionResult `json:"results"`
+}
+
+func poolExpansionPath() string {
+	return filepath.Join(globalConfigDir.Get(), poolExpansionFile)
+}
+
+// withExpandedPools returns the arguments of the pools added online if
+// they extend args, args otherwise.
+func withExpandedPools(args []string) []string {
+	data, err := ioutil.ReadFile(poolExpansionPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.LogIf(GlobalContext, fmt.Errorf("Unable to read the pools added online: %w", err))
+		}
+		return args
+	}
+	var expanded []string
+	if err = json.Unmarshal(data, &expanded); err != nil {
+		logger.LogIf(GlobalContext, fmt.Errorf("Unable to read the pools added online: %w", err))
+		return args
+	}
+	if len(expanded) <= len(args) || !stringsPrefixEqual(expanded, args) {
+		// The command line was updated with the added pools or changed.
+		return args
+	}
+	logger.Info("Using the pools added online %s", expanded[len(args):])
+	return expanded
+}
+
+// stringsPrefixEqual returns whether s starts with prefix.
+func stringsPrefixEqual(s, prefix []string) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func saveExpandedPools(args []string) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(poolExpansionPath(), data, 0644)
+}
+
+// poolsEqual returns whether both pools have the same layout and endpoints.
+func poolsEqual(p1, p2 PoolEndpoints) bool {
+	if p1.SetCount != p2.SetCount || p1.DrivesPerSet != p2.DrivesPerSet || len(p1.Endpoints) != len(p2.Endpoints) {
+		return false
+	}
+	for i := range p1.Endpoints {
+		if p1.Endpoints[i].String() != p2.Endpoints[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePoolExpansion returns the endpoints of the pools of args, the
+// current pools followed by one new pool on servers not serving any
+// current drive.
+func validatePoolExpansion(z *erasureServerPools, args []string) (EndpointServerPools, error) {
+	if !globalIsDistErasure {
+		return nil, errPoolExpansionSetup
+	}
+	endpoints, setupType, err := createServerEndpoints(globalMinioAddr, args...)
+	if err != nil {
+		return nil, err
+	}
+	if setupType != DistErasureSetupType {
+		return nil, errPoolExpansionSetup
+	}
+	current := globalEndpoints
+	if len(endpoints) == len(current) && poolsEqual(endpoints[len(endpoints)-1], current[len(current)-1]) {
+		// Added already.
+		return endpoints, nil
+	}
+	if len(endpoints) != len(current)+1 {
+		return nil, fmt.Errorf("Expected the %d current pools and one new pool, found %d pools", len(current), len(endpoints))
+	}
+	hosts := set.NewStringSet()
+	for i, pool := range current {
+		if !poolsEqual(pool, endpoints[i]) {
+			return nil, fmt.Errorf("Pool %d differs from the current pool %d", i+1, i+1)
+		}
+		for _, endpoint := range pool.Endpoints {
+			hosts.Add(endpoint.Host)
+		}
+	}
+
+	pool := endpoints[len(endpoints)-1]
+	for _, endpoint := range pool.Endpoints {
+		if hosts.Contains(endpoint.Host) {
+			return nil, fmt.Errorf("%s is served by a current server, drives of current servers are only added on restart", endpoint)
+		}
+	}
+	if err = storageclass.ValidateParity(z.currentPools()[0].defaultParityCount, pool.DrivesPerSet); err != nil {
+		return nil, fmt.Errorf("The new pool does not support the parity of the current pools: %w", err)
+	}
+	return endpoints, nil
+}
+
+// Phases of a pool expansion, run on all nodes one after the other.
+const (
+	poolExpansionValidate = "validate"
+	poolExpansionPrepare  = "prepare"
+	poolExpansionCommit   = "commit"
+)
+
+// activatePool runs a phase of the expansion to the pools of args on
+// this node: validate checks the new pool, prepare adds it, formatted by
+// the node of the first drive of the first pool, to serve reads and
+// commit lets it take new objects once prepared on all nodes. Running a
+// phase run already does nothing, so failed expansions can be repeated.
+func activatePool(ctx context.Context, objAPI ObjectLayer, args []string, phase string) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return errPoolExpansionSetup
+	}
+
+	poolExpansionMu.Lock()
+	defer poolExpansionMu.Unlock()
+
+	endpoints, err := validatePoolExpansion(z, args)
+	if err != nil {
+		return err
+	}
+	added := len(endpoints) == len(z.currentPools())
+
+	switch phase {
+	case poolExpansionValidate:
+		return nil
+	case poolExpansionPrepare:
+		if added {
+			return nil
+		}
+		return z.addPool(ctx, endpoints, args)
+	case poolExpansionCommit:
+		if !added {
+			return errors.New("The new pool was not prepared on this node")
+		}
+		z.poolsMu.Lock()
+		z.preparedPools = 0
+		z.poolsMu.Unlock()
+		if err = saveExpandedPools(args); err != nil {
+			logger.LogIf(ctx, fmt.Errorf("Unable to save the pools added online, add them to the command line: %w", err))
+		}
+		return nil
+	}
+	return fmt.Errorf("Unknown pool expansion phase %q", phase)
+}
+
+// addPool adds the last pool of endpoints, serving reads but taking no
+// new objects until committed. The pool is formatted and the buckets are
+// created on it by the node of the first drive of the first pool, other
+// nodes wait for its format.
+func (z *erasureServerPools) addPool(ctx context.Context, endpoints EndpointServerPools, args []string) error {
+	pools := z.currentPools()
+	idx := len(pools)
+	ep := endpoints[idx]
+	first := endpoints.FirstLocal()
+
+	storageDisks, format, err := waitForFormatErasure(ctx, first, ep.Endpoints, idx+1,
+		ep.SetCount, ep.DrivesPerSet, globalDeploymentID, pools[0].distributionAlgo)
+	if err != nil {
+		return err
+	}
+	if format.ID != globalDeploymentID {
+		return fmt.Errorf("All serverPools should have same deployment ID expected %s, got %s", globalDeploymentID, format.ID)
+	}
+
+	pool, err := newErasureSets(GlobalContext, ep.Endpoints, storageDisks, format, pools[0].defaultParityCount, idx)
+	if err != nil {
+		return err
+	}
+
+	if first {
+		buckets, err := z.ListBuckets(ctx)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			err = pool.MakeBucketWithLocation(ctx, bucket.Name, BucketOptions{})
+			if _, ok := err.(BucketExists); err != nil && !ok {
+				return err
+			}
+		}
+	}
+
+	// Readers hold on to the previous pools until they are done.
+	serverPools := make([]*erasureSets, 0, idx+1)
+	serverPools = append(serverPools, pools...)
+	peerClients, allPeerClients := newPeerRestClients(endpoints)
+
+	z.poolsMu.Lock()
+	defer z.poolsMu.Unlock()
+	z.serverPools = append(serverPools, pool)
+	z.preparedPools = 1
+	globalEndpoints = endpoints
+	globalServerCmdArgs = args
+	globalRemoteEndpoints = newRemoteEndpoints(endpoints)
+	globalProxyEndpoints = GetProxyEndpoints(endpoints)
+	globalNotificationSys.peerClients, globalNotificationSys.allPeerClients = peerClients, allPeerClients
+	return nil
+}
+
+// expandPools validates args on all nodes, then prepares the new pool
+// one node at a time, first on the node formatting it, and commits it on
+// all nodes once prepared on all of them. Objects are only placed on the
+// new pool once all nodes read from it.
+func expandPools(ctx context.Context, objAPI ObjectLayer, args []string) PoolExpansionInfo {
+	info := PoolExpansionInfo{Args: args}
+
+	peers, local := globalEndpoints.peers()
+	firstHost := globalEndpoints[0].Endpoints[0].Host
+	hosts := []string{firstHost}
+	for _, host := range peers {
+		if host != firstHost {
+			hosts = append(hosts, host)
+		}
+	}
+
+	run := func(host string, phase string) error {
+		if host == local {
+			return activatePool(ctx, objAPI, args, phase)
+		}
+		client := globalNotificationSys.peerClient(host)
+		if client == nil {
+			return fmt.Errorf("%s is not a peer", host)
+		}
+		return client.AddPool(ctx, args, phase)
+	}
+
+	for _, phase := range []string{poolExpansionValidate, poolExpansionPrepare, poolExpansionCommit} {
+		info.Phase = phase
+		info.Results = make([]PoolExpansionResult, 0, len(hosts))
+		var failed bool
+		for _, host := range hosts {
+			result := PoolExpansionResult{Host: host}
+			if failed {
+				result.Error = "skipped"
+			} else if err := run(host, phase); err != nil {
+				result.Error = err.Error()
+				failed = true
+			}
+			info.Results = append(info.Results, result)
+		}
+		if failed {
+			return info
+		}
+		if phase == poolExpansionValidate {
+			info.Validated = true
+		}
+	}
+	info.Committed = true
+	return info
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestWithExpandedPools(t *testing.T) {
+	rootPath, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+	globalConfigDir = &ConfigDir{path: rootPath}
+
+	args := []string{"http://host{1...4}/export{1...4}"}
+	if got := withExpandedPools(args); !reflect.DeepEqual(got, args) {
+		t.Fatalf("expected %v without added pools, got %v", args, got)
+	}
+
+	expanded := append(append([]string{}, args...), "http://host{5...8}/export{1...4}")
+	if err = saveExpandedPools(expanded); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		// The added pools extend the command line.
+		{args, expanded},
+		// The command line was updated with the added pools.
+		{expanded, expanded},
+		// The command line changed.
+		{[]string{"http://host{1...2}/export{1...4}"}, []string{"http://host{1...2}/export{1...4}"}},
+	}
+	for i, testCase := range testCases {
+		if got := withExpandedPools(testCase.args); !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestPreparedPoolsTakeNoObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	z := objLayer.(*erasureServerPools)
+	if avail := z.getServerPoolsAvailableSpace(ctx, "bucket", "object", 1); avail[0].Available == 0 {
+		t.Fatal("expected the pool to take new objects")
+	}
+	z.poolsMu.Lock()
+	z.preparedPools = 1
+	z.poolsMu.Unlock()
+	if avail := z.getServerPoolsAvailableSpace(ctx, "bucket", "object", 1); avail[0].Available != 0 {
+		t.Errorf("expected a prepared pool not to take new objects, got %+v", avail)
+	}
+	if idx := z.getAvailablePoolIdx(ctx, "bucket", "object", 1); idx != -1 {
+		t.Errorf("expected no pool to be chosen, got %d", idx)
+	}
+}
@@ -0,0 +1,591 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/sync/errgroup"
+)
+
+const (
+	poolTagsFile              = "pool-tags.json"
+	bucketPlacementConfigFile = "placement.json"
+
+	maxPlacementTagLen = 64
+)
+
+var (
+	errNoMatchingPools    = errors.New("No server pool has all the placement tags of the bucket")
+	errPlacementMigrating = errors.New("The objects of the bucket are being migrated already")
+)
+
+// PoolTags - the tags of a server pool, such as its drive class or site,
+// the pool being identified by its position in the command line.
+type PoolTags struct {
+	Pool int      `json:"pool"`
+	Tags []string `json:"tags"`
+}
+
+// BucketPlacementConfig - the tags of the pools a bucket is pinned to,
+// new objects of the bucket are only placed on pools having all tags.
+type BucketPlacementConfig struct {
+	Tags []string `json:"tags"`
+}
+
+// validPlacementTag returns whether tag is made of lower case letters,
+// digits, '-', '_' and '.'.
+func validPlacementTag(tag string) bool {
+	if tag == "" || len(tag) > maxPlacementTagLen {
+		return false
+	}
+	for _, c := range tag {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePlacementTags lower cases, validates, sorts and deduplicates tags.
+func normalizePlacementTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !validPlacementTag(tag) {
+			return nil, fmt.Errorf("'%s' is not a valid placement tag", tag)
+		}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	j := 0
+	for i, tag := range normalized {
+		if i == 0 || tag != normalized[j-1] {
+			normalized[j] = tag
+			j++
+		}
+	}
+	return normalized[:j], nil
+}
+
+// parsePlacementTags parses the comma separated tags of a header value.
+func parsePlacementTags(v string) ([]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	return normalizePlacementTags(strings.Split(v, ","))
+}
+
+func parseBucketPlacementConfig(bucket string, data []byte) (*BucketPlacementConfig, error) {
+	placement := &BucketPlacementConfig{}
+	if err := json.Unmarshal(data, placement); err != nil {
+		return placement, err
+	}
+	tags, err := normalizePlacementTags(placement.Tags)
+	if err != nil {
+		return placement, fmt.Errorf("Invalid placement of bucket %s: %w", bucket, err)
+	}
+	if len(tags) == 0 {
+		return placement, fmt.Errorf("Invalid placement of bucket %s: no tags", bucket)
+	}
+	placement.Tags = tags
+	return placement, nil
+}
+
+// parsePoolTags parses the tags of the pools, pools being numbered from
+// zero up to pools.
+func parsePoolTags(data []byte, pools int) ([]PoolTags, error) {
+	var poolTags []PoolTags
+	if err := json.Unmarshal(data, &poolTags); err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(poolTags))
+	for i, p := range poolTags {
+		if p.Pool < 0 || p.Pool >= pools {
+			return nil, fmt.Errorf("Pool %d does not exist, there are %d pools", p.Pool, pools)
+		}
+		if seen[p.Pool] {
+			return nil, fmt.Errorf("Pool %d is tagged twice", p.Pool)
+		}
+		seen[p.Pool] = true
+		tags, err := normalizePlacementTags(p.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tags of pool %d: %w", p.Pool, err)
+		}
+		poolTags[i].Tags = tags
+	}
+	return poolTags, nil
+}
+
+// poolTagsSys holds the tags of the server pools.
+type poolTagsSys struct {
+	mu   sync.RWMutex
+	tags map[int][]string
+}
+
+var globalPoolTags = &poolTagsSys{tags: make(map[int][]string)}
+
+func (sys *poolTagsSys) set(poolTags []PoolTags) {
+	tags := make(map[int][]string, len(poolTags))
+	for _, p := range poolTags {
+		tags[p.Pool] = p.Tags
+	}
+	sys.mu.Lock()
+	sys.tags = tags
+	sys.mu.Unlock()
+}
+
+// get returns the tags of all pools, empty for untagged pools.
+func (sys *poolTagsSys) get(pools int) []PoolTags {
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	poolTags := make([]PoolTags, 0, pools)
+	for i := 0; i < pools; i++ {
+		poolTags = append(poolTags, PoolTags{Pool: i, Tags: append([]string{}, sys.tags[i]...)})
+	}
+	return poolTags
+}
+
+// matches returns whether pool has all tags.
+func (sys *poolTagsSys) matches(pool int, tags []string) bool {
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	poolTags := sys.tags[pool]
+	for _, tag := range tags {
+		if !contains(poolTags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func poolTagsPath() string {
+	return pathJoin(minioConfigPrefix, poolTagsFile)
+}
+
+func loadPoolTags(ctx context.Context, objAPI ObjectLayer, pools int) ([]PoolTags, error) {
+	data, err := readConfig(ctx, objAPI, poolTagsPath())
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parsePoolTags(data, pools)
+}
+
+// reloadPoolTags loads the pool tags set through any node.
+func reloadPoolTags(ctx context.Context, objAPI ObjectLayer) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return nil
+	}
+	poolTags, err := loadPoolTags(ctx, objAPI, len(z.currentPools()))
+	if err != nil {
+		return fmt.Errorf("Unable to load the pool tags: %w", err)
+	}
+	globalPoolTags.set(poolTags)
+	return nil
+}
+
+// placementPools returns which pools new objects of bucket may be placed
+// on, nil if the bucket is not pinned to pools. A bucket whose tags match
+// no pool anymore, after the pools were tagged again, is not pinned.
+// Low latency buckets are pinned to the pools tagged nvme.
+func (z *erasureServerPools) placementPools(bucket string) []bool {
+	tags := bucketPlacementTags(bucket)
+	if len(tags) == 0 {
+		return nil
+	}
+	pools := make([]bool, len(z.currentPools()))
+	var found bool
+	for i := range pools {
+		pools[i] = globalPoolTags.matches(i, tags)
+		found = found || pools[i]
+	}
+	if !found {
+		return nil
+	}
+	return pools
+}
+
+// checkPlacementPools returns errNoMatchingPools unless a pool of objAPI
+// has all tags.
+func checkPlacementPools(objAPI ObjectLayer, tags []string) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return NotImplemented{}
+	}
+	for i := range z.currentPools() {
+		if globalPoolTags.matches(i, tags) {
+			return nil
+		}
+	}
+	return errNoMatchingPools
+}
+
+// setBucketPlacement pins bucket to the pools having all tags.
+func setBucketPlacement(bucket string, tags []string) error {
+	data, err := json.Marshal(BucketPlacementConfig{Tags: tags})
+	if err != nil {
+		return err
+	}
+	return globalBucketMetadataSys.Update(bucket, bucketPlacementConfigFile, data)
+}
+
+// restoreBucketPlacement sets the placement of bucket back to prev, nil
+// if the bucket was not pinned to pools.
+func restoreBucketPlacement(bucket string, prev *BucketPlacementConfig) error {
+	if prev == nil {
+		return globalBucketMetadataSys.Update(bucket, bucketPlacementConfigFile, nil)
+	}
+	return setBucketPlacement(bucket, prev.Tags)
+}
+
+// PlacementMigrationStatus - the progress of moving the objects of a
+// bucket off the pools it is not pinned to. Objects having encrypted,
+// compressed or transitioned versions are skipped.
+type PlacementMigrationStatus struct {
+	Bucket   string    `json:"bucket"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+	Objects  uint64    `json:"objects"`
+	Bytes    uint64    `json:"bytes"`
+	Skipped  uint64    `json:"skipped"`
+	Failed   uint64    `json:"failed"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// placementMigrations holds the migrations started through this node.
+type placementMigrations struct {
+	sync.Mutex
+	status map[string]*PlacementMigrationStatus
+}
+
+var globalPlacementMigrations = &placementMigrations{status: make(map[string]*PlacementMigrationStatus)}
+
+func (m *placementMigrations) start(bucket string) (*PlacementMigrationStatus, error) {
+	m.Lock()
+	defer m.Unlock()
+	if st, ok := m.status[bucket]; ok && st.Finished.IsZero() {
+		return nil, errPlacementMigrating
+	}
+	st := &PlacementMigrationStatus{Bucket: bucket, Started: UTCNow()}
+	m.status[bucket] = st
+	return st, nil
+}
+
+// get returns a copy of the latest migration status of bucket.
+func (m *placementMigrations) get(bucket string) *PlacementMigrationStatus {
+	m.Lock()
+	defer m.Unlock()
+	st, ok := m.status[bucket]
+	if !ok {
+		return nil
+	}
+	c := *st
+	return &c
+}
+
+func (m *placementMigrations) update(fn func()) {
+	m.Lock()
+	fn()
+	m.Unlock()
+}
+
+// migrateBucketPlacement moves the objects of bucket off the pools it
+// is not pinned to, walking the sets of those pools.
+func (z *erasureServerPools) migrateBucketPlacement(ctx context.Context, bucket string, st *PlacementMigrationStatus) {
+	m := globalPlacementMigrations
+	var err error
+	defer func() {
+		m.update(func() {
+			st.Finished = UTCNow()
+			if err != nil {
+				st.Error = err.Error()
+			}
+		})
+	}()
+
+	pools := z.placementPools(bucket)
+	if pools == nil {
+		err = errNoMatchingPools
+		return
+	}
+	for idx, matches := range pools {
+		if matches {
+			continue
+		}
+		for _, set := range z.currentPools()[idx].sets {
+			if err = z.migrateSetPlacement(ctx, bucket, set, st); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// migrateSetPlacement moves the objects of bucket stored on set.
+func (z *erasureServerPools) migrateSetPlacement(ctx context.Context, bucket string, set *erasureObjects, st *PlacementMigrationStatus) error {
+	disks, _ := set.getOnlineDisksWithHealing()
+	if len(disks) == 0 {
+		return errErasureReadQuorum
+	}
+
+	m := globalPlacementMigrations
+	migrate := func(entry metaCacheEntry) {
+		if entry.isDir() {
+			return
+		}
+		skipped, size, err := z.migrateObjectPlacement(ctx, bucket, entry.name, set)
+		m.update(func() {
+			switch {
+			case err != nil:
+				st.Failed++
+			case skipped:
+				st.Skipped++
+			default:
+				st.Objects++
+				st.Bytes += uint64(size)
+			}
+		})
+		if err != nil {
+			logger.LogIf(ctx, fmt.Errorf("Unable to migrate %s/%s to the pools of its placement: %w", bucket, entry.name, err))
+		}
+	}
+
+	resolver := metadataResolutionParams{
+		dirQuorum: 1,
+		objQuorum: 1,
+		bucket:    bucket,
+	}
+	return listPathRaw(ctx, listPathRawOptions{
+		disks:     disks,
+		bucket:    bucket,
+		recursive: true,
+		minDisks:  1,
+		agreed:    migrate,
+		partial: func(entries metaCacheEntries, nAgreed int, errs []error) {
+			entry, ok := entries.resolve(&resolver)
+			if !ok {
+				entry, _ = entries.firstFound()
+			}
+			if entry != nil {
+				migrate(*entry)
+			}
+		},
+	})
+}
+
+// migrateObjectPlacement copies all versions of object from set to a
+// pool of the placement of bucket, oldest first so the latest version
+// remains the latest, then deletes the object from set. The object is
+// locked meanwhile.
+func (z *erasureServerPools) migrateObjectPlacement(ctx context.Context, bucket, object string, set *erasureObjects) (skipped bool, size int64, err error) {
+	lk := z.NewNSLock(bucket, object)
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return false, 0, err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx.Cancel)
+
+	fivs, err := set.readObjectVersions(ctx, bucket, object)
+	if err != nil {
+		if isErrObjectNotFound(err) {
+			// Deleted meanwhile.
+			return true, 0, nil
+		}
+		return false, 0, err
+	}
+	for _, fi := range fivs.Versions {
+		if fi.Deleted {
+			continue
+		}
+		if _, encrypted := crypto.IsEncrypted(fi.Metadata); encrypted || fi.IsRemote() ||
+			fi.ToObjectInfo(bucket, object).IsCompressed() {
+			return true, 0, nil
+		}
+		size += fi.Size
+	}
+
+	poolIdx := z.getAvailablePoolIdx(ctx, bucket, object, size)
+	if poolIdx < 0 {
+		return false, 0, toObjectErr(errDiskFull)
+	}
+	dst := z.currentPools()[poolIdx].getHashedSet(object)
+	if dst == set {
+		return true, 0, nil
+	}
+	// The object must not have versions on dst already, which are
+	// removed again if copying the versions fails.
+	if _, err = dst.readObjectVersions(ctx, bucket, object); err == nil {
+		return true, 0, nil
+	} else if !isErrObjectNotFound(err) {
+		return false, 0, err
+	}
+
+	for i := len(fivs.Versions) - 1; i >= 0; i-- {
+		if err = copyObjectVersion(ctx, bucket, object, fivs.Versions[i], set, dst); err != nil {
+			logger.LogIf(ctx, dst.deleteObject(ctx, bucket, object, dst.defaultWQuorum()))
+			return false, 0, err
+		}
+	}
+	if err = set.deleteObject(ctx, bucket, object, set.defaultWQuorum()); err != nil {
+		return false, 0, toObjectErr(err, bucket, object)
+	}
+	return false, size, nil
+}
+
+// copyObjectVersion copies the version fi of object from src to dst
+// keeping its version id, modification time, metadata and parts, the
+// caller must hold the object lock.
+func copyObjectVersion(ctx context.Context, bucket, object string, fi FileInfo, src, dst *erasureObjects) error {
+	if fi.Deleted {
+		dm := FileInfo{
+			Name:      object,
+			VersionID: fi.VersionID,
+			Deleted:   true,
+			ModTime:   fi.ModTime,
+		}
+		return toObjectErr(dst.deleteObjectVersion(ctx, bucket, object, dst.defaultWQuorum(), dm, true), bucket, object)
+	}
+
+	versionID := fi.VersionID
+	if versionID == "" {
+		versionID = nullVersionID
+	}
+	gr, err := src.GetObjectNInfo(ctx, bucket, object, nil, nil, noLock, ObjectOptions{VersionID: versionID, NoLock: true})
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	opts := ObjectOptions{
+		VersionID:   fi.VersionID,
+		Versioned:   fi.VersionID != "",
+		MTime:       fi.ModTime,
+		UserDefined: cloneMSS(fi.Metadata),
+		NoLock:      true,
+	}
+	if len(fi.Parts) > 1 {
+		return copyObjectParts(ctx, bucket, object, fi, gr, dst, opts)
+	}
+
+	hr, err := hash.NewReader(gr, fi.Size, "", "", fi.Size)
+	if err != nil {
+		return err
+	}
+	_, err = dst.PutObject(ctx, bucket, object, NewPutObjReader(hr), opts)
+	return err
+}
+
+// copyObjectParts writes the version fi of a multipart object read from
+// r to dst part by part, so that its parts and their ETags are the same
+// as on the source.
+func copyObjectParts(ctx context.Context, bucket, object string, fi FileInfo, r io.Reader, dst *erasureObjects, opts ObjectOptions) (err error) {
+	uploadID, err := dst.NewMultipartUpload(ctx, bucket, object, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.AbortMultipartUpload(ctx, bucket, object, uploadID, ObjectOptions{})
+		}
+	}()
+
+	parts := make([]CompletePart, 0, len(fi.Parts))
+	for _, part := range fi.Parts {
+		var hr *hash.Reader
+		hr, err = hash.NewReader(io.LimitReader(r, part.Size), part.Size, "", "", part.Size)
+		if err != nil {
+			return err
+		}
+		var pi PartInfo
+		pi, err = dst.PutObjectPart(ctx, bucket, object, uploadID, part.Number, NewPutObjReader(hr), ObjectOptions{})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, CompletePart{PartNumber: pi.PartNumber, ETag: pi.ETag})
+	}
+	_, err = dst.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+	return err
+}
+
+// readObjectVersions returns the versions of object agreed by a read
+// quorum of the disks of er.
+func (er erasureObjects) readObjectVersions(ctx context.Context, bucket, object string) (FileInfoVersions, error) {
+	disks := er.getDisks()
+	metas := make([]FileInfoVersions, len(disks))
+	g := errgroup.WithNErrs(len(disks))
+	for index := range disks {
+		index := index
+		g.Go(func() error {
+			if disks[index] == nil {
+				return errDiskNotFound
+			}
+			buf, err := disks[index].ReadAll(ctx, bucket, pathJoin(object, xlStorageFormatFile))
+			if err != nil {
+				return err
+			}
+			metas[index], err = getFileInfoVersions(buf, bucket, object)
+			return err
+		}, index)
+	}
+	errs := g.Wait()
+
+	readQuorum := er.setDriveCount - er.defaultParityCount
+	if err := reduceReadQuorumErrs(ctx, errs, objectOpIgnoredErrs, readQuorum); err != nil {
+		return FileInfoVersions{}, toObjectErr(err, bucket, object)
+	}
+
+	// The versions of the disks agreeing on the same version ids and
+	// modification times.
+	counts := make(map[string]int)
+	var latest FileInfoVersions
+	var latestCount int
+	for index, fivs := range metas {
+		if errs[index] != nil {
+			continue
+		}
+		var key strings.Builder
+		for _, fi := range fivs.Versions {
+			fmt.Fprintf(&key, "%s:%d,", fi.VersionID, fi.ModTime.UnixNano())
+		}
+		counts[key.String()]++
+		if n := counts[key.String()]; n > latestCount {
+			latest, latestCount = fivs, n
+		}
+	}
+	if latestCount < readQuorum {
+		return FileInfoVersions{}, toObjectErr(errErasureReadQuorum, bucket, object)
+	}
+	return latest, nil
+}
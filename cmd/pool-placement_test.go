@@ -0,0 +1,204 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/dustin/go-humanize"
+)
+
+func TestParsePlacementTags(t *testing.T) {
+	testCases := []struct {
+		v    string
+		tags []string
+		ok   bool
+	}{
+		{"", nil, true},
+		{"nvme", []string{"nvme"}, true},
+		{" site-a, NVMe ,nvme", []string{"nvme", "site-a"}, true},
+		{"nvme,", nil, false},
+		{"hdd,site a", nil, false},
+	}
+	for i, testCase := range testCases {
+		tags, err := parsePlacementTags(testCase.v)
+		if (err == nil) != testCase.ok {
+			t.Fatalf("Test %d: expected ok %v, got %v", i+1, testCase.ok, err)
+		}
+		if err == nil && !reflect.DeepEqual(tags, testCase.tags) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.tags, tags)
+		}
+	}
+
+	for _, data := range []string{`{}`, `{"tags":[]}`, `{"tags":["Ümlaut"]}`, `{"tags":`} {
+		if _, err := parseBucketPlacementConfig("bucket", []byte(data)); err == nil {
+			t.Errorf("expected %s to be invalid", data)
+		}
+	}
+}
+
+func TestPoolTagsMatches(t *testing.T) {
+	poolTags, err := parsePoolTags([]byte(`[{"pool":0,"tags":["hdd","site-a"]},{"pool":1,"tags":["NVMe","site-a"]}]`), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys := &poolTagsSys{}
+	sys.set(poolTags)
+
+	testCases := []struct {
+		pool    int
+		tags    []string
+		matches bool
+	}{
+		{0, []string{"hdd"}, true},
+		{0, []string{"hdd", "site-a"}, true},
+		{0, []string{"nvme"}, false},
+		{1, []string{"nvme", "site-a"}, true},
+		{1, []string{"nvme", "site-b"}, false},
+		{2, []string{"hdd"}, false},
+		{2, nil, true},
+	}
+	for i, testCase := range testCases {
+		if matches := sys.matches(testCase.pool, testCase.tags); matches != testCase.matches {
+			t.Errorf("Test %d: expected pool %d matching %v to be %v", i+1, testCase.pool, testCase.tags, testCase.matches)
+		}
+	}
+	if got := sys.get(3); len(got) != 3 || len(got[2].Tags) != 0 || !reflect.DeepEqual(got[1].Tags, []string{"nvme", "site-a"}) {
+		t.Errorf("unexpected pool tags %v", got)
+	}
+
+	for _, data := range []string{`[{"pool":3,"tags":["hdd"]}]`, `[{"pool":0},{"pool":0}]`, `[{"pool":0,"tags":["a,b"]}]`} {
+		if _, err := parsePoolTags([]byte(data), 3); err == nil {
+			t.Errorf("expected %s to be invalid", data)
+		}
+	}
+}
+
+// preparePlacementPools returns an erasure object layer of two pools of
+// four drives each, tagged hdd and nvme.
+func preparePlacementPools(ctx context.Context) (*erasureServerPools, []string, error) {
+	fsDirs, err := getRandomDisks(8)
+	if err != nil {
+		return nil, nil, err
+	}
+	endpoints := append(mustGetPoolEndpoints(fsDirs[:4]...), mustGetPoolEndpoints(fsDirs[4:]...)...)
+	obj, _, err := initObjectLayer(ctx, endpoints)
+	if err != nil {
+		removeRoots(fsDirs)
+		return nil, nil, err
+	}
+	globalPoolTags.set([]PoolTags{{Pool: 0, Tags: []string{"hdd"}}, {Pool: 1, Tags: []string{"nvme"}}})
+	return obj.(*erasureServerPools), fsDirs, nil
+}
+
+func TestMigrateBucketPlacement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	z, fsDirs, err := preparePlacementPools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	defer globalPoolTags.set(nil)
+
+	oldMetadataSys := globalBucketMetadataSys
+	defer func() { globalBucketMetadataSys = oldMetadataSys }()
+	globalBucketMetadataSys = NewBucketMetadataSys()
+
+	const bucket = "bucket"
+	if err = z.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pin := func(tags ...string) {
+		meta := newBucketMetadata(bucket)
+		meta.placementConfig = &BucketPlacementConfig{Tags: tags}
+		globalBucketMetadataSys.Set(bucket, meta)
+	}
+	pin("hdd")
+
+	small := bytes.Repeat([]byte("a"), 1024)
+	if _, err = z.PutObject(ctx, bucket, "small", mustGetPutObjReader(t, bytes.NewReader(small), int64(len(small)), "", ""), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	uploadID, err := z.NewMultipartUpload(ctx, bucket, "multipart", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parts []CompletePart
+	var multipart []byte
+	for i, size := range []int{5 * humanize.MiByte, 1024} {
+		data := bytes.Repeat([]byte{byte('b' + i)}, size)
+		pi, err := z.PutObjectPart(ctx, bucket, "multipart", uploadID, i+1, mustGetPutObjReader(t, bytes.NewReader(data), int64(size), "", ""), ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, CompletePart{PartNumber: pi.PartNumber, ETag: pi.ETag})
+		multipart = append(multipart, data...)
+	}
+	before, err := z.CompleteMultipartUpload(ctx, bucket, "multipart", uploadID, parts, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pin("nvme")
+	st, err := globalPlacementMigrations.start(bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z.migrateBucketPlacement(ctx, bucket, st)
+	if st := globalPlacementMigrations.get(bucket); st.Objects != 2 || st.Failed != 0 || st.Skipped != 0 || st.Error != "" {
+		t.Fatalf("unexpected migration %+v", st)
+	}
+
+	for object, data := range map[string][]byte{"small": small, "multipart": multipart} {
+		if _, err = z.serverPools[0].getHashedSet(object).GetObjectInfo(ctx, bucket, object, ObjectOptions{}); !isErrObjectNotFound(err) {
+			t.Errorf("%s: expected the object to be removed from pool 0, got %v", object, err)
+		}
+		if _, err = z.serverPools[1].getHashedSet(object).GetObjectInfo(ctx, bucket, object, ObjectOptions{}); err != nil {
+			t.Errorf("%s: expected the object on pool 1, got %v", object, err)
+		}
+		gr, err := z.GetObjectNInfo(ctx, bucket, object, nil, nil, readLock, ObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: the data differs after the migration", object)
+		}
+	}
+
+	// The multipart object keeps its parts and its ETag.
+	after, err := z.GetObjectInfo(ctx, bucket, "multipart", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.ETag != before.ETag || len(after.Parts) != 2 || after.Parts[0].Size != 5*humanize.MiByte ||
+		!after.ModTime.Equal(before.ModTime) {
+		t.Errorf("expected %s with 2 parts modified at %s, got %s with %d parts modified at %s",
+			before.ETag, before.ModTime, after.ETag, len(after.Parts), after.ModTime)
+	}
+}
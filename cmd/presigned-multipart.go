@@ -0,0 +1,155 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/handlers"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+const (
+	defaultPresignedUploadExpiry = time.Hour
+	maxPresignedUploadExpiry     = 7 * 24 * time.Hour
+)
+
+// PresignedPart - pre-signed UploadPart URL of a part.
+type PresignedPart struct {
+	PartNumber int
+	Size       int64
+	URL        string
+}
+
+// PresignedMultipartUploadResponse - response of a pre-signed multipart
+// upload, the URLs to upload all parts and to complete or abort the
+// upload, all expiring at Expires.
+type PresignedMultipartUploadResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ PresignedMultipartUploadResult" json:"-"`
+
+	Bucket      string
+	Key         string
+	UploadID    string `xml:"UploadId"`
+	PartSize    int64
+	Expires     time.Time
+	Parts       []PresignedPart `xml:"Part"`
+	CompleteURL string
+	AbortURL    string
+}
+
+// presignedPartSize returns the part size and number of parts to upload
+// an object of size, the smallest part size keeping the number of parts
+// in the limit unless partSize is requested.
+func presignedPartSize(size, partSize int64) (int64, int, APIErrorCode) {
+	if size < 0 {
+		return 0, 0, ErrInvalidRequest
+	}
+	if isMaxObjectSize(size) {
+		return 0, 0, ErrEntityTooLarge
+	}
+	if partSize == 0 {
+		partSize = (size + globalMaxPartID - 1) / globalMaxPartID
+		// Round up to a multiple of the minimum part size.
+		partSize = ((partSize + globalMinPartSize - 1) / globalMinPartSize) * globalMinPartSize
+		if partSize == 0 {
+			partSize = globalMinPartSize
+		}
+	}
+	if partSize < globalMinPartSize {
+		return 0, 0, ErrEntityTooSmall
+	}
+	if isMaxAllowedPartSize(partSize) {
+		return 0, 0, ErrEntityTooLarge
+	}
+	parts := int((size + partSize - 1) / partSize)
+	if parts == 0 {
+		parts = 1
+	}
+	if isMaxPartID(parts) {
+		return 0, 0, ErrInvalidMaxParts
+	}
+	return partSize, parts, ErrNone
+}
+
+// presignedUploadURLs generates the pre-signed URLs of a multipart
+// upload, signed by cred for the host and path of r.
+func presignedUploadURLs(r *http.Request, cred auth.Credentials, uploadID string, size, partSize int64, parts int, expires time.Duration) (presigned []PresignedPart, complete, abort string) {
+	date := UTCNow()
+	presigned = make([]PresignedPart, parts)
+	for i := range presigned {
+		partNumber := i + 1
+		partLen := partSize
+		if last := size - int64(i)*partSize; last < partSize {
+			partLen = last
+		}
+		query := url.Values{}
+		query.Set("partNumber", strconv.Itoa(partNumber))
+		query.Set("uploadId", uploadID)
+		presigned[i] = PresignedPart{
+			PartNumber: partNumber,
+			Size:       partLen,
+			URL:        presignV4(r, cred, http.MethodPut, query, date, expires),
+		}
+	}
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	complete = presignV4(r, cred, http.MethodPost, query, date, expires)
+	abort = presignV4(r, cred, http.MethodDelete, query, date, expires)
+	return presigned, complete, abort
+}
+
+// presignV4 returns the URL of a request to the host and path of r,
+// pre-signed by cred with signature V4.
+func presignV4(r *http.Request, cred auth.Credentials, method string, query url.Values, date time.Time, expires time.Duration) string {
+	scope := getScope(date, globalServerRegion)
+
+	query.Set(xhttp.AmzAlgorithm, signV4Algorithm)
+	query.Set(xhttp.AmzDate, date.Format(iso8601Format))
+	query.Set(xhttp.AmzExpires, strconv.Itoa(int(expires/time.Second)))
+	query.Set(xhttp.AmzSignedHeaders, "host")
+	query.Set(xhttp.AmzCredential, cred.AccessKey+SlashSeparator+scope)
+	if cred.SessionToken != "" {
+		query.Set(xhttp.AmzSecurityToken, cred.SessionToken)
+	}
+
+	// "host" is the only header signed for pre-signed URLs.
+	signedHeaders := make(http.Header)
+	signedHeaders.Set("host", r.Host)
+
+	canonicalRequest := getCanonicalRequest(signedHeaders, unsignedPayload, query.Encode(), r.URL.Path, method)
+	stringToSign := getStringToSign(canonicalRequest, date, scope)
+	signingKey := getSigningKey(cred.SecretKey, date, globalServerRegion, serviceS3)
+	query.Set(xhttp.AmzSignature, getSignature(signingKey, stringToSign))
+
+	proto := handlers.GetSourceScheme(r)
+	if proto == "" {
+		proto = getURLScheme(globalIsTLS)
+	}
+	u := &url.URL{
+		Scheme:   proto,
+		Host:     r.Host,
+		Path:     r.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+func TestPresignedPartSize(t *testing.T) {
+	testCases := []struct {
+		size, partSize int64
+		wantPartSize   int64
+		wantParts      int
+		wantErr        APIErrorCode
+	}{
+		{size: 0, wantPartSize: globalMinPartSize, wantParts: 1},
+		{size: 12 * humanize.MiByte, wantPartSize: globalMinPartSize, wantParts: 3},
+		{size: 100 * humanize.GiByte, wantPartSize: 15 * humanize.MiByte, wantParts: 6827},
+		{size: 100 * humanize.MiByte, partSize: 64 * humanize.MiByte, wantPartSize: 64 * humanize.MiByte, wantParts: 2},
+		{size: 100 * humanize.MiByte, partSize: humanize.MiByte, wantErr: ErrEntityTooSmall},
+		{size: 100 * humanize.GiByte, partSize: globalMinPartSize, wantErr: ErrInvalidMaxParts},
+		{size: -1, wantErr: ErrInvalidRequest},
+	}
+	for i, tc := range testCases {
+		partSize, parts, err := presignedPartSize(tc.size, tc.partSize)
+		if err != tc.wantErr {
+			t.Fatalf("case %d: expected error %v, got %v", i, tc.wantErr, err)
+		}
+		if err == ErrNone && (partSize != tc.wantPartSize || parts != tc.wantParts) {
+			t.Errorf("case %d: expected %d parts of %d, got %d parts of %d", i, tc.wantParts, tc.wantPartSize, parts, partSize)
+		}
+	}
+}
+
+func TestPresignedUploadURLs(t *testing.T) {
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	if err = newTestConfig(globalMinioDefaultRegion, obj); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := http.NewRequest(http.MethodPost, "http://localhost:9000/bucket/dir/object?presigned-uploads&size=12", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, complete, abort := presignedUploadURLs(r, globalActiveCred, "upload-id", 12*humanize.MiByte, globalMinPartSize, 3, time.Hour)
+	if len(parts) != 3 || parts[2].PartNumber != 3 || parts[2].Size != 2*humanize.MiByte {
+		t.Fatalf("unexpected parts %v", parts)
+	}
+
+	for method, u := range map[string]string{
+		http.MethodPut:    parts[0].URL,
+		http.MethodPost:   complete,
+		http.MethodDelete: abort,
+	} {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = req.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if req.Form.Get("uploadId") != "upload-id" {
+			t.Errorf("%s: expected the upload id in %s", method, u)
+		}
+		if s3Err := doesPresignedSignatureMatch(unsignedPayload, req, globalServerRegion, serviceS3); s3Err != ErrNone {
+			t.Errorf("%s: expected a valid signature, got %v", method, s3Err)
+		}
+	}
+}
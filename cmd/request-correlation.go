@@ -0,0 +1,105 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/minio/minio/internal/handlers"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+// maxCorrelationIDLen is the longest correlation ID accepted.
+const maxCorrelationIDLen = 128
+
+type correlationIDKey struct{}
+
+// withCorrelationID returns ctx carrying the correlation ID id, sent
+// along with the internode requests made with it.
+func withCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// correlationIDFromContext returns the correlation ID of ctx, empty
+// if the request of ctx had none.
+func correlationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// isValidCorrelationID returns whether id is short and only made of
+// visible ASCII characters, to be logged and echoed as is.
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestCorrelationID returns the correlation ID of r, sent by peers
+// in x-minio-correlation-id and by clients in the configured header.
+func requestCorrelationID(r *http.Request) string {
+	var id string
+	if guessIsRPCReq(r) && !isAdminReq(r) {
+		id = r.Header.Get(xhttp.MinIOCorrelationID)
+	} else if name := globalAPIConfig.getCorrelationHeader(); name != "" {
+		id = r.Header.Get(name)
+	}
+	if !isValidCorrelationID(id) {
+		return ""
+	}
+	return id
+}
+
+// setCorrelationIDHandler picks up the correlation ID of a request,
+// echoes it in the response and keeps it in the request context for
+// the logs, audit entries, error responses and internode calls of the
+// request. Internode handlers log with the request context as is, it
+// gets the request info right away.
+func setCorrelationIDHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestCorrelationID(r)
+		if id == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if name := globalAPIConfig.getCorrelationHeader(); name != "" {
+			w.Header().Set(name, id)
+		}
+		ctx := withCorrelationID(r.Context(), id)
+		if guessIsRPCReq(r) && !isAdminReq(r) {
+			ctx = logger.SetReqInfo(ctx, &logger.ReqInfo{
+				DeploymentID:  globalDeploymentID,
+				RequestID:     w.Header().Get(xhttp.AmzRequestID),
+				CorrelationID: id,
+				RemoteHost:    handlers.GetSourceIP(r),
+				Host:          getHostName(r),
+				UserAgent:     r.UserAgent(),
+				API:           r.URL.Path,
+			})
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
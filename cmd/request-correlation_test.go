@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+func TestIsValidCorrelationID(t *testing.T) {
+	testCases := []struct {
+		id    string
+		valid bool
+	}{
+		{"", false},
+		{"app-42", true},
+		{"3f2c9a1e-7b1d-4c7e-9f5a-2d1e0b8c6a4f", true},
+		{"has space", false},
+		{"line\nbreak", false},
+		{"caf\xc3\xa9", false},
+		{strings.Repeat("a", maxCorrelationIDLen), true},
+		{strings.Repeat("a", maxCorrelationIDLen+1), false},
+	}
+	for i, testCase := range testCases {
+		if valid := isValidCorrelationID(testCase.id); valid != testCase.valid {
+			t.Errorf("Test %d: expected %v for %q, got %v", i+1, testCase.valid, testCase.id, valid)
+		}
+	}
+}
+
+func TestSetCorrelationIDHandler(t *testing.T) {
+	globalAPIConfig.mu.Lock()
+	prev := globalAPIConfig.correlationHeader
+	globalAPIConfig.correlationHeader = "X-Correlation-Id"
+	globalAPIConfig.mu.Unlock()
+	defer func() {
+		globalAPIConfig.mu.Lock()
+		globalAPIConfig.correlationHeader = prev
+		globalAPIConfig.mu.Unlock()
+	}()
+
+	var gotID, gotReqInfoID string
+	h := setCorrelationIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = correlationIDFromContext(r.Context())
+		gotReqInfoID = logger.GetReqInfo(r.Context()).CorrelationID
+	}))
+
+	// S3 request, the ID is echoed and kept for newContext.
+	r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+	r.Header.Set("X-Correlation-Id", "app-42")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if gotID != "app-42" {
+		t.Fatalf("expected correlation ID app-42, got %q", gotID)
+	}
+	if echoed := w.Header().Get("X-Correlation-Id"); echoed != "app-42" {
+		t.Fatalf("expected the correlation ID to be echoed, got %q", echoed)
+	}
+
+	// Internode request, the ID is set in the request info right away.
+	r = httptest.NewRequest(http.MethodPost, minioReservedBucketPath+"/storage/v42/readall", nil)
+	r.Header.Set(xhttp.MinIOCorrelationID, "app-42")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if gotID != "app-42" || gotReqInfoID != "app-42" {
+		t.Fatalf("expected internode correlation ID app-42, got %q and %q", gotID, gotReqInfoID)
+	}
+
+	// Invalid IDs are dropped.
+	r = httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+	r.Header.Set("X-Correlation-Id", "has space")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if gotID != "" || w.Header().Get("X-Correlation-Id") != "" {
+		t.Fatalf("expected an invalid correlation ID to be dropped, got %q", gotID)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/config/tracing/otlp"
+	"github.com/minio/minio/internal/handlers"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/tracing"
+)
+
+// updateRequestTracing installs a new span exporter as per cfg,
+// disabling tracing altogether when cfg is not enabled.
+func updateRequestTracing(cfg otlp.Config) {
+	if !cfg.Enabled {
+		tracing.SetExporter(nil, 0)
+		return
+	}
+	exporter := tracing.NewExporter(tracing.ExporterArgs{
+		Endpoint:        cfg.Endpoint.String(),
+		ServiceName:     cfg.ServiceName,
+		ServiceInstance: globalLocalNodeName,
+		AuthToken:       cfg.AuthToken,
+		Transport:       NewGatewayHTTPTransport(),
+		LogOnce: func(ctx context.Context, err error, id interface{}) {
+			logger.LogOnceIf(ctx, err, id)
+		},
+	})
+	tracing.SetExporter(exporter, cfg.SampleRatio)
+}
+
+// setRequestTracingHandler picks up the W3C trace context sent by
+// clients and peers, internode calls get a server span right away
+// while S3 spans are started per API in collectAPIStats.
+func setRequestTracingHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(tracing.Extract(r.Context(), r.Header))
+		if !guessIsRPCReq(r) || isAdminReq(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, span := tracing.Start(r.Context(), "internode"+r.URL.Path, tracing.KindServer)
+		defer span.End()
+		if id := requestCorrelationID(r); id != "" {
+			span.SetAttr("minio.correlation_id", id)
+		}
+
+		rw := logger.NewResponseWriter(w)
+		h.ServeHTTP(rw, r.WithContext(ctx))
+		span.SetAttr("http.status_code", rw.StatusCode)
+	})
+}
+
+// startS3Span starts the server span of an S3 API call.
+func startS3Span(r *http.Request, api string) (*http.Request, *tracing.Span) {
+	ctx, span := tracing.Start(r.Context(), "s3."+api, tracing.KindServer)
+	if span == nil {
+		return r.WithContext(ctx), nil
+	}
+	vars := mux.Vars(r)
+	span.SetAttr("http.method", r.Method)
+	span.SetAttr("net.peer.ip", handlers.GetSourceIP(r))
+	if id := correlationIDFromContext(r.Context()); id != "" {
+		span.SetAttr("minio.correlation_id", id)
+	}
+	if bucket := vars["bucket"]; bucket != "" {
+		span.SetAttr("s3.bucket", bucket)
+	}
+	if object, err := unescapePath(vars["object"]); err == nil && object != "" {
+		span.SetAttr("s3.object", object)
+	}
+	return r.WithContext(ctx), span
+}
+
+// endS3Span records the outcome of an S3 API call and ends its span.
+func endS3Span(span *tracing.Span, rw *logger.ResponseWriter) {
+	if span == nil {
+		return
+	}
+	span.SetAttr("http.status_code", rw.StatusCode)
+	span.SetAttr("http.response_content_length", rw.Size())
+	if rw.StatusCode >= http.StatusInternalServerError {
+		span.SetError(errors.New(http.StatusText(rw.StatusCode)))
+	}
+	span.End()
+}
@@ -0,0 +1,472 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+// Rolling update states.
+const (
+	RollingUpdateRunning   = "running"
+	RollingUpdateCompleted = "completed"
+	RollingUpdateFailed    = "failed"
+	RollingUpdateAborted   = "aborted"
+)
+
+// Rolling update states of a node.
+const (
+	updateNodePending    = "pending"
+	updateNodeDraining   = "draining"
+	updateNodeRestarting = "restarting"
+	updateNodeUpdated    = "updated"
+	updateNodeFailed     = "failed"
+	updateNodeRolledBack = "rolled-back"
+)
+
+const (
+	// Time given to the requests in flight on a node before it is restarted.
+	rollingUpdateDrainTimeout = time.Minute
+
+	// Time given to a restarted node to come back, and
+	// to the cluster to be healthy again after a batch.
+	rollingUpdateNodeTimeout = 5 * time.Minute
+
+	rollingUpdatePollInterval = 2 * time.Second
+)
+
+var errRollingUpdateRunning = errors.New("a rolling update is already running")
+
+// RollingUpdateNode is the update progress of a node.
+type RollingUpdateNode struct {
+	Node  string `json:"node"`
+	Batch int    `json:"batch"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+
+	// Set once the binary of the node was replaced.
+	replaced bool
+}
+
+// RollingUpdateStatus is the progress of a rolling update.
+type RollingUpdateStatus struct {
+	State          string              `json:"state"`
+	CurrentVersion string              `json:"currentVersion"`
+	UpdatedVersion string              `json:"updatedVersion"`
+	Started        time.Time           `json:"started"`
+	Finished       time.Time           `json:"finished,omitempty"`
+	Batch          int                 `json:"batch"`
+	Batches        int                 `json:"batches"`
+	Nodes          []RollingUpdateNode `json:"nodes"`
+	Error          string              `json:"error,omitempty"`
+}
+
+// rollingUpdate restarts the nodes on a new binary one batch at a time,
+// the node coordinating the update is restarted last.
+type rollingUpdate struct {
+	mu     sync.Mutex
+	status *RollingUpdateStatus
+	cancel context.CancelFunc
+}
+
+var globalRollingUpdate = &rollingUpdate{}
+
+// planUpdateBatches groups the nodes into batches which can be restarted
+// together without losing write quorum in any erasure set, margins holds
+// the number of drives each set of each pool can lose. The local node is
+// always in the last batch, alone.
+func planUpdateBatches(pools EndpointServerPools, margins [][]int, local string) ([][]string, error) {
+	type setID struct{ pool, set int }
+	drives := make(map[string]map[setID]int)
+	for poolIdx, pool := range pools {
+		for i, endpoint := range pool.Endpoints {
+			node := endpoint.Host
+			if drives[node] == nil {
+				drives[node] = make(map[setID]int)
+			}
+			drives[node][setID{poolIdx, i / pool.DrivesPerSet}]++
+		}
+	}
+
+	fits := func(load map[setID]int, node string) bool {
+		for s, n := range drives[node] {
+			if load[s]+n > margins[s.pool][s.set] {
+				return false
+			}
+		}
+		return true
+	}
+	checkAlone := func(node string) error {
+		if !fits(nil, node) {
+			return fmt.Errorf("restarting node %s would lose write quorum", node)
+		}
+		return nil
+	}
+
+	nodes := make([]string, 0, len(drives))
+	for node := range drives {
+		if node != local {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+
+	var batches [][]string
+	var loads []map[setID]int
+	for _, node := range nodes {
+		if err := checkAlone(node); err != nil {
+			return nil, err
+		}
+		idx := -1
+		for i, load := range loads {
+			if fits(load, node) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			batches = append(batches, nil)
+			loads = append(loads, make(map[setID]int))
+			idx = len(batches) - 1
+		}
+		batches[idx] = append(batches[idx], node)
+		for s, n := range drives[node] {
+			loads[idx][s] += n
+		}
+	}
+
+	if _, ok := drives[local]; ok {
+		if err := checkAlone(local); err != nil {
+			return nil, err
+		}
+		batches = append(batches, []string{local})
+	}
+	return batches, nil
+}
+
+// quorumMargins returns the number of drives each erasure set can lose.
+func quorumMargins(z *erasureServerPools) [][]int {
+	var h ClusterHealth
+	checkSetsHealth(z, &h)
+	margins := make([][]int, len(z.currentPools()))
+	for _, s := range h.Sets {
+		for len(margins[s.Pool]) <= s.Set {
+			margins[s.Pool] = append(margins[s.Pool], 0)
+		}
+		margins[s.Pool][s.Set] = s.QuorumMargin
+	}
+	return margins
+}
+
+// start plans the batches and starts updating the cluster in background.
+func (u *rollingUpdate) start(objAPI ObjectLayer, updateURL *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string) (RollingUpdateStatus, error) {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return RollingUpdateStatus{}, NotImplemented{}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.status != nil && u.status.State == RollingUpdateRunning {
+		return RollingUpdateStatus{}, errRollingUpdateRunning
+	}
+
+	batches, err := planUpdateBatches(globalEndpoints, quorumMargins(z), globalLocalNodeName)
+	if err != nil {
+		return RollingUpdateStatus{}, err
+	}
+
+	status := &RollingUpdateStatus{
+		State:          RollingUpdateRunning,
+		CurrentVersion: Version,
+		UpdatedVersion: lrTime.Format(minioReleaseTagTimeLayout),
+		Started:        UTCNow(),
+		Batches:        len(batches),
+	}
+	for i, batch := range batches {
+		for _, node := range batch {
+			status.Nodes = append(status.Nodes, RollingUpdateNode{Node: node, Batch: i, State: updateNodePending})
+		}
+	}
+	u.status = status
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	u.cancel = cancel
+	go u.run(ctx, objAPI, batches, updateURL, sha256Sum, lrTime, releaseInfo)
+	return *status, nil
+}
+
+// abort stops the update before the next batch, returns false
+// if no update is running.
+func (u *rollingUpdate) abort() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.status == nil || u.status.State != RollingUpdateRunning {
+		return false
+	}
+	u.cancel()
+	return true
+}
+
+// getStatus returns the progress of the last rolling update, false if none.
+func (u *rollingUpdate) getStatus() (RollingUpdateStatus, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.status == nil {
+		return RollingUpdateStatus{}, false
+	}
+	status := *u.status
+	status.Nodes = append([]RollingUpdateNode(nil), u.status.Nodes...)
+	return status, true
+}
+
+func (u *rollingUpdate) setReplaced(node string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for i := range u.status.Nodes {
+		if u.status.Nodes[i].Node == node {
+			u.status.Nodes[i].replaced = true
+		}
+	}
+}
+
+func (u *rollingUpdate) isReplaced(node string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, n := range u.status.Nodes {
+		if n.Node == node {
+			return n.replaced
+		}
+	}
+	return false
+}
+
+func (u *rollingUpdate) setNodeState(node, state string, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for i := range u.status.Nodes {
+		if u.status.Nodes[i].Node == node {
+			u.status.Nodes[i].State = state
+			if err != nil {
+				u.status.Nodes[i].Error = err.Error()
+			}
+		}
+	}
+}
+
+func (u *rollingUpdate) finish(state string, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.status.State = state
+	u.status.Finished = UTCNow()
+	if err != nil {
+		u.status.Error = err.Error()
+	}
+	u.cancel()
+}
+
+func (u *rollingUpdate) run(ctx context.Context, objAPI ObjectLayer, batches [][]string, updateURL *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string) {
+	version := lrTime.Format(minioReleaseTagTimeLayout)
+	for i, batch := range batches {
+		u.mu.Lock()
+		u.status.Batch = i
+		u.mu.Unlock()
+
+		if ctx.Err() != nil {
+			// Aborted on request, the nodes already updated keep the new version.
+			u.finish(RollingUpdateAborted, nil)
+			return
+		}
+
+		if err := waitClusterHealthy(ctx, objAPI); err != nil {
+			u.rollback(batches[:i])
+			u.finish(RollingUpdateFailed, fmt.Errorf("cluster not healthy before batch %d: %w", i, err))
+			return
+		}
+
+		if len(batch) == 1 && batch[0] == globalLocalNodeName {
+			// Last batch, this node restarts itself and the final
+			// state is only known from the status of the other nodes.
+			if _, err := updateServer(updateURL, sha256Sum, lrTime, releaseInfo, getMinioMode(), true); err != nil {
+				u.setNodeState(globalLocalNodeName, updateNodeFailed, err)
+				u.rollback(batches[:i])
+				u.finish(RollingUpdateFailed, err)
+				return
+			}
+			u.setNodeState(globalLocalNodeName, updateNodeRestarting, nil)
+			u.finish(RollingUpdateCompleted, nil)
+			logger.Info("Rolling update of all other nodes to %s completed, restarting", version)
+			globalServiceSignalCh <- serviceRestart
+			return
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(batch))
+		for idx, node := range batch {
+			wg.Add(1)
+			go func(idx int, node string) {
+				defer wg.Done()
+				errs[idx] = u.updateNode(ctx, node, updateURL, sha256Sum, lrTime, releaseInfo)
+			}(idx, node)
+		}
+		wg.Wait()
+
+		var err error
+		for _, err = range errs {
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = waitClusterHealthy(ctx, objAPI)
+		}
+		if err != nil {
+			u.rollback(batches[:i+1])
+			u.finish(RollingUpdateFailed, fmt.Errorf("batch %d failed: %w", i, err))
+			return
+		}
+	}
+	u.finish(RollingUpdateCompleted, nil)
+}
+
+// updateNode replaces the binary of the node, drains and restarts it.
+func (u *rollingUpdate) updateNode(ctx context.Context, node string, updateURL *url.URL, sha256Sum []byte, lrTime time.Time, releaseInfo string) error {
+	client := globalNotificationSys.peerClient(node)
+	if client == nil {
+		u.setNodeState(node, updateNodeFailed, errNodeNotFound)
+		return errNodeNotFound
+	}
+
+	fail := func(err error) error {
+		err = fmt.Errorf("%s: %w", node, err)
+		u.setNodeState(node, updateNodeFailed, err)
+		return err
+	}
+
+	if err := client.ServerUpdate(ctx, updateURL, sha256Sum, lrTime, releaseInfo, true); err != nil {
+		return fail(err)
+	}
+	u.setReplaced(node)
+
+	u.setNodeState(node, updateNodeDraining, nil)
+	if _, err := client.Drain(ctx, rollingUpdateDrainTimeout); err != nil {
+		return fail(err)
+	}
+	if err := pollUntil(ctx, rollingUpdateDrainTimeout+rollingUpdateNodeTimeout, func() (bool, error) {
+		status, err := client.DrainStatus(ctx)
+		return err == nil && status.Ready, nil
+	}); err != nil {
+		return fail(err)
+	}
+
+	u.setNodeState(node, updateNodeRestarting, nil)
+	if err := restartNode(ctx, client, lrTime.Format(minioReleaseTagTimeLayout)); err != nil {
+		return fail(err)
+	}
+	u.setNodeState(node, updateNodeUpdated, nil)
+	return nil
+}
+
+// restartNode restarts the node and waits for it to come back running version.
+func restartNode(ctx context.Context, client *peerRESTClient, version string) error {
+	if err := client.SignalService(serviceRestart); err != nil {
+		return err
+	}
+	// A restarted node is not draining anymore.
+	if err := pollUntil(ctx, rollingUpdateNodeTimeout, func() (bool, error) {
+		status, err := client.DrainStatus(ctx)
+		return err == nil && status.State == NodeDrainNone, nil
+	}); err != nil {
+		return err
+	}
+	props, err := client.ServerInfo()
+	if err != nil {
+		return err
+	}
+	if props.Version != version {
+		return fmt.Errorf("node runs version %s after restart, expected %s", props.Version, version)
+	}
+	return nil
+}
+
+// rollback restores the previous binary of the nodes of the given
+// batches and restarts them, one batch at a time.
+func (u *rollingUpdate) rollback(batches [][]string) {
+	ctx := GlobalContext
+	for _, batch := range batches {
+		var wg sync.WaitGroup
+		for _, node := range batch {
+			client := globalNotificationSys.peerClient(node)
+			if client == nil || !u.isReplaced(node) {
+				continue
+			}
+			wg.Add(1)
+			go func(node string, client *peerRESTClient) {
+				defer wg.Done()
+				err := client.ServerUpdateRollback(ctx)
+				if err == nil {
+					err = restartNode(ctx, client, Version)
+				}
+				if err != nil {
+					logger.LogIf(ctx, fmt.Errorf("unable to roll back update of %s: %w", node, err))
+					u.setNodeState(node, updateNodeFailed, err)
+					return
+				}
+				u.setNodeState(node, updateNodeRolledBack, nil)
+			}(node, client)
+		}
+		wg.Wait()
+	}
+}
+
+// waitClusterHealthy waits for the cluster to have write quorum on all sets.
+func waitClusterHealthy(ctx context.Context, objAPI ObjectLayer) error {
+	return pollUntil(ctx, rollingUpdateNodeTimeout, func() (bool, error) {
+		return objAPI.Health(ctx, HealthOptions{}).Healthy, nil
+	})
+}
+
+// pollUntil calls fn until it returns true, an error or timeout expires.
+func pollUntil(ctx context.Context, timeout time.Duration, fn func() (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(rollingUpdatePollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := fn()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// testPoolEndpoints returns a pool of nodes with drives each, the
+// drives of a node following each other.
+func testPoolEndpoints(nodes, drives, drivesPerSet int) PoolEndpoints {
+	pool := PoolEndpoints{
+		SetCount:     nodes * drives / drivesPerSet,
+		DrivesPerSet: drivesPerSet,
+	}
+	for n := 1; n <= nodes; n++ {
+		for d := 1; d <= drives; d++ {
+			pool.Endpoints = append(pool.Endpoints, Endpoint{URL: &url.URL{
+				Scheme: "http",
+				Host:   fmt.Sprintf("node%d:9000", n),
+				Path:   fmt.Sprintf("/disk%d", d),
+			}})
+		}
+	}
+	return pool
+}
+
+func TestPlanUpdateBatches(t *testing.T) {
+	testCases := []struct {
+		pools   EndpointServerPools
+		margins [][]int
+		local   string
+		batches [][]string
+		wantErr bool
+	}{
+		// 4 nodes of 4 drives in a single set, a node at a time.
+		{
+			pools:   EndpointServerPools{testPoolEndpoints(4, 4, 16)},
+			margins: [][]int{{4}},
+			local:   "node1:9000",
+			batches: [][]string{{"node2:9000"}, {"node3:9000"}, {"node4:9000"}, {"node1:9000"}},
+		},
+		// 4 nodes of 4 drives in 4 sets, each node holds a whole set.
+		{
+			pools:   EndpointServerPools{testPoolEndpoints(4, 4, 4)},
+			margins: [][]int{{1, 1, 1, 1}},
+			local:   "node1:9000",
+			wantErr: true,
+		},
+		// 4 nodes of 2 drives in a single set with a margin of 4 drives.
+		{
+			pools:   EndpointServerPools{testPoolEndpoints(4, 2, 8)},
+			margins: [][]int{{4}},
+			local:   "node4:9000",
+			batches: [][]string{{"node1:9000", "node2:9000"}, {"node3:9000"}, {"node4:9000"}},
+		},
+		// A drive is already offline, no margin left.
+		{
+			pools:   EndpointServerPools{testPoolEndpoints(4, 4, 16)},
+			margins: [][]int{{0}},
+			local:   "node1:9000",
+			wantErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		batches, err := planUpdateBatches(tc.pools, tc.margins, tc.local)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.wantErr, err)
+		}
+		if !reflect.DeepEqual(batches, tc.batches) {
+			t.Errorf("Test %d: expected batches %v, got %v", i+1, tc.batches, batches)
+		}
+	}
+}
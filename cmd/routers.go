@@ -40,6 +40,8 @@ func registerDistErasureRouters(router *mux.Router, endpointServerPools Endpoint
 
 // List of some generic handlers which are applied for all incoming requests.
 var globalHandlers = []mux.MiddlewareFunc{
+	// Rejects banned clients before any other processing.
+	setIPThrottleHandler,
 	// filters HTTP headers which are treated as metadata and are reserved
 	// for internal use only.
 	filterReservedMetadata,
@@ -63,14 +65,24 @@ var globalHandlers = []mux.MiddlewareFunc{
 	setRequestSizeLimitHandler,
 	// Network statistics
 	setHTTPStatsHandler,
+	// Extract W3C trace context and trace internode calls.
+	setRequestTracingHandler,
 	// Validate all the incoming requests.
 	setRequestValidityHandler,
+	// Reject new S3 requests while the node is draining.
+	setDrainHandler,
+	// Reject object writes to buckets mounting a snapshot.
+	setSnapshotMountHandler,
+	// Reject all writes to archived buckets.
+	setBucketArchiveHandler,
 	// Forward path style requests to actual host in a bucket federated setup.
 	setBucketForwardingHandler,
 	// set HTTP security headers such as Content-Security-Policy.
 	addSecurityHeaders,
 	// set x-amz-request-id header.
 	addCustomHeaders,
+	// Pick up the correlation ID of clients and peers.
+	setCorrelationIDHandler,
 	// add redirect handler to redirect
 	// requests when object layer is not
 	// initialized.
@@ -98,6 +110,9 @@ func configureServerHandler(endpointServerPools EndpointServerPools) (http.Handl
 	// Add server metrics router
 	registerMetricsRouter(router)
 
+	// Add status page router
+	registerStatusPageRouter(router)
+
 	// Add STS router always.
 	registerSTSRouter(router)
 
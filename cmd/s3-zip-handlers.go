@@ -139,6 +139,12 @@ func (api objectAPIHandlers) getObjectInArchiveFileHandler(ctx context.Context,
 		return
 	}
 
+	// Files of quarantined archives are not readable either.
+	if isQuarantined(zipObjInfo) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrObjectQuarantined), r.URL)
+		return
+	}
+
 	var zipInfo []byte
 
 	if z, ok := zipObjInfo.UserDefined[archiveInfoMetadataKey]; ok {
@@ -0,0 +1,200 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	scannerHealthObjName       = ".scanner-health.json"
+	scannerHealthCheckInterval = time.Minute
+)
+
+// ScannerSetHealth - the scans of an erasure set. A scan is running if
+// it started after the last success and failure.
+type ScannerSetHealth struct {
+	Pool        int       `json:"pool"`
+	Set         int       `json:"set"`
+	LastStart   time.Time `json:"lastStart"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastFailure time.Time `json:"lastFailure"`
+	LastError   string    `json:"lastError,omitempty"`
+	Stale       bool      `json:"stale"`
+}
+
+func (h ScannerSetHealth) running() bool {
+	return h.LastStart.After(h.LastSuccess) && h.LastStart.After(h.LastFailure)
+}
+
+// ScannerHealth - the scans of all erasure sets, as of LastUpdate.
+type ScannerHealth struct {
+	LastUpdate time.Time          `json:"lastUpdate"`
+	StaleAfter time.Duration      `json:"staleAfter"`
+	Sets       []ScannerSetHealth `json:"sets"`
+}
+
+// scannerHealth tracks the scans of the erasure sets by the scanner
+// running on this node, alerting sets not scanned successfully for
+// longer than staleAfter. Sets never scanned successfully are stale
+// after staleAfter since tracking started.
+type scannerHealth struct {
+	mu         sync.Mutex
+	staleAfter time.Duration
+	since      time.Time
+	sets       map[[2]int]*ScannerSetHealth
+	alerted    map[[2]int]bool
+}
+
+var globalScannerHealth = &scannerHealth{
+	staleAfter: 24 * time.Hour,
+	sets:       make(map[[2]int]*ScannerSetHealth),
+	alerted:    make(map[[2]int]bool),
+}
+
+func (s *scannerHealth) setStaleAfter(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.staleAfter = d
+}
+
+// set returns the health of the set, the caller must hold the lock.
+func (s *scannerHealth) set(pool, set int) *ScannerSetHealth {
+	h, ok := s.sets[[2]int{pool, set}]
+	if !ok {
+		h = &ScannerSetHealth{Pool: pool, Set: set}
+		s.sets[[2]int{pool, set}] = h
+	}
+	return h
+}
+
+// restore seeds the health from the last saved by a previous leader.
+func (s *scannerHealth) restore(prev ScannerHealth, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = now
+	for _, h := range prev.Sets {
+		h := h
+		s.sets[[2]int{h.Pool, h.Set}] = &h
+	}
+}
+
+func (s *scannerHealth) started(pool, set int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(pool, set).LastStart = now
+}
+
+func (s *scannerHealth) finished(pool, set int, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.set(pool, set)
+	if err != nil {
+		h.LastFailure, h.LastError = now, err.Error()
+		return
+	}
+	h.LastSuccess, h.LastError = now, ""
+}
+
+// evaluate marks the sets not scanned successfully for longer than the
+// stale duration and returns those becoming stale.
+func (s *scannerHealth) evaluate(now time.Time) (health ScannerHealth, stale []ScannerSetHealth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	health = ScannerHealth{LastUpdate: now, StaleAfter: s.staleAfter, Sets: []ScannerSetHealth{}}
+	for key, h := range s.sets {
+		last := h.LastSuccess
+		if last.IsZero() {
+			last = s.since
+		}
+		h.Stale = s.staleAfter > 0 && now.Sub(last) > s.staleAfter
+		if h.Stale && !s.alerted[key] {
+			stale = append(stale, *h)
+		}
+		s.alerted[key] = h.Stale
+		health.Sets = append(health.Sets, *h)
+	}
+	sort.Slice(health.Sets, func(i, j int) bool {
+		if health.Sets[i].Pool != health.Sets[j].Pool {
+			return health.Sets[i].Pool < health.Sets[j].Pool
+		}
+		return health.Sets[i].Set < health.Sets[j].Set
+	})
+	return health, stale
+}
+
+// runScannerHealthCheck saves the scanner health for the admin API and
+// the metrics and alerts stale sets, while ctx holds the scanner lead.
+func runScannerHealthCheck(ctx context.Context, objAPI ObjectLayer) {
+	prev, err := loadScannerHealth(ctx, objAPI)
+	logger.LogIf(ctx, err)
+	globalScannerHealth.restore(prev, UTCNow())
+
+	ticker := time.NewTicker(scannerHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			health, stale := globalScannerHealth.evaluate(UTCNow())
+			for _, h := range stale {
+				reason := "never completed"
+				if !h.LastSuccess.IsZero() {
+					reason = fmt.Sprintf("last completed %s", h.LastSuccess.Format(time.RFC3339))
+				}
+				if h.running() {
+					reason += fmt.Sprintf(", running since %s", h.LastStart.Format(time.RFC3339))
+				} else if h.LastError != "" {
+					reason += fmt.Sprintf(", last failed: %s", h.LastError)
+				}
+				logger.LogIf(ctx, fmt.Errorf("Scanner of pool %d set %d is stale, usage may be outdated: %s", h.Pool+1, h.Set+1, reason), logger.Application)
+			}
+			data, err := json.Marshal(health)
+			if err == nil {
+				err = saveConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, scannerHealthObjName), data)
+			}
+			logger.LogIf(ctx, err)
+		}
+	}
+}
+
+// loadScannerHealth returns the scanner health last saved, empty if not
+// saved yet.
+func loadScannerHealth(ctx context.Context, objAPI ObjectLayer) (ScannerHealth, error) {
+	var h ScannerHealth
+	data, err := readConfig(ctx, objAPI, pathJoin(bucketMetaPrefix, scannerHealthObjName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return h, nil
+		}
+		return h, err
+	}
+	if err = json.Unmarshal(data, &h); err != nil {
+		return h, err
+	}
+	return h, nil
+}
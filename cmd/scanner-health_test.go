@@ -0,0 +1,78 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScannerHealthEvaluate(t *testing.T) {
+	now := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	s := &scannerHealth{
+		staleAfter: time.Hour,
+		sets:       make(map[[2]int]*ScannerSetHealth),
+		alerted:    make(map[[2]int]bool),
+	}
+	s.restore(ScannerHealth{}, now)
+
+	s.started(0, 0, now)
+	s.finished(0, 0, nil, now.Add(time.Minute))
+	s.started(0, 1, now)
+	s.finished(0, 1, errors.New("disk not found"), now.Add(time.Minute))
+	s.started(1, 0, now)
+
+	health, stale := s.evaluate(now.Add(30 * time.Minute))
+	if len(stale) != 0 {
+		t.Fatalf("expected no stale sets, got %v", stale)
+	}
+	if len(health.Sets) != 3 || health.Sets[0].Pool != 0 || health.Sets[1].Set != 1 || health.Sets[2].Pool != 1 {
+		t.Fatalf("unexpected sets %v", health.Sets)
+	}
+	if !health.Sets[2].running() || health.Sets[1].running() || health.Sets[1].LastError == "" {
+		t.Fatalf("unexpected set states %v", health.Sets)
+	}
+
+	// All sets are stale, only announced once.
+	_, stale = s.evaluate(now.Add(2 * time.Hour))
+	if len(stale) != 3 {
+		t.Fatalf("expected 3 stale sets, got %v", stale)
+	}
+	health, stale = s.evaluate(now.Add(3 * time.Hour))
+	if len(stale) != 0 || !health.Sets[0].Stale {
+		t.Fatalf("expected stale sets announced once, got %v", stale)
+	}
+
+	// A successful scan ends the staleness, alerted again afterwards.
+	s.finished(0, 0, nil, now.Add(3*time.Hour))
+	health, _ = s.evaluate(now.Add(3 * time.Hour))
+	if health.Sets[0].Stale || health.Sets[0].LastError != "" {
+		t.Fatalf("expected set 0 healthy, got %v", health.Sets[0])
+	}
+	_, stale = s.evaluate(now.Add(5 * time.Hour))
+	if len(stale) != 1 || stale[0].Set != 0 || stale[0].Pool != 0 {
+		t.Fatalf("expected set 0 stale again, got %v", stale)
+	}
+
+	// Disabled.
+	s.setStaleAfter(0)
+	if _, stale = s.evaluate(now.Add(100 * time.Hour)); len(stale) != 0 {
+		t.Fatalf("expected no stale sets when disabled, got %v", stale)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// Maximum size of a manifest listing the objects of a multi-object select.
+const maxSelectManifestSize = 8 << 20
+
+// selectObjectError reports an API error for one object of a
+// multi-object select, it implements s3select.SelectError.
+type selectObjectError struct {
+	apiErr APIError
+}
+
+func (e selectObjectError) Cause() error         { return nil }
+func (e selectObjectError) ErrorCode() string    { return e.apiErr.Code }
+func (e selectObjectError) ErrorMessage() string { return e.apiErr.Description }
+func (e selectObjectError) HTTPStatusCode() int  { return e.apiErr.HTTPStatusCode }
+func (e selectObjectError) Error() string        { return e.apiErr.Description }
+
+// selectObjectKeys iterates over the keys queried by a multi-object
+// select, either listed under prefix or read from a manifest.
+type selectObjectKeys struct {
+	ctx    context.Context
+	objAPI ObjectLayer
+	bucket string
+	prefix string
+
+	keys   []string
+	marker string
+	done   bool
+}
+
+// newSelectManifestKeys reads the keys of a manifest object, one key
+// per line, empty lines are ignored.
+func newSelectManifestKeys(ctx context.Context, objAPI ObjectLayer, bucket, manifest string, opts ObjectOptions) (*selectObjectKeys, error) {
+	gr, err := objAPI.GetObjectNInfo(ctx, bucket, manifest, nil, nil, readLock, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	if gr.ObjInfo.Size > maxSelectManifestSize {
+		return nil, errInvalidArgument
+	}
+
+	l := &selectObjectKeys{done: true}
+	scanner := bufio.NewScanner(io.LimitReader(gr, maxSelectManifestSize))
+	scanner.Buffer(make([]byte, 0, 64<<10), maxSelectManifestSize)
+	for scanner.Scan() {
+		if key := strings.TrimSpace(scanner.Text()); key != "" {
+			l.keys = append(l.keys, key)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// newSelectPrefixKeys lists the objects of bucket under prefix.
+func newSelectPrefixKeys(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) *selectObjectKeys {
+	return &selectObjectKeys{
+		ctx:    ctx,
+		objAPI: objAPI,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+// next returns the next key, a listing failure is returned with the
+// prefix as key and ends the iteration.
+func (l *selectObjectKeys) next() (key string, ok bool, err error) {
+	for len(l.keys) == 0 {
+		if l.done {
+			return "", false, nil
+		}
+		loi, err := l.objAPI.ListObjects(l.ctx, l.bucket, l.prefix, l.marker, "", maxObjectList)
+		if err != nil {
+			l.done = true
+			return l.prefix, true, err
+		}
+		for _, oi := range loi.Objects {
+			if !oi.IsDir {
+				l.keys = append(l.keys, oi.Name)
+			}
+		}
+		l.done = !loi.IsTruncated
+		l.marker = loi.NextMarker
+	}
+	key, l.keys = l.keys[0], l.keys[1:]
+	return key, true, nil
+}
@@ -132,6 +132,22 @@ func serverCmdArgs(ctx *cli.Context) []string {
 	return strings.Fields(v)
 }
 
+// newRemoteEndpoints returns an endpoint of every node by its host, the
+// local node by its name.
+func newRemoteEndpoints(endpoints EndpointServerPools) map[string]Endpoint {
+	remoteEndpoints := make(map[string]Endpoint)
+	for _, z := range endpoints {
+		for _, ep := range z.Endpoints {
+			if ep.IsLocal {
+				remoteEndpoints[globalLocalNodeName] = ep
+			} else {
+				remoteEndpoints[ep.Host] = ep
+			}
+		}
+	}
+	return remoteEndpoints
+}
+
 func serverHandleCmdArgs(ctx *cli.Context) {
 	// Handle common command args.
 	handleCommonCmdArgs(ctx)
@@ -157,21 +173,13 @@ func serverHandleCmdArgs(ctx *cli.Context) {
 	// Register root CAs for remote ENVs
 	env.RegisterGlobalCAs(globalRootCAs)
 
-	globalEndpoints, setupType, err = createServerEndpoints(globalMinioAddr, serverCmdArgs(ctx)...)
+	globalServerCmdArgs = withExpandedPools(serverCmdArgs(ctx))
+	globalEndpoints, setupType, err = createServerEndpoints(globalMinioAddr, globalServerCmdArgs...)
 	logger.FatalIf(err, "Invalid command line arguments")
 
 	globalLocalNodeName = GetLocalPeer(globalEndpoints, globalMinioHost, globalMinioPort)
 
-	globalRemoteEndpoints = make(map[string]Endpoint)
-	for _, z := range globalEndpoints {
-		for _, ep := range z.Endpoints {
-			if ep.IsLocal {
-				globalRemoteEndpoints[globalLocalNodeName] = ep
-			} else {
-				globalRemoteEndpoints[ep.Host] = ep
-			}
-		}
-	}
+	globalRemoteEndpoints = newRemoteEndpoints(globalEndpoints)
 
 	// allow transport to be HTTP/1.1 for proxying.
 	globalProxyTransport = newCustomHTTPProxyTransport(&tls.Config{
@@ -197,6 +205,8 @@ func serverHandleCmdArgs(ctx *cli.Context) {
 	if globalIsDistErasure {
 		globalIsErasure = true
 	}
+
+	initArbiter(globalEndpoints)
 }
 
 func serverHandleEnvVars() {
@@ -250,6 +260,12 @@ func newAllSubsystems() {
 	// Create new bucket quota subsystem
 	globalBucketQuotaSys = NewBucketQuotaSys()
 
+	// Create new tenant subsystem
+	globalTenantSys = NewTenantSys()
+
+	// Create new bucket template subsystem
+	globalBucketTemplateSys = NewBucketTemplateSys()
+
 	// Create new bucket versioning subsystem
 	if globalBucketVersioningSys == nil {
 		globalBucketVersioningSys = NewBucketVersioningSys()
@@ -422,6 +438,21 @@ func initAllSubsystems(ctx context.Context, newObject ObjectLayer) (err error) {
 	// Initialize site replication manager.
 	globalSiteReplicationSys.Init(ctx, newObject)
 
+	// Initialize tenants.
+	if err = globalTenantSys.Init(ctx, newObject); err != nil {
+		logger.LogIf(ctx, fmt.Errorf("Unable to initialize tenants: %w", err))
+	}
+
+	// Initialize bucket templates.
+	if err = globalBucketTemplateSys.Init(ctx, newObject); err != nil {
+		logger.LogIf(ctx, fmt.Errorf("Unable to initialize bucket templates: %w", err))
+	}
+
+	// Initialize background job schedules.
+	if err = globalJobScheduler.Init(ctx, newObject); err != nil {
+		logger.LogIf(ctx, fmt.Errorf("Unable to initialize job schedules: %w", err))
+	}
+
 	if globalIsErasure {
 		// Initialize transition tier configuration manager
 		if err = globalTierConfigMgr.Init(ctx, newObject); err != nil {
@@ -439,7 +470,7 @@ func (lw nullWriter) Write(b []byte) (int, error) {
 
 // serverMain handler called for 'minio server' command.
 func serverMain(ctx *cli.Context) {
-	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	signal.Notify(globalOSSignalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 
 	go handleSignals()
 
@@ -469,6 +500,17 @@ func serverMain(ctx *cli.Context) {
 	// Initialize all sub-systems
 	newAllSubsystems()
 
+	// ACME certificates are served along with the static certificates.
+	acmeSys, err := newACMESys(globalDomainNames)
+	logger.FatalIf(err, "Unable to configure ACME")
+	globalACMESys = acmeSys
+	if globalACMESys != nil && !globalIsTLS {
+		if globalIsDistErasure {
+			logger.Fatal(errors.New("ACME requires static certificates for the internode TLS of distributed setups"), "Unable to start the server")
+		}
+		globalIsTLS = true
+	}
+
 	// Is distributed setup, error out if no certificates are found for HTTPS endpoints.
 	if globalIsDistErasure {
 		if globalEndpoints.HTTPS() && !globalIsTLS {
@@ -487,6 +529,9 @@ func serverMain(ctx *cli.Context) {
 	if !globalActiveCred.IsValid() && globalIsDistErasure {
 		globalActiveCred = auth.DefaultCredentials
 	}
+	if globalArbiter != nil {
+		logger.FatalIf(checkArbiterCredentials(globalActiveCred), "Unable to configure the arbiter")
+	}
 
 	// Set system resources to maximum.
 	setMaxResources()
@@ -499,7 +544,10 @@ func serverMain(ctx *cli.Context) {
 
 	var getCert certs.GetCertificateFunc
 	if globalTLSCerts != nil {
-		getCert = globalTLSCerts.GetCertificate
+		getCert = getTLSCertificate
+	}
+	if globalACMESys != nil {
+		getCert = globalACMESys.getCertificateFunc(getCert)
 	}
 
 	listeners := ctx.Int("listeners")
@@ -572,11 +620,30 @@ func serverMain(ctx *cli.Context) {
 	// Initialize users credentials and policies in background right after config has initialized.
 	go globalIAMSys.Init(GlobalContext, newObject, globalEtcdClient)
 
-	initDataScanner(GlobalContext, newObject)
+	// Obtain and renew ACME certificates, after KMS initialization.
+	initACME(GlobalContext, newObject)
+
+	// The scanner leader lock is handed off to another node when draining.
+	initDataScanner(globalNodeDrain.backgroundContext(GlobalContext), newObject)
 
 	if globalIsErasure { // to be done after config init
 		initBackgroundReplication(GlobalContext, newObject)
 		initBackgroundTransition(GlobalContext, newObject)
+		initTrashPurge(GlobalContext, newObject)
+		initDedupScan(GlobalContext, newObject)
+		initAccessTracking(GlobalContext, newObject)
+		initCommitRecovery(GlobalContext, newObject)
+		initLeaseTokenGC(GlobalContext, newObject)
+		initUploadTokenPurge(GlobalContext, newObject)
+		initListIndex(GlobalContext, newObject)
+		initMetadataIndex(GlobalContext, newObject)
+		initChangelog(GlobalContext, newObject)
+		initSlowRequestLog(GlobalContext, newObject)
+		initSLOTracker(GlobalContext, newObject)
+		initKeyFilters(GlobalContext, newObject)
+		initNamespacePressureCheck(GlobalContext, newObject)
+		initIncidentReports(GlobalContext)
+		logger.LogIf(GlobalContext, reloadPoolTags(GlobalContext, newObject))
 		globalTierJournal, err = initTierDeletionJournal(GlobalContext)
 		if err != nil {
 			logger.FatalIf(err, "Unable to initialize remote tier pending deletes journal")
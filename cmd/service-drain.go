@@ -0,0 +1,194 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+// Node drain states.
+const (
+	NodeDrainNone     = "none"
+	NodeDrainDraining = "draining"
+	NodeDrainDrained  = "drained"
+)
+
+const (
+	// Used when the drain request does not specify a timeout.
+	defaultDrainTimeout = 5 * time.Minute
+
+	// A multipart upload is considered finished once no request
+	// for an upload id was received for this long.
+	drainMultipartIdle = 30 * time.Second
+
+	drainPollInterval = time.Second
+)
+
+// DrainStatus is the drain progress of a node.
+type DrainStatus struct {
+	Node             string    `json:"node"`
+	State            string    `json:"state"`
+	Started          time.Time `json:"started,omitempty"`
+	Deadline         time.Time `json:"deadline,omitempty"`
+	InflightRequests int       `json:"inflightRequests"`
+	// Ready is set once the node can be shut down.
+	Ready bool `json:"ready"`
+}
+
+const (
+	drainStateNone int32 = iota
+	drainStateDraining
+	drainStateDrained
+)
+
+// nodeDrain makes the node stop accepting new S3 requests, let the
+// requests in flight finish and hand off its background jobs.
+type nodeDrain struct {
+	state int32
+	// Unix nanoseconds of the last request for a multipart upload.
+	lastUpload int64
+
+	mu       sync.Mutex
+	started  time.Time
+	deadline time.Time
+
+	// Canceled when draining starts.
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
+}
+
+var globalNodeDrain = newNodeDrain()
+
+func newNodeDrain() *nodeDrain {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &nodeDrain{bgCtx: ctx, bgCancel: cancel}
+}
+
+// isDraining returns true once draining started.
+func (d *nodeDrain) isDraining() bool {
+	return atomic.LoadInt32(&d.state) != drainStateNone
+}
+
+// backgroundContext returns a context derived from parent which is canceled
+// when the node starts draining, background jobs holding cluster wide leader
+// locks release them this way so that another node takes over.
+func (d *nodeDrain) backgroundContext(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-d.bgCtx.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	return ctx
+}
+
+// start starts draining the node, requests still in flight after
+// timeout are canceled. Calling start again has no effect.
+func (d *nodeDrain) start(timeout time.Duration) DrainStatus {
+	d.mu.Lock()
+	if atomic.CompareAndSwapInt32(&d.state, drainStateNone, drainStateDraining) {
+		d.started = UTCNow()
+		d.deadline = d.started.Add(timeout)
+		if srv := newHTTPServerFn(); srv != nil {
+			// Make clients reconnect, load balancers will pick another node.
+			srv.SetKeepAlivesEnabled(false)
+		}
+		d.bgCancel()
+		go d.wait(d.deadline)
+		logger.Info("Draining node %s, new S3 requests are rejected until %s", globalLocalNodeName, d.deadline)
+	}
+	d.mu.Unlock()
+	return d.status()
+}
+
+// wait marks the node drained once there are no more requests
+// in flight and multipart uploads are idle, or deadline is reached.
+func (d *nodeDrain) wait(deadline time.Time) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for time.Now().Before(deadline) && !d.idle() {
+		<-ticker.C
+	}
+	for _, req := range globalInflightRequests.list() {
+		globalInflightRequests.cancel(req.ID)
+	}
+	atomic.StoreInt32(&d.state, drainStateDrained)
+	logger.Info("Node %s drained, ready for shutdown", globalLocalNodeName)
+}
+
+func (d *nodeDrain) idle() bool {
+	lastUpload := time.Unix(0, atomic.LoadInt64(&d.lastUpload))
+	return globalInflightRequests.count() == 0 && time.Since(lastUpload) > drainMultipartIdle
+}
+
+// uploadRequest records a request for an ongoing multipart upload.
+func (d *nodeDrain) uploadRequest() {
+	atomic.StoreInt64(&d.lastUpload, time.Now().UnixNano())
+}
+
+func (d *nodeDrain) status() DrainStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := DrainStatus{
+		Node:             globalLocalNodeName,
+		State:            NodeDrainNone,
+		Started:          d.started,
+		Deadline:         d.deadline,
+		InflightRequests: globalInflightRequests.count(),
+	}
+	switch atomic.LoadInt32(&d.state) {
+	case drainStateDraining:
+		s.State = NodeDrainDraining
+	case drainStateDrained:
+		s.State = NodeDrainDrained
+		s.Ready = true
+	}
+	return s
+}
+
+// setDrainHandler rejects new S3 requests while the node is draining,
+// internode, admin, health check and metrics requests as well as the
+// requests of multipart uploads already in progress are still served.
+func setDrainHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !globalNodeDrain.isDraining() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		switch {
+		case guessIsRPCReq(r), isAdminReq(r), guessIsHealthCheckReq(r), guessIsMetricsReq(r), guessIsStatusPageReq(r):
+		case r.URL.Query().Get(xhttp.UploadID) != "":
+			globalNodeDrain.uploadRequest()
+			w.Header().Set(xhttp.Connection, "close")
+		default:
+			w.Header().Set(xhttp.Connection, "close")
+			writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrServerDraining), r.URL)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
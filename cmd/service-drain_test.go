@@ -0,0 +1,91 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNodeDrainBackgroundContext(t *testing.T) {
+	d := newNodeDrain()
+	ctx := d.backgroundContext(context.Background())
+	if ctx.Err() != nil {
+		t.Fatal("background context canceled before draining")
+	}
+
+	status := d.start(0)
+	if status.State == NodeDrainNone {
+		t.Fatalf("expected node to be draining, got %s", status.State)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("background context not canceled after draining started")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !d.status().Ready {
+		if time.Now().After(deadline) {
+			t.Fatal("node not drained after timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDrainHandler(t *testing.T) {
+	saved := globalNodeDrain
+	defer func() { globalNodeDrain = saved }()
+	globalNodeDrain = newNodeDrain()
+
+	handler := setDrainHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		method   string
+		url      string
+		draining bool
+		status   int
+	}{
+		{http.MethodGet, "/bucket/object", false, http.StatusOK},
+		{http.MethodGet, "/bucket/object", true, http.StatusServiceUnavailable},
+		{http.MethodPut, "/bucket/object?partNumber=2&uploadId=abc", true, http.StatusOK},
+		{http.MethodGet, healthCheckPathPrefix + healthCheckLivenessPath, true, http.StatusOK},
+		{http.MethodPost, adminPathPrefix + adminAPIVersionPrefix + "/service/drain", true, http.StatusOK},
+	}
+
+	for i, tc := range testCases {
+		state := drainStateNone
+		if tc.draining {
+			state = drainStateDraining
+		}
+		atomic.StoreInt32(&globalNodeDrain.state, state)
+
+		req := httptest.NewRequest(tc.method, tc.url, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("Test %d: expected status %d, got %d", i+1, tc.status, rec.Code)
+		}
+	}
+}
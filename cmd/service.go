@@ -31,6 +31,7 @@ const (
 	serviceRestart       serviceSignal = iota // Restarts the server.
 	serviceStop                               // Stops the server.
 	serviceReloadDynamic                      // Reload dynamic config values.
+	serviceReload                             // Reload certificates and all configs.
 	// Add new service requests here.
 )
 
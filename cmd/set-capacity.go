@@ -0,0 +1,145 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"sort"
+
+	"github.com/minio/madmin-go"
+)
+
+// SetCapacity - the capacity of an erasure set. All shards of an object
+// are of the same size, so every drive of a set holds as much as its
+// smallest drive: the usable capacity is the smallest drive times the
+// online drives, the rest of the larger drives is stranded. Shards are
+// not written in proportion to the drive sizes. The data capacity
+// discounts the parity of the standard storage class.
+type SetCapacity struct {
+	Pool          int    `json:"pool"`
+	Set           int    `json:"set"`
+	Drives        int    `json:"drives"`
+	OnlineDrives  int    `json:"onlineDrives"`
+	DataDrives    int    `json:"dataDrives"`
+	SmallestDrive uint64 `json:"smallestDrive"`
+	LargestDrive  uint64 `json:"largestDrive"`
+	Raw           uint64 `json:"raw"`
+	Usable        uint64 `json:"usable"`
+	Stranded      uint64 `json:"stranded"`
+	Free          uint64 `json:"free"`
+	Data          uint64 `json:"data"`
+	DataFree      uint64 `json:"dataFree"`
+}
+
+// CapacityInfo - the capacity of all erasure sets.
+type CapacityInfo struct {
+	Raw      uint64        `json:"raw"`
+	Usable   uint64        `json:"usable"`
+	Stranded uint64        `json:"stranded"`
+	Data     uint64        `json:"data"`
+	DataFree uint64        `json:"dataFree"`
+	Sets     []SetCapacity `json:"sets"`
+}
+
+// getCapacityInfo returns the capacity of the erasure sets of the disks
+// of s, counting online drives only.
+func getCapacityInfo(s StorageInfo) CapacityInfo {
+	type setID struct{ pool, set int }
+	sets := make(map[setID]*SetCapacity)
+	for _, disk := range s.Disks {
+		id := setID{disk.PoolIndex, disk.SetIndex}
+		sc, ok := sets[id]
+		if !ok {
+			sc = &SetCapacity{Pool: disk.PoolIndex, Set: disk.SetIndex}
+			sets[id] = sc
+		}
+		sc.Drives++
+		if disk.State != madmin.DriveStateOk || disk.TotalSpace == 0 {
+			continue
+		}
+		free := disk.TotalSpace - disk.UsedSpace
+		if disk.UsedSpace > disk.TotalSpace {
+			free = 0
+		}
+		if sc.OnlineDrives == 0 || disk.TotalSpace < sc.SmallestDrive {
+			sc.SmallestDrive = disk.TotalSpace
+		}
+		if disk.TotalSpace > sc.LargestDrive {
+			sc.LargestDrive = disk.TotalSpace
+		}
+		if sc.OnlineDrives == 0 || free < sc.Free {
+			// The least free drive for now, multiplied below.
+			sc.Free = free
+		}
+		sc.OnlineDrives++
+		sc.Raw += disk.TotalSpace
+	}
+
+	var info CapacityInfo
+	for _, sc := range sets {
+		n := uint64(sc.OnlineDrives)
+		sc.Usable = sc.SmallestDrive * n
+		sc.Stranded = sc.Raw - sc.Usable
+		sc.Free *= n
+		if sc.Pool >= 0 && sc.Pool < len(s.Backend.StandardSCData) {
+			sc.DataDrives = s.Backend.StandardSCData[sc.Pool]
+		}
+		if n > 0 {
+			// Every stripe has as many data shards with drives offline,
+			// each on another online drive.
+			sc.Data = uint64(float64(sc.Usable) * float64(sc.DataDrives) / float64(n))
+			sc.DataFree = uint64(float64(sc.Free) * float64(sc.DataDrives) / float64(n))
+		}
+
+		info.Raw += sc.Raw
+		info.Usable += sc.Usable
+		info.Stranded += sc.Stranded
+		info.Data += sc.Data
+		info.DataFree += sc.DataFree
+		info.Sets = append(info.Sets, *sc)
+	}
+	sort.Slice(info.Sets, func(i, j int) bool {
+		if info.Sets[i].Pool != info.Sets[j].Pool {
+			return info.Sets[i].Pool < info.Sets[j].Pool
+		}
+		return info.Sets[i].Set < info.Sets[j].Set
+	})
+	return info
+}
+
+// usableAvailable returns the space shards can be written to on the
+// disks, the free space of the least free online disk times the online
+// disks.
+func usableAvailable(disks []*DiskInfo) uint64 {
+	var least uint64
+	var n uint64
+	for _, disk := range disks {
+		if disk == nil || disk.Total == 0 {
+			// Disk offline, as for hasSpaceFor.
+			continue
+		}
+		var free uint64
+		if disk.Total > disk.Used {
+			free = disk.Total - disk.Used
+		}
+		if n == 0 || free < least {
+			least = free
+		}
+		n++
+	}
+	return least * n
+}
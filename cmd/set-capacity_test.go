@@ -0,0 +1,71 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestGetCapacityInfo(t *testing.T) {
+	const tib = uint64(1 << 40)
+	var s StorageInfo
+	s.Backend.StandardSCData = []int{2}
+	s.Backend.StandardSCParity = 2
+	for i, size := range []uint64{4 * tib, 4 * tib, 8 * tib, 8 * tib} {
+		s.Disks = append(s.Disks, madmin.Disk{
+			PoolIndex:  0,
+			SetIndex:   0,
+			DiskIndex:  i,
+			State:      madmin.DriveStateOk,
+			TotalSpace: size,
+			UsedSpace:  tib * uint64(i+1),
+		})
+	}
+	// The second set has a drive offline.
+	for i := 0; i < 3; i++ {
+		s.Disks = append(s.Disks, madmin.Disk{PoolIndex: 0, SetIndex: 1, DiskIndex: i, State: madmin.DriveStateOk, TotalSpace: 4 * tib})
+	}
+	s.Disks = append(s.Disks, madmin.Disk{PoolIndex: 0, SetIndex: 1, DiskIndex: 3, State: madmin.DriveStateOffline})
+
+	info := getCapacityInfo(s)
+	if len(info.Sets) != 2 {
+		t.Fatalf("expected 2 sets, got %d", len(info.Sets))
+	}
+	sc := info.Sets[0]
+	if sc.Raw != 24*tib || sc.Usable != 16*tib || sc.Stranded != 8*tib {
+		t.Errorf("unexpected raw %d, usable %d, stranded %d", sc.Raw, sc.Usable, sc.Stranded)
+	}
+	// The least free drive has 2 TiB left of 4 TiB.
+	if sc.Free != 8*tib || sc.Data != 8*tib || sc.DataFree != 4*tib {
+		t.Errorf("unexpected free %d, data %d, data free %d", sc.Free, sc.Data, sc.DataFree)
+	}
+	// Stripes still have 2 data shards on the 3 online drives.
+	if sc := info.Sets[1]; sc.Drives != 4 || sc.OnlineDrives != 3 || sc.Usable != 12*tib || sc.Data != 8*tib || sc.DataFree != 8*tib {
+		t.Errorf("unexpected capacity of the degraded set %+v", sc)
+	}
+	if info.Stranded != 8*tib || info.Data != 16*tib {
+		t.Errorf("unexpected totals %+v", info)
+	}
+
+	disks := []*DiskInfo{{Total: 100, Used: 10}, {Total: 200, Used: 80}, nil, {}}
+	if available := usableAvailable(disks); available != 180 {
+		t.Errorf("expected 180 available, got %d", available)
+	}
+}
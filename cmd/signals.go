@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"syscall"
 
 	"github.com/minio/minio/internal/logger"
 )
@@ -78,6 +79,15 @@ func handleSignals() {
 		case <-globalHTTPServerErrorCh:
 			exit(stopProcess())
 		case osSignal := <-globalOSSignalCh:
+			if osSignal == syscall.SIGHUP {
+				logger.Info("Reloading configuration on signal: %s", strings.ToUpper(osSignal.String()))
+				if objAPI := newObjectLayerFn(); objAPI != nil {
+					go func() {
+						logger.LogIf(GlobalContext, reloadServerConfig(GlobalContext, objAPI))
+					}()
+				}
+				continue
+			}
 			logger.Info("Exiting on signal: %s", strings.ToUpper(osSignal.String()))
 			exit(stopProcess())
 		case signal := <-globalServiceSignalCh:
@@ -0,0 +1,396 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go"
+)
+
+// Categories of the mismatches reported by the site replication diff.
+const (
+	srDiffIAMPolicy        = "iam-policy"
+	srDiffIAMPolicyMapping = "iam-policy-mapping"
+	srDiffIAMSvcAcc        = "iam-service-account"
+	srDiffBucket           = "bucket"
+	srDiffBucketPolicy     = "bucket-policy"
+	srDiffBucketTags       = "bucket-tags"
+	srDiffBucketObjectLock = "bucket-object-lock"
+	srDiffBucketSSE        = "bucket-sse"
+	srDiffBucketVersioning = "bucket-versioning"
+	srDiffBucketUsage      = "bucket-usage"
+)
+
+// srDiffHints are the remediation hints of the mismatch categories.
+var srDiffHints = map[string]string{
+	srDiffIAMPolicy:        "re-create the policy on the site holding the expected version, site replication copies it to all peers",
+	srDiffIAMPolicyMapping: "set the policy mapping again on one site, site replication copies it to all peers",
+	srDiffIAMSvcAcc:        "update or re-create the service account on one site, site replication copies it to all peers",
+	srDiffBucket:           "create the missing bucket on one site or remove it, or exclude it with the bucket filter if it is meant to stay local",
+	srDiffBucketPolicy:     "set the bucket policy again on one site, site replication copies it to all peers",
+	srDiffBucketTags:       "set the bucket tags again on one site, site replication copies them to all peers",
+	srDiffBucketObjectLock: "set the object lock configuration again on one site, site replication copies it to all peers",
+	srDiffBucketSSE:        "set the bucket encryption again on one site, site replication copies it to all peers",
+	srDiffBucketVersioning: "enable versioning on the bucket on all sites, site replication requires it",
+	srDiffBucketUsage:      "usage is updated by the scanner and may lag, check the replication status of the bucket if the drift persists",
+}
+
+// srDiffMissing is the value reported for entities missing on a site.
+const srDiffMissing = "missing"
+
+// srBucketSnapshot - digests of the replicated metadata and the usage
+// of a bucket on a site.
+type srBucketSnapshot struct {
+	Policy     string `json:"policy,omitempty"`
+	Tags       string `json:"tags,omitempty"`
+	ObjectLock string `json:"objectLock,omitempty"`
+	SSE        string `json:"sse,omitempty"`
+	Versioning string `json:"versioning,omitempty"`
+	Objects    uint64 `json:"objects"`
+	Size       uint64 `json:"size"`
+}
+
+// srSiteSnapshot - digests of the IAM entities and the buckets of a
+// site replicated between sites, to be compared with the peers.
+type srSiteSnapshot struct {
+	Name         string `json:"name"`
+	DeploymentID string `json:"deploymentID"`
+
+	Policies        map[string]string `json:"policies"`
+	PolicyMappings  map[string]string `json:"policyMappings"`
+	ServiceAccounts map[string]string `json:"serviceAccounts"`
+
+	Buckets         map[string]srBucketSnapshot `json:"buckets"`
+	UsageLastUpdate time.Time                   `json:"usageLastUpdate"`
+}
+
+// SRDiffSite - a site compared by the site replication diff.
+type SRDiffSite struct {
+	Name            string    `json:"name"`
+	DeploymentID    string    `json:"deploymentID"`
+	UsageLastUpdate time.Time `json:"usageLastUpdate,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// SRDiffMismatch - an entity differing between sites, with its digest
+// or value on each site by site name.
+type SRDiffMismatch struct {
+	Category string            `json:"category"`
+	Entity   string            `json:"entity"`
+	Sites    map[string]string `json:"sites"`
+	Hint     string            `json:"hint"`
+}
+
+// SRDiffReport - the result of comparing all sites.
+type SRDiffReport struct {
+	Sites      []SRDiffSite     `json:"sites"`
+	Mismatches []SRDiffMismatch `json:"mismatches"`
+}
+
+func srDigest(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// srSecretDigest returns the digest of data holding secrets, an HMAC
+// with key so that the secrets cannot be guessed from the digest.
+func srSecretDigest(key string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// localSnapshot returns the snapshot of this site, the caller must
+// hold at least a read lock.
+func (c *SiteReplicationSys) localSnapshot(ctx context.Context) (srSiteSnapshot, error) {
+	s := srSiteSnapshot{
+		Name:            c.state.Name,
+		DeploymentID:    globalDeploymentID,
+		Policies:        make(map[string]string),
+		PolicyMappings:  make(map[string]string),
+		ServiceAccounts: make(map[string]string),
+		Buckets:         make(map[string]srBucketSnapshot),
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return s, errSRObjectLayerNotReady
+	}
+
+	policies, err := globalIAMSys.ListPolicies("")
+	if err != nil {
+		return s, errSRBackendIssue(err)
+	}
+	for name, p := range policies {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return s, wrapSRErr(err)
+		}
+		s.Policies[name] = srDigest(data)
+	}
+
+	userPolicyMap := make(map[string]MappedPolicy)
+	groupPolicyMap := make(map[string]MappedPolicy)
+	globalIAMSys.store.rlock()
+	errU := globalIAMSys.store.loadMappedPolicies(ctx, stsUser, false, userPolicyMap)
+	errG := globalIAMSys.store.loadMappedPolicies(ctx, stsUser, true, groupPolicyMap)
+	// The secrets of service accounts are only compared keyed with the
+	// secret of the site replicator service account, shared by all sites.
+	srCred, srCredFound := globalIAMSys.iamUsersMap[c.state.ServiceAccountAccessKey]
+	for accessKey, cred := range globalIAMSys.iamUsersMap {
+		if !cred.IsServiceAccount() {
+			continue
+		}
+		if srCredFound && srCred.SecretKey != "" {
+			s.ServiceAccounts[accessKey] = srSecretDigest(srCred.SecretKey,
+				[]byte(cred.ParentUser+cred.Status+cred.SecretKey+cred.SessionToken))
+		} else {
+			s.ServiceAccounts[accessKey] = srDigest([]byte(cred.ParentUser + cred.Status))
+		}
+	}
+	globalIAMSys.store.runlock()
+	if errU != nil {
+		return s, errSRBackendIssue(errU)
+	}
+	if errG != nil {
+		return s, errSRBackendIssue(errG)
+	}
+	for user, mp := range userPolicyMap {
+		s.PolicyMappings["user/"+user] = mp.Policies
+	}
+	for group, mp := range groupPolicyMap {
+		s.PolicyMappings["group/"+group] = mp.Policies
+	}
+
+	buckets, err := objAPI.ListBuckets(ctx)
+	if err != nil {
+		return s, errSRBackendIssue(err)
+	}
+	// The usage is optional, buckets are reported without it until
+	// the first scan completed.
+	usage, _ := loadDataUsageFromBackend(ctx, objAPI)
+	s.UsageLastUpdate = usage.LastUpdate
+	for _, bi := range buckets {
+		if !c.replicatesBucket(bi.Name) {
+			continue
+		}
+		meta, err := globalBucketMetadataSys.GetConfig(bi.Name)
+		if err != nil {
+			return s, errSRBackendIssue(err)
+		}
+		bs := srBucketSnapshot{
+			Policy:     srDigest(meta.PolicyConfigJSON),
+			Tags:       srDigest(meta.TaggingConfigXML),
+			ObjectLock: srDigest(meta.ObjectLockConfigXML),
+			SSE:        srDigest(meta.EncryptionConfigXML),
+			Versioning: srDigest(meta.VersioningConfigXML),
+		}
+		if bu, ok := usage.BucketsUsage[bi.Name]; ok {
+			bs.Objects, bs.Size = bu.ObjectsCount, bu.Size
+		}
+		s.Buckets[bi.Name] = bs
+	}
+	return s, nil
+}
+
+// PeerSnapshotHandler - returns the snapshot of this site compared by
+// the site replication diff of a peer.
+func (c *SiteReplicationSys) PeerSnapshotHandler(ctx context.Context) (srSiteSnapshot, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if !c.enabled {
+		return srSiteSnapshot{}, errSRNotEnabled
+	}
+	return c.localSnapshot(ctx)
+}
+
+// getPeerSnapshot fetches the snapshot of a peer, the caller must hold
+// at least a read lock.
+func (c *SiteReplicationSys) getPeerSnapshot(ctx context.Context, p madmin.PeerInfo) (srSiteSnapshot, error) {
+	var s srSiteSnapshot
+	creds, err := c.getPeerCreds()
+	if err != nil {
+		return s, err
+	}
+	err = callRemoteAdminAPI(ctx, p.Endpoint, creds.AccessKey, creds.SecretKey, globalServerRegion,
+		http.MethodGet, "/site-replication/peer/snapshot", nil, &s)
+	return s, err
+}
+
+// Diff compares the IAM entities, the metadata and the usage of the
+// replicated buckets of all sites.
+func (c *SiteReplicationSys) Diff(ctx context.Context) (SRDiffReport, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if !c.enabled {
+		return SRDiffReport{}, errSRNotEnabled
+	}
+
+	var (
+		mu    sync.Mutex
+		snaps []srSiteSnapshot
+		sites []SRDiffSite
+	)
+	addSite := func(p madmin.PeerInfo, s srSiteSnapshot, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		site := SRDiffSite{Name: p.Name, DeploymentID: p.DeploymentID}
+		if err != nil {
+			site.Error = err.Error()
+		} else {
+			site.UsageLastUpdate = s.UsageLastUpdate
+			s.Name = p.Name
+			snaps = append(snaps, s)
+		}
+		sites = append(sites, site)
+	}
+	c.concDo(func() error {
+		s, err := c.localSnapshot(ctx)
+		addSite(c.state.Peers[globalDeploymentID], s, err)
+		return err
+	}, func(deploymentID string, p madmin.PeerInfo) error {
+		s, err := c.getPeerSnapshot(ctx, p)
+		addSite(p, s, c.annotatePeerErr(p.Name, "Snapshot", err))
+		return err
+	})
+
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Name < sites[j].Name })
+	return SRDiffReport{Sites: sites, Mismatches: diffSRSnapshots(snaps)}, nil
+}
+
+// diffSRSnapshots returns the entities differing between the snapshots,
+// sorted by category and entity.
+func diffSRSnapshots(snaps []srSiteSnapshot) []SRDiffMismatch {
+	mismatches := []SRDiffMismatch{}
+	if len(snaps) < 2 {
+		return mismatches
+	}
+
+	diffMaps := func(category string, get func(s srSiteSnapshot) map[string]string) {
+		entities := make(map[string]struct{})
+		for _, s := range snaps {
+			for e := range get(s) {
+				entities[e] = struct{}{}
+			}
+		}
+		for e := range entities {
+			values := make(map[string]string, len(snaps))
+			for _, s := range snaps {
+				v, ok := get(s)[e]
+				if !ok {
+					v = srDiffMissing
+				}
+				values[s.Name] = v
+			}
+			if !srDiffSameValues(values) {
+				mismatches = append(mismatches, SRDiffMismatch{
+					Category: category,
+					Entity:   e,
+					Sites:    values,
+					Hint:     srDiffHints[category],
+				})
+			}
+		}
+	}
+
+	diffMaps(srDiffIAMPolicy, func(s srSiteSnapshot) map[string]string { return s.Policies })
+	diffMaps(srDiffIAMPolicyMapping, func(s srSiteSnapshot) map[string]string { return s.PolicyMappings })
+	diffMaps(srDiffIAMSvcAcc, func(s srSiteSnapshot) map[string]string { return s.ServiceAccounts })
+	diffMaps(srDiffBucket, func(s srSiteSnapshot) map[string]string {
+		m := make(map[string]string, len(s.Buckets))
+		for b := range s.Buckets {
+			m[b] = "present"
+		}
+		return m
+	})
+
+	// Compare the metadata and usage only of buckets present on all
+	// sites, missing buckets are reported above.
+	bucketFields := []struct {
+		category string
+		get      func(b srBucketSnapshot) string
+	}{
+		{srDiffBucketPolicy, func(b srBucketSnapshot) string { return b.Policy }},
+		{srDiffBucketTags, func(b srBucketSnapshot) string { return b.Tags }},
+		{srDiffBucketObjectLock, func(b srBucketSnapshot) string { return b.ObjectLock }},
+		{srDiffBucketSSE, func(b srBucketSnapshot) string { return b.SSE }},
+		{srDiffBucketVersioning, func(b srBucketSnapshot) string { return b.Versioning }},
+		{srDiffBucketUsage, func(b srBucketSnapshot) string {
+			return fmt.Sprintf("objects=%d size=%d", b.Objects, b.Size)
+		}},
+	}
+	for bucket := range snaps[0].Buckets {
+		present := true
+		for _, s := range snaps[1:] {
+			if _, ok := s.Buckets[bucket]; !ok {
+				present = false
+				break
+			}
+		}
+		if !present {
+			continue
+		}
+		for _, f := range bucketFields {
+			values := make(map[string]string, len(snaps))
+			for _, s := range snaps {
+				values[s.Name] = f.get(s.Buckets[bucket])
+			}
+			if !srDiffSameValues(values) {
+				mismatches = append(mismatches, SRDiffMismatch{
+					Category: f.category,
+					Entity:   bucket,
+					Sites:    values,
+					Hint:     srDiffHints[f.category],
+				})
+			}
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].Category != mismatches[j].Category {
+			return mismatches[i].Category < mismatches[j].Category
+		}
+		return mismatches[i].Entity < mismatches[j].Entity
+	})
+	return mismatches
+}
+
+func srDiffSameValues(values map[string]string) bool {
+	first, set := "", false
+	for _, v := range values {
+		if !set {
+			first, set = v, true
+		} else if v != first {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestDiffSRSnapshots(t *testing.T) {
+	site1 := srSiteSnapshot{
+		Name:            "site1",
+		Policies:        map[string]string{"readonly": "a1", "custom": "b1"},
+		PolicyMappings:  map[string]string{"user/alice": "readonly"},
+		ServiceAccounts: map[string]string{"svc": "c1"},
+		Buckets: map[string]srBucketSnapshot{
+			"photos": {Policy: "d1", Objects: 10, Size: 100},
+			"logs":   {},
+		},
+	}
+	site2 := srSiteSnapshot{
+		Name:            "site2",
+		Policies:        map[string]string{"readonly": "a1", "custom": "b2"},
+		PolicyMappings:  map[string]string{"user/alice": "readonly"},
+		ServiceAccounts: map[string]string{"svc": "c1"},
+		Buckets: map[string]srBucketSnapshot{
+			"photos": {Policy: "d1", Objects: 9, Size: 90},
+		},
+	}
+
+	if mismatches := diffSRSnapshots([]srSiteSnapshot{site1, site1}); len(mismatches) != 0 {
+		t.Fatalf("expected no mismatches of equal sites, got %v", mismatches)
+	}
+
+	mismatches := diffSRSnapshots([]srSiteSnapshot{site1, site2})
+	expected := []struct{ category, entity, site2 string }{
+		{srDiffBucket, "logs", srDiffMissing},
+		{srDiffBucketUsage, "photos", "objects=9 size=90"},
+		{srDiffIAMPolicy, "custom", "b2"},
+	}
+	if len(mismatches) != len(expected) {
+		t.Fatalf("expected %d mismatches, got %v", len(expected), mismatches)
+	}
+	for i, e := range expected {
+		m := mismatches[i]
+		if m.Category != e.category || m.Entity != e.entity || m.Sites["site2"] != e.site2 {
+			t.Errorf("Test %d: expected %s %s with %s on site2, got %v", i+1, e.category, e.entity, e.site2, m)
+		}
+		if m.Hint == "" {
+			t.Errorf("Test %d: expected a remediation hint", i+1)
+		}
+	}
+}
+
+func TestSRSecretDigest(t *testing.T) {
+	data := []byte("parent" + "on" + "secret")
+	if srSecretDigest("key", data) != srSecretDigest("key", data) {
+		t.Fatal("expected equal digests with the same key")
+	}
+	if srSecretDigest("key", data) == srSecretDigest("other", data) {
+		t.Fatal("expected digests to depend on the key")
+	}
+	if srSecretDigest("key", data) == srDigest(data) {
+		t.Fatal("expected the secret digest to differ from the plain digest")
+	}
+}
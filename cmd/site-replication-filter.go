@@ -0,0 +1,165 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/wildcard"
+)
+
+// srBucketFilterBktOp is the peer bucket operation setting the bucket
+// filter of the site replication configuration.
+const srBucketFilterBktOp madmin.BktOp = "bucket-filter"
+
+var errSRInvalidBucketPattern = errors.New("bucket patterns must not be empty or contain ',' or '/'")
+
+// SRBucketFilter - the buckets replicated between sites, the buckets
+// matching any include pattern, all buckets without one, except those
+// matching any exclude pattern. Patterns may contain the wildcards '*'
+// and '?'.
+type SRBucketFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+func (f SRBucketFilter) validate() error {
+	for _, patterns := range [][]string{f.Include, f.Exclude} {
+		for _, p := range patterns {
+			if p == "" || strings.ContainsAny(p, ","+SlashSeparator) {
+				return errSRInvalidBucketPattern
+			}
+		}
+	}
+	return nil
+}
+
+func (f SRBucketFilter) isEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// replicates returns whether bucket is replicated between sites.
+func (f SRBucketFilter) replicates(bucket string) bool {
+	if len(f.Include) > 0 {
+		included := false
+		for _, p := range f.Include {
+			if wildcard.Match(p, bucket) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, p := range f.Exclude {
+		if wildcard.Match(p, bucket) {
+			return false
+		}
+	}
+	return true
+}
+
+// toOpts encodes the filter as options of a peer bucket operation.
+func (f SRBucketFilter) toOpts() map[string]string {
+	return map[string]string{
+		"include": strings.Join(f.Include, ","),
+		"exclude": strings.Join(f.Exclude, ","),
+	}
+}
+
+// srBucketFilterFromOpts decodes the filter of a peer bucket operation.
+func srBucketFilterFromOpts(include, exclude string) SRBucketFilter {
+	var f SRBucketFilter
+	if include != "" {
+		f.Include = strings.Split(include, ",")
+	}
+	if exclude != "" {
+		f.Exclude = strings.Split(exclude, ",")
+	}
+	return f
+}
+
+// GetBucketFilter returns the bucket filter of site replication.
+func (c *SiteReplicationSys) GetBucketFilter() (SRBucketFilter, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if !c.enabled {
+		return SRBucketFilter{}, errSRNotEnabled
+	}
+	return c.state.BucketFilter, nil
+}
+
+// replicatesBucket returns whether bucket is replicated to the peers,
+// the caller must hold at least a read lock.
+func (c *SiteReplicationSys) replicatesBucket(bucket string) bool {
+	return c.state.BucketFilter.replicates(bucket)
+}
+
+// SetBucketFilter sets the bucket filter on all peers, then on this
+// site. It applies to buckets created and changed afterwards, buckets
+// replicated already keep their replication rules.
+func (c *SiteReplicationSys) SetBucketFilter(ctx context.Context, f SRBucketFilter) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+
+	c.RLock()
+	if !c.enabled {
+		c.RUnlock()
+		return errSRNotEnabled
+	}
+	cErr := c.concDo(nil, func(deploymentID string, p madmin.PeerInfo) error {
+		admClient, err := c.getAdminClient(ctx, deploymentID)
+		if err != nil {
+			return wrapSRErr(err)
+		}
+
+		err = admClient.SRInternalBucketOps(ctx, "", srBucketFilterBktOp, f.toOpts())
+		logger.LogIf(ctx, c.annotatePeerErr(p.Name, "BucketFilter", err))
+		return err
+	})
+	c.RUnlock()
+	if cErr.summaryErr != nil {
+		return cErr.summaryErr
+	}
+	return c.PeerBucketFilterHandler(ctx, f)
+}
+
+// PeerBucketFilterHandler - sets the bucket filter of this site.
+func (c *SiteReplicationSys) PeerBucketFilterHandler(ctx context.Context, f SRBucketFilter) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+
+	// The state is updated under the lock, concurrent changes of the
+	// state are not lost.
+	c.Lock()
+	defer c.Unlock()
+	if !c.enabled {
+		return errSRNotEnabled
+	}
+
+	state := c.state
+	state.BucketFilter = f
+	return c.saveToDiskLocked(ctx, state)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestSRBucketFilterReplicates(t *testing.T) {
+	testCases := []struct {
+		filter     SRBucketFilter
+		bucket     string
+		replicates bool
+	}{
+		{SRBucketFilter{}, "photos", true},
+		{SRBucketFilter{Exclude: []string{"tmp-*", "scratch"}}, "photos", true},
+		{SRBucketFilter{Exclude: []string{"tmp-*", "scratch"}}, "tmp-build", false},
+		{SRBucketFilter{Exclude: []string{"tmp-*", "scratch"}}, "scratch", false},
+		{SRBucketFilter{Include: []string{"prod-*"}}, "prod-logs", true},
+		{SRBucketFilter{Include: []string{"prod-*"}}, "dev-logs", false},
+		{SRBucketFilter{Include: []string{"prod-*"}, Exclude: []string{"prod-tmp?"}}, "prod-tmp1", false},
+	}
+	for i, testCase := range testCases {
+		if replicates := testCase.filter.replicates(testCase.bucket); replicates != testCase.replicates {
+			t.Errorf("Test %d: expected %t for %s, got %t", i+1, testCase.replicates, testCase.bucket, replicates)
+		}
+	}
+}
+
+func TestSRBucketFilterOpts(t *testing.T) {
+	f := SRBucketFilter{Include: []string{"prod-*", "shared"}, Exclude: []string{"prod-tmp*"}}
+	if err := f.validate(); err != nil {
+		t.Fatal(err)
+	}
+	opts := f.toOpts()
+	if got := srBucketFilterFromOpts(opts["include"], opts["exclude"]); len(got.Include) != 2 || len(got.Exclude) != 1 {
+		t.Fatalf("Unexpected filter %v", got)
+	}
+	if got := srBucketFilterFromOpts("", ""); got.Include != nil || got.Exclude != nil {
+		t.Fatalf("Expected an empty filter, got %v", got)
+	}
+
+	for _, p := range []string{"", "a,b", "a/b"} {
+		if err := (SRBucketFilter{Exclude: []string{p}}).validate(); err == nil {
+			t.Errorf("Expected pattern %q to be rejected", p)
+		}
+	}
+}
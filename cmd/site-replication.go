@@ -160,6 +160,9 @@ type srStateV1 struct {
 	// Peers maps peers by their deploymentID
 	Peers                   map[string]madmin.PeerInfo `json:"peers"`
 	ServiceAccountAccessKey string                     `json:"serviceAccountAccessKey"`
+
+	// BucketFilter selects the buckets replicated, all when empty.
+	BucketFilter SRBucketFilter `json:"bucketFilter"`
 }
 
 // srStateData represents the format of the current `srStateFile`.
@@ -218,6 +221,14 @@ func (c *SiteReplicationSys) loadFromDisk(ctx context.Context, objAPI ObjectLaye
 }
 
 func (c *SiteReplicationSys) saveToDisk(ctx context.Context, state srState) error {
+	c.Lock()
+	defer c.Unlock()
+	return c.saveToDiskLocked(ctx, state)
+}
+
+// saveToDiskLocked saves state and makes it the current state, the
+// caller must hold the lock.
+func (c *SiteReplicationSys) saveToDiskLocked(ctx context.Context, state srState) error {
 	sdata := srStateData{
 		Version: srStateFormatVersion1,
 		SRState: srStateV1(state),
@@ -240,8 +251,6 @@ func (c *SiteReplicationSys) saveToDisk(ctx context.Context, state srState) erro
 		logger.LogIf(ctx, e)
 	}
 
-	c.Lock()
-	defer c.Unlock()
 	c.state = state
 	c.enabled = true
 	return nil
@@ -253,12 +262,16 @@ const (
 	siteReplicatorSvcAcc = "site-replicator-0"
 )
 
-// AddPeerClusters - add cluster sites for replication configuration.
-func (c *SiteReplicationSys) AddPeerClusters(ctx context.Context, sites []madmin.PeerSite) (madmin.ReplicateAddStatus, SRError) {
+// AddPeerClusters - add cluster sites for replication configuration,
+// replicating the buckets selected by filter.
+func (c *SiteReplicationSys) AddPeerClusters(ctx context.Context, sites []madmin.PeerSite, filter SRBucketFilter) (madmin.ReplicateAddStatus, SRError) {
 	// If current cluster is already SR enabled, we fail.
 	if c.enabled {
 		return madmin.ReplicateAddStatus{}, errSRInvalidRequest(errSRCannotJoin)
 	}
+	if err := filter.validate(); err != nil {
+		return madmin.ReplicateAddStatus{}, errSRInvalidRequest(err)
+	}
 
 	// Only one of the clusters being added, can have any buckets (i.e. self
 	// here) - others must be empty.
@@ -420,6 +433,15 @@ func (c *SiteReplicationSys) AddPeerClusters(ctx context.Context, sites []madmin
 			break
 		}
 		addedCount++
+
+		// The join request does not carry the bucket filter, it is
+		// set on the peer once joined, before any bucket is synced.
+		if !filter.isEmpty() {
+			if err = admClient.SRInternalBucketOps(ctx, "", srBucketFilterBktOp, filter.toOpts()); err != nil {
+				peerAddErr = errSRPeerResp(fmt.Errorf("unable to set the bucket filter on peer %s: %w", v.Name, err))
+				break
+			}
+		}
 	}
 
 	if peerAddErr.Cause != nil {
@@ -443,6 +465,7 @@ func (c *SiteReplicationSys) AddPeerClusters(ctx context.Context, sites []madmin
 		Name:                    sites[selfIdx].Name,
 		Peers:                   joinReq.Peers,
 		ServiceAccountAccessKey: svcCred.AccessKey,
+		BucketFilter:            filter,
 	}
 	err = c.saveToDisk(ctx, state)
 	if err != nil {
@@ -588,7 +611,7 @@ func (c *SiteReplicationSys) MakeBucketHook(ctx context.Context, bucket string,
 
 	c.RLock()
 	defer c.RUnlock()
-	if !c.enabled {
+	if !c.enabled || !c.replicatesBucket(bucket) {
 		return nil
 	}
 
@@ -662,7 +685,7 @@ func (c *SiteReplicationSys) DeleteBucketHook(ctx context.Context, bucket string
 
 	c.RLock()
 	defer c.RUnlock()
-	if !c.enabled {
+	if !c.enabled || !c.replicatesBucket(bucket) {
 		return nil
 	}
 
@@ -1150,7 +1173,7 @@ func (c *SiteReplicationSys) BucketMetaHook(ctx context.Context, item madmin.SRB
 
 	c.RLock()
 	defer c.RUnlock()
-	if !c.enabled {
+	if !c.enabled || !c.replicatesBucket(item.Bucket) {
 		return nil
 	}
 
@@ -0,0 +1,489 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/config/slo"
+	"github.com/minio/minio/internal/logger"
+)
+
+// sloAPIClasses are the S3 calls of the API classes of objectives.
+var sloAPIClasses = map[string][]string{
+	slo.ClassRead: {"getobject", "headobject", "selectobjectcontent"},
+	slo.ClassWrite: {
+		"putobject", "putobjectpart", "copyobject", "copyobjectpart", "newmultipartupload",
+		"completemultipartupload", "appendobject", "composeobject", "postpolicybucket",
+		"deleteobject", "deletemultipleobjects",
+	},
+	slo.ClassList: {
+		"listbuckets", "listobjectsv1", "listobjectsv2", "listobjectversions",
+		"listmultipartuploads", "listobjectparts",
+	},
+}
+
+// Windows of the burn rates of an objective, the last one is the
+// window of the objective itself.
+var sloBurnWindows = []struct {
+	name string
+	d    time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+const sloPeriodWindow = "period"
+
+// SLOCounts are the calls of an objective over a window.
+type SLOCounts struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+	Slow     uint64 `json:"slow"`
+}
+
+func (c *SLOCounts) add(o SLOCounts) {
+	c.Requests += o.Requests
+	c.Errors += o.Errors
+	c.Slow += o.Slow
+}
+
+// sloSlot counts the calls of one minute or hour, the counters are
+// updated atomically.
+type sloSlot struct {
+	start    int64 // unix minute or hour counted
+	requests uint64
+	errors   uint64
+	slow     uint64
+}
+
+func (s *sloSlot) add(c SLOCounts) {
+	atomic.AddUint64(&s.requests, c.Requests)
+	atomic.AddUint64(&s.errors, c.Errors)
+	atomic.AddUint64(&s.slow, c.Slow)
+}
+
+func (s *sloSlot) load() SLOCounts {
+	return SLOCounts{
+		Requests: atomic.LoadUint64(&s.requests),
+		Errors:   atomic.LoadUint64(&s.errors),
+		Slow:     atomic.LoadUint64(&s.slow),
+	}
+}
+
+// sloObjective keeps the calls of an objective per minute for the last
+// hour and per hour for its window. Both rings hold one slot more than
+// the windows they count, the oldest slot of a window is complete while
+// the newest one is still being counted.
+type sloObjective struct {
+	// Counted atomically, keep them first for the alignment of the
+	// 64 bit counters.
+	minutes [61]sloSlot
+	total   sloSlot // since the objective is tracked
+	hours   []sloSlot
+
+	mu sync.Mutex // serializes moving slots to a later minute or hour
+	slo.Objective
+	apis map[string]bool // nil for all calls
+}
+
+func newSLOObjective(o slo.Objective) *sloObjective {
+	window := o.Window
+	if burn := sloBurnWindows[len(sloBurnWindows)-1].d; window < burn {
+		window = burn
+	}
+	so := &sloObjective{
+		Objective: o,
+		hours:     make([]sloSlot, int(window/time.Hour)+1),
+	}
+	for _, api := range o.APIs {
+		if api == slo.ClassAll {
+			so.apis = nil
+			break
+		}
+		if so.apis == nil {
+			so.apis = make(map[string]bool)
+		}
+		if class, ok := sloAPIClasses[api]; ok {
+			for _, name := range class {
+				so.apis[name] = true
+			}
+		} else {
+			so.apis[api] = true
+		}
+	}
+	return so
+}
+
+func (o *sloObjective) covers(bucket, api string) bool {
+	if o.Bucket != "" && o.Bucket != bucket {
+		return false
+	}
+	if o.apis == nil {
+		return api != "notfound" && api != "methodnotallowed"
+	}
+	return o.apis[api]
+}
+
+// slot returns the slot of slots counting start, the slot is reset when
+// it counted an earlier minute or hour. Nil is returned when the slot
+// counts a later one already.
+func (o *sloObjective) slot(slots []sloSlot, start int64) *sloSlot {
+	s := &slots[start%int64(len(slots))]
+	if cur := atomic.LoadInt64(&s.start); cur == start {
+		return s
+	} else if cur > start {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch cur := atomic.LoadInt64(&s.start); {
+	case cur > start:
+		return nil
+	case cur < start:
+		// Reset the counters before the slot is seen counting start.
+		atomic.StoreUint64(&s.requests, 0)
+		atomic.StoreUint64(&s.errors, 0)
+		atomic.StoreUint64(&s.slow, 0)
+		atomic.StoreInt64(&s.start, start)
+	}
+	return s
+}
+
+func (o *sloObjective) add(now time.Time, errored, slow bool) {
+	c := SLOCounts{Requests: 1, Errors: boolToUint64(errored), Slow: boolToUint64(slow)}
+	if s := o.slot(o.minutes[:], now.Unix()/60); s != nil {
+		s.add(c)
+	}
+	if s := o.slot(o.hours, now.Unix()/3600); s != nil {
+		s.add(c)
+	}
+	o.total.add(c)
+}
+
+// counts returns the calls of the last d, counted per minute up to an
+// hour and per hour beyond.
+func (o *sloObjective) counts(now time.Time, d time.Duration) (c SLOCounts) {
+	slots, start := o.hours, now.Unix()/3600-int64(d/time.Hour)
+	if d <= time.Hour {
+		slots, start = o.minutes[:], now.Unix()/60-int64(d/time.Minute)
+	}
+	for i := range slots {
+		if atomic.LoadInt64(&slots[i].start) >= start {
+			c.add(slots[i].load())
+		}
+	}
+	return c
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SLONodeCounts are the calls of an objective on a node over the burn
+// rate windows, by window name.
+type SLONodeCounts struct {
+	Name    string               `json:"name"`
+	Windows map[string]SLOCounts `json:"windows"`
+	Total   SLOCounts            `json:"total"`
+}
+
+// sloTracker tracks the configured objectives on the S3 calls served
+// by this node.
+type sloTracker struct {
+	mu         sync.Mutex   // serializes updates
+	objectives atomic.Value // []*sloObjective
+}
+
+var globalSLOTracker = &sloTracker{}
+
+func (t *sloTracker) tracked() []*sloObjective {
+	objectives, _ := t.objectives.Load().([]*sloObjective)
+	return objectives
+}
+
+// update replaces the objectives by the ones of cfg, unchanged ones
+// keep their counts.
+func (t *sloTracker) update(cfg slo.Config, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev := make(map[string]*sloObjective)
+	for _, o := range t.tracked() {
+		prev[o.Name] = o
+	}
+	objectives := make([]*sloObjective, 0, len(cfg.Objectives))
+	for _, o := range cfg.Objectives {
+		if p, ok := prev[o.Name]; ok && reflect.DeepEqual(p.Objective, o) {
+			objectives = append(objectives, p)
+			continue
+		}
+		objectives = append(objectives, newSLOObjective(o))
+	}
+	t.objectives.Store(objectives)
+}
+
+// record accounts a completed S3 call to the objectives covering it,
+// server errors spend the availability budget and calls slower than
+// the latency threshold the latency budget.
+func (t *sloTracker) record(r *http.Request, api string, w *logger.ResponseWriter) {
+	objectives := t.tracked()
+	if len(objectives) == 0 {
+		return
+	}
+	bucket := mux.Vars(r)["bucket"]
+	now := time.Now()
+	d := now.Sub(w.StartTime)
+	errored := w.StatusCode >= http.StatusInternalServerError
+	for _, o := range objectives {
+		if o.covers(bucket, api) {
+			o.add(now, errored, o.Latency > 0 && d > o.Latency)
+		}
+	}
+}
+
+// nodeCounts returns the calls of all objectives on this node.
+func (t *sloTracker) nodeCounts(now time.Time) []SLONodeCounts {
+	objectives := t.tracked()
+	counts := make([]SLONodeCounts, 0, len(objectives))
+	for _, o := range objectives {
+		c := SLONodeCounts{
+			Name:    o.Name,
+			Windows: make(map[string]SLOCounts, len(sloBurnWindows)+1),
+			Total:   o.total.load(),
+		}
+		for _, w := range sloBurnWindows {
+			c.Windows[w.name] = o.counts(now, w.d)
+		}
+		c.Windows[sloPeriodWindow] = o.counts(now, o.Window)
+		counts = append(counts, c)
+	}
+	return counts
+}
+
+// objectiveConfigs returns the tracked objectives.
+func (t *sloTracker) objectiveConfigs() []slo.Objective {
+	objectives := t.tracked()
+	configs := make([]slo.Objective, 0, len(objectives))
+	for _, o := range objectives {
+		configs = append(configs, o.Objective)
+	}
+	return configs
+}
+
+// sloSlotState - a saved slot of an objective.
+type sloSlotState struct {
+	Start int64 `json:"start"`
+	SLOCounts
+}
+
+// sloObjectiveState - the saved counts of an objective.
+type sloObjectiveState struct {
+	Objective slo.Objective  `json:"objective"`
+	Minutes   []sloSlotState `json:"minutes"`
+	Hours     []sloSlotState `json:"hours"`
+	Total     SLOCounts      `json:"total"`
+}
+
+// sloStatePath is the backend path of the counts of node.
+func sloStatePath(node string) string {
+	return pathJoin(minioConfigPrefix, "slo", strings.NewReplacer(":", "_", "/", "_").Replace(node)+".json")
+}
+
+func sloSlotStates(slots []sloSlot) []sloSlotState {
+	states := make([]sloSlotState, 0, len(slots))
+	for i := range slots {
+		if start := atomic.LoadInt64(&slots[i].start); start > 0 {
+			states = append(states, sloSlotState{Start: start, SLOCounts: slots[i].load()})
+		}
+	}
+	return states
+}
+
+// save writes the counts of the objectives to the backend, so that a
+// restart of this node does not reset the budgets of its calls.
+func (t *sloTracker) save(ctx context.Context, objAPI ObjectLayer) error {
+	objectives := t.tracked()
+	if len(objectives) == 0 {
+		return nil
+	}
+	states := make([]sloObjectiveState, 0, len(objectives))
+	for _, o := range objectives {
+		states = append(states, sloObjectiveState{
+			Objective: o.Objective,
+			Minutes:   sloSlotStates(o.minutes[:]),
+			Hours:     sloSlotStates(o.hours),
+			Total:     o.total.load(),
+		})
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, sloStatePath(globalLocalNodeName), data)
+}
+
+// load adds the counts saved by this node before a restart to the
+// objectives configured alike still.
+func (t *sloTracker) load(ctx context.Context, objAPI ObjectLayer) error {
+	data, err := readConfig(ctx, objAPI, sloStatePath(globalLocalNodeName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil
+		}
+		return err
+	}
+	var states []sloObjectiveState
+	if err = json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	saved := make(map[string]sloObjectiveState, len(states))
+	for _, state := range states {
+		saved[state.Objective.Name] = state
+	}
+	for _, o := range t.tracked() {
+		state, ok := saved[o.Name]
+		if !ok || !reflect.DeepEqual(state.Objective, o.Objective) {
+			continue
+		}
+		for _, s := range state.Minutes {
+			if slot := o.slot(o.minutes[:], s.Start); slot != nil {
+				slot.add(s.SLOCounts)
+			}
+		}
+		for _, s := range state.Hours {
+			if slot := o.slot(o.hours, s.Start); slot != nil {
+				slot.add(s.SLOCounts)
+			}
+		}
+		o.total.add(state.Total)
+	}
+	return nil
+}
+
+// initSLOTracker loads the counts saved by this node and saves them
+// every minute.
+func initSLOTracker(ctx context.Context, objAPI ObjectLayer) {
+	logger.LogIf(ctx, globalSLOTracker.load(ctx, objAPI))
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				logger.LogIf(ctx, globalSLOTracker.save(ctx, objAPI))
+			}
+		}
+	}()
+}
+
+// SLOWindowStatus is the outcome of the calls of an objective over a
+// window, a burn rate of 1 spends the budget exactly over the window
+// of the objective.
+type SLOWindowStatus struct {
+	Window               string  `json:"window"`
+	Requests             uint64  `json:"requests"`
+	Errors               uint64  `json:"errors"`
+	Slow                 uint64  `json:"slow"`
+	AvailabilityBurnRate float64 `json:"availabilityBurnRate"`
+	LatencyBurnRate      float64 `json:"latencyBurnRate"`
+}
+
+// SLOStatus is the state of an objective over the cluster, the
+// remaining budgets are fractions of the budget of the window, below
+// 0 once overspent.
+type SLOStatus struct {
+	Objective                   slo.Objective     `json:"objective"`
+	AvailabilityBudgetRemaining float64           `json:"availabilityBudgetRemaining"`
+	LatencyBudgetRemaining      float64           `json:"latencyBudgetRemaining"`
+	Windows                     []SLOWindowStatus `json:"windows"`
+}
+
+// SLOReport is the state of all objectives over the cluster.
+type SLOReport struct {
+	Time       time.Time   `json:"time"`
+	Nodes      int         `json:"nodes"`
+	Errors     []string    `json:"errors,omitempty"`
+	Objectives []SLOStatus `json:"objectives"`
+}
+
+// sloBurnRate returns how fast bad calls spend the budget of objective.
+func sloBurnRate(bad, requests uint64, objective float64) float64 {
+	if requests == 0 || objective == 0 {
+		return 0
+	}
+	return (float64(bad) / float64(requests)) / (1 - objective)
+}
+
+// sloStatus computes the status of o from the counts of all nodes.
+func sloStatus(o slo.Objective, nodes [][]SLONodeCounts) SLOStatus {
+	windows := make(map[string]SLOCounts)
+	for _, counts := range nodes {
+		for _, c := range counts {
+			if c.Name != o.Name {
+				continue
+			}
+			for name, wc := range c.Windows {
+				sum := windows[name]
+				sum.add(wc)
+				windows[name] = sum
+			}
+		}
+	}
+	status := SLOStatus{Objective: o}
+	names := make([]string, 0, len(sloBurnWindows)+1)
+	for _, w := range sloBurnWindows {
+		names = append(names, w.name)
+	}
+	names = append(names, sloPeriodWindow)
+	for _, name := range names {
+		c := windows[name]
+		status.Windows = append(status.Windows, SLOWindowStatus{
+			Window:               name,
+			Requests:             c.Requests,
+			Errors:               c.Errors,
+			Slow:                 c.Slow,
+			AvailabilityBurnRate: sloBurnRate(c.Errors, c.Requests, o.Availability),
+			LatencyBurnRate:      sloBurnRate(c.Slow, c.Requests, o.LatencyObjective),
+		})
+	}
+	period := status.Windows[len(status.Windows)-1]
+	if o.Availability > 0 {
+		status.AvailabilityBudgetRemaining = 1 - period.AvailabilityBurnRate
+	}
+	if o.Latency > 0 {
+		status.LatencyBudgetRemaining = 1 - period.LatencyBurnRate
+	}
+	return status
+}
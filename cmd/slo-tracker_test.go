@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config/slo"
+)
+
+func TestSLOObjectiveCovers(t *testing.T) {
+	o := newSLOObjective(slo.Objective{Name: "reads", Bucket: "photos", APIs: []string{slo.ClassRead, "listobjectsv2"}, Window: time.Hour})
+	for api, covered := range map[string]bool{"getobject": true, "headobject": true, "listobjectsv2": true, "putobject": false} {
+		if o.covers("photos", api) != covered {
+			t.Errorf("expected %s to be covered %v", api, covered)
+		}
+	}
+	if o.covers("videos", "getobject") {
+		t.Error("expected other buckets not to be covered")
+	}
+
+	all := newSLOObjective(slo.Objective{Name: "all", APIs: []string{slo.ClassAll}, Window: time.Hour})
+	if !all.covers("videos", "putobject") || all.covers("", "notfound") {
+		t.Error("expected all calls but unknown ones to be covered")
+	}
+}
+
+func TestSLOObjectiveCounts(t *testing.T) {
+	o := newSLOObjective(slo.Objective{Name: "all", APIs: []string{slo.ClassAll}, Availability: 0.99, Window: 3 * time.Hour})
+
+	now := time.Unix(1634169600, 0)
+	// 2 hours ago, outside the last hour.
+	for i := 0; i < 100; i++ {
+		o.add(now.Add(-2*time.Hour), i < 10, false)
+	}
+	// 30 minutes ago and now.
+	for i := 0; i < 100; i++ {
+		o.add(now.Add(-30*time.Minute), i < 1, false)
+		o.add(now, false, i < 5)
+	}
+	// The first minute of the last 5 minutes, the current minute being
+	// counted still.
+	o.add(now.Add(-5*time.Minute), false, false)
+	o.add(now.Add(-6*time.Minute), false, false)
+
+	if c := o.counts(now, 5*time.Minute); c.Requests != 101 || c.Errors != 0 || c.Slow != 5 {
+		t.Errorf("unexpected 5m counts %+v", c)
+	}
+	if c := o.counts(now, time.Hour); c.Requests != 202 || c.Errors != 1 {
+		t.Errorf("unexpected 1h counts %+v", c)
+	}
+	if c := o.counts(now, o.Window); c.Requests != 302 || c.Errors != 11 {
+		t.Errorf("unexpected window counts %+v", c)
+	}
+	// All hours of the window are kept with the one being counted.
+	if c := o.counts(now.Add(time.Hour), o.Window); c.Requests != 302 {
+		t.Errorf("expected the hours of the window to be counted, got %+v", c)
+	}
+	// Slots wrapped around are not counted again.
+	if c := o.counts(now.Add(4*time.Hour), o.Window); c.Requests != 0 {
+		t.Errorf("expected expired slots to be left out, got %+v", c)
+	}
+	// Calls older than a slot counting a later minute are dropped.
+	o.add(now.Add(-61*time.Minute), false, false)
+	if c := o.counts(now, time.Hour); c.Requests != 202 {
+		t.Errorf("expected a late call not to reset its slot, got %+v", c)
+	}
+}
+
+func TestSLOTrackerConcurrent(t *testing.T) {
+	o := newSLOObjective(slo.Objective{Name: "all", APIs: []string{slo.ClassAll}, Window: time.Hour})
+	now := time.Unix(1634169600, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				o.add(now.Add(time.Duration(j)*time.Millisecond*100), j%10 == 0, false)
+			}
+		}()
+	}
+	wg.Wait()
+	if c := o.counts(now.Add(2*time.Minute), time.Hour); c.Requests != 8000 || c.Errors != 800 {
+		t.Errorf("unexpected counts %+v", c)
+	}
+	if c := o.total.load(); c.Requests != 8000 {
+		t.Errorf("unexpected total %+v", c)
+	}
+}
+
+func TestSLOStatus(t *testing.T) {
+	o := slo.Objective{Name: "reads", Availability: 0.99, Latency: time.Second, LatencyObjective: 0.9, Window: 720 * time.Hour}
+	node := func(requests, errors, slow uint64) []SLONodeCounts {
+		c := SLOCounts{Requests: requests, Errors: errors, Slow: slow}
+		return []SLONodeCounts{{Name: "reads", Windows: map[string]SLOCounts{"1h": c, sloPeriodWindow: c}}}
+	}
+	status := sloStatus(o, [][]SLONodeCounts{node(500, 5, 10), node(500, 15, 40)})
+
+	period := status.Windows[len(status.Windows)-1]
+	if period.Window != sloPeriodWindow || period.Requests != 1000 || period.Errors != 20 {
+		t.Fatalf("unexpected period status %+v", period)
+	}
+	// 2% errors against a 1% budget burns it twice as fast.
+	if math.Abs(period.AvailabilityBurnRate-2) > 1e-9 || math.Abs(status.AvailabilityBudgetRemaining+1) > 1e-9 {
+		t.Errorf("unexpected availability burn rate %v and budget %v", period.AvailabilityBurnRate, status.AvailabilityBudgetRemaining)
+	}
+	// 5% slow calls against a 10% budget.
+	if math.Abs(period.LatencyBurnRate-0.5) > 1e-9 || math.Abs(status.LatencyBudgetRemaining-0.5) > 1e-9 {
+		t.Errorf("unexpected latency burn rate %v and budget %v", period.LatencyBurnRate, status.LatencyBudgetRemaining)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio/internal/handlers"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+// requestTimings accumulates where the time of an S3 request goes, for
+// the slow request log, in nanoseconds updated atomically.
+type requestTimings struct {
+	auth        int64
+	storageRead int64
+	accessKey   atomic.Value // string
+}
+
+type requestTimingsKey struct{}
+
+func withRequestTimings(ctx context.Context) (context.Context, *requestTimings) {
+	t := &requestTimings{}
+	return context.WithValue(ctx, requestTimingsKey{}, t), t
+}
+
+// requestTimingsFromContext returns the timings of the request of ctx,
+// nil when the slow request log is disabled.
+func requestTimingsFromContext(ctx context.Context) *requestTimings {
+	t, _ := ctx.Value(requestTimingsKey{}).(*requestTimings)
+	return t
+}
+
+// authDone accounts an authentication and authorization check started
+// at start, which authenticated accessKey.
+func (t *requestTimings) authDone(start time.Time, accessKey string) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.auth, int64(time.Since(start)))
+	if accessKey != "" {
+		t.accessKey.Store(accessKey)
+	}
+}
+
+// storageReadDone accounts an object layer read started at start.
+func (t *requestTimings) storageReadDone(start time.Time) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.storageRead, int64(time.Since(start)))
+}
+
+// timedStorageReader accounts the reads of the object data of a request.
+type timedStorageReader struct {
+	io.Reader
+	t *requestTimings
+}
+
+func (r *timedStorageReader) Read(p []byte) (int, error) {
+	defer r.t.storageReadDone(time.Now())
+	return r.Reader.Read(p)
+}
+
+// SlowRequest is an S3 request logged for exceeding the slow request
+// threshold, with a breakdown of its duration. Auth covers signature
+// and policy checks, StorageRead object metadata and data reads from
+// the object layer and NetworkWrite writing the response to the client.
+type SlowRequest struct {
+	Time            time.Time     `json:"time"`
+	Node            string        `json:"node"`
+	API             string        `json:"api"`
+	Bucket          string        `json:"bucket,omitempty"`
+	Object          string        `json:"object,omitempty"`
+	RequestID       string        `json:"requestID"`
+	CorrelationID   string        `json:"correlationID,omitempty"`
+	AccessKey       string        `json:"accessKey,omitempty"`
+	RemoteHost      string        `json:"remoteHost"`
+	StatusCode      int           `json:"statusCode"`
+	InputBytes      int64         `json:"rx"`
+	OutputBytes     int64         `json:"tx"`
+	Duration        time.Duration `json:"duration"`
+	TimeToFirstByte time.Duration `json:"timeToFirstByte,omitempty"`
+	Auth            time.Duration `json:"auth"`
+	StorageRead     time.Duration `json:"storageRead"`
+	NetworkWrite    time.Duration `json:"networkWrite"`
+}
+
+// slowRequestLog keeps the latest slow requests of this node in a ring
+// buffer, saved to the backend every minute to be queried after a
+// restart.
+type slowRequestLog struct {
+	mu      sync.Mutex
+	entries []SlowRequest // ordered by time once full, from next
+	next    int
+	max     int
+	dirty   bool
+}
+
+var globalSlowRequests = &slowRequestLog{max: 1000}
+
+// resize changes the number of entries kept, the newest ones are kept.
+func (l *slowRequestLog) resize(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if max == l.max || max <= 0 {
+		return
+	}
+	entries := l.sortedLocked()
+	if len(entries) > max {
+		entries = entries[len(entries)-max:]
+	}
+	l.entries, l.next, l.max = entries, 0, max
+}
+
+func (l *slowRequestLog) add(e SlowRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < l.max {
+		l.entries = append(l.entries, e)
+	} else {
+		l.entries[l.next] = e
+		l.next = (l.next + 1) % l.max
+	}
+	l.dirty = true
+}
+
+// sortedLocked returns the entries from the oldest, the caller must
+// hold the lock.
+func (l *slowRequestLog) sortedLocked() []SlowRequest {
+	entries := make([]SlowRequest, 0, len(l.entries))
+	entries = append(entries, l.entries[l.next:]...)
+	return append(entries, l.entries[:l.next]...)
+}
+
+// record logs a completed S3 request exceeding the slow request threshold.
+func (l *slowRequestLog) record(r *http.Request, api string, w *logger.ResponseWriter, t *requestTimings) {
+	if t == nil {
+		return
+	}
+	threshold := globalAPIConfig.getSlowRequestsThreshold()
+	d := time.Since(w.StartTime)
+	if threshold <= 0 || d < threshold {
+		return
+	}
+	vars := mux.Vars(r)
+	e := SlowRequest{
+		Time:            w.StartTime,
+		Node:            globalLocalNodeName,
+		API:             api,
+		Bucket:          vars["bucket"],
+		Object:          likelyUnescapeGeneric(vars["object"], url.PathUnescape),
+		RequestID:       w.Header().Get(xhttp.AmzRequestID),
+		CorrelationID:   correlationIDFromContext(r.Context()),
+		RemoteHost:      handlers.GetSourceIP(r),
+		StatusCode:      w.StatusCode,
+		InputBytes:      r.ContentLength,
+		OutputBytes:     int64(w.Size()),
+		Duration:        d,
+		TimeToFirstByte: w.TimeToFirstByte,
+		Auth:            time.Duration(atomic.LoadInt64(&t.auth)),
+		StorageRead:     time.Duration(atomic.LoadInt64(&t.storageRead)),
+		NetworkWrite:    w.WriteTime(),
+	}
+	e.AccessKey, _ = t.accessKey.Load().(string)
+	l.add(e)
+}
+
+// slowRequestFilter selects the slow requests of a query, the same for
+// admin and peer requests.
+type slowRequestFilter struct {
+	Bucket string
+	API    string
+	Since  time.Time
+	Until  time.Time
+	Count  int
+}
+
+func parseSlowRequestFilter(form url.Values) (f slowRequestFilter, err error) {
+	f.Bucket = form.Get("bucket")
+	f.API = strings.ToLower(form.Get("api"))
+	f.Count = 100
+	if v := form.Get("count"); v != "" {
+		if f.Count, err = strconv.Atoi(v); err != nil {
+			return f, err
+		}
+		if f.Count <= 0 {
+			return f, errors.New("count must be positive")
+		}
+	}
+	if v := form.Get("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, err
+		}
+	}
+	if v := form.Get("until"); v != "" {
+		if f.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+func (f slowRequestFilter) values() url.Values {
+	values := make(url.Values)
+	if f.Bucket != "" {
+		values.Set("bucket", f.Bucket)
+	}
+	if f.API != "" {
+		values.Set("api", f.API)
+	}
+	values.Set("count", strconv.Itoa(f.Count))
+	if !f.Since.IsZero() {
+		values.Set("since", f.Since.Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		values.Set("until", f.Until.Format(time.RFC3339))
+	}
+	return values
+}
+
+func (f slowRequestFilter) match(e SlowRequest) bool {
+	return (f.Bucket == "" || e.Bucket == f.Bucket) &&
+		(f.API == "" || e.API == f.API) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// sortSlowRequests sorts slow requests from the newest.
+func sortSlowRequests(entries []SlowRequest) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+}
+
+// query returns the newest slow requests of this node matching f.
+func (l *slowRequestLog) query(f slowRequestFilter) []SlowRequest {
+	l.mu.Lock()
+	entries := l.sortedLocked()
+	l.mu.Unlock()
+
+	matched := make([]SlowRequest, 0, f.Count)
+	for i := len(entries) - 1; i >= 0 && len(matched) < f.Count; i-- {
+		if f.match(entries[i]) {
+			matched = append(matched, entries[i])
+		}
+	}
+	return matched
+}
+
+// slowRequestLogPath is the backend path of the slow requests of node.
+func slowRequestLogPath(node string) string {
+	return pathJoin(minioConfigPrefix, "slow-requests", strings.NewReplacer(":", "_", "/", "_").Replace(node)+".json")
+}
+
+// save writes the log to the backend if it changed since the last save.
+func (l *slowRequestLog) save(ctx context.Context, objAPI ObjectLayer) error {
+	l.mu.Lock()
+	if !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	entries := l.sortedLocked()
+	l.dirty = false
+	l.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, slowRequestLogPath(globalLocalNodeName), data)
+}
+
+// load reads the log saved by this node before a restart.
+func (l *slowRequestLog) load(ctx context.Context, objAPI ObjectLayer) error {
+	data, err := readConfig(ctx, objAPI, slowRequestLogPath(globalLocalNodeName))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return nil
+		}
+		return err
+	}
+	var saved []SlowRequest
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := append(saved, l.sortedLocked()...)
+	if len(entries) > l.max {
+		entries = entries[len(entries)-l.max:]
+	}
+	l.entries, l.next = entries, 0
+	return nil
+}
+
+// initSlowRequestLog loads the slow requests saved by this node and
+// saves them every minute.
+func initSlowRequestLog(ctx context.Context, objAPI ObjectLayer) {
+	logger.LogIf(ctx, globalSlowRequests.load(ctx, objAPI))
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				logger.LogIf(ctx, globalSlowRequests.save(ctx, objAPI))
+			}
+		}
+	}()
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSlowRequestLogRing(t *testing.T) {
+	l := &slowRequestLog{max: 3}
+	start := time.Unix(1634169600, 0)
+	for i := 0; i < 5; i++ {
+		l.add(SlowRequest{Time: start.Add(time.Duration(i) * time.Second), Bucket: "photos", API: "getobject"})
+	}
+
+	reqs := l.query(slowRequestFilter{Count: 10})
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(reqs))
+	}
+	for i, r := range reqs {
+		if want := start.Add(time.Duration(4-i) * time.Second); !r.Time.Equal(want) {
+			t.Errorf("request %d: expected %v, got %v", i, want, r.Time)
+		}
+	}
+
+	l.resize(2)
+	if reqs = l.query(slowRequestFilter{Count: 10}); len(reqs) != 2 || !reqs[1].Time.Equal(start.Add(3*time.Second)) {
+		t.Errorf("expected the newest 2 requests to be kept, got %+v", reqs)
+	}
+	l.resize(4)
+	l.add(SlowRequest{Time: start.Add(5 * time.Second)})
+	if reqs = l.query(slowRequestFilter{Count: 10}); len(reqs) != 3 || !reqs[0].Time.Equal(start.Add(5*time.Second)) {
+		t.Errorf("expected 3 requests after growing, got %+v", reqs)
+	}
+}
+
+func TestSlowRequestFilter(t *testing.T) {
+	start := time.Unix(1634169600, 0).UTC()
+	f := slowRequestFilter{Bucket: "photos", API: "getobject", Since: start, Until: start.Add(time.Minute), Count: 5}
+
+	parsed, err := parseSlowRequestFilter(f.values())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != f {
+		t.Errorf("expected %+v, got %+v", f, parsed)
+	}
+
+	testCases := []struct {
+		req   SlowRequest
+		match bool
+	}{
+		{SlowRequest{Time: start, Bucket: "photos", API: "getobject"}, true},
+		{SlowRequest{Time: start.Add(time.Minute), Bucket: "photos", API: "getobject"}, false},
+		{SlowRequest{Time: start.Add(-time.Second), Bucket: "photos", API: "getobject"}, false},
+		{SlowRequest{Time: start, Bucket: "videos", API: "getobject"}, false},
+		{SlowRequest{Time: start, Bucket: "photos", API: "putobject"}, false},
+	}
+	for i, tc := range testCases {
+		if f.match(tc.req) != tc.match {
+			t.Errorf("case %d: expected match %v", i+1, tc.match)
+		}
+	}
+
+	for _, v := range []string{"count=0", "count=x", "since=yesterday"} {
+		form, _ := url.ParseQuery(v)
+		if _, err := parseSlowRequestFilter(form); err == nil {
+			t.Errorf("expected %s to be rejected", v)
+		}
+	}
+}
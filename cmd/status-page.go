@@ -0,0 +1,370 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gorilla/mux"
+	"github.com/minio/madmin-go"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+const statusPagePath = minioReservedBucketPath + "/status"
+
+const (
+	// A client failing to authenticate statusPageMaxFailures times is
+	// refused until statusPageFailureWindow passed since its first
+	// failure.
+	statusPageMaxFailures   = 5
+	statusPageFailureWindow = time.Minute
+
+	// statusPageMaxClients bounds the clients tracked, beyond it new
+	// clients are refused until tracked ones expire.
+	statusPageMaxClients = 10000
+)
+
+// registerStatusPageRouter - add handler function for the status page.
+func registerStatusPageRouter(router *mux.Router) {
+	router.Methods(http.MethodGet).Path(statusPagePath).HandlerFunc(httpTraceAll(StatusPageHandler))
+}
+
+// guessIsStatusPageReq - returns true if incoming request looks
+// like a status page request
+func guessIsStatusPageReq(req *http.Request) bool {
+	if req == nil {
+		return false
+	}
+	return req.Method == http.MethodGet && req.URL.Path == statusPagePath
+}
+
+// statusPageInfo - the figures shown by the status page.
+type statusPageInfo struct {
+	DeploymentID string
+	Time         string
+
+	TotalSpace, UsedSpace, FreeSpace string
+
+	Buckets, Objects uint64
+	ObjectsSize      string
+	UsageUpdated     string
+
+	Nodes  []statusPageNode
+	Drives []statusPageDrive
+
+	DrivesHealing int
+	HealPending   uint64
+
+	ReplicationPending, ReplicationFailed         uint64
+	ReplicationPendingSize, ReplicationFailedSize string
+}
+
+// statusPageNode - a node of the status page.
+type statusPageNode struct {
+	Endpoint, State, Version, Uptime string
+	Drives, DrivesOnline             int
+}
+
+// statusPageDrive - a drive of the status page.
+type statusPageDrive struct {
+	Endpoint, State       string
+	Healing               bool
+	UsedSpace, TotalSpace string
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>MinIO status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+.offline, .faulty, .unformatted, .corrupt { color: #b00; }
+</style>
+</head>
+<body>
+<h1>MinIO status</h1>
+<p>Deployment {{.DeploymentID}}, as of {{.Time}}</p>
+<h2>Capacity</h2>
+<table>
+<tr><th>Raw capacity</th><td>{{.TotalSpace}}</td></tr>
+<tr><th>Used</th><td>{{.UsedSpace}}</td></tr>
+<tr><th>Free</th><td>{{.FreeSpace}}</td></tr>
+<tr><th>Buckets</th><td>{{.Buckets}}</td></tr>
+<tr><th>Objects</th><td>{{.Objects}} ({{.ObjectsSize}}), last scanned {{.UsageUpdated}}</td></tr>
+</table>
+<h2>Nodes</h2>
+<table>
+<tr><th>Node</th><th>State</th><th>Version</th><th>Uptime</th><th>Drives online</th></tr>
+{{range .Nodes}}<tr><td>{{.Endpoint}}</td><td class="{{.State}}">{{.State}}</td><td>{{.Version}}</td><td>{{.Uptime}}</td><td>{{.DrivesOnline}}/{{.Drives}}</td></tr>
+{{end}}</table>
+<h2>Drives</h2>
+<table>
+<tr><th>Drive</th><th>State</th><th>Healing</th><th>Used</th><th>Total</th></tr>
+{{range .Drives}}<tr><td>{{.Endpoint}}</td><td class="{{.State}}">{{.State}}</td><td>{{if .Healing}}yes{{else}}no{{end}}</td><td>{{.UsedSpace}}</td><td>{{.TotalSpace}}</td></tr>
+{{end}}</table>
+<h2>Backlog</h2>
+<table>
+<tr><th>Drives healing</th><td>{{.DrivesHealing}}</td></tr>
+<tr><th>Objects queued for healing on this node</th><td>{{.HealPending}}</td></tr>
+<tr><th>Replication pending</th><td>{{.ReplicationPending}} objects ({{.ReplicationPendingSize}})</td></tr>
+<tr><th>Replication failed</th><td>{{.ReplicationFailed}} objects ({{.ReplicationFailedSize}})</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// statusPageFailures - the failed authentications of the status page per
+// client address.
+type statusPageFailures struct {
+	mu      sync.Mutex
+	clients map[string]*statusPageClient
+}
+
+type statusPageClient struct {
+	failures int
+	since    time.Time
+}
+
+var globalStatusPageFailures = &statusPageFailures{clients: make(map[string]*statusPageClient)}
+
+// blocked returns how long client is refused, zero if it is not.
+func (f *statusPageFailures) blocked(client string, now time.Time) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.clients[client]
+	if ok && now.Sub(c.since) >= statusPageFailureWindow {
+		delete(f.clients, client)
+		ok = false
+	}
+	if !ok {
+		if len(f.clients) < statusPageMaxClients {
+			return 0
+		}
+		for k, c := range f.clients {
+			if now.Sub(c.since) >= statusPageFailureWindow {
+				delete(f.clients, k)
+			}
+		}
+		if len(f.clients) < statusPageMaxClients {
+			return 0
+		}
+		return statusPageFailureWindow
+	}
+	if c.failures < statusPageMaxFailures {
+		return 0
+	}
+	return statusPageFailureWindow - now.Sub(c.since)
+}
+
+// fail records a failed authentication of client.
+func (f *statusPageFailures) fail(client string, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.clients[client]
+	if !ok {
+		if len(f.clients) >= statusPageMaxClients {
+			return
+		}
+		c = &statusPageClient{since: now}
+		f.clients[client] = c
+	}
+	c.failures++
+}
+
+// reset forgets the failures of client once it authenticated.
+func (f *statusPageFailures) reset(client string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.clients, client)
+}
+
+// StatusPageHandler - GET /minio/status
+// ----------
+// Serves a read-only HTML page with the capacity, the health of the
+// nodes and drives and the healing and replication backlog, for quick
+// checks when the Console is not deployed. Requests authenticate with
+// HTTP basic auth as an account allowed the admin:ServerInfo action,
+// the page is only served over TLS, and clients failing to authenticate
+// repeatedly are refused for a while.
+func StatusPageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "StatusPage")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	// The credentials of basic auth are sent in the clear.
+	if !globalIsTLS {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	// Clients are told apart by their connection, not by headers a
+	// client can set.
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	now := UTCNow()
+	if wait := globalStatusPageFailures.blocked(client, now); wait > 0 {
+		w.Header().Set(xhttp.RetryAfter, strconv.Itoa(int(wait.Seconds())+1))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	switch s3Err := statusPageAuthenticate(r); s3Err {
+	case ErrNone:
+		globalStatusPageFailures.reset(client)
+	case ErrServerNotInitialized:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	default:
+		globalStatusPageFailures.fail(client, now)
+		w.Header().Set("WWW-Authenticate", `Basic realm="MinIO", charset="UTF-8"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil || globalNotificationSys == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := statusPageTemplate.Execute(&buf, getStatusPageInfo(ctx, r, objectAPI)); err != nil {
+		logger.LogIf(ctx, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(xhttp.CacheControl, "no-store")
+	writeResponse(w, http.StatusOK, buf.Bytes(), mimeHTML)
+}
+
+// statusPageAuthenticate checks the HTTP basic auth credentials of r
+// are those of an account allowed the admin:ServerInfo action.
+func statusPageAuthenticate(r *http.Request) APIErrorCode {
+	accessKey, secretKey, ok := r.BasicAuth()
+	if !ok || accessKey == "" || secretKey == "" {
+		return ErrAccessDenied
+	}
+	cred, owner, s3Err := checkKeyValid(r, accessKey)
+	if s3Err != ErrNone {
+		return s3Err
+	}
+	if subtle.ConstantTimeCompare([]byte(cred.SecretKey), []byte(secretKey)) != 1 {
+		return ErrAccessDenied
+	}
+	if !globalIAMSys.IsAllowed(iampolicy.Args{
+		AccountName:     cred.AccessKey,
+		Groups:          cred.Groups,
+		Action:          iampolicy.ServerInfoAdminAction,
+		ConditionValues: getConditionValues(r, "", cred.AccessKey, cred.Claims),
+		IsOwner:         owner,
+		Claims:          cred.Claims,
+	}) {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
+
+// getStatusPageInfo gathers the figures of the status page.
+func getStatusPageInfo(ctx context.Context, r *http.Request, objectAPI ObjectLayer) statusPageInfo {
+	now := UTCNow()
+	info := statusPageInfo{
+		DeploymentID: globalDeploymentID,
+		Time:         now.Format(time.RFC1123),
+		UsageUpdated: "never",
+	}
+
+	servers := globalNotificationSys.ServerInfo()
+	servers = append(servers, getLocalServerProperty(globalEndpoints, r))
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Endpoint < servers[j].Endpoint
+	})
+
+	var total, used, free uint64
+	for _, s := range servers {
+		node := statusPageNode{
+			Endpoint: s.Endpoint,
+			State:    s.State,
+			Version:  s.Version,
+			Uptime:   (time.Duration(s.Uptime) * time.Second).String(),
+			Drives:   len(s.Disks),
+		}
+		for _, d := range s.Disks {
+			if d.State == madmin.DriveStateOk {
+				node.DrivesOnline++
+			}
+			if d.Healing {
+				info.DrivesHealing++
+			}
+			total += d.TotalSpace
+			used += d.UsedSpace
+			free += d.AvailableSpace
+			info.Drives = append(info.Drives, statusPageDrive{
+				Endpoint:   d.Endpoint,
+				State:      d.State,
+				Healing:    d.Healing,
+				UsedSpace:  humanize.IBytes(d.UsedSpace),
+				TotalSpace: humanize.IBytes(d.TotalSpace),
+			})
+		}
+		info.Nodes = append(info.Nodes, node)
+	}
+	info.TotalSpace = humanize.IBytes(total)
+	info.UsedSpace = humanize.IBytes(used)
+	info.FreeSpace = humanize.IBytes(free)
+
+	mrf := globalMRFState.getCurrentMRFRoundInfo()
+	info.HealPending = mrf.TotalItems - mrf.ItemsHealed
+
+	var pendingSize, failedSize uint64
+	if dui, err := loadDataUsageFromBackend(ctx, objectAPI); err == nil && !dui.LastUpdate.IsZero() {
+		info.Buckets = dui.BucketsCount
+		info.Objects = dui.ObjectsTotalCount
+		info.UsageUpdated = dui.LastUpdate.Format(time.RFC1123)
+		for _, bui := range dui.BucketsUsage {
+			for _, tgt := range bui.ReplicationInfo {
+				info.ReplicationPending += tgt.ReplicationPendingCount
+				info.ReplicationFailed += tgt.ReplicationFailedCount
+				pendingSize += tgt.ReplicationPendingSize
+				failedSize += tgt.ReplicationFailedSize
+			}
+		}
+		info.ObjectsSize = humanize.IBytes(dui.ObjectsTotalSize)
+	} else {
+		info.ObjectsSize = humanize.IBytes(0)
+	}
+	info.ReplicationPendingSize = humanize.IBytes(pendingSize)
+	info.ReplicationFailedSize = humanize.IBytes(failedSize)
+	return info
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuessIsStatusPageReq(t *testing.T) {
+	testCases := []struct {
+		method, path string
+		expected     bool
+	}{
+		{http.MethodGet, statusPagePath, true},
+		{http.MethodPost, statusPagePath, false},
+		{http.MethodGet, statusPagePath + "/x", false},
+		{http.MethodGet, "/bucket/status", false},
+	}
+	for i, tc := range testCases {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		if got := guessIsStatusPageReq(r); got != tc.expected {
+			t.Errorf("case %d: expected %v, got %v", i+1, tc.expected, got)
+		}
+	}
+}
+
+func TestStatusPageTemplate(t *testing.T) {
+	info := statusPageInfo{
+		DeploymentID: "b1e4cd9f",
+		Nodes: []statusPageNode{
+			{Endpoint: "node1:9000", State: "online", Drives: 2, DrivesOnline: 1},
+		},
+		Drives: []statusPageDrive{
+			{Endpoint: "http://node1:9000/<drive>", State: "offline"},
+		},
+		ReplicationPending: 42,
+	}
+	var buf bytes.Buffer
+	if err := statusPageTemplate.Execute(&buf, info); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, s := range []string{"b1e4cd9f", "node1:9000", "1/2", "&lt;drive&gt;", `class="offline"`, "42 objects"} {
+		if !strings.Contains(page, s) {
+			t.Errorf("expected page to contain %q", s)
+		}
+	}
+	if strings.Contains(page, "<drive>") {
+		t.Error("expected drive endpoint to be escaped")
+	}
+}
+
+func TestStatusPageFailures(t *testing.T) {
+	f := &statusPageFailures{clients: make(map[string]*statusPageClient)}
+	now := time.Now()
+	for i := 0; i < statusPageMaxFailures; i++ {
+		if wait := f.blocked("10.0.0.1", now); wait != 0 {
+			t.Fatalf("failure %d: unexpected refusal for %v", i+1, wait)
+		}
+		f.fail("10.0.0.1", now)
+	}
+	if wait := f.blocked("10.0.0.1", now.Add(time.Second)); wait != statusPageFailureWindow-time.Second {
+		t.Errorf("expected the client to be refused, got %v", wait)
+	}
+	if wait := f.blocked("10.0.0.2", now); wait != 0 {
+		t.Errorf("unexpected refusal of another client for %v", wait)
+	}
+	if wait := f.blocked("10.0.0.1", now.Add(statusPageFailureWindow)); wait != 0 {
+		t.Errorf("expected the client to be accepted again, got %v", wait)
+	}
+
+	f.fail("10.0.0.1", now)
+	f.reset("10.0.0.1")
+	if len(f.clients) != 0 {
+		t.Errorf("expected the failures to be forgotten, got %d clients", len(f.clients))
+	}
+}
@@ -45,10 +45,18 @@ type DiskInfo struct {
 
 // DiskMetrics has the information about XL Storage APIs
 // the number of calls of each API and the moving average of
-// the duration of each API.
+// the duration of each API, along with the error counters and
+// the recent read/write latency percentiles of the drive.
 type DiskMetrics struct {
 	APILatencies map[string]string `json:"apiLatencies,omitempty"`
 	APICalls     map[string]uint64 `json:"apiCalls,omitempty"`
+
+	// IOLatencies holds read and write latency percentiles
+	// in nanoseconds, keyed as "ReadP50", "WriteP99" etc.
+	IOLatencies   map[string]uint64 `json:"ioLatencies,omitempty"`
+	TotalErrors   uint64            `json:"totalErrors,omitempty"`
+	TotalTimeouts uint64            `json:"totalTimeouts,omitempty"`
+	OfflineEvents uint64            `json:"offlineEvents,omitempty"`
 }
 
 // VolsInfo is a collection of volume(bucket) information
@@ -351,6 +351,54 @@ func (z *DiskMetrics) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 				z.APICalls[za0003] = za0004
 			}
+		case "IOLatencies":
+			var zb0004 uint32
+			zb0004, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "IOLatencies")
+				return
+			}
+			if z.IOLatencies == nil {
+				z.IOLatencies = make(map[string]uint64, zb0004)
+			} else if len(z.IOLatencies) > 0 {
+				for key := range z.IOLatencies {
+					delete(z.IOLatencies, key)
+				}
+			}
+			for zb0004 > 0 {
+				zb0004--
+				var za0005 string
+				var za0006 uint64
+				za0005, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "IOLatencies")
+					return
+				}
+				za0006, err = dc.ReadUint64()
+				if err != nil {
+					err = msgp.WrapError(err, "IOLatencies", za0005)
+					return
+				}
+				z.IOLatencies[za0005] = za0006
+			}
+		case "TotalErrors":
+			z.TotalErrors, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "TotalErrors")
+				return
+			}
+		case "TotalTimeouts":
+			z.TotalTimeouts, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "TotalTimeouts")
+				return
+			}
+		case "OfflineEvents":
+			z.OfflineEvents, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "OfflineEvents")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -364,9 +412,9 @@ func (z *DiskMetrics) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *DiskMetrics) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 2
+	// map header, size 6
 	// write "APILatencies"
-	err = en.Append(0x82, 0xac, 0x41, 0x50, 0x49, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
+	err = en.Append(0x86, 0xac, 0x41, 0x50, 0x49, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
 	if err != nil {
 		return
 	}
@@ -409,15 +457,67 @@ func (z *DiskMetrics) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "IOLatencies"
+	err = en.Append(0xab, 0x49, 0x4f, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.IOLatencies)))
+	if err != nil {
+		err = msgp.WrapError(err, "IOLatencies")
+		return
+	}
+	for za0005, za0006 := range z.IOLatencies {
+		err = en.WriteString(za0005)
+		if err != nil {
+			err = msgp.WrapError(err, "IOLatencies")
+			return
+		}
+		err = en.WriteUint64(za0006)
+		if err != nil {
+			err = msgp.WrapError(err, "IOLatencies", za0005)
+			return
+		}
+	}
+	// write "TotalErrors"
+	err = en.Append(0xab, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.TotalErrors)
+	if err != nil {
+		err = msgp.WrapError(err, "TotalErrors")
+		return
+	}
+	// write "TotalTimeouts"
+	err = en.Append(0xad, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.TotalTimeouts)
+	if err != nil {
+		err = msgp.WrapError(err, "TotalTimeouts")
+		return
+	}
+	// write "OfflineEvents"
+	err = en.Append(0xad, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.OfflineEvents)
+	if err != nil {
+		err = msgp.WrapError(err, "OfflineEvents")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *DiskMetrics) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 2
+	// map header, size 6
 	// string "APILatencies"
-	o = append(o, 0x82, 0xac, 0x41, 0x50, 0x49, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
+	o = append(o, 0x86, 0xac, 0x41, 0x50, 0x49, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
 	o = msgp.AppendMapHeader(o, uint32(len(z.APILatencies)))
 	for za0001, za0002 := range z.APILatencies {
 		o = msgp.AppendString(o, za0001)
@@ -430,6 +530,22 @@ func (z *DiskMetrics) MarshalMsg(b []byte) (o []byte, err error) {
 		o = msgp.AppendString(o, za0003)
 		o = msgp.AppendUint64(o, za0004)
 	}
+	// string "IOLatencies"
+	o = append(o, 0xab, 0x49, 0x4f, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.IOLatencies)))
+	for za0005, za0006 := range z.IOLatencies {
+		o = msgp.AppendString(o, za0005)
+		o = msgp.AppendUint64(o, za0006)
+	}
+	// string "TotalErrors"
+	o = append(o, 0xab, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73)
+	o = msgp.AppendUint64(o, z.TotalErrors)
+	// string "TotalTimeouts"
+	o = append(o, 0xad, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73)
+	o = msgp.AppendUint64(o, z.TotalTimeouts)
+	// string "OfflineEvents"
+	o = append(o, 0xad, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73)
+	o = msgp.AppendUint64(o, z.OfflineEvents)
 	return
 }
 
@@ -511,6 +627,54 @@ func (z *DiskMetrics) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 				z.APICalls[za0003] = za0004
 			}
+		case "IOLatencies":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IOLatencies")
+				return
+			}
+			if z.IOLatencies == nil {
+				z.IOLatencies = make(map[string]uint64, zb0004)
+			} else if len(z.IOLatencies) > 0 {
+				for key := range z.IOLatencies {
+					delete(z.IOLatencies, key)
+				}
+			}
+			for zb0004 > 0 {
+				var za0005 string
+				var za0006 uint64
+				zb0004--
+				za0005, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "IOLatencies")
+					return
+				}
+				za0006, bts, err = msgp.ReadUint64Bytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "IOLatencies", za0005)
+					return
+				}
+				z.IOLatencies[za0005] = za0006
+			}
+		case "TotalErrors":
+			z.TotalErrors, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TotalErrors")
+				return
+			}
+		case "TotalTimeouts":
+			z.TotalTimeouts, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TotalTimeouts")
+				return
+			}
+		case "OfflineEvents":
+			z.OfflineEvents, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "OfflineEvents")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -539,6 +703,14 @@ func (z *DiskMetrics) Msgsize() (s int) {
 			s += msgp.StringPrefixSize + len(za0003) + msgp.Uint64Size
 		}
 	}
+	s += 12 + msgp.MapHeaderSize
+	if z.IOLatencies != nil {
+		for za0005, za0006 := range z.IOLatencies {
+			_ = za0006
+			s += msgp.StringPrefixSize + len(za0005) + msgp.Uint64Size
+		}
+	}
+	s += 12 + msgp.Uint64Size + 14 + msgp.Uint64Size + 14 + msgp.Uint64Size
 	return
 }
 
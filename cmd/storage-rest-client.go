@@ -376,6 +376,9 @@ func (client *storageRESTClient) CreateFile(ctx context.Context, volume, path st
 	values.Set(storageRESTVolume, volume)
 	values.Set(storageRESTFilePath, path)
 	values.Set(storageRESTLength, strconv.Itoa(int(size)))
+	if policy := fsyncPolicyFromContext(ctx); policy != fsyncAlways {
+		values.Set(storageRESTFsyncPolicy, string(policy))
+	}
 	respBody, err := client.call(ctx, storageRESTMethodCreateFile, values, ioutil.NopCloser(reader), size)
 	defer xhttp.DrainBody(respBody)
 	if err != nil {
@@ -465,6 +468,9 @@ func (client *storageRESTClient) RenameData(ctx context.Context, srcVolume, srcP
 	values.Set(storageRESTSrcPath, srcPath)
 	values.Set(storageRESTDstVolume, dstVolume)
 	values.Set(storageRESTDstPath, dstPath)
+	if policy := fsyncPolicyFromContext(ctx); policy != fsyncAlways {
+		values.Set(storageRESTFsyncPolicy, string(policy))
+	}
 
 	var reader bytes.Buffer
 	if err = msgp.Encode(&reader, &fi); err != nil {
@@ -652,6 +658,18 @@ func (client *storageRESTClient) RenameFile(ctx context.Context, srcVolume, srcP
 	return err
 }
 
+// LinkFile - hard links a file.
+func (client *storageRESTClient) LinkFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	values := make(url.Values)
+	values.Set(storageRESTSrcVolume, srcVolume)
+	values.Set(storageRESTSrcPath, srcPath)
+	values.Set(storageRESTDstVolume, dstVolume)
+	values.Set(storageRESTDstPath, dstPath)
+	respBody, err := client.call(ctx, storageRESTMethodLinkFile, values, nil, -1)
+	defer xhttp.DrainBody(respBody)
+	return err
+}
+
 func (client *storageRESTClient) VerifyFile(ctx context.Context, volume, path string, fi FileInfo) error {
 	values := make(url.Values)
 	values.Set(storageRESTVolume, volume)
@@ -326,8 +326,28 @@ func (s *storageRESTServer) CreateFileHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	ctx, err := fsyncPolicyContext(r)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
 	done, body := keepHTTPReqResponseAlive(w, r)
-	done(s.storage.CreateFile(r.Context(), volume, filePath, int64(fileSize), body))
+	done(s.storage.CreateFile(ctx, volume, filePath, int64(fileSize), body))
+}
+
+// fsyncPolicyContext returns the context of r carrying the fsync policy
+// of the files written.
+func fsyncPolicyContext(r *http.Request) (context.Context, error) {
+	ctx := r.Context()
+	if v := r.Form.Get(storageRESTFsyncPolicy); v != "" {
+		policy, err := parseFsyncPolicy(v)
+		if err != nil {
+			return ctx, errInvalidArgument
+		}
+		ctx = withFsyncPolicy(ctx, policy)
+	}
+	return ctx, nil
 }
 
 // DeleteVersion delete updated metadata.
@@ -697,7 +717,13 @@ func (s *storageRESTServer) RenameDataHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err := s.storage.RenameData(r.Context(), srcVolume, srcFilePath, fi, dstVolume, dstFilePath)
+	ctx, err := fsyncPolicyContext(r)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	err = s.storage.RenameData(ctx, srcVolume, srcFilePath, fi, dstVolume, dstFilePath)
 	if err != nil {
 		s.writeErrorResponse(w, err)
 	}
@@ -719,6 +745,22 @@ func (s *storageRESTServer) RenameFileHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// LinkFileHandler - hard link a file.
+func (s *storageRESTServer) LinkFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	srcVolume := vars[storageRESTSrcVolume]
+	srcFilePath := vars[storageRESTSrcPath]
+	dstVolume := vars[storageRESTDstVolume]
+	dstFilePath := vars[storageRESTDstPath]
+	err := s.storage.LinkFile(r.Context(), srcVolume, srcFilePath, dstVolume, dstFilePath)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
 // closeNotifier is itself a ReadCloser that will notify when either an error occurs or
 // the Close() function is called.
 type closeNotifier struct {
@@ -1219,6 +1261,8 @@ func registerStorageRESTHandlers(router *mux.Router, endpointServerPools Endpoin
 
 			subrouter.Methods(http.MethodPost).Path(storageRESTVersionPrefix + storageRESTMethodRenameFile).HandlerFunc(httpTraceHdrs(server.RenameFileHandler)).
 				Queries(restQueries(storageRESTSrcVolume, storageRESTSrcPath, storageRESTDstVolume, storageRESTDstPath)...)
+			subrouter.Methods(http.MethodPost).Path(storageRESTVersionPrefix + storageRESTMethodLinkFile).HandlerFunc(httpTraceHdrs(server.LinkFileHandler)).
+				Queries(restQueries(storageRESTSrcVolume, storageRESTSrcPath, storageRESTDstVolume, storageRESTDstPath)...)
 			subrouter.Methods(http.MethodPost).Path(storageRESTVersionPrefix + storageRESTMethodVerifyFile).HandlerFunc(httpTraceHdrs(server.VerifyFileHandler)).
 				Queries(restQueries(storageRESTVolume, storageRESTFilePath)...)
 			subrouter.Methods(http.MethodPost).Path(storageRESTVersionPrefix + storageRESTMethodWalkDir).HandlerFunc(httpTraceHdrs(server.WalkDirHandler)).
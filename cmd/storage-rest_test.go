@@ -18,11 +18,15 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -416,6 +420,12 @@ func testStorageAPIRenameFile(t *testing.T, storage StorageAPI) {
 }
 
 func newStorageRESTHTTPServerClient(t *testing.T) (*httptest.Server, *storageRESTClient, string) {
+	return newStorageRESTHTTPServerClientWith(t, nil)
+}
+
+// newStorageRESTHTTPServerClientWith serves the storage REST handlers
+// wrapped by wrap, when set.
+func newStorageRESTHTTPServerClientWith(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, *storageRESTClient, string) {
 	prevHost, prevPort := globalMinioHost, globalMinioPort
 	defer func() {
 		globalMinioHost, globalMinioPort = prevHost, prevPort
@@ -427,7 +437,11 @@ func newStorageRESTHTTPServerClient(t *testing.T) (*httptest.Server, *storageRES
 	}
 
 	router := mux.NewRouter()
-	httpServer := httptest.NewServer(router)
+	var handler http.Handler = router
+	if wrap != nil {
+		handler = wrap(router)
+	}
+	httpServer := httptest.NewServer(handler)
 
 	url, err := xnet.ParseHTTPURL(httpServer.URL)
 	if err != nil {
@@ -550,3 +564,74 @@ func TestStorageRESTClientRenameFile(t *testing.T) {
 
 	testStorageAPIRenameFile(t, restClient)
 }
+
+func TestStorageRESTClientFsyncPolicy(t *testing.T) {
+	var mu sync.Mutex
+	policies := make(map[string]string)
+	httpServer, restClient, endpointPath := newStorageRESTHTTPServerClientWith(t, func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, storageRESTMethodCreateFile) {
+				mu.Lock()
+				policies[r.URL.Query().Get(storageRESTFilePath)] = r.URL.Query().Get(storageRESTFsyncPolicy)
+				mu.Unlock()
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	defer httpServer.Close()
+	defer os.RemoveAll(endpointPath)
+
+	if err := restClient.MakeVol(context.Background(), "foo"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for policy, want := range map[fsyncPolicy]string{
+		fsyncAlways:  "",
+		fsyncBatched: "batched",
+		fsyncNever:   "never",
+	} {
+		data := []byte("object written with policy " + string(policy))
+		ctx := withFsyncPolicy(context.Background(), policy)
+		if err := restClient.CreateFile(ctx, "foo", string(policy), int64(len(data)), bytes.NewReader(data)); err != nil {
+			t.Fatalf("%s: unexpected error %v", policy, err)
+		}
+		got, err := restClient.ReadAll(context.Background(), "foo", string(policy))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", policy, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("%s: expected %q, got %q", policy, data, got)
+		}
+		mu.Lock()
+		sent := policies[string(policy)]
+		mu.Unlock()
+		if sent != want {
+			t.Fatalf("%s: expected fsync policy %q to be sent, got %q", policy, want, sent)
+		}
+	}
+
+	for query, want := range map[string]fsyncPolicy{
+		"":                                  fsyncAlways,
+		storageRESTFsyncPolicy + "=never":   fsyncNever,
+		storageRESTFsyncPolicy + "=batched": fsyncBatched,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/?"+query, nil)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		ctx, err := fsyncPolicyContext(r)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", query, err)
+		}
+		if policy := fsyncPolicyFromContext(ctx); policy != want {
+			t.Fatalf("%q: expected policy %s, got %s", query, want, policy)
+		}
+	}
+	r := httptest.NewRequest(http.MethodPost, "/?"+storageRESTFsyncPolicy+"=sometimes", nil)
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsyncPolicyContext(r); err != errInvalidArgument {
+		t.Fatalf("expected %v, got %v", errInvalidArgument, err)
+	}
+}
@@ -50,6 +50,10 @@ const (
 	stsDurationSeconds        = "DurationSeconds"
 	stsLDAPUsername           = "LDAPUsername"
 	stsLDAPPassword           = "LDAPPassword"
+	stsBucket                 = "Bucket"
+	stsKey                    = "Key"
+	stsMaxSize                = "MaxSize"
+	stsContentType            = "ContentType"
 
 	// STS API action constants
 	clientGrants      = "AssumeRoleWithClientGrants"
@@ -57,6 +61,7 @@ const (
 	ldapIdentity      = "AssumeRoleWithLDAPIdentity"
 	clientCertificate = "AssumeRoleWithCertificate"
 	assumeRole        = "AssumeRole"
+	assumeRoleUpload  = "AssumeRoleForUpload"
 
 	stsRequestBodyLimit = 10 * (1 << 20) // 10 MiB
 
@@ -202,7 +207,7 @@ func (sts *stsAPIHandlers) AssumeRole(w http.ResponseWriter, r *http.Request) {
 
 	action := r.Form.Get(stsAction)
 	switch action {
-	case assumeRole:
+	case assumeRole, assumeRoleUpload:
 	default:
 		writeSTSErrorResponse(ctx, w, true, ErrSTSInvalidParameterValue, fmt.Errorf("Unsupported action %s", action))
 		return
@@ -242,6 +247,31 @@ func (sts *stsAPIHandlers) AssumeRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if action == assumeRoleUpload {
+		// Upload tokens allow a single upload of one object, which
+		// the user must be allowed to upload.
+		t, err := parseUploadTokenRequest(r.Form)
+		if err == nil {
+			err = t.setClaims(m)
+		}
+		if err != nil {
+			writeSTSErrorResponse(ctx, w, true, ErrSTSInvalidParameterValue, err)
+			return
+		}
+		if !globalIAMSys.IsAllowed(iampolicy.Args{
+			AccountName:     user.AccessKey,
+			Action:          iampolicy.PutObjectAction,
+			BucketName:      t.bucket,
+			ObjectName:      t.object,
+			ConditionValues: getConditionValues(r, "", user.AccessKey, nil),
+			IsOwner:         user.AccessKey == globalActiveCred.AccessKey,
+		}) {
+			writeSTSErrorResponse(ctx, w, true, ErrSTSAccessDenied, nil)
+			return
+		}
+		sessionPolicyStr = t.sessionPolicy()
+	}
+
 	policies, err := globalIAMSys.PolicyDBGet(user.AccessKey, false)
 	if err != nil {
 		writeSTSErrorResponse(ctx, w, true, ErrSTSInvalidParameterValue, err)
@@ -324,12 +354,13 @@ func (sts *stsAPIHandlers) AssumeRoleWithSSO(w http.ResponseWriter, r *http.Requ
 	ctx = newContext(r, w, action)
 	defer logger.AuditLog(ctx, w, r, nil)
 
-	if globalOpenIDValidators == nil {
+	openIDConfig, openIDValidators := getOpenIDConfig()
+	if openIDValidators == nil {
 		writeSTSErrorResponse(ctx, w, true, ErrSTSNotInitialized, errServerNotInitialized)
 		return
 	}
 
-	v, err := globalOpenIDValidators.Get("jwt")
+	v, err := openIDValidators.Get("jwt")
 	if err != nil {
 		writeSTSErrorResponse(ctx, w, true, ErrSTSInvalidParameterValue, err)
 		return
@@ -374,7 +405,7 @@ func (sts *stsAPIHandlers) AssumeRoleWithSSO(w http.ResponseWriter, r *http.Requ
 			errors.New("STS JWT Token has `aud` claim invalid, `aud` must match configured OpenID Client ID"))
 		return
 	}
-	if !audValues.Contains(globalOpenIDConfig.ClientID) {
+	if !audValues.Contains(openIDConfig.ClientID) {
 		// if audience claims is missing, look for "azp" claims.
 		// OPTIONAL. Authorized party - the party to which the ID
 		// Token was issued. If present, it MUST contain the OAuth
@@ -390,7 +421,7 @@ func (sts *stsAPIHandlers) AssumeRoleWithSSO(w http.ResponseWriter, r *http.Requ
 				errors.New("STS JWT Token has `aud` claim invalid, `aud` must match configured OpenID Client ID"))
 			return
 		}
-		if !azpValues.Contains(globalOpenIDConfig.ClientID) {
+		if !azpValues.Contains(openIDConfig.ClientID) {
 			writeSTSErrorResponse(ctx, w, true, ErrSTSInvalidParameterValue,
 				errors.New("STS JWT Token has `azp` claim invalid, `azp` must match configured OpenID Client ID"))
 			return
@@ -0,0 +1,770 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/avro"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+)
+
+// Table maintenance reports are saved under this prefix of the meta bucket.
+const tableMaintenanceReportsPrefix = "table-maintenance-reports"
+
+// Table maintenance job states.
+const (
+	TableMaintenanceRunning   = "running"
+	TableMaintenanceCompleted = "completed"
+	TableMaintenanceFailed    = "failed"
+)
+
+// Table formats maintained.
+const (
+	tableFormatIceberg = "iceberg"
+	tableFormatDelta   = "delta"
+)
+
+const (
+	// Files more recent than this may belong to a commit in progress,
+	// unless told otherwise only files older are deleted.
+	defaultTableOrphanAge = 72 * time.Hour
+	minTableOrphanAge     = time.Hour
+
+	// maxTableMetadataSize bounds the metadata and log files read.
+	maxTableMetadataSize = 256 << 20
+
+	// Finished jobs are forgotten beyond this count, their reports are kept.
+	maxTableMaintenanceJobs = 100
+
+	// Orphans beyond this count are deleted but not listed in reports.
+	maxTableMaintenanceResults = 10000
+
+	// The table is read again until no commit happened while it was.
+	tableMaintenanceAttempts = 3
+)
+
+var (
+	errNoSuchTableMaintenanceJob = errors.New("no such table maintenance job")
+	errTableNotFound             = errors.New("no Iceberg or Delta table found under the prefix")
+
+	deltaCommitRegexp = regexp.MustCompile(`^[0-9]{20}\.json$`)
+)
+
+// TableMaintenanceOpts are the parameters of a table maintenance job,
+// removing the orphaned files of the Iceberg or Delta table at Prefix.
+type TableMaintenanceOpts struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	// Format of the table, detected when empty.
+	Format string `json:"format,omitempty"`
+	// Only files last modified before this age are deleted.
+	OlderThan time.Duration `json:"olderThan"`
+	// Report the orphaned files without deleting them.
+	DryRun bool `json:"dryRun"`
+}
+
+// TableOrphanFile is a file of a table referenced by none of its
+// snapshots or log entries.
+type TableOrphanFile struct {
+	Object       string    `json:"object"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Deleted      bool      `json:"deleted"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// TableMaintenanceStatus is the progress of a table maintenance job.
+type TableMaintenanceStatus struct {
+	ID          string               `json:"id"`
+	Opts        TableMaintenanceOpts `json:"opts"`
+	Node        string               `json:"node"`
+	State       string               `json:"state"`
+	Started     time.Time            `json:"started"`
+	Finished    time.Time            `json:"finished,omitempty"`
+	Format      string               `json:"format,omitempty"`
+	Versions    int                  `json:"versions"`
+	Referenced  int                  `json:"referenced"`
+	Objects     uint64               `json:"objects"`
+	Orphans     uint64               `json:"orphans"`
+	OrphanBytes uint64               `json:"orphanBytes"`
+	Deleted     uint64               `json:"deleted"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// TableMaintenanceReport is the result of a table maintenance job.
+type TableMaintenanceReport struct {
+	TableMaintenanceStatus
+	Orphans   []TableOrphanFile `json:"orphanFiles"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// tableFiles are the files referenced by the metadata of a table, and
+// the metadata files read with their ETags, changed by commits.
+type tableFiles struct {
+	format     string
+	versions   int
+	referenced map[string]struct{}
+	metadata   map[string]string
+}
+
+// tableObjectKey returns the object of bucket an absolute path of a
+// table file refers to, false if it is not an object of bucket. Paths are
+// not unescaped: Iceberg writes object keys as they are, which may hold
+// escaped partition values or '#' and '?'.
+func tableObjectKey(bucket, p string) (string, bool) {
+	i := strings.Index(p, "://")
+	if i < 0 {
+		return "", false
+	}
+	switch p[:i] {
+	case "s3", "s3a", "s3n":
+	default:
+		return "", false
+	}
+	p = p[i+len("://"):]
+	j := strings.Index(p, SlashSeparator)
+	if j < 0 || p[:j] != bucket || j == len(p)-1 {
+		return "", false
+	}
+	return p[j+1:], true
+}
+
+// protectedTableFile returns whether name, relative to the root of the
+// table, is never deleted: hidden files and folders, such as the Delta
+// log, and the Iceberg metadata and version hint. Delta deletion vectors
+// are named from their encoded UUID and protected as well.
+func protectedTableFile(name string) bool {
+	for _, c := range strings.Split(name, SlashSeparator) {
+		if strings.HasPrefix(c, "_") || strings.HasPrefix(c, ".") {
+			return true
+		}
+	}
+	base := path.Base(name)
+	return strings.HasSuffix(base, ".metadata.json") || base == "version-hint.text" ||
+		strings.HasPrefix(base, "deletion_vector_") && strings.HasSuffix(base, ".bin")
+}
+
+// nestedTable returns whether name, relative to the root of the table,
+// belongs to another table nested under it.
+func nestedTable(name string) bool {
+	dir := path.Dir(name)
+	if dir == "." || dir == "metadata" || dir == "_delta_log" {
+		return false
+	}
+	return strings.HasSuffix(dir, "/_delta_log") && deltaCommitRegexp.MatchString(path.Base(name)) ||
+		strings.HasSuffix(dir, "/metadata") && strings.HasSuffix(name, ".metadata.json")
+}
+
+// listTableObjects calls fn for the latest version of the objects under
+// prefix.
+func listTableObjects(ctx context.Context, objAPI ObjectLayer, bucket, prefix string, fn func(ObjectInfo) error) error {
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, bucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range res.Objects {
+			if err = fn(oi); err != nil {
+				return err
+			}
+		}
+		if !res.IsTruncated {
+			return nil
+		}
+		marker = res.NextMarker
+	}
+}
+
+// readTableFile returns the content of a metadata file, ungzipped.
+func readTableFile(ctx context.Context, objAPI ObjectLayer, bucket, object string) (io.ReadCloser, error) {
+	gr, err := objAPI.GetObjectNInfo(ctx, bucket, object, nil, http.Header{}, readLock, ObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(object, ".gz") && !strings.Contains(path.Base(object), ".gz.") {
+		return gr, nil
+	}
+	zr, err := gzip.NewReader(gr)
+	if err != nil {
+		gr.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, gr}, nil
+}
+
+// tableMetadataFiles returns the ETags of the metadata files of the
+// table at prefix, changed by every commit.
+func tableMetadataFiles(ctx context.Context, objAPI ObjectLayer, bucket, prefix, format string) (map[string]string, error) {
+	dir := "metadata/"
+	if format == tableFormatDelta {
+		dir = "_delta_log/"
+	}
+	files := make(map[string]string)
+	err := listTableObjects(ctx, objAPI, bucket, prefix+dir, func(oi ObjectInfo) error {
+		name := strings.TrimPrefix(oi.Name, prefix+dir)
+		if format == tableFormatIceberg && strings.HasSuffix(name, ".metadata.json") ||
+			format == tableFormatDelta && deltaCommitRegexp.MatchString(name) {
+			files[oi.Name] = oi.ETag
+		}
+		return nil
+	})
+	return files, err
+}
+
+// detectTableFormat returns the format of the table at prefix.
+func detectTableFormat(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) (string, error) {
+	for _, format := range []string{tableFormatIceberg, tableFormatDelta} {
+		files, err := tableMetadataFiles(ctx, objAPI, bucket, prefix, format)
+		if err != nil {
+			return "", err
+		}
+		if len(files) > 0 {
+			return format, nil
+		}
+	}
+	return "", errTableNotFound
+}
+
+// icebergMetadata - the parts of an Iceberg table metadata file listing
+// the files of the table.
+type icebergMetadata struct {
+	Location   string            `json:"location"`
+	Properties map[string]string `json:"properties"`
+	Snapshots  []struct {
+		ManifestList string   `json:"manifest-list"`
+		Manifests    []string `json:"manifests"`
+	} `json:"snapshots"`
+	Statistics []struct {
+		StatisticsPath string `json:"statistics-path"`
+	} `json:"statistics"`
+	PartitionStatistics []struct {
+		StatisticsPath string `json:"statistics-path"`
+	} `json:"partition-statistics"`
+	MetadataLog []struct {
+		MetadataFile string `json:"metadata-file"`
+	} `json:"metadata-log"`
+}
+
+// loadIcebergFiles returns the files referenced by any metadata file of
+// the Iceberg table at prefix, of all the snapshots they list, including
+// the entries of manifests marked deleted. Fails unless all metadata
+// locate the table at prefix, with its metadata under prefix/metadata.
+func loadIcebergFiles(ctx context.Context, objAPI ObjectLayer, bucket, prefix string) (tableFiles, error) {
+	files := tableFiles{format: tableFormatIceberg, referenced: make(map[string]struct{})}
+	var err error
+	if files.metadata, err = tableMetadataFiles(ctx, objAPI, bucket, prefix, tableFormatIceberg); err != nil {
+		return files, err
+	}
+	if len(files.metadata) == 0 {
+		return files, errTableNotFound
+	}
+
+	var manifestLists, manifests []string
+	reference := func(p string, list *[]string) {
+		key, ok := tableObjectKey(bucket, p)
+		if !ok {
+			return
+		}
+		if _, ok = files.referenced[key]; ok {
+			return
+		}
+		files.referenced[key] = struct{}{}
+		if list != nil {
+			*list = append(*list, key)
+		}
+	}
+	for object := range files.metadata {
+		files.referenced[object] = struct{}{}
+		rc, err := readTableFile(ctx, objAPI, bucket, object)
+		if err != nil {
+			return files, err
+		}
+		var m icebergMetadata
+		err = json.NewDecoder(io.LimitReader(rc, maxTableMetadataSize)).Decode(&m)
+		rc.Close()
+		if err != nil {
+			return files, fmt.Errorf("%s: %w", object, err)
+		}
+		if key, ok := tableObjectKey(bucket, m.Location); !ok || strings.Trim(key, SlashSeparator) != strings.Trim(prefix, SlashSeparator) {
+			return files, fmt.Errorf("%s: the table location %s is not the prefix", object, m.Location)
+		}
+		if p := m.Properties["write.metadata.path"]; p != "" && strings.TrimSuffix(p, SlashSeparator) != strings.TrimSuffix(m.Location, SlashSeparator)+"/metadata" {
+			return files, fmt.Errorf("%s: the table metadata is written to %s", object, p)
+		}
+		files.versions += len(m.Snapshots)
+		for _, s := range m.Snapshots {
+			if s.ManifestList != "" {
+				reference(s.ManifestList, &manifestLists)
+			}
+			for _, p := range s.Manifests {
+				reference(p, &manifests)
+			}
+		}
+		for _, s := range m.Statistics {
+			reference(s.StatisticsPath, nil)
+		}
+		for _, s := range m.PartitionStatistics {
+			reference(s.StatisticsPath, nil)
+		}
+		for _, l := range m.MetadataLog {
+			reference(l.MetadataFile, nil)
+		}
+	}
+
+	// Snapshots expired since older metadata were written have their
+	// files deleted, those missing are skipped.
+	readAvro := func(object string, fn func(map[string]interface{})) error {
+		rc, err := readTableFile(ctx, objAPI, bucket, object)
+		if isErrObjectNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		r, err := avro.NewReader(io.LimitReader(rc, maxTableMetadataSize))
+		if err != nil {
+			return fmt.Errorf("%s: %w", object, err)
+		}
+		for {
+			v, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", object, err)
+			}
+			if rec, ok := v.(map[string]interface{}); ok {
+				fn(rec)
+			}
+		}
+	}
+	for _, object := range manifestLists {
+		if err = readAvro(object, func(rec map[string]interface{}) {
+			if p, ok := rec["manifest_path"].(string); ok {
+				reference(p, &manifests)
+			}
+		}); err != nil {
+			return files, err
+		}
+	}
+	for _, object := range manifests {
+		if err = readAvro(object, func(rec map[string]interface{}) {
+			if df, ok := rec["data_file"].(map[string]interface{}); ok {
+				if p, ok := df["file_path"].(string); ok {
+					reference(p, nil)
+				}
+			}
+		}); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// deltaAction - the actions of a Delta log entry adding or removing
+// files.
+type deltaAction struct {
+	Add *struct {
+		Path string `json:"path"`
+	} `json:"add"`
+	Remove *struct {
+		Path              string `json:"path"`
+		DeletionTimestamp int64  `json:"deletionTimestamp"`
+	} `json:"remove"`
+	Cdc *struct {
+		Path string `json:"path"`
+	} `json:"cdc"`
+}
+
+// deltaObjectKey returns the object of a path of a Delta log entry,
+// relative to the table root unless absolute.
+func deltaObjectKey(bucket, prefix, p string) (string, bool) {
+	if strings.Contains(p, "://") {
+		// Delta paths are URIs, absolute ones as well.
+		key, ok := tableObjectKey(bucket, p)
+		if !ok {
+			return "", false
+		}
+		key, err := url.PathUnescape(key)
+		return key, err == nil
+	}
+	p, err := url.PathUnescape(p)
+	if err != nil {
+		return "", false
+	}
+	return prefix + strings.TrimPrefix(p, SlashSeparator), true
+}
+
+// loadDeltaFiles returns the files added by the log of the Delta table
+// at prefix, but those removed before expiry. Fails if the log does not
+// start at version 0, its history being reduced to a checkpoint.
+func loadDeltaFiles(ctx context.Context, objAPI ObjectLayer, bucket, prefix string, expiry time.Time) (tableFiles, error) {
+	files := tableFiles{format: tableFormatDelta, referenced: make(map[string]struct{})}
+	var err error
+	if files.metadata, err = tableMetadataFiles(ctx, objAPI, bucket, prefix, tableFormatDelta); err != nil {
+		return files, err
+	}
+	if len(files.metadata) == 0 {
+		return files, errTableNotFound
+	}
+	commits := make([]string, 0, len(files.metadata))
+	for object := range files.metadata {
+		commits = append(commits, object)
+	}
+	sort.Strings(commits)
+	for i, object := range commits {
+		if path.Base(object) != fmt.Sprintf("%020d.json", i) {
+			return files, fmt.Errorf("the Delta log has no commit %d, tables with a log starting at a checkpoint are not supported", i)
+		}
+	}
+	files.versions = len(commits)
+
+	removed := make(map[string]time.Time)
+	for _, object := range commits {
+		rc, err := readTableFile(ctx, objAPI, bucket, object)
+		if err != nil {
+			return files, err
+		}
+		s := bufio.NewScanner(io.LimitReader(rc, maxTableMetadataSize))
+		s.Buffer(make([]byte, 64<<10), maxTableMetadataSize)
+		for s.Scan() {
+			if len(strings.TrimSpace(s.Text())) == 0 {
+				continue
+			}
+			var a deltaAction
+			if err = json.Unmarshal(s.Bytes(), &a); err != nil {
+				break
+			}
+			if a.Add != nil {
+				if key, ok := deltaObjectKey(bucket, prefix, a.Add.Path); ok {
+					files.referenced[key] = struct{}{}
+					delete(removed, key)
+				}
+			}
+			if a.Cdc != nil {
+				if key, ok := deltaObjectKey(bucket, prefix, a.Cdc.Path); ok {
+					files.referenced[key] = struct{}{}
+				}
+			}
+			if a.Remove != nil {
+				// Files removed at an unknown time are kept.
+				if key, ok := deltaObjectKey(bucket, prefix, a.Remove.Path); ok {
+					files.referenced[key] = struct{}{}
+					delete(removed, key)
+					if a.Remove.DeletionTimestamp > 0 {
+						removed[key] = time.Unix(0, a.Remove.DeletionTimestamp*int64(time.Millisecond))
+					}
+				}
+			}
+		}
+		if err == nil {
+			err = s.Err()
+		}
+		rc.Close()
+		if err != nil {
+			return files, fmt.Errorf("%s: %w", object, err)
+		}
+	}
+	// Files removed long enough ago are no longer needed by time travel.
+	for key, t := range removed {
+		if t.Before(expiry) {
+			delete(files.referenced, key)
+		}
+	}
+	return files, nil
+}
+
+func loadTableFiles(ctx context.Context, objAPI ObjectLayer, opts TableMaintenanceOpts, format string, now time.Time) (tableFiles, error) {
+	if format == tableFormatDelta {
+		return loadDeltaFiles(ctx, objAPI, opts.Bucket, opts.Prefix, now.Add(-opts.OlderThan))
+	}
+	return loadIcebergFiles(ctx, objAPI, opts.Bucket, opts.Prefix)
+}
+
+// sameTableMetadata returns whether the metadata files are unchanged.
+func sameTableMetadata(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type tableMaintenanceJob struct {
+	mu      sync.Mutex
+	status  TableMaintenanceStatus
+	orphans []TableOrphanFile
+}
+
+func (j *tableMaintenanceJob) getStatus() TableMaintenanceStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// tableMaintenanceJobs are the table maintenance jobs started on this node.
+type tableMaintenanceJobs struct {
+	sync.Mutex
+	jobs map[string]*tableMaintenanceJob
+}
+
+var globalTableMaintenanceJobs = &tableMaintenanceJobs{jobs: make(map[string]*tableMaintenanceJob)}
+
+// start starts removing the orphaned files of a table in background.
+func (t *tableMaintenanceJobs) start(objAPI ObjectLayer, opts TableMaintenanceOpts) TableMaintenanceStatus {
+	job := &tableMaintenanceJob{
+		status: TableMaintenanceStatus{
+			ID:      mustGetUUID(),
+			Opts:    opts,
+			Node:    globalLocalNodeName,
+			State:   TableMaintenanceRunning,
+			Started: UTCNow(),
+		},
+	}
+	t.Lock()
+	t.jobs[job.status.ID] = job
+	for len(t.jobs) > maxTableMaintenanceJobs {
+		var oldest *tableMaintenanceJob
+		for _, j := range t.jobs {
+			s := j.getStatus()
+			if s.State != TableMaintenanceRunning && (oldest == nil || s.Started.Before(oldest.getStatus().Started)) {
+				oldest = j
+			}
+		}
+		if oldest == nil {
+			break
+		}
+		delete(t.jobs, oldest.getStatus().ID)
+	}
+	t.Unlock()
+
+	go job.run(GlobalContext, objAPI)
+	return job.getStatus()
+}
+
+// list returns the status of all jobs, most recent first.
+func (t *tableMaintenanceJobs) list() []TableMaintenanceStatus {
+	t.Lock()
+	statuses := make([]TableMaintenanceStatus, 0, len(t.jobs))
+	for _, job := range t.jobs {
+		statuses = append(statuses, job.getStatus())
+	}
+	t.Unlock()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Started.After(statuses[j].Started)
+	})
+	return statuses
+}
+
+func (t *tableMaintenanceJobs) get(id string) (TableMaintenanceStatus, error) {
+	t.Lock()
+	job, ok := t.jobs[id]
+	t.Unlock()
+	if !ok {
+		return TableMaintenanceStatus{}, errNoSuchTableMaintenanceJob
+	}
+	return job.getStatus(), nil
+}
+
+func tableMaintenanceReportFile(id string) string {
+	return path.Join(tableMaintenanceReportsPrefix, id+".json")
+}
+
+// readTableMaintenanceReport returns the report of a finished job.
+func readTableMaintenanceReport(ctx context.Context, objAPI ObjectLayer, id string) ([]byte, error) {
+	data, err := readConfig(ctx, objAPI, tableMaintenanceReportFile(id))
+	if errors.Is(err, errConfigNotFound) {
+		return nil, errNoSuchTableMaintenanceJob
+	}
+	return data, err
+}
+
+// findTableOrphans returns the objects of the table older than the
+// threshold and referenced by none of the table files. Fails if another
+// table is nested under the table.
+func findTableOrphans(ctx context.Context, objAPI ObjectLayer, opts TableMaintenanceOpts, files tableFiles, now time.Time) ([]ObjectInfo, uint64, error) {
+	var orphans []ObjectInfo
+	var objects uint64
+	err := listTableObjects(ctx, objAPI, opts.Bucket, opts.Prefix, func(oi ObjectInfo) error {
+		objects++
+		name := strings.TrimPrefix(oi.Name, opts.Prefix)
+		if nestedTable(name) {
+			return fmt.Errorf("another table is nested under the prefix at %s", oi.Name)
+		}
+		if _, ok := files.referenced[oi.Name]; ok || protectedTableFile(name) || oi.DeleteMarker {
+			return nil
+		}
+		if oi.ModTime.After(now.Add(-opts.OlderThan)) {
+			return nil
+		}
+		orphans = append(orphans, oi)
+		return nil
+	})
+	return orphans, objects, err
+}
+
+func (j *tableMaintenanceJob) run(ctx context.Context, objAPI ObjectLayer) {
+	opts := j.status.Opts
+	err := func() error {
+		format := opts.Format
+		if format == "" {
+			var err error
+			if format, err = detectTableFormat(ctx, objAPI, opts.Bucket, opts.Prefix); err != nil {
+				return err
+			}
+		}
+
+		// Orphans are deleted once the table was read twice without a
+		// commit in between, a commit could reference old files.
+		var files tableFiles
+		var orphans []ObjectInfo
+		for attempt := 0; ; attempt++ {
+			if attempt == tableMaintenanceAttempts {
+				return errors.New("the table kept being committed to while it was read, please try again later")
+			}
+			now := UTCNow()
+			var err error
+			if files, err = loadTableFiles(ctx, objAPI, opts, format, now); err != nil {
+				return err
+			}
+			var objects uint64
+			if orphans, objects, err = findTableOrphans(ctx, objAPI, opts, files, now); err != nil {
+				return err
+			}
+			metadata, err := tableMetadataFiles(ctx, objAPI, opts.Bucket, opts.Prefix, format)
+			if err != nil {
+				return err
+			}
+			j.mu.Lock()
+			j.status.Format, j.status.Versions, j.status.Referenced, j.status.Objects = format, files.versions, len(files.referenced), objects
+			j.mu.Unlock()
+			if sameTableMetadata(files.metadata, metadata) {
+				break
+			}
+		}
+
+		for _, oi := range orphans {
+			orphan := TableOrphanFile{Object: oi.Name, Size: oi.Size, LastModified: oi.ModTime}
+			if !opts.DryRun {
+				if err := deleteTableOrphan(ctx, objAPI, oi); err != nil {
+					orphan.Error = err.Error()
+				} else {
+					orphan.Deleted = true
+				}
+			}
+			j.mu.Lock()
+			j.status.Orphans++
+			j.status.OrphanBytes += uint64(oi.Size)
+			if orphan.Deleted {
+				j.status.Deleted++
+			}
+			j.orphans = append(j.orphans, orphan)
+			j.mu.Unlock()
+		}
+		return nil
+	}()
+
+	j.mu.Lock()
+	report := TableMaintenanceReport{TableMaintenanceStatus: j.status, Orphans: j.orphans}
+	j.mu.Unlock()
+
+	report.Finished = UTCNow()
+	report.State = TableMaintenanceCompleted
+	if err != nil {
+		report.State = TableMaintenanceFailed
+		report.Error = err.Error()
+	}
+	if len(report.Orphans) > maxTableMaintenanceResults {
+		report.Orphans, report.Truncated = report.Orphans[:maxTableMaintenanceResults], true
+	}
+	if report.Orphans == nil {
+		report.Orphans = []TableOrphanFile{}
+	}
+	if serr := saveTableMaintenanceReport(ctx, objAPI, report); serr != nil {
+		logger.LogIf(ctx, fmt.Errorf("unable to save table maintenance report %s: %w", report.ID, serr))
+		if err == nil {
+			report.State = TableMaintenanceFailed
+			report.Error = serr.Error()
+		}
+	}
+
+	// Only report the job as finished once its report can be read.
+	j.mu.Lock()
+	j.status = report.TableMaintenanceStatus
+	j.orphans = nil
+	j.mu.Unlock()
+}
+
+// deleteTableOrphan deletes an orphaned file, leaving a delete marker in
+// versioned buckets.
+func deleteTableOrphan(ctx context.Context, objAPI ObjectLayer, oi ObjectInfo) error {
+	obj, err := objAPI.DeleteObject(ctx, oi.Bucket, oi.Name, ObjectOptions{
+		Versioned:        globalBucketVersioningSys.Enabled(oi.Bucket),
+		VersionSuspended: globalBucketVersioningSys.Suspended(oi.Bucket),
+	})
+	if err != nil {
+		if isErrObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	eventName := event.ObjectRemovedDelete
+	if obj.DeleteMarker {
+		eventName = event.ObjectRemovedDeleteMarkerCreated
+	}
+	sendEvent(eventArgs{
+		EventName:  eventName,
+		BucketName: oi.Bucket,
+		Object:     obj,
+		Host:       "Internal: [TABLE-MAINTENANCE]",
+	})
+	return nil
+}
+
+func saveTableMaintenanceReport(ctx context.Context, objAPI ObjectLayer, report TableMaintenanceReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, tableMaintenanceReportFile(report.ID), data)
+}
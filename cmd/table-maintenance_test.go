@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "testing"
+
+func TestTableObjectKey(t *testing.T) {
+	testCases := []struct {
+		path string
+		key  string
+		ok   bool
+	}{
+		{"s3://warehouse/db/t/data/a.parquet", "db/t/data/a.parquet", true},
+		{"s3a://warehouse/db/t/metadata/snap-1.avro", "db/t/metadata/snap-1.avro", true},
+		{"s3://warehouse/db/t/data/ts_hour=2021-11-02-10%3A00/a.parquet", "db/t/data/ts_hour=2021-11-02-10%3A00/a.parquet", true},
+		{"s3://warehouse/db/t/data/tag=a#b/a.parquet", "db/t/data/tag=a#b/a.parquet", true},
+		{"s3://warehouse/db/t/data/q=a?b/a.parquet", "db/t/data/q=a?b/a.parquet", true},
+		{"s3://other/db/t/data/a.parquet", "", false},
+		{"s3://warehouse.other/db/t/data/a.parquet", "", false},
+		{"s3://warehouse", "", false},
+		{"hdfs://warehouse/db/t/data/a.parquet", "", false},
+		{"db/t/data/a.parquet", "", false},
+	}
+	for _, tc := range testCases {
+		key, ok := tableObjectKey("warehouse", tc.path)
+		if key != tc.key || ok != tc.ok {
+			t.Errorf("%s: expected %q %v, got %q %v", tc.path, tc.key, tc.ok, key, ok)
+		}
+	}
+
+	for p, want := range map[string]string{
+		"part-00000.snappy.parquet":          "db/t/part-00000.snappy.parquet",
+		"date=2021-11-02/part%20000.parquet": "db/t/date=2021-11-02/part 000.parquet",
+		"s3a://warehouse/db/t/x.parquet":     "db/t/x.parquet",
+		"s3a://warehouse/db/t/a%3Ab.parquet": "db/t/a:b.parquet",
+	} {
+		if key, ok := deltaObjectKey("warehouse", "db/t/", p); !ok || key != want {
+			t.Errorf("%s: expected %q, got %q %v", p, want, key, ok)
+		}
+	}
+}
+
+func TestProtectedTableFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"data/00000-1-a.parquet":                    false,
+		"metadata/snap-1-1-a.avro":                  false,
+		"metadata/00001-a.metadata.json":            true,
+		"metadata/version-hint.text":                true,
+		"_delta_log/00000000000000000000.json":      true,
+		"_change_data/cdc-00000.parquet":            true,
+		"date=2021-11-02/.part-0.parquet.crc":       true,
+		"deletion_vector_0a1b2c.bin":                true,
+		"date=2021-11-02/part-00000.snappy.parquet": false,
+	} {
+		if got := protectedTableFile(name); got != want {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestNestedTable(t *testing.T) {
+	for name, want := range map[string]bool{
+		"metadata/00001-a.metadata.json":             false,
+		"_delta_log/00000000000000000000.json":       false,
+		"data/a.parquet":                             false,
+		"other/metadata/00001-a.metadata.json":       true,
+		"other/_delta_log/00000000000000000000.json": true,
+		"data/metadata/a.parquet":                    false,
+	} {
+		if got := nestedTable(name); got != want {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
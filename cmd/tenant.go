@@ -0,0 +1,489 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/wildcard"
+	"golang.org/x/time/rate"
+)
+
+const (
+	tenantsConfigFile = "tenants.json"
+
+	tenantsFormatVersion1 = 1
+)
+
+var (
+	errNoSuchTenant      = errors.New("The specified tenant does not exist")
+	errInvalidTenantName = errors.New("Tenant names must be non-empty and shorter than 64 characters")
+	errTenantNoBuckets   = errors.New("A tenant needs at least one bucket name or pattern")
+	errInvalidTenantRate = errors.New("Tenant request rate must not be negative")
+	errUnknownDomain     = errors.New("Tenant domains must be one of the configured MINIO_DOMAIN values")
+)
+
+// Tenant groups buckets and users sharing aggregate resource budgets.
+// Users of a tenant can only access the buckets of their tenant, buckets
+// of a tenant are only accessible by its users and the cluster owner.
+type Tenant struct {
+	Name string `json:"name"`
+	// Bucket names or patterns, like "teama-*".
+	Buckets []string `json:"buckets"`
+	// Access keys of the users, service accounts and temporary
+	// credentials of these users belong to the tenant too.
+	Users []string `json:"users,omitempty"`
+	// Aggregate size of the buckets in bytes, zero is unlimited.
+	Quota uint64 `json:"quota,omitempty"`
+	// S3 requests per second on the buckets, per node, zero is unlimited.
+	RequestsPerSecond int `json:"requestsPerSecond,omitempty"`
+	// Domains of MINIO_DOMAIN dedicated to the tenant, requests to these
+	// hosts can only address the buckets of the tenant.
+	Domains []string `json:"domains,omitempty"`
+}
+
+func (t Tenant) validate() error {
+	if t.Name == "" || len(t.Name) > 63 {
+		return errInvalidTenantName
+	}
+	if len(t.Buckets) == 0 {
+		return errTenantNoBuckets
+	}
+	if t.RequestsPerSecond < 0 {
+		return errInvalidTenantRate
+	}
+	for _, domain := range t.Domains {
+		found := false
+		for _, domainName := range globalDomainNames {
+			if domain == domainName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errUnknownDomain
+		}
+	}
+	return nil
+}
+
+// TenantUsage - usage of the buckets of a tenant, as of the last scanner cycle.
+type TenantUsage struct {
+	Tenant       Tenant                     `json:"tenant"`
+	Size         uint64                     `json:"size"`
+	ObjectsCount uint64                     `json:"objectsCount"`
+	Buckets      map[string]BucketUsageInfo `json:"buckets"`
+}
+
+type tenantsConfig struct {
+	Version int               `json:"version"`
+	Tenants map[string]Tenant `json:"tenants"`
+}
+
+// tenantPattern - a bucket pattern of a tenant.
+type tenantPattern struct {
+	pattern string
+	tenant  string
+}
+
+// TenantSys holds the tenants of the cluster and their request budgets.
+type TenantSys struct {
+	sync.RWMutex
+	tenants  map[string]Tenant
+	users    map[string]string // user => tenant
+	domains  map[string]string // domain => tenant
+	buckets  map[string]string // bucket => tenant
+	patterns []tenantPattern   // sorted by tenant
+	limiters map[string]*rate.Limiter
+}
+
+// NewTenantSys - creates a new tenant system.
+func NewTenantSys() *TenantSys {
+	return &TenantSys{
+		tenants:  make(map[string]Tenant),
+		users:    make(map[string]string),
+		domains:  make(map[string]string),
+		buckets:  make(map[string]string),
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+func getTenantsConfigPath() string {
+	return pathJoin(minioConfigPrefix, tenantsConfigFile)
+}
+
+// Init - loads the tenants from the backend.
+func (sys *TenantSys) Init(ctx context.Context, objAPI ObjectLayer) error {
+	buf, err := readConfig(ctx, objAPI, getTenantsConfigPath())
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			sys.set(nil)
+			return nil
+		}
+		return err
+	}
+	var cfg tenantsConfig
+	if err = json.Unmarshal(buf, &cfg); err != nil {
+		return err
+	}
+	if cfg.Version != tenantsFormatVersion1 {
+		return fmt.Errorf("Unexpected tenants config version: %d", cfg.Version)
+	}
+	sys.set(cfg.Tenants)
+	return nil
+}
+
+func (sys *TenantSys) set(tenants map[string]Tenant) {
+	if tenants == nil {
+		tenants = make(map[string]Tenant)
+	}
+
+	sys.Lock()
+	defer sys.Unlock()
+	users := make(map[string]string)
+	domains := make(map[string]string)
+	buckets := make(map[string]string)
+	var patterns []tenantPattern
+	limiters := make(map[string]*rate.Limiter)
+	for name, t := range tenants {
+		for _, user := range t.Users {
+			users[user] = name
+		}
+		for _, domain := range t.Domains {
+			if other, ok := domains[domain]; !ok || name < other {
+				domains[domain] = name
+			}
+		}
+		for _, bucket := range t.Buckets {
+			if strings.ContainsAny(bucket, "*?") {
+				patterns = append(patterns, tenantPattern{pattern: bucket, tenant: name})
+				continue
+			}
+			if other, ok := buckets[bucket]; !ok || name < other {
+				buckets[bucket] = name
+			}
+		}
+		if t.RequestsPerSecond > 0 {
+			// Keep the current budget of unchanged tenants.
+			if l, ok := sys.limiters[name]; ok && l.Limit() == rate.Limit(t.RequestsPerSecond) {
+				limiters[name] = l
+				continue
+			}
+			limiters[name] = rate.NewLimiter(rate.Limit(t.RequestsPerSecond), t.RequestsPerSecond)
+		}
+	}
+	sys.tenants = tenants
+	sys.users = users
+	sys.domains = domains
+	sys.buckets = buckets
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].tenant < patterns[j].tenant
+	})
+	sys.patterns = patterns
+	sys.limiters = limiters
+}
+
+// save persists tenants and tells all peers to reload them.
+func (sys *TenantSys) save(ctx context.Context, objAPI ObjectLayer, tenants map[string]Tenant) error {
+	buf, err := json.Marshal(tenantsConfig{
+		Version: tenantsFormatVersion1,
+		Tenants: tenants,
+	})
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, getTenantsConfigPath(), buf); err != nil {
+		return err
+	}
+	sys.set(tenants)
+	for _, nerr := range globalNotificationSys.LoadTenants(ctx) {
+		if nerr.Err != nil {
+			logger.GetReqInfo(ctx).SetTags("peerAddress", nerr.Host.String())
+			logger.LogIf(ctx, nerr.Err)
+		}
+	}
+	return nil
+}
+
+// copy returns a copy of the tenants to modify.
+func (sys *TenantSys) copy() map[string]Tenant {
+	sys.RLock()
+	defer sys.RUnlock()
+	tenants := make(map[string]Tenant, len(sys.tenants))
+	for name, t := range sys.tenants {
+		tenants[name] = t
+	}
+	return tenants
+}
+
+// SetTenant - creates or replaces a tenant. A user or a domain can only
+// belong to one tenant.
+func (sys *TenantSys) SetTenant(ctx context.Context, objAPI ObjectLayer, t Tenant) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	tenants := sys.copy()
+	for name, other := range tenants {
+		if name == t.Name {
+			continue
+		}
+		for _, user := range t.Users {
+			for _, u := range other.Users {
+				if u == user {
+					return fmt.Errorf("User %s already belongs to tenant %s", user, name)
+				}
+			}
+		}
+		for _, domain := range t.Domains {
+			for _, d := range other.Domains {
+				if d == domain {
+					return fmt.Errorf("Domain %s already belongs to tenant %s", domain, name)
+				}
+			}
+		}
+	}
+	tenants[t.Name] = t
+	return sys.save(ctx, objAPI, tenants)
+}
+
+// RemoveTenant - removes a tenant, its buckets and users are left alone.
+func (sys *TenantSys) RemoveTenant(ctx context.Context, objAPI ObjectLayer, name string) error {
+	tenants := sys.copy()
+	if _, ok := tenants[name]; !ok {
+		return errNoSuchTenant
+	}
+	delete(tenants, name)
+	return sys.save(ctx, objAPI, tenants)
+}
+
+// GetTenant - returns a tenant by name.
+func (sys *TenantSys) GetTenant(name string) (Tenant, error) {
+	sys.RLock()
+	defer sys.RUnlock()
+	t, ok := sys.tenants[name]
+	if !ok {
+		return Tenant{}, errNoSuchTenant
+	}
+	return t, nil
+}
+
+// ListTenants - returns all tenants sorted by name.
+func (sys *TenantSys) ListTenants() []Tenant {
+	sys.RLock()
+	defer sys.RUnlock()
+	tenants := make([]Tenant, 0, len(sys.tenants))
+	for _, t := range sys.tenants {
+		tenants = append(tenants, t)
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].Name < tenants[j].Name
+	})
+	return tenants
+}
+
+// bucketTenantName returns the name of the tenant owning bucket. When
+// names or patterns of several tenants match, the tenant with the
+// smallest name wins. The caller must hold the lock.
+func (sys *TenantSys) bucketTenantName(bucket string) (string, bool) {
+	name, ok := sys.buckets[bucket]
+	for _, p := range sys.patterns {
+		if ok && p.tenant >= name {
+			break
+		}
+		if wildcard.Match(p.pattern, bucket) {
+			return p.tenant, true
+		}
+	}
+	return name, ok
+}
+
+// bucketTenant returns the tenant owning bucket.
+func (sys *TenantSys) bucketTenant(bucket string) (Tenant, bool) {
+	sys.RLock()
+	defer sys.RUnlock()
+	name, ok := sys.bucketTenantName(bucket)
+	if !ok {
+		return Tenant{}, false
+	}
+	return sys.tenants[name], true
+}
+
+// allowsBucket returns whether user may access bucket, buckets and users
+// of a tenant are isolated from the rest of the cluster.
+func (sys *TenantSys) allowsBucket(user, bucket string) bool {
+	if sys == nil {
+		return true
+	}
+	if bucket == "" {
+		return true
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	name, _ := sys.bucketTenantName(bucket)
+	return name == sys.users[user]
+}
+
+// hostTenant returns the name of the tenant of the domain of host, the
+// domain itself for path style requests or a parent of the bucket for
+// virtual host style requests.
+func (sys *TenantSys) hostTenant(host string) (string, bool) {
+	if sys == nil {
+		return "", false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	// Of nested domains the longest one matching host wins.
+	var match, tenant string
+	for domain, name := range sys.domains {
+		if len(domain) > len(match) && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			match, tenant = domain, name
+		}
+	}
+	return tenant, match != ""
+}
+
+// allowsHostBucket returns whether bucket may be addressed through host,
+// the domains of a tenant only serve the buckets of the tenant.
+func (sys *TenantSys) allowsHostBucket(host, bucket string) bool {
+	name, ok := sys.hostTenant(host)
+	if !ok || bucket == "" {
+		return true
+	}
+	sys.RLock()
+	defer sys.RUnlock()
+	owner, ok := sys.bucketTenantName(bucket)
+	return ok && owner == name
+}
+
+// filterHostBuckets returns the buckets which may be addressed through
+// host, in place.
+func (sys *TenantSys) filterHostBuckets(host string, buckets []BucketInfo) []BucketInfo {
+	if _, ok := sys.hostTenant(host); !ok {
+		return buckets
+	}
+	n := 0
+	for _, bucket := range buckets {
+		if sys.allowsHostBucket(host, bucket.Name) {
+			buckets[n] = bucket
+			n++
+		}
+	}
+	return buckets[:n]
+}
+
+// filterBuckets returns the buckets user may access, in place.
+func (sys *TenantSys) filterBuckets(user string, buckets []BucketInfo) []BucketInfo {
+	n := 0
+	for _, bucket := range buckets {
+		if sys.allowsBucket(user, bucket.Name) {
+			buckets[n] = bucket
+			n++
+		}
+	}
+	return buckets[:n]
+}
+
+// allowRequest consumes a request of the budget of the tenant of bucket.
+func (sys *TenantSys) allowRequest(bucket string) bool {
+	if sys == nil || bucket == "" {
+		return true
+	}
+	sys.RLock()
+	name, _ := sys.bucketTenantName(bucket)
+	l := sys.limiters[name]
+	sys.RUnlock()
+	return l == nil || l.Allow()
+}
+
+type tenantRequestKey struct{}
+
+// tenantRequest - the budget charge of a request, a request is charged
+// once however many of its actions are authorized.
+type tenantRequest struct {
+	once    sync.Once
+	bucket  string
+	allowed bool
+}
+
+// withTenantRequest returns r to be charged to the budget of the tenant
+// of bucket once it is authorized.
+func withTenantRequest(r *http.Request, bucket string) *http.Request {
+	if bucket == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), tenantRequestKey{}, &tenantRequest{bucket: bucket}))
+}
+
+// chargeRequest consumes a request of the budget of the tenant of the
+// bucket of the authorized request of ctx. Requests failing
+// authentication or authorization do not use up the budget of a tenant.
+func (sys *TenantSys) chargeRequest(ctx context.Context) bool {
+	tr, ok := ctx.Value(tenantRequestKey{}).(*tenantRequest)
+	if !ok {
+		return true
+	}
+	tr.once.Do(func() {
+		tr.allowed = sys.allowRequest(tr.bucket)
+	})
+	return tr.allowed
+}
+
+// usage sums the usage of the buckets of tenant.
+func (sys *TenantSys) usage(t Tenant, dui DataUsageInfo) TenantUsage {
+	tu := TenantUsage{
+		Tenant:  t,
+		Buckets: make(map[string]BucketUsageInfo),
+	}
+	for bucket, bui := range dui.BucketsUsage {
+		if bt, ok := sys.bucketTenant(bucket); !ok || bt.Name != t.Name {
+			continue
+		}
+		tu.Size += bui.Size
+		tu.ObjectsCount += bui.ObjectsCount
+		tu.Buckets[bucket] = bui
+	}
+	return tu
+}
+
+// checkQuota returns an error if writing size bytes to bucket exceeds
+// the capacity of its tenant.
+func (sys *TenantSys) checkQuota(bucket string, size int64, dui DataUsageInfo) error {
+	if sys == nil {
+		return nil
+	}
+	t, ok := sys.bucketTenant(bucket)
+	if !ok || t.Quota == 0 {
+		return nil
+	}
+	if sys.usage(t, dui).Size+uint64(size) >= t.Quota {
+		return TenantQuotaExceeded{Tenant: t.Name}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	sys := NewTenantSys()
+	sys.set(map[string]Tenant{
+		"teama": {Name: "teama", Buckets: []string{"teama-*"}, Users: []string{"alice"}, Quota: 100},
+		"teamb": {Name: "teamb", Buckets: []string{"shared", "teamb"}, Users: []string{"bob"}, RequestsPerSecond: 1},
+	})
+
+	testCases := []struct {
+		user, bucket string
+		allowed      bool
+	}{
+		{"alice", "teama-logs", true},
+		{"alice", "teamb", false},
+		{"alice", "other", false},
+		{"bob", "shared", true},
+		{"bob", "teama-logs", false},
+		{"carol", "other", true},
+		{"carol", "teama-logs", false},
+		{"alice", "", true},
+	}
+	for i, tc := range testCases {
+		if got := sys.allowsBucket(tc.user, tc.bucket); got != tc.allowed {
+			t.Errorf("Test %d: expected %s allowed on %q to be %v", i+1, tc.user, tc.bucket, tc.allowed)
+		}
+	}
+
+	if !sys.allowRequest("teamb") || sys.allowRequest("shared") {
+		t.Error("expected the second request within a second to exceed the budget of teamb")
+	}
+	if !sys.allowRequest("teama-logs") || !sys.allowRequest("other") {
+		t.Error("expected requests without a budget to be allowed")
+	}
+
+	dui := DataUsageInfo{BucketsUsage: map[string]BucketUsageInfo{
+		"teama-logs": {Size: 60, ObjectsCount: 2},
+		"teama-data": {Size: 30, ObjectsCount: 1},
+		"other":      {Size: 1000, ObjectsCount: 10},
+	}}
+	ta, _ := sys.GetTenant("teama")
+	if u := sys.usage(ta, dui); u.Size != 90 || u.ObjectsCount != 3 || len(u.Buckets) != 2 {
+		t.Errorf("unexpected tenant usage %#v", u)
+	}
+	if err := sys.checkQuota("teama-data", 5, dui); err != nil {
+		t.Errorf("expected write within tenant quota to succeed, got %v", err)
+	}
+	if _, ok := sys.checkQuota("teama-data", 10, dui).(TenantQuotaExceeded); !ok {
+		t.Error("expected write beyond tenant quota to fail")
+	}
+	if err := sys.checkQuota("other", 1<<30, dui); err != nil {
+		t.Errorf("expected buckets without tenant to be unlimited, got %v", err)
+	}
+}
+
+func TestTenantRequestCharge(t *testing.T) {
+	sys := NewTenantSys()
+	sys.set(map[string]Tenant{
+		"teama": {Name: "teama", Buckets: []string{"shared", "teama-*"}, RequestsPerSecond: 1},
+		"teamb": {Name: "teamb", Buckets: []string{"shared*", "teamb"}},
+		"teamc": {Name: "teamc", Buckets: []string{"teama-*", "teamc"}},
+	})
+
+	// The smallest tenant name wins over names and patterns alike.
+	for bucket, want := range map[string]string{
+		"shared":     "teama",
+		"shared-2":   "teamb",
+		"teama-logs": "teama",
+		"teamc":      "teamc",
+	} {
+		if tn, ok := sys.bucketTenant(bucket); !ok || tn.Name != want {
+			t.Errorf("%s: expected tenant %s, got %s %v", bucket, want, tn.Name, ok)
+		}
+	}
+	if _, ok := sys.bucketTenant("other"); ok {
+		t.Error("expected no tenant of other")
+	}
+
+	// A request is charged once however often it is authorized.
+	r := withTenantRequest(httptest.NewRequest(http.MethodGet, "/shared/object", nil), "shared")
+	for i := 0; i < 3; i++ {
+		if !sys.chargeRequest(r.Context()) {
+			t.Fatalf("Test %d: expected the first request to be within the budget", i+1)
+		}
+	}
+	r = withTenantRequest(httptest.NewRequest(http.MethodGet, "/shared/object", nil), "shared")
+	if sys.chargeRequest(r.Context()) {
+		t.Error("expected the second request within a second to exceed the budget of teama")
+	}
+	if !sys.chargeRequest(context.Background()) {
+		t.Error("expected requests outside of the S3 API to be allowed")
+	}
+}
+
+func TestTenantDomains(t *testing.T) {
+	sys := NewTenantSys()
+	sys.set(map[string]Tenant{
+		"teama": {Name: "teama", Buckets: []string{"teama-*"}, Domains: []string{"teama.example.com"}},
+		"teamb": {Name: "teamb", Buckets: []string{"teamb"}},
+	})
+
+	testCases := []struct {
+		host, bucket string
+		allowed      bool
+	}{
+		{"teama-logs.teama.example.com", "teama-logs", true},
+		{"teama.example.com:9000", "teama-logs", true},
+		{"teamb.teama.example.com", "teamb", false},
+		{"teama.example.com", "other", false},
+		{"teama.example.com", "", true},
+		{"teamb.example.com", "teamb", true},
+		{"localhost:9000", "other", true},
+	}
+	for i, tc := range testCases {
+		if got := sys.allowsHostBucket(tc.host, tc.bucket); got != tc.allowed {
+			t.Errorf("Test %d: expected %q allowed on %s to be %v", i+1, tc.bucket, tc.host, tc.allowed)
+		}
+	}
+
+	buckets := []BucketInfo{{Name: "teama-logs"}, {Name: "teamb"}, {Name: "other"}}
+	if got := sys.filterHostBuckets("teama.example.com", buckets); len(got) != 1 || got[0].Name != "teama-logs" {
+		t.Errorf("expected only the buckets of teama, got %v", got)
+	}
+	if got := sys.filterHostBuckets("example.com", []BucketInfo{{Name: "teamb"}, {Name: "other"}}); len(got) != 2 {
+		t.Errorf("expected all buckets outside tenant domains, got %v", got)
+	}
+
+	// Nested domains resolve to the tenant of the longest match.
+	sys.set(map[string]Tenant{
+		"teama": {Name: "teama", Buckets: []string{"teama-*"}, Domains: []string{"example.com"}},
+		"teamb": {Name: "teamb", Buckets: []string{"teamb"}, Domains: []string{"teamb.example.com", "eu.teamb.example.com"}},
+	})
+	for host, want := range map[string]string{
+		"teama-logs.example.com":     "teama",
+		"teamb.example.com":          "teamb",
+		"teamb.teamb.example.com":    "teamb",
+		"teamb.eu.teamb.example.com": "teamb",
+		"example.com:9000":           "teama",
+	} {
+		for i := 0; i < 10; i++ {
+			if name, ok := sys.hostTenant(host); !ok || name != want {
+				t.Fatalf("%s: expected tenant %s, got %q %v", host, want, name, ok)
+			}
+		}
+	}
+
+	oldDomains := globalDomainNames
+	defer func() { globalDomainNames = oldDomains }()
+	globalDomainNames = []string{"teama.example.com"}
+	if err := (Tenant{Name: "teamc", Buckets: []string{"teamc"}, Domains: []string{"teamc.example.com"}}).validate(); err != errUnknownDomain {
+		t.Errorf("expected %v, got %v", errUnknownDomain, err)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/minio/madmin-go"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+// Query parameters of the trace filters, the same for admin and peer
+// trace requests.
+const (
+	traceFilterBucket    = "bucket"
+	traceFilterAccessKey = "accesskey"
+	traceFilterStatus    = "status"
+	traceFilterSample    = "sample"
+)
+
+// traceOptions are the options of a trace, with the server side
+// filters on top of the madmin trace options. The filters are applied
+// by every node to its own entries, before they are sent.
+type traceOptions struct {
+	madmin.ServiceTraceOpts
+
+	// Bucket only traces the requests and drive calls of the bucket.
+	Bucket string
+	// AccessKey only traces the requests signed by the access key.
+	AccessKey string
+	// StatusClasses only traces the requests answered with one of the
+	// status classes, 4 for 4xx.
+	StatusClasses []int
+	// SampleRatio is the fraction of the matching entries traced, 0
+	// traces all of them.
+	SampleRatio float64
+}
+
+// parseTraceFilters parses the trace filters of form into opts.
+func parseTraceFilters(form url.Values, opts *traceOptions) error {
+	opts.Bucket = form.Get(traceFilterBucket)
+	opts.AccessKey = form.Get(traceFilterAccessKey)
+	if v := form.Get(traceFilterStatus); v != "" {
+		for _, class := range strings.Split(v, ",") {
+			class = strings.ToLower(strings.TrimSpace(class))
+			if len(class) != 3 || !strings.HasSuffix(class, "xx") || class[0] < '1' || class[0] > '5' {
+				return errors.New("invalid trace status class " + class + ", must be one of 1xx to 5xx")
+			}
+			opts.StatusClasses = append(opts.StatusClasses, int(class[0]-'0'))
+		}
+	}
+	if v := form.Get(traceFilterSample); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		if ratio <= 0 || ratio > 1 {
+			return errors.New("invalid trace sample ratio, must be above 0 and at most 1")
+		}
+		opts.SampleRatio = ratio
+	}
+	return nil
+}
+
+// setTraceFilters sets the trace filters of opts in values.
+func setTraceFilters(values url.Values, opts traceOptions) {
+	if opts.Bucket != "" {
+		values.Set(traceFilterBucket, opts.Bucket)
+	}
+	if opts.AccessKey != "" {
+		values.Set(traceFilterAccessKey, opts.AccessKey)
+	}
+	if len(opts.StatusClasses) > 0 {
+		classes := make([]string, 0, len(opts.StatusClasses))
+		for _, class := range opts.StatusClasses {
+			classes = append(classes, strconv.Itoa(class)+"xx")
+		}
+		values.Set(traceFilterStatus, strings.Join(classes, ","))
+	}
+	if opts.SampleRatio > 0 {
+		values.Set(traceFilterSample, strconv.FormatFloat(opts.SampleRatio, 'f', -1, 64))
+	}
+}
+
+// matchTraceFilters returns whether trcInfo passes the filters of opts
+// and its sampling. Entries which cannot be attributed, such as OS
+// calls for the bucket filter, are left out by the filters.
+func matchTraceFilters(trcInfo madmin.TraceInfo, opts traceOptions) bool {
+	if opts.Bucket != "" && traceBucket(trcInfo) != opts.Bucket {
+		return false
+	}
+	if opts.AccessKey != "" && (trcInfo.TraceType != madmin.TraceHTTP || traceAccessKey(trcInfo.ReqInfo) != opts.AccessKey) {
+		return false
+	}
+	if len(opts.StatusClasses) > 0 {
+		if trcInfo.TraceType != madmin.TraceHTTP {
+			return false
+		}
+		class := trcInfo.RespInfo.StatusCode / 100
+		var found bool
+		for _, c := range opts.StatusClasses {
+			if c == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return opts.SampleRatio <= 0 || opts.SampleRatio >= 1 || rand.Float64() < opts.SampleRatio
+}
+
+// traceBucket returns the bucket of an S3 request or a drive call,
+// empty for other entries.
+func traceBucket(trcInfo madmin.TraceInfo) string {
+	switch trcInfo.TraceType {
+	case madmin.TraceStorage:
+		// The paths of a drive call start with its volume.
+		volume := strings.SplitN(trcInfo.StorageStats.Path, " ", 2)[0]
+		if isMinioMetaBucketName(volume) {
+			return ""
+		}
+		return volume
+	case madmin.TraceHTTP:
+		if HasPrefix(trcInfo.ReqInfo.Path, minioReservedBucketPath+SlashSeparator) {
+			return ""
+		}
+		host := trcInfo.ReqInfo.Headers.Get("Host")
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		for _, domain := range globalDomainNames {
+			if strings.HasSuffix(host, "."+domain) {
+				return strings.TrimSuffix(host, "."+domain)
+			}
+		}
+		path, err := url.PathUnescape(trcInfo.ReqInfo.Path)
+		if err != nil {
+			return ""
+		}
+		bucket, _ := path2BucketObject(path)
+		return bucket
+	}
+	return ""
+}
+
+// traceAccessKey returns the access key signing the request of reqInfo,
+// by its authorization header or presigned query.
+func traceAccessKey(reqInfo madmin.TraceRequestInfo) string {
+	auth := reqInfo.Headers.Get(xhttp.Authorization)
+	switch {
+	case strings.HasPrefix(auth, signV4Algorithm):
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			return strings.SplitN(auth[i+len("Credential="):], SlashSeparator, 2)[0]
+		}
+	case strings.HasPrefix(auth, signV2Algorithm+" "):
+		return strings.SplitN(strings.TrimPrefix(auth, signV2Algorithm+" "), ":", 2)[0]
+	}
+	query, err := url.ParseQuery(reqInfo.RawQuery)
+	if err != nil {
+		return ""
+	}
+	if cred := query.Get(xhttp.AmzCredential); cred != "" {
+		return strings.SplitN(cred, SlashSeparator, 2)[0]
+	}
+	return query.Get(xhttp.AmzAccessKeyID)
+}
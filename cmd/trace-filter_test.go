@@ -0,0 +1,109 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestTraceFiltersRoundTrip(t *testing.T) {
+	form := url.Values{}
+	form.Set(traceFilterBucket, "photos")
+	form.Set(traceFilterAccessKey, "AKIAEXAMPLE")
+	form.Set(traceFilterStatus, "4xx, 5XX")
+	form.Set(traceFilterSample, "0.25")
+
+	var opts traceOptions
+	if err := parseTraceFilters(form, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opts.StatusClasses, []int{4, 5}) || opts.SampleRatio != 0.25 {
+		t.Fatalf("unexpected trace filters %+v", opts)
+	}
+
+	values := url.Values{}
+	setTraceFilters(values, opts)
+	var peerOpts traceOptions
+	if err := parseTraceFilters(values, &peerOpts); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opts, peerOpts) {
+		t.Fatalf("expected %+v, got %+v", opts, peerOpts)
+	}
+
+	for _, invalid := range []url.Values{
+		{traceFilterStatus: []string{"6xx"}},
+		{traceFilterStatus: []string{"404"}},
+		{traceFilterSample: []string{"0"}},
+		{traceFilterSample: []string{"1.5"}},
+	} {
+		if err := parseTraceFilters(invalid, &traceOptions{}); err == nil {
+			t.Errorf("expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestMatchTraceFilters(t *testing.T) {
+	httpTrace := func(path, auth string, status int) madmin.TraceInfo {
+		headers := http.Header{}
+		headers.Set("Host", "localhost:9000")
+		if auth != "" {
+			headers.Set("Authorization", auth)
+		}
+		return madmin.TraceInfo{
+			TraceType: madmin.TraceHTTP,
+			ReqInfo:   madmin.TraceRequestInfo{Path: path, Headers: headers},
+			RespInfo:  madmin.TraceResponseInfo{StatusCode: status},
+		}
+	}
+	v4Auth := "AWS4-HMAC-SHA256 Credential=AKIAEXAMPLE/20211014/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc"
+
+	testCases := []struct {
+		trcInfo madmin.TraceInfo
+		opts    traceOptions
+		match   bool
+	}{
+		{httpTrace("/photos/a.jpg", v4Auth, 200), traceOptions{Bucket: "photos"}, true},
+		{httpTrace("/videos/a.mp4", v4Auth, 200), traceOptions{Bucket: "photos"}, false},
+		{httpTrace("/minio/storage/v42/readall", "", 200), traceOptions{Bucket: "minio"}, false},
+		{httpTrace("/photos/a.jpg", v4Auth, 200), traceOptions{AccessKey: "AKIAEXAMPLE"}, true},
+		{httpTrace("/photos/a.jpg", "AWS AKIAEXAMPLE:sig", 200), traceOptions{AccessKey: "AKIAEXAMPLE"}, true},
+		{httpTrace("/photos/a.jpg", "AWS OTHER:sig", 200), traceOptions{AccessKey: "AKIAEXAMPLE"}, false},
+		{httpTrace("/photos/a.jpg", "", 503), traceOptions{StatusClasses: []int{5}}, true},
+		{httpTrace("/photos/a.jpg", "", 404), traceOptions{StatusClasses: []int{5}}, false},
+		{
+			madmin.TraceInfo{TraceType: madmin.TraceStorage, StorageStats: madmin.TraceStorageStats{Path: "photos a.jpg/xl.meta"}},
+			traceOptions{Bucket: "photos"}, true,
+		},
+		{
+			madmin.TraceInfo{TraceType: madmin.TraceStorage, StorageStats: madmin.TraceStorageStats{Path: "photos a.jpg/xl.meta"}},
+			traceOptions{StatusClasses: []int{2}}, false,
+		},
+		{httpTrace("/photos/a.jpg", "", 200), traceOptions{SampleRatio: 1}, true},
+	}
+	for i, testCase := range testCases {
+		if match := matchTraceFilters(testCase.trcInfo, testCase.opts); match != testCase.match {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.match, match)
+		}
+	}
+}
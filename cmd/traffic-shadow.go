@@ -0,0 +1,302 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio/internal/config/shadow"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+// shadowRequest is a completed read request that should be replayed
+// against the shadow cluster.
+type shadowRequest struct {
+	api        string
+	bucket     string
+	object     string
+	versionID  string
+	header     http.Header
+	statusCode int
+	etag       string
+	size       int64
+}
+
+// shadowHeaders are the request headers replayed to the shadow
+// cluster, they select the part of the object returned.
+var shadowHeaders = []string{
+	xhttp.Range,
+	xhttp.IfMatch,
+	xhttp.IfNoneMatch,
+	xhttp.IfModifiedSince,
+	xhttp.IfUnmodifiedSince,
+}
+
+// shadowStats counts the outcome of mirrored requests.
+type shadowStats struct {
+	Mirrored   uint64 `json:"mirrored"`
+	Matched    uint64 `json:"matched"`
+	Mismatched uint64 `json:"mismatched"`
+	Failed     uint64 `json:"failed"`
+	Dropped    uint64 `json:"dropped"`
+}
+
+// trafficShadow mirrors a sample of S3 read traffic to a second
+// cluster, asynchronously, and compares response codes and ETags.
+type trafficShadow struct {
+	mu          sync.RWMutex
+	enabled     bool
+	sampleRatio float64
+	client      *minio.Client
+	queue       chan shadowRequest
+	cancel      context.CancelFunc
+
+	stats shadowStats
+}
+
+var globalTrafficShadow = &trafficShadow{}
+
+// shadowWorkers is the number of concurrent requests sent to the shadow cluster.
+const shadowWorkers = 16
+
+// Update applies a new shadowing configuration, restarting workers if needed.
+func (t *trafficShadow) Update(cfg shadow.Config) error {
+	var client *minio.Client
+	if cfg.Enabled {
+		var err error
+		client, err = minio.New(cfg.Endpoint.Host, &minio.Options{
+			Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+			Secure:    cfg.Endpoint.Scheme == "https",
+			Transport: NewRemoteTargetHTTPTransport(),
+		})
+		if err != nil {
+			return fmt.Errorf("Unable to initialize traffic shadowing client: %w", err)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.enabled = cfg.Enabled
+	t.sampleRatio = cfg.SampleRatio
+	t.client = client
+	t.queue = nil
+	if !cfg.Enabled {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	t.cancel = cancel
+	t.queue = make(chan shadowRequest, cfg.QueueSize)
+	for i := 0; i < shadowWorkers; i++ {
+		go t.worker(ctx, client, t.queue)
+	}
+	return nil
+}
+
+// sample returns the queue to use when the current request
+// has been selected for mirroring, nil otherwise.
+func (t *trafficShadow) sample() chan shadowRequest {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if !t.enabled || t.sampleRatio <= 0 {
+		return nil
+	}
+	if t.sampleRatio < 1 && rand.Float64() >= t.sampleRatio {
+		return nil
+	}
+	return t.queue
+}
+
+func (t *trafficShadow) worker(ctx context.Context, client *minio.Client, queue <-chan shadowRequest) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-queue:
+			t.replay(ctx, client, req)
+		}
+	}
+}
+
+// replay sends req to the shadow cluster and compares the outcome.
+func (t *trafficShadow) replay(ctx context.Context, client *minio.Client, req shadowRequest) {
+	atomic.AddUint64(&t.stats.Mirrored, 1)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	var statusCode int
+	var etag string
+	var size int64
+	var err error
+	if req.api == "getobject" {
+		statusCode, etag, size, err = replayGetObject(ctx, client, req)
+	} else {
+		statusCode, etag, err = replayHeadObject(ctx, client, req)
+		size = req.size
+	}
+	if err != nil {
+		statusCode = minio.ToErrorResponse(err).StatusCode
+		if statusCode == 0 {
+			// Network or client side failure, nothing to compare.
+			atomic.AddUint64(&t.stats.Failed, 1)
+			logger.LogOnceIf(ctx, fmt.Errorf("traffic shadowing: unable to reach shadow cluster: %w", err), "traffic-shadow-unreachable")
+			return
+		}
+	}
+
+	success := statusCode == http.StatusOK || statusCode == http.StatusPartialContent
+	if statusCode == req.statusCode && (!success || (etag == req.etag && size == req.size)) {
+		atomic.AddUint64(&t.stats.Matched, 1)
+		return
+	}
+
+	atomic.AddUint64(&t.stats.Mismatched, 1)
+	reqInfo := (&logger.ReqInfo{}).AppendTags("api", req.api)
+	reqInfo.AppendTags("bucket", req.bucket)
+	reqInfo.AppendTags("object", req.object)
+	logger.LogIf(logger.SetReqInfo(ctx, reqInfo),
+		fmt.Errorf("traffic shadowing: mismatch for %s/%s: status %d (shadow %d), etag %q (shadow %q), size %d (shadow %d)",
+			req.bucket, req.object, req.statusCode, statusCode, req.etag, etag, req.size, size))
+}
+
+// replayGetObject reads the same part of the object as req from the
+// shadow cluster, returning the status, ETag and bytes read.
+func replayGetObject(ctx context.Context, client *minio.Client, req shadowRequest) (int, string, int64, error) {
+	opts := minio.GetObjectOptions{VersionID: req.versionID}
+	for k, v := range req.header {
+		opts.Set(k, v[0])
+	}
+	obj, err := client.GetObject(ctx, req.bucket, req.object, opts)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	defer obj.Close()
+
+	n, err := io.Copy(ioutil.Discard, obj)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	oi, err := obj.Stat()
+	if err != nil {
+		return 0, "", 0, err
+	}
+	return shadowSuccessStatus(req), oi.ETag, n, nil
+}
+
+// replayHeadObject sends req as a HEAD to the shadow cluster, returning
+// the status and ETag, the size is not compared.
+func replayHeadObject(ctx context.Context, client *minio.Client, req shadowRequest) (int, string, error) {
+	opts := minio.StatObjectOptions{VersionID: req.versionID}
+	for k, v := range req.header {
+		opts.Set(k, v[0])
+	}
+	oi, err := client.StatObject(ctx, req.bucket, req.object, opts)
+	if err != nil {
+		return 0, "", err
+	}
+	return shadowSuccessStatus(req), oi.ETag, nil
+}
+
+// shadowSuccessStatus returns the status of a successful replay of req.
+func shadowSuccessStatus(req shadowRequest) int {
+	if req.header.Get(xhttp.Range) != "" {
+		return http.StatusPartialContent
+	}
+	return http.StatusOK
+}
+
+// Stats returns a snapshot of the mirrored request outcomes.
+func (t *trafficShadow) Stats() shadowStats {
+	return shadowStats{
+		Mirrored:   atomic.LoadUint64(&t.stats.Mirrored),
+		Matched:    atomic.LoadUint64(&t.stats.Matched),
+		Mismatched: atomic.LoadUint64(&t.stats.Mismatched),
+		Failed:     atomic.LoadUint64(&t.stats.Failed),
+		Dropped:    atomic.LoadUint64(&t.stats.Dropped),
+	}
+}
+
+// shadowTraffic mirrors a sample of successful or not-found read
+// requests to the configured shadow cluster, fire-and-forget.
+func shadowTraffic(api string, f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queue := globalTrafficShadow.sample()
+		if queue == nil {
+			f.ServeHTTP(w, r)
+			return
+		}
+
+		rw := logger.NewResponseWriter(w)
+		f.ServeHTTP(rw, r)
+
+		// Only compare outcomes that are a property of the data,
+		// authorization and throttling failures are local decisions.
+		if rw.StatusCode != http.StatusOK && rw.StatusCode != http.StatusPartialContent &&
+			rw.StatusCode != http.StatusNotModified && rw.StatusCode != http.StatusNotFound {
+			return
+		}
+
+		vars := mux.Vars(r)
+		object, err := unescapePath(vars["object"])
+		if err != nil {
+			return
+		}
+		header := make(http.Header)
+		for _, k := range shadowHeaders {
+			if v := r.Header.Get(k); v != "" {
+				header.Set(k, v)
+			}
+		}
+		size, _ := strconv.ParseInt(rw.Header().Get(xhttp.ContentLength), 10, 64)
+		req := shadowRequest{
+			api:        api,
+			bucket:     vars["bucket"],
+			object:     object,
+			versionID:  r.URL.Query().Get(xhttp.VersionID),
+			header:     header,
+			statusCode: rw.StatusCode,
+			etag:       strings.Trim(rw.Header().Get(xhttp.ETag), `"`),
+			size:       size,
+		}
+		select {
+		case queue <- req:
+		default:
+			atomic.AddUint64(&globalTrafficShadow.stats.Dropped, 1)
+		}
+	}
+}
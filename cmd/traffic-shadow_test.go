@@ -0,0 +1,110 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestReplayShadowedGetObject(t *testing.T) {
+	data := []byte("0123456789")
+	const etag = "e807f1fcf82d132f9bb018ca6738a19f"
+	modTime := time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get(xhttp.Range))
+		mu.Unlock()
+		w.Header().Set(xhttp.ETag, `"`+etag+`"`)
+		http.ServeContent(w, r, "object", modTime, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	client, err := minio.New(strings.TrimPrefix(srv.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("access", "secret", ""),
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		rng    string
+		status int
+		data   []byte
+	}{
+		{"", http.StatusOK, data},
+		{"bytes=2-5", http.StatusPartialContent, data[2:6]},
+		{"bytes=7-", http.StatusPartialContent, data[7:]},
+		{"bytes=-3", http.StatusPartialContent, data[7:]},
+	}
+	for i, tc := range testCases {
+		header := make(http.Header)
+		if tc.rng != "" {
+			header.Set(xhttp.Range, tc.rng)
+		}
+		req := shadowRequest{
+			api:        "getobject",
+			bucket:     "bucket",
+			object:     "object",
+			header:     header,
+			statusCode: tc.status,
+			etag:       etag,
+			size:       int64(len(tc.data)),
+		}
+
+		mu.Lock()
+		ranges = nil
+		mu.Unlock()
+		status, gotETag, n, err := replayGetObject(context.Background(), client, req)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if status != tc.status || gotETag != etag || n != int64(len(tc.data)) {
+			t.Fatalf("Test %d: expected %d %s %d, got %d %s %d", i+1, tc.status, etag, len(tc.data), status, gotETag, n)
+		}
+		mu.Lock()
+		sent := append([]string(nil), ranges...)
+		mu.Unlock()
+		if len(sent) != 1 || sent[0] != tc.rng {
+			t.Fatalf("Test %d: expected range %q to be replayed, got %q", i+1, tc.rng, sent)
+		}
+
+		// The bytes read from both clusters are compared.
+		ts := &trafficShadow{}
+		ts.replay(context.Background(), client, req)
+		req.size++
+		ts.replay(context.Background(), client, req)
+		if stats := ts.Stats(); stats.Mirrored != 2 || stats.Matched != 1 || stats.Mismatched != 1 {
+			t.Fatalf("Test %d: unexpected stats %+v", i+1, stats)
+		}
+	}
+}
@@ -55,6 +55,10 @@ var errInvalidRange = errors.New("Invalid range")
 // the source object size.
 var errInvalidRangeSource = errors.New("Range specified exceeds source object size")
 
+// errInvalidObjectEncoding - returned when an object to decompress is not
+// encoded as its metadata states.
+var errInvalidObjectEncoding = errors.New("Object is not gzip compressed")
+
 // error returned by disks which are to be initialized are waiting for the
 // first server to initialize them in distributed set to initialize them.
 var errNotFirstDisk = errors.New("Not first disk")
@@ -89,3 +93,6 @@ var errIAMNotInitialized = errors.New("IAM sub-system is being initialized, plea
 
 // error returned when upload id not found
 var errUploadIDNotFound = errors.New("Specified Upload ID is not found")
+
+// error returned when a node is not part of the cluster
+var errNodeNotFound = errors.New("Specified node is not part of the cluster")
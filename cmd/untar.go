@@ -19,14 +19,18 @@ package cmd
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
+	"sync/atomic"
 
 	"github.com/cosnicolaou/pbzip2"
 	"github.com/klauspost/compress/s2"
@@ -38,6 +42,18 @@ import (
 // Max bzip2 concurrency across calls. 50% of GOMAXPROCS.
 var bz2Limiter = pbzip2.CreateConcurrencyPool((runtime.GOMAXPROCS(0) + 1) / 2)
 
+var (
+	// Max size of a zip archive spooled to a temporary file, and of all
+	// zip archives spooled at once.
+	maxZipSpoolSize  int64 = 5 << 30
+	maxZipSpoolTotal int64 = 20 << 30
+
+	// Size of the zip archives spooled on this node.
+	zipSpoolUsed int64
+
+	errZipSpoolFull = errors.New("Too many zip archives are being extracted, please try again later")
+)
+
 func detect(r *bufio.Reader) format {
 	z, err := r.Peek(4)
 	if err != nil {
@@ -61,6 +77,7 @@ const (
 	formatLZ4
 	formatS2
 	formatBZ2
+	formatZip
 )
 
 var magicHeaders = []struct {
@@ -95,9 +112,18 @@ var magicHeaders = []struct {
 		header: []byte{0x42, 0x5a, 'h'},
 		f:      formatBZ2,
 	},
+	{
+		// Zip local file header.
+		header: []byte{'P', 'K', 3, 4},
+		f:      formatZip,
+	},
 }
 
-func untar(r io.Reader, putObject func(reader io.Reader, info os.FileInfo, name string)) error {
+// untar extracts a tar or zip archive, calling putObject for each
+// regular file and directory. Entries which putObject fails are
+// accounted in report and skipped, an error reading the archive aborts
+// the extraction.
+func untar(ctx context.Context, r io.Reader, report *extractReport, putObject func(reader io.Reader, info os.FileInfo, name string) error) error {
 	bf := bufio.NewReader(r)
 	switch f := detect(bf); f {
 	case formatGzip:
@@ -117,13 +143,15 @@ func untar(r io.Reader, putObject func(reader io.Reader, info os.FileInfo, name
 		defer dec.Close()
 		r = dec
 	case formatBZ2:
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 		r = pbzip2.NewReader(ctx, bf, pbzip2.DecompressionOptions(
 			pbzip2.BZConcurrency((runtime.GOMAXPROCS(0)+1)/2),
 			pbzip2.BZConcurrencyPool(bz2Limiter)))
 	case formatLZ4:
 		r = lz4.NewReader(bf)
+	case formatZip:
+		return unzip(ctx, bf, report, putObject)
 	case formatUnknown:
 		r = bf
 	default:
@@ -148,6 +176,10 @@ func untar(r io.Reader, putObject func(reader io.Reader, info os.FileInfo, name
 			continue
 		}
 
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
 		name := header.Name
 		if name == slashSeparator {
 			continue
@@ -155,12 +187,98 @@ func untar(r io.Reader, putObject func(reader io.Reader, info os.FileInfo, name
 
 		switch header.Typeflag {
 		case tar.TypeDir: // = directory
-			putObject(tarReader, header.FileInfo(), trimLeadingSlash(pathJoin(name, slashSeparator)))
+			name = trimLeadingSlash(pathJoin(name, slashSeparator))
 		case tar.TypeReg, tar.TypeChar, tar.TypeBlock, tar.TypeFifo, tar.TypeGNUSparse: // = regular
-			putObject(tarReader, header.FileInfo(), trimLeadingSlash(path.Clean(name)))
+			name = trimLeadingSlash(path.Clean(name))
 		default:
 			// ignore symlink'ed
 			continue
 		}
+		extractEntry(ctx, tarReader, header.FileInfo(), name, report, putObject)
+	}
+}
+
+// unzip extracts a zip archive. The central directory is at the end of
+// the archive, so the archive is first spooled to a temporary file.
+func unzip(ctx context.Context, r io.Reader, report *extractReport, putObject func(reader io.Reader, info os.FileInfo, name string) error) error {
+	f, err := ioutil.TempFile("", "minio-extract-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	sw := &zipSpoolWriter{w: f}
+	defer sw.release()
+	size, err := io.Copy(sw, r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		name := zf.Name
+		if name == slashSeparator {
+			continue
+		}
+
+		info := zf.FileInfo()
+		switch {
+		case info.IsDir():
+			name = trimLeadingSlash(pathJoin(name, slashSeparator))
+		case info.Mode().IsRegular():
+			name = trimLeadingSlash(path.Clean(name))
+		default:
+			// ignore symlink'ed
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			report.fail(ctx, name, err)
+			continue
+		}
+		extractEntry(ctx, rc, info, name, report, putObject)
+		rc.Close()
+	}
+	return nil
+}
+
+// zipSpoolWriter spools a zip archive within the size limits.
+type zipSpoolWriter struct {
+	w    io.Writer
+	size int64
+}
+
+func (sw *zipSpoolWriter) Write(p []byte) (int, error) {
+	n := int64(len(p))
+	if sw.size+n > maxZipSpoolSize {
+		return 0, errDataTooLarge
+	}
+	if atomic.AddInt64(&zipSpoolUsed, n) > maxZipSpoolTotal {
+		atomic.AddInt64(&zipSpoolUsed, -n)
+		return 0, errZipSpoolFull
+	}
+	sw.size += n
+	return sw.w.Write(p)
+}
+
+// release returns the spooled size to the total.
+func (sw *zipSpoolWriter) release() {
+	atomic.AddInt64(&zipSpoolUsed, -sw.size)
+}
+
+func extractEntry(ctx context.Context, r io.Reader, info os.FileInfo, name string, report *extractReport, putObject func(reader io.Reader, info os.FileInfo, name string) error) {
+	if err := putObject(r, info, name); err != nil {
+		report.fail(ctx, name, err)
+		return
 	}
+	report.done(info.Size())
 }
@@ -0,0 +1,177 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+func TestUntarFormats(t *testing.T) {
+	files := map[string]string{
+		"dir/a.txt": "hello",
+		"dir/b.txt": "world!",
+		"/c.txt":    "failed",
+	}
+
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	if err := tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	if _, err := zw.Create("dir/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for format, archive := range map[string][]byte{"tar": tarBuf.Bytes(), "zip": zipBuf.Bytes()} {
+		got := make(map[string]string)
+		report := globalExtractTracker.start("bucket", "archive."+format)
+		err := untar(context.Background(), bytes.NewReader(archive), report, func(r io.Reader, info os.FileInfo, name string) error {
+			if name == "c.txt" {
+				return errInvalidArgument
+			}
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			got[name] = string(data)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(globalExtractTracker.list()) != 1 {
+			t.Fatalf("%s: expected the extraction to be tracked", format)
+		}
+		globalExtractTracker.finish(report)
+
+		if got["dir/"] != "" || got["dir/a.txt"] != "hello" || got["dir/b.txt"] != "world!" || len(got) != 3 {
+			t.Errorf("%s: unexpected extracted objects %v", format, got)
+		}
+		res := report.result(context.Background(), nil)
+		if res.Extracted != 3 || res.Failed != 1 || res.Bytes != 11 {
+			t.Errorf("%s: unexpected result %+v", format, res)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Name != "c.txt" {
+			t.Errorf("%s: expected c.txt to fail, got %v", format, res.Errors)
+		}
+	}
+}
+
+func TestUnzipSpoolLimits(t *testing.T) {
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	fw, err := zw.Create("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fw.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := zipBuf.Bytes()
+
+	defer func(size, total int64) {
+		maxZipSpoolSize, maxZipSpoolTotal = size, total
+	}(maxZipSpoolSize, maxZipSpoolTotal)
+
+	testCases := []struct {
+		size, total int64
+		want        error
+	}{
+		{size: int64(len(archive)) - 1, total: 1 << 20, want: errDataTooLarge},
+		{size: 1 << 20, total: int64(len(archive)) - 1, want: errZipSpoolFull},
+		{size: int64(len(archive)), total: int64(len(archive)), want: nil},
+	}
+	for i, testCase := range testCases {
+		maxZipSpoolSize, maxZipSpoolTotal = testCase.size, testCase.total
+		report := &extractReport{}
+		err := untar(context.Background(), bytes.NewReader(archive), report, func(r io.Reader, info os.FileInfo, name string) error {
+			return nil
+		})
+		if err != testCase.want {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.want, err)
+		}
+		if zipSpoolUsed != 0 {
+			t.Errorf("Test %d: expected the spooled size to be released, got %d", i+1, zipSpoolUsed)
+		}
+	}
+}
+
+func TestParseExtractManifest(t *testing.T) {
+	manifest, err := parseExtractManifest(context.Background(), []byte(`{
+		"/dir/a.txt": {
+			"metadata": {"Content-Type": "text/plain", "X-Amz-Meta-Owner": "alice", "X-Amz-Storage-Class": "REDUCED_REDUNDANCY"},
+			"tags": {"project": "ingest"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := manifest["dir/a.txt"]
+	if metadata["content-type"] != "text/plain" || metadata["X-Amz-Meta-Owner"] != "alice" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+	if _, ok := metadata[xhttp.AmzStorageClass]; ok {
+		t.Errorf("expected storage class to be ignored, got %v", metadata)
+	}
+	if metadata[xhttp.AmzObjectTagging] != "project=ingest" || !manifest.hasTags() {
+		t.Errorf("unexpected tags %q", metadata[xhttp.AmzObjectTagging])
+	}
+
+	if _, err = parseExtractManifest(context.Background(), []byte(`{"a": {"tags": {"": "x"}}}`)); err == nil {
+		t.Error("expected an invalid tag to fail the manifest")
+	}
+}
@@ -49,6 +49,10 @@ const (
 
 	envMinisignPubKey = "MINIO_UPDATE_MINISIGN_PUBKEY"
 	updateTimeout     = 10 * time.Second
+
+	// The binary replaced by an update is kept with this suffix
+	// when the update may have to be rolled back.
+	updateRollbackSuffix = ".rollback"
 )
 
 var (
@@ -517,7 +521,9 @@ func getUpdateReaderFromURL(u *url.URL, transport http.RoundTripper, mode string
 	return resp.Body, nil
 }
 
-func doUpdate(u *url.URL, lrTime time.Time, sha256Sum []byte, releaseInfo string, mode string) (err error) {
+// doUpdate replaces the running binary, the replaced binary is kept
+// for rollbackUpdate if keepOld is set.
+func doUpdate(u *url.URL, lrTime time.Time, sha256Sum []byte, releaseInfo string, mode string, keepOld bool) (err error) {
 	transport := getUpdateTransport(30 * time.Second)
 	var reader io.ReadCloser
 	if u.Scheme == "https" || u.Scheme == "http" {
@@ -537,6 +543,14 @@ func doUpdate(u *url.URL, lrTime time.Time, sha256Sum []byte, releaseInfo string
 		Checksum: sha256Sum,
 	}
 
+	if keepOld {
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		opts.OldSavePath = exe + updateRollbackSuffix
+	}
+
 	if err := opts.CheckPermissions(); err != nil {
 		return AdminError{
 			Code:       AdminUpdateApplyFailure,
@@ -585,3 +599,13 @@ func doUpdate(u *url.URL, lrTime time.Time, sha256Sum []byte, releaseInfo string
 
 	return nil
 }
+
+// rollbackUpdate restores the binary replaced by the last update
+// applied with doUpdate keeping the old binary.
+func rollbackUpdate() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return os.Rename(exe+updateRollbackSuffix, exe)
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio/internal/auth"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+)
+
+const (
+	// Claims of upload token credentials.
+	uploadTokenIDClaim          = "uploadTokenId"
+	uploadTokenObjectClaim      = "uploadObject"
+	uploadTokenMaxSizeClaim     = "uploadMaxSize"
+	uploadTokenContentTypeClaim = "uploadContentType"
+
+	uploadTokensPrefix = minioConfigPrefix + "/upload-tokens"
+
+	maxUploadTokenDuration   = 24 * time.Hour
+	uploadTokenPurgeInterval = time.Hour
+)
+
+var (
+	errUploadTokenUsed   = errors.New("Upload token was already used")
+	errUploadTokenDenied = errors.New("Upload is not allowed by the upload token")
+)
+
+// uploadToken restricts temporary credentials to a single upload of
+// one object, up to maxSize bytes and of contentType if set.
+type uploadToken struct {
+	id          string
+	bucket      string
+	object      string
+	maxSize     int64
+	contentType string
+}
+
+// parseUploadTokenRequest parses the upload token parameters of an
+// AssumeRoleForUpload request.
+func parseUploadTokenRequest(form url.Values) (uploadToken, error) {
+	t := uploadToken{
+		id:          mustGetUUID(),
+		bucket:      form.Get(stsBucket),
+		object:      trimLeadingSlash(form.Get(stsKey)),
+		contentType: form.Get(stsContentType),
+	}
+	if form.Get(stsPolicy) != "" {
+		return t, errors.New("Session policies are not supported for upload tokens")
+	}
+	if err := s3utils.CheckValidBucketNameStrict(t.bucket); err != nil {
+		return t, fmt.Errorf("Invalid bucket %q: %w", t.bucket, err)
+	}
+	if !IsValidObjectName(t.object) {
+		return t, fmt.Errorf("Invalid key %q", t.object)
+	}
+	maxSize, err := strconv.ParseInt(form.Get(stsMaxSize), 10, 64)
+	if err != nil || maxSize < 0 || isMaxObjectSize(maxSize) {
+		return t, fmt.Errorf("Invalid %s %q", stsMaxSize, form.Get(stsMaxSize))
+	}
+	t.maxSize = maxSize
+	return t, nil
+}
+
+// setClaims adds the restrictions of t to the claims of its
+// credentials, which must not outlive maxUploadTokenDuration.
+func (t uploadToken) setClaims(claims map[string]interface{}) error {
+	if d, ok := claims[expClaim].(time.Duration); !ok || d > maxUploadTokenDuration {
+		return fmt.Errorf("Upload tokens cannot be valid for more than %s", maxUploadTokenDuration)
+	}
+	claims[uploadTokenIDClaim] = t.id
+	claims[uploadTokenObjectClaim] = pathJoin(t.bucket, t.object)
+	claims[uploadTokenMaxSizeClaim] = strconv.FormatInt(t.maxSize, 10)
+	if t.contentType != "" {
+		claims[uploadTokenContentTypeClaim] = t.contentType
+	}
+	return nil
+}
+
+// sessionPolicy returns the session policy of the credentials of t,
+// allowing nothing but the upload of its object.
+func (t uploadToken) sessionPolicy() string {
+	resource, _ := json.Marshal("arn:aws:s3:::" + pathJoin(t.bucket, t.object))
+	return fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:PutObject"],"Resource":[%s]}]}`, resource)
+}
+
+// getUploadToken returns the upload token of credentials with claims.
+func getUploadToken(claims map[string]interface{}) (t uploadToken, ok bool) {
+	id, ok := claims[uploadTokenIDClaim].(string)
+	if !ok {
+		return t, false
+	}
+	t.id = id
+	objectPath, _ := claims[uploadTokenObjectClaim].(string)
+	t.bucket, t.object = path2BucketObject(objectPath)
+	maxSize, _ := claims[uploadTokenMaxSizeClaim].(string)
+	t.maxSize, _ = strconv.ParseInt(maxSize, 10, 64)
+	t.contentType, _ = claims[uploadTokenContentTypeClaim].(string)
+	return t, true
+}
+
+// checkUploadTokenAPI denies the requests of upload token credentials
+// to any API but PutObject, multipart uploads and copies included.
+func checkUploadTokenAPI(ctx context.Context, cred auth.Credentials) APIErrorCode {
+	if _, ok := getUploadToken(cred.Claims); ok && logger.GetReqInfo(ctx).API != "PutObject" {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
+
+func uploadTokenPath(id string) string {
+	return pathJoin(uploadTokensPrefix, id)
+}
+
+// useUploadToken checks an upload of size bytes to bucket/object with
+// the upload token of r, if any, and holds the token until the returned
+// function is called with whether the upload succeeded. The token is
+// recorded as used before the upload starts, the record is only removed
+// again if the upload failed, so that a token whose use cannot be
+// recorded is never used. The returned function can be called more than
+// once, only the first call counts.
+func useUploadToken(ctx context.Context, objAPI ObjectLayer, r *http.Request, bucket, object string, size int64) (func(uploaded bool), error) {
+	t, ok := getUploadToken(mustGetClaimsFromToken(r))
+	if !ok {
+		return func(bool) {}, nil
+	}
+	if t.bucket != bucket || t.object != object {
+		return nil, errUploadTokenDenied
+	}
+	if t.contentType != "" && !strings.EqualFold(r.Header.Get(xhttp.ContentType), t.contentType) {
+		return nil, errUploadTokenDenied
+	}
+	if size > t.maxSize {
+		return nil, errDataTooLarge
+	}
+
+	// Concurrent uploads with the same token are serialized by the
+	// token lock, only one of them can succeed.
+	lock := objAPI.NewNSLock(minioMetaBucket, uploadTokenPath(t.id))
+	lkctx, err := lock.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = readConfig(ctx, objAPI, uploadTokenPath(t.id)); err == nil || !errors.Is(err, errConfigNotFound) {
+		lock.Unlock(lkctx.Cancel)
+		if err == nil {
+			err = errUploadTokenUsed
+		}
+		return nil, err
+	}
+	if err = saveConfig(ctx, objAPI, uploadTokenPath(t.id), []byte(UTCNow().Format(time.RFC3339))); err != nil {
+		lock.Unlock(lkctx.Cancel)
+		return nil, err
+	}
+
+	var released bool
+	return func(uploaded bool) {
+		if released {
+			return
+		}
+		released = true
+		if !uploaded {
+			// A token whose record remains is lost, never reused.
+			logger.LogIf(ctx, deleteConfig(ctx, objAPI, uploadTokenPath(t.id)))
+		}
+		lock.Unlock(lkctx.Cancel)
+	}, nil
+}
+
+func initUploadTokenPurge(ctx context.Context, objAPI ObjectLayer) {
+	go runUploadTokenPurge(ctx, objAPI)
+}
+
+// runUploadTokenPurge periodically removes the records of used upload
+// tokens, once the tokens expired.
+func runUploadTokenPurge(ctx context.Context, objAPI ObjectLayer) {
+	purgeTimer := time.NewTimer(uploadTokenPurgeInterval)
+	defer purgeTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-purgeTimer.C:
+			logger.LogIf(ctx, purgeUploadTokens(ctx, objAPI, UTCNow().Add(-maxUploadTokenDuration)))
+			purgeTimer.Reset(uploadTokenPurgeInterval)
+		}
+	}
+}
+
+// purgeUploadTokens removes the records of tokens used before olderThan.
+func purgeUploadTokens(ctx context.Context, objAPI ObjectLayer, olderThan time.Time) error {
+	var marker string
+	for {
+		loi, err := objAPI.ListObjects(ctx, minioMetaBucket, uploadTokensPrefix+SlashSeparator, marker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range loi.Objects {
+			if oi.ModTime.Before(olderThan) {
+				if err = deleteConfig(ctx, objAPI, oi.Name); err != nil && !errors.Is(err, errConfigNotFound) {
+					return err
+				}
+			}
+		}
+		if !loi.IsTruncated {
+			return nil
+		}
+		marker = loi.NextMarker
+	}
+}
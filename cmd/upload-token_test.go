@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+func TestParseUploadTokenRequest(t *testing.T) {
+	testCases := []struct {
+		form    url.Values
+		success bool
+	}{
+		{url.Values{stsBucket: {"uploads"}, stsKey: {"users/42/avatar.png"}, stsMaxSize: {"1048576"}}, true},
+		{url.Values{stsBucket: {"uploads"}, stsKey: {"/avatar.png"}, stsMaxSize: {"0"}, stsContentType: {"image/png"}}, true},
+		{url.Values{stsBucket: {"uploads"}, stsKey: {"avatar.png"}}, false},
+		{url.Values{stsBucket: {"uploads"}, stsKey: {"avatar.png"}, stsMaxSize: {"-1"}}, false},
+		{url.Values{stsBucket: {"Uploads"}, stsKey: {"avatar.png"}, stsMaxSize: {"1"}}, false},
+		{url.Values{stsBucket: {"uploads"}, stsKey: {""}, stsMaxSize: {"1"}}, false},
+		{url.Values{stsBucket: {"uploads"}, stsKey: {"avatar.png"}, stsMaxSize: {"1"}, stsPolicy: {"{}"}}, false},
+	}
+	for i, testCase := range testCases {
+		_, err := parseUploadTokenRequest(testCase.form)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+	}
+}
+
+func TestUploadTokenClaims(t *testing.T) {
+	token, err := parseUploadTokenRequest(url.Values{
+		stsBucket:      {"uploads"},
+		stsKey:         {"users/42/avatar.png"},
+		stsMaxSize:     {"1048576"},
+		stsContentType: {"image/png"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = token.setClaims(map[string]interface{}{expClaim: 48 * time.Hour}); err == nil {
+		t.Fatal("expected upload tokens valid longer than a day to be rejected")
+	}
+	claims := map[string]interface{}{expClaim: time.Hour}
+	if err = token.setClaims(claims); err != nil {
+		t.Fatal(err)
+	}
+	parsed, ok := getUploadToken(claims)
+	if !ok {
+		t.Fatal("expected an upload token")
+	}
+	if parsed != token {
+		t.Errorf("expected %+v, got %+v", token, parsed)
+	}
+	if _, ok = getUploadToken(map[string]interface{}{expClaim: time.Hour}); ok {
+		t.Error("expected no upload token")
+	}
+
+	p, err := iampolicy.ParseConfig(strings.NewReader(token.sessionPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range []string{"users/42/avatar.png", "users/42/other.png"} {
+		allowed := p.IsAllowed(iampolicy.Args{
+			Action:     iampolicy.PutObjectAction,
+			BucketName: "uploads",
+			ObjectName: object,
+		})
+		if allowed != (object == token.object) {
+			t.Errorf("expected upload of %s allowed %t", object, !allowed)
+		}
+	}
+}
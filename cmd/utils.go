@@ -46,6 +46,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/minio/madmin-go"
 	miniogopolicy "github.com/minio/minio-go/v7/pkg/policy"
+	"github.com/minio/minio/internal/config/rpc"
 	"github.com/minio/minio/internal/handlers"
 	xhttp "github.com/minio/minio/internal/http"
 	"github.com/minio/minio/internal/logger"
@@ -460,20 +461,43 @@ func ToS3ETag(etag string) string {
 	return etag
 }
 
-func newInternodeHTTPTransport(tlsConfig *tls.Config, dialTimeout time.Duration) func() http.RoundTripper {
+// internodeTransport is the round tripper of the internode RPC clients,
+// its transport is replaced when the rpc config changes.
+type internodeTransport struct {
+	tlsConfig *tls.Config
+
+	mu       sync.RWMutex
+	cfg      rpc.Config
+	tr       *http.Transport
+	limiters trafficLimiters
+}
+
+func (t *internodeTransport) newTransport(cfg rpc.Config) *http.Transport {
+	dialOpts := xhttp.InternodeDialOptions{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.TCPKeepAlive,
+	}
+	if cfg.Network != nil {
+		if dialOpts.LocalAddr = xhttp.LocalAddrInNetwork(cfg.Network); dialOpts.LocalAddr == nil {
+			logger.LogIf(GlobalContext, fmt.Errorf("No local address in the rpc network %s, connecting from any address", cfg.Network))
+		}
+	}
+	dial := xhttp.ZeroCopyDialContext(xhttp.NewInternodeDialContextWithOptions(dialOpts), cfg.ZeroCopyNetworks)
+
 	// For more details about various values used here refer
 	// https://golang.org/pkg/net/http/#Transport documentation
 	tr := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           xhttp.DialContextWithDNSCache(globalDNSCache, xhttp.NewInternodeDialContext(dialTimeout)),
-		MaxIdleConnsPerHost:   1024,
+		DialContext:           xhttp.DialContextWithDNSCacheInNetwork(globalDNSCache, dial, cfg.Network),
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
 		WriteBufferSize:       32 << 10, // 32KiB moving up from 4KiB default
 		ReadBufferSize:        32 << 10, // 32KiB moving up from 4KiB default
-		IdleConnTimeout:       15 * time.Second,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
 		ResponseHeaderTimeout: 15 * time.Minute, // Set conservative timeouts for MinIO internode.
 		TLSHandshakeTimeout:   15 * time.Second,
 		ExpectContinueTimeout: 15 * time.Second,
-		TLSClientConfig:       tlsConfig,
+		TLSClientConfig:       t.tlsConfig,
 		// Go net/http automatically unzip if content-type is
 		// gzip disable this feature, as we are always interested
 		// in raw stream.
@@ -494,15 +518,73 @@ func newInternodeHTTPTransport(tlsConfig *tls.Config, dialTimeout time.Duration)
 	// 		trhttp2.ReadIdleTimeout = 5 * time.Minute
 	// 		// PingTimeout is the timeout after which the connection will be closed
 	// 		// if a response to Ping is not received.
-	// 		trhttp2.PingTimeout = dialTimeout
+	// 		trhttp2.PingTimeout = cfg.DialTimeout
 	// 		// DisableCompression, if true, prevents the Transport from
 	// 		// requesting compression with an "Accept-Encoding: gzip"
 	// 		trhttp2.DisableCompression = true
 	// 	}
 	// }
 
+	return tr
+}
+
+// RoundTrip sends the request with the current transport, within the
+// bandwidth of its traffic class, along with its correlation ID.
+func (t *internodeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	tr, limiters := t.tr, t.limiters
+	t.mu.RUnlock()
+
+	ctx := req.Context()
+	if id := correlationIDFromContext(ctx); id != "" && req.Header.Get(xhttp.MinIOCorrelationID) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(xhttp.MinIOCorrelationID, id)
+	}
+	limiter := limiters.get(trafficClassFromContext(ctx))
+	if limiter == nil {
+		return tr.RoundTrip(req)
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		r := *req
+		r.Body = &throttledBody{ReadCloser: req.Body, ctx: ctx, limiter: limiter}
+		req = &r
+	}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &throttledBody{ReadCloser: resp.Body, ctx: ctx, limiter: limiter}
+	return resp, nil
+}
+
+// update replaces the transport if cfg changed, the requests in flight
+// complete on the connections of the previous one.
+func (t *internodeTransport) update(cfg rpc.Config) {
+	t.mu.Lock()
+	if reflect.DeepEqual(cfg, t.cfg) {
+		t.mu.Unlock()
+		return
+	}
+	old := t.tr
+	t.cfg, t.tr = cfg, t.newTransport(cfg)
+	t.limiters = newTrafficLimiters(cfg)
+	t.mu.Unlock()
+	old.CloseIdleConnections()
+}
+
+func newInternodeHTTPTransport(tlsConfig *tls.Config, dialTimeout time.Duration) func() http.RoundTripper {
+	t := &internodeTransport{
+		tlsConfig: tlsConfig,
+		cfg: rpc.Config{
+			MaxIdleConnsPerHost: 1024,
+			IdleConnTimeout:     15 * time.Second,
+			DialTimeout:         dialTimeout,
+		},
+	}
+	t.tr = t.newTransport(t.cfg)
+
 	return func() http.RoundTripper {
-		return tr
+		return t
 	}
 }
 
@@ -755,14 +837,15 @@ func newContext(r *http.Request, w http.ResponseWriter, api string) context.Cont
 		object = prefix
 	}
 	reqInfo := &logger.ReqInfo{
-		DeploymentID: globalDeploymentID,
-		RequestID:    w.Header().Get(xhttp.AmzRequestID),
-		RemoteHost:   handlers.GetSourceIP(r),
-		Host:         getHostName(r),
-		UserAgent:    r.UserAgent(),
-		API:          api,
-		BucketName:   bucket,
-		ObjectName:   object,
+		DeploymentID:  globalDeploymentID,
+		RequestID:     w.Header().Get(xhttp.AmzRequestID),
+		CorrelationID: correlationIDFromContext(r.Context()),
+		RemoteHost:    handlers.GetSourceIP(r),
+		Host:          getHostName(r),
+		UserAgent:     r.UserAgent(),
+		API:           api,
+		BucketName:    bucket,
+		ObjectName:    object,
 	}
 	return logger.SetReqInfo(r.Context(), reqInfo)
 }
@@ -843,7 +926,8 @@ func getMinioMode() string {
 }
 
 func iamPolicyClaimNameOpenID() string {
-	return globalOpenIDConfig.ClaimPrefix + globalOpenIDConfig.ClaimName
+	openIDConfig, _ := getOpenIDConfig()
+	return openIDConfig.ClaimPrefix + openIDConfig.ClaimName
 }
 
 func iamPolicyClaimNameSA() string {
@@ -1121,7 +1205,7 @@ func speedTest(ctx context.Context, throughputSize, iopsSize int, concurrencySta
 
 	numDisks := 0
 	if pools, ok := objAPI.(*erasureServerPools); ok {
-		for _, set := range pools.serverPools {
+		for _, set := range pools.currentPools() {
 			numDisks = set.setCount * set.setDriveCount
 		}
 	}
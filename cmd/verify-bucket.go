@@ -0,0 +1,414 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+)
+
+// Verification reports are saved under this prefix of the meta bucket.
+const verifyReportsPrefix = "verify-reports"
+
+// Verification job states.
+const (
+	VerifyJobRunning   = "running"
+	VerifyJobCompleted = "completed"
+	VerifyJobFailed    = "failed"
+)
+
+// States of an object failing verification.
+const (
+	verifyObjectCorrupt      = "corrupt"
+	verifyObjectPartial      = "partial"
+	verifyObjectETagMismatch = "etag-mismatch"
+	verifyObjectUnreadable   = "unreadable"
+)
+
+// Algorithm used to sign the verification reports.
+const verifyReportSignatureAlgorithm = "HMAC-SHA256"
+
+// Finished jobs are forgotten beyond this count, their reports are kept.
+const maxVerifyJobs = 100
+
+var errNoSuchVerifyJob = errors.New("no such verification job")
+
+// VerifyBucketOpts are the parameters of a verification job.
+type VerifyBucketOpts struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix,omitempty"`
+	// Verify all versions instead of the latest only.
+	Versions bool `json:"versions"`
+	// Recompute the ETag of unencrypted and uncompressed objects.
+	ETag bool `json:"etag"`
+	// When set, the report is also written to this bucket.
+	ReportBucket string `json:"reportBucket,omitempty"`
+}
+
+// VerifyObjectResult describes an object failing verification.
+type VerifyObjectResult struct {
+	Object    string `json:"object"`
+	VersionID string `json:"versionId,omitempty"`
+	State     string `json:"state"`
+	// Drives holding missing or corrupt shards.
+	Drives []string `json:"drives,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// VerifyJobStatus is the progress of a verification job.
+type VerifyJobStatus struct {
+	ID       string           `json:"id"`
+	Opts     VerifyBucketOpts `json:"opts"`
+	Node     string           `json:"node"`
+	State    string           `json:"state"`
+	Started  time.Time        `json:"started"`
+	Finished time.Time        `json:"finished,omitempty"`
+	Objects  uint64           `json:"objects"`
+	Bytes    uint64           `json:"bytes"`
+	Problems uint64           `json:"problems"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// VerifyReport is the result of a verification job.
+type VerifyReport struct {
+	VerifyJobStatus
+	Results []VerifyObjectResult `json:"results"`
+}
+
+// SignedVerifyReport is a report along with its signature, computed over
+// the report bytes with a key derived from the root credentials.
+type SignedVerifyReport struct {
+	Report    json.RawMessage `json:"report"`
+	Algorithm string          `json:"algorithm"`
+	Signature string          `json:"signature"`
+}
+
+// signVerifyReport returns the signature of the report.
+func signVerifyReport(report []byte, secretKey string) string {
+	key := hmac.New(sha256.New, []byte(secretKey))
+	key.Write([]byte(verifyReportsPrefix))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write(report)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type verifyJob struct {
+	mu      sync.Mutex
+	status  VerifyJobStatus
+	results []VerifyObjectResult
+}
+
+func (j *verifyJob) getStatus() VerifyJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// verifyJobs are the verification jobs started on this node.
+type verifyJobs struct {
+	sync.Mutex
+	jobs map[string]*verifyJob
+}
+
+var globalVerifyJobs = &verifyJobs{jobs: make(map[string]*verifyJob)}
+
+// start starts verifying the objects of a bucket in background.
+func (v *verifyJobs) start(objAPI ObjectLayer, opts VerifyBucketOpts) VerifyJobStatus {
+	job := &verifyJob{
+		status: VerifyJobStatus{
+			ID:      mustGetUUID(),
+			Opts:    opts,
+			Node:    globalLocalNodeName,
+			State:   VerifyJobRunning,
+			Started: UTCNow(),
+		},
+	}
+	v.Lock()
+	v.jobs[job.status.ID] = job
+	for len(v.jobs) > maxVerifyJobs {
+		var oldest *verifyJob
+		for _, j := range v.jobs {
+			s := j.getStatus()
+			if s.State != VerifyJobRunning && (oldest == nil || s.Started.Before(oldest.getStatus().Started)) {
+				oldest = j
+			}
+		}
+		if oldest == nil {
+			break
+		}
+		delete(v.jobs, oldest.getStatus().ID)
+	}
+	v.Unlock()
+
+	go job.run(GlobalContext, objAPI)
+	return job.getStatus()
+}
+
+// list returns the status of all jobs, most recent first.
+func (v *verifyJobs) list() []VerifyJobStatus {
+	v.Lock()
+	statuses := make([]VerifyJobStatus, 0, len(v.jobs))
+	for _, job := range v.jobs {
+		statuses = append(statuses, job.getStatus())
+	}
+	v.Unlock()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Started.After(statuses[j].Started)
+	})
+	return statuses
+}
+
+func (v *verifyJobs) get(id string) (VerifyJobStatus, error) {
+	v.Lock()
+	job, ok := v.jobs[id]
+	v.Unlock()
+	if !ok {
+		return VerifyJobStatus{}, errNoSuchVerifyJob
+	}
+	return job.getStatus(), nil
+}
+
+func verifyReportFile(id string) string {
+	return path.Join(verifyReportsPrefix, id+".json")
+}
+
+// readVerifyReport returns the signed report of a finished job.
+func readVerifyReport(ctx context.Context, objAPI ObjectLayer, id string) ([]byte, error) {
+	data, err := readConfig(ctx, objAPI, verifyReportFile(id))
+	if errors.Is(err, errConfigNotFound) {
+		return nil, errNoSuchVerifyJob
+	}
+	return data, err
+}
+
+func (j *verifyJob) run(ctx context.Context, objAPI ObjectLayer) {
+	opts := j.status.Opts
+	err := listVerifyObjects(ctx, objAPI, opts, func(oi ObjectInfo) {
+		result, ok := verifyObject(ctx, objAPI, oi, opts.ETag)
+		j.mu.Lock()
+		j.status.Objects++
+		j.status.Bytes += uint64(oi.Size)
+		if !ok {
+			j.status.Problems++
+			j.results = append(j.results, result)
+		}
+		j.mu.Unlock()
+	})
+
+	j.mu.Lock()
+	report := VerifyReport{VerifyJobStatus: j.status, Results: j.results}
+	j.mu.Unlock()
+
+	report.Finished = UTCNow()
+	report.State = VerifyJobCompleted
+	if err != nil {
+		report.State = VerifyJobFailed
+		report.Error = err.Error()
+	}
+	if report.Results == nil {
+		report.Results = []VerifyObjectResult{}
+	}
+	if serr := saveVerifyReport(ctx, objAPI, report); serr != nil {
+		logger.LogIf(ctx, fmt.Errorf("unable to save verification report %s: %w", report.ID, serr))
+		if err == nil {
+			report.State = VerifyJobFailed
+			report.Error = serr.Error()
+		}
+	}
+
+	// Only report the job as finished once its report can be read.
+	j.mu.Lock()
+	j.status = report.VerifyJobStatus
+	j.mu.Unlock()
+}
+
+// saveVerifyReport signs and saves the report in the meta bucket, and in
+// the report bucket if one was given.
+func saveVerifyReport(ctx context.Context, objAPI ObjectLayer, report VerifyReport) error {
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(SignedVerifyReport{
+		Report:    reportBytes,
+		Algorithm: verifyReportSignatureAlgorithm,
+		Signature: signVerifyReport(reportBytes, globalActiveCred.SecretKey),
+	})
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, verifyReportFile(report.ID), data); err != nil {
+		return err
+	}
+	if report.Opts.ReportBucket == "" {
+		return nil
+	}
+	hr, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", getSHA256Hash(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	_, err = objAPI.PutObject(ctx, report.Opts.ReportBucket, verifyReportFile(report.ID), NewPutObjReader(hr), ObjectOptions{
+		UserDefined: map[string]string{"content-type": "application/json"},
+	})
+	return err
+}
+
+// listVerifyObjects calls fn for all objects, or object versions,
+// under the prefix of the bucket. Delete markers are skipped.
+func listVerifyObjects(ctx context.Context, objAPI ObjectLayer, opts VerifyBucketOpts, fn func(ObjectInfo)) error {
+	if !opts.Versions {
+		marker := ""
+		for {
+			res, err := objAPI.ListObjects(ctx, opts.Bucket, opts.Prefix, marker, "", maxObjectList)
+			if err != nil {
+				return err
+			}
+			for _, oi := range res.Objects {
+				fn(oi)
+			}
+			if !res.IsTruncated {
+				return nil
+			}
+			marker = res.NextMarker
+		}
+	}
+
+	marker, versionMarker := "", ""
+	for {
+		res, err := objAPI.ListObjectVersions(ctx, opts.Bucket, opts.Prefix, marker, versionMarker, "", maxObjectList)
+		if err != nil {
+			return err
+		}
+		for _, oi := range res.Objects {
+			if !oi.DeleteMarker {
+				fn(oi)
+			}
+		}
+		if !res.IsTruncated {
+			return nil
+		}
+		marker, versionMarker = res.NextMarker, res.NextVersionIDMarker
+	}
+}
+
+// verifyObject checks the erasure shards of the object for bitrot and
+// optionally recomputes its ETag, returns false if verification failed.
+func verifyObject(ctx context.Context, objAPI ObjectLayer, oi ObjectInfo, checkETag bool) (VerifyObjectResult, bool) {
+	result := VerifyObjectResult{Object: oi.Name, VersionID: oi.VersionID}
+	if oi.IsRemote() {
+		// The data lives on a remote tier.
+		return result, true
+	}
+
+	hr, err := objAPI.HealObject(ctx, oi.Bucket, oi.Name, oi.VersionID, madmin.HealOpts{
+		ScanMode: madmin.HealDeepScan,
+		DryRun:   true,
+	})
+	if err != nil {
+		result.State = verifyObjectUnreadable
+		result.Error = err.Error()
+		return result, false
+	}
+	for _, drive := range hr.Before.Drives {
+		switch drive.State {
+		case madmin.DriveStateCorrupt:
+			result.State = verifyObjectCorrupt
+			result.Drives = append(result.Drives, drive.Endpoint)
+		case madmin.DriveStateMissing:
+			if result.State == "" {
+				result.State = verifyObjectPartial
+			}
+			result.Drives = append(result.Drives, drive.Endpoint)
+		}
+	}
+	if result.State != "" {
+		return result, false
+	}
+
+	if !checkETag {
+		return result, true
+	}
+	if _, encrypted := crypto.IsEncrypted(oi.UserDefined); encrypted || oi.IsCompressed() {
+		// The ETag is not the MD5 of the stored data.
+		return result, true
+	}
+	etag, err := computeObjectETag(ctx, objAPI, oi)
+	if err != nil {
+		result.State = verifyObjectUnreadable
+		result.Error = err.Error()
+		return result, false
+	}
+	if etag != canonicalizeETag(oi.ETag) {
+		result.State = verifyObjectETagMismatch
+		result.Error = fmt.Sprintf("computed ETag %s, expected %s", etag, oi.ETag)
+		return result, false
+	}
+	return result, true
+}
+
+// computeObjectETag reads the object and returns its S3 ETag, computed
+// per part for multipart uploads.
+func computeObjectETag(ctx context.Context, objAPI ObjectLayer, oi ObjectInfo) (string, error) {
+	gr, err := objAPI.GetObjectNInfo(ctx, oi.Bucket, oi.Name, nil, http.Header{}, readLock, ObjectOptions{VersionID: oi.VersionID})
+	if err != nil {
+		return "", err
+	}
+	defer gr.Close()
+
+	if !isMultipartETag(oi.ETag) {
+		h := md5.New()
+		if _, err = io.Copy(h, gr); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	parts := make([]CompletePart, 0, len(oi.Parts))
+	for _, part := range oi.Parts {
+		h := md5.New()
+		if _, err = io.CopyN(h, gr, part.Size); err != nil {
+			return "", err
+		}
+		parts = append(parts, CompletePart{PartNumber: part.Number, ETag: hex.EncodeToString(h.Sum(nil))})
+	}
+	return getCompleteMultipartMD5(parts), nil
+}
+
+// isMultipartETag returns true for ETags of multipart uploads.
+func isMultipartETag(etag string) bool {
+	return strings.Contains(canonicalizeETag(etag), "-")
+}
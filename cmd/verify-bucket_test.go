@@ -0,0 +1,69 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSignVerifyReport(t *testing.T) {
+	report := []byte(`{"id":"1"}`)
+	sig := signVerifyReport(report, "secret")
+	if sig != signVerifyReport(report, "secret") {
+		t.Fatal("expected signature to be deterministic")
+	}
+	if sig == signVerifyReport(report, "other-secret") {
+		t.Fatal("expected signature to depend on the key")
+	}
+	if sig == signVerifyReport([]byte(`{"id":"2"}`), "secret") {
+		t.Fatal("expected signature to depend on the report")
+	}
+}
+
+func TestVerifyObject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objLayer, fsDirs, err := prepareErasure16(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucket, object := "bucket", "object"
+	if err = objLayer.MakeBucketWithLocation(ctx, bucket, BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1<<20)
+	oi, err := objLayer.PutObject(ctx, bucket, object, mustGetPutObjReader(t, bytes.NewReader(data), int64(len(data)), "", ""), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, ok := verifyObject(ctx, objLayer, oi, true); !ok {
+		t.Fatalf("expected object to be verified, got %#v", result)
+	}
+
+	oi.ETag = "00000000000000000000000000000000"
+	result, ok := verifyObject(ctx, objLayer, oi, true)
+	if ok || result.State != verifyObjectETagMismatch {
+		t.Fatalf("expected ETag mismatch, got %#v", result)
+	}
+}
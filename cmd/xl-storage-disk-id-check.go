@@ -19,7 +19,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,6 +29,7 @@ import (
 
 	"github.com/VividCortex/ewma"
 	"github.com/minio/madmin-go"
+	"github.com/minio/minio/internal/tracing"
 )
 
 //go:generate stringer -type=storageMetric -trimprefix=storageMetric $GOFILE
@@ -58,6 +61,7 @@ const (
 	storageMetricReadVersion
 	storageMetricReadAll
 	storageStatInfoFile
+	storageMetricLinkFile
 
 	// .... add more
 
@@ -73,9 +77,77 @@ type xlStorageDiskIDCheck struct {
 	storage      StorageAPI
 	apiLatencies [storageMetricLast]ewma.MovingAverage
 	diskID       string
+	ioStats      *diskIOStats
 	apiCalls     [storageMetricLast]uint64
 }
 
+// diskIOStats are the error counters and recent read/write latencies
+// of a drive.
+type diskIOStats struct {
+	totalErrors   uint64
+	totalTimeouts uint64
+	offlineEvents uint64
+	offline       int32
+	readLatency   latencyWindow
+	writeLatency  latencyWindow
+}
+
+// Number of recent samples used to compute latency percentiles.
+const latencyWindowSize = 256
+
+// latencyWindow keeps the durations of the most recent calls.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [latencyWindowSize]time.Duration
+	next    int
+	n       int
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+	if w.n < latencyWindowSize {
+		w.n++
+	}
+	w.mu.Unlock()
+}
+
+// percentiles returns the p50, p90 and p99 latencies of the window.
+func (w *latencyWindow) percentiles() (p50, p90, p99 time.Duration) {
+	w.mu.Lock()
+	samples := make([]time.Duration, w.n)
+	copy(samples, w.samples[:w.n])
+	w.mu.Unlock()
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	at := func(p int) time.Duration {
+		return samples[(len(samples)-1)*p/100]
+	}
+	return at(50), at(90), at(99)
+}
+
+// isReadMetric returns true for calls reading object data or metadata.
+func isReadMetric(s storageMetric) bool {
+	switch s {
+	case storageMetricReadFile, storageMetricReadFileStream, storageMetricReadVersion, storageMetricReadAll:
+		return true
+	}
+	return false
+}
+
+// isWriteMetric returns true for calls writing object data or metadata.
+func isWriteMetric(s storageMetric) bool {
+	switch s {
+	case storageMetricAppendFile, storageMetricCreateFile, storageMetricWriteAll,
+		storageMetricWriteMetadata, storageMetricRenameData, storageMetricUpdateMetadata:
+		return true
+	}
+	return false
+}
+
 func (p *xlStorageDiskIDCheck) getMetrics() DiskMetrics {
 	diskMetric := DiskMetrics{
 		APILatencies: make(map[string]string),
@@ -87,6 +159,16 @@ func (p *xlStorageDiskIDCheck) getMetrics() DiskMetrics {
 	for i := range p.apiCalls {
 		diskMetric.APICalls[storageMetric(i).String()] = atomic.LoadUint64(&p.apiCalls[i])
 	}
+	diskMetric.TotalErrors = atomic.LoadUint64(&p.ioStats.totalErrors)
+	diskMetric.TotalTimeouts = atomic.LoadUint64(&p.ioStats.totalTimeouts)
+	diskMetric.OfflineEvents = atomic.LoadUint64(&p.ioStats.offlineEvents)
+	diskMetric.IOLatencies = make(map[string]uint64, 6)
+	for prefix, w := range map[string]*latencyWindow{"Read": &p.ioStats.readLatency, "Write": &p.ioStats.writeLatency} {
+		p50, p90, p99 := w.percentiles()
+		diskMetric.IOLatencies[prefix+"P50"] = uint64(p50)
+		diskMetric.IOLatencies[prefix+"P90"] = uint64(p90)
+		diskMetric.IOLatencies[prefix+"P99"] = uint64(p99)
+	}
 	return diskMetric
 }
 
@@ -117,6 +199,7 @@ func (e *lockedSimpleEWMA) Value() float64 {
 func newXLStorageDiskIDCheck(storage *xlStorage) *xlStorageDiskIDCheck {
 	xl := xlStorageDiskIDCheck{
 		storage: storage,
+		ioStats: &diskIOStats{},
 	}
 	for i := range xl.apiLatencies[:] {
 		xl.apiLatencies[i] = &lockedSimpleEWMA{
@@ -229,7 +312,7 @@ func (p *xlStorageDiskIDCheck) DiskInfo(ctx context.Context) (info DiskInfo, err
 }
 
 func (p *xlStorageDiskIDCheck) MakeVolBulk(ctx context.Context, volumes ...string) (err error) {
-	defer p.updateStorageMetrics(storageMetricMakeVolBulk, volumes...)()
+	defer p.updateStorageMetrics(ctx, storageMetricMakeVolBulk, volumes...)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -242,7 +325,7 @@ func (p *xlStorageDiskIDCheck) MakeVolBulk(ctx context.Context, volumes ...strin
 }
 
 func (p *xlStorageDiskIDCheck) MakeVol(ctx context.Context, volume string) (err error) {
-	defer p.updateStorageMetrics(storageMetricMakeVol, volume)()
+	defer p.updateStorageMetrics(ctx, storageMetricMakeVol, volume)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -254,21 +337,21 @@ func (p *xlStorageDiskIDCheck) MakeVol(ctx context.Context, volume string) (err
 	return p.storage.MakeVol(ctx, volume)
 }
 
-func (p *xlStorageDiskIDCheck) ListVols(ctx context.Context) ([]VolInfo, error) {
-	defer p.updateStorageMetrics(storageMetricListVols, "/")()
+func (p *xlStorageDiskIDCheck) ListVols(ctx context.Context) (vols []VolInfo, err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricListVols, "/")(&err)
 
 	if contextCanceled(ctx) {
 		return nil, ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return nil, err
 	}
 	return p.storage.ListVols(ctx)
 }
 
 func (p *xlStorageDiskIDCheck) StatVol(ctx context.Context, volume string) (vol VolInfo, err error) {
-	defer p.updateStorageMetrics(storageMetricStatVol, volume)()
+	defer p.updateStorageMetrics(ctx, storageMetricStatVol, volume)(&err)
 
 	if contextCanceled(ctx) {
 		return VolInfo{}, ctx.Err()
@@ -281,7 +364,7 @@ func (p *xlStorageDiskIDCheck) StatVol(ctx context.Context, volume string) (vol
 }
 
 func (p *xlStorageDiskIDCheck) DeleteVol(ctx context.Context, volume string, forceDelete bool) (err error) {
-	defer p.updateStorageMetrics(storageMetricDeleteVol, volume)()
+	defer p.updateStorageMetrics(ctx, storageMetricDeleteVol, volume)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -293,14 +376,14 @@ func (p *xlStorageDiskIDCheck) DeleteVol(ctx context.Context, volume string, for
 	return p.storage.DeleteVol(ctx, volume, forceDelete)
 }
 
-func (p *xlStorageDiskIDCheck) ListDir(ctx context.Context, volume, dirPath string, count int) ([]string, error) {
-	defer p.updateStorageMetrics(storageMetricListDir, volume, dirPath)()
+func (p *xlStorageDiskIDCheck) ListDir(ctx context.Context, volume, dirPath string, count int) (entries []string, err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricListDir, volume, dirPath)(&err)
 
 	if contextCanceled(ctx) {
 		return nil, ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return nil, err
 	}
 
@@ -308,13 +391,13 @@ func (p *xlStorageDiskIDCheck) ListDir(ctx context.Context, volume, dirPath stri
 }
 
 func (p *xlStorageDiskIDCheck) ReadFile(ctx context.Context, volume string, path string, offset int64, buf []byte, verifier *BitrotVerifier) (n int64, err error) {
-	defer p.updateStorageMetrics(storageMetricReadFile, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricReadFile, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return 0, ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return 0, err
 	}
 
@@ -322,7 +405,7 @@ func (p *xlStorageDiskIDCheck) ReadFile(ctx context.Context, volume string, path
 }
 
 func (p *xlStorageDiskIDCheck) AppendFile(ctx context.Context, volume string, path string, buf []byte) (err error) {
-	defer p.updateStorageMetrics(storageMetricAppendFile, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricAppendFile, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -335,56 +418,70 @@ func (p *xlStorageDiskIDCheck) AppendFile(ctx context.Context, volume string, pa
 	return p.storage.AppendFile(ctx, volume, path, buf)
 }
 
-func (p *xlStorageDiskIDCheck) CreateFile(ctx context.Context, volume, path string, size int64, reader io.Reader) error {
-	defer p.updateStorageMetrics(storageMetricCreateFile, volume, path)()
+func (p *xlStorageDiskIDCheck) CreateFile(ctx context.Context, volume, path string, size int64, reader io.Reader) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricCreateFile, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return err
 	}
 
 	return p.storage.CreateFile(ctx, volume, path, size, reader)
 }
 
-func (p *xlStorageDiskIDCheck) ReadFileStream(ctx context.Context, volume, path string, offset, length int64) (io.ReadCloser, error) {
-	defer p.updateStorageMetrics(storageMetricReadFileStream, volume, path)()
+func (p *xlStorageDiskIDCheck) ReadFileStream(ctx context.Context, volume, path string, offset, length int64) (rc io.ReadCloser, err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricReadFileStream, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return nil, ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return nil, err
 	}
 
 	return p.storage.ReadFileStream(ctx, volume, path, offset, length)
 }
 
-func (p *xlStorageDiskIDCheck) RenameFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) error {
-	defer p.updateStorageMetrics(storageMetricRenameFile, srcVolume, srcPath, dstVolume, dstPath)()
+func (p *xlStorageDiskIDCheck) RenameFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricRenameFile, srcVolume, srcPath, dstVolume, dstPath)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return err
 	}
 
 	return p.storage.RenameFile(ctx, srcVolume, srcPath, dstVolume, dstPath)
 }
 
-func (p *xlStorageDiskIDCheck) RenameData(ctx context.Context, srcVolume, srcPath string, fi FileInfo, dstVolume, dstPath string) error {
-	defer p.updateStorageMetrics(storageMetricRenameData, srcPath, fi.DataDir, dstVolume, dstPath)()
+func (p *xlStorageDiskIDCheck) LinkFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricLinkFile, srcVolume, srcPath, dstVolume, dstPath)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
+		return err
+	}
+
+	return p.storage.LinkFile(ctx, srcVolume, srcPath, dstVolume, dstPath)
+}
+
+func (p *xlStorageDiskIDCheck) RenameData(ctx context.Context, srcVolume, srcPath string, fi FileInfo, dstVolume, dstPath string) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricRenameData, srcPath, fi.DataDir, dstVolume, dstPath)(&err)
+
+	if contextCanceled(ctx) {
+		return ctx.Err()
+	}
+
+	if err = p.checkDiskStale(); err != nil {
 		return err
 	}
 
@@ -392,7 +489,7 @@ func (p *xlStorageDiskIDCheck) RenameData(ctx context.Context, srcVolume, srcPat
 }
 
 func (p *xlStorageDiskIDCheck) CheckParts(ctx context.Context, volume string, path string, fi FileInfo) (err error) {
-	defer p.updateStorageMetrics(storageMetricCheckParts, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricCheckParts, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -406,7 +503,7 @@ func (p *xlStorageDiskIDCheck) CheckParts(ctx context.Context, volume string, pa
 }
 
 func (p *xlStorageDiskIDCheck) Delete(ctx context.Context, volume string, path string, recursive bool) (err error) {
-	defer p.updateStorageMetrics(storageMetricDelete, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricDelete, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -428,7 +525,7 @@ func (p *xlStorageDiskIDCheck) DeleteVersions(ctx context.Context, volume string
 		path = versions[0].Name
 	}
 
-	defer p.updateStorageMetrics(storageMetricDeleteVersions, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricDeleteVersions, volume, path)(nil)
 
 	errs = make([]error, len(versions))
 
@@ -449,14 +546,14 @@ func (p *xlStorageDiskIDCheck) DeleteVersions(ctx context.Context, volume string
 	return p.storage.DeleteVersions(ctx, volume, versions)
 }
 
-func (p *xlStorageDiskIDCheck) VerifyFile(ctx context.Context, volume, path string, fi FileInfo) error {
-	defer p.updateStorageMetrics(storageMetricVerifyFile, volume, path)()
+func (p *xlStorageDiskIDCheck) VerifyFile(ctx context.Context, volume, path string, fi FileInfo) (err error) {
+	defer p.updateStorageMetrics(ctx, storageMetricVerifyFile, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
 	}
 
-	if err := p.checkDiskStale(); err != nil {
+	if err = p.checkDiskStale(); err != nil {
 		return err
 	}
 
@@ -464,7 +561,7 @@ func (p *xlStorageDiskIDCheck) VerifyFile(ctx context.Context, volume, path stri
 }
 
 func (p *xlStorageDiskIDCheck) WriteAll(ctx context.Context, volume string, path string, b []byte) (err error) {
-	defer p.updateStorageMetrics(storageMetricWriteAll, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricWriteAll, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -478,7 +575,7 @@ func (p *xlStorageDiskIDCheck) WriteAll(ctx context.Context, volume string, path
 }
 
 func (p *xlStorageDiskIDCheck) DeleteVersion(ctx context.Context, volume, path string, fi FileInfo, forceDelMarker bool) (err error) {
-	defer p.updateStorageMetrics(storageMetricDeleteVersion, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricDeleteVersion, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -492,7 +589,7 @@ func (p *xlStorageDiskIDCheck) DeleteVersion(ctx context.Context, volume, path s
 }
 
 func (p *xlStorageDiskIDCheck) UpdateMetadata(ctx context.Context, volume, path string, fi FileInfo) (err error) {
-	defer p.updateStorageMetrics(storageMetricUpdateMetadata, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricUpdateMetadata, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -506,7 +603,7 @@ func (p *xlStorageDiskIDCheck) UpdateMetadata(ctx context.Context, volume, path
 }
 
 func (p *xlStorageDiskIDCheck) WriteMetadata(ctx context.Context, volume, path string, fi FileInfo) (err error) {
-	defer p.updateStorageMetrics(storageMetricWriteMetadata, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricWriteMetadata, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return ctx.Err()
@@ -520,7 +617,7 @@ func (p *xlStorageDiskIDCheck) WriteMetadata(ctx context.Context, volume, path s
 }
 
 func (p *xlStorageDiskIDCheck) ReadVersion(ctx context.Context, volume, path, versionID string, readData bool) (fi FileInfo, err error) {
-	defer p.updateStorageMetrics(storageMetricReadVersion, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricReadVersion, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return fi, ctx.Err()
@@ -534,7 +631,7 @@ func (p *xlStorageDiskIDCheck) ReadVersion(ctx context.Context, volume, path, ve
 }
 
 func (p *xlStorageDiskIDCheck) ReadAll(ctx context.Context, volume string, path string) (buf []byte, err error) {
-	defer p.updateStorageMetrics(storageMetricReadAll, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageMetricReadAll, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return nil, ctx.Err()
@@ -548,7 +645,7 @@ func (p *xlStorageDiskIDCheck) ReadAll(ctx context.Context, volume string, path
 }
 
 func (p *xlStorageDiskIDCheck) StatInfoFile(ctx context.Context, volume, path string, glob bool) (stat []StatInfo, err error) {
-	defer p.updateStorageMetrics(storageStatInfoFile, volume, path)()
+	defer p.updateStorageMetrics(ctx, storageStatInfoFile, volume, path)(&err)
 
 	if contextCanceled(ctx) {
 		return nil, ctx.Err()
@@ -574,15 +671,52 @@ func storageTrace(s storageMetric, startTime time.Time, duration time.Duration,
 	}
 }
 
-// Update storage metrics
-func (p *xlStorageDiskIDCheck) updateStorageMetrics(s storageMetric, paths ...string) func() {
+// updateErrorMetrics accounts the outcome of a storage call, expected
+// errors such as missing files do not count as drive errors.
+func (p *xlStorageDiskIDCheck) updateErrorMetrics(err error) {
+	switch {
+	case err == nil:
+		atomic.StoreInt32(&p.ioStats.offline, 0)
+		return
+	case IsErr(err, errFileNotFound, errFileVersionNotFound, errVolumeNotFound,
+		errVolumeExists, errVolumeNotEmpty, errPathNotFound, errIsNotRegular,
+		errFileNameTooLong, errDoneForNow, errSkipFile, context.Canceled):
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		atomic.AddUint64(&p.ioStats.totalTimeouts, 1)
+	case IsErr(err, errDiskNotFound, errFaultyDisk):
+		if atomic.CompareAndSwapInt32(&p.ioStats.offline, 0, 1) {
+			atomic.AddUint64(&p.ioStats.offlineEvents, 1)
+		}
+	}
+	atomic.AddUint64(&p.ioStats.totalErrors, 1)
+}
+
+// Update storage metrics, err points to the error returned
+// by the call, if any.
+func (p *xlStorageDiskIDCheck) updateStorageMetrics(ctx context.Context, s storageMetric, paths ...string) func(err *error) {
 	startTime := time.Now()
 	trace := globalTrace.NumSubscribers() > 0
-	return func() {
+	_, span := tracing.Start(ctx, "storage."+s.String(), tracing.KindInternal)
+	if span != nil {
+		span.SetAttr("disk", p.String())
+		span.SetAttr("paths", strings.Join(paths, " "))
+	}
+	return func(err *error) {
 		duration := time.Since(startTime)
+		if err != nil {
+			span.SetError(*err)
+			p.updateErrorMetrics(*err)
+		}
+		span.End()
 
 		atomic.AddUint64(&p.apiCalls[s], 1)
 		p.apiLatencies[s].Add(float64(duration))
+		if isReadMetric(s) {
+			p.ioStats.readLatency.add(duration)
+		} else if isWriteMetric(s) {
+			p.ioStats.writeLatency.add(duration)
+		}
 
 		if trace {
 			globalTrace.Publish(storageTrace(s, startTime, duration, strings.Join(paths, " ")))
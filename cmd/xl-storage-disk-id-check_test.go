@@ -0,0 +1,88 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLatencyWindowPercentiles(t *testing.T) {
+	var w latencyWindow
+	if p50, p90, p99 := w.percentiles(); p50 != 0 || p90 != 0 || p99 != 0 {
+		t.Fatalf("expected zero percentiles for an empty window, got %v %v %v", p50, p90, p99)
+	}
+	// Older samples are overwritten once the window is full.
+	for i := 0; i < latencyWindowSize; i++ {
+		w.add(time.Hour)
+	}
+	for i := 1; i <= latencyWindowSize; i++ {
+		w.add(time.Duration(i) * time.Millisecond)
+	}
+	p50, p90, p99 := w.percentiles()
+	if p50 != 128*time.Millisecond || p90 != 230*time.Millisecond || p99 != 253*time.Millisecond {
+		t.Errorf("unexpected percentiles %v %v %v", p50, p90, p99)
+	}
+}
+
+func TestDiskErrorMetrics(t *testing.T) {
+	p := newXLStorageDiskIDCheck(nil)
+	for _, err := range []error{
+		nil,
+		errFileNotFound,
+		errVolumeNotFound,
+		context.Canceled,
+		errFaultyDisk,
+		errDiskNotFound,
+		context.DeadlineExceeded,
+		nil,
+		errDiskNotFound,
+		errFileCorrupt,
+	} {
+		p.updateErrorMetrics(err)
+	}
+	m := p.getMetrics()
+	if m.TotalErrors != 5 {
+		t.Errorf("expected 5 errors, got %d", m.TotalErrors)
+	}
+	if m.TotalTimeouts != 1 {
+		t.Errorf("expected 1 timeout, got %d", m.TotalTimeouts)
+	}
+	if m.OfflineEvents != 2 {
+		t.Errorf("expected 2 offline events, got %d", m.OfflineEvents)
+	}
+}
+
+func TestToMadminDiskMetrics(t *testing.T) {
+	dm := toMadminDiskMetrics(DiskMetrics{
+		APILatencies:  map[string]string{"ReadFile": "1ms"},
+		APICalls:      map[string]uint64{"ReadFile": 3},
+		IOLatencies:   map[string]uint64{"ReadP99": uint64(2 * time.Millisecond)},
+		TotalErrors:   4,
+		TotalTimeouts: 2,
+		OfflineEvents: 1,
+	})
+	if dm.APICalls["ReadFile"] != 3 || dm.APICalls[diskMetricTotalErrors] != 4 ||
+		dm.APICalls[diskMetricTotalTimeouts] != 2 || dm.APICalls[diskMetricOfflineEvents] != 1 {
+		t.Errorf("unexpected API calls %v", dm.APICalls)
+	}
+	if dm.APILatencies["ReadFile"] != "1ms" || dm.APILatencies["IOReadP99"] != "2ms" {
+		t.Errorf("unexpected API latencies %v", dm.APILatencies)
+	}
+}
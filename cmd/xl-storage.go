@@ -42,6 +42,7 @@ import (
 	"github.com/minio/minio/internal/bucket/lifecycle"
 	"github.com/minio/minio/internal/color"
 	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/config/storageclass"
 	"github.com/minio/minio/internal/disk"
 	xioutil "github.com/minio/minio/internal/ioutil"
 	"github.com/minio/minio/internal/logger"
@@ -112,6 +113,10 @@ type xlStorage struct {
 	// mutex to prevent concurrent read operations overloading walks.
 	walkMu     sync.Mutex
 	walkReadMu sync.Mutex
+
+	// syncs the drive for the objects committed with the batched
+	// fsync policy.
+	fsyncBatch fsyncBatcher
 }
 
 // checkPathLength - returns error if given path name length more than 255
@@ -402,7 +407,8 @@ func (s *xlStorage) NSScanner(ctx context.Context, cache dataUsageCache, updates
 	// Check if the current bucket has a configured lifecycle policy
 	if globalLifecycleSys != nil {
 		lc, err = globalLifecycleSys.Get(cache.Info.Name)
-		if err == nil && lc.HasActiveRules("", true) {
+		// Lifecycle rules do not expire nor transition archived objects.
+		if err == nil && lc.HasActiveRules("", true) && !isBucketArchived(cache.Info.Name) {
 			cache.Info.lifeCycle = lc
 			if intDataUpdateTracker.debug {
 				console.Debugln(color.Green("scannerDisk:") + " lifecycle: Active rules found")
@@ -435,6 +441,12 @@ func (s *xlStorage) NSScanner(ctx context.Context, cache dataUsageCache, updates
 
 	cache.Info.updates = updates
 
+	// Recorded access times, nil when access tracking is disabled.
+	accessTimes := globalAccessTracker.bucketAccessTimes(ctx, objAPI, cache.Info.Name)
+
+	// Whether the latest versions are written to the metadata sink.
+	backfill := globalMetadataSink.backfilling(ctx, objAPI, cache.Info.Name, cache.Info.NextCycle)
+
 	dataUsageInfo, err := scanDataFolder(ctx, s.diskPath, cache, func(item scannerItem) (sizeSummary, error) {
 		// Look for `xl.meta/xl.json' at the leaf.
 		if !strings.HasSuffix(item.Path, SlashSeparator+xlStorageFormatFile) &&
@@ -468,14 +480,45 @@ func (s *xlStorage) NSScanner(ctx context.Context, cache dataUsageCache, updates
 			sizeS.tiers = make(map[string]tierStats)
 		}
 		atomic.AddUint64(&globalScannerStats.accTotalObjects, 1)
+		var lastAccess time.Time
 		for _, version := range fivs.Versions {
 			atomic.AddUint64(&globalScannerStats.accTotalVersions, 1)
 			oi := version.ToObjectInfo(item.bucket, item.objectPath())
 			sz := item.applyActions(ctx, objAPI, oi, &sizeS)
+			if backfill && oi.IsLatest {
+				globalMetadataSink.backfill(ctx, oi)
+			}
 			if !oi.DeleteMarker && sz == oi.Size {
 				sizeS.versions++
 			}
 			sizeS.totalSize += sz
+			if !oi.IsLatest {
+				sizeS.noncurrentSize += sz
+			}
+			if sz > 0 && oi.IsCompressed() && !oi.TransitionedObject.FreeVersion && oi.TransitionedObject.Status != lifecycle.TransitionComplete {
+				if sizeS.compressedSizes == nil {
+					sizeS.compressedSizes = &compressedSizes{}
+				}
+				sizeS.compressedSizes.add(oi.Size, sz)
+			}
+			if oi.IsLatest && !oi.DeleteMarker {
+				sizeS.contentType, sizeS.extension = contentStatsKeys(oi)
+				lastAccess = oi.ModTime
+			}
+
+			if !oi.DeleteMarker && !oi.TransitionedObject.FreeVersion {
+				sc := oi.StorageClass
+				if sc == "" {
+					sc = storageclass.STANDARD
+				}
+				if oi.TransitionedObject.Status == lifecycle.TransitionComplete {
+					sc = oi.TransitionedObject.Tier
+				}
+				if sizeS.storageClasses == nil {
+					sizeS.storageClasses = make(map[string]tierStats)
+				}
+				sizeS.storageClasses[sc] = sizeS.storageClasses[sc].add(oi.tierStats())
+			}
 
 			// Skip tier accounting if,
 			// 1. no tiers configured
@@ -492,6 +535,17 @@ func (s *xlStorage) NSScanner(ctx context.Context, cache dataUsageCache, updates
 			}
 			sizeS.tiers[tier] = sizeS.tiers[tier].add(oi.tierStats())
 		}
+
+		// Objects never read since tracking was enabled are accounted
+		// as last accessed when their latest version was written.
+		if accessTimes != nil && !lastAccess.IsZero() {
+			day := accessDay(lastAccess)
+			if d := accessTimes[item.objectPath()]; d > day {
+				day = d
+			}
+			cs := coldSizesOf(sizeS.totalSize, day)
+			sizeS.coldSizes = &cs
+		}
 		return sizeS, nil
 	})
 
@@ -1556,10 +1610,19 @@ func (s *xlStorage) CreateFile(ctx context.Context, volume, path string, fileSiz
 		}
 	}()
 
+	// Files written without sync are left to the page cache to be written
+	// back, they are lost if the node crashes before.
+	sync := fsyncPolicyFromContext(ctx) == fsyncAlways
+
 	if fileSize >= 0 && fileSize <= smallFileThreshold {
 		// For streams smaller than 128KiB we simply write them as O_DSYNC (fdatasync)
 		// and not O_DIRECT to avoid the complexities of aligned I/O.
-		w, err := s.openFileSync(filePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL)
+		var w *os.File
+		if sync {
+			w, err = s.openFileSync(filePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL)
+		} else {
+			w, err = s.openFileNoSync(filePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL)
+		}
 		if err != nil {
 			return err
 		}
@@ -1591,7 +1654,9 @@ func (s *xlStorage) CreateFile(ctx context.Context, volume, path string, fileSiz
 	}
 
 	defer func() {
-		disk.Fdatasync(w) // Only interested in flushing the size_t not mtime/atime
+		if sync {
+			disk.Fdatasync(w) // Only interested in flushing the size_t not mtime/atime
+		}
 		w.Close()
 	}()
 
@@ -1828,11 +1893,15 @@ func (s *xlStorage) Delete(ctx context.Context, volume string, path string, recu
 
 // RenameData - rename source path to destination path atomically, metadata and data directory.
 func (s *xlStorage) RenameData(ctx context.Context, srcVolume, srcPath string, fi FileInfo, dstVolume, dstPath string) (err error) {
+	policy := fsyncPolicyFromContext(ctx)
 	defer func() {
 		if err == nil {
 			if s.globalSync {
 				globalSync()
 			}
+			if policy == fsyncBatched {
+				err = s.fsyncBatch.sync(s.diskPath)
+			}
 		}
 	}()
 
@@ -2045,7 +2114,7 @@ func (s *xlStorage) RenameData(ctx context.Context, srcVolume, srcPath string, f
 	}
 
 	if srcDataPath != "" {
-		if err = s.WriteAll(ctx, srcVolume, pathJoin(srcPath, xlStorageFormatFile), dstBuf); err != nil {
+		if err = s.writeAll(ctx, srcVolume, pathJoin(srcPath, xlStorageFormatFile), dstBuf, policy == fsyncAlways); err != nil {
 			if legacyPreserved {
 				// Any failed rename calls un-roll previous transaction.
 				s.deleteFile(dstVolumeDir, legacyDataPath, true)
@@ -2092,7 +2161,7 @@ func (s *xlStorage) RenameData(ctx context.Context, srcVolume, srcPath string, f
 		}
 	} else {
 		// Write meta-file directly, no data
-		if err = s.WriteAll(ctx, dstVolume, pathJoin(dstPath, xlStorageFormatFile), dstBuf); err != nil {
+		if err = s.writeAll(ctx, dstVolume, pathJoin(dstPath, xlStorageFormatFile), dstBuf, policy == fsyncAlways); err != nil {
 			if legacyPreserved {
 				// Any failed rename calls un-roll previous transaction.
 				s.deleteFile(dstVolumeDir, legacyDataPath, true)
@@ -2191,6 +2260,37 @@ func (s *xlStorage) RenameFile(ctx context.Context, srcVolume, srcPath, dstVolum
 	return nil
 }
 
+// LinkFile - hard links the source file at the destination path, both
+// volumes being on the same drive they share the data of the file.
+func (s *xlStorage) LinkFile(ctx context.Context, srcVolume, srcPath, dstVolume, dstPath string) (err error) {
+	srcVolumeDir, err := s.getVolDir(srcVolume)
+	if err != nil {
+		return err
+	}
+	dstVolumeDir, err := s.getVolDir(dstVolume)
+	if err != nil {
+		return err
+	}
+	if HasSuffix(srcPath, SlashSeparator) || HasSuffix(dstPath, SlashSeparator) {
+		return errFileAccessDenied
+	}
+	srcFilePath := pathutil.Join(srcVolumeDir, srcPath)
+	if err = checkPathLength(srcFilePath); err != nil {
+		return err
+	}
+	dstFilePath := pathutil.Join(dstVolumeDir, dstPath)
+	if err = checkPathLength(dstFilePath); err != nil {
+		return err
+	}
+	if err = mkdirAll(pathutil.Dir(dstFilePath), 0777); err != nil {
+		return osErrToFileErr(err)
+	}
+	if err = os.Link(srcFilePath, dstFilePath); err != nil {
+		return osErrToFileErr(err)
+	}
+	return nil
+}
+
 func (s *xlStorage) bitrotVerify(partPath string, partSize int64, algo BitrotAlgorithm, sum []byte, shardSize int64) error {
 	// Open the file for reading.
 	file, err := Open(partPath)
@@ -0,0 +1,455 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package avro reads the records of Avro object container files, such
+// as the manifests of Iceberg tables, decoded with the writer schema.
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const magic = "Obj\x01"
+
+// maxBlockSize bounds the blocks, and strings or bytes, read.
+const maxBlockSize = 64 << 20
+
+// ErrInvalid is returned for data which is not a valid Avro file.
+var ErrInvalid = errors.New("invalid avro data")
+
+// schema - a node of a parsed schema. Records are decoded to
+// map[string]interface{}, arrays to []interface{}, maps to
+// map[string]interface{}, enums and strings to string, bytes and fixed
+// to []byte, int and long to int64, float and double to float64.
+type schema struct {
+	typ     string
+	name    string
+	fields  []field
+	symbols []string
+	items   *schema
+	values  *schema
+	union   []*schema
+	size    int
+}
+
+type field struct {
+	name   string
+	schema *schema
+}
+
+// parser resolves the references to the named types of a schema.
+type parser struct {
+	named map[string]*schema
+}
+
+func (p *parser) parse(v interface{}, namespace string) (*schema, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &schema{typ: v}, nil
+		}
+		name := v
+		if !strings.Contains(name, ".") && namespace != "" {
+			name = namespace + "." + name
+		}
+		if s, ok := p.named[name]; ok {
+			return s, nil
+		}
+		if s, ok := p.named[v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalid, v)
+	case []interface{}:
+		s := &schema{typ: "union"}
+		for _, t := range v {
+			ts, err := p.parse(t, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.union = append(s.union, ts)
+		}
+		return s, nil
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		s := &schema{typ: typ}
+		switch typ {
+		case "record", "error", "enum", "fixed":
+			name, _ := v["name"].(string)
+			if ns, ok := v["namespace"].(string); ok && !strings.Contains(name, ".") {
+				namespace = ns
+			}
+			if !strings.Contains(name, ".") && namespace != "" {
+				name = namespace + "." + name
+			}
+			s.name = name
+			p.named[name] = s
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				namespace = name[:i]
+			}
+		}
+		switch typ {
+		case "record", "error":
+			s.typ = "record"
+			fields, _ := v["fields"].([]interface{})
+			for _, f := range fields {
+				fm, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, ErrInvalid
+				}
+				name, _ := fm["name"].(string)
+				fs, err := p.parse(fm["type"], namespace)
+				if err != nil {
+					return nil, err
+				}
+				s.fields = append(s.fields, field{name: name, schema: fs})
+			}
+		case "enum":
+			symbols, _ := v["symbols"].([]interface{})
+			for _, sym := range symbols {
+				name, _ := sym.(string)
+				s.symbols = append(s.symbols, name)
+			}
+		case "array":
+			items, err := p.parse(v["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+		case "map":
+			values, err := p.parse(v["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.values = values
+		case "fixed":
+			size, _ := v["size"].(float64)
+			s.size = int(size)
+		default:
+			// A primitive type with attributes, such as a logical type.
+			return p.parse(typ, namespace)
+		}
+		return s, nil
+	}
+	return nil, ErrInvalid
+}
+
+// decoder decodes values of the binary encoding.
+type decoder struct {
+	r io.ByteReader
+	b *bytes.Reader
+}
+
+func (d decoder) long() (int64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (d decoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxBlockSize || n > int64(d.b.Len()) {
+		return nil, ErrInvalid
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(d.b, buf)
+	return buf, err
+}
+
+func (d decoder) decode(s *schema) (interface{}, error) {
+	switch s.typ {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := d.r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		return d.long()
+	case "float":
+		var buf [4]byte
+		if _, err := io.ReadFull(d.b, buf[:]); err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))), nil
+	case "double":
+		var buf [8]byte
+		if _, err := io.ReadFull(d.b, buf[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf[:])), nil
+	case "bytes":
+		return d.bytes()
+	case "string":
+		b, err := d.bytes()
+		return string(b), err
+	case "fixed":
+		if s.size < 0 || s.size > d.b.Len() {
+			return nil, ErrInvalid
+		}
+		buf := make([]byte, s.size)
+		_, err := io.ReadFull(d.b, buf)
+		return buf, err
+	case "enum":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.symbols)) {
+			return nil, ErrInvalid
+		}
+		return s.symbols[i], nil
+	case "union":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.union)) {
+			return nil, ErrInvalid
+		}
+		return d.decode(s.union[i])
+	case "record":
+		m := make(map[string]interface{}, len(s.fields))
+		for _, f := range s.fields {
+			v, err := d.decode(f.schema)
+			if err != nil {
+				return nil, err
+			}
+			m[f.name] = v
+		}
+		return m, nil
+	case "array":
+		var a []interface{}
+		err := d.blocks(func() error {
+			v, err := d.decode(s.items)
+			a = append(a, v)
+			return err
+		})
+		return a, err
+	case "map":
+		m := make(map[string]interface{})
+		err := d.blocks(func() error {
+			k, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			v, err := d.decode(s.values)
+			m[string(k)] = v
+			return err
+		})
+		return m, err
+	}
+	return nil, fmt.Errorf("%w: unsupported type %q", ErrInvalid, s.typ)
+}
+
+// blocks calls fn for the items of the blocks of an array or a map.
+func (d decoder) blocks(fn func() error) error {
+	for {
+		n, err := d.long()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			// The count is followed by the size of the block.
+			n = -n
+			if _, err = d.long(); err != nil {
+				return err
+			}
+		}
+		if n > int64(d.b.Len()) {
+			return ErrInvalid
+		}
+		for ; n > 0; n-- {
+			if err = fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Reader reads the records of an object container file.
+type Reader struct {
+	r      *bufio.Reader
+	schema *schema
+	codec  string
+	sync   [16]byte
+
+	block *bytes.Reader
+	count int64
+}
+
+// NewReader reads the header of the object container file of r.
+func NewReader(r io.Reader) (*Reader, error) {
+	ar := &Reader{r: bufio.NewReader(r)}
+	var head [4]byte
+	if _, err := io.ReadFull(ar.r, head[:]); err != nil || string(head[:]) != magic {
+		return nil, ErrInvalid
+	}
+
+	// The metadata map is read as the block of a map of bytes.
+	meta := make(map[string][]byte)
+	hd := decoder{r: ar.r}
+	for {
+		n, err := hd.long()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		if n < 0 {
+			n = -n
+			if _, err = hd.long(); err != nil {
+				return nil, err
+			}
+		}
+		for ; n > 0; n-- {
+			k, err := ar.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := ar.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			meta[string(k)] = v
+		}
+	}
+	if _, err := io.ReadFull(ar.r, ar.sync[:]); err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(meta["avro.schema"], &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	s, err := (&parser{named: make(map[string]*schema)}).parse(v, "")
+	if err != nil {
+		return nil, err
+	}
+	ar.schema = s
+	ar.codec = string(meta["avro.codec"])
+	switch ar.codec {
+	case "", "null", "deflate", "snappy", "zstandard":
+	default:
+		return nil, fmt.Errorf("%w: unsupported codec %q", ErrInvalid, ar.codec)
+	}
+	return ar, nil
+}
+
+func (ar *Reader) readBytes() ([]byte, error) {
+	n, err := decoder{r: ar.r}.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxBlockSize {
+		return nil, ErrInvalid
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(ar.r, buf)
+	return buf, err
+}
+
+// nextBlock reads and decompresses the next block of records.
+func (ar *Reader) nextBlock() error {
+	d := decoder{r: ar.r}
+	count, err := d.long()
+	if err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return err
+	}
+	data, err := ar.readBytes()
+	if err != nil {
+		return err
+	}
+	var sync [16]byte
+	if _, err = io.ReadFull(ar.r, sync[:]); err != nil {
+		return err
+	}
+	if sync != ar.sync || count < 0 {
+		return ErrInvalid
+	}
+
+	switch ar.codec {
+	case "deflate":
+		if data, err = ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(data)), maxBlockSize)); err != nil {
+			return err
+		}
+	case "snappy":
+		// The compressed data is followed by the CRC-32 of the data.
+		if len(data) < 4 {
+			return ErrInvalid
+		}
+		crc := binary.BigEndian.Uint32(data[len(data)-4:])
+		if data, err = snappy.Decode(nil, data[:len(data)-4]); err != nil {
+			return err
+		}
+		if crc32.ChecksumIEEE(data) != crc {
+			return ErrInvalid
+		}
+	case "zstandard":
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		data, err = ioutil.ReadAll(io.LimitReader(zr, maxBlockSize))
+		zr.Close()
+		if err != nil {
+			return err
+		}
+	}
+	ar.block, ar.count = bytes.NewReader(data), count
+	return nil
+}
+
+// Read returns the next record, io.EOF after the last one.
+func (ar *Reader) Read() (interface{}, error) {
+	for ar.count == 0 {
+		if err := ar.nextBlock(); err != nil {
+			return nil, err
+		}
+	}
+	ar.count--
+	v, err := decoder{r: ar.block, b: ar.block}.decode(ar.schema)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = ErrInvalid
+	}
+	return v, err
+}
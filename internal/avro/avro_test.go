@@ -0,0 +1,164 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package avro
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func appendLong(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(v<<1)^uint64(v>>63))
+	return append(b, buf[:n]...)
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendLong(b, int64(len(s))), s...)
+}
+
+// manifestSchema mimics the schema of the entries of Iceberg manifests.
+const manifestSchema = `{"type":"record","name":"manifest_entry","fields":[
+	{"name":"status","type":"int"},
+	{"name":"snapshot_id","type":["null","long"]},
+	{"name":"data_file","type":{"type":"record","name":"r2","fields":[
+		{"name":"file_path","type":"string"},
+		{"name":"file_format","type":{"type":"enum","name":"format","symbols":["AVRO","PARQUET","ORC"]}},
+		{"name":"record_count","type":"long"},
+		{"name":"split_offsets","type":["null",{"type":"array","items":"long"}]},
+		{"name":"metadata","type":{"type":"map","values":"bytes"}},
+		{"name":"key_metadata","type":["null",{"type":"fixed","name":"key","size":2}]}
+	]}},
+	{"name":"previous","type":["null","r2"]}
+]}`
+
+func encodeEntry(path string) []byte {
+	var b []byte
+	b = appendLong(b, 1)
+	b = appendLong(b, 1)
+	b = appendLong(b, 42)
+	b = appendString(b, path)
+	b = appendLong(b, 1)
+	b = appendLong(b, 100)
+	b = appendLong(b, 1)
+	b = appendLong(b, -2)
+	b = appendLong(b, 2)
+	b = appendLong(b, 4)
+	b = appendLong(b, 1024)
+	b = appendLong(b, 0)
+	b = appendLong(b, 1)
+	b = appendString(b, "k")
+	b = appendString(b, "v")
+	b = appendLong(b, 0)
+	b = appendLong(b, 1)
+	b = append(b, 0xca, 0xfe)
+	b = appendLong(b, 0)
+	return b
+}
+
+func encodeFile(codec string, blocks [][][]byte) []byte {
+	sync := []byte("0123456789abcdef")
+	b := []byte(magic)
+	b = appendLong(b, 2)
+	b = appendString(b, "avro.schema")
+	b = appendString(b, manifestSchema)
+	b = appendString(b, "avro.codec")
+	b = appendString(b, codec)
+	b = appendLong(b, 0)
+	b = append(b, sync...)
+	for _, records := range blocks {
+		var data []byte
+		for _, r := range records {
+			data = append(data, r...)
+		}
+		if codec == "deflate" {
+			var buf bytes.Buffer
+			w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+			w.Write(data)
+			w.Close()
+			data = buf.Bytes()
+		}
+		b = appendLong(b, int64(len(records)))
+		b = appendString(b, string(data))
+		b = append(b, sync...)
+	}
+	return b
+}
+
+func TestReader(t *testing.T) {
+	want := map[string]interface{}{
+		"status":      int64(1),
+		"snapshot_id": int64(42),
+		"data_file": map[string]interface{}{
+			"file_path":     "s3://warehouse/db/t/data/a.parquet",
+			"file_format":   "PARQUET",
+			"record_count":  int64(100),
+			"split_offsets": []interface{}{int64(4), int64(1024)},
+			"metadata":      map[string]interface{}{"k": []byte("v")},
+			"key_metadata":  []byte{0xca, 0xfe},
+		},
+		"previous": nil,
+	}
+	for _, codec := range []string{"null", "deflate"} {
+		data := encodeFile(codec, [][][]byte{
+			{encodeEntry("s3://warehouse/db/t/data/a.parquet"), encodeEntry("s3://warehouse/db/t/data/b.parquet")},
+			{encodeEntry("s3://warehouse/db/t/data/c.parquet")},
+		})
+		r, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", codec, err)
+		}
+		var paths []string
+		for {
+			v, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", codec, err)
+			}
+			if len(paths) == 0 && !reflect.DeepEqual(v, want) {
+				t.Fatalf("%s: expected %v, got %v", codec, want, v)
+			}
+			paths = append(paths, v.(map[string]interface{})["data_file"].(map[string]interface{})["file_path"].(string))
+		}
+		if len(paths) != 3 || paths[2] != "s3://warehouse/db/t/data/c.parquet" {
+			t.Fatalf("%s: unexpected records %v", codec, paths)
+		}
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("PAR1"))); err != ErrInvalid {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	data := encodeFile("null", [][][]byte{{encodeEntry("a")}})
+	// Truncate the record.
+	data[len(data)-20] = 0xff
+	r, err := NewReader(bytes.NewReader(data[:len(data)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Read(); err == nil {
+		t.Fatal("expected truncated data to fail")
+	}
+}
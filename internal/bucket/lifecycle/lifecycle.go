@@ -468,3 +468,25 @@ func (lc Lifecycle) TransitionTier(obj ObjectOpts) string {
 	}
 	return ""
 }
+
+// AbortIncompleteUploadAfter returns the age after which incomplete
+// multipart uploads of object are aborted, the smallest of the enabled
+// rules matching object.
+func (lc Lifecycle) AbortIncompleteUploadAfter(object string) (time.Duration, bool) {
+	var days ExpirationDays
+	for _, rule := range lc.Rules {
+		if rule.Status == Disabled || rule.AbortIncompleteMultipartUpload.IsDaysNull() {
+			continue
+		}
+		if !strings.HasPrefix(object, rule.GetPrefix()) {
+			continue
+		}
+		if d := rule.AbortIncompleteMultipartUpload.DaysAfterInitiation; days == 0 || d < days {
+			days = d
+		}
+	}
+	if days == 0 {
+		return 0, false
+	}
+	return time.Duration(days) * 24 * time.Hour, true
+}
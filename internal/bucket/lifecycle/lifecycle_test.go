@@ -619,3 +619,52 @@ func TestTransitionTier(t *testing.T) {
 		t.Fatalf("Expected TIER-2 but got %s", got)
 	}
 }
+
+func TestAbortIncompleteUploadAfter(t *testing.T) {
+	lc := Lifecycle{
+		Rules: []Rule{
+			{
+				ID:     "rule-1",
+				Status: "Enabled",
+				AbortIncompleteMultipartUpload: AbortIncompleteMultipartUpload{
+					DaysAfterInitiation: ExpirationDays(7),
+				},
+			},
+			{
+				ID:     "rule-2",
+				Status: "Enabled",
+				Filter: Filter{Prefix: Prefix{string: "tmp/", set: true}},
+				AbortIncompleteMultipartUpload: AbortIncompleteMultipartUpload{
+					DaysAfterInitiation: ExpirationDays(1),
+				},
+			},
+			{
+				ID:     "rule-3",
+				Status: "Disabled",
+				Filter: Filter{Prefix: Prefix{string: "logs/", set: true}},
+				AbortIncompleteMultipartUpload: AbortIncompleteMultipartUpload{
+					DaysAfterInitiation: ExpirationDays(1),
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		object   string
+		expected time.Duration
+	}{
+		{"obj1", 7 * 24 * time.Hour},
+		{"tmp/obj1", 24 * time.Hour},
+		{"logs/obj1", 7 * 24 * time.Hour},
+	}
+	for i, tc := range testCases {
+		got, ok := lc.AbortIncompleteUploadAfter(tc.object)
+		if !ok || got != tc.expected {
+			t.Fatalf("%d: Expected %v but got %v", i+1, tc.expected, got)
+		}
+	}
+
+	if _, ok := (Lifecycle{}).AbortIncompleteUploadAfter("obj1"); ok {
+		t.Fatal("Expected no age without rules")
+	}
+}
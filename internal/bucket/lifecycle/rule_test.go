@@ -61,6 +61,37 @@ func TestInvalidRules(t *testing.T) {
 	                    </Rule>`,
 			expectedErr: errInvalidRuleStatus,
 		},
+		{ // Rule aborting incomplete multipart uploads only
+			inputXML: ` <Rule>
+			                  <ID>rule aborting uploads</ID>
+			                  <Filter><Prefix>logs/</Prefix></Filter>
+			                  <AbortIncompleteMultipartUpload>
+			                      <DaysAfterInitiation>7</DaysAfterInitiation>
+			                  </AbortIncompleteMultipartUpload>
+                              <Status>Enabled</Status>
+	                    </Rule>`,
+			expectedErr: nil,
+		},
+		{ // Rule aborting incomplete multipart uploads with a tag filter
+			inputXML: ` <Rule>
+			                  <ID>rule aborting uploads with tags</ID>
+			                  <Filter><Tag><Key>key1</Key><Value>val1</Value></Tag></Filter>
+			                  <AbortIncompleteMultipartUpload>
+			                      <DaysAfterInitiation>7</DaysAfterInitiation>
+			                  </AbortIncompleteMultipartUpload>
+                              <Status>Enabled</Status>
+	                    </Rule>`,
+			expectedErr: errAbortMultipartWithTags,
+		},
+		{ // Rule aborting incomplete multipart uploads without days
+			inputXML: ` <Rule>
+			                  <ID>rule aborting uploads without days</ID>
+			                  <Filter><Prefix>logs/</Prefix></Filter>
+			                  <AbortIncompleteMultipartUpload></AbortIncompleteMultipartUpload>
+                              <Status>Enabled</Status>
+	                    </Rule>`,
+			expectedErr: errXMLNotWellFormed,
+		},
 	}
 
 	for i, tc := range invalidTestCases {
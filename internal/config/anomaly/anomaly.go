@@ -0,0 +1,192 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package anomaly
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// Access anomaly detection sub-system constants
+const (
+	Endpoint    = "endpoint"
+	AuthToken   = "auth_token"
+	Sensitivity = "sensitivity"
+	MinDeletes  = "min_deletes"
+	MinLists    = "min_lists"
+	MinEgress   = "min_egress"
+
+	EnvEnable      = "MINIO_ANOMALY_ENABLE"
+	EnvEndpoint    = "MINIO_ANOMALY_ENDPOINT"
+	EnvAuthToken   = "MINIO_ANOMALY_AUTH_TOKEN"
+	EnvSensitivity = "MINIO_ANOMALY_SENSITIVITY"
+	EnvMinDeletes  = "MINIO_ANOMALY_MIN_DELETES"
+	EnvMinLists    = "MINIO_ANOMALY_MIN_LISTS"
+	EnvMinEgress   = "MINIO_ANOMALY_MIN_EGRESS"
+)
+
+// Config represents the access anomaly detection settings. The deletes,
+// listings and egress bytes of each principal and bucket per minute are
+// compared to their baseline, an alert is posted to Endpoint when one
+// exceeds its minimum and Sensitivity times its baseline.
+type Config struct {
+	Enabled     bool      `json:"enabled"`
+	Endpoint    *xnet.URL `json:"endpoint"`
+	AuthToken   string    `json:"authToken"`
+	Sensitivity float64   `json:"sensitivity"`
+	MinDeletes  uint64    `json:"minDeletes"`
+	MinLists    uint64    `json:"minLists"`
+	MinEgress   uint64    `json:"minEgress"`
+}
+
+var (
+	// DefaultKVS - default KV config for access anomaly detection
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Endpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   AuthToken,
+			Value: "",
+		},
+		config.KV{
+			Key:   Sensitivity,
+			Value: "10",
+		},
+		config.KV{
+			Key:   MinDeletes,
+			Value: "1000",
+		},
+		config.KV{
+			Key:   MinLists,
+			Value: "300",
+		},
+		config.KV{
+			Key:   MinEgress,
+			Value: "10GiB",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Endpoint,
+			Description: `webhook endpoint alerts are posted to e.g. "https://alerts.example.net/minio"`,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         AuthToken,
+			Description: `opaque string or JWT authorization token sent to the webhook`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         Sensitivity,
+			Description: `alert when a minute exceeds its baseline by this factor, defaults to '10'`,
+			Optional:    true,
+			Type:        "float",
+		},
+		config.HelpKV{
+			Key:         MinDeletes,
+			Description: `minimum objects deleted per minute by a principal in a bucket to alert, defaults to '1000'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         MinLists,
+			Description: `minimum listing requests per minute by a principal in a bucket to alert, defaults to '300'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         MinEgress,
+			Description: `minimum bytes downloaded per minute by a principal from a bucket to alert, defaults to '10GiB'`,
+			Optional:    true,
+			Type:        "size",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup access anomaly detection config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.AnomalySubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	endpoint := env.Get(EnvEndpoint, kvs.Get(Endpoint))
+	if endpoint == "" {
+		return cfg, errors.New("'anomaly:endpoint' cannot be empty when anomaly detection is enabled")
+	}
+	cfg.Endpoint, err = xnet.ParseHTTPURL(endpoint)
+	if err != nil {
+		return cfg, fmt.Errorf("'anomaly:endpoint' value invalid: %w", err)
+	}
+	cfg.AuthToken = env.Get(EnvAuthToken, kvs.Get(AuthToken))
+
+	cfg.Sensitivity, err = strconv.ParseFloat(env.Get(EnvSensitivity, kvs.Get(Sensitivity)), 64)
+	if err != nil {
+		return cfg, fmt.Errorf("'anomaly:sensitivity' value invalid: %w", err)
+	}
+	if cfg.Sensitivity <= 1 {
+		return cfg, errors.New("'anomaly:sensitivity' must be greater than 1")
+	}
+
+	cfg.MinDeletes, err = strconv.ParseUint(env.Get(EnvMinDeletes, kvs.Get(MinDeletes)), 10, 64)
+	if err != nil {
+		return cfg, fmt.Errorf("'anomaly:min_deletes' value invalid: %w", err)
+	}
+	cfg.MinLists, err = strconv.ParseUint(env.Get(EnvMinLists, kvs.Get(MinLists)), 10, 64)
+	if err != nil {
+		return cfg, fmt.Errorf("'anomaly:min_lists' value invalid: %w", err)
+	}
+	cfg.MinEgress, err = humanize.ParseBytes(env.Get(EnvMinEgress, kvs.Get(MinEgress)))
+	if err != nil {
+		return cfg, fmt.Errorf("'anomaly:min_egress' value invalid: %w", err)
+	}
+	return cfg, nil
+}
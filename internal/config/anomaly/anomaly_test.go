@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package anomaly
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, endpoint, sensitivity, minEgress string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Endpoint, Value: endpoint},
+			config.KV{Key: AuthToken, Value: ""},
+			config.KV{Key: Sensitivity, Value: sensitivity},
+			config.KV{Key: MinDeletes, Value: "1000"},
+			config.KV{Key: MinLists, Value: "300"},
+			config.KV{Key: MinEgress, Value: minEgress},
+		}
+	}
+	testCases := []struct {
+		kvs       config.KVS
+		enabled   bool
+		minEgress uint64
+		success   bool
+	}{
+		{kvs(config.EnableOff, "", "10", "10GiB"), false, 0, true},
+		{kvs(config.EnableOn, "https://alerts:8080", "10", "10GiB"), true, 10 << 30, true},
+		{kvs(config.EnableOn, "https://alerts:8080", "2.5", "1MB"), true, 1000 * 1000, true},
+		{kvs(config.EnableOn, "", "10", "10GiB"), true, 0, false},
+		{kvs(config.EnableOn, "https://alerts:8080", "1", "10GiB"), true, 0, false},
+		{kvs(config.EnableOn, "https://alerts:8080", "10", "lots"), true, 0, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && (cfg.Enabled != testCase.enabled || cfg.MinEgress != testCase.minEgress) {
+			t.Errorf("Test %d: expected enabled %t and min egress %d, got %t and %d", i+1,
+				testCase.enabled, testCase.minEgress, cfg.Enabled, cfg.MinEgress)
+		}
+	}
+}
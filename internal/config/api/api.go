@@ -20,11 +20,15 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/minio/internal/config"
 	"github.com/minio/pkg/env"
 )
@@ -43,6 +47,24 @@ const (
 	apiStaleUploadsCleanupInterval = "stale_uploads_cleanup_interval"
 	apiStaleUploadsExpiry          = "stale_uploads_expiry"
 	apiDeleteCleanupInterval       = "delete_cleanup_interval"
+	apiBucketLatencyTopN           = "bucket_latency_top_n"
+	apiAccessTracking              = "access_tracking"
+	apiReadyQuorumMargin           = "ready_quorum_margin"
+	apiReadyHealBacklog            = "ready_heal_backlog"
+	apiThrottleAuthFailures        = "throttle_auth_failures"
+	apiThrottleRequestsRate        = "throttle_requests_rate"
+	apiThrottleBanDuration         = "throttle_ban_duration"
+	apiThrottleAllowlist           = "throttle_allowlist"
+	apiDiskHighWatermark           = "disk_high_watermark"
+	apiReplicationProxyNearest     = "replication_proxy_nearest"
+	apiListIndexThreshold          = "list_index_threshold"
+	apiHeadKeyFilter               = "head_key_filter"
+	apiCorrelationHeader           = "correlation_header"
+	apiSlowRequestsThreshold       = "slow_requests_threshold"
+	apiSlowRequestsMax             = "slow_requests_max"
+	apiMemoryCacheSize             = "memory_cache_size"
+	apiMemoryCacheObjectMax        = "memory_cache_object_max"
+	apiTrustedProxies              = "trusted_proxies"
 
 	EnvAPIRequestsMax              = "MINIO_API_REQUESTS_MAX"
 	EnvAPIRequestsDeadline         = "MINIO_API_REQUESTS_DEADLINE"
@@ -59,6 +81,24 @@ const (
 	EnvAPIStaleUploadsExpiry          = "MINIO_API_STALE_UPLOADS_EXPIRY"
 	EnvAPIDeleteCleanupInterval       = "MINIO_API_DELETE_CLEANUP_INTERVAL"
 	EnvDeleteCleanupInterval          = "MINIO_DELETE_CLEANUP_INTERVAL"
+	EnvAPIBucketLatencyTopN           = "MINIO_API_BUCKET_LATENCY_TOP_N"
+	EnvAPIAccessTracking              = "MINIO_API_ACCESS_TRACKING"
+	EnvAPIReadyQuorumMargin           = "MINIO_API_READY_QUORUM_MARGIN"
+	EnvAPIReadyHealBacklog            = "MINIO_API_READY_HEAL_BACKLOG"
+	EnvAPIThrottleAuthFailures        = "MINIO_API_THROTTLE_AUTH_FAILURES"
+	EnvAPIThrottleRequestsRate        = "MINIO_API_THROTTLE_REQUESTS_RATE"
+	EnvAPIThrottleBanDuration         = "MINIO_API_THROTTLE_BAN_DURATION"
+	EnvAPIThrottleAllowlist           = "MINIO_API_THROTTLE_ALLOWLIST"
+	EnvAPIDiskHighWatermark           = "MINIO_API_DISK_HIGH_WATERMARK"
+	EnvAPIReplicationProxyNearest     = "MINIO_API_REPLICATION_PROXY_NEAREST"
+	EnvAPIListIndexThreshold          = "MINIO_API_LIST_INDEX_THRESHOLD"
+	EnvAPIHeadKeyFilter               = "MINIO_API_HEAD_KEY_FILTER"
+	EnvAPICorrelationHeader           = "MINIO_API_CORRELATION_HEADER"
+	EnvAPISlowRequestsThreshold       = "MINIO_API_SLOW_REQUESTS_THRESHOLD"
+	EnvAPISlowRequestsMax             = "MINIO_API_SLOW_REQUESTS_MAX"
+	EnvAPIMemoryCacheSize             = "MINIO_API_MEMORY_CACHE_SIZE"
+	EnvAPIMemoryCacheObjectMax        = "MINIO_API_MEMORY_CACHE_OBJECT_MAX"
+	EnvAPITrustedProxies              = "MINIO_API_TRUSTED_PROXIES"
 )
 
 // Deprecated key and ENVs
@@ -118,6 +158,78 @@ var (
 			Key:   apiDeleteCleanupInterval,
 			Value: "5m",
 		},
+		config.KV{
+			Key:   apiBucketLatencyTopN,
+			Value: "0",
+		},
+		config.KV{
+			Key:   apiAccessTracking,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   apiReadyQuorumMargin,
+			Value: "",
+		},
+		config.KV{
+			Key:   apiReadyHealBacklog,
+			Value: "",
+		},
+		config.KV{
+			Key:   apiThrottleAuthFailures,
+			Value: "0",
+		},
+		config.KV{
+			Key:   apiThrottleRequestsRate,
+			Value: "0",
+		},
+		config.KV{
+			Key:   apiThrottleBanDuration,
+			Value: "1m",
+		},
+		config.KV{
+			Key:   apiThrottleAllowlist,
+			Value: "",
+		},
+		config.KV{
+			Key:   apiDiskHighWatermark,
+			Value: "99",
+		},
+		config.KV{
+			Key:   apiReplicationProxyNearest,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   apiListIndexThreshold,
+			Value: "100000000",
+		},
+		config.KV{
+			Key:   apiHeadKeyFilter,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   apiCorrelationHeader,
+			Value: "X-Correlation-Id",
+		},
+		config.KV{
+			Key:   apiSlowRequestsThreshold,
+			Value: "0s",
+		},
+		config.KV{
+			Key:   apiSlowRequestsMax,
+			Value: "1000",
+		},
+		config.KV{
+			Key:   apiMemoryCacheSize,
+			Value: "0",
+		},
+		config.KV{
+			Key:   apiMemoryCacheObjectMax,
+			Value: "128KiB",
+		},
+		config.KV{
+			Key:   apiTrustedProxies,
+			Value: "",
+		},
 	}
 )
 
@@ -135,6 +247,24 @@ type Config struct {
 	StaleUploadsCleanupInterval time.Duration `json:"stale_uploads_cleanup_interval"`
 	StaleUploadsExpiry          time.Duration `json:"stale_uploads_expiry"`
 	DeleteCleanupInterval       time.Duration `json:"delete_cleanup_interval"`
+	BucketLatencyTopN           int           `json:"bucket_latency_top_n"`
+	AccessTracking              bool          `json:"access_tracking"`
+	ReadyQuorumMargin           int           `json:"ready_quorum_margin"`
+	ReadyHealBacklog            int           `json:"ready_heal_backlog"`
+	ThrottleAuthFailures        int           `json:"throttle_auth_failures"`
+	ThrottleRequestsRate        int           `json:"throttle_requests_rate"`
+	ThrottleBanDuration         time.Duration `json:"throttle_ban_duration"`
+	ThrottleAllowlist           []string      `json:"throttle_allowlist"`
+	DiskHighWatermark           int           `json:"disk_high_watermark"`
+	ReplicationProxyNearest     bool          `json:"replication_proxy_nearest"`
+	ListIndexThreshold          int64         `json:"list_index_threshold"`
+	HeadKeyFilter               bool          `json:"head_key_filter"`
+	CorrelationHeader           string        `json:"correlation_header"`
+	SlowRequestsThreshold       time.Duration `json:"slow_requests_threshold"`
+	SlowRequestsMax             int           `json:"slow_requests_max"`
+	MemoryCacheSize             uint64        `json:"memory_cache_size"`
+	MemoryCacheObjectMax        uint64        `json:"memory_cache_object_max"`
+	TrustedProxies              []string      `json:"trusted_proxies"`
 }
 
 // UnmarshalJSON - Validate SS and RRS parity when unmarshalling JSON.
@@ -254,6 +384,174 @@ func LookupConfig(kvs config.KVS) (cfg Config, err error) {
 		return cfg, err
 	}
 
+	var bucketLatencyTopN int
+	if v := env.Get(EnvAPIBucketLatencyTopN, kvs.Get(apiBucketLatencyTopN)); v != "" {
+		bucketLatencyTopN, err = strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		if bucketLatencyTopN < 0 {
+			return cfg, errors.New("invalid API bucket latency top N value")
+		}
+	}
+
+	accessTracking, err := config.ParseBool(env.Get(EnvAPIAccessTracking, kvs.Get(apiAccessTracking)))
+	if err != nil {
+		return cfg, err
+	}
+
+	// Readiness gates are disabled unless set.
+	readyQuorumMargin := -1
+	if v := env.Get(EnvAPIReadyQuorumMargin, kvs.Get(apiReadyQuorumMargin)); v != "" {
+		readyQuorumMargin, err = strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		if readyQuorumMargin < 0 {
+			return cfg, errors.New("invalid API ready quorum margin value")
+		}
+	}
+
+	readyHealBacklog := -1
+	if v := env.Get(EnvAPIReadyHealBacklog, kvs.Get(apiReadyHealBacklog)); v != "" {
+		readyHealBacklog, err = strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		if readyHealBacklog < 0 {
+			return cfg, errors.New("invalid API ready heal backlog value")
+		}
+	}
+
+	throttleAuthFailures, err := strconv.Atoi(env.Get(EnvAPIThrottleAuthFailures, kvs.Get(apiThrottleAuthFailures)))
+	if err != nil {
+		return cfg, err
+	}
+	if throttleAuthFailures < 0 {
+		return cfg, errors.New("invalid API throttle auth failures value")
+	}
+
+	throttleRequestsRate, err := strconv.Atoi(env.Get(EnvAPIThrottleRequestsRate, kvs.Get(apiThrottleRequestsRate)))
+	if err != nil {
+		return cfg, err
+	}
+	if throttleRequestsRate < 0 {
+		return cfg, errors.New("invalid API throttle requests rate value")
+	}
+
+	throttleBanDuration, err := time.ParseDuration(env.Get(EnvAPIThrottleBanDuration, kvs.Get(apiThrottleBanDuration)))
+	if err != nil {
+		return cfg, err
+	}
+	if throttleBanDuration <= 0 {
+		return cfg, errors.New("invalid API throttle ban duration value")
+	}
+
+	var throttleAllowlist []string
+	for _, v := range strings.Split(env.Get(EnvAPIThrottleAllowlist, kvs.Get(apiThrottleAllowlist)), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if _, _, err = net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+			return cfg, fmt.Errorf("invalid API throttle allowlist entry '%s'", v)
+		}
+		throttleAllowlist = append(throttleAllowlist, v)
+	}
+
+	// Configs saved before the watermark was added have no value.
+	diskHighWatermark := 99
+	if v := env.Get(EnvAPIDiskHighWatermark, kvs.Get(apiDiskHighWatermark)); v != "" {
+		diskHighWatermark, err = strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		if diskHighWatermark <= 0 || diskHighWatermark > 100 {
+			return cfg, errors.New("invalid API disk high watermark value, must be a percentage between 1 and 100")
+		}
+	}
+
+	var replicationProxyNearest bool
+	if v := env.Get(EnvAPIReplicationProxyNearest, kvs.Get(apiReplicationProxyNearest)); v != "" {
+		replicationProxyNearest, err = config.ParseBool(v)
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	listIndexThreshold := int64(100000000)
+	if v := env.Get(EnvAPIListIndexThreshold, kvs.Get(apiListIndexThreshold)); v != "" {
+		listIndexThreshold, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return cfg, err
+		}
+		if listIndexThreshold < 0 {
+			return cfg, errors.New("invalid API list index threshold value, must be a positive number of objects or 0 to disable")
+		}
+	}
+
+	var headKeyFilter bool
+	if v := env.Get(EnvAPIHeadKeyFilter, kvs.Get(apiHeadKeyFilter)); v != "" {
+		headKeyFilter, err = config.ParseBool(v)
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	correlationHeader := env.Get(EnvAPICorrelationHeader, kvs.Get(apiCorrelationHeader))
+	if strings.ContainsAny(correlationHeader, " \t:") {
+		return cfg, fmt.Errorf("invalid API correlation header name %q", correlationHeader)
+	}
+
+	var slowRequestsThreshold time.Duration
+	if v := env.Get(EnvAPISlowRequestsThreshold, kvs.Get(apiSlowRequestsThreshold)); v != "" {
+		slowRequestsThreshold, err = time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		if slowRequestsThreshold < 0 {
+			return cfg, errors.New("invalid API slow requests threshold value, must be a positive duration or 0 to disable")
+		}
+	}
+
+	slowRequestsMax := 1000
+	if v := env.Get(EnvAPISlowRequestsMax, kvs.Get(apiSlowRequestsMax)); v != "" {
+		slowRequestsMax, err = strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		if slowRequestsMax <= 0 || slowRequestsMax > 100000 {
+			return cfg, errors.New("invalid API slow requests max value, must be between 1 and 100000")
+		}
+	}
+
+	var memoryCacheSize uint64
+	if v := env.Get(EnvAPIMemoryCacheSize, kvs.Get(apiMemoryCacheSize)); v != "" {
+		if memoryCacheSize, err = humanize.ParseBytes(v); err != nil {
+			return cfg, err
+		}
+	}
+
+	memoryCacheObjectMax := uint64(128 << 10)
+	if v := env.Get(EnvAPIMemoryCacheObjectMax, kvs.Get(apiMemoryCacheObjectMax)); v != "" {
+		if memoryCacheObjectMax, err = humanize.ParseBytes(v); err != nil {
+			return cfg, err
+		}
+		if memoryCacheObjectMax == 0 || memoryCacheObjectMax > 16<<20 {
+			return cfg, errors.New("invalid API memory cache object max value, must be between 1B and 16MiB")
+		}
+	}
+
+	var trustedProxies []string
+	for _, v := range strings.Split(env.Get(EnvAPITrustedProxies, kvs.Get(apiTrustedProxies)), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if _, _, err = net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+			return cfg, fmt.Errorf("invalid API trusted proxies entry '%s'", v)
+		}
+		trustedProxies = append(trustedProxies, v)
+	}
+
 	return Config{
 		RequestsMax:                 requestsMax,
 		RequestsDeadline:            requestsDeadline,
@@ -267,5 +565,23 @@ func LookupConfig(kvs config.KVS) (cfg Config, err error) {
 		StaleUploadsCleanupInterval: staleUploadsCleanupInterval,
 		StaleUploadsExpiry:          staleUploadsExpiry,
 		DeleteCleanupInterval:       deleteCleanupInterval,
+		BucketLatencyTopN:           bucketLatencyTopN,
+		AccessTracking:              accessTracking,
+		ReadyQuorumMargin:           readyQuorumMargin,
+		ReadyHealBacklog:            readyHealBacklog,
+		ThrottleAuthFailures:        throttleAuthFailures,
+		ThrottleRequestsRate:        throttleRequestsRate,
+		ThrottleBanDuration:         throttleBanDuration,
+		ThrottleAllowlist:           throttleAllowlist,
+		DiskHighWatermark:           diskHighWatermark,
+		ReplicationProxyNearest:     replicationProxyNearest,
+		ListIndexThreshold:          listIndexThreshold,
+		HeadKeyFilter:               headKeyFilter,
+		CorrelationHeader:           http.CanonicalHeaderKey(correlationHeader),
+		SlowRequestsThreshold:       slowRequestsThreshold,
+		SlowRequestsMax:             slowRequestsMax,
+		MemoryCacheSize:             memoryCacheSize,
+		MemoryCacheObjectMax:        memoryCacheObjectMax,
+		TrustedProxies:              trustedProxies,
 	}, nil
 }
@@ -94,5 +94,113 @@ var (
 			Optional:    true,
 			Type:        "duration",
 		},
+		config.HelpKV{
+			Key:         apiBucketLatencyTopN,
+			Description: `set to export per bucket API latency histograms for the N busiest buckets, defaults to '0' (disabled)`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiAccessTracking,
+			Description: `set to 'on' to record coarse last access times of objects for cold data reports, defaults to 'off'`,
+			Optional:    true,
+			Type:        "on|off",
+		},
+		config.HelpKV{
+			Key:         apiReadyQuorumMargin,
+			Description: `set to report not ready while an erasure set of this node has fewer spare drives above write quorum, disabled by default`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiReadyHealBacklog,
+			Description: `set to report not ready while more objects remain to be healed on the drives of this node, disabled by default`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiThrottleAuthFailures,
+			Description: `set to ban clients temporarily after N authentication failures within a minute, defaults to '0' (disabled)`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiThrottleRequestsRate,
+			Description: `set to ban clients temporarily after more than N requests within a second, defaults to '0' (disabled)`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiThrottleBanDuration,
+			Description: `set the duration of the first ban of a client, doubled for each repeated ban up to an hour, defaults to '1m'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         apiThrottleAllowlist,
+			Description: `set comma separated IP addresses or CIDR ranges never banned, load balancers among them are trusted to forward the client address`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         apiDiskHighWatermark,
+			Description: `set the percentage of the drives of an erasure set uploads may fill, uploads beyond are rejected before writing, defaults to '99'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiReplicationProxyNearest,
+			Description: `set to 'on' to proxy reads of objects not yet replicated to the replication target of the lowest measured latency having them, defaults to 'off'`,
+			Optional:    true,
+			Type:        "on|off",
+		},
+		config.HelpKV{
+			Key:         apiListIndexThreshold,
+			Description: `set the number of direct children of a prefix from which its listings are served by a sharded index, '0' disables the index, defaults to '100000000'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiHeadKeyFilter,
+			Description: `set to 'on' to answer lookups of missing objects from per erasure set key filters and a reduced drive check, defaults to 'off'`,
+			Optional:    true,
+			Type:        "on|off",
+		},
+		config.HelpKV{
+			Key:         apiCorrelationHeader,
+			Description: `set the request header carrying client correlation IDs, empty to ignore them, defaults to 'X-Correlation-Id'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         apiSlowRequestsThreshold,
+			Description: `set the latency from which S3 requests are kept in the slow request log e.g. "2s", '0s' disables the log, defaults to '0s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         apiSlowRequestsMax,
+			Description: `set the number of slow requests kept per node, defaults to '1000'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         apiMemoryCacheSize,
+			Description: `set the memory of each node caching small hot objects e.g. "1GiB", '0' disables the cache, defaults to '0'`,
+			Optional:    true,
+			Type:        "size",
+		},
+		config.HelpKV{
+			Key:         apiMemoryCacheObjectMax,
+			Description: `set the size of the largest objects cached in memory, defaults to '128KiB'`,
+			Optional:    true,
+			Type:        "size",
+		},
+		config.HelpKV{
+			Key:         apiTrustedProxies,
+			Description: `set comma separated IP addresses or CIDR ranges of the reverse proxies trusted to forward the client address to bucket network ACLs`,
+			Optional:    true,
+			Type:        "csv",
+		},
 	}
 )
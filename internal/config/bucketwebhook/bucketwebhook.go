@@ -0,0 +1,210 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bucketwebhook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// Bucket webhook sub-system constants
+const (
+	Endpoint  = "endpoint"
+	AuthToken = "auth_token"
+	Hooks     = "hooks"
+	Timeout   = "timeout"
+	FailMode  = "fail_mode"
+
+	EnvEnable    = "MINIO_BUCKET_WEBHOOK_ENABLE"
+	EnvEndpoint  = "MINIO_BUCKET_WEBHOOK_ENDPOINT"
+	EnvAuthToken = "MINIO_BUCKET_WEBHOOK_AUTH_TOKEN"
+	EnvHooks     = "MINIO_BUCKET_WEBHOOK_HOOKS"
+	EnvTimeout   = "MINIO_BUCKET_WEBHOOK_TIMEOUT"
+	EnvFailMode  = "MINIO_BUCKET_WEBHOOK_FAIL_MODE"
+)
+
+// Hooks invoked around bucket operations, the pre hooks can veto the
+// operation.
+const (
+	PreMakeBucket    = "pre_make_bucket"
+	PostMakeBucket   = "post_make_bucket"
+	PreDeleteBucket  = "pre_delete_bucket"
+	PostDeleteBucket = "post_delete_bucket"
+)
+
+var allHooks = []string{PreMakeBucket, PostMakeBucket, PreDeleteBucket, PostDeleteBucket}
+
+// Modes of the pre hooks when the webhook fails or times out
+const (
+	// FailOpen lets the operation proceed.
+	FailOpen = "open"
+	// FailClosed denies the operation.
+	FailClosed = "closed"
+)
+
+// Config represents the bucket webhook settings. The hooks of Hooks
+// post the bucket operations to Endpoint.
+type Config struct {
+	Enabled   bool            `json:"enabled"`
+	Endpoint  *xnet.URL       `json:"endpoint"`
+	AuthToken string          `json:"authToken"`
+	Hooks     map[string]bool `json:"hooks"`
+	Timeout   time.Duration   `json:"timeout"`
+	FailMode  string          `json:"failMode"`
+}
+
+// Invokes returns whether hook is invoked.
+func (c Config) Invokes(hook string) bool {
+	return c.Enabled && c.Hooks[hook]
+}
+
+var (
+	// DefaultKVS - default KV config for bucket webhooks
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Endpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   AuthToken,
+			Value: "",
+		},
+		config.KV{
+			Key:   Hooks,
+			Value: strings.Join(allHooks, config.ValueSeparator),
+		},
+		config.KV{
+			Key:   Timeout,
+			Value: "5s",
+		},
+		config.KV{
+			Key:   FailMode,
+			Value: FailOpen,
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Endpoint,
+			Description: `webhook endpoint bucket operations are posted to e.g. "https://cmdb.example.com/minio/buckets"`,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         AuthToken,
+			Description: `opaque string or JWT authorization token sent to the webhook`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         Hooks,
+			Description: `comma separated list of 'pre_make_bucket', 'post_make_bucket', 'pre_delete_bucket' and 'post_delete_bucket', defaults to all`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         Timeout,
+			Description: `maximum duration of a webhook call, defaults to '5s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         FailMode,
+			Description: `let operations proceed with 'open' or deny them with 'closed' when a pre hook fails, defaults to 'open'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup bucket webhook config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.BucketWebhookSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	endpoint := env.Get(EnvEndpoint, kvs.Get(Endpoint))
+	if endpoint == "" {
+		return cfg, errors.New("'bucket_webhook:endpoint' cannot be empty when bucket webhooks are enabled")
+	}
+	cfg.Endpoint, err = xnet.ParseHTTPURL(endpoint)
+	if err != nil {
+		return cfg, fmt.Errorf("'bucket_webhook:endpoint' value invalid: %w", err)
+	}
+	cfg.AuthToken = env.Get(EnvAuthToken, kvs.Get(AuthToken))
+
+	cfg.Hooks = make(map[string]bool)
+	for _, hook := range strings.Split(env.Get(EnvHooks, kvs.Get(Hooks)), config.ValueSeparator) {
+		if hook = strings.TrimSpace(hook); hook == "" {
+			continue
+		}
+		known := false
+		for _, h := range allHooks {
+			known = known || h == hook
+		}
+		if !known {
+			return cfg, fmt.Errorf("'bucket_webhook:hooks' value invalid: unknown hook %q", hook)
+		}
+		cfg.Hooks[hook] = true
+	}
+
+	cfg.Timeout, err = time.ParseDuration(env.Get(EnvTimeout, kvs.Get(Timeout)))
+	if err != nil {
+		return cfg, fmt.Errorf("'bucket_webhook:timeout' value invalid: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		return cfg, errors.New("'bucket_webhook:timeout' must be positive")
+	}
+
+	cfg.FailMode = env.Get(EnvFailMode, kvs.Get(FailMode))
+	switch cfg.FailMode {
+	case FailOpen, FailClosed:
+	default:
+		return cfg, fmt.Errorf("'bucket_webhook:fail_mode' value invalid: %q, expected %q or %q", cfg.FailMode, FailOpen, FailClosed)
+	}
+	return cfg, nil
+}
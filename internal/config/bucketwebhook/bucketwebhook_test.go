@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bucketwebhook
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, endpoint, hooks, timeout, failMode string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Endpoint, Value: endpoint},
+			config.KV{Key: AuthToken, Value: ""},
+			config.KV{Key: Hooks, Value: hooks},
+			config.KV{Key: Timeout, Value: timeout},
+			config.KV{Key: FailMode, Value: failMode},
+		}
+	}
+	testCases := []struct {
+		kvs     config.KVS
+		hook    string
+		invokes bool
+		success bool
+	}{
+		{kvs(config.EnableOff, "", "", "5s", FailOpen), PreMakeBucket, false, true},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_make_bucket,post_make_bucket,pre_delete_bucket,post_delete_bucket", "5s", FailOpen), PostDeleteBucket, true, true},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_make_bucket, pre_delete_bucket", "5s", FailClosed), PreDeleteBucket, true, true},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_make_bucket", "5s", FailClosed), PostMakeBucket, false, true},
+		{kvs(config.EnableOn, "", "pre_make_bucket", "5s", FailOpen), "", false, false},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_put_object", "5s", FailOpen), "", false, false},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_make_bucket", "0s", FailOpen), "", false, false},
+		{kvs(config.EnableOn, "http://cmdb:8080/buckets", "pre_make_bucket", "5s", "ignore"), "", false, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.Invokes(testCase.hook) != testCase.invokes {
+			t.Errorf("Test %d: expected invokes %t for hook %s", i+1, testCase.invokes, testCase.hook)
+		}
+	}
+}
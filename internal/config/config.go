@@ -75,12 +75,23 @@ const (
 	APISubSys            = "api"
 	CompressionSubSys    = "compression"
 	LoggerWebhookSubSys  = "logger_webhook"
+	LoggerSyslogSubSys   = "logger_syslog"
 	AuditWebhookSubSys   = "audit_webhook"
 	AuditKafkaSubSys     = "audit_kafka"
+	AuditFileSubSys      = "audit_file"
+	AuditRedactionSubSys = "audit_redaction"
 	HealSubSys           = "heal"
 	ScannerSubSys        = "scanner"
 	CrawlerSubSys        = "crawler"
 	SubnetSubSys         = "subnet"
+	ShadowSubSys         = "shadow"
+	AnomalySubSys        = "anomaly"
+	MalwareScanSubSys    = "malware_scan"
+	MetadataSinkSubSys   = "metadata_sink"
+	BucketWebhookSubSys  = "bucket_webhook"
+	TracingOTLPSubSys    = "tracing_otlp"
+	RPCSubSys            = "rpc"
+	SLOSubSys            = "slo"
 
 	// Add new constants here if you add new fields to config.
 )
@@ -111,8 +122,11 @@ var SubSystems = set.CreateStringSet(
 	StorageClassSubSys,
 	CompressionSubSys,
 	LoggerWebhookSubSys,
+	LoggerSyslogSubSys,
 	AuditWebhookSubSys,
 	AuditKafkaSubSys,
+	AuditFileSubSys,
+	AuditRedactionSubSys,
 	PolicyOPASubSys,
 	IdentityLDAPSubSys,
 	IdentityOpenIDSubSys,
@@ -130,6 +144,14 @@ var SubSystems = set.CreateStringSet(
 	NotifyRedisSubSys,
 	NotifyWebhookSubSys,
 	SubnetSubSys,
+	ShadowSubSys,
+	AnomalySubSys,
+	MalwareScanSubSys,
+	MetadataSinkSubSys,
+	BucketWebhookSubSys,
+	TracingOTLPSubSys,
+	RPCSubSys,
+	SLOSubSys,
 )
 
 // SubSystemsDynamic - all sub-systems that have dynamic config.
@@ -139,6 +161,26 @@ var SubSystemsDynamic = set.CreateStringSet(
 	ScannerSubSys,
 	HealSubSys,
 	SubnetSubSys,
+	ShadowSubSys,
+	AnomalySubSys,
+	MalwareScanSubSys,
+	MetadataSinkSubSys,
+	BucketWebhookSubSys,
+	TracingOTLPSubSys,
+	AuditRedactionSubSys,
+	RPCSubSys,
+	SLOSubSys,
+	IdentityOpenIDSubSys,
+	NotifyAMQPSubSys,
+	NotifyESSubSys,
+	NotifyKafkaSubSys,
+	NotifyMQTTSubSys,
+	NotifyMySQLSubSys,
+	NotifyNATSSubSys,
+	NotifyNSQSubSys,
+	NotifyPostgresSubSys,
+	NotifyRedisSubSys,
+	NotifyWebhookSubSys,
 )
 
 // SubSystemsSingleTargets - subsystems which only support single target.
@@ -156,6 +198,13 @@ var SubSystemsSingleTargets = set.CreateStringSet([]string{
 	IdentityTLSSubSys,
 	HealSubSys,
 	ScannerSubSys,
+	ShadowSubSys,
+	AnomalySubSys,
+	MalwareScanSubSys,
+	MetadataSinkSubSys,
+	BucketWebhookSubSys,
+	TracingOTLPSubSys,
+	AuditRedactionSubSys,
 }...)
 
 // Constant separators
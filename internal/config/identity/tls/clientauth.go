@@ -0,0 +1,143 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tls
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/minio/pkg/wildcard"
+)
+
+// Certificate fields matched by client authentication rules.
+const (
+	FieldCN    = "cn"
+	FieldOU    = "ou"
+	FieldDNS   = "dns"
+	FieldURI   = "uri"
+	FieldEmail = "email"
+)
+
+// Targets of client authentication rules.
+const (
+	targetUser   = "user"
+	targetPolicy = "policy"
+)
+
+// ClientAuthRule maps client certificates with a field value matching
+// Pattern to the IAM user User, or else to the policies Policies.
+type ClientAuthRule struct {
+	Field    string   `json:"field"`
+	Pattern  string   `json:"pattern"`
+	User     string   `json:"user,omitempty"`
+	Policies []string `json:"policies,omitempty"`
+}
+
+// ParseClientAuthRules parses rules of the form
+//
+//	<field>=<pattern>:user=<user>
+//	<field>=<pattern>:policy=<policy>[,<policy>...]
+//
+// separated by ';', field being one of cn, ou, dns, uri or email and
+// pattern matching the field value with '*' and '?' wildcards.
+func ParseClientAuthRules(s string) ([]ClientAuthRule, error) {
+	var rules []ClientAuthRule
+	for _, r := range strings.Split(s, ";") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		i := strings.LastIndex(r, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("client auth rule '%s' has no user or policy", r)
+		}
+		match, target := r[:i], r[i+1:]
+
+		field, pattern, ok := cut(match, "=")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("client auth rule '%s' has no pattern", r)
+		}
+		rule := ClientAuthRule{Field: strings.ToLower(field), Pattern: pattern}
+		switch rule.Field {
+		case FieldCN, FieldOU, FieldDNS, FieldURI, FieldEmail:
+		default:
+			return nil, fmt.Errorf("client auth rule '%s' has unknown certificate field '%s'", r, field)
+		}
+
+		kind, value, ok := cut(target, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("client auth rule '%s' has no user or policy", r)
+		}
+		switch strings.ToLower(kind) {
+		case targetUser:
+			rule.User = value
+		case targetPolicy:
+			for _, p := range strings.Split(value, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					rule.Policies = append(rule.Policies, p)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("client auth rule '%s' maps to unknown target '%s'", r, kind)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// values returns the values of field in the certificate.
+func values(cert *x509.Certificate, field string) []string {
+	switch field {
+	case FieldCN:
+		return []string{cert.Subject.CommonName}
+	case FieldOU:
+		return cert.Subject.OrganizationalUnit
+	case FieldDNS:
+		return cert.DNSNames
+	case FieldURI:
+		uris := make([]string, 0, len(cert.URIs))
+		for _, u := range cert.URIs {
+			uris = append(uris, u.String())
+		}
+		return uris
+	case FieldEmail:
+		return cert.EmailAddresses
+	}
+	return nil
+}
+
+// Match returns the first rule matching the certificate, and the
+// matched field value.
+func (l Config) Match(cert *x509.Certificate) (ClientAuthRule, string, bool) {
+	for _, rule := range l.ClientAuthRules {
+		for _, v := range values(cert, rule.Field) {
+			if v != "" && wildcard.Match(rule.Pattern, v) {
+				return rule, v, true
+			}
+		}
+	}
+	return ClientAuthRule{}, "", false
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseClientAuthRules(t *testing.T) {
+	testCases := []struct {
+		rules   string
+		want    []ClientAuthRule
+		success bool
+	}{
+		{"", nil, true},
+		{"ou=payments:user=svc-payments", []ClientAuthRule{{Field: FieldOU, Pattern: "payments", User: "svc-payments"}}, true},
+		{
+			"uri=spiffe://mesh/ns/*:policy=readwrite, diagnostics; dns=*.mesh.local:user=mesh",
+			[]ClientAuthRule{
+				{Field: FieldURI, Pattern: "spiffe://mesh/ns/*", Policies: []string{"readwrite", "diagnostics"}},
+				{Field: FieldDNS, Pattern: "*.mesh.local", User: "mesh"},
+			},
+			true,
+		},
+		{"ou=payments", nil, false},
+		{"serial=1:user=foo", nil, false},
+		{"cn=foo:group=bar", nil, false},
+		{"cn=:user=bar", nil, false},
+		{"cn=foo:user=", nil, false},
+	}
+	for i, testCase := range testCases {
+		rules, err := ParseClientAuthRules(testCase.rules)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if err == nil && !reflect.DeepEqual(rules, testCase.want) {
+			t.Errorf("Test %d: expected %#v, got %#v", i+1, testCase.want, rules)
+		}
+	}
+}
+
+func TestConfigMatch(t *testing.T) {
+	rules, err := ParseClientAuthRules("uri=spiffe://mesh/ns/prod/*:policy=readwrite;ou=payments:user=svc-payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{ClientAuthRules: rules}
+
+	uri, _ := url.Parse("spiffe://mesh/ns/prod/sa/api")
+	cert := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "api", OrganizationalUnit: []string{"payments"}},
+		URIs:    []*url.URL{uri},
+	}
+	rule, value, ok := cfg.Match(cert)
+	if !ok || !reflect.DeepEqual(rule.Policies, []string{"readwrite"}) || value != "spiffe://mesh/ns/prod/sa/api" {
+		t.Fatalf("expected the first rule to match, got %#v", rule)
+	}
+
+	cert.URIs = nil
+	if rule, _, ok = cfg.Match(cert); !ok || rule.User != "svc-payments" {
+		t.Fatalf("expected the second rule to match, got %#v", rule)
+	}
+
+	cert.Subject.OrganizationalUnit = []string{"billing"}
+	if _, _, ok = cfg.Match(cert); ok {
+		t.Fatal("expected no rule to match")
+	}
+}
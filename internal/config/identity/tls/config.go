@@ -41,6 +41,17 @@ const (
 	// clients to obtain temp. credentials with arbitrary policy
 	// permissions - including admin permissions.
 	EnvIdentityTLSSkipVerify = "MINIO_IDENTITY_TLS_SKIP_VERIFY"
+
+	// EnvIdentityTLSClientAuth is an environment variable that controls
+	// whether S3 API requests without a signature are authenticated by
+	// their client certificate, mapped to an IAM user or policies by
+	// the client auth rules. By default, it is disabled.
+	EnvIdentityTLSClientAuth = "MINIO_IDENTITY_TLS_CLIENT_AUTH"
+
+	// EnvIdentityTLSClientAuthRules is an environment variable holding
+	// the rules mapping client certificates to IAM users or policies,
+	// see ParseClientAuthRules.
+	EnvIdentityTLSClientAuthRules = "MINIO_IDENTITY_TLS_CLIENT_AUTH_RULES"
 )
 
 // Config contains the STS TLS configuration for generating temp.
@@ -52,6 +63,11 @@ type Config struct {
 	// certificate verification. It should only be set for
 	// debugging or testing purposes.
 	InsecureSkipVerify bool `json:"skip_verify"`
+
+	// ClientAuth, if set to true, authenticates S3 API requests by
+	// their client certificate, mapped by ClientAuthRules.
+	ClientAuth      bool             `json:"client_auth"`
+	ClientAuthRules []ClientAuthRule `json:"client_auth_rules"`
 }
 
 const (
@@ -87,13 +103,25 @@ func Lookup(kvs config.KVS) (Config, error) {
 	}
 	cfg := Config{}
 	var err error
+	if v := env.Get(EnvIdentityTLSClientAuth, ""); v != "" {
+		cfg.ClientAuth, err = config.ParseBool(v)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.ClientAuthRules, err = ParseClientAuthRules(env.Get(EnvIdentityTLSClientAuthRules, kvs.Get(clientAuthRules)))
+		if err != nil {
+			return Config{}, err
+		}
+	}
 	v := env.Get(EnvIdentityTLSEnabled, "")
-	if v == "" {
+	if v == "" && !cfg.ClientAuth {
 		return cfg, nil
 	}
-	cfg.Enabled, err = config.ParseBool(v)
-	if err != nil {
-		return Config{}, err
+	if v != "" {
+		cfg.Enabled, err = config.ParseBool(v)
+		if err != nil {
+			return Config{}, err
+		}
 	}
 	cfg.InsecureSkipVerify, err = config.ParseBool(env.Get(EnvIdentityTLSSkipVerify, kvs.Get(skipVerify)))
 	if err != nil {
@@ -103,7 +131,8 @@ func Lookup(kvs config.KVS) (Config, error) {
 }
 
 const (
-	skipVerify = "skip_verify"
+	skipVerify      = "skip_verify"
+	clientAuthRules = "client_auth_rules"
 )
 
 // DefaultKVS is the the default K/V config system for
@@ -113,6 +142,10 @@ var DefaultKVS = config.KVS{
 		Key:   skipVerify,
 		Value: "off",
 	},
+	config.KV{
+		Key:   clientAuthRules,
+		Value: "",
+	},
 }
 
 // Help is the help and description for the STS API K/V configuration.
@@ -123,4 +156,10 @@ var Help = config.HelpKVS{
 		Optional:    true,
 		Type:        "on|off",
 	},
+	config.HelpKV{
+		Key:         clientAuthRules,
+		Description: `rules mapping client certificates to IAM users or policies e.g. "ou=payments:user=svc-payments;uri=spiffe://mesh/*:policy=readwrite", requires MINIO_IDENTITY_TLS_CLIENT_AUTH=on`,
+		Optional:    true,
+		Type:        "string",
+	},
 }
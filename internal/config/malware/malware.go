@@ -0,0 +1,271 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package malware
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// Malware scan sub-system constants
+const (
+	Endpoint  = "endpoint"
+	AuthToken = "auth_token"
+	Buckets   = "buckets"
+	Mode      = "mode"
+	Action    = "action"
+	MaxSize   = "max_size"
+	Timeout   = "timeout"
+	QueueSize = "queue_size"
+
+	EnvEnable    = "MINIO_MALWARE_SCAN_ENABLE"
+	EnvEndpoint  = "MINIO_MALWARE_SCAN_ENDPOINT"
+	EnvAuthToken = "MINIO_MALWARE_SCAN_AUTH_TOKEN"
+	EnvBuckets   = "MINIO_MALWARE_SCAN_BUCKETS"
+	EnvMode      = "MINIO_MALWARE_SCAN_MODE"
+	EnvAction    = "MINIO_MALWARE_SCAN_ACTION"
+	EnvMaxSize   = "MINIO_MALWARE_SCAN_MAX_SIZE"
+	EnvTimeout   = "MINIO_MALWARE_SCAN_TIMEOUT"
+	EnvQueueSize = "MINIO_MALWARE_SCAN_QUEUE_SIZE"
+)
+
+// Scan modes
+const (
+	// ModeAsync scans uploaded objects in the background.
+	ModeAsync = "async"
+	// ModeSync scans uploaded objects before responding to the upload.
+	ModeSync = "sync"
+)
+
+// Actions on infected objects
+const (
+	// ActionTag tags infected objects.
+	ActionTag = "tag"
+	// ActionQuarantine tags infected objects and denies reading them.
+	ActionQuarantine = "quarantine"
+)
+
+// Config represents the malware scan settings. Objects uploaded to
+// Buckets, all buckets if empty, are posted to the scan service at
+// Endpoint.
+type Config struct {
+	Enabled   bool          `json:"enabled"`
+	Endpoint  *xnet.URL     `json:"endpoint"`
+	AuthToken string        `json:"authToken"`
+	Buckets   []string      `json:"buckets"`
+	Mode      string        `json:"mode"`
+	Action    string        `json:"action"`
+	MaxSize   uint64        `json:"maxSize"`
+	Timeout   time.Duration `json:"timeout"`
+	QueueSize int           `json:"queueSize"`
+}
+
+// Scans returns whether uploads to bucket are scanned.
+func (c Config) Scans(bucket string) bool {
+	if !c.Enabled {
+		return false
+	}
+	if len(c.Buckets) == 0 {
+		return true
+	}
+	for _, b := range c.Buckets {
+		if b == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// DefaultKVS - default KV config for malware scanning
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Endpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   AuthToken,
+			Value: "",
+		},
+		config.KV{
+			Key:   Buckets,
+			Value: "",
+		},
+		config.KV{
+			Key:   Mode,
+			Value: ModeAsync,
+		},
+		config.KV{
+			Key:   Action,
+			Value: ActionTag,
+		},
+		config.KV{
+			Key:   MaxSize,
+			Value: "100MiB",
+		},
+		config.KV{
+			Key:   Timeout,
+			Value: "30s",
+		},
+		config.KV{
+			Key:   QueueSize,
+			Value: "10000",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Endpoint,
+			Description: `scan service endpoint uploaded objects are posted to e.g. "http://clamav-rest:8080/scan"`,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         AuthToken,
+			Description: `opaque string or JWT authorization token sent to the scan service`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         Buckets,
+			Description: `comma separated list of buckets to scan uploads to, all buckets if empty`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         Mode,
+			Description: `scan uploads in the background with 'async' or before responding with 'sync', defaults to 'async'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         Action,
+			Description: `'tag' infected objects or 'quarantine' them, denying reads, defaults to 'tag'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         MaxSize,
+			Description: `objects larger than this size are not scanned, defaults to '100MiB'`,
+			Optional:    true,
+			Type:        "size",
+		},
+		config.HelpKV{
+			Key:         Timeout,
+			Description: `maximum duration of a scan, defaults to '30s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         QueueSize,
+			Description: `maximum number of uploads waiting for a background scan, defaults to '10000'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup malware scan config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.MalwareScanSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	endpoint := env.Get(EnvEndpoint, kvs.Get(Endpoint))
+	if endpoint == "" {
+		return cfg, errors.New("'malware_scan:endpoint' cannot be empty when malware scanning is enabled")
+	}
+	cfg.Endpoint, err = xnet.ParseHTTPURL(endpoint)
+	if err != nil {
+		return cfg, fmt.Errorf("'malware_scan:endpoint' value invalid: %w", err)
+	}
+	cfg.AuthToken = env.Get(EnvAuthToken, kvs.Get(AuthToken))
+
+	if buckets := env.Get(EnvBuckets, kvs.Get(Buckets)); buckets != "" {
+		for _, bucket := range strings.Split(buckets, config.ValueSeparator) {
+			if bucket = strings.TrimSpace(bucket); bucket != "" {
+				cfg.Buckets = append(cfg.Buckets, bucket)
+			}
+		}
+	}
+
+	cfg.Mode = env.Get(EnvMode, kvs.Get(Mode))
+	switch cfg.Mode {
+	case ModeAsync, ModeSync:
+	default:
+		return cfg, fmt.Errorf("'malware_scan:mode' value invalid: %q, expected %q or %q", cfg.Mode, ModeAsync, ModeSync)
+	}
+
+	cfg.Action = env.Get(EnvAction, kvs.Get(Action))
+	switch cfg.Action {
+	case ActionTag, ActionQuarantine:
+	default:
+		return cfg, fmt.Errorf("'malware_scan:action' value invalid: %q, expected %q or %q", cfg.Action, ActionTag, ActionQuarantine)
+	}
+
+	cfg.MaxSize, err = humanize.ParseBytes(env.Get(EnvMaxSize, kvs.Get(MaxSize)))
+	if err != nil {
+		return cfg, fmt.Errorf("'malware_scan:max_size' value invalid: %w", err)
+	}
+	cfg.Timeout, err = time.ParseDuration(env.Get(EnvTimeout, kvs.Get(Timeout)))
+	if err != nil {
+		return cfg, fmt.Errorf("'malware_scan:timeout' value invalid: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		return cfg, errors.New("'malware_scan:timeout' must be positive")
+	}
+	cfg.QueueSize, err = strconv.Atoi(env.Get(EnvQueueSize, kvs.Get(QueueSize)))
+	if err != nil {
+		return cfg, fmt.Errorf("'malware_scan:queue_size' value invalid: %w", err)
+	}
+	if cfg.QueueSize <= 0 {
+		return cfg, errors.New("'malware_scan:queue_size' must be positive")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package malware
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, endpoint, buckets, mode, action string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Endpoint, Value: endpoint},
+			config.KV{Key: AuthToken, Value: ""},
+			config.KV{Key: Buckets, Value: buckets},
+			config.KV{Key: Mode, Value: mode},
+			config.KV{Key: Action, Value: action},
+			config.KV{Key: MaxSize, Value: "100MiB"},
+			config.KV{Key: Timeout, Value: "30s"},
+			config.KV{Key: QueueSize, Value: "10000"},
+		}
+	}
+	testCases := []struct {
+		kvs     config.KVS
+		bucket  string
+		scans   bool
+		success bool
+	}{
+		{kvs(config.EnableOff, "", "", ModeAsync, ActionTag), "uploads", false, true},
+		{kvs(config.EnableOn, "http://clamav:8080/scan", "", ModeAsync, ActionTag), "uploads", true, true},
+		{kvs(config.EnableOn, "http://clamav:8080/scan", "uploads, inbox", ModeSync, ActionQuarantine), "inbox", true, true},
+		{kvs(config.EnableOn, "http://clamav:8080/scan", "uploads,inbox", ModeAsync, ActionTag), "backups", false, true},
+		{kvs(config.EnableOn, "", "", ModeAsync, ActionTag), "", false, false},
+		{kvs(config.EnableOn, "http://clamav:8080/scan", "", "later", ActionTag), "", false, false},
+		{kvs(config.EnableOn, "http://clamav:8080/scan", "", ModeAsync, "delete"), "", false, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.Scans(testCase.bucket) != testCase.scans {
+			t.Errorf("Test %d: expected scans %t for bucket %s", i+1, testCase.scans, testCase.bucket)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metadatasink
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// Metadata sink sub-system constants
+const (
+	Backend          = "backend"
+	URL              = "url"
+	Index            = "index"
+	Username         = "username"
+	Password         = "password"
+	ConnectionString = "connection_string"
+	Table            = "table"
+	Buckets          = "buckets"
+	BatchSize        = "batch_size"
+	FlushInterval    = "flush_interval"
+	QueueSize        = "queue_size"
+
+	EnvEnable           = "MINIO_METADATA_SINK_ENABLE"
+	EnvBackend          = "MINIO_METADATA_SINK_BACKEND"
+	EnvURL              = "MINIO_METADATA_SINK_URL"
+	EnvIndex            = "MINIO_METADATA_SINK_INDEX"
+	EnvUsername         = "MINIO_METADATA_SINK_USERNAME"
+	EnvPassword         = "MINIO_METADATA_SINK_PASSWORD"
+	EnvConnectionString = "MINIO_METADATA_SINK_CONNECTION_STRING"
+	EnvTable            = "MINIO_METADATA_SINK_TABLE"
+	EnvBuckets          = "MINIO_METADATA_SINK_BUCKETS"
+	EnvBatchSize        = "MINIO_METADATA_SINK_BATCH_SIZE"
+	EnvFlushInterval    = "MINIO_METADATA_SINK_FLUSH_INTERVAL"
+	EnvQueueSize        = "MINIO_METADATA_SINK_QUEUE_SIZE"
+)
+
+// Backends
+const (
+	// BackendElasticsearch indexes objects in an Elasticsearch or
+	// OpenSearch index.
+	BackendElasticsearch = "elasticsearch"
+	// BackendPostgreSQL indexes objects in a PostgreSQL table.
+	BackendPostgreSQL = "postgresql"
+)
+
+var (
+	validIndexName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	validTableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Config represents the metadata sink settings. The metadata of the
+// objects written, tagged and removed in Buckets, all buckets if empty,
+// is streamed to the index of Backend.
+type Config struct {
+	Enabled          bool          `json:"enabled"`
+	Backend          string        `json:"backend"`
+	URL              *xnet.URL     `json:"url,omitempty"`
+	Index            string        `json:"index,omitempty"`
+	Username         string        `json:"username,omitempty"`
+	Password         string        `json:"password,omitempty"`
+	ConnectionString string        `json:"connectionString,omitempty"`
+	Table            string        `json:"table,omitempty"`
+	Buckets          []string      `json:"buckets"`
+	BatchSize        int           `json:"batchSize"`
+	FlushInterval    time.Duration `json:"flushInterval"`
+	QueueSize        int           `json:"queueSize"`
+}
+
+// Includes returns whether the objects of bucket are indexed.
+func (c Config) Includes(bucket string) bool {
+	if !c.Enabled {
+		return false
+	}
+	if len(c.Buckets) == 0 {
+		return true
+	}
+	for _, b := range c.Buckets {
+		if b == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// DefaultKVS - default KV config for the metadata sink
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Backend,
+			Value: "",
+		},
+		config.KV{
+			Key:   URL,
+			Value: "",
+		},
+		config.KV{
+			Key:   Index,
+			Value: "minio-metadata",
+		},
+		config.KV{
+			Key:   Username,
+			Value: "",
+		},
+		config.KV{
+			Key:   Password,
+			Value: "",
+		},
+		config.KV{
+			Key:   ConnectionString,
+			Value: "",
+		},
+		config.KV{
+			Key:   Table,
+			Value: "minio_metadata",
+		},
+		config.KV{
+			Key:   Buckets,
+			Value: "",
+		},
+		config.KV{
+			Key:   BatchSize,
+			Value: "500",
+		},
+		config.KV{
+			Key:   FlushInterval,
+			Value: "1s",
+		},
+		config.KV{
+			Key:   QueueSize,
+			Value: "100000",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Backend,
+			Description: `index to stream object metadata to, 'elasticsearch' or 'postgresql'`,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         URL,
+			Description: `Elasticsearch or OpenSearch server URL e.g. "http://localhost:9200"`,
+			Optional:    true,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         Index,
+			Description: `Elasticsearch index holding the objects, created if missing, defaults to 'minio-metadata'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         Username,
+			Description: `Elasticsearch username for basic authentication`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         Password,
+			Description: `Elasticsearch password for basic authentication`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         ConnectionString,
+			Description: `PostgreSQL connection string e.g. "host=localhost user=minio dbname=minio sslmode=disable"`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         Table,
+			Description: `PostgreSQL table holding the objects, created if missing, defaults to 'minio_metadata'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         Buckets,
+			Description: `comma separated list of buckets to index, all buckets if empty`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         BatchSize,
+			Description: `maximum number of objects written to the index at once, defaults to '500'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         FlushInterval,
+			Description: `maximum time changes wait to be batched, defaults to '1s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         QueueSize,
+			Description: `maximum number of changes waiting to be written, defaults to '100000'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup metadata sink config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.MetadataSinkSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	cfg.Backend = env.Get(EnvBackend, kvs.Get(Backend))
+	switch cfg.Backend {
+	case BackendElasticsearch:
+		u := env.Get(EnvURL, kvs.Get(URL))
+		if u == "" {
+			return cfg, errors.New("'metadata_sink:url' cannot be empty for the elasticsearch backend")
+		}
+		cfg.URL, err = xnet.ParseHTTPURL(u)
+		if err != nil {
+			return cfg, fmt.Errorf("'metadata_sink:url' value invalid: %w", err)
+		}
+		cfg.Index = env.Get(EnvIndex, kvs.Get(Index))
+		if !validIndexName.MatchString(cfg.Index) {
+			return cfg, fmt.Errorf("'metadata_sink:index' value invalid: %q", cfg.Index)
+		}
+		cfg.Username = env.Get(EnvUsername, kvs.Get(Username))
+		cfg.Password = env.Get(EnvPassword, kvs.Get(Password))
+		if (cfg.Username == "") != (cfg.Password == "") {
+			return cfg, errors.New("'metadata_sink:username' and 'metadata_sink:password' are to be set together")
+		}
+	case BackendPostgreSQL:
+		cfg.ConnectionString = env.Get(EnvConnectionString, kvs.Get(ConnectionString))
+		if cfg.ConnectionString == "" {
+			return cfg, errors.New("'metadata_sink:connection_string' cannot be empty for the postgresql backend")
+		}
+		cfg.Table = env.Get(EnvTable, kvs.Get(Table))
+		if !validTableName.MatchString(cfg.Table) {
+			return cfg, fmt.Errorf("'metadata_sink:table' value invalid: %q", cfg.Table)
+		}
+	default:
+		return cfg, fmt.Errorf("'metadata_sink:backend' value invalid: %q, expected %q or %q", cfg.Backend, BackendElasticsearch, BackendPostgreSQL)
+	}
+
+	if buckets := env.Get(EnvBuckets, kvs.Get(Buckets)); buckets != "" {
+		for _, bucket := range strings.Split(buckets, config.ValueSeparator) {
+			if bucket = strings.TrimSpace(bucket); bucket != "" {
+				cfg.Buckets = append(cfg.Buckets, bucket)
+			}
+		}
+	}
+
+	cfg.BatchSize, err = strconv.Atoi(env.Get(EnvBatchSize, kvs.Get(BatchSize)))
+	if err != nil {
+		return cfg, fmt.Errorf("'metadata_sink:batch_size' value invalid: %w", err)
+	}
+	if cfg.BatchSize <= 0 {
+		return cfg, errors.New("'metadata_sink:batch_size' must be positive")
+	}
+	cfg.FlushInterval, err = time.ParseDuration(env.Get(EnvFlushInterval, kvs.Get(FlushInterval)))
+	if err != nil {
+		return cfg, fmt.Errorf("'metadata_sink:flush_interval' value invalid: %w", err)
+	}
+	if cfg.FlushInterval <= 0 {
+		return cfg, errors.New("'metadata_sink:flush_interval' must be positive")
+	}
+	cfg.QueueSize, err = strconv.Atoi(env.Get(EnvQueueSize, kvs.Get(QueueSize)))
+	if err != nil {
+		return cfg, fmt.Errorf("'metadata_sink:queue_size' value invalid: %w", err)
+	}
+	if cfg.QueueSize <= 0 {
+		return cfg, errors.New("'metadata_sink:queue_size' must be positive")
+	}
+	return cfg, nil
+}
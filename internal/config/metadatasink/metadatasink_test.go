@@ -0,0 +1,73 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metadatasink
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, backend, url, index, connStr, table, buckets string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Backend, Value: backend},
+			config.KV{Key: URL, Value: url},
+			config.KV{Key: Index, Value: index},
+			config.KV{Key: Username, Value: ""},
+			config.KV{Key: Password, Value: ""},
+			config.KV{Key: ConnectionString, Value: connStr},
+			config.KV{Key: Table, Value: table},
+			config.KV{Key: Buckets, Value: buckets},
+			config.KV{Key: BatchSize, Value: "500"},
+			config.KV{Key: FlushInterval, Value: "1s"},
+			config.KV{Key: QueueSize, Value: "100000"},
+		}
+	}
+	const connStr = "host=localhost dbname=minio sslmode=disable"
+	testCases := []struct {
+		kvs      config.KVS
+		bucket   string
+		includes bool
+		success  bool
+	}{
+		{kvs(config.EnableOff, "", "", "minio-metadata", "", "minio_metadata", ""), "photos", false, true},
+		{kvs(config.EnableOn, BackendElasticsearch, "http://localhost:9200", "minio-metadata", "", "minio_metadata", ""), "photos", true, true},
+		{kvs(config.EnableOn, BackendPostgreSQL, "", "minio-metadata", connStr, "minio_metadata", "photos, videos"), "videos", true, true},
+		{kvs(config.EnableOn, BackendPostgreSQL, "", "minio-metadata", connStr, "minio_metadata", "photos,videos"), "backups", false, true},
+		{kvs(config.EnableOn, "", "", "minio-metadata", "", "minio_metadata", ""), "", false, false},
+		{kvs(config.EnableOn, BackendElasticsearch, "", "minio-metadata", "", "minio_metadata", ""), "", false, false},
+		{kvs(config.EnableOn, BackendElasticsearch, "http://localhost:9200", "Minio", "", "minio_metadata", ""), "", false, false},
+		{kvs(config.EnableOn, BackendPostgreSQL, "", "minio-metadata", "", "minio_metadata", ""), "", false, false},
+		{kvs(config.EnableOn, BackendPostgreSQL, "", "minio-metadata", connStr, "objects; DROP TABLE users", ""), "", false, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.Includes(testCase.bucket) != testCase.includes {
+			t.Errorf("Test %d: expected includes %t for bucket %s", i+1, testCase.includes, testCase.bucket)
+		}
+	}
+}
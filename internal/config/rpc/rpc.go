@@ -0,0 +1,251 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+)
+
+// Internode RPC sub-system constants
+const (
+	MaxIdleConnsPerHost  = "max_idle_conns_per_host"
+	MaxConnsPerHost      = "max_conns_per_host"
+	IdleConnTimeout      = "idle_conn_timeout"
+	DialTimeout          = "dial_timeout"
+	TCPKeepAlive         = "tcp_keepalive"
+	ZeroCopyNetworks     = "zerocopy_networks"
+	Network              = "network"
+	HealBandwidth        = "heal_bandwidth"
+	ReplicationBandwidth = "replication_bandwidth"
+
+	EnvMaxIdleConnsPerHost  = "MINIO_RPC_MAX_IDLE_CONNS_PER_HOST"
+	EnvMaxConnsPerHost      = "MINIO_RPC_MAX_CONNS_PER_HOST"
+	EnvIdleConnTimeout      = "MINIO_RPC_IDLE_CONN_TIMEOUT"
+	EnvDialTimeout          = "MINIO_RPC_DIAL_TIMEOUT"
+	EnvTCPKeepAlive         = "MINIO_RPC_TCP_KEEPALIVE"
+	EnvZeroCopyNetworks     = "MINIO_RPC_ZEROCOPY_NETWORKS"
+	EnvNetwork              = "MINIO_RPC_NETWORK"
+	EnvHealBandwidth        = "MINIO_RPC_HEAL_BANDWIDTH"
+	EnvReplicationBandwidth = "MINIO_RPC_REPLICATION_BANDWIDTH"
+)
+
+// Config represents the transport settings of the internode RPC clients.
+type Config struct {
+	MaxIdleConnsPerHost int           `json:"maxIdleConnsPerHost"`
+	MaxConnsPerHost     int           `json:"maxConnsPerHost"`
+	IdleConnTimeout     time.Duration `json:"idleConnTimeout"`
+	DialTimeout         time.Duration `json:"dialTimeout"`
+	TCPKeepAlive        time.Duration `json:"tcpKeepAlive"`
+	ZeroCopyNetworks    []*net.IPNet  `json:"zeroCopyNetworks"`
+	Network             *net.IPNet    `json:"network"`
+
+	// Bytes per second of the traffic classes, sent and received by
+	// this node, 0 if unlimited.
+	HealBandwidth        uint64 `json:"healBandwidth"`
+	ReplicationBandwidth uint64 `json:"replicationBandwidth"`
+}
+
+var (
+	// DefaultKVS - default KV config for the internode RPC transport
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   MaxIdleConnsPerHost,
+			Value: "1024",
+		},
+		config.KV{
+			Key:   MaxConnsPerHost,
+			Value: "0",
+		},
+		config.KV{
+			Key:   IdleConnTimeout,
+			Value: "15s",
+		},
+		config.KV{
+			Key:   DialTimeout,
+			Value: "10s",
+		},
+		config.KV{
+			Key:   TCPKeepAlive,
+			Value: "15s",
+		},
+		config.KV{
+			Key:   ZeroCopyNetworks,
+			Value: "",
+		},
+		config.KV{
+			Key:   Network,
+			Value: "",
+		},
+		config.KV{
+			Key:   HealBandwidth,
+			Value: "0",
+		},
+		config.KV{
+			Key:   ReplicationBandwidth,
+			Value: "0",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         MaxIdleConnsPerHost,
+			Description: `maximum idle connections kept open to each node, defaults to '1024'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         MaxConnsPerHost,
+			Description: `maximum connections, and so concurrent requests, to each node, '0' for no limit, defaults to '0'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         IdleConnTimeout,
+			Description: `duration after which idle connections are closed, defaults to '15s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         DialTimeout,
+			Description: `timeout connecting to a node, defaults to '10s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         TCPKeepAlive,
+			Description: `interval of the TCP keep-alive probes of idle connections, '-1s' disables them, defaults to '15s'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         ZeroCopyNetworks,
+			Description: `experimental, comma separated list of networks in CIDR notation large requests to nodes in are sent with MSG_ZEROCOPY, Linux only e.g. "10.10.0.0/16"`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         Network,
+			Description: `network in CIDR notation internode connections are made in, from a local address in it to the addresses of the nodes in it e.g. "10.20.0.0/16"`,
+			Optional:    true,
+			Type:        "cidr",
+		},
+		config.HelpKV{
+			Key:         HealBandwidth,
+			Description: `maximum internode bandwidth of healing per node e.g. "500MiB", '0' for no limit, defaults to '0'`,
+			Optional:    true,
+			Type:        "size",
+		},
+		config.HelpKV{
+			Key:         ReplicationBandwidth,
+			Description: `maximum internode bandwidth of replication reading objects per node e.g. "1GiB", '0' for no limit, defaults to '0'`,
+			Optional:    true,
+			Type:        "size",
+		},
+	}
+)
+
+func lookupInt(kvs config.KVS, envKey, key string) (int, error) {
+	v, err := strconv.Atoi(env.Get(envKey, kvs.Get(key)))
+	if err != nil {
+		return 0, fmt.Errorf("'rpc:%s' value invalid: %w", key, err)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("'rpc:%s' cannot be negative", key)
+	}
+	return v, nil
+}
+
+func lookupDuration(kvs config.KVS, envKey, key string) (time.Duration, error) {
+	v, err := time.ParseDuration(env.Get(envKey, kvs.Get(key)))
+	if err != nil {
+		return 0, fmt.Errorf("'rpc:%s' value invalid: %w", key, err)
+	}
+	return v, nil
+}
+
+func lookupSize(kvs config.KVS, envKey, key string) (uint64, error) {
+	s := env.Get(envKey, kvs.Get(key))
+	if s == "" {
+		return 0, nil
+	}
+	v, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("'rpc:%s' value invalid: %w", key, err)
+	}
+	return v, nil
+}
+
+// LookupConfig - lookup internode RPC config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.RPCSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	if cfg.MaxIdleConnsPerHost, err = lookupInt(kvs, EnvMaxIdleConnsPerHost, MaxIdleConnsPerHost); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxConnsPerHost, err = lookupInt(kvs, EnvMaxConnsPerHost, MaxConnsPerHost); err != nil {
+		return cfg, err
+	}
+	if cfg.IdleConnTimeout, err = lookupDuration(kvs, EnvIdleConnTimeout, IdleConnTimeout); err != nil {
+		return cfg, err
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		return cfg, fmt.Errorf("'rpc:%s' must be positive", IdleConnTimeout)
+	}
+	if cfg.DialTimeout, err = lookupDuration(kvs, EnvDialTimeout, DialTimeout); err != nil {
+		return cfg, err
+	}
+	if cfg.DialTimeout <= 0 {
+		return cfg, fmt.Errorf("'rpc:%s' must be positive", DialTimeout)
+	}
+	if cfg.TCPKeepAlive, err = lookupDuration(kvs, EnvTCPKeepAlive, TCPKeepAlive); err != nil {
+		return cfg, err
+	}
+	for _, v := range strings.Split(env.Get(EnvZeroCopyNetworks, kvs.Get(ZeroCopyNetworks)), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return cfg, fmt.Errorf("'rpc:%s' value invalid: %w", ZeroCopyNetworks, err)
+		}
+		cfg.ZeroCopyNetworks = append(cfg.ZeroCopyNetworks, n)
+	}
+	if v := env.Get(EnvNetwork, kvs.Get(Network)); v != "" {
+		if _, cfg.Network, err = net.ParseCIDR(v); err != nil {
+			return cfg, fmt.Errorf("'rpc:%s' value invalid: %w", Network, err)
+		}
+	}
+	if cfg.HealBandwidth, err = lookupSize(kvs, EnvHealBandwidth, HealBandwidth); err != nil {
+		return cfg, err
+	}
+	if cfg.ReplicationBandwidth, err = lookupSize(kvs, EnvReplicationBandwidth, ReplicationBandwidth); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
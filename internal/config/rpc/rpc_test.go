@@ -0,0 +1,104 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(idle, conns, idleTimeout, dial, keepAlive string, zeroCopy ...string) config.KVS {
+		return config.KVS{
+			config.KV{Key: MaxIdleConnsPerHost, Value: idle},
+			config.KV{Key: MaxConnsPerHost, Value: conns},
+			config.KV{Key: IdleConnTimeout, Value: idleTimeout},
+			config.KV{Key: DialTimeout, Value: dial},
+			config.KV{Key: TCPKeepAlive, Value: keepAlive},
+			config.KV{Key: ZeroCopyNetworks, Value: strings.Join(zeroCopy, ",")},
+		}
+	}
+	testCases := []struct {
+		kvs     config.KVS
+		dial    time.Duration
+		success bool
+	}{
+		{DefaultKVS, 10 * time.Second, true},
+		{kvs("4096", "256", "90s", "2s", "-1s"), 2 * time.Second, true},
+		{kvs("-1", "0", "15s", "10s", "15s"), 0, false},
+		{kvs("1024", "many", "15s", "10s", "15s"), 0, false},
+		{kvs("1024", "0", "0s", "10s", "15s"), 0, false},
+		{kvs("1024", "0", "15s", "0s", "15s"), 0, false},
+		{kvs("1024", "0", "15s", "10s", "often"), 0, false},
+		{kvs("1024", "0", "15s", "10s", "15s", "10.10.0.0/16", " fd00::/8"), 10 * time.Second, true},
+		{kvs("1024", "0", "15s", "10s", "15s", "10.10.0.1"), 0, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.DialTimeout != testCase.dial {
+			t.Errorf("Test %d: expected dial timeout %s, got %s", i+1, testCase.dial, cfg.DialTimeout)
+		}
+	}
+}
+
+func TestLookupConfigTraffic(t *testing.T) {
+	kvs := func(network, heal, replication string) config.KVS {
+		kvs := config.KVS{}
+		for _, kv := range DefaultKVS {
+			kvs.Set(kv.Key, kv.Value)
+		}
+		kvs.Set(Network, network)
+		kvs.Set(HealBandwidth, heal)
+		kvs.Set(ReplicationBandwidth, replication)
+		return kvs
+	}
+	testCases := []struct {
+		kvs     config.KVS
+		heal    uint64
+		success bool
+	}{
+		{kvs("", "0", "0"), 0, true},
+		{kvs("10.20.0.0/16", "500MiB", "1GiB"), 500 << 20, true},
+		{kvs("10.20.0.1", "0", "0"), 0, false},
+		{kvs("", "fast", "0"), 0, false},
+		{kvs("", "0", "1GB/s"), 0, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.HealBandwidth != testCase.heal {
+			t.Errorf("Test %d: expected heal bandwidth %d, got %d", i+1, testCase.heal, cfg.HealBandwidth)
+		}
+	}
+}
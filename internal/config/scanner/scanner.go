@@ -30,12 +30,26 @@ const (
 	Delay   = "delay"
 	MaxWait = "max_wait"
 	Cycle   = "cycle"
+	// StaleAfter is the age of the last successful scan of an erasure
+	// set alerted as stale.
+	StaleAfter = "stale_after"
+	// ObjectsAlert is the number of objects of the cluster alerted as
+	// approaching the object count limit.
+	ObjectsAlert = "objects_alert"
+	// InodesAlert is the percentage of used inodes of a drive alerted.
+	InodesAlert = "inodes_alert"
+	// DirEntriesAlert is the number of entries of a directory alerted.
+	DirEntriesAlert = "dir_entries_alert"
 
-	EnvDelay         = "MINIO_SCANNER_DELAY"
-	EnvCycle         = "MINIO_SCANNER_CYCLE"
-	EnvDelayLegacy   = "MINIO_CRAWLER_DELAY"
-	EnvMaxWait       = "MINIO_SCANNER_MAX_WAIT"
-	EnvMaxWaitLegacy = "MINIO_CRAWLER_MAX_WAIT"
+	EnvDelay           = "MINIO_SCANNER_DELAY"
+	EnvCycle           = "MINIO_SCANNER_CYCLE"
+	EnvStaleAfter      = "MINIO_SCANNER_STALE_AFTER"
+	EnvObjectsAlert    = "MINIO_SCANNER_OBJECTS_ALERT"
+	EnvInodesAlert     = "MINIO_SCANNER_INODES_ALERT"
+	EnvDirEntriesAlert = "MINIO_SCANNER_DIR_ENTRIES_ALERT"
+	EnvDelayLegacy     = "MINIO_CRAWLER_DELAY"
+	EnvMaxWait         = "MINIO_SCANNER_MAX_WAIT"
+	EnvMaxWaitLegacy   = "MINIO_CRAWLER_MAX_WAIT"
 )
 
 // Config represents the heal settings.
@@ -46,6 +60,16 @@ type Config struct {
 	MaxWait time.Duration
 	// Cycle is the time.Duration between each scanner cycles
 	Cycle time.Duration
+	// StaleAfter is the age of the last successful scan of a set after
+	// which it is alerted as stale, zero disables the alerts.
+	StaleAfter time.Duration
+	// ObjectsAlert is the number of objects of the cluster, InodesAlert
+	// the percentage of used inodes of a drive and DirEntriesAlert the
+	// number of entries of a directory from which an alert is logged,
+	// zero disables the alert.
+	ObjectsAlert    uint64
+	InodesAlert     float64
+	DirEntriesAlert uint64
 }
 
 var (
@@ -63,6 +87,22 @@ var (
 			Key:   Cycle,
 			Value: "1m",
 		},
+		config.KV{
+			Key:   StaleAfter,
+			Value: "24h",
+		},
+		config.KV{
+			Key:   ObjectsAlert,
+			Value: "0",
+		},
+		config.KV{
+			Key:   InodesAlert,
+			Value: "90",
+		},
+		config.KV{
+			Key:   DirEntriesAlert,
+			Value: "5000000",
+		},
 	}
 
 	// Help provides help for config values
@@ -85,6 +125,30 @@ var (
 			Optional:    true,
 			Type:        "duration",
 		},
+		config.HelpKV{
+			Key:         StaleAfter,
+			Description: `alert when an erasure set was not scanned successfully for this long, '0' to disable, defaults to '24h'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         ObjectsAlert,
+			Description: `alert when the cluster holds this many objects, '0' to disable, defaults to '0'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         InodesAlert,
+			Description: `alert when a drive uses this percentage of its inodes, '0' to disable, defaults to '90'`,
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         DirEntriesAlert,
+			Description: `alert when a directory of a drive holds this many entries, '0' to disable, defaults to '5000000'`,
+			Optional:    true,
+			Type:        "number",
+		},
 	}
 )
 
@@ -114,5 +178,39 @@ func LookupConfig(kvs config.KVS) (cfg Config, err error) {
 	if err != nil {
 		return cfg, err
 	}
+
+	staleAfter := env.Get(EnvStaleAfter, kvs.Get(StaleAfter))
+	if staleAfter == "" {
+		// Configs saved before the key was added.
+		staleAfter = DefaultKVS.Get(StaleAfter)
+	}
+	cfg.StaleAfter, err = time.ParseDuration(staleAfter)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.StaleAfter < 0 {
+		return cfg, config.Errorf("invalid %s: %s", StaleAfter, staleAfter)
+	}
+
+	// Configs saved before the alert keys were added use the defaults.
+	lookup := func(envKey, key string) string {
+		if v := env.Get(envKey, kvs.Get(key)); v != "" {
+			return v
+		}
+		return DefaultKVS.Get(key)
+	}
+	objectsAlert := lookup(EnvObjectsAlert, ObjectsAlert)
+	if cfg.ObjectsAlert, err = strconv.ParseUint(objectsAlert, 10, 64); err != nil {
+		return cfg, config.Errorf("invalid %s: %s", ObjectsAlert, objectsAlert)
+	}
+	inodesAlert := lookup(EnvInodesAlert, InodesAlert)
+	cfg.InodesAlert, err = strconv.ParseFloat(inodesAlert, 64)
+	if err != nil || cfg.InodesAlert < 0 || cfg.InodesAlert > 100 {
+		return cfg, config.Errorf("invalid %s: %s", InodesAlert, inodesAlert)
+	}
+	dirEntriesAlert := lookup(EnvDirEntriesAlert, DirEntriesAlert)
+	if cfg.DirEntriesAlert, err = strconv.ParseUint(dirEntriesAlert, 10, 64); err != nil {
+		return cfg, config.Errorf("invalid %s: %s", DirEntriesAlert, dirEntriesAlert)
+	}
 	return cfg, nil
 }
@@ -0,0 +1,175 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package shadow
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// Traffic shadowing sub-system constants
+const (
+	Endpoint    = "endpoint"
+	AccessKey   = "access_key"
+	SecretKey   = "secret_key"
+	SampleRatio = "sample_ratio"
+	QueueSize   = "queue_size"
+
+	EnvEnable      = "MINIO_SHADOW_ENABLE"
+	EnvEndpoint    = "MINIO_SHADOW_ENDPOINT"
+	EnvAccessKey   = "MINIO_SHADOW_ACCESS_KEY"
+	EnvSecretKey   = "MINIO_SHADOW_SECRET_KEY"
+	EnvSampleRatio = "MINIO_SHADOW_SAMPLE_RATIO"
+	EnvQueueSize   = "MINIO_SHADOW_QUEUE_SIZE"
+)
+
+// Config represents the traffic shadowing settings, read requests
+// served by this cluster are replayed against Endpoint and the
+// outcomes compared.
+type Config struct {
+	Enabled     bool      `json:"enabled"`
+	Endpoint    *xnet.URL `json:"endpoint"`
+	AccessKey   string    `json:"accessKey"`
+	SecretKey   string    `json:"secretKey"`
+	SampleRatio float64   `json:"sampleRatio"`
+	QueueSize   int       `json:"queueSize"`
+}
+
+var (
+	// DefaultKVS - default KV config for traffic shadowing
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Endpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   AccessKey,
+			Value: "",
+		},
+		config.KV{
+			Key:   SecretKey,
+			Value: "",
+		},
+		config.KV{
+			Key:   SampleRatio,
+			Value: "0.01",
+		},
+		config.KV{
+			Key:   QueueSize,
+			Value: "10000",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Endpoint,
+			Description: `shadow cluster endpoint to mirror read traffic to e.g. "https://shadow.example.net:9000"`,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         AccessKey,
+			Description: `access key used to sign requests sent to the shadow cluster`,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         SecretKey,
+			Description: `secret key used to sign requests sent to the shadow cluster`,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         SampleRatio,
+			Description: `fraction of GET/HEAD object requests to mirror between 0 and 1, defaults to '0.01'`,
+			Optional:    true,
+			Type:        "float",
+		},
+		config.HelpKV{
+			Key:         QueueSize,
+			Description: `maximum number of mirrored requests waiting to be sent, excess requests are dropped, defaults to '10000'`,
+			Optional:    true,
+			Type:        "int",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup traffic shadowing config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.ShadowSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	endpoint := env.Get(EnvEndpoint, kvs.Get(Endpoint))
+	if endpoint == "" {
+		return cfg, errors.New("'shadow:endpoint' cannot be empty when shadowing is enabled")
+	}
+	cfg.Endpoint, err = xnet.ParseHTTPURL(endpoint)
+	if err != nil {
+		return cfg, fmt.Errorf("'shadow:endpoint' value invalid: %w", err)
+	}
+
+	cfg.AccessKey = env.Get(EnvAccessKey, kvs.Get(AccessKey))
+	cfg.SecretKey = env.Get(EnvSecretKey, kvs.Get(SecretKey))
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return cfg, errors.New("'shadow:access_key' and 'shadow:secret_key' are required when shadowing is enabled")
+	}
+
+	cfg.SampleRatio, err = strconv.ParseFloat(env.Get(EnvSampleRatio, kvs.Get(SampleRatio)), 64)
+	if err != nil {
+		return cfg, fmt.Errorf("'shadow:sample_ratio' value invalid: %w", err)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return cfg, errors.New("'shadow:sample_ratio' must be between 0 and 1")
+	}
+
+	cfg.QueueSize, err = strconv.Atoi(env.Get(EnvQueueSize, kvs.Get(QueueSize)))
+	if err != nil {
+		return cfg, fmt.Errorf("'shadow:queue_size' value invalid: %w", err)
+	}
+	if cfg.QueueSize <= 0 {
+		return cfg, errors.New("'shadow:queue_size' must be a positive integer")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package shadow
+
+import (
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, endpoint, ratio string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Endpoint, Value: endpoint},
+			config.KV{Key: AccessKey, Value: "minio"},
+			config.KV{Key: SecretKey, Value: "minio123"},
+			config.KV{Key: SampleRatio, Value: ratio},
+			config.KV{Key: QueueSize, Value: "100"},
+		}
+	}
+	testCases := []struct {
+		kvs     config.KVS
+		enabled bool
+		success bool
+	}{
+		{kvs(config.EnableOff, "", "0.1"), false, true},
+		{kvs(config.EnableOn, "https://shadow:9000", "0.1"), true, true},
+		{kvs(config.EnableOn, "https://shadow:9000", "1"), true, true},
+		{kvs(config.EnableOn, "", "0.1"), true, false},
+		{kvs(config.EnableOn, "https://shadow:9000", "1.5"), true, false},
+		{kvs(config.EnableOn, "https://shadow:9000", "-1"), true, false},
+		{kvs(config.EnableOn, "ftp://shadow", "0.1"), true, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(testCase.kvs)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && cfg.Enabled != testCase.enabled {
+			t.Errorf("Test %d: expected enabled %t, got %t", i+1, testCase.enabled, cfg.Enabled)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+)
+
+// SLO sub-system constants
+const (
+	Bucket           = "bucket"
+	API              = "api"
+	Availability     = "availability"
+	Latency          = "latency"
+	LatencyObjective = "latency_objective"
+	Window           = "window"
+)
+
+// API classes of the S3 calls an objective covers, besides a comma
+// separated list of API names.
+const (
+	ClassAll   = "all"
+	ClassRead  = "read"
+	ClassWrite = "write"
+	ClassList  = "list"
+)
+
+// Limits of the rolling window of an objective.
+const (
+	MinWindow = time.Hour
+	MaxWindow = 90 * 24 * time.Hour
+)
+
+// Objective is a service level objective on the S3 calls of a bucket or
+// of all buckets. Availability is the fraction of calls to answer
+// without a server error, LatencyObjective the fraction of calls to
+// answer within Latency, both over the rolling Window.
+type Objective struct {
+	Name             string        `json:"name"`
+	Bucket           string        `json:"bucket,omitempty"`
+	APIs             []string      `json:"apis"`
+	Availability     float64       `json:"availability,omitempty"`
+	Latency          time.Duration `json:"latency,omitempty"`
+	LatencyObjective float64       `json:"latencyObjective,omitempty"`
+	Window           time.Duration `json:"window"`
+}
+
+// Config holds the objectives of all enabled targets, sorted by name.
+type Config struct {
+	Objectives []Objective `json:"objectives"`
+}
+
+var (
+	// DefaultKVS - default KV config of an objective
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Bucket,
+			Value: "",
+		},
+		config.KV{
+			Key:   API,
+			Value: ClassAll,
+		},
+		config.KV{
+			Key:   Availability,
+			Value: "",
+		},
+		config.KV{
+			Key:   Latency,
+			Value: "",
+		},
+		config.KV{
+			Key:   LatencyObjective,
+			Value: "99",
+		},
+		config.KV{
+			Key:   Window,
+			Value: "720h",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Bucket,
+			Description: `bucket the objective covers, all buckets if empty`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         API,
+			Description: `S3 calls the objective covers: 'all', 'read', 'write', 'list' or comma separated API names e.g. "getobject,headobject", defaults to 'all'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         Availability,
+			Description: `percentage of calls to answer without a server error e.g. "99.9"`,
+			Optional:    true,
+			Type:        "float",
+		},
+		config.HelpKV{
+			Key:         Latency,
+			Description: `latency threshold of the calls e.g. "250ms"`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         LatencyObjective,
+			Description: `percentage of calls to answer within the latency threshold, defaults to '99'`,
+			Optional:    true,
+			Type:        "float",
+		},
+		config.HelpKV{
+			Key:         Window,
+			Description: `rolling window of the error budget, from '1h' to '2160h', defaults to '720h'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// parsePercentage parses a percentage of key into a fraction.
+func parsePercentage(subSysTarget, key, v string) (float64, error) {
+	pct, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s:%s' value invalid: %w", subSysTarget, key, err)
+	}
+	if pct <= 0 || pct >= 100 {
+		return 0, fmt.Errorf("'%s:%s' must be above 0 and below 100", subSysTarget, key)
+	}
+	return pct / 100, nil
+}
+
+// lookupObjective parses the objective of an enabled target.
+func lookupObjective(subSysTarget, name string, kvs config.KVS) (o Objective, err error) {
+	o.Name = name
+	o.Bucket = kvs.Get(Bucket)
+
+	apis := strings.ToLower(strings.TrimSpace(kvs.Get(API)))
+	if apis == "" {
+		apis = ClassAll
+	}
+	for _, api := range strings.Split(apis, ",") {
+		if api = strings.TrimSpace(api); api != "" {
+			o.APIs = append(o.APIs, api)
+		}
+	}
+
+	if v := kvs.Get(Availability); v != "" {
+		if o.Availability, err = parsePercentage(subSysTarget, Availability, v); err != nil {
+			return o, err
+		}
+	}
+	if v := kvs.Get(Latency); v != "" {
+		if o.Latency, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("'%s:%s' value invalid: %w", subSysTarget, Latency, err)
+		}
+		if o.Latency <= 0 {
+			return o, fmt.Errorf("'%s:%s' must be positive", subSysTarget, Latency)
+		}
+		if o.LatencyObjective, err = parsePercentage(subSysTarget, LatencyObjective, kvs.Get(LatencyObjective)); err != nil {
+			return o, err
+		}
+	}
+	if o.Availability == 0 && o.Latency == 0 {
+		return o, fmt.Errorf("'%s' needs an availability or a latency objective", subSysTarget)
+	}
+
+	if o.Window, err = time.ParseDuration(kvs.Get(Window)); err != nil {
+		return o, fmt.Errorf("'%s:%s' value invalid: %w", subSysTarget, Window, err)
+	}
+	if o.Window < MinWindow || o.Window > MaxWindow {
+		return o, fmt.Errorf("'%s:%s' must be between %s and %s", subSysTarget, Window, MinWindow, MaxWindow)
+	}
+	// The budget is accounted per hour.
+	o.Window = o.Window.Truncate(time.Hour)
+	return o, nil
+}
+
+// LookupConfig - lookup the objectives of all targets of the SLO
+// sub-system, the default target included.
+func LookupConfig(targets map[string]config.KVS) (cfg Config, err error) {
+	for target, kvs := range targets {
+		subSysTarget := config.SLOSubSys
+		if target != config.Default {
+			subSysTarget = config.SLOSubSys + config.SubSystemSeparator + target
+		}
+		if err = config.CheckValidKeys(subSysTarget, kvs, DefaultKVS); err != nil {
+			return cfg, err
+		}
+		if kvs.Empty() {
+			continue
+		}
+		enabled, err := config.ParseBool(kvs.Get(config.Enable))
+		if err != nil {
+			return cfg, err
+		}
+		if !enabled {
+			continue
+		}
+		o, err := lookupObjective(subSysTarget, target, kvs)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Objectives = append(cfg.Objectives, o)
+	}
+	if len(cfg.Objectives) > 100 {
+		return cfg, errors.New("at most 100 service level objectives can be enabled")
+	}
+	sort.Slice(cfg.Objectives, func(i, j int) bool {
+		return cfg.Objectives[i].Name < cfg.Objectives[j].Name
+	})
+	return cfg, nil
+}
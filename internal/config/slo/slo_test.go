@@ -0,0 +1,76 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+)
+
+func TestLookupConfig(t *testing.T) {
+	kvs := func(enable, api, availability, latency, window string) config.KVS {
+		return config.KVS{
+			config.KV{Key: config.Enable, Value: enable},
+			config.KV{Key: Bucket, Value: "photos"},
+			config.KV{Key: API, Value: api},
+			config.KV{Key: Availability, Value: availability},
+			config.KV{Key: Latency, Value: latency},
+			config.KV{Key: LatencyObjective, Value: "99"},
+			config.KV{Key: Window, Value: window},
+		}
+	}
+	testCases := []struct {
+		kvs        config.KVS
+		objectives int
+		success    bool
+	}{
+		{kvs(config.EnableOff, "all", "", "", "720h"), 0, true},
+		{kvs(config.EnableOn, "read", "99.9", "", "720h"), 1, true},
+		{kvs(config.EnableOn, "GetObject, headobject", "", "250ms", "24h"), 1, true},
+		{kvs(config.EnableOn, "all", "", "", "720h"), 0, false},
+		{kvs(config.EnableOn, "all", "100", "", "720h"), 0, false},
+		{kvs(config.EnableOn, "all", "99.9", "-1s", "720h"), 0, false},
+		{kvs(config.EnableOn, "all", "99.9", "", "30m"), 0, false},
+		{kvs(config.EnableOn, "all", "99.9", "", "2400h"), 0, false},
+	}
+
+	for i, testCase := range testCases {
+		cfg, err := LookupConfig(map[string]config.KVS{"reads": testCase.kvs})
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && len(cfg.Objectives) != testCase.objectives {
+			t.Errorf("Test %d: expected %d objectives, got %d", i+1, testCase.objectives, len(cfg.Objectives))
+		}
+	}
+
+	cfg, err := LookupConfig(map[string]config.KVS{"reads": kvs(config.EnableOn, "GetObject, headobject", "99.5", "250ms", "36h30m")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := cfg.Objectives[0]
+	if o.Name != "reads" || o.Bucket != "photos" || len(o.APIs) != 2 || o.APIs[0] != "getobject" ||
+		o.Availability != 0.995 || o.Latency != 250*time.Millisecond || o.LatencyObjective != 0.99 || o.Window != 36*time.Hour {
+		t.Errorf("unexpected objective %+v", o)
+	}
+}
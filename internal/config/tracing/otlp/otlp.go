@@ -0,0 +1,154 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/env"
+	xnet "github.com/minio/pkg/net"
+)
+
+// OTLP tracing sub-system constants
+const (
+	Endpoint    = "endpoint"
+	AuthToken   = "auth_token"
+	ServiceName = "service_name"
+	SampleRatio = "sample_ratio"
+
+	EnvEnable      = "MINIO_TRACING_OTLP_ENABLE"
+	EnvEndpoint    = "MINIO_TRACING_OTLP_ENDPOINT"
+	EnvAuthToken   = "MINIO_TRACING_OTLP_AUTH_TOKEN"
+	EnvServiceName = "MINIO_TRACING_OTLP_SERVICE_NAME"
+	EnvSampleRatio = "MINIO_TRACING_OTLP_SAMPLE_RATIO"
+)
+
+// Config represents the OpenTelemetry span export settings.
+type Config struct {
+	Enabled     bool      `json:"enabled"`
+	Endpoint    *xnet.URL `json:"endpoint"`
+	AuthToken   string    `json:"authToken"`
+	ServiceName string    `json:"serviceName"`
+	SampleRatio float64   `json:"sampleRatio"`
+}
+
+var (
+	// DefaultKVS - default KV config for OTLP tracing
+	DefaultKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   Endpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   AuthToken,
+			Value: "",
+		},
+		config.KV{
+			Key:   ServiceName,
+			Value: "minio",
+		},
+		config.KV{
+			Key:   SampleRatio,
+			Value: "0.1",
+		},
+	}
+
+	// Help provides help for config values
+	Help = config.HelpKVS{
+		config.HelpKV{
+			Key:         Endpoint,
+			Description: `OTLP/HTTP traces endpoint e.g. "http://otel-collector:4318/v1/traces"`,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         AuthToken,
+			Description: `opaque string or JWT authorization token sent as a bearer token to the collector`,
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         ServiceName,
+			Description: `service name reported with every span, defaults to 'minio'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         SampleRatio,
+			Description: `fraction of requests without an incoming trace context to trace, between 0 and 1, defaults to '0.1'`,
+			Optional:    true,
+			Type:        "float",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+// LookupConfig - lookup OTLP tracing config and override with valid environment settings if any.
+func LookupConfig(kvs config.KVS) (cfg Config, err error) {
+	if err = config.CheckValidKeys(config.TracingOTLPSubSys, kvs, DefaultKVS); err != nil {
+		return cfg, err
+	}
+
+	cfg.Enabled, err = config.ParseBool(env.Get(EnvEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	endpoint := env.Get(EnvEndpoint, kvs.Get(Endpoint))
+	if endpoint == "" {
+		return cfg, errors.New("'tracing_otlp:endpoint' cannot be empty when tracing is enabled")
+	}
+	cfg.Endpoint, err = xnet.ParseHTTPURL(endpoint)
+	if err != nil {
+		return cfg, fmt.Errorf("'tracing_otlp:endpoint' value invalid: %w", err)
+	}
+
+	cfg.AuthToken = env.Get(EnvAuthToken, kvs.Get(AuthToken))
+	cfg.ServiceName = env.Get(EnvServiceName, kvs.Get(ServiceName))
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "minio"
+	}
+
+	cfg.SampleRatio, err = strconv.ParseFloat(env.Get(EnvSampleRatio, kvs.Get(SampleRatio)), 64)
+	if err != nil {
+		return cfg, fmt.Errorf("'tracing_otlp:sample_ratio' value invalid: %w", err)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return cfg, errors.New("'tracing_otlp:sample_ratio' must be between 0 and 1")
+	}
+	return cfg, nil
+}
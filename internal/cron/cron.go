@@ -0,0 +1,201 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package cron parses cron expressions of the five fields minute, hour,
+// day of month, month and day of week, and finds the times they match.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds the search of the next match, expressions like
+// "0 0 30 2 *" never match.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 7 is Sunday as well.
+	{name: "day of week", min: 0, max: 7, names: dayNames},
+}
+
+// Schedule - a parsed cron expression.
+type Schedule struct {
+	expr   string
+	bits   [5]uint64
+	domAny bool
+	dowAny bool
+}
+
+// Parse parses a cron expression of five fields separated by spaces,
+// each a "*", a value, a range "a-b" or a list of those separated by
+// commas, optionally followed by a step "/n". Months and days of week
+// may be given by their first three letters. The macros @yearly,
+// @monthly, @weekly, @daily and @hourly are accepted as well.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expr, len(fields))
+	}
+	s := &Schedule{expr: expr}
+	for i, part := range parts {
+		bits, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		s.bits[i] = bits
+	}
+	// Sunday is 0 and 7.
+	if s.bits[4]&(1<<7) != 0 {
+		s.bits[4] |= 1
+	}
+	s.domAny = parts[2] == "*"
+	s.dowAny = parts[4] == "*"
+	return s, nil
+}
+
+func parseField(part string, f field) (bits uint64, err error) {
+	for _, item := range strings.Split(part, ",") {
+		step := 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q of the %s", item[i+1:], f.name)
+			}
+			item = item[:i]
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case item == "*":
+		case strings.IndexByte(item, '-') > 0:
+			i := strings.IndexByte(item, '-')
+			if lo, err = parseValue(item[:i], f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(item[i+1:], f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q of the %s", item, f.name)
+			}
+		default:
+			if lo, err = parseValue(item, f); err != nil {
+				return 0, err
+			}
+			if step == 1 {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be between %d and %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+func (s *Schedule) has(i, v int) bool {
+	return s.bits[i]&(1<<uint(v)) != 0
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom, dow := s.has(2, t.Day()), s.has(4, int(t.Weekday()))
+	// When both days are restricted either matches, like cron.
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Matches returns whether the minute of t matches the schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.has(0, t.Minute()) && s.has(1, t.Hour()) && s.has(3, int(t.Month())) && s.matchesDay(t)
+}
+
+// Next returns the first minute matching the schedule after t, in the
+// location of t. The zero time is returned when the schedule does not
+// match within five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxSearch)
+	for t.Before(end) {
+		switch {
+		case !s.has(3, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.has(1, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.has(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		expr  string
+		valid bool
+	}{
+		{"* * * * *", true},
+		{"0 2 * * *", true},
+		{"*/15 9-17 * * mon-fri", true},
+		{"0 0 1,15 jan,jul *", true},
+		{"30 1 * * 7", true},
+		{"@daily", true},
+		{"@Hourly", true},
+		{"* * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"*/0 * * * *", false},
+		{"5-1 * * * *", false},
+		{"* * * * funday", false},
+		{"@sometimes", false},
+	}
+	for i, tc := range testCases {
+		_, err := Parse(tc.expr)
+		if (err == nil) != tc.valid {
+			t.Errorf("Test %d: %q expected valid %v, got %v", i+1, tc.expr, tc.valid, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2021, time.September, 15, 10, 7, 30, 0, time.UTC)
+	testCases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, time.September, 15, 10, 8, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2021, time.September, 16, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, time.September, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2021, time.September, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 1 * * sun", time.Date(2021, time.September, 19, 1, 0, 0, 0, time.UTC)},
+		{"0 1 * * 7", time.Date(2021, time.September, 19, 1, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day matches when both are restricted.
+		{"0 0 1 * fri", time.Date(2021, time.September, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for i, tc := range testCases {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("Test %d: %q expected next %v, got %v", i+1, tc.expr, tc.want, got)
+		}
+		if !tc.want.IsZero() && !s.Matches(tc.want) {
+			t.Errorf("Test %d: %q expected to match %v", i+1, tc.expr, tc.want)
+		}
+	}
+}
@@ -41,6 +41,12 @@ func Fdatasync(f *os.File) error {
 	return syscall.Fdatasync(int(f.Fd()))
 }
 
+// Syncfs - syncfs() commits to disk all the modified data and metadata of
+// the file system containing f.
+func Syncfs(f *os.File) error {
+	return unix.Syncfs(int(f.Fd()))
+}
+
 // FadviseDontNeed invalidates page-cache
 func FadviseDontNeed(f *os.File) error {
 	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
@@ -30,6 +30,11 @@ func Fdatasync(f *os.File) error {
 	return syscall.Fsync(int(f.Fd()))
 }
 
+// Syncfs is sync on freebsd/darwin, committing to disk all file systems
+func Syncfs(f *os.File) error {
+	return syscall.Sync()
+}
+
 // FadviseDontNeed is a no-op
 func FadviseDontNeed(f *os.File) error {
 	return nil
@@ -29,6 +29,11 @@ func Fdatasync(f *os.File) error {
 	return nil
 }
 
+// Syncfs is a no-op
+func Syncfs(f *os.File) error {
+	return nil
+}
+
 // FadviseDontNeed is a no-op
 func FadviseDontNeed(f *os.File) error {
 	return nil
@@ -25,7 +25,8 @@ import (
 // Name - event type enum.
 // Refer http://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html#notification-how-to-event-types-and-destinations
 // for most basic values we have since extend this and its not really much applicable other than a reference point.
-// "s3:Replication:OperationCompletedReplication" is a MinIO extension.
+// "s3:Replication:OperationCompletedReplication" and "s3:BucketQuota:Threshold"
+// are MinIO extensions.
 type Name int
 
 // Values of event Name
@@ -61,6 +62,7 @@ const (
 	ObjectTransitionAll
 	ObjectTransitionFailed
 	ObjectTransitionComplete
+	BucketQuotaThreshold
 )
 
 // Expand - returns expanded values of abbreviated event type.
@@ -70,6 +72,8 @@ func (name Name) Expand() []Name {
 		return []Name{BucketCreated}
 	case BucketRemoved:
 		return []Name{BucketRemoved}
+	case BucketQuotaThreshold:
+		return []Name{BucketQuotaThreshold}
 	case ObjectAccessedAll:
 		return []Name{
 			ObjectAccessedGet, ObjectAccessedHead,
@@ -117,6 +121,8 @@ func (name Name) String() string {
 		return "s3:BucketCreated:*"
 	case BucketRemoved:
 		return "s3:BucketRemoved:*"
+	case BucketQuotaThreshold:
+		return "s3:BucketQuota:Threshold"
 	case ObjectAccessedAll:
 		return "s3:ObjectAccessed:*"
 	case ObjectAccessedGet:
@@ -227,6 +233,8 @@ func ParseName(s string) (Name, error) {
 		return BucketCreated, nil
 	case "s3:BucketRemoved:*":
 		return BucketRemoved, nil
+	case "s3:BucketQuota:Threshold":
+		return BucketQuotaThreshold, nil
 	case "s3:ObjectAccessed:*":
 		return ObjectAccessedAll, nil
 	case "s3:ObjectAccessed:Get":
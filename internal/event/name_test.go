@@ -56,6 +56,7 @@ func TestNameString(t *testing.T) {
 	}{
 		{BucketCreated, "s3:BucketCreated:*"},
 		{BucketRemoved, "s3:BucketRemoved:*"},
+		{BucketQuotaThreshold, "s3:BucketQuota:Threshold"},
 		{ObjectAccessedAll, "s3:ObjectAccessed:*"},
 		{ObjectAccessedGet, "s3:ObjectAccessed:Get"},
 		{ObjectAccessedHead, "s3:ObjectAccessed:Head"},
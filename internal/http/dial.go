@@ -0,0 +1,47 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"net"
+	"time"
+)
+
+// InternodeDialOptions are the options of the internode dialer.
+type InternodeDialOptions struct {
+	Timeout time.Duration
+	// Interval of TCP keep-alive probes, a negative value disables them.
+	KeepAlive time.Duration
+	// Local address connections are made from, any if nil.
+	LocalAddr net.Addr
+}
+
+// LocalAddrInNetwork returns the first address of the interfaces of
+// this host in network, nil if there is none.
+func LocalAddrInNetwork(network *net.IPNet) net.Addr {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && network.Contains(ipNet.IP) {
+			return &net.TCPAddr{IP: ipNet.IP}
+		}
+	}
+	return nil
+}
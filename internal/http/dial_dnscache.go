@@ -36,6 +36,12 @@ import (
 // In this function, it uses functions from `rand` package. To make it really random,
 // you MUST call `rand.Seed` and change the value from the default in your application
 func DialContextWithDNSCache(resolver *dnscache.Resolver, baseDialCtx DialContext) DialContext {
+	return DialContextWithDNSCacheInNetwork(resolver, baseDialCtx, nil)
+}
+
+// DialContextWithDNSCacheInNetwork is like DialContextWithDNSCache, the
+// addresses of a host in preferred, if not nil, are dialed first.
+func DialContextWithDNSCacheInNetwork(resolver *dnscache.Resolver, baseDialCtx DialContext, preferred *net.IPNet) DialContext {
 	if baseDialCtx == nil {
 		// This is same as which `http.DefaultTransport` uses.
 		baseDialCtx = (&net.Dialer{
@@ -58,6 +64,9 @@ func DialContextWithDNSCache(resolver *dnscache.Resolver, baseDialCtx DialContex
 		if err != nil {
 			return nil, err
 		}
+		if preferred != nil {
+			ips = sortIPsInNetworkFirst(ips, preferred)
+		}
 
 		for _, ip := range ips {
 			conn, err = baseDialCtx(ctx, "tcp", net.JoinHostPort(ip, port))
@@ -69,3 +78,17 @@ func DialContextWithDNSCache(resolver *dnscache.Resolver, baseDialCtx DialContex
 		return
 	}
 }
+
+// sortIPsInNetworkFirst returns ips with the addresses in network first.
+func sortIPsInNetworkFirst(ips []string, network *net.IPNet) []string {
+	sorted := make([]string, 0, len(ips))
+	var others []string
+	for _, ip := range ips {
+		if network.Contains(net.ParseIP(ip)) {
+			sorted = append(sorted, ip)
+		} else {
+			others = append(others, ip)
+		}
+	}
+	return append(sorted, others...)
+}
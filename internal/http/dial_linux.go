@@ -70,6 +70,20 @@ func NewInternodeDialContext(dialTimeout time.Duration) DialContext {
 	}
 }
 
+// NewInternodeDialContextWithOptions setups a custom dialer for internode
+// communication with opts.
+func NewInternodeDialContextWithOptions(opts InternodeDialOptions) DialContext {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialer := &net.Dialer{
+			Timeout:   opts.Timeout,
+			KeepAlive: opts.KeepAlive,
+			LocalAddr: opts.LocalAddr,
+			Control:   setTCPParameters,
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
 // NewCustomDialContext setups a custom dialer for any external communication and proxies.
 func NewCustomDialContext(dialTimeout time.Duration) DialContext {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
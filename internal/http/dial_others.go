@@ -48,3 +48,16 @@ func NewCustomDialContext(dialTimeout time.Duration) DialContext {
 		return dialer.DialContext(ctx, network, addr)
 	}
 }
+
+// NewInternodeDialContextWithOptions setups a custom dialer for internode
+// communication with opts.
+func NewInternodeDialContextWithOptions(opts InternodeDialOptions) DialContext {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialer := &net.Dialer{
+			Timeout:   opts.Timeout,
+			KeepAlive: opts.KeepAlive,
+			LocalAddr: opts.LocalAddr,
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
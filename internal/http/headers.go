@@ -25,6 +25,7 @@ const (
 	ContentType        = "Content-Type"
 	ContentMD5         = "Content-Md5"
 	ContentEncoding    = "Content-Encoding"
+	AcceptEncoding     = "Accept-Encoding"
 	Expires            = "Expires"
 	ContentLength      = "Content-Length"
 	ContentLanguage    = "Content-Language"
@@ -83,6 +84,7 @@ const (
 	AmzCopySource                 = "X-Amz-Copy-Source"
 	AmzCopySourceVersionID        = "X-Amz-Copy-Source-Version-Id"
 	AmzCopySourceRange            = "X-Amz-Copy-Source-Range"
+	AmzRenameSource               = "X-Amz-Rename-Source"
 	AmzMetadataDirective          = "X-Amz-Metadata-Directive"
 	AmzObjectLockMode             = "X-Amz-Object-Lock-Mode"
 	AmzObjectLockRetainUntilDate  = "X-Amz-Object-Lock-Retain-Until-Date"
@@ -90,6 +92,7 @@ const (
 	AmzObjectLockBypassGovernance = "X-Amz-Bypass-Governance-Retention"
 	AmzBucketReplicationStatus    = "X-Amz-Replication-Status"
 	AmzSnowballExtract            = "X-Amz-Meta-Snowball-Auto-Extract"
+	AmzSnowballManifest           = "X-Amz-Meta-Minio-Snowball-Manifest"
 
 	// Multipart parts count
 	AmzMpPartsCount = "x-amz-mp-parts-count"
@@ -170,8 +173,16 @@ const (
 	MinIOSourceProxyRequest = "X-Minio-Source-Proxy-Request"
 	// Header indicates that this request is a replication request to create a REPLICA
 	MinIOSourceReplicationRequest = "X-Minio-Source-Replication-Request"
+	// Header indicates that a lifecycle or replication configuration is to
+	// be validated and its filters counted, without being applied.
+	MinIODryRun = "X-Minio-Dry-Run"
 	// Header indicates replication reset status.
 	MinIOReplicationResetStatus = "X-Minio-Replication-Reset-Status"
+	// Headers indicate the ID of a changelog streamed, and its first and
+	// next offsets when the stream started.
+	MinIOChangelogID          = "X-Minio-Changelog-Id"
+	MinIOChangelogFirstOffset = "X-Minio-Changelog-First-Offset"
+	MinIOChangelogNextOffset  = "X-Minio-Changelog-Next-Offset"
 
 	// Header indiicates last tag update time on source
 	MinIOSourceTaggingTimestamp = "X-Minio-Source-Replication-Tagging-Timestamp"
@@ -181,6 +192,44 @@ const (
 	MinIOSourceObjectLegalHoldTimestamp = "X-Minio-Source-Replication-LegalHold-Timestamp"
 	// predicted date/time of transition
 	MinIOTransition = "X-Minio-Transition"
+
+	// Header returning the position of the next append to an object
+	MinIONextAppendPosition = "X-Minio-Next-Append-Position"
+
+	// Header requesting objects stored gzip compressed to be decompressed,
+	// also accepted as a lower case query parameter.
+	MinIODecompress = "X-Minio-Decompress"
+
+	// Header fencing writes with the token of a lease of the object
+	MinIOFencingToken = "X-Minio-Fencing-Token"
+
+	// Header requesting the ExtractResult response of an extracting upload
+	MinIOExtractResult = "X-Minio-Extract-Result"
+
+	// Headers naming the object posted to the malware scan service
+	MinIOScanBucket = "X-Minio-Scan-Bucket"
+	MinIOScanObject = "X-Minio-Scan-Object"
+
+	// Header pinning a new bucket to the pools having all the comma
+	// separated tags
+	MinIOBucketPoolTags = "X-Minio-Bucket-Pool-Tags"
+
+	// Header naming the bucket template a new bucket is created with
+	MinIOBucketTemplate = "X-Minio-Bucket-Template"
+
+	// Headers reporting on HeadBucket the approximate usage of the bucket
+	// as of the last data usage scan
+	MinIOBucketUsageSize       = "X-Minio-Bucket-Usage-Size"
+	MinIOBucketUsageObjects    = "X-Minio-Bucket-Usage-Objects"
+	MinIOBucketUsageLastUpdate = "X-Minio-Bucket-Usage-Last-Update"
+
+	// Header reporting on HeadBucket the fsync policy of the objects
+	// committed to the bucket, one of always, batched or never
+	MinIOBucketFsyncPolicy = "X-Minio-Bucket-Fsync-Policy"
+
+	// Header carrying the client correlation ID of a request on the
+	// internode calls made for it
+	MinIOCorrelationID = "X-Minio-Correlation-Id"
 )
 
 // Common http query params S3 API
@@ -173,7 +173,8 @@ func NewServer(addrs []string, handler http.Handler, getCert certs.GetCertificat
 			GetCertificate:           getCert,
 		}
 
-		tlsClientIdentity := env.Get(xtls.EnvIdentityTLSEnabled, "") == config.EnableOn
+		tlsClientIdentity := env.Get(xtls.EnvIdentityTLSEnabled, "") == config.EnableOn ||
+			env.Get(xtls.EnvIdentityTLSClientAuth, "") == config.EnableOn
 		if tlsClientIdentity {
 			tlsConfig.ClientAuth = tls.RequestClientCert
 		}
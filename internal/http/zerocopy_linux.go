@@ -0,0 +1,257 @@
+//go:build linux
+// +build linux
+
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Writes smaller than this are copied, pinning pages for them
+	// costs more than the copy.
+	zeroCopyMinWrite = 16 << 10
+
+	// Bytes of zero-copy sends in flight on a connection, further sends
+	// wait for the kernel to release the pages of earlier ones.
+	zeroCopyMaxInflight = 4 << 20
+
+	// How long to wait for the kernel to release the pages of a send.
+	zeroCopyCompletionTimeout = 5 * time.Second
+)
+
+var errZeroCopyTimeout = errors.New("timed out waiting for zero-copy send completions")
+
+// zeroCopyConn sends large writes with MSG_ZEROCOPY, the kernel sends
+// from the pages of the buffer instead of copying it. The completions
+// of the sends are reaped while sending, at most zeroCopyMaxInflight
+// bytes being in flight. Write returns once the kernel released all
+// pages of the buffer, the caller may reuse it.
+type zeroCopyConn struct {
+	*net.TCPConn
+	raw syscall.RawConn
+
+	mu            sync.Mutex
+	disabled      bool
+	sent          uint32 // zero-copy sends issued
+	completed     uint32 // zero-copy sends released by the kernel
+	inflight      []int  // sizes of the sends in flight, oldest first
+	inflightBytes int
+	oob           []byte
+}
+
+// NewZeroCopyConn returns c sending large writes with MSG_ZEROCOPY,
+// or c unchanged if it is not a TCP connection or the kernel does not
+// support zero-copy sends, before Linux 4.14.
+func NewZeroCopyConn(c net.Conn) net.Conn {
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return c
+	}
+	raw, err := tc.SyscallConn()
+	if err != nil {
+		return c
+	}
+	var serr error
+	if err = raw.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ZEROCOPY, 1)
+	}); err != nil || serr != nil {
+		return c
+	}
+	return &zeroCopyConn{TCPConn: tc, raw: raw, oob: make([]byte, 128)}
+}
+
+func (c *zeroCopyConn) Write(b []byte) (int, error) {
+	if len(b) < zeroCopyMinWrite {
+		return c.TCPConn.Write(b)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled {
+		return c.TCPConn.Write(b)
+	}
+
+	var written int
+	var serr error
+	err := c.raw.Write(func(fd uintptr) bool {
+		for written < len(b) {
+			if c.inflightBytes > zeroCopyMaxInflight-zeroCopyMinWrite {
+				if serr = c.reap(int(fd), time.Now().Add(zeroCopyCompletionTimeout)); serr != nil {
+					return true
+				}
+				continue
+			}
+			chunk := b[written:]
+			if n := zeroCopyMaxInflight - c.inflightBytes; len(chunk) > n {
+				chunk = chunk[:n]
+			}
+			n, err := unix.SendmsgN(int(fd), chunk, nil, nil, unix.MSG_ZEROCOPY)
+			switch err {
+			case nil:
+			case unix.EAGAIN:
+				// Reap the completions while the socket is full.
+				serr = c.reap(int(fd), time.Time{})
+				return serr != nil
+			case unix.EINTR:
+				continue
+			case unix.ENOBUFS:
+				// Out of pinned memory, wait for the sends in
+				// flight or copy the rest.
+				if len(c.inflight) == 0 {
+					return true
+				}
+				if serr = c.reap(int(fd), time.Now().Add(zeroCopyCompletionTimeout)); serr != nil {
+					return true
+				}
+				continue
+			default:
+				serr = err
+				return true
+			}
+			c.sent++
+			c.inflight = append(c.inflight, n)
+			c.inflightBytes += n
+			written += n
+			if serr = c.reap(int(fd), time.Time{}); serr != nil {
+				return true
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = serr
+	}
+	if err == errZeroCopyTimeout {
+		c.abort()
+	} else if cerr := c.awaitCompletions(); err == nil {
+		err = cerr
+	}
+	if err != nil || written == len(b) {
+		return written, err
+	}
+	n, err := c.TCPConn.Write(b[written:])
+	return written + n, err
+}
+
+// awaitCompletions waits until the kernel released all zero-copy
+// sends. The connection is reset if it does not in time.
+func (c *zeroCopyConn) awaitCompletions() error {
+	if len(c.inflight) == 0 {
+		return nil
+	}
+	deadline := time.Now().Add(zeroCopyCompletionTimeout)
+	var rerr error
+	err := c.raw.Control(func(fd uintptr) {
+		for len(c.inflight) > 0 && rerr == nil {
+			rerr = c.reap(int(fd), deadline)
+		}
+	})
+	if err == nil {
+		err = rerr
+	}
+	if err == errZeroCopyTimeout {
+		c.abort()
+	}
+	return err
+}
+
+// reap records the completions queued on the error queue of the
+// socket. Unless deadline is zero it waits until then for at least
+// one completion.
+func (c *zeroCopyConn) reap(fd int, deadline time.Time) error {
+	reaped := false
+	for {
+		_, oobn, _, _, err := unix.Recvmsg(fd, nil, c.oob, unix.MSG_ERRQUEUE)
+		switch err {
+		case nil:
+			c.parseCompletions(c.oob[:oobn])
+			reaped = true
+		case unix.EINTR:
+		case unix.EAGAIN:
+			if reaped || deadline.IsZero() {
+				return nil
+			}
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return errZeroCopyTimeout
+			}
+			// Pending notifications flag the socket with POLLERR.
+			fds := []unix.PollFd{{Fd: int32(fd)}}
+			if _, err = unix.Poll(fds, int(wait/time.Millisecond)+1); err != nil && err != unix.EINTR {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// abort resets the connection after a send the kernel did not release:
+// the caller reuses the buffer whose pages may still be queued, they
+// are discarded instead of sent. Later writes are copied.
+func (c *zeroCopyConn) abort() {
+	c.disabled = true
+	c.completed = c.sent
+	c.inflight, c.inflightBytes = nil, 0
+	c.TCPConn.SetLinger(0)
+	c.TCPConn.Close()
+}
+
+// parseCompletions records the sends released by the notifications in
+// oob.
+func (c *zeroCopyConn) parseCompletions(oob []byte) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return
+	}
+	for _, msg := range msgs {
+		if hi, ok := zeroCopyCompletion(msg.Data); ok {
+			c.complete(hi)
+		}
+	}
+}
+
+// zeroCopyCompletion decodes the last send id released by a completion
+// notification, a struct sock_extended_err: errno u32, origin u8, type
+// u8, code u8, pad u8, info u32 and data u32, the range of send ids
+// released, in host byte order.
+func zeroCopyCompletion(d []byte) (hi uint32, ok bool) {
+	if len(d) < 16 || d[4] != unix.SO_EE_ORIGIN_ZEROCOPY {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(d[12:16]), true
+}
+
+// complete releases the sends in flight up to the send id hi. Send ids
+// wrap around, completions are in order, those of sends already
+// released after a reset are ignored.
+func (c *zeroCopyConn) complete(hi uint32) {
+	for next := hi + 1; int32(next-c.completed) > 0 && len(c.inflight) > 0; c.completed++ {
+		c.inflightBytes -= c.inflight[0]
+		c.inflight = c.inflight[1:]
+	}
+}
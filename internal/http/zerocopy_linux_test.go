@@ -0,0 +1,109 @@
+//go:build linux
+// +build linux
+
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestZeroCopyConn(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	data := make([]byte, 4<<20)
+	if _, err = rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan []byte, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer c.Close()
+		b, _ := ioutil.ReadAll(c)
+		received <- b
+	}()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = NewZeroCopyConn(c)
+	// Small and large writes, both reusing the buffer right away.
+	for _, b := range [][]byte{data[:100], data[100 : 1<<20], data[1<<20:]} {
+		buf := append([]byte(nil), b...)
+		if n, err := c.Write(buf); err != nil || n != len(buf) {
+			t.Fatalf("wrote %d of %d bytes: %v", n, len(buf), err)
+		}
+		for i := range buf {
+			buf[i] = 0
+		}
+	}
+	c.Close()
+
+	if got := <-received; !bytes.Equal(got, data) {
+		t.Fatalf("received %d bytes differing from the %d bytes sent", len(got), len(data))
+	}
+}
+
+func TestZeroCopyCompletions(t *testing.T) {
+	// Notifications are not aligned in the control messages.
+	d := make([]byte, 17)[1:]
+	d[4] = unix.SO_EE_ORIGIN_ZEROCOPY
+	binary.LittleEndian.PutUint32(d[8:], 2)
+	binary.LittleEndian.PutUint32(d[12:], 3)
+	if hi, ok := zeroCopyCompletion(d); !ok || hi != 3 {
+		t.Fatalf("expected send 3 released, got %d %v", hi, ok)
+	}
+	d[4] = 0
+	if _, ok := zeroCopyCompletion(d); ok {
+		t.Fatal("expected other notifications to be ignored")
+	}
+
+	// Send ids wrap around.
+	c := &zeroCopyConn{sent: 2, completed: ^uint32(1), inflight: []int{1, 2, 3, 4}, inflightBytes: 10}
+	c.complete(^uint32(0))
+	if c.completed != 0 || len(c.inflight) != 2 || c.inflightBytes != 7 {
+		t.Fatalf("unexpected state after completion %d: %d %v %d", ^uint32(0), c.completed, c.inflight, c.inflightBytes)
+	}
+	// Late completions of released sends are ignored.
+	c.complete(^uint32(1))
+	c.complete(1)
+	if c.completed != 2 || len(c.inflight) != 0 || c.inflightBytes != 0 {
+		t.Fatalf("unexpected state after completion 1: %d %v %d", c.completed, c.inflight, c.inflightBytes)
+	}
+	c.complete(5)
+	if c.completed != 2 {
+		t.Fatalf("unexpected completions without sends in flight: %d", c.completed)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/klauspost/compress/gzhttp"
@@ -32,17 +33,25 @@ import (
 
 // ResponseWriter - is a wrapper to trap the http response status code.
 type ResponseWriter struct {
+	// number of bytes written, updated atomically as it may be
+	// read while the request is in flight, keep it first for
+	// 64-bit alignment.
+	bytesWritten int64
+	// nanoseconds spent writing and flushing the response when
+	// TimeWrites is set, updated atomically.
+	writeTime int64
+
 	http.ResponseWriter
 	StatusCode int
 	// Log body of 4xx or 5xx responses
 	LogErrBody bool
 	// Log body of all responses
 	LogAllBody bool
+	// Measure the time spent writing the response
+	TimeWrites bool
 
 	TimeToFirstByte time.Duration
 	StartTime       time.Time
-	// number of bytes written
-	bytesWritten int
 	// Internal recording buffer
 	headers bytes.Buffer
 	body    bytes.Buffer
@@ -66,8 +75,15 @@ func (lrw *ResponseWriter) Write(p []byte) (int, error) {
 		// that way following Golang HTTP response behavior.
 		lrw.WriteHeader(http.StatusOK)
 	}
+	var start time.Time
+	if lrw.TimeWrites {
+		start = time.Now()
+	}
 	n, err := lrw.ResponseWriter.Write(p)
-	lrw.bytesWritten += n
+	if lrw.TimeWrites {
+		atomic.AddInt64(&lrw.writeTime, int64(time.Since(start)))
+	}
+	atomic.AddInt64(&lrw.bytesWritten, int64(n))
 	if lrw.TimeToFirstByte == 0 {
 		lrw.TimeToFirstByte = time.Now().UTC().Sub(lrw.StartTime)
 	}
@@ -84,10 +100,10 @@ func (lrw *ResponseWriter) Write(p []byte) (int, error) {
 // Write the headers into the given buffer
 func (lrw *ResponseWriter) writeHeaders(w io.Writer, statusCode int, headers http.Header) {
 	n, _ := fmt.Fprintf(w, "%d %s\n", statusCode, http.StatusText(statusCode))
-	lrw.bytesWritten += n
+	atomic.AddInt64(&lrw.bytesWritten, int64(n))
 	for k, v := range headers {
 		n, _ := fmt.Fprintf(w, "%s: %s\n", k, v[0])
-		lrw.bytesWritten += n
+		atomic.AddInt64(&lrw.bytesWritten, int64(n))
 	}
 }
 
@@ -117,12 +133,23 @@ func (lrw *ResponseWriter) WriteHeader(code int) {
 
 // Flush - Calls the underlying Flush.
 func (lrw *ResponseWriter) Flush() {
+	if lrw.TimeWrites {
+		defer func(start time.Time) {
+			atomic.AddInt64(&lrw.writeTime, int64(time.Since(start)))
+		}(time.Now())
+	}
 	lrw.ResponseWriter.(http.Flusher).Flush()
 }
 
+// WriteTime - returns the time spent writing the response, 0 unless
+// TimeWrites is set.
+func (lrw *ResponseWriter) WriteTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&lrw.writeTime))
+}
+
 // Size - reutrns the number of bytes written
 func (lrw *ResponseWriter) Size() int {
-	return lrw.bytesWritten
+	return int(atomic.LoadInt64(&lrw.bytesWritten))
 }
 
 const contextAuditKey = contextKeyType("audit-entry")
@@ -204,6 +231,7 @@ func AuditLog(ctx context.Context, w http.ResponseWriter, r *http.Request, reqCl
 			outputBytes = int64(st.Size())
 		}
 
+		entry.CorrelationID = reqInfo.CorrelationID
 		entry.API.Name = reqInfo.API
 		entry.API.Bucket = reqInfo.BucketName
 		entry.API.Object = reqInfo.ObjectName
@@ -224,6 +252,8 @@ func AuditLog(ctx context.Context, w http.ResponseWriter, r *http.Request, reqCl
 		}
 	}
 
+	redactAuditEntry(&entry)
+
 	// Send audit logs only to http targets.
 	for _, t := range AuditTargets {
 		_ = t.Send(entry, string(All))
@@ -21,13 +21,18 @@ import (
 	"crypto/tls"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
 
 	"github.com/minio/pkg/env"
 	xnet "github.com/minio/pkg/net"
 
 	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger/target/file"
 	"github.com/minio/minio/internal/logger/target/http"
 	"github.com/minio/minio/internal/logger/target/kafka"
+	"github.com/minio/minio/internal/logger/target/syslog"
 )
 
 // Console logger target
@@ -55,6 +60,23 @@ const (
 	KafkaClientTLSKey  = "client_tls_key"
 	KafkaVersion       = "version"
 
+	SyslogAddress       = "address"
+	SyslogProtocol      = "protocol"
+	SyslogFacility      = "facility"
+	SyslogAppName       = "app_name"
+	SyslogTLSSkipVerify = "tls_skip_verify"
+
+	FileDir                 = "dir"
+	FileMaxSize             = "max_size"
+	FileRotateInterval      = "rotate_interval"
+	FileCompress            = "compress"
+	FileUploadEndpoint      = "upload_endpoint"
+	FileUploadAccessKey     = "upload_access_key"
+	FileUploadSecretKey     = "upload_secret_key"
+	FileUploadBucket        = "upload_bucket"
+	FileUploadRetentionMode = "upload_retention_mode"
+	FileUploadRetentionDays = "upload_retention_days"
+
 	EnvLoggerWebhookEnable    = "MINIO_LOGGER_WEBHOOK_ENABLE"
 	EnvLoggerWebhookEndpoint  = "MINIO_LOGGER_WEBHOOK_ENDPOINT"
 	EnvLoggerWebhookAuthToken = "MINIO_LOGGER_WEBHOOK_AUTH_TOKEN"
@@ -78,6 +100,25 @@ const (
 	EnvKafkaClientTLSCert = "MINIO_AUDIT_KAFKA_CLIENT_TLS_CERT"
 	EnvKafkaClientTLSKey  = "MINIO_AUDIT_KAFKA_CLIENT_TLS_KEY"
 	EnvKafkaVersion       = "MINIO_AUDIT_KAFKA_VERSION"
+
+	EnvSyslogEnable        = "MINIO_LOGGER_SYSLOG_ENABLE"
+	EnvSyslogAddress       = "MINIO_LOGGER_SYSLOG_ADDRESS"
+	EnvSyslogProtocol      = "MINIO_LOGGER_SYSLOG_PROTOCOL"
+	EnvSyslogFacility      = "MINIO_LOGGER_SYSLOG_FACILITY"
+	EnvSyslogAppName       = "MINIO_LOGGER_SYSLOG_APP_NAME"
+	EnvSyslogTLSSkipVerify = "MINIO_LOGGER_SYSLOG_TLS_SKIP_VERIFY"
+
+	EnvFileEnable              = "MINIO_AUDIT_FILE_ENABLE"
+	EnvFileDir                 = "MINIO_AUDIT_FILE_DIR"
+	EnvFileMaxSize             = "MINIO_AUDIT_FILE_MAX_SIZE"
+	EnvFileRotateInterval      = "MINIO_AUDIT_FILE_ROTATE_INTERVAL"
+	EnvFileCompress            = "MINIO_AUDIT_FILE_COMPRESS"
+	EnvFileUploadEndpoint      = "MINIO_AUDIT_FILE_UPLOAD_ENDPOINT"
+	EnvFileUploadAccessKey     = "MINIO_AUDIT_FILE_UPLOAD_ACCESS_KEY"
+	EnvFileUploadSecretKey     = "MINIO_AUDIT_FILE_UPLOAD_SECRET_KEY"
+	EnvFileUploadBucket        = "MINIO_AUDIT_FILE_UPLOAD_BUCKET"
+	EnvFileUploadRetentionMode = "MINIO_AUDIT_FILE_UPLOAD_RETENTION_MODE"
+	EnvFileUploadRetentionDays = "MINIO_AUDIT_FILE_UPLOAD_RETENTION_DAYS"
 )
 
 // Default KVS for loggerHTTP and loggerAuditHTTP
@@ -174,14 +215,90 @@ var (
 			Value: "",
 		},
 	}
+
+	DefaultSyslogKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   SyslogAddress,
+			Value: "",
+		},
+		config.KV{
+			Key:   SyslogProtocol,
+			Value: syslog.ProtocolUDP,
+		},
+		config.KV{
+			Key:   SyslogFacility,
+			Value: "local0",
+		},
+		config.KV{
+			Key:   SyslogAppName,
+			Value: "minio",
+		},
+		config.KV{
+			Key:   SyslogTLSSkipVerify,
+			Value: config.EnableOff,
+		},
+	}
+
+	DefaultAuditFileKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   FileDir,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileMaxSize,
+			Value: "100MiB",
+		},
+		config.KV{
+			Key:   FileRotateInterval,
+			Value: "1h",
+		},
+		config.KV{
+			Key:   FileCompress,
+			Value: config.EnableOn,
+		},
+		config.KV{
+			Key:   FileUploadEndpoint,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileUploadAccessKey,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileUploadSecretKey,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileUploadBucket,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileUploadRetentionMode,
+			Value: "",
+		},
+		config.KV{
+			Key:   FileUploadRetentionDays,
+			Value: "0",
+		},
+	}
 )
 
 // Config console and http logger targets
 type Config struct {
-	Console      Console                 `json:"console"`
-	HTTP         map[string]http.Config  `json:"http"`
-	AuditWebhook map[string]http.Config  `json:"audit"`
-	AuditKafka   map[string]kafka.Config `json:"audit_kafka"`
+	Console      Console                  `json:"console"`
+	HTTP         map[string]http.Config   `json:"http"`
+	Syslog       map[string]syslog.Config `json:"syslog"`
+	AuditWebhook map[string]http.Config   `json:"audit"`
+	AuditKafka   map[string]kafka.Config  `json:"audit_kafka"`
+	AuditFile    map[string]file.Config   `json:"audit_file"`
 }
 
 // NewConfig - initialize new logger config.
@@ -192,8 +309,10 @@ func NewConfig() Config {
 			Enabled: true,
 		},
 		HTTP:         make(map[string]http.Config),
+		Syslog:       make(map[string]syslog.Config),
 		AuditWebhook: make(map[string]http.Config),
 		AuditKafka:   make(map[string]kafka.Config),
+		AuditFile:    make(map[string]file.Config),
 	}
 
 	return cfg
@@ -373,6 +492,128 @@ func GetAuditKafka(kafkaKVS map[string]config.KVS) (map[string]kafka.Config, err
 	return kafkaTargets, nil
 }
 
+// GetLoggerSyslog - returns a map of registered logger 'syslog' targets
+func GetLoggerSyslog(syslogKVS map[string]config.KVS) (map[string]syslog.Config, error) {
+	syslogTargets := make(map[string]syslog.Config)
+	for k, kv := range config.Merge(syslogKVS, EnvSyslogEnable, DefaultSyslogKVS) {
+		getEnv := func(name, key string) string {
+			if k != config.Default {
+				name = name + config.Default + k
+			}
+			return env.Get(name, kv.Get(key))
+		}
+		enabled, err := config.ParseBool(getEnv(EnvSyslogEnable, config.Enable))
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+
+		syslogArgs := syslog.Config{
+			Enabled:  true,
+			Address:  getEnv(EnvSyslogAddress, SyslogAddress),
+			Protocol: strings.ToLower(getEnv(EnvSyslogProtocol, SyslogProtocol)),
+			AppName:  getEnv(EnvSyslogAppName, SyslogAppName),
+		}
+		if _, err = xnet.ParseHost(syslogArgs.Address); err != nil {
+			return nil, config.Errorf("syslog 'address' value invalid: %s", err)
+		}
+		switch syslogArgs.Protocol {
+		case syslog.ProtocolUDP, syslog.ProtocolTCP, syslog.ProtocolTLS:
+		default:
+			return nil, config.Errorf("syslog 'protocol' must be one of udp, tcp or tls")
+		}
+		facility, ok := syslog.Facilities[strings.ToLower(getEnv(EnvSyslogFacility, SyslogFacility))]
+		if !ok {
+			return nil, config.Errorf("syslog 'facility' value invalid: %s", getEnv(EnvSyslogFacility, SyslogFacility))
+		}
+		syslogArgs.Facility = facility
+		if syslogArgs.TLSSkipVerify, err = config.ParseBool(getEnv(EnvSyslogTLSSkipVerify, SyslogTLSSkipVerify)); err != nil {
+			return nil, err
+		}
+
+		syslogTargets[k] = syslogArgs
+	}
+
+	return syslogTargets, nil
+}
+
+// GetAuditFile - returns a map of registered audit 'file' targets
+func GetAuditFile(fileKVS map[string]config.KVS) (map[string]file.Config, error) {
+	fileTargets := make(map[string]file.Config)
+	for k, kv := range config.Merge(fileKVS, EnvFileEnable, DefaultAuditFileKVS) {
+		getEnv := func(name, key string) string {
+			if k != config.Default {
+				name = name + config.Default + k
+			}
+			return env.Get(name, kv.Get(key))
+		}
+		enabled, err := config.ParseBool(getEnv(EnvFileEnable, config.Enable))
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+
+		fileArgs := file.Config{
+			Enabled:             true,
+			Dir:                 getEnv(EnvFileDir, FileDir),
+			UploadEndpoint:      getEnv(EnvFileUploadEndpoint, FileUploadEndpoint),
+			UploadAccessKey:     getEnv(EnvFileUploadAccessKey, FileUploadAccessKey),
+			UploadSecretKey:     getEnv(EnvFileUploadSecretKey, FileUploadSecretKey),
+			UploadBucket:        getEnv(EnvFileUploadBucket, FileUploadBucket),
+			UploadRetentionMode: strings.ToUpper(getEnv(EnvFileUploadRetentionMode, FileUploadRetentionMode)),
+		}
+		if fileArgs.Dir == "" {
+			return nil, config.Errorf("audit file 'dir' cannot be empty")
+		}
+		if v := getEnv(EnvFileMaxSize, FileMaxSize); v != "" {
+			if fileArgs.MaxSize, err = humanize.ParseBytes(v); err != nil {
+				return nil, config.Errorf("audit file 'max_size' value invalid: %s", err)
+			}
+		}
+		if v := getEnv(EnvFileRotateInterval, FileRotateInterval); v != "" {
+			if fileArgs.RotateInterval, err = time.ParseDuration(v); err != nil {
+				return nil, config.Errorf("audit file 'rotate_interval' value invalid: %s", err)
+			}
+			if fileArgs.RotateInterval < 0 || (fileArgs.RotateInterval > 0 && fileArgs.RotateInterval < file.MinRotateInterval) {
+				return nil, config.Errorf("audit file 'rotate_interval' must be at least %s, or 0 to disable it", file.MinRotateInterval)
+			}
+		}
+		if fileArgs.Compress, err = config.ParseBool(getEnv(EnvFileCompress, FileCompress)); err != nil {
+			return nil, err
+		}
+
+		if fileArgs.UploadEndpoint != "" {
+			if _, err = xnet.ParseHTTPURL(fileArgs.UploadEndpoint); err != nil {
+				return nil, config.Errorf("audit file 'upload_endpoint' value invalid: %s", err)
+			}
+			if fileArgs.UploadBucket == "" || fileArgs.UploadAccessKey == "" || fileArgs.UploadSecretKey == "" {
+				return nil, config.Errorf("audit file 'upload_bucket', 'upload_access_key' and 'upload_secret_key' are required with 'upload_endpoint'")
+			}
+		}
+		switch fileArgs.UploadRetentionMode {
+		case "", "GOVERNANCE", "COMPLIANCE":
+		default:
+			return nil, config.Errorf("audit file 'upload_retention_mode' must be one of GOVERNANCE or COMPLIANCE")
+		}
+		if v := getEnv(EnvFileUploadRetentionDays, FileUploadRetentionDays); v != "" {
+			if fileArgs.UploadRetentionDays, err = strconv.Atoi(v); err != nil || fileArgs.UploadRetentionDays < 0 {
+				return nil, config.Errorf("audit file 'upload_retention_days' value invalid: %s", v)
+			}
+		}
+		if fileArgs.UploadRetentionMode != "" && fileArgs.UploadRetentionDays == 0 {
+			return nil, config.Errorf("audit file 'upload_retention_days' is required with 'upload_retention_mode'")
+		}
+
+		fileTargets[k] = fileArgs
+	}
+
+	return fileTargets, nil
+}
+
 // LookupConfig - lookup logger config, override with ENVs if set.
 func LookupConfig(scfg config.Config) (Config, error) {
 	// Lookup for legacy environment variables first
@@ -536,10 +777,20 @@ func LookupConfig(scfg config.Config) (Config, error) {
 		}
 	}
 
+	cfg.Syslog, err = GetLoggerSyslog(scfg[config.LoggerSyslogSubSys])
+	if err != nil {
+		return cfg, err
+	}
+
 	cfg.AuditKafka, err = GetAuditKafka(scfg[config.AuditKafkaSubSys])
 	if err != nil {
 		return cfg, err
 	}
 
+	cfg.AuditFile, err = GetAuditFile(scfg[config.AuditFileSubSys])
+	if err != nil {
+		return cfg, err
+	}
+
 	return cfg, nil
 }
@@ -164,4 +164,111 @@ var (
 			Type:        "sentence",
 		},
 	}
+
+	HelpSyslog = config.HelpKVS{
+		config.HelpKV{
+			Key:         SyslogAddress,
+			Description: `syslog server address e.g. "syslog.example.net:514"`,
+			Type:        "address",
+		},
+		config.HelpKV{
+			Key:         SyslogProtocol,
+			Description: `transport protocol, one of 'udp', 'tcp' or 'tls', defaults to 'udp'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         SyslogFacility,
+			Description: `syslog facility e.g. "daemon", defaults to 'local0'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         SyslogAppName,
+			Description: `application name reported in messages, defaults to 'minio'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         SyslogTLSSkipVerify,
+			Description: `trust server TLS without verification, defaults to "off" (verify)`,
+			Optional:    true,
+			Type:        "on|off",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+
+	HelpFile = config.HelpKVS{
+		config.HelpKV{
+			Key:         FileDir,
+			Description: `local directory to write audit log segments to e.g. "/var/log/minio/audit"`,
+			Type:        "path",
+		},
+		config.HelpKV{
+			Key:         FileMaxSize,
+			Description: `rotate the active segment once it reaches this size, defaults to '100MiB'`,
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         FileRotateInterval,
+			Description: `rotate the active segment once it is this old e.g. "1h", at least '1m', defaults to '1h'`,
+			Optional:    true,
+			Type:        "duration",
+		},
+		config.HelpKV{
+			Key:         FileCompress,
+			Description: `set to 'off' to keep closed segments uncompressed, defaults to 'on'`,
+			Optional:    true,
+			Type:        "on|off",
+		},
+		config.HelpKV{
+			Key:         FileUploadEndpoint,
+			Description: `upload closed segments to a bucket on this S3 endpoint e.g. "https://audit.example.net:9000"`,
+			Optional:    true,
+			Type:        "url",
+		},
+		config.HelpKV{
+			Key:         FileUploadAccessKey,
+			Description: "access key for the audit bucket endpoint",
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         FileUploadSecretKey,
+			Description: "secret key for the audit bucket endpoint",
+			Optional:    true,
+			Type:        "string",
+			Sensitive:   true,
+		},
+		config.HelpKV{
+			Key:         FileUploadBucket,
+			Description: "bucket to upload closed segments to",
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         FileUploadRetentionMode,
+			Description: "object lock retention mode set on uploaded segments, one of GOVERNANCE or COMPLIANCE",
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         FileUploadRetentionDays,
+			Description: "number of days uploaded segments are retained with object lock",
+			Optional:    true,
+			Type:        "number",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
 )
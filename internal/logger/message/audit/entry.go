@@ -27,7 +27,11 @@ import (
 )
 
 // Version - represents the current version of audit log structure.
-const Version = "1"
+// It is bumped on incompatible changes only, new optional fields are
+// added without changing the version. Version 2 masks the fields of the
+// audit redaction rules, the masked claims are strings whatever their
+// original type.
+const Version = "2"
 
 // Entry - audit entry logs.
 type Entry struct {
@@ -46,14 +50,17 @@ type Entry struct {
 		TimeToFirstByte string `json:"timeToFirstByte,omitempty"`
 		TimeToResponse  string `json:"timeToResponse,omitempty"`
 	} `json:"api"`
-	RemoteHost string                 `json:"remotehost,omitempty"`
-	RequestID  string                 `json:"requestID,omitempty"`
-	UserAgent  string                 `json:"userAgent,omitempty"`
-	ReqClaims  map[string]interface{} `json:"requestClaims,omitempty"`
-	ReqQuery   map[string]string      `json:"requestQuery,omitempty"`
-	ReqHeader  map[string]string      `json:"requestHeader,omitempty"`
-	RespHeader map[string]string      `json:"responseHeader,omitempty"`
-	Tags       map[string]interface{} `json:"tags,omitempty"`
+	RemoteHost    string                 `json:"remotehost,omitempty"`
+	RequestID     string                 `json:"requestID,omitempty"`
+	CorrelationID string                 `json:"correlationID,omitempty"`
+	UserAgent     string                 `json:"userAgent,omitempty"`
+	ReqClaims     map[string]interface{} `json:"requestClaims,omitempty"`
+	ReqQuery      map[string]string      `json:"requestQuery,omitempty"`
+	ReqHeader     map[string]string      `json:"requestHeader,omitempty"`
+	RespHeader    map[string]string      `json:"responseHeader,omitempty"`
+	Tags          map[string]interface{} `json:"tags,omitempty"`
+	// Redacted lists the fields masked by the audit redaction rules.
+	Redacted []string `json:"redacted,omitempty"`
 }
 
 // NewEntry - constructs an audit entry object with some fields filled
@@ -0,0 +1,103 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import "strings"
+
+// RedactedValue replaces the value of redacted fields.
+const RedactedValue = "*REDACTED*"
+
+// Redaction lists the fields of an audit entry whose values are
+// masked before the entry is sent to any audit target.
+type Redaction struct {
+	// QueryParams are matched case-insensitively against requestQuery.
+	QueryParams []string `json:"queryParams"`
+	// Headers are matched case-insensitively against requestHeader
+	// and responseHeader.
+	Headers []string `json:"headers"`
+	// Claims are matched exactly against requestClaims.
+	Claims []string `json:"claims"`
+}
+
+// IsEmpty returns true if no field is redacted.
+func (r Redaction) IsEmpty() bool {
+	return len(r.QueryParams) == 0 && len(r.Headers) == 0 && len(r.Claims) == 0
+}
+
+// redactFold returns m with the values of keys masked, copied if any
+// is, m being shared with the request.
+func redactFold(m map[string]string, keys []string, field string, redacted []string) (map[string]string, []string) {
+	var masked map[string]string
+	for k, v := range m {
+		if v == "" {
+			continue
+		}
+		for _, key := range keys {
+			if strings.EqualFold(k, key) {
+				if masked == nil {
+					masked = make(map[string]string, len(m))
+					for k, v := range m {
+						masked[k] = v
+					}
+				}
+				masked[k] = RedactedValue
+				redacted = append(redacted, field+"."+k)
+				break
+			}
+		}
+	}
+	if masked == nil {
+		return m, redacted
+	}
+	return masked, redacted
+}
+
+// Apply masks the configured fields of e, the maps holding masked
+// values are replaced by copies such that the maps of e can be shared
+// with other entries.
+func (r Redaction) Apply(e *Entry) {
+	if r.IsEmpty() {
+		return
+	}
+	var redacted []string
+	e.ReqQuery, redacted = redactFold(e.ReqQuery, r.QueryParams, "requestQuery", redacted)
+	e.ReqHeader, redacted = redactFold(e.ReqHeader, r.Headers, "requestHeader", redacted)
+	e.RespHeader, redacted = redactFold(e.RespHeader, r.Headers, "responseHeader", redacted)
+	if len(e.ReqClaims) > 0 && len(r.Claims) > 0 {
+		var claims map[string]interface{}
+		for _, key := range r.Claims {
+			if _, ok := e.ReqClaims[key]; !ok {
+				continue
+			}
+			if claims == nil {
+				claims = make(map[string]interface{}, len(e.ReqClaims))
+				for k, v := range e.ReqClaims {
+					claims[k] = v
+				}
+			}
+			claims[key] = RedactedValue
+			redacted = append(redacted, "requestClaims."+key)
+		}
+		if claims != nil {
+			e.ReqClaims = claims
+		}
+	}
+	if len(redacted) > 0 {
+		e.Redacted = append(append([]string(nil), e.Redacted...), redacted...)
+	}
+}
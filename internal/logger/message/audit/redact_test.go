@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRedactionApply(t *testing.T) {
+	rules := Redaction{
+		QueryParams: []string{"X-Amz-Signature"},
+		Headers:     []string{"authorization"},
+		Claims:      []string{"accessKey"},
+	}
+	testCases := []struct {
+		rules    Redaction
+		entry    Entry
+		expected Entry
+	}{
+		// No rules, the entry is left as is.
+		{
+			rules:    Redaction{},
+			entry:    Entry{Version: Version, ReqHeader: map[string]string{"Authorization": "secret"}},
+			expected: Entry{Version: Version, ReqHeader: map[string]string{"Authorization": "secret"}},
+		},
+		// Query parameters and headers are matched case-insensitively.
+		{
+			rules: rules,
+			entry: Entry{
+				Version:    Version,
+				ReqQuery:   map[string]string{"x-amz-signature": "sig", "prefix": "a"},
+				ReqHeader:  map[string]string{"Authorization": "secret", "Host": "minio"},
+				RespHeader: map[string]string{"AUTHORIZATION": "secret"},
+			},
+			expected: Entry{
+				Version:    Version,
+				ReqQuery:   map[string]string{"x-amz-signature": RedactedValue, "prefix": "a"},
+				ReqHeader:  map[string]string{"Authorization": RedactedValue, "Host": "minio"},
+				RespHeader: map[string]string{"AUTHORIZATION": RedactedValue},
+				Redacted:   []string{"requestHeader.Authorization", "requestQuery.x-amz-signature", "responseHeader.AUTHORIZATION"},
+			},
+		},
+		// Empty values are not redacted.
+		{
+			rules:    rules,
+			entry:    Entry{Version: Version, ReqHeader: map[string]string{"Authorization": ""}},
+			expected: Entry{Version: Version, ReqHeader: map[string]string{"Authorization": ""}},
+		},
+		// Claims are matched exactly and replaced by strings.
+		{
+			rules: rules,
+			entry: Entry{
+				Version:   Version,
+				ReqClaims: map[string]interface{}{"accessKey": "minio", "AccessKey": 1, "exp": 10},
+			},
+			expected: Entry{
+				Version:   Version,
+				ReqClaims: map[string]interface{}{"accessKey": RedactedValue, "AccessKey": 1, "exp": 10},
+				Redacted:  []string{"requestClaims.accessKey"},
+			},
+		},
+		// Nothing matched.
+		{
+			rules:    rules,
+			entry:    Entry{Version: Version, ReqHeader: map[string]string{"Host": "minio"}},
+			expected: Entry{Version: Version, ReqHeader: map[string]string{"Host": "minio"}},
+		},
+	}
+
+	for i, testCase := range testCases {
+		entry := testCase.entry
+		testCase.rules.Apply(&entry)
+		sort.Strings(entry.Redacted)
+		if !reflect.DeepEqual(entry, testCase.expected) {
+			t.Errorf("Test %d: expected %+v, got %+v", i+1, testCase.expected, entry)
+		}
+	}
+}
+
+func TestRedactionApplyClaimsCopied(t *testing.T) {
+	claims := map[string]interface{}{"accessKey": "minio"}
+	entry := Entry{ReqClaims: claims}
+	Redaction{Claims: []string{"accessKey"}}.Apply(&entry)
+	if claims["accessKey"] != "minio" {
+		t.Errorf("expected the request claims not to be modified, got %v", claims["accessKey"])
+	}
+}
+
+func TestRedactionApplyMapsCopied(t *testing.T) {
+	query := map[string]string{"X-Amz-Signature": "sig"}
+	header := map[string]string{"Authorization": "secret", "Host": "minio"}
+	redacted := []string{"requestClaims.accessKey"}
+	entry := Entry{ReqQuery: query, ReqHeader: header, RespHeader: header, Redacted: redacted[:1:1]}
+	shared := entry
+	Redaction{QueryParams: []string{"X-Amz-Signature"}, Headers: []string{"Authorization"}}.Apply(&entry)
+	if query["X-Amz-Signature"] != "sig" || header["Authorization"] != "secret" {
+		t.Errorf("expected the maps of the entry not to be modified, got %v %v", query, header)
+	}
+	if entry.ReqHeader["Authorization"] != RedactedValue || entry.RespHeader["Authorization"] != RedactedValue {
+		t.Errorf("expected the headers to be redacted, got %v %v", entry.ReqHeader, entry.RespHeader)
+	}
+	if len(shared.Redacted) != 1 || len(entry.Redacted) != 4 {
+		t.Errorf("unexpected redacted fields %v %v", shared.Redacted, entry.Redacted)
+	}
+}
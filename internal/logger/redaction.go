@@ -0,0 +1,137 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger/message/audit"
+	"github.com/minio/pkg/env"
+)
+
+// Audit redaction constants
+const (
+	RedactQueryParams = "query_params"
+	RedactHeaders     = "headers"
+	RedactClaims      = "claims"
+
+	EnvAuditRedactionEnable      = "MINIO_AUDIT_REDACTION_ENABLE"
+	EnvAuditRedactionQueryParams = "MINIO_AUDIT_REDACTION_QUERY_PARAMS"
+	EnvAuditRedactionHeaders     = "MINIO_AUDIT_REDACTION_HEADERS"
+	EnvAuditRedactionClaims      = "MINIO_AUDIT_REDACTION_CLAIMS"
+)
+
+var (
+	// DefaultAuditRedactionKVS - default KV config for audit redaction,
+	// covers the credentials carried by presigned and signed requests.
+	DefaultAuditRedactionKVS = config.KVS{
+		config.KV{
+			Key:   config.Enable,
+			Value: config.EnableOff,
+		},
+		config.KV{
+			Key:   RedactQueryParams,
+			Value: "X-Amz-Signature,X-Amz-Credential,X-Amz-Security-Token,Signature,AWSAccessKeyId",
+		},
+		config.KV{
+			Key:   RedactHeaders,
+			Value: "Authorization,X-Amz-Security-Token,Cookie,Set-Cookie",
+		},
+		config.KV{
+			Key:   RedactClaims,
+			Value: "",
+		},
+	}
+
+	// HelpAuditRedaction - help for audit redaction config
+	HelpAuditRedaction = config.HelpKVS{
+		config.HelpKV{
+			Key:         RedactQueryParams,
+			Description: "comma separated list of query parameters to redact, these are matched case-insensitively",
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         RedactHeaders,
+			Description: "comma separated list of request and response headers to redact, these are matched case-insensitively",
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         RedactClaims,
+			Description: `comma separated list of request claims to redact e.g. "accessKey,parent"`,
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         config.Comment,
+			Description: config.DefaultComment,
+			Optional:    true,
+			Type:        "sentence",
+		},
+	}
+)
+
+func splitCSV(v string) (l []string) {
+	for _, s := range strings.Split(v, config.ValueSeparator) {
+		if s = strings.TrimSpace(s); s != "" {
+			l = append(l, s)
+		}
+	}
+	return l
+}
+
+// LookupAuditRedactionConfig - lookup audit redaction config and override with valid environment settings if any.
+func LookupAuditRedactionConfig(kvs config.KVS) (r audit.Redaction, err error) {
+	if err = config.CheckValidKeys(config.AuditRedactionSubSys, kvs, DefaultAuditRedactionKVS); err != nil {
+		return r, err
+	}
+
+	enabled, err := config.ParseBool(env.Get(EnvAuditRedactionEnable, kvs.Get(config.Enable)))
+	if err != nil {
+		// Parsing failures happen due to empty KVS, ignore it.
+		if kvs.Empty() {
+			return r, nil
+		}
+		return r, err
+	}
+	if !enabled {
+		return r, nil
+	}
+
+	r.QueryParams = splitCSV(env.Get(EnvAuditRedactionQueryParams, kvs.Get(RedactQueryParams)))
+	r.Headers = splitCSV(env.Get(EnvAuditRedactionHeaders, kvs.Get(RedactHeaders)))
+	r.Claims = splitCSV(env.Get(EnvAuditRedactionClaims, kvs.Get(RedactClaims)))
+	return r, nil
+}
+
+var auditRedaction atomic.Value // audit.Redaction
+
+// SetAuditRedaction sets the redaction rules applied to all
+// audit entries before they are sent to audit targets.
+func SetAuditRedaction(r audit.Redaction) {
+	auditRedaction.Store(r)
+}
+
+func redactAuditEntry(entry *audit.Entry) {
+	if r, ok := auditRedaction.Load().(audit.Redaction); ok {
+		r.Apply(entry)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger/message/audit"
+)
+
+func TestLookupAuditRedactionConfig(t *testing.T) {
+	testCases := []struct {
+		kvs      config.KVS
+		env      string
+		expected audit.Redaction
+		success  bool
+	}{
+		// Empty config, nothing is redacted.
+		{kvs: config.KVS{}, success: true},
+		// Disabled by default.
+		{kvs: DefaultAuditRedactionKVS, success: true},
+		{
+			kvs: config.KVS{
+				config.KV{Key: config.Enable, Value: config.EnableOn},
+				config.KV{Key: RedactQueryParams, Value: "X-Amz-Signature, prefix,"},
+				config.KV{Key: RedactHeaders, Value: "Authorization"},
+				config.KV{Key: RedactClaims, Value: ""},
+			},
+			expected: audit.Redaction{
+				QueryParams: []string{"X-Amz-Signature", "prefix"},
+				Headers:     []string{"Authorization"},
+			},
+			success: true,
+		},
+		// Enabled from the environment.
+		{
+			kvs: config.KVS{
+				config.KV{Key: config.Enable, Value: config.EnableOff},
+				config.KV{Key: RedactClaims, Value: "accessKey"},
+			},
+			env:      config.EnableOn,
+			expected: audit.Redaction{Claims: []string{"accessKey"}},
+			success:  true,
+		},
+		// Unknown keys.
+		{
+			kvs: config.KVS{
+				config.KV{Key: config.Enable, Value: config.EnableOn},
+				config.KV{Key: "body", Value: "password"},
+			},
+		},
+		// Invalid state.
+		{
+			kvs: config.KVS{
+				config.KV{Key: config.Enable, Value: "maybe"},
+			},
+		},
+		// Invalid state from the environment.
+		{
+			kvs: DefaultAuditRedactionKVS,
+			env: "maybe",
+		},
+	}
+
+	for i, testCase := range testCases {
+		if testCase.env != "" {
+			os.Setenv(EnvAuditRedactionEnable, testCase.env)
+		}
+		r, err := LookupAuditRedactionConfig(testCase.kvs)
+		os.Unsetenv(EnvAuditRedactionEnable)
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: expected success but failed instead %s", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: expected failure but success instead", i+1)
+		}
+		if err == nil && !reflect.DeepEqual(r, testCase.expected) {
+			t.Errorf("Test %d: expected %+v, got %+v", i+1, testCase.expected, r)
+		}
+	}
+}
@@ -36,16 +36,17 @@ type KeyVal struct {
 
 // ReqInfo stores the request info.
 type ReqInfo struct {
-	RemoteHost   string   // Client Host/IP
-	Host         string   // Node Host/IP
-	UserAgent    string   // User Agent
-	DeploymentID string   // x-minio-deployment-id
-	RequestID    string   // x-amz-request-id
-	API          string   // API name - GetObject PutObject NewMultipartUpload etc.
-	BucketName   string   // Bucket name
-	ObjectName   string   // Object name
-	AccessKey    string   // Access Key
-	tags         []KeyVal // Any additional info not accommodated by above fields
+	RemoteHost    string   // Client Host/IP
+	Host          string   // Node Host/IP
+	UserAgent     string   // User Agent
+	DeploymentID  string   // x-minio-deployment-id
+	RequestID     string   // x-amz-request-id
+	CorrelationID string   // client supplied correlation ID
+	API           string   // API name - GetObject PutObject NewMultipartUpload etc.
+	BucketName    string   // Bucket name
+	ObjectName    string   // Object name
+	AccessKey     string   // Access Key
+	tags          []KeyVal // Any additional info not accommodated by above fields
 	sync.RWMutex
 }
 
@@ -0,0 +1,394 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	segmentPrefix = "audit-"
+	segmentExt    = ".ndjson"
+	gzipExt       = ".gz"
+
+	// Interval at which closed segments that failed
+	// to upload earlier are retried.
+	uploadRetryInterval = time.Minute
+)
+
+// MinRotateInterval is the shortest age after which the active
+// segment can be rotated.
+const MinRotateInterval = time.Minute
+
+// UploadFn uploads a closed segment as object, the reader is
+// consumed entirely on success.
+type UploadFn func(ctx context.Context, object string, r io.Reader, size int64) error
+
+// Config file logger target
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Name    string `json:"name"`
+	Dir     string `json:"dir"`
+	// MaxSize is the size in bytes after which the active
+	// segment is rotated, 0 disables size based rotation.
+	MaxSize uint64 `json:"maxSize"`
+	// RotateInterval is the age after which the active segment is
+	// rotated, at least MinRotateInterval, 0 disables time based
+	// rotation.
+	RotateInterval time.Duration `json:"rotateInterval"`
+	Compress       bool          `json:"compress"`
+
+	// Audit bucket closed segments are delivered to, the bucket
+	// is expected to have object locking enabled when a retention
+	// mode is set.
+	UploadEndpoint      string `json:"uploadEndpoint"`
+	UploadAccessKey     string `json:"uploadAccessKey"`
+	UploadSecretKey     string `json:"uploadSecretKey"`
+	UploadBucket        string `json:"uploadBucket"`
+	UploadRetentionMode string `json:"uploadRetentionMode"`
+	UploadRetentionDays int    `json:"uploadRetentionDays"`
+
+	// Upload delivers closed segments to the audit bucket, segments
+	// are removed locally once uploaded. Without it segments are
+	// kept on disk.
+	Upload UploadFn `json:"-"`
+	// UploadPrefix is prepended to uploaded object names,
+	// typically used to separate segments of each node.
+	UploadPrefix string `json:"-"`
+
+	// Custom logger
+	LogOnce func(ctx context.Context, err error, id interface{}, errKind ...interface{}) `json:"-"`
+}
+
+// Target implements logger.Target and appends the json format
+// of a log entry as a single line to local segment files.
+// An internal buffer of logs is maintained but when the
+// buffer is full, new logs are just ignored and an error
+// is returned to the caller.
+type Target struct {
+	// Channel of log entries
+	logCh chan interface{}
+	// Channel of closed segments to post-process
+	closedCh chan string
+
+	config Config
+
+	// Only accessed by the writer goroutine.
+	active     *os.File
+	activeName string
+	writer     *bufio.Writer
+	size       uint64
+	openedAt   time.Time
+}
+
+// Endpoint returns the backend endpoint
+func (h *Target) Endpoint() string {
+	return h.config.Dir
+}
+
+func (h *Target) String() string {
+	return h.config.Name
+}
+
+// Init validate and initialize the file target
+func (h *Target) Init() error {
+	if h.config.Dir == "" {
+		return errors.New("audit file target directory cannot be empty")
+	}
+	if h.config.RotateInterval < 0 || (h.config.RotateInterval > 0 && h.config.RotateInterval < MinRotateInterval) {
+		return fmt.Errorf("audit file target rotate interval must be at least %s", MinRotateInterval)
+	}
+	if err := os.MkdirAll(h.config.Dir, 0o700); err != nil {
+		return err
+	}
+	if err := h.openSegment(); err != nil {
+		return err
+	}
+
+	go h.startFileLogger()
+	go h.startSegmentProcessor()
+	return nil
+}
+
+// openSegment creates a new active segment, named after its creation
+// time so that segments sort chronologically.
+func (h *Target) openSegment() error {
+	now := time.Now().UTC()
+	name := segmentPrefix + now.Format("20060102T150405.000000000Z") + segmentExt
+	f, err := os.OpenFile(filepath.Join(h.config.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	h.active = f
+	h.activeName = name
+	h.writer = bufio.NewWriterSize(f, 64<<10)
+	h.size = 0
+	h.openedAt = now
+	return nil
+}
+
+// rotate closes the active segment, if non-empty, and opens a new one.
+func (h *Target) rotate() error {
+	if h.size == 0 {
+		h.openedAt = time.Now().UTC()
+		return nil
+	}
+	closed := h.activeName
+	if err := h.closeSegment(); err != nil {
+		return err
+	}
+	select {
+	case h.closedCh <- closed:
+	default:
+		// Picked up by the periodic scan.
+	}
+	return h.openSegment()
+}
+
+func (h *Target) closeSegment() error {
+	if err := h.writer.Flush(); err != nil {
+		h.active.Close()
+		return err
+	}
+	if err := h.active.Sync(); err != nil {
+		h.active.Close()
+		return err
+	}
+	return h.active.Close()
+}
+
+func (h *Target) logOnce(err error) {
+	if err != nil && h.config.LogOnce != nil {
+		h.config.LogOnce(context.Background(), err, h.config.Dir)
+	}
+}
+
+func (h *Target) startFileLogger() {
+	var tick <-chan time.Time
+	if h.config.RotateInterval > 0 {
+		ticker := time.NewTicker(h.config.RotateInterval / 10)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case entry := <-h.logCh:
+			logJSON, err := json.Marshal(&entry)
+			if err != nil {
+				continue
+			}
+			logJSON = append(logJSON, '\n')
+			if _, err = h.writer.Write(logJSON); err != nil {
+				h.logOnce(fmt.Errorf("unable to write audit log to %s: %w", h.config.Dir, err))
+				continue
+			}
+			h.size += uint64(len(logJSON))
+			if len(h.logCh) == 0 {
+				// Flush once caught up, so that a crash
+				// loses as few entries as possible.
+				h.logOnce(h.writer.Flush())
+			}
+			if h.config.MaxSize > 0 && h.size >= h.config.MaxSize {
+				h.logOnce(h.rotate())
+			}
+		case <-tick:
+			if time.Since(h.openedAt) >= h.config.RotateInterval {
+				h.logOnce(h.rotate())
+			}
+		}
+	}
+}
+
+// closedSegments lists all segments on disk except the active one.
+func (h *Target) closedSegments(active string) ([]string, error) {
+	entries, err := ioutil.ReadDir(h.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Mode().IsRegular() || name == active || !strings.HasPrefix(name, segmentPrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, segmentExt) || strings.HasSuffix(name, segmentExt+gzipExt) {
+			segments = append(segments, name)
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+func (h *Target) startSegmentProcessor() {
+	// Segments left behind by an earlier run are processed first,
+	// the active segment of this run is always the newest one.
+	h.processSegments()
+
+	ticker := time.NewTicker(uploadRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case name := <-h.closedCh:
+			h.logOnce(h.processSegment(name))
+		case <-ticker.C:
+			h.processSegments()
+		}
+	}
+}
+
+func (h *Target) processSegments() {
+	segments, err := h.closedSegments(h.newestSegment())
+	if err != nil {
+		h.logOnce(err)
+		return
+	}
+	for _, name := range segments {
+		if err = h.processSegment(name); err != nil {
+			h.logOnce(err)
+			return
+		}
+	}
+}
+
+// newestSegment returns the name of the newest uncompressed segment,
+// which is the one currently being written to.
+func (h *Target) newestSegment() string {
+	entries, err := ioutil.ReadDir(h.config.Dir)
+	if err != nil {
+		return ""
+	}
+	var newest string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, segmentPrefix) && strings.HasSuffix(name, segmentExt) && name > newest {
+			newest = name
+		}
+	}
+	return newest
+}
+
+// processSegment compresses and uploads a closed segment as configured.
+func (h *Target) processSegment(name string) error {
+	if _, err := os.Stat(filepath.Join(h.config.Dir, name)); os.IsNotExist(err) {
+		// Already handled by an earlier scan.
+		return nil
+	}
+	if h.config.Compress && !strings.HasSuffix(name, gzipExt) {
+		compressed, err := h.compressSegment(name)
+		if err != nil {
+			return err
+		}
+		name = compressed
+	}
+	if h.config.Upload == nil {
+		return nil
+	}
+	return h.uploadSegment(name)
+}
+
+func (h *Target) compressSegment(name string) (string, error) {
+	src := filepath.Join(h.config.Dir, name)
+	dst := src + gzipExt
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("unable to compress audit segment %s: %w", src, err)
+	}
+	return name + gzipExt, os.Remove(src)
+}
+
+func (h *Target) uploadSegment(name string) error {
+	src := filepath.Join(h.config.Dir, name)
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err = h.config.Upload(ctx, path.Join(h.config.UploadPrefix, name), f, fi.Size()); err != nil {
+		return fmt.Errorf("unable to upload audit segment %s: %w", src, err)
+	}
+	return os.Remove(src)
+}
+
+// New initializes a new logger target which
+// writes audit logs to local segment files
+func New(config Config) *Target {
+	h := &Target{
+		logCh:    make(chan interface{}, 10000),
+		closedCh: make(chan string, 100),
+		config:   config,
+	}
+
+	return h
+}
+
+// Send log message 'e' to file target.
+func (h *Target) Send(entry interface{}, errKind string) error {
+	select {
+	case h.logCh <- entry:
+	default:
+		// log channel is full, do not wait and return
+		// an error immediately to the caller
+		return errors.New("log buffer full")
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type uploaded struct {
+	object string
+	lines  []string
+}
+
+func TestFileTargetRotateAndUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	uploads := make(chan uploaded, 10)
+	target := New(Config{
+		Enabled:      true,
+		Dir:          dir,
+		MaxSize:      64,
+		Compress:     true,
+		UploadPrefix: "node1",
+		Upload: func(ctx context.Context, object string, r io.Reader, size int64) error {
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return err
+			}
+			var u uploaded
+			u.object = object
+			s := bufio.NewScanner(zr)
+			for s.Scan() {
+				u.lines = append(u.lines, s.Text())
+			}
+			uploads <- u
+			return s.Err()
+		},
+	})
+	if err = target.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := map[string]string{"requestID": strings.Repeat("a", 64)}
+	if err = target.Send(entry, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case u := <-uploads:
+		if !strings.HasPrefix(u.object, "node1/"+segmentPrefix) || !strings.HasSuffix(u.object, segmentExt+gzipExt) {
+			t.Errorf("unexpected object name %s", u.object)
+		}
+		if len(u.lines) != 1 {
+			t.Fatalf("expected 1 line, got %d", len(u.lines))
+		}
+		var got map[string]string
+		if err = json.Unmarshal([]byte(u.lines[0]), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["requestID"] != entry["requestID"] {
+			t.Errorf("unexpected entry %v", got)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for segment upload")
+	}
+
+	// Uploaded segments are removed, only the new active one remains.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		matches, _ := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"))
+		if len(matches) == 1 && strings.HasSuffix(matches[0], segmentExt) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected segments left behind %v", matches)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileTargetKeepsSegmentsWithoutUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Left over from an earlier run.
+	stale := filepath.Join(dir, segmentPrefix+"20210101T000000.000000000Z"+segmentExt)
+	if err = ioutil.WriteFile(stale, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	target := New(Config{Enabled: true, Dir: dir, Compress: true})
+	if err = target.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err = os.Stat(stale + gzipExt); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stale segment to be compressed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err = os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected uncompressed segment to be removed, got %v", err)
+	}
+}
+
+func TestFileTargetRotateInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, interval := range []time.Duration{-time.Hour, time.Nanosecond, 9, MinRotateInterval - 1} {
+		target := New(Config{Enabled: true, Dir: dir, RotateInterval: interval})
+		if err := target.Init(); err == nil {
+			t.Fatalf("expected rotate interval %s to fail", interval)
+		}
+	}
+}
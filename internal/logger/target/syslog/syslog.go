@@ -0,0 +1,311 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/internal/logger/message/log"
+)
+
+// Supported transport protocols
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+	ProtocolTLS = "tls"
+)
+
+// Timeout for establishing a connection and writing a message.
+const syslogTimeout = 5 * time.Second
+
+// sdID is the RFC5424 structured data element carrying entry fields,
+// 32473 is the private enterprise number reserved for documentation.
+const sdID = "minio@32473"
+
+// Facilities maps the supported facility names to their code.
+var Facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"authpriv": 10,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// RFC5424 severities
+const (
+	severityCritical = 2
+	severityError    = 3
+	severityNotice   = 5
+	severityInfo     = 6
+)
+
+// Config syslog logger target
+type Config struct {
+	Enabled       bool   `json:"enabled"`
+	Name          string `json:"name"`
+	Address       string `json:"address"`
+	Protocol      string `json:"protocol"`
+	Facility      int    `json:"facility"`
+	AppName       string `json:"appName"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify"`
+
+	// Hostname reported in messages, defaults to os.Hostname()
+	Hostname string         `json:"-"`
+	RootCAs  *x509.CertPool `json:"-"`
+
+	// Custom logger
+	LogOnce func(ctx context.Context, err error, id interface{}, errKind ...interface{}) `json:"-"`
+}
+
+// Target implements logger.Target and sends log entries as
+// RFC5424 messages to a syslog server over UDP, TCP or TLS.
+// An internal buffer of logs is maintained but when the
+// buffer is full, new logs are just ignored and an error
+// is returned to the caller.
+type Target struct {
+	// Channel of log entries
+	logCh chan interface{}
+
+	config Config
+	conn   net.Conn
+	procID string
+}
+
+// Endpoint returns the backend endpoint
+func (h *Target) Endpoint() string {
+	return h.config.Protocol + "://" + h.config.Address
+}
+
+func (h *Target) String() string {
+	return h.config.Name
+}
+
+func (h *Target) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+	switch h.config.Protocol {
+	case ProtocolTLS:
+		return tls.DialWithDialer(dialer, "tcp", h.config.Address, &tls.Config{
+			RootCAs:            h.config.RootCAs,
+			InsecureSkipVerify: h.config.TLSSkipVerify,
+		})
+	case ProtocolUDP, ProtocolTCP:
+		return dialer.Dial(h.config.Protocol, h.config.Address)
+	}
+	return nil, fmt.Errorf("unsupported syslog protocol %q", h.config.Protocol)
+}
+
+// Init validate and initialize the syslog target
+func (h *Target) Init() error {
+	if h.config.Hostname == "" {
+		h.config.Hostname, _ = os.Hostname()
+	}
+	if h.config.AppName == "" {
+		h.config.AppName = "minio"
+	}
+	conn, err := h.dial()
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+	go h.startSyslogLogger()
+	return nil
+}
+
+func (h *Target) startSyslogLogger() {
+	for entry := range h.logCh {
+		msg := h.format(entry, time.Now())
+		if msg == nil {
+			continue
+		}
+		if err := h.write(msg); err != nil {
+			h.config.LogOnce(context.Background(), fmt.Errorf("unable to send log to syslog %s: %w", h.Endpoint(), err), h.Endpoint())
+		}
+	}
+}
+
+// write sends msg, reconnecting once if the connection was lost.
+func (h *Target) write(msg []byte) (err error) {
+	for i := 0; i < 2; i++ {
+		if h.conn == nil {
+			if h.conn, err = h.dial(); err != nil {
+				return err
+			}
+		}
+		h.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+		if h.config.Protocol == ProtocolUDP {
+			_, err = h.conn.Write(msg)
+		} else {
+			// RFC6587 octet counting framing
+			_, err = h.conn.Write(append([]byte(strconv.Itoa(len(msg))+" "), msg...))
+		}
+		if err == nil {
+			return nil
+		}
+		h.conn.Close()
+		h.conn = nil
+	}
+	return err
+}
+
+func severity(level string) int {
+	switch level {
+	case "FATAL":
+		return severityCritical
+	case "ERROR":
+		return severityError
+	case "INFO":
+		return severityInfo
+	}
+	return severityNotice
+}
+
+// header returns an RFC5424 header field, NILVALUE if empty.
+func header(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, v)
+	if v == "" {
+		return "-"
+	}
+	if len(v) > max {
+		v = v[:max]
+	}
+	return v
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// format encodes entry as an RFC5424 message, log entries carry their
+// fields as structured data, anything else is sent as JSON.
+func (h *Target) format(entry interface{}, now time.Time) []byte {
+	sev := severityNotice
+	msgID := ""
+	var params [][2]string
+	var text string
+
+	switch e := entry.(type) {
+	case log.Entry:
+		sev = severity(e.Level)
+		msgID = e.LogKind
+		params = append(params,
+			[2]string{"level", e.Level},
+			[2]string{"deploymentid", e.DeploymentID},
+			[2]string{"requestID", e.RequestID},
+			[2]string{"remotehost", e.RemoteHost},
+			[2]string{"host", e.Host},
+			[2]string{"userAgent", e.UserAgent},
+		)
+		if e.API != nil {
+			params = append(params, [2]string{"api", e.API.Name})
+			if e.API.Args != nil {
+				params = append(params,
+					[2]string{"bucket", e.API.Args.Bucket},
+					[2]string{"object", e.API.Args.Object},
+				)
+			}
+		}
+		text = e.Message
+		if e.Trace != nil {
+			if text == "" {
+				text = e.Trace.Message
+			}
+			if len(e.Trace.Source) > 0 {
+				params = append(params, [2]string{"source", e.Trace.Source[0]})
+			}
+		}
+	default:
+		buf, err := json.Marshal(entry)
+		if err != nil {
+			return nil
+		}
+		text = string(buf)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<%d>1 %s %s %s %s %s ",
+		h.config.Facility*8+sev,
+		now.UTC().Format(time.RFC3339Nano),
+		header(h.config.Hostname, 255),
+		header(h.config.AppName, 48),
+		header(h.procID, 128),
+		header(msgID, 32))
+
+	var sd strings.Builder
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		fmt.Fprintf(&sd, ` %s="%s"`, p[0], sdEscaper.Replace(p[1]))
+	}
+	if sd.Len() == 0 {
+		sb.WriteString("-")
+	} else {
+		sb.WriteString("[" + sdID + sd.String() + "]")
+	}
+	if text != "" {
+		sb.WriteString(" " + text)
+	}
+	return []byte(sb.String())
+}
+
+// New initializes a new logger target which
+// sends logs to a syslog server
+func New(config Config) *Target {
+	h := &Target{
+		logCh:  make(chan interface{}, 10000),
+		config: config,
+		procID: strconv.Itoa(os.Getpid()),
+	}
+
+	return h
+}
+
+// Send log message 'e' to syslog target.
+func (h *Target) Send(entry interface{}, errKind string) error {
+	select {
+	case h.logCh <- entry:
+	default:
+		// log channel is full, do not wait and return
+		// an error immediately to the caller
+		return errors.New("log buffer full")
+	}
+
+	return nil
+}
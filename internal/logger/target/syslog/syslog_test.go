@@ -0,0 +1,116 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/logger/message/log"
+)
+
+func TestFormat(t *testing.T) {
+	h := New(Config{Facility: Facilities["local0"], AppName: "minio", Hostname: "node1"})
+	h.procID = "42"
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		entry    interface{}
+		expected string
+	}{
+		{
+			entry: log.Entry{
+				Level:     "ERROR",
+				LogKind:   "MINIO",
+				RequestID: "16A8",
+				API:       &log.API{Name: "PutObject", Args: &log.Args{Bucket: "bucket", Object: `a"b]`}},
+				Trace:     &log.Trace{Message: "disk not found", Source: []string{"cmd/xl-storage.go:100"}},
+			},
+			expected: `<131>1 2021-10-01T12:00:00Z node1 minio 42 MINIO [minio@32473 level="ERROR" requestID="16A8" api="PutObject" bucket="bucket" object="a\"b\]" source="cmd/xl-storage.go:100"] disk not found`,
+		},
+		{
+			entry:    log.Entry{Level: "INFO", Message: "hello"},
+			expected: `<134>1 2021-10-01T12:00:00Z node1 minio 42 - [minio@32473 level="INFO"] hello`,
+		},
+		{
+			entry:    map[string]string{"k": "v"},
+			expected: `<133>1 2021-10-01T12:00:00Z node1 minio 42 - - {"k":"v"}`,
+		},
+	}
+	for i, testCase := range testCases {
+		if got := string(h.format(testCase.entry, now)); got != testCase.expected {
+			t.Errorf("Test %d: expected\n%s\ngot\n%s", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestTCPFraming(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		n, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return
+		}
+		buf := make([]byte, size)
+		if _, err = r.Read(buf); err != nil {
+			return
+		}
+		received <- string(buf)
+	}()
+
+	h := New(Config{
+		Address:  l.Addr().String(),
+		Protocol: ProtocolTCP,
+		LogOnce:  func(ctx context.Context, err error, id interface{}, errKind ...interface{}) { t.Log(err) },
+	})
+	if err = h.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err = h.Send(log.Entry{Level: "ERROR", Message: "boom"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-received:
+		if !strings.HasPrefix(msg, "<3>1 ") || !strings.HasSuffix(msg, " boom") {
+			t.Errorf("unexpected message %q", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for syslog message")
+	}
+}
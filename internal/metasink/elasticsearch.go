@@ -0,0 +1,241 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metasink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	xnet "github.com/minio/pkg/net"
+)
+
+// elasticsearchMapping is the mapping of the index, tags and user
+// metadata are mapped as keywords as they appear.
+var elasticsearchMapping = map[string]interface{}{
+	"_meta": map[string]interface{}{
+		"minio_schema_version": SchemaVersion,
+	},
+	"dynamic_templates": []interface{}{
+		map[string]interface{}{
+			"tags": map[string]interface{}{
+				"path_match": "tags.*",
+				"mapping":    map[string]interface{}{"type": "keyword"},
+			},
+		},
+		map[string]interface{}{
+			"user_metadata": map[string]interface{}{
+				"path_match": "userMetadata.*",
+				"mapping":    map[string]interface{}{"type": "keyword"},
+			},
+		},
+	},
+	"properties": map[string]interface{}{
+		"bucket":       map[string]interface{}{"type": "keyword"},
+		"key":          map[string]interface{}{"type": "keyword"},
+		"versionId":    map[string]interface{}{"type": "keyword"},
+		"size":         map[string]interface{}{"type": "long"},
+		"etag":         map[string]interface{}{"type": "keyword"},
+		"contentType":  map[string]interface{}{"type": "keyword"},
+		"storageClass": map[string]interface{}{"type": "keyword"},
+		"lastModified": map[string]interface{}{"type": "date"},
+		"userMetadata": map[string]interface{}{"type": "object"},
+		"tags":         map[string]interface{}{"type": "object"},
+		"event":        map[string]interface{}{"type": "keyword"},
+		"eventTime":    map[string]interface{}{"type": "date"},
+	},
+}
+
+type elasticsearchSink struct {
+	url                *xnet.URL
+	index              string
+	username, password string
+	client             *http.Client
+}
+
+// NewElasticsearch returns a sink writing to the index of an
+// Elasticsearch or OpenSearch server with its REST API.
+func NewElasticsearch(u *xnet.URL, index, username, password string, transport http.RoundTripper) Sink {
+	return &elasticsearchSink{
+		url:      u,
+		index:    index,
+		username: username,
+		password: password,
+		client:   &http.Client{Transport: transport},
+	}
+}
+
+func (s *elasticsearchSink) do(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	u := *s.url
+	u.Path += path
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return s.client.Do(req)
+}
+
+// responseError returns the error of a failed response.
+func responseError(resp *http.Response) error {
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	return fmt.Errorf("%s %s returned '%s': %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, bytes.TrimSpace(data))
+}
+
+// Init creates the index with its mapping, or adds the fields missing
+// to the mapping of an existing index.
+func (s *elasticsearchSink) Init(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "/"+s.index, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	var body []byte
+	path := "/" + s.index
+	switch resp.StatusCode {
+	case http.StatusOK:
+		path += "/_mapping"
+		body, err = json.Marshal(elasticsearchMapping)
+	case http.StatusNotFound:
+		body, err = json.Marshal(map[string]interface{}{"mappings": elasticsearchMapping})
+	default:
+		return fmt.Errorf("HEAD /%s returned '%s'", s.index, resp.Status)
+	}
+	if err != nil {
+		return err
+	}
+	resp, err = s.do(ctx, http.MethodPut, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// Write indexes the records with the bulk API, deleting the documents
+// of deleted records. Documents are versioned externally by the last
+// modified time of the records, older records being rejected. Records
+// of the same version, like a change of the tags of an object, replace
+// the document.
+func (s *elasticsearchSink) Write(ctx context.Context, records []Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		action := "index"
+		if r.Deleted {
+			action = "delete"
+		}
+		meta := map[string]map[string]interface{}{action: {
+			"_index":       s.index,
+			"_id":          r.ID(),
+			"version":      r.LastModified.UnixNano(),
+			"version_type": "external_gte",
+		}}
+		if err := enc.Encode(meta); err != nil {
+			return err
+		}
+		if !r.Deleted {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", buf.Bytes())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	var result elasticsearchBulkResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	for _, item := range result.Items {
+		for action, res := range item {
+			// Deleting an object not indexed yet is not an error, nor is
+			// a conflict with a newer version.
+			if res.Status < 300 || res.Status == http.StatusConflict ||
+				action == "delete" && res.Status == http.StatusNotFound {
+				continue
+			}
+			return fmt.Errorf("bulk %s failed with status %d: %s", action, res.Status, res.Error)
+		}
+	}
+	return nil
+}
+
+// Sweep deletes the documents written before the given time.
+func (s *elasticsearchSink) Sweep(ctx context.Context, before time.Time) error {
+	query := map[string]interface{}{
+		"conflicts": "proceed",
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"eventTime": map[string]interface{}{
+					"lt":     before.UnixNano() / int64(time.Millisecond),
+					"format": "epoch_millis",
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPost, "/"+s.index+"/_delete_by_query", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (s *elasticsearchSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metasink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	xnet "github.com/minio/pkg/net"
+)
+
+func TestElasticsearchSink(t *testing.T) {
+	var (
+		exists   bool
+		puts     []string
+		actions  []string
+		versions []float64
+		swept    map[string]interface{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/objects":
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPut:
+			puts = append(puts, r.URL.Path)
+			exists = true
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			var items []map[string]map[string]interface{}
+			sc := bufio.NewScanner(r.Body)
+			for sc.Scan() {
+				var line map[string]interface{}
+				if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+					t.Error(err)
+				}
+				for action, meta := range line {
+					if action == "index" || action == "delete" {
+						meta := meta.(map[string]interface{})
+						if meta["version_type"] != "external_gte" {
+							t.Errorf("unexpected version type %v", meta["version_type"])
+						}
+						actions = append(actions, action)
+						versions = append(versions, meta["version"].(float64))
+						// Report an older version and an unknown object,
+						// neither being an error.
+						status := 200
+						switch len(actions) {
+						case 2:
+							status = 409
+						case 3:
+							status = 404
+						}
+						items = append(items, map[string]map[string]interface{}{action: {"status": status}})
+					}
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": true, "items": items})
+		case r.Method == http.MethodPost && r.URL.Path == "/objects/_delete_by_query":
+			if err := json.NewDecoder(r.Body).Decode(&swept); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"deleted":1}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := xnet.ParseHTTPURL(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewElasticsearch(u, "objects", "", "", http.DefaultTransport)
+	defer s.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err = s.Init(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(puts) != 2 || puts[0] != "/objects" || puts[1] != "/objects/_mapping" {
+		t.Fatalf("expected the index to be created then its mapping updated, got %v", puts)
+	}
+
+	modTime := time.Unix(1635847445, 0)
+	records := []Record{
+		{Bucket: "photos", Key: "a.jpg", Size: 10, ETag: "abc", Tags: map[string]string{"env": "prod"}, LastModified: modTime},
+		{Bucket: "photos", Key: "c.jpg", Size: 10, ETag: "def", LastModified: modTime},
+		{Bucket: "photos", Key: "b.jpg", Deleted: true, LastModified: modTime.Add(time.Second)},
+	}
+	if err = s.Write(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 3 || actions[0] != "index" || actions[1] != "index" || actions[2] != "delete" {
+		t.Fatalf("unexpected bulk actions %v", actions)
+	}
+	if versions[0] != float64(modTime.UnixNano()) || versions[2] != float64(modTime.Add(time.Second).UnixNano()) {
+		t.Fatalf("expected the documents to be versioned by their last modified time, got %v", versions)
+	}
+	if records[0].ID() == records[2].ID() {
+		t.Fatal("expected distinct object ids")
+	}
+
+	if err = s.Sweep(ctx, modTime); err != nil {
+		t.Fatal(err)
+	}
+	lt := swept["query"].(map[string]interface{})["range"].(map[string]interface{})["eventTime"].(map[string]interface{})["lt"]
+	if swept["conflicts"] != "proceed" || lt != float64(modTime.UnixNano()/int64(time.Millisecond)) {
+		t.Fatalf("unexpected sweep %v", swept)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package metasink writes the metadata of objects to external indexes.
+package metasink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// SchemaVersion is the version of the schema of the indexes, recorded
+// in the indexes created or upgraded.
+const SchemaVersion = 1
+
+// Record - the metadata of the latest version of an object, replacing
+// the previous record of the object. Deleted records remove the object
+// from the index, it has no current version left, LastModified being
+// the time of the removal.
+//
+// Records are ordered by LastModified, then by VersionID and EventTime,
+// records older than the indexed one being ignored so that the changes
+// written by several nodes converge whatever their order.
+type Record struct {
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	Deleted      bool              `json:"-"`
+	VersionID    string            `json:"versionId,omitempty"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"contentType,omitempty"`
+	StorageClass string            `json:"storageClass,omitempty"`
+	LastModified time.Time         `json:"lastModified"`
+	UserMetadata map[string]string `json:"userMetadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Event        string            `json:"event"`
+	EventTime    time.Time         `json:"eventTime"`
+}
+
+// ID returns the identifier of the object of the record in the index.
+func (r Record) ID() string {
+	sum := sha256.Sum256([]byte(r.Bucket + "/" + r.Key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sink - an external index of the metadata of objects.
+type Sink interface {
+	// Init creates the index if missing or upgrades its schema.
+	Init(ctx context.Context) error
+	// Write applies the records in order.
+	Write(ctx context.Context, records []Record) error
+	// Sweep removes the objects whose last record was written before
+	// the given time.
+	Sweep(ctx context.Context, before time.Time) error
+	Close() error
+}
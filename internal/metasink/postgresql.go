@@ -0,0 +1,176 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metasink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq" // Register postgres driver
+)
+
+// postgresColumns are the columns of the table, added to tables created
+// by an older schema version. The table name is validated by the
+// configuration.
+var postgresColumns = []struct {
+	name, typ string
+}{
+	{"bucket", "TEXT NOT NULL"},
+	{"key", "TEXT NOT NULL"},
+	{"version_id", "TEXT"},
+	{"size", "BIGINT"},
+	{"etag", "TEXT"},
+	{"content_type", "TEXT"},
+	{"storage_class", "TEXT"},
+	{"last_modified", "TIMESTAMP WITH TIME ZONE"},
+	{"user_metadata", "JSONB"},
+	{"tags", "JSONB"},
+	{"event", "TEXT"},
+	{"event_time", "TIMESTAMP WITH TIME ZONE"},
+}
+
+const (
+	psqlCreateTable = `CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY);`
+	psqlAddColumn   = `ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;`
+	psqlCreateIndex = `CREATE INDEX IF NOT EXISTS %s_%s ON %s %s;`
+	psqlComment     = `COMMENT ON TABLE %s IS 'MinIO object metadata, schema version %d';`
+
+	// psqlUpsert keeps the row of a newer version, the version id and
+	// the event time breaking ties of versions written at once, or of
+	// the tags of an object changing.
+	psqlUpsert = `INSERT INTO %[1]s (id, bucket, key, version_id, size, etag, content_type, storage_class, last_modified, user_metadata, tags, event, event_time)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (id) DO UPDATE SET version_id = EXCLUDED.version_id, size = EXCLUDED.size, etag = EXCLUDED.etag,
+content_type = EXCLUDED.content_type, storage_class = EXCLUDED.storage_class, last_modified = EXCLUDED.last_modified,
+user_metadata = EXCLUDED.user_metadata, tags = EXCLUDED.tags, event = EXCLUDED.event, event_time = EXCLUDED.event_time
+WHERE (%[1]s.last_modified, %[1]s.version_id, %[1]s.event_time) <= (EXCLUDED.last_modified, EXCLUDED.version_id, EXCLUDED.event_time);`
+	psqlDelete = `DELETE FROM %s WHERE id = $1 AND last_modified <= $2;`
+	psqlSweep  = `DELETE FROM %s WHERE event_time < $1;`
+)
+
+// postgresIndexes are the indexes of the table, by name suffix.
+var postgresIndexes = []struct {
+	suffix, def string
+}{
+	{"bucket_key", "(bucket, key)"},
+	{"last_modified", "(bucket, last_modified)"},
+	{"size", "(bucket, size)"},
+	{"event_time", "(event_time)"},
+	{"tags", "USING GIN (tags)"},
+	{"user_metadata", "USING GIN (user_metadata)"},
+}
+
+type postgresSink struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgreSQL returns a sink writing to a table of a PostgreSQL
+// database.
+func NewPostgreSQL(connectionString, table string) (Sink, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresSink{db: db, table: table}, nil
+}
+
+// Init creates the table with its indexes, or adds the columns and the
+// indexes missing to an existing table.
+func (s *postgresSink) Init(ctx context.Context) error {
+	stmts := []string{fmt.Sprintf(psqlCreateTable, s.table)}
+	for _, c := range postgresColumns {
+		stmts = append(stmts, fmt.Sprintf(psqlAddColumn, s.table, c.name, c.typ))
+	}
+	for _, idx := range postgresIndexes {
+		stmts = append(stmts, fmt.Sprintf(psqlCreateIndex, s.table, idx.suffix, s.table, idx.def))
+	}
+	stmts = append(stmts, fmt.Sprintf(psqlComment, s.table, SchemaVersion))
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%s: %w", strings.SplitN(stmt, " (", 2)[0], err)
+		}
+	}
+	return nil
+}
+
+// Write upserts the records in one transaction, deleting the rows of
+// deleted records. Records older than the rows are ignored.
+func (s *postgresSink) Write(ctx context.Context, records []Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.PrepareContext(ctx, fmt.Sprintf(psqlUpsert, s.table))
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+	del, err := tx.PrepareContext(ctx, fmt.Sprintf(psqlDelete, s.table))
+	if err != nil {
+		return err
+	}
+	defer del.Close()
+
+	for _, r := range records {
+		if r.Deleted {
+			if _, err = del.ExecContext(ctx, r.ID(), r.LastModified); err != nil {
+				return err
+			}
+			continue
+		}
+		userMetadata, err := jsonbValue(r.UserMetadata)
+		if err != nil {
+			return err
+		}
+		tags, err := jsonbValue(r.Tags)
+		if err != nil {
+			return err
+		}
+		if _, err = upsert.ExecContext(ctx, r.ID(), r.Bucket, r.Key, r.VersionID, r.Size, r.ETag,
+			r.ContentType, r.StorageClass, r.LastModified, userMetadata, tags, r.Event, r.EventTime); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Sweep deletes the rows written before the given time.
+func (s *postgresSink) Sweep(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(psqlSweep, s.table), before)
+	return err
+}
+
+// jsonbValue returns m as a JSON object, empty maps included.
+func jsonbValue(m map[string]string) (string, error) {
+	if m == nil {
+		m = map[string]string{}
+	}
+	data, err := json.Marshal(m)
+	return string(data), err
+}
+
+func (s *postgresSink) Close() error {
+	return s.db.Close()
+}
@@ -33,6 +33,12 @@ const (
 // ReaderArgs - represents elements inside <InputSerialization><JSON/> in request XML.
 type ReaderArgs struct {
 	ContentType string `xml:"Type"`
+
+	// Projection lists the top level keys of JSON objects needed by
+	// the query, other keys are dropped while reading. All keys are
+	// kept when it is nil.
+	Projection []string `xml:"-"`
+
 	unmarshaled bool
 }
 
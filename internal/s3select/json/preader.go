@@ -193,7 +193,7 @@ func (r *PReader) startReaders() {
 					if mv.ValueType == jstream.Object {
 						// This is a JSON object type (that preserves key
 						// order)
-						kvs = mv.Value.(jstream.KVS)
+						kvs = project(mv.Value.(jstream.KVS), r.args.Projection)
 					} else {
 						// To be AWS S3 compatible Select for JSON needs to
 						// output non-object JSON as single column value
@@ -49,7 +49,7 @@ func (r *Reader) Read(dst sql.Record) (sql.Record, error) {
 	if v.ValueType == jstream.Object {
 		// This is a JSON object type (that preserves key
 		// order)
-		kvs = v.Value.(jstream.KVS)
+		kvs = project(v.Value.(jstream.KVS), r.args.Projection)
 	} else {
 		// To be AWS S3 compatible Select for JSON needs to
 		// output non-object JSON as single column value
@@ -204,3 +204,22 @@ func jsonFloat(f float64) string {
 	}
 	return string(dst)
 }
+
+// project drops the keys of kvs which are not listed in keys, nil keys
+// keep all of them.
+func project(kvs jstream.KVS, keys []string) jstream.KVS {
+	if keys == nil {
+		return kvs
+	}
+	n := 0
+	for _, kv := range kvs {
+		for _, key := range keys {
+			if kv.Key == key {
+				kvs[n] = kv
+				n++
+				break
+			}
+		}
+	}
+	return kvs[:n]
+}
@@ -20,6 +20,7 @@ package s3select
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/xml"
 	"fmt"
 	"hash/crc32"
 	"net/http"
@@ -235,6 +236,40 @@ func newErrorMessage(errorCode, errorMessage []byte) []byte {
 	return genMessage(buf.Bytes(), nil)
 }
 
+// Headers of an ObjectError message, laid out as in statsHeader.
+var objectErrorHeader = []byte{
+	13, ':', 'm', 'e', 's', 's', 'a', 'g', 'e', '-', 't', 'y', 'p', 'e', 7, 0, 5, 'e', 'v', 'e', 'n', 't',
+	13, ':', 'c', 'o', 'n', 't', 'e', 'n', 't', '-', 't', 'y', 'p', 'e', 7, 0, 8, 't', 'e', 'x', 't', '/', 'x', 'm', 'l',
+	11, ':', 'e', 'v', 'e', 'n', 't', '-', 't', 'y', 'p', 'e', 7, 0, 11, 'O', 'b', 'j', 'e', 'c', 't', 'E', 'r', 'r', 'o', 'r',
+}
+
+// newObjectErrorMessage - creates new ObjectError Message, a MinIO extension sent when
+// a query over multiple objects skips an object which could not be read. Unlike a
+// request level error message, the query goes on with the next object.
+//
+// Payload specification:
+// ObjectError message payload is an XML document naming the object and the failure.
+//
+// Example:
+//
+// <?xml version="1.0" encoding="UTF-8"?>
+// <ObjectError>
+//   <Key>data/2021/part-0001.csv</Key>
+//   <Code>NoSuchKey</Code>
+//   <Message>The specified key does not exist.</Message>
+// </ObjectError>
+func newObjectErrorMessage(key, errorCode, errorMessage string) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ObjectError><Key>`)
+	xml.EscapeText(buf, []byte(key))
+	buf.WriteString(`</Key><Code>`)
+	xml.EscapeText(buf, []byte(errorCode))
+	buf.WriteString(`</Code><Message>`)
+	xml.EscapeText(buf, []byte(errorMessage))
+	buf.WriteString(`</Message></ObjectError>`)
+	return genMessage(objectErrorHeader, buf.Bytes())
+}
+
 // NewErrorMessage - creates new Request Level Error Message specified in
 // https://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectSELECTContent.html.
 func NewErrorMessage(errorCode, errorMessage string) []byte {
@@ -252,8 +287,9 @@ type messageWriter struct {
 
 	finBytesScanned, finBytesProcessed int64
 
-	errCh  chan []byte
-	doneCh chan struct{}
+	errCh   chan []byte
+	eventCh chan []byte
+	doneCh  chan struct{}
 }
 
 func (writer *messageWriter) write(data []byte) bool {
@@ -313,23 +349,18 @@ func (writer *messageWriter) start() {
 					break
 				}
 				writer.write(endMessage)
-			} else {
-				for payload.Len() > 0 {
-					copiedLen := copy(writer.payloadBuffer[writer.payloadBufferIndex:], payload.Bytes())
-					writer.payloadBufferIndex += copiedLen
-					payload.Next(copiedLen)
-
-					// If buffer is filled, flush it now!
-					freeSpace := bufLength - writer.payloadBufferIndex
-					if freeSpace == 0 {
-						if !writer.flushRecords() {
-							quitFlag = true
-							break
-						}
-					}
-				}
+			} else if !writer.stageRecords(payload) {
+				quitFlag = true
+			}
 
-				bufPool.Put(payload)
+		case data := <-writer.eventCh:
+			// Records sent before the event are already queued,
+			// write them out first to keep the order.
+			for len(writer.payloadCh) > 0 && !quitFlag {
+				quitFlag = !writer.stageRecords(<-writer.payloadCh)
+			}
+			if quitFlag || !writer.flushRecords() || !writer.write(data) {
+				quitFlag = true
 			}
 
 		case <-recordStagingTicker.C:
@@ -365,6 +396,26 @@ func (writer *messageWriter) start() {
 	}
 }
 
+// stageRecords copies payload into the records buffer, flushing it
+// whenever it fills up.
+func (writer *messageWriter) stageRecords(payload *bytes.Buffer) bool {
+	defer bufPool.Put(payload)
+	for payload.Len() > 0 {
+		copiedLen := copy(writer.payloadBuffer[writer.payloadBufferIndex:], payload.Bytes())
+		writer.payloadBufferIndex += copiedLen
+		payload.Next(copiedLen)
+
+		// If buffer is filled, flush it now!
+		freeSpace := bufLength - writer.payloadBufferIndex
+		if freeSpace == 0 {
+			if !writer.flushRecords() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // Sends a single whole record.
 func (writer *messageWriter) SendRecord(payload *bytes.Buffer) error {
 	select {
@@ -404,6 +455,16 @@ func (writer *messageWriter) Finish(bytesScanned, bytesProcessed int64) error {
 	}
 }
 
+// SendObjectError sends an ObjectError message after the records sent so far.
+func (writer *messageWriter) SendObjectError(key, errorCode, errorMessage string) error {
+	select {
+	case writer.eventCh <- newObjectErrorMessage(key, errorCode, errorMessage):
+		return nil
+	case <-writer.doneCh:
+		return fmt.Errorf("messageWriter is done")
+	}
+}
+
 func (writer *messageWriter) FinishWithError(errorCode, errorMessage string) error {
 	select {
 	case <-writer.doneCh:
@@ -425,8 +486,9 @@ func newMessageWriter(w http.ResponseWriter, getProgressFunc func() (bytesScanne
 		payloadBuffer: make([]byte, bufLength),
 		payloadCh:     make(chan *bytes.Buffer, 1),
 
-		errCh:  make(chan []byte),
-		doneCh: make(chan struct{}),
+		errCh:   make(chan []byte),
+		eventCh: make(chan []byte),
+		doneCh:  make(chan struct{}),
 	}
 	go writer.start()
 	return writer
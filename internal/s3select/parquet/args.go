@@ -17,10 +17,21 @@
 
 package parquet
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+
+	"github.com/minio/minio/internal/s3select/sql"
+)
 
 // ReaderArgs - represents elements inside <InputSerialization><Parquet/> in request XML.
 type ReaderArgs struct {
+	// Projection lists the top level columns needed by the query,
+	// only these are read when set.
+	Projection []string `xml:"-"`
+	// Bounds on column values required by the query, row groups whose
+	// statistics fall outside of them are skipped.
+	Bounds []sql.ColumnBound `xml:"-"`
+
 	unmarshaled bool
 }
 
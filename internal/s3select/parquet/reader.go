@@ -18,11 +18,15 @@
 package parquet
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/bcicen/jstream"
+	"github.com/minio/minio-go/v7/pkg/set"
 	jsonfmt "github.com/minio/minio/internal/s3select/json"
 	"github.com/minio/minio/internal/s3select/sql"
 	parquetgo "github.com/minio/parquet-go"
@@ -43,6 +47,11 @@ func (r *Reader) Read(dst sql.Record) (rec sql.Record, rerr error) {
 		}
 	}()
 
+	if r.reader == nil {
+		// All row groups were skipped.
+		return nil, io.EOF
+	}
+
 	parquetRecord, err := r.reader.Read()
 	if err != nil {
 		if err != io.EOF {
@@ -69,6 +78,8 @@ func (r *Reader) Read(dst sql.Record) (rec sql.Record, rerr error) {
 				switch *v.Schema.ConvertedType {
 				case parquetgen.ConvertedType_DATE:
 					value = sql.FormatSQLTimestamp(time.Unix(60*60*24*int64(v.Value.(int32)), 0).UTC())
+				case parquetgen.ConvertedType_DECIMAL:
+					value = decimalValue(big.NewInt(int64(v.Value.(int32))), v.Schema)
 				}
 			}
 		case parquetgen.Type_INT64:
@@ -80,14 +91,27 @@ func (r *Reader) Read(dst sql.Record) (rec sql.Record, rerr error) {
 					value = sql.FormatSQLTimestamp(time.Unix(0, 0).Add(time.Duration(v.Value.(int64)) * time.Millisecond).UTC())
 				case parquetgen.ConvertedType_TIMESTAMP_MICROS:
 					value = sql.FormatSQLTimestamp(time.Unix(0, 0).Add(time.Duration(v.Value.(int64)) * time.Microsecond).UTC())
+				case parquetgen.ConvertedType_DECIMAL:
+					value = decimalValue(big.NewInt(v.Value.(int64)), v.Schema)
 				}
 			}
 		case parquetgen.Type_FLOAT:
 			value = float64(v.Value.(float32))
 		case parquetgen.Type_DOUBLE:
 			value = v.Value.(float64)
-		case parquetgen.Type_INT96, parquetgen.Type_BYTE_ARRAY, parquetgen.Type_FIXED_LEN_BYTE_ARRAY:
+		case parquetgen.Type_INT96:
+			// Legacy timestamps, as written by Hive, Impala and Spark.
+			b := v.Value.([]byte)
+			if len(b) != 12 {
+				rerr = errParquetParsingError(nil)
+				return false
+			}
+			value = sql.FormatSQLTimestamp(int96Timestamp(b))
+		case parquetgen.Type_BYTE_ARRAY, parquetgen.Type_FIXED_LEN_BYTE_ARRAY:
 			value = string(v.Value.([]byte))
+			if v.Schema != nil && v.Schema.ConvertedType != nil && *v.Schema.ConvertedType == parquetgen.ConvertedType_DECIMAL {
+				value = decimalValue(twosComplement(v.Value.([]byte)), v.Schema)
+			}
 		default:
 			rerr = errParquetParsingError(nil)
 			return false
@@ -112,9 +136,49 @@ func (r *Reader) Read(dst sql.Record) (rec sql.Record, rerr error) {
 
 // Close - closes underlying readers.
 func (r *Reader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
 	return r.reader.Close()
 }
 
+// Julian day of the unix epoch.
+const julianDayOfEpoch = 2440588
+
+// int96Timestamp decodes an INT96 timestamp, the nanoseconds of the day
+// followed by the julian day, both little endian.
+func int96Timestamp(b []byte) time.Time {
+	nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+	days := int64(binary.LittleEndian.Uint32(b[8:])) - julianDayOfEpoch
+	return time.Unix(days*24*60*60, nanos).UTC()
+}
+
+// twosComplement decodes a big endian two's complement integer.
+func twosComplement(b []byte) *big.Int {
+	i := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return i
+}
+
+// decimalValue formats the unscaled value of a DECIMAL column with the
+// digits of its scale, decimals are not exact as float64.
+func decimalValue(unscaled *big.Int, schema *parquetgen.SchemaElement) string {
+	digits := new(big.Int).Abs(unscaled).String()
+	if schema.Scale != nil && *schema.Scale > 0 {
+		scale := int(*schema.Scale)
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
 // NewReader - creates new Parquet reader using readerFunc callback.
 func NewReader(getReaderFunc func(offset, length int64) (io.ReadCloser, error), args *ReaderArgs) (r *Reader, err error) {
 	defer func() {
@@ -122,7 +186,37 @@ func NewReader(getReaderFunc func(offset, length int64) (io.ReadCloser, error),
 			err = fmt.Errorf("panic reading parquet header: %v", rec)
 		}
 	}()
-	reader, err := parquetgo.NewReader(getReaderFunc, nil)
+
+	meta, err := readFileMetadata(getReaderFunc)
+	if err != nil {
+		return nil, err
+	}
+	pruneRowGroups(meta, args.Bounds)
+	if len(meta.RowGroups) == 0 {
+		return &Reader{args: args}, nil
+	}
+
+	// Only read the projected columns when all of them are top level
+	// leaf columns, which the reader names the same.
+	var columnNames set.StringSet
+	if len(args.Projection) > 0 {
+		columns := topLevelColumns(meta.Schema)
+		columnNames = set.NewStringSet()
+		for _, name := range args.Projection {
+			if _, ok := columns[name]; !ok {
+				columnNames = nil
+				break
+			}
+			columnNames.Add(name)
+		}
+	}
+
+	// The reader reads the footer again, serve it the pruned one.
+	footer, err := encodeFooter(meta)
+	if err != nil {
+		return nil, errParquetParsingError(err)
+	}
+	reader, err := parquetgo.NewReader(footerReaderFunc(getReaderFunc, footer), columnNames)
 	if err != nil {
 		if err != io.EOF {
 			return nil, errParquetParsingError(err)
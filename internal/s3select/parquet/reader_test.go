@@ -0,0 +1,101 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/internal/s3select/sql"
+	parquetgen "github.com/minio/parquet-go/gen-go/parquet"
+)
+
+func TestInt96Timestamp(t *testing.T) {
+	b := make([]byte, 12)
+	binary.LittleEndian.PutUint64(b, uint64(time.Hour+time.Second))
+	binary.LittleEndian.PutUint32(b[8:], julianDayOfEpoch+1)
+	want := time.Date(1970, 1, 2, 1, 0, 1, 0, time.UTC)
+	if got := int96Timestamp(b); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDecimalValue(t *testing.T) {
+	scale := int32(2)
+	schema := &parquetgen.SchemaElement{Scale: &scale}
+	cases := []struct {
+		b    []byte
+		want string
+	}{
+		{[]byte{0x04, 0xd2}, "12.34"},
+		{[]byte{0xfb, 0x2e}, "-12.34"},
+		{[]byte{0x00}, "0.00"},
+		{[]byte{0x07}, "0.07"},
+		{[]byte{0xff, 0xfb}, "-0.05"},
+		// Not exact as float64.
+		{[]byte{0x11, 0x22, 0x10, 0xf4, 0x7d, 0xe9, 0x81, 0x15}, "12345678901234567.89"},
+	}
+	for i, tc := range cases {
+		if got := decimalValue(twosComplement(tc.b), schema); got != tc.want {
+			t.Errorf("Case %d: expected %v, got %v", i+1, tc.want, got)
+		}
+	}
+}
+
+func TestPruneRowGroups(t *testing.T) {
+	int64Stat := func(v int64) []byte {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(v))
+		return b
+	}
+	children := int32(1)
+	rowGroup := func(min, max int64) *parquetgen.RowGroup {
+		return &parquetgen.RowGroup{
+			NumRows: 10,
+			Columns: []*parquetgen.ColumnChunk{{
+				MetaData: &parquetgen.ColumnMetaData{
+					Type:         parquetgen.Type_INT64,
+					PathInSchema: []string{"size"},
+					NumValues:    10,
+					Statistics: &parquetgen.Statistics{
+						MinValue: int64Stat(min),
+						MaxValue: int64Stat(max),
+					},
+				},
+			}},
+		}
+	}
+	meta := &parquetgen.FileMetaData{
+		Schema: []*parquetgen.SchemaElement{
+			{Name: "schema", NumChildren: &children},
+			{Name: "size"},
+		},
+		RowGroups: []*parquetgen.RowGroup{rowGroup(0, 9), rowGroup(10, 19), rowGroup(20, 29)},
+		NumRows:   30,
+	}
+
+	stmt, err := sql.ParseSelectStatement("select * from s3object s where s.size >= 12 and s.size <= 15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pruneRowGroups(meta, stmt.ColumnBounds())
+	if len(meta.RowGroups) != 1 || meta.NumRows != 10 {
+		t.Fatalf("expected a single row group to be kept, got %d with %d rows", len(meta.RowGroups), meta.NumRows)
+	}
+}
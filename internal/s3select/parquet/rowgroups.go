@@ -0,0 +1,234 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/minio/minio/internal/s3select/sql"
+	parquetgen "github.com/minio/parquet-go/gen-go/parquet"
+)
+
+const parquetMagic = "PAR1"
+
+// readFileMetadata reads the footer of a parquet file.
+func readFileMetadata(getReaderFunc func(offset, length int64) (io.ReadCloser, error)) (*parquetgen.FileMetaData, error) {
+	rc, err := getReaderFunc(-8, 8)
+	if err != nil {
+		return nil, err
+	}
+	tail := make([]byte, 8)
+	_, err = io.ReadFull(rc, tail)
+	rc.Close()
+	if err != nil {
+		return nil, errParquetParsingError(err)
+	}
+	if string(tail[4:]) != parquetMagic {
+		return nil, errParquetParsingError(errors.New("not a parquet file"))
+	}
+
+	size := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if rc, err = getReaderFunc(-(8 + size), size); err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	meta := parquetgen.NewFileMetaData()
+	if err = meta.Read(thrift.NewTCompactProtocol(thrift.NewStreamTransportR(io.LimitReader(rc, size)))); err != nil {
+		return nil, errParquetParsingError(err)
+	}
+	return meta, nil
+}
+
+// encodeFooter serializes meta as the footer of a parquet file.
+func encodeFooter(meta *parquetgen.FileMetaData) ([]byte, error) {
+	buf := thrift.NewTMemoryBuffer()
+	if err := meta.Write(thrift.NewTCompactProtocol(buf)); err != nil {
+		return nil, err
+	}
+	footer := buf.Bytes()
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(footer)))
+	footer = append(footer, size...)
+	return append(footer, parquetMagic...), nil
+}
+
+// footerReaderFunc serves the suffix reads of the footer from footer,
+// which the parquet reader does when opening a file, and the other
+// reads from getReaderFunc.
+func footerReaderFunc(getReaderFunc func(offset, length int64) (io.ReadCloser, error), footer []byte) func(offset, length int64) (io.ReadCloser, error) {
+	return func(offset, length int64) (io.ReadCloser, error) {
+		if offset >= 0 {
+			return getReaderFunc(offset, length)
+		}
+		start := int64(len(footer)) + offset
+		if start < 0 {
+			return nil, errParquetParsingError(errors.New("read beyond the parquet footer"))
+		}
+		end := int64(len(footer))
+		if length >= 0 && start+length < end {
+			end = start + length
+		}
+		return ioutil.NopCloser(bytes.NewReader(footer[start:end])), nil
+	}
+}
+
+// topLevelColumns returns the schema of the top level leaf columns.
+func topLevelColumns(schema []*parquetgen.SchemaElement) map[string]*parquetgen.SchemaElement {
+	columns := make(map[string]*parquetgen.SchemaElement)
+	if len(schema) == 0 {
+		return columns
+	}
+	// The root comes first, followed by its children depth first.
+	i := 1
+	for c := int32(0); c < numChildren(schema[0]) && i < len(schema); c++ {
+		if numChildren(schema[i]) == 0 {
+			columns[schema[i].Name] = schema[i]
+			i++
+			continue
+		}
+		i = skipSchemaElement(schema, i)
+	}
+	return columns
+}
+
+func numChildren(elem *parquetgen.SchemaElement) int32 {
+	if elem.NumChildren == nil {
+		return 0
+	}
+	return *elem.NumChildren
+}
+
+// skipSchemaElement returns the index following the subtree at i.
+func skipSchemaElement(schema []*parquetgen.SchemaElement, i int) int {
+	n := numChildren(schema[i])
+	i++
+	for c := int32(0); c < n && i < len(schema); c++ {
+		i = skipSchemaElement(schema, i)
+	}
+	return i
+}
+
+// pruneRowGroups removes the row groups of meta which cannot hold any
+// record within bounds, going by their column statistics.
+func pruneRowGroups(meta *parquetgen.FileMetaData, bounds []sql.ColumnBound) {
+	if len(bounds) == 0 {
+		return
+	}
+	columns := topLevelColumns(meta.Schema)
+	kept := meta.RowGroups[:0]
+	numRows := int64(0)
+	for _, rg := range meta.RowGroups {
+		if rowGroupExcluded(rg, columns, bounds) {
+			continue
+		}
+		kept = append(kept, rg)
+		numRows += rg.NumRows
+	}
+	meta.RowGroups = kept
+	meta.NumRows = numRows
+}
+
+func rowGroupExcluded(rg *parquetgen.RowGroup, columns map[string]*parquetgen.SchemaElement, bounds []sql.ColumnBound) bool {
+	for _, chunk := range rg.Columns {
+		md := chunk.MetaData
+		if md == nil || len(md.PathInSchema) != 1 || md.Statistics == nil {
+			continue
+		}
+		schema, ok := columns[md.PathInSchema[0]]
+		if !ok {
+			continue
+		}
+		stats := md.Statistics
+		allNull := stats.NullCount != nil && md.NumValues > 0 && *stats.NullCount == md.NumValues
+		min, max, ok := columnStatistics(md.Type, schema, stats)
+		for _, b := range bounds {
+			if b.Column != schema.Name {
+				continue
+			}
+			// Comparisons with null never match.
+			if allNull || (ok && b.Excludes(min, max)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// columnStatistics decodes the plain encoded minimum and maximum of a
+// column chunk. Values of converted types are not compared, since they
+// are read back as a different type than the one stored.
+func columnStatistics(t parquetgen.Type, schema *parquetgen.SchemaElement, stats *parquetgen.Statistics) (min, max *sql.Value, ok bool) {
+	minB, maxB := stats.MinValue, stats.MaxValue
+	if minB == nil || maxB == nil {
+		if t == parquetgen.Type_BYTE_ARRAY {
+			// The deprecated statistics of byte arrays have no defined order.
+			return nil, nil, false
+		}
+		minB, maxB = stats.Min, stats.Max
+	}
+	if minB == nil || maxB == nil {
+		return nil, nil, false
+	}
+	if schema.ConvertedType != nil && !(t == parquetgen.Type_BYTE_ARRAY && *schema.ConvertedType == parquetgen.ConvertedType_UTF8) {
+		return nil, nil, false
+	}
+	if min, ok = statisticValue(t, minB); !ok {
+		return nil, nil, false
+	}
+	if max, ok = statisticValue(t, maxB); !ok {
+		return nil, nil, false
+	}
+	return min, max, true
+}
+
+func statisticValue(t parquetgen.Type, b []byte) (*sql.Value, bool) {
+	switch t {
+	case parquetgen.Type_INT32:
+		if len(b) != 4 {
+			return nil, false
+		}
+		return sql.FromInt(int64(int32(binary.LittleEndian.Uint32(b)))), true
+	case parquetgen.Type_INT64:
+		if len(b) != 8 {
+			return nil, false
+		}
+		return sql.FromInt(int64(binary.LittleEndian.Uint64(b))), true
+	case parquetgen.Type_FLOAT:
+		if len(b) != 4 {
+			return nil, false
+		}
+		f := float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		return sql.FromFloat(f), !math.IsNaN(f)
+	case parquetgen.Type_DOUBLE:
+		if len(b) != 8 {
+			return nil, false
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return sql.FromFloat(f), !math.IsNaN(f)
+	case parquetgen.Type_BYTE_ARRAY:
+		return sql.FromString(string(b)), true
+	}
+	return nil, false
+}
@@ -227,6 +227,18 @@ type S3Select struct {
 	progressReader *progressReader
 	recordReader   recordReader
 	close          func() error
+
+	// Set while evaluating over multiple objects.
+	key                          string
+	bytesScanned, bytesProcessed int64
+}
+
+// SelectObject - an object queried by EvaluateObjects.
+type SelectObject struct {
+	Key       string
+	GetReader func(offset, length int64) (io.ReadCloser, error)
+	// Err is reported for the object instead of opening it.
+	Err error
 }
 
 var (
@@ -274,6 +286,13 @@ func (s3Select *S3Select) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 
 	parsedS3Select.statement = &statement
 
+	// Only keep the keys of records the query refers to.
+	if keys, ok := statement.ProjectedKeys(); ok {
+		parsedS3Select.Input.JSONArgs.Projection = keys
+		parsedS3Select.Input.ParquetArgs.Projection = keys
+	}
+	parsedS3Select.Input.ParquetArgs.Bounds = statement.ColumnBounds()
+
 	*s3Select = S3Select(parsedS3Select)
 	return nil
 }
@@ -290,8 +309,13 @@ func (s3Select *S3Select) outputRecord() sql.Record {
 }
 
 func (s3Select *S3Select) getProgress() (bytesScanned, bytesProcessed int64) {
+	bytesScanned, bytesProcessed = s3Select.bytesScanned, s3Select.bytesProcessed
 	if s3Select.progressReader != nil {
-		return s3Select.progressReader.Stats()
+		scanned, processed := s3Select.progressReader.Stats()
+		return bytesScanned + scanned, bytesProcessed + processed
+	}
+	if bytesScanned > 0 {
+		return bytesScanned, bytesProcessed
 	}
 
 	return -1, -1
@@ -420,8 +444,72 @@ func (s3Select *S3Select) marshal(buf *bytes.Buffer, record sql.Record) error {
 	panic(fmt.Errorf("unknown output format '%v'", s3Select.Output.format))
 }
 
+// closeObject closes the opened object, keeping its progress.
+func (s3Select *S3Select) closeObject() {
+	if s3Select.progressReader != nil {
+		scanned, processed := s3Select.progressReader.Stats()
+		s3Select.bytesScanned += scanned
+		s3Select.bytesProcessed += processed
+		s3Select.progressReader = nil
+	}
+	if s3Select.recordReader != nil {
+		s3Select.recordReader.Close()
+		s3Select.recordReader = nil
+	}
+	if s3Select.close != nil {
+		s3Select.close()
+		s3Select.close = nil
+	}
+}
+
+// openNext closes the opened object and opens the next one returned
+// by next, objects which fail to open are reported and skipped. It
+// returns false when there are no more objects to read.
+func (s3Select *S3Select) openNext(writer *messageWriter, next func() (SelectObject, bool)) bool {
+	s3Select.closeObject()
+	for {
+		obj, ok := next()
+		if !ok {
+			return false
+		}
+		s3Select.key = obj.Key
+		err := obj.Err
+		if err == nil {
+			err = s3Select.Open(obj.GetReader)
+		}
+		if err == nil {
+			return true
+		}
+		if !s3Select.sendObjectError(writer, err) {
+			return false
+		}
+	}
+}
+
+// sendObjectError reports err for the current object.
+func (s3Select *S3Select) sendObjectError(writer *messageWriter, err error) bool {
+	code, message := "InternalError", err.Error()
+	if serr, ok := err.(SelectError); ok {
+		code, message = serr.ErrorCode(), serr.ErrorMessage()
+	}
+	return writer.SendObjectError(s3Select.key, code, message) == nil
+}
+
 // Evaluate - filters and sends records read from opened reader as per select statement to http response writer.
 func (s3Select *S3Select) Evaluate(w http.ResponseWriter) {
+	s3Select.evaluate(w, nil)
+}
+
+// EvaluateObjects - like Evaluate, but reads the records of all objects
+// returned by next in turn as if they were a single object, such that
+// aggregations and LIMIT apply across objects. Objects which cannot be
+// opened or read are reported in ObjectError messages and skipped, the
+// records already sent for them are kept. Progress messages are not sent.
+func (s3Select *S3Select) EvaluateObjects(w http.ResponseWriter, next func() (SelectObject, bool)) {
+	s3Select.evaluate(w, next)
+}
+
+func (s3Select *S3Select) evaluate(w http.ResponseWriter, next func() (SelectObject, bool)) {
 	defer func() {
 		if s3Select.close != nil {
 			s3Select.close()
@@ -429,11 +517,16 @@ func (s3Select *S3Select) Evaluate(w http.ResponseWriter) {
 	}()
 
 	getProgressFunc := s3Select.getProgress
-	if !s3Select.Progress.Enabled {
+	if !s3Select.Progress.Enabled || next != nil {
+		// The progress reader changes with every object.
 		getProgressFunc = nil
 	}
 	writer := newMessageWriter(w, getProgressFunc)
 
+	if next != nil && !s3Select.openNext(writer, next) {
+		next = nil
+	}
+
 	var outputQueue []sql.Record
 
 	// Create queue based on the type.
@@ -487,9 +580,29 @@ OuterLoop:
 			break
 		}
 
-		if rec, err = s3Select.recordReader.Read(rec); err != nil {
+		if s3Select.recordReader == nil {
+			// None of the objects could be opened.
+			err = io.EOF
+		} else {
+			rec, err = s3Select.recordReader.Read(rec)
+		}
+		if err != nil {
 			if err != io.EOF {
-				break
+				if next == nil {
+					break
+				}
+				if !s3Select.sendObjectError(writer, err) {
+					err = nil
+					break
+				}
+			}
+			err = nil
+
+			if next != nil {
+				if s3Select.openNext(writer, next) {
+					continue
+				}
+				next = nil
 			}
 
 			if s3Select.statement.IsAggregated() {
@@ -566,6 +679,9 @@ OuterLoop:
 
 // Close - closes opened S3 object.
 func (s3Select *S3Select) Close() error {
+	if s3Select.recordReader == nil {
+		return nil
+	}
 	return s3Select.recordReader.Close()
 }
 
@@ -19,7 +19,9 @@ package s3select
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -29,7 +31,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/cpuid/v2"
+	gzip "github.com/klauspost/pgzip"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/simdjson-go"
 )
@@ -531,6 +535,116 @@ func TestJSONQueries(t *testing.T) {
 	}
 }
 
+func TestJSONCompressedQueries(t *testing.T) {
+	input := `{"time":"2021-10-01T10:00:00Z","req":{"method":"GET","path":"/a","status":200,"headers":{"host":"one"}},"size":10}
+{"time":"2021-10-01T10:00:01Z","req":{"method":"PUT","path":"/b","status":503,"headers":{"host":"two"}},"size":20}
+{"time":"2021-10-01T10:00:02Z","req":{"method":"GET","path":"/c","status":500,"headers":{"host":"one"}},"size":30}
+`
+	compressors := map[string]func(w io.Writer) io.WriteCloser{
+		"GZIP": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"ZSTD": func(w io.Writer) io.WriteCloser {
+			enc, err := zstd.NewWriter(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return enc
+		},
+	}
+
+	var testTable = []struct {
+		name       string
+		query      string
+		wantResult string
+	}{
+		{
+			name:       "nested-where",
+			query:      `SELECT s.req.path FROM S3Object s WHERE s.req.status >= 500 AND s.req.headers.host = 'one'`,
+			wantResult: `{"path":"/c"}`,
+		},
+		{
+			name:  "nested-projection",
+			query: `SELECT s.req.method, s.size FROM S3Object s WHERE s.req.status > 200`,
+			wantResult: `{"method":"PUT","size":20}
+{"method":"GET","size":30}`,
+		},
+		{
+			name:       "aggregate",
+			query:      `SELECT SUM(s.size) FROM S3Object s WHERE s.req.method = 'GET'`,
+			wantResult: `{"_1":40}`,
+		},
+	}
+
+	defRequest := `<?xml version="1.0" encoding="UTF-8"?>
+<SelectObjectContentRequest>
+    <Expression>%s</Expression>
+    <ExpressionType>SQL</ExpressionType>
+    <InputSerialization>
+        <CompressionType>%s</CompressionType>
+        <JSON>
+            <Type>LINES</Type>
+        </JSON>
+    </InputSerialization>
+    <OutputSerialization>
+        <JSON>
+        </JSON>
+    </OutputSerialization>
+    <RequestProgress>
+        <Enabled>FALSE</Enabled>
+    </RequestProgress>
+</SelectObjectContentRequest>`
+
+	for compression, newWriter := range compressors {
+		var compressed bytes.Buffer
+		w := newWriter(&compressed)
+		if _, err := io.WriteString(w, input); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, testCase := range testTable {
+			t.Run(compression+"-"+testCase.name, func(t *testing.T) {
+				var escaped bytes.Buffer
+				xml.EscapeText(&escaped, []byte(testCase.query))
+				s3Select, err := NewS3Select(bytes.NewReader([]byte(fmt.Sprintf(defRequest, escaped.String(), compression))))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err = s3Select.Open(func(offset, length int64) (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(compressed.Bytes())), nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+
+				w := &testResponseWriter{}
+				s3Select.Evaluate(w)
+				s3Select.Close()
+				resp := http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          ioutil.NopCloser(bytes.NewReader(w.response)),
+					ContentLength: int64(len(w.response)),
+				}
+				res, err := minio.NewSelectResults(&resp, "testbucket")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got, err := ioutil.ReadAll(res)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				gotS := strings.TrimSpace(string(got))
+				if !reflect.DeepEqual(gotS, testCase.wantResult) {
+					t.Errorf("received response does not match with expected reply. Query: %s\ngot: %s\nwant:%s", testCase.query, gotS, testCase.wantResult)
+				}
+			})
+		}
+	}
+}
+
 func TestCSVQueries(t *testing.T) {
 	input := `index,ID,CaseNumber,Date,Day,Month,Year,Block,IUCR,PrimaryType,Description,LocationDescription,Arrest,Domestic,Beat,District,Ward,CommunityArea,FBI Code,XCoordinate,YCoordinate,UpdatedOn,Latitude,Longitude,Location
 2700763,7732229,,2010-05-26 00:00:00,26,May,2010,113XX S HALSTED ST,1150,,CREDIT CARD FRAUD,,False,False,2233,22.0,34.0,,11,,,,41.688043288,-87.6422444,"(41.688043288, -87.6422444)"`
@@ -614,6 +728,135 @@ func TestCSVQueries(t *testing.T) {
 	}
 }
 
+// readEvents splits an event stream into its records payload and the
+// payloads of the remaining events by event type.
+func readEvents(stream []byte) (records string, events map[string][]string) {
+	events = make(map[string][]string)
+	for len(stream) > 0 {
+		totalLength := int(binary.BigEndian.Uint32(stream[0:4]))
+		headerLength := int(binary.BigEndian.Uint32(stream[4:8]))
+		headers, payload := stream[12:12+headerLength], stream[12+headerLength:totalLength-4]
+		stream = stream[totalLength:]
+
+		var eventType string
+		for len(headers) > 0 {
+			name := string(headers[1 : 1+headers[0]])
+			headers = headers[1+headers[0]+1:]
+			valueLength := int(binary.BigEndian.Uint16(headers[0:2]))
+			value := string(headers[2 : 2+valueLength])
+			headers = headers[2+valueLength:]
+			if name == ":event-type" || name == ":error-code" {
+				eventType = value
+			}
+		}
+		if eventType == "Records" {
+			records += string(payload)
+		} else {
+			events[eventType] = append(events[eventType], string(payload))
+		}
+	}
+	delete(events, "Cont")
+	return records, events
+}
+
+func TestCSVEvaluateObjects(t *testing.T) {
+	objects := []struct {
+		key  string
+		data string
+		err  error
+	}{
+		{key: "a.csv", data: "name,n\na,1\nb,2\n"},
+		{key: "missing.csv", err: errors.New("object not found")},
+		{key: "c.csv", data: "name,n\nc,3\n"},
+	}
+
+	var testTable = []struct {
+		name       string
+		query      string
+		wantResult string
+	}{
+		{
+			name:       "concatenated",
+			query:      `SELECT name FROM S3Object`,
+			wantResult: "a\nb\nc",
+		},
+		{
+			name:       "aggregate-across-objects",
+			query:      `SELECT SUM(CAST(n AS INT)) FROM S3Object`,
+			wantResult: "6",
+		},
+		{
+			name:       "limit-across-objects",
+			query:      `SELECT name FROM S3Object LIMIT 2`,
+			wantResult: "a\nb",
+		},
+	}
+
+	defRequest := `<?xml version="1.0" encoding="UTF-8"?>
+<SelectObjectContentRequest>
+    <Expression>%s</Expression>
+    <ExpressionType>SQL</ExpressionType>
+    <InputSerialization>
+        <CompressionType>NONE</CompressionType>
+        <CSV>
+        	<FileHeaderInfo>USE</FileHeaderInfo>
+        </CSV>
+    </InputSerialization>
+    <OutputSerialization>
+        <CSV>
+        </CSV>
+    </OutputSerialization>
+    <RequestProgress>
+        <Enabled>FALSE</Enabled>
+    </RequestProgress>
+</SelectObjectContentRequest>`
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			s3Select, err := NewS3Select(bytes.NewReader([]byte(fmt.Sprintf(defRequest, testCase.query))))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			i := 0
+			w := &testResponseWriter{}
+			s3Select.EvaluateObjects(w, func() (SelectObject, bool) {
+				if i == len(objects) {
+					return SelectObject{}, false
+				}
+				obj := objects[i]
+				i++
+				return SelectObject{
+					Key: obj.key,
+					GetReader: func(offset, length int64) (io.ReadCloser, error) {
+						if obj.err != nil {
+							return nil, obj.err
+						}
+						return ioutil.NopCloser(bytes.NewBufferString(obj.data)), nil
+					},
+				}, true
+			})
+			s3Select.Close()
+
+			records, events := readEvents(w.response)
+			if got := strings.TrimSpace(records); got != testCase.wantResult {
+				t.Errorf("received response does not match with expected reply. Query: %s\ngot: %s\nwant:%s", testCase.query, got, testCase.wantResult)
+			}
+			if len(events["End"]) != 1 {
+				t.Errorf("expected the query to end successfully, got %v", events)
+			}
+			if testCase.name == "limit-across-objects" {
+				// The query is done before reaching the missing object.
+				return
+			}
+			wantError := `<?xml version="1.0" encoding="UTF-8"?><ObjectError><Key>missing.csv</Key><Code>InternalError</Code><Message>object not found</Message></ObjectError>`
+			if !reflect.DeepEqual(events["ObjectError"], []string{wantError}) {
+				t.Errorf("expected %s, got %v", wantError, events["ObjectError"])
+			}
+		})
+	}
+}
+
 func TestCSVQueries2(t *testing.T) {
 	input := `id,time,num,num2,text
 1,2010-01-01T,7867786,4565.908123,"a text, with comma"
@@ -50,6 +50,11 @@ var (
 type qProp struct {
 	isAggregation, isRowFunc bool
 
+	// Top level record keys referenced by the term, the whole
+	// record is referenced when allKeys is set.
+	keys    []string
+	allKeys bool
+
 	err error
 }
 
@@ -65,6 +70,8 @@ func (p *qProp) combine(q qProp) {
 	default:
 		p.isAggregation = p.isAggregation || q.isAggregation
 		p.isRowFunc = p.isRowFunc || q.isRowFunc
+		p.keys = append(p.keys, q.keys...)
+		p.allKeys = p.allKeys || q.allKeys
 		if p.isAggregation && p.isRowFunc {
 			p.err = errNestedAggregation
 		}
@@ -73,7 +80,7 @@ func (p *qProp) combine(q qProp) {
 
 func (e *SelectExpression) analyze(s *Select) (result qProp) {
 	if e.All {
-		return qProp{isRowFunc: true}
+		return qProp{isRowFunc: true, allKeys: true}
 	}
 
 	for _, ex := range e.Expressions {
@@ -185,6 +192,7 @@ func (e *PrimaryTerm) analyze(s *Select) (result qProp) {
 			}
 		}
 		result = qProp{isRowFunc: true}
+		result.addKeypath(e.JPathExpr, s.From.As)
 
 	case e.ListExpr != nil:
 		result = e.ListExpr.analyze(s)
@@ -201,6 +209,23 @@ func (e *PrimaryTerm) analyze(s *Select) (result qProp) {
 	return
 }
 
+// addKeypath records the top level record key looked up by the path.
+func (p *qProp) addKeypath(e *JSONPath, tableAlias string) {
+	if tableAlias == "" {
+		tableAlias = baseTableName
+	}
+	pathExpr := e.StripTableAlias(tableAlias)
+	switch {
+	case len(pathExpr) == 0:
+		p.keys = append(p.keys, e.BaseKey.String())
+	case pathExpr[0].Key != nil:
+		p.keys = append(p.keys, pathExpr[0].Key.keyString())
+	default:
+		// Wildcards and indexes on the record itself.
+		p.allKeys = true
+	}
+}
+
 func (e *FuncExpr) analyze(s *Select) (result qProp) {
 	funcName := e.getFunctionName()
 
@@ -246,7 +271,7 @@ func (e *FuncExpr) analyze(s *Select) (result qProp) {
 		if exprA.isAggregation {
 			return qProp{err: errNestedAggregation}
 		}
-		return qProp{isAggregation: true}
+		return qProp{isAggregation: true, keys: exprA.keys, allKeys: exprA.allKeys}
 
 	case sqlFnCoalesce:
 		if len(e.SFunc.ArgsList) == 0 {
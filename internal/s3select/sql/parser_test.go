@@ -19,6 +19,7 @@ package sql
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/alecthomas/participle"
@@ -384,3 +385,58 @@ func TestSqlLexerArithOps(t *testing.T) {
 	// 	fmt.Printf("%d: %#v\n", i, t)
 	// }
 }
+
+func TestProjectedKeys(t *testing.T) {
+	cases := []struct {
+		query string
+		keys  []string
+		ok    bool
+	}{
+		{"select * from s3object", nil, false},
+		{"select s.* from s3object s", nil, false},
+		{"select s.a.b, c from s3object s where s.d[0].e > 1 and s.a.f = 'x'", []string{"a", "c", "d"}, true},
+		{"select count(*) from s3object", []string{}, true},
+		{"select max(s.size) from s3object s where s.req.status >= 500", []string{"size", "req"}, true},
+		{"select s.a from s3object[*].records s", nil, false},
+	}
+	for i, tc := range cases {
+		stmt, err := ParseSelectStatement(tc.query)
+		if err != nil {
+			t.Fatalf("Case %d: %v", i+1, err)
+		}
+		keys, ok := stmt.ProjectedKeys()
+		if ok != tc.ok || !reflect.DeepEqual(keys, tc.keys) {
+			t.Errorf("Case %d: expected %v %v, got %v %v", i+1, tc.keys, tc.ok, keys, ok)
+		}
+	}
+}
+
+func TestColumnBounds(t *testing.T) {
+	cases := []struct {
+		query    string
+		bounds   int
+		min, max *Value
+		excluded bool
+	}{
+		{"select * from s3object s where s.size > 100", 1, FromInt(10), FromInt(50), true},
+		{"select * from s3object s where s.size > 100", 1, FromInt(10), FromInt(150), false},
+		{"select * from s3object s where s.size between -5 and 5", 1, FromInt(10), FromInt(20), true},
+		{"select * from s3object s where s.name = 'm' and s.size < 3", 2, FromString("a"), FromString("k"), true},
+		{"select * from s3object s where s.size > 100 or s.size < 5", 0, nil, nil, false},
+		{"select * from s3object s where s.size + 1 > 100", 0, nil, nil, false},
+		{"select * from s3object s where 100 < s.size", 0, nil, nil, false},
+	}
+	for i, tc := range cases {
+		stmt, err := ParseSelectStatement(tc.query)
+		if err != nil {
+			t.Fatalf("Case %d: %v", i+1, err)
+		}
+		bounds := stmt.ColumnBounds()
+		if len(bounds) != tc.bounds {
+			t.Fatalf("Case %d: expected %d bounds, got %v", i+1, tc.bounds, bounds)
+		}
+		if len(bounds) > 0 && bounds[0].Excludes(tc.min, tc.max) != tc.excluded {
+			t.Errorf("Case %d: expected excluded %v", i+1, tc.excluded)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sql
+
+// ColumnBound - a range of values of a top level column which the WHERE
+// clause requires, records with a value outside of it never match.
+type ColumnBound struct {
+	Column string
+	// Inclusive limits, nil when unbounded.
+	Min, Max *Value
+}
+
+// Excludes returns true when no value in [min, max] can be within the
+// bound, such that a block of records with these column statistics can
+// be skipped. Values of different types never exclude each other.
+func (b ColumnBound) Excludes(min, max *Value) bool {
+	if b.Min != nil && max != nil && boundComparable(b.Min, max) {
+		if res, err := max.compareOp(opLt, b.Min); err == nil && res {
+			return true
+		}
+	}
+	if b.Max != nil && min != nil && boundComparable(b.Max, min) {
+		if res, err := min.compareOp(opGt, b.Max); err == nil && res {
+			return true
+		}
+	}
+	return false
+}
+
+func boundComparable(a, b *Value) bool {
+	if a.isNumeric() && b.isNumeric() {
+		return true
+	}
+	_, okA := a.ToString()
+	_, okB := b.ToString()
+	return okA && okB
+}
+
+// ColumnBounds returns the bounds the WHERE clause puts on top level
+// columns. Only comparisons of a column with a literal joined by AND
+// are considered, any OR in the clause yields no bounds.
+func (e *SelectStatement) ColumnBounds() []ColumnBound {
+	where := e.selectAST.Where
+	if where == nil || len(where.And) != 1 || len(e.selectAST.From.Table.PathExpr) > 0 {
+		return nil
+	}
+
+	var bounds []ColumnBound
+	for _, cond := range where.And[0].Condition {
+		if cond.Operand == nil || cond.Operand.ConditionRHS == nil {
+			continue
+		}
+		rhs := cond.Operand.ConditionRHS
+		column, ok := operandColumn(cond.Operand.Operand, e.tableAlias)
+		if !ok {
+			continue
+		}
+		switch {
+		case rhs.Compare != nil:
+			v, ok := operandLiteral(rhs.Compare.Operand)
+			if !ok {
+				continue
+			}
+			switch rhs.Compare.Operator {
+			case opEq:
+				bounds = append(bounds, ColumnBound{Column: column, Min: v, Max: v})
+			case opLt, opLte:
+				bounds = append(bounds, ColumnBound{Column: column, Max: v})
+			case opGt, opGte:
+				bounds = append(bounds, ColumnBound{Column: column, Min: v})
+			}
+		case rhs.Between != nil && !rhs.Between.Not:
+			start, ok1 := operandLiteral(rhs.Between.Start)
+			end, ok2 := operandLiteral(rhs.Between.End)
+			if ok1 && ok2 {
+				bounds = append(bounds, ColumnBound{Column: column, Min: start, Max: end})
+			}
+		}
+	}
+	return bounds
+}
+
+// operandPrimary returns the primary term of an operand without any
+// arithmetic.
+func operandPrimary(op *Operand) (*PrimaryTerm, bool) {
+	if op == nil || len(op.Right) > 0 || op.Left == nil || len(op.Left.Right) > 0 || op.Left.Left == nil {
+		return nil, false
+	}
+	return op.Left.Left.Primary, op.Left.Left.Primary != nil
+}
+
+// operandColumn returns the top level column named by the operand.
+func operandColumn(op *Operand, tableAlias string) (string, bool) {
+	p, ok := operandPrimary(op)
+	if !ok || p.JPathExpr == nil {
+		return "", false
+	}
+	if tableAlias == "" {
+		tableAlias = baseTableName
+	}
+	pathExpr := p.JPathExpr.StripTableAlias(tableAlias)
+	switch {
+	case len(pathExpr) == 0:
+		return p.JPathExpr.BaseKey.String(), true
+	case len(pathExpr) == 1 && pathExpr[0].Key != nil:
+		return pathExpr[0].Key.keyString(), true
+	}
+	return "", false
+}
+
+// operandLiteral returns the value of a literal operand.
+func operandLiteral(op *Operand) (*Value, bool) {
+	if op != nil && len(op.Right) == 0 && op.Left != nil && len(op.Left.Right) == 0 && op.Left.Left != nil {
+		if neg := op.Left.Left.Negated; neg != nil && neg.Term.Value != nil {
+			v, err := neg.Term.Value.evalNode(nil)
+			if err != nil || !v.isNumeric() {
+				return nil, false
+			}
+			v.negate()
+			return v, true
+		}
+	}
+	p, ok := operandPrimary(op)
+	if !ok || p.Value == nil {
+		return nil, false
+	}
+	v, err := p.Value.evalNode(nil)
+	if err != nil || v.IsNull() {
+		return nil, false
+	}
+	if _, ok := v.ToBool(); ok {
+		return nil, false
+	}
+	return v, true
+}
@@ -50,6 +50,10 @@ type SelectStatement struct {
 
 	// Table alias
 	tableAlias string
+
+	// Top level record keys needed by the query, nil when the
+	// whole record is needed.
+	projection []string
 }
 
 // ParseSelectStatement - parses a select query from the given string
@@ -86,8 +90,9 @@ func ParseSelectStatement(s string) (stmt SelectStatement, err error) {
 	}
 
 	// Analyze where clause
+	var whereQProp qProp
 	if selectAST.Where != nil {
-		whereQProp := selectAST.Where.analyze(&selectAST)
+		whereQProp = selectAST.Where.analyze(&selectAST)
 		if whereQProp.err != nil {
 			err = errQueryAnalysisFailure(fmt.Errorf("Where clause error: %w", whereQProp.err))
 			return
@@ -114,9 +119,28 @@ func ParseSelectStatement(s string) (stmt SelectStatement, err error) {
 
 	// Set table alias
 	stmt.tableAlias = selectAST.From.As
+
+	// Keys are relative to the record only without a FROM keypath.
+	if !stmt.selectQProp.allKeys && !whereQProp.allKeys && len(selectAST.From.Table.PathExpr) == 0 {
+		stmt.projection = []string{}
+		seen := make(map[string]struct{})
+		for _, key := range append(stmt.selectQProp.keys, whereQProp.keys...) {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				stmt.projection = append(stmt.projection, key)
+			}
+		}
+	}
 	return
 }
 
+// ProjectedKeys returns the top level keys of the input records
+// referenced by the query, the other keys can be dropped while reading
+// the records. It returns false when the whole record is needed.
+func (e *SelectStatement) ProjectedKeys() ([]string, bool) {
+	return e.projection, e.projection != nil
+}
+
 func validateTableName(from *TableExpression) error {
 	if strings.ToLower(from.Table.BaseKey.String()) != baseTableName {
 		return errBadTableName(errors.New("table name must be `s3object`"))
@@ -0,0 +1,284 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maximum number of spans sent in a single export request.
+	exportBatchSize = 512
+	// maximum time a finished span waits before being exported.
+	exportInterval = 5 * time.Second
+	// number of finished spans buffered before new ones are dropped.
+	exportQueueSize = 10000
+)
+
+// ExporterArgs configures an OTLP/HTTP span exporter.
+type ExporterArgs struct {
+	// Endpoint is the full OTLP/HTTP traces URL, e.g.
+	// "http://otel-collector:4318/v1/traces".
+	Endpoint    string
+	ServiceName string
+	// ServiceInstance identifies this server among its peers.
+	ServiceInstance string
+	AuthToken       string
+	Transport       http.RoundTripper
+	// LogOnce is called on export failures.
+	LogOnce func(ctx context.Context, err error, id interface{})
+}
+
+// Exporter batches finished spans and sends them to an OTLP
+// collector using the OTLP/HTTP JSON encoding.
+type Exporter struct {
+	args    ExporterArgs
+	client  *http.Client
+	queue   chan *Span
+	doneCh  chan struct{}
+	once    sync.Once
+	dropped uint64
+}
+
+// NewExporter creates an exporter and starts its export loop.
+func NewExporter(args ExporterArgs) *Exporter {
+	e := &Exporter{
+		args:   args,
+		client: &http.Client{Transport: args.Transport, Timeout: 30 * time.Second},
+		queue:  make(chan *Span, exportQueueSize),
+		doneCh: make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Dropped returns the number of spans discarded because the
+// export queue was full.
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Close flushes pending spans and stops the export loop.
+func (e *Exporter) Close() {
+	e.once.Do(func() {
+		close(e.doneCh)
+	})
+}
+
+func (e *Exporter) enqueue(s *Span) {
+	select {
+	case <-e.doneCh:
+	case e.queue <- s:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+func (e *Exporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil && e.args.LogOnce != nil {
+			e.args.LogOnce(context.Background(), err, "tracing-otlp-export")
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case <-e.doneCh:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) == exportBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) == exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (e *Exporter) export(spans []*Span) error {
+	buf, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.args.Endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.args.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+e.args.AuthToken)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tracing: unable to export spans to %s: %w", e.args.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("tracing: unable to export spans to %s: %s", e.args.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON wire types, only the subset we emit.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// OTLP status codes
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+func otlpAttr(key string, value interface{}) otlpKeyValue {
+	kv := otlpKeyValue{Key: key}
+	switch v := value.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case bool:
+		kv.Value.BoolValue = &v
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		kv.Value.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &s
+	case uint64:
+		s := strconv.FormatUint(v, 10)
+		kv.Value.IntValue = &s
+	case float64:
+		kv.Value.DoubleValue = &v
+	default:
+		s := fmt.Sprint(v)
+		kv.Value.StringValue = &s
+	}
+	return kv
+}
+
+func (e *Exporter) encode(spans []*Span) otlpRequest {
+	resource := otlpResource{
+		Attributes: []otlpKeyValue{otlpAttr("service.name", e.args.ServiceName)},
+	}
+	if e.args.ServiceInstance != "" {
+		resource.Attributes = append(resource.Attributes, otlpAttr("service.instance.id", e.args.ServiceInstance))
+	}
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+		if s.parent.IsValid() {
+			span.ParentSpanID = s.parent.String()
+		}
+		for _, attr := range s.attrs {
+			span.Attributes = append(span.Attributes, otlpAttr(attr.Key, attr.Value))
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.errMsg}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: resource,
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/minio/minio"},
+				Spans: out,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracing implements a minimal distributed tracer compatible with
+// W3C trace context propagation, exporting spans with the OTLP protocol.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceParentHeader is the W3C trace context propagation header.
+const TraceParentHeader = "traceparent"
+
+// Kind describes the relationship between a span and its parent,
+// values match the OTLP SpanKind enumeration.
+type Kind int
+
+// Supported span kinds
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// TraceID uniquely identifies a trace.
+type TraceID [16]byte
+
+// SpanID uniquely identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid returns true if the trace id is non-zero.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid returns true if the span id is non-zero.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// SpanContext is the propagated part of a span.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns true if both trace id and span id are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Attribute is a key value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span records a single timed operation. All methods are safe to
+// call on a nil span, which is what Start returns when the request
+// is not sampled, so callers never have to check.
+type Span struct {
+	mu         sync.Mutex
+	sc         SpanContext
+	parent     SpanID
+	name       string
+	kind       Kind
+	start, end time.Time
+	attrs      []Attribute
+	errMsg     string
+	ended      bool
+	exporter   *Exporter
+}
+
+// SetAttr attaches an attribute to the span.
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, Attribute{Key: key, Value: value})
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed, nil errors are ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// Context returns the propagated span context.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// End finishes the span and queues it for export, calling End
+// more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.exporter.enqueue(s)
+}
+
+type tracer struct {
+	exporter    *Exporter
+	sampleRatio float64
+}
+
+var globalTracer atomic.Value // *tracer
+
+// SetExporter installs the exporter used for all new spans, root
+// spans are sampled with the given probability. A nil exporter
+// disables tracing. Any previously installed exporter is closed.
+func SetExporter(e *Exporter, sampleRatio float64) {
+	var old *tracer
+	if v, ok := globalTracer.Load().(*tracer); ok {
+		old = v
+	}
+	globalTracer.Store(&tracer{exporter: e, sampleRatio: sampleRatio})
+	if old != nil && old.exporter != nil && old.exporter != e {
+		old.exporter.Close()
+	}
+}
+
+func getTracer() *tracer {
+	t, ok := globalTracer.Load().(*tracer)
+	if !ok || t.exporter == nil {
+		return nil
+	}
+	return t
+}
+
+// Enabled returns true if an exporter has been configured.
+func Enabled() bool {
+	return getTracer() != nil
+}
+
+type contextKeyType string
+
+const (
+	spanContextKey   = contextKeyType("span")
+	remoteContextKey = contextKeyType("remote-span")
+)
+
+// FromContext returns the active span context, either a local
+// span or a remote parent extracted from request headers.
+func FromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if s, ok := ctx.Value(spanContextKey).(*Span); ok && s != nil {
+		return s.sc
+	}
+	if sc, ok := ctx.Value(remoteContextKey).(SpanContext); ok {
+		return sc
+	}
+	return SpanContext{}
+}
+
+// Start creates a new span as a child of the span in ctx, if ctx has
+// no span a new trace is started subject to sampling. When tracing is
+// disabled or the trace is not sampled a nil span is returned.
+func Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	t := getTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	parent := FromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+	if !parent.IsValid() {
+		sc.TraceID = newTraceID()
+		sc.Sampled = sample(t.sampleRatio)
+	}
+	if !sc.Sampled {
+		if !parent.IsValid() {
+			// Remember the decision so that children
+			// are not sampled again independently.
+			sc.SpanID = newSpanID()
+			ctx = context.WithValue(ctx, remoteContextKey, sc)
+		}
+		return ctx, nil
+	}
+	sc.SpanID = newSpanID()
+	s := &Span{
+		sc:       sc,
+		parent:   parent.SpanID,
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		exporter: t.exporter,
+	}
+	return context.WithValue(ctx, spanContextKey, s), s
+}
+
+// Inject writes the active span context of ctx into h.
+func Inject(ctx context.Context, h http.Header) {
+	sc := FromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	h.Set(TraceParentHeader, fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags))
+}
+
+// Extract reads a W3C traceparent header from h and returns a context
+// carrying it as remote parent, ctx is returned as is if the header
+// is missing or malformed.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, ok := ParseTraceParent(h.Get(TraceParentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteContextKey, sc)
+}
+
+// ParseTraceParent parses a W3C traceparent header value.
+func ParseTraceParent(v string) (sc SpanContext, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, false
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+	return sc, sc.IsValid()
+}
+
+func newTraceID() (t TraceID) {
+	rand.Read(t[:])
+	return t
+}
+
+func newSpanID() (s SpanID) {
+	rand.Read(s[:])
+	return s
+}
+
+func sample(ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return float64(binary.LittleEndian.Uint64(b[:])>>11)/float64(1<<53) < ratio
+}
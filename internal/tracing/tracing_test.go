@@ -0,0 +1,137 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	testCases := []struct {
+		value   string
+		success bool
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", false, false},
+		{"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"", false, false},
+	}
+	for i, testCase := range testCases {
+		sc, ok := ParseTraceParent(testCase.value)
+		if ok != testCase.success {
+			t.Errorf("Test %d: expected success %t, got %t", i+1, testCase.success, ok)
+		}
+		if ok && sc.Sampled != testCase.sampled {
+			t.Errorf("Test %d: expected sampled %t, got %t", i+1, testCase.sampled, sc.Sampled)
+		}
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	e := NewExporter(ExporterArgs{Endpoint: "http://127.0.0.1:0", ServiceName: "minio"})
+	SetExporter(e, 0)
+	defer SetExporter(nil, 0)
+
+	// Unsampled roots propagate the decision but record nothing.
+	ctx, span := Start(context.Background(), "root", KindServer)
+	if span != nil {
+		t.Fatal("expected no span with a zero sample ratio")
+	}
+	h := http.Header{}
+	Inject(ctx, h)
+	sc, ok := ParseTraceParent(h.Get(TraceParentHeader))
+	if !ok || sc.Sampled {
+		t.Fatalf("expected unsampled trace context, got %q", h.Get(TraceParentHeader))
+	}
+
+	// Sampled remote parents are always honored.
+	h.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span = Start(Extract(context.Background(), h), "child", KindServer)
+	if span == nil {
+		t.Fatal("expected a span for a sampled parent")
+	}
+	if span.Context().TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace id %s", span.Context().TraceID)
+	}
+	if span.parent.String() != "00f067aa0ba902b7" {
+		t.Errorf("unexpected parent span id %s", span.parent)
+	}
+	if FromContext(ctx) != span.Context() {
+		t.Error("expected the new span to be active in the returned context")
+	}
+}
+
+func TestExporter(t *testing.T) {
+	received := make(chan otlpRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- req
+	}))
+	defer srv.Close()
+
+	e := NewExporter(ExporterArgs{Endpoint: srv.URL, ServiceName: "minio", AuthToken: "token"})
+	SetExporter(e, 1)
+
+	_, span := Start(context.Background(), "s3.GetObject", KindServer)
+	span.SetAttr("s3.bucket", "bucket")
+	span.SetAttr("http.status_code", 500)
+	span.SetError(errors.New("Internal Server Error"))
+	span.End()
+	span.End()
+
+	// Closing flushes pending spans.
+	SetExporter(nil, 0)
+
+	select {
+	case req := <-received:
+		spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		if spans[0].Name != "s3.GetObject" || spans[0].Kind != KindServer {
+			t.Errorf("unexpected span %#v", spans[0])
+		}
+		if spans[0].Status.Code != otlpStatusError {
+			t.Errorf("expected error status, got %d", spans[0].Status.Code)
+		}
+		if len(spans[0].Attributes) != 2 || *spans[0].Attributes[1].Value.IntValue != "500" {
+			t.Errorf("unexpected attributes %#v", spans[0].Attributes)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for exported spans")
+	}
+}